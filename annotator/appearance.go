@@ -0,0 +1,721 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/textencoding"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// AppearanceDef defines the appearance streams of an annotation or a widget
+// annotation generated by GenerateAppearance and GenerateWidgetAppearance:
+// a background, a border and a text, optionally rotated.
+type AppearanceDef struct {
+	// Width and Height are the dimensions of the annotation rectangle.
+	Width  float64
+	Height float64
+
+	// Rotation is the counterclockwise rotation of the contents of the
+	// annotation, in degrees, as the R entry of the appearance
+	// characteristics (MK) of widgets. It must be a multiple of 90.
+	Rotation int
+
+	// BorderStyle, BorderWidth and BorderColor define the border. No border
+	// is drawn if BorderWidth is 0 or BorderColor is nil. DashArray is the
+	// dash pattern of dashed borders, [3] if empty.
+	BorderStyle model.BorderStyle
+	BorderWidth float64
+	BorderColor model.PdfColor
+	DashArray   []float64
+
+	// BackgroundColor is the color of the background. The background is
+	// transparent if nil.
+	BackgroundColor model.PdfColor
+
+	// Text is the text drawn inside of the border. RolloverText and DownText
+	// are the texts of the rollover and down appearances of widgets, Text if
+	// empty.
+	Text         string
+	RolloverText string
+	DownText     string
+
+	// DA is the default appearance string, specifying the font, font size
+	// and color of the text, e.g. "/Helv 12 Tf 0 g". The font is looked up
+	// in Resources, e.g. the resources (DR) of the interactive form, and
+	// Helvetica is used if not found. The font size is chosen to fit the text
+	// if 0.
+	DA        string
+	Resources *model.PdfPageResources
+
+	// DS is the default style string of rich text (12.7.3.4 - p. 472), e.g.
+	// "font: bold 12pt Helvetica; color: #FF0000; text-align: center". The
+	// style overrides the font size, color and alignment of DA. The font
+	// families are mapped to the standard 14 fonts.
+	DS string
+
+	// Alignment is the quadding of the text: 0 - left, 1 - centered,
+	// 2 - right.
+	Alignment int
+
+	// Multiline wraps the text to the width of the annotation. A single line
+	// is vertically centered otherwise.
+	Multiline bool
+}
+
+// appearanceState represents the appearance states of widgets.
+type appearanceState int
+
+const (
+	appearanceNormal appearanceState = iota
+	appearanceRollover
+	appearanceDown
+)
+
+// appearanceText is the font, size and color of the text of an appearance.
+type appearanceText struct {
+	name  core.PdfObjectName
+	font  *model.PdfFont
+	size  float64
+	color model.PdfColor
+}
+
+// NewAppearanceDefFromWidget returns an appearance definition initialized
+// from the rectangle, the appearance characteristics (MK) and the border
+// style (BS) of the widget annotation `wa`. The caption entries of the
+// appearance characteristics (CA, RC and AC) are used as texts.
+func NewAppearanceDefFromWidget(wa *model.PdfAnnotationWidget) (AppearanceDef, error) {
+	def := AppearanceDef{Alignment: 1}
+	array, ok := core.GetArray(wa.Rect)
+	if !ok {
+		return def, errors.New("invalid Rect")
+	}
+	rect, err := model.NewPdfRectangle(*array)
+	if err != nil {
+		return def, err
+	}
+	def.Width, def.Height = rect.Width(), rect.Height()
+
+	def.BorderWidth = 1
+	if bs, ok := core.GetDict(wa.BS); ok {
+		if w, err := core.GetNumberAsFloat(core.TraceToDirectObject(bs.Get("W"))); err == nil {
+			def.BorderWidth = w
+		}
+		if name, ok := core.GetNameVal(bs.Get("S")); ok {
+			def.BorderStyle = borderStyleFromName(name)
+		}
+		if arr, ok := core.GetArray(bs.Get("D")); ok {
+			if dash, err := arr.ToFloat64Array(); err == nil {
+				def.DashArray = dash
+			}
+		}
+	}
+
+	if mk, ok := core.GetDict(wa.MK); ok {
+		if r, err := core.GetNumberAsFloat(core.TraceToDirectObject(mk.Get("R"))); err == nil {
+			def.Rotation = int(r)
+		}
+		def.BorderColor = colorFromArray(mk.Get("BC"))
+		def.BackgroundColor = colorFromArray(mk.Get("BG"))
+		if s, ok := core.GetString(mk.Get("CA")); ok {
+			def.Text = s.Decoded()
+		}
+		if s, ok := core.GetString(mk.Get("RC")); ok {
+			def.RolloverText = s.Decoded()
+		}
+		if s, ok := core.GetString(mk.Get("AC")); ok {
+			def.DownText = s.Decoded()
+		}
+	}
+	return def, nil
+}
+
+// GenerateAppearance returns an appearance dictionary containing the normal
+// appearance (N) defined by `def`.
+func GenerateAppearance(def AppearanceDef) (*core.PdfObjectDictionary, error) {
+	xform, err := def.generate(appearanceNormal)
+	if err != nil {
+		return nil, err
+	}
+	apDict := core.MakeDict()
+	apDict.Set("N", xform.ToPdfObject())
+	return apDict, nil
+}
+
+// GenerateWidgetAppearance returns an appearance dictionary containing the
+// normal (N), rollover (R) and down (D) appearances defined by `def`, as
+// used by push buttons. The background of the rollover appearance is
+// lightened and the background of the down appearance is darkened, with the
+// shading of beveled and inset borders inverted.
+func GenerateWidgetAppearance(def AppearanceDef) (*core.PdfObjectDictionary, error) {
+	apDict := core.MakeDict()
+	for _, state := range []struct {
+		key   core.PdfObjectName
+		state appearanceState
+	}{
+		{"N", appearanceNormal},
+		{"R", appearanceRollover},
+		{"D", appearanceDown},
+	} {
+		xform, err := def.generate(state.state)
+		if err != nil {
+			return nil, err
+		}
+		apDict.Set(state.key, xform.ToPdfObject())
+	}
+	return apDict, nil
+}
+
+// generate returns the appearance XObject form of the state `state`.
+func (def AppearanceDef) generate(state appearanceState) (*model.XObjectForm, error) {
+	if def.Width <= 0 || def.Height <= 0 {
+		return nil, errors.New("invalid appearance dimensions")
+	}
+	rotation := def.Rotation % 360
+	if rotation < 0 {
+		rotation += 360
+	}
+	if rotation%90 != 0 {
+		return nil, errors.New("rotation must be a multiple of 90")
+	}
+
+	cc := contentstream.NewContentCreator()
+	cc.Add_q()
+
+	// The contents are drawn in the coordinates of the unrotated rectangle.
+	width, height := def.Width, def.Height
+	switch rotation {
+	case 90:
+		cc.Add_cm(0, 1, -1, 0, def.Width, 0)
+		width, height = height, width
+	case 180:
+		cc.Add_cm(-1, 0, 0, -1, def.Width, def.Height)
+	case 270:
+		cc.Add_cm(0, -1, 1, 0, 0, def.Height)
+		width, height = height, width
+	}
+
+	background := def.BackgroundColor
+	switch state {
+	case appearanceRollover:
+		background = lightenColor(background, 0.25)
+	case appearanceDown:
+		background = darkenColor(background, 0.25)
+	}
+	if background != nil {
+		cc.Add_q().
+			SetNonStrokingColor(background).
+			Add_re(0, 0, width, height).
+			Add_f().
+			Add_Q()
+	}
+	drawBorder(cc, def.BorderStyle, def.BorderWidth, def.BorderColor, def.DashArray, background,
+		state == appearanceDown, width, height)
+
+	resources := model.NewPdfPageResources()
+	text := def.Text
+	switch {
+	case state == appearanceRollover && def.RolloverText != "":
+		text = def.RolloverText
+	case state == appearanceDown && def.DownText != "":
+		text = def.DownText
+	}
+	if text != "" {
+		if err := def.drawText(cc, text, resources, width, height); err != nil {
+			return nil, err
+		}
+	}
+	cc.Add_Q()
+
+	xform := model.NewXObjectForm()
+	xform.Resources = resources
+	xform.BBox = core.MakeArrayFromFloats([]float64{0, 0, def.Width, def.Height})
+	if err := xform.SetContentStream(cc.Bytes(), defStreamEncoder()); err != nil {
+		return nil, err
+	}
+	return xform, nil
+}
+
+// drawText draws the text `text` inside of the border of the appearance of
+// size `width`x`height`, adding its font to `resources`.
+func (def AppearanceDef) drawText(cc *contentstream.ContentCreator, text string,
+	resources *model.PdfPageResources, width, height float64) error {
+	at, alignment, err := def.textStyle()
+	if err != nil {
+		return err
+	}
+	if err := resources.SetFontByName(at.name, at.font.ToPdfObject()); err != nil {
+		return err
+	}
+
+	padding := 2.0
+	if def.BorderWidth > 0 && def.BorderColor != nil {
+		padding += def.BorderWidth
+		if def.BorderStyle == model.BorderStyleBeveled || def.BorderStyle == model.BorderStyleInset {
+			padding += def.BorderWidth
+		}
+	}
+	innerWidth, innerHeight := width-2*padding, height-2*padding
+	if innerWidth <= 0 || innerHeight <= 0 {
+		return nil
+	}
+
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	text = strings.Replace(text, "\r", "\n", -1)
+	size := at.size
+	var lines []string
+	if def.Multiline {
+		if size <= 0 {
+			size = 12
+		}
+		lines = wrapText(text, at.font, size, innerWidth)
+	} else {
+		lines = []string{strings.Replace(text, "\n", " ", -1)}
+		if size <= 0 {
+			// Fit the text in the inner area.
+			size = 0.65 * innerHeight
+			if w := textWidth(lines[0], at.font, 1); w > 0 && w*size > innerWidth {
+				size = innerWidth / w
+			}
+		}
+	}
+
+	capHeight := 0.7
+	if descriptor, err := at.font.GetFontDescriptor(); err == nil && descriptor != nil {
+		if ch, err := descriptor.GetCapHeight(); err == nil && ch > 0 {
+			capHeight = ch / 1000
+		}
+	}
+	leading := 1.2 * size
+
+	encoder := at.font.Encoder()
+	if encoder == nil {
+		common.Log.Debug("WARN: font encoder is nil. Assuming identity encoder. Output may be incorrect.")
+		encoder = textencoding.NewIdentityTextEncoder("Identity-H")
+	}
+
+	// Text, clipped to the inner area.
+	cc.Add_re(padding, padding, innerWidth, innerHeight).Add_W().Add_n()
+	cc.Add_BT()
+	if at.color != nil {
+		cc.SetNonStrokingColor(at.color)
+	}
+	cc.Add_Tf(at.name, size)
+
+	y := height - padding - size
+	if !def.Multiline {
+		y = (height - capHeight*size) / 2
+	}
+	for _, line := range lines {
+		x := padding
+		if alignment == 1 || alignment == 2 {
+			free := innerWidth - textWidth(line, at.font, size)
+			if alignment == 1 {
+				free /= 2
+			}
+			x += free
+		}
+		cc.Add_Tm(1, 0, 0, 1, x, y).
+			Add_Tj(*core.MakeStringFromBytes(encoder.Encode(line)))
+		y -= leading
+	}
+	cc.Add_ET()
+	return nil
+}
+
+// textStyle returns the font, size and color of the text and its alignment,
+// according to the default appearance and default style strings.
+func (def AppearanceDef) textStyle() (*appearanceText, int, error) {
+	at := &appearanceText{color: model.NewPdfColorDeviceGray(0)}
+	alignment := def.Alignment
+
+	// Default appearance.
+	if def.DA != "" {
+		ops, err := contentstream.NewContentStreamParser(def.DA).Parse()
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, op := range *ops {
+			vals, _ := core.GetNumbersAsFloat(op.Params)
+			switch op.Operand {
+			case "Tf":
+				if len(op.Params) != 2 {
+					continue
+				}
+				if name, ok := core.GetName(op.Params[0]); ok {
+					at.name = *name
+				}
+				if size, err := core.GetNumberAsFloat(op.Params[1]); err == nil {
+					at.size = size
+				}
+			case "g":
+				if len(vals) == 1 {
+					at.color = model.NewPdfColorDeviceGray(vals[0])
+				}
+			case "rg":
+				if len(vals) == 3 {
+					at.color = model.NewPdfColorDeviceRGB(vals[0], vals[1], vals[2])
+				}
+			case "k":
+				if len(vals) == 4 {
+					at.color = model.NewPdfColorDeviceCMYK(vals[0], vals[1], vals[2], vals[3])
+				}
+			}
+		}
+		if at.name != "" && def.Resources != nil {
+			if obj, ok := def.Resources.GetFontByName(at.name); ok {
+				font, err := model.NewPdfFontFromPdfObject(obj)
+				if err != nil {
+					common.Log.Debug("ERROR: could not load appearance font %s: %v", at.name, err)
+				}
+				at.font = font
+			}
+		}
+	}
+
+	// Default style of rich text.
+	if def.DS != "" {
+		ds := parseDefaultStyle(def.DS)
+		if ds.size > 0 {
+			at.size = ds.size
+		}
+		if ds.color != nil {
+			at.color = ds.color
+		}
+		if ds.alignment >= 0 {
+			alignment = ds.alignment
+		}
+		if ds.family != "" || ds.bold || ds.italic {
+			name, fontName := standardFontName(ds.family, ds.bold, ds.italic)
+			font, err := model.NewStandard14Font(fontName)
+			if err != nil {
+				return nil, 0, err
+			}
+			at.name, at.font = name, font
+		}
+	}
+
+	if at.font == nil {
+		font, err := model.NewStandard14Font(model.HelveticaName)
+		if err != nil {
+			return nil, 0, err
+		}
+		at.name, at.font = "Helv", font
+	}
+	return at, alignment, nil
+}
+
+// defaultStyle contains the properties of a default style string.
+type defaultStyle struct {
+	family    string
+	size      float64
+	bold      bool
+	italic    bool
+	color     model.PdfColor
+	alignment int // -1 if not set.
+}
+
+// parseDefaultStyle parses the default style string `ds`, a list of CSS
+// declarations separated by semicolons. The unsupported declarations are
+// ignored.
+func parseDefaultStyle(ds string) defaultStyle {
+	style := defaultStyle{alignment: -1}
+	for _, decl := range strings.Split(ds, ";") {
+		parts := strings.SplitN(decl, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+		switch name {
+		case "font":
+			// [style] [weight] size family
+			fields := strings.Fields(value)
+			for i, field := range fields {
+				if size, ok := parseFontSize(field); ok {
+					style.size = size
+					style.family = strings.Join(fields[i+1:], " ")
+					break
+				}
+				switch strings.ToLower(field) {
+				case "italic", "oblique":
+					style.italic = true
+				default:
+					style.bold = style.bold || isBoldWeight(field)
+				}
+			}
+		case "font-family":
+			style.family = value
+		case "font-size":
+			if size, ok := parseFontSize(value); ok {
+				style.size = size
+			}
+		case "font-weight":
+			style.bold = isBoldWeight(value)
+		case "font-style":
+			v := strings.ToLower(value)
+			style.italic = v == "italic" || v == "oblique"
+		case "color":
+			style.color = parseCSSColor(value)
+		case "text-align":
+			switch strings.ToLower(value) {
+			case "left":
+				style.alignment = 0
+			case "center":
+				style.alignment = 1
+			case "right":
+				style.alignment = 2
+			}
+		}
+	}
+
+	// Use the first family of font family lists.
+	if i := strings.Index(style.family, ","); i >= 0 {
+		style.family = style.family[:i]
+	}
+	style.family = strings.Trim(strings.TrimSpace(style.family), `'"`)
+	return style
+}
+
+// parseFontSize parses a font size in points, e.g. "12pt" or "12".
+func parseFontSize(s string) (float64, bool) {
+	size, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(s), "pt"), 64)
+	if err != nil || size <= 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+// isBoldWeight returns true if the font weight `s` is bold.
+func isBoldWeight(s string) bool {
+	s = strings.ToLower(s)
+	if s == "bold" || s == "bolder" {
+		return true
+	}
+	weight, err := strconv.Atoi(s)
+	return err == nil && weight >= 600
+}
+
+// parseCSSColor parses a color in the #RGB, #RRGGBB or rgb(r, g, b) forms.
+// Returns nil if the color is not valid.
+func parseCSSColor(s string) model.PdfColor {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case strings.HasPrefix(s, "#"):
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return nil
+		}
+		val, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return nil
+		}
+		return model.NewPdfColorDeviceRGB(float64(val>>16)/255, float64(val>>8&0xff)/255, float64(val&0xff)/255)
+	case strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")"):
+		parts := strings.Split(s[4:len(s)-1], ",")
+		if len(parts) != 3 {
+			return nil
+		}
+		var comps [3]float64
+		for i, part := range parts {
+			val, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return nil
+			}
+			comps[i] = math.Max(0, math.Min(val, 255)) / 255
+		}
+		return model.NewPdfColorDeviceRGB(comps[0], comps[1], comps[2])
+	}
+	return nil
+}
+
+// standardFontName returns the resource name and the name of the standard 14
+// font used for the font family `family`.
+func standardFontName(family string, bold, italic bool) (core.PdfObjectName, model.StdFontName) {
+	family = strings.ToLower(family)
+	variant := 0
+	if bold {
+		variant |= 1
+	}
+	if italic {
+		variant |= 2
+	}
+	switch {
+	case strings.Contains(family, "courier") || strings.Contains(family, "mono"):
+		names := []core.PdfObjectName{"Cour", "CoBo", "CoOb", "CoBO"}
+		fonts := []model.StdFontName{model.CourierName, model.CourierBoldName,
+			model.CourierObliqueName, model.CourierBoldObliqueName}
+		return names[variant], fonts[variant]
+	case strings.Contains(family, "times") || family == "serif":
+		names := []core.PdfObjectName{"TiRo", "TiBo", "TiIt", "TiBI"}
+		fonts := []model.StdFontName{model.TimesRomanName, model.TimesBoldName,
+			model.TimesItalicName, model.TimesBoldItalicName}
+		return names[variant], fonts[variant]
+	}
+	names := []core.PdfObjectName{"Helv", "HeBo", "HeOb", "HeBO"}
+	fonts := []model.StdFontName{model.HelveticaName, model.HelveticaBoldName,
+		model.HelveticaObliqueName, model.HelveticaBoldObliqueName}
+	return names[variant], fonts[variant]
+}
+
+// drawBorder draws a border of style `style`, width `width` and color
+// `color` along the edges of the rectangle of size `w`x`h`. The shades of
+// beveled borders are derived from the `background` color, and the shades
+// of beveled and inset borders are swapped if `inverted` is true, as for the
+// down appearance of push buttons.
+func drawBorder(cc *contentstream.ContentCreator, style model.BorderStyle, width float64,
+	color model.PdfColor, dash []float64, background model.PdfColor, inverted bool, w, h float64) {
+	if width <= 0 || color == nil {
+		return
+	}
+	cc.Add_q()
+	cc.SetStrokingColor(color).Add_w(width)
+	half := width / 2
+	switch style {
+	case model.BorderStyleUnderline:
+		cc.Add_m(0, half).Add_l(w, half).Add_S()
+		cc.Add_Q()
+		return
+	case model.BorderStyleDashed:
+		if len(dash) == 0 {
+			dash = []float64{3}
+		}
+		cc.AddOperand(contentstream.ContentStreamOperation{
+			Operand: "d",
+			Params:  []core.PdfObject{core.MakeArrayFromFloats(dash), core.MakeInteger(0)},
+		})
+	}
+	cc.Add_re(half, half, w-width, h-width).Add_S()
+
+	if style == model.BorderStyleBeveled || style == model.BorderStyleInset {
+		var light, dark model.PdfColor
+		if style == model.BorderStyleBeveled {
+			light = model.NewPdfColorDeviceGray(1)
+			if background == nil {
+				background = model.NewPdfColorDeviceGray(1)
+			}
+			dark = darkenColor(background, 0.5)
+		} else {
+			light = model.NewPdfColorDeviceGray(0.5)
+			dark = model.NewPdfColorDeviceGray(0.75)
+		}
+		if inverted {
+			light, dark = dark, light
+		}
+
+		// Top left and bottom right bevels, inside of the border.
+		b := width
+		cc.SetNonStrokingColor(light).
+			Add_m(b, b).Add_l(b, h-b).Add_l(w-b, h-b).
+			Add_l(w-2*b, h-2*b).Add_l(2*b, h-2*b).Add_l(2*b, 2*b).
+			Add_h().Add_f()
+		cc.SetNonStrokingColor(dark).
+			Add_m(w-b, h-b).Add_l(w-b, b).Add_l(b, b).
+			Add_l(2*b, 2*b).Add_l(w-2*b, 2*b).Add_l(w-2*b, h-2*b).
+			Add_h().Add_f()
+	}
+	cc.Add_Q()
+}
+
+// borderStyleFromName returns the border style of the name `name` of the S
+// entry of border style dictionaries. Solid is used for unknown names.
+func borderStyleFromName(name string) model.BorderStyle {
+	switch name {
+	case "D":
+		return model.BorderStyleDashed
+	case "B":
+		return model.BorderStyleBeveled
+	case "I":
+		return model.BorderStyleInset
+	case "U":
+		return model.BorderStyleUnderline
+	}
+	return model.BorderStyleSolid
+}
+
+// colorFromArray returns the color of the color array `obj` of appearance
+// characteristics, with 1 (gray), 3 (RGB) or 4 (CMYK) components. Returns
+// nil for empty or invalid arrays, meaning transparent.
+func colorFromArray(obj core.PdfObject) model.PdfColor {
+	arr, ok := core.GetArray(obj)
+	if !ok {
+		return nil
+	}
+	vals, err := arr.ToFloat64Array()
+	if err != nil {
+		common.Log.Debug("ERROR: invalid color array: %v", err)
+		return nil
+	}
+	switch len(vals) {
+	case 1:
+		return model.NewPdfColorDeviceGray(vals[0])
+	case 3:
+		return model.NewPdfColorDeviceRGB(vals[0], vals[1], vals[2])
+	case 4:
+		return model.NewPdfColorDeviceCMYK(vals[0], vals[1], vals[2], vals[3])
+	}
+	return nil
+}
+
+// darkenColor returns the color `color` darkened by the fraction `f`.
+// Returns nil if `color` is nil.
+func darkenColor(color model.PdfColor, f float64) model.PdfColor {
+	switch t := color.(type) {
+	case *model.PdfColorDeviceGray:
+		return model.NewPdfColorDeviceGray(shade(t.Val(), 0, f))
+	case *model.PdfColorDeviceRGB:
+		return model.NewPdfColorDeviceRGB(shade(t.R(), 0, f), shade(t.G(), 0, f), shade(t.B(), 0, f))
+	case *model.PdfColorDeviceCMYK:
+		return model.NewPdfColorDeviceCMYK(t.C(), t.M(), t.Y(), shade(t.K(), 1, f))
+	}
+	return color
+}
+
+// lightenColor returns the color `color` lightened by the fraction `f`.
+// Returns nil if `color` is nil.
+func lightenColor(color model.PdfColor, f float64) model.PdfColor {
+	switch t := color.(type) {
+	case *model.PdfColorDeviceGray:
+		return model.NewPdfColorDeviceGray(shade(t.Val(), 1, f))
+	case *model.PdfColorDeviceRGB:
+		return model.NewPdfColorDeviceRGB(shade(t.R(), 1, f), shade(t.G(), 1, f), shade(t.B(), 1, f))
+	case *model.PdfColorDeviceCMYK:
+		return model.NewPdfColorDeviceCMYK(shade(t.C(), 0, f), shade(t.M(), 0, f), shade(t.Y(), 0, f), shade(t.K(), 0, f))
+	}
+	return color
+}
+
+// shade moves the color component `val` towards `target` by the fraction
+// `f`, rounded to avoid lengthy operands in the content streams.
+func shade(val, target, f float64) float64 {
+	return math.Round((val+(target-val)*f)*1e4) / 1e4
+}
+
+// colorToArray returns the color array of the color `color`, as used by the
+// appearance characteristics of widgets. Returns an empty array, meaning
+// transparent, for unsupported color spaces.
+func colorToArray(color model.PdfColor) *core.PdfObjectArray {
+	switch t := color.(type) {
+	case *model.PdfColorDeviceGray:
+		return core.MakeArrayFromFloats([]float64{t.Val()})
+	case *model.PdfColorDeviceRGB:
+		return core.MakeArrayFromFloats([]float64{t.R(), t.G(), t.B()})
+	case *model.PdfColorDeviceCMYK:
+		return core.MakeArrayFromFloats([]float64{t.C(), t.M(), t.Y(), t.K()})
+	}
+	common.Log.Debug("ERROR: unsupported appearance color: %T", color)
+	return core.MakeArray()
+}
@@ -0,0 +1,125 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// appearanceContent returns the decoded content stream of the appearance
+// `key` of the appearance dictionary `apDict`.
+func appearanceContent(t *testing.T, apDict *core.PdfObjectDictionary, key core.PdfObjectName) string {
+	stream, ok := core.GetStream(apDict.Get(key))
+	require.True(t, ok, "missing appearance %s", key)
+	data, err := core.DecodeStream(stream)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestGenerateAppearance(t *testing.T) {
+	def := AppearanceDef{
+		Width: 100, Height: 20,
+		BorderStyle: model.BorderStyleDashed, BorderWidth: 1,
+		BorderColor:     model.NewPdfColorDeviceRGB(1, 0, 0),
+		BackgroundColor: model.NewPdfColorDeviceGray(0.9),
+		Text:            "Hello",
+		DA:              "/Helv 10 Tf 0 0 1 rg",
+		Alignment:       1,
+	}
+	apDict, err := GenerateAppearance(def)
+	require.NoError(t, err)
+	require.Equal(t, []core.PdfObjectName{"N"}, apDict.Keys())
+
+	content := appearanceContent(t, apDict, "N")
+	require.Contains(t, content, "0.9 g")
+	require.Contains(t, content, "[3] 0 d")
+	require.Contains(t, content, "1 0 0 RG")
+	require.Contains(t, content, "0 0 1 rg")
+	require.Contains(t, content, "/Helv 10 Tf")
+	require.Contains(t, content, "(Hello) Tj")
+
+	// Rotated contents keep the bounding box of the annotation.
+	def.Rotation = 90
+	apDict, err = GenerateAppearance(def)
+	require.NoError(t, err)
+	require.Contains(t, appearanceContent(t, apDict, "N"), "0 1 -1 0 100 0 cm")
+	stream, _ := core.GetStream(apDict.Get("N"))
+	bboxArr, ok := core.GetArray(stream.Get("BBox"))
+	require.True(t, ok)
+	bbox, err := bboxArr.ToFloat64Array()
+	require.NoError(t, err)
+	require.Equal(t, []float64{0, 0, 100, 20}, bbox)
+
+	def.Rotation = 45
+	_, err = GenerateAppearance(def)
+	require.Error(t, err)
+}
+
+func TestGenerateAppearanceDefaultStyle(t *testing.T) {
+	apDict, err := GenerateAppearance(AppearanceDef{
+		Width: 100, Height: 40,
+		Text:      "Styled",
+		DA:        "/Helv 10 Tf 0 g",
+		DS:        "font: italic bold 14pt 'Times New Roman', serif; color: #F00; text-align: right",
+		Multiline: true,
+	})
+	require.NoError(t, err)
+	content := appearanceContent(t, apDict, "N")
+	require.Contains(t, content, "/TiBI 14 Tf")
+	require.Contains(t, content, "1 0 0 rg")
+
+	stream, _ := core.GetStream(apDict.Get("N"))
+	resources, ok := core.GetDict(stream.Get("Resources"))
+	require.True(t, ok)
+	fonts, ok := core.GetDict(resources.Get("Font"))
+	require.True(t, ok)
+	require.NotNil(t, fonts.Get("TiBI"))
+}
+
+func TestNewPushButtonField(t *testing.T) {
+	page := model.NewPdfPage()
+	field, err := NewPushButtonField(page, "submit", []float64{100, 100, 200, 130}, PushButtonFieldOptions{
+		Caption:         "Submit",
+		BorderStyle:     model.BorderStyleBeveled,
+		BorderWidth:     1,
+		BorderColor:     model.NewPdfColorDeviceGray(0),
+		BackgroundColor: model.NewPdfColorDeviceRGB(0.8, 0.8, 1),
+	})
+	require.NoError(t, err)
+	require.True(t, field.IsPush())
+	require.Len(t, field.Annotations, 1)
+
+	apDict, ok := core.GetDict(field.Annotations[0].AP)
+	require.True(t, ok)
+	require.Equal(t, []core.PdfObjectName{"N", "R", "D"}, apDict.Keys())
+	normal := appearanceContent(t, apDict, "N")
+	require.Contains(t, normal, "0.8 0.8 1 rg")
+	require.Contains(t, normal, "(Submit) Tj")
+	require.Contains(t, appearanceContent(t, apDict, "D"), "0.6 0.6 0.75 rg")
+	require.Contains(t, appearanceContent(t, apDict, "R"), "0.85 0.85 1 rg")
+
+	// The push button appearance is regenerated by the form field appearance generator.
+	form := model.NewPdfAcroForm()
+	form.Fields = &[]*model.PdfField{field.PdfField}
+	apDict, err = FieldAppearance{}.GenerateAppearanceDict(form, field.PdfField, field.Annotations[0])
+	require.NoError(t, err)
+	require.Len(t, apDict.Keys(), 3)
+	require.Contains(t, appearanceContent(t, apDict, "N"), "(Submit) Tj")
+
+	// The document with the button can be written.
+	page.AddAnnotation(field.Annotations[0].PdfAnnotation)
+	w := model.NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	require.NoError(t, w.SetForms(form))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+}
@@ -0,0 +1,131 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"strconv"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// CheckboxStyle represents the style of the mark of checked checkboxes and
+// selected radio buttons, drawn with a glyph of the ZapfDingbats font.
+type CheckboxStyle int
+
+// Checkbox and radio button styles.
+const (
+	// CheckboxStyleDefault is a check for checkboxes and a circle for radio
+	// buttons.
+	CheckboxStyleDefault CheckboxStyle = iota
+
+	// CheckboxStyleCheck represents a check (✔).
+	CheckboxStyleCheck
+
+	// CheckboxStyleCross represents a cross (✘).
+	CheckboxStyleCross
+
+	// CheckboxStyleDiamond represents a diamond (◆).
+	CheckboxStyleDiamond
+
+	// CheckboxStyleCircle represents a circle (●).
+	CheckboxStyleCircle
+
+	// CheckboxStyleSquare represents a square (■).
+	CheckboxStyleSquare
+
+	// CheckboxStyleStar represents a star (★).
+	CheckboxStyleStar
+)
+
+// Rune returns the glyph of the style. The check is returned for
+// CheckboxStyleDefault.
+func (s CheckboxStyle) Rune() rune {
+	switch s {
+	case CheckboxStyleCross:
+		return '✘'
+	case CheckboxStyleDiamond:
+		return '◆'
+	case CheckboxStyleCircle:
+		return '●'
+	case CheckboxStyleSquare:
+		return '■'
+	case CheckboxStyleStar:
+		return '★'
+	}
+	return '✔'
+}
+
+// caption returns the normal caption (CA) of the appearance characteristics
+// of widgets with the style, i.e. the ZapfDingbats character code of its
+// glyph, as written by conforming writers.
+func (s CheckboxStyle) caption() string {
+	switch s {
+	case CheckboxStyleCross:
+		return "8"
+	case CheckboxStyleDiamond:
+		return "u"
+	case CheckboxStyleCircle:
+		return "l"
+	case CheckboxStyleSquare:
+		return "n"
+	case CheckboxStyleStar:
+		return "H"
+	}
+	return "4"
+}
+
+// buttonOnState returns the name of the "on" appearance state of the widget
+// annotation `wa` of the checkbox or radio button field `fbtn`. The state of
+// the existing appearance dictionary is used if present. Otherwise, "Yes" is
+// used for checkboxes and the position of the widget in the field for radio
+// buttons, so that the buttons of the group have distinct states.
+func buttonOnState(wa *model.PdfAnnotationWidget, fbtn *model.PdfFieldButton) string {
+	if apDict, ok := core.GetDict(wa.AP); ok {
+		if nDict, ok := core.GetDict(apDict.Get("N")); ok {
+			for _, key := range nDict.Keys() {
+				if key != "Off" {
+					return key.String()
+				}
+			}
+		}
+	}
+	if fbtn.IsRadio() {
+		for i, annot := range fbtn.Annotations {
+			if annot == wa {
+				return strconv.Itoa(i)
+			}
+		}
+	}
+	return "Yes"
+}
+
+// buttonValue returns the value (V) of the button field `field`, inherited
+// from its ancestors if not set.
+func buttonValue(field *model.PdfField) (string, bool) {
+	for f := field; f != nil; f = f.Parent {
+		if f.V != nil {
+			return core.GetNameVal(f.V)
+		}
+	}
+	return "", false
+}
+
+// setButtonState sets the appearance state (AS) of the widget annotation
+// `wa` of the button field `fbtn` with "on" state `onState`: the "on" state
+// if the value of the field is `onState`, Off otherwise. The current state
+// is kept if the field has no value and the state is valid.
+func setButtonState(wa *model.PdfAnnotationWidget, fbtn *model.PdfFieldButton, onState string) {
+	state := "Off"
+	if value, ok := buttonValue(fbtn.PdfField); ok {
+		if value == onState {
+			state = onState
+		}
+	} else if as, ok := core.GetNameVal(wa.AS); ok && as == onState {
+		state = onState
+	}
+	wa.AS = core.MakeName(state)
+}
@@ -0,0 +1,96 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// buttonAppearances returns the normal appearance states of the widget `wa`.
+func buttonAppearances(t *testing.T, wa *model.PdfAnnotationWidget) *core.PdfObjectDictionary {
+	apDict, ok := core.GetDict(wa.AP)
+	require.True(t, ok)
+	nDict, ok := core.GetDict(apDict.Get("N"))
+	require.True(t, ok)
+	return nDict
+}
+
+func TestNewCheckboxFieldStyle(t *testing.T) {
+	page := model.NewPdfPage()
+	field, err := NewCheckboxField(page, "agree", []float64{100, 100, 120, 120}, CheckboxFieldOptions{
+		Checked: true,
+		Style:   CheckboxStyleCross,
+	})
+	require.NoError(t, err)
+	wa := field.Annotations[0]
+
+	nDict := buttonAppearances(t, wa)
+	require.Equal(t, []core.PdfObjectName{"Off", "Yes"}, nDict.Keys())
+	require.Contains(t, appearanceContent(t, nDict, "Yes"), "(8) Tj")
+	require.NotContains(t, appearanceContent(t, nDict, "Off"), "Tj")
+	as, _ := core.GetNameVal(wa.AS)
+	require.Equal(t, "Yes", as)
+
+	// Unchecking switches the appearance state.
+	field.V = core.MakeName("Off")
+	_, err = FieldAppearance{}.GenerateAppearanceDict(model.NewPdfAcroForm(), field.PdfField, wa)
+	require.NoError(t, err)
+	as, _ = core.GetNameVal(wa.AS)
+	require.Equal(t, "Off", as)
+}
+
+func TestNewRadioButtonField(t *testing.T) {
+	page := model.NewPdfPage()
+	rects := [][]float64{{100, 100, 115, 115}, {100, 120, 115, 135}, {100, 140, 115, 155}}
+	field, err := NewRadioButtonField(page, "size", rects, RadioButtonFieldOptions{
+		States:   []string{"S", "M", "L"},
+		Selected: "M",
+	})
+	require.NoError(t, err)
+	require.True(t, field.IsRadio())
+	require.Len(t, field.Annotations, 3)
+
+	check := func(selected string) {
+		for i, state := range []string{"S", "M", "L"} {
+			wa := field.Annotations[i]
+			nDict := buttonAppearances(t, wa)
+			require.Equal(t, []core.PdfObjectName{"Off", core.PdfObjectName(state)}, nDict.Keys())
+			require.Contains(t, appearanceContent(t, nDict, core.PdfObjectName(state)), "(l) Tj")
+
+			expected := "Off"
+			if state == selected {
+				expected = state
+			}
+			as, _ := core.GetNameVal(wa.AS)
+			require.Equal(t, expected, as)
+		}
+	}
+	check("M")
+
+	// The appearance states follow the value of the field.
+	field.V = core.MakeName("L")
+	form := model.NewPdfAcroForm()
+	for _, wa := range field.Annotations {
+		_, err := FieldAppearance{}.GenerateAppearanceDict(form, field.PdfField, wa)
+		require.NoError(t, err)
+	}
+	check("L")
+
+	// The positions of the buttons are used as states by default.
+	field, err = NewRadioButtonField(page, "default", rects[:2], RadioButtonFieldOptions{Selected: "1"})
+	require.NoError(t, err)
+	require.NotNil(t, buttonAppearances(t, field.Annotations[1]).Get("1"))
+	as, _ := core.GetNameVal(field.Annotations[1].AS)
+	require.Equal(t, "1", as)
+
+	_, err = NewRadioButtonField(page, "invalid", rects, RadioButtonFieldOptions{States: []string{"A"}})
+	require.Error(t, err)
+}
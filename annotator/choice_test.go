@@ -0,0 +1,85 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestComboboxAppearance(t *testing.T) {
+	page := model.NewPdfPage()
+	field, err := NewComboboxField(page, "country", []float64{100, 100, 250, 120}, ComboboxFieldOptions{
+		Choices: []string{"Italy"},
+		Options: []model.FormFieldOption{{Export: "FR", Display: "France"}},
+		Value:   "FR",
+	})
+	require.NoError(t, err)
+	require.True(t, field.IsCombo())
+
+	form := model.NewPdfAcroForm()
+	apDict, err := FieldAppearance{}.GenerateAppearanceDict(form, field.PdfField, field.Annotations[0])
+	require.NoError(t, err)
+	require.Contains(t, appearanceContent(t, apDict, "N"), "(France) Tj")
+
+	// Editable combo boxes display the entered text.
+	field, err = NewComboboxField(page, "city", []float64{100, 100, 250, 120}, ComboboxFieldOptions{
+		Choices:  []string{"Paris"},
+		Value:    "Lyon",
+		Editable: true,
+	})
+	require.NoError(t, err)
+	require.True(t, field.IsEditable())
+	apDict, err = FieldAppearance{}.GenerateAppearanceDict(form, field.PdfField, field.Annotations[0])
+	require.NoError(t, err)
+	require.Contains(t, appearanceContent(t, apDict, "N"), "(Lyon) Tj")
+
+	_, err = NewComboboxField(page, "invalid", []float64{100, 100, 250, 120}, ComboboxFieldOptions{
+		Choices: []string{"Paris"},
+		Value:   "Lyon",
+	})
+	require.Error(t, err)
+}
+
+func TestListboxAppearance(t *testing.T) {
+	page := model.NewPdfPage()
+	field, err := NewListboxField(page, "colors", []float64{100, 100, 200, 150}, ListboxFieldOptions{
+		Options: []model.FormFieldOption{
+			{Export: "R", Display: "Red"},
+			{Export: "G", Display: "Green"},
+			{Export: "B", Display: "Blue"},
+			{Export: "Y", Display: "Yellow"},
+			{Export: "K", Display: "Black"},
+		},
+		Selected:    []string{"G", "Y"},
+		MultiSelect: true,
+	})
+	require.NoError(t, err)
+	require.False(t, field.IsCombo())
+	require.Equal(t, []int{1, 3}, field.SelectedIndices())
+
+	form := model.NewPdfAcroForm()
+	apDict, err := FieldAppearance{}.GenerateAppearanceDict(form, field.PdfField, field.Annotations[0])
+	require.NoError(t, err)
+	require.Equal(t, []core.PdfObjectName{"N"}, apDict.Keys())
+	content := appearanceContent(t, apDict, "N")
+	require.Contains(t, content, "(Red) Tj")
+	require.Contains(t, content, "(Yellow) Tj")
+	require.NotContains(t, content, "(Black) Tj") // Not visible.
+	require.Contains(t, content, "0.6 0.75 0.86 rg")
+
+	// Options are listed from the top index.
+	field.TI = core.MakeInteger(3)
+	apDict, err = FieldAppearance{}.GenerateAppearanceDict(form, field.PdfField, field.Annotations[0])
+	require.NoError(t, err)
+	content = appearanceContent(t, apDict, "N")
+	require.NotContains(t, content, "(Red) Tj")
+	require.Contains(t, content, "(Black) Tj")
+}
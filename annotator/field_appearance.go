@@ -29,7 +29,13 @@ import (
 type FieldAppearance struct {
 	OnlyIfMissing        bool
 	RegenerateTextFields bool
-	style                *AppearanceStyle
+
+	// FormatValues displays the values of text fields formatted according
+	// to the format actions of the fields, as viewers do (see
+	// FormatFieldValue). The values of the fields are kept unformatted.
+	FormatValues bool
+
+	style *AppearanceStyle
 }
 
 // AppearanceStyle defines style parameters for appearance stream generation.
@@ -44,6 +50,12 @@ type AppearanceStyle struct {
 	BorderColor model.PdfColor
 	FillColor   model.PdfColor
 
+	// BorderStyle is the style of the border and BorderDash is the dash
+	// pattern of dashed borders. Set from the border style (BS) of widgets
+	// if AllowMK is true.
+	BorderStyle model.BorderStyle
+	BorderDash  []float64
+
 	// Multiplier for lineheight for multi line text.
 	MultilineLineHeight   float64
 	MultilineVAlignMiddle bool // Defaults to top.
@@ -159,7 +171,7 @@ func (fa FieldAppearance) GenerateAppearanceDict(form *model.PdfAcroForm, field
 		case ftxt.Flags().Has(model.FieldFlagComb):
 			// Special handling for comb. Only if max len is set.
 			if ftxt.MaxLen != nil {
-				appDict, err := genFieldTextCombAppearance(wa, ftxt, form.DR, fa.Style())
+				appDict, err := genFieldTextCombAppearance(form, wa, ftxt, fa.Style(), fa.FormatValues)
 				if err != nil {
 					return nil, err
 				}
@@ -167,7 +179,7 @@ func (fa FieldAppearance) GenerateAppearanceDict(form *model.PdfAcroForm, field
 			}
 		}
 
-		appDict, err := genFieldTextAppearance(wa, ftxt, form.DR, fa.Style())
+		appDict, err := genFieldTextAppearance(form, wa, ftxt, fa.Style(), fa.FormatValues)
 		if err != nil {
 			return nil, err
 		}
@@ -175,7 +187,7 @@ func (fa FieldAppearance) GenerateAppearanceDict(form *model.PdfAcroForm, field
 		return appDict, nil
 	case *model.PdfFieldButton:
 		fbtn := t
-		if fbtn.IsCheckbox() {
+		if fbtn.IsCheckbox() || fbtn.IsRadio() {
 			appDict, err := genFieldCheckboxAppearance(wa, fbtn, form.DR, fa.Style())
 			if err != nil {
 				return nil, err
@@ -183,6 +195,14 @@ func (fa FieldAppearance) GenerateAppearanceDict(form *model.PdfAcroForm, field
 			return appDict, nil
 		}
 
+		if fbtn.IsPush() {
+			appDict, err := genFieldPushButtonAppearance(form, wa, fbtn, fa.Style())
+			if err != nil {
+				return nil, err
+			}
+			return appDict, nil
+		}
+
 		common.Log.Debug("TODO: UNHANDLED button type: %+v", fbtn.GetType())
 	case *model.PdfFieldChoice:
 		fch := t
@@ -194,7 +214,11 @@ func (fa FieldAppearance) GenerateAppearanceDict(form *model.PdfAcroForm, field
 			}
 			return appDict, nil
 		default:
-			common.Log.Debug("TODO: UNHANDLED choice field with flags: %s", fch.Flags().String())
+			appDict, err := genFieldListboxAppearance(form, wa, fch, fa.Style())
+			if err != nil {
+				return nil, err
+			}
+			return appDict, nil
 		}
 
 	default:
@@ -205,8 +229,9 @@ func (fa FieldAppearance) GenerateAppearanceDict(form *model.PdfAcroForm, field
 }
 
 // genTextAppearance generates the appearance stream for widget annotation `wa` with text field `ftxt`.
-// It requires access to the form resources DR entry via `dr`.
-func genFieldTextAppearance(wa *model.PdfAnnotationWidget, ftxt *model.PdfFieldText, dr *model.PdfPageResources, style AppearanceStyle) (*core.PdfObjectDictionary, error) {
+// It requires access to the form resources DR entry and the form default appearance via `form`.
+func genFieldTextAppearance(form *model.PdfAcroForm, wa *model.PdfAnnotationWidget, ftxt *model.PdfFieldText, style AppearanceStyle, formatValue bool) (*core.PdfObjectDictionary, error) {
+	dr := form.DR
 	resources := model.NewPdfPageResources()
 
 	// Get bounding Rect.
@@ -231,7 +256,7 @@ func genFieldTextAppearance(wa *model.PdfAnnotationWidget, ftxt *model.PdfFieldT
 	}
 
 	// Get and process the default appearance string (DA) operands.
-	daOps, err := contentstream.NewContentStreamParser(getDA(ftxt.PdfField)).Parse()
+	daOps, err := contentstream.NewContentStreamParser(getFormDA(form, ftxt.PdfField)).Parse()
 	if err != nil {
 		return nil, err
 	}
@@ -285,10 +310,7 @@ func genFieldTextAppearance(wa *model.PdfAnnotationWidget, ftxt *model.PdfFieldT
 		common.Log.Debug("Error: Unable to get font descriptor")
 	}
 
-	var text string
-	if str, ok := core.GetString(ftxt.V); ok {
-		text = str.Decoded()
-	}
+	text := fieldText(ftxt, formatValue)
 
 	// If no text, no appearance needed.
 	if len(text) == 0 {
@@ -364,7 +386,7 @@ func genFieldTextAppearance(wa *model.PdfAnnotationWidget, ftxt *model.PdfFieldT
 	alignment := quaddingLeft
 	// Account for horizontal alignment (quadding).
 	{
-		if val, has := core.GetIntVal(ftxt.Q); has {
+		if val, has := getFormQ(form, ftxt.PdfField); has {
 			switch val {
 			case 0: // Left aligned.
 				alignment = quaddingLeft
@@ -484,7 +506,8 @@ func genFieldTextAppearance(wa *model.PdfAnnotationWidget, ftxt *model.PdfFieldT
 
 // genFieldTextCombAppearance generates an appearance dictionary for a comb text field where the width is split
 // into equal size boxes.
-func genFieldTextCombAppearance(wa *model.PdfAnnotationWidget, ftxt *model.PdfFieldText, dr *model.PdfPageResources, style AppearanceStyle) (*core.PdfObjectDictionary, error) {
+func genFieldTextCombAppearance(form *model.PdfAcroForm, wa *model.PdfAnnotationWidget, ftxt *model.PdfFieldText, style AppearanceStyle, formatValue bool) (*core.PdfObjectDictionary, error) {
+	dr := form.DR
 	resources := model.NewPdfPageResources()
 
 	// Get bounding Rect.
@@ -519,7 +542,7 @@ func genFieldTextCombAppearance(wa *model.PdfAnnotationWidget, ftxt *model.PdfFi
 	boxwidth := float64(width) / float64(maxLen)
 
 	// Get and process the default appearance string (DA) operands.
-	daOps, err := contentstream.NewContentStreamParser(getDA(ftxt.PdfField)).Parse()
+	daOps, err := contentstream.NewContentStreamParser(getFormDA(form, ftxt.PdfField)).Parse()
 	if err != nil {
 		return nil, err
 	}
@@ -565,10 +588,7 @@ func genFieldTextCombAppearance(wa *model.PdfAnnotationWidget, ftxt *model.PdfFi
 		encoder = textencoding.NewIdentityTextEncoder("Identity-H")
 	}
 
-	var text string
-	if str, ok := core.GetString(ftxt.V); ok {
-		text = str.Decoded()
-	}
+	text := fieldText(ftxt, formatValue)
 
 	cc.Add_Tf(*fontname, fontsize)
 
@@ -629,7 +649,7 @@ func genFieldTextCombAppearance(wa *model.PdfAnnotationWidget, ftxt *model.PdfFi
 	}
 	cc.Add_Td(0, ty)
 
-	if quadding, has := core.GetIntVal(ftxt.Q); has {
+	if quadding, has := getFormQ(form, ftxt.PdfField); has {
 		switch quadding {
 		case 2: // Right justified.
 			if len(text) < maxLen {
@@ -681,7 +701,8 @@ func genFieldTextCombAppearance(wa *model.PdfAnnotationWidget, ftxt *model.PdfFi
 }
 
 // genFieldCheckboxAppearance generates an appearance dictionary for a widget annotation `wa` referenced by
-// a button field `fbtn` with form resources `dr` (DR).
+// a checkbox or radio button field `fbtn` with form resources `dr` (DR), with the "on" and Off
+// appearance states. The appearance state (AS) of the widget is set according to the field value.
 func genFieldCheckboxAppearance(wa *model.PdfAnnotationWidget, fbtn *model.PdfFieldButton, dr *model.PdfPageResources, style AppearanceStyle) (*core.PdfObjectDictionary, error) {
 	// Get bounding Rect.
 	array, ok := core.GetArray(wa.Rect)
@@ -703,6 +724,11 @@ func genFieldCheckboxAppearance(wa *model.PdfAnnotationWidget, fbtn *model.PdfFi
 	}
 
 	mkDict, has := core.GetDict(wa.MK)
+	if fbtn.IsRadio() && (!has || mkDict.Get("CA") == nil) {
+		// Radio buttons are circles by default.
+		style.CheckmarkRune = CheckboxStyleCircle.Rune()
+	}
+	onState := buttonOnState(wa, fbtn)
 	if has {
 		bsDict, _ := core.GetDict(wa.BS)
 		err := style.applyAppearanceCharacteristics(mkDict, bsDict, zapfdb)
@@ -781,14 +807,37 @@ func genFieldCheckboxAppearance(wa *model.PdfAnnotationWidget, fbtn *model.PdfFi
 
 	dchoiceapp := core.MakeDict()
 	dchoiceapp.Set("Off", xformOff.ToPdfObject())
-	dchoiceapp.Set("Yes", xformOn.ToPdfObject())
+	dchoiceapp.Set(*core.MakeName(onState), xformOn.ToPdfObject())
 
 	appDict := core.MakeDict()
 	appDict.Set("N", dchoiceapp)
+	setButtonState(wa, fbtn, onState)
 
 	return appDict, nil
 }
 
+// genFieldPushButtonAppearance generates an appearance dictionary for a widget annotation `wa` referenced by
+// a push button field `fbtn`, with the normal, rollover and down appearances. The caption and the
+// colors are taken from the appearance characteristics (MK) of the widget if `style` allows it.
+func genFieldPushButtonAppearance(form *model.PdfAcroForm, wa *model.PdfAnnotationWidget, fbtn *model.PdfFieldButton, style AppearanceStyle) (*core.PdfObjectDictionary, error) {
+	def, err := NewAppearanceDefFromWidget(wa)
+	if err != nil {
+		return nil, err
+	}
+	def.DA = getFormDA(form, fbtn.PdfField)
+	def.Resources = form.DR
+
+	if !style.AllowMK {
+		def.Rotation = 0
+		def.BorderWidth = style.BorderSize
+		def.BorderColor = style.BorderColor
+		def.BorderStyle = style.BorderStyle
+		def.DashArray = style.BorderDash
+		def.BackgroundColor = style.FillColor
+	}
+	return GenerateWidgetAppearance(def)
+}
+
 // genFieldComboboxAppearance generates an appearance dictionary for a widget annotation `wa` referenced by a
 // combobox choice field `fch` with form resources (DR) `dr`.
 func genFieldComboboxAppearance(form *model.PdfAcroForm, wa *model.PdfAnnotationWidget, fch *model.PdfFieldChoice, style AppearanceStyle) (*core.PdfObjectDictionary, error) {
@@ -821,34 +870,171 @@ func genFieldComboboxAppearance(form *model.PdfAcroForm, wa *model.PdfAnnotation
 	}
 
 	// See section 12.7.4.4 "Choice Fields" (pp. 444-446 PDF32000_2008).
-	dchoiceapp := core.MakeDict()
-	for _, optObj := range fch.Opt.Elements() {
-		if optArr, ok := core.GetArray(optObj); ok && optArr.Len() == 2 {
-			optObj = optArr.Get(1)
+	// The combo box displays the selected value, or the text entered by the
+	// user for editable combo boxes.
+	var text string
+	if values := fch.SelectedValues(); len(values) > 0 {
+		text = values[0]
+		for _, opt := range fch.Options() {
+			if opt.Export == text {
+				text = opt.Display
+				break
+			}
 		}
+	}
 
-		var optstr string
-		if opt, ok := core.GetString(optObj); ok {
-			optstr = opt.Decoded()
-		} else if opt, ok := core.GetName(optObj); ok {
-			optstr = opt.String()
-		} else {
-			common.Log.Debug("ERROR: Opt not a name/string - %T", optObj)
-			return nil, errors.New("not a name/string")
+	xform, err := makeComboboxTextXObjForm(fch.PdfField, width, height, text, style, daOps, form.DR, mkDict)
+	if err != nil {
+		return nil, err
+	}
+	if xform == nil {
+		// No value selected.
+		cc := contentstream.NewContentCreator()
+		if style.BorderSize > 0 {
+			drawRect(cc, style, width, height)
 		}
+		xform = model.NewXObjectForm()
+		xform.BBox = core.MakeArrayFromFloats([]float64{0, 0, width, height})
+		xform.SetContentStream(cc.Bytes(), defStreamEncoder())
+	}
 
-		if len(optstr) > 0 {
-			xform, err := makeComboboxTextXObjForm(fch.PdfField, width, height, optstr, style, daOps, form.DR, mkDict)
-			if err != nil {
-				return nil, err
-			}
+	appDict := core.MakeDict()
+	appDict.Set("N", xform.ToPdfObject())
+
+	return appDict, nil
+}
+
+// listboxSelectionColor is the background color of the selected options of list boxes.
+var listboxSelectionColor = model.NewPdfColorDeviceRGB(0.6, 0.75, 0.86)
 
-			dchoiceapp.Set(*core.MakeName(optstr), xform.ToPdfObject())
+// genFieldListboxAppearance generates an appearance dictionary for a widget annotation `wa` referenced by a
+// list box choice field `fch`. The options are listed from the top index (TI) of the field, with the
+// selected options highlighted.
+func genFieldListboxAppearance(form *model.PdfAcroForm, wa *model.PdfAnnotationWidget, fch *model.PdfFieldChoice, style AppearanceStyle) (*core.PdfObjectDictionary, error) {
+	// Get bounding Rect.
+	array, ok := core.GetArray(wa.Rect)
+	if !ok {
+		return nil, errors.New("invalid Rect")
+	}
+	rect, err := model.NewPdfRectangle(*array)
+	if err != nil {
+		return nil, err
+	}
+	width, height := rect.Width(), rect.Height()
+	bboxWidth, bboxHeight := width, height
+
+	// Get and process the default appearance string (DA) operands.
+	daOps, err := contentstream.NewContentStreamParser(getFormDA(form, fch.PdfField)).Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	mkDict, has := core.GetDict(wa.MK)
+	if has {
+		bsDict, _ := core.GetDict(wa.BS)
+		err := style.applyAppearanceCharacteristics(mkDict, bsDict, nil)
+		if err != nil {
+			return nil, err
 		}
 	}
 
+	resources := model.NewPdfPageResources()
+	cc := contentstream.NewContentCreator()
+	if style.BorderSize > 0 {
+		drawRect(cc, style, width, height)
+	}
+
+	// Apply rotation if present.
+	// Update width and height, as the appearance is generated based on
+	// the bounding of the annotation with no rotation.
+	width, height = style.applyRotation(mkDict, width, height, cc)
+
+	// Process DA operands. They are added to the text object, after the
+	// selection highlights.
+	daCC := contentstream.NewContentCreator()
+	apFont, _, err := style.processDA(fch.PdfField, daOps, form.DR, resources, daCC)
+	if err != nil {
+		return nil, err
+	}
+	font := apFont.Font
+	fontsize := apFont.Size
+	if fontsize == 0 {
+		// Auto sized list boxes use the default font size.
+		fontsize = 12
+	}
+	encoder := font.Encoder()
+	if encoder == nil {
+		common.Log.Debug("WARN: font encoder is nil. Assuming identity encoder. Output may be incorrect.")
+		encoder = textencoding.NewIdentityTextEncoder("Identity-H")
+	}
+
+	selected := map[int]bool{}
+	for _, i := range fch.SelectedIndices() {
+		selected[i] = true
+	}
+	top := 0
+	if fch.TI != nil {
+		top = int(*fch.TI)
+	}
+
+	padding := style.BorderSize + 1
+	lineheight := style.MultilineLineHeight * fontsize
+	if lineheight <= 0 {
+		lineheight = fontsize
+	}
+
+	cc.Add_BMC("Tx")
+	cc.Add_q()
+	cc.Add_re(padding, padding, width-2*padding, height-2*padding).Add_W().Add_n()
+
+	// Lines of the visible options.
+	type line struct {
+		text     string
+		y        float64
+		selected bool
+	}
+	var lines []line
+	options := fch.Options()
+	y := height - padding
+	for i := top; i >= 0 && i < len(options) && y > padding; i++ {
+		y -= lineheight
+		lines = append(lines, line{text: options[i].Display, y: y, selected: selected[i]})
+	}
+
+	for _, l := range lines {
+		if l.selected {
+			cc.Add_q().
+				SetNonStrokingColor(listboxSelectionColor).
+				Add_re(padding, l.y, width-2*padding, lineheight).
+				Add_f().
+				Add_Q()
+		}
+	}
+
+	cc.Add_BT()
+	for _, op := range *daCC.Operations() {
+		cc.AddOperand(*op)
+	}
+	cc.Add_Tf(*core.MakeName(apFont.Name), fontsize)
+	for _, l := range lines {
+		// Center the text vertically in the line, with the baseline above the descent.
+		ty := l.y + (lineheight-fontsize)/2 + 0.25*fontsize
+		cc.Add_Tm(1, 0, 0, 1, padding+1, ty)
+		cc.Add_Tj(*core.MakeStringFromBytes(encoder.Encode(l.text)))
+	}
+	cc.Add_ET()
+	cc.Add_Q()
+	cc.Add_EMC()
+
+	xform := model.NewXObjectForm()
+	xform.Resources = resources
+	xform.BBox = core.MakeArrayFromFloats([]float64{0, 0, bboxWidth, bboxHeight})
+	if err := xform.SetContentStream(cc.Bytes(), defStreamEncoder()); err != nil {
+		return nil, err
+	}
+
 	appDict := core.MakeDict()
-	appDict.Set("N", dchoiceapp)
+	appDict.Set("N", xform.ToPdfObject())
 
 	return appDict, nil
 }
@@ -961,6 +1147,22 @@ func makeComboboxTextXObjForm(field *model.PdfField, width, height float64,
 	return xform, nil
 }
 
+// fieldText returns the text displayed in the text field `ftxt`, i.e. its
+// value, formatted according to the format action of the field if
+// `formatValue` is true.
+func fieldText(ftxt *model.PdfFieldText, formatValue bool) string {
+	var text string
+	if str, ok := core.GetString(ftxt.V); ok {
+		text = str.Decoded()
+	}
+	if formatValue {
+		if formatted, ok := FormatFieldValue(ftxt.PdfField, text); ok {
+			return formatted
+		}
+	}
+	return text
+}
+
 // getDA returns the default appearance text (DA) for a given field `ftxt`.
 // If not set for `ftxt` then checks if set by Parent (inherited), otherwise
 // returns "".
@@ -981,9 +1183,50 @@ func getDA(field *model.PdfField) string {
 	return getDA(ftxt.Parent)
 }
 
+// getFormDA returns the default appearance text (DA) for `field`. If the
+// field and its ancestors do not specify the DA, the default appearance of
+// the `form` is returned.
+func getFormDA(form *model.PdfAcroForm, field *model.PdfField) string {
+	if da := getDA(field); da != "" {
+		return da
+	}
+	if form != nil && form.DA != nil {
+		return form.DA.Str()
+	}
+	return ""
+}
+
+// getFormQ returns the quadding (justification) of the text in `field`.
+// The value is inherited from the field ancestors and, if not set, from the
+// `form` default quadding.
+func getFormQ(form *model.PdfAcroForm, field *model.PdfField) (int, bool) {
+	for f := field; f != nil; f = f.Parent {
+		if ftxt, ok := f.GetContext().(*model.PdfFieldText); ok && ftxt.Q != nil {
+			return int(*ftxt.Q), true
+		}
+	}
+	if form != nil && form.Q != nil {
+		return int(*form.Q), true
+	}
+	return 0, false
+}
+
 // drawRect draws the annotation Rectangle.
 // TODO(gunnsth): Apply clipping so annotation contents cannot go outside Rect.
 func drawRect(cc *contentstream.ContentCreator, style AppearanceStyle, width, height float64) {
+	if style.BorderStyle != model.BorderStyleSolid {
+		if style.FillColor != nil {
+			cc.Add_q().
+				SetNonStrokingColor(style.FillColor).
+				Add_re(0, 0, width, height).
+				Add_f().
+				Add_Q()
+		}
+		drawBorder(cc, style.BorderStyle, style.BorderSize, style.BorderColor, style.BorderDash,
+			style.FillColor, false, width, height)
+		return
+	}
+
 	cc.Add_q().
 		Add_re(0, 0, width, height).
 		Add_w(style.BorderSize).
@@ -1018,6 +1261,14 @@ func (style *AppearanceStyle) applyAppearanceCharacteristics(mkDict *core.PdfObj
 		if w, err := core.GetNumberAsFloat(bsDict.Get("W")); err == nil {
 			style.BorderSize = w
 		}
+		if name, ok := core.GetNameVal(bsDict.Get("S")); ok {
+			style.BorderStyle = borderStyleFromName(name)
+		}
+		if D, ok := core.GetArray(bsDict.Get("D")); ok {
+			if dash, err := D.ToFloat64Array(); err == nil {
+				style.BorderDash = dash
+			}
+		}
 	}
 
 	// Normal caption.
@@ -0,0 +1,513 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// afFormatRegexp matches the calls of the format functions of the Acrobat
+// JavaScript API used in the format actions of fields, e.g.
+// `AFNumber_Format(2, 0, 0, 0, "$", true);`.
+var afFormatRegexp = regexp.MustCompile(`(AF(?:Number|Percent|Date|Time|Special)_Format(?:Ex)?)\s*\(([^)]*)\)`)
+
+// afDateFormats are the date formats of AFDate_Format, by index.
+var afDateFormats = []string{
+	"m/d", "m/d/yy", "mm/dd/yy", "mm/yy", "d-mmm", "d-mmm-yy", "dd-mmm-yy",
+	"yy-mm-dd", "mmm-yy", "mmmm-yy", "mmm d, yyyy", "mmmm d, yyyy",
+	"m/d/yy h:MM tt", "m/d/yy HH:MM",
+}
+
+// afTimeFormats are the time formats of AFTime_Format, by index.
+var afTimeFormats = []string{"HH:MM", "h:MM tt", "HH:MM:ss", "h:MM:ss tt"}
+
+// afDateLayouts are the layouts of the date values parsed by the built-in
+// date formatter.
+var afDateLayouts = []string{
+	time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04",
+	"2006-01-02 15:04", "2006-01-02", "01/02/2006 15:04", "01/02/2006", "1/2/2006",
+	"15:04:05", "15:04",
+}
+
+// FormatFieldValue formats the value `value` of the field `field` for display,
+// as viewers do with the format action (AA F) of the field. The calls of the
+// AFNumber_Format, AFPercent_Format, AFDate_Format(Ex), AFTime_Format(Ex) and
+// AFSpecial_Format functions of the Acrobat JavaScript API are supported by a
+// built-in formatter; other scripts are not executed. The value of the field
+// is not modified, as it is formatted again by viewers. Returns false if the
+// field has no supported format action or if the value cannot be formatted,
+// e.g. if the value of a number field is not a number.
+func FormatFieldValue(field *model.PdfField, value string) (string, bool) {
+	if value == "" {
+		return "", false
+	}
+	js, ok := field.GetActionJavaScript("F")
+	if !ok {
+		return "", false
+	}
+	match := afFormatRegexp.FindStringSubmatch(js)
+	if match == nil {
+		common.Log.Debug("Unsupported field format script: %s", js)
+		return "", false
+	}
+	args := parseAFArgs(match[2])
+
+	switch match[1] {
+	case "AFNumber_Format":
+		if len(args) < 6 {
+			break
+		}
+		return afNumberFormat(value, afInt(args[0]), afInt(args[1]), afInt(args[2]),
+			args[4], args[5] == "true")
+	case "AFPercent_Format":
+		if len(args) < 2 {
+			break
+		}
+		return afPercentFormat(value, afInt(args[0]), afInt(args[1]))
+	case "AFDate_FormatEx", "AFTime_FormatEx":
+		if len(args) < 1 {
+			break
+		}
+		return afDateFormat(value, args[0])
+	case "AFDate_Format":
+		if len(args) < 1 {
+			break
+		}
+		if i := afInt(args[0]); i >= 0 && i < len(afDateFormats) {
+			return afDateFormat(value, afDateFormats[i])
+		}
+	case "AFTime_Format":
+		if len(args) < 1 {
+			break
+		}
+		if i := afInt(args[0]); i >= 0 && i < len(afTimeFormats) {
+			return afDateFormat(value, afTimeFormats[i])
+		}
+	case "AFSpecial_Format":
+		if len(args) < 1 {
+			break
+		}
+		return afSpecialFormat(value, afInt(args[0]))
+	}
+	common.Log.Debug("Invalid field format script: %s", js)
+	return "", false
+}
+
+// parseAFArgs returns the arguments of a call, separated by commas. The
+// spaces between the arguments and the quotes of string arguments are
+// removed, and the escape sequences of strings, e.g. "\u20AC", are decoded.
+func parseAFArgs(s string) []string {
+	var args []string
+	var arg strings.Builder
+	var quote rune
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0 && r == '\\' && i+1 < len(runes):
+			i++
+			if runes[i] == 'u' && i+4 < len(runes) {
+				if code, err := strconv.ParseUint(string(runes[i+1:i+5]), 16, 32); err == nil {
+					arg.WriteRune(rune(code))
+					i += 4
+					continue
+				}
+			}
+			arg.WriteRune(runes[i])
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			arg.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			args = append(args, arg.String())
+			arg.Reset()
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			// Spaces between the arguments.
+		default:
+			arg.WriteRune(r)
+		}
+	}
+	if arg.Len() > 0 || len(args) > 0 {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+// afInt returns the integer value of the argument `s`, or -1 if invalid.
+func afInt(s string) int {
+	i, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return -1
+	}
+	return i
+}
+
+// parseAFNumber parses the number `value`, with the decimal separator of
+// the separator style `sepStyle`.
+func parseAFNumber(value string, sepStyle int) (float64, bool) {
+	value = strings.Replace(strings.TrimSpace(value), " ", "", -1)
+	if sepStyle == 2 || sepStyle == 3 {
+		value = strings.Replace(value, ".", "", -1)
+		value = strings.Replace(value, ",", ".", -1)
+	} else {
+		value = strings.Replace(value, ",", "", -1)
+		value = strings.Replace(value, "'", "", -1)
+	}
+	num, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsInf(num, 0) || math.IsNaN(num) {
+		return 0, false
+	}
+	return num, true
+}
+
+// formatAFNumber formats the absolute value of `num` with `nDec` decimals
+// and the separator style `sepStyle`: 0 - 1,234.56, 1 - 1234.56,
+// 2 - 1.234,56, 3 - 1234,56, 4 - 1'234.56.
+func formatAFNumber(num float64, nDec, sepStyle int) string {
+	if nDec < 0 {
+		nDec = 0
+	}
+	// Half values are rounded away from zero, as by viewers.
+	scale := math.Pow(10, float64(nDec))
+	s := strconv.FormatFloat(math.Round(math.Abs(num)*scale)/scale, 'f', nDec, 64)
+	intPart, decPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, decPart = s[:i], s[i+1:]
+	}
+
+	group, decimal := ",", "."
+	switch sepStyle {
+	case 1:
+		group = ""
+	case 2:
+		group, decimal = ".", ","
+	case 3:
+		group, decimal = "", ","
+	case 4:
+		group = "'"
+	}
+	if group != "" {
+		var b strings.Builder
+		for i, r := range intPart {
+			if i > 0 && (len(intPart)-i)%3 == 0 {
+				b.WriteString(group)
+			}
+			b.WriteRune(r)
+		}
+		intPart = b.String()
+	}
+	if decPart == "" {
+		return intPart
+	}
+	return intPart + decimal + decPart
+}
+
+// afNumberFormat formats `value` as AFNumber_Format. The negative styles
+// displaying negative numbers in red are rendered without sign, as the color
+// of the text is specified by the default appearance of the field.
+func afNumberFormat(value string, nDec, sepStyle, negStyle int, currency string, prepend bool) (string, bool) {
+	num, ok := parseAFNumber(value, sepStyle)
+	if !ok {
+		return "", false
+	}
+	s := formatAFNumber(num, nDec, sepStyle)
+	if currency != "" {
+		if prepend {
+			s = currency + s
+		} else {
+			s = s + currency
+		}
+	}
+	// Numbers rounded to zero are not negative.
+	if num < 0 && strings.Trim(formatAFNumber(num, nDec, 1), "0.") != "" {
+		switch negStyle {
+		case 0:
+			s = "-" + s
+		case 2, 3:
+			s = "(" + s + ")"
+		}
+	}
+	return s, true
+}
+
+// afPercentFormat formats `value` as AFPercent_Format.
+func afPercentFormat(value string, nDec, sepStyle int) (string, bool) {
+	num, ok := parseAFNumber(value, sepStyle)
+	if !ok {
+		return "", false
+	}
+	s := formatAFNumber(num*100, nDec, sepStyle) + "%"
+	if num < 0 && strings.Trim(formatAFNumber(num*100, nDec, 1), "0.") != "" {
+		s = "-" + s
+	}
+	return s, true
+}
+
+// afSpecialFormat formats `value` as AFSpecial_Format: 0 - zip code,
+// 1 - zip code + 4, 2 - phone number, 3 - social security number.
+func afSpecialFormat(value string, psf int) (string, bool) {
+	var digits []rune
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, r)
+		}
+	}
+	d := string(digits)
+	switch {
+	case psf == 0 && len(d) == 5:
+		return d, true
+	case psf == 1 && len(d) == 9:
+		return d[:5] + "-" + d[5:], true
+	case psf == 2 && len(d) == 10:
+		return "(" + d[:3] + ") " + d[3:6] + "-" + d[6:], true
+	case psf == 2 && len(d) == 7:
+		return d[:3] + "-" + d[3:], true
+	case psf == 3 && len(d) == 9:
+		return d[:3] + "-" + d[3:5] + "-" + d[5:], true
+	}
+	return "", false
+}
+
+// afDateFormat formats the date `value` with the Acrobat date format
+// `format`, e.g. "mm/dd/yyyy". The date is parsed using the format itself,
+// or one of the common layouts (ISO 8601, mm/dd/yyyy), or the PDF date
+// format.
+func afDateFormat(value, format string) (string, bool) {
+	value = strings.TrimSpace(value)
+	t, ok := parseAFDate(value, format)
+	if !ok {
+		for _, layout := range afDateLayouts {
+			var err error
+			if t, err = time.Parse(layout, value); err == nil {
+				ok = true
+				break
+			}
+		}
+	}
+	if !ok {
+		pdfDate, err := model.NewPdfDate(value)
+		if err != nil {
+			return "", false
+		}
+		t = pdfDate.ToGoTime()
+	}
+	return formatAFDate(t, format), true
+}
+
+// afDateTokens are the tokens of Acrobat date formats, longest first.
+var afDateTokens = []string{
+	"yyyy", "mmmm", "dddd", "mmm", "ddd", "yy", "mm", "dd", "HH", "hh", "MM", "ss", "tt",
+	"m", "d", "H", "h", "M", "s", "t",
+}
+
+// splitAFDateFormat splits the date format `format` into tokens and literal
+// text.
+func splitAFDateFormat(format string) []string {
+	var parts []string
+	for len(format) > 0 {
+		token := ""
+		for _, t := range afDateTokens {
+			if strings.HasPrefix(format, t) {
+				token = t
+				break
+			}
+		}
+		if token == "" {
+			token = format[:1]
+			if len(parts) > 0 && !isAFDateToken(parts[len(parts)-1]) {
+				parts[len(parts)-1] += token
+				format = format[1:]
+				continue
+			}
+		}
+		parts = append(parts, token)
+		format = format[len(token):]
+	}
+	return parts
+}
+
+// isAFDateToken returns true if `s` is a token of Acrobat date formats.
+func isAFDateToken(s string) bool {
+	for _, t := range afDateTokens {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
+
+// formatAFDate formats the time `t` with the Acrobat date format `format`.
+func formatAFDate(t time.Time, format string) string {
+	hour12 := t.Hour() % 12
+	if hour12 == 0 {
+		hour12 = 12
+	}
+	ampm := "am"
+	if t.Hour() >= 12 {
+		ampm = "pm"
+	}
+
+	var b strings.Builder
+	for _, part := range splitAFDateFormat(format) {
+		switch part {
+		case "yyyy":
+			b.WriteString(strconv.Itoa(t.Year()))
+		case "yy":
+			b.WriteString(t.Format("06"))
+		case "mmmm":
+			b.WriteString(t.Month().String())
+		case "mmm":
+			b.WriteString(t.Month().String()[:3])
+		case "mm":
+			b.WriteString(t.Format("01"))
+		case "m":
+			b.WriteString(strconv.Itoa(int(t.Month())))
+		case "dddd":
+			b.WriteString(t.Weekday().String())
+		case "ddd":
+			b.WriteString(t.Weekday().String()[:3])
+		case "dd":
+			b.WriteString(t.Format("02"))
+		case "d":
+			b.WriteString(strconv.Itoa(t.Day()))
+		case "HH":
+			b.WriteString(t.Format("15"))
+		case "H":
+			b.WriteString(strconv.Itoa(t.Hour()))
+		case "hh":
+			b.WriteString(t.Format("03"))
+		case "h":
+			b.WriteString(strconv.Itoa(hour12))
+		case "MM":
+			b.WriteString(t.Format("04"))
+		case "M":
+			b.WriteString(strconv.Itoa(t.Minute()))
+		case "ss":
+			b.WriteString(t.Format("05"))
+		case "s":
+			b.WriteString(strconv.Itoa(t.Second()))
+		case "tt":
+			b.WriteString(ampm)
+		case "t":
+			b.WriteString(ampm[:1])
+		default:
+			b.WriteString(part)
+		}
+	}
+	return b.String()
+}
+
+// parseAFDate parses the date `value` formatted with the Acrobat date format
+// `format`. Only numeric formats and month names are supported.
+func parseAFDate(value, format string) (time.Time, bool) {
+	year, month, day := 1, 1, 1
+	var hour, minute, second int
+	pm := -1
+
+	readNumber := func(maxLen int) (int, bool) {
+		n := 0
+		for n < len(value) && n < maxLen && value[n] >= '0' && value[n] <= '9' {
+			n++
+		}
+		if n == 0 {
+			return 0, false
+		}
+		v, _ := strconv.Atoi(value[:n])
+		value = value[n:]
+		return v, true
+	}
+
+	for _, part := range splitAFDateFormat(format) {
+		var ok bool
+		switch part {
+		case "yyyy":
+			year, ok = readNumber(4)
+		case "yy":
+			year, ok = readNumber(2)
+			year += 2000
+			if year > 2049 {
+				year -= 100
+			}
+		case "mmmm", "mmm":
+			for m := time.January; m <= time.December; m++ {
+				name := m.String()
+				if part == "mmm" {
+					name = name[:3]
+				}
+				if strings.HasPrefix(strings.ToLower(value), strings.ToLower(name)) {
+					month, ok = int(m), true
+					value = value[len(name):]
+					break
+				}
+			}
+		case "mm", "m":
+			month, ok = readNumber(2)
+		case "dd", "d":
+			day, ok = readNumber(2)
+		case "HH", "H", "hh", "h":
+			hour, ok = readNumber(2)
+		case "MM", "M":
+			minute, ok = readNumber(2)
+		case "ss", "s":
+			second, ok = readNumber(2)
+		case "tt", "t":
+			lower := strings.ToLower(value)
+			switch {
+			case strings.HasPrefix(lower, "am") || strings.HasPrefix(lower, "pm"):
+				pm, ok = boolToInt(lower[0] == 'p'), true
+				value = value[2:]
+			case strings.HasPrefix(lower, "a") || strings.HasPrefix(lower, "p"):
+				pm, ok = boolToInt(lower[0] == 'p'), true
+				value = value[1:]
+			}
+		case "dddd", "ddd":
+			// Weekday names are not needed to parse the date.
+			for value != "" && (value[0] >= 'A' && value[0] <= 'Z' || value[0] >= 'a' && value[0] <= 'z') {
+				value = value[1:]
+			}
+			ok = true
+		default:
+			ok = strings.HasPrefix(value, part)
+			if ok {
+				value = value[len(part):]
+			}
+		}
+		if !ok {
+			return time.Time{}, false
+		}
+	}
+	if value != "" {
+		return time.Time{}, false
+	}
+
+	if pm == 1 && hour < 12 {
+		hour += 12
+	} else if pm == 0 && hour == 12 {
+		hour = 0
+	}
+	t := time.Date(year, time.Month(month), day, hour, minute, second, 0, time.UTC)
+	if t.Month() != time.Month(month) || t.Day() != day || hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// boolToInt returns 1 if `b` is true, 0 otherwise.
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// newFormattedTextField returns a text field with the format script `js`.
+func newFormattedTextField(t *testing.T, js string) *model.PdfFieldText {
+	page := model.NewPdfPage()
+	ftxt, err := NewTextField(page, "field", []float64{100, 100, 200, 120}, TextFieldOptions{})
+	require.NoError(t, err)
+
+	action := core.MakeDict()
+	action.Set("S", core.MakeName("JavaScript"))
+	action.Set("JS", core.MakeEncodedString(js, true))
+	aa := core.MakeDict()
+	aa.Set("F", action)
+	ftxt.AA = aa
+	return ftxt
+}
+
+func TestFormatFieldValue(t *testing.T) {
+	testcases := []struct {
+		js       string
+		value    string
+		expected string
+		ok       bool
+	}{
+		{`AFNumber_Format(2, 0, 0, 0, "$", true);`, "1234.5", "$1,234.50", true},
+		{`AFNumber_Format(2, 0, 0, 0, "$", true);`, "-1234.567", "-$1,234.57", true},
+		{`AFNumber_Format(0, 1, 2, 0, "", false);`, "-1234.5", "(1235)", true},
+		{`AFNumber_Format(2, 2, 0, 0, " €", false);`, "1234567,891", "1.234.567,89 €", true},
+		{`AFNumber_Format(0, 0, 0, 0, "\u20AC", true);`, "12", "€12", true},
+		{`AFNumber_Format(1, 4, 0, 0, "", false);`, "1234567", "1'234'567.0", true},
+		{`AFNumber_Format(2, 0, 0, 0, "", false);`, "-0.001", "0.00", true},
+		{`AFNumber_Format(2, 0, 0, 0, "", false);`, "abc", "", false},
+		{`AFPercent_Format(1, 0);`, "0.1234", "12.3%", true},
+		{`AFDate_FormatEx("mm/dd/yyyy");`, "2020-03-07", "03/07/2020", true},
+		{`AFDate_FormatEx("mmmm d, yyyy");`, "03/07/2020", "March 7, 2020", true},
+		{`AFDate_FormatEx("dd-mmm-yy");`, "7-Mar-20", "07-Mar-20", true},
+		{`AFDate_FormatEx("yyyy-mm-dd");`, "D:20200307120000Z", "2020-03-07", true},
+		{`AFDate_Format(2);`, "2020-03-07", "03/07/20", true},
+		{`AFTime_Format(1);`, "14:05", "2:05 pm", true},
+		{`AFTime_FormatEx("HH:MM:ss");`, "2:05:09 pm", "14:05:09", false},
+		{`AFSpecial_Format(2);`, "5551234567", "(555) 123-4567", true},
+		{`AFSpecial_Format(1);`, "12345-6789", "12345-6789", true},
+		{`AFSpecial_Format(3);`, "12345", "", false},
+		{`event.value = util.printx("99", event.value);`, "12", "", false},
+	}
+
+	for _, tc := range testcases {
+		ftxt := newFormattedTextField(t, tc.js)
+		formatted, ok := FormatFieldValue(ftxt.PdfField, tc.value)
+		require.Equal(t, tc.ok, ok, "%s %s", tc.js, tc.value)
+		if ok {
+			require.Equal(t, tc.expected, formatted, "%s %s", tc.js, tc.value)
+		}
+	}
+}
+
+func TestFieldAppearanceFormatValues(t *testing.T) {
+	ftxt := newFormattedTextField(t, `AFNumber_Format(2, 0, 0, 0, "$", true);`)
+	ftxt.V = core.MakeString("1234.5")
+	form := model.NewPdfAcroForm()
+
+	apDict, err := FieldAppearance{}.GenerateAppearanceDict(form, ftxt.PdfField, ftxt.Annotations[0])
+	require.NoError(t, err)
+	require.Contains(t, appearanceContent(t, apDict, "N"), "(1234.5) Tj")
+
+	apDict, err = FieldAppearance{FormatValues: true}.GenerateAppearanceDict(form, ftxt.PdfField, ftxt.Annotations[0])
+	require.NoError(t, err)
+	require.Contains(t, appearanceContent(t, apDict, "N"), "($1,234.50) Tj")
+	require.Equal(t, "1234.5", ftxt.V.(*core.PdfObjectString).Decoded())
+}
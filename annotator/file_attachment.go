@@ -0,0 +1,108 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	pdfcore "github.com/unidoc/unipdf/v3/core"
+	pdf "github.com/unidoc/unipdf/v3/model"
+)
+
+// FileAttachmentAnnotationDef defines a file attachment annotation, embedding the specified file in the document.
+// The annotation is displayed as an icon with a lower left corner at (X,Y) and a specified Size. The Icon is the
+// name of the icon used by viewers which regenerate the appearance (e.g. "PushPin", "Paperclip", "Graph", "Tag").
+type FileAttachmentAnnotationDef struct {
+	X       float64
+	Y       float64
+	Size    float64 // Defaults to 20 if not set.
+	File    *pdf.EmbeddedFile
+	Icon    string // Defaults to "PushPin".
+	Color   *pdf.PdfColorDeviceRGB
+	Opacity float64 // Alpha value (0-1).
+	MarkupInfo
+}
+
+// CreateFileAttachmentAnnotation creates a file attachment annotation object that can be added to page PDF
+// annotations.
+func CreateFileAttachmentAnnotation(def FileAttachmentAnnotationDef) (*pdf.PdfAnnotation, error) {
+	if def.File == nil {
+		return nil, errors.New("file attachment annotation requires a file")
+	}
+	if def.Size <= 0 {
+		def.Size = 20
+	}
+	if def.Icon == "" {
+		def.Icon = "PushPin"
+	}
+	if def.Contents == "" {
+		def.Contents = def.File.Name
+	}
+
+	fs, err := pdf.NewPdfFilespecFromEmbeddedFile(def.File)
+	if err != nil {
+		return nil, err
+	}
+
+	faAnnotation := pdf.NewPdfAnnotationFileAttachment()
+	def.MarkupInfo.apply(faAnnotation.PdfAnnotation, faAnnotation.PdfAnnotationMarkup)
+	faAnnotation.FS = fs.ToPdfObject()
+	faAnnotation.Name = pdfcore.MakeName(def.Icon)
+	faAnnotation.C = rgbArray(def.Color)
+	if def.Opacity < 1.0 {
+		faAnnotation.CA = pdfcore.MakeFloat(def.Opacity)
+	}
+
+	bbox := &pdf.PdfRectangle{Llx: def.X, Lly: def.Y, Urx: def.X + def.Size, Ury: def.Y + def.Size}
+	faAnnotation.Rect = pdfcore.MakeArrayFromFloats([]float64{bbox.Llx, bbox.Lly, bbox.Urx, bbox.Ury})
+
+	// Make the appearance stream (for uniform appearance). The icon is drawn
+	// as a document with a folded corner, regardless of the icon name.
+	s := def.Size
+	x, y := bbox.Llx, bbox.Lly
+	fold := s / 4
+	lineWidth := s / 20
+
+	cc := contentstream.NewContentCreator()
+	cc.Add_q()
+	if def.Opacity < 1.0 {
+		cc.Add_gs("gs1")
+	}
+	cc.Add_w(lineWidth).Add_j("1").
+		Add_RG(rgbComponents(def.Color)).
+		Add_rg(1, 1, 1)
+
+	left, right := x+s*0.15, x+s*0.85
+	bottom, top := y+lineWidth, y+s-lineWidth
+	cc.Add_m(left, bottom).
+		Add_l(right, bottom).
+		Add_l(right, top-fold).
+		Add_l(right-fold, top).
+		Add_l(left, top).
+		Add_h().
+		Add_B()
+	cc.Add_m(right-fold, top).
+		Add_l(right-fold, top-fold).
+		Add_l(right, top-fold).
+		Add_S()
+
+	// Text lines.
+	for i := 1; i <= 3; i++ {
+		ly := top - fold - float64(i)*s*0.15
+		cc.Add_m(left+s*0.1, ly).Add_l(right-s*0.1, ly)
+	}
+	cc.Add_S()
+	cc.Add_Q()
+
+	apDict, err := makeAnnotationAppearance(cc.Bytes(), bbox, def.Opacity, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	faAnnotation.AP = apDict
+
+	return faAnnotation.PdfAnnotation, nil
+}
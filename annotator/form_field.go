@@ -6,10 +6,8 @@
 package annotator
 
 import (
-	"bytes"
 	"errors"
 
-	"github.com/unidoc/unipdf/v3/contentstream"
 	"github.com/unidoc/unipdf/v3/core"
 	"github.com/unidoc/unipdf/v3/model"
 )
@@ -61,6 +59,9 @@ func NewTextField(page *model.PdfPage, name string, rect []float64, opt TextFiel
 // CheckboxFieldOptions defines optional parameters for a checkbox field a form.
 type CheckboxFieldOptions struct {
 	Checked bool
+
+	// Style is the style of the mark of the checked checkbox.
+	Style CheckboxStyle
 }
 
 // NewCheckboxField generates a new checkbox field with partial name `name` at location `rect`
@@ -76,11 +77,6 @@ func NewCheckboxField(page *model.PdfPage, name string, rect []float64, opt Chec
 		return nil, errors.New("invalid range")
 	}
 
-	zapfdb, err := model.NewStandard14Font(model.ZapfDingbatsName)
-	if err != nil {
-		return nil, err
-	}
-
 	field := model.NewPdfField()
 	buttonfield := &model.PdfFieldButton{}
 	field.SetContext(buttonfield)
@@ -101,63 +97,175 @@ func NewCheckboxField(page *model.PdfPage, name string, rect []float64, opt Chec
 	widget.P = page.ToPdfObject()
 	widget.F = core.MakeInteger(4)
 	widget.Parent = buttonfield.ToPdfObject()
+	widget.MK = newCheckboxMK(opt.Style, false)
+	buttonfield.Annotations = append(buttonfield.Annotations, widget)
+
+	appearance, err := genFieldCheckboxAppearance(widget, buttonfield, nil, FieldAppearance{}.Style())
+	if err != nil {
+		return nil, err
+	}
+	widget.AP = appearance
+
+	return buttonfield, nil
+}
+
+// RadioButtonFieldOptions defines optional parameters for a radio button field.
+type RadioButtonFieldOptions struct {
+	// States are the names of the "on" appearance states of the buttons of the
+	// group, i.e. the values of the field when the buttons are selected. The
+	// positions of the buttons ("0", "1", ...) are used if not specified.
+	States []string
+
+	// Selected is the state of the selected button. No button is selected if empty.
+	Selected string
 
-	w := rect[2] - rect[0]
-	h := rect[3] - rect[1]
-
-	// Off state.
-	var cs bytes.Buffer
-	cs.WriteString("q\n")
-	cs.WriteString("0 0 1 rg\n")
-	cs.WriteString("BT\n")
-	cs.WriteString("/ZaDb 12 Tf\n")
-	cs.WriteString("ET\n")
-	cs.WriteString("Q\n")
-
-	cc := contentstream.NewContentCreator()
-	cc.Add_q()
-	cc.Add_rg(0, 0, 1)
-	cc.Add_BT()
-	cc.Add_Tf(*core.MakeName("ZaDb"), 12)
-	cc.Add_Td(0, 0)
-	cc.Add_ET()
-	cc.Add_Q()
-
-	xformOff := model.NewXObjectForm()
-	xformOff.SetContentStream(cc.Bytes(), core.NewRawEncoder())
-	xformOff.BBox = core.MakeArrayFromFloats([]float64{0, 0, w, h})
-	xformOff.Resources = model.NewPdfPageResources()
-	xformOff.Resources.SetFontByName("ZaDb", zapfdb.ToPdfObject())
-
-	// On state (Yes).
-	cc = contentstream.NewContentCreator()
-	cc.Add_q()
-	cc.Add_re(0, 0, w, h)
-	cc.Add_W().Add_n()
-	cc.Add_rg(0, 0, 1)
-	cc.Translate(0, 3.0)
-	cc.Add_BT()
-	cc.Add_Tf(*core.MakeName("ZaDb"), 12)
-	cc.Add_Td(0, 0)
-	cc.Add_Tj(*core.MakeString("\064"))
-	cc.Add_ET()
-	cc.Add_Q()
-
-	xformOn := model.NewXObjectForm()
-	xformOn.SetContentStream(cc.Bytes(), core.NewRawEncoder())
-	xformOn.BBox = core.MakeArrayFromFloats([]float64{0, 0, w, h})
-	xformOn.Resources = model.NewPdfPageResources()
-	xformOn.Resources.SetFontByName("ZaDb", zapfdb.ToPdfObject())
-
-	dchoiceapp := core.MakeDict()
-	dchoiceapp.Set("Off", xformOff.ToPdfObject())
-	dchoiceapp.Set("Yes", xformOn.ToPdfObject())
-
-	appearance := core.MakeDict()
-	appearance.Set("N", dchoiceapp)
+	// Style is the style of the mark of the selected button.
+	Style CheckboxStyle
+}
+
+// NewRadioButtonField generates a new radio button field with partial name `name` on specified `page`,
+// with a button at each location of `rects` and with field specific options `opt`.
+func NewRadioButtonField(page *model.PdfPage, name string, rects [][]float64, opt RadioButtonFieldOptions) (*model.PdfFieldButton, error) {
+	if page == nil {
+		return nil, errors.New("page not specified")
+	}
+	if len(name) <= 0 {
+		return nil, errors.New("required attribute not specified")
+	}
+	if len(rects) == 0 {
+		return nil, errors.New("no buttons specified")
+	}
+	if len(opt.States) != 0 && len(opt.States) != len(rects) {
+		return nil, errors.New("invalid number of states")
+	}
+
+	field := model.NewPdfField()
+	buttonfield := &model.PdfFieldButton{}
+	field.SetContext(buttonfield)
+	buttonfield.PdfField = field
 
+	buttonfield.T = core.MakeString(name)
+	buttonfield.SetFlag(model.FieldFlagRadio | model.FieldFlagNoToggleToOff)
+
+	state := opt.Selected
+	if state == "" {
+		state = "Off"
+	}
+	buttonfield.V = core.MakeName(state)
+
+	for i, rect := range rects {
+		if len(rect) != 4 {
+			return nil, errors.New("invalid range")
+		}
+
+		widget := model.NewPdfAnnotationWidget()
+		widget.Rect = core.MakeArrayFromFloats(rect)
+		widget.P = page.ToPdfObject()
+		widget.F = core.MakeInteger(4)
+		widget.Parent = buttonfield.ToPdfObject()
+		widget.MK = newCheckboxMK(opt.Style, true)
+		buttonfield.Annotations = append(buttonfield.Annotations, widget)
+
+		if len(opt.States) != 0 {
+			// Set the state name used by the appearance generation.
+			nDict := core.MakeDict()
+			nDict.Set(*core.MakeName(opt.States[i]), core.MakeNull())
+			apDict := core.MakeDict()
+			apDict.Set("N", nDict)
+			widget.AP = apDict
+		}
+		appearance, err := genFieldCheckboxAppearance(widget, buttonfield, nil, FieldAppearance{}.Style())
+		if err != nil {
+			return nil, err
+		}
+		widget.AP = appearance
+	}
+
+	return buttonfield, nil
+}
+
+// newCheckboxMK returns the appearance characteristics (MK) dictionary of
+// checkbox and radio button widgets with the mark style `style`.
+func newCheckboxMK(style CheckboxStyle, radio bool) *core.PdfObjectDictionary {
+	if style == CheckboxStyleDefault && radio {
+		style = CheckboxStyleCircle
+	}
+	mk := core.MakeDict()
+	mk.Set("CA", core.MakeString(style.caption()))
+	return mk
+}
+
+// PushButtonFieldOptions defines optional parameters for a push button form field.
+type PushButtonFieldOptions struct {
+	// Caption is the text displayed on the button, in Helvetica with a
+	// font size fitting the button.
+	Caption string
+
+	// BorderStyle, BorderWidth and BorderColor define the border of the
+	// button. No border is drawn if BorderColor is nil.
+	BorderStyle model.BorderStyle
+	BorderWidth float64
+	BorderColor model.PdfColor
+
+	// BackgroundColor is the background color of the button. The
+	// background is transparent if nil.
+	BackgroundColor model.PdfColor
+}
+
+// NewPushButtonField generates a new push button field with partial name `name` at location `rect`
+// on specified `page` and with field specific options `opt`. The normal, rollover and down
+// appearances of the button are generated.
+func NewPushButtonField(page *model.PdfPage, name string, rect []float64, opt PushButtonFieldOptions) (*model.PdfFieldButton, error) {
+	if page == nil {
+		return nil, errors.New("page not specified")
+	}
+	if len(name) <= 0 {
+		return nil, errors.New("required attribute not specified")
+	}
+	if len(rect) != 4 {
+		return nil, errors.New("invalid range")
+	}
+
+	field := model.NewPdfField()
+	buttonfield := &model.PdfFieldButton{}
+	field.SetContext(buttonfield)
+	buttonfield.PdfField = field
+
+	buttonfield.T = core.MakeString(name)
+	buttonfield.SetType(model.ButtonTypePush)
+
+	widget := model.NewPdfAnnotationWidget()
+	widget.Rect = core.MakeArrayFromFloats(rect)
+	widget.P = page.ToPdfObject()
+	widget.F = core.MakeInteger(4)
+	widget.Parent = buttonfield.ToPdfObject()
+
+	mk := core.MakeDict()
+	if opt.Caption != "" {
+		mk.Set("CA", core.MakeString(opt.Caption))
+	}
+	if opt.BorderColor != nil {
+		mk.Set("BC", colorToArray(opt.BorderColor))
+	}
+	if opt.BackgroundColor != nil {
+		mk.Set("BG", colorToArray(opt.BackgroundColor))
+	}
+	widget.MK = mk
+
+	bs := model.NewBorderStyle()
+	bs.SetBorderWidth(opt.BorderWidth)
+	bs.S = &opt.BorderStyle
+	widget.BS = bs.ToPdfObject()
+
+	def, err := NewAppearanceDefFromWidget(widget)
+	if err != nil {
+		return nil, err
+	}
+	appearance, err := GenerateWidgetAppearance(def)
+	if err != nil {
+		return nil, err
+	}
 	widget.AP = appearance
-	widget.AS = core.MakeName(state)
 
 	buttonfield.Annotations = append(buttonfield.Annotations, widget)
 
@@ -168,6 +276,17 @@ func NewCheckboxField(page *model.PdfPage, name string, rect []float64, opt Chec
 type ComboboxFieldOptions struct {
 	// Choices is the list of string values that can be selected.
 	Choices []string
+
+	// Options is the list of options with distinct export and display
+	// values, appended to Choices.
+	Options []model.FormFieldOption
+
+	// Value is the export value of the selected option, or any text for
+	// editable comboboxes. No option is selected if empty.
+	Value string
+
+	// Editable allows the user to enter a value which is not in the options.
+	Editable bool
 }
 
 // NewComboboxField generates a new combobox form field with partial name `name` at location `rect`
@@ -183,17 +302,82 @@ func NewComboboxField(page *model.PdfPage, name string, rect []float64, opt Comb
 		return nil, errors.New("invalid range")
 	}
 
+	flags := model.FieldFlagCombo
+	if opt.Editable {
+		flags |= model.FieldFlagEdit
+	}
+	chfield, err := newChoiceField(page, name, rect, opt.Choices, opt.Options, flags)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Value != "" {
+		if err := chfield.SetSelected(opt.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return chfield, nil
+}
+
+// ListboxFieldOptions defines optional parameters for a list box form field.
+type ListboxFieldOptions struct {
+	// Options is the list of options that can be selected, with their
+	// export and display values.
+	Options []model.FormFieldOption
+
+	// Selected contains the export values of the selected options.
+	Selected []string
+
+	// MultiSelect allows the selection of several options.
+	MultiSelect bool
+}
+
+// NewListboxField generates a new list box form field with partial name `name` at location `rect`
+// on specified `page` and with field specific options `opt`.
+func NewListboxField(page *model.PdfPage, name string, rect []float64, opt ListboxFieldOptions) (*model.PdfFieldChoice, error) {
+	if page == nil {
+		return nil, errors.New("page not specified")
+	}
+	if len(name) <= 0 {
+		return nil, errors.New("required attribute not specified")
+	}
+	if len(rect) != 4 {
+		return nil, errors.New("invalid range")
+	}
+
+	var flags model.FieldFlag
+	if opt.MultiSelect {
+		flags |= model.FieldFlagMultiSelect
+	}
+	chfield, err := newChoiceField(page, name, rect, nil, opt.Options, flags)
+	if err != nil {
+		return nil, err
+	}
+	if err := chfield.SetSelected(opt.Selected...); err != nil {
+		return nil, err
+	}
+
+	return chfield, nil
+}
+
+// newChoiceField returns a new choice field with partial name `name`, flags `flags` and the
+// options `choices` and `options`, with a widget annotation at location `rect` on `page`.
+func newChoiceField(page *model.PdfPage, name string, rect []float64, choices []string,
+	options []model.FormFieldOption, flags model.FieldFlag) (*model.PdfFieldChoice, error) {
 	field := model.NewPdfField()
 	chfield := &model.PdfFieldChoice{}
 	field.SetContext(chfield)
 	chfield.PdfField = field
 
 	chfield.T = core.MakeString(name)
-	chfield.Opt = core.MakeArray()
-	for _, choicestr := range opt.Choices {
-		chfield.Opt.Append(core.MakeString(choicestr))
+	all := make([]model.FormFieldOption, 0, len(choices)+len(options))
+	for _, choice := range choices {
+		all = append(all, model.FormFieldOption{Export: choice, Display: choice})
+	}
+	chfield.SetOptions(append(all, options...))
+	if flags != 0 {
+		chfield.SetFlag(flags)
 	}
-	chfield.SetFlag(model.FieldFlagCombo)
 
 	widget := model.NewPdfAnnotationWidget()
 	widget.Rect = core.MakeArrayFromFloats(rect)
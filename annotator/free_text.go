@@ -0,0 +1,201 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	pdfcore "github.com/unidoc/unipdf/v3/core"
+	pdf "github.com/unidoc/unipdf/v3/model"
+)
+
+// FreeTextAnnotationDef defines a free text annotation displaying text directly on the page, in a box with a lower
+// left corner at (X,Y) and a specified Width and Height. The text is wrapped to fit the width of the box and is
+// rendered using the Helvetica font. The box can optionally have a border and a filling color.
+type FreeTextAnnotationDef struct {
+	X             float64
+	Y             float64
+	Width         float64
+	Height        float64
+	Text          string
+	FontSize      float64 // Defaults to 12 if not set.
+	TextColor     *pdf.PdfColorDeviceRGB
+	Alignment     int  // Quadding: 0 - left, 1 - centered, 2 - right.
+	FillEnabled   bool // Show fill?
+	FillColor     *pdf.PdfColorDeviceRGB
+	BorderEnabled bool // Show border?
+	BorderWidth   float64
+	BorderColor   *pdf.PdfColorDeviceRGB
+	Opacity       float64 // Alpha value (0-1).
+	MarkupInfo
+}
+
+// CreateFreeTextAnnotation creates a free text annotation object that can be added to page PDF annotations.
+func CreateFreeTextAnnotation(def FreeTextAnnotationDef) (*pdf.PdfAnnotation, error) {
+	if def.FontSize <= 0 {
+		def.FontSize = 12
+	}
+	if def.Contents == "" {
+		def.Contents = def.Text
+	}
+
+	ftAnnotation := pdf.NewPdfAnnotationFreeText()
+	def.MarkupInfo.apply(ftAnnotation.PdfAnnotation, ftAnnotation.PdfAnnotationMarkup)
+
+	r, g, b := rgbComponents(def.TextColor)
+	ftAnnotation.DA = pdfcore.MakeString(fmt.Sprintf("/Helv %s Tf %s %s %s rg",
+		formatFloat(def.FontSize), formatFloat(r), formatFloat(g), formatFloat(b)))
+	if def.Alignment > 0 {
+		ftAnnotation.Q = pdfcore.MakeInteger(int64(def.Alignment))
+	}
+
+	bs := pdf.NewBorderStyle()
+	bs.SetBorderWidth(0)
+	if def.BorderEnabled {
+		bs.SetBorderWidth(def.BorderWidth)
+		ftAnnotation.C = rgbArray(def.BorderColor)
+	}
+	ftAnnotation.BS = bs.ToPdfObject()
+
+	if def.Opacity < 1.0 {
+		ftAnnotation.CA = pdfcore.MakeFloat(def.Opacity)
+	}
+
+	bbox := &pdf.PdfRectangle{Llx: def.X, Lly: def.Y, Urx: def.X + def.Width, Ury: def.Y + def.Height}
+	ftAnnotation.Rect = pdfcore.MakeArrayFromFloats([]float64{bbox.Llx, bbox.Lly, bbox.Urx, bbox.Ury})
+
+	// Make the appearance stream (for uniform appearance).
+	font, err := pdf.NewStandard14Font(pdf.HelveticaName)
+	if err != nil {
+		return nil, err
+	}
+	resources := pdf.NewPdfPageResources()
+	if err := resources.SetFontByName("Helv", font.ToPdfObject()); err != nil {
+		return nil, err
+	}
+
+	content := drawFreeText(def, bbox, font)
+	apDict, err := makeAnnotationAppearance(content, bbox, def.Opacity, "", resources)
+	if err != nil {
+		return nil, err
+	}
+	ftAnnotation.AP = apDict
+
+	return ftAnnotation.PdfAnnotation, nil
+}
+
+// drawFreeText returns the content stream of the appearance of the free text annotation.
+func drawFreeText(def FreeTextAnnotationDef, bbox *pdf.PdfRectangle, font *pdf.PdfFont) []byte {
+	cc := contentstream.NewContentCreator()
+	cc.Add_q()
+	if def.Opacity < 1.0 {
+		cc.Add_gs("gs1")
+	}
+
+	borderWidth := 0.0
+	if def.BorderEnabled {
+		borderWidth = def.BorderWidth
+	}
+	if def.FillEnabled || def.BorderEnabled {
+		half := borderWidth / 2
+		cc.Add_re(bbox.Llx+half, bbox.Lly+half, def.Width-borderWidth, def.Height-borderWidth)
+		if def.FillEnabled {
+			cc.Add_rg(rgbComponents(def.FillColor))
+		}
+		if def.BorderEnabled {
+			cc.Add_RG(rgbComponents(def.BorderColor)).Add_w(borderWidth)
+		}
+		switch {
+		case def.FillEnabled && def.BorderEnabled:
+			cc.Add_B()
+		case def.FillEnabled:
+			cc.Add_f()
+		default:
+			cc.Add_S()
+		}
+	}
+
+	// Text, clipped to the inner area of the box.
+	padding := borderWidth + 2
+	innerWidth := def.Width - 2*padding
+	cc.Add_re(bbox.Llx+borderWidth, bbox.Lly+borderWidth, def.Width-2*borderWidth, def.Height-2*borderWidth).
+		Add_W().Add_n()
+
+	lines := wrapText(def.Text, font, def.FontSize, innerWidth)
+	encoder := font.Encoder()
+	leading := 1.2 * def.FontSize
+
+	cc.Add_BT().
+		Add_rg(rgbComponents(def.TextColor)).
+		Add_Tf("Helv", def.FontSize)
+
+	y := bbox.Ury - padding - def.FontSize
+	for _, line := range lines {
+		x := bbox.Llx + padding
+		if def.Alignment == 1 || def.Alignment == 2 {
+			free := innerWidth - textWidth(line, font, def.FontSize)
+			if def.Alignment == 1 {
+				free /= 2
+			}
+			x += free
+		}
+		cc.Add_Tm(1, 0, 0, 1, x, y).
+			Add_Tj(*pdfcore.MakeStringFromBytes(encoder.Encode(line)))
+		y -= leading
+	}
+	cc.Add_ET()
+	cc.Add_Q()
+
+	return cc.Bytes()
+}
+
+// wrapText splits `text` into lines which fit in `maxWidth` when rendered using the specified font and font size.
+// Explicit line breaks are preserved. Words longer than `maxWidth` are placed on their own line.
+func wrapText(text string, font *pdf.PdfFont, fontSize, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := words[0]
+		for _, word := range words[1:] {
+			candidate := line + " " + word
+			if textWidth(candidate, font, fontSize) > maxWidth {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// textWidth returns the width of `text` rendered using the specified font and font size.
+func textWidth(text string, font *pdf.PdfFont, fontSize float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		metrics, has := font.GetRuneMetrics(r)
+		if !has {
+			continue
+		}
+		width += metrics.Wx
+	}
+	return width * fontSize / 1000.0
+}
+
+// formatFloat formats a number for use in content stream strings, without trailing zeros.
+func formatFloat(val float64) string {
+	s := fmt.Sprintf("%.4f", val)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
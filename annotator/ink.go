@@ -0,0 +1,96 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/contentstream/draw"
+	pdfcore "github.com/unidoc/unipdf/v3/core"
+	pdf "github.com/unidoc/unipdf/v3/model"
+)
+
+// InkAnnotationDef defines a freehand "scribble" composed of one or more disjoint paths, each one specified as a
+// series of points in page coordinates. The paths are stroked with the specified width, color and opacity.
+type InkAnnotationDef struct {
+	Paths     [][]draw.Point
+	LineColor *pdf.PdfColorDeviceRGB
+	LineWidth float64
+	Opacity   float64 // Alpha value (0-1).
+	MarkupInfo
+}
+
+// CreateInkAnnotation creates an ink annotation object that can be added to page PDF annotations.
+func CreateInkAnnotation(def InkAnnotationDef) (*pdf.PdfAnnotation, error) {
+	inkList := pdfcore.MakeArray()
+	var allPoints []float64
+	for _, path := range def.Paths {
+		if len(path) == 0 {
+			continue
+		}
+		var points []float64
+		for _, p := range path {
+			points = append(points, p.X, p.Y)
+		}
+		inkList.Append(pdfcore.MakeArrayFromFloats(points))
+		allPoints = append(allPoints, points...)
+	}
+	if inkList.Len() == 0 {
+		return nil, errors.New("ink annotation requires at least one path")
+	}
+	if def.LineWidth <= 0 {
+		def.LineWidth = 1
+	}
+
+	inkAnnotation := pdf.NewPdfAnnotationInk()
+	def.MarkupInfo.apply(inkAnnotation.PdfAnnotation, inkAnnotation.PdfAnnotationMarkup)
+	inkAnnotation.InkList = inkList
+	inkAnnotation.C = rgbArray(def.LineColor)
+	bs := pdf.NewBorderStyle()
+	bs.SetBorderWidth(def.LineWidth)
+	inkAnnotation.BS = bs.ToPdfObject()
+	if def.Opacity < 1.0 {
+		inkAnnotation.CA = pdfcore.MakeFloat(def.Opacity)
+	}
+
+	bbox := pointsBBox(allPoints, def.LineWidth)
+	inkAnnotation.Rect = pdfcore.MakeArrayFromFloats([]float64{bbox.Llx, bbox.Lly, bbox.Urx, bbox.Ury})
+
+	// Make the appearance stream (for uniform appearance).
+	cc := contentstream.NewContentCreator()
+	cc.Add_q()
+	if def.Opacity < 1.0 {
+		cc.Add_gs("gs1")
+	}
+	cc.Add_RG(rgbComponents(def.LineColor)).
+		Add_w(def.LineWidth).
+		Add_J("1").
+		Add_j("1")
+	for _, path := range def.Paths {
+		if len(path) == 0 {
+			continue
+		}
+		cc.Add_m(path[0].X, path[0].Y)
+		if len(path) == 1 {
+			// Single point, drawn as a dot using the round line cap.
+			cc.Add_l(path[0].X, path[0].Y)
+		}
+		for _, p := range path[1:] {
+			cc.Add_l(p.X, p.Y)
+		}
+		cc.Add_S()
+	}
+	cc.Add_Q()
+
+	apDict, err := makeAnnotationAppearance(cc.Bytes(), bbox, def.Opacity, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	inkAnnotation.AP = apDict
+
+	return inkAnnotation.PdfAnnotation, nil
+}
@@ -0,0 +1,126 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"math"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/common"
+	pdfcore "github.com/unidoc/unipdf/v3/core"
+	pdf "github.com/unidoc/unipdf/v3/model"
+)
+
+// MarkupInfo contains the common attributes of markup annotations.
+type MarkupInfo struct {
+	Author   string // Text label of the annotation (T), usually the author name.
+	Subject  string // Subject of the annotation (Subj).
+	Contents string // Text displayed for the annotation, e.g. in its popup (Contents).
+}
+
+// apply sets the markup attributes on the annotation `annot` and its markup
+// dictionary `markup`.
+func (info MarkupInfo) apply(annot *pdf.PdfAnnotation, markup *pdf.PdfAnnotationMarkup) {
+	if info.Contents != "" {
+		annot.Contents = pdfcore.MakeEncodedString(info.Contents, true)
+	}
+	if info.Author != "" {
+		markup.T = pdfcore.MakeEncodedString(info.Author, true)
+	}
+	if info.Subject != "" {
+		markup.Subj = pdfcore.MakeEncodedString(info.Subject, true)
+	}
+
+	now := time.Now()
+	if date, err := pdf.NewPdfDateFromTime(now); err == nil {
+		markup.CreationDate = date.ToPdfObject()
+		annot.M = date.ToPdfObject()
+	}
+
+	annot.F = pdfcore.MakeInteger(4) // 4 (100 -> Print/show annotations).
+}
+
+// makeAnnotationAppearance returns an appearance dictionary with a normal
+// appearance XObject form, drawn from the operations in `content`. The form
+// bounding box is `bbox`, expressed in the default user space of the page.
+// If `opacity` is lower than 1 or `blendMode` is set, a graphics state named
+// "gs1" is added to the resources of the form.
+func makeAnnotationAppearance(content []byte, bbox *pdf.PdfRectangle, opacity float64, blendMode string,
+	resources *pdf.PdfPageResources) (*pdfcore.PdfObjectDictionary, error) {
+	form := pdf.NewXObjectForm()
+	form.Resources = resources
+	if form.Resources == nil {
+		form.Resources = pdf.NewPdfPageResources()
+	}
+
+	if opacity < 1.0 || blendMode != "" {
+		gsState := pdfcore.MakeDict()
+		if opacity < 1.0 {
+			gsState.Set("ca", pdfcore.MakeFloat(opacity))
+			gsState.Set("CA", pdfcore.MakeFloat(opacity))
+		}
+		if blendMode != "" {
+			gsState.Set("BM", pdfcore.MakeName(blendMode))
+		}
+		err := form.Resources.AddExtGState("gs1", gsState)
+		if err != nil {
+			common.Log.Debug("Unable to add extgstate gs1")
+			return nil, err
+		}
+	}
+
+	if err := form.SetContentStream(content, nil); err != nil {
+		return nil, err
+	}
+	form.BBox = bbox.ToPdfObject()
+
+	apDict := pdfcore.MakeDict()
+	apDict.Set("N", form.ToPdfObject())
+	return apDict, nil
+}
+
+// rgbArray returns the components of `color` as a PDF array. Black is used
+// if `color` is nil.
+func rgbArray(color *pdf.PdfColorDeviceRGB) *pdfcore.PdfObjectArray {
+	if color == nil {
+		return pdfcore.MakeArrayFromFloats([]float64{0, 0, 0})
+	}
+	return pdfcore.MakeArrayFromFloats([]float64{color.R(), color.G(), color.B()})
+}
+
+// rgbComponents returns the components of `color`. Black is used if `color`
+// is nil.
+func rgbComponents(color *pdf.PdfColorDeviceRGB) (float64, float64, float64) {
+	if color == nil {
+		return 0, 0, 0
+	}
+	return color.R(), color.G(), color.B()
+}
+
+// pointsBBox returns the bounding box of the specified points (x, y pairs),
+// expanded by `margin` on each side.
+func pointsBBox(points []float64, margin float64) *pdf.PdfRectangle {
+	bbox := &pdf.PdfRectangle{
+		Llx: math.Inf(1), Lly: math.Inf(1),
+		Urx: math.Inf(-1), Ury: math.Inf(-1),
+	}
+	for i := 0; i+1 < len(points); i += 2 {
+		x, y := points[i], points[i+1]
+		bbox.Llx = math.Min(bbox.Llx, x)
+		bbox.Lly = math.Min(bbox.Lly, y)
+		bbox.Urx = math.Max(bbox.Urx, x)
+		bbox.Ury = math.Max(bbox.Ury, y)
+	}
+	if len(points) < 2 {
+		return &pdf.PdfRectangle{}
+	}
+
+	bbox.Llx -= margin
+	bbox.Lly -= margin
+	bbox.Urx += margin
+	bbox.Ury += margin
+	return bbox
+}
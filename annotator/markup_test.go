@@ -0,0 +1,127 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/contentstream/draw"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestCreateMarkupAnnotations(t *testing.T) {
+	red := model.NewPdfColorDeviceRGB(1, 0, 0)
+	rects := []model.PdfRectangle{
+		{Llx: 100, Lly: 700, Urx: 300, Ury: 714},
+		{Llx: 100, Lly: 684, Urx: 250, Ury: 698},
+	}
+
+	var annotations []*model.PdfAnnotation
+	add := func(annot *model.PdfAnnotation, err error) {
+		require.NoError(t, err)
+		annotations = append(annotations, annot)
+	}
+
+	markupDef := TextMarkupAnnotationDef{Rects: rects, Color: red, Opacity: 0.5}
+	markupDef.Author = "John Doe"
+	add(CreateHighlightAnnotation(markupDef))
+	add(CreateUnderlineAnnotation(markupDef))
+	add(CreateStrikeOutAnnotation(markupDef))
+	add(CreateSquigglyAnnotation(markupDef))
+	add(CreateFreeTextAnnotation(FreeTextAnnotationDef{
+		X: 100, Y: 500, Width: 150, Height: 60,
+		Text:          "The quick brown fox jumps over the lazy dog",
+		BorderEnabled: true, BorderWidth: 1, BorderColor: red,
+		Opacity: 1,
+	}))
+	add(CreatePolygonAnnotation(PolygonAnnotationDef{
+		Vertices:      []draw.Point{{X: 100, Y: 300}, {X: 200, Y: 300}, {X: 150, Y: 400}},
+		BorderEnabled: true, BorderWidth: 2, BorderColor: red,
+		Opacity: 1,
+	}))
+	add(CreateInkAnnotation(InkAnnotationDef{
+		Paths:     [][]draw.Point{{{X: 300, Y: 300}, {X: 320, Y: 330}, {X: 340, Y: 300}}},
+		LineColor: red, LineWidth: 2, Opacity: 1,
+	}))
+	add(CreateStampAnnotation(StampAnnotationDef{
+		X: 350, Y: 500, Width: 150, Height: 50, Name: "Approved", Color: red, Opacity: 1,
+	}))
+	add(CreateFileAttachmentAnnotation(FileAttachmentAnnotationDef{
+		X: 50, Y: 50, Opacity: 1,
+		File: &model.EmbeddedFile{Name: "notes.txt", Content: []byte("Hello"), FileType: "text/plain"},
+	}))
+
+	// Write the annotations to a page and read them back.
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	for _, annot := range annotations {
+		page.AddAnnotation(annot)
+	}
+
+	w := model.NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	readPage, err := r.GetPage(1)
+	require.NoError(t, err)
+	readAnnots, err := readPage.GetAnnotations()
+	require.NoError(t, err)
+	require.Len(t, readAnnots, len(annotations))
+
+	expected := []string{
+		"Highlight", "Underline", "StrikeOut", "Squiggly", "FreeText",
+		"Polygon", "Ink", "Stamp", "FileAttachment",
+	}
+	for i, annot := range readAnnots {
+		dict, ok := core.GetDict(annot.GetContainingPdfObject())
+		require.True(t, ok)
+		subtype, _ := core.GetNameVal(dict.Get("Subtype"))
+		require.Equal(t, expected[i], subtype)
+
+		// Each annotation must have a normal appearance stream with a
+		// bounding box matching the annotation rectangle.
+		apDict, ok := core.GetDict(annot.AP)
+		require.True(t, ok, subtype)
+		xform, ok := core.GetStream(apDict.Get("N"))
+		require.True(t, ok, subtype)
+		bbox, err := model.NewPdfRectangle(*xform.Get("BBox").(*core.PdfObjectArray))
+		require.NoError(t, err)
+		rect, err := model.NewPdfRectangle(*annot.Rect.(*core.PdfObjectArray))
+		require.NoError(t, err)
+		require.Equal(t, *rect, *bbox, subtype)
+	}
+
+	// Text markup quad points.
+	highlight, ok := readAnnots[0].GetContext().(*model.PdfAnnotationHighlight)
+	require.True(t, ok)
+	quads, ok := core.GetArray(highlight.QuadPoints)
+	require.True(t, ok)
+	vals, err := quads.ToFloat64Array()
+	require.NoError(t, err)
+	require.Equal(t, []float64{100, 714, 300, 714, 100, 700, 300, 700}, vals[:8])
+	author, ok := core.GetString(highlight.T)
+	require.True(t, ok)
+	require.Equal(t, "John Doe", author.Decoded())
+
+	// Embedded file of the file attachment.
+	attachment, ok := readAnnots[8].GetContext().(*model.PdfAnnotationFileAttachment)
+	require.True(t, ok)
+	fs, err := model.NewPdfFilespecFromObj(attachment.FS)
+	require.NoError(t, err)
+	file, err := fs.GetEmbeddedFile()
+	require.NoError(t, err)
+	require.NotNil(t, file)
+	require.Equal(t, "notes.txt", file.Name)
+	require.Equal(t, "text/plain", file.FileType)
+	require.Equal(t, []byte("Hello"), file.Content)
+}
@@ -0,0 +1,101 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/contentstream/draw"
+	pdfcore "github.com/unidoc/unipdf/v3/core"
+	pdf "github.com/unidoc/unipdf/v3/model"
+)
+
+// PolygonAnnotationDef defines a closed polygon through the specified vertices, in page coordinates.
+// The polygon can optionally have a border and a filling color.
+type PolygonAnnotationDef struct {
+	Vertices      []draw.Point
+	FillEnabled   bool // Show fill?
+	FillColor     *pdf.PdfColorDeviceRGB
+	BorderEnabled bool // Show border?
+	BorderWidth   float64
+	BorderColor   *pdf.PdfColorDeviceRGB
+	Opacity       float64 // Alpha value (0-1).
+	MarkupInfo
+}
+
+// CreatePolygonAnnotation creates a polygon annotation object that can be added to page PDF annotations.
+func CreatePolygonAnnotation(def PolygonAnnotationDef) (*pdf.PdfAnnotation, error) {
+	if len(def.Vertices) < 2 {
+		return nil, errors.New("polygon annotation requires at least two vertices")
+	}
+
+	polyAnnotation := pdf.NewPdfAnnotationPolygon()
+	def.MarkupInfo.apply(polyAnnotation.PdfAnnotation, polyAnnotation.PdfAnnotationMarkup)
+
+	var points []float64
+	for _, v := range def.Vertices {
+		points = append(points, v.X, v.Y)
+	}
+	polyAnnotation.Vertices = pdfcore.MakeArrayFromFloats(points)
+
+	borderWidth := 0.0
+	bs := pdf.NewBorderStyle()
+	bs.SetBorderWidth(0)
+	if def.BorderEnabled {
+		borderWidth = def.BorderWidth
+		polyAnnotation.C = rgbArray(def.BorderColor)
+		bs.SetBorderWidth(def.BorderWidth)
+	}
+	polyAnnotation.BS = bs.ToPdfObject()
+
+	if def.FillEnabled {
+		polyAnnotation.IC = rgbArray(def.FillColor)
+	}
+	if def.Opacity < 1.0 {
+		polyAnnotation.CA = pdfcore.MakeFloat(def.Opacity)
+	}
+
+	bbox := pointsBBox(points, borderWidth)
+	polyAnnotation.Rect = pdfcore.MakeArrayFromFloats([]float64{bbox.Llx, bbox.Lly, bbox.Urx, bbox.Ury})
+
+	// Make the appearance stream (for uniform appearance).
+	cc := contentstream.NewContentCreator()
+	cc.Add_q()
+	if def.Opacity < 1.0 {
+		cc.Add_gs("gs1")
+	}
+	if def.FillEnabled {
+		cc.Add_rg(rgbComponents(def.FillColor))
+	}
+	if def.BorderEnabled {
+		cc.Add_RG(rgbComponents(def.BorderColor)).Add_w(borderWidth).Add_j("1")
+	}
+	cc.Add_m(points[0], points[1])
+	for i := 2; i+1 < len(points); i += 2 {
+		cc.Add_l(points[i], points[i+1])
+	}
+	cc.Add_h()
+	switch {
+	case def.FillEnabled && def.BorderEnabled:
+		cc.Add_B()
+	case def.FillEnabled:
+		cc.Add_f()
+	case def.BorderEnabled:
+		cc.Add_S()
+	default:
+		cc.Add_n()
+	}
+	cc.Add_Q()
+
+	apDict, err := makeAnnotationAppearance(cc.Bytes(), bbox, def.Opacity, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	polyAnnotation.AP = apDict
+
+	return polyAnnotation.PdfAnnotation, nil
+}
@@ -0,0 +1,116 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	pdfcore "github.com/unidoc/unipdf/v3/core"
+	pdf "github.com/unidoc/unipdf/v3/model"
+)
+
+// StampAnnotationDef defines a rubber stamp annotation with a lower left corner at (X,Y) and a specified Width and
+// Height. The stamp is rendered as a rounded box containing the stamp text, centered and scaled to fit the box.
+// The Name is the name of the icon used by viewers which regenerate the appearance of the stamp (e.g. "Approved",
+// "Draft", "Confidential"). If Text is empty, the upper case icon name is used as the stamp text.
+type StampAnnotationDef struct {
+	X       float64
+	Y       float64
+	Width   float64
+	Height  float64
+	Name    string // Defaults to "Draft".
+	Text    string
+	Color   *pdf.PdfColorDeviceRGB
+	Opacity float64 // Alpha value (0-1).
+	MarkupInfo
+}
+
+// CreateStampAnnotation creates a rubber stamp annotation object that can be added to page PDF annotations.
+func CreateStampAnnotation(def StampAnnotationDef) (*pdf.PdfAnnotation, error) {
+	if def.Name == "" {
+		def.Name = "Draft"
+	}
+	if def.Text == "" {
+		def.Text = strings.ToUpper(def.Name)
+	}
+
+	stampAnnotation := pdf.NewPdfAnnotationStamp()
+	def.MarkupInfo.apply(stampAnnotation.PdfAnnotation, stampAnnotation.PdfAnnotationMarkup)
+	stampAnnotation.Name = pdfcore.MakeName(def.Name)
+	stampAnnotation.C = rgbArray(def.Color)
+	if def.Opacity < 1.0 {
+		stampAnnotation.CA = pdfcore.MakeFloat(def.Opacity)
+	}
+
+	bbox := &pdf.PdfRectangle{Llx: def.X, Lly: def.Y, Urx: def.X + def.Width, Ury: def.Y + def.Height}
+	stampAnnotation.Rect = pdfcore.MakeArrayFromFloats([]float64{bbox.Llx, bbox.Lly, bbox.Urx, bbox.Ury})
+
+	// Make the appearance stream (for uniform appearance).
+	font, err := pdf.NewStandard14Font(pdf.HelveticaBoldName)
+	if err != nil {
+		return nil, err
+	}
+	resources := pdf.NewPdfPageResources()
+	if err := resources.SetFontByName("HeBo", font.ToPdfObject()); err != nil {
+		return nil, err
+	}
+
+	borderWidth := def.Height / 15
+	radius := def.Height / 5
+	r, g, b := rgbComponents(def.Color)
+
+	cc := contentstream.NewContentCreator()
+	cc.Add_q()
+	if def.Opacity < 1.0 {
+		cc.Add_gs("gs1")
+	}
+	cc.Add_RG(r, g, b).Add_rg(r, g, b).Add_w(borderWidth)
+	drawRoundedRect(cc, bbox.Llx+borderWidth/2, bbox.Lly+borderWidth/2,
+		def.Width-borderWidth, def.Height-borderWidth, radius)
+	cc.Add_S()
+
+	// Fit the text in the box, keeping some padding.
+	fontSize := def.Height * 0.6
+	maxWidth := def.Width - 2*(borderWidth+radius)
+	if w := textWidth(def.Text, font, fontSize); w > maxWidth && w > 0 {
+		fontSize *= maxWidth / w
+	}
+	x := bbox.Llx + (def.Width-textWidth(def.Text, font, fontSize))/2
+	// Vertically center, using the approximate cap height of the font.
+	y := bbox.Lly + (def.Height-0.7*fontSize)/2
+
+	cc.Add_BT().
+		Add_Tf("HeBo", fontSize).
+		Add_Tm(1, 0, 0, 1, x, y).
+		Add_Tj(*pdfcore.MakeStringFromBytes(font.Encoder().Encode(def.Text))).
+		Add_ET()
+	cc.Add_Q()
+
+	apDict, err := makeAnnotationAppearance(cc.Bytes(), bbox, def.Opacity, "", resources)
+	if err != nil {
+		return nil, err
+	}
+	stampAnnotation.AP = apDict
+
+	return stampAnnotation.PdfAnnotation, nil
+}
+
+// drawRoundedRect adds the path of a rectangle with rounded corners of radius `r` to the content creator.
+func drawRoundedRect(cc *contentstream.ContentCreator, x, y, width, height, r float64) {
+	// Bezier control point offset approximating a quarter circle.
+	k := 0.5523 * r
+	cc.Add_m(x+r, y).
+		Add_l(x+width-r, y).
+		Add_c(x+width-r+k, y, x+width, y+r-k, x+width, y+r).
+		Add_l(x+width, y+height-r).
+		Add_c(x+width, y+height-r+k, x+width-r+k, y+height, x+width-r, y+height).
+		Add_l(x+r, y+height).
+		Add_c(x+r-k, y+height, x, y+height-r+k, x, y+height-r).
+		Add_l(x, y+r).
+		Add_c(x, y+r-k, x+r-k, y, x+r, y).
+		Add_h()
+}
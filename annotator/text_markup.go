@@ -0,0 +1,172 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package annotator
+
+import (
+	"errors"
+	"math"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	pdfcore "github.com/unidoc/unipdf/v3/core"
+	pdf "github.com/unidoc/unipdf/v3/model"
+)
+
+// TextMarkupType represents the type of a text markup annotation.
+type TextMarkupType int
+
+// Text markup annotation types.
+const (
+	TextMarkupHighlight TextMarkupType = iota
+	TextMarkupUnderline
+	TextMarkupStrikeOut
+	TextMarkupSquiggly
+)
+
+// TextMarkupAnnotationDef defines a text markup annotation (highlight, underline, strikeout or squiggly) covering
+// the text contained in the specified rectangles, usually the bounding boxes of the marked up text lines.
+type TextMarkupAnnotationDef struct {
+	Type    TextMarkupType
+	Rects   []pdf.PdfRectangle // Areas of the marked up text, in page coordinates.
+	Color   *pdf.PdfColorDeviceRGB
+	Opacity float64 // Alpha value (0-1).
+	MarkupInfo
+}
+
+// CreateHighlightAnnotation creates a highlight annotation covering the specified rectangles.
+func CreateHighlightAnnotation(def TextMarkupAnnotationDef) (*pdf.PdfAnnotation, error) {
+	def.Type = TextMarkupHighlight
+	return CreateTextMarkupAnnotation(def)
+}
+
+// CreateUnderlineAnnotation creates an underline annotation for the text in the specified rectangles.
+func CreateUnderlineAnnotation(def TextMarkupAnnotationDef) (*pdf.PdfAnnotation, error) {
+	def.Type = TextMarkupUnderline
+	return CreateTextMarkupAnnotation(def)
+}
+
+// CreateStrikeOutAnnotation creates a strikeout annotation for the text in the specified rectangles.
+func CreateStrikeOutAnnotation(def TextMarkupAnnotationDef) (*pdf.PdfAnnotation, error) {
+	def.Type = TextMarkupStrikeOut
+	return CreateTextMarkupAnnotation(def)
+}
+
+// CreateSquigglyAnnotation creates a squiggly underline annotation for the text in the specified rectangles.
+func CreateSquigglyAnnotation(def TextMarkupAnnotationDef) (*pdf.PdfAnnotation, error) {
+	def.Type = TextMarkupSquiggly
+	return CreateTextMarkupAnnotation(def)
+}
+
+// CreateTextMarkupAnnotation creates a text markup annotation of the type specified by the definition, that can be
+// added to page PDF annotations.
+func CreateTextMarkupAnnotation(def TextMarkupAnnotationDef) (*pdf.PdfAnnotation, error) {
+	if len(def.Rects) == 0 {
+		return nil, errors.New("text markup annotation requires at least one rectangle")
+	}
+
+	// QuadPoints, in the order used by most conforming readers: upper left,
+	// upper right, lower left, lower right.
+	var quadPoints, points []float64
+	for _, r := range def.Rects {
+		quadPoints = append(quadPoints, r.Llx, r.Ury, r.Urx, r.Ury, r.Llx, r.Lly, r.Urx, r.Lly)
+		points = append(points, r.Llx, r.Lly, r.Urx, r.Ury)
+	}
+	quads := pdfcore.MakeArrayFromFloats(quadPoints)
+
+	var annotation *pdf.PdfAnnotation
+	var markup *pdf.PdfAnnotationMarkup
+	switch def.Type {
+	case TextMarkupHighlight:
+		a := pdf.NewPdfAnnotationHighlight()
+		a.QuadPoints = quads
+		annotation, markup = a.PdfAnnotation, a.PdfAnnotationMarkup
+	case TextMarkupUnderline:
+		a := pdf.NewPdfAnnotationUnderline()
+		a.QuadPoints = quads
+		annotation, markup = a.PdfAnnotation, a.PdfAnnotationMarkup
+	case TextMarkupStrikeOut:
+		a := pdf.NewPdfAnnotationStrikeOut()
+		a.QuadPoints = quads
+		annotation, markup = a.PdfAnnotation, a.PdfAnnotationMarkup
+	case TextMarkupSquiggly:
+		a := pdf.NewPdfAnnotationSquiggly()
+		a.QuadPoints = quads
+		annotation, markup = a.PdfAnnotation, a.PdfAnnotationMarkup
+	default:
+		return nil, errors.New("unsupported text markup annotation type")
+	}
+
+	def.MarkupInfo.apply(annotation, markup)
+	annotation.C = rgbArray(def.Color)
+	if def.Opacity < 1.0 {
+		markup.CA = pdfcore.MakeFloat(def.Opacity)
+	}
+
+	// The squiggly line can extend slightly below the marked up area.
+	bbox := pointsBBox(points, 1)
+	annotation.Rect = pdfcore.MakeArrayFromFloats([]float64{bbox.Llx, bbox.Lly, bbox.Urx, bbox.Ury})
+
+	// Make the appearance stream (for uniform appearance).
+	blendMode := ""
+	if def.Type == TextMarkupHighlight {
+		// Highlights are multiplied with the page contents so that the text remains legible.
+		blendMode = "Multiply"
+	}
+	content := drawTextMarkup(def)
+	apDict, err := makeAnnotationAppearance(content, bbox, def.Opacity, blendMode, nil)
+	if err != nil {
+		return nil, err
+	}
+	annotation.AP = apDict
+
+	return annotation, nil
+}
+
+// drawTextMarkup returns the content stream of the appearance of the text markup annotation.
+func drawTextMarkup(def TextMarkupAnnotationDef) []byte {
+	r, g, b := rgbComponents(def.Color)
+
+	cc := contentstream.NewContentCreator()
+	cc.Add_q()
+	if def.Opacity < 1.0 || def.Type == TextMarkupHighlight {
+		cc.Add_gs("gs1")
+	}
+	cc.Add_rg(r, g, b).Add_RG(r, g, b)
+
+	for _, rect := range def.Rects {
+		width := rect.Urx - rect.Llx
+		height := rect.Ury - rect.Lly
+		lineWidth := math.Max(height/14, 0.5)
+
+		switch def.Type {
+		case TextMarkupHighlight:
+			cc.Add_re(rect.Llx, rect.Lly, width, height).Add_f()
+		case TextMarkupUnderline:
+			y := rect.Lly + lineWidth
+			cc.Add_w(lineWidth).Add_m(rect.Llx, y).Add_l(rect.Urx, y).Add_S()
+		case TextMarkupStrikeOut:
+			y := rect.Lly + height/2
+			cc.Add_w(lineWidth).Add_m(rect.Llx, y).Add_l(rect.Urx, y).Add_S()
+		case TextMarkupSquiggly:
+			// Zigzag line along the bottom of the rectangle.
+			step := math.Max(height/8, 1)
+			y := rect.Lly + step/2
+			cc.Add_w(lineWidth).Add_m(rect.Llx, y)
+			up := true
+			for x := rect.Llx + step; x < rect.Urx+step; x += step {
+				dy := -step / 2
+				if up {
+					dy = step / 2
+				}
+				cc.Add_l(math.Min(x, rect.Urx), y+dy)
+				up = !up
+			}
+			cc.Add_S()
+		}
+	}
+	cc.Add_Q()
+
+	return cc.Bytes()
+}
@@ -0,0 +1,211 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// BalanceIssueType represents the kind of an unbalanced q/Q or BT/ET operator.
+type BalanceIssueType int
+
+// Balance issue types.
+const (
+	BalanceIssueUnmatchedQ  BalanceIssueType = iota // Q operator without a matching q.
+	BalanceIssueUnclosedQ                           // q operator without a matching Q.
+	BalanceIssueUnmatchedET                         // ET operator outside of a text object.
+	BalanceIssueUnclosedBT                          // BT operator without a matching ET.
+	BalanceIssueNestedBT                            // BT operator inside of a text object.
+)
+
+// String returns a description of the balance issue type.
+func (t BalanceIssueType) String() string {
+	switch t {
+	case BalanceIssueUnmatchedQ:
+		return "unmatched Q"
+	case BalanceIssueUnclosedQ:
+		return "unclosed q"
+	case BalanceIssueUnmatchedET:
+		return "unmatched ET"
+	case BalanceIssueUnclosedBT:
+		return "unclosed BT"
+	case BalanceIssueNestedBT:
+		return "nested BT"
+	}
+	return fmt.Sprintf("unknown balance issue %d", int(t))
+}
+
+// BalanceIssue describes an unbalanced q/Q or BT/ET operator of a content
+// stream. Such operators break the content streams which are appended to
+// the content stream, e.g. when stamping or overlaying pages, as the
+// appended operations are painted in the graphics state or text object left
+// open by the content stream.
+type BalanceIssue struct {
+	Type BalanceIssueType
+
+	// Index is the index of the operation of the operator in the content
+	// stream operations. For unclosed operators, the operator is the opening
+	// one.
+	Index int
+
+	// Stream is the index of the page content stream containing the
+	// operator, for the issues returned by RepairPageBalance.
+	Stream int
+
+	// Offset is the byte offset of the operator in its content stream, or -1
+	// if the operations were not parsed from a content stream.
+	Offset int64
+}
+
+// String returns a description of the balance issue.
+func (issue BalanceIssue) String() string {
+	if issue.Offset < 0 {
+		return fmt.Sprintf("%s (operation %d)", issue.Type, issue.Index)
+	}
+	return fmt.Sprintf("%s at offset %d (operation %d)", issue.Type, issue.Offset, issue.Index)
+}
+
+// CheckBalance returns the unbalanced q/Q and BT/ET operators of the
+// operations `ops`. Returns nil if the operations are balanced.
+func (ops *ContentStreamOperations) CheckBalance() []BalanceIssue {
+	_, issues := balanceOperations(*ops, nil)
+	return issues
+}
+
+// RepairBalance repairs the unbalanced q/Q and BT/ET operators of the
+// operations `ops` and returns the repaired issues. The unmatched Q and ET
+// operators are removed, an ET operator is inserted before nested BT
+// operators and the unclosed text objects and graphics states are closed at
+// the end.
+func (ops *ContentStreamOperations) RepairBalance() []BalanceIssue {
+	repaired, issues := balanceOperations(*ops, nil)
+	if len(issues) > 0 {
+		*ops = repaired
+	}
+	return issues
+}
+
+// CheckContentStreamBalance parses the content stream `content` and returns
+// its unbalanced q/Q and BT/ET operators, with their byte offsets.
+func CheckContentStreamBalance(content string) ([]BalanceIssue, error) {
+	parser := NewContentStreamParser(content)
+	ops, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+	_, issues := balanceOperations(*ops, parser.offsets)
+	return issues, nil
+}
+
+// RepairPageBalance repairs the unbalanced q/Q and BT/ET operators of the
+// content streams of the page `page`, as RepairBalance, and returns the
+// repaired issues. The balance is checked for the concatenation of the
+// content streams, as they are painted as a single content stream: the
+// content streams are only replaced, by a single content stream, if they
+// have issues.
+func RepairPageBalance(page *model.PdfPage) ([]BalanceIssue, error) {
+	if page == nil {
+		return nil, errors.New("page cannot be nil")
+	}
+	cstreams, err := page.GetContentStreams()
+	if err != nil {
+		return nil, err
+	}
+	content, err := page.GetAllContentStreams()
+	if err != nil {
+		return nil, err
+	}
+
+	parser := NewContentStreamParser(content)
+	ops, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+	repaired, issues := balanceOperations(*ops, parser.offsets)
+	if len(issues) == 0 {
+		return nil, nil
+	}
+
+	// Map the offsets in the concatenation to the offsets in the content
+	// streams, which are joined with single spaces.
+	for i := range issues {
+		for _, cstream := range cstreams[:len(cstreams)-1] {
+			size := int64(len(cstream))
+			if issues[i].Offset <= size {
+				break
+			}
+			issues[i].Offset -= size + 1
+			issues[i].Stream++
+		}
+	}
+
+	if err := page.SetContentStreams([]string{repaired.String()}, core.NewFlateEncoder()); err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// balanceOperations returns the operations `ops` with their unbalanced q/Q
+// and BT/ET operators repaired, and the repaired issues. The issue offsets are
+// taken from `offsets`, the offsets of the operations, if not nil.
+func balanceOperations(ops ContentStreamOperations, offsets []int64) (ContentStreamOperations, []BalanceIssue) {
+	var issues []BalanceIssue
+	addIssue := func(t BalanceIssueType, index int) {
+		issue := BalanceIssue{Type: t, Index: index, Offset: -1}
+		if index < len(offsets) {
+			issue.Offset = offsets[index]
+		}
+		issues = append(issues, issue)
+	}
+
+	// Indices of the open q operators and of the open BT operator.
+	var saves []int
+	text := -1
+
+	repaired := make(ContentStreamOperations, 0, len(ops))
+	for i, op := range ops {
+		if op == nil {
+			continue
+		}
+		switch op.Operand {
+		case "q":
+			saves = append(saves, i)
+		case "Q":
+			if len(saves) == 0 {
+				addIssue(BalanceIssueUnmatchedQ, i)
+				continue
+			}
+			saves = saves[:len(saves)-1]
+		case "BT":
+			if text >= 0 {
+				addIssue(BalanceIssueNestedBT, i)
+				repaired = append(repaired, &ContentStreamOperation{Operand: "ET"})
+			}
+			text = i
+		case "ET":
+			if text < 0 {
+				addIssue(BalanceIssueUnmatchedET, i)
+				continue
+			}
+			text = -1
+		}
+		repaired = append(repaired, op)
+	}
+
+	if text >= 0 {
+		addIssue(BalanceIssueUnclosedBT, text)
+		repaired = append(repaired, &ContentStreamOperation{Operand: "ET"})
+	}
+	for i := len(saves) - 1; i >= 0; i-- {
+		addIssue(BalanceIssueUnclosedQ, saves[i])
+		repaired = append(repaired, &ContentStreamOperation{Operand: "Q"})
+	}
+	return repaired, issues
+}
@@ -0,0 +1,80 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestCheckContentStreamBalance(t *testing.T) {
+	issues, err := CheckContentStreamBalance("q 1 0 0 rg BT /F1 12 Tf (Hi) Tj ET Q")
+	require.NoError(t, err)
+	require.Empty(t, issues)
+
+	content := "q BT (a) Tj BT (b) Tj ET ET Q Q q % comment\nq BT"
+	issues, err = CheckContentStreamBalance(content)
+	require.NoError(t, err)
+	require.Equal(t, []BalanceIssue{
+		{Type: BalanceIssueNestedBT, Index: 3, Offset: 12},
+		{Type: BalanceIssueUnmatchedET, Index: 6, Offset: 25},
+		{Type: BalanceIssueUnmatchedQ, Index: 8, Offset: 30},
+		{Type: BalanceIssueUnclosedBT, Index: 11, Offset: 46},
+		{Type: BalanceIssueUnclosedQ, Index: 10, Offset: 44},
+		{Type: BalanceIssueUnclosedQ, Index: 9, Offset: 32},
+	}, issues)
+	require.Equal(t, "nested BT at offset 12 (operation 3)", issues[0].String())
+	for _, issue := range issues {
+		op := map[BalanceIssueType]string{
+			BalanceIssueNestedBT:    "BT",
+			BalanceIssueUnmatchedET: "ET",
+			BalanceIssueUnmatchedQ:  "Q",
+			BalanceIssueUnclosedBT:  "BT",
+			BalanceIssueUnclosedQ:   "q",
+		}[issue.Type]
+		require.Equal(t, op, content[issue.Offset:issue.Offset+int64(len(op))])
+	}
+}
+
+func TestRepairBalance(t *testing.T) {
+	ops, err := NewContentStreamParser("Q q BT (a) Tj BT (b) Tj ET ET 0 g").Parse()
+	require.NoError(t, err)
+	issues := ops.CheckBalance()
+	require.Len(t, issues, 4)
+	require.Equal(t, int64(-1), issues[0].Offset)
+
+	issues = ops.RepairBalance()
+	require.Len(t, issues, 4)
+	require.Equal(t, "q\nBT\n(a) Tj\nET\nBT\n(b) Tj\nET\n0 g\nQ\n", ops.String())
+	require.Empty(t, ops.CheckBalance())
+	require.Empty(t, ops.RepairBalance())
+}
+
+func TestRepairPageBalance(t *testing.T) {
+	page := model.NewPdfPage()
+	require.NoError(t, page.SetContentStreams([]string{"q 1 0 0 rg", "0 0 m 1 1 l S Q Q"}, nil))
+	issues, err := RepairPageBalance(page)
+	require.NoError(t, err)
+	require.Equal(t, []BalanceIssue{{Type: BalanceIssueUnmatchedQ, Index: 6, Stream: 1, Offset: 16}}, issues)
+
+	cstreams, err := page.GetContentStreams()
+	require.NoError(t, err)
+	require.Len(t, cstreams, 1)
+	require.Equal(t, "q\n1 0 0 rg\n0 0 m\n1 1 l\nS\nQ\n", cstreams[0])
+
+	// Balanced content streams are not replaced.
+	contents := page.Contents
+	issues, err = RepairPageBalance(page)
+	require.NoError(t, err)
+	require.Empty(t, issues)
+	require.Equal(t, contents, page.Contents)
+	_, ok := core.GetStream(page.Contents)
+	require.True(t, ok)
+}
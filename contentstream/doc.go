@@ -8,7 +8,14 @@
 // For processing and manipulating content streams, it allows parse the content stream into a list of
 // operands that can then be processed further for rendering or extraction of information.
 // The ContentStreamProcessor offers a basic engine for processing the content stream and can be used
-// to render or modify the contents.
+// to render or modify the contents. The TextEditor, built on the processor, locates text showing operations
+// by their decoded text and allows replacing, deleting or restyling them. Rewrite handlers registered with
+// AddRewriteHandler allow transforming the operations of a content stream, for example for remapping colors,
+// stripping operators or shifting coordinates. Trace pretty-prints content streams annotated with the
+// graphics state, for debugging. CheckBalance and RepairBalance detect and repair unbalanced q/Q and BT/ET
+// operators, which break the content appended to content streams when stamping or overlaying pages.
+// NormalizeContentStream writes content streams in a canonical form, for diffing, and
+// NormalizePageContentStreams and MergePageContentStreams merge the content streams of pages into one.
 //
 // For creating content streams, see NewContentCreator.  It allows adding multiple operands and then can
 // be converted to a string for embedding in a PDF file.
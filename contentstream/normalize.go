@@ -0,0 +1,120 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// NormalizeOptions contains the options used for normalizing content streams.
+type NormalizeOptions struct {
+	// Precision is the number of decimal places to which the real numbers
+	// are rounded, if positive. The real numbers are not rounded otherwise.
+	Precision int
+
+	// Encoder is the encoder of the normalized page content streams. The
+	// content streams are not compressed if nil.
+	Encoder core.StreamEncoder
+}
+
+// NormalizeContentStream returns the canonical form of the content stream
+// `content`, normalized according to the options `opts`: each operation is
+// written on its own line, with its operands separated by single spaces, and
+// the comments are removed. The real numbers are written in their shortest
+// form, e.g. 0.5 for 0.50, and as integers if they have integer values.
+// Canonical content streams can be compared for finding the differences of
+// the contents of pages.
+func NormalizeContentStream(content string, opts *NormalizeOptions) (string, error) {
+	if opts == nil {
+		opts = &NormalizeOptions{}
+	}
+	ops, err := NewContentStreamParser(content).Parse()
+	if err != nil {
+		return "", err
+	}
+	for _, op := range *ops {
+		for i, param := range op.Params {
+			op.Params[i] = normalizeObject(param, opts.Precision)
+		}
+	}
+	return ops.String(), nil
+}
+
+// MergePageContentStreams replaces the content streams of the page `page`
+// by a single content stream, encoded with `encoder`, containing their
+// concatenation. The raw encoding is used if `encoder` is nil.
+func MergePageContentStreams(page *model.PdfPage, encoder core.StreamEncoder) error {
+	if page == nil {
+		return errors.New("page cannot be nil")
+	}
+	cstreams, err := page.GetContentStreams()
+	if err != nil {
+		return err
+	}
+	if len(cstreams) == 0 {
+		return nil
+	}
+	return page.SetContentStreams([]string{strings.Join(cstreams, "\n")}, encoder)
+}
+
+// NormalizePageContentStreams replaces the content streams of the page
+// `page` by a single content stream containing their concatenation,
+// normalized as NormalizeContentStream and encoded with the encoder of
+// `opts`.
+func NormalizePageContentStreams(page *model.PdfPage, opts *NormalizeOptions) error {
+	if page == nil {
+		return errors.New("page cannot be nil")
+	}
+	if opts == nil {
+		opts = &NormalizeOptions{}
+	}
+	cstreams, err := page.GetContentStreams()
+	if err != nil {
+		return err
+	}
+	if len(cstreams) == 0 {
+		return nil
+	}
+	content, err := NormalizeContentStream(strings.Join(cstreams, "\n"), opts)
+	if err != nil {
+		return err
+	}
+	return page.SetContentStreams([]string{content}, opts.Encoder)
+}
+
+// normalizeObject returns the object `obj` with its real numbers rounded to
+// `precision` decimal places if positive, and written as integers if they
+// have integer values.
+func normalizeObject(obj core.PdfObject, precision int) core.PdfObject {
+	switch t := obj.(type) {
+	case *core.PdfObjectFloat:
+		val := float64(*t)
+		if precision > 0 {
+			scale := math.Pow(10, float64(precision))
+			val = math.Round(val*scale) / scale
+		}
+		if val == math.Trunc(val) && math.Abs(val) < math.MaxInt32 {
+			return core.MakeInteger(int64(val))
+		}
+		return core.MakeFloat(val)
+	case *core.PdfObjectArray:
+		elements := t.Elements()
+		for i, elem := range elements {
+			elements[i] = normalizeObject(elem, precision)
+		}
+		return core.MakeArray(elements...)
+	case *core.PdfObjectDictionary:
+		for _, key := range t.Keys() {
+			t.Set(key, normalizeObject(t.Get(key), precision))
+		}
+	}
+	return obj
+}
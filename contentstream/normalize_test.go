@@ -0,0 +1,52 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestNormalizeContentStream(t *testing.T) {
+	content := "q  1.0 0 0 1.00 72.50 -0.0 cm % move\n\r\n0.333333 g\t[3.0 1.5] 0 d" +
+		" /OC << /MCID 2.0 >> BDC BT [(a) -250.25 (b)] TJ ET EMC Q"
+	normalized, err := NormalizeContentStream(content, nil)
+	require.NoError(t, err)
+	require.Equal(t, "q\n1 0 0 1 72.5 0 cm\n0.333333 g\n[3 1.5] 0 d\n/OC <</MCID 2>> BDC\n"+
+		"BT\n[(a) -250.25 (b)] TJ\nET\nEMC\nQ\n", normalized)
+
+	normalized, err = NormalizeContentStream(content, &NormalizeOptions{Precision: 2})
+	require.NoError(t, err)
+	require.Contains(t, normalized, "\n0.33 g\n")
+
+	// Normalized content streams are stable.
+	again, err := NormalizeContentStream(normalized, &NormalizeOptions{Precision: 2})
+	require.NoError(t, err)
+	require.Equal(t, normalized, again)
+}
+
+func TestNormalizePageContentStreams(t *testing.T) {
+	page := model.NewPdfPage()
+	require.NoError(t, page.SetContentStreams([]string{"q 1 0 0 rg", "0 0 m\n1.50 1 l S Q"}, nil))
+	require.NoError(t, MergePageContentStreams(page, nil))
+	cstreams, err := page.GetContentStreams()
+	require.NoError(t, err)
+	require.Equal(t, []string{"q 1 0 0 rg\n0 0 m\n1.50 1 l S Q"}, cstreams)
+
+	require.NoError(t, page.SetContentStreams([]string{"q 1 0 0 rg", "0 0 m\n1.50 1 l S Q"}, nil))
+	require.NoError(t, NormalizePageContentStreams(page, &NormalizeOptions{Encoder: core.NewFlateEncoder()}))
+	stream, ok := core.GetStream(page.Contents)
+	require.True(t, ok)
+	filter, _ := core.GetNameVal(stream.Get("Filter"))
+	require.Equal(t, core.StreamEncodingFilterNameFlate, filter)
+	cstreams, err = page.GetContentStreams()
+	require.NoError(t, err)
+	require.Equal(t, []string{"q\n1 0 0 rg\n0 0 m\n1.5 1 l\nS\nQ\n"}, cstreams)
+}
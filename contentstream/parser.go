@@ -21,6 +21,14 @@ import (
 // ContentStreamParser represents a content stream parser for parsing content streams in PDFs.
 type ContentStreamParser struct {
 	reader *bufio.Reader
+
+	// buffer is the data read by the reader, of total size `size`.
+	buffer *bytes.Buffer
+	size   int
+
+	// offsets contains the byte offsets of the operators of the parsed
+	// operations.
+	offsets []int64
 }
 
 // NewContentStreamParser creates a new instance of the content stream parser from an input content
@@ -31,6 +39,8 @@ func NewContentStreamParser(contentStr string) *ContentStreamParser {
 
 	buffer := bytes.NewBufferString(contentStr + "\n") // Add newline at end to get last operand without EOF error.
 	parser.reader = bufio.NewReader(buffer)
+	parser.buffer = buffer
+	parser.size = buffer.Len()
 
 	return &parser
 }
@@ -54,6 +64,7 @@ func (csp *ContentStreamParser) Parse() (*ContentStreamOperations, error) {
 			if isOperand {
 				operation.Operand, _ = core.GetStringVal(obj)
 				operations = append(operations, &operation)
+				csp.offsets = append(csp.offsets, csp.offset()-int64(len(operation.Operand)))
 				break
 			} else {
 				operation.Params = append(operation.Params, obj)
@@ -72,6 +83,11 @@ func (csp *ContentStreamParser) Parse() (*ContentStreamOperations, error) {
 	}
 }
 
+// offset returns the offset of the next byte to be read.
+func (csp *ContentStreamParser) offset() int64 {
+	return int64(csp.size - csp.buffer.Len() - csp.reader.Buffered())
+}
+
 // Skip over any spaces.  Returns the number of spaces skipped and
 // an error if any.
 func (csp *ContentStreamParser) skipSpaces() (int, error) {
@@ -23,6 +23,22 @@ type GraphicsState struct {
 	ColorStroking         model.PdfColor
 	ColorNonStroking      model.PdfColor
 	CTM                   transform.Matrix
+
+	// LineWidth is the line width in user space units, set by the w operator
+	// or graphics state parameter dictionaries.
+	LineWidth float64
+
+	// DashArray and DashPhase are the line dash pattern.
+	DashArray []float64
+	DashPhase float64
+
+	// BlendMode, StrokeAlpha, FillAlpha and SoftMask are the transparency
+	// parameters, set by graphics state parameter dictionaries. SoftMask is
+	// nil if no soft mask is in effect.
+	BlendMode   model.PdfBlendMode
+	StrokeAlpha float64
+	FillAlpha   float64
+	SoftMask    *model.PdfSoftMask
 }
 
 // GraphicStateStack represents a stack of GraphicsState.
@@ -54,7 +70,15 @@ type ContentStreamProcessor struct {
 	graphicsState GraphicsState
 
 	handlers     []handlerEntry
+	rewriters    []rewriteEntry
 	currentIndex int
+
+	// initialState is the graphics state at the start of processing, if set.
+	initialState *GraphicsState
+
+	// extGStates caches the graphics state parameters loaded from the
+	// ExtGState dictionaries set by gs operators.
+	extGStates map[*core.PdfObjectDictionary]*model.PdfExtGState
 }
 
 // HandlerFunc is the function syntax that the ContentStreamProcessor handler must implement.
@@ -99,6 +123,7 @@ func NewContentStreamProcessor(ops []*ContentStreamOperation) *ContentStreamProc
 	csp.handlers = []handlerEntry{}
 	csp.currentIndex = 0
 	csp.operations = ops
+	csp.extGStates = map[*core.PdfObjectDictionary]*model.PdfExtGState{}
 
 	return &csp
 }
@@ -215,74 +240,113 @@ func (proc *ContentStreamProcessor) getInitialColor(cs model.PdfColorspace) (mod
 // Process processes the entire list of operations. Maintains the graphics state that is passed to any
 // handlers that are triggered during processing (either on specific operators or all).
 func (proc *ContentStreamProcessor) Process(resources *model.PdfPageResources) error {
-	// Initialize graphics state
+	proc.initGraphicsState()
+	for _, op := range proc.operations {
+		if err := proc.processOperation(op, resources); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetInitialGraphicsState sets the graphics state at the start of processing. By default, the
+// processing starts with the default graphics state. This allows processing the content stream of
+// a Form XObject in the graphics state in effect when the form is painted.
+func (proc *ContentStreamProcessor) SetInitialGraphicsState(gs GraphicsState) {
+	proc.initialState = &gs
+}
+
+// initGraphicsState sets the initial graphics state.
+func (proc *ContentStreamProcessor) initGraphicsState() {
+	if proc.initialState != nil {
+		proc.graphicsState = *proc.initialState
+		return
+	}
 	proc.graphicsState.ColorspaceStroking = model.NewPdfColorspaceDeviceGray()
 	proc.graphicsState.ColorspaceNonStroking = model.NewPdfColorspaceDeviceGray()
 	proc.graphicsState.ColorStroking = model.NewPdfColorDeviceGray(0)
 	proc.graphicsState.ColorNonStroking = model.NewPdfColorDeviceGray(0)
 	proc.graphicsState.CTM = transform.IdentityMatrix()
+	proc.graphicsState.LineWidth = 1
+	proc.graphicsState.DashArray = nil
+	proc.graphicsState.DashPhase = 0
+	proc.graphicsState.BlendMode = model.PdfBlendModeNormal
+	proc.graphicsState.StrokeAlpha = 1
+	proc.graphicsState.FillAlpha = 1
+	proc.graphicsState.SoftMask = nil
+}
 
-	for _, op := range proc.operations {
-		var err error
+// processOperation updates the graphics state for the operation `op` and calls the handlers
+// registered for it.
+func (proc *ContentStreamProcessor) processOperation(op *ContentStreamOperation, resources *model.PdfPageResources) error {
+	var err error
+
+	// Internal handling.
+	switch op.Operand {
+	case "q":
+		proc.graphicsStack.Push(proc.graphicsState)
+	case "Q":
+		if len(proc.graphicsStack) == 0 {
+			common.Log.Debug("WARN: invalid `Q` operator. Graphics state stack is empty. Skipping.")
+			return nil
+		}
+		proc.graphicsState = proc.graphicsStack.Pop()
+
+	// Color operations (Table 74 p. 179)
+	case "CS":
+		err = proc.handleCommand_CS(op, resources)
+	case "cs":
+		err = proc.handleCommand_cs(op, resources)
+	case "SC":
+		err = proc.handleCommand_SC(op, resources)
+	case "SCN":
+		err = proc.handleCommand_SCN(op, resources)
+	case "sc":
+		err = proc.handleCommand_sc(op, resources)
+	case "scn":
+		err = proc.handleCommand_scn(op, resources)
+	case "G":
+		err = proc.handleCommand_G(op, resources)
+	case "g":
+		err = proc.handleCommand_g(op, resources)
+	case "RG":
+		err = proc.handleCommand_RG(op, resources)
+	case "rg":
+		err = proc.handleCommand_rg(op, resources)
+	case "K":
+		err = proc.handleCommand_K(op, resources)
+	case "k":
+		err = proc.handleCommand_k(op, resources)
+	case "cm":
+		err = proc.handleCommand_cm(op, resources)
+
+	// Graphics state operations (Table 57 p. 127)
+	case "w":
+		err = proc.handleCommand_w(op, resources)
+	case "d":
+		err = proc.handleCommand_d(op, resources)
+	case "gs":
+		err = proc.handleCommand_gs(op, resources)
+	}
+	if err != nil {
+		common.Log.Debug("Processor handling error (%s): %v", op.Operand, err)
+		common.Log.Debug("Operand: %#v", op.Operand)
+		return err
+	}
 
-		// Internal handling.
-		switch op.Operand {
-		case "q":
-			proc.graphicsStack.Push(proc.graphicsState)
-		case "Q":
-			if len(proc.graphicsStack) == 0 {
-				common.Log.Debug("WARN: invalid `Q` operator. Graphics state stack is empty. Skipping.")
-				continue
-			}
-			proc.graphicsState = proc.graphicsStack.Pop()
-
-		// Color operations (Table 74 p. 179)
-		case "CS":
-			err = proc.handleCommand_CS(op, resources)
-		case "cs":
-			err = proc.handleCommand_cs(op, resources)
-		case "SC":
-			err = proc.handleCommand_SC(op, resources)
-		case "SCN":
-			err = proc.handleCommand_SCN(op, resources)
-		case "sc":
-			err = proc.handleCommand_sc(op, resources)
-		case "scn":
-			err = proc.handleCommand_scn(op, resources)
-		case "G":
-			err = proc.handleCommand_G(op, resources)
-		case "g":
-			err = proc.handleCommand_g(op, resources)
-		case "RG":
-			err = proc.handleCommand_RG(op, resources)
-		case "rg":
-			err = proc.handleCommand_rg(op, resources)
-		case "K":
-			err = proc.handleCommand_K(op, resources)
-		case "k":
-			err = proc.handleCommand_k(op, resources)
-		case "cm":
-			err = proc.handleCommand_cm(op, resources)
+	// Check if have external handler also, and process if so.
+	for _, entry := range proc.handlers {
+		var err error
+		if entry.Condition.All() {
+			err = entry.Handler(op, proc.graphicsState, resources)
+		} else if entry.Condition.Operand() && op.Operand == entry.Operand {
+			err = entry.Handler(op, proc.graphicsState, resources)
 		}
 		if err != nil {
-			common.Log.Debug("Processor handling error (%s): %v", op.Operand, err)
-			common.Log.Debug("Operand: %#v", op.Operand)
+			common.Log.Debug("Processor handler error: %v", err)
 			return err
 		}
-
-		// Check if have external handler also, and process if so.
-		for _, entry := range proc.handlers {
-			var err error
-			if entry.Condition.All() {
-				err = entry.Handler(op, proc.graphicsState, resources)
-			} else if entry.Condition.Operand() && op.Operand == entry.Operand {
-				err = entry.Handler(op, proc.graphicsState, resources)
-			}
-			if err != nil {
-				common.Log.Debug("Processor handler error: %v", err)
-				return err
-			}
-		}
 	}
 
 	return nil
@@ -589,3 +653,142 @@ func (proc *ContentStreamProcessor) handleCommand_cm(op *ContentStreamOperation,
 
 	return nil
 }
+
+// w: sets the line width. Invalid operands are ignored.
+func (proc *ContentStreamProcessor) handleCommand_w(op *ContentStreamOperation,
+	resources *model.PdfPageResources) error {
+	if len(op.Params) != 1 {
+		common.Log.Debug("ERROR: Invalid number of parameters for w: %d", len(op.Params))
+		return nil
+	}
+
+	width, err := core.GetNumberAsFloat(op.Params[0])
+	if err != nil {
+		common.Log.Debug("ERROR: Invalid line width for w: %v", op.Params[0])
+		return nil
+	}
+	proc.graphicsState.LineWidth = width
+
+	return nil
+}
+
+// d: sets the line dash pattern. Invalid operands are ignored.
+func (proc *ContentStreamProcessor) handleCommand_d(op *ContentStreamOperation,
+	resources *model.PdfPageResources) error {
+	if len(op.Params) != 2 {
+		common.Log.Debug("ERROR: Invalid number of parameters for d: %d", len(op.Params))
+		return nil
+	}
+
+	arr, ok := core.GetArray(op.Params[0])
+	if !ok {
+		common.Log.Debug("ERROR: Invalid dash array for d: %v", op.Params[0])
+		return nil
+	}
+	dashes, err := arr.ToFloat64Array()
+	if err != nil {
+		common.Log.Debug("ERROR: Invalid dash array for d: %v", arr)
+		return nil
+	}
+	phase, err := core.GetNumberAsFloat(op.Params[1])
+	if err != nil {
+		common.Log.Debug("ERROR: Invalid dash phase for d: %v", op.Params[1])
+		return nil
+	}
+	proc.graphicsState.DashArray = dashes
+	proc.graphicsState.DashPhase = phase
+
+	return nil
+}
+
+// gs: sets the parameters of the graphics state parameter dictionary whose
+// name is the operand. Invalid operands and missing or invalid dictionaries
+// are ignored.
+func (proc *ContentStreamProcessor) handleCommand_gs(op *ContentStreamOperation,
+	resources *model.PdfPageResources) error {
+	if len(op.Params) != 1 {
+		common.Log.Debug("ERROR: Invalid number of parameters for gs: %d", len(op.Params))
+		return nil
+	}
+
+	name, ok := core.GetName(op.Params[0])
+	if !ok {
+		common.Log.Debug("ERROR: Invalid ExtGState name for gs: %v", op.Params[0])
+		return nil
+	}
+	if resources == nil {
+		common.Log.Debug("ERROR: ExtGState %s used without resources", *name)
+		return nil
+	}
+	egs, err := proc.getExtGState(*name, resources)
+	if err != nil {
+		common.Log.Debug("ERROR: Unable to load ExtGState %s: %v", *name, err)
+		return nil
+	}
+
+	gs := &proc.graphicsState
+	if egs.LW != nil {
+		gs.LineWidth = *egs.LW
+	}
+	if egs.D != nil {
+		gs.DashArray = egs.D.Array
+		gs.DashPhase = egs.D.Phase
+	}
+	if egs.BM != "" {
+		gs.BlendMode = egs.BM
+	}
+	if egs.CA != nil {
+		gs.StrokeAlpha = *egs.CA
+	}
+	if egs.Ca != nil {
+		gs.FillAlpha = *egs.Ca
+	}
+	if egs.SMask != nil || egs.SMaskNone {
+		gs.SoftMask = egs.SMask
+	}
+
+	return nil
+}
+
+// extGStateParams contains the entries of the ExtGState dictionaries which are
+// tracked in the graphics state.
+var extGStateParams = []core.PdfObjectName{"LW", "D", "BM", "CA", "ca"}
+
+// getExtGState returns the graphics state parameters of the ExtGState `name`
+// of `resources`. Only the entries tracked in the graphics state are loaded,
+// and an invalid SMask entry is ignored. The parameters are loaded once per
+// ExtGState dictionary.
+func (proc *ContentStreamProcessor) getExtGState(name core.PdfObjectName,
+	resources *model.PdfPageResources) (*model.PdfExtGState, error) {
+	obj, ok := resources.GetExtGState(name)
+	if !ok {
+		return nil, fmt.Errorf("ExtGState %s not found", name)
+	}
+	dict, ok := core.GetDict(obj)
+	if !ok {
+		return nil, fmt.Errorf("ExtGState not a dictionary (%T)", obj)
+	}
+	if egs, ok := proc.extGStates[dict]; ok {
+		return egs, nil
+	}
+
+	params := core.MakeDict()
+	for _, key := range extGStateParams {
+		params.SetIfNotNil(key, dict.Get(key))
+	}
+	egs, err := model.NewPdfExtGStateFromPdfObject(params)
+	if err != nil {
+		return nil, err
+	}
+	if obj := dict.Get("SMask"); obj != nil {
+		mask, err := model.NewPdfSoftMaskFromPdfObject(obj)
+		if err != nil {
+			common.Log.Debug("ERROR: Invalid ExtGState %s soft mask: %v", name, err)
+		} else {
+			egs.SMask = mask
+			egs.SMaskNone = mask == nil
+		}
+	}
+	proc.extGStates[dict] = egs
+	return egs, nil
+}
@@ -0,0 +1,117 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestProcessorGraphicsStateParameters(t *testing.T) {
+	lw, alpha := 4.0, 0.25
+	gs := model.NewPdfExtGState()
+	gs.LW = &lw
+	gs.BM = model.PdfBlendModeScreen
+	gs.Ca = &alpha
+	resources := model.NewPdfPageResources()
+	require.NoError(t, resources.SetExtGStateByName("GS1", gs))
+
+	content := "0 0 m S q 2 w [3 1] 1 d 0 0 m S /GS1 gs 0 0 m S /GS2 gs 0 0 m S Q 0 0 m S"
+	ops, err := NewContentStreamParser(content).Parse()
+	require.NoError(t, err)
+
+	var states []GraphicsState
+	proc := NewContentStreamProcessor(*ops)
+	proc.AddHandler(HandlerConditionEnumOperand, "S",
+		func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) error {
+			states = append(states, gs)
+			return nil
+		})
+	require.NoError(t, proc.Process(resources))
+	require.Len(t, states, 5)
+
+	// Default graphics state.
+	for _, i := range []int{0, 4} {
+		require.Equal(t, 1.0, states[i].LineWidth)
+		require.Nil(t, states[i].DashArray)
+		require.Equal(t, model.PdfBlendModeNormal, states[i].BlendMode)
+		require.Equal(t, 1.0, states[i].StrokeAlpha)
+		require.Equal(t, 1.0, states[i].FillAlpha)
+	}
+
+	require.Equal(t, 2.0, states[1].LineWidth)
+	require.Equal(t, []float64{3, 1}, states[1].DashArray)
+	require.Equal(t, 1.0, states[1].DashPhase)
+
+	// The parameters which are not set by the ExtGState are unchanged, and
+	// the missing ExtGState GS2 is ignored.
+	for _, i := range []int{2, 3} {
+		require.Equal(t, 4.0, states[i].LineWidth)
+		require.Equal(t, []float64{3, 1}, states[i].DashArray)
+		require.Equal(t, model.PdfBlendModeScreen, states[i].BlendMode)
+		require.Equal(t, 1.0, states[i].StrokeAlpha)
+		require.Equal(t, 0.25, states[i].FillAlpha)
+	}
+}
+
+func TestProcessorInvalidGraphicsStateOperands(t *testing.T) {
+	// The invalid w, d and gs operands are ignored, keeping the current
+	// graphics state, and do not abort the processing.
+	content := "2 w [3 1] 1 d w (x) w 1 2 d [3 1] d (x) gs /A /B gs 0 0 m S"
+	ops, err := NewContentStreamParser(content).Parse()
+	require.NoError(t, err)
+
+	var states []GraphicsState
+	proc := NewContentStreamProcessor(*ops)
+	proc.AddHandler(HandlerConditionEnumOperand, "S",
+		func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) error {
+			states = append(states, gs)
+			return nil
+		})
+	require.NoError(t, proc.Process(model.NewPdfPageResources()))
+	require.Len(t, states, 1)
+	require.Equal(t, 2.0, states[0].LineWidth)
+	require.Equal(t, []float64{3, 1}, states[0].DashArray)
+	require.Equal(t, 1.0, states[0].DashPhase)
+	require.Equal(t, 1.0, states[0].FillAlpha)
+}
+
+func TestProcessorInvalidExtGStateEntries(t *testing.T) {
+	// The invalid Font and SMask entries of the ExtGState do not prevent the
+	// other parameters from being set.
+	dict := core.MakeDict()
+	dict.Set("LW", core.MakeFloat(3))
+	dict.Set("CA", core.MakeFloat(0.5))
+	dict.Set("ca", core.MakeFloat(0.25))
+	dict.Set("Font", core.MakeArray(core.MakeInteger(1), core.MakeInteger(12)))
+	dict.Set("SMask", core.MakeName("Invalid"))
+	resources := model.NewPdfPageResources()
+	require.NoError(t, resources.AddExtGState("GS1", dict))
+
+	content := "/GS1 gs 0 0 m S 1 w /GS1 gs 0 0 m S"
+	ops, err := NewContentStreamParser(content).Parse()
+	require.NoError(t, err)
+
+	var states []GraphicsState
+	proc := NewContentStreamProcessor(*ops)
+	proc.AddHandler(HandlerConditionEnumOperand, "S",
+		func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) error {
+			states = append(states, gs)
+			return nil
+		})
+	require.NoError(t, proc.Process(resources))
+	require.Len(t, states, 2)
+	for _, gs := range states {
+		require.Equal(t, 3.0, gs.LineWidth)
+		require.Equal(t, 0.5, gs.StrokeAlpha)
+		require.Equal(t, 0.25, gs.FillAlpha)
+		require.Nil(t, gs.SoftMask)
+	}
+}
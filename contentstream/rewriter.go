@@ -0,0 +1,113 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// RewriteFunc is the function syntax that the ContentStreamProcessor rewrite handlers must implement.
+// The handler returns the operations replacing `op` in the rewritten content stream: returning `op`
+// alone keeps the operation unchanged, while returning no operations removes it. The graphics state
+// `gs` is the state after processing `op` in the original content stream.
+type RewriteFunc func(op *ContentStreamOperation, gs GraphicsState,
+	resources *model.PdfPageResources) ([]*ContentStreamOperation, error)
+
+type rewriteEntry struct {
+	Condition HandlerConditionEnum
+	Operand   string
+	Handler   RewriteFunc
+}
+
+// AddRewriteHandler adds a new rewrite `handler` of type `condition` for `operand`, used by Rewrite.
+// The rewrite handlers form a pipeline, called in the order in which they were added: each handler
+// is called for the operations emitted by the previous handlers.
+func (proc *ContentStreamProcessor) AddRewriteHandler(condition HandlerConditionEnum, operand string,
+	handler RewriteFunc) {
+	entry := rewriteEntry{}
+	entry.Condition = condition
+	entry.Operand = operand
+	entry.Handler = handler
+	proc.rewriters = append(proc.rewriters, entry)
+}
+
+// Rewrite processes the entire list of operations like Process, and returns the operations emitted by
+// the rewrite handlers. The operations for which no rewrite handler is triggered are kept unchanged.
+// The processed operations are not modified, unless modified in place by the handlers.
+func (proc *ContentStreamProcessor) Rewrite(resources *model.PdfPageResources) (*ContentStreamOperations, error) {
+	proc.initGraphicsState()
+
+	rewritten := make(ContentStreamOperations, 0, len(proc.operations))
+	for _, op := range proc.operations {
+		if err := proc.processOperation(op, resources); err != nil {
+			return nil, err
+		}
+
+		ops := []*ContentStreamOperation{op}
+		for _, entry := range proc.rewriters {
+			var emitted []*ContentStreamOperation
+			for _, op := range ops {
+				if !entry.Condition.All() && !(entry.Condition.Operand() && op.Operand == entry.Operand) {
+					emitted = append(emitted, op)
+					continue
+				}
+
+				handlerOps, err := entry.Handler(op, proc.graphicsState, resources)
+				if err != nil {
+					common.Log.Debug("Processor rewrite handler error: %v", err)
+					return nil, err
+				}
+				emitted = append(emitted, handlerOps...)
+			}
+			ops = emitted
+		}
+		rewritten = append(rewritten, ops...)
+	}
+
+	return &rewritten, nil
+}
+
+// RewritePageContent rewrites the content streams of the page `page` using the rewrite handler
+// `handler`, called for all the operations. The rewritten content replaces the content of the page.
+func RewritePageContent(page *model.PdfPage, handler RewriteFunc) error {
+	if page == nil {
+		return errors.New("page cannot be nil")
+	}
+	if handler == nil {
+		return errors.New("rewrite handler cannot be nil")
+	}
+
+	ops, err := pageOperations(page)
+	if err != nil {
+		return err
+	}
+	proc := NewContentStreamProcessor(*ops)
+	proc.AddRewriteHandler(HandlerConditionEnumAllOperands, "", handler)
+	rewritten, err := proc.Rewrite(page.Resources)
+	if err != nil {
+		return err
+	}
+	return setPageOperations(page, rewritten)
+}
+
+// pageOperations returns the parsed content stream operations of the page `page`.
+func pageOperations(page *model.PdfPage) (*ContentStreamOperations, error) {
+	content, err := page.GetAllContentStreams()
+	if err != nil {
+		return nil, err
+	}
+	return NewContentStreamParser(content).Parse()
+}
+
+// setPageOperations replaces the content streams of the page `page` with a stream containing the
+// operations `ops`.
+func setPageOperations(page *model.PdfPage, ops *ContentStreamOperations) error {
+	return page.SetContentStreams([]string{string(ops.Bytes())}, core.NewFlateEncoder())
+}
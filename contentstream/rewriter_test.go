@@ -0,0 +1,110 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// rewriteContent rewrites `content` using a processor set up by `setup`.
+func rewriteContent(t *testing.T, content string, setup func(proc *ContentStreamProcessor)) string {
+	ops, err := NewContentStreamParser(content).Parse()
+	require.NoError(t, err)
+	proc := NewContentStreamProcessor(*ops)
+	setup(proc)
+	rewritten, err := proc.Rewrite(model.NewPdfPageResources())
+	require.NoError(t, err)
+	return rewritten.String()
+}
+
+func TestRewriteColorRemap(t *testing.T) {
+	content := "1 0 0 rg 0 0 10 10 re f 0 0 1 RG 0 0 m 10 10 l S"
+	rewritten := rewriteContent(t, content, func(proc *ContentStreamProcessor) {
+		// Convert the RGB colors to CMYK, using the color tracked by the graphics state.
+		toCMYK := func(color model.PdfColor) []core.PdfObject {
+			rgb := color.(*model.PdfColorDeviceRGB)
+			c, m, y, k := 1-rgb.R(), 1-rgb.G(), 1-rgb.B(), 0.0
+			return makeParamsFromFloats([]float64{c, m, y, k})
+		}
+		proc.AddRewriteHandler(HandlerConditionEnumOperand, "rg",
+			func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) ([]*ContentStreamOperation, error) {
+				return []*ContentStreamOperation{{Operand: "k", Params: toCMYK(gs.ColorNonStroking)}}, nil
+			})
+		proc.AddRewriteHandler(HandlerConditionEnumOperand, "RG",
+			func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) ([]*ContentStreamOperation, error) {
+				return []*ContentStreamOperation{{Operand: "K", Params: toCMYK(gs.ColorStroking)}}, nil
+			})
+	})
+	require.Equal(t, "0 1 1 0 k\n0 0 10 10 re\nf\n1 1 0 0 K\n0 0 m\n10 10 l\nS\n", rewritten)
+}
+
+func TestRewritePipeline(t *testing.T) {
+	content := "q 1 0 0 1 5 5 cm /Im1 Do Q 0 0 m 10 20 l S"
+	rewritten := rewriteContent(t, content, func(proc *ContentStreamProcessor) {
+		// Strip the XObjects.
+		proc.AddRewriteHandler(HandlerConditionEnumOperand, "Do",
+			func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) ([]*ContentStreamOperation, error) {
+				return nil, nil
+			})
+		// Shift the path coordinates.
+		proc.AddRewriteHandler(HandlerConditionEnumAllOperands, "",
+			func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) ([]*ContentStreamOperation, error) {
+				if op.Operand != "m" && op.Operand != "l" {
+					return []*ContentStreamOperation{op}, nil
+				}
+				vals, err := core.GetNumbersAsFloat(op.Params)
+				if err != nil {
+					return nil, err
+				}
+				shifted := &ContentStreamOperation{
+					Operand: op.Operand,
+					Params:  makeParamsFromFloats([]float64{vals[0] + 100, vals[1] + 200}),
+				}
+				return []*ContentStreamOperation{shifted}, nil
+			})
+		// Emit several operations: wrap the strokes in q/Q.
+		proc.AddRewriteHandler(HandlerConditionEnumOperand, "S",
+			func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) ([]*ContentStreamOperation, error) {
+				width := &ContentStreamOperation{Operand: "w", Params: makeParamsFromFloats([]float64{2})}
+				return []*ContentStreamOperation{{Operand: "q"}, width, op, {Operand: "Q"}}, nil
+			})
+	})
+	require.Equal(t, "q\n1 0 0 1 5 5 cm\nQ\n100 200 m\n110 220 l\nq\n2 w\nS\nQ\n", rewritten)
+}
+
+func TestRewritePageContent(t *testing.T) {
+	page := model.NewPdfPage()
+	require.NoError(t, page.AddContentStreamByString("0 0 m 10 10 l S"))
+	require.NoError(t, page.AddContentStreamByString("BT (Text) Tj ET"))
+
+	// Strip the text objects.
+	var inText bool
+	err := RewritePageContent(page,
+		func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) ([]*ContentStreamOperation, error) {
+			switch op.Operand {
+			case "BT":
+				inText = true
+			case "ET":
+				inText = false
+				return nil, nil
+			}
+			if inText {
+				return nil, nil
+			}
+			return []*ContentStreamOperation{op}, nil
+		})
+	require.NoError(t, err)
+
+	content, err := page.GetAllContentStreams()
+	require.NoError(t, err)
+	require.Equal(t, "0 0 m\n10 10 l\nS\n", content)
+	require.Error(t, RewritePageContent(page, nil))
+}
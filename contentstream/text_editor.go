@@ -0,0 +1,634 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// ErrUnencodableText is returned when a text cannot be encoded using the font
+// of a text showing operation.
+var ErrUnencodableText = errors.New("text cannot be encoded using the font")
+
+// TextOperation represents a text showing operation (Tj, TJ, ' or ") located
+// by a TextEditor.
+type TextOperation struct {
+	// Text is the decoded text shown by the operation, taking the edits
+	// into account.
+	Text string
+
+	// Font is the font used by the operation, taking the edits into account.
+	// It is nil if the font used by the operation cannot be loaded.
+	Font *model.PdfFont
+
+	// FontSize is the font size used by the operation, taking the edits into
+	// account.
+	FontSize float64
+
+	// Index is the index of the operation in the content stream operations.
+	Index int
+
+	op       *ContentStreamOperation
+	fontName core.PdfObjectName
+	deleted  bool
+	modified bool
+
+	// origFontName, origFontSize and origFont are the text font parameters
+	// of the operation before edits.
+	origFontName core.PdfObjectName
+	origFontSize float64
+	origFont     *model.PdfFont
+
+	// color is the non-stroking color set by a restyle. colorOps are the
+	// operations restoring the non-stroking color in effect before the
+	// operation.
+	color    model.PdfColor
+	colorOps []*ContentStreamOperation
+}
+
+// Operation returns the original content stream operation.
+func (to *TextOperation) Operation() *ContentStreamOperation {
+	return to.op
+}
+
+// IsDeleted returns true if the operation was deleted.
+func (to *TextOperation) IsDeleted() bool {
+	return to.deleted
+}
+
+// TextStyle represents the style applied to text showing operations by
+// TextEditor.Restyle. The zero values of the fields leave the corresponding
+// style attributes unchanged.
+type TextStyle struct {
+	// Font replaces the font of the operations. The text is re-encoded using
+	// the font.
+	Font *model.PdfFont
+
+	// FontSize replaces the font size of the operations.
+	FontSize float64
+
+	// Color replaces the fill color of the text. Only DeviceGray, DeviceRGB
+	// and DeviceCMYK colors are supported.
+	Color model.PdfColor
+}
+
+// TextEditor locates the text showing operations of a content stream, using
+// the fonts of the resources to decode them, and allows replacing, deleting or
+// restyling them. The edits are applied to the operations returned by
+// Operations, the source operations are not modified.
+//
+// Each text showing operation is edited as a whole: text split across several
+// operations cannot be matched, and replaced TJ operations lose their
+// individual glyph positioning. Subsequent text on the same line is shifted
+// when the width of a replaced text differs from the original.
+type TextEditor struct {
+	ops       ContentStreamOperations
+	resources *model.PdfPageResources
+	texts     []*TextOperation
+
+	// fonts contains the fonts loaded from the resources.
+	fonts map[core.PdfObjectName]*model.PdfFont
+
+	// fontNames contains the resource names of the fonts added to the
+	// resources by restyles.
+	fontNames map[*model.PdfFont]core.PdfObjectName
+}
+
+// textEditorState represents the part of the graphics state tracked by the
+// text editor.
+type textEditorState struct {
+	fontName core.PdfObjectName
+	fontSize float64
+
+	// csOp and colorOp are the last operations setting the non-stroking
+	// color space and color.
+	csOp    *ContentStreamOperation
+	colorOp *ContentStreamOperation
+}
+
+// NewTextEditor returns a new TextEditor for the content stream operations
+// `ops` using the resources `resources`. The fonts added by restyles are
+// added to the resources.
+func NewTextEditor(ops *ContentStreamOperations, resources *model.PdfPageResources) (*TextEditor, error) {
+	if ops == nil {
+		return nil, errors.New("operations cannot be nil")
+	}
+	if resources == nil {
+		resources = model.NewPdfPageResources()
+	}
+
+	editor := &TextEditor{
+		ops:       *ops,
+		resources: resources,
+		fonts:     map[core.PdfObjectName]*model.PdfFont{},
+		fontNames: map[*model.PdfFont]core.PdfObjectName{},
+	}
+	indices := make(map[*ContentStreamOperation]int, len(editor.ops))
+	for i, op := range editor.ops {
+		indices[op] = i
+	}
+
+	var state textEditorState
+	var stack []textEditorState
+	proc := NewContentStreamProcessor(editor.ops)
+	proc.AddHandler(HandlerConditionEnumAllOperands, "",
+		func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) error {
+			switch op.Operand {
+			case "q":
+				stack = append(stack, state)
+			case "Q":
+				if len(stack) > 0 {
+					state = stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+				}
+			case "Tf":
+				if len(op.Params) != 2 {
+					return errors.New("invalid number of parameters")
+				}
+				name, ok := core.GetName(op.Params[0])
+				if !ok {
+					return errors.New("font name is not a name")
+				}
+				size, err := core.GetNumberAsFloat(op.Params[1])
+				if err != nil {
+					return err
+				}
+				state.fontName = *name
+				state.fontSize = size
+			case "cs":
+				state.csOp = op
+				state.colorOp = nil
+			case "g", "rg", "k":
+				state.csOp = nil
+				state.colorOp = op
+			case "sc", "scn":
+				state.colorOp = op
+			case "Tj", "TJ", "'", `"`:
+				text, err := editor.newTextOperation(op, indices[op], state)
+				if err != nil {
+					return err
+				}
+				editor.texts = append(editor.texts, text)
+			}
+			return nil
+		})
+	if err := proc.Process(resources); err != nil {
+		return nil, err
+	}
+	return editor, nil
+}
+
+// newTextOperation returns the text operation for the text showing operation
+// `op` having the index `index`, in the graphics state `state`.
+func (e *TextEditor) newTextOperation(op *ContentStreamOperation, index int,
+	state textEditorState) (*TextOperation, error) {
+	font := e.loadFont(state.fontName)
+	to := &TextOperation{
+		Font:         font,
+		FontSize:     state.fontSize,
+		Index:        index,
+		op:           op,
+		fontName:     state.fontName,
+		origFontName: state.fontName,
+		origFontSize: state.fontSize,
+		origFont:     font,
+	}
+
+	// Record the operations restoring the current non-stroking color.
+	if state.csOp != nil {
+		to.colorOps = append(to.colorOps, state.csOp)
+	}
+	if state.colorOp != nil {
+		to.colorOps = append(to.colorOps, state.colorOp)
+	}
+	if len(to.colorOps) == 0 {
+		to.colorOps = append(to.colorOps, &ContentStreamOperation{
+			Operand: "g",
+			Params:  []core.PdfObject{core.MakeFloat(0)},
+		})
+	}
+
+	strs, err := textOperationStrings(op)
+	if err != nil {
+		return nil, err
+	}
+	if font == nil {
+		return to, nil
+	}
+	var sb strings.Builder
+	for _, str := range strs {
+		text, _, numMisses := font.CharcodeBytesToUnicode(str.Bytes())
+		if numMisses > 0 {
+			common.Log.Debug("Text editor: %d characters of %q not decoded", numMisses, text)
+		}
+		sb.WriteString(text)
+	}
+	to.Text = sb.String()
+	return to, nil
+}
+
+// loadFont returns the font of the resources having the name `name`, or nil
+// if the font cannot be loaded.
+func (e *TextEditor) loadFont(name core.PdfObjectName) *model.PdfFont {
+	if font, ok := e.fonts[name]; ok {
+		return font
+	}
+	var font *model.PdfFont
+	if obj, ok := e.resources.GetFontByName(name); ok {
+		var err error
+		font, err = model.NewPdfFontFromPdfObject(obj)
+		if err != nil {
+			common.Log.Debug("ERROR: unable to load font %s: %v", name, err)
+			font = nil
+		}
+	} else {
+		common.Log.Debug("Text editor: font %s not found in resources", name)
+	}
+	e.fonts[name] = font
+	return font
+}
+
+// textOperationStrings returns the strings shown by the text showing
+// operation `op`.
+func textOperationStrings(op *ContentStreamOperation) ([]*core.PdfObjectString, error) {
+	var param core.PdfObject
+	switch op.Operand {
+	case "Tj", "TJ", "'":
+		if len(op.Params) != 1 {
+			return nil, fmt.Errorf("invalid number of %s parameters", op.Operand)
+		}
+		param = op.Params[0]
+	case `"`:
+		if len(op.Params) != 3 {
+			return nil, fmt.Errorf("invalid number of %s parameters", op.Operand)
+		}
+		param = op.Params[2]
+	}
+
+	if op.Operand == "TJ" {
+		arr, ok := core.GetArray(param)
+		if !ok {
+			return nil, errors.New("TJ parameter is not an array")
+		}
+		var strs []*core.PdfObjectString
+		for _, obj := range arr.Elements() {
+			if str, ok := core.GetString(obj); ok {
+				strs = append(strs, str)
+			}
+		}
+		return strs, nil
+	}
+
+	str, ok := core.GetString(param)
+	if !ok {
+		return nil, fmt.Errorf("%s parameter is not a string", op.Operand)
+	}
+	return []*core.PdfObjectString{str}, nil
+}
+
+// TextOperations returns the text showing operations of the content stream,
+// in content stream order.
+func (e *TextEditor) TextOperations() []*TextOperation {
+	return e.texts
+}
+
+// Find returns the text showing operations whose text contains `text`.
+// Deleted operations are skipped.
+func (e *TextEditor) Find(text string) []*TextOperation {
+	var found []*TextOperation
+	for _, to := range e.texts {
+		if !to.deleted && strings.Contains(to.Text, text) {
+			found = append(found, to)
+		}
+	}
+	return found
+}
+
+// SetText replaces the text of the operation `to` with `text`, encoded using
+// the font of the operation. ErrUnencodableText is returned if the font has no
+// glyphs for some characters of the text, which is common for subset fonts.
+func (e *TextEditor) SetText(to *TextOperation, text string) error {
+	if to.Font == nil {
+		return errors.New("font of the operation is not available")
+	}
+	if _, err := encodeText(to.Font, text); err != nil {
+		return err
+	}
+	to.Text = text
+	to.modified = true
+	return nil
+}
+
+// SetTextWithFont replaces the text of the operation `to` with `text`, encoded
+// using the font `font`. The font size of the operation is kept.
+func (e *TextEditor) SetTextWithFont(to *TextOperation, text string, font *model.PdfFont) error {
+	if font == nil {
+		return errors.New("font cannot be nil")
+	}
+	if _, err := encodeText(font, text); err != nil {
+		return err
+	}
+	if err := e.setFont(to, font); err != nil {
+		return err
+	}
+	to.Text = text
+	to.modified = true
+	return nil
+}
+
+// Delete deletes the operation `to`. The line moves performed by the ' and "
+// operators are kept.
+func (e *TextEditor) Delete(to *TextOperation) {
+	to.deleted = true
+}
+
+// Restyle applies the style `style` to the operation `to`. The text state and
+// the color are restored after the operation, so that the following
+// operations are not affected.
+func (e *TextEditor) Restyle(to *TextOperation, style TextStyle) error {
+	if style.Color != nil {
+		if _, err := colorOperation(style.Color); err != nil {
+			return err
+		}
+		to.color = style.Color
+		to.modified = true
+	}
+	if style.Font != nil {
+		if to.Font == nil {
+			return errors.New("text of the operation cannot be decoded")
+		}
+		if _, err := encodeText(style.Font, to.Text); err != nil {
+			return err
+		}
+		if err := e.setFont(to, style.Font); err != nil {
+			return err
+		}
+		to.modified = true
+	}
+	if style.FontSize > 0 {
+		to.FontSize = style.FontSize
+		to.modified = true
+	}
+	return nil
+}
+
+// ReplaceAll replaces the occurrences of `old` with `new` in the text showing
+// operations and returns the number of modified operations. The new text is
+// encoded using the fonts of the operations. If a font cannot encode the new
+// text and `font` is not nil, `font` is used to encode it instead, otherwise
+// ErrUnencodableText is returned.
+func (e *TextEditor) ReplaceAll(old, new string, font *model.PdfFont) (int, error) {
+	if old == "" {
+		return 0, errors.New("replaced text cannot be empty")
+	}
+
+	var count int
+	for _, to := range e.Find(old) {
+		text := strings.Replace(to.Text, old, new, -1)
+		err := e.SetText(to, text)
+		if err == ErrUnencodableText && font != nil {
+			err = e.SetTextWithFont(to, text, font)
+		}
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// DeleteAll deletes the text showing operations whose text contains `text`
+// and returns the number of deleted operations.
+func (e *TextEditor) DeleteAll(text string) int {
+	found := e.Find(text)
+	for _, to := range found {
+		e.Delete(to)
+	}
+	return len(found)
+}
+
+// setFont sets the font of the operation `to`, adding the font to the
+// resources if needed.
+func (e *TextEditor) setFont(to *TextOperation, font *model.PdfFont) error {
+	if font == to.origFont {
+		to.Font = font
+		to.fontName = to.origFontName
+		return nil
+	}
+
+	name, ok := e.fontNames[font]
+	if !ok {
+		for i := 1; ; i++ {
+			name = core.PdfObjectName(fmt.Sprintf("EditF%d", i))
+			if !e.resources.HasFontByName(name) {
+				break
+			}
+		}
+		if err := e.resources.SetFontByName(name, font.ToPdfObject()); err != nil {
+			return err
+		}
+		e.fontNames[font] = name
+		e.fonts[name] = font
+	}
+	to.Font = font
+	to.fontName = name
+	return nil
+}
+
+// Operations returns the content stream operations with the edits applied.
+func (e *TextEditor) Operations() (*ContentStreamOperations, error) {
+	edits := make(map[int]*TextOperation)
+	for _, to := range e.texts {
+		if to.deleted || to.modified {
+			edits[to.Index] = to
+		}
+	}
+
+	ops := make(ContentStreamOperations, 0, len(e.ops))
+	for i, op := range e.ops {
+		to, ok := edits[i]
+		if !ok {
+			ops = append(ops, op)
+			continue
+		}
+		edited, err := to.operations()
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, edited...)
+	}
+	return &ops, nil
+}
+
+// operations returns the content stream operations replacing the edited
+// operation `to`.
+func (to *TextOperation) operations() ([]*ContentStreamOperation, error) {
+	op := to.op
+	if to.deleted {
+		switch op.Operand {
+		case "'":
+			return []*ContentStreamOperation{{Operand: "T*"}}, nil
+		case `"`:
+			return []*ContentStreamOperation{
+				{Operand: "Tw", Params: []core.PdfObject{op.Params[0]}},
+				{Operand: "Tc", Params: []core.PdfObject{op.Params[1]}},
+				{Operand: "T*"},
+			}, nil
+		}
+		return nil, nil
+	}
+
+	var ops []*ContentStreamOperation
+	fontChanged := to.fontName != to.origFontName || to.FontSize != to.origFontSize
+	if fontChanged {
+		ops = append(ops, makeTfOperation(to.fontName, to.FontSize))
+	}
+	if to.color != nil {
+		colorOp, err := colorOperation(to.color)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, colorOp)
+	}
+
+	// Re-encode the text if it or the font changed.
+	showOp := op
+	if to.Font != nil && (to.Font != to.origFont || to.textChanged()) {
+		encoded, err := encodeText(to.Font, to.Text)
+		if err != nil {
+			return nil, err
+		}
+		str := core.MakeStringFromBytes(encoded)
+		switch op.Operand {
+		case "'":
+			showOp = &ContentStreamOperation{Operand: "'", Params: []core.PdfObject{str}}
+		case `"`:
+			showOp = &ContentStreamOperation{
+				Operand: `"`,
+				Params:  []core.PdfObject{op.Params[0], op.Params[1], str},
+			}
+		default:
+			showOp = &ContentStreamOperation{Operand: "Tj", Params: []core.PdfObject{str}}
+		}
+	}
+	ops = append(ops, showOp)
+
+	if fontChanged {
+		ops = append(ops, makeTfOperation(to.origFontName, to.origFontSize))
+	}
+	if to.color != nil {
+		ops = append(ops, to.colorOps...)
+	}
+	return ops, nil
+}
+
+// textChanged returns true if the text of the operation was replaced.
+func (to *TextOperation) textChanged() bool {
+	if to.origFont == nil {
+		return true
+	}
+	strs, err := textOperationStrings(to.op)
+	if err != nil {
+		return true
+	}
+	var sb strings.Builder
+	for _, str := range strs {
+		text, _, _ := to.origFont.CharcodeBytesToUnicode(str.Bytes())
+		sb.WriteString(text)
+	}
+	return sb.String() != to.Text
+}
+
+// encodeText encodes `text` using the font `font`. ErrUnencodableText is
+// returned if the encoded text does not decode back to `text`, as the font
+// encoders replace or skip the characters they cannot map.
+func encodeText(font *model.PdfFont, text string) ([]byte, error) {
+	encoded, numMisses := font.StringToCharcodeBytes(text)
+	if numMisses > 0 {
+		return nil, ErrUnencodableText
+	}
+	if decoded, _, _ := font.CharcodeBytesToUnicode(encoded); decoded != text {
+		return nil, ErrUnencodableText
+	}
+	return encoded, nil
+}
+
+// makeTfOperation returns a Tf operation setting the font `name` with the
+// size `size`.
+func makeTfOperation(name core.PdfObjectName, size float64) *ContentStreamOperation {
+	return &ContentStreamOperation{
+		Operand: "Tf",
+		Params:  []core.PdfObject{core.MakeName(string(name)), core.MakeFloat(size)},
+	}
+}
+
+// colorOperation returns the operation setting the non-stroking color
+// `color`.
+func colorOperation(color model.PdfColor) (*ContentStreamOperation, error) {
+	switch c := color.(type) {
+	case *model.PdfColorDeviceGray:
+		return &ContentStreamOperation{Operand: "g", Params: makeParamsFromFloats([]float64{c.Val()})}, nil
+	case *model.PdfColorDeviceRGB:
+		return &ContentStreamOperation{
+			Operand: "rg",
+			Params:  makeParamsFromFloats([]float64{c.R(), c.G(), c.B()}),
+		}, nil
+	case *model.PdfColorDeviceCMYK:
+		return &ContentStreamOperation{
+			Operand: "k",
+			Params:  makeParamsFromFloats([]float64{c.C(), c.M(), c.Y(), c.K()}),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported text color %T", color)
+}
+
+// ReplacePageText replaces the occurrences of `old` with `new` in the text
+// showing operations of the page `page` and returns the number of modified
+// operations. See TextEditor.ReplaceAll for the use of `font`.
+func ReplacePageText(page *model.PdfPage, old, new string, font *model.PdfFont) (int, error) {
+	return EditPageText(page, func(editor *TextEditor) (int, error) {
+		return editor.ReplaceAll(old, new, font)
+	})
+}
+
+// EditPageText runs the function `edit` on a TextEditor for the content of
+// the page `page` and replaces the page content with the edited operations,
+// unless `edit` returns an error or zero edits.
+func EditPageText(page *model.PdfPage, edit func(editor *TextEditor) (int, error)) (int, error) {
+	if page == nil {
+		return 0, errors.New("page cannot be nil")
+	}
+	ops, err := pageOperations(page)
+	if err != nil {
+		return 0, err
+	}
+	if page.Resources == nil {
+		page.Resources = model.NewPdfPageResources()
+	}
+	editor, err := NewTextEditor(ops, page.Resources)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := edit(editor)
+	if err != nil || count == 0 {
+		return count, err
+	}
+	edited, err := editor.Operations()
+	if err != nil {
+		return 0, err
+	}
+	if err := setPageOperations(page, edited); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
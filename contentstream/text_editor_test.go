@@ -0,0 +1,127 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// newTestTextEditor returns a text editor for `content` using Helvetica as
+// the font F1 and Times-Roman as the font F2.
+func newTestTextEditor(t *testing.T, content string) (*TextEditor, *model.PdfPageResources) {
+	resources := model.NewPdfPageResources()
+	for name, fontName := range map[string]model.StdFontName{"F1": model.HelveticaName, "F2": model.TimesRomanName} {
+		font, err := model.NewStandard14Font(fontName)
+		require.NoError(t, err)
+		require.NoError(t, resources.SetFontByName(core.PdfObjectName(name), font.ToPdfObject()))
+	}
+
+	ops, err := NewContentStreamParser(content).Parse()
+	require.NoError(t, err)
+	editor, err := NewTextEditor(ops, resources)
+	require.NoError(t, err)
+	return editor, resources
+}
+
+// editedContent returns the content stream of the edited operations.
+func editedContent(t *testing.T, editor *TextEditor) string {
+	ops, err := editor.Operations()
+	require.NoError(t, err)
+	return ops.String()
+}
+
+func TestTextEditorFind(t *testing.T) {
+	content := `BT /F1 12 Tf (Hello world) Tj q /F2 10 Tf [(Con)10(fidential)] TJ Q (Goodbye) ' ET`
+	editor, _ := newTestTextEditor(t, content)
+
+	texts := editor.TextOperations()
+	require.Len(t, texts, 3)
+	require.Equal(t, "Hello world", texts[0].Text)
+	require.Equal(t, 12.0, texts[0].FontSize)
+	require.Equal(t, "Confidential", texts[1].Text)
+	require.Equal(t, "Times-Roman", texts[1].Font.BaseFont())
+	require.Equal(t, 10.0, texts[1].FontSize)
+
+	// The text state is restored by Q.
+	require.Equal(t, "Goodbye", texts[2].Text)
+	require.Equal(t, "Helvetica", texts[2].Font.BaseFont())
+	require.Equal(t, 7, texts[2].Index)
+
+	found := editor.Find("fidential")
+	require.Len(t, found, 1)
+	require.Equal(t, "TJ", found[0].Operation().Operand)
+	require.Empty(t, editor.Find("missing"))
+}
+
+func TestTextEditorReplace(t *testing.T) {
+	content := "BT /F1 12 Tf (Draft) Tj [(Dr)20(aft copy)] TJ 1 2 (Draft) \" ET"
+	editor, _ := newTestTextEditor(t, content)
+
+	count, err := editor.ReplaceAll("Draft", "Final", nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Equal(t, "BT\n/F1 12 Tf\n(Final) Tj\n(Final copy) Tj\n1 2 (Final) \"\nET\n", editedContent(t, editor))
+
+	// The source operations are not modified.
+	require.Equal(t, "Draft", editor.TextOperations()[0].Operation().Params[0].(*core.PdfObjectString).Str())
+
+	// Characters not supported by the fonts.
+	_, err = editor.ReplaceAll("Final", "中", nil)
+	require.Equal(t, ErrUnencodableText, err)
+}
+
+func TestTextEditorDeleteRestyle(t *testing.T) {
+	content := `BT /F1 12 Tf 0 0 1 rg (Secret) Tj (Public) Tj (Line) ' 1 2 (Other) " ET`
+	editor, _ := newTestTextEditor(t, content)
+
+	require.Equal(t, 1, editor.DeleteAll("Secret"))
+	require.Empty(t, editor.Find("Secret"))
+	found := editor.Find("Line")
+	require.Len(t, found, 1)
+	editor.Delete(found[0])
+	editor.Delete(editor.Find("Other")[0])
+
+	font, err := model.NewStandard14Font(model.CourierName)
+	require.NoError(t, err)
+	public := editor.Find("Public")[0]
+	require.Error(t, editor.Restyle(public, TextStyle{Color: model.NewPdfColorLab(0, 0, 0)}))
+	require.NoError(t, editor.Restyle(public, TextStyle{
+		Font:     font,
+		FontSize: 20,
+		Color:    model.NewPdfColorDeviceRGB(1, 0, 0),
+	}))
+
+	expected := "BT\n/F1 12 Tf\n0 0 1 rg\n" +
+		"/EditF1 20 Tf\n1 0 0 rg\n(Public) Tj\n/F1 12 Tf\n0 0 1 rg\n" +
+		"T*\n1 Tw\n2 Tc\nT*\nET\n"
+	require.Equal(t, expected, editedContent(t, editor))
+}
+
+func TestReplacePageText(t *testing.T) {
+	font, err := model.NewStandard14Font(model.HelveticaName)
+	require.NoError(t, err)
+	page := model.NewPdfPage()
+	page.Resources = model.NewPdfPageResources()
+	require.NoError(t, page.Resources.SetFontByName("F1", font.ToPdfObject()))
+	require.NoError(t, page.AddContentStreamByString("BT /F1 12 Tf (Page one) Tj ET"))
+
+	count, err := ReplacePageText(page, "one", "two", nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	content, err := page.GetAllContentStreams()
+	require.NoError(t, err)
+	require.Contains(t, content, "(Page two) Tj")
+
+	count, err = ReplacePageText(page, "three", "four", nil)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
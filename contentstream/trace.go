@@ -0,0 +1,239 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// traceOperandWidth is the width of the operation column of the traces.
+const traceOperandWidth = 40
+
+// traceFont represents the text font tracked by a trace.
+type traceFont struct {
+	name     core.PdfObjectName
+	baseFont string
+	size     float64
+}
+
+// Trace writes to `w` a pretty-printed listing of the content stream
+// operations `ops`, using the resources `resources`, for debugging purposes.
+// The operations are indented according to their nesting in q/Q, BT/ET and
+// marked content (BMC/BDC/EMC) blocks, and each operation is annotated with
+// the graphics state in effect after the operation: the current
+// transformation matrix, the stroking and non-stroking colors, and the text
+// font and size if set. For example:
+//
+//	q                                        % CTM=[1 0 0 1 0 0] stroke=DeviceGray(0) fill=DeviceGray(0)
+//	  1 0 0 rg                               % CTM=[1 0 0 1 0 0] stroke=DeviceGray(0) fill=DeviceRGB(1 0 0)
+//	  BT                                     % CTM=[1 0 0 1 0 0] stroke=DeviceGray(0) fill=DeviceRGB(1 0 0)
+//	    /F1 12 Tf                            % CTM=[1 0 0 1 0 0] stroke=DeviceGray(0) fill=DeviceRGB(1 0 0) font=/F1(Helvetica) 12
+func Trace(w io.Writer, ops *ContentStreamOperations, resources *model.PdfPageResources) error {
+	if ops == nil {
+		return errors.New("operations cannot be nil")
+	}
+	if resources == nil {
+		resources = model.NewPdfPageResources()
+	}
+
+	var (
+		depth     int
+		font      *traceFont
+		fontStack []*traceFont
+		writeErr  error
+	)
+	proc := NewContentStreamProcessor(*ops)
+	proc.AddHandler(HandlerConditionEnumAllOperands, "",
+		func(op *ContentStreamOperation, gs GraphicsState, resources *model.PdfPageResources) error {
+			switch op.Operand {
+			case "q":
+				fontStack = append(fontStack, font)
+			case "Q":
+				if len(fontStack) > 0 {
+					font = fontStack[len(fontStack)-1]
+					fontStack = fontStack[:len(fontStack)-1]
+				}
+			case "Tf":
+				if f := newTraceFont(op, resources); f != nil {
+					font = f
+				}
+			}
+
+			switch op.Operand {
+			case "Q", "ET", "EMC":
+				if depth > 0 {
+					depth--
+				}
+			}
+			line := strings.Repeat("  ", depth) + traceOperation(op)
+			if len(line) < traceOperandWidth {
+				line += strings.Repeat(" ", traceOperandWidth-len(line))
+			}
+			line += " % " + traceState(gs, font)
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				writeErr = err
+				return err
+			}
+			switch op.Operand {
+			case "q", "BT", "BMC", "BDC":
+				depth++
+			}
+			return nil
+		})
+
+	if err := proc.Process(resources); err != nil {
+		if writeErr != nil {
+			return writeErr
+		}
+		return err
+	}
+	return nil
+}
+
+// TracePage writes to `w` a pretty-printed listing of the content stream of
+// the page `page`, see Trace.
+func TracePage(w io.Writer, page *model.PdfPage) error {
+	content, err := page.GetAllContentStreams()
+	if err != nil {
+		return err
+	}
+	ops, err := NewContentStreamParser(content).Parse()
+	if err != nil {
+		return err
+	}
+	return Trace(w, ops, page.Resources)
+}
+
+// newTraceFont returns the font set by the Tf operation `op`, or nil if the
+// operation is invalid.
+func newTraceFont(op *ContentStreamOperation, resources *model.PdfPageResources) *traceFont {
+	if len(op.Params) != 2 {
+		return nil
+	}
+	name, ok := core.GetName(op.Params[0])
+	if !ok {
+		return nil
+	}
+	size, err := core.GetNumberAsFloat(op.Params[1])
+	if err != nil {
+		return nil
+	}
+
+	font := &traceFont{name: *name, size: size}
+	if obj, ok := resources.GetFontByName(*name); ok {
+		if d, ok := core.GetDict(obj); ok {
+			font.baseFont, _ = core.GetNameVal(d.Get("BaseFont"))
+		}
+	}
+	return font
+}
+
+// traceOperation returns the representation of the operation `op`. The data
+// of inline images is omitted.
+func traceOperation(op *ContentStreamOperation) string {
+	var parts []string
+	for _, param := range op.Params {
+		if img, ok := param.(*ContentStreamInlineImage); ok {
+			var attrs []string
+			for _, attr := range []struct {
+				key string
+				obj core.PdfObject
+			}{{"W", img.Width}, {"H", img.Height}, {"CS", img.ColorSpace}, {"BPC", img.BitsPerComponent}} {
+				if attr.obj != nil {
+					attrs = append(attrs, "/"+attr.key+" "+attr.obj.WriteString())
+				}
+			}
+			parts = append(parts, strings.Join(attrs, " "), fmt.Sprintf("ID <%d bytes> EI", len(img.stream)))
+			continue
+		}
+		parts = append(parts, param.WriteString())
+	}
+	if op.Operand == "BI" {
+		return strings.Join(append([]string{op.Operand}, parts...), " ")
+	}
+	return strings.Join(append(parts, op.Operand), " ")
+}
+
+// traceState returns the representation of the graphics state `gs` with the
+// text font `font`.
+func traceState(gs GraphicsState, font *traceFont) string {
+	s := "CTM=" + traceMatrix(gs.CTM) +
+		" stroke=" + traceColor(gs.ColorspaceStroking, gs.ColorStroking) +
+		" fill=" + traceColor(gs.ColorspaceNonStroking, gs.ColorNonStroking)
+	if font != nil {
+		s += " font=/" + string(font.name)
+		if font.baseFont != "" {
+			s += "(" + font.baseFont + ")"
+		}
+		s += " " + traceNumber(font.size)
+	}
+	return s
+}
+
+// traceMatrix returns the representation of the matrix `m` as the operands of
+// the cm operator.
+func traceMatrix(m transform.Matrix) string {
+	return "[" + traceNumbers([]float64{m[0], m[1], m[3], m[4], m[6], m[7]}) + "]"
+}
+
+// traceColor returns the representation of the color `color` of the color
+// space `cs`.
+func traceColor(cs model.PdfColorspace, color model.PdfColor) string {
+	if cs == nil {
+		return "none"
+	}
+
+	var vals []float64
+	switch c := color.(type) {
+	case *model.PdfColorDeviceGray:
+		vals = []float64{float64(*c)}
+	case *model.PdfColorDeviceRGB:
+		vals = c[:]
+	case *model.PdfColorDeviceCMYK:
+		vals = c[:]
+	case *model.PdfColorCalGray:
+		vals = []float64{float64(*c)}
+	case *model.PdfColorCalRGB:
+		vals = c[:]
+	case *model.PdfColorLab:
+		vals = c[:]
+	case *model.PdfColorPattern:
+		return fmt.Sprintf("%s(/%s)", cs.String(), c.PatternName)
+	case nil:
+		return cs.String()
+	default:
+		return fmt.Sprintf("%s(%v)", cs.String(), color)
+	}
+	return cs.String() + "(" + traceNumbers(vals) + ")"
+}
+
+// traceNumbers returns the space separated representations of `vals`.
+func traceNumbers(vals []float64) string {
+	strs := make([]string, len(vals))
+	for i, val := range vals {
+		strs[i] = traceNumber(val)
+	}
+	return strings.Join(strs, " ")
+}
+
+// traceNumber returns the shortest representation of `val`, rounded to 5
+// decimals.
+func traceNumber(val float64) string {
+	s := strconv.FormatFloat(val, 'f', 5, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
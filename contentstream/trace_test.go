@@ -0,0 +1,68 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package contentstream
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestTrace(t *testing.T) {
+	resources := model.NewPdfPageResources()
+	font, err := model.NewStandard14Font(model.HelveticaName)
+	require.NoError(t, err)
+	require.NoError(t, resources.SetFontByName("F1", font.ToPdfObject()))
+
+	content := "q 2 0 0 2 72 72 cm 1 0 0 rg /OC /MC0 BDC BT /F1 12 Tf (Hi) Tj ET EMC Q " +
+		"0 0 m 10 10 l S BI /W 2 /H 1 /CS /G /BPC 8 ID \x00\xff EI"
+	ops, err := NewContentStreamParser(content).Parse()
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, Trace(&buf, ops, resources))
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, len(*ops))
+
+	expected := []struct {
+		op    string
+		state string
+	}{
+		{"q", "CTM=[1 0 0 1 0 0] stroke=DeviceGray(0) fill=DeviceGray(0)"},
+		{"  2 0 0 2 72 72 cm", "CTM=[2 0 0 2 72 72] stroke=DeviceGray(0) fill=DeviceGray(0)"},
+		{"  1 0 0 rg", "CTM=[2 0 0 2 72 72] stroke=DeviceGray(0) fill=DeviceRGB(1 0 0)"},
+		{"  /OC /MC0 BDC", "CTM=[2 0 0 2 72 72] stroke=DeviceGray(0) fill=DeviceRGB(1 0 0)"},
+		{"    BT", "CTM=[2 0 0 2 72 72] stroke=DeviceGray(0) fill=DeviceRGB(1 0 0)"},
+		{"      /F1 12 Tf", "CTM=[2 0 0 2 72 72] stroke=DeviceGray(0) fill=DeviceRGB(1 0 0) font=/F1(Helvetica) 12"},
+		{"      (Hi) Tj", "CTM=[2 0 0 2 72 72] stroke=DeviceGray(0) fill=DeviceRGB(1 0 0) font=/F1(Helvetica) 12"},
+		{"    ET", "CTM=[2 0 0 2 72 72] stroke=DeviceGray(0) fill=DeviceRGB(1 0 0) font=/F1(Helvetica) 12"},
+		{"  EMC", "CTM=[2 0 0 2 72 72] stroke=DeviceGray(0) fill=DeviceRGB(1 0 0) font=/F1(Helvetica) 12"},
+		{"Q", "CTM=[1 0 0 1 0 0] stroke=DeviceGray(0) fill=DeviceGray(0)"},
+		{"0 0 m", "CTM=[1 0 0 1 0 0] stroke=DeviceGray(0) fill=DeviceGray(0)"},
+		{"10 10 l", "CTM=[1 0 0 1 0 0] stroke=DeviceGray(0) fill=DeviceGray(0)"},
+		{"S", "CTM=[1 0 0 1 0 0] stroke=DeviceGray(0) fill=DeviceGray(0)"},
+		{"BI /W 2 /H 1 /CS /G /BPC 8 ID <2 bytes> EI", "CTM=[1 0 0 1 0 0] stroke=DeviceGray(0) fill=DeviceGray(0)"},
+	}
+	for i, exp := range expected {
+		parts := strings.SplitN(lines[i], " % ", 2)
+		require.Len(t, parts, 2)
+		require.Equal(t, exp.op, strings.TrimRight(parts[0], " "))
+		require.Equal(t, exp.state, parts[1])
+	}
+
+	// Unbalanced operators do not break the indentation.
+	ops, err = NewContentStreamParser("ET q Q").Parse()
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, Trace(&buf, ops, nil))
+	require.True(t, strings.HasPrefix(buf.String(), "ET "))
+
+	require.Error(t, Trace(&buf, nil, nil))
+}
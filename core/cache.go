@@ -0,0 +1,257 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"container/list"
+)
+
+// EvictionPolicy defines which entry is removed from a full cache.
+type EvictionPolicy int
+
+const (
+	// EvictLeastRecentlyUsed removes the entry accessed the least recently.
+	EvictLeastRecentlyUsed EvictionPolicy = iota
+
+	// EvictOldest removes the entry added first, regardless of its use.
+	EvictOldest
+)
+
+// CacheOptions configures the caches of a PdfParser.
+// The parsed objects are always kept, as the objects using them refer to them
+// directly.
+type CacheOptions struct {
+	// MaxObjectStreams is the maximum number of decoded object streams kept
+	// to load the objects they contain. Zero, the default, means no limit.
+	MaxObjectStreams int
+
+	// MaxDecodedStreamSize is the maximum total size in bytes of the data
+	// decoded by PdfParser.DecodeStream kept in cache. Zero, the default,
+	// disables the cache.
+	MaxDecodedStreamSize int64
+
+	// Eviction is the policy selecting the entries removed from full caches.
+	Eviction EvictionPolicy
+}
+
+// CacheStats holds the counters of the work done by a PdfParser.
+type CacheStats struct {
+	// ObjectsParsed is the number of objects parsed from the file or from
+	// object streams.
+	ObjectsParsed int64
+
+	// ObjectCacheHits is the number of object lookups served by the cache of
+	// parsed objects.
+	ObjectCacheHits int64
+
+	// ObjectStreamsDecoded is the number of object streams decoded.
+	ObjectStreamsDecoded int64
+
+	// ObjectStreamCacheHits is the number of object loads served by the
+	// cache of decoded object streams.
+	ObjectStreamCacheHits int64
+
+	// StreamsDecoded is the number of streams decoded by
+	// PdfParser.DecodeStream.
+	StreamsDecoded int64
+
+	// StreamCacheHits is the number of PdfParser.DecodeStream calls served by
+	// the cache of decoded streams.
+	StreamCacheHits int64
+
+	// BytesDecoded is the total size of the data decoded from object streams
+	// and by PdfParser.DecodeStream.
+	BytesDecoded int64
+
+	// CachedObjectStreams is the number of decoded object streams in cache.
+	CachedObjectStreams int
+
+	// CachedStreamSize is the total size of the decoded stream data in cache.
+	CachedStreamSize int64
+}
+
+// boundedCache is a cache limited in number of entries and in total size of
+// the entries, evicting entries according to an EvictionPolicy.
+type boundedCache struct {
+	maxEntries int   // Zero means no limit.
+	maxSize    int64 // Zero means no limit.
+	policy     EvictionPolicy
+
+	size    int64
+	order   *list.List // Front is the next entry to evict.
+	entries map[interface{}]*list.Element
+
+	// onEvict is called with the keys of the evicted entries, if set.
+	onEvict func(key interface{})
+}
+
+// boundedCacheEntry is an entry of a boundedCache.
+type boundedCacheEntry struct {
+	key   interface{}
+	value interface{}
+	size  int64
+}
+
+// newBoundedCache returns a new empty cache with the given limits.
+func newBoundedCache(maxEntries int, maxSize int64, policy EvictionPolicy) *boundedCache {
+	return &boundedCache{
+		maxEntries: maxEntries,
+		maxSize:    maxSize,
+		policy:     policy,
+		order:      list.New(),
+		entries:    map[interface{}]*list.Element{},
+	}
+}
+
+// get returns the value cached for `key`, if any.
+func (c *boundedCache) get(key interface{}) (interface{}, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.policy == EvictLeastRecentlyUsed {
+		c.order.MoveToBack(elem)
+	}
+	return elem.Value.(*boundedCacheEntry).value, true
+}
+
+// add caches `value` of size `size` for `key`, evicting entries as needed.
+// Values larger than the size limit are not cached.
+func (c *boundedCache) add(key, value interface{}, size int64) {
+	c.remove(key)
+	if c.maxSize > 0 && size > c.maxSize {
+		return
+	}
+	entry := &boundedCacheEntry{key: key, value: value, size: size}
+	c.entries[key] = c.order.PushBack(entry)
+	c.size += size
+	c.evict()
+}
+
+// remove removes the entry of `key`, if any.
+func (c *boundedCache) remove(key interface{}) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	c.order.Remove(elem)
+	delete(c.entries, key)
+	c.size -= elem.Value.(*boundedCacheEntry).size
+}
+
+// setLimits changes the limits and the eviction policy of the cache, evicting
+// entries as needed.
+func (c *boundedCache) setLimits(maxEntries int, maxSize int64, policy EvictionPolicy) {
+	c.maxEntries = maxEntries
+	c.maxSize = maxSize
+	c.policy = policy
+	c.evict()
+}
+
+// evict removes entries until the cache is within its limits.
+func (c *boundedCache) evict() {
+	for c.order.Len() > 0 &&
+		(c.maxEntries > 0 && c.order.Len() > c.maxEntries || c.maxSize > 0 && c.size > c.maxSize) {
+		key := c.order.Front().Value.(*boundedCacheEntry).key
+		c.remove(key)
+		if c.onEvict != nil {
+			c.onEvict(key)
+		}
+	}
+}
+
+// decodedStream is the decoded data of a stream kept in cache.
+type decodedStream struct {
+	encoded []byte // Stream data that was decoded.
+	decoded []byte
+}
+
+// objectStreamCache returns the cache tracking the decoded object streams of
+// the parser for eviction. The object streams themselves are kept in objstms.
+func (parser *PdfParser) objectStreamCache() *boundedCache {
+	if parser.objstmCache == nil {
+		opts := parser.cacheOpts
+		parser.objstmCache = newBoundedCache(opts.MaxObjectStreams, 0, opts.Eviction)
+		parser.objstmCache.onEvict = func(key interface{}) {
+			delete(parser.objstms, key.(int))
+		}
+	}
+	return parser.objstmCache
+}
+
+// decodedStreamCache returns the cache of the data decoded by DecodeStream.
+func (parser *PdfParser) decodedStreamCache() *boundedCache {
+	if parser.streamCache == nil {
+		opts := parser.cacheOpts
+		parser.streamCache = newBoundedCache(0, opts.MaxDecodedStreamSize, opts.Eviction)
+	}
+	return parser.streamCache
+}
+
+// SetCacheOptions sets the configuration of the caches of the parser.
+// The entries exceeding the new limits are removed.
+func (parser *PdfParser) SetCacheOptions(opts CacheOptions) {
+	parser.cacheOpts = opts
+	parser.objectStreamCache().setLimits(opts.MaxObjectStreams, 0, opts.Eviction)
+	if opts.MaxDecodedStreamSize > 0 {
+		parser.decodedStreamCache().setLimits(0, opts.MaxDecodedStreamSize, opts.Eviction)
+	} else {
+		parser.streamCache = nil
+	}
+}
+
+// GetCacheOptions returns the configuration of the caches of the parser.
+func (parser *PdfParser) GetCacheOptions() CacheOptions {
+	return parser.cacheOpts
+}
+
+// CacheStats returns the counters of the work done by the parser.
+func (parser *PdfParser) CacheStats() CacheStats {
+	stats := parser.stats
+	stats.CachedObjectStreams = len(parser.objstms)
+	if parser.streamCache != nil {
+		stats.CachedStreamSize = parser.streamCache.size
+	}
+	return stats
+}
+
+// DecodeStream decodes the stream data of `streamObj` as DecodeStream does,
+// caching the decoded data as configured by SetCacheOptions, so that the
+// streams used repeatedly, e.g. the fonts and the forms shared by pages, are
+// decoded once. The cached data is only used while the stream data of
+// `streamObj` is unchanged and the returned slice must not be modified.
+func (parser *PdfParser) DecodeStream(streamObj *PdfObjectStream) ([]byte, error) {
+	if parser.cacheOpts.MaxDecodedStreamSize > 0 {
+		if cached, ok := parser.decodedStreamCache().get(streamObj); ok {
+			ds := cached.(decodedStream)
+			if sameBytes(ds.encoded, streamObj.Stream) {
+				parser.stats.StreamCacheHits++
+				return ds.decoded, nil
+			}
+			parser.decodedStreamCache().remove(streamObj)
+		}
+	}
+
+	decoded, err := DecodeStream(streamObj)
+	if err != nil {
+		return nil, err
+	}
+	parser.stats.StreamsDecoded++
+	parser.stats.BytesDecoded += int64(len(decoded))
+	if parser.cacheOpts.MaxDecodedStreamSize > 0 {
+		ds := decodedStream{encoded: streamObj.Stream, decoded: decoded}
+		parser.decodedStreamCache().add(streamObj, ds, int64(len(decoded)))
+	}
+	return decoded, nil
+}
+
+// sameBytes returns true if `a` and `b` are the same slice of the same array.
+func sameBytes(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || &a[0] == &b[0]
+}
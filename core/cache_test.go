@@ -0,0 +1,92 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoundedCache(t *testing.T) {
+	for _, policy := range []EvictionPolicy{EvictLeastRecentlyUsed, EvictOldest} {
+		var evicted []interface{}
+		c := newBoundedCache(2, 0, policy)
+		c.onEvict = func(key interface{}) { evicted = append(evicted, key) }
+		c.add(1, "a", 1)
+		c.add(2, "b", 1)
+		val, ok := c.get(1)
+		require.True(t, ok)
+		require.Equal(t, "a", val)
+		c.add(3, "c", 1)
+
+		// The least recently used entry is 2, the oldest is 1.
+		if policy == EvictLeastRecentlyUsed {
+			require.Equal(t, []interface{}{2}, evicted)
+		} else {
+			require.Equal(t, []interface{}{1}, evicted)
+		}
+		require.Equal(t, 2, c.order.Len())
+		require.Equal(t, int64(2), c.size)
+	}
+
+	// Size limit.
+	c := newBoundedCache(0, 10, EvictLeastRecentlyUsed)
+	c.add(1, nil, 6)
+	c.add(2, nil, 4)
+	require.Equal(t, int64(10), c.size)
+	c.add(3, nil, 11)
+	_, ok := c.get(3)
+	require.False(t, ok)
+	c.add(3, nil, 1)
+	_, ok = c.get(1)
+	require.False(t, ok)
+	require.Equal(t, int64(5), c.size)
+
+	c.setLimits(0, 2, EvictLeastRecentlyUsed)
+	require.Equal(t, 1, c.order.Len())
+	_, ok = c.get(3)
+	require.True(t, ok)
+}
+
+func TestParserDecodeStreamCache(t *testing.T) {
+	parser := NewParserFromString("")
+	stream, err := MakeStream([]byte("content"), NewFlateEncoder())
+	require.NoError(t, err)
+
+	// Not cached by default.
+	for i := 0; i < 2; i++ {
+		decoded, err := parser.DecodeStream(stream)
+		require.NoError(t, err)
+		require.Equal(t, "content", string(decoded))
+	}
+	stats := parser.CacheStats()
+	require.Equal(t, int64(2), stats.StreamsDecoded)
+	require.Equal(t, int64(0), stats.StreamCacheHits)
+	require.Equal(t, int64(14), stats.BytesDecoded)
+
+	parser.SetCacheOptions(CacheOptions{MaxDecodedStreamSize: 100})
+	for i := 0; i < 2; i++ {
+		_, err := parser.DecodeStream(stream)
+		require.NoError(t, err)
+	}
+	stats = parser.CacheStats()
+	require.Equal(t, int64(3), stats.StreamsDecoded)
+	require.Equal(t, int64(1), stats.StreamCacheHits)
+	require.Equal(t, int64(7), stats.CachedStreamSize)
+
+	// Changing the stream data invalidates the cached data.
+	other, err := MakeStream([]byte("other"), NewFlateEncoder())
+	require.NoError(t, err)
+	stream.Stream = other.Stream
+	decoded, err := parser.DecodeStream(stream)
+	require.NoError(t, err)
+	require.Equal(t, "other", string(decoded))
+	require.Equal(t, int64(1), parser.CacheStats().StreamCacheHits)
+
+	parser.SetCacheOptions(CacheOptions{})
+	require.Equal(t, int64(0), parser.CacheStats().CachedStreamSize)
+}
@@ -12,6 +12,8 @@ import (
 	"os"
 	"strings"
 
+	"golang.org/x/xerrors"
+
 	"github.com/unidoc/unipdf/v3/common"
 )
 
@@ -69,10 +71,8 @@ type objectCache map[int]PdfObject
 // lookupObjectViaOS returns an object from an object stream.
 func (parser *PdfParser) lookupObjectViaOS(sobjNumber int, objNum int) (PdfObject, error) {
 	var bufReader *bytes.Reader
-	var objstm objectStream
-	var cached bool
 
-	objstm, cached = parser.objstms[sobjNumber]
+	objstm, cached := parser.objstms[sobjNumber]
 	if !cached {
 		soi, err := parser.LookupByNumber(sobjNumber)
 		if err != nil {
@@ -105,6 +105,9 @@ func (parser *PdfParser) lookupObjectViaOS(sobjNumber int, objNum int) (PdfObjec
 		if !ok {
 			return nil, errors.New("invalid N in stream dictionary")
 		}
+		if max := parser.limits.MaxObjects; max > 0 && int64(*N) > int64(max) {
+			return nil, &LimitError{Limit: "MaxObjects", Value: int64(max)}
+		}
 		firstOffset, ok := sod.Get("First").(*PdfObjectInteger)
 		if !ok {
 			return nil, errors.New("invalid First in stream dictionary")
@@ -117,6 +120,8 @@ func (parser *PdfParser) lookupObjectViaOS(sobjNumber int, objNum int) (PdfObjec
 		}
 
 		common.Log.Trace("Decoded: %s", ds)
+		parser.stats.ObjectStreamsDecoded++
+		parser.stats.BytesDecoded += int64(len(ds))
 
 		// Temporarily change the reader object to this decoded buffer.
 		// Change back afterwards.
@@ -159,7 +164,11 @@ func (parser *PdfParser) lookupObjectViaOS(sobjNumber int, objNum int) (PdfObjec
 
 		objstm = objectStream{N: int(*N), ds: ds, offsets: offsets}
 		parser.objstms[sobjNumber] = objstm
+		parser.objectStreamCache().add(sobjNumber, nil, int64(len(ds)))
 	} else {
+		parser.objectStreamCache().get(sobjNumber)
+		parser.stats.ObjectStreamCacheHits++
+
 		// Temporarily change the reader object to this decoded buffer.
 		// Point back afterwards.
 		bakOffset := parser.GetFileOffset()
@@ -179,6 +188,10 @@ func (parser *PdfParser) lookupObjectViaOS(sobjNumber int, objNum int) (PdfObjec
 	bb, _ := parser.reader.Peek(100)
 	common.Log.Trace("OBJ peek \"%s\"", string(bb))
 
+	prevObjNum, prevInObjectStream := parser.objNum, parser.inObjectStream
+	parser.objNum, parser.inObjectStream = int64(objNum), true
+	defer func() { parser.objNum, parser.inObjectStream = prevObjNum, prevInObjectStream }()
+
 	val, err := parser.parseObject()
 	if err != nil {
 		common.Log.Debug("ERROR Fail to read object (%s)", err)
@@ -196,11 +209,19 @@ func (parser *PdfParser) lookupObjectViaOS(sobjNumber int, objNum int) (PdfObjec
 	return &io, nil
 }
 
-// LookupByNumber looks up a PdfObject by object number.  Returns an error on failure.
+// LookupByNumber looks up a PdfObject by object number.  Returns an error on failure, a *ParseError if
+// the object could not be parsed.
 func (parser *PdfParser) LookupByNumber(objNumber int) (PdfObject, error) {
 	// Outside interface for lookupByNumberWrapper.  Default attempts repairs of bad xref tables.
 	obj, _, err := parser.lookupByNumberWrapper(objNumber, true)
-	return obj, err
+	if err != nil {
+		offset := int64(-1)
+		if xref, ok := parser.xrefs.ObjectMap[objNumber]; ok && xref.XType == XrefTypeTableEntry {
+			offset = xref.Offset
+		}
+		return nil, newParseError(err, int64(objNumber), offset)
+	}
+	return obj, nil
 }
 
 // Wrapper for lookupByNumber, checks if object encrypted etc.
@@ -240,6 +261,7 @@ func (parser *PdfParser) lookupByNumber(objNumber int, attemptRepairs bool) (Pdf
 	obj, ok := parser.ObjCache[objNumber]
 	if ok {
 		common.Log.Trace("Returning cached object %d", objNumber)
+		parser.stats.ObjectCacheHits++
 		return obj, false, nil
 	}
 
@@ -253,6 +275,10 @@ func (parser *PdfParser) lookupByNumber(objNumber int, attemptRepairs bool) (Pdf
 		return &nullObj, false, nil
 	}
 
+	if err := parser.checkObjectCount(); err != nil {
+		return nil, false, err
+	}
+
 	common.Log.Trace("Lookup obj number %d", objNumber)
 	if xref.XType == XrefTypeTableEntry {
 		common.Log.Trace("xrefobj obj num %d", xref.ObjectNumber)
@@ -266,7 +292,8 @@ func (parser *PdfParser) lookupByNumber(objNumber int, attemptRepairs bool) (Pdf
 		if err != nil {
 			common.Log.Debug("ERROR Failed reading xref (%s)", err)
 			// Offset pointing to a non-object.  Try to repair the file.
-			if attemptRepairs {
+			// Exceeded limits are not repaired.
+			if attemptRepairs && !xerrors.Is(err, ErrLimitExceeded) {
 				common.Log.Debug("Attempting to repair xrefs (top down)")
 				xrefTable, err := parser.repairRebuildXrefsTopDown()
 				if err != nil {
@@ -298,6 +325,7 @@ func (parser *PdfParser) lookupByNumber(objNumber int, attemptRepairs bool) (Pdf
 		}
 
 		common.Log.Trace("Returning obj")
+		parser.stats.ObjectsParsed++
 		parser.ObjCache[objNumber] = obj
 		return obj, false, nil
 	} else if xref.XType == XrefTypeObjectStream {
@@ -317,6 +345,7 @@ func (parser *PdfParser) lookupByNumber(objNumber int, attemptRepairs bool) (Pdf
 				return nil, true, err
 			}
 			common.Log.Trace("<Loaded via OS")
+			parser.stats.ObjectsParsed++
 			parser.ObjCache[objNumber] = optr
 			if parser.crypter != nil {
 				// Mark as decrypted (inside object stream) for caching.
@@ -6,10 +6,13 @@
 package core
 
 import (
+	"bytes"
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
 )
 
 func TestParseXRefs(t *testing.T) {
@@ -46,3 +49,27 @@ func TestParseXRefs(t *testing.T) {
 
 	require.Equal(t, expected, p.xrefs)
 }
+
+func TestLookupParseError(t *testing.T) {
+	// Object 2 is truncated.
+	data := "1 0 obj\n<< /A 1 >>\nendobj\n2 0 obj\n<< /B [1 2"
+	offset := int64(strings.Index(data, "2 0 obj"))
+	p := NewParserFromString(data)
+	p.xrefs.ObjectMap[1] = XrefObject{XType: XrefTypeTableEntry, ObjectNumber: 1}
+	p.xrefs.ObjectMap[2] = XrefObject{XType: XrefTypeTableEntry, ObjectNumber: 2, Offset: offset}
+
+	_, err := p.LookupByNumber(1)
+	require.NoError(t, err)
+
+	_, err = p.LookupByNumber(2)
+	require.Error(t, err)
+	var parseErr *ParseError
+	require.True(t, xerrors.As(err, &parseErr))
+	require.Equal(t, int64(2), parseErr.ObjNum)
+	require.Equal(t, offset, parseErr.Offset)
+	require.True(t, strings.HasPrefix(err.Error(), "parse error in object 2 at offset 26: "))
+
+	_, err = NewParser(bytes.NewReader([]byte("not a PDF file")))
+	require.True(t, xerrors.As(err, &parseErr))
+	require.Equal(t, int64(0), parseErr.ObjNum)
+}
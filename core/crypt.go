@@ -283,6 +283,63 @@ func (crypt *PdfCrypt) String() string {
 	return str
 }
 
+// CryptInfo describes the encryption of a document.
+type CryptInfo struct {
+	// Filter is the name of the security handler, e.g. "Standard".
+	Filter string
+
+	// SubFilter is the format of the encryption dictionary, if specified.
+	SubFilter string
+
+	// V is the code of the encryption algorithm (1 to 5).
+	V int
+
+	// R is the revision of the standard security handler.
+	R int
+
+	// Method is the method of the crypt filter of the streams: "V2" (RC4),
+	// "AESV2" (AES-128), "AESV3" (AES-256) or "Identity". RC4 is used if V
+	// is less than 4.
+	Method string
+
+	// KeyLength is the length of the encryption key, in bits.
+	KeyLength int
+
+	// EncryptMetadata specifies whether the metadata streams are encrypted.
+	EncryptMetadata bool
+
+	// Permissions contains the user access permissions.
+	Permissions security.Permissions
+}
+
+// Info returns the description of the encryption.
+func (crypt *PdfCrypt) Info() CryptInfo {
+	info := CryptInfo{
+		Filter:          crypt.encrypt.Filter,
+		SubFilter:       crypt.encrypt.SubFilter,
+		V:               crypt.encrypt.V,
+		R:               crypt.encryptStd.R,
+		Method:          "V2",
+		KeyLength:       crypt.encrypt.Length,
+		EncryptMetadata: crypt.encryptStd.EncryptMetadata,
+		Permissions:     crypt.encryptStd.P,
+	}
+	if info.V == 1 || info.KeyLength == 0 {
+		info.KeyLength = 40
+	}
+	if info.V >= 4 {
+		if cf, ok := crypt.cryptFilters[crypt.streamFilter]; ok {
+			info.Method = cf.Name()
+			if length := cf.KeyLength() * 8; length > 0 {
+				info.KeyLength = length
+			}
+		} else {
+			info.Method = "Identity"
+		}
+	}
+	return info
+}
+
 // encryptDict is a set of field common to all encryption dictionaries.
 type encryptDict struct {
 	Filter    string // (Required) The name of the preferred security handler for this document.
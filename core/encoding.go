@@ -76,6 +76,9 @@ type FlateEncoder struct {
 	// For predictors
 	Columns int
 	Colors  int
+
+	// Maximum size of the decoded data, no limit if 0. See Limits.
+	maxDecodedSize int64
 }
 
 // NewFlateEncoder makes a new flate encoder with default parameters, predictor 1 and bits per component 8.
@@ -275,7 +278,10 @@ func (enc *FlateEncoder) DecodeBytes(encoded []byte) ([]byte, error) {
 	defer r.Close()
 
 	var outBuf bytes.Buffer
-	outBuf.ReadFrom(r)
+	outBuf.ReadFrom(limitReader(r, enc.maxDecodedSize))
+	if err := checkDecodedSize(outBuf.Len(), enc.maxDecodedSize); err != nil {
+		return nil, err
+	}
 
 	return outBuf.Bytes(), nil
 }
@@ -484,6 +490,9 @@ type LZWEncoder struct {
 	Colors  int
 	// LZW algorithm setting.
 	EarlyChange int
+
+	// Maximum size of the decoded data, no limit if 0. See Limits.
+	maxDecodedSize int64
 }
 
 // NewLZWEncoder makes a new LZW encoder with default parameters.
@@ -699,10 +708,13 @@ func (enc *LZWEncoder) DecodeBytes(encoded []byte) ([]byte, error) {
 	}
 	defer r.Close()
 
-	_, err := outBuf.ReadFrom(r)
+	_, err := outBuf.ReadFrom(limitReader(r, enc.maxDecodedSize))
 	if err != nil {
 		return nil, err
 	}
+	if err := checkDecodedSize(outBuf.Len(), enc.maxDecodedSize); err != nil {
+		return nil, err
+	}
 
 	return outBuf.Bytes(), nil
 }
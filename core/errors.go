@@ -0,0 +1,59 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+// ParseError is the error returned when PDF data cannot be parsed, typically because the file is
+// corrupted. It wraps the underlying error and can be checked with errors.As (or xerrors.As).
+// Unsupported but valid features are reported by errors wrapping ErrNotSupported instead.
+type ParseError struct {
+	// Offset is the offset in the file of the data which could not be parsed, -1 if unknown.
+	Offset int64
+
+	// ObjNum is the number of the object which could not be parsed, 0 if not parsing an object.
+	ObjNum int64
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	s := "parse error"
+	if e.ObjNum > 0 {
+		s += fmt.Sprintf(" in object %d", e.ObjNum)
+	}
+	if e.Offset >= 0 {
+		s += fmt.Sprintf(" at offset %d", e.Offset)
+	}
+	return s + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newParseError returns a ParseError wrapping `err`, which occurred when parsing the object
+// number `objNum` (0 if not parsing an object) at offset `offset`. `err` is returned as is if it
+// already is a ParseError, with its object number set if unknown.
+func newParseError(err error, objNum, offset int64) error {
+	var parseErr *ParseError
+	if xerrors.As(err, &parseErr) {
+		if parseErr.ObjNum == 0 && objNum > 0 {
+			e := *parseErr
+			e.ObjNum = objNum
+			return &e
+		}
+		return err
+	}
+	return &ParseError{Offset: offset, ObjNum: objNum, Err: err}
+}
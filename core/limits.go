@@ -0,0 +1,137 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrLimitExceeded is matched, with errors.Is (or xerrors.Is), by the errors returned when a limit
+// set by Limits is exceeded.
+var ErrLimitExceeded = errors.New("resource limit exceeded")
+
+// Limits configures the limits enforced by a PdfParser to guard against malicious files, such as
+// decompression bombs and deeply nested objects. The zero value of a limit means no limit.
+type Limits struct {
+	// MaxObjects is the maximum number of objects parsed, including the objects of object streams.
+	MaxObjects int
+
+	// MaxXrefEntries is the maximum number of entries of the cross-reference table, i.e. of the
+	// objects defined by the file.
+	MaxXrefEntries int
+
+	// MaxRecursionDepth is the maximum nesting depth of the arrays and dictionaries of the parsed
+	// objects, and of the objects resolved by ResolveReferencesDeep.
+	MaxRecursionDepth int
+
+	// MaxDecodedStreamSize is the maximum size in bytes of the decoded data of a stream.
+	MaxDecodedStreamSize int64
+}
+
+// LimitError is the error returned when a limit set by Limits is exceeded. It wraps
+// ErrLimitExceeded.
+type LimitError struct {
+	// Limit is the name of the exceeded limit, e.g. "MaxObjects".
+	Limit string
+
+	// Value is the value of the exceeded limit.
+	Value int64
+}
+
+// Error implements the error interface.
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%v: %s (%d)", ErrLimitExceeded, e.Limit, e.Value)
+}
+
+// Unwrap returns ErrLimitExceeded.
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// NewParserWithLimits creates a new parser for a PDF file via ReadSeeker as NewParser, enforcing
+// the limits `limits` when loading the cross reference table and later on when parsing objects.
+func NewParserWithLimits(rs io.ReadSeeker, limits Limits) (*PdfParser, error) {
+	return newParser(rs, limits)
+}
+
+// Limits returns the limits enforced by the parser.
+func (parser *PdfParser) Limits() Limits {
+	return parser.limits
+}
+
+// checkObjectCount returns an error if parsing one more object exceeds the MaxObjects limit.
+func (parser *PdfParser) checkObjectCount() error {
+	if max := parser.limits.MaxObjects; max > 0 && parser.stats.ObjectsParsed >= int64(max) {
+		return &LimitError{Limit: "MaxObjects", Value: int64(max)}
+	}
+	return nil
+}
+
+// checkXrefEntries returns an error if `numEntries` cross-reference entries exceed the
+// MaxXrefEntries limit.
+func (parser *PdfParser) checkXrefEntries(numEntries int) error {
+	if max := parser.limits.MaxXrefEntries; max > 0 && numEntries > max {
+		return &LimitError{Limit: "MaxXrefEntries", Value: int64(max)}
+	}
+	return nil
+}
+
+// enterNested increments the nesting depth of the parsed objects, returning an error if it
+// exceeds the MaxRecursionDepth limit. Each call must be followed by a call to leaveNested.
+func (parser *PdfParser) enterNested() error {
+	parser.nesting++
+	if max := parser.limits.MaxRecursionDepth; max > 0 && parser.nesting > max {
+		return &LimitError{Limit: "MaxRecursionDepth", Value: int64(max)}
+	}
+	return nil
+}
+
+// leaveNested decrements the nesting depth of the parsed objects.
+func (parser *PdfParser) leaveNested() {
+	parser.nesting--
+}
+
+// streamLimits returns the limits of the parser of the stream `streamObj`.
+func streamLimits(streamObj *PdfObjectStream) Limits {
+	if streamObj.PdfObjectDictionary == nil || streamObj.PdfObjectDictionary.parser == nil {
+		return Limits{}
+	}
+	return streamObj.PdfObjectDictionary.parser.limits
+}
+
+// checkDecodedSize returns an error if `size` decoded bytes exceed the limit `max`.
+func checkDecodedSize(size int, max int64) error {
+	if max > 0 && int64(size) > max {
+		return &LimitError{Limit: "MaxDecodedStreamSize", Value: max}
+	}
+	return nil
+}
+
+// setDecodeLimit sets the maximum size of the data decoded by `encoder` to `max`, so that
+// decoding stops as soon as the limit is exceeded.
+func setDecodeLimit(encoder StreamEncoder, max int64) {
+	switch enc := encoder.(type) {
+	case *FlateEncoder:
+		enc.maxDecodedSize = max
+	case *LZWEncoder:
+		enc.maxDecodedSize = max
+	case *MultiEncoder:
+		for _, e := range enc.encoders {
+			setDecodeLimit(e, max)
+		}
+	}
+}
+
+// limitReader returns a reader reading at most one byte more than `max` bytes of `r`, so that
+// exceeding the limit can be detected with checkDecodedSize, or `r` if `max` is not positive.
+func limitReader(r io.Reader, max int64) io.Reader {
+	if max > 0 {
+		return io.LimitReader(r, max+1)
+	}
+	return r
+}
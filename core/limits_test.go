@@ -0,0 +1,98 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+// makeLimitsTestPDF returns a PDF file containing the objects `objects`, numbered from 1, with a
+// cross-reference table.
+func makeLimitsTestPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xrefOffset := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xrefOffset)
+	return buf.Bytes()
+}
+
+func requireLimitError(t *testing.T, err error, limit string) {
+	require.True(t, xerrors.Is(err, ErrLimitExceeded), "error: %v", err)
+	var limitErr *LimitError
+	require.True(t, xerrors.As(err, &limitErr))
+	require.Equal(t, limit, limitErr.Limit)
+}
+
+func TestLimits(t *testing.T) {
+	flateEncoder := NewFlateEncoder()
+	bomb, err := flateEncoder.EncodeBytes(make([]byte, 10000))
+	require.NoError(t, err)
+	data := makeLimitsTestPDF(
+		"<< /Type /Catalog /Next 2 0 R >>",
+		"<< /Next 3 0 R >>",
+		"<< /Next 4 0 R /A [[[1]]] >>",
+		fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", len(bomb), bomb),
+	)
+
+	// No limits.
+	parser, err := NewParser(bytes.NewReader(data))
+	require.NoError(t, err)
+	obj, err := parser.LookupByNumber(4)
+	require.NoError(t, err)
+	stream := obj.(*PdfObjectStream)
+	decoded, err := DecodeStream(stream)
+	require.NoError(t, err)
+	require.Len(t, decoded, 10000)
+
+	_, err = NewParserWithLimits(bytes.NewReader(data), Limits{MaxXrefEntries: 3})
+	requireLimitError(t, err, "MaxXrefEntries")
+
+	parser, err = NewParserWithLimits(bytes.NewReader(data), Limits{MaxObjects: 2})
+	require.NoError(t, err)
+	for _, objNum := range []int{1, 2} {
+		_, err = parser.LookupByNumber(objNum)
+		require.NoError(t, err)
+	}
+	_, err = parser.LookupByNumber(3)
+	requireLimitError(t, err, "MaxObjects")
+
+	parser, err = NewParserWithLimits(bytes.NewReader(data), Limits{MaxRecursionDepth: 3})
+	require.NoError(t, err)
+	_, err = parser.LookupByNumber(3)
+	requireLimitError(t, err, "MaxRecursionDepth")
+	obj, err = parser.LookupByNumber(1)
+	require.NoError(t, err)
+	err = ResolveReferencesDeep(obj, nil)
+	requireLimitError(t, err, "MaxRecursionDepth")
+
+	parser, err = NewParserWithLimits(bytes.NewReader(data), Limits{MaxDecodedStreamSize: 1000})
+	require.NoError(t, err)
+	obj, err = parser.LookupByNumber(4)
+	require.NoError(t, err)
+	stream = obj.(*PdfObjectStream)
+	_, err = DecodeStream(stream)
+	requireLimitError(t, err, "MaxDecodedStreamSize")
+	encoder, err := NewEncoderFromStream(stream)
+	require.NoError(t, err)
+	_, err = encoder.DecodeBytes(stream.Stream)
+	requireLimitError(t, err, "MaxDecodedStreamSize")
+	require.True(t, strings.HasPrefix(err.Error(), "resource limit exceeded: MaxDecodedStreamSize (1000)"))
+}
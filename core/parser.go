@@ -51,6 +51,18 @@ type PdfParser struct {
 
 	ObjCache objectCache
 
+	limits  Limits
+	nesting int // Nesting depth of the arrays and dictionaries being parsed.
+
+	objNum         int64         // Number of the object being parsed, 0 if unknown.
+	inObjectStream bool          // Parsing decoded object stream data: file offsets are unknown.
+	recovered      []*ParseError // Malformed tokens skipped while parsing, see RecoveredErrors.
+
+	cacheOpts   CacheOptions
+	objstmCache *boundedCache // Eviction order of objstms, see objectStreamCache.
+	streamCache *boundedCache // Decoded streams (decodedStream).
+	stats       CacheStats
+
 	// Tracker for reference lookups when looking up Length entry of stream objects.
 	// The Length entries of stream objects are a special case, as they can require recursive parsing, i.e. look up
 	// the length reference (if not object) prior to reading the actual stream.  This has risks of endless looping.
@@ -228,7 +240,7 @@ func (parser *PdfParser) parseName() (PdfObjectName, error) {
 				parser.skipSpaces()
 			} else {
 				common.Log.Debug("ERROR Name starting with %s (% x)", bb, bb)
-				return PdfObjectName(r.String()), fmt.Errorf("invalid name: (%c)", bb[0])
+				return PdfObjectName(r.String()), parser.tokenError(fmt.Errorf("invalid name: (%c)", bb[0]))
 			}
 		} else {
 			if IsWhiteSpace(bb[0]) {
@@ -406,6 +418,10 @@ func (parser *PdfParser) parseHexString() (*PdfObjectString, error) {
 // Starts with '[' ends with ']'.  Can contain any kinds of direct objects.
 func (parser *PdfParser) parseArray() (*PdfObjectArray, error) {
 	arr := MakeArray()
+	defer parser.leaveNested()
+	if err := parser.enterNested(); err != nil {
+		return arr, err
+	}
 
 	parser.reader.ReadByte()
 
@@ -424,7 +440,10 @@ func (parser *PdfParser) parseArray() (*PdfObjectArray, error) {
 
 		obj, err := parser.parseObject()
 		if err != nil {
-			return arr, err
+			if !parser.recover(err, "]") {
+				return arr, err
+			}
+			continue
 		}
 		arr.Append(obj)
 	}
@@ -569,7 +588,7 @@ func (parser *PdfParser) parseObject() (PdfObject, error) {
 			}
 
 			common.Log.Debug("ERROR Unknown (peek \"%s\")", peekStr)
-			return nil, errors.New("object parsing error - unexpected pattern")
+			return nil, parser.tokenError(errors.New("object parsing error - unexpected pattern"))
 		}
 	}
 }
@@ -581,6 +600,11 @@ func (parser *PdfParser) ParseDict() (*PdfObjectDictionary, error) {
 	dict := MakeDict()
 	dict.parser = parser
 
+	defer parser.leaveNested()
+	if err := parser.enterNested(); err != nil {
+		return nil, err
+	}
+
 	// Pass the '<<'
 	c, _ := parser.reader.ReadByte()
 	if c != '<' {
@@ -612,6 +636,9 @@ func (parser *PdfParser) ParseDict() (*PdfObjectDictionary, error) {
 		keyName, err := parser.parseName()
 		common.Log.Trace("Key: %s", keyName)
 		if err != nil {
+			if parser.recover(err, ">>") {
+				continue
+			}
 			common.Log.Debug("ERROR Returning name err %s", err)
 			return nil, err
 		}
@@ -634,6 +661,9 @@ func (parser *PdfParser) ParseDict() (*PdfObjectDictionary, error) {
 
 		val, err := parser.parseObject()
 		if err != nil {
+			if parser.recover(err, ">>") {
+				continue
+			}
 			return nil, err
 		}
 		dict.Set(keyName, val)
@@ -774,6 +804,9 @@ func (parser *PdfParser) parseXrefTable() (*PdfObjectDictionary, error) {
 						XType:  XrefTypeTableEntry,
 						Offset: first, Generation: gen}
 					parser.xrefs.ObjectMap[curObjNum] = obj
+					if err := parser.checkXrefEntries(len(parser.xrefs.ObjectMap)); err != nil {
+						return nil, err
+					}
 				}
 			}
 
@@ -937,6 +970,13 @@ func (parser *PdfParser) parseXrefStream(xstm *PdfObjectInteger) (*PdfObjectDict
 			return nil, err
 		}
 
+		numEntries := 0
+		for i := 1; i < len(indices); i += 2 {
+			numEntries += indices[i]
+		}
+		if err := parser.checkXrefEntries(numEntries); err != nil {
+			return nil, err
+		}
 		for i := 0; i < len(indices); i += 2 {
 			// add the indices to the list..
 
@@ -949,6 +989,9 @@ func (parser *PdfParser) parseXrefStream(xstm *PdfObjectInteger) (*PdfObjectDict
 		}
 	} else {
 		// If no Index, then assume [0 Size]
+		if err := parser.checkXrefEntries(int(*sizeObj)); err != nil {
+			return nil, err
+		}
 		for i := 0; i < int(*sizeObj); i++ {
 			indexList = append(indexList, i)
 		}
@@ -1052,6 +1095,9 @@ func (parser *PdfParser) parseXrefStream(xstm *PdfObjectInteger) (*PdfObjectDict
 				obj := XrefObject{ObjectNumber: objNum,
 					XType: XrefTypeTableEntry, Offset: n2, Generation: int(n3)}
 				parser.xrefs.ObjectMap[objNum] = obj
+				if err := parser.checkXrefEntries(len(parser.xrefs.ObjectMap)); err != nil {
+					return nil, err
+				}
 			}
 		} else if ftype == 2 {
 			// Object type 2: Compressed object.
@@ -1061,6 +1107,9 @@ func (parser *PdfParser) parseXrefStream(xstm *PdfObjectInteger) (*PdfObjectDict
 					XType: XrefTypeObjectStream, OsObjNumber: int(n2), OsObjIndex: int(n3)}
 				parser.xrefs.ObjectMap[objNum] = obj
 				common.Log.Trace("entry: %+v", obj)
+				if err := parser.checkXrefEntries(len(parser.xrefs.ObjectMap)); err != nil {
+					return nil, err
+				}
 			}
 		} else {
 			common.Log.Debug("ERROR: --------INVALID TYPE XrefStm invalid?-------")
@@ -1191,6 +1240,7 @@ func (parser *PdfParser) seekToEOFMarker(fSize int64) error {
 func (parser *PdfParser) loadXrefs() (*PdfObjectDictionary, error) {
 	parser.xrefs.ObjectMap = make(map[int]XrefObject)
 	parser.objstms = make(objectStreams)
+	parser.objstmCache = nil
 
 	// Get the file size.
 	fSize, err := parser.rs.Seek(0, io.SeekEnd)
@@ -1448,6 +1498,10 @@ func (parser *PdfParser) ParseIndirectObject() (PdfObject, error) {
 	indirect.ObjectNumber = int64(on)
 	indirect.GenerationNumber = int64(gn)
 
+	prevObjNum := parser.objNum
+	parser.objNum = indirect.ObjectNumber
+	defer func() { parser.objNum = prevObjNum }()
+
 	for {
 		bb, err := parser.reader.Peek(2)
 		if err != nil {
@@ -1613,19 +1667,25 @@ func NewParserFromString(txt string) *PdfParser {
 }
 
 // NewParser creates a new parser for a PDF file via ReadSeeker. Loads the cross reference stream and trailer.
-// An error is returned on failure.
+// An error is returned on failure, a *ParseError if the file structure could not be parsed.
 func NewParser(rs io.ReadSeeker) (*PdfParser, error) {
+	return newParser(rs, Limits{})
+}
+
+// newParser creates a new parser for a PDF file via ReadSeeker enforcing the limits `limits`.
+func newParser(rs io.ReadSeeker, limits Limits) (*PdfParser, error) {
 	parser := &PdfParser{
 		rs:                                    rs,
 		ObjCache:                              make(objectCache),
 		streamLengthReferenceLookupInProgress: map[int64]bool{},
+		limits:                                limits,
 	}
 
 	// Parse PDF version.
 	majorVersion, minorVersion, err := parser.parsePdfVersion()
 	if err != nil {
 		common.Log.Error("Unable to parse version: %v", err)
-		return nil, err
+		return nil, newParseError(err, 0, -1)
 	}
 	parser.version.Major = majorVersion
 	parser.version.Minor = minorVersion
@@ -1633,12 +1693,12 @@ func NewParser(rs io.ReadSeeker) (*PdfParser, error) {
 	// Start by reading the xrefs (from bottom).
 	if parser.trailer, err = parser.loadXrefs(); err != nil {
 		common.Log.Debug("ERROR: Failed to load xref table! %s", err)
-		return nil, err
+		return nil, newParseError(err, 0, -1)
 	}
 	common.Log.Trace("Trailer: %s", parser.trailer)
 
 	if len(parser.xrefs.ObjectMap) == 0 {
-		return nil, fmt.Errorf("empty XREF table - Invalid")
+		return nil, newParseError(errors.New("empty XREF table - Invalid"), 0, -1)
 	}
 
 	return parser, nil
@@ -1648,6 +1708,7 @@ func NewParser(rs io.ReadSeeker) (*PdfParser, error) {
 func (parser *PdfParser) resolveReference(ref *PdfObjectReference) (PdfObject, bool, error) {
 	cachedObj, isCached := parser.ObjCache[int(ref.ObjectNumber)]
 	if isCached {
+		parser.stats.ObjectCacheHits++
 		return cachedObj, true, nil
 	}
 	obj, err := parser.LookupByReference(*ref)
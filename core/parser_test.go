@@ -463,6 +463,59 @@ func TestDictParsing4(t *testing.T) {
 }
 */
 
+// Test the resynchronization of the parser after malformed tokens in arrays and dictionaries.
+func TestMalformedTokenRecovery(t *testing.T) {
+	parser := makeParserForText("[1 @x 2 /A]")
+	arr, err := parser.parseArray()
+	require.NoError(t, err)
+	require.Equal(t, "[1 2 /A]", arr.WriteString())
+	require.Len(t, parser.RecoveredErrors(), 1)
+	require.Equal(t, int64(3), parser.RecoveredErrors()[0].Offset)
+
+	parser = makeParserForText("[1 >> 2]")
+	arr, err = parser.parseArray()
+	require.NoError(t, err)
+	require.Equal(t, "[1 2]", arr.WriteString())
+
+	parser = makeParserForText("<< 1 /A 2 /B ) /C 3 >>")
+	dict, err := parser.ParseDict()
+	require.NoError(t, err)
+	require.Equal(t, []PdfObjectName{"A", "C"}, dict.Keys())
+	errs := parser.RecoveredErrors()
+	require.Len(t, errs, 2)
+	require.Equal(t, int64(3), errs[0].Offset)
+	require.Equal(t, int64(13), errs[1].Offset)
+
+	// Missing value before the end of the dictionary.
+	parser = makeParserForText("<< /A 1 /B >> /C")
+	dict, err = parser.ParseDict()
+	require.NoError(t, err)
+	require.Equal(t, []PdfObjectName{"A"}, dict.Keys())
+	parser.skipSpaces()
+	name, err := parser.parseName()
+	require.NoError(t, err)
+	require.Equal(t, PdfObjectName("C"), name)
+
+	// Truncated data cannot be recovered from.
+	parser = makeParserForText("[1 2")
+	_, err = parser.parseArray()
+	require.Error(t, err)
+	require.Empty(t, parser.RecoveredErrors())
+
+	// The errors of indirect objects report the object number.
+	parser = makeParserForText("5 0 obj\n[1 @ 2]\nendobj\n")
+	obj, err := parser.ParseIndirectObject()
+	require.NoError(t, err)
+	ind, ok := obj.(*PdfIndirectObject)
+	require.True(t, ok)
+	require.Equal(t, "[1 2]", ind.PdfObject.WriteString())
+	errs = parser.RecoveredErrors()
+	require.Len(t, errs, 1)
+	require.Equal(t, int64(5), errs[0].ObjNum)
+	require.Equal(t, int64(11), errs[0].Offset)
+	require.Contains(t, errs[0].Error(), "in object 5 at offset 11")
+}
+
 func TestArrayParsing(t *testing.T) {
 	// 7.3.7.
 }
@@ -10,6 +10,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -685,6 +686,14 @@ func (d *PdfObjectDictionary) Keys() []PdfObjectName {
 	return d.keys
 }
 
+// SortKeys sorts the keys of the dictionary in lexicographical order, which
+// is the order in which its entries are written.
+func (d *PdfObjectDictionary) SortKeys() {
+	sort.Slice(d.keys, func(i, j int) bool {
+		return d.keys[i] < d.keys[j]
+	})
+}
+
 // Clear resets the dictionary to an empty state.
 func (d *PdfObjectDictionary) Clear() {
 	d.keys = []PdfObjectName{}
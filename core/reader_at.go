@@ -0,0 +1,203 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"errors"
+	"io"
+)
+
+// defaultReadWindowSize is the size of the windows of data read at once by
+// readerAtSeeker.
+const defaultReadWindowSize = 64 * 1024
+
+// defaultReadWindows is the number of windows kept by readerAtSeeker.
+const defaultReadWindows = 4
+
+// ReaderAtOptions contains the options of the readers returned by
+// NewReaderAtSeekerWithOptions.
+type ReaderAtOptions struct {
+	// WindowSize is the size of the windows of data read at once, 64 KiB if
+	// not positive. Larger windows reduce the number of reads, e.g. of range
+	// requests for readers backed by cloud storage, at the cost of reading
+	// more unneeded data.
+	WindowSize int
+
+	// MaxWindows is the number of windows kept, 4 if not positive. The most
+	// recently used windows are kept, so that moving back and forth between
+	// distant parts of the file, e.g. between the cross-reference table at
+	// the end of the file and the objects, does not read the data again.
+	MaxWindows int
+}
+
+// readerAtSeeker implements io.ReadSeeker over an io.ReaderAt of known size,
+// reading the data by windows of fixed size. The most recently used windows
+// are kept across seeks so that the frequent back and forth moves of the
+// parser within nearby objects do not read the data again.
+// The io.ReaderAt implementation reads from the underlying reader directly,
+// and is as safe for concurrent use as the underlying reader.
+type readerAtSeeker struct {
+	ra   io.ReaderAt
+	size int64
+	pos  int64
+
+	windowSize int
+	maxWindows int
+	windows    []*readWindow // Most recently used first.
+}
+
+// readWindow is a window of data read by readerAtSeeker.
+type readWindow struct {
+	start int64
+	data  []byte
+}
+
+// newReaderAtSeeker returns an io.ReadSeeker reading `ra` of size `size` by
+// windows of `windowSize` bytes, keeping `maxWindows` windows.
+func newReaderAtSeeker(ra io.ReaderAt, size int64, windowSize, maxWindows int) *readerAtSeeker {
+	if windowSize <= 0 {
+		windowSize = defaultReadWindowSize
+	}
+	if maxWindows <= 0 {
+		maxWindows = defaultReadWindows
+	}
+	return &readerAtSeeker{
+		ra:         ra,
+		size:       size,
+		windowSize: windowSize,
+		maxWindows: maxWindows,
+	}
+}
+
+// Read implements io.Reader.
+func (r *readerAtSeeker) Read(p []byte) (int, error) {
+	if r.pos >= r.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	window := r.findWindow(r.pos)
+	if window == nil {
+		// Large reads bypass the windows.
+		if len(p) >= r.windowSize {
+			n, err := r.ReadAt(p, r.pos)
+			r.pos += int64(n)
+			if err == io.EOF && n > 0 {
+				err = nil
+			}
+			return n, err
+		}
+		var err error
+		if window, err = r.fill(r.pos); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, window.data[r.pos-window.start:])
+	r.pos += int64(n)
+	return n, nil
+}
+
+// findWindow returns the window containing `offset`, making it the most
+// recently used one, or nil if not found.
+func (r *readerAtSeeker) findWindow(offset int64) *readWindow {
+	for i, window := range r.windows {
+		if offset >= window.start && offset < window.start+int64(len(window.data)) {
+			copy(r.windows[1:i+1], r.windows[:i])
+			r.windows[0] = window
+			return window
+		}
+	}
+	return nil
+}
+
+// fill reads the window starting at `offset`, replacing the least recently
+// used window if all the windows are used.
+func (r *readerAtSeeker) fill(offset int64) (*readWindow, error) {
+	var buf []byte
+	if len(r.windows) == r.maxWindows {
+		oldest := r.windows[len(r.windows)-1]
+		buf = oldest.data[:cap(oldest.data)]
+		r.windows = r.windows[:len(r.windows)-1]
+	} else {
+		buf = make([]byte, r.windowSize)
+	}
+
+	length := int64(len(buf))
+	if offset+length > r.size {
+		length = r.size - offset
+	}
+	n, err := r.ra.ReadAt(buf[:length], offset)
+	if n < int(length) {
+		if err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	window := &readWindow{start: offset, data: buf[:n]}
+	r.windows = append([]*readWindow{window}, r.windows...)
+	return window, nil
+}
+
+// Seek implements io.Seeker. Seeking does not read any data.
+func (r *readerAtSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.pos = offset
+	return offset, nil
+}
+
+// ReadAt implements io.ReaderAt.
+func (r *readerAtSeeker) ReadAt(p []byte, offset int64) (int, error) {
+	if offset >= r.size {
+		return 0, io.EOF
+	}
+	if remaining := r.size - offset; int64(len(p)) > remaining {
+		n, err := r.ra.ReadAt(p[:remaining], offset)
+		if err == nil {
+			err = io.EOF
+		}
+		return n, err
+	}
+	return r.ra.ReadAt(p, offset)
+}
+
+// NewReaderAtSeeker returns an io.ReadSeeker reading the `size` bytes of
+// `ra` by windows, so that parsing a file, e.g. an *os.File, reads only the
+// parts needed instead of loading it in memory. The returned reader also
+// implements io.ReaderAt, reading `ra` directly.
+func NewReaderAtSeeker(ra io.ReaderAt, size int64) io.ReadSeeker {
+	return newReaderAtSeeker(ra, size, defaultReadWindowSize, defaultReadWindows)
+}
+
+// NewReaderAtSeekerWithOptions returns an io.ReadSeeker reading the `size`
+// bytes of `ra` by windows as NewReaderAtSeeker, with the window options
+// `opts`. Any io.ReaderAt can be read, e.g. readers issuing HTTP range
+// requests to cloud storage, so that loading the metadata or a few pages of
+// a document does not download the whole file.
+func NewReaderAtSeekerWithOptions(ra io.ReaderAt, size int64, opts ReaderAtOptions) io.ReadSeeker {
+	return newReaderAtSeeker(ra, size, opts.WindowSize, opts.MaxWindows)
+}
+
+// NewParserFromReaderAt creates a new parser for a PDF file of `size` bytes
+// read from `ra`, e.g. an *os.File. The data is read by windows as needed
+// rather than being loaded in memory, and `ra` must remain readable while the
+// parser is used. Loads the cross reference stream and trailer.
+// An error is returned on failure.
+func NewParserFromReaderAt(ra io.ReaderAt, size int64) (*PdfParser, error) {
+	return NewParser(NewReaderAtSeeker(ra, size))
+}
@@ -0,0 +1,149 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingReaderAt counts the calls to ReadAt of the underlying reader.
+type countingReaderAt struct {
+	io.ReaderAt
+	calls int
+}
+
+func (r *countingReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	r.calls++
+	return r.ReaderAt.ReadAt(p, offset)
+}
+
+func TestReaderAtSeeker(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	ra := &countingReaderAt{ReaderAt: bytes.NewReader(data)}
+	r := newReaderAtSeeker(ra, int64(len(data)), 64, 1)
+	expected := bytes.NewReader(data)
+
+	// Reads and seeks give the same results as a bytes.Reader, whether
+	// within a window, across windows or bypassing the window.
+	steps := []struct {
+		offset int64
+		whence int
+		length int
+	}{
+		{0, io.SeekStart, 10},
+		{0, io.SeekCurrent, 100},
+		{30, io.SeekStart, 64},
+		{-20, io.SeekCurrent, 5},
+		{-10, io.SeekEnd, 64},
+		{900, io.SeekStart, 200},
+		{500, io.SeekStart, 1},
+	}
+	for _, step := range steps {
+		pos, err := r.Seek(step.offset, step.whence)
+		require.NoError(t, err)
+		expectedPos, err := expected.Seek(step.offset, step.whence)
+		require.NoError(t, err)
+		require.Equal(t, expectedPos, pos)
+
+		got, err := ioutil.ReadAll(io.LimitReader(r, int64(step.length)))
+		require.NoError(t, err)
+		want, err := ioutil.ReadAll(io.LimitReader(expected, int64(step.length)))
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	_, err := r.Seek(0, io.SeekEnd)
+	require.NoError(t, err)
+	n, err := r.Read(make([]byte, 1))
+	require.Equal(t, 0, n)
+	require.Equal(t, io.EOF, err)
+	_, err = r.Seek(-1, io.SeekStart)
+	require.Error(t, err)
+
+	// Reading again within the current window does not read any data.
+	_, err = r.Seek(500, io.SeekStart)
+	require.NoError(t, err)
+	_, err = r.Read(make([]byte, 4))
+	require.NoError(t, err)
+	calls := ra.calls
+	_, err = r.Seek(-4, io.SeekCurrent)
+	require.NoError(t, err)
+	_, err = r.Read(make([]byte, 8))
+	require.NoError(t, err)
+	require.Equal(t, calls, ra.calls)
+
+	// ReadAt is clamped to the size.
+	p := make([]byte, 10)
+	n, err = r.ReadAt(p, 995)
+	require.Equal(t, 5, n)
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, data[995:], p[:n])
+}
+
+func TestReaderAtSeekerWindows(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	ra := &countingReaderAt{ReaderAt: bytes.NewReader(data)}
+	r := NewReaderAtSeekerWithOptions(ra, int64(len(data)), ReaderAtOptions{WindowSize: 100, MaxWindows: 2})
+
+	read := func(offset int64) {
+		_, err := r.Seek(offset, io.SeekStart)
+		require.NoError(t, err)
+		p := make([]byte, 10)
+		_, err = io.ReadFull(r, p)
+		require.NoError(t, err)
+		require.Equal(t, data[offset:offset+10], p)
+	}
+
+	// Moving back and forth between two windows reads each window once.
+	for i := 0; i < 3; i++ {
+		read(10)
+		read(950)
+	}
+	require.Equal(t, 2, ra.calls)
+
+	// The least recently used window is replaced.
+	read(500)
+	read(950)
+	require.Equal(t, 3, ra.calls)
+	read(10)
+	require.Equal(t, 4, ra.calls)
+}
+
+func TestNewParserFromReaderAt(t *testing.T) {
+	f, err := os.Open("./testdata/minimal.pdf")
+	require.NoError(t, err)
+	defer f.Close()
+	info, err := f.Stat()
+	require.NoError(t, err)
+
+	parser, err := NewParserFromReaderAt(f, info.Size())
+	require.NoError(t, err)
+	data, err := ioutil.ReadFile(f.Name())
+	require.NoError(t, err)
+	expected, err := NewParser(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	require.Equal(t, expected.GetObjectNums(), parser.GetObjectNums())
+	for _, num := range expected.GetObjectNums() {
+		obj, err := parser.LookupByNumber(num)
+		require.NoError(t, err)
+		expectedObj, err := expected.LookupByNumber(num)
+		require.NoError(t, err)
+		require.Equal(t, expectedObj.WriteString(), obj.WriteString())
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package core
+
+import (
+	"io"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"golang.org/x/xerrors"
+)
+
+// maxRecoveredErrors is the maximum number of recovered errors kept by the parser. Parsing carries
+// on past this number but the following errors are only logged.
+const maxRecoveredErrors = 100
+
+// RecoveredErrors returns the errors of the malformed tokens which were skipped when parsing
+// arrays and dictionaries, in the order they were encountered. The offsets and object numbers of
+// the errors locate the corrupted data in the file. At most 100 errors are kept.
+func (parser *PdfParser) RecoveredErrors() []*ParseError {
+	return parser.recovered
+}
+
+// tokenOffset returns the file offset of the token being parsed, -1 if unknown, i.e. when
+// parsing the decoded data of object streams.
+func (parser *PdfParser) tokenOffset() int64 {
+	if parser.inObjectStream || parser.rs == nil {
+		return -1
+	}
+	return parser.GetFileOffset()
+}
+
+// tokenError returns a ParseError wrapping `err`, the error of the malformed token at the current
+// position.
+func (parser *PdfParser) tokenError(err error) error {
+	return &ParseError{Offset: parser.tokenOffset(), ObjNum: parser.objNum, Err: err}
+}
+
+// recover attempts to resume the parsing of an array or a dictionary, ended by `closing`, after
+// the error `err`. It returns true if `err` is the error of a malformed token, in which case the
+// token is skipped and the error recorded, see RecoveredErrors. Other errors, such as I/O errors
+// or exceeded limits, cannot be recovered from.
+func (parser *PdfParser) recover(err error, closing string) bool {
+	var parseErr *ParseError
+	if !xerrors.As(err, &parseErr) || xerrors.Is(err, io.EOF) || xerrors.Is(err, ErrLimitExceeded) {
+		return false
+	}
+	common.Log.Debug("WARNING: skipping malformed token: %v", err)
+	if len(parser.recovered) < maxRecoveredErrors {
+		parser.recovered = append(parser.recovered, parseErr)
+	}
+	parser.skipToken(closing)
+	return true
+}
+
+// skipToken discards the malformed token at the current position, up to the next whitespace or
+// delimiter. At least one byte is discarded, unless the current position is at the `closing`
+// marker of the array or dictionary being parsed, so that parsing always progresses.
+func (parser *PdfParser) skipToken(closing string) {
+	for n := 0; ; n++ {
+		bb, _ := parser.reader.Peek(len(closing))
+		if len(bb) == 0 {
+			return
+		}
+		if n == 0 {
+			if string(bb) == closing {
+				return
+			}
+		} else if IsWhiteSpace(bb[0]) || IsDelimiter(bb[0]) {
+			return
+		}
+		parser.reader.Discard(1)
+	}
+}
@@ -177,6 +177,9 @@ func (parser *PdfParser) repairRebuildXrefsTopDown() (*XrefTable, error) {
 				xrefEntry.Generation = int(genNum)
 				xrefEntry.Offset = objOffset
 				xrefTable.ObjectMap[objNum] = xrefEntry
+				if err := parser.checkXrefEntries(len(xrefTable.ObjectMap)); err != nil {
+					return nil, err
+				}
 			}
 		}
 
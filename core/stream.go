@@ -12,7 +12,21 @@ import (
 )
 
 // NewEncoderFromStream creates a StreamEncoder based on the stream's dictionary.
+// If the stream was parsed with a MaxDecodedStreamSize limit (see Limits), the Flate and LZW
+// encoders returned stop decoding once the limit is exceeded.
 func NewEncoderFromStream(streamObj *PdfObjectStream) (StreamEncoder, error) {
+	encoder, err := newEncoderFromStream(streamObj)
+	if err != nil {
+		return nil, err
+	}
+	if max := streamLimits(streamObj).MaxDecodedStreamSize; max > 0 {
+		setDecodeLimit(encoder, max)
+	}
+	return encoder, nil
+}
+
+// newEncoderFromStream creates a StreamEncoder based on the stream's dictionary.
+func newEncoderFromStream(streamObj *PdfObjectStream) (StreamEncoder, error) {
 	filterObj := TraceToDirectObject(streamObj.PdfObjectDictionary.Get("Filter"))
 	if filterObj == nil {
 		// No filter, return raw data back.
@@ -80,7 +94,8 @@ func NewEncoderFromStream(streamObj *PdfObjectStream) (StreamEncoder, error) {
 }
 
 // DecodeStream decodes the stream data and returns the decoded data.
-// An error is returned upon failure.
+// An error is returned upon failure, a *LimitError if the decoded data exceeds the
+// MaxDecodedStreamSize limit of the parser of the stream.
 func DecodeStream(streamObj *PdfObjectStream) ([]byte, error) {
 	common.Log.Trace("Decode stream")
 
@@ -96,6 +111,10 @@ func DecodeStream(streamObj *PdfObjectStream) ([]byte, error) {
 		common.Log.Debug("ERROR: Stream decoding failed: %v", err)
 		return nil, err
 	}
+	if err := checkDecodedSize(len(decoded), streamLimits(streamObj).MaxDecodedStreamSize); err != nil {
+		common.Log.Debug("ERROR: Stream decoding failed: %v", err)
+		return nil, err
+	}
 
 	return decoded, nil
 }
@@ -15,6 +15,8 @@ import (
 	"sort"
 	"strconv"
 
+	"golang.org/x/xerrors"
+
 	"github.com/unidoc/unipdf/v3/common"
 )
 
@@ -67,6 +69,8 @@ func ResolveReference(obj PdfObject) PdfObject {
 // references with indirect objects.
 // Optionally a map of already deep-resolved objects can be provided via `traversed`. The `traversed` map
 // is updated while traversing the objects to avoid traversing same objects multiple times.
+// A *LimitError is returned if a limit of the parser of the references is exceeded, e.g. if the
+// depth of the objects exceeds MaxRecursionDepth.
 func ResolveReferencesDeep(o PdfObject, traversed map[PdfObject]struct{}) error {
 	if traversed == nil {
 		traversed = map[PdfObject]struct{}{}
@@ -74,6 +78,29 @@ func ResolveReferencesDeep(o PdfObject, traversed map[PdfObject]struct{}) error
 	return resolveReferencesDeep(o, 0, traversed)
 }
 
+// resolveDeepReference resolves the reference `ref` at depth `depth` as ref.Resolve does, returning
+// an error if a limit of the parser of the reference is exceeded, e.g. MaxRecursionDepth.
+func resolveDeepReference(ref *PdfObjectReference, depth int) (PdfObject, error) {
+	if ref.parser == nil {
+		return MakeNull(), nil
+	}
+	if max := ref.parser.limits.MaxRecursionDepth; max > 0 && depth >= max {
+		return nil, &LimitError{Limit: "MaxRecursionDepth", Value: int64(max)}
+	}
+	obj, _, err := ref.parser.resolveReference(ref)
+	if err != nil {
+		if xerrors.Is(err, ErrLimitExceeded) {
+			return nil, err
+		}
+		common.Log.Debug("ERROR resolving reference: %v - returning null object", err)
+		return MakeNull(), nil
+	}
+	if obj == nil {
+		return MakeNull(), nil
+	}
+	return obj, nil
+}
+
 func resolveReferencesDeep(o PdfObject, depth int, traversed map[PdfObject]struct{}) error {
 	common.Log.Trace("Traverse object data (depth = %d)", depth)
 	if _, isTraversed := traversed[o]; isTraversed {
@@ -97,9 +124,12 @@ func resolveReferencesDeep(o PdfObject, depth int, traversed map[PdfObject]struc
 		for _, name := range dict.Keys() {
 			v := dict.Get(name)
 			if ref, isRef := v.(*PdfObjectReference); isRef {
-				resolvedObj := ref.Resolve()
+				resolvedObj, err := resolveDeepReference(ref, depth)
+				if err != nil {
+					return err
+				}
 				dict.Set(name, resolvedObj)
-				err := resolveReferencesDeep(resolvedObj, depth+1, traversed)
+				err = resolveReferencesDeep(resolvedObj, depth+1, traversed)
 				if err != nil {
 					return err
 				}
@@ -116,9 +146,12 @@ func resolveReferencesDeep(o PdfObject, depth int, traversed map[PdfObject]struc
 		common.Log.Trace("- array: %s", arr)
 		for idx, v := range arr.Elements() {
 			if ref, isRef := v.(*PdfObjectReference); isRef {
-				resolvedObj := ref.Resolve()
+				resolvedObj, err := resolveDeepReference(ref, depth)
+				if err != nil {
+					return err
+				}
 				arr.Set(idx, resolvedObj)
-				err := resolveReferencesDeep(resolvedObj, depth+1, traversed)
+				err = resolveReferencesDeep(resolvedObj, depth+1, traversed)
 				if err != nil {
 					return err
 				}
@@ -44,6 +44,15 @@ type Block struct {
 
 	// Block annotations.
 	annotations []*model.PdfAnnotation
+
+	// Luminosity soft mask applied to the block contents.
+	softMask *Block
+
+	// cached specifies whether the block contents are drawn through a Form
+	// XObject, created when the block is first drawn and reset when the
+	// contents change.
+	cached bool
+	form   *core.PdfObjectStream
 }
 
 // NewBlock creates a new Block with specified width and height.
@@ -123,6 +132,67 @@ func (blk *Block) AddAnnotation(annotation *model.PdfAnnotation) {
 	blk.annotations = append(blk.annotations, annotation)
 }
 
+// SetCached sets whether the block contents are converted to a Form XObject
+// when the block is first drawn. The pages the block is drawn on then
+// reference the Form XObject instead of containing a copy of the block
+// contents, which reduces the size of documents drawing the same content on
+// many pages, e.g. a logo or a header table. The contents are clipped to the
+// block bounds. The Form XObject is created again if the block contents change.
+func (blk *Block) SetCached(cached bool) {
+	blk.cached = cached
+	blk.form = nil
+}
+
+// IsCached returns true if the block contents are drawn through a Form
+// XObject, see SetCached.
+func (blk *Block) IsCached() bool {
+	return blk.cached
+}
+
+// makeForm returns a Form XObject with the contents and resources of the
+// block, including its soft mask.
+func (blk *Block) makeForm() (*core.PdfObjectStream, error) {
+	src := blk
+	if blk.softMask != nil {
+		src = blk.duplicate()
+		if err := src.applySoftMask(blk.softMask); err != nil {
+			return nil, err
+		}
+	}
+
+	form := model.NewXObjectForm()
+	form.BBox = core.MakeArrayFromFloats([]float64{0, 0, blk.width, blk.height})
+	form.Resources = src.resources
+	if err := form.SetContentStream(src.contents.Bytes(), core.NewFlateEncoder()); err != nil {
+		return nil, err
+	}
+	stream, ok := form.ToPdfObject().(*core.PdfObjectStream)
+	if !ok {
+		return nil, errors.New("invalid form object")
+	}
+	return stream, nil
+}
+
+// formBlock returns a copy of the block whose contents paint the Form XObject
+// of the block, creating the Form XObject if needed.
+func (blk *Block) formBlock() (*Block, error) {
+	if blk.form == nil {
+		form, err := blk.makeForm()
+		if err != nil {
+			return nil, err
+		}
+		blk.form = form
+	}
+
+	dup := blk.duplicate()
+	dup.resources = model.NewPdfPageResources()
+	if err := dup.resources.SetXObjectByName("Fm1", blk.form); err != nil {
+		return nil, err
+	}
+	dup.contents = contentstream.NewContentCreator().Add_Do("Fm1").Operations()
+	return dup, nil
+}
+
 // duplicate duplicates the block with a new copy of the operations list.
 func (blk *Block) duplicate() *Block {
 	dup := &Block{}
@@ -169,7 +239,20 @@ func (blk *Block) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, er
 		ctx.Y += rotatedHeight
 	}
 
-	dup := blk.duplicate()
+	var dup *Block
+	if blk.cached {
+		var err error
+		if dup, err = blk.formBlock(); err != nil {
+			return nil, ctx, err
+		}
+	} else {
+		dup = blk.duplicate()
+		if blk.softMask != nil {
+			if err := dup.applySoftMask(blk.softMask); err != nil {
+				return nil, ctx, err
+			}
+		}
+	}
 	contents := append(*cc.Operations(), *dup.contents...)
 	contents.WrapIfNeeded()
 	dup.contents = &contents
@@ -177,6 +260,74 @@ func (blk *Block) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, er
 	return []*Block{dup}, ctx, nil
 }
 
+// SetSoftMask sets a soft mask controlling the opacity of the block contents
+// through the luminosity of the `mask` block contents, drawn over the block
+// with its lower left corner at the block origin. The block contents are
+// opaque where the mask is white and transparent where the mask is black or
+// not painted. The soft mask is removed if `mask` is nil.
+func (blk *Block) SetSoftMask(mask *Block) {
+	blk.softMask = mask
+	blk.form = nil
+}
+
+// applySoftMask wraps the block contents in a graphics state setting a
+// luminosity soft mask defined by the contents of `mask`.
+func (blk *Block) applySoftMask(mask *Block) error {
+	group := model.NewXObjectForm()
+	group.BBox = core.MakeArrayFromFloats([]float64{0, 0, mask.width, mask.height})
+	group.Resources = mask.resources
+	group.SetTransparencyGroup(model.NewPdfTransparencyGroup(model.NewPdfColorspaceDeviceRGB()))
+	if err := group.SetContentStream(mask.contents.Bytes(), core.NewFlateEncoder()); err != nil {
+		return err
+	}
+
+	gs := model.NewPdfExtGState()
+	gs.SMask = model.NewPdfSoftMask(model.PdfSoftMaskLuminosity, group)
+	return blk.applyExtGState(gs)
+}
+
+// applyBlendMode wraps the block contents in a graphics state setting the
+// blend mode `mode`. The contents are left unchanged if `mode` is empty.
+func (blk *Block) applyBlendMode(mode model.PdfBlendMode) error {
+	if mode == "" {
+		return nil
+	}
+	gs := model.NewPdfExtGState()
+	gs.BM = mode
+	return blk.applyExtGState(gs)
+}
+
+// applyExtGState wraps the block contents in the graphics state `gs`. The
+// block resources are copied, leaving the resources of the original block
+// intact.
+func (blk *Block) applyExtGState(gs *model.PdfExtGState) error {
+	resources := *blk.resources
+	extGState := core.MakeDict()
+	if dict, ok := core.GetDict(resources.ExtGState); ok {
+		extGState.Merge(dict)
+	}
+	resources.ExtGState = extGState
+
+	// Find an available GS name.
+	i := 0
+	gsName := core.PdfObjectName(fmt.Sprintf("GS%d", i))
+	for resources.HasExtGState(gsName) {
+		i++
+		gsName = core.PdfObjectName(fmt.Sprintf("GS%d", i))
+	}
+	if err := resources.AddExtGState(gsName, core.MakeIndirectObject(gs.ToPdfObject())); err != nil {
+		return err
+	}
+	blk.resources = &resources
+
+	cc := contentstream.NewContentCreator()
+	cc.Add_gs(gsName)
+	contents := append(*cc.Operations(), *blk.contents...)
+	contents.WrapIfNeeded()
+	blk.contents = &contents
+	return nil
+}
+
 // Height returns the Block's height.
 func (blk *Block) Height() float64 {
 	return blk.height
@@ -199,6 +350,7 @@ func (blk *Block) addContents(operations *contentstream.ContentStreamOperations)
 	blk.contents.WrapIfNeeded()
 	operations.WrapIfNeeded()
 	*blk.contents = append(*blk.contents, *operations...)
+	blk.form = nil
 }
 
 // addContentsByString adds contents to a block by contents string.
@@ -212,6 +364,7 @@ func (blk *Block) addContentsByString(contents string) error {
 	blk.contents.WrapIfNeeded()
 	operations.WrapIfNeeded()
 	*blk.contents = append(*blk.contents, *operations...)
+	blk.form = nil
 
 	return nil
 }
@@ -247,6 +400,7 @@ func (blk *Block) Scale(sx, sy float64) {
 
 	blk.width *= sx
 	blk.height *= sy
+	blk.form = nil
 }
 
 // ScaleToWidth scales the Block to a specified width, maintaining the same aspect ratio.
@@ -269,6 +423,7 @@ func (blk *Block) translate(tx, ty float64) {
 
 	*blk.contents = append(*ops, *blk.contents...)
 	blk.contents.WrapIfNeeded()
+	blk.form = nil
 }
 
 // drawToPage draws the block on a PdfPage. Generates the content streams and appends to the PdfPage's content
@@ -377,6 +532,7 @@ func (blk *Block) mergeBlocks(toAdd *Block) error {
 	if err != nil {
 		return err
 	}
+	blk.form = nil
 
 	// Merge annotations.
 	for _, annot := range toAdd.annotations {
@@ -0,0 +1,167 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"bytes"
+	goimage "image"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestBlockSoftMask(t *testing.T) {
+	c := New()
+	c.NewPage()
+
+	block := NewBlock(200, 100)
+	rect := c.NewRectangle(0, 0, 200, 100)
+	rect.SetFillColor(ColorRed)
+	require.NoError(t, block.Draw(rect))
+
+	// Fade out the rectangle from left to right.
+	mask := NewBlock(200, 100)
+	maskRect := c.NewRectangle(0, 0, 200, 100)
+	maskRect.SetFillShading(c.NewLinearShading(ColorWhite, ColorBlack))
+	maskRect.SetBorderWidth(0)
+	require.NoError(t, mask.Draw(maskRect))
+	block.SetSoftMask(mask)
+
+	block.SetPos(50, 50)
+	require.NoError(t, c.Draw(block))
+	require.Nil(t, block.resources.ExtGState)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Write(&buf))
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	page, err := r.GetPage(1)
+	require.NoError(t, err)
+	content, err := page.GetAllContentStreams()
+	require.NoError(t, err)
+	require.Contains(t, content, "1 0 0 1 50 642 cm\nq\n/GS0 gs\n")
+
+	softMask, err := page.Resources.GetSoftMask("GS0")
+	require.NoError(t, err)
+	require.NotNil(t, softMask)
+	require.Equal(t, model.PdfSoftMaskLuminosity, softMask.S)
+	group, err := softMask.G.GetTransparencyGroup()
+	require.NoError(t, err)
+	require.IsType(t, &model.PdfColorspaceDeviceRGB{}, group.CS)
+	maskContent, err := softMask.G.GetContentStream()
+	require.NoError(t, err)
+	require.Contains(t, string(maskContent), "/Sh1 sh")
+	_, ok := softMask.G.Resources.GetShadingByName("Sh1")
+	require.True(t, ok)
+}
+
+func TestBlendMode(t *testing.T) {
+	c := New()
+	c.NewPage()
+
+	rect := c.NewRectangle(50, 50, 200, 100)
+	rect.SetFillColor(ColorRed)
+	rect.SetBlendMode(model.PdfBlendModeMultiply)
+	require.NoError(t, c.Draw(rect))
+
+	line := c.NewLine(50, 200, 250, 200)
+	require.NoError(t, c.Draw(line))
+
+	img, err := c.NewImageFromGoImage(goimage.NewRGBA(goimage.Rect(0, 0, 4, 4)))
+	require.NoError(t, err)
+	img.SetPos(50, 250)
+	img.SetOpacity(0.5)
+	img.SetBlendMode(model.PdfBlendModeScreen)
+	require.NoError(t, c.Draw(img))
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Write(&buf))
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	page, err := r.GetPage(1)
+	require.NoError(t, err)
+	content, err := page.GetAllContentStreams()
+	require.NoError(t, err)
+	ops, err := contentstream.NewContentStreamParser(content).Parse()
+	require.NoError(t, err)
+
+	var states []contentstream.GraphicsState
+	proc := contentstream.NewContentStreamProcessor(*ops)
+	proc.AddHandler(contentstream.HandlerConditionEnumAllOperands, "",
+		func(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState,
+			resources *model.PdfPageResources) error {
+			switch op.Operand {
+			case "B", "f", "Do":
+				states = append(states, gs)
+			}
+			return nil
+		})
+	require.NoError(t, proc.Process(page.Resources))
+	require.Len(t, states, 3)
+	require.Equal(t, model.PdfBlendModeMultiply, states[0].BlendMode)
+	require.Equal(t, model.PdfBlendModeNormal, states[1].BlendMode)
+	require.Equal(t, model.PdfBlendModeScreen, states[2].BlendMode)
+	require.Equal(t, 0.5, states[2].FillAlpha)
+}
+
+func TestBlockCached(t *testing.T) {
+	c := New()
+
+	header := NewBlock(300, 50)
+	p := c.NewParagraph("Quarterly report")
+	p.SetPos(10, 10)
+	require.NoError(t, header.Draw(p))
+	header.SetCached(true)
+	require.True(t, header.IsCached())
+	header.SetPos(50, 20)
+
+	for i := 0; i < 3; i++ {
+		c.NewPage()
+		require.NoError(t, c.Draw(header))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	var form *core.PdfObjectStream
+	for i := 1; i <= 3; i++ {
+		page, err := r.GetPage(i)
+		require.NoError(t, err)
+		content, err := page.GetAllContentStreams()
+		require.NoError(t, err)
+		require.Contains(t, content, "/Fm1 Do")
+		require.NotContains(t, content, "Quarterly")
+
+		stream, xtype := page.Resources.GetXObjectByName("Fm1")
+		require.Equal(t, model.XObjectTypeForm, xtype)
+		if form == nil {
+			form = stream
+		}
+		// All the pages share the same Form XObject.
+		require.True(t, form == stream)
+	}
+
+	xform, err := model.NewXObjectFormFromStream(form)
+	require.NoError(t, err)
+	formContent, err := xform.GetContentStream()
+	require.NoError(t, err)
+	require.Contains(t, string(formContent), "(Quarterly)")
+	require.NotNil(t, xform.Resources.Font)
+
+	// Changing the contents resets the Form XObject.
+	formObj := header.form
+	require.NotNil(t, formObj)
+	require.NoError(t, header.Draw(c.NewParagraph("Draft")))
+	require.Nil(t, header.form)
+}
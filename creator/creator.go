@@ -15,6 +15,7 @@ import (
 	"github.com/unidoc/unipdf/v3/common"
 	"github.com/unidoc/unipdf/v3/core"
 	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/render"
 )
 
 // Creator is a wrapper around functionality for creating PDF reports and/or adding new
@@ -72,6 +73,10 @@ type Creator struct {
 	// Fonts that have been enabled for subsetting prior to write.
 	subsetFonts []*model.PdfFont
 
+	// Width in pixels of the page thumbnails generated prior to write.
+	// Thumbnails are not generated if 0.
+	thumbnailWidth int
+
 	// Default fonts used by all components instantiated through the creator.
 	defaultFontRegular *model.PdfFont
 	defaultFontBold    *model.PdfFont
@@ -200,6 +205,15 @@ func (c *Creator) TOC() *TOC {
 	return c.toc
 }
 
+// Outline returns the outline generated by the creator. Items can be added
+// to the outline or modified before the document is written. The destinations
+// of the items added to the outline are relative to the content pages of the
+// creator, with the Y coordinate measured from the top of the page, like the
+// destinations of the chapter outline items.
+func (c *Creator) Outline() *model.Outline {
+	return c.outline
+}
+
 // SetTOC sets the table of content component of the creator.
 // This method should be used when building a custom table of contents.
 func (c *Creator) SetTOC(toc *TOC) {
@@ -692,6 +706,13 @@ func (c *Creator) Write(ws io.Writer) error {
 		}
 	}
 
+	if c.thumbnailWidth > 0 {
+		if err := c.generateThumbnails(); err != nil {
+			common.Log.Debug("ERROR: Could not generate thumbnails: %v", err)
+			return err
+		}
+	}
+
 	// Pdf Writer access hook. Can be used to encrypt, etc. via the PdfWriter instance.
 	if c.pdfWriterAccessFunc != nil {
 		err := c.pdfWriterAccessFunc(&pdfWriter)
@@ -741,6 +762,42 @@ func (c *Creator) EnableFontSubsetting(font *model.PdfFont) {
 	c.subsetFonts = append(c.subsetFonts, font)
 }
 
+// EnableThumbnails enables the generation of page thumbnails of width `width`
+// pixels when the creator output is written. The thumbnails are rendered from
+// the pages and embedded as the page Thumb images, which are used by some
+// viewers for navigation. Pages which already have a thumbnail, e.g. attached
+// with model.PdfPage.SetThumbnail, are left unchanged.
+// Thumbnail generation is disabled if `width` is 0.
+func (c *Creator) EnableThumbnails(width int) {
+	c.thumbnailWidth = width
+}
+
+// generateThumbnails renders and sets the thumbnails of the pages which do not
+// have one.
+func (c *Creator) generateThumbnails() error {
+	device := render.NewImageDevice()
+	device.OutputWidth = c.thumbnailWidth
+
+	for idx, page := range c.pages {
+		if page.Thumb != nil {
+			continue
+		}
+		goimg, err := device.Render(page)
+		if err != nil {
+			common.Log.Debug("ERROR: rendering page %d: %v", idx+1, err)
+			return err
+		}
+		img, err := model.ImageHandling.NewImageFromGoImage(goimg)
+		if err != nil {
+			return err
+		}
+		if err := page.SetThumbnail(img, core.NewDCTEncoder()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // WriteToFile writes the Creator output to file specified by path.
 func (c *Creator) WriteToFile(outputPath string) error {
 	fWrite, err := os.Create(outputPath)
@@ -865,6 +922,18 @@ func (c *Creator) NewEllipse(xc, yc, width, height float64) *Ellipse {
 	return newEllipse(xc, yc, width, height)
 }
 
+// NewLinearShading creates a new linear gradient from color `start` to `end`,
+// which can be used to fill rectangles and ellipses.
+func (c *Creator) NewLinearShading(start, end Color) *LinearShading {
+	return newLinearShading(start, end)
+}
+
+// NewRadialShading creates a new radial gradient from color `start` at the
+// center to `end`, which can be used to fill rectangles and ellipses.
+func (c *Creator) NewRadialShading(start, end Color) *RadialShading {
+	return newRadialShading(start, end)
+}
+
 // NewCurve returns new instance of Curve between points (x1,y1) and (x2, y2) with control point (cx,cy).
 func (c *Creator) NewCurve(x1, y1, cx, cy, x2, y2 float64) *Curve {
 	return newCurve(x1, y1, cx, cy, x2, y2)
@@ -875,11 +944,18 @@ func (c *Creator) NewImage(img *model.Image) (*Image, error) {
 	return newImage(img)
 }
 
-// NewImageFromData creates an Image from image data.
+// NewImageFromData creates an Image from image data (e.g. JPEG or PNG). The
+// format of the data is detected from its content.
 func (c *Creator) NewImageFromData(data []byte) (*Image, error) {
 	return newImageFromData(data)
 }
 
+// NewImageFromReader creates an Image from the image data (e.g. JPEG or PNG)
+// read from `r`. The format of the data is detected from its content.
+func (c *Creator) NewImageFromReader(r io.Reader) (*Image, error) {
+	return newImageFromReader(r)
+}
+
 // NewImageFromFile creates an Image from a file.
 func (c *Creator) NewImageFromFile(path string) (*Image, error) {
 	return newImageFromFile(path)
@@ -12,10 +12,14 @@ package creator
 import (
 	"bytes"
 	"crypto/md5"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	goimage "image"
+	gocolor "image/color"
+	"image/jpeg"
+	"image/png"
 	"io/ioutil"
 	"math"
 	"os"
@@ -34,6 +38,7 @@ import (
 	"github.com/unidoc/unipdf/v3/model"
 	"github.com/unidoc/unipdf/v3/model/optimize"
 
+	"github.com/unidoc/unipdf/v3/internal/ccittfax"
 	"github.com/unidoc/unipdf/v3/internal/testutils"
 )
 
@@ -140,6 +145,222 @@ func TestImage1(t *testing.T) {
 	testWriteAndRender(t, creator, "1.pdf")
 }
 
+// TestImageFromReader tests loading images from readers, with the data of JPEG
+// images embedded without re-encoding.
+func TestImageFromReader(t *testing.T) {
+	c := New()
+
+	f, err := os.Open(testImageFile1)
+	require.NoError(t, err)
+	defer f.Close()
+	img, err := c.NewImageFromReader(f)
+	require.NoError(t, err)
+	require.Nil(t, img.jpegData)
+	require.NoError(t, c.Draw(img))
+	_, ok := img.xobj.Filter.(*core.FlateEncoder)
+	require.True(t, ok)
+
+	goimg := goimage.NewRGBA(goimage.Rect(0, 0, 16, 8))
+	for i := range goimg.Pix {
+		goimg.Pix[i] = byte(i)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, goimg, nil))
+	jpegData := buf.Bytes()
+
+	img, err = c.NewImageFromReader(bytes.NewReader(jpegData))
+	require.NoError(t, err)
+	require.Equal(t, 16.0, img.Width())
+	require.Equal(t, 8.0, img.Height())
+	require.NoError(t, c.Draw(img))
+	_, ok = img.xobj.Filter.(*core.DCTEncoder)
+	require.True(t, ok)
+	require.Equal(t, jpegData, img.xobj.Stream)
+
+	// Images with an encoder are re-encoded.
+	img, err = c.NewImageFromData(jpegData)
+	require.NoError(t, err)
+	img.SetEncoder(core.NewFlateEncoder())
+	require.NoError(t, c.Draw(img))
+	_, ok = img.xobj.Filter.(*core.FlateEncoder)
+	require.True(t, ok)
+
+	_, err = c.NewImageFromReader(strings.NewReader("not an image"))
+	require.Error(t, err)
+
+	testWriteAndRender(t, c, "image_from_reader.pdf")
+}
+
+// TestImageJPEGPassThrough tests embedding JPEG images without decoding them.
+func TestImageJPEGPassThrough(t *testing.T) {
+	c := New()
+
+	// CMYK JPEG image with an Adobe APP14 marker. The image data is not
+	// decoded when embedded, only the structure of the image is checked.
+	cmyk := []byte{0xFF, 0xD8,
+		0xFF, 0xEE, 0x00, 0x0E, 'A', 'd', 'o', 'b', 'e', 0x00, 0x64, 0x00, 0x00, 0x00, 0x00, 0x02,
+		0xFF, 0xDB, 0x00, 0x43, 0x00}
+	cmyk = append(cmyk, bytes.Repeat([]byte{0x01}, 64)...)
+	cmyk = append(cmyk,
+		0xFF, 0xC4, 0x00, 0x14, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0xFF, 0xC0, 0x00, 0x14, 0x08, 0x00, 0x20, 0x00, 0x10, 0x04,
+		0x01, 0x11, 0x00, 0x02, 0x11, 0x00, 0x03, 0x11, 0x00, 0x04, 0x11, 0x00,
+		0xFF, 0xDA, 0x00, 0x0E, 0x04, 0x01, 0x00, 0x02, 0x00, 0x03, 0x00, 0x04, 0x00, 0x00, 0x3F, 0x00,
+		0x12, 0xFF, 0x00, 0x34, 0xFF, 0xD0, 0x56,
+		0xFF, 0xD9,
+	)
+	img, err := c.NewImageFromData(cmyk)
+	require.NoError(t, err)
+	require.Equal(t, 16.0, img.Width())
+	require.Equal(t, 32.0, img.Height())
+	require.Nil(t, img.img.Data)
+	require.NoError(t, c.Draw(img))
+	require.Equal(t, cmyk, img.xobj.Stream)
+	_, ok := img.xobj.ColorSpace.(*model.PdfColorspaceDeviceCMYK)
+	require.True(t, ok)
+	decode, ok := core.GetArray(img.xobj.Decode)
+	require.True(t, ok)
+	require.Equal(t, 8, decode.Len())
+
+	// Gray JPEG without Adobe marker.
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, goimage.NewGray(goimage.Rect(0, 0, 4, 4)), nil))
+	info, ok := readJPEGInfo(buf.Bytes())
+	require.True(t, ok)
+	require.Equal(t, &jpegInfo{width: 4, height: 4, components: 1}, info)
+
+	// Truncated JPEG images are not embedded as is.
+	for _, data := range [][]byte{cmyk[:len(cmyk)-2], buf.Bytes()[:buf.Len()/2]} {
+		_, ok = readJPEGInfo(data)
+		require.False(t, ok)
+	}
+	_, err = c.NewImageFromData(cmyk[:len(cmyk)-2])
+	require.Error(t, err)
+
+	// Converting the image decodes it.
+	img, err = c.NewImageFromData(buf.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, img.ConvertToBinary())
+	require.Nil(t, img.jpegData)
+	require.NotNil(t, img.img.Data)
+	require.NoError(t, c.Draw(img))
+	_, ok = img.xobj.Filter.(*core.FlateEncoder)
+	require.True(t, ok)
+
+	// Truncated and non-JPEG data.
+	_, ok = readJPEGInfo(cmyk[:20])
+	require.False(t, ok)
+	_, ok = readJPEGInfo([]byte("GIF89a"))
+	require.False(t, ok)
+}
+
+// TestImageColorReduction tests writing paletted and black and white images
+// with low bit depths.
+func TestImageColorReduction(t *testing.T) {
+	c := New()
+
+	palette := gocolor.Palette{
+		gocolor.RGBA{R: 255, A: 255},
+		gocolor.RGBA{G: 255, A: 255},
+		gocolor.RGBA{B: 255, A: 255},
+	}
+	paletted := goimage.NewPaletted(goimage.Rect(0, 0, 10, 10), palette)
+	for i := range paletted.Pix {
+		paletted.Pix[i] = uint8(i % 3)
+	}
+	img, err := c.NewImageFromGoImage(paletted)
+	require.NoError(t, err)
+	require.NoError(t, c.Draw(img))
+	cs, ok := img.xobj.ColorSpace.(*model.PdfColorspaceSpecialIndexed)
+	require.True(t, ok)
+	require.Equal(t, 2, cs.HiVal)
+	require.Equal(t, int64(2), *img.xobj.BitsPerComponent)
+
+	// Black and white PNG image.
+	gray := goimage.NewGray(goimage.Rect(0, 0, 10, 10))
+	for i := range gray.Pix {
+		if i%2 == 0 {
+			gray.Pix[i] = 255
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, gray))
+	img, err = c.NewImageFromData(buf.Bytes())
+	require.NoError(t, err)
+	require.True(t, img.colorReduction)
+	require.NoError(t, c.Draw(img))
+	_, ok = img.xobj.ColorSpace.(*model.PdfColorspaceDeviceGray)
+	require.True(t, ok)
+	require.Equal(t, int64(1), *img.xobj.BitsPerComponent)
+
+	// RGB images are only reduced when enabled.
+	rgb := goimage.NewRGBA(goimage.Rect(0, 0, 10, 10))
+	for i := range rgb.Pix {
+		rgb.Pix[i] = byte(255 * (i % 2))
+	}
+	img, err = c.NewImageFromGoImage(rgb)
+	require.NoError(t, err)
+	require.NoError(t, c.Draw(img))
+	require.Equal(t, int64(8), *img.xobj.BitsPerComponent)
+
+	img, err = c.NewImageFromGoImage(rgb)
+	require.NoError(t, err)
+	img.EnableColorReduction(true)
+	require.NoError(t, c.Draw(img))
+	_, ok = img.xobj.ColorSpace.(*model.PdfColorspaceSpecialIndexed)
+	require.True(t, ok)
+	require.Equal(t, int64(1), *img.xobj.BitsPerComponent)
+
+	testWriteAndRender(t, c, "image_color_reduction.pdf")
+}
+
+// TestImageFromCCITTTIFF tests loading bilevel TIFF images, embedded with
+// the CCITTFax filter.
+func TestImageFromCCITTTIFF(t *testing.T) {
+	const width, height = 16, 2
+	rows := [][]byte{
+		{0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+		{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0},
+	}
+	encoder := &ccittfax.Encoder{K: -1, Columns: width, Rows: height}
+	strip := encoder.Encode(rows)
+
+	// Little-endian TIFF with the strip followed by the image file directory.
+	var buf bytes.Buffer
+	buf.WriteString("II\x2A\x00")
+	binary.Write(&buf, binary.LittleEndian, uint32(8+len(strip)))
+	buf.Write(strip)
+	tags := [][2]uint32{
+		{256, width}, {257, height}, {258, 1}, {259, 4}, {262, 0}, {273, 8},
+		{278, height}, {279, uint32(len(strip))},
+	}
+	binary.Write(&buf, binary.LittleEndian, uint16(len(tags)))
+	for _, tag := range tags {
+		binary.Write(&buf, binary.LittleEndian, uint16(tag[0]))
+		binary.Write(&buf, binary.LittleEndian, uint16(4))
+		binary.Write(&buf, binary.LittleEndian, uint32(1))
+		binary.Write(&buf, binary.LittleEndian, tag[1])
+	}
+	binary.Write(&buf, binary.LittleEndian, uint32(0))
+
+	c := New()
+	img, err := c.NewImageFromReader(&buf)
+	require.NoError(t, err)
+	require.True(t, img.ccittFax)
+	require.NoError(t, c.Draw(img))
+
+	filter, ok := img.xobj.Filter.(*core.CCITTFaxEncoder)
+	require.True(t, ok)
+	require.Equal(t, -1, filter.K)
+	require.Equal(t, int64(1), *img.xobj.BitsPerComponent)
+	decoded, err := filter.DecodeBytes(img.xobj.Stream)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x3F, 0xFF, 0xFF, 0xFC}, decoded)
+
+	testWriteAndRender(t, c, "image_ccitt_tiff.pdf")
+}
+
 // TestImageWithEncoder tests loading inserting an image with a specified encoder.
 func TestImageWithEncoder(t *testing.T) {
 	creator := New()
@@ -3240,3 +3461,55 @@ func testRender(t *testing.T, pdfPath string) {
 
 	testutils.RunRenderTest(t, pdfPath, tempDir, baselineRenderPath, saveBaseline)
 }
+
+func TestThumbnails(t *testing.T) {
+	c := New()
+	c.SetPageSize(PageSize{200, 100})
+	c.EnableThumbnails(40)
+
+	c.NewPage()
+	rect := c.NewRectangle(0, 0, 100, 100)
+	rect.SetFillColor(ColorRed)
+	rect.SetBorderWidth(0)
+	require.NoError(t, c.Draw(rect))
+
+	// Pre-rendered thumbnails are kept.
+	page := c.NewPage()
+	goimg := goimage.NewGray(goimage.Rect(0, 0, 20, 10))
+	img, err := model.ImageHandling.NewImageFromGoImage(goimg)
+	require.NoError(t, err)
+	require.NoError(t, page.SetThumbnail(img, nil))
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	for i, expected := range []struct {
+		width, height int64
+		components    int
+	}{{40, 20, 3}, {20, 10, 1}} {
+		page, err := r.GetPage(i + 1)
+		require.NoError(t, err)
+		thumb, err := page.GetThumbnail()
+		require.NoError(t, err)
+		require.NotNil(t, thumb)
+		require.Equal(t, expected.width, *thumb.Width)
+		require.Equal(t, expected.height, *thumb.Height)
+		require.Equal(t, expected.components, thumb.ColorSpace.GetNumComponents())
+		require.Nil(t, thumb.SMask)
+	}
+
+	// Thumbnails are not generated by default.
+	c = New()
+	c.NewPage()
+	buf.Reset()
+	require.NoError(t, c.Write(&buf))
+	r, err = model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	page, err = r.GetPage(1)
+	require.NoError(t, err)
+	thumb, err := page.GetThumbnail()
+	require.NoError(t, err)
+	require.Nil(t, thumb)
+}
@@ -41,6 +41,7 @@ type Curve struct {
 
 	lineColor *model.PdfColorDeviceRGB
 	lineWidth float64
+	blendMode model.PdfBlendMode
 }
 
 // SetWidth sets line width.
@@ -53,6 +54,12 @@ func (c *Curve) SetColor(col Color) {
 	c.lineColor = model.NewPdfColorDeviceRGB(col.ToRGB())
 }
 
+// SetBlendMode sets the blend mode of the curve with the page contents,
+// e.g. model.PdfBlendModeMultiply. The normal blend mode is used by default.
+func (c *Curve) SetBlendMode(mode model.PdfBlendMode) {
+	c.blendMode = mode
+}
+
 // GeneratePageBlocks draws the curve onto page blocks.
 func (c *Curve) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
 	block := NewBlock(ctx.PageWidth, ctx.PageHeight)
@@ -67,5 +74,9 @@ func (c *Curve) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, erro
 	if err != nil {
 		return nil, ctx, err
 	}
+	err = block.applyBlendMode(c.blendMode)
+	if err != nil {
+		return nil, ctx, err
+	}
 	return []*Block{block}, ctx, nil
 }
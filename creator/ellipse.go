@@ -6,6 +6,7 @@
 package creator
 
 import (
+	"github.com/unidoc/unipdf/v3/contentstream"
 	"github.com/unidoc/unipdf/v3/contentstream/draw"
 	"github.com/unidoc/unipdf/v3/model"
 )
@@ -19,8 +20,10 @@ type Ellipse struct {
 	width       float64
 	height      float64
 	fillColor   *model.PdfColorDeviceRGB
+	fillShading Shading
 	borderColor *model.PdfColorDeviceRGB
 	borderWidth float64
+	blendMode   model.PdfBlendMode
 }
 
 // newEllipse creates a new ellipse centered at (xc,yc) with a width and height specified.
@@ -58,6 +61,18 @@ func (ell *Ellipse) SetFillColor(col Color) {
 	ell.fillColor = model.NewPdfColorDeviceRGB(col.ToRGB())
 }
 
+// SetFillShading sets a gradient filling the ellipse. The shading takes
+// precedence over the fill color.
+func (ell *Ellipse) SetFillShading(shading Shading) {
+	ell.fillShading = shading
+}
+
+// SetBlendMode sets the blend mode of the ellipse with the page contents,
+// e.g. model.PdfBlendModeMultiply. The normal blend mode is used by default.
+func (ell *Ellipse) SetBlendMode(mode model.PdfBlendMode) {
+	ell.blendMode = mode
+}
+
 // GeneratePageBlocks draws the rectangle on a new block representing the page.
 func (ell *Ellipse) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
 	block := NewBlock(ctx.PageWidth, ctx.PageHeight)
@@ -70,7 +85,21 @@ func (ell *Ellipse) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 		Opacity:     1.0,
 		BorderWidth: ell.borderWidth,
 	}
-	if ell.fillColor != nil {
+	if ell.fillShading != nil {
+		bbox := model.PdfRectangle{
+			Llx: drawell.X,
+			Lly: drawell.Y,
+			Urx: drawell.X + drawell.Width,
+			Ury: drawell.Y + drawell.Height,
+		}
+		err := addShadingContents(block, ell.fillShading, bbox, func(cc *contentstream.ContentCreator) {
+			draw.DrawBezierPathWithCreator(ellipsePath(bbox), cc)
+			cc.Add_h()
+		})
+		if err != nil {
+			return nil, ctx, err
+		}
+	} else if ell.fillColor != nil {
 		drawell.FillEnabled = true
 		drawell.FillColor = ell.fillColor
 	}
@@ -90,5 +119,26 @@ func (ell *Ellipse) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 		return nil, ctx, err
 	}
 
+	err = block.applyBlendMode(ell.blendMode)
+	if err != nil {
+		return nil, ctx, err
+	}
 	return []*Block{block}, ctx, nil
 }
+
+// ellipsePath returns the bezier path of the ellipse inscribed in `bbox`.
+func ellipsePath(bbox model.PdfRectangle) draw.CubicBezierPath {
+	xRad := (bbox.Urx - bbox.Llx) / 2
+	yRad := (bbox.Ury - bbox.Lly) / 2
+
+	magic := 0.551784
+	xMagic := xRad * magic
+	yMagic := yRad * magic
+
+	bpath := draw.NewCubicBezierPath()
+	bpath = bpath.AppendCurve(draw.NewCubicBezierCurve(-xRad, 0, -xRad, yMagic, -xMagic, yRad, 0, yRad))
+	bpath = bpath.AppendCurve(draw.NewCubicBezierCurve(0, yRad, xMagic, yRad, xRad, yMagic, xRad, 0))
+	bpath = bpath.AppendCurve(draw.NewCubicBezierCurve(xRad, 0, xRad, -yMagic, xMagic, -yRad, 0, -yRad))
+	bpath = bpath.AppendCurve(draw.NewCubicBezierCurve(0, -yRad, -xMagic, -yRad, -xRad, -yMagic, -xRad, 0))
+	return bpath.Offset(bbox.Llx+xRad, bbox.Lly+yRad)
+}
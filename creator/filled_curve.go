@@ -20,6 +20,7 @@ type FilledCurve struct {
 	BorderEnabled bool // Show border?
 	BorderWidth   float64
 	borderColor   *pdf.PdfColorDeviceRGB
+	blendMode     pdf.PdfBlendMode
 }
 
 // newFilledCurve returns a instance of filled curve.
@@ -45,6 +46,12 @@ func (fc *FilledCurve) SetBorderColor(color Color) {
 	fc.borderColor = pdf.NewPdfColorDeviceRGB(color.ToRGB())
 }
 
+// SetBlendMode sets the blend mode of the filled curve with the page contents,
+// e.g. model.PdfBlendModeMultiply. The normal blend mode is used by default.
+func (fc *FilledCurve) SetBlendMode(mode pdf.PdfBlendMode) {
+	fc.blendMode = mode
+}
+
 // draw draws the filled curve. Can specify a graphics state (gsName) for setting opacity etc. Otherwise leave empty ("").
 // Returns the content stream as a byte array, the bounding box and an error on failure.
 func (fc *FilledCurve) draw(gsName string) ([]byte, *pdf.PdfRectangle, error) {
@@ -108,5 +115,9 @@ func (fc *FilledCurve) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContex
 	if err != nil {
 		return nil, ctx, err
 	}
+	err = block.applyBlendMode(fc.blendMode)
+	if err != nil {
+		return nil, ctx, err
+	}
 	return []*Block{block}, ctx, nil
 }
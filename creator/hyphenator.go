@@ -0,0 +1,244 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Hyphenator hyphenates words of a given language using TeX hyphenation patterns (Liang's
+// algorithm), such as the hyph-*.tex files of the hyph-utf8 project. It is used by paragraphs to
+// insert soft hyphens in words before breaking the text into lines, see
+// Paragraph.SetHyphenator.
+type Hyphenator struct {
+	// LeftMin and RightMin are the minimum number of characters before and after hyphens
+	// (\lefthyphenmin and \righthyphenmin in TeX), 2 and 3 by default.
+	LeftMin  int
+	RightMin int
+
+	patterns   map[string][]int
+	exceptions map[string][]int
+	maxLength  int // Length in runes of the longest pattern.
+}
+
+// NewHyphenator returns a new hyphenator using the TeX hyphenation patterns `patterns`
+// (e.g. "hy3ph") and the exception words `exceptions`, hyphenated with hyphens (e.g. "ta-ble").
+func NewHyphenator(patterns, exceptions []string) (*Hyphenator, error) {
+	h := &Hyphenator{
+		LeftMin:    2,
+		RightMin:   3,
+		patterns:   map[string][]int{},
+		exceptions: map[string][]int{},
+	}
+	for _, pattern := range patterns {
+		if err := h.addPattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+	for _, exception := range exceptions {
+		h.addException(exception)
+	}
+	return h, nil
+}
+
+// NewHyphenatorFromReader returns a new hyphenator using the patterns of the TeX hyphenation
+// file `r`. The patterns are read from its \patterns{...} group and the exceptions from its
+// \hyphenation{...} group. Files without groups are read as lists of patterns.
+func NewHyphenatorFromReader(r io.Reader) (*Hyphenator, error) {
+	var patterns, exceptions []string
+	inPatterns, inExceptions := false, false
+	hasGroups := false
+	var words []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '%'); i >= 0 {
+			line = line[:i]
+		}
+		for _, field := range strings.Fields(line) {
+			switch {
+			case strings.HasPrefix(field, `\patterns{`):
+				inPatterns, hasGroups = true, true
+				field = strings.TrimPrefix(field, `\patterns{`)
+			case strings.HasPrefix(field, `\hyphenation{`):
+				inExceptions, hasGroups = true, true
+				field = strings.TrimPrefix(field, `\hyphenation{`)
+			case strings.HasPrefix(field, `\`):
+				// Other TeX commands.
+				continue
+			}
+			closing := strings.HasSuffix(field, "}")
+			field = strings.TrimSuffix(field, "}")
+			if field != "" {
+				switch {
+				case inPatterns:
+					patterns = append(patterns, field)
+				case inExceptions:
+					exceptions = append(exceptions, field)
+				default:
+					words = append(words, field)
+				}
+			}
+			if closing {
+				inPatterns, inExceptions = false, false
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !hasGroups {
+		patterns = words
+	}
+	if len(patterns) == 0 {
+		return nil, errors.New("no hyphenation patterns")
+	}
+	return NewHyphenator(patterns, exceptions)
+}
+
+// NewHyphenatorFromFile returns a new hyphenator using the patterns of the TeX hyphenation file
+// at `path`, see NewHyphenatorFromReader.
+func NewHyphenatorFromFile(path string) (*Hyphenator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return NewHyphenatorFromReader(f)
+}
+
+// addPattern adds the TeX pattern `pattern`, made of letters and of the priorities of the
+// positions between letters.
+func (h *Hyphenator) addPattern(pattern string) error {
+	var letters []rune
+	values := []int{0}
+	for _, r := range pattern {
+		if r >= '0' && r <= '9' {
+			values[len(values)-1] = int(r - '0')
+			continue
+		}
+		letters = append(letters, unicode.ToLower(r))
+		values = append(values, 0)
+	}
+	if len(letters) == 0 {
+		return errors.New("invalid hyphenation pattern: " + pattern)
+	}
+	h.patterns[string(letters)] = values
+	if len(letters) > h.maxLength {
+		h.maxLength = len(letters)
+	}
+	return nil
+}
+
+// addException adds the hyphenated exception word `word`.
+func (h *Hyphenator) addException(word string) {
+	var letters []rune
+	values := []int{0}
+	for _, r := range word {
+		if r == '-' {
+			values[len(values)-1] = 1
+			continue
+		}
+		letters = append(letters, unicode.ToLower(r))
+		values = append(values, 0)
+	}
+	h.exceptions[string(letters)] = values
+}
+
+// Hyphenate returns the parts of `word` between which it can be hyphenated.
+func (h *Hyphenator) Hyphenate(word string) []string {
+	runes := []rune(word)
+	var parts []string
+	start := 0
+	for i, point := range h.points(runes) {
+		if point {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// points returns the positions where `word` can be hyphenated: points[i] is true if a hyphen can
+// be inserted before the rune at index i.
+func (h *Hyphenator) points(word []rune) []bool {
+	n := len(word)
+	points := make([]bool, n)
+	if n < h.LeftMin+h.RightMin {
+		return points
+	}
+	lower := make([]rune, n)
+	for i, r := range word {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// values[i] is the priority of the position before rune i.
+	values, ok := h.exceptions[string(lower)]
+	if !ok {
+		dotted := append(append([]rune{'.'}, lower...), '.')
+		values = make([]int, len(dotted)+1)
+		for i := range dotted {
+			for j := i + 1; j <= len(dotted) && j-i <= h.maxLength; j++ {
+				pattern, ok := h.patterns[string(dotted[i:j])]
+				if !ok {
+					continue
+				}
+				for k, v := range pattern {
+					if v > values[i+k] {
+						values[i+k] = v
+					}
+				}
+			}
+		}
+		// Remove the position of the leading dot.
+		values = values[1:]
+	}
+
+	for i := h.LeftMin; i <= n-h.RightMin; i++ {
+		points[i] = values[i]%2 == 1
+	}
+	return points
+}
+
+// HyphenateText returns `text` with soft hyphens (U+00AD) inserted at the positions where its
+// words can be hyphenated. Words which already contain soft hyphens are left unchanged.
+func (h *Hyphenator) HyphenateText(text string) string {
+	var sb strings.Builder
+	var word []rune
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		if strings.ContainsRune(string(word), softHyphen) {
+			sb.WriteString(string(word))
+		} else {
+			for i, point := range h.points(word) {
+				if point {
+					sb.WriteRune(softHyphen)
+				}
+				sb.WriteRune(word[i])
+			}
+		}
+		word = word[:0]
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || r == softHyphen {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		sb.WriteRune(r)
+	}
+	flush()
+	return sb.String()
+}
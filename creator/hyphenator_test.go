@@ -0,0 +1,60 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testHyphenationPatterns are the patterns hyphenating "hyphenation" in The TeXbook, appendix H.
+const testHyphenationPatterns = `% Test patterns.
+\patterns{ % Comment.
+hy3ph he2n hena4 hen5at 1na n2at 1tio 2io
+}
+\hyphenation{ ta-ble }
+`
+
+func TestHyphenator(t *testing.T) {
+	h, err := NewHyphenatorFromReader(strings.NewReader(testHyphenationPatterns))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"hy", "phen", "ation"}, h.Hyphenate("hyphenation"))
+	require.Equal(t, []string{"Hy", "phen", "ation"}, h.Hyphenate("Hyphenation"))
+	require.Equal(t, []string{"ta", "ble"}, h.Hyphenate("table"))
+	require.Equal(t, []string{"hyp"}, h.Hyphenate("hyp"))
+
+	// Minimum number of characters before and after hyphens.
+	h.LeftMin, h.RightMin = 3, 5
+	require.Equal(t, []string{"hyphen", "ation"}, h.Hyphenate("hyphenation"))
+	h.LeftMin, h.RightMin = 2, 3
+
+	// Words with soft hyphens are left unchanged.
+	require.Equal(t, "hy­phen­ation, hyphen­ation.",
+		h.HyphenateText("hyphenation, hyphen­ation."))
+
+	// Lists of patterns.
+	h, err = NewHyphenatorFromReader(strings.NewReader("hy3ph\nhe2n hena4 hen5at 1na n2at 1tio 2io"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"hy", "phen", "ation"}, h.Hyphenate("hyphenation"))
+
+	_, err = NewHyphenatorFromReader(strings.NewReader("% No patterns."))
+	require.Error(t, err)
+}
+
+func TestParagraphHyphenation(t *testing.T) {
+	h, err := NewHyphenatorFromReader(strings.NewReader(testHyphenationPatterns))
+	require.NoError(t, err)
+
+	c := New()
+	p := c.NewParagraph("hyphenation hyphenation")
+	p.SetHyphenator(h)
+	p.SetWidth(p.getTextLineWidth("hyphenation hyphen-")/1000.0 + 0.1)
+	require.Equal(t, []string{"hyphenation hyphen-", "ation"}, p.textLines)
+	require.NoError(t, c.Draw(p))
+}
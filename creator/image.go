@@ -9,6 +9,9 @@ import (
 	"bytes"
 	"fmt"
 	goimage "image"
+	"image/color"
+	"io"
+	"io/ioutil"
 	"os"
 
 	"github.com/unidoc/unipdf/v3/common"
@@ -45,6 +48,9 @@ type Image struct {
 	// Opacity (alpha value).
 	opacity float64
 
+	// Blend mode of the image with the page contents (normal if empty).
+	blendMode model.PdfBlendMode
+
 	// Margins to be applied around the block when drawing on Page.
 	margins margins
 
@@ -53,6 +59,19 @@ type Image struct {
 
 	// Encoder
 	encoder core.StreamEncoder
+
+	// Original data of images loaded from JPEG files, embedded as is when no
+	// encoder is set. The image data is only decoded when needed.
+	jpegData []byte
+	jpegInfo *jpegInfo
+
+	// Specifies whether the image is a bilevel TIFF image, encoded with the
+	// CCITT Group 4 scheme when no encoder is set.
+	ccittFax bool
+
+	// Specifies whether the image is written with 1 bit per component when
+	// black and white or as an Indexed image when it has few colors.
+	colorReduction bool
 }
 
 // newImage create a new image from a unidoc image (model.Image).
@@ -73,8 +92,26 @@ func newImage(img *model.Image) (*Image, error) {
 	}, nil
 }
 
-// newImageFromData creates an Image from image data.
+// newImageFromData creates an Image from image data. The format of the data
+// is detected from its content. The data of JPEG images is embedded without
+// being decoded and re-encoded and bilevel TIFF images are encoded with the
+// CCITT Group 4 scheme, unless an encoder is set.
 func newImageFromData(data []byte) (*Image, error) {
+	if info, ok := readJPEGInfo(data); ok {
+		image, err := newImage(&model.Image{
+			Width:            int64(info.width),
+			Height:           int64(info.height),
+			BitsPerComponent: 8,
+			ColorComponents:  info.components,
+		})
+		if err != nil {
+			return nil, err
+		}
+		image.jpegData = data
+		image.jpegInfo = info
+		return image, nil
+	}
+
 	imgReader := bytes.NewReader(data)
 
 	// Load the image with default handler.
@@ -84,7 +121,25 @@ func newImageFromData(data []byte) (*Image, error) {
 		return nil, err
 	}
 
-	return newImage(img)
+	image, err := newImage(img)
+	if err != nil {
+		return nil, err
+	}
+	image.colorReduction = isPalettedOrGrayImageData(data)
+	isTIFF := bytes.HasPrefix(data, []byte("II\x2A\x00")) || bytes.HasPrefix(data, []byte("MM\x00\x2A"))
+	if isTIFF && img.BitsPerComponent == 1 && img.ColorComponents == 1 {
+		image.ccittFax = true
+	}
+	return image, nil
+}
+
+// newImageFromReader creates an Image from the image data read from `r`.
+func newImageFromReader(r io.Reader) (*Image, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return newImageFromData(data)
 }
 
 // newImageFromFile creates an Image from a file.
@@ -95,14 +150,123 @@ func newImageFromFile(path string) (*Image, error) {
 	}
 	defer f.Close()
 
-	// Load the image with default handler.
-	img, err := model.ImageHandling.Read(f)
-	if err != nil {
-		common.Log.Error("Error loading image: %s", err)
-		return nil, err
+	return newImageFromReader(f)
+}
+
+// jpegInfo describes the data of a JPEG image.
+type jpegInfo struct {
+	width, height int
+	components    int
+
+	// Specifies whether the image has an Adobe APP14 marker. The components of
+	// CMYK images with this marker are inverted.
+	adobe bool
+}
+
+// readJPEGInfo reads the header of the JPEG image `data`. Returns false if
+// `data` is not an 8 bit baseline or progressive JPEG image, which can be
+// embedded as is with the DCTDecode filter. The structure of the whole image
+// is checked, up to the end of image marker, so that truncated or corrupt data
+// is not embedded, but the entropy-coded data of the scans is not decoded.
+func readJPEGInfo(data []byte) (*jpegInfo, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, false
+	}
+
+	var info *jpegInfo
+	adobe := false
+	hasQuantTables, hasHuffmanTables := false, false
+	scans := 0
+	for i := 2; i+2 <= len(data); {
+		if data[i] != 0xFF {
+			return nil, false
+		}
+		marker := data[i+1]
+		if marker == 0xFF {
+			// Fill byte.
+			i++
+			continue
+		}
+		if marker == 0xD9 { // End of image.
+			if info == nil || scans == 0 {
+				return nil, false
+			}
+			info.adobe = adobe
+			return info, true
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			// Markers without segment.
+			i += 2
+			continue
+		}
+		if i+4 > len(data) {
+			return nil, false
+		}
+		length := int(data[i+2])<<8 | int(data[i+3])
+		if length < 2 || i+2+length > len(data) {
+			return nil, false
+		}
+		segment := data[i+4 : i+2+length]
+		i += 2 + length
+
+		switch marker {
+		case 0xC0, 0xC1, 0xC2: // Baseline, extended sequential and progressive DCT.
+			if info != nil || len(segment) < 6 || segment[0] != 8 {
+				return nil, false
+			}
+			info = &jpegInfo{
+				height:     int(segment[1])<<8 | int(segment[2]),
+				width:      int(segment[3])<<8 | int(segment[4]),
+				components: int(segment[5]),
+			}
+			if info.width <= 0 || info.height <= 0 || len(segment) != 6+3*info.components {
+				return nil, false
+			}
+			switch info.components {
+			case 1, 3, 4:
+			default:
+				return nil, false
+			}
+		case 0xC3, 0xC5, 0xC6, 0xC7, 0xC9, 0xCA, 0xCB, 0xCD, 0xCE, 0xCF:
+			// Lossless, hierarchical and arithmetic coding.
+			return nil, false
+		case 0xC4: // Huffman tables.
+			hasHuffmanTables = true
+		case 0xDB: // Quantization tables.
+			hasQuantTables = true
+		case 0xEE: // APP14.
+			adobe = adobe || bytes.HasPrefix(segment, []byte("Adobe"))
+		case 0xDA: // Start of scan.
+			if info == nil || !hasQuantTables || !hasHuffmanTables || len(segment) < 1 {
+				return nil, false
+			}
+			if n := int(segment[0]); n < 1 || n > info.components || len(segment) != 4+2*n {
+				return nil, false
+			}
+			scans++
+			if i = skipJPEGScanData(data, i); i < 0 {
+				return nil, false
+			}
+		}
 	}
+	return nil, false
+}
 
-	return newImage(img)
+// skipJPEGScanData returns the position of the marker following the
+// entropy-coded data of a scan starting at position `i` of the JPEG image
+// `data`, or -1 if the data is truncated.
+func skipJPEGScanData(data []byte, i int) int {
+	for ; i+1 < len(data); i++ {
+		if data[i] != 0xFF {
+			continue
+		}
+		// Stuffed zero bytes and restart markers are part of the scan data.
+		if next := data[i+1]; next != 0x00 && (next < 0xD0 || next > 0xD7) {
+			return i
+		}
+		i++
+	}
+	return -1
 }
 
 // newImageFromGoImage creates an Image from a go image.Image data structure.
@@ -112,7 +276,39 @@ func newImageFromGoImage(goimg goimage.Image) (*Image, error) {
 		return nil, err
 	}
 
-	return newImage(img)
+	image, err := newImage(img)
+	if err != nil {
+		return nil, err
+	}
+	switch goimg.(type) {
+	case *goimage.Paletted, *goimage.Gray:
+		image.colorReduction = true
+	}
+	return image, nil
+}
+
+// isPalettedOrGrayImageData returns true if `data` is a paletted image, e.g.
+// a GIF image, or an 8 bit grayscale image.
+func isPalettedOrGrayImageData(data []byte) bool {
+	config, _, err := goimage.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return false
+	}
+	if _, ok := config.ColorModel.(color.Palette); ok {
+		return true
+	}
+	return config.ColorModel == color.GrayModel
+}
+
+// EnableColorReduction sets whether the image is written in a compact
+// lossless form when possible: with 1 bit per component when it only
+// contains black and white pixels, or as an Indexed image with 1, 2, 4 or 8
+// bits per component when it has at most 256 colors. It is enabled by default
+// for paletted images, such as GIF images, and for 8 bit grayscale images,
+// e.g. scanned line art. It is not applied when the image is encoded with a
+// DCT encoder.
+func (img *Image) EnableColorReduction(enable bool) {
+	img.colorReduction = enable
 }
 
 // SetEncoder sets the encoding/compression mechanism for the image.
@@ -135,6 +331,13 @@ func (img *Image) SetOpacity(opacity float64) {
 	img.opacity = opacity
 }
 
+// SetBlendMode sets the blend mode of the image with the page contents, e.g.
+// model.PdfBlendModeMultiply. The image is drawn with the normal blend mode
+// by default.
+func (img *Image) SetBlendMode(mode model.PdfBlendMode) {
+	img.blendMode = mode
+}
+
 // GetHorizontalAlignment returns the horizontal alignment of the image.
 func (img *Image) GetHorizontalAlignment() HorizontalAlignment {
 	return img.hAlignment
@@ -162,19 +365,60 @@ func (img *Image) GetMargins() (float64, float64, float64, float64) {
 // If provided image is RGB or GrayScale the function converts it into binary image
 // using histogram auto threshold method.
 func (img *Image) ConvertToBinary() error {
+	if err := img.decodeJPEG(); err != nil {
+		return err
+	}
+	img.ccittFax = false
 	return img.img.ConvertToBinary()
 }
 
+// decodeJPEG decodes the data of images loaded from JPEG files, which are no
+// longer embedded as is.
+func (img *Image) decodeJPEG() error {
+	if img.jpegData == nil {
+		return nil
+	}
+	decoded, err := model.ImageHandling.Read(bytes.NewReader(img.jpegData))
+	if err != nil {
+		common.Log.Error("Error loading image: %s", err)
+		return err
+	}
+	img.img = decoded
+	img.jpegData = nil
+	img.jpegInfo = nil
+	return nil
+}
+
 // makeXObject makes the encoded XObject Image that will be used in the PDF.
 func (img *Image) makeXObject() error {
+	if img.encoder == nil && img.jpegData != nil {
+		return img.makeJPEGXObject()
+	}
+	if img.encoder == nil && img.ccittFax {
+		return img.makeCCITTFaxXObject()
+	}
+
+	if err := img.decodeJPEG(); err != nil {
+		return err
+	}
 	encoder := img.encoder
 	if encoder == nil {
 		// Default: Use flate encoder.
 		encoder = core.NewFlateEncoder()
 	}
 
+	data := img.img
+	var cs model.PdfColorspace
+	if _, isDCT := encoder.(*core.DCTEncoder); img.colorReduction && !isDCT {
+		if bitonal, ok := img.img.ToBitonal(); ok {
+			data = bitonal
+		} else if indexed, indexedCS, ok := img.img.ToIndexed(256); ok {
+			data, cs = indexed, indexedCS
+		}
+	}
+
 	// Create the XObject image.
-	ximg, err := model.NewXObjectImageFromImage(img.img, nil, encoder)
+	ximg, err := model.NewXObjectImageFromImage(data, cs, encoder)
 	if err != nil {
 		common.Log.Error("Failed to create xobject image: %s", err)
 		return err
@@ -184,6 +428,79 @@ func (img *Image) makeXObject() error {
 	return nil
 }
 
+// makeJPEGXObject makes the XObject Image of JPEG images from their original
+// data.
+func (img *Image) makeJPEGXObject() error {
+	width, height := img.img.Width, img.img.Height
+	bpc := int64(8)
+
+	encoder := core.NewDCTEncoder()
+	encoder.Width = int(width)
+	encoder.Height = int(height)
+	encoder.BitsPerComponent = int(bpc)
+
+	encoder.ColorComponents = img.jpegInfo.components
+
+	ximg := model.NewXObjectImage()
+	ximg.Width = &width
+	ximg.Height = &height
+	ximg.BitsPerComponent = &bpc
+	switch img.jpegInfo.components {
+	case 1:
+		ximg.ColorSpace = model.NewPdfColorspaceDeviceGray()
+	case 3:
+		ximg.ColorSpace = model.NewPdfColorspaceDeviceRGB()
+	case 4:
+		ximg.ColorSpace = model.NewPdfColorspaceDeviceCMYK()
+		if img.jpegInfo.adobe {
+			// Adobe CMYK images are stored inverted.
+			ximg.Decode = core.MakeArrayFromFloats([]float64{1, 0, 1, 0, 1, 0, 1, 0})
+		}
+	}
+	ximg.Filter = encoder
+	ximg.Stream = img.jpegData
+
+	img.xobj = ximg
+	return nil
+}
+
+// makeCCITTFaxXObject makes the XObject Image of bilevel images, encoded with
+// the CCITT Group 4 scheme.
+func (img *Image) makeCCITTFaxXObject() error {
+	width, height := img.img.Width, img.img.Height
+	bpc := int64(1)
+
+	// The encoder takes 1 byte per pixel, 255 for white pixels.
+	stride := (int(width) + 7) / 8
+	pixels := make([]byte, int(width*height))
+	for y := 0; y < int(height); y++ {
+		for x := 0; x < int(width); x++ {
+			if img.img.Data[y*stride+x/8]&(0x80>>uint(x%8)) != 0 {
+				pixels[y*int(width)+x] = 255
+			}
+		}
+	}
+	encoder := core.NewCCITTFaxEncoder()
+	encoder.K = -1
+	encoder.Columns = int(width)
+	encoder.Rows = int(height)
+	encoded, err := encoder.EncodeBytes(pixels)
+	if err != nil {
+		return err
+	}
+
+	ximg := model.NewXObjectImage()
+	ximg.Width = &width
+	ximg.Height = &height
+	ximg.BitsPerComponent = &bpc
+	ximg.ColorSpace = model.NewPdfColorspaceDeviceGray()
+	ximg.Filter = encoder
+	ximg.Stream = encoded
+
+	img.xobj = ximg
+	return nil
+}
+
 // GeneratePageBlocks generate the Page blocks. Draws the Image on a block, implementing the Drawable interface.
 func (img *Image) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
 	if img.xobj == nil {
@@ -336,15 +653,18 @@ func drawImageOnBlock(blk *Block, img *Image, ctx DrawContext) (DrawContext, err
 		gsName = core.PdfObjectName(fmt.Sprintf("GS%d", i))
 	}
 
-	// Graphics state with normal blend mode.
-	gs0 := core.MakeDict()
-	gs0.Set("BM", core.MakeName("Normal"))
-	if img.opacity < 1.0 {
-		gs0.Set("CA", core.MakeFloat(img.opacity))
-		gs0.Set("ca", core.MakeFloat(img.opacity))
+	// Graphics state with the image blend mode, normal by default.
+	gs0 := model.NewPdfExtGState()
+	gs0.BM = model.PdfBlendModeNormal
+	if img.blendMode != "" {
+		gs0.BM = img.blendMode
+	}
+	if opacity := img.opacity; opacity < 1.0 {
+		gs0.CA = &opacity
+		gs0.Ca = &opacity
 	}
 
-	err = blk.resources.AddExtGState(gsName, core.MakeIndirectObject(gs0))
+	err = blk.resources.AddExtGState(gsName, core.MakeIndirectObject(gs0.ToPdfObject()))
 	if err != nil {
 		return ctx, err
 	}
@@ -22,6 +22,7 @@ type Line struct {
 	y2        float64
 	lineColor *model.PdfColorDeviceRGB
 	lineWidth float64
+	blendMode model.PdfBlendMode
 }
 
 // newLine creates a new Line with default parameters between (x1,y1) to (x2,y2).
@@ -55,6 +56,12 @@ func (l *Line) SetColor(col Color) {
 	l.lineColor = model.NewPdfColorDeviceRGB(col.ToRGB())
 }
 
+// SetBlendMode sets the blend mode of the line with the page contents,
+// e.g. model.PdfBlendModeMultiply. The normal blend mode is used by default.
+func (l *Line) SetBlendMode(mode model.PdfBlendMode) {
+	l.blendMode = mode
+}
+
 // Length calculates and returns the line length.
 func (l *Line) Length() float64 {
 	return math.Sqrt(math.Pow(l.x2-l.x1, 2.0) + math.Pow(l.y2-l.y1, 2.0))
@@ -86,5 +93,9 @@ func (l *Line) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error
 		return nil, ctx, err
 	}
 
+	err = block.applyBlendMode(l.blendMode)
+	if err != nil {
+		return nil, ctx, err
+	}
 	return []*Block{block}, ctx, nil
 }
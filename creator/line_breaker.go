@@ -0,0 +1,228 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+)
+
+// LineBreaker represents an algorithm breaking text into lines for paragraph wrapping.
+type LineBreaker interface {
+	// BreakLines breaks `text` drawn with `style` into lines which fit in `width`, when possible.
+	// Lines ending with a line feed (LF) in the text keep it. Lines broken at soft hyphens
+	// (U+00AD) end with a hyphen and soft hyphens are removed from the lines.
+	BreakLines(text string, style TextStyle, width float64) ([]string, error)
+}
+
+// GreedyLineBreaker is a line breaker filling each line with as many words as fit, before moving
+// to the next line. It is the default line breaker of paragraphs.
+type GreedyLineBreaker struct{}
+
+// BreakLines breaks `text` into lines which fit in `width`. Implements the LineBreaker interface.
+func (GreedyLineBreaker) BreakLines(text string, style TextStyle, width float64) ([]string, error) {
+	return NewTextChunk(text, style).Wrap(width)
+}
+
+// KnuthPlassLineBreaker is a line breaker choosing the breaks of each paragraph as a whole, in
+// order to minimize the sum of the squares of the unused space at the end of the lines (except
+// the last one), as in the Knuth-Plass algorithm. It produces more even lines than the greedy
+// algorithm, which mostly improves the look of justified text.
+type KnuthPlassLineBreaker struct {
+	// HyphenPenalty is the cost of breaking a line at a soft hyphen, relatively to the cost of a
+	// line which is entirely empty.
+	HyphenPenalty float64
+}
+
+// NewKnuthPlassLineBreaker returns a new Knuth-Plass line breaker with the default hyphen penalty.
+func NewKnuthPlassLineBreaker() *KnuthPlassLineBreaker {
+	return &KnuthPlassLineBreaker{HyphenPenalty: 0.5}
+}
+
+// breakFragment is a part of text which is not broken across lines.
+type breakFragment struct {
+	text      string
+	width     float64
+	glue      string  // Spaces following the fragment.
+	glueWidth float64 // Width of the spaces following the fragment.
+	hyphen    bool    // Whether the fragment ends with a soft hyphen.
+}
+
+// BreakLines breaks `text` into lines which fit in `width`. Implements the LineBreaker interface.
+func (lb *KnuthPlassLineBreaker) BreakLines(text string, style TextStyle, width float64) ([]string, error) {
+	if int(width) <= 0 {
+		return []string{text}, nil
+	}
+	maxWidth := width * 1000.0
+
+	hyphenWidth := -1.0
+	if metrics, found := style.Font.GetRuneMetrics('-'); found {
+		hyphenWidth = style.FontSize*metrics.Wx + style.CharSpacing*1000.0
+	}
+
+	var lines []string
+	segments := strings.Split(text, "\u000A")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if last && segment == "" {
+			break
+		}
+		frags, err := lb.fragments(segment, style, maxWidth, hyphenWidth)
+		if err != nil {
+			return nil, err
+		}
+		segmentLines := lb.breakFragments(frags, maxWidth, hyphenWidth)
+		if len(segmentLines) == 0 {
+			segmentLines = []string{""}
+		}
+		if !last {
+			segmentLines[len(segmentLines)-1] += "\u000A"
+		}
+		lines = append(lines, segmentLines...)
+	}
+	return lines, nil
+}
+
+// fragments splits the LF free text `text` into the fragments separated by spaces and soft
+// hyphens. Fragments wider than `maxWidth` are split into fragments which fit.
+func (lb *KnuthPlassLineBreaker) fragments(text string, style TextStyle, maxWidth,
+	hyphenWidth float64) ([]breakFragment, error) {
+	var frags []breakFragment
+	var cur breakFragment
+	var word []rune
+	var wordWidth float64
+	inGlue := false
+
+	flush := func(hyphen bool) {
+		cur.text += string(word)
+		cur.width += wordWidth
+		cur.hyphen = hyphen
+		frags = append(frags, cur)
+		cur = breakFragment{}
+		word = nil
+		wordWidth = 0
+		inGlue = false
+	}
+
+	for _, r := range text {
+		metrics, found := getRuneMetrics(style.Font, r)
+		if !found {
+			common.Log.Debug("ERROR: Rune char metrics not found! rune=0x%04x=%c font=%s %#q",
+				r, r, style.Font.BaseFont(), style.Font.Subtype())
+			return nil, errors.New("glyph char metrics missing")
+		}
+		w := style.FontSize * metrics.Wx
+
+		switch {
+		case r == ' ':
+			if len(word) == 0 && len(frags) == 0 && cur.text == "" {
+				// Leading spaces are kept with the first fragment.
+				cur.text += " "
+				cur.width += w
+				continue
+			}
+			if !inGlue {
+				cur.text += string(word)
+				cur.width += wordWidth
+				word = nil
+				wordWidth = 0
+				inGlue = true
+			}
+			cur.glue += " "
+			cur.glueWidth += w
+		case r == softHyphen:
+			if inGlue || (len(word) == 0 && cur.text == "") {
+				continue
+			}
+			if hyphenWidth < 0 {
+				// Cannot hyphenate without a hyphen glyph.
+				continue
+			}
+			flush(true)
+		default:
+			if inGlue {
+				flush(false)
+			}
+			// Split words which cannot fit on a line.
+			charWidth := w + style.CharSpacing*1000.0
+			if len(word) > 0 && cur.width+wordWidth+charWidth > maxWidth {
+				flush(false)
+			}
+			word = append(word, r)
+			wordWidth += charWidth
+		}
+	}
+	if len(word) > 0 || cur.text != "" {
+		flush(false)
+	}
+	return frags, nil
+}
+
+// breakFragments returns the lines of the fragments `frags` which minimize the total cost of the
+// lines.
+func (lb *KnuthPlassLineBreaker) breakFragments(frags []breakFragment, maxWidth,
+	hyphenWidth float64) []string {
+	n := len(frags)
+	if n == 0 {
+		return nil
+	}
+
+	// cost[j] is the minimum cost of the lines of the first j fragments, starts[j] the first
+	// fragment of the last of these lines.
+	cost := make([]float64, n+1)
+	starts := make([]int, n+1)
+	for j := 1; j <= n; j++ {
+		cost[j] = math.Inf(1)
+		lineWidth := 0.0
+		for i := j - 1; i >= 0; i-- {
+			// Line made of the fragments i to j-1.
+			lineWidth += frags[i].width
+			if i < j-1 {
+				lineWidth += frags[i].glueWidth
+			}
+			width := lineWidth
+			if frags[j-1].hyphen && j < n {
+				width += hyphenWidth
+			}
+			if width > maxWidth && i < j-1 {
+				break
+			}
+
+			lineCost := 0.0
+			if j < n {
+				slack := math.Max(maxWidth-width, 0) / maxWidth
+				lineCost = slack * slack
+				if frags[j-1].hyphen {
+					lineCost += lb.HyphenPenalty
+				}
+			}
+			if c := cost[i] + lineCost; c < cost[j] {
+				cost[j] = c
+				starts[j] = i
+			}
+		}
+	}
+
+	var lines []string
+	for j := n; j > 0; j = starts[j] {
+		i := starts[j]
+		var sb strings.Builder
+		for k := i; k < j; k++ {
+			sb.WriteString(frags[k].text)
+			if k < j-1 {
+				sb.WriteString(frags[k].glue)
+			}
+		}
+		if frags[j-1].hyphen && j < n {
+			sb.WriteString("-")
+		}
+		lines = append([]string{sb.String()}, lines...)
+	}
+	return lines
+}
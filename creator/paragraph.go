@@ -63,6 +63,12 @@ type Paragraph struct {
 
 	// Text lines after wrapping to available width.
 	textLines []string
+
+	// The algorithm breaking the text into lines (greedy when not set).
+	lineBreaker LineBreaker
+
+	// Hyphenator inserting soft hyphens in the words of the text before wrapping (optional).
+	hyphenator *Hyphenator
 }
 
 // newParagraph create a new text paragraph. Uses default parameters: Helvetica, WinAnsiEncoding and
@@ -168,6 +174,19 @@ func (p *Paragraph) GetMargins() (float64, float64, float64, float64) {
 	return p.margins.left, p.margins.right, p.margins.top, p.margins.bottom
 }
 
+// SetLineBreaker sets the algorithm used to break the text of the paragraph into lines when
+// wrapping is enabled. By default, lines are filled greedily, see GreedyLineBreaker.
+func (p *Paragraph) SetLineBreaker(lineBreaker LineBreaker) {
+	p.lineBreaker = lineBreaker
+}
+
+// SetHyphenator sets the hyphenator used to hyphenate the words of the text of the paragraph
+// when wrapping is enabled. By default, words are only hyphenated at the soft hyphens (U+00AD)
+// of the text.
+func (p *Paragraph) SetHyphenator(hyphenator *Hyphenator) {
+	p.hyphenator = hyphenator
+}
+
 // SetWidth sets the the Paragraph width. This is essentially the wrapping width, i.e. the width the
 // text can extend to prior to wrapping over to next line.
 func (p *Paragraph) SetWidth(width float64) {
@@ -200,7 +219,7 @@ func (p *Paragraph) getTextWidth() float64 {
 			continue
 		}
 
-		metrics, found := p.textFont.GetRuneMetrics(r)
+		metrics, found := getRuneMetrics(p.textFont, r)
 		if !found {
 			common.Log.Debug("ERROR: Rune char metrics not found! (rune 0x%04x=%c)", r, r)
 			return -1 // FIXME: return error.
@@ -220,7 +239,7 @@ func (p *Paragraph) getTextLineWidth(line string) float64 {
 			continue
 		}
 
-		metrics, found := p.textFont.GetRuneMetrics(r)
+		metrics, found := getRuneMetrics(p.textFont, r)
 		if !found {
 			common.Log.Debug("ERROR: Rune char metrics not found! (rune 0x%04x=%c)", r, r)
 			return -1 // FIXME: return error.
@@ -249,20 +268,26 @@ func (p *Paragraph) getMaxLineWidth() float64 {
 	return width
 }
 
-// Simple algorithm to wrap the text into lines (greedy algorithm - fill the lines).
-// TODO: Consider the Knuth/Plass algorithm or an alternative.
+// wrapText wraps the text into lines with the line breaker of the paragraph (greedy algorithm -
+// fill the lines - by default).
 func (p *Paragraph) wrapText() error {
 	if !p.enableWrap || int(p.wrapWidth) <= 0 {
 		p.textLines = []string{p.text}
 		return nil
 	}
 
-	chunk := NewTextChunk(p.text, TextStyle{
+	lineBreaker := p.lineBreaker
+	if lineBreaker == nil {
+		lineBreaker = GreedyLineBreaker{}
+	}
+	text := p.text
+	if p.hyphenator != nil {
+		text = p.hyphenator.HyphenateText(text)
+	}
+	lines, err := lineBreaker.BreakLines(text, TextStyle{
 		Font:     p.textFont,
 		FontSize: p.fontSize,
-	})
-
-	lines, err := chunk.Wrap(p.wrapWidth)
+	}, p.wrapWidth)
 	if err != nil {
 		return err
 	}
@@ -385,10 +410,10 @@ func drawParagraphOnBlock(blk *Block, p *Paragraph, ctx DrawContext) (DrawContex
 				spaces++
 				continue
 			}
-			if r == '\u000A' { // LF
+			if r == '\u000A' || r == softHyphen { // LF
 				continue
 			}
-			metrics, found := p.textFont.GetRuneMetrics(r)
+			metrics, found := getRuneMetrics(p.textFont, r)
 			if !found {
 				common.Log.Debug("Unsupported rune i=%d rune=0x%04x=%c in font %s %s",
 					i, r, r,
@@ -425,15 +450,21 @@ func drawParagraphOnBlock(blk *Block, p *Paragraph, ctx DrawContext) (DrawContex
 
 		var encoded []byte
 		for _, r := range runes {
-			if r == '\u000A' { // LF
+			if r == '\u000A' || r == softHyphen { // LF
 				continue
 			}
-			if r == ' ' { // TODO: What about \t and other spaces.
+			if r == ' ' || r == nonBreakingSpace { // TODO: What about \t and other spaces.
 				if len(encoded) > 0 {
 					objs = append(objs, core.MakeStringFromBytes(encoded))
 					encoded = nil
 				}
-				objs = append(objs, core.MakeFloat(-spaceWidth))
+				if r == ' ' {
+					objs = append(objs, core.MakeFloat(-spaceWidth))
+				} else {
+					// Non-breaking spaces are not stretched when justifying.
+					metrics, _ := getRuneMetrics(p.textFont, r)
+					objs = append(objs, core.MakeFloat(-metrics.Wx))
+				}
 			} else {
 				if _, ok := enc.RuneToCharcode(r); !ok {
 					common.Log.Debug("unsupported rune in text encoding: %#x (%c)", r, r)
@@ -8,6 +8,7 @@ package creator
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -33,3 +34,46 @@ func benchmarkParagraphAdding(b *testing.B, loops int) {
 func BenchmarkParagraphAdding1(b *testing.B)   { benchmarkParagraphAdding(b, 1) }
 func BenchmarkParagraphAdding10(b *testing.B)  { benchmarkParagraphAdding(b, 10) }
 func BenchmarkParagraphAdding100(b *testing.B) { benchmarkParagraphAdding(b, 100) }
+
+func TestParagraphLineBreakers(t *testing.T) {
+	c := New()
+	style := TextStyle{Font: c.NewTextStyle().Font, FontSize: 10}
+	text := "aaa bb cc ddddd"
+	lineWidth := func(line string) float64 {
+		var w float64
+		for _, r := range line {
+			metrics, found := style.Font.GetRuneMetrics(r)
+			require.True(t, found)
+			w += style.FontSize * metrics.Wx / 1000.0
+		}
+		return w
+	}
+	width := lineWidth("aaa bb") + 0.1
+
+	// The greedy breaker fills the first line, leaving a short second line.
+	greedy, err := GreedyLineBreaker{}.BreakLines(text, style, width)
+	require.NoError(t, err)
+	require.Equal(t, []string{"aaa bb", "cc", "ddddd"}, greedy)
+
+	// The Knuth-Plass breaker balances the lines.
+	kp := NewKnuthPlassLineBreaker()
+	lines, err := kp.BreakLines(text, style, width)
+	require.NoError(t, err)
+	require.Equal(t, []string{"aaa", "bb cc", "ddddd"}, lines)
+
+	// Line feeds, soft hyphens and overlong words.
+	lines, err = kp.BreakLines("ab­cd­ef\nxxxxxxxxxxxxxxx\n", style, lineWidth("abcd-")+0.1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"abcd-", "ef\n"}, lines[:2])
+	require.Equal(t, "xxxxxxxxxxxxxxx\n", strings.Join(lines[2:], ""))
+	for _, line := range lines {
+		require.True(t, lineWidth(line) <= lineWidth("abcd-")+0.1)
+	}
+
+	p := c.NewParagraph(text)
+	p.SetLineBreaker(kp)
+	p.SetWidth(width)
+	require.Equal(t, []string{"aaa", "bb cc", "ddddd"}, p.textLines)
+	require.NoError(t, c.Draw(p))
+	require.NoError(t, c.Write(&bytes.Buffer{}))
+}
@@ -6,6 +6,7 @@
 package creator
 
 import (
+	"github.com/unidoc/unipdf/v3/contentstream"
 	"github.com/unidoc/unipdf/v3/contentstream/draw"
 	"github.com/unidoc/unipdf/v3/model"
 )
@@ -19,8 +20,10 @@ type Rectangle struct {
 	width       float64
 	height      float64
 	fillColor   *model.PdfColorDeviceRGB
+	fillShading Shading
 	borderColor *model.PdfColorDeviceRGB
 	borderWidth float64
+	blendMode   model.PdfBlendMode
 }
 
 // newRectangle creates a new Rectangle with default parameters with left corner at (x,y) and width, height as specified.
@@ -58,6 +61,18 @@ func (rect *Rectangle) SetFillColor(col Color) {
 	rect.fillColor = model.NewPdfColorDeviceRGB(col.ToRGB())
 }
 
+// SetFillShading sets a gradient filling the rectangle. The shading takes
+// precedence over the fill color.
+func (rect *Rectangle) SetFillShading(shading Shading) {
+	rect.fillShading = shading
+}
+
+// SetBlendMode sets the blend mode of the rectangle with the page contents,
+// e.g. model.PdfBlendModeMultiply. The normal blend mode is used by default.
+func (rect *Rectangle) SetBlendMode(mode model.PdfBlendMode) {
+	rect.blendMode = mode
+}
+
 // GeneratePageBlocks draws the rectangle on a new block representing the page. Implements the Drawable interface.
 func (rect *Rectangle) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext, error) {
 	block := NewBlock(ctx.PageWidth, ctx.PageHeight)
@@ -69,7 +84,20 @@ func (rect *Rectangle) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContex
 		Height:  rect.height,
 		Width:   rect.width,
 	}
-	if rect.fillColor != nil {
+	if rect.fillShading != nil {
+		bbox := model.PdfRectangle{
+			Llx: drawrect.X,
+			Lly: drawrect.Y,
+			Urx: drawrect.X + drawrect.Width,
+			Ury: drawrect.Y + drawrect.Height,
+		}
+		err := addShadingContents(block, rect.fillShading, bbox, func(cc *contentstream.ContentCreator) {
+			cc.Add_re(drawrect.X, drawrect.Y, drawrect.Width, drawrect.Height)
+		})
+		if err != nil {
+			return nil, ctx, err
+		}
+	} else if rect.fillColor != nil {
 		drawrect.FillEnabled = true
 		drawrect.FillColor = rect.fillColor
 	}
@@ -89,5 +117,9 @@ func (rect *Rectangle) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContex
 		return nil, ctx, err
 	}
 
+	err = block.applyBlendMode(rect.blendMode)
+	if err != nil {
+		return nil, ctx, err
+	}
 	return []*Block{block}, ctx, nil
 }
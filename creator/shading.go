@@ -0,0 +1,209 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// Shading represents a smooth color gradient which can be used to fill
+// shapes such as rectangles and ellipses.
+type Shading interface {
+	// pdfShading returns the PDF shading filling the area `bbox`.
+	pdfShading(bbox model.PdfRectangle) *model.PdfShading
+}
+
+// ColorPoint is a color stop of a gradient, defining the color at the
+// position `Point` (0-1) of the gradient.
+type ColorPoint struct {
+	Color Color
+	Point float64
+}
+
+// NewColorPoint returns a new color stop with color `color` at position
+// `point` (0-1) of the gradient.
+func NewColorPoint(color Color, point float64) *ColorPoint {
+	return &ColorPoint{
+		Color: color,
+		Point: point,
+	}
+}
+
+// gradient contains the color stops shared by linear and radial shadings.
+type gradient struct {
+	stops  []*ColorPoint
+	extend bool
+}
+
+func newGradient(start, end Color) gradient {
+	return gradient{
+		stops:  []*ColorPoint{NewColorPoint(start, 0), NewColorPoint(end, 1)},
+		extend: true,
+	}
+}
+
+// addColorStop inserts the color stop `color` at position `point`.
+func (g *gradient) addColorStop(color Color, point float64) {
+	point = math.Max(0, math.Min(1, point))
+	g.stops = append(g.stops, NewColorPoint(color, point))
+	sort.SliceStable(g.stops, func(i, j int) bool {
+		return g.stops[i].Point < g.stops[j].Point
+	})
+}
+
+// function returns the DeviceRGB color function of the gradient. Gradients
+// with more than two color stops result in a stitching function of
+// exponential interpolation functions.
+func (g *gradient) function() model.PdfFunction {
+	makeFunction := func(c0, c1 Color) *model.PdfFunctionType2 {
+		r0, g0, b0 := c0.ToRGB()
+		r1, g1, b1 := c1.ToRGB()
+		return &model.PdfFunctionType2{
+			Domain: []float64{0, 1},
+			C0:     []float64{r0, g0, b0},
+			C1:     []float64{r1, g1, b1},
+			N:      1,
+		}
+	}
+
+	numStops := len(g.stops)
+	if numStops == 2 && g.stops[0].Point == 0 && g.stops[1].Point == 1 {
+		return makeFunction(g.stops[0].Color, g.stops[1].Color)
+	}
+
+	// Leading and trailing stops extend to the gradient boundaries.
+	stops := g.stops
+	if stops[0].Point > 0 {
+		stops = append([]*ColorPoint{NewColorPoint(stops[0].Color, 0)}, stops...)
+	}
+	if stops[len(stops)-1].Point < 1 {
+		stops = append(stops, NewColorPoint(stops[len(stops)-1].Color, 1))
+	}
+
+	stitching := &model.PdfFunctionType3{
+		Domain: []float64{0, 1},
+	}
+	for i := 0; i < len(stops)-1; i++ {
+		stitching.Functions = append(stitching.Functions, makeFunction(stops[i].Color, stops[i+1].Color))
+		stitching.Encode = append(stitching.Encode, 0, 1)
+		if i > 0 {
+			stitching.Bounds = append(stitching.Bounds, stops[i].Point)
+		}
+	}
+	return stitching
+}
+
+// LinearShading is an axial gradient between color stops, varying along a
+// line crossing the filled area at a specified angle.
+type LinearShading struct {
+	gradient
+	angle float64
+}
+
+// newLinearShading returns a new linear shading from color `start` to `end`.
+func newLinearShading(start, end Color) *LinearShading {
+	return &LinearShading{
+		gradient: newGradient(start, end),
+	}
+}
+
+// AddColorStop adds a color stop with color `color` at position `point`
+// (0-1) of the gradient.
+func (sh *LinearShading) AddColorStop(color Color, point float64) {
+	sh.addColorStop(color, point)
+}
+
+// SetAngle sets the angle of the gradient axis in degrees, measured
+// counterclockwise. The default angle of 0 varies the colors from left to
+// right.
+func (sh *LinearShading) SetAngle(angle float64) {
+	sh.angle = angle
+}
+
+// SetExtend sets whether the colors at the ends of the gradient extend
+// beyond the gradient axis.
+func (sh *LinearShading) SetExtend(extend bool) {
+	sh.extend = extend
+}
+
+func (sh *LinearShading) pdfShading(bbox model.PdfRectangle) *model.PdfShading {
+	rad := sh.angle * math.Pi / 180
+	dx, dy := math.Cos(rad), math.Sin(rad)
+
+	// The axis passes through the center of the area and spans its
+	// projection on the axis direction.
+	cx, cy := (bbox.Llx+bbox.Urx)/2, (bbox.Lly+bbox.Ury)/2
+	l := ((bbox.Urx-bbox.Llx)*math.Abs(dx) + (bbox.Ury-bbox.Lly)*math.Abs(dy)) / 2
+
+	shading := model.NewPdfShadingType2(model.NewPdfColorspaceDeviceRGB(),
+		cx-l*dx, cy-l*dy, cx+l*dx, cy+l*dy, sh.function())
+	if sh.extend {
+		shading.Extend = core.MakeArray(core.MakeBool(true), core.MakeBool(true))
+	}
+	return shading.PdfShading
+}
+
+// RadialShading is a radial gradient between color stops, varying from the
+// center of the filled area to its corners.
+type RadialShading struct {
+	gradient
+}
+
+// newRadialShading returns a new radial shading from color `start` at the
+// center to `end`.
+func newRadialShading(start, end Color) *RadialShading {
+	return &RadialShading{
+		gradient: newGradient(start, end),
+	}
+}
+
+// AddColorStop adds a color stop with color `color` at position `point`
+// (0-1) of the gradient.
+func (sh *RadialShading) AddColorStop(color Color, point float64) {
+	sh.addColorStop(color, point)
+}
+
+// SetExtend sets whether the colors at the ends of the gradient extend
+// beyond the gradient circles.
+func (sh *RadialShading) SetExtend(extend bool) {
+	sh.extend = extend
+}
+
+func (sh *RadialShading) pdfShading(bbox model.PdfRectangle) *model.PdfShading {
+	cx, cy := (bbox.Llx+bbox.Urx)/2, (bbox.Lly+bbox.Ury)/2
+	r := math.Hypot(bbox.Urx-bbox.Llx, bbox.Ury-bbox.Lly) / 2
+
+	shading := model.NewPdfShadingType3(model.NewPdfColorspaceDeviceRGB(),
+		cx, cy, 0, cx, cy, r, sh.function())
+	if sh.extend {
+		shading.Extend = core.MakeArray(core.MakeBool(true), core.MakeBool(true))
+	}
+	return shading.PdfShading
+}
+
+// addShadingContents paints `shading` over the area `bbox`, clipped to the
+// path drawn by `drawPath`, and adds the shading to the block resources.
+func addShadingContents(block *Block, shading Shading, bbox model.PdfRectangle,
+	drawPath func(cc *contentstream.ContentCreator)) error {
+	name := core.PdfObjectName("Sh1")
+	obj := shading.pdfShading(bbox).GetContext().ToPdfObject()
+	if err := block.resources.SetShadingByName(name, obj); err != nil {
+		return err
+	}
+
+	cc := contentstream.NewContentCreator()
+	cc.Add_q()
+	drawPath(cc)
+	cc.Add_W().Add_n()
+	cc.Add_sh(name)
+	cc.Add_Q()
+	return block.addContentsByString(cc.String())
+}
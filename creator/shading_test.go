@@ -0,0 +1,78 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package creator
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestShadingFill(t *testing.T) {
+	c := New()
+	c.NewPage()
+
+	linear := c.NewLinearShading(ColorRed, ColorBlue)
+	linear.AddColorStop(ColorGreen, 0.25)
+	linear.SetAngle(90)
+	rect := c.NewRectangle(50, 50, 200, 100)
+	rect.SetFillShading(linear)
+	require.NoError(t, c.Draw(rect))
+
+	radial := c.NewRadialShading(ColorWhite, ColorBlack)
+	radial.SetExtend(false)
+	ell := c.NewEllipse(400, 100, 100, 60)
+	ell.SetFillShading(radial)
+	require.NoError(t, c.Draw(ell))
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Write(&buf))
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	page, err := r.GetPage(1)
+	require.NoError(t, err)
+	content, err := page.GetAllContentStreams()
+	require.NoError(t, err)
+	require.Contains(t, content, "50 642 200 100 re\nW\nn\n/Sh1 sh\n")
+	require.Contains(t, content, "W\nn\n/Sh10 sh\n")
+
+	// Linear shading: vertical axis from bottom to top.
+	sh, ok := page.Resources.GetShadingByName("Sh1")
+	require.True(t, ok)
+	axial, ok := sh.GetContext().(*model.PdfShadingType2)
+	require.True(t, ok)
+	coords, err := axial.Coords.ToFloat64Array()
+	require.NoError(t, err)
+	require.InDeltaSlice(t, []float64{150, 642, 150, 742}, coords, 1e-9)
+	require.Len(t, axial.Function, 1)
+	for x, expected := range map[float64][]float64{0: {1, 0, 0}, 0.25: {0, 1, 0}, 1: {0, 0, 1}} {
+		vals, err := axial.Function[0].Evaluate([]float64{x})
+		require.NoError(t, err)
+		require.InDeltaSlice(t, expected, vals, 1e-9)
+	}
+
+	// Radial shading: from the ellipse center to its corners.
+	sh, ok = page.Resources.GetShadingByName("Sh10")
+	require.True(t, ok)
+	radialSh, ok := sh.GetContext().(*model.PdfShadingType3)
+	require.True(t, ok)
+	coords, err = radialSh.Coords.ToFloat64Array()
+	require.NoError(t, err)
+	require.InDelta(t, 400, coords[0], 1e-9)
+	require.InDelta(t, 0, coords[2], 1e-9)
+	require.InDelta(t, 58.31, coords[5], 0.01)
+	require.Nil(t, radialSh.Extend)
+	vals, err := radialSh.Function[0].Evaluate([]float64{0.5})
+	require.NoError(t, err)
+	require.InDeltaSlice(t, []float64{0.5, 0.5, 0.5}, vals, 1e-9)
+	_, ok = core.GetDict(sh.GetContainingPdfObject())
+	require.True(t, ok)
+}
@@ -30,6 +30,12 @@ type Table struct {
 	// Column width fractions: should add up to 1.
 	colWidths []float64
 
+	// Specifies whether the column widths are computed from the content of the cells.
+	autoWidths bool
+
+	// Callback returning the style of the cells, applied when the table is drawn.
+	cellStyleFunc func(row, col int) CellStyle
+
 	// Row heights.
 	rowHeights []float64
 
@@ -83,6 +89,15 @@ func (table *Table) SetColumnWidths(widths ...float64) error {
 	return nil
 }
 
+// EnableAutoWidths sets whether the column widths are computed from the content of the cells when
+// the table is drawn, as in HTML tables, instead of the fractional widths set by SetColumnWidths.
+// The columns are given their maximum content width (text not wrapped) when the table is wide
+// enough, and the available width is otherwise distributed between their minimum content width
+// (longest words) and their maximum content width.
+func (table *Table) EnableAutoWidths(enable bool) {
+	table.autoWidths = enable
+}
+
 func (table *Table) resetColumnWidths() {
 	table.colWidths = []float64{}
 	colWidth := float64(1.0) / float64(table.cols)
@@ -266,6 +281,10 @@ func (table *Table) GeneratePageBlocks(ctx DrawContext) ([]*Block, DrawContext,
 		ctx.Height -= table.margins.bottom + table.margins.top
 	}
 	tableWidth := ctx.Width
+	table.applyCellStyles()
+	if table.autoWidths {
+		table.colWidths = table.computeAutoWidths(tableWidth)
+	}
 
 	// Store table's upper left corner.
 	ulX := ctx.X
@@ -695,6 +714,79 @@ const (
 	CellVerticalAlignmentBottom
 )
 
+// CellStyle represents the style applied to table cells by the callback set with
+// Table.SetCellStyleFunc. The unset (nil or zero) fields do not change the cells.
+type CellStyle struct {
+	// BackgroundColor is the background color of the cell.
+	BackgroundColor Color
+
+	// BorderColor is the color of the borders of the cell.
+	BorderColor Color
+
+	// TextColor, Font and FontSize are applied to the text of Paragraph and StyledParagraph
+	// content.
+	TextColor Color
+	Font      *model.PdfFont
+	FontSize  float64
+}
+
+// SetCellStyleFunc sets a callback returning the style of the cell at row `row` and column `col`
+// (both starting from 1). The style is applied to all the cells of the table when it is drawn,
+// overriding the styles set on the cells, which simplifies alternating row backgrounds and
+// formatting cells depending on their values. For example:
+//
+//	table.SetCellStyleFunc(func(row, col int) creator.CellStyle {
+//	    if row%2 == 0 {
+//	        return creator.CellStyle{BackgroundColor: creator.ColorRGBFrom8bit(240, 240, 240)}
+//	    }
+//	    return creator.CellStyle{}
+//	})
+func (table *Table) SetCellStyleFunc(styleFunc func(row, col int) CellStyle) {
+	table.cellStyleFunc = styleFunc
+}
+
+// applyCellStyles applies the styles returned by the cell style callback of the table to its
+// cells.
+func (table *Table) applyCellStyles() {
+	if table.cellStyleFunc == nil {
+		return
+	}
+	for _, cell := range table.cells {
+		style := table.cellStyleFunc(cell.row, cell.col)
+		if style.BackgroundColor != nil {
+			cell.SetBackgroundColor(style.BackgroundColor)
+		}
+		if style.BorderColor != nil {
+			cell.SetBorderColor(style.BorderColor)
+		}
+
+		switch t := cell.content.(type) {
+		case *Paragraph:
+			if style.TextColor != nil {
+				t.SetColor(style.TextColor)
+			}
+			if style.Font != nil {
+				t.SetFont(style.Font)
+			}
+			if style.FontSize > 0 {
+				t.SetFontSize(style.FontSize)
+			}
+		case *StyledParagraph:
+			for _, chunk := range t.chunks {
+				if style.TextColor != nil {
+					chunk.Style.Color = style.TextColor
+				}
+				if style.Font != nil {
+					chunk.Style.Font = style.Font
+				}
+				if style.FontSize > 0 {
+					chunk.Style.FontSize = style.FontSize
+				}
+			}
+		}
+	}
+}
+
 // TableCell defines a table cell which can contain a Drawable as content.
 type TableCell struct {
 	// Background
@@ -954,3 +1046,127 @@ func (cell *TableCell) SetContent(vd VectorDrawable) error {
 
 	return nil
 }
+
+// computeAutoWidths returns the column width fractions of the table, computed from the minimum
+// and maximum widths of the content of its cells for the table width `tableWidth`.
+func (table *Table) computeAutoWidths(tableWidth float64) []float64 {
+	minWidths := make([]float64, table.cols)
+	maxWidths := make([]float64, table.cols)
+
+	// Cells spanning single columns first, then the spanning cells widen their columns when
+	// needed.
+	cells := make([]*TableCell, len(table.cells))
+	copy(cells, table.cells)
+	sort.SliceStable(cells, func(i, j int) bool { return cells[i].colspan < cells[j].colspan })
+	for _, cell := range cells {
+		minW, maxW := cell.contentWidths()
+		first := cell.col - 1
+		last := first + cell.colspan
+		if first < 0 || last > table.cols {
+			continue
+		}
+		widen := func(widths []float64, width float64) {
+			var sum float64
+			for i := first; i < last; i++ {
+				sum += widths[i]
+			}
+			if width <= sum {
+				return
+			}
+			for i := first; i < last; i++ {
+				widths[i] += (width - sum) / float64(cell.colspan)
+			}
+		}
+		widen(minWidths, minW)
+		widen(maxWidths, math.Max(minW, maxW))
+	}
+
+	var sumMin, sumMax float64
+	for i := range minWidths {
+		sumMin += minWidths[i]
+		sumMax += maxWidths[i]
+	}
+
+	fractions := make([]float64, table.cols)
+	switch {
+	case sumMax <= 0 || tableWidth <= 0:
+		for i := range fractions {
+			fractions[i] = 1.0 / float64(table.cols)
+		}
+	case sumMax <= tableWidth:
+		// Distribute the extra space in proportion to the maximum widths.
+		for i := range fractions {
+			fractions[i] = maxWidths[i] / sumMax
+		}
+	case sumMin >= tableWidth:
+		for i := range fractions {
+			fractions[i] = minWidths[i] / sumMin
+		}
+	default:
+		ratio := (tableWidth - sumMin) / (sumMax - sumMin)
+		for i := range fractions {
+			fractions[i] = (minWidths[i] + ratio*(maxWidths[i]-minWidths[i])) / tableWidth
+		}
+	}
+	return fractions
+}
+
+// contentWidths returns the minimum and the maximum widths of the content of the cell, including
+// its indent and the margins of the content. The minimum width is the width of the longest word
+// of text content, the maximum width the width of the longest line of text content when not
+// wrapped. Both are 0 when the content does not have an intrinsic width.
+func (cell *TableCell) contentWidths() (float64, float64) {
+	var minW, maxW float64
+	var m margins
+	switch t := cell.content.(type) {
+	case *Paragraph:
+		minW, maxW = textContentWidths([]*TextChunk{NewTextChunk(t.text, TextStyle{
+			Font:     t.textFont,
+			FontSize: t.fontSize,
+		})})
+		m = t.margins
+	case *StyledParagraph:
+		minW, maxW = textContentWidths(t.chunks)
+		m = t.margins
+	case *Image:
+		minW, maxW = t.Width(), t.Width()
+		m = t.margins
+	default:
+		return 0, 0
+	}
+	extra := cell.indent + m.left + m.right
+	return minW + extra, maxW + extra
+}
+
+// textContentWidths returns the width of the longest word and the width of the longest line of
+// the text of `chunks`.
+func textContentWidths(chunks []*TextChunk) (float64, float64) {
+	var minW, maxW, wordWidth, lineWidth float64
+	for _, chunk := range chunks {
+		style := chunk.Style
+		if style.Font == nil {
+			continue
+		}
+		for _, r := range chunk.Text {
+			if r == '\u000A' { // LF
+				wordWidth, lineWidth = 0, 0
+				continue
+			}
+			metrics, found := getRuneMetrics(style.Font, r)
+			if !found {
+				continue
+			}
+			w := style.FontSize * metrics.Wx / 1000.0
+			if r == ' ' {
+				wordWidth = 0
+			} else {
+				w += style.CharSpacing
+				wordWidth += w
+			}
+			lineWidth += w
+			minW = math.Max(minW, wordWidth)
+			maxW = math.Max(maxW, lineWidth)
+		}
+	}
+	return minW, maxW
+}
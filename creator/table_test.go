@@ -614,3 +614,110 @@ func TestTableHorizontalCellAlign(t *testing.T) {
 	require.NoError(t, c.Draw(table))
 	testWriteAndRender(t, c, "table_horizontal_cell_align.pdf")
 }
+
+func TestTableAutoWidths(t *testing.T) {
+	c := New()
+	table := c.NewTable(3)
+	table.EnableAutoWidths(true)
+
+	texts := []string{"ID", "Description of the item", "Quantity"}
+	for _, text := range texts {
+		p := c.NewParagraph(text)
+		p.SetFont(fontHelvetica)
+		p.SetFontSize(10)
+		table.NewCell().SetContent(p)
+	}
+	cell := table.MultiColCell(3)
+	p := c.NewParagraph("Total")
+	p.SetFont(fontHelvetica)
+	cell.SetContent(p)
+
+	textWidth := func(text string) float64 {
+		minW, maxW := textContentWidths([]*TextChunk{NewTextChunk(text, TextStyle{
+			Font: fontHelvetica, FontSize: 10})})
+		require.True(t, minW <= maxW)
+		return maxW + 5 // Default cell indent.
+	}
+	maxWidths := []float64{textWidth("ID"), textWidth("Description of the item"), textWidth("Quantity")}
+	minWidths := []float64{textWidth("ID"), textWidth("Description"), textWidth("Quantity")}
+	sumMax := maxWidths[0] + maxWidths[1] + maxWidths[2]
+	sumMin := minWidths[0] + minWidths[1] + minWidths[2]
+
+	// Wide table: widths proportional to the maximum widths.
+	widths := table.computeAutoWidths(2 * sumMax)
+	for i := range widths {
+		require.InDelta(t, maxWidths[i]/sumMax, widths[i], 1e-9)
+	}
+
+	// Narrow table: the description column is wrapped.
+	tableWidth := (sumMin + sumMax) / 2
+	widths = table.computeAutoWidths(tableWidth)
+	require.InDelta(t, 1, widths[0]+widths[1]+widths[2], 1e-9)
+	for i := range widths {
+		w := widths[i] * tableWidth
+		require.True(t, w >= minWidths[i]-1e-9 && w <= maxWidths[i]+1e-9)
+	}
+	require.InDelta(t, maxWidths[0]/tableWidth, widths[0], 1e-9)
+
+	// Very narrow table: widths proportional to the minimum widths.
+	widths = table.computeAutoWidths(sumMin / 2)
+	for i := range widths {
+		require.InDelta(t, minWidths[i]/sumMin, widths[i], 1e-9)
+	}
+
+	require.NoError(t, c.Draw(table))
+	require.NoError(t, c.WriteToFile(tempFile("table_auto_widths.pdf")))
+}
+
+func TestTableCellStyleFunc(t *testing.T) {
+	c := New()
+	table := c.NewTable(2)
+
+	values := [][]float64{{1, -2}, {-3, 4}, {5, 6}}
+	for _, row := range values {
+		for _, v := range row {
+			table.NewCell().SetContent(c.NewParagraph(fmt.Sprintf("%.2f", v)))
+		}
+	}
+	sp := c.NewStyledParagraph()
+	sp.Append("styled")
+	table.NewCell().SetContent(sp)
+
+	red := ColorRGBFrom8bit(255, 0, 0)
+	gray := ColorRGBFrom8bit(240, 240, 240)
+	table.SetCellStyleFunc(func(row, col int) CellStyle {
+		var style CellStyle
+		if row%2 == 0 {
+			style.BackgroundColor = gray
+		}
+		if row <= len(values) && values[row-1][col-1] < 0 {
+			style.TextColor = red
+			style.Font = fontHelveticaBold
+		}
+		if row > len(values) {
+			style.FontSize = 14
+		}
+		return style
+	})
+	require.NoError(t, c.Draw(table))
+
+	for _, cell := range table.cells {
+		if cell.row%2 == 0 {
+			require.NotNil(t, cell.backgroundColor)
+		} else {
+			require.Nil(t, cell.backgroundColor)
+		}
+		if cell.row > len(values) {
+			require.Equal(t, 14.0, cell.content.(*StyledParagraph).chunks[0].Style.FontSize)
+			continue
+		}
+		p := cell.content.(*Paragraph)
+		if values[cell.row-1][cell.col-1] < 0 {
+			require.Equal(t, 1.0, p.color.R())
+			require.Equal(t, fontHelveticaBold, p.textFont)
+		} else {
+			require.Equal(t, 0.0, p.color.R())
+		}
+	}
+	require.NoError(t, c.WriteToFile(tempFile("table_cell_styles.pdf")))
+}
@@ -15,6 +15,15 @@ import (
 	"github.com/unidoc/unipdf/v3/model"
 )
 
+// Special characters handled when wrapping text.
+const (
+	// softHyphen marks a position where a word can be broken with a hyphen.
+	softHyphen = '\u00AD'
+
+	// nonBreakingSpace is a space which is not a line break opportunity.
+	nonBreakingSpace = '\u00A0'
+)
+
 // TextChunk represents a chunk of text along with a particular style.
 type TextChunk struct {
 	// The text that is being rendered in the PDF.
@@ -45,13 +54,30 @@ func (tc *TextChunk) SetAnnotation(annotation *model.PdfAnnotation) {
 }
 
 // Wrap wraps the text of the chunk into lines based on its style and the
-// specified width.
+// specified width. Lines are broken at spaces, or at soft hyphens (U+00AD)
+// in which case the line ends with a hyphen. Non-breaking spaces (U+00A0)
+// are not used as break points. Soft hyphens are removed from the lines.
 func (tc *TextChunk) Wrap(width float64) ([]string, error) {
+	lines, _, err := tc.wrap(width)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		lines[i] = strings.Replace(line, string(softHyphen), "", -1)
+	}
+	return lines, nil
+}
+
+// wrap wraps the text of the chunk into lines like Wrap, without removing the
+// soft hyphens. The returned flags specify which lines have been broken at a
+// soft hyphen.
+func (tc *TextChunk) wrap(width float64) ([]string, []bool, error) {
 	if int(width) <= 0 {
-		return []string{tc.Text}, nil
+		return []string{tc.Text}, []bool{false}, nil
 	}
 
 	var lines []string
+	var hyphenated []bool
 	var line []rune
 	var lineWidth float64
 	var widths []float64
@@ -59,10 +85,19 @@ func (tc *TextChunk) Wrap(width float64) ([]string, error) {
 	style := tc.Style
 	runes := []rune(tc.Text)
 
+	hyphenWidth := -1.0
+	if metrics, found := style.Font.GetRuneMetrics('-'); found {
+		hyphenWidth = style.FontSize*metrics.Wx + style.CharSpacing*1000.0
+	}
+	addLine := func(text string, hyphen bool) {
+		lines = append(lines, text)
+		hyphenated = append(hyphenated, hyphen)
+	}
+
 	for _, r := range runes {
 		// Move to the next line due to newline wrapping (LF).
 		if r == '\u000A' {
-			lines = append(lines, strings.TrimRightFunc(string(line), unicode.IsSpace)+string(r))
+			addLine(strings.TrimRightFunc(string(line), unicode.IsSpace)+string(r), false)
 			line = nil
 			lineWidth = 0
 			widths = nil
@@ -70,37 +105,50 @@ func (tc *TextChunk) Wrap(width float64) ([]string, error) {
 		}
 		isSpace := r == ' '
 
-		metrics, found := style.Font.GetRuneMetrics(r)
+		metrics, found := getRuneMetrics(style.Font, r)
 		if !found {
 			common.Log.Debug("ERROR: Rune char metrics not found! rune=0x%04x=%c font=%s %#q",
 				r, r, style.Font.BaseFont(), style.Font.Subtype())
 			common.Log.Trace("Font: %#v", style.Font)
 			common.Log.Trace("Encoder: %#v", style.Font.Encoder())
-			return nil, errors.New("glyph char metrics missing")
+			return nil, nil, errors.New("glyph char metrics missing")
 		}
 		w := style.FontSize * metrics.Wx
 
 		charWidth := w
-		if !isSpace {
+		if !isSpace && r != softHyphen {
 			charWidth = w + style.CharSpacing*1000.0
 		}
 
 		if lineWidth+w > width*1000.0 {
-			// Goes out of bounds. Break on the character.
+			// Goes out of bounds. Break on the last space, or on the last
+			// soft hyphen after which the line and the hyphen fit.
 			idx := -1
+			hyphen := false
 			if !isSpace {
+				prefixWidth := lineWidth
 				for i := len(line) - 1; i >= 0; i-- {
+					prefixWidth -= widths[i]
 					if line[i] == ' ' {
 						idx = i
 						break
 					}
+					if line[i] == softHyphen && hyphenWidth >= 0 &&
+						prefixWidth+hyphenWidth <= width*1000.0 {
+						idx = i
+						hyphen = true
+						break
+					}
 				}
 			}
 
 			text := string(line)
 			if idx > 0 {
-				// Back up to last space.
+				// Back up to last space or soft hyphen.
 				text = string(line[0 : idx+1])
+				if hyphen {
+					text = string(line[0:idx]) + "-"
+				}
 
 				// Remainder of line.
 				line = append(line[idx+1:], r)
@@ -122,7 +170,7 @@ func (tc *TextChunk) Wrap(width float64) ([]string, error) {
 				}
 			}
 
-			lines = append(lines, strings.TrimRightFunc(text, unicode.IsSpace))
+			addLine(strings.TrimRightFunc(text, unicode.IsSpace), hyphen)
 		} else {
 			line = append(line, r)
 			lineWidth += charWidth
@@ -130,10 +178,10 @@ func (tc *TextChunk) Wrap(width float64) ([]string, error) {
 		}
 	}
 	if len(line) > 0 {
-		lines = append(lines, string(line))
+		addLine(string(line), false)
 	}
 
-	return lines, nil
+	return lines, hyphenated, nil
 }
 
 // Fit fits the chunk into the specified bounding box, cropping off the
@@ -142,7 +190,7 @@ func (tc *TextChunk) Wrap(width float64) ([]string, error) {
 // line height values, the passed in height must be divided by the line height:
 // height = height / lineHeight
 func (tc *TextChunk) Fit(width, height float64) (*TextChunk, error) {
-	lines, err := tc.Wrap(width)
+	lines, hyphenated, err := tc.wrap(width)
 	if err != nil {
 		return nil, err
 	}
@@ -151,13 +199,45 @@ func (tc *TextChunk) Fit(width, height float64) (*TextChunk, error) {
 	if fit >= len(lines) {
 		return nil, nil
 	}
-	lf := "\u000A"
-	tc.Text = strings.Replace(strings.Join(lines[:fit], " "), lf+" ", lf, -1)
-
-	remainder := strings.Replace(strings.Join(lines[fit:], " "), lf+" ", lf, -1)
+	tc.Text = joinWrappedLines(lines[:fit], hyphenated[:fit])
+	remainder := joinWrappedLines(lines[fit:], hyphenated[fit:])
 	return NewTextChunk(remainder, tc.Style), nil
 }
 
+// joinWrappedLines joins the lines produced by TextChunk.wrap. The lines
+// broken at soft hyphens are joined back with the soft hyphens instead of
+// the inserted hyphens.
+func joinWrappedLines(lines []string, hyphenated []bool) string {
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 && !hyphenated[i-1] && !strings.HasSuffix(lines[i-1], "\u000A") {
+			sb.WriteString(" ")
+		}
+		if hyphenated[i] && i < len(lines)-1 {
+			line = strings.TrimSuffix(line, "-") + string(softHyphen)
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// getRuneMetrics returns the metrics of rune `r` in `font`. Soft hyphens
+// have no width, they are only drawn as hyphens when lines are broken at
+// them. Non-breaking spaces have the metrics of spaces when the font does
+// not have them.
+func getRuneMetrics(font *model.PdfFont, r rune) (model.CharMetrics, bool) {
+	switch r {
+	case softHyphen:
+		return model.CharMetrics{}, true
+	case nonBreakingSpace:
+		if metrics, found := font.GetRuneMetrics(r); found && metrics.Wx > 0 {
+			return metrics, true
+		}
+		return font.GetRuneMetrics(' ')
+	}
+	return font.GetRuneMetrics(r)
+}
+
 // newExternalLinkAnnotation returns a new external link annotation.
 func newExternalLinkAnnotation(url string) *model.PdfAnnotation {
 	annotation := model.NewPdfAnnotationLink()
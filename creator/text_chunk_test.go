@@ -128,3 +128,43 @@ func TestTextChunkFit(t *testing.T) {
 		tc = tc2
 	}
 }
+
+func TestTextChunkWrapSoftHyphens(t *testing.T) {
+	style := TextStyle{
+		Font:     model.DefaultFont(),
+		FontSize: 10,
+	}
+	textWidth := func(text string) float64 {
+		var w float64
+		for _, r := range text {
+			metrics, found := style.Font.GetRuneMetrics(r)
+			require.True(t, found)
+			w += style.FontSize * metrics.Wx / 1000.0
+		}
+		return w
+	}
+
+	// Lines are broken at the soft hyphens, with a hyphen.
+	text := "the inter­nation­al­iza­tion rules"
+	tc := NewTextChunk(text, style)
+	lines, err := tc.Wrap(textWidth("the internation-") + 1)
+	require.NoError(t, err)
+	require.Equal(t, []string{"the internation-", "alization rules"}, lines)
+
+	// Soft hyphens have no width and are removed.
+	lines, err = tc.Wrap(1000)
+	require.NoError(t, err)
+	require.Equal(t, []string{"the internationalization rules"}, lines)
+
+	// The remainder of fitted chunks keeps the soft hyphens.
+	tc2, err := tc.Fit(textWidth("the internation-")+1, 10)
+	require.NoError(t, err)
+	require.Equal(t, "the inter­nation-", tc.Text)
+	require.Equal(t, "al­iza­tion rules", tc2.Text)
+
+	// Non-breaking spaces are not break points.
+	tc = NewTextChunk("width 10 mm", style)
+	lines, err = tc.Wrap(textWidth("width 10 m"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"width", "10 mm"}, lines)
+}
@@ -5,6 +5,7 @@
 
 //
 // Package extractor is used for quickly extracting PDF content through a simple interface.
-// Currently offers functionality for extracting textual content.
+// Currently offers functionality for extracting textual content, images and vector graphics, and
+// for finding the content drawn at given page coordinates.
 //
 package extractor
@@ -0,0 +1,252 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// ExtractDocumentText extracts the text of all the pages of the document `r`, see
+// Extractor.ExtractText, and returns it by page. The extraction stops with the error of `ctx` when
+// it is cancelled or its deadline is exceeded. If not nil, `progress` is called with the number of
+// pages processed.
+func ExtractDocumentText(ctx context.Context, r *model.PdfReader, progress model.ProgressFunc) ([]string, error) {
+	pageTexts, err := extractDocumentPageTexts(ctx, r, progress)
+	if err != nil {
+		return nil, err
+	}
+	texts := make([]string, len(pageTexts))
+	for i, pt := range pageTexts {
+		texts[i] = pt.Text()
+	}
+	return texts, nil
+}
+
+// extractDocumentPageTexts returns the extracted texts of the pages of the document `r`, see
+// ExtractDocumentText.
+func extractDocumentPageTexts(ctx context.Context, r *model.PdfReader, progress model.ProgressFunc) ([]*PageText, error) {
+	numPages, err := r.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+
+	pageTexts := make([]*PageText, numPages)
+	for i := range pageTexts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := r.GetPage(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		e, err := New(page)
+		if err != nil {
+			return nil, err
+		}
+		if pageTexts[i], _, _, err = e.ExtractPageText(); err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(i+1, numPages)
+		}
+	}
+	return pageTexts, nil
+}
+
+// AllTextOptions specifies the formatting of the text returned by ExtractAllText.
+type AllTextOptions struct {
+	// PageDelimiter is written between the texts of the pages. Default: "\f" (form feed), as
+	// written by common PDF to text converters.
+	PageDelimiter string
+
+	// PageNumbers specifies whether each page text is preceded by its page number, formatted with
+	// PageNumberFormat.
+	PageNumbers bool
+
+	// PageNumberFormat is the Printf format of the page numbers line. Default: "--- Page %d ---".
+	PageNumberFormat string
+
+	// SkipHeadersFooters skips the headers and footers of the pages: the lines at the top and the
+	// bottom of the pages which are repeated on at least half of the pages (and 2 pages), ignoring
+	// their numbers so that page numbers are detected as repeated.
+	SkipHeadersFooters bool
+
+	// SkipRepeatedText skips the lines repeated at the same position on most pages, found by
+	// FindRepeatedText with the RepeatedText options. This is more accurate than
+	// SkipHeadersFooters as the positions of the lines are compared, but requires keeping the
+	// extracted texts of all the pages in memory.
+	SkipRepeatedText bool
+
+	// RepeatedText specifies the options of FindRepeatedText for SkipRepeatedText. Default: the
+	// default options.
+	RepeatedText *RepeatedTextOptions
+
+	// Progress is called with the number of pages processed, if not nil.
+	Progress model.ProgressFunc
+}
+
+// Default options of ExtractAllText.
+const (
+	defaultPageDelimiter    = "\f"
+	defaultPageNumberFormat = "--- Page %d ---"
+)
+
+// marginLines is the number of lines at the top and the bottom of the pages which are examined
+// for headers and footers.
+const marginLines = 2
+
+// ExtractAllText extracts the text of all the pages of the document `r`, see ExtractDocumentText,
+// and returns it as a single string formatted according to `opts`. `opts` may be nil for the
+// default options. The extraction stops with the error of `ctx` when it is cancelled or its
+// deadline is exceeded.
+func ExtractAllText(ctx context.Context, r *model.PdfReader, opts *AllTextOptions) (string, error) {
+	if opts == nil {
+		opts = &AllTextOptions{}
+	}
+	if !opts.SkipRepeatedText {
+		texts, err := ExtractDocumentText(ctx, r, opts.Progress)
+		if err != nil {
+			return "", err
+		}
+		return joinPageTexts(texts, opts), nil
+	}
+
+	pageTexts, err := extractDocumentPageTexts(ctx, r, opts.Progress)
+	if err != nil {
+		return "", err
+	}
+	repeated := findRepeatedText(pageTexts, opts.RepeatedText)
+	texts := make([]string, len(pageTexts))
+	for i, pt := range pageTexts {
+		texts[i] = pt.TextWithout(repeated)
+	}
+	return joinPageTexts(texts, opts), nil
+}
+
+// joinPageTexts returns the page texts `texts` joined as specified by `opts`.
+func joinPageTexts(texts []string, opts *AllTextOptions) string {
+	delimiter := opts.PageDelimiter
+	if delimiter == "" {
+		delimiter = defaultPageDelimiter
+	}
+	format := opts.PageNumberFormat
+	if format == "" {
+		format = defaultPageNumberFormat
+	}
+
+	pageLines := make([][]string, len(texts))
+	for i, text := range texts {
+		pageLines[i] = strings.Split(strings.Trim(text, "\n"), "\n")
+	}
+	if opts.SkipHeadersFooters {
+		skipRepeatedLines(pageLines)
+	}
+
+	var b strings.Builder
+	for i, lines := range pageLines {
+		if i > 0 {
+			b.WriteString(delimiter)
+		}
+		if opts.PageNumbers {
+			fmt.Fprintf(&b, format, i+1)
+			b.WriteString("\n")
+		}
+		if text := strings.Trim(strings.Join(lines, "\n"), "\n"); text != "" {
+			b.WriteString(text)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// skipRepeatedLines removes the headers and footers of the pages with lines `pageLines`, see
+// AllTextOptions.SkipHeadersFooters.
+func skipRepeatedLines(pageLines [][]string) {
+	minPages := (len(pageLines) + 1) / 2
+	if minPages < 2 {
+		minPages = 2
+	}
+
+	// The candidates are the first and last non-empty lines of the pages, counted once per page.
+	headers, footers := map[string]int{}, map[string]int{}
+	for _, lines := range pageLines {
+		for key := range marginLineKeys(lines, true) {
+			headers[key]++
+		}
+		for key := range marginLineKeys(lines, false) {
+			footers[key]++
+		}
+	}
+
+	for i, lines := range pageLines {
+		// Skip the repeated lines from the top, then from the bottom, stopping at the first line
+		// which is not repeated.
+		start, end, n := 0, len(lines), 0
+		for ; start < end && n < marginLines; start++ {
+			key := normalizeMarginLine(lines[start])
+			if key == "" {
+				continue
+			}
+			if headers[key] < minPages {
+				break
+			}
+			n++
+		}
+		for n = 0; end > start && n < marginLines; end-- {
+			key := normalizeMarginLine(lines[end-1])
+			if key == "" {
+				continue
+			}
+			if footers[key] < minPages {
+				break
+			}
+			n++
+		}
+		pageLines[i] = lines[start:end]
+	}
+}
+
+// marginLineKeys returns the normalized keys of the first (if `top` is true) or last
+// non-empty lines of `lines`.
+func marginLineKeys(lines []string, top bool) map[string]struct{} {
+	keys := map[string]struct{}{}
+	for i, n := 0, 0; i < len(lines) && n < marginLines; i++ {
+		line := lines[i]
+		if !top {
+			line = lines[len(lines)-1-i]
+		}
+		if key := normalizeMarginLine(line); key != "" {
+			keys[key] = struct{}{}
+			n++
+		}
+	}
+	return keys
+}
+
+// normalizeMarginLine returns `line` with its numbers replaced by '#' and its spaces collapsed, so
+// that headers and footers differing by their page numbers, e.g. "Page 9" and "Page 10", are
+// considered as the same.
+func normalizeMarginLine(line string) string {
+	var b strings.Builder
+	digits := false
+	for _, r := range strings.Join(strings.Fields(line), " ") {
+		if unicode.IsDigit(r) {
+			if !digits {
+				b.WriteByte('#')
+			}
+			digits = true
+			continue
+		}
+		digits = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestExtractDocumentText(t *testing.T) {
+	f, err := os.Open("./testdata/multi.pdf")
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := model.NewPdfReader(f)
+	require.NoError(t, err)
+
+	var pages []int
+	texts, err := ExtractDocumentText(context.Background(), r, func(done, total int) {
+		require.Equal(t, 7, total)
+		pages = append(pages, done)
+	})
+	require.NoError(t, err)
+	require.Len(t, texts, 7)
+	require.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, pages)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ExtractDocumentText(ctx, r, nil)
+	require.Equal(t, context.Canceled, err)
+}
+
+func TestExtractAllText(t *testing.T) {
+	f, err := os.Open("./testdata/multi.pdf")
+	require.NoError(t, err)
+	defer f.Close()
+	r, err := model.NewPdfReader(f)
+	require.NoError(t, err)
+
+	texts, err := ExtractDocumentText(context.Background(), r, nil)
+	require.NoError(t, err)
+	text, err := ExtractAllText(context.Background(), r, &AllTextOptions{PageNumbers: true})
+	require.NoError(t, err)
+	require.Equal(t, 6, strings.Count(text, "\f"))
+	require.Contains(t, text, "\f--- Page 7 ---\n")
+	require.Contains(t, text, strings.TrimSpace(texts[3]))
+
+	var pages []int
+	text, err = ExtractAllText(context.Background(), r, &AllTextOptions{
+		SkipRepeatedText: true,
+		Progress:         func(done, total int) { pages = append(pages, done) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, 6, strings.Count(text, "\f"))
+	require.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, pages)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ExtractAllText(ctx, r, nil)
+	require.Equal(t, context.Canceled, err)
+}
+
+func TestJoinPageTexts(t *testing.T) {
+	texts := []string{
+		"Annual report\nIntroduction\nFirst page body.\n\nPage 1 of 3\n",
+		"Annual report\nSecond page body.\nPage 2 of 3",
+		"\nAnnual report\nThird page body.\nPage 3 of 3\n",
+	}
+	require.Equal(t, "Annual report\nIntroduction\nFirst page body.\n\nPage 1 of 3\n"+
+		"\fAnnual report\nSecond page body.\nPage 2 of 3\n"+
+		"\fAnnual report\nThird page body.\nPage 3 of 3\n",
+		joinPageTexts(texts, &AllTextOptions{}))
+	require.Equal(t, "[1]\nIntroduction\nFirst page body.\n\n[2]\nSecond page body.\n\n[3]\nThird page body.\n",
+		joinPageTexts(texts, &AllTextOptions{
+			PageDelimiter:      "\n",
+			PageNumbers:        true,
+			PageNumberFormat:   "[%d]",
+			SkipHeadersFooters: true,
+		}))
+
+	// Lines are not skipped when they are not repeated on enough pages.
+	texts = []string{"Title\nBody", "Other\nSecond body", "Another\nThird body"}
+	require.Equal(t, "Title\nBody\n\fOther\nSecond body\n\fAnother\nThird body\n",
+		joinPageTexts(texts, &AllTextOptions{SkipHeadersFooters: true}))
+}
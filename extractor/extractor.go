@@ -18,10 +18,16 @@ type Extractor struct {
 	resources *model.PdfPageResources
 	mediaBox  model.PdfRectangle
 
+	// page is the page the contents are extracted from, nil if created with NewFromContents.
+	page *model.PdfPage
+
 	// fontCache is a simple LRU cache that is used to prevent redundant constructions of PdfFonts
 	// from PDF objects. NOTE: This is not a conventional glyph cache. It only caches PdfFonts.
 	fontCache map[string]fontEntry
 
+	// fontCacheSize is the maximum number of PdfFonts in fontCache.
+	fontCacheSize int
+
 	// text results from running extractXYText on forms within the page.
 	// TODO(peterwilliams97): Cache this map accross all pages in a PDF to speed up processing.
 	formResults map[string]textResult
@@ -50,22 +56,42 @@ func New(page *model.PdfPage) (*Extractor, error) {
 		return nil, fmt.Errorf("extractor requires mediaBox. %v", err)
 	}
 	e := &Extractor{
-		contents:    contents,
-		resources:   page.Resources,
-		mediaBox:    *mediaBox,
-		fontCache:   map[string]fontEntry{},
-		formResults: map[string]textResult{},
+		contents:      contents,
+		resources:     page.Resources,
+		mediaBox:      *mediaBox,
+		page:          page,
+		fontCache:     map[string]fontEntry{},
+		fontCacheSize: maxFontCache,
+		formResults:   map[string]textResult{},
 	}
 	return e, nil
 }
 
+// SetFontCacheSize sets the maximum number of fonts kept by the extractor to
+// avoid loading the fonts used repeatedly again, 10 by default. Zero disables
+// the cache.
+func (e *Extractor) SetFontCacheSize(size int) {
+	e.fontCacheSize = size
+	if size <= 0 {
+		e.fontCache = nil
+		return
+	}
+	if e.fontCache == nil {
+		e.fontCache = map[string]fontEntry{}
+	}
+	for len(e.fontCache) > size {
+		e.evictFont()
+	}
+}
+
 // NewFromContents creates a new extractor from contents and page resources.
 func NewFromContents(contents string, resources *model.PdfPageResources) (*Extractor, error) {
 	e := &Extractor{
-		contents:    contents,
-		resources:   resources,
-		fontCache:   map[string]fontEntry{},
-		formResults: map[string]textResult{},
+		contents:      contents,
+		resources:     resources,
+		fontCache:     map[string]fontEntry{},
+		fontCacheSize: maxFontCache,
+		formResults:   map[string]textResult{},
 	}
 	return e, nil
 }
@@ -0,0 +1,316 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// Heading is a heading of a document, inferred from the font size and weight of its text by
+// InferHeadings.
+type Heading struct {
+	// Title is the text of the heading. The lines of multi-line headings are joined by spaces.
+	Title string
+	// Level is the level of the heading in the outline of the document, starting from 1 for the
+	// largest headings.
+	Level int
+	// PageNum is the number of the page of the heading, starting from 1.
+	PageNum int
+	// BBox is the bounding box of the heading on the page.
+	BBox model.PdfRectangle
+	// FontSize is the rendered font size of the heading.
+	FontSize float64
+	// Bold specifies whether the heading is drawn with a bold font.
+	Bold bool
+}
+
+// String returns a description of `h`.
+func (h *Heading) String() string {
+	return fmt.Sprintf("%s%q (level %d, page %d, size %.1f, bold %t)",
+		strings.Repeat("  ", h.Level-1), h.Title, h.Level, h.PageNum, h.FontSize, h.Bold)
+}
+
+// HeadingOptions specifies the heuristics used for inferring the headings of a document.
+type HeadingOptions struct {
+	// MaxLevels is the maximum number of heading levels. Default: 3.
+	MaxLevels int
+	// MinSizeRatio is the minimum ratio of the font size of headings to the font size of the body
+	// text. Bold text at least as large as the body text is also considered as a heading, at the
+	// lowest levels. Default: 1.2.
+	MinSizeRatio float64
+	// MaxLength is the maximum number of characters of a heading line. Default: 100.
+	MaxLength int
+}
+
+// Default heading inference options.
+const (
+	defaultHeadingMaxLevels    = 3
+	defaultHeadingMinSizeRatio = 1.2
+	defaultHeadingMaxLength    = 100
+)
+
+// headingLineStyleRatio is the minimum fraction of the characters of a heading line which are
+// drawn with the heading style.
+const headingLineStyleRatio = 0.8
+
+// headingStyle is the style of a line of text: its rounded font size and font weight.
+type headingStyle struct {
+	size float64
+	bold bool
+}
+
+// less returns true if `s` is smaller or lighter than `other`. It is used for breaking ties
+// deterministically.
+func (s headingStyle) less(other headingStyle) bool {
+	if s.size != other.size {
+		return s.size < other.size
+	}
+	return !s.bold && other.bold
+}
+
+// headingLine is a line of text of a page.
+type headingLine struct {
+	text    string
+	pageNum int
+	bbox    model.PdfRectangle
+	style   headingStyle
+	chars   int // Number of characters of the line.
+	styled  int // Number of characters drawn with `style`.
+}
+
+// InferHeadings infers the headings of the document `r` from font size and weight statistics:
+// the body text is the style of most of the text, and the lines drawn with larger or bold fonts
+// are headings, leveled by decreasing font size. This allows building an outline for documents
+// which lack one, see HeadingsOutline. `opts` may be nil for the default options. The inference
+// stops with the error of `ctx` when it is cancelled or its deadline is exceeded.
+func InferHeadings(ctx context.Context, r *model.PdfReader, opts *HeadingOptions) ([]*Heading, error) {
+	numPages, err := r.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []*headingLine
+	for i := 0; i < numPages; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := r.GetPage(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		e, err := New(page)
+		if err != nil {
+			return nil, err
+		}
+		pageText, _, _, err := e.ExtractPageText()
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, headingLines(pageText.Marks().Elements(), i+1)...)
+	}
+	return inferHeadings(lines, opts), nil
+}
+
+// HeadingsOutline returns the outline of the document `r` with the headings `headings`, nested
+// according to their levels, e.g. as inferred by InferHeadings. The outline items point to the
+// top of the headings. The outline can be written as bookmarks with PdfWriter.AddOutlineTree or
+// PdfAppender.SetOutline.
+func HeadingsOutline(r *model.PdfReader, headings []*Heading) (*model.Outline, error) {
+	outline := model.NewOutline()
+	type parent struct {
+		level int
+		item  *model.OutlineItem
+	}
+	var parents []parent
+	for _, h := range headings {
+		page, err := r.GetPage(h.PageNum)
+		if err != nil {
+			return nil, err
+		}
+		dest := model.NewOutlineDest(int64(h.PageNum-1), h.BBox.Llx, h.BBox.Ury)
+		dest.PageObj = page.GetPageAsIndirectObject()
+		item := model.NewOutlineItem(h.Title, dest)
+
+		for len(parents) > 0 && parents[len(parents)-1].level >= h.Level {
+			parents = parents[:len(parents)-1]
+		}
+		if len(parents) == 0 {
+			outline.Add(item)
+		} else {
+			parents[len(parents)-1].item.Add(item)
+		}
+		parents = append(parents, parent{level: h.Level, item: item})
+	}
+	return outline, nil
+}
+
+// headingLines returns the lines of text of the page `pageNum` with text marks `marks`.
+func headingLines(marks []TextMark, pageNum int) []*headingLine {
+	var lines []*headingLine
+	var b strings.Builder
+	var line *headingLine
+	styleChars := map[headingStyle]int{}
+
+	flush := func() {
+		if line == nil {
+			return
+		}
+		line.text = strings.TrimSpace(b.String())
+		for style, n := range styleChars {
+			if n > line.styled || n == line.styled && style.less(line.style) {
+				line.style, line.styled = style, n
+			}
+		}
+		if line.text != "" {
+			lines = append(lines, line)
+		}
+		line = nil
+		b.Reset()
+		styleChars = map[headingStyle]int{}
+	}
+
+	for _, tm := range marks {
+		if tm.Meta {
+			if strings.Contains(tm.Text, "\n") {
+				flush()
+			} else if line != nil {
+				b.WriteString(" ")
+			}
+			continue
+		}
+		if line == nil {
+			line = &headingLine{pageNum: pageNum, bbox: tm.BBox}
+		} else {
+			line.bbox = rectUnion(line.bbox, tm.BBox)
+		}
+		b.WriteString(tm.Text)
+		n := utf8.RuneCountInString(tm.Text)
+		line.chars += n
+		styleChars[textMarkHeadingStyle(tm)] += n
+	}
+	flush()
+	return lines
+}
+
+// textMarkHeadingStyle returns the style of `tm`. The font size is rounded to half points so that
+// sizes differing by rounding errors are considered as the same.
+func textMarkHeadingStyle(tm TextMark) headingStyle {
+	return headingStyle{
+		size: math.Round(tm.FontSize*2) / 2,
+		bold: tm.IsBold(),
+	}
+}
+
+// inferHeadings returns the headings of the document with lines of text `lines`, see
+// InferHeadings.
+func inferHeadings(lines []*headingLine, opts *HeadingOptions) []*Heading {
+	maxLevels, minSizeRatio, maxLength := defaultHeadingMaxLevels, defaultHeadingMinSizeRatio,
+		defaultHeadingMaxLength
+	if opts != nil {
+		if opts.MaxLevels > 0 {
+			maxLevels = opts.MaxLevels
+		}
+		if opts.MinSizeRatio > 0 {
+			minSizeRatio = opts.MinSizeRatio
+		}
+		if opts.MaxLength > 0 {
+			maxLength = opts.MaxLength
+		}
+	}
+
+	// The style of the body text is the style of most characters.
+	styleChars := map[headingStyle]int{}
+	for _, line := range lines {
+		styleChars[line.style] += line.chars
+	}
+	var body headingStyle
+	bodyChars := 0
+	for style, n := range styleChars {
+		if n > bodyChars || n == bodyChars && style.less(body) {
+			body, bodyChars = style, n
+		}
+	}
+
+	// The heading styles are the larger and bold styles, leveled by decreasing size, the bold
+	// styles first.
+	var styles []headingStyle
+	for style, n := range styleChars {
+		if n >= bodyChars {
+			continue
+		}
+		if style.size >= body.size*minSizeRatio || style.size >= body.size && style.bold && !body.bold {
+			styles = append(styles, style)
+		}
+	}
+	sort.Slice(styles, func(i, j int) bool {
+		if styles[i].size != styles[j].size {
+			return styles[i].size > styles[j].size
+		}
+		return styles[i].bold && !styles[j].bold
+	})
+	if len(styles) > maxLevels {
+		styles = styles[:maxLevels]
+	}
+	levels := map[headingStyle]int{}
+	for i, style := range styles {
+		levels[style] = i + 1
+	}
+
+	var headings []*Heading
+	var prev *headingLine
+	for _, line := range lines {
+		level, ok := levels[line.style]
+		if !ok || !isHeadingLine(line, maxLength) {
+			prev = nil
+			continue
+		}
+
+		// Join the lines of multi-line headings.
+		if prev != nil && prev.pageNum == line.pageNum && prev.style == line.style &&
+			prev.bbox.Lly-line.bbox.Ury < line.style.size {
+			h := headings[len(headings)-1]
+			h.Title += " " + line.text
+			h.BBox = rectUnion(h.BBox, line.bbox)
+			prev = line
+			continue
+		}
+		headings = append(headings, &Heading{
+			Title:    line.text,
+			Level:    level,
+			PageNum:  line.pageNum,
+			BBox:     line.bbox,
+			FontSize: line.style.size,
+			Bold:     line.style.bold,
+		})
+		prev = line
+	}
+	return headings
+}
+
+// isHeadingLine returns true if `line` may be a heading line: it is short, mostly drawn with its
+// style and contains a letter or a digit.
+func isHeadingLine(line *headingLine, maxLength int) bool {
+	if utf8.RuneCountInString(line.text) > maxLength {
+		return false
+	}
+	if float64(line.styled) < headingLineStyleRatio*float64(line.chars) {
+		return false
+	}
+	for _, r := range line.text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestInferHeadings(t *testing.T) {
+	newPage := func(contents string) *model.PdfPage {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+		for name, stdName := range map[string]model.StdFontName{
+			"F1": model.HelveticaName,
+			"F2": model.HelveticaBoldName,
+		} {
+			font, err := model.NewStandard14Font(stdName)
+			require.NoError(t, err)
+			require.NoError(t, page.Resources.SetFontByName(core.PdfObjectName(name), font.ToPdfObject()))
+		}
+		require.NoError(t, page.SetContentStreams([]string{contents}, core.NewRawEncoder()))
+		return page
+	}
+	body := func(y int) string {
+		var b strings.Builder
+		b.WriteString("BT /F1 10 Tf 72 " + strconv.Itoa(y) + " Td 12 TL\n")
+		for i := 0; i < 5; i++ {
+			b.WriteString("(Lorem ipsum dolor sit amet, consectetur adipiscing elit.) Tj T*\n")
+		}
+		b.WriteString("ET\n")
+		return b.String()
+	}
+
+	pages := []*model.PdfPage{
+		newPage("BT /F2 24 Tf 72 720 Td (Chapter 1) Tj ET\n" +
+			"BT /F2 16 Tf 72 680 Td (Section 1.1) Tj ET\n" + body(650) +
+			"BT /F2 10 Tf 72 560 Td (Note) Tj ET\n" + body(540) +
+			"BT /F2 16 Tf 72 460 Td (Section 1.2 with a long) Tj 0 -18 Td (title) Tj ET\n" + body(400)),
+		newPage("BT /F2 24 Tf 72 720 Td (Chapter 2) Tj ET\n" + body(680)),
+	}
+	var lines []*headingLine
+	for i, page := range pages {
+		e, err := New(page)
+		require.NoError(t, err)
+		pageText, _, _, err := e.ExtractPageText()
+		require.NoError(t, err)
+		lines = append(lines, headingLines(pageText.Marks().Elements(), i+1)...)
+	}
+	headings := inferHeadings(lines, nil)
+
+	type heading struct {
+		title   string
+		level   int
+		pageNum int
+	}
+	var got []heading
+	for _, h := range headings {
+		got = append(got, heading{h.Title, h.Level, h.PageNum})
+	}
+	require.Equal(t, []heading{
+		{"Chapter 1", 1, 1},
+		{"Section 1.1", 2, 1},
+		{"Note", 3, 1},
+		{"Section 1.2 with a long title", 2, 1},
+		{"Chapter 2", 1, 2},
+	}, got)
+	require.True(t, headings[0].Bold)
+	require.Equal(t, 24.0, headings[0].FontSize)
+
+	// Two levels at most.
+	require.Len(t, inferHeadings(lines, &HeadingOptions{MaxLevels: 2}), 4)
+
+	// Write the outline as bookmarks.
+	w := model.NewPdfWriter()
+	for _, page := range pages {
+		require.NoError(t, w.AddPage(page))
+	}
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	_, err = InferHeadings(context.Background(), r, nil)
+	require.NoError(t, err)
+
+	outline, err := HeadingsOutline(r, headings)
+	require.NoError(t, err)
+	require.Len(t, outline.Entries, 2)
+	require.Len(t, outline.Entries[0].Entries, 2)
+	require.Len(t, outline.Entries[0].Entries[0].Entries, 1)
+
+	a, err := model.NewPdfAppender(r)
+	require.NoError(t, err)
+	a.SetOutline(outline)
+	buf.Reset()
+	require.NoError(t, a.Write(&buf))
+
+	r, err = model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	outline, err = r.GetOutlines()
+	require.NoError(t, err)
+	require.Len(t, outline.Entries, 2)
+	require.Equal(t, "Chapter 1", outline.Entries[0].Title)
+	require.Equal(t, "Section 1.2 with a long title", outline.Entries[0].Entries[1].Title)
+	require.Equal(t, "Chapter 2", outline.Entries[1].Title)
+	require.Equal(t, int64(1), outline.Entries[1].Dest.Page)
+}
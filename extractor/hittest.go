@@ -0,0 +1,139 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// ContentIndex represents the processed content of a page: its text, images, paths and
+// annotations, for finding the content at given page coordinates. It is created by
+// Extractor.IndexContent.
+type ContentIndex struct {
+	text      []TextMark
+	images    []ImageMark
+	imageCTMs []transform.Matrix
+	paths     []PathMark
+
+	annotations []*model.PdfAnnotation
+	annotRects  []model.PdfRectangle
+}
+
+// ContentHits represents the content of a page found at a point, in the order it is drawn.
+type ContentHits struct {
+	Text        []TextMark
+	Images      []ImageMark
+	Paths       []PathMark
+	Annotations []*model.PdfAnnotation
+}
+
+// IndexContent processes the content of the page and returns an index of its text, images, paths
+// and annotations for hit testing, see ContentIndex.HitTest. The annotations are only indexed for
+// extractors created from pages with New.
+func (e *Extractor) IndexContent() (*ContentIndex, error) {
+	pageText, _, _, err := e.ExtractPageText()
+	if err != nil {
+		return nil, err
+	}
+	images, err := e.extractImages(nil)
+	if err != nil {
+		return nil, err
+	}
+	vectors, err := e.ExtractPageVectors(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &ContentIndex{
+		images:    images.extractedImages,
+		imageCTMs: images.imageCTMs,
+		paths:     vectors.Paths,
+	}
+	for _, mark := range pageText.Marks().Elements() {
+		// Skip the spaces and line breaks inserted in the extracted text.
+		if !mark.Meta {
+			index.text = append(index.text, mark)
+		}
+	}
+
+	if e.page != nil {
+		annotations, err := e.page.GetAnnotations()
+		if err != nil {
+			return nil, err
+		}
+		for _, annot := range annotations {
+			arr, ok := core.GetArray(annot.Rect)
+			if !ok {
+				continue
+			}
+			rect, err := model.NewPdfRectangle(*arr)
+			if err != nil {
+				common.Log.Debug("Invalid annotation rectangle: %v", err)
+				continue
+			}
+			index.annotations = append(index.annotations, annot)
+			index.annotRects = append(index.annotRects, *rect)
+		}
+	}
+	return index, nil
+}
+
+// HitTest returns the content of the page whose bounds contain the point (`x`,`y`) in device
+// coordinates, i.e. in the default user space of the page. Text and paths are matched by their
+// bounding boxes, images by the area they are drawn in and annotations by their rectangles.
+func (ci *ContentIndex) HitTest(x, y float64) *ContentHits {
+	hits := &ContentHits{}
+	for _, mark := range ci.text {
+		if rectContains(mark.BBox, x, y) {
+			hits.Text = append(hits.Text, mark)
+		}
+	}
+	for i, img := range ci.images {
+		if imageContains(ci.imageCTMs[i], x, y) {
+			hits.Images = append(hits.Images, img)
+		}
+	}
+	for _, path := range ci.paths {
+		if rectContains(path.BBox, x, y) {
+			hits.Paths = append(hits.Paths, path)
+		}
+	}
+	for i, annot := range ci.annotations {
+		if rectContains(ci.annotRects[i], x, y) {
+			hits.Annotations = append(hits.Annotations, annot)
+		}
+	}
+	return hits
+}
+
+// rectContains returns true if the point (`x`,`y`) is inside `r`, including its edges.
+func rectContains(r model.PdfRectangle, x, y float64) bool {
+	llx, urx := r.Llx, r.Urx
+	if llx > urx {
+		llx, urx = urx, llx
+	}
+	lly, ury := r.Lly, r.Ury
+	if lly > ury {
+		lly, ury = ury, lly
+	}
+	return llx <= x && x <= urx && lly <= y && y <= ury
+}
+
+// imageContains returns true if the point (`x`,`y`) is inside the unit square transformed by
+// `ctm`, which is the area images are drawn in.
+func imageContains(ctm transform.Matrix, x, y float64) bool {
+	det := ctm[0]*ctm[4] - ctm[1]*ctm[3]
+	if det == 0 {
+		return false
+	}
+	dx, dy := x-ctm[6], y-ctm[7]
+	u := (ctm[4]*dx - ctm[3]*dy) / det
+	v := (ctm[0]*dy - ctm[1]*dx) / det
+	return 0 <= u && u <= 1 && 0 <= v && v <= 1
+}
@@ -0,0 +1,59 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestHitTest(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	font, err := model.NewStandard14Font(model.HelveticaName)
+	require.NoError(t, err)
+	require.NoError(t, page.Resources.SetFontByName("F1", font.ToPdfObject()))
+
+	// Text, vertically flipped and rotated inline images and line.
+	contents := `BT /F1 12 Tf 100 700 Td (Hello) Tj ET
+q 50 0 0 -50 300 600 cm BI /W 1 /H 1 /CS /G /BPC 8 ID A EI Q
+q 0 50 -50 0 500 600 cm BI /W 1 /H 1 /CS /G /BPC 8 ID A EI Q
+10 10 m 60 60 l S`
+	require.NoError(t, page.SetContentStreams([]string{contents}, core.NewRawEncoder()))
+	link := model.NewPdfAnnotationLink()
+	link.Rect = core.MakeArrayFromFloats([]float64{90, 690, 200, 720})
+	page.AddAnnotation(link.PdfAnnotation)
+
+	e, err := New(page)
+	require.NoError(t, err)
+	index, err := e.IndexContent()
+	require.NoError(t, err)
+
+	hits := index.HitTest(102, 704)
+	require.Len(t, hits.Text, 1)
+	require.Equal(t, "H", hits.Text[0].Text)
+	require.Len(t, hits.Annotations, 1)
+	require.Equal(t, link.PdfAnnotation, hits.Annotations[0])
+	require.Empty(t, hits.Images)
+	require.Empty(t, hits.Paths)
+
+	hits = index.HitTest(320, 570)
+	require.Len(t, hits.Images, 1)
+	require.Empty(t, hits.Text)
+	require.Empty(t, index.HitTest(320, 620).Images)
+
+	// The image rotated by 90 degrees covers [450,500]x[600,650].
+	require.Len(t, index.HitTest(475, 625).Images, 1)
+	require.Empty(t, index.HitTest(525, 575).Images)
+
+	hits = index.HitTest(30, 30)
+	require.Len(t, hits.Paths, 1)
+	require.Empty(t, hits.Annotations)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/unidoc/unipdf/v3/common"
 	"github.com/unidoc/unipdf/v3/contentstream"
 	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
 	"github.com/unidoc/unipdf/v3/model"
 )
 
@@ -24,6 +25,18 @@ type ImageExtractOptions struct {
 // parameter can be nil for the default options. By default, inline stencil masks
 // are not extracted.
 func (e *Extractor) ExtractPageImages(options *ImageExtractOptions) (*PageImages, error) {
+	ctx, err := e.extractImages(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PageImages{
+		Images: ctx.extractedImages,
+	}, nil
+}
+
+// extractImages processes the page contents and returns the context holding the extracted images.
+func (e *Extractor) extractImages(options *ImageExtractOptions) (*imageExtractContext, error) {
 	ctx := &imageExtractContext{
 		options: options,
 	}
@@ -32,10 +45,7 @@ func (e *Extractor) ExtractPageImages(options *ImageExtractOptions) (*PageImages
 	if err != nil {
 		return nil, err
 	}
-
-	return &PageImages{
-		Images: ctx.extractedImages,
-	}, nil
+	return ctx, nil
 }
 
 // PageImages represents extracted images on a PDF page with spatial information:
@@ -64,6 +74,7 @@ type ImageMark struct {
 // Provide context for image extraction content stream processing.
 type imageExtractContext struct {
 	extractedImages []ImageMark
+	imageCTMs       []transform.Matrix // CTMs the extracted images are drawn with.
 	inlineImages    int
 	xObjectImages   int
 	xObjectForms    int
@@ -167,6 +178,7 @@ func (ctx *imageExtractContext) extractInlineImage(iimg *contentstream.ContentSt
 	imgMark.X, imgMark.Y = gs.CTM.Translation()
 
 	ctx.extractedImages = append(ctx.extractedImages, imgMark)
+	ctx.imageCTMs = append(ctx.imageCTMs, gs.CTM)
 	ctx.inlineImages++
 	return nil
 }
@@ -217,6 +229,7 @@ func (ctx *imageExtractContext) extractXObjectImage(name *core.PdfObjectName, gs
 	imgMark.X, imgMark.Y = gs.CTM.Translation()
 
 	ctx.extractedImages = append(ctx.extractedImages, imgMark)
+	ctx.imageCTMs = append(ctx.imageCTMs, gs.CTM)
 	ctx.xObjectImages++
 	return nil
 }
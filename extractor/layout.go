@@ -0,0 +1,281 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// BlockType is the type of a block of a page layout.
+type BlockType int
+
+// Block types.
+const (
+	// BlockParagraph is a paragraph of body text.
+	BlockParagraph BlockType = iota
+	// BlockHeading is a heading: a short paragraph drawn with a larger or bolder font than the body
+	// text.
+	BlockHeading
+	// BlockList is a list, whose items start with bullets or enumerators.
+	BlockList
+	// BlockTable is a table.
+	BlockTable
+	// BlockFigure is an image.
+	BlockFigure
+	// BlockHeader is text in the top margin of the page, e.g. a running title.
+	BlockHeader
+	// BlockFooter is text in the bottom margin of the page, e.g. a page number.
+	BlockFooter
+)
+
+// String returns the name of the block type.
+func (t BlockType) String() string {
+	switch t {
+	case BlockHeading:
+		return "heading"
+	case BlockList:
+		return "list"
+	case BlockTable:
+		return "table"
+	case BlockFigure:
+		return "figure"
+	case BlockHeader:
+		return "header"
+	case BlockFooter:
+		return "footer"
+	}
+	return "paragraph"
+}
+
+// LayoutBlock is a block of a page layout, see Extractor.AnalyzeLayout.
+type LayoutBlock struct {
+	// Type is the type of the block.
+	Type BlockType
+	// BBox is the bounding box of the block in device coordinates.
+	BBox model.PdfRectangle
+	// Text is the text of the block. Empty for figures.
+	Text string
+	// Marks are the text marks of the block. The offsets of the marks are relative to Text.
+	// Empty for figures.
+	Marks *TextMarkArray
+	// Items are the texts of the items of lists, without their bullets or enumerators.
+	Items []string
+	// Table is the table of table blocks.
+	Table *TextTable
+	// Image is the image of figure blocks.
+	Image *ImageMark
+}
+
+// PageLayout is the layout of a page: its blocks, in reading order. The header blocks come first
+// and the footer blocks last.
+type PageLayout struct {
+	Blocks []*LayoutBlock
+}
+
+// layoutMarginR is the fraction of the page height of the top and bottom margins, where the text
+// is classified as headers and footers.
+const layoutMarginR = 0.08
+
+// layoutMarginMaxLines is the maximum number of lines of headers and footers.
+const layoutMarginMaxLines = 2
+
+// layoutHeadingMaxLines is the maximum number of lines of headings.
+const layoutHeadingMaxLines = 3
+
+// listItemRegexp matches the bullet or enumerator of list items, e.g. "•", "-", "1.", "a)" or
+// "(iv)".
+var listItemRegexp = regexp.MustCompile(`^\s*(?:[•◦▪▫●○■□‣⁃–—*-]\s*|\(?(?:\d{1,3}|[a-zA-Z]|[ivxlcdm]{1,6})[.)]\s+)(\S.*)$`)
+
+// AnalyzeLayout segments the page into blocks classified as paragraphs, headings, lists, tables,
+// figures, headers and footers, using the positions and styles of the text extracted from the
+// page (see ExtractPageText) and the images drawn on it. Headings are detected by comparing their
+// font size and weight with the body text of the page, headers and footers by their position in
+// the top and bottom margins of the page. The blocks are returned in reading order, which allows
+// converting pages to structured formats such as HTML.
+func (e *Extractor) AnalyzeLayout() (*PageLayout, error) {
+	pageText, _, _, err := e.ExtractPageText()
+	if err != nil {
+		return nil, err
+	}
+	images, err := e.extractImages(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*LayoutBlock
+	var paras paraList
+	for _, para := range pageText.paras {
+		offset := 0
+		marks := &TextMarkArray{marks: para.toTextMarks(&offset)}
+		bbox, ok := marks.BBox()
+		if !ok {
+			continue
+		}
+		block := &LayoutBlock{
+			Type:  BlockParagraph,
+			BBox:  bbox,
+			Text:  para.text(),
+			Marks: marks,
+		}
+		if para.table != nil {
+			table := para.table.toTextTable()
+			block.Type = BlockTable
+			block.Table = &table
+		}
+		blocks = append(blocks, block)
+		paras = append(paras, para)
+	}
+	classifyLayoutBlocks(blocks, paras, e.mediaBox)
+
+	for i := range images.extractedImages {
+		blocks = insertFigure(blocks, &LayoutBlock{
+			Type:  BlockFigure,
+			BBox:  imageBBox(images.imageCTMs[i]),
+			Image: &images.extractedImages[i],
+		})
+	}
+	return &PageLayout{Blocks: orderMarginBlocks(blocks)}, nil
+}
+
+// classifyLayoutBlocks classifies the text blocks `blocks` of a page with media box `mediaBox`.
+// `paras` are the paragraphs of the blocks.
+func classifyLayoutBlocks(blocks []*LayoutBlock, paras paraList, mediaBox model.PdfRectangle) {
+	// The style of the body text is the style of most characters of the page.
+	styleChars := map[headingStyle]int{}
+	for _, block := range blocks {
+		for style, n := range blockStyles(block) {
+			styleChars[style] += n
+		}
+	}
+	var body headingStyle
+	bodyChars := 0
+	for style, n := range styleChars {
+		if n > bodyChars || n == bodyChars && style.less(body) {
+			body, bodyChars = style, n
+		}
+	}
+
+	margin := layoutMarginR * mediaBox.Height()
+	for i, block := range blocks {
+		if block.Type == BlockTable {
+			continue
+		}
+		numLines := len(paras[i].lines)
+		switch {
+		case numLines <= layoutMarginMaxLines && block.BBox.Lly >= mediaBox.Ury-margin:
+			block.Type = BlockHeader
+		case numLines <= layoutMarginMaxLines && block.BBox.Ury <= mediaBox.Lly+margin:
+			block.Type = BlockFooter
+		case isListPara(paras[i]):
+			block.Type = BlockList
+			block.Items = listItems(paras[i])
+		case numLines <= layoutHeadingMaxLines && isHeadingBlock(block, body):
+			block.Type = BlockHeading
+		}
+	}
+}
+
+// blockStyles returns the number of characters of each style of the text block `block`.
+func blockStyles(block *LayoutBlock) map[headingStyle]int {
+	styles := map[headingStyle]int{}
+	for _, tm := range block.Marks.Elements() {
+		if !tm.Meta {
+			styles[textMarkHeadingStyle(tm)] += utf8.RuneCountInString(tm.Text)
+		}
+	}
+	return styles
+}
+
+// isHeadingBlock returns true if the text block `block` is mostly drawn with a style larger or
+// bolder than the `body` style.
+func isHeadingBlock(block *LayoutBlock, body headingStyle) bool {
+	var style headingStyle
+	styled, chars := 0, 0
+	for s, n := range blockStyles(block) {
+		chars += n
+		if n > styled || n == styled && s.less(style) {
+			style, styled = s, n
+		}
+	}
+	if chars == 0 || float64(styled) < headingLineStyleRatio*float64(chars) {
+		return false
+	}
+	return style.size >= body.size*defaultHeadingMinSizeRatio ||
+		style.size >= body.size && style.bold && !body.bold
+}
+
+// isListPara returns true if the first line of `para` starts with a bullet or an enumerator.
+func isListPara(para *textPara) bool {
+	return len(para.lines) > 0 && listItemRegexp.MatchString(para.lines[0].text())
+}
+
+// listItems returns the texts of the list items of `para`. The lines which do not start with a
+// bullet or an enumerator continue the previous item.
+func listItems(para *textPara) []string {
+	var items []string
+	for _, line := range para.lines {
+		text := line.text()
+		if m := listItemRegexp.FindStringSubmatch(text); m != nil || len(items) == 0 {
+			if m != nil {
+				text = m[1]
+			}
+			items = append(items, strings.TrimSpace(text))
+			continue
+		}
+		items[len(items)-1] += " " + strings.TrimSpace(text)
+	}
+	return items
+}
+
+// imageBBox returns the bounding box of the area an image drawn with the CTM `ctm` covers, i.e.
+// the unit square transformed by `ctm`.
+func imageBBox(ctm transform.Matrix) model.PdfRectangle {
+	bbox := model.PdfRectangle{Llx: math.Inf(1), Lly: math.Inf(1), Urx: math.Inf(-1), Ury: math.Inf(-1)}
+	for _, p := range [][2]float64{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		x, y := ctm[0]*p[0]+ctm[3]*p[1]+ctm[6], ctm[1]*p[0]+ctm[4]*p[1]+ctm[7]
+		bbox.Llx, bbox.Urx = math.Min(bbox.Llx, x), math.Max(bbox.Urx, x)
+		bbox.Lly, bbox.Ury = math.Min(bbox.Lly, y), math.Max(bbox.Ury, y)
+	}
+	return bbox
+}
+
+// insertFigure inserts the figure block `figure` in `blocks` before the first block starting
+// below the top of the figure, other than headers and footers.
+func insertFigure(blocks []*LayoutBlock, figure *LayoutBlock) []*LayoutBlock {
+	i := len(blocks)
+	for j, block := range blocks {
+		if block.Type != BlockHeader && block.Type != BlockFooter && block.BBox.Ury < figure.BBox.Ury {
+			i = j
+			break
+		}
+	}
+	blocks = append(blocks, nil)
+	copy(blocks[i+1:], blocks[i:])
+	blocks[i] = figure
+	return blocks
+}
+
+// orderMarginBlocks returns `blocks` with the header blocks first and the footer blocks last.
+func orderMarginBlocks(blocks []*LayoutBlock) []*LayoutBlock {
+	var headers, body, footers []*LayoutBlock
+	for _, block := range blocks {
+		switch block.Type {
+		case BlockHeader:
+			headers = append(headers, block)
+		case BlockFooter:
+			footers = append(footers, block)
+		default:
+			body = append(body, block)
+		}
+	}
+	return append(append(headers, body...), footers...)
+}
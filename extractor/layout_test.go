@@ -0,0 +1,78 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestAnalyzeLayout(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	for name, stdName := range map[string]model.StdFontName{
+		"F1": model.HelveticaName,
+		"F2": model.HelveticaBoldName,
+	} {
+		font, err := model.NewStandard14Font(stdName)
+		require.NoError(t, err)
+		require.NoError(t, page.Resources.SetFontByName(core.PdfObjectName(name), font.ToPdfObject()))
+	}
+
+	contents := `BT /F1 8 Tf 72 770 Td (Running title) Tj ET
+BT /F2 20 Tf 72 700 Td (Introduction) Tj ET
+BT /F1 10 Tf 72 660 Td 12 TL
+(Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod) Tj T*
+(tempor incididunt ut labore et dolore magna aliqua. Ut enim ad minim veniam,) Tj T*
+(quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo.) Tj ET
+BT /F1 10 Tf 72 580 Td 12 TL
+(1. First item of the list) Tj T*
+(2. Second item of the list, which is) Tj T*
+(continued on a second line) Tj ET
+q 100 0 0 50 72 420 cm BI /W 1 /H 1 /CS /G /BPC 8 ID A EI Q
+BT /F1 8 Tf 300 30 Td (1) Tj ET`
+	require.NoError(t, page.SetContentStreams([]string{contents}, core.NewRawEncoder()))
+
+	e, err := New(page)
+	require.NoError(t, err)
+	layout, err := e.AnalyzeLayout()
+	require.NoError(t, err)
+
+	var types []BlockType
+	for _, block := range layout.Blocks {
+		types = append(types, block.Type)
+	}
+	require.Equal(t, []BlockType{BlockHeader, BlockHeading, BlockParagraph, BlockList, BlockFigure, BlockFooter},
+		types)
+
+	blocks := layout.Blocks
+	require.Equal(t, "Running title", blocks[0].Text)
+	require.Equal(t, "Introduction", blocks[1].Text)
+	require.Contains(t, blocks[2].Text, "Lorem ipsum")
+	require.Equal(t, []string{"First item of the list", "Second item of the list, which is continued on a second line"},
+		blocks[3].Items)
+	require.NotNil(t, blocks[4].Image)
+	require.Equal(t, model.PdfRectangle{Llx: 72, Lly: 420, Urx: 172, Ury: 470}, blocks[4].BBox)
+	require.Equal(t, "1", blocks[5].Text)
+	require.Equal(t, "figure", BlockFigure.String())
+
+	// The box of a rotated image is the area it covers.
+	e, err = NewFromContents(`q 0 50 -50 0 300 600 cm BI /W 1 /H 1 /CS /G /BPC 8 ID A EI Q`, page.Resources)
+	require.NoError(t, err)
+	layout, err = e.AnalyzeLayout()
+	require.NoError(t, err)
+	require.Len(t, layout.Blocks, 1)
+	require.Equal(t, model.PdfRectangle{Llx: 250, Lly: 600, Urx: 300, Ury: 650}, layout.Blocks[0].BBox)
+
+	for _, block := range blocks[:4] {
+		require.NotNil(t, block.Marks)
+		require.True(t, block.BBox.Ury > block.BBox.Lly)
+	}
+}
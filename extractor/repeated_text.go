@@ -0,0 +1,211 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// RepeatedText is a line of text repeated at the same position on many pages of a document, such
+// as a running head or a page number, found by FindRepeatedText.
+type RepeatedText struct {
+	// Text is the text of the first occurrence of the line.
+	Text string
+	// BBox is the bounding box of the first occurrence of the line.
+	BBox model.PdfRectangle
+	// Pages are the numbers of the pages where the line is repeated, starting from 1.
+	Pages []int
+
+	key       string  // Normalized text of the line.
+	tolerance float64 // Position tolerance.
+}
+
+// RepeatedTextOptions specifies the heuristics used for finding repeated text.
+type RepeatedTextOptions struct {
+	// MinPageRatio is the minimum fraction of the pages of the document where a line must be
+	// repeated. The lines must be repeated on at least 2 pages. Default: 0.5.
+	MinPageRatio float64
+	// Tolerance is the maximum distance in points between the positions of the occurrences of a
+	// line. Default: 4.
+	Tolerance float64
+}
+
+// Default repeated text options.
+const (
+	defaultRepeatedMinPageRatio = 0.5
+	defaultRepeatedTolerance    = 4
+)
+
+// repeatedLine is a line of the extracted text of a page.
+type repeatedLine struct {
+	text       string
+	bbox       model.PdfRectangle
+	start, end int // Offsets of the line in the extracted text.
+}
+
+// FindRepeatedText returns the lines of text of the document `r` which are repeated at the same
+// position on most of its pages, such as running heads and page numbers. The numbers of the lines
+// are ignored so that the lines differing by their numbers are considered as the same. The found
+// lines can be removed from the text of the pages with PageText.TextWithout. `opts` may be nil
+// for the default options. The search stops with the error of `ctx` when it is cancelled or its
+// deadline is exceeded.
+func FindRepeatedText(ctx context.Context, r *model.PdfReader, opts *RepeatedTextOptions) ([]*RepeatedText, error) {
+	pageTexts, err := extractDocumentPageTexts(ctx, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	return findRepeatedText(pageTexts, opts), nil
+}
+
+// findRepeatedText returns the lines repeated on the pages with texts `pageTexts`, see
+// FindRepeatedText.
+func findRepeatedText(pageTexts []*PageText, opts *RepeatedTextOptions) []*RepeatedText {
+	minPageRatio, tolerance := defaultRepeatedMinPageRatio, float64(defaultRepeatedTolerance)
+	if opts != nil {
+		if opts.MinPageRatio > 0 {
+			minPageRatio = opts.MinPageRatio
+		}
+		if opts.Tolerance > 0 {
+			tolerance = opts.Tolerance
+		}
+	}
+	minPages := int(math.Ceil(minPageRatio * float64(len(pageTexts))))
+	if minPages < 2 {
+		minPages = 2
+	}
+
+	// Group the occurrences of the lines by normalized text, then by position.
+	var candidates []*RepeatedText
+	byKey := map[string][]*RepeatedText{}
+	for i, pt := range pageTexts {
+		for _, line := range pageTextLines(pt) {
+			key := normalizeMarginLine(line.text)
+			var found *RepeatedText
+			for _, rt := range byKey[key] {
+				if rt.matchesPosition(line.bbox) {
+					found = rt
+					break
+				}
+			}
+			if found == nil {
+				found = &RepeatedText{Text: line.text, BBox: line.bbox, key: key, tolerance: tolerance}
+				byKey[key] = append(byKey[key], found)
+				candidates = append(candidates, found)
+			}
+			if n := len(found.Pages); n == 0 || found.Pages[n-1] != i+1 {
+				found.Pages = append(found.Pages, i+1)
+			}
+		}
+	}
+
+	var repeated []*RepeatedText
+	for _, rt := range candidates {
+		if len(rt.Pages) >= minPages {
+			repeated = append(repeated, rt)
+		}
+	}
+	sort.SliceStable(repeated, func(i, j int) bool {
+		return repeated[i].BBox.Ury > repeated[j].BBox.Ury
+	})
+	return repeated
+}
+
+// Matches returns true if the line of text `text` with bounding box `bbox` is an occurrence of
+// `rt`.
+func (rt *RepeatedText) Matches(text string, bbox model.PdfRectangle) bool {
+	return normalizeMarginLine(text) == rt.key && rt.matchesPosition(bbox)
+}
+
+// matchesPosition returns true if `bbox` is at the position of `rt`: on the same baseline and
+// aligned on the left, on the right or on the center, as the widths of the lines vary with
+// their numbers.
+func (rt *RepeatedText) matchesPosition(bbox model.PdfRectangle) bool {
+	if math.Abs(bbox.Lly-rt.BBox.Lly) > rt.tolerance {
+		return false
+	}
+	return math.Abs(bbox.Llx-rt.BBox.Llx) <= rt.tolerance ||
+		math.Abs(bbox.Urx-rt.BBox.Urx) <= rt.tolerance ||
+		math.Abs(bbox.Llx+bbox.Urx-rt.BBox.Llx-rt.BBox.Urx) <= 2*rt.tolerance
+}
+
+// TextWithout returns the extracted text of the page, see Text, without the lines which are
+// occurrences of `repeated`, e.g. the running heads and page numbers found by FindRepeatedText.
+func (pt PageText) TextWithout(repeated []*RepeatedText) string {
+	text := pt.Text()
+	var b strings.Builder
+	pos := 0
+	for _, line := range pageTextLines(&pt) {
+		if !isRepeatedLine(line, repeated) {
+			continue
+		}
+		b.WriteString(text[pos:line.start])
+		pos = line.end
+		// The trailing spaces and the line break of the removed line are removed with it.
+		for pos < len(text) && text[pos] == ' ' {
+			pos++
+		}
+		if pos < len(text) && text[pos] == '\n' {
+			pos++
+		}
+	}
+	b.WriteString(text[pos:])
+	return strings.Trim(b.String(), "\n")
+}
+
+// isRepeatedLine returns true if `line` is an occurrence of one of `repeated`.
+func isRepeatedLine(line *repeatedLine, repeated []*RepeatedText) bool {
+	for _, rt := range repeated {
+		if rt.Matches(line.text, line.bbox) {
+			return true
+		}
+	}
+	return false
+}
+
+// pageTextLines returns the lines of the extracted text of `pt`, separated by line break marks.
+func pageTextLines(pt *PageText) []*repeatedLine {
+	var lines []*repeatedLine
+	var b strings.Builder
+	var line *repeatedLine
+
+	flush := func() {
+		if line != nil {
+			line.text = strings.TrimSpace(b.String())
+			if line.text != "" {
+				lines = append(lines, line)
+			}
+		}
+		line = nil
+		b.Reset()
+	}
+
+	for _, tm := range pt.Marks().Elements() {
+		if tm.Meta && strings.Contains(tm.Text, "\n") {
+			flush()
+			continue
+		}
+		if tm.Meta || isTextSpace(tm.Text) {
+			if line != nil {
+				b.WriteString(tm.Text)
+			}
+			continue
+		}
+		if line == nil {
+			line = &repeatedLine{bbox: tm.BBox, start: tm.Offset}
+		} else {
+			line.bbox = rectUnion(line.bbox, tm.BBox)
+		}
+		b.WriteString(tm.Text)
+		line.end = tm.Offset + len(tm.Text)
+	}
+	flush()
+	return lines
+}
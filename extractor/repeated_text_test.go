@@ -0,0 +1,62 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestFindRepeatedText(t *testing.T) {
+	font, err := model.NewStandard14Font(model.HelveticaName)
+	require.NoError(t, err)
+
+	bodies := []string{
+		"(Annual report) Tj 0 -14 Td (First page body.) Tj",
+		"(Second page body.) Tj",
+		"(Third page body.) Tj",
+		"(Fourth page body.) Tj",
+	}
+	var pageTexts []*PageText
+	for i, body := range bodies {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+		require.NoError(t, page.Resources.SetFontByName("F1", font.ToPdfObject()))
+		// The page numbers are centered, and the running head is missing from the last page.
+		x := 300 - 2.5*float64(len(fmt.Sprint(i+1)))
+		contents := fmt.Sprintf(`BT /F1 10 Tf 72 700 Td %s ET
+BT /F1 10 Tf %.1f 30 Td (Page %d) Tj ET`, body, x, i+1)
+		if i < 3 {
+			contents += "\nBT /F1 8 Tf 72 760 Td (Annual report) Tj ET"
+		}
+		require.NoError(t, page.SetContentStreams([]string{contents}, core.NewRawEncoder()))
+		e, err := New(page)
+		require.NoError(t, err)
+		pt, _, _, err := e.ExtractPageText()
+		require.NoError(t, err)
+		pageTexts = append(pageTexts, pt)
+	}
+
+	repeated := findRepeatedText(pageTexts, nil)
+	require.Len(t, repeated, 2)
+	require.Equal(t, "Annual report", repeated[0].Text)
+	require.Equal(t, []int{1, 2, 3}, repeated[0].Pages)
+	require.Equal(t, "Page 1", repeated[1].Text)
+	require.Equal(t, []int{1, 2, 3, 4}, repeated[1].Pages)
+
+	// The running head in the body of the first page is kept, as it is not at the same position.
+	require.Equal(t, "Annual report\nFirst page body.", pageTexts[0].TextWithout(repeated))
+	require.Equal(t, "Fourth page body.", pageTexts[3].TextWithout(repeated))
+
+	require.Len(t, findRepeatedText(pageTexts, &RepeatedTextOptions{MinPageRatio: 1}), 1)
+	require.Equal(t, "Page # of #", normalizeMarginLine(" Page 9  of 12"))
+	require.Equal(t, normalizeMarginLine("Page 10"), normalizeMarginLine("Page 9"))
+}
@@ -492,10 +492,12 @@ func (to *textObject) setFont(name string, size float64) error {
 		return err
 	}
 	to.state.tfont = font
+	to.state.tfname = name
 	if to.savedStates.empty() {
 		to.savedStates.push(to.state)
 	} else {
 		to.savedStates.top().tfont = to.state.tfont
+		to.savedStates.top().tfname = to.state.tfname
 	}
 
 	return nil
@@ -506,7 +508,7 @@ func (to *textObject) setTextRenderMode(mode int) {
 	if to == nil {
 		return
 	}
-	to.state.tmode = RenderMode(mode)
+	to.state.tmode = renderModeFromTr(mode)
 }
 
 // setTextRise "Ts". Set text rise.
@@ -639,6 +641,7 @@ type textState struct {
 	tmode    RenderMode     // Text rendering mode.
 	trise    float64        // Text rise. Unscaled text space units. Set by Ts.
 	tfont    *model.PdfFont // Text font.
+	tfname   string         // Name of the text font in the font resources. Set by Tf.
 	mediaBox model.PdfRectangle
 	// For debugging
 	numChars  int
@@ -885,6 +888,7 @@ type PageText struct {
 	viewText   string             // Extracted page text.
 	viewMarks  []TextMark         // Public view of text marks.
 	viewTables []TextTable        // Public view of text tables.
+	paras      paraList           // Paragraphs in reading order. Used for layout analysis.
 	pageSize   model.PdfRectangle // Page size. Used to calculate depth.
 }
 
@@ -950,6 +954,7 @@ func (pt *PageText) computeViews() {
 	pt.viewText = b.String()
 	pt.viewMarks = paras.toTextMarks()
 	pt.viewTables = paras.tables()
+	pt.paras = paras
 }
 
 // TextMarkArray is a collection of TextMarks.
@@ -1029,6 +1034,32 @@ func (ma *TextMarkArray) RangeOffset(start, end int) (*TextMarkArray, error) {
 	return &TextMarkArray{marks: ma.marks[iStart:iEnd]}, nil
 }
 
+// Runs splits `ma` into text runs: sequences of consecutive TextMarks drawn with the same text
+// state (font, font size, text rise, rendering mode and fill color). The spaces and line breaks
+// inserted in the extracted text (TextMark.Meta) are added to the current run. Runs allow
+// reconstructing the styling of the text, e.g. to distinguish headings from body text.
+func (ma *TextMarkArray) Runs() []*TextMarkArray {
+	if ma == nil {
+		return nil
+	}
+	var runs []*TextMarkArray
+	var run *TextMarkArray
+	var style TextMark
+	for _, tm := range ma.marks {
+		if run != nil && (tm.Meta || tm.sameStyle(style)) {
+			run.Append(tm)
+			continue
+		}
+		run = &TextMarkArray{}
+		run.Append(tm)
+		runs = append(runs, run)
+		if !tm.Meta {
+			style = tm
+		}
+	}
+	return runs
+}
+
 // BBox returns the smallest axis-aligned rectangle that encloses all the TextMarks in `ma`.
 func (ma *TextMarkArray) BBox() (model.PdfRectangle, bool) {
 	var bbox model.PdfRectangle
@@ -1094,6 +1125,64 @@ type TextMark struct {
 	// StrokeColor is the stroke color of the text.
 	// The color is nil for spaces and line breaks (i.e. the Meta field is true).
 	StrokeColor color.Color
+	// FontName is the name of the font in the font resources of the page, as selected by the Tf
+	// operator, e.g. "F1".
+	FontName string
+	// TextFontSize is the font size set by the Tf operator, in unscaled text space units. Unlike
+	// FontSize, it does not depend on the text and transformation matrices.
+	TextFontSize float64
+	// Rise is the text rise set by the Ts operator, in unscaled text space units. Superscripts have
+	// a positive rise, subscripts a negative one.
+	Rise float64
+	// RenderMode is the text rendering mode set by the Tr operator.
+	// It is zero for invisible text.
+	RenderMode RenderMode
+}
+
+// sameStyle returns true if `tm` and `other` were drawn with the same text state: font, font size,
+// text rise, rendering mode and fill color.
+func (tm TextMark) sameStyle(other TextMark) bool {
+	return tm.FontName == other.FontName && tm.Font == other.Font &&
+		tm.TextFontSize == other.TextFontSize && tm.Rise == other.Rise &&
+		tm.RenderMode == other.RenderMode && sameColor(tm.FillColor, other.FillColor)
+}
+
+// sameColor returns true if `c1` and `c2` are the same color.
+func sameColor(c1, c2 color.Color) bool {
+	if c1 == nil || c2 == nil {
+		return c1 == nil && c2 == nil
+	}
+	r1, g1, b1, a1 := c1.RGBA()
+	r2, g2, b2, a2 := c2.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+// IsBold returns true if `tm` is drawn with a bold font weight: the name of its font denotes a bold
+// weight, e.g. "Helvetica-Bold" or "Arial-Black", or it is both filled and stroked, which is
+// commonly used for emulating bold text.
+func (tm TextMark) IsBold() bool {
+	return fontNameContains(tm.Font, "bold", "black", "heavy", "demi") ||
+		tm.RenderMode&RenderModeStroke != 0 && tm.RenderMode&RenderModeFill != 0
+}
+
+// IsItalic returns true if the name of the font of `tm` denotes an italic or oblique font.
+func (tm TextMark) IsItalic() bool {
+	return fontNameContains(tm.Font, "italic", "oblique")
+}
+
+// fontNameContains returns true if the base font name of `font` contains one of `words`, ignoring
+// case.
+func fontNameContains(font *model.PdfFont, words ...string) bool {
+	if font == nil {
+		return false
+	}
+	name := strings.ToLower(font.BaseFont())
+	for _, word := range words {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
 }
 
 // String returns a string describing `tm`.
@@ -1176,15 +1265,8 @@ func (to *textObject) getFont(name string) (*model.PdfFont, error) {
 		entry := fontEntry{font, to.e.accessCount}
 
 		// Eject a victim if the cache is full.
-		if len(to.e.fontCache) >= maxFontCache {
-			var names []string
-			for name := range to.e.fontCache {
-				names = append(names, name)
-			}
-			sort.Slice(names, func(i, j int) bool {
-				return to.e.fontCache[names[i]].access < to.e.fontCache[names[j]].access
-			})
-			delete(to.e.fontCache, names[0])
+		if len(to.e.fontCache) >= to.e.fontCacheSize {
+			to.e.evictFont()
 		}
 		to.e.fontCache[name] = entry
 	}
@@ -1198,9 +1280,24 @@ type fontEntry struct {
 	access int64          // Last access. Used to determine LRU cache victims.
 }
 
-// maxFontCache is the maximum number of PdfFont's in fontCache.
+// maxFontCache is the default maximum number of PdfFont's in fontCache.
 const maxFontCache = 10
 
+// evictFont removes the least recently used font from the font cache.
+func (e *Extractor) evictFont() {
+	var names []string
+	for name := range e.fontCache {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return e.fontCache[names[i]].access < e.fontCache[names[j]].access
+	})
+	delete(e.fontCache, names[0])
+}
+
 // getFontDirect returns the font named `name` if it exists in the page's resources or an error if
 // it doesn't. Accesses page resources directly (not cached).
 func (to *textObject) getFontDirect(name string) (*model.PdfFont, error) {
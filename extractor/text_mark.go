@@ -30,6 +30,10 @@ type textMark struct {
 	originaBBox        model.PdfRectangle // Bounding box without orientation correction.
 	fillColor          color.Color        // Text fill color.
 	strokeColor        color.Color        // Text stroke color.
+	fontName           string             // Name of the font resource selected by Tf.
+	tfs                float64            // Font size set by Tf.
+	rise               float64            // Text rise.
+	renderMode         RenderMode         // Text rendering mode.
 }
 
 // newTextMark returns a textMark for text `text` rendered with text rendering matrix (TRM) `trm`
@@ -123,6 +127,10 @@ func (to *textObject) newTextMark(text string, trm transform.Matrix, end transfo
 		orient:       orient,
 		fillColor:    fillColor,
 		strokeColor:  strokeColor,
+		fontName:     to.state.tfname,
+		tfs:          to.state.tfs,
+		rise:         to.state.trise,
+		renderMode:   to.state.tmode,
 	}
 	if verboseGeom {
 		common.Log.Info("newTextMark: start=%.2f end=%.2f %s", start, end, tm.String())
@@ -143,13 +151,17 @@ func (tm *textMark) bbox() model.PdfRectangle {
 // ToTextMark returns the public view of `tm`.
 func (tm *textMark) ToTextMark() TextMark {
 	return TextMark{
-		Text:        tm.text,
-		Original:    tm.original,
-		BBox:        tm.originaBBox,
-		Font:        tm.font,
-		FontSize:    tm.fontsize,
-		FillColor:   tm.fillColor,
-		StrokeColor: tm.strokeColor,
+		Text:         tm.text,
+		Original:     tm.original,
+		BBox:         tm.originaBBox,
+		Font:         tm.font,
+		FontSize:     tm.fontsize,
+		FillColor:    tm.fillColor,
+		StrokeColor:  tm.strokeColor,
+		FontName:     tm.fontName,
+		TextFontSize: tm.tfs,
+		Rise:         tm.rise,
+		RenderMode:   tm.renderMode,
 	}
 }
 
@@ -0,0 +1,94 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestTextMarkState(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	for name, stdName := range map[string]model.StdFontName{
+		"F1": model.HelveticaName,
+		"F2": model.HelveticaBoldName,
+	} {
+		font, err := model.NewStandard14Font(stdName)
+		require.NoError(t, err)
+		require.NoError(t, page.Resources.SetFontByName(core.PdfObjectName(name), font.ToPdfObject()))
+	}
+
+	// Red bold title, body text with a stroked superscript.
+	contents := `BT /F2 18 Tf 1 0 0 rg 100 700 Td (Title) Tj ET
+BT /F1 10 Tf 0 g 2 0 0 2 100 600 Tm (Body) Tj 2 Tr 3 Ts (2) Tj ET`
+	require.NoError(t, page.SetContentStreams([]string{contents}, core.NewRawEncoder()))
+
+	e, err := New(page)
+	require.NoError(t, err)
+	pageText, _, _, err := e.ExtractPageText()
+	require.NoError(t, err)
+
+	runs := pageText.Marks().Runs()
+	require.Len(t, runs, 3)
+	var texts []string
+	for _, run := range runs {
+		var text string
+		for _, tm := range run.Elements() {
+			if !tm.Meta {
+				text += tm.Text
+			}
+		}
+		texts = append(texts, text)
+	}
+	require.Equal(t, []string{"Title", "Body", "2"}, texts)
+
+	title := runs[0].Elements()[0]
+	require.Equal(t, "F2", title.FontName)
+	require.Equal(t, 18.0, title.TextFontSize)
+	require.Equal(t, 0.0, title.Rise)
+	require.Equal(t, RenderModeFill, title.RenderMode)
+	r, g, b, _ := title.FillColor.RGBA()
+	require.Equal(t, []uint32{0xffff, 0, 0}, []uint32{r, g, b})
+
+	body := runs[1].Elements()[0]
+	require.Equal(t, "F1", body.FontName)
+	require.Equal(t, 10.0, body.TextFontSize)
+	require.InDelta(t, 20.0, body.FontSize, 1e-6)
+	require.Equal(t, RenderModeFill, body.RenderMode)
+	require.True(t, sameColor(color.Black, body.FillColor))
+
+	sup := runs[2].Elements()[0]
+	require.Equal(t, "F1", sup.FontName)
+	require.Equal(t, 3.0, sup.Rise)
+	require.Equal(t, RenderModeFill|RenderModeStroke, sup.RenderMode)
+}
+
+func TestTextMarkBoldItalic(t *testing.T) {
+	font := func(name model.StdFontName) *model.PdfFont {
+		f, err := model.NewStandard14Font(name)
+		require.NoError(t, err)
+		return f
+	}
+	regular := TextMark{Font: font(model.HelveticaName), RenderMode: RenderModeFill}
+	require.False(t, regular.IsBold())
+	require.False(t, regular.IsItalic())
+
+	bold := TextMark{Font: font(model.HelveticaBoldObliqueName), RenderMode: RenderModeFill}
+	require.True(t, bold.IsBold())
+	require.True(t, bold.IsItalic())
+
+	// Filled and stroked text is emulated bold text.
+	stroked := TextMark{Font: font(model.TimesItalicName), RenderMode: RenderModeFill | RenderModeStroke}
+	require.True(t, stroked.IsBold())
+	require.True(t, stroked.IsItalic())
+	require.False(t, TextMark{}.IsBold())
+}
@@ -113,6 +113,17 @@ func TestTextExtractionFragments(t *testing.T) {
         `,
 			text: "Hello World!\nDoink",
 		},
+		{
+			name: "invalid graphics state operands",
+			contents: `
+        1 2 d w (x) gs
+        BT
+        /UniDocHelvetica 24 Tf
+        (Hello World!)Tj
+        ET
+        `,
+			text: "Hello World!",
+		},
 	}
 
 	// Setup mock resources.
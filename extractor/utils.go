@@ -29,6 +29,31 @@ const (
 	RenderModeClip                          // Clip
 )
 
+// renderModeFromTr returns the rendering mode of the operand `mode` of the Tr operator (see Table
+// 106 - p. 254). Invalid modes are treated as the default fill mode.
+func renderModeFromTr(mode int) RenderMode {
+	switch mode {
+	case 0:
+		return RenderModeFill
+	case 1:
+		return RenderModeStroke
+	case 2:
+		return RenderModeFill | RenderModeStroke
+	case 3:
+		return 0
+	case 4:
+		return RenderModeFill | RenderModeClip
+	case 5:
+		return RenderModeStroke | RenderModeClip
+	case 6:
+		return RenderModeFill | RenderModeStroke | RenderModeClip
+	case 7:
+		return RenderModeClip
+	}
+	common.Log.Debug("ERROR: invalid text rendering mode %d", mode)
+	return RenderModeFill
+}
+
 // toFloatXY returns `objs` as 2 floats, if that's what `objs` is, or an error if it isn't.
 func toFloatXY(objs []core.PdfObject) (x, y float64, err error) {
 	if len(objs) != 2 {
@@ -0,0 +1,404 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"errors"
+	"math"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// maxVectorFormDepth is the maximum nesting depth of the Form XObjects processed by vector
+// extraction.
+const maxVectorFormDepth = 32
+
+// VectorExtractOptions contains options for controlling vector graphics extraction from PDF pages.
+type VectorExtractOptions struct {
+	// IncludeClippingPaths includes the paths which are only used as clipping paths, without being
+	// painted.
+	IncludeClippingPaths bool
+}
+
+// PageVectors represents the vector graphics drawn on a PDF page.
+type PageVectors struct {
+	Paths []PathMark
+}
+
+// PathMark represents a path painted on a page. All coordinates are in device coordinates, i.e. in
+// the default user space of the page.
+type PathMark struct {
+	Subpaths []Subpath
+
+	// Stroke and Fill are true if the path is stroked and filled. Both are false for clipping paths
+	// which are not painted.
+	Stroke bool
+	Fill   bool
+
+	// EvenOdd is true if the path is filled using the even-odd rule instead of the nonzero winding
+	// number rule.
+	EvenOdd bool
+
+	// Clip is true if the path is used as a clipping path.
+	Clip bool
+
+	// Colors and colorspaces used for stroking and filling the path.
+	StrokeColor      model.PdfColor
+	StrokeColorspace model.PdfColorspace
+	FillColor        model.PdfColor
+	FillColorspace   model.PdfColorspace
+
+	// LineWidth is the line width in device coordinates. The line width is scaled by the CTM, using
+	// the geometric mean of its scaling factors.
+	LineWidth float64
+
+	// BBox is the bounding box of the points of the path, including the curve control points.
+	BBox model.PdfRectangle
+}
+
+// Subpath represents a sequence of connected segments of a path. The segments of a closed
+// subpath end with a line segment to the start point of the subpath.
+type Subpath struct {
+	Segments []PathSegment
+	Closed   bool
+}
+
+// PathSegmentType represents the type of a path segment.
+type PathSegmentType int
+
+// Path segment types.
+const (
+	LineSegment  PathSegmentType = iota // Straight line: the start and end points.
+	CurveSegment                        // Cubic Bézier curve: the start, two control, and end points.
+)
+
+// PathSegment represents a segment of a subpath.
+type PathSegment struct {
+	Type   PathSegmentType
+	Points []PathPoint
+}
+
+// PathPoint represents a point of a path segment.
+type PathPoint struct {
+	X float64
+	Y float64
+}
+
+// Start returns the start point of the segment.
+func (s PathSegment) Start() PathPoint {
+	return s.Points[0]
+}
+
+// End returns the end point of the segment.
+func (s PathSegment) End() PathPoint {
+	return s.Points[len(s.Points)-1]
+}
+
+// ExtractPageVectors returns the paths painted on the page, with their geometry in device
+// coordinates, their colors and line widths. The paths painted by the Form XObjects drawn on the
+// page are included. The options parameter can be nil for the default options. By default,
+// clipping paths which are not painted are not extracted.
+func (e *Extractor) ExtractPageVectors(options *VectorExtractOptions) (*PageVectors, error) {
+	if options == nil {
+		options = &VectorExtractOptions{}
+	}
+	ctx := &vectorExtractContext{
+		options: options,
+	}
+
+	err := ctx.extractContentStreamVectors(e.contents, e.resources, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PageVectors{
+		Paths: ctx.paths,
+	}, nil
+}
+
+// vectorExtractContext provides context for vector extraction content stream processing.
+type vectorExtractContext struct {
+	paths   []PathMark
+	options *VectorExtractOptions
+}
+
+// vectorPathBuilder tracks the path under construction in a content stream.
+type vectorPathBuilder struct {
+	subpaths []Subpath
+
+	// current is the current point and start the start point of the current subpath.
+	current PathPoint
+	start   PathPoint
+
+	clip        bool
+	clipEvenOdd bool
+}
+
+// extractContentStreamVectors extracts the paths painted by `contents`, processed starting with the
+// graphics state `gs` (the default graphics state if nil).
+func (ctx *vectorExtractContext) extractContentStreamVectors(contents string, resources *model.PdfPageResources,
+	gs *contentstream.GraphicsState, depth int) error {
+	operations, err := contentstream.NewContentStreamParser(contents).Parse()
+	if err != nil {
+		return err
+	}
+
+	var builder vectorPathBuilder
+	processor := contentstream.NewContentStreamProcessor(*operations)
+	if gs != nil {
+		processor.SetInitialGraphicsState(*gs)
+	}
+	processor.AddHandler(contentstream.HandlerConditionEnumAllOperands, "",
+		func(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+			if op.Operand == "Do" {
+				return ctx.extractFormVectors(op, gs, resources, depth)
+			}
+			return ctx.processPathOperation(&builder, op, gs)
+		})
+
+	return processor.Process(resources)
+}
+
+// processPathOperation processes the path construction and painting operations.
+func (ctx *vectorExtractContext) processPathOperation(builder *vectorPathBuilder,
+	op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState) error {
+	point := func(x, y float64) PathPoint {
+		m := gs.CTM
+		return PathPoint{X: m[0]*x + m[3]*y + m[6], Y: m[1]*x + m[4]*y + m[7]}
+	}
+
+	switch op.Operand {
+	case "m":
+		vals, err := vectorOperationParams(op, 2)
+		if err != nil {
+			return err
+		}
+		builder.moveTo(point(vals[0], vals[1]))
+	case "l":
+		vals, err := vectorOperationParams(op, 2)
+		if err != nil {
+			return err
+		}
+		builder.addSegment(LineSegment, point(vals[0], vals[1]))
+	case "c":
+		vals, err := vectorOperationParams(op, 6)
+		if err != nil {
+			return err
+		}
+		builder.addSegment(CurveSegment, point(vals[0], vals[1]), point(vals[2], vals[3]),
+			point(vals[4], vals[5]))
+	case "v":
+		vals, err := vectorOperationParams(op, 4)
+		if err != nil {
+			return err
+		}
+		builder.addSegment(CurveSegment, builder.current, point(vals[0], vals[1]), point(vals[2], vals[3]))
+	case "y":
+		vals, err := vectorOperationParams(op, 4)
+		if err != nil {
+			return err
+		}
+		end := point(vals[2], vals[3])
+		builder.addSegment(CurveSegment, point(vals[0], vals[1]), end, end)
+	case "h":
+		builder.closeSubpath()
+	case "re":
+		vals, err := vectorOperationParams(op, 4)
+		if err != nil {
+			return err
+		}
+		x, y, w, h := vals[0], vals[1], vals[2], vals[3]
+		builder.moveTo(point(x, y))
+		builder.addSegment(LineSegment, point(x+w, y))
+		builder.addSegment(LineSegment, point(x+w, y+h))
+		builder.addSegment(LineSegment, point(x, y+h))
+		builder.closeSubpath()
+	case "W":
+		builder.clip = true
+	case "W*":
+		builder.clip = true
+		builder.clipEvenOdd = true
+	case "S", "s", "f", "F", "f*", "B", "B*", "b", "b*", "n":
+		if op.Operand == "s" || op.Operand == "b" || op.Operand == "b*" {
+			builder.closeSubpath()
+		}
+		path := PathMark{
+			Subpaths: builder.paintedSubpaths(),
+			Clip:     builder.clip,
+		}
+		switch op.Operand {
+		case "S", "s":
+			path.Stroke = true
+		case "f", "F":
+			path.Fill = true
+		case "f*":
+			path.Fill = true
+			path.EvenOdd = true
+		case "B", "b":
+			path.Stroke = true
+			path.Fill = true
+		case "B*", "b*":
+			path.Stroke = true
+			path.Fill = true
+			path.EvenOdd = true
+		case "n":
+			path.EvenOdd = builder.clipEvenOdd
+		}
+		*builder = vectorPathBuilder{}
+
+		if len(path.Subpaths) == 0 || (!path.Stroke && !path.Fill && !(path.Clip && ctx.options.IncludeClippingPaths)) {
+			return nil
+		}
+		if path.Stroke {
+			path.StrokeColor = gs.ColorStroking
+			path.StrokeColorspace = gs.ColorspaceStroking
+			m := gs.CTM
+			path.LineWidth = gs.LineWidth * math.Sqrt(math.Abs(m[0]*m[4]-m[1]*m[3]))
+		}
+		if path.Fill {
+			path.FillColor = gs.ColorNonStroking
+			path.FillColorspace = gs.ColorspaceNonStroking
+		}
+		path.BBox = pathBBox(path.Subpaths)
+		ctx.paths = append(ctx.paths, path)
+	}
+	return nil
+}
+
+// extractFormVectors extracts the paths painted by the Form XObject painted by the Do operation
+// `op`, in the graphics state `gs`.
+func (ctx *vectorExtractContext) extractFormVectors(op *contentstream.ContentStreamOperation,
+	gs contentstream.GraphicsState, resources *model.PdfPageResources, depth int) error {
+	if len(op.Params) != 1 {
+		return errors.New("invalid number of parameters")
+	}
+	name, ok := core.GetName(op.Params[0])
+	if !ok {
+		common.Log.Debug("ERROR: Type")
+		return errTypeCheck
+	}
+	if resources == nil {
+		return nil
+	}
+	if _, xtype := resources.GetXObjectByName(*name); xtype != model.XObjectTypeForm {
+		return nil
+	}
+	if depth >= maxVectorFormDepth {
+		common.Log.Debug("ERROR: Form XObject nesting too deep. Skipping %s", *name)
+		return nil
+	}
+
+	xform, err := resources.GetXObjectFormByName(*name)
+	if err != nil {
+		return err
+	}
+	if xform == nil {
+		return nil
+	}
+	formContent, err := xform.GetContentStream()
+	if err != nil {
+		return err
+	}
+
+	// The form matrix maps the form space to the user space in effect when it is painted.
+	if arr, ok := core.GetArray(xform.Matrix); ok {
+		vals, err := arr.ToFloat64Array()
+		if err != nil || len(vals) != 6 {
+			common.Log.Debug("ERROR: invalid form matrix: %v", xform.Matrix)
+			return nil
+		}
+		gs.CTM = gs.CTM.Mult(transform.NewMatrix(vals[0], vals[1], vals[2], vals[3], vals[4], vals[5]))
+	}
+
+	formResources := xform.Resources
+	if formResources == nil {
+		formResources = resources
+	}
+	return ctx.extractContentStreamVectors(string(formContent), formResources, &gs, depth+1)
+}
+
+// moveTo starts a new subpath at `p`.
+func (b *vectorPathBuilder) moveTo(p PathPoint) {
+	b.subpaths = append(b.subpaths, Subpath{})
+	b.current = p
+	b.start = p
+}
+
+// addSegment adds a segment from the current point to the points `points` to the current subpath.
+func (b *vectorPathBuilder) addSegment(segType PathSegmentType, points ...PathPoint) {
+	if len(b.subpaths) == 0 {
+		common.Log.Debug("WARN: path segment without current point. Skipping.")
+		return
+	}
+	subpath := &b.subpaths[len(b.subpaths)-1]
+	if subpath.Closed {
+		// A segment following a closed subpath starts a new subpath at the same point.
+		b.subpaths = append(b.subpaths, Subpath{})
+		subpath = &b.subpaths[len(b.subpaths)-1]
+	}
+	segment := PathSegment{Type: segType, Points: append([]PathPoint{b.current}, points...)}
+	subpath.Segments = append(subpath.Segments, segment)
+	b.current = points[len(points)-1]
+}
+
+// closeSubpath closes the current subpath, adding a line segment to its start point if needed.
+func (b *vectorPathBuilder) closeSubpath() {
+	if len(b.subpaths) == 0 {
+		return
+	}
+	subpath := &b.subpaths[len(b.subpaths)-1]
+	if subpath.Closed {
+		return
+	}
+	if b.current != b.start {
+		subpath.Segments = append(subpath.Segments, PathSegment{
+			Type:   LineSegment,
+			Points: []PathPoint{b.current, b.start},
+		})
+	}
+	subpath.Closed = true
+	b.current = b.start
+}
+
+// paintedSubpaths returns the subpaths of the path which contain segments.
+func (b *vectorPathBuilder) paintedSubpaths() []Subpath {
+	var subpaths []Subpath
+	for _, subpath := range b.subpaths {
+		if len(subpath.Segments) > 0 {
+			subpaths = append(subpaths, subpath)
+		}
+	}
+	return subpaths
+}
+
+// pathBBox returns the bounding box of the points of `subpaths`.
+func pathBBox(subpaths []Subpath) model.PdfRectangle {
+	bbox := model.PdfRectangle{Llx: math.Inf(1), Lly: math.Inf(1), Urx: math.Inf(-1), Ury: math.Inf(-1)}
+	for _, subpath := range subpaths {
+		for _, segment := range subpath.Segments {
+			for _, p := range segment.Points {
+				bbox.Llx = math.Min(bbox.Llx, p.X)
+				bbox.Lly = math.Min(bbox.Lly, p.Y)
+				bbox.Urx = math.Max(bbox.Urx, p.X)
+				bbox.Ury = math.Max(bbox.Ury, p.Y)
+			}
+		}
+	}
+	return bbox
+}
+
+// vectorOperationParams returns the `n` numeric parameters of the operation `op`.
+func vectorOperationParams(op *contentstream.ContentStreamOperation, n int) ([]float64, error) {
+	if len(op.Params) != n {
+		common.Log.Debug("ERROR: Invalid number of parameters for %s: %d", op.Operand, len(op.Params))
+		return nil, errors.New("invalid number of parameters")
+	}
+	return core.GetNumbersAsFloat(op.Params)
+}
@@ -0,0 +1,87 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package extractor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestVectorExtraction(t *testing.T) {
+	form := model.NewXObjectForm()
+	form.Matrix = core.MakeArrayFromFloats([]float64{1, 0, 0, 1, 100, 100})
+	require.NoError(t, form.SetContentStream([]byte("0 0 m 10 0 l S"), nil))
+
+	resources := model.NewPdfPageResources()
+	require.NoError(t, resources.SetXObjectFormByName("Fm1", form))
+	gstate := core.MakeDict()
+	gstate.Set("LW", core.MakeFloat(4))
+	require.NoError(t, resources.AddExtGState("GS1", gstate))
+
+	contents := `2 w 1 0 0 rg 0 0 1 RG 10 10 100 50 re B
+q 2 0 0 2 0 0 cm 0 0 m 10 0 l 10 10 5 15 0 10 c S Q
+0 0 m 5 5 l W n
+q /GS1 gs 0 0 m 0 20 l 20 20 l f* /Fm1 Do Q`
+	e, err := NewFromContents(contents, resources)
+	require.NoError(t, err)
+
+	vectors, err := e.ExtractPageVectors(nil)
+	require.NoError(t, err)
+	paths := vectors.Paths
+	require.Len(t, paths, 4)
+
+	// Rectangle.
+	rect := paths[0]
+	require.True(t, rect.Stroke)
+	require.True(t, rect.Fill)
+	require.False(t, rect.EvenOdd)
+	require.Len(t, rect.Subpaths, 1)
+	require.True(t, rect.Subpaths[0].Closed)
+	require.Len(t, rect.Subpaths[0].Segments, 4)
+	require.Equal(t, PathPoint{X: 10, Y: 60}, rect.Subpaths[0].Segments[3].Start())
+	require.Equal(t, PathPoint{X: 10, Y: 10}, rect.Subpaths[0].Segments[3].End())
+	require.Equal(t, model.PdfRectangle{Llx: 10, Lly: 10, Urx: 110, Ury: 60}, rect.BBox)
+	require.Equal(t, 2.0, rect.LineWidth)
+	require.Equal(t, model.NewPdfColorDeviceRGB(1, 0, 0), rect.FillColor)
+	require.Equal(t, model.NewPdfColorDeviceRGB(0, 0, 1), rect.StrokeColor)
+
+	// Scaled line and curve.
+	curve := paths[1]
+	require.True(t, curve.Stroke)
+	require.False(t, curve.Fill)
+	require.Nil(t, curve.FillColor)
+	segments := curve.Subpaths[0].Segments
+	require.Len(t, segments, 2)
+	require.Equal(t, LineSegment, segments[0].Type)
+	require.Equal(t, CurveSegment, segments[1].Type)
+	require.Equal(t, []PathPoint{{20, 0}, {20, 20}, {10, 30}, {0, 20}}, segments[1].Points)
+	require.Equal(t, 4.0, curve.LineWidth)
+
+	// Even-odd fill with an extended graphics state line width.
+	fill := paths[2]
+	require.True(t, fill.Fill)
+	require.True(t, fill.EvenOdd)
+	require.False(t, fill.Stroke)
+	require.Equal(t, model.PdfRectangle{Urx: 20, Ury: 20}, fill.BBox)
+
+	// Form XObject path, in the graphics state of the Do operator.
+	formLine := paths[3]
+	require.Equal(t, []PathPoint{{100, 100}, {110, 100}}, formLine.Subpaths[0].Segments[0].Points)
+	require.Equal(t, 4.0, formLine.LineWidth)
+	require.Equal(t, model.NewPdfColorDeviceRGB(0, 0, 1), formLine.StrokeColor)
+
+	// Clipping paths.
+	vectors, err = e.ExtractPageVectors(&VectorExtractOptions{IncludeClippingPaths: true})
+	require.NoError(t, err)
+	require.Len(t, vectors.Paths, 5)
+	clip := vectors.Paths[2]
+	require.True(t, clip.Clip)
+	require.False(t, clip.Stroke || clip.Fill)
+}
@@ -132,7 +132,13 @@ func NewToUnicodeCMap(codeToRune map[CharCode]rune) *CMap {
 	for code, r := range codeToRune {
 		codeToUnicode[code] = string(r)
 	}
+	return NewToUnicodeCMapFromStrings(codeToUnicode)
+}
 
+// NewToUnicodeCMapFromStrings returns an identity CMap with codeToUnicode
+// matching the `codeToUnicode` arg. Unlike NewToUnicodeCMap, codes can be
+// mapped to several runes, e.g. for ligatures.
+func NewToUnicodeCMapFromStrings(codeToUnicode map[CharCode]string) *CMap {
 	cmap := &CMap{
 		name:  "Adobe-Identity-UCS",
 		ctype: 2,
@@ -143,10 +149,13 @@ func NewToUnicodeCMap(codeToRune map[CharCode]rune) *CMap {
 			Supplement: 0,
 		},
 		codespaces:    []Codespace{{Low: 0, High: 0xffff}},
-		codeToUnicode: codeToUnicode,
-		unicodeToCode: make(map[string]CharCode, len(codeToRune)),
-		codeToCID:     make(map[CharCode]CharCode, len(codeToRune)),
-		cidToCode:     make(map[CharCode]CharCode, len(codeToRune)),
+		codeToUnicode: make(map[CharCode]string, len(codeToUnicode)),
+		unicodeToCode: make(map[string]CharCode, len(codeToUnicode)),
+		codeToCID:     make(map[CharCode]CharCode, len(codeToUnicode)),
+		cidToCode:     make(map[CharCode]CharCode, len(codeToUnicode)),
+	}
+	for code, s := range codeToUnicode {
+		cmap.codeToUnicode[code] = s
 	}
 
 	cmap.computeInverseMappings()
@@ -327,6 +336,16 @@ func (cmap *CMap) CharcodeToUnicode(code CharCode) (string, bool) {
 	return MissingCodeString, false
 }
 
+// CodeToUnicode returns a copy of the mappings of the character codes to
+// unicode strings of the ToUnicode `cmap`.
+func (cmap *CMap) CodeToUnicode() map[CharCode]string {
+	codeToUnicode := make(map[CharCode]string, len(cmap.codeToUnicode))
+	for code, s := range cmap.codeToUnicode {
+		codeToUnicode[code] = s
+	}
+	return codeToUnicode
+}
+
 // StringToCID maps the specified string to a character identifier. If the provided
 // string has no available mapping, the bool return value is false.
 func (cmap *CMap) StringToCID(s string) (CharCode, bool) {
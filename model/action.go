@@ -850,6 +850,13 @@ func tryLoadFilespec(obj core.PdfObject) (*PdfFilespec, error) {
 		return nil, nil
 	}
 
+	// Simple file specification, consisting of the file name only.
+	if str, ok := core.GetString(obj); ok {
+		fs := NewPdfFilespec()
+		fs.F = str
+		return fs, nil
+	}
+
 	return NewPdfFilespecFromObj(obj)
 }
 
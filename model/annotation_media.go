@@ -0,0 +1,236 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// annotationKeys contains the keys of the entries common to all annotation
+// dictionaries, which are modeled by PdfAnnotation.
+var annotationKeys = []core.PdfObjectName{
+	"Type", "Subtype", "Rect", "Contents", "P", "NM", "M", "F", "AP", "AS",
+	"Border", "C", "StructParent", "OC", "AF",
+}
+
+// markupAnnotationKeys contains the keys of the entries of markup annotation
+// dictionaries, which are modeled by PdfAnnotationMarkup.
+var markupAnnotationKeys = []core.PdfObjectName{
+	"T", "Popup", "CA", "RC", "CreationDate", "IRT", "Subj", "RT", "IT", "ExData",
+}
+
+// unmodeledEntries returns the entries of the annotation dictionary `d`
+// which are neither common annotation entries, markup annotation entries if
+// `markup` is true, nor in `keys`, or nil if all the entries are modeled.
+// The media annotations keep these entries, e.g. the entries of newer
+// versions of the specification, so that they survive read-modify-write
+// cycles.
+func unmodeledEntries(d *core.PdfObjectDictionary, markup bool, keys ...core.PdfObjectName) *core.PdfObjectDictionary {
+	known := map[core.PdfObjectName]struct{}{}
+	for _, key := range annotationKeys {
+		known[key] = struct{}{}
+	}
+	if markup {
+		for _, key := range markupAnnotationKeys {
+			known[key] = struct{}{}
+		}
+	}
+	for _, key := range keys {
+		known[key] = struct{}{}
+	}
+
+	var unmodeled *core.PdfObjectDictionary
+	for _, key := range d.Keys() {
+		if _, ok := known[key]; ok {
+			continue
+		}
+		if unmodeled == nil {
+			unmodeled = core.MakeDict()
+		}
+		unmodeled.Set(key, d.Get(key))
+	}
+	return unmodeled
+}
+
+// setUnmodeledEntries sets the entries of `unmodeled` which are not set in
+// `d`.
+func setUnmodeledEntries(d, unmodeled *core.PdfObjectDictionary) {
+	if unmodeled == nil {
+		return
+	}
+	for _, key := range unmodeled.Keys() {
+		if d.Get(key) == nil {
+			d.Set(key, unmodeled.Get(key))
+		}
+	}
+}
+
+// GetAssociatedFiles returns the files associated with the annotation (AF
+// entry, PDF 2.0).
+func (a *PdfAnnotation) GetAssociatedFiles() ([]*PdfFilespec, error) {
+	arr, ok := core.GetArray(a.AF)
+	if !ok {
+		return nil, nil
+	}
+
+	var files []*PdfFilespec
+	for _, obj := range arr.Elements() {
+		fs, err := NewPdfFilespecFromObj(core.ResolveReference(obj))
+		if err != nil {
+			common.Log.Debug("ERROR: invalid associated file: %v", err)
+			continue
+		}
+		files = append(files, fs)
+	}
+	return files, nil
+}
+
+// AddAssociatedFile associates the file specified by `fs` with the
+// annotation, adding it to the AF entry of the annotation (PDF 2.0).
+func (a *PdfAnnotation) AddAssociatedFile(fs *PdfFilespec) error {
+	if fs == nil {
+		return errors.New("associated file specification cannot be nil")
+	}
+
+	arr, ok := core.GetArray(a.AF)
+	if !ok {
+		arr = core.MakeArray()
+		a.AF = arr
+	}
+	arr.Append(fs.ToPdfObject())
+	return nil
+}
+
+// GetAssets returns the files embedded in the rich media annotation, listed by
+// the Assets name tree of its RichMediaContent dictionary, keyed by asset
+// name (Adobe Supplement to ISO 32000 BaseVersion 1.7 ExtensionLevel 3).
+func (rm *PdfAnnotationRichMedia) GetAssets() ([]*EmbeddedFileEntry, error) {
+	content, ok := core.GetDict(rm.RichMediaContent)
+	if !ok {
+		return nil, nil
+	}
+
+	var assets []*EmbeddedFileEntry
+	var lastErr error
+	nameTreeEntries(content.Get("Assets"), 0, func(key string, obj core.PdfObject) {
+		fs, err := NewPdfFilespecFromObj(core.ResolveReference(obj))
+		if err != nil {
+			lastErr = err
+			return
+		}
+		assets = append(assets, &EmbeddedFileEntry{Key: key, Filespec: fs})
+	})
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return assets, nil
+}
+
+// Pdf3DStream represents the 3D artwork of a 3D annotation (section 13.6.3
+// "3D Streams" p. 510 PDF32000_2008).
+type Pdf3DStream struct {
+	// Subtype is the format of the artwork: "U3D" or "PRC".
+	Subtype string
+
+	// Data is the decoded artwork data.
+	Data []byte
+
+	// Stream is the 3D stream.
+	Stream *core.PdfObjectStream
+}
+
+// Get3DStream returns the 3D artwork of the annotation, specified by its 3DD
+// entry, which is a 3D stream or a 3D reference dictionary referring to a 3D
+// stream.
+func (a3d *PdfAnnotation3D) Get3DStream() (*Pdf3DStream, error) {
+	obj := core.ResolveReference(a3d.T3DD)
+	if ref, ok := core.GetDict(obj); ok {
+		// 3D reference dictionary.
+		obj = core.ResolveReference(ref.Get("3DD"))
+	}
+	stream, ok := core.GetStream(obj)
+	if !ok {
+		return nil, errors.New("3D stream not found")
+	}
+
+	data, err := core.DecodeStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	subtype, _ := core.GetNameVal(stream.Get("Subtype"))
+	return &Pdf3DStream{Subtype: subtype, Data: data, Stream: stream}, nil
+}
+
+// PdfSound represents a sound object, containing sampled sound data (section
+// 13.3 "Sounds" p. 486 PDF32000_2008).
+type PdfSound struct {
+	// Rate is the sampling rate, in samples per second.
+	Rate float64
+
+	// Channels is the number of sound channels.
+	Channels int
+
+	// BitsPerSample is the number of bits per sample value per channel.
+	BitsPerSample int
+
+	// Encoding is the encoding format of the samples: "Raw", "Signed",
+	// "muLaw" or "ALaw".
+	Encoding string
+
+	// Data is the decoded sample data.
+	Data []byte
+}
+
+// GetSound returns the sound played by the sound annotation.
+func (snd *PdfAnnotationSound) GetSound() (*PdfSound, error) {
+	stream, ok := core.GetStream(snd.Sound)
+	if !ok {
+		return nil, errors.New("sound stream not found")
+	}
+
+	data, err := core.DecodeStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	sound := &PdfSound{Channels: 1, BitsPerSample: 8, Encoding: "Raw", Data: data}
+	rate, err := core.GetNumberAsFloat(core.TraceToDirectObject(stream.Get("R")))
+	if err != nil {
+		return nil, errors.New("sound sampling rate missing")
+	}
+	sound.Rate = rate
+	if channels, ok := core.GetIntVal(stream.Get("C")); ok {
+		sound.Channels = channels
+	}
+	if bits, ok := core.GetIntVal(stream.Get("B")); ok {
+		sound.BitsPerSample = bits
+	}
+	if encoding, ok := core.GetNameVal(stream.Get("E")); ok {
+		sound.Encoding = encoding
+	}
+	return sound, nil
+}
+
+// GetMovieFile returns the file specification of the movie played by the
+// movie annotation (F entry of the movie dictionary). The movie file is
+// embedded if the file specification has an embedded file, see
+// PdfFilespec.GetEmbeddedFile.
+func (mov *PdfAnnotationMovie) GetMovieFile() (*PdfFilespec, error) {
+	movie, ok := core.GetDict(mov.Movie)
+	if !ok {
+		return nil, errors.New("movie dictionary not found")
+	}
+
+	obj := core.ResolveReference(movie.Get("F"))
+	if str, ok := core.GetString(obj); ok {
+		fs := NewPdfFilespec()
+		fs.F = str
+		return fs, nil
+	}
+	return NewPdfFilespecFromObj(obj)
+}
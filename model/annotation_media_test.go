@@ -0,0 +1,139 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// makeMediaAnnotations returns the annotation dictionaries of 3D, rich media,
+// sound and movie annotations, with entries not modeled by the annotation
+// types.
+func makeMediaAnnotations(t *testing.T) *core.PdfObjectArray {
+	rect := core.MakeArrayFromFloats([]float64{0, 0, 100, 100})
+	makeAnnot := func(subtype string, entries map[core.PdfObjectName]core.PdfObject) core.PdfObject {
+		d := core.MakeDict()
+		d.Set("Type", core.MakeName("Annot"))
+		d.Set("Subtype", core.MakeName(subtype))
+		d.Set("Rect", rect)
+		for key, val := range entries {
+			d.Set(key, val)
+		}
+		return core.MakeIndirectObject(d)
+	}
+
+	u3d, err := core.MakeStream([]byte("U3D artwork"), core.NewFlateEncoder())
+	require.NoError(t, err)
+	u3d.Set("Type", core.MakeName("3D"))
+	u3d.Set("Subtype", core.MakeName("U3D"))
+
+	asset, err := NewPdfFilespecFromEmbeddedFile(&EmbeddedFile{Name: "model.prc", Content: []byte("PRC model")})
+	require.NoError(t, err)
+	assets := core.MakeDict()
+	assets.Set("Names", core.MakeArray(core.MakeString("model.prc"), asset.ToPdfObject()))
+	content := core.MakeDict()
+	content.Set("Assets", assets)
+
+	sound, err := core.MakeStream([]byte{1, 2, 3, 4}, nil)
+	require.NoError(t, err)
+	sound.Set("Type", core.MakeName("Sound"))
+	sound.Set("R", core.MakeInteger(8000))
+	sound.Set("C", core.MakeInteger(2))
+	sound.Set("E", core.MakeName("Signed"))
+
+	movie := core.MakeDict()
+	movie.Set("F", core.MakeString("clip.mov"))
+
+	return core.MakeArray(
+		makeAnnot("3D", map[core.PdfObjectName]core.PdfObject{"3DD": u3d, "3DU": core.MakeDict()}),
+		makeAnnot("RichMedia", map[core.PdfObjectName]core.PdfObject{"RichMediaContent": content, "Custom": core.MakeName("Kept")}),
+		makeAnnot("Sound", map[core.PdfObjectName]core.PdfObject{"Sound": sound, "T": core.MakeString("Author")}),
+		makeAnnot("Movie", map[core.PdfObjectName]core.PdfObject{"Movie": movie}),
+	)
+}
+
+func TestMediaAnnotations(t *testing.T) {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+	page.Annots = makeMediaAnnotations(t)
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	// Read, modify and write the annotations.
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	annots, err := r.PageList[0].GetAnnotations()
+	require.NoError(t, err)
+	require.Len(t, annots, 4)
+	annots[1].Contents = core.MakeString("Rich media")
+	fs, err := NewPdfFilespecFromEmbeddedFile(&EmbeddedFile{Name: "data.csv", Content: []byte("a,b"), Relationship: AFRelationshipData})
+	require.NoError(t, err)
+	require.NoError(t, annots[0].AddAssociatedFile(fs))
+
+	w = NewPdfWriter()
+	require.NoError(t, w.AddPage(r.PageList[0]))
+	buf.Reset()
+	require.NoError(t, w.Write(&buf))
+	r, err = NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	annots, err = r.PageList[0].GetAnnotations()
+	require.NoError(t, err)
+	require.Len(t, annots, 4)
+
+	// 3D annotation.
+	a3d, ok := annots[0].GetContext().(*PdfAnnotation3D)
+	require.True(t, ok)
+	artwork, err := a3d.Get3DStream()
+	require.NoError(t, err)
+	require.Equal(t, "U3D", artwork.Subtype)
+	require.Equal(t, []byte("U3D artwork"), artwork.Data)
+	d, ok := core.GetDict(a3d.ToPdfObject())
+	require.True(t, ok)
+	require.NotNil(t, d.Get("3DU"))
+	files, err := annots[0].GetAssociatedFiles()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, "data.csv", files[0].FileName())
+
+	// Rich media annotation.
+	rm, ok := annots[1].GetContext().(*PdfAnnotationRichMedia)
+	require.True(t, ok)
+	require.Equal(t, "Rich media", annots[1].Contents.(*core.PdfObjectString).Decoded())
+	d, ok = core.GetDict(rm.ToPdfObject())
+	require.True(t, ok)
+	custom, _ := core.GetNameVal(d.Get("Custom"))
+	require.Equal(t, "Kept", custom)
+	assets, err := rm.GetAssets()
+	require.NoError(t, err)
+	require.Len(t, assets, 1)
+	require.Equal(t, "model.prc", assets[0].Key)
+	file, err := assets[0].Filespec.GetEmbeddedFile()
+	require.NoError(t, err)
+	require.Equal(t, []byte("PRC model"), file.Content)
+
+	// Sound annotation.
+	snd, ok := annots[2].GetContext().(*PdfAnnotationSound)
+	require.True(t, ok)
+	sound, err := snd.GetSound()
+	require.NoError(t, err)
+	require.Equal(t, &PdfSound{Rate: 8000, Channels: 2, BitsPerSample: 8, Encoding: "Signed", Data: []byte{1, 2, 3, 4}}, sound)
+	require.Nil(t, snd.unmodeled)
+
+	// Movie annotation.
+	mov, ok := annots[3].GetContext().(*PdfAnnotationMovie)
+	require.True(t, ok)
+	movieFile, err := mov.GetMovieFile()
+	require.NoError(t, err)
+	require.Equal(t, "clip.mov", movieFile.FileName())
+}
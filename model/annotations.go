@@ -32,6 +32,7 @@ type PdfAnnotation struct {
 	C            core.PdfObject
 	StructParent core.PdfObject
 	OC           core.PdfObject
+	AF           core.PdfObject // Associated files (PDF 2.0).
 
 	container *core.PdfIndirectObject
 }
@@ -299,6 +300,9 @@ type PdfAnnotationSound struct {
 	*PdfAnnotationMarkup
 	Sound core.PdfObject
 	Name  core.PdfObject
+
+	// unmodeled contains the entries which are passed through unchanged.
+	unmodeled *core.PdfObjectDictionary
 }
 
 // PdfAnnotationRichMedia represents Rich Media annotations.
@@ -306,6 +310,9 @@ type PdfAnnotationRichMedia struct {
 	*PdfAnnotation
 	RichMediaSettings core.PdfObject
 	RichMediaContent  core.PdfObject
+
+	// unmodeled contains the entries which are passed through unchanged.
+	unmodeled *core.PdfObjectDictionary
 }
 
 // PdfAnnotationMovie represents Movie annotations.
@@ -315,6 +322,9 @@ type PdfAnnotationMovie struct {
 	T     core.PdfObject
 	Movie core.PdfObject
 	A     core.PdfObject
+
+	// unmodeled contains the entries which are passed through unchanged.
+	unmodeled *core.PdfObjectDictionary
 }
 
 // PdfAnnotationScreen represents Screen annotations.
@@ -378,6 +388,9 @@ type PdfAnnotation3D struct {
 	T3DA core.PdfObject
 	T3DI core.PdfObject
 	T3DB core.PdfObject
+
+	// unmodeled contains the entries which are passed through unchanged.
+	unmodeled *core.PdfObjectDictionary
 }
 
 // PdfAnnotationProjection represents Projection annotations.
@@ -759,6 +772,10 @@ func (r *PdfReader) newPdfAnnotationFromIndirectObject(container *core.PdfIndire
 		annot.OC = obj
 	}
 
+	if obj := d.Get("AF"); obj != nil {
+		annot.AF = obj
+	}
+
 	subtypeObj := d.Get("Subtype")
 	if subtypeObj == nil {
 		common.Log.Debug("WARNING: Compatibility issue - annotation Subtype missing - assuming no subtype")
@@ -1080,7 +1097,7 @@ func (r *PdfReader) newPdfAnnotationTextFromDict(d *core.PdfObjectDictionary) (*
 }
 
 func (r *PdfReader) newPdfAnnotationLinkFromDict(d *core.PdfObjectDictionary) (*PdfAnnotationLink, error) {
-	annot := PdfAnnotationLink{}
+	annot := PdfAnnotationLink{reader: r}
 
 	annot.A = d.Get("A")
 	annot.Dest = d.Get("Dest")
@@ -1099,8 +1116,11 @@ func (r *PdfReader) loadAction(obj core.PdfObject) (*PdfAction, error) {
 			return nil, err
 		}
 		return actionObj, nil
+	} else if d, isDict := core.GetDict(obj); isDict {
+		// Direct action dictionary.
+		return r.newPdfActionFromIndirectObject(core.MakeIndirectObject(d))
 	} else if !core.IsNullObject(obj) {
-		return nil, errors.New("action should point to an indirect object")
+		return nil, errors.New("action should be a dictionary or point to an indirect object")
 	}
 	return nil, nil
 }
@@ -1361,6 +1381,7 @@ func (r *PdfReader) newPdfAnnotationSoundFromDict(d *core.PdfObjectDictionary) (
 
 	annot.Name = d.Get("Name")
 	annot.Sound = d.Get("Sound")
+	annot.unmodeled = unmodeledEntries(d, true, "Name", "Sound")
 
 	return &annot, nil
 }
@@ -1370,6 +1391,7 @@ func (r *PdfReader) newPdfAnnotationRichMediaFromDict(d *core.PdfObjectDictionar
 
 	annot.RichMediaSettings = d.Get("RichMediaSettings")
 	annot.RichMediaContent = d.Get("RichMediaContent")
+	annot.unmodeled = unmodeledEntries(d, false, "RichMediaSettings", "RichMediaContent")
 
 	return annot, nil
 }
@@ -1380,6 +1402,7 @@ func (r *PdfReader) newPdfAnnotationMovieFromDict(d *core.PdfObjectDictionary) (
 	annot.T = d.Get("T")
 	annot.Movie = d.Get("Movie")
 	annot.A = d.Get("A")
+	annot.unmodeled = unmodeledEntries(d, false, "T", "Movie", "A")
 
 	return &annot, nil
 }
@@ -1442,6 +1465,7 @@ func (r *PdfReader) newPdfAnnotation3DFromDict(d *core.PdfObjectDictionary) (*Pd
 	annot.T3DA = d.Get("3DA")
 	annot.T3DI = d.Get("3DI")
 	annot.T3DB = d.Get("3DB")
+	annot.unmodeled = unmodeledEntries(d, false, "3DD", "3DV", "3DA", "3DI", "3DB")
 
 	return &annot, nil
 }
@@ -1504,6 +1528,7 @@ func (a *PdfAnnotation) ToPdfObject() core.PdfObject {
 	d.SetIfNotNil("C", a.C)
 	d.SetIfNotNil("StructParent", a.StructParent)
 	d.SetIfNotNil("OC", a.OC)
+	d.SetIfNotNil("AF", a.AF)
 
 	return container
 }
@@ -1801,6 +1826,7 @@ func (rm *PdfAnnotationRichMedia) ToPdfObject() core.PdfObject {
 	d.SetIfNotNil("Subtype", core.MakeName("RichMedia"))
 	d.SetIfNotNil("RichMediaSettings", rm.RichMediaSettings)
 	d.SetIfNotNil("RichMediaContent", rm.RichMediaContent)
+	setUnmodeledEntries(d, rm.unmodeled)
 	return container
 }
 
@@ -1814,6 +1840,7 @@ func (snd *PdfAnnotationSound) ToPdfObject() core.PdfObject {
 	d.SetIfNotNil("Subtype", core.MakeName("Sound"))
 	d.SetIfNotNil("Sound", snd.Sound)
 	d.SetIfNotNil("Name", snd.Name)
+	setUnmodeledEntries(d, snd.unmodeled)
 	return container
 }
 
@@ -1827,6 +1854,7 @@ func (mov *PdfAnnotationMovie) ToPdfObject() core.PdfObject {
 	d.SetIfNotNil("T", mov.T)
 	d.SetIfNotNil("Movie", mov.Movie)
 	d.SetIfNotNil("A", mov.A)
+	setUnmodeledEntries(d, mov.unmodeled)
 	return container
 }
 
@@ -1924,6 +1952,7 @@ func (a3d *PdfAnnotation3D) ToPdfObject() core.PdfObject {
 	d.SetIfNotNil("3DA", a3d.T3DA)
 	d.SetIfNotNil("3DI", a3d.T3DI)
 	d.SetIfNotNil("3DB", a3d.T3DB)
+	setUnmodeledEntries(d, a3d.unmodeled)
 	return container
 }
 
@@ -26,6 +26,10 @@ type PdfAppender struct {
 	Reader   *PdfReader
 	pages    []*PdfPage
 	acroForm *PdfAcroForm
+	info     *PdfInfo
+
+	// Catalog entries set in the new revision. Null entries are removed.
+	catalogUpdates *core.PdfObjectDictionary
 
 	xrefs          core.XrefTable
 	xrefOffset     int64
@@ -45,6 +49,10 @@ type PdfAppender struct {
 
 	prevRevisionSize int64
 	written          bool
+
+	// idPolicy is the policy applied to the file identifiers of the
+	// original document.
+	idPolicy DocumentIDPolicy
 }
 
 func getPageResources(p *PdfPage) map[core.PdfObjectName]core.PdfObject {
@@ -520,12 +528,83 @@ func (a *PdfAppender) ReplaceAcroForm(acroForm *PdfAcroForm) {
 	a.acroForm = acroForm
 }
 
+// AddAnnotation adds `annot` to the annotations of the page `pageNum` and
+// marks the page as updated in the new revision.
+func (a *PdfAppender) AddAnnotation(pageNum int, annot *PdfAnnotation) error {
+	if annot == nil {
+		return errors.New("annotation cannot be nil")
+	}
+	pageIndex := pageNum - 1
+	if pageIndex < 0 || pageIndex > len(a.pages)-1 {
+		return fmt.Errorf("page %d not found", pageNum)
+	}
+
+	// Modify the page of the original reader, unless the page was replaced.
+	page := a.pages[pageIndex]
+	if pageIndex < len(a.roReader.PageList) && page == a.roReader.PageList[pageIndex] {
+		page = a.Reader.PageList[pageIndex]
+	}
+	page.AddAnnotation(annot)
+
+	a.UpdatePage(page)
+	a.pages[pageIndex] = page
+	return nil
+}
+
+// pagesChanged returns true if pages were added, removed or replaced.
+func (a *PdfAppender) pagesChanged() bool {
+	if len(a.roReader.PageList) != len(a.pages) {
+		return true
+	}
+	for i := range a.roReader.PageList {
+		switch {
+		case a.pages[i] == a.roReader.PageList[i]:
+			// from ro reader - no change.
+		case a.pages[i] == a.Reader.PageList[i]:
+			// same as original file (possibly some modification of the page itself).
+		default:
+			// Different source.
+			return true
+		}
+	}
+	return false
+}
+
+// SetDocumentIDPolicy sets the policy applied to the file identifiers of the
+// original document in the new revision. Defaults to DocumentIDKeep, so that
+// writing the same changes twice produces the same output, as required for
+// external signatures. DocumentIDRefreshSecond identifies the new revision.
+func (a *PdfAppender) SetDocumentIDPolicy(policy DocumentIDPolicy) {
+	a.idPolicy = policy
+}
+
+// HasChanges returns true if the appender has changes to write in a new
+// revision: updated or new objects, added, removed or replaced pages, a
+// replaced form, document information or catalog entries. Write outputs the original document
+// unchanged if the appender has no changes.
+func (a *PdfAppender) HasChanges() bool {
+	return len(a.newObjects) > 0 || a.info != nil || a.acroForm != a.roReader.AcroForm ||
+		a.catalogUpdates != nil || a.pagesChanged()
+}
+
 // Write writes the Appender output to io.Writer.
 // It can only be called once and further invocations will result in an error.
+// Only the changes are appended to the original document, in a new revision,
+// so that the previous revisions, and their signatures, remain valid.
 func (a *PdfAppender) Write(w io.Writer) error {
 	if a.written {
 		return errors.New("appender write can only be invoked once")
 	}
+	if !a.HasChanges() {
+		if _, err := a.rs.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, a.rs); err != nil {
+			return err
+		}
+		a.written = true
+		return nil
+	}
 
 	writer := NewPdfWriter()
 
@@ -569,31 +648,26 @@ func (a *PdfAppender) Write(w io.Writer) error {
 		writer.catalog.Set("AcroForm", a.acroForm.ToPdfObject())
 		a.updateObjectsDeep(a.acroForm.ToPdfObject(), nil)
 	}
+	if a.catalogUpdates != nil {
+		for _, key := range a.catalogUpdates.Keys() {
+			obj := a.catalogUpdates.Get(key)
+			if core.IsNullObject(obj) {
+				writer.catalog.Remove(key)
+				continue
+			}
+			writer.catalog.Set(key, obj)
+			a.updateObjectsDeep(obj, nil)
+		}
+	}
 
+	if a.info != nil {
+		writer.SetPdfInfo(a.info)
+	}
 	a.addNewObject(writer.infoObj)
 	a.addNewObject(writer.root)
 
 	// TODO: Represent the Pages as a model/object.  PdfPages should represent the Pages dictionary.
-	pagesChanged := false
-	if len(a.roReader.PageList) != len(a.pages) {
-		pagesChanged = true
-	} else {
-		for i := range a.roReader.PageList {
-			switch {
-			case a.pages[i] == a.roReader.PageList[i]:
-				// from ro reader - no change.
-			case a.pages[i] == a.Reader.PageList[i]:
-				// same as original file (possibly some modification of the page itself).
-			default:
-				// Different source.
-				pagesChanged = true
-			}
-			if pagesChanged {
-				break
-			}
-		}
-	}
-	if pagesChanged {
+	if a.pagesChanged() {
 		a.updateObjectsDeep(writer.pages, nil)
 	} else {
 		a.ignoreObjects[writer.pages] = struct{}{}
@@ -706,6 +780,10 @@ func (a *PdfAppender) Write(w io.Writer) error {
 	writer.appendPrevRevisionSize = a.prevRevisionSize
 	writer.minorVersion = a.roReader.PdfVersion().Minor
 	writer.appendReplaceMap = a.replaceObjects
+	if id0, id1, ok := a.roReader.GetDocumentID(); ok {
+		writer.SetDocumentID(id0, id1)
+	}
+	writer.SetDocumentIDPolicy(a.idPolicy)
 
 	xrefType := a.parser.GetXrefType()
 	if xrefType != nil {
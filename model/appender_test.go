@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"crypto"
 	"crypto/rsa"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -19,6 +20,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/unidoc/pkcs7"
 	"golang.org/x/crypto/pkcs12"
 
 	"github.com/unidoc/unipdf/v3/annotator"
@@ -55,7 +57,7 @@ func tempFile(name string) string {
 	return filepath.Join(os.TempDir(), name)
 }
 
-// Appender with no data added should output an equivalent file.
+// Appender with no data added should output the original file.
 func TestAppenderNoop(t *testing.T) {
 	f, err := os.Open("./testdata/minimal.pdf")
 	require.NoError(t, err)
@@ -66,6 +68,7 @@ func TestAppenderNoop(t *testing.T) {
 
 	appender, err := model.NewPdfAppender(reader)
 	require.NoError(t, err)
+	require.False(t, appender.HasChanges())
 
 	model.SetPdfProducer("UniPDF")
 	model.SetPdfCreator("UniDoc UniPDF")
@@ -75,12 +78,12 @@ func TestAppenderNoop(t *testing.T) {
 		return
 	}
 
-	// The first part of the appended file should be same as in the original.
+	// No revision is appended to the original file.
 	minimal, err := ioutil.ReadFile("./testdata/minimal.pdf")
 	require.NoError(t, err)
 	out, err := ioutil.ReadFile(tempFile("appender_noop.pdf"))
 	require.NoError(t, err)
-	require.Equal(t, minimal, out[0:len(minimal)])
+	require.Equal(t, minimal, out)
 
 	origReader := reader
 	origObjNums := origReader.GetObjectNums()
@@ -98,38 +101,49 @@ func TestAppenderNoop(t *testing.T) {
 		4: core.XrefObject{ObjectNumber: 4, XType: 0, Offset: 457},
 	}
 	require.Equal(t, expected, origXref.ObjectMap)
+}
 
-	// Check the output.
-	{
-		f, err := os.Open(tempFile("appender_noop.pdf"))
-		require.NoError(t, err)
-		defer f.Close()
+// Appender with an annotation added through AddAnnotation should append the
+// changed page and the annotation only.
+func TestAppenderAddAnnotationChanges(t *testing.T) {
+	f, err := os.Open(testPdfFile1)
+	require.NoError(t, err)
+	defer f.Close()
 
-		reader, err := model.NewPdfReader(f)
-		require.NoError(t, err)
+	reader, err := model.NewPdfReader(f)
+	require.NoError(t, err)
 
-		objNums := reader.GetObjectNums()
+	appender, err := model.NewPdfAppender(reader)
+	require.NoError(t, err)
 
-		// Number of objects should be equal.
-		// The appended version should only add 2 objects (new Info and Catalog).
-		require.Equal(t, len(origObjNums)+2, len(objNums))
-		require.Equal(t, []int{1, 2, 3, 4, 5, 6}, objNums)
+	annotation := model.NewPdfAnnotationSquare()
+	annotation.Rect = core.MakeArrayFromFloats([]float64{50, 50, 150, 250})
+	require.Error(t, appender.AddAnnotation(2, annotation.PdfAnnotation))
+	require.False(t, appender.HasChanges())
+	require.NoError(t, appender.AddAnnotation(1, annotation.PdfAnnotation))
+	require.True(t, appender.HasChanges())
 
-		// Check cross references table of appended version.
-		page, err := reader.GetPage(1)
-		require.NoError(t, err)
-		parser := page.GetPageAsIndirectObject().GetParser()
-		xrefs := parser.GetXrefTable()
-		expected := map[int]core.XrefObject{
-			1: core.XrefObject{ObjectNumber: 1, XType: 0, Offset: 18},
-			2: core.XrefObject{ObjectNumber: 2, XType: 0, Offset: 77},
-			3: core.XrefObject{ObjectNumber: 3, XType: 0, Offset: 178},
-			4: core.XrefObject{ObjectNumber: 4, XType: 0, Offset: 457},
-			5: core.XrefObject{ObjectNumber: 5, XType: 0, Offset: 740},
-			6: core.XrefObject{ObjectNumber: 6, XType: 0, Offset: 802},
-		}
-		require.Equal(t, expected, xrefs.ObjectMap)
-	}
+	var buf bytes.Buffer
+	require.NoError(t, appender.Write(&buf))
+
+	// The original file is the first revision of the output.
+	minimal, err := ioutil.ReadFile(testPdfFile1)
+	require.NoError(t, err)
+	require.Equal(t, minimal, buf.Bytes()[:len(minimal)])
+
+	out, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	page, err := out.GetPage(1)
+	require.NoError(t, err)
+	annots, err := page.GetAnnotations()
+	require.NoError(t, err)
+	require.Len(t, annots, 1)
+
+	// The page keeps its object number.
+	origPage, err := reader.GetPage(1)
+	require.NoError(t, err)
+	require.Equal(t, origPage.GetPageAsIndirectObject().ObjectNumber,
+		page.GetPageAsIndirectObject().ObjectNumber)
 }
 
 func TestAppenderRemovePage(t *testing.T) {
@@ -1819,3 +1833,194 @@ func TestSignatureAppearanceWithTimestamp(t *testing.T) {
 	}
 	validateFile(t, tempFile("appender-signature-appearance-with-timestamp.pdf"))
 }
+
+func TestAppenderFillForm(t *testing.T) {
+	f, err := os.Open(testPdfAcroFormFile1)
+	require.NoError(t, err)
+	defer f.Close()
+
+	reader, err := model.NewPdfReader(f)
+	require.NoError(t, err)
+
+	appender, err := model.NewPdfAppender(reader)
+	require.NoError(t, err)
+
+	type address struct {
+		City    string `pdf:"City Text Box"`
+		Country string `pdf:"Country Combo Box"`
+	}
+	type person struct {
+		Name    string `pdf:"Given Name Text Box"`
+		Driver  bool   `pdf:"Driving License Check Box"`
+		Lang2   bool   `pdf:"Language 2 Check Box"`
+		Ignored string `pdf:"-"`
+		Address *address
+	}
+	values, err := model.NewFieldValueMapFromStruct(person{
+		Name:    "John",
+		Driver:  true,
+		Address: &address{City: "Paris", Country: "France"},
+	})
+	require.NoError(t, err)
+	require.Len(t, values, 5)
+
+	// Nested struct fields are prefixed. Use partial names for the test form.
+	values["City Text Box"] = values["Address.City Text Box"]
+	values["Country Combo Box"] = values["Address.Country Combo Box"]
+
+	fa := annotator.FieldAppearance{OnlyIfMissing: false, RegenerateTextFields: true}
+	require.NoError(t, appender.FillForm(values, fa))
+
+	var buf bytes.Buffer
+	require.NoError(t, appender.Write(&buf))
+
+	// Check the filled values in the new revision.
+	reader, err = model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	fields, err := reader.GetFormFields()
+	require.NoError(t, err)
+
+	fieldMap := map[string]*model.FormField{}
+	for _, field := range fields {
+		fieldMap[field.Name] = field
+	}
+	require.Equal(t, "John", fieldMap["Given Name Text Box"].Value)
+	require.Equal(t, "Paris", fieldMap["City Text Box"].Value)
+	require.Equal(t, "France", fieldMap["Country Combo Box"].Value)
+	require.True(t, fieldMap["Driving License Check Box"].IsChecked())
+	require.False(t, fieldMap["Language 2 Check Box"].IsChecked())
+
+	// Text fields should have regenerated appearances.
+	for _, w := range fieldMap["Given Name Text Box"].Widgets {
+		apDict, ok := core.GetDict(w.Annotation.AP)
+		require.True(t, ok)
+		require.NotNil(t, apDict.Get("N"))
+	}
+}
+
+func TestAppenderAddFormField(t *testing.T) {
+	f, err := os.Open(testPdfFile1)
+	require.NoError(t, err)
+	defer f.Close()
+	reader, err := model.NewPdfReader(f)
+	require.NoError(t, err)
+	require.Nil(t, reader.AcroForm)
+
+	appender, err := model.NewPdfAppender(reader)
+	require.NoError(t, err)
+	page, err := reader.GetPage(1)
+	require.NoError(t, err)
+
+	textField, err := annotator.NewTextField(page, "name", []float64{100, 700, 300, 720}, annotator.TextFieldOptions{})
+	require.NoError(t, err)
+	require.NoError(t, appender.AddFormField(1, textField.PdfField))
+	checkbox, err := annotator.NewCheckboxField(page, "agree", []float64{100, 650, 115, 665}, annotator.CheckboxFieldOptions{})
+	require.NoError(t, err)
+	require.NoError(t, appender.AddFormField(1, checkbox.PdfField))
+
+	duplicate, err := annotator.NewTextField(page, "name", []float64{100, 600, 300, 620}, annotator.TextFieldOptions{})
+	require.NoError(t, err)
+	require.Error(t, appender.AddFormField(1, duplicate.PdfField))
+	require.Error(t, appender.AddFormField(2, duplicate.PdfField))
+
+	var buf bytes.Buffer
+	require.NoError(t, appender.Write(&buf))
+
+	reader, err = model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.NotNil(t, reader.AcroForm)
+	require.NotNil(t, reader.AcroForm.DR)
+	require.True(t, reader.AcroForm.DR.HasFontByName("Helv"))
+	require.True(t, reader.AcroForm.DR.HasFontByName("ZaDb"))
+	require.Equal(t, "/Helv 0 Tf 0 g", reader.AcroForm.DA.Str())
+
+	fields, err := reader.GetFormFields()
+	require.NoError(t, err)
+	require.Len(t, fields, 2)
+	require.Equal(t, "name", fields[0].Name)
+	require.Equal(t, model.FormFieldTypeText, fields[0].Type)
+	require.Equal(t, []int{1}, fields[0].Pages())
+	require.Equal(t, "agree", fields[1].Name)
+	require.Equal(t, model.FormFieldTypeCheckbox, fields[1].Type)
+	require.Equal(t, []int{1}, fields[1].Pages())
+
+	// Fill the new fields.
+	appender, err = model.NewPdfAppender(reader)
+	require.NoError(t, err)
+	values := model.FieldValueMap{"name": core.MakeString("John Doe"), "agree": core.MakeName("Yes")}
+	require.NoError(t, appender.FillForm(values, annotator.FieldAppearance{}))
+	buf.Reset()
+	require.NoError(t, appender.Write(&buf))
+
+	reader, err = model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	fields, err = reader.GetFormFields()
+	require.NoError(t, err)
+	require.Equal(t, "John Doe", fields[0].Value)
+	require.True(t, fields[1].IsChecked())
+}
+
+func TestAppenderPrepareExternalSignature(t *testing.T) {
+	f, err := os.Open(testPdfFile1)
+	require.NoError(t, err)
+	defer f.Close()
+	reader, err := model.NewPdfReader(f)
+	require.NoError(t, err)
+	appender, err := model.NewPdfAppender(reader)
+	require.NoError(t, err)
+
+	// Prepare the document with an unsigned signature field.
+	signature := model.NewPdfSignature(nil)
+	signature.SetName("Test External Signature")
+	signature.SetReason("TestAppenderPrepareExternalSignature")
+	signature.SetDate(time.Date(2019, 3, 24, 7, 30, 24, 0, time.UTC), "")
+
+	opts := annotator.NewSignatureFieldOpts()
+	opts.Rect = []float64{10, 25, 75, 60}
+	field, err := annotator.NewSignatureField(signature, []*annotator.SignatureLine{
+		annotator.NewSignatureLine("Name", "John Doe"),
+	}, opts)
+	require.NoError(t, err)
+	field.T = core.MakeString("External signature")
+
+	extSig, err := appender.PrepareExternalSignature(1, field, 8192)
+	require.NoError(t, err)
+	require.Equal(t, 8192, extSig.ContentsSize())
+	require.Equal(t, int64(0), extSig.ByteRange[0])
+	require.Equal(t, int64(len(extSig.Data)), extSig.ByteRange[2]+extSig.ByteRange[3])
+
+	// Load the prepared document back, as in a new signing session.
+	loaded, err := model.NewExternalSignature(extSig.Data)
+	require.NoError(t, err)
+	require.Equal(t, extSig.ByteRange, loaded.ByteRange)
+
+	signedData := loaded.SignedData()
+	require.Len(t, signedData, int(loaded.ByteRange[1]+loaded.ByteRange[3]))
+	digest, err := loaded.Digest(crypto.SHA256)
+	require.NoError(t, err)
+	expected := sha256.Sum256(signedData)
+	require.Equal(t, expected[:], digest)
+
+	// Sign out-of-band.
+	certData, err := ioutil.ReadFile(testPKS12Key)
+	require.NoError(t, err)
+	privateKey, cert, err := pkcs12.Decode(certData, testPKS12KeyPassword)
+	require.NoError(t, err)
+	signedDataPKCS7, err := pkcs7.NewSignedData(signedData)
+	require.NoError(t, err)
+	require.NoError(t, signedDataPKCS7.AddSigner(cert, privateKey.(*rsa.PrivateKey), pkcs7.SignerInfoConfig{}))
+	signedDataPKCS7.Detach()
+	sigBytes, err := signedDataPKCS7.Finish()
+	require.NoError(t, err)
+
+	require.Error(t, loaded.Embed(make([]byte, 8193)))
+	require.NoError(t, loaded.Embed(sigBytes))
+
+	outputPath := tempFile("appender_prepare_external_signature.pdf")
+	require.NoError(t, ioutil.WriteFile(outputPath, loaded.Data, os.ModePerm))
+	validateFile(t, outputPath)
+
+	_, err = model.NewExternalSignature([]byte("%PDF-1.7"))
+	require.Error(t, err)
+}
@@ -0,0 +1,73 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// catalogModeledKeys are the document catalog entries which are set by the
+// writer or by its dedicated methods (e.g. SetOCProperties or SetForms).
+// Perms is included as the usage rights signatures it contains are
+// invalidated when the document is rewritten.
+var catalogModeledKeys = map[core.PdfObjectName]struct{}{
+	"Type": {}, "Version": {}, "Pages": {}, "PageLabels": {}, "Names": {}, "ViewerPreferences": {},
+	"PageLayout": {}, "PageMode": {}, "Outlines": {}, "Threads": {}, "OpenAction": {}, "AA": {},
+	"AcroForm": {}, "Metadata": {}, "StructTreeRoot": {}, "MarkInfo": {}, "Lang": {},
+	"OutputIntents": {}, "OCProperties": {}, "Collection": {}, "AF": {}, "Perms": {},
+}
+
+// GetCatalogExtensionEntries returns the entries of the document catalog
+// which are not modeled by the reader and by the writer, e.g. the developer
+// extensions (Extensions), the page-piece dictionary (PieceInfo) or vendor
+// specific entries. These entries are not written when the pages of the
+// document are added to a writer. See CopyCatalogExtensionEntries.
+func (r *PdfReader) GetCatalogExtensionEntries() (*core.PdfObjectDictionary, error) {
+	entries := core.MakeDict()
+	for _, key := range r.catalog.Keys() {
+		if _, ok := catalogModeledKeys[key]; ok {
+			continue
+		}
+		obj := core.ResolveReference(r.catalog.Get(key))
+		if !r.isLazy {
+			if err := r.traverseObjectData(obj); err != nil {
+				return nil, err
+			}
+		}
+		entries.Set(key, obj)
+	}
+	return entries, nil
+}
+
+// SetCatalogExtensionEntries sets the entries `entries` in the document
+// catalog. The entries which are modeled by the writer are skipped, as they
+// are set by its dedicated methods.
+func (w *PdfWriter) SetCatalogExtensionEntries(entries *core.PdfObjectDictionary) error {
+	for _, key := range entries.Keys() {
+		if _, ok := catalogModeledKeys[key]; ok {
+			common.Log.Debug("Skipping modeled catalog entry %s", key)
+			continue
+		}
+		obj := entries.Get(key)
+		w.catalog.Set(key, obj)
+		if err := w.addObjects(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CopyCatalogExtensionEntries copies the catalog extension entries of the
+// document read by `r` (see GetCatalogExtensionEntries) to the document
+// catalog, so that they survive the rewriting of the document.
+func (w *PdfWriter) CopyCatalogExtensionEntries(r *PdfReader) error {
+	entries, err := r.GetCatalogExtensionEntries()
+	if err != nil {
+		return err
+	}
+	return w.SetCatalogExtensionEntries(entries)
+}
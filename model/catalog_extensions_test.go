@@ -0,0 +1,82 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestCatalogExtensionEntriesRoundTrip(t *testing.T) {
+	// Write a document with extension entries in the catalog and the page.
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	require.NoError(t, page.AddContentStreamByString("0 0 10 10 re f"))
+	page.pageDict.Set("ABCD_Vendor", core.MakeString("page data"))
+	require.NoError(t, w.AddPage(page))
+
+	extensions := core.MakeDict()
+	adbe := core.MakeDict()
+	adbe.Set("BaseVersion", core.MakeName("1.7"))
+	adbe.Set("ExtensionLevel", core.MakeInteger(3))
+	extensions.Set("ADBE", adbe)
+	entries := core.MakeDict()
+	entries.Set("Extensions", extensions)
+	entries.Set("PieceInfo", core.MakeIndirectObject(core.MakeDict()))
+	entries.Set("ABCD_Vendor", core.MakeString("catalog data"))
+	entries.Set("Pages", core.MakeNull())
+	require.NoError(t, w.SetCatalogExtensionEntries(entries))
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	check := func(r *PdfReader) {
+		entries, err := r.GetCatalogExtensionEntries()
+		require.NoError(t, err)
+		require.Equal(t, []core.PdfObjectName{"Extensions", "PieceInfo", "ABCD_Vendor"}, entries.Keys())
+		ext, ok := core.GetDict(entries.Get("Extensions"))
+		require.True(t, ok)
+		adbe, ok := core.GetDict(ext.Get("ADBE"))
+		require.True(t, ok)
+		level, ok := core.GetIntVal(adbe.Get("ExtensionLevel"))
+		require.True(t, ok)
+		require.Equal(t, 3, level)
+		vendor, ok := core.GetString(entries.Get("ABCD_Vendor"))
+		require.True(t, ok)
+		require.Equal(t, "catalog data", vendor.Str())
+
+		page, err := r.GetPage(1)
+		require.NoError(t, err)
+		dict, ok := core.GetDict(page.ToPdfObject())
+		require.True(t, ok)
+		vendor, ok = core.GetString(dict.Get("ABCD_Vendor"))
+		require.True(t, ok)
+		require.Equal(t, "page data", vendor.Str())
+	}
+
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	check(r)
+
+	// Rewrite the document, modifying its page.
+	w = NewPdfWriter()
+	page, err = r.GetPage(1)
+	require.NoError(t, err)
+	page.Rotate = new(int64)
+	*page.Rotate = 90
+	require.NoError(t, w.AddPage(page.Duplicate()))
+	require.NoError(t, w.CopyCatalogExtensionEntries(r))
+	buf.Reset()
+	require.NoError(t, w.Write(&buf))
+
+	r, err = NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	check(r)
+}
@@ -0,0 +1,432 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// CollectionView represents the initial presentation of the files of a
+// portable collection (Table 155 - p. 386).
+type CollectionView string
+
+// Collection views.
+const (
+	CollectionViewDetails CollectionView = "D" // Files listed with the fields of the schema.
+	CollectionViewTile    CollectionView = "T" // Files shown as tiles.
+	CollectionViewHidden  CollectionView = "H" // File list hidden.
+)
+
+// CollectionFieldSubtype represents the data type of a collection schema
+// field (Table 157 - p. 387).
+type CollectionFieldSubtype string
+
+// Collection field subtypes. The values of the text, date and number fields
+// are stored in the collection item dictionaries of the files, while the
+// values of the other fields are taken from the file specifications and the
+// embedded file streams.
+const (
+	CollectionFieldText         CollectionFieldSubtype = "S"
+	CollectionFieldDate         CollectionFieldSubtype = "D"
+	CollectionFieldNumber       CollectionFieldSubtype = "N"
+	CollectionFieldFileName     CollectionFieldSubtype = "F"
+	CollectionFieldDescription  CollectionFieldSubtype = "Desc"
+	CollectionFieldModDate      CollectionFieldSubtype = "ModDate"
+	CollectionFieldCreationDate CollectionFieldSubtype = "CreationDate"
+	CollectionFieldSize         CollectionFieldSubtype = "Size"
+)
+
+// CollectionField represents a field of the schema of a portable collection
+// (Table 157 - p. 387).
+type CollectionField struct {
+	// Key is the key of the field in the schema and collection item dictionaries.
+	Key string
+
+	// Name is the field name presented to the user.
+	Name string
+
+	// Subtype is the data type of the field.
+	Subtype CollectionFieldSubtype
+
+	// Order is the relative order of the field in the user interface.
+	Order int64
+
+	// Hidden specifies whether the field is hidden in the user interface.
+	Hidden bool
+
+	// Editable specifies whether the field values can be edited by the user.
+	Editable bool
+}
+
+// PdfCollection represents a portable collection dictionary (PDF portfolio)
+// specifying how the embedded files of the document are presented
+// (section 12.3.5 p. 385).
+type PdfCollection struct {
+	// Schema contains the fields displayed for each file of the collection.
+	Schema []*CollectionField
+
+	// InitialDocument is the name tree key of the embedded file initially
+	// presented. If empty, the cover sheet document is presented.
+	InitialDocument string
+
+	// View is the initial view of the collection.
+	View CollectionView
+
+	// SortKeys contains the keys of the schema fields used for ordering
+	// the files, in order of precedence.
+	SortKeys []string
+
+	// SortAscending specifies the sort order of the files.
+	SortAscending bool
+
+	container *core.PdfObjectDictionary
+}
+
+// NewPdfCollection returns a new portable collection using the details view.
+// The default schema of the collection displays the file name, description,
+// modification date and size of the embedded files, which are sorted by name.
+func NewPdfCollection() *PdfCollection {
+	return &PdfCollection{
+		Schema: []*CollectionField{
+			{Key: "FileName", Name: "Name", Subtype: CollectionFieldFileName, Order: 1},
+			{Key: "Description", Name: "Description", Subtype: CollectionFieldDescription, Order: 2},
+			{Key: "ModDate", Name: "Modified", Subtype: CollectionFieldModDate, Order: 3},
+			{Key: "Size", Name: "Size", Subtype: CollectionFieldSize, Order: 4},
+		},
+		View:          CollectionViewDetails,
+		SortKeys:      []string{"FileName"},
+		SortAscending: true,
+		container:     core.MakeDict(),
+	}
+}
+
+// newPdfCollectionFromPdfObject loads a portable collection from the
+// specified collection dictionary.
+func newPdfCollectionFromPdfObject(obj core.PdfObject) (*PdfCollection, error) {
+	dict, ok := core.GetDict(obj)
+	if !ok {
+		return nil, core.ErrTypeError
+	}
+
+	collection := &PdfCollection{View: CollectionViewDetails, SortAscending: true, container: dict}
+	if schema, ok := core.GetDict(dict.Get("Schema")); ok {
+		for _, key := range schema.Keys() {
+			fieldDict, ok := core.GetDict(schema.Get(key))
+			if !ok {
+				continue
+			}
+
+			field := &CollectionField{Key: string(key)}
+			if name, ok := core.GetString(fieldDict.Get("N")); ok {
+				field.Name = name.Decoded()
+			}
+			if subtype, ok := core.GetNameVal(fieldDict.Get("Subtype")); ok {
+				field.Subtype = CollectionFieldSubtype(subtype)
+			}
+			if order, ok := core.GetIntVal(fieldDict.Get("O")); ok {
+				field.Order = int64(order)
+			}
+			if visible, ok := core.GetBoolVal(fieldDict.Get("V")); ok {
+				field.Hidden = !visible
+			}
+			if editable, ok := core.GetBoolVal(fieldDict.Get("E")); ok {
+				field.Editable = editable
+			}
+			collection.Schema = append(collection.Schema, field)
+		}
+
+		sort.SliceStable(collection.Schema, func(i, j int) bool {
+			return collection.Schema[i].Order < collection.Schema[j].Order
+		})
+	}
+
+	if initial, ok := core.GetString(dict.Get("D")); ok {
+		collection.InitialDocument = initial.Decoded()
+	}
+	if view, ok := core.GetNameVal(dict.Get("View")); ok {
+		collection.View = CollectionView(view)
+	}
+
+	if sortDict, ok := core.GetDict(dict.Get("Sort")); ok {
+		switch t := core.TraceToDirectObject(sortDict.Get("S")).(type) {
+		case *core.PdfObjectName:
+			collection.SortKeys = []string{string(*t)}
+		case *core.PdfObjectArray:
+			for _, key := range t.Elements() {
+				if name, ok := core.GetNameVal(key); ok {
+					collection.SortKeys = append(collection.SortKeys, name)
+				}
+			}
+		}
+
+		// The sort order can be specified for each sort key. Only the order
+		// of the first key is taken into account.
+		ascending := sortDict.Get("A")
+		if arr, ok := core.GetArray(ascending); ok && arr.Len() > 0 {
+			ascending = arr.Get(0)
+		}
+		if val, ok := core.GetBoolVal(ascending); ok {
+			collection.SortAscending = val
+		}
+	}
+
+	return collection, nil
+}
+
+// GetContainingPdfObject implements interface PdfModel.
+func (c *PdfCollection) GetContainingPdfObject() core.PdfObject {
+	return c.container
+}
+
+// ToPdfObject implements interface PdfModel.
+func (c *PdfCollection) ToPdfObject() core.PdfObject {
+	if c.container == nil {
+		c.container = core.MakeDict()
+	}
+	d := c.container
+	d.Clear()
+
+	d.Set("Type", core.MakeName("Collection"))
+	if len(c.Schema) > 0 {
+		schema := core.MakeDict()
+		schema.Set("Type", core.MakeName("CollectionSchema"))
+		for _, field := range c.Schema {
+			fieldDict := core.MakeDict()
+			fieldDict.Set("Type", core.MakeName("CollectionField"))
+			fieldDict.Set("Subtype", core.MakeName(string(field.Subtype)))
+			fieldDict.Set("N", core.MakeEncodedString(field.Name, true))
+			fieldDict.Set("O", core.MakeInteger(field.Order))
+			fieldDict.Set("V", core.MakeBool(!field.Hidden))
+			fieldDict.Set("E", core.MakeBool(field.Editable))
+			schema.Set(core.PdfObjectName(field.Key), fieldDict)
+		}
+		d.Set("Schema", schema)
+	}
+	if c.InitialDocument != "" {
+		d.Set("D", core.MakeEncodedString(c.InitialDocument, true))
+	}
+	if c.View != "" {
+		d.Set("View", core.MakeName(string(c.View)))
+	}
+	if len(c.SortKeys) > 0 {
+		keys := core.MakeArray()
+		for _, key := range c.SortKeys {
+			keys.Append(core.MakeName(key))
+		}
+		sortDict := core.MakeDict()
+		sortDict.Set("Type", core.MakeName("CollectionSort"))
+		sortDict.Set("S", keys)
+		sortDict.Set("A", core.MakeBool(c.SortAscending))
+		d.Set("Sort", sortDict)
+	}
+
+	return d
+}
+
+// EmbeddedFileEntry represents an entry of the embedded files name tree of a
+// document. For portable collections, the entry also contains the values of
+// the collection item dictionary of the file.
+type EmbeddedFileEntry struct {
+	// Key is the key of the entry in the embedded files name tree.
+	Key string
+
+	// Filespec is the file specification of the embedded file.
+	Filespec *PdfFilespec
+
+	// Values contains the values of the collection schema fields of the
+	// file, keyed by field key.
+	Values map[string]core.PdfObject
+}
+
+// IsPortfolio returns true if the document is a portable collection
+// (PDF portfolio).
+func (r *PdfReader) IsPortfolio() bool {
+	_, ok := core.GetDict(r.catalog.Get("Collection"))
+	return ok
+}
+
+// GetCollection returns the portable collection dictionary of the document,
+// or nil if the document is not a portable collection.
+func (r *PdfReader) GetCollection() (*PdfCollection, error) {
+	obj := core.ResolveReference(r.catalog.Get("Collection"))
+	if obj == nil || core.IsNullObject(obj) {
+		return nil, nil
+	}
+	if !r.isLazy {
+		if err := r.traverseObjectData(obj); err != nil {
+			return nil, err
+		}
+	}
+	return newPdfCollectionFromPdfObject(obj)
+}
+
+// GetEmbeddedFiles returns the entries of the embedded files name tree of the
+// document, which contains the files of portable collections and the document
+// level file attachments. The contents of the files can be retrieved using the
+// GetEmbeddedFile method of the file specifications.
+func (r *PdfReader) GetEmbeddedFiles() ([]*EmbeddedFileEntry, error) {
+	names, ok := core.GetDict(r.catalog.Get("Names"))
+	if !ok {
+		return nil, nil
+	}
+
+	var entries []*EmbeddedFileEntry
+	var lastErr error
+	nameTreeEntries(names.Get("EmbeddedFiles"), 0, func(key string, obj core.PdfObject) {
+		if !r.isLazy {
+			if err := r.traverseObjectData(obj); err != nil {
+				lastErr = err
+				return
+			}
+		}
+
+		fs, err := NewPdfFilespecFromObj(obj)
+		if err != nil {
+			common.Log.Debug("ERROR: invalid embedded file %s: %v", key, err)
+			return
+		}
+
+		entry := &EmbeddedFileEntry{Key: key, Filespec: fs}
+		if ci, ok := core.GetDict(fs.CI); ok {
+			entry.Values = map[string]core.PdfObject{}
+			for _, k := range ci.Keys() {
+				if k == "Type" {
+					continue
+				}
+				entry.Values[string(k)] = core.TraceToDirectObject(ci.Get(k))
+			}
+		}
+		entries = append(entries, entry)
+	})
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return entries, nil
+}
+
+// nameTreeEntries calls `fn` for each entry of the name tree `node`, in
+// the order in which the entries are stored.
+func nameTreeEntries(node core.PdfObject, depth int, fn func(key string, obj core.PdfObject)) {
+	dict, ok := core.GetDict(node)
+	if !ok || depth > maxNameTreeDepth {
+		return
+	}
+
+	if names, ok := core.GetArray(dict.Get("Names")); ok {
+		for i := 0; i+1 < names.Len(); i += 2 {
+			key, ok := core.GetString(names.Get(i))
+			if !ok {
+				continue
+			}
+			fn(key.Decoded(), core.ResolveReference(names.Get(i+1)))
+		}
+	}
+
+	if kids, ok := core.GetArray(dict.Get("Kids")); ok {
+		for _, kid := range kids.Elements() {
+			nameTreeEntries(kid, depth+1, fn)
+		}
+	}
+}
+
+// SetEmbeddedFiles sets the embedded files name tree of the document, in the
+// Names entry of the PDF catalog. The entries are sorted by key, as required
+// for name trees. The collection item values of the entries, if any, are set
+// as the CI entry of the file specifications.
+func (w *PdfWriter) SetEmbeddedFiles(entries []*EmbeddedFileEntry) error {
+	sorted := make([]*EmbeddedFileEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	names := core.MakeArray()
+	for i, entry := range sorted {
+		if entry.Filespec == nil {
+			return errors.New("embedded file specification cannot be nil")
+		}
+		if i > 0 && entry.Key == sorted[i-1].Key {
+			return fmt.Errorf("duplicate embedded file key: %s", entry.Key)
+		}
+
+		if len(entry.Values) > 0 {
+			keys := make([]string, 0, len(entry.Values))
+			for key := range entry.Values {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			ci := core.MakeDict()
+			ci.Set("Type", core.MakeName("CollectionItem"))
+			for _, key := range keys {
+				ci.Set(core.PdfObjectName(key), entry.Values[key])
+			}
+			entry.Filespec.CI = ci
+		}
+
+		names.Append(core.MakeEncodedString(entry.Key, true), entry.Filespec.ToPdfObject())
+	}
+
+	tree := core.MakeDict()
+	tree.Set("Names", names)
+
+	namesDict, ok := core.GetDict(w.catalog.Get("Names"))
+	if !ok {
+		namesDict = core.MakeDict()
+		w.catalog.Set("Names", namesDict)
+	}
+	namesDict.Set("EmbeddedFiles", tree)
+
+	common.Log.Trace("Setting catalog EmbeddedFiles...")
+	return w.addObjects(namesDict)
+}
+
+// SetCollection sets the portable collection dictionary of the document,
+// turning it into a PDF portfolio. The files of the collection are set using
+// SetEmbeddedFiles. The PDF version of the output is set to at least 1.7.
+func (w *PdfWriter) SetCollection(collection *PdfCollection) error {
+	if collection == nil {
+		return nil
+	}
+	if w.majorVersion == 1 && w.minorVersion < 7 {
+		w.SetVersion(1, 7)
+	}
+
+	obj := collection.ToPdfObject()
+	w.catalog.Set("Collection", obj)
+	return w.addObjects(obj)
+}
+
+// SetPortfolio creates a PDF portfolio containing the specified files. The
+// files are presented using the collection `collection`, or the default
+// collection returned by NewPdfCollection if nil. The name of each file is
+// used as its key in the embedded files name tree. The pages added to the
+// writer are used as the cover sheet of the portfolio, which is presented by
+// viewers not supporting portable collections.
+func (w *PdfWriter) SetPortfolio(files []*EmbeddedFile, collection *PdfCollection) error {
+	if collection == nil {
+		collection = NewPdfCollection()
+	}
+
+	entries := make([]*EmbeddedFileEntry, len(files))
+	for i, file := range files {
+		fs, err := NewPdfFilespecFromEmbeddedFile(file)
+		if err != nil {
+			return err
+		}
+		entries[i] = &EmbeddedFileEntry{Key: file.Name, Filespec: fs}
+	}
+
+	if err := w.SetEmbeddedFiles(entries); err != nil {
+		return err
+	}
+	return w.SetCollection(collection)
+}
@@ -0,0 +1,133 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestPortfolio(t *testing.T) {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+
+	modTime := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	files := []*EmbeddedFile{
+		{Name: "report.txt", Content: []byte("report"), FileType: "text/plain", ModTime: modTime},
+		{Name: "data.csv", Content: []byte("a,b\n1,2\n"), Description: "Data"},
+	}
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	require.NoError(t, w.SetPortfolio(files, nil))
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.True(t, r.IsPortfolio())
+
+	collection, err := r.GetCollection()
+	require.NoError(t, err)
+	require.NotNil(t, collection)
+	require.Equal(t, CollectionViewDetails, collection.View)
+	require.Equal(t, []string{"FileName"}, collection.SortKeys)
+	require.True(t, collection.SortAscending)
+	require.Len(t, collection.Schema, 4)
+	require.Equal(t, "FileName", collection.Schema[0].Key)
+	require.Equal(t, CollectionFieldFileName, collection.Schema[0].Subtype)
+	require.Equal(t, CollectionFieldSize, collection.Schema[3].Subtype)
+
+	// Entries are sorted by key.
+	entries, err := r.GetEmbeddedFiles()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "data.csv", entries[0].Key)
+	require.Equal(t, "report.txt", entries[1].Key)
+
+	file, err := entries[1].Filespec.GetEmbeddedFile()
+	require.NoError(t, err)
+	require.Equal(t, "report.txt", file.Name)
+	require.Equal(t, []byte("report"), file.Content)
+	require.Equal(t, "text/plain", file.FileType)
+	require.True(t, modTime.Equal(file.ModTime))
+
+	file, err = entries[0].Filespec.GetEmbeddedFile()
+	require.NoError(t, err)
+	require.Equal(t, "Data", file.Description)
+}
+
+func TestPortfolioCustomSchema(t *testing.T) {
+	collection := NewPdfCollection()
+	collection.Schema = []*CollectionField{
+		{Key: "FileName", Name: "Name", Subtype: CollectionFieldFileName, Order: 1},
+		{Key: "Author", Name: "Author", Subtype: CollectionFieldText, Order: 2, Editable: true},
+		{Key: "Pages", Name: "Pages", Subtype: CollectionFieldNumber, Order: 3, Hidden: true},
+	}
+	collection.View = CollectionViewTile
+	collection.SortKeys = []string{"Author", "FileName"}
+	collection.SortAscending = false
+	collection.InitialDocument = "b.pdf"
+
+	var entries []*EmbeddedFileEntry
+	for _, name := range []string{"b.pdf", "a.pdf"} {
+		fs, err := NewPdfFilespecFromEmbeddedFile(&EmbeddedFile{Name: name, Content: []byte(name)})
+		require.NoError(t, err)
+		entries = append(entries, &EmbeddedFileEntry{
+			Key:      name,
+			Filespec: fs,
+			Values: map[string]core.PdfObject{
+				"Author": core.MakeString("John " + name),
+				"Pages":  core.MakeInteger(3),
+			},
+		})
+	}
+
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+	require.NoError(t, w.AddPage(page))
+	require.NoError(t, w.SetEmbeddedFiles(entries))
+	require.NoError(t, w.SetCollection(collection))
+
+	// Duplicate keys are not allowed.
+	require.Error(t, w.SetEmbeddedFiles([]*EmbeddedFileEntry{entries[0], entries[0]}))
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	readCollection, err := r.GetCollection()
+	require.NoError(t, err)
+	require.Equal(t, CollectionViewTile, readCollection.View)
+	require.Equal(t, "b.pdf", readCollection.InitialDocument)
+	require.Equal(t, []string{"Author", "FileName"}, readCollection.SortKeys)
+	require.False(t, readCollection.SortAscending)
+	require.Len(t, readCollection.Schema, 3)
+	require.Equal(t, "Author", readCollection.Schema[1].Key)
+	require.True(t, readCollection.Schema[1].Editable)
+	require.False(t, readCollection.Schema[1].Hidden)
+	require.True(t, readCollection.Schema[2].Hidden)
+
+	readEntries, err := r.GetEmbeddedFiles()
+	require.NoError(t, err)
+	require.Len(t, readEntries, 2)
+	require.Equal(t, "a.pdf", readEntries[0].Key)
+	author, ok := core.GetStringVal(readEntries[0].Values["Author"])
+	require.True(t, ok)
+	require.Equal(t, "John a.pdf", author)
+	pages, ok := core.GetIntVal(readEntries[0].Values["Pages"])
+	require.True(t, ok)
+	require.Equal(t, 3, pages)
+}
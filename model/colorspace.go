@@ -6,13 +6,17 @@
 package model
 
 import (
+	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"image/color"
 	"math"
+	"strconv"
 
 	"github.com/unidoc/unipdf/v3/common"
 	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/ps"
 )
 
 // PdfColorspace interface defines the common methods of a PDF colorspace.
@@ -1763,7 +1767,8 @@ func (this *PdfColorICCBased) ToInteger(bits int) []uint32 {
 // A conforming reader shall support ICC.1:2004:10 as required by PDF 1.7, which will enable it
 // to properly render all embedded ICC profiles regardless of the PDF version
 //
-// In the current implementation, we rely on the alternative colormap provided.
+// The embedded profile can be parsed with Profile. Color conversions rely on
+// the alternative colormap provided.
 type PdfColorspaceICCBased struct {
 	N         int           // Number of color components (Required). Can be 1,3, or 4.
 	Alternate PdfColorspace // Alternate colorspace for non-conforming readers.
@@ -1775,6 +1780,7 @@ type PdfColorspaceICCBased struct {
 
 	container *core.PdfIndirectObject
 	stream    *core.PdfObjectStream
+	profile   *ICCProfile
 }
 
 // GetNumComponents returns the number of color components.
@@ -1804,6 +1810,53 @@ func NewPdfColorspaceICCBased(N int) (*PdfColorspaceICCBased, error) {
 	return cs, nil
 }
 
+// NewPdfColorspaceICCBasedFromProfile returns a new ICCBased colorspace
+// embedding `profile`. The alternate colorspace is set to the device
+// colorspace with the same number of components.
+func NewPdfColorspaceICCBasedFromProfile(profile *ICCProfile) (*PdfColorspaceICCBased, error) {
+	if profile == nil {
+		return nil, errors.New("nil ICC profile")
+	}
+
+	var alternate PdfColorspace
+	switch profile.ColorSpace {
+	case ICCColorSpaceGray:
+		alternate = NewPdfColorspaceDeviceGray()
+	case ICCColorSpaceRGB:
+		alternate = NewPdfColorspaceDeviceRGB()
+	case ICCColorSpaceCMYK:
+		alternate = NewPdfColorspaceDeviceCMYK()
+	default:
+		return nil, fmt.Errorf("unsupported ICC profile color space %q", profile.ColorSpace)
+	}
+
+	cs, err := NewPdfColorspaceICCBased(profile.NumComponents())
+	if err != nil {
+		return nil, err
+	}
+	cs.Alternate = alternate
+	cs.Data = profile.Data
+	cs.profile = profile
+	return cs, nil
+}
+
+// Profile returns the parsed ICC profile of the colorspace. An error is
+// returned if the profile is invalid or if its number of color components
+// does not match N.
+func (cs *PdfColorspaceICCBased) Profile() (*ICCProfile, error) {
+	if cs.profile == nil || !bytes.Equal(cs.profile.Data, cs.Data) {
+		profile, err := NewICCProfile(cs.Data)
+		if err != nil {
+			return nil, err
+		}
+		cs.profile = profile
+	}
+	if n := cs.profile.NumComponents(); n != cs.N {
+		return nil, fmt.Errorf("ICC profile components mismatch (%d != N %d)", n, cs.N)
+	}
+	return cs.profile, nil
+}
+
 // Input format [/ICCBased stream]
 func newPdfColorspaceICCBasedFromPdfObject(obj core.PdfObject) (*PdfColorspaceICCBased, error) {
 	cs := &PdfColorspaceICCBased{}
@@ -1897,6 +1950,12 @@ func newPdfColorspaceICCBasedFromPdfObject(obj core.PdfObject) (*PdfColorspaceIC
 	cs.Data = data
 	cs.stream = stream
 
+	// Validate the embedded profile. Invalid profiles are tolerated as the
+	// alternate colorspace is used for color conversions.
+	if _, err := cs.Profile(); err != nil {
+		common.Log.Debug("ICCBased colorspace with invalid profile: %v", err)
+	}
+
 	return cs, nil
 }
 
@@ -1931,10 +1990,17 @@ func (cs *PdfColorspaceICCBased) ToPdfObject() core.PdfObject {
 		dict.Set("Range", core.MakeArray(ranges...))
 	}
 
-	// Encode with a default encoder?
-	dict.Set("Length", core.MakeInteger(int64(len(cs.Data))))
-	// Need to have a representation of the stream...
-	stream.Stream = cs.Data
+	// Compress the profile data.
+	data := cs.Data
+	encoder := core.NewFlateEncoder()
+	if encoded, err := encoder.EncodeBytes(cs.Data); err == nil {
+		data = encoded
+		dict.Set("Filter", core.MakeName(encoder.GetFilterName()))
+	} else {
+		common.Log.Debug("ERROR: unable to encode ICC profile: %v", err)
+	}
+	dict.Set("Length", core.MakeInteger(int64(len(data))))
+	stream.Stream = data
 	stream.PdfObjectDictionary = dict
 
 	csObj.Append(stream)
@@ -2236,6 +2302,45 @@ func NewPdfColorspaceSpecialIndexed() *PdfColorspaceSpecialIndexed {
 	return &PdfColorspaceSpecialIndexed{HiVal: 255}
 }
 
+// NewPdfColorspaceSpecialIndexedFromColors returns a new Indexed colorspace,
+// whose color table contains `colors`, specified as components of the `base`
+// colorspace. At most 256 colors can be specified.
+func NewPdfColorspaceSpecialIndexedFromColors(base PdfColorspace, colors [][]float64) (*PdfColorspaceSpecialIndexed, error) {
+	if base == nil {
+		return nil, errors.New("indexed base colorspace undefined")
+	}
+	switch base.(type) {
+	case *PdfColorspaceSpecialIndexed, *PdfColorspaceSpecialPattern:
+		return nil, errors.New("indexed CS: invalid base colorspace")
+	}
+	if len(colors) == 0 || len(colors) > 256 {
+		return nil, fmt.Errorf("indexed CS: invalid number of colors (%d)", len(colors))
+	}
+
+	N := base.GetNumComponents()
+	decode := base.DecodeArray()
+	lookup := make([]byte, 0, len(colors)*N)
+	for _, color := range colors {
+		if len(color) != N {
+			return nil, fmt.Errorf("indexed CS: invalid number of color components (%d != %d)", len(color), N)
+		}
+		for i, val := range color {
+			if len(decode) >= 2*i+2 {
+				val = interpolate(val, decode[2*i], decode[2*i+1], 0, 1)
+			}
+			val = math.Min(math.Max(val, 0), 1)
+			lookup = append(lookup, byte(math.Round(val*255)))
+		}
+	}
+
+	cs := NewPdfColorspaceSpecialIndexed()
+	cs.Base = base
+	cs.HiVal = len(colors) - 1
+	cs.Lookup = core.MakeStringFromBytes(lookup)
+	cs.colorLookup = lookup
+	return cs, nil
+}
+
 func (cs *PdfColorspaceSpecialIndexed) String() string {
 	return "Indexed"
 }
@@ -2355,10 +2460,13 @@ func (cs *PdfColorspaceSpecialIndexed) ColorFromFloats(vals []float64) (PdfColor
 		return nil, errors.New("outside range")
 	}
 
+	// The lookup table values are mapped to the component ranges of the
+	// base colorspace.
 	cvals := cs.colorLookup[index : index+N]
+	decode := cs.Base.DecodeArray()
 	var floats []float64
-	for _, val := range cvals {
-		floats = append(floats, float64(val)/255.0)
+	for i, val := range cvals {
+		floats = append(floats, indexedComponentValue(val, decode, i))
 	}
 	color, err := cs.Base.ColorFromFloats(floats)
 	if err != nil {
@@ -2394,33 +2502,45 @@ func (cs *PdfColorspaceSpecialIndexed) ColorToRGB(color PdfColor) (PdfColor, err
 
 // ImageToRGB convert an indexed image to RGB.
 func (cs *PdfColorspaceSpecialIndexed) ImageToRGB(img Image) (Image, error) {
-	//baseImage := img
 	// Make a new representation of the image to be converted with the base colorspace.
+	// The lookup table entries are 8 bit values, whatever the bits per
+	// component of the indexed image.
 	baseImage := Image{}
 	baseImage.Height = img.Height
 	baseImage.Width = img.Width
 	baseImage.alphaData = img.alphaData
-	// TODO(peterwilliams97): Add support for other BitsPerComponent values.
-	// See https://github.com/unidoc/unipdf/issues/260
 	baseImage.BitsPerComponent = 8
 	baseImage.hasAlpha = img.hasAlpha
 	baseImage.ColorComponents = cs.Base.GetNumComponents()
+	baseImage.decode = cs.Base.DecodeArray()
 
-	samples := img.GetSamples()
+	samples := img.samplesByRow()
 	N := cs.Base.GetNumComponents()
 
 	if N < 1 {
 		return Image{}, fmt.Errorf("bad base colorspace NumComponents=%d", N)
 	}
 
-	var baseSamples []uint32
+	maxVal := float64(uint32(1)<<uint(img.BitsPerComponent) - 1)
+	decode := img.decode
+	if len(decode) != 2 {
+		decode = nil
+	}
+
+	baseData := make([]byte, 0, len(samples)*N)
 	// Convert the indexed data to base color map data.
 	for i := 0; i < len(samples); i++ {
 		// Each data point represents an index location.
 		// For each entry there are N values.
 		index := int(samples[i])
+		if decode != nil {
+			index = int(math.Round(interpolate(float64(samples[i]), 0, maxVal, decode[0], decode[1])))
+		}
 		common.Log.Trace("Indexed: index=%d N=%d lut=%d", index, N, len(cs.colorLookup))
 		// Ensure does not go out of bounds.
+		if index < 0 {
+			index = 0
+		}
 		if (index+1)*N > len(cs.colorLookup) {
 			// Clip to the end value.
 			index = len(cs.colorLookup)/N - 1
@@ -2431,22 +2551,23 @@ func (cs *PdfColorspaceSpecialIndexed) ImageToRGB(img Image) (Image, error) {
 			}
 		}
 
-		cvals := cs.colorLookup[index*N : (index+1)*N]
-		common.Log.Trace("C Vals: % d", cvals)
-		for _, val := range cvals {
-			baseSamples = append(baseSamples, uint32(val))
-		}
+		baseData = append(baseData, cs.colorLookup[index*N:(index+1)*N]...)
 	}
-	baseImage.SetSamples(baseSamples)
-	baseImage.ColorComponents = N
-
-	common.Log.Trace("Input samples: %d", samples)
-	common.Log.Trace("-> Output samples: %d", baseSamples)
+	baseImage.Data = baseData
 
 	// Convert to rgb.
 	return cs.Base.ImageToRGB(baseImage)
 }
 
+// indexedComponentValue maps the lookup table value `val` of the component
+// `i` to the component range specified by `decode`.
+func indexedComponentValue(val byte, decode []float64, i int) float64 {
+	if len(decode) < 2*i+2 {
+		return float64(val) / 255.0
+	}
+	return interpolate(float64(val), 0, 255, decode[2*i], decode[2*i+1])
+}
+
 // ToPdfObject converts colorspace to a PDF object. [/Indexed base hival lookup]
 func (cs *PdfColorspaceSpecialIndexed) ToPdfObject() core.PdfObject {
 	csObj := core.MakeArray(core.MakeName("Indexed"))
@@ -2484,6 +2605,64 @@ func NewPdfColorspaceSpecialSeparation() *PdfColorspaceSpecialSeparation {
 	return cs
 }
 
+// NewPdfColorspaceSpecialSeparationSpot returns a new Separation colorspace
+// for the spot colorant `colorant`. The tint transform linearly maps tint 0
+// to the absence of colorant and tint 1 to `alternateColor`, the components
+// of the colorant in the `alternate` colorspace.
+func NewPdfColorspaceSpecialSeparationSpot(colorant string, alternate PdfColorspace, alternateColor []float64) (*PdfColorspaceSpecialSeparation, error) {
+	if colorant == "" {
+		return nil, errors.New("separation CS: empty colorant name")
+	}
+	white, err := colorspaceNoColorant(alternate)
+	if err != nil {
+		return nil, err
+	}
+	if len(alternateColor) != len(white) {
+		return nil, fmt.Errorf("separation CS: invalid number of color components (%d != %d)", len(alternateColor), len(white))
+	}
+
+	cs := NewPdfColorspaceSpecialSeparation()
+	cs.ColorantName = core.MakeName(colorant)
+	cs.AlternateSpace = alternate
+	cs.TintTransform = &PdfFunctionType2{
+		Domain: []float64{0, 1},
+		C0:     white,
+		C1:     alternateColor,
+		N:      1,
+	}
+	return cs, nil
+}
+
+// colorspaceNoColorant returns the color components representing the
+// absence of colorant (blank paper) in the device colorspace `cs`.
+func colorspaceNoColorant(cs PdfColorspace) ([]float64, error) {
+	switch t := cs.(type) {
+	case *PdfColorspaceDeviceGray, *PdfColorspaceCalGray:
+		return []float64{1}, nil
+	case *PdfColorspaceDeviceRGB, *PdfColorspaceCalRGB:
+		return []float64{1, 1, 1}, nil
+	case *PdfColorspaceDeviceCMYK:
+		return []float64{0, 0, 0, 0}, nil
+	case *PdfColorspaceLab:
+		return []float64{100, 0, 0}, nil
+	case *PdfColorspaceICCBased:
+		if t.Alternate != nil {
+			return colorspaceNoColorant(t.Alternate)
+		}
+		switch t.N {
+		case 1:
+			return []float64{1}, nil
+		case 3:
+			return []float64{1, 1, 1}, nil
+		case 4:
+			return []float64{0, 0, 0, 0}, nil
+		}
+	case nil:
+		return nil, errors.New("alternate colorspace undefined")
+	}
+	return nil, fmt.Errorf("unsupported alternate colorspace %s", cs)
+}
+
 func (cs *PdfColorspaceSpecialSeparation) String() string {
 	return "Separation"
 }
@@ -2627,50 +2806,90 @@ func (cs *PdfColorspaceSpecialSeparation) ColorToRGB(color PdfColor) (PdfColor,
 // ImageToRGB converts an image with samples in Separation CS to an image with samples specified in
 // DeviceRGB CS.
 func (cs *PdfColorspaceSpecialSeparation) ImageToRGB(img Image) (Image, error) {
-	altImage := img
-
-	samples := img.GetSamples()
-	maxVal := math.Pow(2, float64(img.BitsPerComponent)) - 1
-
 	common.Log.Trace("Separation color space -> ToRGB conversion")
-	common.Log.Trace("samples in: %d", len(samples))
 	common.Log.Trace("TintTransform: %+v", cs.TintTransform)
 
-	altDecode := cs.AlternateSpace.DecodeArray()
+	altImage, err := tintImageToAlternate(img, 1, cs.AlternateSpace, cs.TintTransform)
+	if err != nil {
+		return img, err
+	}
 
-	var altSamples []uint32
-	// Convert tints to color data in the alternate colorspace.
-	for _, sample := range samples {
-		// A single tint component is in the range 0.0 - 1.0
-		tint := float64(sample) / maxVal
+	// Convert to RGB via the alternate colorspace.
+	return cs.AlternateSpace.ImageToRGB(altImage)
+}
+
+// tintImageToAlternate converts the tint values of the `numTints` components
+// of `img` to an 8 bit image in the `alternate` colorspace, by evaluating the
+// `tintTransform` function. The transformed values are cached, as images
+// usually contain a limited number of different colors.
+func tintImageToAlternate(img Image, numTints int, alternate PdfColorspace, tintTransform PdfFunction) (Image, error) {
+	if alternate == nil || tintTransform == nil {
+		return img, errors.New("alternate colorspace or tint transform undefined")
+	}
+
+	samples := img.samplesByRow()
+	maxVal := float64(uint32(1)<<uint(img.BitsPerComponent) - 1)
 
-		// Convert the tint value to the alternate space value.
-		outputs, err := cs.TintTransform.Evaluate([]float64{tint})
-		//common.Log.Trace("%v Converting tint value: %f -> [% f]", cs.AlternateSpace, tint, outputs)
+	decode := img.decode
+	if len(decode) != 2*numTints {
+		decode = nil
+	}
+	altDecode := alternate.DecodeArray()
+	numAlt := alternate.GetNumComponents()
+
+	cache := map[string][]byte{}
+	key := make([]byte, 4*numTints)
+	inputs := make([]float64, numTints)
+	altData := make([]byte, 0, len(samples)/numTints*numAlt)
+	for i := 0; i+numTints <= len(samples); i += numTints {
+		for j := 0; j < numTints; j++ {
+			binary.BigEndian.PutUint32(key[4*j:], samples[i+j])
+		}
+		if vals, ok := cache[string(key)]; ok {
+			altData = append(altData, vals...)
+			continue
+		}
+
+		// A single tint component is in the range 0.0 - 1.0
+		for j := range inputs {
+			tint := float64(samples[i+j]) / maxVal
+			if decode != nil {
+				tint = interpolate(tint, 0, 1, decode[2*j], decode[2*j+1])
+			}
+			inputs[j] = tint
+		}
 
+		// Convert the tint values to the alternate space values.
+		outputs, err := tintTransform.Evaluate(inputs)
 		if err != nil {
 			return img, err
 		}
+		if len(outputs) < numAlt {
+			return img, fmt.Errorf("tint transform output too short (%d < %d)", len(outputs), numAlt)
+		}
 
-		for i, val := range outputs {
+		vals := make([]byte, numAlt)
+		for j := range vals {
 			// Convert component value to 0-1 range.
-			altVal := interpolate(val, altDecode[i*2], altDecode[i*2+1], 0, 1)
-
-			// Rescale to [0, maxVal]
-			altComponent := uint32(altVal * maxVal)
-
-			altSamples = append(altSamples, altComponent)
+			val := outputs[j]
+			if len(altDecode) >= 2*j+2 {
+				val = interpolate(val, altDecode[2*j], altDecode[2*j+1], 0, 1)
+			}
+			val = math.Min(math.Max(val, 0), 1)
+			// Rescale to [0, 255].
+			vals[j] = byte(math.Round(val * 255))
 		}
+		cache[string(key)] = vals
+		altData = append(altData, vals...)
 	}
-	common.Log.Trace("Samples out: %d", len(altSamples))
-	altImage.SetSamples(altSamples)
-	altImage.ColorComponents = cs.AlternateSpace.GetNumComponents()
 
+	altImage := img
+	altImage.Data = altData
+	altImage.BitsPerComponent = 8
+	altImage.ColorComponents = numAlt
 	// Set the image's decode parameters for interpretation in the alternative CS.
 	altImage.decode = altDecode
-
-	// Convert to RGB via the alternate colorspace.
-	return cs.AlternateSpace.ImageToRGB(altImage)
+	return altImage, nil
 }
 
 // PdfColorspaceDeviceN represents a DeviceN color space. DeviceN color spaces are similar to Separation color
@@ -2694,6 +2913,96 @@ func NewPdfColorspaceDeviceN() *PdfColorspaceDeviceN {
 	return cs
 }
 
+// NewPdfColorspaceDeviceNFromColorants returns a new DeviceN colorspace for
+// the `colorants`, where `alternateColors` contains the components of each
+// colorant in the `alternate` colorspace. The tint transform is a PostScript
+// calculator function mixing the colorants: each tint moves the alternate
+// components from the absence of colorant towards the colorant color, and the
+// contributions of the colorants are summed.
+func NewPdfColorspaceDeviceNFromColorants(colorants []string, alternate PdfColorspace, alternateColors [][]float64) (*PdfColorspaceDeviceN, error) {
+	if len(colorants) == 0 {
+		return nil, errors.New("deviceN CS: no colorants")
+	}
+	if len(alternateColors) != len(colorants) {
+		return nil, errors.New("deviceN CS: colorants and alternate colors mismatch")
+	}
+	white, err := colorspaceNoColorant(alternate)
+	if err != nil {
+		return nil, err
+	}
+	n, m := len(colorants), len(white)
+
+	names := core.MakeArray()
+	for _, colorant := range colorants {
+		if colorant == "" {
+			return nil, errors.New("deviceN CS: empty colorant name")
+		}
+		names.Append(core.MakeName(colorant))
+	}
+
+	// The tints t_0 ... t_(n-1) are on the stack. Each output component is
+	// computed as white_j + sum_i t_i * (color_ij - white_j), clipped.
+	altRange := alternate.DecodeArray()
+	var prog bytes.Buffer
+	prog.WriteString("{")
+	for j := 0; j < m; j++ {
+		fmt.Fprintf(&prog, " %s", formatPSNumber(white[j]))
+		for i, color := range alternateColors {
+			if len(color) != m {
+				return nil, fmt.Errorf("deviceN CS: invalid number of color components (%d != %d)", len(color), m)
+			}
+			// Position of t_i from the top of the stack.
+			pos := n - 1 - i + j + 1
+			fmt.Fprintf(&prog, " %d index %s mul add", pos, formatPSNumber(color[j]-white[j]))
+		}
+		lo, hi := 0.0, 1.0
+		if len(altRange) >= 2*j+2 {
+			lo, hi = altRange[2*j], altRange[2*j+1]
+		}
+		fmt.Fprintf(&prog, " dup %s lt { pop %s } if dup %s gt { pop %s } if",
+			formatPSNumber(lo), formatPSNumber(lo), formatPSNumber(hi), formatPSNumber(hi))
+	}
+	// Remove the tints.
+	fmt.Fprintf(&prog, " %d %d roll", n+m, m)
+	for i := 0; i < n; i++ {
+		prog.WriteString(" pop")
+	}
+	prog.WriteString(" }")
+
+	program, err := ps.NewPSParser(prog.Bytes()).Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	domain := make([]float64, 0, 2*n)
+	for i := 0; i < n; i++ {
+		domain = append(domain, 0, 1)
+	}
+	rang := altRange
+	if len(rang) != 2*m {
+		rang = nil
+		for j := 0; j < m; j++ {
+			rang = append(rang, 0, 1)
+		}
+	}
+
+	cs := NewPdfColorspaceDeviceN()
+	cs.ColorantNames = names
+	cs.AlternateSpace = alternate
+	cs.TintTransform = &PdfFunctionType4{
+		Domain:      domain,
+		Range:       rang,
+		Program:     program,
+		decodedData: prog.Bytes(),
+	}
+	return cs, nil
+}
+
+// formatPSNumber formats `val` for use in a PostScript calculator function.
+func formatPSNumber(val float64) string {
+	return strconv.FormatFloat(val, 'f', -1, 64)
+}
+
 // String returns the name of the colorspace (DeviceN).
 func (cs *PdfColorspaceDeviceN) String() string {
 	return "DeviceN"
@@ -2846,40 +3155,11 @@ func (cs *PdfColorspaceDeviceN) ColorToRGB(color PdfColor) (PdfColor, error) {
 
 // ImageToRGB converts an Image in a given PdfColorspace to an RGB image.
 func (cs *PdfColorspaceDeviceN) ImageToRGB(img Image) (Image, error) {
-	altImage := img
-
-	samples := img.GetSamples()
-	maxVal := math.Pow(2, float64(img.BitsPerComponent)) - 1
-
-	// Convert tints to color data in the alternate colorspace.
-	var altSamples []uint32
-	for i := 0; i < len(samples); i += cs.GetNumComponents() {
-		// The input to the tint transformation is the tint
-		// for each color component.
-		//
-		// A single tint component is in the range 0.0 - 1.0
-		var inputs []float64
-		for j := 0; j < cs.GetNumComponents(); j++ {
-			tint := float64(samples[i+j]) / maxVal
-			inputs = append(inputs, tint)
-		}
-
-		// Transform the tints to the alternate colorspace.
-		// (scaled units).
-		outputs, err := cs.TintTransform.Evaluate(inputs)
-		if err != nil {
-			return img, err
-		}
-
-		for _, val := range outputs {
-			// Clip.
-			val = math.Min(math.Max(0, val), 1.0)
-			// Rescale to [0, maxVal]
-			altComponent := uint32(val * maxVal)
-			altSamples = append(altSamples, altComponent)
-		}
+	// Transform the tints to the alternate colorspace.
+	altImage, err := tintImageToAlternate(img, cs.GetNumComponents(), cs.AlternateSpace, cs.TintTransform)
+	if err != nil {
+		return img, err
 	}
-	altImage.SetSamples(altSamples)
 
 	// Convert to RGB via the alternate colorspace.
 	return cs.AlternateSpace.ImageToRGB(altImage)
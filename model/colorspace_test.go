@@ -11,6 +11,8 @@ import (
 	"io/ioutil"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/unidoc/unipdf/v3/core"
 	"github.com/unidoc/unipdf/v3/internal/testutils"
 )
@@ -174,3 +176,130 @@ endstream endobj
 		t.Fatalf("Incorrect function obj number (got %d)", f.ObjectNumber)
 	}
 }
+
+// reloadColorspace serializes `cs` and loads it back.
+func reloadColorspace(t *testing.T, cs PdfColorspace) PdfColorspace {
+	loaded, err := NewPdfColorspaceFromPdfObject(cs.ToPdfObject())
+	require.NoError(t, err)
+	return loaded
+}
+
+func TestSeparationSpot(t *testing.T) {
+	_, err := NewPdfColorspaceSpecialSeparationSpot("Spot", NewPdfColorspaceDeviceCMYK(), []float64{1})
+	require.Error(t, err)
+
+	spot, err := NewPdfColorspaceSpecialSeparationSpot("PANTONE 185 C", NewPdfColorspaceDeviceCMYK(), []float64{0, 1, 0.8, 0})
+	require.NoError(t, err)
+	cs := reloadColorspace(t, spot).(*PdfColorspaceSpecialSeparation)
+	require.Equal(t, "PANTONE 185 C", cs.ColorantName.String())
+
+	color, err := cs.ColorFromFloats([]float64{0.5})
+	require.NoError(t, err)
+	require.Equal(t, NewPdfColorDeviceCMYK(0, 0.5, 0.4, 0), color)
+
+	// 1 bit image with inverted decode and padded rows.
+	rgbSpot, err := NewPdfColorspaceSpecialSeparationSpot("Red", NewPdfColorspaceDeviceRGB(), []float64{1, 0, 0})
+	require.NoError(t, err)
+	img := Image{
+		Width:            3,
+		Height:           2,
+		BitsPerComponent: 1,
+		ColorComponents:  1,
+		Data:             []byte{0x40, 0xa0},
+		decode:           []float64{1, 0},
+	}
+	rgb, err := rgbSpot.ImageToRGB(img)
+	require.NoError(t, err)
+	require.Equal(t, int64(8), rgb.BitsPerComponent)
+	require.Equal(t, 3, rgb.ColorComponents)
+	require.Equal(t, []byte{
+		255, 0, 0, 255, 255, 255, 255, 0, 0,
+		255, 255, 255, 255, 0, 0, 255, 255, 255,
+	}, rgb.Data)
+}
+
+func TestDeviceNColorants(t *testing.T) {
+	alternate := NewPdfColorspaceDeviceCMYK()
+	colors := [][]float64{{0, 1, 0.8, 0}, {0.6, 0, 0, 0.2}}
+	devn, err := NewPdfColorspaceDeviceNFromColorants([]string{"Red", "Gray"}, alternate, colors)
+	require.NoError(t, err)
+	cs := reloadColorspace(t, devn).(*PdfColorspaceDeviceN)
+	require.Equal(t, 2, cs.GetNumComponents())
+
+	for _, tc := range []struct {
+		tints    []float64
+		expected []float64
+	}{
+		{[]float64{0, 0}, []float64{0, 0, 0, 0}},
+		{[]float64{1, 0}, []float64{0, 1, 0.8, 0}},
+		{[]float64{0.5, 0.5}, []float64{0.3, 0.5, 0.4, 0.1}},
+		{[]float64{1, 1}, []float64{0.6, 1, 0.8, 0.2}},
+	} {
+		color, err := cs.ColorFromFloats(tc.tints)
+		require.NoError(t, err)
+		cmyk := color.(*PdfColorDeviceCMYK)
+		require.InDeltaSlice(t, tc.expected, []float64{cmyk.C(), cmyk.M(), cmyk.Y(), cmyk.K()}, 1e-9)
+	}
+
+	// Additive alternate colorspaces are clipped.
+	devn, err = NewPdfColorspaceDeviceNFromColorants([]string{"A", "B"}, NewPdfColorspaceDeviceGray(), [][]float64{{0}, {0.2}})
+	require.NoError(t, err)
+	color, err := devn.ColorFromFloats([]float64{1, 1})
+	require.NoError(t, err)
+	require.Equal(t, NewPdfColorDeviceGray(0), color)
+
+	img := Image{
+		Width:            2,
+		Height:           1,
+		BitsPerComponent: 8,
+		ColorComponents:  2,
+		Data:             []byte{0, 0, 255, 0},
+	}
+	rgb, err := cs.ImageToRGB(img)
+	require.NoError(t, err)
+	require.Equal(t, 3, rgb.ColorComponents)
+	require.Equal(t, []byte{255, 255, 255, 255, 0, 50}, rgb.Data)
+
+	_, err = NewPdfColorspaceDeviceNFromColorants([]string{"Red"}, alternate, [][]float64{{1}})
+	require.Error(t, err)
+}
+
+func TestIndexedFromColors(t *testing.T) {
+	_, err := NewPdfColorspaceSpecialIndexedFromColors(NewPdfColorspaceDeviceRGB(), [][]float64{{1}})
+	require.Error(t, err)
+
+	colors := [][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {1, 1, 1}}
+	indexed, err := NewPdfColorspaceSpecialIndexedFromColors(NewPdfColorspaceDeviceRGB(), colors)
+	require.NoError(t, err)
+	cs := reloadColorspace(t, indexed).(*PdfColorspaceSpecialIndexed)
+	require.Equal(t, 3, cs.HiVal)
+
+	color, err := cs.ColorFromFloats([]float64{1})
+	require.NoError(t, err)
+	require.Equal(t, NewPdfColorDeviceRGB(0, 1, 0), color)
+
+	// 2 bit image with padded rows.
+	img := Image{
+		Width:            3,
+		Height:           2,
+		BitsPerComponent: 2,
+		ColorComponents:  1,
+		Data:             []byte{0x1c, 0xe4},
+	}
+	rgb, err := cs.ImageToRGB(img)
+	require.NoError(t, err)
+	require.Equal(t, []byte{
+		255, 0, 0, 0, 255, 0, 255, 255, 255,
+		255, 255, 255, 0, 0, 255, 0, 255, 0,
+	}, rgb.Data)
+
+	// Lab base colorspace.
+	lab := NewPdfColorspaceLab()
+	lab.Range = []float64{-100, 100, -100, 100}
+	indexed, err = NewPdfColorspaceSpecialIndexedFromColors(lab, [][]float64{{100, 0, 0}, {50, -100, 100}})
+	require.NoError(t, err)
+	color, err = indexed.ColorFromFloats([]float64{1})
+	require.NoError(t, err)
+	labColor := color.(*PdfColorLab)
+	require.InDeltaSlice(t, []float64{50, -100, 100}, []float64{labColor.L(), labColor.A(), labColor.B()}, 0.5)
+}
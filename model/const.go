@@ -7,7 +7,8 @@ package model
 
 import (
 	"errors"
-	"fmt"
+
+	"golang.org/x/xerrors"
 
 	"github.com/unidoc/unipdf/v3/core"
 )
@@ -21,8 +22,8 @@ var (
 	errRangeError               = errors.New("range check error")
 	ErrEncrypted                = errors.New("file needs to be decrypted first")
 	ErrNoFont                   = errors.New("font not defined")
-	ErrFontNotSupported         = fmt.Errorf("unsupported font (%v)", core.ErrNotSupported)
-	ErrType1CFontNotSupported   = fmt.Errorf("Type1C fonts are not currently supported (%v)", core.ErrNotSupported)
-	ErrType3FontNotSupported    = fmt.Errorf("Type3 fonts are not currently supported (%v)", core.ErrNotSupported)
-	ErrTTCmapNotSupported       = fmt.Errorf("unsupported TrueType cmap format (%v)", core.ErrNotSupported)
+	ErrFontNotSupported         = xerrors.Errorf("unsupported font: %w", core.ErrNotSupported)
+	ErrType1CFontNotSupported   = xerrors.Errorf("Type1C fonts are not currently supported: %w", core.ErrNotSupported)
+	ErrType3FontNotSupported    = xerrors.Errorf("Type3 fonts are not currently supported: %w", core.ErrNotSupported)
+	ErrTTCmapNotSupported       = xerrors.Errorf("unsupported TrueType cmap format: %w", core.ErrNotSupported)
 )
@@ -0,0 +1,422 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// PdfDocumentActions represents the additional actions of a document,
+// performed on document level trigger events (AA entry of the catalog).
+// See section 12.6.3 "Trigger Events" and Table 197 (p. 417 PDF32000_2008).
+type PdfDocumentActions struct {
+	// WillClose is performed before closing the document.
+	WillClose *PdfAction
+
+	// WillSave is performed before saving the document.
+	WillSave *PdfAction
+
+	// DidSave is performed after saving the document.
+	DidSave *PdfAction
+
+	// WillPrint is performed before printing the document.
+	WillPrint *PdfAction
+
+	// DidPrint is performed after printing the document.
+	DidPrint *PdfAction
+}
+
+// ToPdfObject returns the additional-actions dictionary of the document
+// actions.
+func (a *PdfDocumentActions) ToPdfObject() core.PdfObject {
+	d := core.MakeDict()
+	setAction(d, "WC", a.WillClose)
+	setAction(d, "WS", a.WillSave)
+	setAction(d, "DS", a.DidSave)
+	setAction(d, "WP", a.WillPrint)
+	setAction(d, "DP", a.DidPrint)
+	return d
+}
+
+// PdfPageActions represents the additional actions of a page, performed when
+// the page is opened or closed (AA entry of the page dictionary).
+// See section 12.6.3 "Trigger Events" and Table 195 (p. 416 PDF32000_2008).
+type PdfPageActions struct {
+	// Open is performed when the page is opened.
+	Open *PdfAction
+
+	// Close is performed when the page is closed.
+	Close *PdfAction
+}
+
+// ToPdfObject returns the additional-actions dictionary of the page actions.
+func (a *PdfPageActions) ToPdfObject() core.PdfObject {
+	d := core.MakeDict()
+	setAction(d, "O", a.Open)
+	setAction(d, "C", a.Close)
+	return d
+}
+
+// DocumentJavaScript represents a document level JavaScript, i.e. an entry of
+// the JavaScript name tree of the document, executed when the document is
+// opened. See section 12.6.4.16 "JavaScript Actions" (p. 436 PDF32000_2008).
+type DocumentJavaScript struct {
+	// Name is the key of the script in the name tree.
+	Name string
+
+	// Script is the JavaScript code.
+	Script string
+}
+
+// NewPdfActionJavaScriptFromScript returns a new JavaScript action executing
+// `script`.
+func NewPdfActionJavaScriptFromScript(script string) *PdfAction {
+	action := NewPdfActionJavaScript()
+	action.JS = makeTextString(script)
+	return action.PdfAction
+}
+
+// JavaScript returns the script executed by the action if it is a JavaScript
+// action, and false otherwise.
+func (a *PdfAction) JavaScript() (string, bool) {
+	js, ok := a.GetContext().(*PdfActionJavaScript)
+	if !ok {
+		return "", false
+	}
+	script, err := javaScriptText(js.JS)
+	if err != nil {
+		common.Log.Debug("ERROR: invalid JavaScript: %v", err)
+		return "", false
+	}
+	return script, true
+}
+
+// javaScriptText returns the script contained in `obj`, a text string or a
+// text stream.
+func javaScriptText(obj core.PdfObject) (string, error) {
+	switch t := core.TraceToDirectObject(obj).(type) {
+	case *core.PdfObjectString:
+		return t.Decoded(), nil
+	case *core.PdfObjectStream:
+		data, err := core.DecodeStream(t)
+		if err != nil {
+			return "", err
+		}
+		return core.MakeStringFromBytes(data).Decoded(), nil
+	case nil, *core.PdfObjectNull:
+		return "", nil
+	}
+	return "", fmt.Errorf("invalid JavaScript object type: %T", obj)
+}
+
+// actionObject returns the PDF object of `action`, including the entries of
+// its action type.
+func actionObject(action *PdfAction) core.PdfObject {
+	if ctx := action.GetContext(); ctx != nil {
+		return ctx.ToPdfObject()
+	}
+	return action.ToPdfObject()
+}
+
+// setAction sets the entry `key` of `d` to `action`, if not nil.
+func setAction(d *core.PdfObjectDictionary, key core.PdfObjectName, action *PdfAction) {
+	if action != nil {
+		d.Set(key, actionObject(action))
+	}
+}
+
+// actionReader returns `r`, or a reader without source document that can be
+// used for loading the actions of new objects if `r` is nil.
+func actionReader(r *PdfReader) *PdfReader {
+	if r != nil {
+		return r
+	}
+	return &PdfReader{
+		traversed:    map[core.PdfObject]struct{}{},
+		modelManager: newModelManager(),
+	}
+}
+
+// loadActions loads the actions of the additional-actions dictionary `obj`
+// and calls `fn` with the key and action of each entry.
+func (r *PdfReader) loadActions(obj core.PdfObject, fn func(key core.PdfObjectName, action *PdfAction)) error {
+	d, ok := core.GetDict(obj)
+	if !ok {
+		return nil
+	}
+	for _, key := range d.Keys() {
+		action, err := r.loadAction(d.Get(key))
+		if err != nil {
+			return err
+		}
+		if action != nil {
+			fn(key, action)
+		}
+	}
+	return nil
+}
+
+// GetOpenAction returns the action performed when the document is opened
+// (OpenAction entry of the catalog), or nil if not specified. An open
+// destination is returned as a GoTo action to the destination.
+func (r *PdfReader) GetOpenAction() (*PdfAction, error) {
+	obj := core.ResolveReference(r.catalog.Get("OpenAction"))
+	if obj == nil || core.IsNullObject(obj) {
+		return nil, nil
+	}
+	if !r.isLazy {
+		if err := r.traverseObjectData(obj); err != nil {
+			return nil, err
+		}
+	}
+
+	if arr, ok := core.GetArray(obj); ok {
+		action := NewPdfActionGoTo()
+		action.D = arr
+		return action.PdfAction, nil
+	}
+	return r.loadAction(obj)
+}
+
+// GetDocumentActions returns the additional actions of the document, or nil
+// if not specified.
+func (r *PdfReader) GetDocumentActions() (*PdfDocumentActions, error) {
+	obj := core.ResolveReference(r.catalog.Get("AA"))
+	if obj == nil || core.IsNullObject(obj) {
+		return nil, nil
+	}
+	if !r.isLazy {
+		if err := r.traverseObjectData(obj); err != nil {
+			return nil, err
+		}
+	}
+
+	actions := &PdfDocumentActions{}
+	err := r.loadActions(obj, func(key core.PdfObjectName, action *PdfAction) {
+		switch key {
+		case "WC":
+			actions.WillClose = action
+		case "WS":
+			actions.WillSave = action
+		case "DS":
+			actions.DidSave = action
+		case "WP":
+			actions.WillPrint = action
+		case "DP":
+			actions.DidPrint = action
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+// GetJavaScripts returns the document level JavaScripts of the document,
+// in the order of the JavaScript name tree.
+func (r *PdfReader) GetJavaScripts() ([]*DocumentJavaScript, error) {
+	names, ok := core.GetDict(r.catalog.Get("Names"))
+	if !ok {
+		return nil, nil
+	}
+
+	var scripts []*DocumentJavaScript
+	var lastErr error
+	nameTreeEntries(names.Get("JavaScript"), 0, func(key string, obj core.PdfObject) {
+		if !r.isLazy {
+			if err := r.traverseObjectData(obj); err != nil {
+				lastErr = err
+				return
+			}
+		}
+
+		action, err := r.loadAction(obj)
+		if err != nil || action == nil {
+			common.Log.Debug("ERROR: invalid JavaScript action %s: %v", key, err)
+			return
+		}
+		script, ok := action.JavaScript()
+		if !ok {
+			common.Log.Debug("ERROR: %s is not a JavaScript action", key)
+			return
+		}
+		scripts = append(scripts, &DocumentJavaScript{Name: key, Script: script})
+	})
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return scripts, nil
+}
+
+// GetActions returns the additional actions of the page, or nil if not
+// specified.
+func (p *PdfPage) GetActions() (*PdfPageActions, error) {
+	if p.AA == nil {
+		return nil, nil
+	}
+
+	actions := &PdfPageActions{}
+	err := actionReader(p.reader).loadActions(p.AA, func(key core.PdfObjectName, action *PdfAction) {
+		switch key {
+		case "O":
+			actions.Open = action
+		case "C":
+			actions.Close = action
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+// SetActions sets the additional actions of the page. The additional actions
+// are removed if `actions` is nil.
+func (p *PdfPage) SetActions(actions *PdfPageActions) {
+	if actions == nil {
+		p.AA = nil
+		return
+	}
+	p.AA = actions.ToPdfObject()
+}
+
+// javaScriptNameTree returns the JavaScript name tree containing `scripts`.
+// The entries are sorted by name, as required for name trees.
+func javaScriptNameTree(scripts []*DocumentJavaScript) (core.PdfObject, error) {
+	for _, script := range scripts {
+		if script == nil {
+			return nil, errors.New("JavaScript cannot be nil")
+		}
+	}
+	sorted := make([]*DocumentJavaScript, len(scripts))
+	copy(sorted, scripts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	names := core.MakeArray()
+	for i, script := range sorted {
+		if i > 0 && script.Name == sorted[i-1].Name {
+			return nil, fmt.Errorf("duplicate JavaScript name: %s", script.Name)
+		}
+		action := NewPdfActionJavaScriptFromScript(script.Script)
+		names.Append(core.MakeEncodedString(script.Name, true), actionObject(action))
+	}
+
+	tree := core.MakeDict()
+	tree.Set("Names", names)
+	return tree, nil
+}
+
+// namesWithJavaScripts returns a copy of the names dictionary `names`, which
+// can be nil, with its JavaScript name tree replaced by the one containing
+// `scripts`, or removed if `scripts` is empty.
+func namesWithJavaScripts(names core.PdfObject, scripts []*DocumentJavaScript) (*core.PdfObjectDictionary, error) {
+	d := core.MakeDict()
+	if orig, ok := core.GetDict(names); ok {
+		d.Merge(orig)
+	}
+	if len(scripts) == 0 {
+		d.Remove("JavaScript")
+		return d, nil
+	}
+
+	tree, err := javaScriptNameTree(scripts)
+	if err != nil {
+		return nil, err
+	}
+	d.Set("JavaScript", tree)
+	return d, nil
+}
+
+// SetOpenAction sets the action performed when the document is opened. The
+// open action is removed if `action` is nil.
+func (w *PdfWriter) SetOpenAction(action *PdfAction) error {
+	if action == nil {
+		w.catalog.Remove("OpenAction")
+		return nil
+	}
+
+	obj := actionObject(action)
+	w.catalog.Set("OpenAction", obj)
+	return w.addObjects(obj)
+}
+
+// SetDocumentActions sets the additional actions of the document. The
+// additional actions are removed if `actions` is nil.
+func (w *PdfWriter) SetDocumentActions(actions *PdfDocumentActions) error {
+	if actions == nil {
+		w.catalog.Remove("AA")
+		return nil
+	}
+
+	obj := actions.ToPdfObject()
+	w.catalog.Set("AA", obj)
+	return w.addObjects(obj)
+}
+
+// SetJavaScripts sets the document level JavaScripts of the document, in the
+// JavaScript name tree of the Names entry of the catalog. The other name trees
+// of the Names entry are kept.
+func (w *PdfWriter) SetJavaScripts(scripts []*DocumentJavaScript) error {
+	names, err := namesWithJavaScripts(w.catalog.Get("Names"), scripts)
+	if err != nil {
+		return err
+	}
+
+	common.Log.Trace("Setting catalog JavaScript...")
+	w.catalog.Set("Names", names)
+	return w.addObjects(names)
+}
+
+// setCatalogEntry sets the catalog entry `key` to `obj` in the new revision,
+// or removes the entry if `obj` is nil.
+func (a *PdfAppender) setCatalogEntry(key core.PdfObjectName, obj core.PdfObject) {
+	if a.catalogUpdates == nil {
+		a.catalogUpdates = core.MakeDict()
+	}
+	if obj == nil {
+		obj = core.MakeNull()
+	}
+	a.catalogUpdates.Set(key, obj)
+}
+
+// SetOpenAction sets the action performed when the document is opened in the
+// new revision. The open action is removed if `action` is nil.
+func (a *PdfAppender) SetOpenAction(action *PdfAction) {
+	if action == nil {
+		a.setCatalogEntry("OpenAction", nil)
+		return
+	}
+	a.setCatalogEntry("OpenAction", actionObject(action))
+}
+
+// SetDocumentActions sets the additional actions of the document in the new
+// revision. The additional actions are removed if `actions` is nil.
+func (a *PdfAppender) SetDocumentActions(actions *PdfDocumentActions) {
+	if actions == nil {
+		a.setCatalogEntry("AA", nil)
+		return
+	}
+	a.setCatalogEntry("AA", actions.ToPdfObject())
+}
+
+// SetJavaScripts sets the document level JavaScripts of the document in the
+// new revision. The other name trees of the Names entry of the catalog are
+// kept.
+func (a *PdfAppender) SetJavaScripts(scripts []*DocumentJavaScript) error {
+	names, err := namesWithJavaScripts(a.roReader.catalog.Get("Names"), scripts)
+	if err != nil {
+		return err
+	}
+	a.setCatalogEntry("Names", names)
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestDocumentActions(t *testing.T) {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+	page.SetActions(&PdfPageActions{Open: NewPdfActionJavaScriptFromScript("app.alert('open');")})
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	require.NoError(t, w.SetOpenAction(NewPdfActionJavaScriptFromScript("this.zoom = 100;")))
+	require.NoError(t, w.SetDocumentActions(&PdfDocumentActions{
+		WillPrint: NewPdfActionJavaScriptFromScript("console.println('print');"),
+	}))
+	require.NoError(t, w.SetJavaScripts([]*DocumentJavaScript{
+		{Name: "init", Script: "var x = 1;"},
+		{Name: "helpers", Script: "function f() { return 'é'; }"},
+	}))
+	require.Error(t, w.SetJavaScripts([]*DocumentJavaScript{{Name: "a"}, {Name: "a"}}))
+	require.Error(t, w.SetJavaScripts([]*DocumentJavaScript{{Name: "b"}, nil, {Name: "a"}}))
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	openAction, err := r.GetOpenAction()
+	require.NoError(t, err)
+	require.NotNil(t, openAction)
+	script, ok := openAction.JavaScript()
+	require.True(t, ok)
+	require.Equal(t, "this.zoom = 100;", script)
+
+	docActions, err := r.GetDocumentActions()
+	require.NoError(t, err)
+	require.NotNil(t, docActions.WillPrint)
+	require.Nil(t, docActions.WillClose)
+	script, ok = docActions.WillPrint.JavaScript()
+	require.True(t, ok)
+	require.Equal(t, "console.println('print');", script)
+
+	scripts, err := r.GetJavaScripts()
+	require.NoError(t, err)
+	require.Equal(t, []*DocumentJavaScript{
+		{Name: "helpers", Script: "function f() { return 'é'; }"},
+		{Name: "init", Script: "var x = 1;"},
+	}, scripts)
+
+	readPage, err := r.GetPage(1)
+	require.NoError(t, err)
+	pageActions, err := readPage.GetActions()
+	require.NoError(t, err)
+	require.NotNil(t, pageActions.Open)
+	require.Nil(t, pageActions.Close)
+	script, ok = pageActions.Open.JavaScript()
+	require.True(t, ok)
+	require.Equal(t, "app.alert('open');", script)
+
+	// Replace the document level actions in an incremental update.
+	a, err := NewPdfAppender(r)
+	require.NoError(t, err)
+	a.SetOpenAction(nil)
+	require.NoError(t, a.SetJavaScripts([]*DocumentJavaScript{{Name: "main", Script: "main();"}}))
+	require.True(t, a.HasChanges())
+
+	var out bytes.Buffer
+	require.NoError(t, a.Write(&out))
+	r, err = NewPdfReader(bytes.NewReader(out.Bytes()))
+	require.NoError(t, err)
+
+	openAction, err = r.GetOpenAction()
+	require.NoError(t, err)
+	require.Nil(t, openAction)
+	scripts, err = r.GetJavaScripts()
+	require.NoError(t, err)
+	require.Equal(t, []*DocumentJavaScript{{Name: "main", Script: "main();"}}, scripts)
+	docActions, err = r.GetDocumentActions()
+	require.NoError(t, err)
+	require.NotNil(t, docActions.WillPrint)
+}
+
+func TestOpenActionDestination(t *testing.T) {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	dest := core.MakeArray(core.MakeInteger(0), core.MakeName("Fit"))
+	w.catalog.Set("OpenAction", dest)
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	action, err := r.GetOpenAction()
+	require.NoError(t, err)
+	goTo, ok := action.GetContext().(*PdfActionGoTo)
+	require.True(t, ok)
+	arr, ok := core.GetArray(goTo.D)
+	require.True(t, ok)
+	require.Equal(t, 2, arr.Len())
+}
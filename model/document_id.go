@@ -0,0 +1,123 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// DocumentIDPolicy specifies how the file identifiers of the ID entry of the
+// trailer are set when a document is written (14.4 - p. 559). The first
+// identifier is the permanent identifier of the document and the second one
+// identifies its revision. Signature and document management systems rely on
+// the first identifier being kept when a document is modified.
+type DocumentIDPolicy int
+
+// Document ID policies.
+const (
+	// DocumentIDKeep writes the identifiers unchanged: the identifiers set by
+	// SetDocumentID or Encrypt, or the identifiers of the original document
+	// in append mode. No identifiers are written if none is set.
+	DocumentIDKeep DocumentIDPolicy = iota
+
+	// DocumentIDRefreshSecond keeps the first identifier and generates a new
+	// second identifier. Both identifiers are generated if none is set.
+	DocumentIDRefreshSecond
+
+	// DocumentIDNew generates new identifiers, e.g. for documents derived
+	// from a template. The first identifier of encrypted documents is kept,
+	// as it is used by the encryption key.
+	DocumentIDNew
+)
+
+// GetDocumentID returns the file identifiers of the ID entry of the trailer.
+// Returns false if the document has no valid ID entry.
+func (r *PdfReader) GetDocumentID() (id0, id1 []byte, ok bool) {
+	trailer, err := r.GetTrailer()
+	if err != nil {
+		return nil, nil, false
+	}
+	return getDocumentID(trailer.Get("ID"))
+}
+
+// getDocumentID returns the file identifiers of the ID array `obj`.
+func getDocumentID(obj core.PdfObject) (id0, id1 []byte, ok bool) {
+	ids, ok := core.GetArray(obj)
+	if !ok || ids.Len() != 2 {
+		return nil, nil, false
+	}
+	s0, ok0 := core.GetString(ids.Get(0))
+	s1, ok1 := core.GetString(ids.Get(1))
+	if !ok0 || !ok1 {
+		common.Log.Debug("ERROR: invalid trailer ID: %v", ids)
+		return nil, nil, false
+	}
+	return s0.Bytes(), s1.Bytes(), true
+}
+
+// GetDocumentID returns the file identifiers written in the ID entry of the
+// trailer. After writing, these are the identifiers set according to the
+// document ID policy. Returns false if no identifiers are set.
+func (w *PdfWriter) GetDocumentID() (id0, id1 []byte, ok bool) {
+	return getDocumentID(w.ids)
+}
+
+// SetDocumentIDPolicy sets the policy applied to the file identifiers when
+// the document is written. Defaults to DocumentIDKeep.
+func (w *PdfWriter) SetDocumentIDPolicy(policy DocumentIDPolicy) {
+	w.idPolicy = policy
+}
+
+// applyDocumentIDPolicy sets the file identifiers according to the document
+// ID policy.
+func (w *PdfWriter) applyDocumentIDPolicy() {
+	id0, _, ok := w.GetDocumentID()
+	switch w.idPolicy {
+	case DocumentIDRefreshSecond:
+		if !ok {
+			id0 = w.newDocumentID()
+		}
+	case DocumentIDNew:
+		if !ok || w.crypter == nil {
+			id0 = w.newDocumentID()
+		}
+	default:
+		return
+	}
+	w.SetDocumentID(id0, w.newDocumentID())
+}
+
+// newDocumentID returns a new file identifier, computed from the current
+// time, the document information dictionary and random data. In the
+// deterministic output mode, the identifier of the options is returned.
+func (w *PdfWriter) newDocumentID() []byte {
+	if w.deterministic != nil {
+		if len(w.deterministic.ID) > 0 {
+			return w.deterministic.ID
+		}
+		return make([]byte, 16)
+	}
+
+	h := md5.New()
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(time.Now().UnixNano()))
+	h.Write(buf[:])
+	if w.infoObj != nil {
+		h.Write([]byte(w.infoObj.PdfObject.WriteString()))
+	}
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		common.Log.Debug("ERROR: unable to read random data: %v", err)
+	}
+	h.Write(random)
+	return h.Sum(nil)
+}
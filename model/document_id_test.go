@@ -0,0 +1,107 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentIDPolicy(t *testing.T) {
+	write := func(w *PdfWriter) *PdfReader {
+		var buf bytes.Buffer
+		require.NoError(t, w.Write(&buf))
+		r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		return r
+	}
+	newWriter := func() *PdfWriter {
+		w := NewPdfWriter()
+		require.NoError(t, w.AddPage(NewPdfPage()))
+		return &w
+	}
+
+	// No identifiers are written by default.
+	r := write(newWriter())
+	_, _, ok := r.GetDocumentID()
+	require.False(t, ok)
+
+	w := newWriter()
+	w.SetDocumentID([]byte("first"), []byte("second"))
+	r = write(w)
+	id0, id1, ok := r.GetDocumentID()
+	require.True(t, ok)
+	require.Equal(t, []byte("first"), id0)
+	require.Equal(t, []byte("second"), id1)
+
+	w = newWriter()
+	w.SetDocumentID([]byte("first"), []byte("second"))
+	w.SetDocumentIDPolicy(DocumentIDRefreshSecond)
+	r = write(w)
+	id0, id1, ok = r.GetDocumentID()
+	require.True(t, ok)
+	require.Equal(t, []byte("first"), id0)
+	require.Len(t, id1, 16)
+	wid0, wid1, ok := w.GetDocumentID()
+	require.True(t, ok)
+	require.Equal(t, id0, wid0)
+	require.Equal(t, id1, wid1)
+
+	w = newWriter()
+	w.SetDocumentIDPolicy(DocumentIDRefreshSecond)
+	r = write(w)
+	id0, id1, ok = r.GetDocumentID()
+	require.True(t, ok)
+	require.Len(t, id0, 16)
+	require.Len(t, id1, 16)
+	require.NotEqual(t, id0, id1)
+
+	w = newWriter()
+	w.SetDocumentID([]byte("first"), []byte("second"))
+	w.SetDocumentIDPolicy(DocumentIDNew)
+	r = write(w)
+	id0, _, ok = r.GetDocumentID()
+	require.True(t, ok)
+	require.Len(t, id0, 16)
+}
+
+func TestAppenderDocumentID(t *testing.T) {
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(NewPdfPage()))
+	w.SetDocumentID([]byte("first"), []byte("second"))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	appendPage := func(policy *DocumentIDPolicy) *PdfReader {
+		r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		a, err := NewPdfAppender(r)
+		require.NoError(t, err)
+		if policy != nil {
+			a.SetDocumentIDPolicy(*policy)
+		}
+		a.AddPages(NewPdfPage())
+		var out bytes.Buffer
+		require.NoError(t, a.Write(&out))
+		r, err = NewPdfReader(bytes.NewReader(out.Bytes()))
+		require.NoError(t, err)
+		return r
+	}
+
+	// The identifiers are kept by default.
+	id0, id1, ok := appendPage(nil).GetDocumentID()
+	require.True(t, ok)
+	require.Equal(t, []byte("first"), id0)
+	require.Equal(t, []byte("second"), id1)
+
+	refresh := DocumentIDRefreshSecond
+	id0, id1, ok = appendPage(&refresh).GetDocumentID()
+	require.True(t, ok)
+	require.Equal(t, []byte("first"), id0)
+	require.NotEqual(t, []byte("second"), id1)
+}
@@ -0,0 +1,57 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// FontError is the error returned when a font cannot be loaded. It wraps the underlying error and
+// can be checked with errors.As (or xerrors.As). The underlying error wraps core.ErrNotSupported
+// for valid fonts which are not supported, e.g. ErrType3FontNotSupported, and is a
+// *core.ParseError if the font data could not be parsed.
+type FontError struct {
+	// ObjNum is the number of the font object, 0 if the font is a direct object.
+	ObjNum int64
+
+	// Subtype is the subtype of the font, empty if unknown.
+	Subtype string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *FontError) Error() string {
+	s := "font error"
+	if e.Subtype != "" {
+		s += " (" + e.Subtype + ")"
+	}
+	if e.ObjNum > 0 {
+		s += fmt.Sprintf(" in object %d", e.ObjNum)
+	}
+	return s + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *FontError) Unwrap() error {
+	return e.Err
+}
+
+// newFontError returns a FontError wrapping the error `err` which occurred when loading the font
+// `fontObj`.
+func newFontError(fontObj core.PdfObject, err error) *FontError {
+	fontErr := &FontError{Err: err}
+	if ind, ok := fontObj.(*core.PdfIndirectObject); ok {
+		fontErr.ObjNum = ind.ObjectNumber
+	}
+	if d, ok := core.GetDict(fontObj); ok {
+		fontErr.Subtype, _ = core.GetNameVal(d.Get("Subtype"))
+	}
+	return fontErr
+}
@@ -0,0 +1,232 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// PdfBlendMode represents a blend mode, specifying how the colors of the
+// painted objects are combined with the colors of the backdrop.
+// See section 11.3.5 "Blend Mode" (p. 320 PDF32000_2008).
+type PdfBlendMode string
+
+// Blend modes (Tables 136 and 137 - pp. 323-327).
+const (
+	PdfBlendModeNormal     PdfBlendMode = "Normal"
+	PdfBlendModeMultiply   PdfBlendMode = "Multiply"
+	PdfBlendModeScreen     PdfBlendMode = "Screen"
+	PdfBlendModeOverlay    PdfBlendMode = "Overlay"
+	PdfBlendModeDarken     PdfBlendMode = "Darken"
+	PdfBlendModeLighten    PdfBlendMode = "Lighten"
+	PdfBlendModeColorDodge PdfBlendMode = "ColorDodge"
+	PdfBlendModeColorBurn  PdfBlendMode = "ColorBurn"
+	PdfBlendModeHardLight  PdfBlendMode = "HardLight"
+	PdfBlendModeSoftLight  PdfBlendMode = "SoftLight"
+	PdfBlendModeDifference PdfBlendMode = "Difference"
+	PdfBlendModeExclusion  PdfBlendMode = "Exclusion"
+	PdfBlendModeHue        PdfBlendMode = "Hue"
+	PdfBlendModeSaturation PdfBlendMode = "Saturation"
+	PdfBlendModeColor      PdfBlendMode = "Color"
+	PdfBlendModeLuminosity PdfBlendMode = "Luminosity"
+)
+
+// PdfDashPattern represents a line dash pattern (8.4.3.6 - p. 127).
+type PdfDashPattern struct {
+	// Array contains the lengths of the alternating dashes and gaps. The
+	// line is solid if it is empty.
+	Array []float64
+
+	// Phase is the distance into the pattern at which the dashes start.
+	Phase float64
+}
+
+// PdfExtGState represents a graphics state parameter dictionary, set by the
+// gs operator of content streams (Table 58 - p. 128). The parameters which
+// are not set (nil pointers or empty values) are left unchanged by the
+// graphics state. The entries of loaded dictionaries which are not modeled
+// are kept when the dictionary is written.
+type PdfExtGState struct {
+	LW *float64        // Line width.
+	LC *int64          // Line cap style.
+	LJ *int64          // Line join style.
+	ML *float64        // Miter limit.
+	D  *PdfDashPattern // Line dash pattern.
+
+	// Font is the font of the text state, with size FontSize.
+	Font     *PdfFont
+	FontSize float64
+
+	// BM is the blend mode.
+	BM PdfBlendMode
+
+	// SMask is the soft mask. SMaskNone resets the soft mask when SMask is
+	// nil.
+	SMask     *PdfSoftMask
+	SMaskNone bool
+
+	CA  *float64 // Stroking alpha constant.
+	Ca  *float64 // Nonstroking alpha constant (ca).
+	AIS *bool    // Alpha source flag: the alpha constants and soft masks are shapes.
+
+	primitive *core.PdfObjectDictionary
+}
+
+// NewPdfExtGState returns a new graphics state parameter dictionary which
+// does not set any parameter.
+func NewPdfExtGState() *PdfExtGState {
+	return &PdfExtGState{}
+}
+
+// NewPdfExtGStateFromPdfObject loads a graphics state parameter dictionary
+// from `obj`.
+func NewPdfExtGStateFromPdfObject(obj core.PdfObject) (*PdfExtGState, error) {
+	dict, ok := core.GetDict(obj)
+	if !ok {
+		return nil, fmt.Errorf("ExtGState not a dictionary (%T)", obj)
+	}
+
+	gs := &PdfExtGState{primitive: dict}
+	if val, err := core.GetNumberAsFloat(core.TraceToDirectObject(dict.Get("LW"))); err == nil {
+		gs.LW = &val
+	}
+	if val, ok := core.GetIntVal(dict.Get("LC")); ok {
+		lc := int64(val)
+		gs.LC = &lc
+	}
+	if val, ok := core.GetIntVal(dict.Get("LJ")); ok {
+		lj := int64(val)
+		gs.LJ = &lj
+	}
+	if val, err := core.GetNumberAsFloat(core.TraceToDirectObject(dict.Get("ML"))); err == nil {
+		gs.ML = &val
+	}
+	if arr, ok := core.GetArray(dict.Get("D")); ok && arr.Len() == 2 {
+		dashes, ok := core.GetArray(arr.Get(0))
+		if !ok {
+			return nil, fmt.Errorf("invalid ExtGState dash array (%T)", arr.Get(0))
+		}
+		array, err := dashes.ToFloat64Array()
+		if err != nil {
+			return nil, err
+		}
+		phase, err := core.GetNumberAsFloat(core.TraceToDirectObject(arr.Get(1)))
+		if err != nil {
+			return nil, err
+		}
+		gs.D = &PdfDashPattern{Array: array, Phase: phase}
+	}
+	if arr, ok := core.GetArray(dict.Get("Font")); ok && arr.Len() == 2 {
+		font, err := NewPdfFontFromPdfObject(arr.Get(0))
+		if err != nil {
+			common.Log.Debug("ERROR: invalid ExtGState font: %v", err)
+			return nil, err
+		}
+		size, err := core.GetNumberAsFloat(core.TraceToDirectObject(arr.Get(1)))
+		if err != nil {
+			return nil, err
+		}
+		gs.Font = font
+		gs.FontSize = size
+	}
+
+	// The blend mode can be an array of blend modes, of which the first
+	// supported one is used: all the standard blend modes are supported.
+	bm := dict.Get("BM")
+	if arr, ok := core.GetArray(bm); ok && arr.Len() > 0 {
+		bm = arr.Get(0)
+	}
+	if name, ok := core.GetNameVal(bm); ok {
+		gs.BM = PdfBlendMode(name)
+	}
+
+	if obj := dict.Get("SMask"); obj != nil {
+		mask, err := NewPdfSoftMaskFromPdfObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		gs.SMask = mask
+		gs.SMaskNone = mask == nil
+	}
+	if val, err := core.GetNumberAsFloat(core.TraceToDirectObject(dict.Get("CA"))); err == nil {
+		gs.CA = &val
+	}
+	if val, err := core.GetNumberAsFloat(core.TraceToDirectObject(dict.Get("ca"))); err == nil {
+		gs.Ca = &val
+	}
+	if val, ok := core.GetBoolVal(dict.Get("AIS")); ok {
+		gs.AIS = &val
+	}
+	return gs, nil
+}
+
+// ToPdfObject returns the graphics state parameter dictionary.
+func (gs *PdfExtGState) ToPdfObject() core.PdfObject {
+	dict := core.MakeDict()
+	if gs.primitive != nil {
+		dict.Merge(gs.primitive)
+		for _, key := range []core.PdfObjectName{"LW", "LC", "LJ", "ML", "D", "Font", "BM", "SMask", "CA", "ca", "AIS"} {
+			dict.Remove(key)
+		}
+	}
+	dict.Set("Type", core.MakeName("ExtGState"))
+
+	if gs.LW != nil {
+		dict.Set("LW", core.MakeFloat(*gs.LW))
+	}
+	if gs.LC != nil {
+		dict.Set("LC", core.MakeInteger(*gs.LC))
+	}
+	if gs.LJ != nil {
+		dict.Set("LJ", core.MakeInteger(*gs.LJ))
+	}
+	if gs.ML != nil {
+		dict.Set("ML", core.MakeFloat(*gs.ML))
+	}
+	if gs.D != nil {
+		dict.Set("D", core.MakeArray(core.MakeArrayFromFloats(gs.D.Array), core.MakeFloat(gs.D.Phase)))
+	}
+	if gs.Font != nil {
+		dict.Set("Font", core.MakeArray(gs.Font.ToPdfObject(), core.MakeFloat(gs.FontSize)))
+	}
+	if gs.BM != "" {
+		dict.Set("BM", core.MakeName(string(gs.BM)))
+	}
+	if gs.SMask != nil {
+		dict.Set("SMask", gs.SMask.ToPdfObject())
+	} else if gs.SMaskNone {
+		dict.Set("SMask", core.MakeName("None"))
+	}
+	if gs.CA != nil {
+		dict.Set("CA", core.MakeFloat(*gs.CA))
+	}
+	if gs.Ca != nil {
+		dict.Set("ca", core.MakeFloat(*gs.Ca))
+	}
+	if gs.AIS != nil {
+		dict.Set("AIS", core.MakeBool(*gs.AIS))
+	}
+	return dict
+}
+
+// GetExtGStateByName returns the graphics state parameter dictionary `name`
+// of the resources.
+func (r *PdfPageResources) GetExtGStateByName(name core.PdfObjectName) (*PdfExtGState, error) {
+	obj, ok := r.GetExtGState(name)
+	if !ok {
+		return nil, fmt.Errorf("ExtGState %s not found", name)
+	}
+	return NewPdfExtGStateFromPdfObject(obj)
+}
+
+// SetExtGStateByName sets the graphics state parameter dictionary `name` of
+// the resources to `gs`.
+func (r *PdfPageResources) SetExtGStateByName(name core.PdfObjectName, gs *PdfExtGState) error {
+	return r.AddExtGState(name, gs.ToPdfObject())
+}
@@ -0,0 +1,87 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestExtGStateRoundTrip(t *testing.T) {
+	font, err := NewStandard14Font(HelveticaName)
+	require.NoError(t, err)
+
+	lw, ml, alpha := 2.5, 4.0, 0.5
+	lc, lj := int64(1), int64(2)
+	ais := true
+	gs := NewPdfExtGState()
+	gs.LW = &lw
+	gs.LC = &lc
+	gs.LJ = &lj
+	gs.ML = &ml
+	gs.D = &PdfDashPattern{Array: []float64{3, 1}, Phase: 1}
+	gs.Font = font
+	gs.FontSize = 12
+	gs.BM = PdfBlendModeMultiply
+	gs.SMaskNone = true
+	gs.CA = &alpha
+	gs.Ca = &alpha
+	gs.AIS = &ais
+
+	resources := NewPdfPageResources()
+	require.NoError(t, resources.SetExtGStateByName("GS1", gs))
+	require.True(t, resources.HasExtGState("GS1"))
+	require.False(t, resources.HasExtGState("GS2"))
+
+	loaded, err := resources.GetExtGStateByName("GS1")
+	require.NoError(t, err)
+	require.Equal(t, lw, *loaded.LW)
+	require.Equal(t, lc, *loaded.LC)
+	require.Equal(t, lj, *loaded.LJ)
+	require.Equal(t, ml, *loaded.ML)
+	require.Equal(t, gs.D, loaded.D)
+	require.NotNil(t, loaded.Font)
+	require.Equal(t, "Helvetica", loaded.Font.BaseFont())
+	require.Equal(t, 12.0, loaded.FontSize)
+	require.Equal(t, PdfBlendModeMultiply, loaded.BM)
+	require.Nil(t, loaded.SMask)
+	require.True(t, loaded.SMaskNone)
+	require.Equal(t, alpha, *loaded.CA)
+	require.Equal(t, alpha, *loaded.Ca)
+	require.True(t, *loaded.AIS)
+
+	_, err = resources.GetExtGStateByName("GS2")
+	require.Error(t, err)
+
+	// The first blend mode of blend mode arrays is used and the entries which
+	// are not modeled are kept.
+	dict := core.MakeDict()
+	dict.Set("BM", core.MakeArray(core.MakeName("Screen"), core.MakeName("Normal")))
+	dict.Set("OP", core.MakeBool(true))
+	dict.Set("LW", core.MakeInteger(3))
+	loaded, err = NewPdfExtGStateFromPdfObject(dict)
+	require.NoError(t, err)
+	require.Equal(t, PdfBlendModeScreen, loaded.BM)
+	require.Equal(t, 3.0, *loaded.LW)
+	require.Nil(t, loaded.CA)
+
+	loaded.BM = PdfBlendModeDarken
+	loaded.LW = nil
+	out, ok := core.GetDict(loaded.ToPdfObject())
+	require.True(t, ok)
+	bm, _ := core.GetNameVal(out.Get("BM"))
+	require.Equal(t, "Darken", bm)
+	op, _ := core.GetBoolVal(out.Get("OP"))
+	require.True(t, op)
+	require.Nil(t, out.Get("LW"))
+	require.Nil(t, out.Get("SMask"))
+
+	_, err = NewPdfExtGStateFromPdfObject(core.MakeName("GS"))
+	require.Error(t, err)
+}
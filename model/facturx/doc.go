@@ -0,0 +1,9 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package facturx embeds and extracts the XML invoices of hybrid electronic
+// invoices following the Factur-X and ZUGFeRD 2 specifications, which are
+// PDF/A-3 documents with the structured invoice as associated file.
+package facturx
@@ -0,0 +1,229 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package facturx
+
+import (
+	"errors"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/xmp"
+)
+
+// Profile is a Factur-X profile, the conformance level of the XML invoice.
+type Profile string
+
+// Factur-X profiles.
+const (
+	ProfileMinimum   Profile = "MINIMUM"
+	ProfileBasicWL   Profile = "BASIC WL"
+	ProfileBasic     Profile = "BASIC"
+	ProfileEN16931   Profile = "EN 16931"
+	ProfileExtended  Profile = "EXTENDED"
+	ProfileXRechnung Profile = "XRECHNUNG"
+)
+
+// isValid returns true if `p` is a known profile.
+func (p Profile) isValid() bool {
+	switch p {
+	case ProfileMinimum, ProfileBasicWL, ProfileBasic, ProfileEN16931, ProfileExtended, ProfileXRechnung:
+		return true
+	}
+	return false
+}
+
+// relationship returns the relationship of the XML invoices of the profile
+// to the documents: the MINIMUM and BASIC WL profiles are not complete
+// invoices.
+func (p Profile) relationship() model.AFRelationship {
+	if p == ProfileMinimum || p == ProfileBasicWL {
+		return model.AFRelationshipData
+	}
+	return model.AFRelationshipAlternative
+}
+
+const (
+	// FileName is the name of the embedded XML invoice in Factur-X documents.
+	FileName = "factur-x.xml"
+
+	// Namespace is the namespace of the Factur-X XMP properties.
+	Namespace = "urn:factur-x:pdfa:CrossIndustryDocument:invoice:1p0#"
+
+	// namespaceZUGFeRD is the namespace of the ZUGFeRD 2 XMP properties,
+	// read by Extract.
+	namespaceZUGFeRD = "urn:zugferd:pdfa:CrossIndustryDocument:invoice:2p0#"
+)
+
+// invoiceFileNames contains the names of the XML invoices recognized by
+// Extract.
+var invoiceFileNames = []string{FileName, "zugferd-invoice.xml", "ZUGFeRD-invoice.xml", "xrechnung.xml"}
+
+// extensionSchema describes the Factur-X XMP properties.
+var extensionSchema = xmp.ExtensionSchema{
+	Schema:       "Factur-X PDFA Extension Schema",
+	NamespaceURI: Namespace,
+	Prefix:       "fx",
+	Properties: []xmp.ExtensionProperty{
+		{Name: "DocumentFileName", ValueType: "Text", Category: "external", Description: "name of the embedded XML invoice file"},
+		{Name: "DocumentType", ValueType: "Text", Category: "external", Description: "INVOICE"},
+		{Name: "Version", ValueType: "Text", Category: "external", Description: "The actual version of the Factur-X XML schema"},
+		{Name: "ConformanceLevel", ValueType: "Text", Category: "external", Description: "The conformance level of the embedded Factur-X data"},
+	},
+}
+
+// Options configures Embed.
+type Options struct {
+	// Profile is the profile of the XML invoice (Required).
+	Profile Profile
+
+	// Version is the version of the Factur-X schema. Defaults to "1.0".
+	Version string
+
+	// Relationship is the relationship of the XML invoice to the document.
+	// Defaults to model.AFRelationshipData for the MINIMUM and BASIC WL
+	// profiles and to model.AFRelationshipAlternative otherwise.
+	Relationship model.AFRelationship
+
+	// ModTime is the modification time of the XML invoice. Defaults to the
+	// current time.
+	ModTime time.Time
+
+	// Metadata is the XMP metadata of the document, to which the Factur-X
+	// properties are added. A new XMP document is used if nil.
+	Metadata *xmp.Document
+}
+
+// Embed embeds the XML invoice `invoice` in the document written by `w`, as
+// document level associated file named factur-x.xml, and sets the XMP
+// metadata of the document with the Factur-X properties and their PDF/A
+// extension schema. The embedded files name tree of the document is replaced.
+// To produce a PDF/A-3 document, as required by Factur-X, a pdfa.Profile of
+// level pdfa.LevelA3B should be applied to `w` after calling Embed; the
+// profile keeps the metadata set by Embed.
+func Embed(w *model.PdfWriter, invoice []byte, opts Options) error {
+	if !opts.Profile.isValid() {
+		return errors.New("invalid Factur-X profile")
+	}
+	if len(invoice) == 0 {
+		return errors.New("empty invoice")
+	}
+	if opts.Version == "" {
+		opts.Version = "1.0"
+	}
+	if opts.Relationship == "" {
+		opts.Relationship = opts.Profile.relationship()
+	}
+	if opts.ModTime.IsZero() {
+		opts.ModTime = time.Now()
+	}
+
+	fs, err := model.NewPdfFilespecFromEmbeddedFile(&model.EmbeddedFile{
+		Name:         FileName,
+		Content:      invoice,
+		FileType:     "text/xml",
+		Description:  "Factur-X invoice",
+		ModTime:      opts.ModTime,
+		Relationship: opts.Relationship,
+	})
+	if err != nil {
+		return err
+	}
+	entries := []*model.EmbeddedFileEntry{{Key: FileName, Filespec: fs}}
+	if err := w.SetEmbeddedFiles(entries); err != nil {
+		return err
+	}
+	if err := w.AddAssociatedFile(fs); err != nil {
+		return err
+	}
+
+	doc := opts.Metadata
+	if doc == nil {
+		doc = xmp.NewDocument()
+	}
+	doc.SetExtensionSchema(extensionSchema)
+	doc.SetText(Namespace, "DocumentFileName", FileName)
+	doc.SetText(Namespace, "DocumentType", "INVOICE")
+	doc.SetText(Namespace, "Version", opts.Version)
+	doc.SetText(Namespace, "ConformanceLevel", string(opts.Profile))
+	return xmp.WriteDocument(w, doc)
+}
+
+// Invoice is an XML invoice extracted from a document.
+type Invoice struct {
+	// Name is the name of the embedded file.
+	Name string
+
+	// XML is the content of the XML invoice.
+	XML []byte
+
+	// Profile is the profile declared by the XMP metadata of the document,
+	// if any.
+	Profile Profile
+
+	// Relationship is the relationship of the invoice to the document.
+	Relationship model.AFRelationship
+}
+
+// Extract returns the XML invoice embedded in the document loaded by `r`,
+// or nil if the document is not a Factur-X or ZUGFeRD 2 invoice. The invoice
+// is looked up by file name in the associated files of the document and
+// then in its embedded files.
+func Extract(r *model.PdfReader) (*Invoice, error) {
+	files, err := r.GetAssociatedFiles()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := r.GetEmbeddedFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		files = append(files, entry.Filespec)
+	}
+
+	var fs *model.PdfFilespec
+	for _, f := range files {
+		if isInvoiceFileName(f.FileName()) {
+			fs = f
+			break
+		}
+	}
+	if fs == nil {
+		return nil, nil
+	}
+	file, err := fs.GetEmbeddedFile()
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, errors.New("invoice file not embedded")
+	}
+
+	invoice := &Invoice{Name: file.Name, XML: file.Content, Relationship: file.Relationship}
+	doc, err := xmp.ReadDocument(r)
+	if err != nil {
+		common.Log.Debug("ERROR: invalid metadata: %v", err)
+	} else if doc != nil {
+		for _, namespace := range []string{Namespace, namespaceZUGFeRD} {
+			if level, ok := doc.GetText(namespace, "ConformanceLevel"); ok {
+				invoice.Profile = Profile(level)
+				break
+			}
+		}
+	}
+	return invoice, nil
+}
+
+// isInvoiceFileName returns true if `name` is the name of an XML invoice.
+func isInvoiceFileName(name string) bool {
+	for _, n := range invoiceFileNames {
+		if name == n {
+			return true
+		}
+	}
+	return false
+}
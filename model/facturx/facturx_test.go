@@ -0,0 +1,90 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package facturx_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/facturx"
+	"github.com/unidoc/unipdf/v3/model/pdfa"
+	"github.com/unidoc/unipdf/v3/model/xmp"
+)
+
+const testInvoice = `<?xml version="1.0" encoding="UTF-8"?>
+<rsm:CrossIndustryInvoice xmlns:rsm="urn:un:unece:uncefact:data:standard:CrossIndustryInvoice:100"/>`
+
+func TestEmbedExtract(t *testing.T) {
+	substitute, err := ioutil.ReadFile("../testdata/font/OpenSans-Regular.ttf")
+	require.NoError(t, err)
+
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 595, Ury: 842}
+	w := model.NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	require.NoError(t, facturx.Embed(&w, []byte(testInvoice), facturx.Options{Profile: facturx.ProfileEN16931}))
+	profile := pdfa.NewProfile(pdfa.Options{
+		Level:           pdfa.LevelA3B,
+		FontSubstitutes: map[string][]byte{"Helvetica": substitute},
+	})
+	require.NoError(t, profile.Apply(&w))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	require.Empty(t, profile.Violations())
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	report, err := pdfa.Validate(r, pdfa.LevelA3B)
+	require.NoError(t, err)
+	require.True(t, report.IsCompliant(), "%v", report.Violations)
+
+	invoice, err := facturx.Extract(r)
+	require.NoError(t, err)
+	require.NotNil(t, invoice)
+	require.Equal(t, facturx.FileName, invoice.Name)
+	require.Equal(t, testInvoice, string(invoice.XML))
+	require.Equal(t, facturx.ProfileEN16931, invoice.Profile)
+	require.Equal(t, model.AFRelationshipAlternative, invoice.Relationship)
+
+	files, err := r.GetAssociatedFiles()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	file, err := files[0].GetEmbeddedFile()
+	require.NoError(t, err)
+	require.Equal(t, "text/xml", file.FileType)
+	require.False(t, file.ModTime.IsZero())
+
+	doc, err := xmp.ReadDocument(r)
+	require.NoError(t, err)
+	require.Equal(t, []string{facturx.Namespace}, doc.ExtensionSchemas())
+	fileName, _ := doc.GetText(facturx.Namespace, "DocumentFileName")
+	require.Equal(t, facturx.FileName, fileName)
+	part, _, ok := doc.PDFAIdentification()
+	require.True(t, ok)
+	require.Equal(t, 3, part)
+}
+
+func TestEmbedOptions(t *testing.T) {
+	w := model.NewPdfWriter()
+	require.Error(t, facturx.Embed(&w, []byte(testInvoice), facturx.Options{Profile: "FULL"}))
+	require.Error(t, facturx.Embed(&w, nil, facturx.Options{Profile: facturx.ProfileBasic}))
+
+	// The MINIMUM profile is not a complete invoice.
+	require.NoError(t, w.AddPage(model.NewPdfPage()))
+	require.NoError(t, facturx.Embed(&w, []byte(testInvoice), facturx.Options{Profile: facturx.ProfileMinimum}))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	invoice, err := facturx.Extract(r)
+	require.NoError(t, err)
+	require.Equal(t, model.AFRelationshipData, invoice.Relationship)
+	require.Equal(t, facturx.ProfileMinimum, invoice.Profile)
+}
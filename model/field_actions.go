@@ -0,0 +1,70 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// GetActionJavaScript returns the script of the JavaScript action triggered
+// by the event `trigger` of the additional-actions (AA) dictionary of the
+// field (12.6.3 - p. 415), e.g. "F" for the script formatting the value of
+// the field, "K" for the keystroke script or "C" for the calculation script.
+// Returns false if the field has no such JavaScript action.
+func (f *PdfField) GetActionJavaScript(trigger core.PdfObjectName) (string, bool) {
+	aa, ok := core.GetDict(f.AA)
+	if !ok {
+		return "", false
+	}
+	action, ok := core.GetDict(aa.Get(trigger))
+	if !ok {
+		return "", false
+	}
+	if s, _ := core.GetNameVal(action.Get("S")); s != "JavaScript" {
+		return "", false
+	}
+
+	switch t := core.TraceToDirectObject(action.Get("JS")).(type) {
+	case *core.PdfObjectString:
+		return t.Decoded(), true
+	case *core.PdfObjectStream:
+		data, err := core.DecodeStream(t)
+		if err != nil {
+			common.Log.Debug("ERROR: could not decode field JavaScript: %v", err)
+			return "", false
+		}
+		return string(data), true
+	}
+	return "", false
+}
+
+// CalculationOrder returns the fields having calculation actions, in the
+// order in which their values are recalculated when the value of any field
+// changes (CO). The entries of the CO array which do not reference fields of
+// the form are skipped.
+func (form *PdfAcroForm) CalculationOrder() []*PdfField {
+	if form.CO == nil {
+		return nil
+	}
+
+	fields := map[core.PdfObject]*PdfField{}
+	for _, field := range form.AllFields() {
+		fields[field.container] = field
+	}
+
+	var order []*PdfField
+	for _, obj := range form.CO.Elements() {
+		obj = core.ResolveReference(obj)
+		field, ok := fields[obj]
+		if !ok {
+			common.Log.Debug("WARN: calculation order entry is not a form field: %v", obj)
+			continue
+		}
+		order = append(order, field)
+	}
+	return order
+}
@@ -0,0 +1,92 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// testFieldValues is a field value provider backed by a map.
+type testFieldValues map[string]core.PdfObject
+
+func (v testFieldValues) FieldValues() (map[string]core.PdfObject, error) {
+	return v, nil
+}
+
+func TestFieldActionsPreservedOnFill(t *testing.T) {
+	newTextField := func(name, trigger, js string) *PdfFieldText {
+		field := NewPdfField()
+		ftxt := &PdfFieldText{}
+		field.SetContext(ftxt)
+		ftxt.PdfField = field
+		ftxt.T = core.MakeString(name)
+
+		action := core.MakeDict()
+		action.Set("S", core.MakeName("JavaScript"))
+		action.Set("JS", core.MakeString(js))
+		aa := core.MakeDict()
+		aa.Set(*core.MakeName(trigger), action)
+		ftxt.AA = aa
+		return ftxt
+	}
+	price := newTextField("price", "F", `AFNumber_Format(2, 0, 0, 0, "$", true);`)
+	total := newTextField("total", "C", `AFSimple_Calculate("SUM", new Array ("price"));`)
+	tax := newTextField("tax", "C", `event.value = this.getField("price").value * 0.2;`)
+
+	form := NewPdfAcroForm()
+	form.Fields = &[]*PdfField{price.PdfField, total.PdfField, tax.PdfField}
+	form.CO = core.MakeArray(tax.ToPdfObject(), total.ToPdfObject())
+
+	write := func(form *PdfAcroForm) *PdfReader {
+		w := NewPdfWriter()
+		require.NoError(t, w.AddPage(NewPdfPage()))
+		require.NoError(t, w.SetForms(form))
+		var buf bytes.Buffer
+		require.NoError(t, w.Write(&buf))
+		r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		return r
+	}
+	check := func(form *PdfAcroForm) {
+		var names []string
+		for _, field := range form.CalculationOrder() {
+			names = append(names, field.PartialName())
+		}
+		require.Equal(t, []string{"tax", "total"}, names)
+
+		fields := map[string]*PdfField{}
+		for _, field := range form.AllFields() {
+			fields[field.PartialName()] = field
+		}
+		js, ok := fields["price"].GetActionJavaScript("F")
+		require.True(t, ok)
+		require.Equal(t, `AFNumber_Format(2, 0, 0, 0, "$", true);`, js)
+		_, ok = fields["price"].GetActionJavaScript("C")
+		require.False(t, ok)
+		js, ok = fields["total"].GetActionJavaScript("C")
+		require.True(t, ok)
+		require.Contains(t, js, "AFSimple_Calculate")
+	}
+	check(form)
+
+	r := write(form)
+	check(r.AcroForm)
+
+	// Fill the form and write it again.
+	require.NoError(t, r.AcroForm.Fill(testFieldValues{"price": core.MakeString("1234.5")}))
+	r = write(r.AcroForm)
+	check(r.AcroForm)
+	for _, field := range r.AcroForm.AllFields() {
+		if field.PartialName() == "price" {
+			require.Equal(t, "1234.5", fieldValueString(field.V))
+		}
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// IsCombo returns true if the choice field is a combo box, false if it is a
+// list box.
+func (ch *PdfFieldChoice) IsCombo() bool {
+	return ch.Flags().Has(FieldFlagCombo)
+}
+
+// IsEditable returns true if the choice field is a combo box accepting
+// values which are not in its options, entered by the user.
+func (ch *PdfFieldChoice) IsEditable() bool {
+	return ch.IsCombo() && ch.Flags().Has(FieldFlagEdit)
+}
+
+// IsMultiSelect returns true if more than one of the options of the choice
+// field may be selected simultaneously.
+func (ch *PdfFieldChoice) IsMultiSelect() bool {
+	return ch.Flags().Has(FieldFlagMultiSelect)
+}
+
+// Options returns the options of the choice field (Opt), with their export
+// and display values.
+func (ch *PdfFieldChoice) Options() []FormFieldOption {
+	return choiceFieldOptions(ch.Opt)
+}
+
+// SetOptions sets the options of the choice field. The options having
+// different export and display values are written as pairs, the other ones
+// as single text strings (12.7.4.4 - p. 445). The selection indices (I) are
+// removed, as they are not valid anymore.
+func (ch *PdfFieldChoice) SetOptions(options []FormFieldOption) {
+	ch.Opt = core.MakeArray()
+	for _, opt := range options {
+		if opt.Display == "" || opt.Display == opt.Export {
+			ch.Opt.Append(makeTextString(opt.Export))
+			continue
+		}
+		ch.Opt.Append(core.MakeArray(makeTextString(opt.Export), makeTextString(opt.Display)))
+	}
+	ch.I = nil
+}
+
+// SelectedValues returns the export values of the selected options, i.e. the
+// value (V) of the field, which is inherited from the ancestors of the field
+// if not set. The values of editable combo boxes may not be in the options.
+func (ch *PdfFieldChoice) SelectedValues() []string {
+	return fieldValueStrings(ch.inheritedValue(func(f *PdfField) core.PdfObject { return f.V }))
+}
+
+// SelectedIndices returns the sorted indices of the selected options. The
+// selection indices (I) are used if set, as they distinguish between options
+// having the same export value. Otherwise, the indices are looked up from the
+// selected values.
+func (ch *PdfFieldChoice) SelectedIndices() []int {
+	if ch.I != nil {
+		var indices []int
+		for _, obj := range ch.I.Elements() {
+			if i, ok := core.GetIntVal(obj); ok {
+				indices = append(indices, i)
+			}
+		}
+		sort.Ints(indices)
+		return indices
+	}
+
+	options := ch.Options()
+	var indices []int
+	for _, val := range ch.SelectedValues() {
+		for i, opt := range options {
+			if opt.Export == val {
+				indices = append(indices, i)
+				break
+			}
+		}
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// SetSelected selects the options with the export values `values`, setting
+// the value (V) of the field. The selection indices (I) of multi-select
+// fields are set accordingly. No option is selected if `values` is empty.
+// An error is returned if several values are selected while the field is not
+// multi-select, or if a value is not one of the options, unless the field is
+// an editable combo box.
+func (ch *PdfFieldChoice) SetSelected(values ...string) error {
+	indices, err := ch.choiceIndices(values)
+	if err != nil {
+		return err
+	}
+
+	switch len(values) {
+	case 0:
+		ch.V = nil
+	case 1:
+		ch.V = makeTextString(values[0])
+	default:
+		arr := core.MakeArray()
+		for _, val := range values {
+			arr.Append(makeTextString(val))
+		}
+		ch.V = arr
+	}
+	ch.setIndices(indices)
+	return nil
+}
+
+// choiceIndices returns the sorted indices of the options with the export
+// values `values`. The values which are not in the options are skipped for
+// editable combo boxes.
+func (ch *PdfFieldChoice) choiceIndices(values []string) ([]int, error) {
+	if len(values) > 1 && !ch.IsMultiSelect() {
+		return nil, errors.New("multiple selection not allowed")
+	}
+
+	options := ch.Options()
+	var indices []int
+	for _, val := range values {
+		index := -1
+		for i, opt := range options {
+			if opt.Export == val {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			if !ch.IsEditable() {
+				return nil, fmt.Errorf("invalid choice %q", val)
+			}
+			continue
+		}
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	return indices, nil
+}
+
+// setIndices sets the selection indices (I) of multi-select fields.
+func (ch *PdfFieldChoice) setIndices(indices []int) {
+	ch.I = nil
+	if !ch.IsMultiSelect() || len(indices) == 0 {
+		return
+	}
+	ch.I = core.MakeArray()
+	for _, i := range indices {
+		ch.I.Append(core.MakeInteger(int64(i)))
+	}
+}
+
+// setChoiceIndices updates the selection indices (I) of the choice field
+// `ch` according to its value, after the value has been filled. The indices
+// are removed if the value does not match the options.
+func setChoiceIndices(ch *PdfFieldChoice) {
+	indices, err := ch.choiceIndices(ch.SelectedValues())
+	if err != nil {
+		common.Log.Debug("Choice field value not in options: %v", err)
+	}
+	ch.setIndices(indices)
+}
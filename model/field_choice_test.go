@@ -0,0 +1,78 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func newTestChoiceField(flags FieldFlag) *PdfFieldChoice {
+	field := NewPdfField()
+	ch := &PdfFieldChoice{}
+	field.SetContext(ch)
+	ch.PdfField = field
+	if flags != 0 {
+		ch.SetFlag(flags)
+	}
+	ch.SetOptions([]FormFieldOption{
+		{Export: "FR", Display: "France"},
+		{Export: "DE", Display: "Germany"},
+		{Export: "IT"},
+	})
+	return ch
+}
+
+func TestChoiceFieldOptions(t *testing.T) {
+	ch := newTestChoiceField(FieldFlagCombo)
+	require.Equal(t, `[[(FR) (France)] [(DE) (Germany)] (IT)]`, ch.Opt.WriteString())
+	require.Equal(t, []FormFieldOption{
+		{Export: "FR", Display: "France"},
+		{Export: "DE", Display: "Germany"},
+		{Export: "IT", Display: "IT"},
+	}, ch.Options())
+	require.True(t, ch.IsCombo())
+	require.False(t, ch.IsEditable())
+	require.False(t, ch.IsMultiSelect())
+
+	require.NoError(t, ch.SetSelected("DE"))
+	require.Equal(t, []string{"DE"}, ch.SelectedValues())
+	require.Equal(t, []int{1}, ch.SelectedIndices())
+	require.Nil(t, ch.I)
+
+	require.Error(t, ch.SetSelected("FR", "DE"))
+	require.Error(t, ch.SetSelected("ES"))
+
+	// Editable combo boxes accept any value.
+	ch = newTestChoiceField(FieldFlagCombo | FieldFlagEdit)
+	require.True(t, ch.IsEditable())
+	require.NoError(t, ch.SetSelected("Spain"))
+	require.Equal(t, []string{"Spain"}, ch.SelectedValues())
+	require.Empty(t, ch.SelectedIndices())
+
+	require.NoError(t, ch.SetSelected())
+	require.Nil(t, ch.V)
+}
+
+func TestChoiceFieldMultiSelect(t *testing.T) {
+	ch := newTestChoiceField(FieldFlagMultiSelect)
+	require.NoError(t, ch.SetSelected("IT", "FR"))
+	require.Equal(t, []string{"IT", "FR"}, ch.SelectedValues())
+	require.Equal(t, "[0 2]", ch.I.WriteString())
+	require.Equal(t, []int{0, 2}, ch.SelectedIndices())
+
+	// The selection indices follow the filled values.
+	require.NoError(t, fillFieldValue(ch.PdfField, core.MakeArray(core.MakeString("DE"), core.MakeString("IT"))))
+	require.Equal(t, "[1 2]", ch.I.WriteString())
+	require.NoError(t, fillFieldValue(ch.PdfField, core.MakeString("FR")))
+	require.Equal(t, "[0]", ch.I.WriteString())
+
+	dict := ch.ToPdfObject().(*core.PdfIndirectObject).PdfObject.(*core.PdfObjectDictionary)
+	require.Equal(t, "[0]", dict.Get("I").WriteString())
+}
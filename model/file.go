@@ -6,7 +6,9 @@
 package model
 
 import (
+	"crypto/md5"
 	"errors"
+	"time"
 
 	"github.com/unidoc/unipdf/v3/common"
 	"github.com/unidoc/unipdf/v3/core"
@@ -45,6 +47,10 @@ type PdfFilespec struct {
 	Desc core.PdfObject // Descriptive text associated with the file specification
 	CI   core.PdfObject // A collection item dictionary, which shall be used to create the user interface for portable collections
 
+	// AFRelationship is the relationship of the associated file to the
+	// content referring to it (PDF 2.0, PDF/A-3), see AFRelationship.
+	AFRelationship core.PdfObject
+
 	container core.PdfObject
 }
 
@@ -87,10 +93,23 @@ func (f *PdfFilespec) ToPdfObject() core.PdfObject {
 	d.SetIfNotNil("RF", f.RF)
 	d.SetIfNotNil("Desc", f.Desc)
 	d.SetIfNotNil("CI", f.CI)
+	d.SetIfNotNil("AFRelationship", f.AFRelationship)
 
 	return f.container
 }
 
+// FileName returns the name of the file referred to by the file
+// specification. The Unicode file name (UF) is preferred, if set, followed by
+// the file specification string (F) and the platform specific file names.
+func (f *PdfFilespec) FileName() string {
+	for _, obj := range []core.PdfObject{f.UF, f.F, f.Unix, f.DOS, f.Mac} {
+		if name, ok := core.GetString(obj); ok {
+			return name.Decoded()
+		}
+	}
+	return ""
+}
+
 // NewPdfFilespecFromObj creates and returns a new PdfFilespec object.
 func NewPdfFilespecFromObj(obj core.PdfObject) (*PdfFilespec, error) {
 	fs := &PdfFilespec{}
@@ -167,6 +186,9 @@ func NewPdfFilespecFromObj(obj core.PdfObject) (*PdfFilespec, error) {
 	if obj := dict.Get("CI"); obj != nil {
 		fs.CI = obj
 	}
+	if obj := dict.Get("AFRelationship"); obj != nil {
+		fs.AFRelationship = obj
+	}
 	return fs, nil
 }
 
@@ -176,3 +198,191 @@ func NewPdfFilespec() *PdfFilespec {
 	action.container = core.MakeIndirectObject(core.MakeDict())
 	return action
 }
+
+// AFRelationship represents the relationship of an associated file to the
+// content referring to it, i.e. the document, a page or an object (Table 43
+// PDF32000_2017).
+type AFRelationship string
+
+// Associated file relationships.
+const (
+	// AFRelationshipSource is used for the original source material of the
+	// content.
+	AFRelationshipSource AFRelationship = "Source"
+
+	// AFRelationshipData is used for the data used to derive the content,
+	// e.g. the values of a table or a chart.
+	AFRelationshipData AFRelationship = "Data"
+
+	// AFRelationshipAlternative is used for an alternative representation of
+	// the content, e.g. an invoice in XML format.
+	AFRelationshipAlternative AFRelationship = "Alternative"
+
+	// AFRelationshipSupplement is used for a supplemental representation of
+	// the content, e.g. a version easier to process.
+	AFRelationshipSupplement AFRelationship = "Supplement"
+
+	// AFRelationshipUnspecified is used when the relationship is not known.
+	AFRelationshipUnspecified AFRelationship = "Unspecified"
+)
+
+// EmbeddedFile represents the contents and the attributes of a file embedded
+// in a PDF document (Section 7.11.4 p. 104).
+type EmbeddedFile struct {
+	// Name is the file name used in the file specification.
+	Name string
+
+	// Content is the raw content of the file.
+	Content []byte
+
+	// FileType is the MIME type of the file (e.g. "text/xml"). Optional.
+	FileType string
+
+	// Description is the descriptive text of the file specification. Optional.
+	Description string
+
+	// ModTime is the modification time of the file. Optional.
+	ModTime time.Time
+
+	// Relationship is the relationship of the file to the content it is
+	// associated with, for the associated files. Optional.
+	Relationship AFRelationship
+}
+
+// NewPdfFilespecFromEmbeddedFile returns a new file specification embedding
+// the specified file.
+func NewPdfFilespecFromEmbeddedFile(file *EmbeddedFile) (*PdfFilespec, error) {
+	if file == nil {
+		return nil, errors.New("embedded file cannot be nil")
+	}
+	if file.Name == "" {
+		return nil, ErrRequiredAttributeMissing
+	}
+
+	stream, err := core.MakeStream(file.Content, core.NewFlateEncoder())
+	if err != nil {
+		return nil, err
+	}
+	stream.Set("Type", core.MakeName("EmbeddedFile"))
+	if file.FileType != "" {
+		stream.Set("Subtype", core.MakeName(file.FileType))
+	}
+
+	checksum := md5.Sum(file.Content)
+	params := core.MakeDict()
+	params.Set("Size", core.MakeInteger(int64(len(file.Content))))
+	params.Set("CheckSum", core.MakeStringFromBytes(checksum[:]))
+	if !file.ModTime.IsZero() {
+		if date, err := NewPdfDateFromTime(file.ModTime); err == nil {
+			params.Set("ModDate", date.ToPdfObject())
+		}
+	}
+	stream.Set("Params", params)
+
+	ef := core.MakeDict()
+	ef.Set("F", stream)
+	ef.Set("UF", stream)
+
+	fs := NewPdfFilespec()
+	fs.F = core.MakeString(file.Name)
+	fs.UF = core.MakeEncodedString(file.Name, true)
+	fs.EF = ef
+	if file.Description != "" {
+		fs.Desc = core.MakeEncodedString(file.Description, true)
+	}
+	if file.Relationship != "" {
+		fs.AFRelationship = core.MakeName(string(file.Relationship))
+	}
+	return fs, nil
+}
+
+// GetEmbeddedFile returns the file embedded by the file specification or nil
+// if the file specification does not refer to an embedded file.
+func (f *PdfFilespec) GetEmbeddedFile() (*EmbeddedFile, error) {
+	efDict, ok := core.GetDict(f.EF)
+	if !ok {
+		return nil, nil
+	}
+
+	var stream *core.PdfObjectStream
+	for _, key := range []core.PdfObjectName{"UF", "F", "Unix", "Mac", "DOS"} {
+		if stream, ok = core.GetStream(efDict.Get(key)); ok {
+			break
+		}
+	}
+	if stream == nil {
+		return nil, errors.New("embedded file stream not found")
+	}
+
+	content, err := core.DecodeStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &EmbeddedFile{Name: f.FileName(), Content: content}
+	if fileType, ok := core.GetNameVal(stream.Get("Subtype")); ok {
+		file.FileType = fileType
+	}
+	if desc, ok := core.GetString(f.Desc); ok {
+		file.Description = desc.Decoded()
+	}
+	if relationship, ok := core.GetNameVal(f.AFRelationship); ok {
+		file.Relationship = AFRelationship(relationship)
+	}
+	if params, ok := core.GetDict(stream.Get("Params")); ok {
+		if modDate, ok := core.GetString(params.Get("ModDate")); ok {
+			if date, err := NewPdfDate(modDate.Str()); err == nil {
+				file.ModTime = date.ToGoTime()
+			}
+		}
+	}
+	return file, nil
+}
+
+// GetAssociatedFiles returns the files associated with the document, listed
+// by the AF entry of the catalog (PDF 2.0, PDF/A-3).
+func (r *PdfReader) GetAssociatedFiles() ([]*PdfFilespec, error) {
+	arr, ok := core.GetArray(r.catalog.Get("AF"))
+	if !ok {
+		return nil, nil
+	}
+
+	var files []*PdfFilespec
+	for _, obj := range arr.Elements() {
+		obj = core.ResolveReference(obj)
+		if !r.isLazy {
+			if err := r.traverseObjectData(obj); err != nil {
+				return nil, err
+			}
+		}
+		fs, err := NewPdfFilespecFromObj(obj)
+		if err != nil {
+			common.Log.Debug("ERROR: invalid associated file: %v", err)
+			continue
+		}
+		files = append(files, fs)
+	}
+	return files, nil
+}
+
+// AddAssociatedFile associates the file specified by `fs` with the output
+// document, adding it to the AF entry of the catalog (PDF 2.0, PDF/A-3). The
+// relationship of the file should be set by the AFRelationship entry of `fs`.
+// Embedded files should also be added to the embedded files name tree, see
+// SetEmbeddedFiles.
+func (w *PdfWriter) AddAssociatedFile(fs *PdfFilespec) error {
+	if fs == nil {
+		return errors.New("associated file specification cannot be nil")
+	}
+
+	arr, ok := core.GetArray(w.catalog.Get("AF"))
+	if !ok {
+		arr = core.MakeArray()
+		w.catalog.Set("AF", arr)
+	}
+	obj := fs.ToPdfObject()
+	arr.Append(obj)
+
+	common.Log.Trace("Adding catalog AF...")
+	return w.addObjects(obj)
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"unicode/utf8"
 
 	"github.com/unidoc/unipdf/v3/common"
@@ -39,6 +40,38 @@ type pdfFont interface {
 // etc.
 type PdfFont struct {
 	context pdfFont // The underlying font: Type0, Type1, Truetype, etc..
+
+	// unicodeCache caches the strings of the character codes converted by CharcodesToStrings. It
+	// is created on first use, see getUnicodeCache.
+	unicodeCache *charcodeUnicodeCache
+}
+
+// unicodeCacheMu guards the creation of the unicode caches of fonts.
+var unicodeCacheMu sync.Mutex
+
+// getUnicodeCache returns the unicode cache of the font, creating it if needed.
+func (font *PdfFont) getUnicodeCache() *charcodeUnicodeCache {
+	unicodeCacheMu.Lock()
+	defer unicodeCacheMu.Unlock()
+	if font.unicodeCache == nil {
+		font.unicodeCache = &charcodeUnicodeCache{}
+	}
+	return font.unicodeCache
+}
+
+// charcodeUnicodeCache maps the character codes of a font to their unicode strings. It is built
+// lazily and reset when the encoding of the font changes, see fontCommon.encodingVersion.
+type charcodeUnicodeCache struct {
+	sync.Mutex
+	version int
+	entries map[textencoding.CharCode]charcodeUnicode
+}
+
+// charcodeUnicode is the unicode string of a character code. `ok` is false if the code could not
+// be converted.
+type charcodeUnicode struct {
+	text string
+	ok   bool
 }
 
 // SubsetRegistered subsets the font to only the glyphs that have been registered by the encoder.
@@ -280,10 +313,15 @@ func GetAlphabet(text string) map[rune]int {
 	return alphabet
 }
 
-// NewPdfFontFromPdfObject loads a PdfFont from the dictionary `fontObj`.  If there is a problem an
-// error is returned.
+// NewPdfFontFromPdfObject loads a PdfFont from the dictionary `fontObj`.  If there is a problem a
+// *FontError is returned. For fonts which are not yet supported, such as Type3 fonts, the font
+// returned with the error gives access to some font properties.
 func NewPdfFontFromPdfObject(fontObj core.PdfObject) (*PdfFont, error) {
-	return newPdfFontFromPdfObject(fontObj, true)
+	font, err := newPdfFontFromPdfObject(fontObj, true)
+	if err != nil {
+		return font, newFontError(fontObj, err)
+	}
+	return font, nil
 }
 
 // newPdfFontFromPdfObject loads a PdfFont from the dictionary `fontObj`.  If there is a problem an
@@ -440,28 +478,37 @@ func (font *PdfFont) CharcodesToStrings(charcodes []textencoding.CharCode) ([]st
 	fontBase := font.baseFields()
 	texts := make([]string, 0, len(charcodes))
 	numMisses := 0
+
+	cache := font.getUnicodeCache()
+	cache.Lock()
+	defer cache.Unlock()
+	if cache.entries == nil || cache.version != fontBase.encodingVersion {
+		cache.entries = map[textencoding.CharCode]charcodeUnicode{}
+		cache.version = fontBase.encodingVersion
+	}
+
+	var encoder textencoding.TextEncoder
+	var hasEncoder bool
 	for _, code := range charcodes {
-		if fontBase.toUnicodeCmap != nil {
-			if s, ok := fontBase.toUnicodeCmap.CharcodeToUnicode(cmap.CharCode(code)); ok {
-				texts = append(texts, s)
-				continue
+		entry, cached := cache.entries[code]
+		if !cached {
+			if !hasEncoder {
+				encoder, hasEncoder = font.Encoder(), true
 			}
-		}
-
-		// Fall back to encoding.
-		encoder := font.Encoder()
-		if encoder != nil {
-			if r, ok := encoder.CharcodeToRune(code); ok {
-				texts = append(texts, string(r))
-				continue
+			entry = font.charcodeToString(code, encoder)
+			cache.entries[code] = entry
+			if !entry.ok {
+				common.Log.Debug("ERROR: No rune. code=0x%04x charcodes=[% 04x] CID=%t\n"+
+					"\tfont=%s\n\tencoding=%s",
+					code, charcodes, fontBase.isCIDFont(), font, encoder)
 			}
 		}
-
-		common.Log.Debug("ERROR: No rune. code=0x%04x charcodes=[% 04x] CID=%t\n"+
-			"\tfont=%s\n\tencoding=%s",
-			code, charcodes, fontBase.isCIDFont(), font, encoder)
-		numMisses++
-		texts = append(texts, cmap.MissingCodeString)
+		if !entry.ok {
+			numMisses++
+			texts = append(texts, cmap.MissingCodeString)
+			continue
+		}
+		texts = append(texts, entry.text)
 	}
 
 	if numMisses != 0 {
@@ -474,6 +521,24 @@ func (font *PdfFont) CharcodesToStrings(charcodes []textencoding.CharCode) ([]st
 	return texts, len(texts), numMisses
 }
 
+// charcodeToString returns the unicode string of the character code `code`, using the ToUnicode
+// CMap of the font if there is one, and its encoder `encoder` otherwise.
+func (font *PdfFont) charcodeToString(code textencoding.CharCode, encoder textencoding.TextEncoder) charcodeUnicode {
+	if toUnicode := font.baseFields().toUnicodeCmap; toUnicode != nil {
+		if s, ok := toUnicode.CharcodeToUnicode(cmap.CharCode(code)); ok {
+			return charcodeUnicode{text: s, ok: true}
+		}
+	}
+
+	// Fall back to encoding.
+	if encoder != nil {
+		if r, ok := encoder.CharcodeToRune(code); ok {
+			return charcodeUnicode{text: string(r), ok: true}
+		}
+	}
+	return charcodeUnicode{}
+}
+
 // CharcodeBytesToUnicode converts PDF character codes `data` to a Go unicode string.
 //
 // 9.10 Extraction of Text Content (page 292)
@@ -677,6 +742,10 @@ type fontCommon struct {
 
 	// objectNumber helps us find the font in the PDF being processed. This helps with debugging.
 	objectNumber int64
+
+	// encodingVersion is incremented when the encoder or the ToUnicode CMap of the font change,
+	// which invalidates the unicode strings cached by PdfFont.CharcodesToStrings.
+	encodingVersion int
 }
 
 // asPdfObjectDictionary returns `base` as a core.PdfObjectDictionary.
@@ -762,7 +831,7 @@ func newFontBaseFieldsFromPdfObject(fontObj core.PdfObject) (*core.PdfObjectDict
 	d, ok := core.GetDict(fontObj)
 	if !ok {
 		common.Log.Debug("ERROR: Font not given by a dictionary (%T)", fontObj)
-		return nil, nil, ErrFontNotSupported
+		return nil, nil, ErrTypeCheck
 	}
 
 	objtype, ok := core.GetNameVal(d.Get("Type"))
@@ -898,6 +967,7 @@ type PdfFontDescriptor struct {
 	missingWidth float64
 	*fontFile
 	fontFile2 *fonts.TtfType
+	licensing *FontLicensing
 
 	// Additional entries for CIDFonts
 	Style  core.PdfObject
@@ -1016,7 +1086,7 @@ func newPdfFontDescriptorFromPdfObject(obj core.PdfObject) (*PdfFontDescriptor,
 	}
 
 	if descriptor.FontFile != nil {
-		fontFile, err := newFontFileFromPdfObject(descriptor.FontFile)
+		fontFile, err := loadFontFile(descriptor.FontFile)
 		if err != nil {
 			return descriptor, err
 		}
@@ -1024,12 +1094,16 @@ func newPdfFontDescriptorFromPdfObject(obj core.PdfObject) (*PdfFontDescriptor,
 		descriptor.fontFile = fontFile
 	}
 	if descriptor.FontFile2 != nil {
-		fontFile2, err := fonts.NewFontFile2FromPdfObject(descriptor.FontFile2)
+		fontFile2, err := loadFontFile2(descriptor.FontFile2)
 		if err != nil {
 			return descriptor, err
 		}
 		common.Log.Trace("fontFile2=%s", fontFile2.String())
-		descriptor.fontFile2 = &fontFile2
+		descriptor.fontFile2 = fontFile2
+		descriptor.licensing = newFontLicensing(fontFile2)
+	}
+	if descriptor.FontFile3 != nil {
+		descriptor.licensing = loadFontFile3Licensing(descriptor.FontFile3)
 	}
 	return descriptor, nil
 }
@@ -0,0 +1,219 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"sync"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model/internal/fonts"
+)
+
+// defaultFontCacheEntries is the number of font programs kept by the font
+// cache when the size is not specified.
+const defaultFontCacheEntries = 256
+
+// FontCacheStats contains the statistics of the font program cache.
+type FontCacheStats struct {
+	// Entries is the number of font programs in the cache.
+	Entries int
+
+	// Hits and Misses are the numbers of font programs which were found in
+	// the cache and which were parsed since the cache was enabled.
+	Hits   int64
+	Misses int64
+}
+
+// EnableFontCache enables the process-wide cache of the parsed embedded font
+// programs (FontFile and FontFile2 streams), keyed by the checksum of their
+// data. The cache avoids parsing the identical font programs embedded in
+// the documents processed by a service, e.g. documents produced by the same
+// application, every time they are loaded. The `maxEntries` most recently
+// used font programs are kept, 256 if `maxEntries` is not positive. The
+// cache is disabled by default.
+func EnableFontCache(maxEntries int) {
+	if maxEntries <= 0 {
+		maxEntries = defaultFontCacheEntries
+	}
+	fontCache.Lock()
+	defer fontCache.Unlock()
+	fontCache.maxEntries = maxEntries
+	fontCache.clear()
+}
+
+// DisableFontCache disables the font program cache and releases the cached
+// font programs.
+func DisableFontCache() {
+	fontCache.Lock()
+	defer fontCache.Unlock()
+	fontCache.maxEntries = 0
+	fontCache.clear()
+}
+
+// GetFontCacheStats returns the statistics of the font program cache.
+func GetFontCacheStats() FontCacheStats {
+	fontCache.Lock()
+	defer fontCache.Unlock()
+	return FontCacheStats{
+		Entries: len(fontCache.entries),
+		Hits:    fontCache.hits,
+		Misses:  fontCache.misses,
+	}
+}
+
+// fontCache is the process-wide font program cache.
+var fontCache fontProgramCache
+
+// fontProgramCache is a least recently used cache of parsed font programs.
+// The cached font programs are shared by the fonts which load them and must
+// not be modified.
+type fontProgramCache struct {
+	sync.Mutex
+
+	// maxEntries is the maximal number of entries, 0 if the cache is
+	// disabled.
+	maxEntries int
+
+	entries map[fontProgramKey]*list.Element
+	order   *list.List // Of *fontProgramEntry, most recently used first.
+
+	hits   int64
+	misses int64
+}
+
+// fontProgramKey identifies a font program by the stream entries used for
+// parsing it and by the checksum of its data.
+type fontProgramKey struct {
+	key      core.PdfObjectName // FontFile or FontFile2.
+	length1  int
+	length2  int
+	checksum [sha256.Size]byte
+}
+
+// fontProgramEntry is a font program of the cache.
+type fontProgramEntry struct {
+	key       fontProgramKey
+	fontFile  *fontFile
+	fontFile2 *fonts.TtfType
+}
+
+// clear removes all the entries of the cache. The cache must be locked.
+func (c *fontProgramCache) clear() {
+	c.entries = map[fontProgramKey]*list.Element{}
+	c.order = list.New()
+	c.hits = 0
+	c.misses = 0
+}
+
+// get returns the cached font program with key `key`, or nil if not found.
+func (c *fontProgramCache) get(key fontProgramKey) *fontProgramEntry {
+	c.Lock()
+	defer c.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*fontProgramEntry)
+}
+
+// add adds the font program `entry` to the cache, removing the least
+// recently used font programs if the cache is full.
+func (c *fontProgramCache) add(entry *fontProgramEntry) {
+	c.Lock()
+	defer c.Unlock()
+	if c.maxEntries == 0 {
+		return
+	}
+	if elem, ok := c.entries[entry.key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[entry.key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		delete(c.entries, oldest.Value.(*fontProgramEntry).key)
+		c.order.Remove(oldest)
+	}
+}
+
+// enabled returns true if the cache is enabled.
+func (c *fontProgramCache) enabled() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.maxEntries > 0
+}
+
+// loadFontFile loads the FontFile stream `obj`, using the font cache if
+// enabled.
+func loadFontFile(obj core.PdfObject) (*fontFile, error) {
+	if !fontCache.enabled() {
+		return newFontFileFromPdfObject(obj)
+	}
+	stream, ok := core.GetStream(obj)
+	if !ok {
+		common.Log.Debug("ERROR: FontFile must be a stream (%T)", obj)
+		return nil, core.ErrTypeError
+	}
+	data, err := core.DecodeStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fontProgramKey{key: "FontFile", checksum: sha256.Sum256(data)}
+	key.length1, _ = core.GetIntVal(stream.Get("Length1"))
+	key.length2, _ = core.GetIntVal(stream.Get("Length2"))
+	if entry := fontCache.get(key); entry != nil {
+		return entry.fontFile, nil
+	}
+
+	fontfile, err := newFontFileFromData(stream.PdfObjectDictionary, data)
+	if err != nil {
+		return nil, err
+	}
+	fontCache.add(&fontProgramEntry{key: key, fontFile: fontfile})
+	return fontfile, nil
+}
+
+// loadFontFile2 loads the FontFile2 stream `obj`, using the font cache if
+// enabled.
+func loadFontFile2(obj core.PdfObject) (*fonts.TtfType, error) {
+	if !fontCache.enabled() {
+		ttf, err := fonts.NewFontFile2FromPdfObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		return &ttf, nil
+	}
+	stream, ok := core.GetStream(obj)
+	if !ok {
+		common.Log.Debug("ERROR: FontFile2 must be a stream (%T)", obj)
+		return nil, core.ErrTypeError
+	}
+	data, err := core.DecodeStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fontProgramKey{key: "FontFile2", checksum: sha256.Sum256(data)}
+	if entry := fontCache.get(key); entry != nil {
+		return entry.fontFile2, nil
+	}
+
+	ttf, err := fonts.TtfParse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	fontCache.add(&fontProgramEntry{key: key, fontFile2: &ttf})
+	return &ttf, nil
+}
@@ -0,0 +1,76 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/textencoding"
+)
+
+func TestFontCache(t *testing.T) {
+	loadFontObject := func(path string) core.PdfObject {
+		font, err := NewPdfFontFromTTFFile(path)
+		require.NoError(t, err)
+		return font.ToPdfObject()
+	}
+	openSans := loadFontObject("testdata/font/OpenSans-Regular.ttf")
+	glyfTest := loadFontObject("testdata/font/glyfTest.ttf")
+
+	load := func(obj core.PdfObject) *PdfFontDescriptor {
+		font, err := NewPdfFontFromPdfObject(obj)
+		require.NoError(t, err)
+		descriptor, err := font.GetFontDescriptor()
+		require.NoError(t, err)
+		require.NotNil(t, descriptor.fontFile2)
+		return descriptor
+	}
+
+	// The cache is disabled by default.
+	require.Equal(t, FontCacheStats{}, GetFontCacheStats())
+	require.True(t, load(openSans).fontFile2 != load(openSans).fontFile2)
+
+	EnableFontCache(1)
+	defer DisableFontCache()
+	first := load(openSans).fontFile2
+	require.True(t, first == load(openSans).fontFile2)
+	require.Equal(t, FontCacheStats{Entries: 1, Hits: 1, Misses: 1}, GetFontCacheStats())
+
+	// The least recently used font programs are removed.
+	load(glyfTest)
+	require.True(t, first != load(openSans).fontFile2)
+	require.Equal(t, FontCacheStats{Entries: 1, Hits: 1, Misses: 3}, GetFontCacheStats())
+
+	DisableFontCache()
+	require.Equal(t, FontCacheStats{}, GetFontCacheStats())
+}
+
+func TestCharcodeUnicodeCache(t *testing.T) {
+	font, err := NewStandard14Font(HelveticaName)
+	require.NoError(t, err)
+
+	str, _, numMisses := font.CharcodeBytesToUnicode([]byte("AB"))
+	require.Equal(t, "AB", str)
+	require.Zero(t, numMisses)
+	require.Len(t, font.unicodeCache.entries, 2)
+
+	// Cached lookups give the same results.
+	str, _, _ = font.CharcodeBytesToUnicode([]byte("BA"))
+	require.Equal(t, "BA", str)
+
+	// Changing the encoder invalidates the cache.
+	encoder, err := textencoding.NewCustomSimpleTextEncoder(map[textencoding.CharCode]textencoding.GlyphName{
+		'A': "Z",
+	}, nil)
+	require.NoError(t, err)
+	font.context.(*pdfFontSimple).SetEncoder(encoder)
+	str, _, numMisses = font.CharcodeBytesToUnicode([]byte("AB"))
+	require.Equal(t, "Z", str[:1])
+	require.Equal(t, 1, numMisses)
+}
@@ -0,0 +1,110 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/model/internal/fonts"
+)
+
+// TTCFontNames returns the PostScript names of the fonts of the TrueType
+// collection (.ttc) `r`, in the order of their indices in the collection.
+func TTCFontNames(r io.ReadSeeker) ([]string, error) {
+	numFonts, err := fonts.TtcNumFonts(r)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, numFonts)
+	for i := range names {
+		ttf, err := fonts.TtcParse(r, i)
+		if err != nil {
+			return nil, err
+		}
+		names[i] = ttf.PostScriptName
+	}
+	return names, nil
+}
+
+// TTCFontIndex returns the index of the font with the PostScript name `name`
+// in the TrueType collection (.ttc) `r`.
+func TTCFontIndex(r io.ReadSeeker, name string) (int, error) {
+	names, err := TTCFontNames(r)
+	if err != nil {
+		return 0, err
+	}
+	for i, fontName := range names {
+		if fontName == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("font %q not found in collection", name)
+}
+
+// readTTCFont returns the font file of the font at index `index` of the
+// TrueType collection `r`.
+func readTTCFont(r io.ReadSeeker, index int) ([]byte, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		common.Log.Debug("ERROR: Unable to read font contents: %v", err)
+		return nil, err
+	}
+	return fonts.TtcExtractFont(data, index)
+}
+
+// NewPdfFontFromTTC loads the font at index `index` of the TrueType
+// collection (.ttc) `r` as a simple font, see NewPdfFontFromTTF. The index of
+// a font can be found by name with TTCFontIndex.
+func NewPdfFontFromTTC(r io.ReadSeeker, index int) (*PdfFont, error) {
+	data, err := readTTCFont(r, index)
+	if err != nil {
+		return nil, err
+	}
+	return newPdfFontFromTTFData(data)
+}
+
+// NewPdfFontFromTTCFile loads the font at index `index` of the TrueType
+// collection file `filePath` as a simple font, see NewPdfFontFromTTC.
+func NewPdfFontFromTTCFile(filePath string, index int) (*PdfFont, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		common.Log.Debug("ERROR: reading TTC font file: %v", err)
+		return nil, err
+	}
+	defer f.Close()
+
+	return NewPdfFontFromTTC(f, index)
+}
+
+// NewCompositePdfFontFromTTC loads the font at index `index` of the TrueType
+// collection (.ttc) `r` as a composite font, see NewCompositePdfFontFromTTF.
+// The index of a font can be found by name with TTCFontIndex.
+func NewCompositePdfFontFromTTC(r io.ReadSeeker, index int) (*PdfFont, error) {
+	data, err := readTTCFont(r, index)
+	if err != nil {
+		return nil, err
+	}
+	return newCompositePdfFontFromTTFData(data)
+}
+
+// NewCompositePdfFontFromTTCFile loads the font at index `index` of the
+// TrueType collection file `filePath` as a composite font, see
+// NewCompositePdfFontFromTTC.
+func NewCompositePdfFontFromTTCFile(filePath string, index int) (*PdfFont, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		common.Log.Debug("ERROR: opening file: %v", err)
+		return nil, err
+	}
+	defer f.Close()
+
+	return NewCompositePdfFontFromTTC(f, index)
+}
@@ -0,0 +1,131 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// makeTTC returns a TrueType collection containing the fonts of the font
+// files `files`.
+func makeTTC(t *testing.T, files ...string) []byte {
+	var fontsData [][]byte
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+		fontsData = append(fontsData, data)
+	}
+
+	headerSize := 12 + 4*len(fontsData)
+	var header, body bytes.Buffer
+	header.WriteString("ttcf")
+	binary.Write(&header, binary.BigEndian, uint32(0x00010000))
+	binary.Write(&header, binary.BigEndian, uint32(len(fontsData)))
+	for _, data := range fontsData {
+		// Each font is copied with its table offsets shifted by the offset
+		// of the font in the collection.
+		offset := headerSize + body.Len()
+		binary.Write(&header, binary.BigEndian, uint32(offset))
+
+		font := append([]byte{}, data...)
+		numTables := int(binary.BigEndian.Uint16(font[4:]))
+		for i := 0; i < numTables; i++ {
+			rec := font[12+16*i:]
+			binary.BigEndian.PutUint32(rec[8:], binary.BigEndian.Uint32(rec[8:])+uint32(offset))
+		}
+		body.Write(font)
+		body.Write(make([]byte, (4-len(font)%4)%4))
+	}
+	return append(header.Bytes(), body.Bytes()...)
+}
+
+func TestTrueTypeCollection(t *testing.T) {
+	ttc := makeTTC(t, "testdata/font/OpenSans-Regular.ttf", "testdata/font/glyfTest.ttf")
+
+	names, err := model.TTCFontNames(bytes.NewReader(ttc))
+	require.NoError(t, err)
+	require.Equal(t, []string{"OpenSans-Regular", "glyfTest"}, names)
+	index, err := model.TTCFontIndex(bytes.NewReader(ttc), "glyfTest")
+	require.NoError(t, err)
+	require.Equal(t, 1, index)
+	_, err = model.TTCFontIndex(bytes.NewReader(ttc), "Missing")
+	require.Error(t, err)
+
+	font, err := model.NewPdfFontFromTTC(bytes.NewReader(ttc), index)
+	require.NoError(t, err)
+	require.Equal(t, "glyfTest", font.BaseFont())
+	require.Equal(t, "TrueType", font.Subtype())
+
+	// The embedded font file only contains the selected font.
+	fontFile, ok := core.GetStream(font.FontDescriptor().FontFile2)
+	require.True(t, ok)
+	data, err := core.DecodeStream(fontFile)
+	require.NoError(t, err)
+	require.NotEqual(t, "ttcf", string(data[:4]))
+	embedded, err := model.NewPdfFontFromTTF(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, "glyfTest", embedded.BaseFont())
+
+	font, err = model.NewCompositePdfFontFromTTC(bytes.NewReader(ttc), 0)
+	require.NoError(t, err)
+	require.Equal(t, "OpenSans-Regular", font.BaseFont())
+	require.Equal(t, "Type0:CIDFontType2", font.Subtype())
+
+	// The TTF loading functions load the first font of collections.
+	font, err = model.NewPdfFontFromTTF(bytes.NewReader(ttc))
+	require.NoError(t, err)
+	require.Equal(t, "OpenSans-Regular", font.BaseFont())
+
+	_, err = model.NewPdfFontFromTTC(bytes.NewReader(ttc), 2)
+	require.Error(t, err)
+}
+
+func TestOpenTypeCFFFont(t *testing.T) {
+	// Simple font.
+	font, err := model.NewPdfFontFromTTFFile("testdata/font/CFFTest.otf")
+	require.NoError(t, err)
+	require.Equal(t, "CFFTest", font.BaseFont())
+	require.Equal(t, "Type1", font.Subtype())
+	descriptor := font.FontDescriptor()
+	require.Nil(t, descriptor.FontFile2)
+	fontFile, ok := core.GetStream(descriptor.FontFile3)
+	require.True(t, ok)
+	subtype, _ := core.GetNameVal(fontFile.Get("Subtype"))
+	require.Equal(t, "OpenType", subtype)
+
+	// Composite font.
+	font, err = model.NewCompositePdfFontFromTTFFile("testdata/font/CFFTest.otf")
+	require.NoError(t, err)
+	metrics, ok := font.GetRuneMetrics('A')
+	require.True(t, ok)
+	require.NotZero(t, metrics.Wx)
+
+	d, ok := core.GetDict(font.ToPdfObject())
+	require.True(t, ok)
+	descendants, ok := core.GetArray(d.Get("DescendantFonts"))
+	require.True(t, ok)
+	cidFont, ok := core.GetDict(descendants.Get(0))
+	require.True(t, ok)
+	subtype, _ = core.GetNameVal(cidFont.Get("Subtype"))
+	require.Equal(t, "CIDFontType0", subtype)
+	require.Nil(t, cidFont.Get("CIDToGIDMap"))
+	fd, ok := core.GetDict(cidFont.Get("FontDescriptor"))
+	require.True(t, ok)
+	require.NotNil(t, fd.Get("FontFile3"))
+
+	// The font can be loaded back.
+	loaded, err := model.NewPdfFontFromPdfObject(font.ToPdfObject())
+	require.NoError(t, err)
+	require.Equal(t, "CFFTest", loaded.BaseFont())
+}
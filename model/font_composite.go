@@ -7,11 +7,11 @@ package model
 
 import (
 	"bytes"
+	"crypto/md5"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"math/rand"
 	"os"
 	"sort"
 	"strings"
@@ -186,12 +186,14 @@ func makeSubsetName(name, tag string) string {
 	return tag + "+" + name
 }
 
-// Generates tag for subsetting with 6 random uppercase letters.
-func genSubsetTag() string {
+// Generates tag for subsetting with 6 uppercase letters, derived from the
+// subset font program `data` so that identical subsets get identical tags.
+func genSubsetTag(data []byte) string {
 	letters := "QWERTYUIOPASDFGHJKLZXCVBNM"
+	sum := md5.Sum(data)
 	var buf bytes.Buffer
 	for i := 0; i < 6; i++ {
-		buf.WriteRune(rune(letters[rand.Intn(len(letters))]))
+		buf.WriteRune(rune(letters[int(sum[i])%len(letters)]))
 	}
 	return buf.String()
 }
@@ -293,6 +295,7 @@ func (font *pdfFontType0) subsetRegistered() error {
 			codeToUnicode[cmap.CharCode(cc)] = r
 		}
 		font.toUnicodeCmap = cmap.NewToUnicodeCMap(codeToUnicode)
+		font.encodingVersion++
 	}
 
 	stream, err = core.MakeStream(buf.Bytes(), core.NewFlateEncoder())
@@ -309,7 +312,7 @@ func (font *pdfFontType0) subsetRegistered() error {
 	}
 
 	// Set subset name.
-	tag := genSubsetTag()
+	tag := genSubsetTag(buf.Bytes())
 
 	if len(font.basefont) > 0 {
 		font.basefont = makeSubsetName(font.basefont, tag)
@@ -425,6 +428,10 @@ type pdfCIDFontType0 struct {
 
 	widths       map[textencoding.CharCode]float64
 	defaultWidth float64
+
+	// Mapping between unicode runes to widths, for fonts loaded from font
+	// files.
+	runeToWidthMap map[rune]int
 }
 
 // pdfCIDFontType0FromSkeleton returns a pdfCIDFontType0 with its common fields initalized.
@@ -451,6 +458,9 @@ func (font pdfCIDFontType0) Encoder() textencoding.TextEncoder {
 // GetRuneMetrics returns the character metrics for the specified rune.
 // A bool flag is returned to indicate whether or not the entry was found.
 func (font pdfCIDFontType0) GetRuneMetrics(r rune) (fonts.CharMetrics, bool) {
+	if w, ok := font.runeToWidthMap[r]; ok {
+		return fonts.CharMetrics{Wx: float64(w)}, true
+	}
 	return fonts.CharMetrics{Wx: font.defaultWidth}, true
 }
 
@@ -466,7 +476,29 @@ func (font pdfCIDFontType0) GetCharMetrics(code textencoding.CharCode) (fonts.Ch
 
 // ToPdfObject converts the pdfCIDFontType0 to a PDF representation.
 func (font *pdfCIDFontType0) ToPdfObject() core.PdfObject {
-	return core.MakeNull()
+	if font.container == nil {
+		font.container = &core.PdfIndirectObject{}
+	}
+	d := font.baseFields().asPdfObjectDictionary("CIDFontType0")
+	font.container.PdfObject = d
+
+	if font.CIDSystemInfo != nil {
+		d.Set("CIDSystemInfo", font.CIDSystemInfo)
+	}
+	if font.DW != nil {
+		d.Set("DW", font.DW)
+	}
+	if font.DW2 != nil {
+		d.Set("DW2", font.DW2)
+	}
+	if font.W != nil {
+		d.Set("W", font.W)
+	}
+	if font.W2 != nil {
+		d.Set("W2", font.W2)
+	}
+
+	return font.container
 }
 
 // newPdfCIDFontType0FromPdfObject creates a pdfCIDFontType0 object from a dictionary (either direct
@@ -751,6 +783,9 @@ func NewCompositePdfFontFromTTFFile(filePath string) (*PdfFont, error) {
 // be used to represent unicode fonts which can have multi-byte character codes, representing a wide
 // range of values. They are often used for symbolic languages, including Chinese, Japanese and Korean.
 // It is represented by a Type0 Font with an underlying CIDFontType2 and an Identity-H encoding map.
+// OpenType fonts with PostScript (CFF) outlines (.otf) are represented by an underlying
+// CIDFontType0 instead. The first font of TrueType collections (.ttc) is loaded, see
+// NewCompositePdfFontFromTTC for selecting another font of the collection.
 // TODO: May be extended in the future to support a larger variety of CMaps and vertical fonts.
 // NOTE: For simple fonts, use NewPdfFontFromTTF.
 func NewCompositePdfFontFromTTF(r io.ReadSeeker) (*PdfFont, error) {
@@ -759,24 +794,23 @@ func NewCompositePdfFontFromTTF(r io.ReadSeeker) (*PdfFont, error) {
 		common.Log.Debug("ERROR: Unable to read font contents: %v", err)
 		return nil, err
 	}
+	if fonts.IsTtc(ttfBytes) {
+		if ttfBytes, err = fonts.TtcExtractFont(ttfBytes, 0); err != nil {
+			return nil, err
+		}
+	}
+	return newCompositePdfFontFromTTFData(ttfBytes)
+}
 
+// newCompositePdfFontFromTTFData loads a composite font from the TrueType or
+// OpenType font file `ttfBytes`, see NewCompositePdfFontFromTTF.
+func newCompositePdfFontFromTTFData(ttfBytes []byte) (*PdfFont, error) {
 	ttf, err := fonts.TtfParse(bytes.NewReader(ttfBytes))
 	if err != nil {
 		common.Log.Debug("ERROR: while loading ttf font: %v", err)
 		return nil, err
 	}
 
-	// Prepare the inner descendant font (CIDFontType2).
-	cidfont := &pdfCIDFontType2{
-		fontCommon: fontCommon{
-			subtype: "CIDFontType2",
-		},
-
-		// Use identity character id (CID) to glyph id (GID) mapping.
-		// Code below relies on the fact that identity mapping is used.
-		CIDToGIDMap: core.MakeName("Identity"),
-	}
-
 	// 2-byte character codes ➞ runes
 	runes := make([]rune, 0, len(ttf.Chars))
 	for r := range ttf.Chars {
@@ -787,6 +821,18 @@ func NewCompositePdfFontFromTTF(r io.ReadSeeker) (*PdfFont, error) {
 		return runes[i] < runes[j]
 	})
 
+	// Map runes to CIDs. The CIDs are the GIDs, except for CID-keyed CFF
+	// fonts whose charset maps the GIDs to CIDs.
+	cids := ttf.Chars
+	if ttf.GlyphCIDs != nil {
+		cids = make(map[rune]fonts.GID, len(ttf.Chars))
+		for r, gid := range ttf.Chars {
+			if int(gid) < len(ttf.GlyphCIDs) {
+				cids[r] = fonts.GID(ttf.GlyphCIDs[gid])
+			}
+		}
+	}
+
 	k := 1000.0 / float64(ttf.UnitsPerEm)
 
 	if len(ttf.Widths) <= 0 {
@@ -803,20 +849,17 @@ func NewCompositePdfFontFromTTF(r io.ReadSeeker) (*PdfFont, error) {
 		w := k * float64(ttf.Widths[gid])
 		runeToWidthMap[r] = int(w)
 	}
-	cidfont.runeToWidthMap = runeToWidthMap
 
 	// Default width.
-	cidfont.DW = core.MakeInteger(int64(missingWidth))
+	dw := core.MakeInteger(int64(missingWidth))
 
 	// Construct W array.  Stores character code to width mappings.
-	wArr := makeCIDWidthArr(runes, runeToWidthMap, ttf.Chars)
-	cidfont.W = core.MakeIndirectObject(wArr)
+	wArr := makeCIDWidthArr(runes, runeToWidthMap, cids)
 
 	d := core.MakeDict()
 	d.Set("Ordering", core.MakeString("Identity"))
 	d.Set("Registry", core.MakeString("Adobe"))
 	d.Set("Supplement", core.MakeInteger(0))
-	cidfont.CIDSystemInfo = d
 
 	// Make the font descriptor.
 	descriptor := &PdfFontDescriptor{
@@ -834,14 +877,10 @@ func NewCompositePdfFontFromTTF(r io.ReadSeeker) (*PdfFont, error) {
 		MissingWidth: core.MakeFloat(k * float64(ttf.Widths[0])),
 	}
 
-	// Embed the TrueType font program.
-	stream, err := core.MakeStream(ttfBytes, core.NewFlateEncoder())
-	if err != nil {
-		common.Log.Debug("ERROR: Unable to make stream: %v", err)
+	// Embed the font program.
+	if err := embedFontFile(descriptor, &ttf, ttfBytes); err != nil {
 		return nil, err
 	}
-	stream.PdfObjectDictionary.Set("Length1", core.MakeInteger(int64(len(ttfBytes))))
-	descriptor.FontFile2 = stream
 
 	if ttf.Bold {
 		descriptor.StemV = core.MakeInteger(120)
@@ -859,8 +898,41 @@ func NewCompositePdfFontFromTTF(r io.ReadSeeker) (*PdfFont, error) {
 	}
 	descriptor.Flags = core.MakeInteger(int64(flags))
 
-	cidfont.basefont = ttf.PostScriptName
-	cidfont.fontDescriptor = descriptor
+	// Prepare the inner descendant font: CIDFontType0 for CFF outlines,
+	// CIDFontType2 otherwise.
+	var descendant pdfFont
+	if ttf.CFF {
+		cidfont := &pdfCIDFontType0{
+			fontCommon: fontCommon{
+				subtype:        "CIDFontType0",
+				basefont:       ttf.PostScriptName,
+				fontDescriptor: descriptor,
+			},
+			CIDSystemInfo:  d,
+			DW:             dw,
+			W:              core.MakeIndirectObject(wArr),
+			defaultWidth:   missingWidth,
+			runeToWidthMap: runeToWidthMap,
+		}
+		descendant = cidfont
+	} else {
+		cidfont := &pdfCIDFontType2{
+			fontCommon: fontCommon{
+				subtype:        "CIDFontType2",
+				basefont:       ttf.PostScriptName,
+				fontDescriptor: descriptor,
+			},
+			CIDSystemInfo: d,
+			DW:            dw,
+			W:             core.MakeIndirectObject(wArr),
+
+			// Use identity character id (CID) to glyph id (GID) mapping.
+			// Code below relies on the fact that identity mapping is used.
+			CIDToGIDMap:    core.MakeName("Identity"),
+			runeToWidthMap: runeToWidthMap,
+		}
+		descendant = cidfont
+	}
 
 	// Make root Type0 font.
 	type0 := pdfFontType0{
@@ -869,19 +941,19 @@ func NewCompositePdfFontFromTTF(r io.ReadSeeker) (*PdfFont, error) {
 			basefont: ttf.PostScriptName,
 		},
 		DescendantFont: &PdfFont{
-			context: cidfont,
+			context: descendant,
 		},
 		Encoding: core.MakeName("Identity-H"),
-		encoder:  ttf.NewEncoder(),
+		encoder:  textencoding.NewTrueTypeFontEncoder(cids),
 	}
 
-	// Generate CMap for the Type 0 font, which is the inverse of ttf.Chars.
-	if len(ttf.Chars) > 0 {
-		codeToUnicode := make(map[cmap.CharCode]rune, len(ttf.Chars))
-		for r, gid := range ttf.Chars {
-			cid := cmap.CharCode(gid)
-			if rn, ok := codeToUnicode[cid]; !ok || (ok && rn > r) {
-				codeToUnicode[cid] = r
+	// Generate CMap for the Type 0 font, which is the inverse of cids.
+	if len(cids) > 0 {
+		codeToUnicode := make(map[cmap.CharCode]rune, len(cids))
+		for r, cid := range cids {
+			code := cmap.CharCode(cid)
+			if rn, ok := codeToUnicode[code]; !ok || (ok && rn > r) {
+				codeToUnicode[code] = r
 			}
 		}
 		type0.toUnicodeCmap = cmap.NewToUnicodeCMap(codeToUnicode)
@@ -895,6 +967,32 @@ func NewCompositePdfFontFromTTF(r io.ReadSeeker) (*PdfFont, error) {
 	return &font, nil
 }
 
+// embedFontFile embeds the font file `data` described by `ttf` in the font
+// descriptor `descriptor`: as a FontFile2 TrueType font program, or as a
+// FontFile3 OpenType font program for fonts with CFF outlines. The licensing
+// rights of the font are set in the descriptor, see FontLicensing.
+func embedFontFile(descriptor *PdfFontDescriptor, ttf *fonts.TtfType, data []byte) error {
+	descriptor.licensing = newFontLicensing(ttf)
+	if l := descriptor.licensing; l != nil && !l.Embeddable() {
+		common.Log.Debug("WARNING: Embedding font %q not permitted by its license (%s)",
+			ttf.PostScriptName, l)
+	}
+
+	stream, err := core.MakeStream(data, core.NewFlateEncoder())
+	if err != nil {
+		common.Log.Debug("ERROR: Unable to make stream: %v", err)
+		return err
+	}
+	if ttf.CFF {
+		stream.PdfObjectDictionary.Set("Subtype", core.MakeName("OpenType"))
+		descriptor.FontFile3 = stream
+		return nil
+	}
+	stream.PdfObjectDictionary.Set("Length1", core.MakeInteger(int64(len(data))))
+	descriptor.FontFile2 = stream
+	return nil
+}
+
 func makeCIDWidthArr(runes []rune, widths map[rune]int, gids map[rune]fonts.GID) *core.PdfObjectArray {
 	// Construct W array. Stores character code to width mappings.
 	arr := &core.PdfObjectArray{}
@@ -0,0 +1,147 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model/internal/fonts"
+)
+
+// Flags of the fsType field of the OS/2 table.
+const (
+	fsTypeRestricted      = 0x0002
+	fsTypePreviewAndPrint = 0x0004
+	fsTypeEditable        = 0x0008
+	fsTypeNoSubsetting    = 0x0100
+	fsTypeBitmapOnly      = 0x0200
+)
+
+// FontLicensing describes the licensing rights for embedding a font program, as given by the
+// fsType field of the OS/2 table of TrueType and OpenType fonts.
+// See the OpenType specification of the OS/2 table.
+type FontLicensing struct {
+	// FsType is the value of the fsType field.
+	FsType uint16
+}
+
+// Installable returns true if the font can be embedded and permanently installed, i.e. it has no
+// usage restrictions.
+func (l FontLicensing) Installable() bool {
+	return l.FsType&0x000f == 0
+}
+
+// Restricted returns true if the font must not be embedded without the permission of the legal
+// owner. When several usage permissions are set, the least restrictive one applies.
+func (l FontLicensing) Restricted() bool {
+	return l.FsType&fsTypeRestricted != 0 && l.FsType&(fsTypePreviewAndPrint|fsTypeEditable) == 0
+}
+
+// PreviewAndPrint returns true if the font can only be embedded in documents opened read-only,
+// for previewing and printing.
+func (l FontLicensing) PreviewAndPrint() bool {
+	return l.FsType&fsTypePreviewAndPrint != 0 && l.FsType&fsTypeEditable == 0
+}
+
+// Editable returns true if the font can be embedded in documents which can be edited.
+func (l FontLicensing) Editable() bool {
+	return l.FsType&fsTypeEditable != 0
+}
+
+// NoSubsetting returns true if the font must not be subsetted before embedding.
+func (l FontLicensing) NoSubsetting() bool {
+	return l.FsType&fsTypeNoSubsetting != 0
+}
+
+// BitmapOnly returns true if only the bitmaps of the font can be embedded, not its outlines.
+func (l FontLicensing) BitmapOnly() bool {
+	return l.FsType&fsTypeBitmapOnly != 0
+}
+
+// Embeddable returns true if the outlines of the font can be embedded in documents.
+func (l FontLicensing) Embeddable() bool {
+	return !l.Restricted() && !l.BitmapOnly()
+}
+
+// String returns a description of the licensing rights, e.g. "preview and print, no subsetting".
+func (l FontLicensing) String() string {
+	var parts []string
+	switch {
+	case l.Installable():
+		parts = append(parts, "installable")
+	case l.Editable():
+		parts = append(parts, "editable")
+	case l.PreviewAndPrint():
+		parts = append(parts, "preview and print")
+	default:
+		parts = append(parts, "restricted")
+	}
+	if l.NoSubsetting() {
+		parts = append(parts, "no subsetting")
+	}
+	if l.BitmapOnly() {
+		parts = append(parts, "bitmap only")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Licensing returns the licensing rights of the embedded TrueType or OpenType font program of the
+// font. The bool flag is false if the font has no such program or its rights are not specified.
+func (font *PdfFont) Licensing() (FontLicensing, bool) {
+	descriptor := font.baseFields().fontDescriptor
+	if descriptor == nil && font.context != nil {
+		descriptor = font.context.getFontDescriptor()
+	}
+	if descriptor == nil {
+		return FontLicensing{}, false
+	}
+	return descriptor.Licensing()
+}
+
+// Licensing returns the licensing rights of the TrueType (FontFile2) or OpenType (FontFile3)
+// font program of the font descriptor. The bool flag is false if the descriptor has no such
+// program or its rights are not specified.
+func (desc *PdfFontDescriptor) Licensing() (FontLicensing, bool) {
+	if desc.licensing == nil {
+		return FontLicensing{}, false
+	}
+	return *desc.licensing, true
+}
+
+// newFontLicensing returns the licensing rights of the font described by `ttf`, nil if the font
+// has no OS/2 table.
+func newFontLicensing(ttf *fonts.TtfType) *FontLicensing {
+	if !ttf.HasOS2 {
+		return nil
+	}
+	return &FontLicensing{FsType: ttf.FsType}
+}
+
+// loadFontFile3Licensing returns the licensing rights of the FontFile3 font program `obj`. Only
+// OpenType programs specify licensing rights: nil is returned for bare CFF programs.
+func loadFontFile3Licensing(obj core.PdfObject) *FontLicensing {
+	stream, ok := core.GetStream(obj)
+	if !ok {
+		return nil
+	}
+	if subtype, _ := core.GetNameVal(stream.Get("Subtype")); subtype != "OpenType" {
+		return nil
+	}
+	data, err := core.DecodeStream(stream)
+	if err != nil {
+		common.Log.Debug("ERROR: Unable to decode FontFile3: %v", err)
+		return nil
+	}
+	ttf, err := fonts.TtfParse(bytes.NewReader(data))
+	if err != nil {
+		common.Log.Debug("ERROR: Unable to parse OpenType FontFile3: %v", err)
+		return nil
+	}
+	return newFontLicensing(&ttf)
+}
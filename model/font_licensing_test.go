@@ -0,0 +1,84 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// setFsType returns a copy of the font file `data` with the fsType field of its OS/2 table set to
+// `fsType`.
+func setFsType(t *testing.T, data []byte, fsType uint16) []byte {
+	data = append([]byte{}, data...)
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		rec := data[12+16*i:]
+		if string(rec[:4]) == "OS/2" {
+			offset := binary.BigEndian.Uint32(rec[8:])
+			binary.BigEndian.PutUint16(data[offset+8:], fsType)
+			return data
+		}
+	}
+	t.Fatalf("no OS/2 table")
+	return nil
+}
+
+func TestFontLicensing(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/font/OpenSans-Regular.ttf")
+	require.NoError(t, err)
+
+	// Restricted license embedding, no subsetting.
+	font, err := model.NewPdfFontFromTTF(bytes.NewReader(setFsType(t, data, 0x0102)))
+	require.NoError(t, err)
+	licensing, ok := font.Licensing()
+	require.True(t, ok)
+	require.Equal(t, uint16(0x0102), licensing.FsType)
+	require.True(t, licensing.Restricted())
+	require.True(t, licensing.NoSubsetting())
+	require.False(t, licensing.Embeddable())
+	require.Equal(t, "restricted, no subsetting", licensing.String())
+
+	// The licensing rights are loaded from embedded FontFile2 programs.
+	loaded, err := model.NewPdfFontFromPdfObject(font.ToPdfObject())
+	require.NoError(t, err)
+	licensing, ok = loaded.Licensing()
+	require.True(t, ok)
+	require.True(t, licensing.Restricted())
+
+	// When several usage permissions are set, the least restrictive one applies.
+	font, err = model.NewCompositePdfFontFromTTF(bytes.NewReader(setFsType(t, data, 0x0006)))
+	require.NoError(t, err)
+	licensing, ok = font.Licensing()
+	require.True(t, ok)
+	require.True(t, licensing.PreviewAndPrint())
+	require.False(t, licensing.Restricted())
+	require.True(t, licensing.Embeddable())
+
+	// OpenType FontFile3 programs.
+	otf, err := ioutil.ReadFile("testdata/font/CFFTest.otf")
+	require.NoError(t, err)
+	font, err = model.NewPdfFontFromTTF(bytes.NewReader(setFsType(t, otf, 0x0008)))
+	require.NoError(t, err)
+	loaded, err = model.NewPdfFontFromPdfObject(font.ToPdfObject())
+	require.NoError(t, err)
+	licensing, ok = loaded.Licensing()
+	require.True(t, ok)
+	require.True(t, licensing.Editable())
+	require.Equal(t, "editable", licensing.String())
+
+	// Standard 14 fonts have no font program.
+	font, err = model.NewStandard14Font(model.HelveticaName)
+	require.NoError(t, err)
+	_, ok = font.Licensing()
+	require.False(t, ok)
+}
@@ -108,6 +108,7 @@ func (font *pdfFontSimple) Encoder() textencoding.TextEncoder {
 // TODO(gunnsth): Makes sense if SetEncoder is removed from the interface fonts.Font as proposed in PR #260.
 func (font *pdfFontSimple) SetEncoder(encoder textencoding.TextEncoder) {
 	font.encoder = encoder
+	font.encodingVersion++
 }
 
 // GetRuneMetrics returns the character metrics for the rune.
@@ -400,26 +401,45 @@ func NewPdfFontFromTTFFile(filePath string) (*PdfFont, error) {
 // NewPdfFontFromTTF loads a TTF font and returns a PdfFont type that can be
 // used in text styling functions.
 // Uses a WinAnsiTextEncoder and loads only character codes 32-255.
+// OpenType fonts with PostScript (CFF) outlines (.otf) are loaded as Type1
+// fonts embedding the OpenType font program. The first font of TrueType
+// collections (.ttc) is loaded, see NewPdfFontFromTTC for selecting another
+// font of the collection.
 // NOTE: For composite fonts such as used in symbolic languages, use NewCompositePdfFontFromTTF.
 func NewPdfFontFromTTF(r io.ReadSeeker) (*PdfFont, error) {
-	const minCode = textencoding.CharCode(32)
-	const maxCode = textencoding.CharCode(255)
-
 	ttfBytes, err := ioutil.ReadAll(r)
 	if err != nil {
 		common.Log.Debug("ERROR: Unable to read font contents: %v", err)
 		return nil, err
 	}
+	if fonts.IsTtc(ttfBytes) {
+		if ttfBytes, err = fonts.TtcExtractFont(ttfBytes, 0); err != nil {
+			return nil, err
+		}
+	}
+	return newPdfFontFromTTFData(ttfBytes)
+}
+
+// newPdfFontFromTTFData loads a simple font from the TrueType or OpenType
+// font file `ttfBytes`, see NewPdfFontFromTTF.
+func newPdfFontFromTTFData(ttfBytes []byte) (*PdfFont, error) {
+	const minCode = textencoding.CharCode(32)
+	const maxCode = textencoding.CharCode(255)
+
 	ttf, err := fonts.TtfParse(bytes.NewReader(ttfBytes))
 	if err != nil {
 		common.Log.Debug("ERROR: loading TTF font: %v", err)
 		return nil, err
 	}
 
+	subtype := "TrueType"
+	if ttf.CFF {
+		subtype = "Type1"
+	}
 	truefont := &pdfFontSimple{
 		charWidths: make(map[textencoding.CharCode]float64),
 		fontCommon: fontCommon{
-			subtype: "TrueType",
+			subtype: subtype,
 		},
 	}
 
@@ -481,13 +501,9 @@ func NewPdfFontFromTTF(r io.ReadSeeker) (*PdfFont, error) {
 	descriptor.ItalicAngle = core.MakeFloat(float64(ttf.ItalicAngle))
 	descriptor.MissingWidth = core.MakeFloat(k * float64(ttf.Widths[0]))
 
-	stream, err := core.MakeStream(ttfBytes, core.NewFlateEncoder())
-	if err != nil {
-		common.Log.Debug("ERROR: Unable to make stream: %v", err)
+	if err := embedFontFile(descriptor, &ttf, ttfBytes); err != nil {
 		return nil, err
 	}
-	stream.PdfObjectDictionary.Set("Length1", core.MakeInteger(int64(len(ttfBytes))))
-	descriptor.FontFile2 = stream
 
 	if ttf.Bold {
 		descriptor.StemV = core.MakeInteger(120)
@@ -13,6 +13,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
 
 	"github.com/unidoc/unipdf/v3/common"
 	"github.com/unidoc/unipdf/v3/core"
@@ -885,3 +886,70 @@ func TestNewFontFromFile(t *testing.T) {
 		t.Fatalf("Failed to load font from file. err=%v", err)
 	}
 }
+
+func TestFontError(t *testing.T) {
+	d, err := core.NewParserFromString(`<< /Type /Font /Subtype /Type3 /FontMatrix [0.001 0 0 0.001 0 0]
+		/FirstChar 32 /LastChar 32 /Widths [250] >>`).ParseDict()
+	require.NoError(t, err)
+	ind := core.MakeIndirectObject(d)
+	ind.ObjectNumber = 7
+
+	// Unsupported fonts are returned with the error.
+	font, err := model.NewPdfFontFromPdfObject(ind)
+	require.NotNil(t, font)
+	var fontErr *model.FontError
+	require.True(t, xerrors.As(err, &fontErr))
+	require.Equal(t, int64(7), fontErr.ObjNum)
+	require.Equal(t, "Type3", fontErr.Subtype)
+	require.True(t, xerrors.Is(err, model.ErrType3FontNotSupported))
+	require.True(t, xerrors.Is(err, core.ErrNotSupported))
+
+	// Invalid fonts.
+	_, err = model.NewPdfFontFromPdfObject(core.MakeInteger(1))
+	require.True(t, xerrors.As(err, &fontErr))
+	require.Zero(t, fontErr.ObjNum)
+	require.False(t, xerrors.Is(err, core.ErrNotSupported))
+}
+
+func TestFontGetWidths(t *testing.T) {
+	// Standard 14 font.
+	font, err := model.NewStandard14Font(model.HelveticaName)
+	require.NoError(t, err)
+	widths, defaultWidth := font.GetWidths()
+	require.Equal(t, 667.0, widths['A'])
+	require.Equal(t, 250.0, defaultWidth)
+
+	// The widths are consistent with GetCharMetrics.
+	checkWidths := func(font *model.PdfFont) {
+		widths, defaultWidth := font.GetWidths()
+		require.NotEmpty(t, widths)
+		for code := textencoding.CharCode(0); code < 512; code++ {
+			metrics, ok := font.GetCharMetrics(code)
+			require.True(t, ok)
+			width, ok := widths[code]
+			if !ok {
+				width = defaultWidth
+			}
+			require.Equal(t, metrics.Wx, width, "code=%d", code)
+		}
+	}
+
+	// Simple TrueType font, created and loaded.
+	font, err = model.NewPdfFontFromTTFFile("testdata/font/OpenSans-Regular.ttf")
+	require.NoError(t, err)
+	checkWidths(font)
+	loaded, err := model.NewPdfFontFromPdfObject(font.ToPdfObject())
+	require.NoError(t, err)
+	checkWidths(loaded)
+
+	// Composite TrueType font: the widths of created fonts are given by their W array.
+	font, err = model.NewCompositePdfFontFromTTFFile("testdata/font/OpenSans-Regular.ttf")
+	require.NoError(t, err)
+	widths, defaultWidth = font.GetWidths()
+	loaded, err = model.NewPdfFontFromPdfObject(font.ToPdfObject())
+	require.NoError(t, err)
+	checkWidths(loaded)
+	loadedWidths, loadedDefaultWidth := loaded.GetWidths()
+	require.Equal(t, loadedWidths, widths)
+	require.Equal(t, loadedDefaultWidth, defaultWidth)
+}
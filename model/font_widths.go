@@ -0,0 +1,83 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/textencoding"
+	"github.com/unidoc/unipdf/v3/model/internal/fonts"
+)
+
+// GetWidths returns the glyph widths of the character codes of the font in glyph space units
+// (thousandths of text space units), as given by the Widths array of simple fonts, the W array of
+// composite fonts or the font metrics of the standard 14 fonts, and the default width of the codes
+// which are not in the map: the DW entry of composite fonts, the MissingWidth entry of simple
+// fonts or 250 for the standard 14 fonts. The character codes of composite fonts are their CIDs.
+// The returned map can be modified by the caller.
+func (font *PdfFont) GetWidths() (map[textencoding.CharCode]float64, float64) {
+	switch t := font.context.(type) {
+	case *pdfFontSimple:
+		widths := make(map[textencoding.CharCode]float64, len(t.charWidths))
+		for code, w := range t.charWidths {
+			widths[code] = w
+		}
+		if len(widths) == 0 && t.fontMetrics != nil {
+			// Standard 14 fonts which were not loaded from a PDF: use the AFM metrics of the
+			// runes of the codes of the encoding.
+			if se, ok := t.Encoder().(textencoding.SimpleEncoder); ok {
+				for _, code := range se.Charcodes() {
+					r, _ := se.CharcodeToRune(code)
+					if metrics, ok := t.fontMetrics[r]; ok {
+						widths[code] = metrics.Wx
+					}
+				}
+			}
+		}
+		if fonts.IsStdFont(fonts.StdFontName(t.basefont)) {
+			// Same as GetCharMetrics.
+			return widths, 250
+		}
+		var defaultWidth float64
+		if descriptor, err := font.GetFontDescriptor(); err == nil && descriptor != nil {
+			defaultWidth = descriptor.missingWidth
+		}
+		return widths, defaultWidth
+	case *pdfFontType0:
+		if t.DescendantFont == nil {
+			common.Log.Debug("ERROR: No descendant. font=%s", font)
+			return map[textencoding.CharCode]float64{}, 0
+		}
+		return t.DescendantFont.GetWidths()
+	case *pdfCIDFontType0:
+		return cidFontWidths(t.widths, t.W, t.DW, t.defaultWidth)
+	case *pdfCIDFontType2:
+		return cidFontWidths(t.widths, t.W, t.DW, t.defaultWidth)
+	}
+	common.Log.Debug("ERROR: GetWidths not implemented for font type=%T.", font.context)
+	return map[textencoding.CharCode]float64{}, 0
+}
+
+// cidFontWidths returns a copy of the widths `widths` of a CID font, parsed from its W array `w`
+// if not loaded, and its default width: its DW entry `dw` if set, `defaultWidth` otherwise.
+func cidFontWidths(widths map[textencoding.CharCode]float64, w, dw core.PdfObject,
+	defaultWidth float64) (map[textencoding.CharCode]float64, float64) {
+	if widths == nil {
+		parsed, err := parseCIDFontWidthsArray(w)
+		if err != nil {
+			common.Log.Debug("ERROR: Invalid W array: %v", err)
+		}
+		widths = parsed
+	}
+	copied := make(map[textencoding.CharCode]float64, len(widths))
+	for code, width := range widths {
+		copied[code] = width
+	}
+	if width, err := core.GetNumberAsFloat(dw); err == nil {
+		defaultWidth = width
+	}
+	return copied, defaultWidth
+}
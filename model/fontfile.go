@@ -49,8 +49,6 @@ func (fontfile *fontFile) String() string {
 // *PdfIndirectObject or a *PdfObjectDictionary.
 func newFontFileFromPdfObject(obj core.PdfObject) (*fontFile, error) {
 	common.Log.Trace("newFontFileFromPdfObject: obj=%s", obj)
-	fontfile := &fontFile{}
-
 	obj = core.TraceToDirectObject(obj)
 
 	streamObj, ok := obj.(*core.PdfObjectStream)
@@ -58,12 +56,17 @@ func newFontFileFromPdfObject(obj core.PdfObject) (*fontFile, error) {
 		common.Log.Debug("ERROR: FontFile must be a stream (%T)", obj)
 		return nil, core.ErrTypeError
 	}
-	d := streamObj.PdfObjectDictionary
 	data, err := core.DecodeStream(streamObj)
 	if err != nil {
 		return nil, err
 	}
+	return newFontFileFromData(streamObj.PdfObjectDictionary, data)
+}
 
+// newFontFileFromData loads a FontFile from the decoded data `data` of a
+// FontFile stream with dictionary `d`.
+func newFontFileFromData(d *core.PdfObjectDictionary, data []byte) (*fontFile, error) {
+	fontfile := &fontFile{}
 	subtype, ok := core.GetNameVal(d.Get("Subtype"))
 	if !ok {
 		fontfile.subtype = subtype
@@ -305,7 +305,7 @@ func (form *PdfAcroForm) fill(provider FieldValueProvider, appGen FieldAppearanc
 
 // fillFieldValue populates form field `f` with value represented by `v`.
 func fillFieldValue(f *PdfField, val core.PdfObject) error {
-	switch f.GetContext().(type) {
+	switch ctx := f.GetContext().(type) {
 	case *PdfFieldText:
 		switch t := val.(type) {
 		case *core.PdfObjectName:
@@ -319,7 +319,7 @@ func fillFieldValue(f *PdfField, val core.PdfObject) error {
 		}
 	case *PdfFieldButton:
 		// See section 12.7.4.2.3 "Check Boxes" (pp. 440-441 PDF32000_2008).
-		switch val.(type) {
+		switch t := val.(type) {
 		case *core.PdfObjectName:
 			if len(val.String()) > 0 {
 				f.V = val
@@ -330,23 +330,43 @@ func fillFieldValue(f *PdfField, val core.PdfObject) error {
 				f.V = core.MakeName(val.String())
 				setFieldAnnotAS(f, f.V)
 			}
+		case *core.PdfObjectBool:
+			// Select the first "on" state of the widgets if true.
+			state := core.MakeName("Off")
+			if bool(*t) {
+				state = core.MakeName("Yes")
+				for _, wa := range f.Annotations {
+					if states := widgetOnStates(wa); len(states) > 0 {
+						state = core.MakeName(states[0])
+						break
+					}
+				}
+			}
+			f.V = state
+			setFieldAnnotAS(f, state)
 		default:
 			common.Log.Debug("ERROR: UNEXPECTED %s -> %v", f.PartialName(), val)
 			f.V = val
 		}
 	case *PdfFieldChoice:
 		// See section 12.7.4.4 "Choice Fields" (pp. 444-446 PDF32000_2008).
-		switch val.(type) {
+		switch t := val.(type) {
 		case *core.PdfObjectName:
 			if len(val.String()) > 0 {
 				f.V = core.MakeString(val.String())
+				setChoiceIndices(ctx)
 				setFieldAnnotAS(f, val)
 			}
 		case *core.PdfObjectString:
 			if len(val.String()) > 0 {
 				f.V = val
+				setChoiceIndices(ctx)
 				setFieldAnnotAS(f, core.MakeName(val.String()))
 			}
+		case *core.PdfObjectArray:
+			// Multiple selection.
+			f.V = t
+			setChoiceIndices(ctx)
 		default:
 			common.Log.Debug("ERROR: UNEXPECTED %s -> %v", f.PartialName(), val)
 			f.V = val
@@ -359,9 +379,26 @@ func fillFieldValue(f *PdfField, val core.PdfObject) error {
 }
 
 // setFieldAnnotAS sets the appearance stream of the field annotations to `val`.
+// Widgets which have appearance states, but not the `val` state (e.g. the
+// other buttons of a radio group), are set to the Off state.
 func setFieldAnnotAS(f *PdfField, val core.PdfObject) {
+	state, _ := core.GetNameVal(val)
 	for _, wa := range f.Annotations {
-		wa.AS = val
+		as := val
+		if states := widgetOnStates(wa); len(states) > 0 && state != "Off" {
+			found := false
+			for _, s := range states {
+				if s == state {
+					found = true
+					break
+				}
+			}
+			if !found {
+				as = core.MakeName("Off")
+			}
+		}
+
+		wa.AS = as
 		wa.ToPdfObject()
 	}
 }
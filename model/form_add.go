@@ -0,0 +1,134 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// AddField adds the field `field` to the root fields of the form and adds
+// its widget annotations, and the widgets of its descendants, to the
+// annotations of the page `page`. The location of the widgets on the page is
+// specified by their Rect entries. The default resources (DR) and the
+// default appearance (DA) of the form are initialized if not set, with the
+// Helvetica (Helv) and ZapfDingbats (ZaDb) fonts used by viewers for the
+// appearance of the fields. An error is returned if the form already has a
+// field with the same name.
+func (form *PdfAcroForm) AddField(page *PdfPage, field *PdfField) error {
+	if page == nil {
+		return errors.New("page cannot be nil")
+	}
+	if field == nil {
+		return errors.New("field cannot be nil")
+	}
+	name := field.PartialName()
+	if form.Fields != nil {
+		for _, f := range *form.Fields {
+			if f == field {
+				return errors.New("field already added")
+			}
+			if f.PartialName() == name {
+				return fmt.Errorf("field %s already exists", name)
+			}
+		}
+	}
+	if err := form.setFieldDefaults(); err != nil {
+		return err
+	}
+
+	for _, f := range flattenFields(field) {
+		for _, wa := range f.Annotations {
+			wa.P = page.ToPdfObject()
+			page.AddAnnotation(wa.PdfAnnotation)
+		}
+	}
+
+	var fields []*PdfField
+	if form.Fields != nil {
+		fields = *form.Fields
+	}
+	fields = append(fields, field)
+	form.Fields = &fields
+	return nil
+}
+
+// setFieldDefaults initializes the default resources (DR) and the default
+// appearance (DA) of the form, if not set.
+func (form *PdfAcroForm) setFieldDefaults() error {
+	if form.DR == nil {
+		form.DR = NewPdfPageResources()
+	}
+	for _, font := range []struct {
+		name core.PdfObjectName
+		font StdFontName
+	}{
+		{"Helv", HelveticaName},
+		{"ZaDb", ZapfDingbatsName},
+	} {
+		if form.DR.HasFontByName(font.name) {
+			continue
+		}
+		stdFont, err := NewStandard14Font(font.font)
+		if err != nil {
+			return err
+		}
+		if err := form.DR.SetFontByName(font.name, stdFont.ToPdfObject()); err != nil {
+			return err
+		}
+	}
+	if form.DA == nil {
+		form.DA = core.MakeString("/Helv 0 Tf 0 g")
+	}
+	return nil
+}
+
+// AddFormField adds the field `field` to the interactive form of the
+// document, creating the form if not set, and adds the widget annotations of
+// the field to the page `page` (see PdfAcroForm.AddField). The page must be
+// added to the writer after its fields.
+func (w *PdfWriter) AddFormField(page *PdfPage, field *PdfField) error {
+	if w.acroForm == nil {
+		w.acroForm = NewPdfAcroForm()
+	}
+	return w.acroForm.AddField(page, field)
+}
+
+// AddFormField adds the field `field` to the interactive form of the
+// document, creating the form if the document has none, and adds the widget
+// annotations of the field to the page `pageNum`. The widgets are located by
+// their Rect entries, in the coordinates of the page. This allows adding
+// fillable fields to documents which are not interactive forms.
+func (a *PdfAppender) AddFormField(pageNum int, field *PdfField) error {
+	pageIndex := pageNum - 1
+	if pageIndex < 0 || pageIndex > len(a.pages)-1 {
+		return fmt.Errorf("page %d not found", pageNum)
+	}
+
+	// Modify the page of the original reader, unless the page was replaced.
+	page := a.pages[pageIndex]
+	if pageIndex < len(a.roReader.PageList) && page == a.roReader.PageList[pageIndex] {
+		page = a.Reader.PageList[pageIndex]
+	}
+
+	if a.acroForm == a.roReader.AcroForm {
+		a.acroForm = a.Reader.AcroForm
+	}
+	acroForm := a.acroForm
+	if acroForm == nil {
+		acroForm = NewPdfAcroForm()
+	}
+	if err := acroForm.AddField(page, field); err != nil {
+		return err
+	}
+	a.ReplaceAcroForm(acroForm)
+
+	a.UpdatePage(page)
+	a.pages[pageIndex] = page
+	return nil
+}
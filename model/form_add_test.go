@@ -0,0 +1,51 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestWriterAddFormField(t *testing.T) {
+	newField := func(name string) *PdfField {
+		field := NewPdfField()
+		ftxt := &PdfFieldText{}
+		field.SetContext(ftxt)
+		ftxt.PdfField = field
+		ftxt.T = core.MakeString(name)
+
+		widget := NewPdfAnnotationWidget()
+		widget.Rect = core.MakeArrayFromFloats([]float64{100, 100, 200, 120})
+		widget.Parent = ftxt.ToPdfObject()
+		ftxt.Annotations = append(ftxt.Annotations, widget)
+		return field
+	}
+
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	require.NoError(t, w.AddFormField(page, newField("first")))
+	require.NoError(t, w.AddFormField(page, newField("second")))
+	require.Error(t, w.AddFormField(page, newField("first")))
+	require.NoError(t, w.AddPage(page))
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	fields, err := r.GetFormFields()
+	require.NoError(t, err)
+	require.Len(t, fields, 2)
+	for _, field := range fields {
+		require.Equal(t, []int{1}, field.Pages())
+	}
+	require.True(t, r.AcroForm.DR.HasFontByName("Helv"))
+}
@@ -0,0 +1,389 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// FormFieldType represents the resolved type of a terminal form field.
+type FormFieldType int
+
+// Form field types.
+const (
+	FormFieldTypeUnknown FormFieldType = iota
+	FormFieldTypeText
+	FormFieldTypeCheckbox
+	FormFieldTypeRadioGroup
+	FormFieldTypePushButton
+	FormFieldTypeComboBox
+	FormFieldTypeListBox
+	FormFieldTypeSignature
+)
+
+// String returns a string representation of the form field type.
+func (t FormFieldType) String() string {
+	switch t {
+	case FormFieldTypeText:
+		return "TextField"
+	case FormFieldTypeCheckbox:
+		return "Checkbox"
+	case FormFieldTypeRadioGroup:
+		return "RadioGroup"
+	case FormFieldTypePushButton:
+		return "PushButton"
+	case FormFieldTypeComboBox:
+		return "ComboBox"
+	case FormFieldTypeListBox:
+		return "ListBox"
+	case FormFieldTypeSignature:
+		return "Signature"
+	}
+	return "Unknown"
+}
+
+// FormFieldOption represents an option of a choice field or an "on" state of
+// a button field. The export value is the value stored in the field, while
+// the display value is the text shown to the user.
+type FormFieldOption struct {
+	Export  string
+	Display string
+}
+
+// FormFieldWidget represents a widget annotation of a form field, along with
+// the number of the page it appears on. The page number is 0 if the widget is
+// not referenced by any of the pages of the document.
+type FormFieldWidget struct {
+	Annotation *PdfAnnotationWidget
+	PageNum    int
+}
+
+// FormField represents a terminal form field with resolved, typed values.
+// The values take into account the inheritable attributes of the field
+// hierarchy (FT, Ff, V, DV).
+type FormField struct {
+	// Field is the underlying form field model.
+	Field *PdfField
+
+	Type        FormFieldType
+	Name        string // Fully qualified name.
+	PartialName string
+	AltName     string // Alternate user-facing name (TU).
+	MappingName string // Name used when exporting field data (TM).
+	Flags       FieldFlag
+
+	// Value is the current value of the field. For checkboxes and radio
+	// groups it contains the name of the selected state ("Off" if none).
+	// For multi-select list boxes it contains the first selected value.
+	Value string
+
+	// Values contains all the selected values of a choice field.
+	Values []string
+
+	// DefaultValue is the value the field is reset to.
+	DefaultValue string
+
+	// Options contains the available options of choice fields and the
+	// "on" states of button fields.
+	Options []FormFieldOption
+
+	// MaxLen is the maximum length of the text in a text field (0 if not set).
+	MaxLen int
+
+	// Signature is the signature dictionary of signed signature fields.
+	Signature *PdfSignature
+
+	Widgets []*FormFieldWidget
+}
+
+// IsChecked returns true if the field is a checkbox or radio group which
+// has an "on" state selected.
+func (ff *FormField) IsChecked() bool {
+	switch ff.Type {
+	case FormFieldTypeCheckbox, FormFieldTypeRadioGroup:
+		return ff.Value != "" && ff.Value != "Off"
+	}
+	return false
+}
+
+// Pages returns the distinct numbers of the pages the widgets of the field
+// appear on, in the order of the widgets.
+func (ff *FormField) Pages() []int {
+	var pages []int
+	seen := map[int]struct{}{}
+	for _, w := range ff.Widgets {
+		if w.PageNum == 0 {
+			continue
+		}
+		if _, ok := seen[w.PageNum]; ok {
+			continue
+		}
+		seen[w.PageNum] = struct{}{}
+		pages = append(pages, w.PageNum)
+	}
+	return pages
+}
+
+// GetFormFields returns the terminal fields of the interactive form of the
+// document as typed form fields with resolved values. Returns nil if the
+// document does not contain an interactive form.
+func (r *PdfReader) GetFormFields() ([]*FormField, error) {
+	if r.AcroForm == nil {
+		return nil, nil
+	}
+
+	// Map widget annotations to the pages they belong to.
+	widgetPages := map[*PdfAnnotationWidget]int{}
+	for i, page := range r.PageList {
+		annotations, err := page.GetAnnotations()
+		if err != nil {
+			return nil, err
+		}
+		for _, annot := range annotations {
+			if widget, ok := annot.GetContext().(*PdfAnnotationWidget); ok {
+				widgetPages[widget] = i + 1
+			}
+		}
+	}
+
+	var fields []*FormField
+	for _, field := range r.AcroForm.AllFields() {
+		if !field.IsTerminal() {
+			continue
+		}
+		fields = append(fields, newFormField(field, widgetPages))
+	}
+	return fields, nil
+}
+
+// newFormField returns a new typed form field for the terminal field `field`.
+// The `widgetPages` map is used for determining the pages of the widgets.
+func newFormField(field *PdfField, widgetPages map[*PdfAnnotationWidget]int) *FormField {
+	ff := &FormField{
+		Field:       field,
+		PartialName: field.PartialName(),
+		Flags:       field.Flags(),
+	}
+	if name, err := field.FullName(); err == nil {
+		ff.Name = name
+	} else {
+		ff.Name = ff.PartialName
+	}
+	if field.TU != nil {
+		ff.AltName = field.TU.Decoded()
+	}
+	if field.TM != nil {
+		ff.MappingName = field.TM.Decoded()
+	}
+	for _, widget := range field.Annotations {
+		ff.Widgets = append(ff.Widgets, &FormFieldWidget{
+			Annotation: widget,
+			PageNum:    widgetPages[widget],
+		})
+	}
+
+	value := field.inheritedValue(func(f *PdfField) core.PdfObject { return f.V })
+	defValue := field.inheritedValue(func(f *PdfField) core.PdfObject { return f.DV })
+
+	switch ctx := field.inheritedContext().(type) {
+	case *PdfFieldText:
+		ff.Type = FormFieldTypeText
+		ff.Value = fieldValueString(value)
+		ff.DefaultValue = fieldValueString(defValue)
+		if ctx.MaxLen != nil {
+			ff.MaxLen = int(*ctx.MaxLen)
+		}
+	case *PdfFieldButton:
+		switch {
+		case ff.Flags.Has(FieldFlagPushbutton):
+			ff.Type = FormFieldTypePushButton
+		case ff.Flags.Has(FieldFlagRadio):
+			ff.Type = FormFieldTypeRadioGroup
+		default:
+			ff.Type = FormFieldTypeCheckbox
+		}
+		if ff.Type == FormFieldTypePushButton {
+			break
+		}
+		ff.Value = fieldValueString(value)
+		if ff.Value == "" {
+			ff.Value = "Off"
+		}
+		ff.DefaultValue = fieldValueString(defValue)
+		ff.Options = buttonFieldOptions(field, ctx)
+	case *PdfFieldChoice:
+		ff.Type = FormFieldTypeListBox
+		if ff.Flags.Has(FieldFlagCombo) {
+			ff.Type = FormFieldTypeComboBox
+		}
+		ff.Values = fieldValueStrings(value)
+		if len(ff.Values) > 0 {
+			ff.Value = ff.Values[0]
+		}
+		ff.DefaultValue = fieldValueString(defValue)
+		ff.Options = choiceFieldOptions(ctx.Opt)
+	case *PdfFieldSignature:
+		ff.Type = FormFieldTypeSignature
+		ff.Signature = ctx.V
+	default:
+		common.Log.Debug("Unknown form field type: %T", ctx)
+	}
+
+	return ff
+}
+
+// inheritedContext returns the context of the field or, if the field does
+// not specify a type, the context of the closest ancestor which does.
+func (f *PdfField) inheritedContext() PdfModel {
+	var ctx PdfModel
+	_, err := f.inherit(func(node *PdfField) bool {
+		if node.context != nil {
+			ctx = node.context
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		common.Log.Debug("ERROR: could not resolve inherited field type: %v", err)
+	}
+	return ctx
+}
+
+// inheritedValue returns the first non-nil object returned by `get` when
+// traversing the field hierarchy from the field up to the root.
+func (f *PdfField) inheritedValue(get func(*PdfField) core.PdfObject) core.PdfObject {
+	var val core.PdfObject
+	_, err := f.inherit(func(node *PdfField) bool {
+		if obj := get(node); obj != nil && !core.IsNullObject(obj) {
+			val = obj
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		common.Log.Debug("ERROR: could not resolve inherited field value: %v", err)
+	}
+	return val
+}
+
+// fieldValueString converts a field value object to a string. Text streams
+// (used in rich text fields) are decoded.
+func fieldValueString(obj core.PdfObject) string {
+	switch t := core.TraceToDirectObject(obj).(type) {
+	case *core.PdfObjectString:
+		return t.Decoded()
+	case *core.PdfObjectName:
+		return t.String()
+	case *core.PdfObjectStream:
+		data, err := core.DecodeStream(t)
+		if err != nil {
+			common.Log.Debug("ERROR: could not decode field value stream: %v", err)
+			return ""
+		}
+		return string(data)
+	case *core.PdfObjectArray:
+		if vals := fieldValueStrings(t); len(vals) > 0 {
+			return vals[0]
+		}
+	}
+	return ""
+}
+
+// fieldValueStrings converts a field value object, which can be either
+// a single value or an array of values, to a list of strings.
+func fieldValueStrings(obj core.PdfObject) []string {
+	arr, ok := core.GetArray(obj)
+	if !ok {
+		if s := fieldValueString(obj); s != "" {
+			return []string{s}
+		}
+		return nil
+	}
+
+	var vals []string
+	for _, elem := range arr.Elements() {
+		if s := fieldValueString(elem); s != "" {
+			vals = append(vals, s)
+		}
+	}
+	return vals
+}
+
+// choiceFieldOptions parses the Opt array of a choice field. Each element of
+// the array is either a text string or an array of two strings, the export
+// value and the display text.
+func choiceFieldOptions(opt *core.PdfObjectArray) []FormFieldOption {
+	if opt == nil {
+		return nil
+	}
+
+	var options []FormFieldOption
+	for _, elem := range opt.Elements() {
+		if pair, ok := core.GetArray(elem); ok && pair.Len() >= 2 {
+			options = append(options, FormFieldOption{
+				Export:  fieldValueString(pair.Get(0)),
+				Display: fieldValueString(pair.Get(1)),
+			})
+			continue
+		}
+
+		val := fieldValueString(elem)
+		options = append(options, FormFieldOption{Export: val, Display: val})
+	}
+	return options
+}
+
+// buttonFieldOptions returns the "on" states of the widgets of the button
+// field. If the field has an Opt array, the export values in the array are
+// used as display values for the corresponding widget states.
+func buttonFieldOptions(field *PdfField, fbtn *PdfFieldButton) []FormFieldOption {
+	var exports []string
+	if fbtn.Opt != nil {
+		for _, elem := range fbtn.Opt.Elements() {
+			exports = append(exports, fieldValueString(elem))
+		}
+	}
+
+	var options []FormFieldOption
+	seen := map[string]struct{}{}
+	for i, widget := range field.Annotations {
+		for _, state := range widgetOnStates(widget) {
+			if _, ok := seen[state]; ok {
+				continue
+			}
+			seen[state] = struct{}{}
+
+			display := state
+			if i < len(exports) && exports[i] != "" {
+				display = exports[i]
+			}
+			options = append(options, FormFieldOption{Export: state, Display: display})
+		}
+	}
+	return options
+}
+
+// widgetOnStates returns the names of the "on" appearance states of the
+// specified widget annotation (all normal appearance states except Off).
+func widgetOnStates(widget *PdfAnnotationWidget) []string {
+	apDict, ok := core.GetDict(widget.AP)
+	if !ok {
+		return nil
+	}
+	nDict, ok := core.GetDict(apDict.Get("N"))
+	if !ok {
+		return nil
+	}
+
+	var states []string
+	for _, key := range nDict.Keys() {
+		if key != "Off" {
+			states = append(states, key.String())
+		}
+	}
+	return states
+}
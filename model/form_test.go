@@ -209,3 +209,45 @@ func TestAcroFormNeedsRepair(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, needsRepair, true)
 }
+
+func TestGetFormFields(t *testing.T) {
+	f, err := os.Open("./testdata/OoPdfFormExample.pdf")
+	require.NoError(t, err)
+	defer f.Close()
+
+	reader, err := NewPdfReader(f)
+	require.NoError(t, err)
+
+	fields, err := reader.GetFormFields()
+	require.NoError(t, err)
+	require.Len(t, fields, 17)
+
+	fieldMap := map[string]*FormField{}
+	for _, field := range fields {
+		fieldMap[field.Name] = field
+		require.Equal(t, []int{1}, field.Pages())
+	}
+
+	height := fieldMap["Height Formatted Field"]
+	require.NotNil(t, height)
+	require.Equal(t, FormFieldTypeText, height.Type)
+	require.Equal(t, "150", height.Value)
+
+	country := fieldMap["Country Combo Box"]
+	require.NotNil(t, country)
+	require.Equal(t, FormFieldTypeComboBox, country.Type)
+	require.Len(t, country.Options, 28)
+	require.Equal(t, FormFieldOption{Export: "Austria", Display: "Austria"}, country.Options[0])
+
+	colour := fieldMap["Favourite Colour List Box"]
+	require.NotNil(t, colour)
+	require.Equal(t, FormFieldTypeComboBox, colour.Type)
+	require.Equal(t, "Red", colour.Value)
+	require.Equal(t, []string{"Red"}, colour.Values)
+
+	lang2 := fieldMap["Language 2 Check Box"]
+	require.NotNil(t, lang2)
+	require.Equal(t, FormFieldTypeCheckbox, lang2.Type)
+	require.True(t, lang2.IsChecked())
+	require.False(t, fieldMap["Language 1 Check Box"].IsChecked())
+}
@@ -0,0 +1,171 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// FieldValueMap is a FieldValueProvider which provides field values from a
+// map of field names to values. The keys of the map can be either partial or
+// full field names. String values are used for text fields, choice fields and
+// button states. Bool values are used for checkboxes, where true selects the
+// "on" state of the checkbox. String slices represent the selected values of
+// multi-select choice fields and numbers are formatted as text. Values of
+// type core.PdfObject are used as is.
+type FieldValueMap map[string]interface{}
+
+// FieldValues implements interface FieldValueProvider. An error is returned
+// if any of the values has an unsupported type.
+func (m FieldValueMap) FieldValues() (map[string]core.PdfObject, error) {
+	objMap := make(map[string]core.PdfObject, len(m))
+	for name, val := range m {
+		obj, err := fieldValueToPdfObject(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for field %s: %v", name, err)
+		}
+		objMap[name] = obj
+	}
+	return objMap, nil
+}
+
+// NewFieldValueMapFromStruct returns a new field value map populated using the
+// exported fields of the struct `v` (or pointer to struct). By default, the
+// name of a struct field is used as the name of the form field. The name can
+// be customized using the `pdf` struct tag. Fields tagged with `pdf:"-"` are
+// skipped. The fields of nested structs are prefixed by the name of the
+// nested struct field, followed by a dot, matching the full names of the form
+// fields in a field hierarchy.
+func NewFieldValueMapFromStruct(v interface{}) (FieldValueMap, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.New("nil struct pointer")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected struct, got %T", v)
+	}
+
+	m := FieldValueMap{}
+	if err := m.addStructFields(rv, ""); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// addStructFields adds the fields of struct value `rv` to the map. The names
+// of the fields are prefixed by `prefix`.
+func (m FieldValueMap) addStructFields(rv reflect.Value, prefix string) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup("pdf"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag = strings.TrimSpace(tag); tag != "" {
+				name = tag
+			}
+		}
+		name = prefix + name
+
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+
+		if _, isObj := fv.Interface().(core.PdfObject); !isObj && fv.Kind() == reflect.Struct {
+			if err := m.addStructFields(fv, name+"."); err != nil {
+				return err
+			}
+			continue
+		}
+
+		m[name] = fv.Interface()
+	}
+	return nil
+}
+
+// fieldValueToPdfObject converts the field value `val` to a PDF object.
+func fieldValueToPdfObject(val interface{}) (core.PdfObject, error) {
+	switch t := val.(type) {
+	case core.PdfObject:
+		return t, nil
+	case string:
+		return core.MakeString(t), nil
+	case bool:
+		return core.MakeBool(t), nil
+	case []string:
+		arr := core.MakeArray()
+		for _, s := range t {
+			arr.Append(core.MakeString(s))
+		}
+		return arr, nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return core.MakeString(fmt.Sprint(t)), nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", val)
+}
+
+// FillForm populates the interactive form of the document with the values
+// provided by `provider`. If not nil, `appGen` is used to regenerate the
+// appearance streams of the widget annotations of the filled fields.
+// Otherwise, the NeedAppearances flag of the form is set, instructing the
+// viewers to generate the appearances. The filled form can be written using
+// the SetForms method of the PdfWriter.
+func (r *PdfReader) FillForm(provider FieldValueProvider, appGen FieldAppearanceGenerator) error {
+	if r.AcroForm == nil {
+		return errors.New("document does not have an interactive form")
+	}
+	return r.AcroForm.fillForm(provider, appGen)
+}
+
+// FillForm populates the interactive form of the document with the values
+// provided by `provider` and appends the updated form in the new revision.
+// If not nil, `appGen` is used to regenerate the appearance streams of the
+// widget annotations of the filled fields. Otherwise, the NeedAppearances flag
+// of the form is set, instructing the viewers to generate the appearances.
+func (a *PdfAppender) FillForm(provider FieldValueProvider, appGen FieldAppearanceGenerator) error {
+	acroForm := a.Reader.AcroForm
+	if acroForm == nil {
+		return errors.New("document does not have an interactive form")
+	}
+	if err := acroForm.fillForm(provider, appGen); err != nil {
+		return err
+	}
+
+	a.ReplaceAcroForm(acroForm)
+	return nil
+}
+
+// fillForm fills the form using `provider` and `appGen` and updates the
+// NeedAppearances flag accordingly.
+func (form *PdfAcroForm) fillForm(provider FieldValueProvider, appGen FieldAppearanceGenerator) error {
+	if err := form.fill(provider, appGen); err != nil {
+		return err
+	}
+	if appGen == nil {
+		form.NeedAppearances = core.MakeBool(true)
+	}
+	return nil
+}
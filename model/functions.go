@@ -25,25 +25,37 @@ type PdfFunction interface {
 // - Stream: Type 0, Type 4
 // - Dictionary: Type 2, Type 3.
 
+// NewPdfFunctionFromPdfObject loads a PDF function from a PdfObject, which is
+// a stream for sampled (type 0) and PostScript calculator (type 4) functions,
+// or a dictionary for exponential (type 2) and stitching (type 3) functions.
+// See section 7.10 "Functions" (p. 92 PDF32000_2008).
+func NewPdfFunctionFromPdfObject(obj core.PdfObject) (PdfFunction, error) {
+	return newPdfFunctionFromPdfObject(obj)
+}
+
 // Loads a PDF Function from a PdfObject (can be either stream or dictionary).
 func newPdfFunctionFromPdfObject(obj core.PdfObject) (PdfFunction, error) {
 	obj = core.ResolveReference(obj)
 	if stream, is := obj.(*core.PdfObjectStream); is {
 		dict := stream.PdfObjectDictionary
 
-		ftype, ok := dict.Get("FunctionType").(*core.PdfObjectInteger)
+		ftype, ok := core.GetIntVal(dict.Get("FunctionType"))
 		if !ok {
 			common.Log.Error("FunctionType number missing")
 			return nil, errors.New("invalid parameter or missing")
 		}
 
-		if *ftype == 0 {
+		switch ftype {
+		case 0:
 			return newPdfFunctionType0FromStream(stream)
-		} else if *ftype == 4 {
+		case 4:
 			return newPdfFunctionType4FromStream(stream)
-		} else {
-			return nil, errors.New("invalid function type")
+		case 2, 3:
+			// Dictionary based functions incorrectly stored as streams.
+			common.Log.Debug("Function type %d stored in a stream", ftype)
+			return newPdfFunctionFromDict(dict, dict)
 		}
+		return nil, errors.New("invalid function type")
 	} else if indObj, is := obj.(*core.PdfIndirectObject); is {
 		// Indirect object containing a dictionary.
 		// The indirect object is the container (which is tracked).
@@ -52,40 +64,33 @@ func newPdfFunctionFromPdfObject(obj core.PdfObject) (PdfFunction, error) {
 			common.Log.Error("Function Indirect object not containing dictionary")
 			return nil, errors.New("invalid parameter or missing")
 		}
-
-		ftype, ok := dict.Get("FunctionType").(*core.PdfObjectInteger)
-		if !ok {
-			common.Log.Error("FunctionType number missing")
-			return nil, errors.New("invalid parameter or missing")
-		}
-
-		if *ftype == 2 {
-			return newPdfFunctionType2FromPdfObject(indObj)
-		} else if *ftype == 3 {
-			return newPdfFunctionType3FromPdfObject(indObj)
-		} else {
-			return nil, errors.New("invalid function type")
-		}
+		return newPdfFunctionFromDict(indObj, dict)
 	} else if dict, is := obj.(*core.PdfObjectDictionary); is {
-		ftype, ok := dict.Get("FunctionType").(*core.PdfObjectInteger)
-		if !ok {
-			common.Log.Error("FunctionType number missing")
-			return nil, errors.New("invalid parameter or missing")
-		}
-
-		if *ftype == 2 {
-			return newPdfFunctionType2FromPdfObject(dict)
-		} else if *ftype == 3 {
-			return newPdfFunctionType3FromPdfObject(dict)
-		} else {
-			return nil, errors.New("invalid function type")
-		}
+		return newPdfFunctionFromDict(dict, dict)
 	} else {
 		common.Log.Debug("Function Type error: %#v", obj)
 		return nil, errors.New("type error")
 	}
 }
 
+// newPdfFunctionFromDict loads a type 2 or type 3 function from `dict`, where
+// `obj` is either `dict` or its indirect object container.
+func newPdfFunctionFromDict(obj core.PdfObject, dict *core.PdfObjectDictionary) (PdfFunction, error) {
+	ftype, ok := core.GetIntVal(dict.Get("FunctionType"))
+	if !ok {
+		common.Log.Error("FunctionType number missing")
+		return nil, errors.New("invalid parameter or missing")
+	}
+
+	switch ftype {
+	case 2:
+		return newPdfFunctionType2FromPdfObject(obj)
+	case 3:
+		return newPdfFunctionType3FromPdfObject(obj)
+	}
+	return nil, errors.New("invalid function type")
+}
+
 // Simple linear interpolation from the PDF manual.
 func interpolate(x, xmin, xmax, ymin, ymax float64) float64 {
 	if math.Abs(xmax-xmin) < 0.000001 {
@@ -96,6 +101,18 @@ func interpolate(x, xmin, xmax, ymin, ymax float64) float64 {
 	return y
 }
 
+// clipToRanges clips the values of `vals` to the [min max] pairs of `ranges`.
+// The values without corresponding range are kept unchanged.
+func clipToRanges(vals []float64, ranges []float64) []float64 {
+	for i := range vals {
+		if 2*i+1 >= len(ranges) {
+			break
+		}
+		vals[i] = math.Min(math.Max(vals[i], ranges[2*i]), ranges[2*i+1])
+	}
+	return vals
+}
+
 // PdfFunctionType0 uses a sequence of sample values (contained in a stream) to provide an approximation
 // for functions whose domains and ranges are bounded. The samples are organized as an m-dimensional
 // table in which each entry has n components
@@ -118,6 +135,52 @@ type PdfFunctionType0 struct {
 	container *core.PdfObjectStream
 }
 
+// NewPdfFunctionType0 returns a new sampled function with `domain` and
+// `rang` the [min max] pairs of its inputs and outputs. The sample table
+// contains size[0] * ... * size[m-1] samples of n output values, each encoded
+// on `bitsPerSample` bits, where the first input varies the fastest.
+func NewPdfFunctionType0(domain, rang []float64, size []int, bitsPerSample int,
+	samples []uint32) (*PdfFunctionType0, error) {
+	if len(domain) == 0 || len(domain)%2 != 0 || len(rang) == 0 || len(rang)%2 != 0 {
+		return nil, errors.New("invalid domain or range")
+	}
+	if len(size) != len(domain)/2 {
+		return nil, errors.New("table size not matching number of inputs")
+	}
+	switch bitsPerSample {
+	case 1, 2, 4, 8, 12, 16, 24, 32:
+	default:
+		return nil, errors.New("invalid bits per sample")
+	}
+
+	numSamples := len(rang) / 2
+	for _, s := range size {
+		if s < 1 {
+			return nil, errors.New("invalid table size")
+		}
+		numSamples *= s
+	}
+	if len(samples) != numSamples {
+		return nil, errors.New("number of samples not matching table size")
+	}
+
+	resampled := sampling.ResampleUint32(samples, bitsPerSample, 8)
+	data := make([]byte, len(resampled))
+	for i, val := range resampled {
+		data[i] = byte(val)
+	}
+	return &PdfFunctionType0{
+		Domain:        domain,
+		Range:         rang,
+		NumInputs:     len(domain) / 2,
+		NumOutputs:    len(rang) / 2,
+		Size:          size,
+		BitsPerSample: bitsPerSample,
+		Order:         1,
+		rawData:       data,
+	}, nil
+}
+
 // Construct the PDF function object from a stream object (typically loaded from a PDF file).
 func newPdfFunctionType0FromStream(stream *core.PdfObjectStream) (*PdfFunctionType0, error) {
 	fun := &PdfFunctionType0{}
@@ -262,9 +325,14 @@ func (f *PdfFunctionType0) ToPdfObject() core.PdfObject {
 	if f.Order != 1 {
 		dict.Set("Order", core.MakeInteger(int64(f.Order)))
 	}
+	if f.Encode != nil {
+		dict.Set("Encode", core.MakeArrayFromFloats(f.Encode))
+	}
+	if f.Decode != nil {
+		dict.Set("Decode", core.MakeArrayFromFloats(f.Decode))
+	}
 
-	// TODO: Encode.
-	// Either here, or automatically later on when writing out.
+	// The samples are written without filter.
 	dict.Set("Length", core.MakeInteger(int64(len(f.rawData))))
 	f.container.Stream = f.rawData
 
@@ -273,11 +341,17 @@ func (f *PdfFunctionType0) ToPdfObject() core.PdfObject {
 }
 
 // Evaluate runs the function on the passed in slice and returns the results.
+// The output values are obtained by multilinear interpolation of the nearest
+// surrounding samples. Cubic spline interpolation (Order 3) is approximated
+// by linear interpolation.
 func (f *PdfFunctionType0) Evaluate(x []float64) ([]float64, error) {
 	if len(x) != f.NumInputs {
 		common.Log.Error("Number of inputs not matching what is needed")
 		return nil, errors.New("range check error")
 	}
+	if len(f.Domain) < 2*f.NumInputs || len(f.Size) < f.NumInputs || len(f.Range) < 2*f.NumOutputs {
+		return nil, errors.New("invalid sampled function parameters")
+	}
 
 	if f.data == nil {
 		// Process the samples if not already done.
@@ -289,7 +363,7 @@ func (f *PdfFunctionType0) Evaluate(x []float64) ([]float64, error) {
 
 	// Fall back to default Encode/Decode params if not set.
 	encode := f.Encode
-	if encode == nil {
+	if len(encode) < 2*f.NumInputs {
 		encode = []float64{}
 		for i := 0; i < len(f.Size); i++ {
 			encode = append(encode, 0)
@@ -297,63 +371,74 @@ func (f *PdfFunctionType0) Evaluate(x []float64) ([]float64, error) {
 		}
 	}
 	decode := f.Decode
-	if decode == nil {
+	if len(decode) < 2*f.NumOutputs {
 		decode = f.Range
 	}
 
-	var indices []int
-	// Start with nearest neighbour interpolation.
+	// Determine the cell of the sample table containing the input values,
+	// and the position within the cell.
+	// See section 7.10.2 Type 0 (Sampled) Functions (pp. 93-94 PDF32000_2008).
+	base := make([]int, f.NumInputs)
+	frac := make([]float64, f.NumInputs)
+	stride := make([]int, f.NumInputs)
 	for i := 0; i < len(x); i++ {
-		xi := x[i]
-
-		// See section 7.10.2 Type 0 (Sampled) Functions (pp. 93-94 PDF32000_2008).
-		xip := math.Min(math.Max(xi, f.Domain[2*i]), f.Domain[2*i+1])
+		xip := math.Min(math.Max(x[i], f.Domain[2*i]), f.Domain[2*i+1])
 		ei := interpolate(xip, f.Domain[2*i], f.Domain[2*i+1], encode[2*i], encode[2*i+1])
 		eip := math.Min(math.Max(ei, 0), float64(f.Size[i]-1))
-		// eip represents coordinate into the data table.
-		// At this point it is real values.
-
-		// Interpolation shall be used to to determine output values
-		// from the nearest surrounding values in the sample table.
 
-		// Initial implementation is simply nearest neighbour.
-		// Then will add the linear and possibly bicubic/spline.
-		index := int(math.Floor(eip + 0.5))
-		if index < 0 {
-			index = 0
-		} else if index > f.Size[i] {
-			index = f.Size[i] - 1
+		b := int(math.Floor(eip))
+		if b > f.Size[i]-2 {
+			b = f.Size[i] - 2
+		}
+		if b < 0 {
+			b = 0
+		}
+		base[i] = b
+		frac[i] = math.Min(eip-float64(b), 1)
+		if f.Size[i] < 2 {
+			frac[i] = 0
 		}
-		indices = append(indices, index)
-	}
 
-	// Calculate the index
-	m := indices[0]
-	for i := 1; i < f.NumInputs; i++ {
-		add := indices[i]
-		for j := 0; j < i; j++ {
-			add *= f.Size[j]
+		stride[i] = f.NumOutputs
+		if i > 0 {
+			stride[i] = stride[i-1] * f.Size[i-1]
 		}
-		m += add
 	}
-	m *= f.NumOutputs
 
-	// Output values.
-	var outputs []float64
-	for j := 0; j < f.NumOutputs; j++ {
-		rjIdx := m + j
-		if rjIdx >= len(f.data) {
-			common.Log.Debug("WARN: not enough input samples to determine output values. Output may be incorrect.")
+	// Interpolate the samples at the corners of the cell.
+	samples := make([]float64, f.NumOutputs)
+	for corner := 0; corner < 1<<uint(f.NumInputs); corner++ {
+		weight := 1.0
+		offset := 0
+		for i := 0; i < f.NumInputs; i++ {
+			idx := base[i]
+			if corner&(1<<uint(i)) != 0 {
+				idx++
+				weight *= frac[i]
+			} else {
+				weight *= 1 - frac[i]
+			}
+			offset += idx * stride[i]
+		}
+		if weight == 0 {
 			continue
 		}
-
-		rj := f.data[rjIdx]
-		rjp := interpolate(float64(rj), 0, math.Pow(2, float64(f.BitsPerSample)), decode[2*j], decode[2*j+1])
-		yj := math.Min(math.Max(rjp, f.Range[2*j]), f.Range[2*j+1])
-		outputs = append(outputs, yj)
+		for j := range samples {
+			if offset+j >= len(f.data) {
+				common.Log.Debug("WARN: not enough input samples to determine output values. Output may be incorrect.")
+				continue
+			}
+			samples[j] += weight * float64(f.data[offset+j])
+		}
 	}
 
-	return outputs, nil
+	// Output values.
+	maxVal := math.Pow(2, float64(f.BitsPerSample)) - 1
+	outputs := make([]float64, f.NumOutputs)
+	for j, rj := range samples {
+		outputs[j] = interpolate(rj, 0, maxVal, decode[2*j], decode[2*j+1])
+	}
+	return clipToRanges(outputs, f.Range), nil
 }
 
 // Convert raw data to data table.  The maximum supported BitsPerSample is 32, so we store the resulting data
@@ -538,13 +623,22 @@ func (f *PdfFunctionType2) Evaluate(x []float64) ([]float64, error) {
 		c1 = f.C1
 	}
 
+	xv := x[0]
+	if len(f.Domain) >= 2 {
+		xv = math.Min(math.Max(xv, f.Domain[0]), f.Domain[1])
+	}
+	xn := math.Pow(xv, f.N)
+	if math.IsNaN(xn) || math.IsInf(xn, 0) {
+		return nil, errors.New("exponential function undefined for input")
+	}
+
 	var y []float64
-	for i := 0; i < len(c0); i++ {
-		yi := c0[i] + math.Pow(x[0], f.N)*(c1[i]-c0[i])
+	for i := 0; i < len(c0) && i < len(c1); i++ {
+		yi := c0[i] + xn*(c1[i]-c0[i])
 		y = append(y, yi)
 	}
 
-	return y, nil
+	return clipToRanges(y, f.Range), nil
 }
 
 // PdfFunctionType3 defines stitching of the subdomains of several 1-input functions to produce
@@ -566,12 +660,38 @@ func (f *PdfFunctionType3) Evaluate(x []float64) ([]float64, error) {
 		common.Log.Error("Only one input allowed")
 		return nil, errors.New("range check")
 	}
+	k := len(f.Functions)
+	if k == 0 || len(f.Domain) < 2 || len(f.Bounds) < k-1 || len(f.Encode) < 2*k {
+		return nil, errors.New("invalid stitching function parameters")
+	}
 
-	// Determine which function to use
+	// Determine which function to use. The subdomains are half-open
+	// intervals, except the last one which is closed. If Domain0 equals
+	// Bounds0, the first subdomain is the closed interval [Domain0 Bounds0].
+	xv := math.Min(math.Max(x[0], f.Domain[0]), f.Domain[1])
+	i := 0
+	for i < k-1 && xv >= f.Bounds[i] {
+		if i == 0 && xv == f.Bounds[0] && f.Bounds[0] == f.Domain[0] {
+			break
+		}
+		i++
+	}
 
-	// Encode
+	low, high := f.Domain[0], f.Domain[1]
+	if i > 0 {
+		low = f.Bounds[i-1]
+	}
+	if i < k-1 {
+		high = f.Bounds[i]
+	}
 
-	return nil, errors.New("not implemented yet")
+	// Encode the input value to the domain of the subfunction.
+	xe := interpolate(xv, low, high, f.Encode[2*i], f.Encode[2*i+1])
+	y, err := f.Functions[i].Evaluate([]float64{xe})
+	if err != nil {
+		return nil, err
+	}
+	return clipToRanges(y, f.Range), nil
 }
 
 func newPdfFunctionType3FromPdfObject(obj core.PdfObject) (*PdfFunctionType3, error) {
@@ -637,15 +757,18 @@ func newPdfFunctionType3FromPdfObject(obj core.PdfObject) (*PdfFunctionType3, er
 
 	// Bounds
 	array, has = core.TraceToDirectObject(dict.Get("Bounds")).(*core.PdfObjectArray)
-	if !has {
+	if !has && len(fun.Functions) > 1 {
 		common.Log.Error("Bounds not specified")
 		return nil, errors.New("required attribute missing or invalid")
 	}
-	bounds, err := array.ToFloat64Array()
-	if err != nil {
-		return nil, err
+	fun.Bounds = []float64{}
+	if has {
+		bounds, err := array.ToFloat64Array()
+		if err != nil {
+			return nil, err
+		}
+		fun.Bounds = bounds
 	}
-	fun.Bounds = bounds
 	if len(fun.Bounds) != len(fun.Functions)-1 {
 		common.Log.Error("Bounds (%d) and num functions (%d) not matching", len(fun.Bounds), len(fun.Functions))
 		return nil, errors.New("range check")
@@ -741,13 +864,21 @@ type PdfFunctionType4 struct {
 }
 
 // Evaluate runs the function. Input is [x1 x2 x3].
+// The inputs are clipped to the domain and the outputs to the range of the
+// function.
 func (f *PdfFunctionType4) Evaluate(xVec []float64) ([]float64, error) {
+	if f.Program == nil {
+		return nil, errors.New("missing PostScript program")
+	}
 	if f.executor == nil {
 		f.executor = ps.NewPSExecutor(f.Program)
 	}
 
 	var inputs []ps.PSObject
-	for _, val := range xVec {
+	for i, val := range xVec {
+		if 2*i+1 < len(f.Domain) {
+			val = math.Min(math.Max(val, f.Domain[2*i]), f.Domain[2*i+1])
+		}
 		inputs = append(inputs, ps.MakeReal(val))
 	}
 
@@ -763,7 +894,7 @@ func (f *PdfFunctionType4) Evaluate(xVec []float64) ([]float64, error) {
 		return nil, err
 	}
 
-	return yVec, nil
+	return clipToRanges(yVec, f.Range), nil
 }
 
 // Load a type 4 function from a PDF stream object.
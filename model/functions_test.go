@@ -6,10 +6,14 @@ package model
 
 import (
 	"math"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/require"
+
 	"github.com/unidoc/unipdf/v3/common"
 	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/ps"
 )
 
 func init() {
@@ -100,3 +104,115 @@ endobj
 
 	t.Logf("%s", stream.Stream)
 }
+
+func TestType0Function(t *testing.T) {
+	// 1 input, 2 outputs: linear interpolation between 3 samples.
+	fun, err := NewPdfFunctionType0([]float64{0, 1}, []float64{0, 1, 0, 1}, []int{3}, 8,
+		[]uint32{0, 255, 255, 0, 0, 255})
+	require.NoError(t, err)
+	for x, expected := range map[float64][]float64{
+		0: {0, 1}, 0.25: {0.5, 0.5}, 0.5: {1, 0}, 0.75: {0.5, 0.5}, 1: {0, 1}, 2: {0, 1},
+	} {
+		y, err := fun.Evaluate([]float64{x})
+		require.NoError(t, err)
+		require.InDeltaSlice(t, expected, y, 1e-9)
+	}
+
+	// Reload with Encode and Decode.
+	fun.Encode = []float64{0, 1}
+	fun.Decode = []float64{0, 0.5, 0, 0.5}
+	loaded, err := NewPdfFunctionFromPdfObject(fun.ToPdfObject())
+	require.NoError(t, err)
+	y, err := loaded.Evaluate([]float64{0.5})
+	require.NoError(t, err)
+	require.InDeltaSlice(t, []float64{0.25, 0.25}, y, 1e-9)
+
+	// 2 inputs with 4 bits per sample: bilinear interpolation.
+	fun, err = NewPdfFunctionType0([]float64{0, 1, 0, 1}, []float64{0, 1}, []int{2, 2}, 4,
+		[]uint32{0, 15, 15, 0})
+	require.NoError(t, err)
+	for _, tc := range []struct{ x, y, expected float64 }{
+		{0, 0, 0}, {1, 0, 1}, {0, 1, 1}, {1, 1, 0}, {0.5, 0.5, 0.5}, {0.5, 0, 0.5}, {0.25, 1, 0.75},
+	} {
+		out, err := fun.Evaluate([]float64{tc.x, tc.y})
+		require.NoError(t, err)
+		require.InDelta(t, tc.expected, out[0], 1e-9)
+	}
+
+	_, err = NewPdfFunctionType0([]float64{0, 1}, []float64{0, 1}, []int{2}, 8, []uint32{0})
+	require.Error(t, err)
+	_, err = NewPdfFunctionType0([]float64{0, 1}, []float64{0, 1}, []int{2}, 7, []uint32{0, 1})
+	require.Error(t, err)
+}
+
+func TestType2Function(t *testing.T) {
+	fun := &PdfFunctionType2{
+		Domain: []float64{0, 1},
+		Range:  []float64{0, 1, 0, 0.5},
+		C0:     []float64{0, 1},
+		C1:     []float64{1, 0},
+		N:      2,
+	}
+	y, err := fun.Evaluate([]float64{0.5})
+	require.NoError(t, err)
+	require.InDeltaSlice(t, []float64{0.25, 0.5}, y, 1e-9)
+
+	// The input is clipped to the domain.
+	y, err = fun.Evaluate([]float64{2})
+	require.NoError(t, err)
+	require.InDeltaSlice(t, []float64{1, 0}, y, 1e-9)
+}
+
+func TestType3Function(t *testing.T) {
+	rawText := `
+<<
+	/FunctionType 3
+	/Domain [0 1]
+	/Bounds [0.5]
+	/Encode [0 1 1 0]
+	/Functions [
+		<< /FunctionType 2 /Domain [0 1] /C0 [0] /C1 [1] /N 1 >>
+		<< /FunctionType 2 /Domain [0 1] /C0 [0] /C1 [0.5] /N 1 >>
+	]
+>>`
+	obj, err := core.NewParserFromString(strings.TrimSpace(rawText)).ParseDict()
+	require.NoError(t, err)
+	fun, err := NewPdfFunctionFromPdfObject(obj)
+	require.NoError(t, err)
+
+	for x, expected := range map[float64]float64{
+		0: 0, 0.25: 0.5, 0.49: 0.98, 0.5: 0.5, 0.75: 0.25, 1: 0, -1: 0,
+	} {
+		y, err := fun.Evaluate([]float64{x})
+		require.NoError(t, err)
+		require.InDelta(t, expected, y[0], 1e-9, "x=%v", x)
+	}
+
+	// Reload.
+	loaded, err := NewPdfFunctionFromPdfObject(fun.ToPdfObject())
+	require.NoError(t, err)
+	y, err := loaded.Evaluate([]float64{0.75})
+	require.NoError(t, err)
+	require.InDelta(t, 0.25, y[0], 1e-9)
+}
+
+func TestType4FunctionClipping(t *testing.T) {
+	prog, err := ps.NewPSParser([]byte("{ 2 mul }")).Parse()
+	require.NoError(t, err)
+	fun := &PdfFunctionType4{Domain: []float64{-1, 1}, Range: []float64{0, 1.5}, Program: prog}
+	for x, expected := range map[float64]float64{0.5: 1, 5: 1.5, -0.5: 0} {
+		y, err := fun.Evaluate([]float64{x})
+		require.NoError(t, err)
+		require.InDelta(t, expected, y[0], 1e-9)
+	}
+
+	// A failed execution does not affect the next evaluations.
+	prog, err = ps.NewPSParser([]byte("{ add }")).Parse()
+	require.NoError(t, err)
+	fun = &PdfFunctionType4{Domain: []float64{0, 1, 0, 1}, Range: []float64{0, 2}, Program: prog}
+	_, err = fun.Evaluate([]float64{1})
+	require.Error(t, err)
+	y, err := fun.Evaluate([]float64{1, 0.5})
+	require.NoError(t, err)
+	require.Equal(t, []float64{1.5}, y)
+}
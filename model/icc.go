@@ -0,0 +1,700 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf16"
+)
+
+// ICC profile device classes (ICC.1:2004-10 section 7.2.5).
+const (
+	ICCClassInput      = "scnr"
+	ICCClassDisplay    = "mntr"
+	ICCClassOutput     = "prtr"
+	ICCClassLink       = "link"
+	ICCClassColorSpace = "spac"
+	ICCClassAbstract   = "abst"
+	ICCClassNamedColor = "nmcl"
+)
+
+// ICC profile data and connection color space signatures (ICC.1:2004-10 section 7.2.6).
+const (
+	ICCColorSpaceGray = "GRAY"
+	ICCColorSpaceRGB  = "RGB "
+	ICCColorSpaceCMYK = "CMYK"
+	ICCColorSpaceLab  = "Lab "
+	ICCColorSpaceXYZ  = "XYZ "
+)
+
+// iccHeaderSize is the size of the fixed ICC profile header.
+const iccHeaderSize = 128
+
+// ICCProfile represents a parsed ICC color profile, as embedded in ICCBased
+// colorspaces and output intents.
+//
+// Colors can be converted to the profile connection space and to sRGB for
+// matrix/TRC based profiles and for lut8/lut16 based profiles (A2B0 tag).
+type ICCProfile struct {
+	Size            uint32 // Profile size in bytes, as declared in the header.
+	CMMType         string // Preferred color management module.
+	Version         string // Profile version, e.g. "2.1.0".
+	DeviceClass     string // Profile/device class, e.g. ICCClassDisplay.
+	ColorSpace      string // Data color space, e.g. ICCColorSpaceRGB.
+	PCS             string // Profile connection space (ICCColorSpaceXYZ or ICCColorSpaceLab).
+	RenderingIntent int    // Rendering intent (0: perceptual, 1: relative, 2: saturation, 3: absolute).
+	Creator         string // Profile creator signature.
+	Description     string // Profile description (desc tag).
+	Copyright       string // Copyright notice (cprt tag).
+
+	// Data contains the raw profile data.
+	Data []byte
+
+	tags      map[string][]byte
+	transform iccTransform
+}
+
+// iccTransform converts color components to the profile connection space.
+type iccTransform interface {
+	toPCS(vals []float64) ([]float64, error)
+}
+
+// NewICCProfile parses the ICC profile contained in `data`. The header and tag
+// table are validated.
+func NewICCProfile(data []byte) (*ICCProfile, error) {
+	if len(data) < iccHeaderSize+4 {
+		return nil, errors.New("ICC profile too short")
+	}
+	if string(data[36:40]) != "acsp" {
+		return nil, errors.New("invalid ICC profile signature")
+	}
+
+	p := &ICCProfile{
+		Size:            binary.BigEndian.Uint32(data[0:4]),
+		CMMType:         iccSignature(data[4:8]),
+		Version:         fmt.Sprintf("%d.%d.%d", data[8], data[9]>>4, data[9]&0x0f),
+		DeviceClass:     string(data[12:16]),
+		ColorSpace:      string(data[16:20]),
+		PCS:             string(data[20:24]),
+		RenderingIntent: int(binary.BigEndian.Uint32(data[64:68]) & 0xffff),
+		Creator:         iccSignature(data[80:84]),
+		Data:            data,
+		tags:            map[string][]byte{},
+	}
+	if int(p.Size) > len(data) {
+		return nil, fmt.Errorf("ICC profile truncated (%d < %d bytes)", len(data), p.Size)
+	}
+	if p.NumComponents() == 0 {
+		return nil, fmt.Errorf("unsupported ICC profile color space %q", p.ColorSpace)
+	}
+
+	// Tag table.
+	count := int(binary.BigEndian.Uint32(data[iccHeaderSize:]))
+	if count < 0 || len(data) < iccHeaderSize+4+12*count {
+		return nil, errors.New("invalid ICC profile tag table")
+	}
+	for i := 0; i < count; i++ {
+		entry := data[iccHeaderSize+4+12*i:]
+		sig := string(entry[0:4])
+		offset := int64(binary.BigEndian.Uint32(entry[4:8]))
+		size := int64(binary.BigEndian.Uint32(entry[8:12]))
+		if offset+size > int64(len(data)) {
+			return nil, fmt.Errorf("ICC profile tag %q out of bounds", sig)
+		}
+		p.tags[sig] = data[offset : offset+size]
+	}
+
+	p.Description = iccText(p.tags["desc"])
+	p.Copyright = iccText(p.tags["cprt"])
+
+	transform, err := p.loadTransform()
+	if err != nil {
+		return nil, err
+	}
+	p.transform = transform
+	return p, nil
+}
+
+// NumComponents returns the number of color components of the profile data
+// color space, or 0 if the color space is not supported.
+func (p *ICCProfile) NumComponents() int {
+	switch p.ColorSpace {
+	case ICCColorSpaceGray:
+		return 1
+	case ICCColorSpaceRGB, ICCColorSpaceLab, ICCColorSpaceXYZ:
+		return 3
+	case ICCColorSpaceCMYK:
+		return 4
+	}
+	return 0
+}
+
+// HasTag returns true if the profile contains the tag with signature `sig`.
+func (p *ICCProfile) HasTag(sig string) bool {
+	_, ok := p.tags[sig]
+	return ok
+}
+
+// Tag returns the data of the tag with signature `sig`, or nil if the tag
+// does not exist.
+func (p *ICCProfile) Tag(sig string) []byte {
+	return p.tags[sig]
+}
+
+// CanTransform returns true if colors can be converted using the profile.
+func (p *ICCProfile) CanTransform() bool {
+	return p.transform != nil
+}
+
+// ToXYZ converts the color components `vals` (range 0-1) to CIE XYZ values
+// relative to the D50 illuminant of the profile connection space.
+func (p *ICCProfile) ToXYZ(vals []float64) ([]float64, error) {
+	if len(vals) != p.NumComponents() {
+		return nil, fmt.Errorf("invalid number of components (%d != %d)", len(vals), p.NumComponents())
+	}
+	if p.transform == nil {
+		return nil, errors.New("ICC profile transform not supported")
+	}
+	pcs, err := p.transform.toPCS(vals)
+	if err != nil {
+		return nil, err
+	}
+	if p.PCS == ICCColorSpaceLab {
+		pcs = labToXYZ(pcs[0], pcs[1], pcs[2])
+	}
+	return pcs, nil
+}
+
+// ToRGB converts the color components `vals` (range 0-1) to sRGB values in
+// the range 0-1.
+func (p *ICCProfile) ToRGB(vals []float64) ([]float64, error) {
+	xyz, err := p.ToXYZ(vals)
+	if err != nil {
+		return nil, err
+	}
+
+	// D50 XYZ to linear sRGB (Bradford adapted).
+	x, y, z := xyz[0], xyz[1], xyz[2]
+	rgb := []float64{
+		3.1338561*x - 1.6168667*y - 0.4906146*z,
+		-0.9787684*x + 1.9161415*y + 0.0334540*z,
+		0.0719453*x - 0.2289914*y + 1.4052427*z,
+	}
+	for i, v := range rgb {
+		v = math.Min(math.Max(v, 0), 1)
+		if v <= 0.0031308 {
+			v *= 12.92
+		} else {
+			v = 1.055*math.Pow(v, 1/2.4) - 0.055
+		}
+		rgb[i] = math.Min(math.Max(v, 0), 1)
+	}
+	return rgb, nil
+}
+
+// loadTransform returns the transform to the profile connection space, or
+// nil if the profile tags are not supported.
+func (p *ICCProfile) loadTransform() (iccTransform, error) {
+	if p.PCS != ICCColorSpaceXYZ && p.PCS != ICCColorSpaceLab {
+		return nil, nil
+	}
+
+	// Prefer lookup tables.
+	if t, err := newICCLutTransform(p.tags["A2B0"], p.NumComponents(), p.PCS); err != nil {
+		return nil, err
+	} else if t != nil {
+		return t, nil
+	}
+
+	switch p.ColorSpace {
+	case ICCColorSpaceGray:
+		trc, err := newICCCurve(p.tags["kTRC"])
+		if err != nil || trc == nil {
+			return nil, err
+		}
+		return &iccGrayTransform{trc: trc, lab: p.PCS == ICCColorSpaceLab}, nil
+	case ICCColorSpaceRGB:
+		if p.PCS != ICCColorSpaceXYZ {
+			return nil, nil
+		}
+		t := &iccMatrixTransform{}
+		for i, c := range []string{"r", "g", "b"} {
+			trc, err := newICCCurve(p.tags[c+"TRC"])
+			if err != nil || trc == nil {
+				return nil, err
+			}
+			xyz, err := iccXYZ(p.tags[c+"XYZ"])
+			if err != nil || xyz == nil {
+				return nil, err
+			}
+			t.trc[i] = trc
+			for j := 0; j < 3; j++ {
+				t.matrix[j][i] = xyz[j]
+			}
+		}
+		return t, nil
+	}
+	return nil, nil
+}
+
+// iccGrayTransform is a grayscale TRC based transform.
+type iccGrayTransform struct {
+	trc iccCurve
+	lab bool // The curve output is L*/100 for Lab connection spaces.
+}
+
+func (t *iccGrayTransform) toPCS(vals []float64) ([]float64, error) {
+	y := t.trc.eval(vals[0])
+	if t.lab {
+		return []float64{100 * y, 0, 0}, nil
+	}
+	return []float64{iccD50[0] * y, y, iccD50[2] * y}, nil
+}
+
+// iccMatrixTransform is a RGB matrix/TRC based transform.
+type iccMatrixTransform struct {
+	trc    [3]iccCurve
+	matrix [3][3]float64
+}
+
+func (t *iccMatrixTransform) toPCS(vals []float64) ([]float64, error) {
+	var lin [3]float64
+	for i := range lin {
+		lin[i] = t.trc[i].eval(vals[i])
+	}
+	xyz := make([]float64, 3)
+	for i := range xyz {
+		xyz[i] = t.matrix[i][0]*lin[0] + t.matrix[i][1]*lin[1] + t.matrix[i][2]*lin[2]
+	}
+	return xyz, nil
+}
+
+// iccLutTransform is a lut8 (mft1) or lut16 (mft2) based transform.
+type iccLutTransform struct {
+	inCurves  [][]float64
+	outCurves [][]float64
+	grid      int
+	outChan   int
+	clut      []float64
+	pcs       string
+	is16      bool
+}
+
+// newICCLutTransform loads the lut8 or lut16 tag `data`. nil is returned if
+// `data` is empty or uses an unsupported tag type.
+func newICCLutTransform(data []byte, numComponents int, pcs string) (*iccLutTransform, error) {
+	if len(data) < 48 {
+		return nil, nil
+	}
+	typ := string(data[0:4])
+	if typ != "mft1" && typ != "mft2" {
+		return nil, nil
+	}
+
+	inChan, outChan, grid := int(data[8]), int(data[9]), int(data[10])
+	if inChan != numComponents || outChan != 3 || grid < 2 {
+		return nil, errors.New("invalid ICC lut dimensions")
+	}
+	t := &iccLutTransform{grid: grid, outChan: outChan, pcs: pcs, is16: typ == "mft2"}
+
+	inEntries, outEntries, size, pos := 256, 256, 1, 48
+	if t.is16 {
+		if len(data) < 52 {
+			return nil, errors.New("ICC lut16 too short")
+		}
+		inEntries = int(binary.BigEndian.Uint16(data[48:50]))
+		outEntries = int(binary.BigEndian.Uint16(data[50:52]))
+		size, pos = 2, 52
+	}
+	if inEntries < 2 || outEntries < 2 {
+		return nil, errors.New("invalid ICC lut table size")
+	}
+	numGrid := int(math.Pow(float64(grid), float64(inChan)))
+
+	need := pos + size*(inChan*inEntries+numGrid*outChan+outChan*outEntries)
+	if len(data) < need {
+		return nil, errors.New("ICC lut too short")
+	}
+	read := func(n int) []float64 {
+		vals := make([]float64, n)
+		for i := range vals {
+			if t.is16 {
+				vals[i] = float64(binary.BigEndian.Uint16(data[pos:])) / 65535
+			} else {
+				vals[i] = float64(data[pos]) / 255
+			}
+			pos += size
+		}
+		return vals
+	}
+	for i := 0; i < inChan; i++ {
+		t.inCurves = append(t.inCurves, read(inEntries))
+	}
+	t.clut = read(numGrid * outChan)
+	for i := 0; i < outChan; i++ {
+		t.outCurves = append(t.outCurves, read(outEntries))
+	}
+	return t, nil
+}
+
+func (t *iccLutTransform) toPCS(vals []float64) ([]float64, error) {
+	in := make([]float64, len(vals))
+	for i, v := range vals {
+		in[i] = interpolateTable(t.inCurves[i], v)
+	}
+
+	// Multilinear interpolation over the grid cell containing `in`.
+	n := len(in)
+	base := make([]int, n)
+	frac := make([]float64, n)
+	for i, v := range in {
+		pos := math.Min(math.Max(v, 0), 1) * float64(t.grid-1)
+		base[i] = int(pos)
+		if base[i] >= t.grid-1 {
+			base[i] = t.grid - 2
+		}
+		frac[i] = pos - float64(base[i])
+	}
+	out := make([]float64, t.outChan)
+	for corner := 0; corner < 1<<uint(n); corner++ {
+		weight := 1.0
+		offset := 0
+		for i := 0; i < n; i++ {
+			idx := base[i]
+			if corner&(1<<uint(n-1-i)) != 0 {
+				idx++
+				weight *= frac[i]
+			} else {
+				weight *= 1 - frac[i]
+			}
+			offset = offset*t.grid + idx
+		}
+		if weight == 0 {
+			continue
+		}
+		for j := range out {
+			out[j] += weight * t.clut[offset*t.outChan+j]
+		}
+	}
+	for i, v := range out {
+		out[i] = interpolateTable(t.outCurves[i], v)
+	}
+
+	// Decode the PCS values.
+	if t.pcs == ICCColorSpaceLab {
+		if t.is16 {
+			// Legacy 16-bit Lab encoding: L 0xFF00 is 100.
+			scale := 65535.0 / 65280.0
+			return []float64{out[0] * scale * 100, out[1]*scale*255 - 128, out[2]*scale*255 - 128}, nil
+		}
+		return []float64{out[0] * 100, out[1]*255 - 128, out[2]*255 - 128}, nil
+	}
+	scale := 65535.0 / 32768.0
+	return []float64{out[0] * scale, out[1] * scale, out[2] * scale}, nil
+}
+
+// iccCurve is a tone reproduction curve.
+type iccCurve interface {
+	eval(v float64) float64
+}
+
+// iccGammaCurve is a curve defined by an exponent.
+type iccGammaCurve float64
+
+func (c iccGammaCurve) eval(v float64) float64 {
+	return math.Pow(math.Min(math.Max(v, 0), 1), float64(c))
+}
+
+// iccSampledCurve is a curve defined by equally spaced samples.
+type iccSampledCurve []float64
+
+func (c iccSampledCurve) eval(v float64) float64 {
+	return interpolateTable(c, v)
+}
+
+// iccParametricCurve is a parametric curve (ICC.1:2004-10 section 10.15).
+type iccParametricCurve struct {
+	funcType int
+	params   []float64
+}
+
+func (c *iccParametricCurve) eval(x float64) float64 {
+	x = math.Min(math.Max(x, 0), 1)
+	p := c.params
+	var y float64
+	switch c.funcType {
+	case 0:
+		y = math.Pow(x, p[0])
+	case 1:
+		if x >= -p[2]/p[1] {
+			y = math.Pow(p[1]*x+p[2], p[0])
+		}
+	case 2:
+		y = p[3]
+		if x >= -p[2]/p[1] {
+			y = math.Pow(p[1]*x+p[2], p[0]) + p[3]
+		}
+	case 3:
+		y = p[3] * x
+		if x >= p[4] {
+			y = math.Pow(p[1]*x+p[2], p[0])
+		}
+	case 4:
+		y = p[3]*x + p[6]
+		if x >= p[4] {
+			y = math.Pow(p[1]*x+p[2], p[0]) + p[5]
+		}
+	}
+	return math.Min(math.Max(y, 0), 1)
+}
+
+// newICCCurve loads the curv or para tag `data`. nil is returned if `data` is
+// empty or uses an unsupported tag type.
+func newICCCurve(data []byte) (iccCurve, error) {
+	if len(data) < 12 {
+		return nil, nil
+	}
+	switch string(data[0:4]) {
+	case "curv":
+		count := int(binary.BigEndian.Uint32(data[8:12]))
+		if len(data) < 12+2*count {
+			return nil, errors.New("ICC curve too short")
+		}
+		switch count {
+		case 0:
+			return iccGammaCurve(1), nil
+		case 1:
+			return iccGammaCurve(float64(binary.BigEndian.Uint16(data[12:14])) / 256), nil
+		}
+		curve := make(iccSampledCurve, count)
+		for i := range curve {
+			curve[i] = float64(binary.BigEndian.Uint16(data[12+2*i:])) / 65535
+		}
+		return curve, nil
+	case "para":
+		funcType := int(binary.BigEndian.Uint16(data[8:10]))
+		numParams := []int{1, 3, 4, 5, 7}
+		if funcType >= len(numParams) {
+			return nil, fmt.Errorf("unsupported ICC parametric curve type %d", funcType)
+		}
+		n := numParams[funcType]
+		if len(data) < 12+4*n {
+			return nil, errors.New("ICC parametric curve too short")
+		}
+		curve := &iccParametricCurve{funcType: funcType}
+		for i := 0; i < n; i++ {
+			curve.params = append(curve.params, iccS15Fixed16(data[12+4*i:]))
+		}
+		return curve, nil
+	}
+	return nil, nil
+}
+
+// iccD50 is the D50 illuminant of the profile connection space.
+var iccD50 = []float64{0.9642, 1.0, 0.8249}
+
+// labToXYZ converts CIE L*a*b* color values to XYZ values relative to D50.
+func labToXYZ(l, a, b float64) []float64 {
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - b/200
+	f := func(t float64) float64 {
+		if t > 6.0/29.0 {
+			return t * t * t
+		}
+		return 3 * (6.0 / 29.0) * (6.0 / 29.0) * (t - 4.0/29.0)
+	}
+	return []float64{iccD50[0] * f(fx), iccD50[1] * f(fy), iccD50[2] * f(fz)}
+}
+
+// interpolateTable linearly interpolates the equally spaced samples of
+// `table` at position `v` (range 0-1).
+func interpolateTable(table []float64, v float64) float64 {
+	pos := math.Min(math.Max(v, 0), 1) * float64(len(table)-1)
+	i := int(pos)
+	if i >= len(table)-1 {
+		return table[len(table)-1]
+	}
+	frac := pos - float64(i)
+	return table[i] + frac*(table[i+1]-table[i])
+}
+
+// iccXYZ loads the XYZ tag `data`.
+func iccXYZ(data []byte) ([]float64, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if len(data) < 20 || string(data[0:4]) != "XYZ " {
+		return nil, errors.New("invalid ICC XYZ tag")
+	}
+	return []float64{iccS15Fixed16(data[8:]), iccS15Fixed16(data[12:]), iccS15Fixed16(data[16:])}, nil
+}
+
+// iccS15Fixed16 decodes a signed 15.16 fixed point number.
+func iccS15Fixed16(data []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(data))) / 65536
+}
+
+// iccSignature returns the signature `data` with trailing spaces and null
+// bytes removed.
+func iccSignature(data []byte) string {
+	return string(bytes.TrimRight(data, " \x00"))
+}
+
+// iccText returns the text of a textDescriptionType (desc), textType (text) or
+// multiLocalizedUnicodeType (mluc) tag.
+func iccText(data []byte) string {
+	if len(data) < 12 {
+		return ""
+	}
+	switch string(data[0:4]) {
+	case "desc":
+		n := int(binary.BigEndian.Uint32(data[8:12]))
+		if n > len(data)-12 {
+			n = len(data) - 12
+		}
+		return string(bytes.TrimRight(data[12:12+n], "\x00"))
+	case "text":
+		return string(bytes.TrimRight(data[8:], "\x00"))
+	case "mluc":
+		if len(data) < 28 || binary.BigEndian.Uint32(data[8:12]) == 0 {
+			return ""
+		}
+		// Use the first record.
+		length := int(binary.BigEndian.Uint32(data[20:24]))
+		offset := int(binary.BigEndian.Uint32(data[24:28]))
+		if offset+length > len(data) {
+			return ""
+		}
+		units := make([]uint16, length/2)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(data[offset+2*i:])
+		}
+		return string(utf16.Decode(units))
+	}
+	return ""
+}
+
+// iccProfileBuilder assembles ICC profiles from tags.
+type iccProfileBuilder struct {
+	sigs []string
+	tags map[string][]byte
+}
+
+func newICCProfileBuilder() *iccProfileBuilder {
+	return &iccProfileBuilder{tags: map[string][]byte{}}
+}
+
+// addTag adds the tag `sig` with `data`. Tags with identical data share their
+// storage in the output profile.
+func (b *iccProfileBuilder) addTag(sig string, data []byte) {
+	b.sigs = append(b.sigs, sig)
+	b.tags[sig] = data
+}
+
+// addDescription adds a textDescriptionType tag for version 2 profiles.
+func (b *iccProfileBuilder) addDescription(sig, text string) {
+	var buf bytes.Buffer
+	buf.WriteString("desc\x00\x00\x00\x00")
+	binary.Write(&buf, binary.BigEndian, uint32(len(text)+1))
+	buf.WriteString(text)
+	buf.WriteByte(0)
+	// Empty Unicode and ScriptCode descriptions.
+	buf.Write(make([]byte, 8+2+1+67))
+	b.addTag(sig, buf.Bytes())
+}
+
+// addText adds a textType tag.
+func (b *iccProfileBuilder) addText(sig, text string) {
+	b.addTag(sig, append([]byte("text\x00\x00\x00\x00"+text), 0))
+}
+
+// addXYZ adds a XYZType tag.
+func (b *iccProfileBuilder) addXYZ(sig string, xyz [3]float64) {
+	var buf bytes.Buffer
+	buf.WriteString("XYZ \x00\x00\x00\x00")
+	for _, v := range xyz {
+		binary.Write(&buf, binary.BigEndian, int32(math.Round(v*65536)))
+	}
+	b.addTag(sig, buf.Bytes())
+}
+
+// bytes returns the profile data.
+func (b *iccProfileBuilder) bytes(class, colorSpace, pcs string) []byte {
+	var tagData bytes.Buffer
+	offsets := map[string]int{}
+	tableSize := 4 + 12*len(b.sigs)
+	dataOffset := iccHeaderSize + tableSize
+
+	var table bytes.Buffer
+	binary.Write(&table, binary.BigEndian, uint32(len(b.sigs)))
+	for _, sig := range b.sigs {
+		data := b.tags[sig]
+		offset, ok := offsets[string(data)]
+		if !ok {
+			offset = dataOffset + tagData.Len()
+			offsets[string(data)] = offset
+			tagData.Write(data)
+			// Tags are 4-byte aligned.
+			for tagData.Len()%4 != 0 {
+				tagData.WriteByte(0)
+			}
+		}
+		table.WriteString(sig)
+		binary.Write(&table, binary.BigEndian, uint32(offset))
+		binary.Write(&table, binary.BigEndian, uint32(len(data)))
+	}
+
+	header := make([]byte, iccHeaderSize)
+	binary.BigEndian.PutUint32(header[0:], uint32(dataOffset+tagData.Len()))
+	header[8], header[9] = 2, 0x10
+	copy(header[12:], class)
+	copy(header[16:], colorSpace)
+	copy(header[20:], pcs)
+	copy(header[36:], "acsp")
+	// PCS illuminant.
+	for i, v := range iccD50 {
+		binary.BigEndian.PutUint32(header[68+4*i:], uint32(int32(math.Round(v*65536))))
+	}
+
+	out := append(header, table.Bytes()...)
+	return append(out, tagData.Bytes()...)
+}
+
+// NewICCProfileSRGB returns a matrix/TRC based sRGB (IEC 61966-2.1) display
+// profile, suitable for ICCBased colorspaces and output intents.
+func NewICCProfileSRGB() (*ICCProfile, error) {
+	b := newICCProfileBuilder()
+	b.addDescription("desc", "sRGB IEC61966-2.1")
+	b.addText("cprt", "No copyright, use freely")
+	b.addXYZ("wtpt", [3]float64{0.9642, 1.0, 0.8249})
+	b.addXYZ("rXYZ", [3]float64{0.4361, 0.2225, 0.0139})
+	b.addXYZ("gXYZ", [3]float64{0.3851, 0.7169, 0.0971})
+	b.addXYZ("bXYZ", [3]float64{0.1431, 0.0606, 0.7141})
+
+	var trc bytes.Buffer
+	trc.WriteString("curv\x00\x00\x00\x00")
+	const samples = 1024
+	binary.Write(&trc, binary.BigEndian, uint32(samples))
+	for i := 0; i < samples; i++ {
+		v := float64(i) / (samples - 1)
+		if v <= 0.04045 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		binary.Write(&trc, binary.BigEndian, uint16(math.Round(v*65535)))
+	}
+	for _, sig := range []string{"rTRC", "gTRC", "bTRC"} {
+		b.addTag(sig, trc.Bytes())
+	}
+
+	return NewICCProfile(b.bytes(ICCClassDisplay, ICCColorSpaceRGB, ICCColorSpaceXYZ))
+}
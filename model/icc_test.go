@@ -0,0 +1,172 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestICCProfileParse(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/iccstream.bin")
+	require.NoError(t, err)
+
+	profile, err := NewICCProfile(data)
+	require.NoError(t, err)
+	require.Equal(t, uint32(3144), profile.Size)
+	require.Equal(t, "Lino", profile.CMMType)
+	require.Equal(t, "2.1.0", profile.Version)
+	require.Equal(t, ICCClassDisplay, profile.DeviceClass)
+	require.Equal(t, ICCColorSpaceRGB, profile.ColorSpace)
+	require.Equal(t, ICCColorSpaceXYZ, profile.PCS)
+	require.Equal(t, 3, profile.NumComponents())
+	require.Equal(t, "sRGB IEC61966-2.1", profile.Description)
+	require.Contains(t, profile.Copyright, "Hewlett-Packard")
+	require.True(t, profile.HasTag("rTRC"))
+	require.False(t, profile.HasTag("A2B0"))
+
+	// The sRGB profile transform is close to the identity.
+	require.True(t, profile.CanTransform())
+	for _, vals := range [][]float64{{0, 0, 0}, {1, 1, 1}, {0.5, 0.5, 0.5}, {1, 0, 0}, {0.2, 0.6, 0.9}} {
+		rgb, err := profile.ToRGB(vals)
+		require.NoError(t, err)
+		require.InDeltaSlice(t, vals, rgb, 0.01)
+	}
+	_, err = profile.ToRGB([]float64{1})
+	require.Error(t, err)
+
+	// Invalid profiles.
+	_, err = NewICCProfile(data[:100])
+	require.Error(t, err)
+	_, err = NewICCProfile(data[:1000])
+	require.Error(t, err)
+	invalid := append([]byte{}, data...)
+	copy(invalid[36:], "xxxx")
+	_, err = NewICCProfile(invalid)
+	require.Error(t, err)
+}
+
+func TestICCProfileSRGB(t *testing.T) {
+	profile, err := NewICCProfileSRGB()
+	require.NoError(t, err)
+	require.Equal(t, "sRGB IEC61966-2.1", profile.Description)
+
+	parsed, err := NewICCProfile(profile.Data)
+	require.NoError(t, err)
+	require.Equal(t, uint32(len(profile.Data)), parsed.Size)
+	for _, vals := range [][]float64{{1, 1, 1}, {0.5, 0.25, 0}, {0, 0, 1}} {
+		rgb, err := parsed.ToRGB(vals)
+		require.NoError(t, err)
+		require.InDeltaSlice(t, vals, rgb, 0.01)
+	}
+}
+
+func TestICCProfileLut16(t *testing.T) {
+	// Gray profile with a 2 point lut16 from black to white in the Lab
+	// connection space.
+	var lut bytes.Buffer
+	lut.WriteString("mft2\x00\x00\x00\x00")
+	lut.Write([]byte{1, 3, 2, 0})
+	for _, v := range []int32{1, 0, 0, 0, 1, 0, 0, 0, 1} {
+		binary.Write(&lut, binary.BigEndian, v<<16)
+	}
+	for _, v := range []uint16{2, 2, 0, 0xffff, 0, 0x8000, 0x8000, 0xff00, 0x8000, 0x8000} {
+		binary.Write(&lut, binary.BigEndian, v)
+	}
+	for i := 0; i < 3; i++ {
+		binary.Write(&lut, binary.BigEndian, []uint16{0, 0xffff})
+	}
+	b := newICCProfileBuilder()
+	b.addDescription("desc", "Gray lut")
+	b.addTag("A2B0", lut.Bytes())
+	profile, err := NewICCProfile(b.bytes(ICCClassOutput, ICCColorSpaceGray, ICCColorSpaceLab))
+	require.NoError(t, err)
+	require.Equal(t, "Gray lut", profile.Description)
+	require.True(t, profile.CanTransform())
+
+	for _, gray := range []float64{0, 1} {
+		rgb, err := profile.ToRGB([]float64{gray})
+		require.NoError(t, err)
+		require.InDeltaSlice(t, []float64{gray, gray, gray}, rgb, 0.01)
+	}
+	xyz, err := profile.ToXYZ([]float64{0.5})
+	require.NoError(t, err)
+	require.InDelta(t, 0.184, xyz[1], 0.01)
+}
+
+func TestICCBasedColorspaceProfile(t *testing.T) {
+	profile, err := NewICCProfileSRGB()
+	require.NoError(t, err)
+	cs, err := NewPdfColorspaceICCBasedFromProfile(profile)
+	require.NoError(t, err)
+	require.Equal(t, 3, cs.N)
+	require.IsType(t, &PdfColorspaceDeviceRGB{}, cs.Alternate)
+
+	// The profile is compressed and can be loaded back.
+	arr, ok := core.GetArray(cs.ToPdfObject())
+	require.True(t, ok)
+	stream, ok := core.GetStream(arr.Get(1))
+	require.True(t, ok)
+	require.Equal(t, "FlateDecode", stream.Get("Filter").String())
+
+	loaded, err := newPdfColorspaceICCBasedFromPdfObject(arr)
+	require.NoError(t, err)
+	require.Equal(t, profile.Data, loaded.Data)
+	loadedProfile, err := loaded.Profile()
+	require.NoError(t, err)
+	require.Equal(t, ICCColorSpaceRGB, loadedProfile.ColorSpace)
+
+	// Mismatching number of components.
+	loaded.N = 4
+	_, err = loaded.Profile()
+	require.Error(t, err)
+}
+
+func TestOutputIntents(t *testing.T) {
+	profile, err := NewICCProfileSRGB()
+	require.NoError(t, err)
+
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+	require.NoError(t, w.AddPage(page))
+	require.Error(t, w.AddOutputIntent(&PdfOutputIntent{Type: PdfOutputIntentTypePDFA}))
+
+	intent := NewPdfOutputIntent(PdfOutputIntentTypePDFA, "sRGB", profile)
+	intent.RegistryName = "http://www.color.org"
+	require.NoError(t, w.AddOutputIntent(intent))
+	require.NoError(t, w.AddOutputIntent(&PdfOutputIntent{
+		Type:                      PdfOutputIntentTypePDFX,
+		OutputConditionIdentifier: "FOGRA39",
+		OutputCondition:           "Coated FOGRA39",
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	intents, err := r.GetOutputIntents()
+	require.NoError(t, err)
+	require.Len(t, intents, 2)
+
+	require.Equal(t, PdfOutputIntentTypePDFA, intents[0].Type)
+	require.Equal(t, "sRGB", intents[0].OutputConditionIdentifier)
+	require.Equal(t, "http://www.color.org", intents[0].RegistryName)
+	require.Equal(t, "sRGB IEC61966-2.1", intents[0].Info)
+	require.NotNil(t, intents[0].DestOutputProfile)
+	require.Equal(t, profile.Data, intents[0].DestOutputProfile.Data)
+
+	require.Equal(t, PdfOutputIntentTypePDFX, intents[1].Type)
+	require.Equal(t, "Coated FOGRA39", intents[1].OutputCondition)
+	require.Nil(t, intents[1].DestOutputProfile)
+}
@@ -6,6 +6,7 @@
 package model
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	goimage "image"
@@ -15,6 +16,12 @@ import (
 	_ "image/gif"
 	_ "image/png"
 	"io"
+	"io/ioutil"
+
+	// Imported for initialization side effects.
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
 
 	"github.com/unidoc/unipdf/v3/common"
 	"github.com/unidoc/unipdf/v3/core"
@@ -124,6 +131,30 @@ func (img *Image) GetSamples() []uint32 {
 	return samples
 }
 
+// samplesByRow returns the samples of the image, skipping the padding bits at
+// the end of the rows of images with less than 8 bits per component, whose
+// rows start at byte boundaries.
+func (img *Image) samplesByRow() []uint32 {
+	bpc := int(img.BitsPerComponent)
+	rowSamples := int(img.Width) * img.ColorComponents
+	if bpc >= 8 || bpc <= 0 || (rowSamples*bpc)%8 == 0 {
+		return img.GetSamples()
+	}
+
+	rowBytes := (rowSamples*bpc + 7) / 8
+	samples := make([]uint32, 0, rowSamples*int(img.Height))
+	for row := 0; row < int(img.Height); row++ {
+		start := row * rowBytes
+		if start+rowBytes > len(img.Data) {
+			common.Log.Debug("Error: Too few samples (got %d rows, expecting %d)", row, img.Height)
+			break
+		}
+		rowData := sampling.ResampleBytes(img.Data[start:start+rowBytes], bpc)
+		samples = append(samples, rowData[:rowSamples]...)
+	}
+	return samples
+}
+
 // SetSamples convert samples to byte-data and sets for the image.
 // NOTE: The method resamples the data and this could lead to high memory usage,
 // especially on large images. It should be used only when it is not possible
@@ -511,10 +542,29 @@ func (ih DefaultImageHandler) NewGrayImageFromGoImage(goimg goimage.Image) (*Ima
 }
 
 // Read reads an image and loads into a new Image object with an RGB
-// colormap and 8 bits per component.
+// colormap and 8 bits per component. The supported formats are JPEG, PNG,
+// GIF, BMP, WebP and TIFF. CMYK TIFF images are loaded with 4 components and
+// bilevel TIFF images compressed with CCITT schemes with 1 bit per component.
 func (ih DefaultImageHandler) Read(reader io.Reader) (*Image, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// CCITT compressed and CMYK TIFF images are not supported by the native implementation.
+	if isTIFF(data) {
+		img, ok, err := decodeTIFF(data)
+		if err != nil {
+			common.Log.Debug("Error decoding TIFF file: %s", err)
+			return nil, err
+		}
+		if ok {
+			return img, nil
+		}
+	}
+
 	// Load the image with the native implementation.
-	goimg, _, err := goimage.Decode(reader)
+	goimg, _, err := goimage.Decode(bytes.NewReader(data))
 	if err != nil {
 		common.Log.Debug("Error decoding file: %s", err)
 		return nil, err
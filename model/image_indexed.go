@@ -0,0 +1,137 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// ToIndexed returns a copy of the 8 bit gray or RGB image `img` where the colors are replaced by
+// indices into the color table of the returned Indexed colorspace, when the image has at most
+// `maxColors` (up to 256) distinct colors. The indices are stored with the lowest number of bits
+// per component (1, 2, 4 or 8) which can represent them, or 8 bits if the image has an alpha
+// channel. Returns false if the image has more colors or another format.
+func (img *Image) ToIndexed(maxColors int) (*Image, *PdfColorspaceSpecialIndexed, bool) {
+	n := img.ColorComponents
+	numPixels := int(img.Width * img.Height)
+	if img.BitsPerComponent != 8 || (n != 1 && n != 3) || numPixels <= 0 ||
+		len(img.Data) < n*numPixels || maxColors < 1 {
+		return nil, nil, false
+	}
+	if maxColors > 256 {
+		maxColors = 256
+	}
+
+	colorIndices := map[uint32]int{}
+	var lookup []byte
+	indices := make([]byte, numPixels)
+	for i := 0; i < numPixels; i++ {
+		pixel := img.Data[i*n : (i+1)*n]
+		var key uint32
+		for _, v := range pixel {
+			key = key<<8 | uint32(v)
+		}
+		index, ok := colorIndices[key]
+		if !ok {
+			if len(colorIndices) == maxColors {
+				return nil, nil, false
+			}
+			index = len(colorIndices)
+			colorIndices[key] = index
+			lookup = append(lookup, pixel...)
+		}
+		indices[i] = byte(index)
+	}
+
+	var bpc int64 = 8
+	if !img.hasAlpha {
+		switch numColors := len(colorIndices); {
+		case numColors <= 2:
+			bpc = 1
+		case numColors <= 4:
+			bpc = 2
+		case numColors <= 16:
+			bpc = 4
+		}
+	}
+
+	cs := NewPdfColorspaceSpecialIndexed()
+	if n == 1 {
+		cs.Base = NewPdfColorspaceDeviceGray()
+	} else {
+		cs.Base = NewPdfColorspaceDeviceRGB()
+	}
+	cs.HiVal = len(colorIndices) - 1
+	cs.Lookup = core.MakeStringFromBytes(lookup)
+	cs.colorLookup = lookup
+
+	indexed := &Image{
+		Width:            img.Width,
+		Height:           img.Height,
+		BitsPerComponent: bpc,
+		ColorComponents:  1,
+		Data:             packSamples(indices, int(img.Width), int(img.Height), uint(bpc)),
+		alphaData:        img.alphaData,
+		hasAlpha:         img.hasAlpha,
+	}
+	return indexed, cs, true
+}
+
+// ToBitonal returns a copy of the 8 bit gray or RGB image `img` with 1 bit per component, when
+// all of its pixels are either black or white and it does not have an alpha channel. Returns false
+// otherwise.
+func (img *Image) ToBitonal() (*Image, bool) {
+	n := img.ColorComponents
+	numPixels := int(img.Width * img.Height)
+	if img.BitsPerComponent != 8 || (n != 1 && n != 3) || numPixels <= 0 ||
+		len(img.Data) < n*numPixels || img.hasAlpha {
+		return nil, false
+	}
+
+	samples := make([]byte, numPixels)
+	for i := 0; i < numPixels; i++ {
+		pixel := img.Data[i*n : (i+1)*n]
+		v := pixel[0]
+		if v != 0 && v != 255 {
+			return nil, false
+		}
+		for _, c := range pixel[1:] {
+			if c != v {
+				return nil, false
+			}
+		}
+		if v == 255 {
+			samples[i] = 1
+		}
+	}
+
+	return &Image{
+		Width:            img.Width,
+		Height:           img.Height,
+		BitsPerComponent: 1,
+		ColorComponents:  1,
+		Data:             packSamples(samples, int(img.Width), int(img.Height), 1),
+	}, true
+}
+
+// packSamples packs the `width`x`height` samples `samples` with `bpc` bits per sample, each row
+// starting at a byte boundary.
+func packSamples(samples []byte, width, height int, bpc uint) []byte {
+	if bpc == 8 {
+		return samples
+	}
+	stride := (width*int(bpc) + 7) / 8
+	packed := make([]byte, stride*height)
+	perByte := 8 / int(bpc)
+	for y := 0; y < height; y++ {
+		row := packed[y*stride:]
+		for x := 0; x < width; x++ {
+			shift := 8 - bpc*uint(x%perByte+1)
+			row[x/perByte] |= samples[y*width+x] << shift
+		}
+	}
+	return packed
+}
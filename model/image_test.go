@@ -379,3 +379,48 @@ func BenchmarkImageCopyingDirect(b *testing.B) {
 		}
 	}
 }
+
+func TestImageToIndexed(t *testing.T) {
+	// 3x2 RGB image with 3 colors.
+	img := &Image{
+		Width:            3,
+		Height:           2,
+		BitsPerComponent: 8,
+		ColorComponents:  3,
+		Data: []byte{
+			255, 0, 0, 0, 255, 0, 255, 0, 0,
+			0, 0, 255, 0, 255, 0, 255, 0, 0,
+		},
+	}
+	indexed, cs, ok := img.ToIndexed(256)
+	require.True(t, ok)
+	require.Equal(t, int64(2), indexed.BitsPerComponent)
+	require.Equal(t, 1, indexed.ColorComponents)
+	require.Equal(t, 2, cs.HiVal)
+	require.Equal(t, []byte{255, 0, 0, 0, 255, 0, 0, 0, 255}, cs.colorLookup)
+	// Indices 0 1 0 and 2 1 0, 2 bits each, rows padded to bytes.
+	require.Equal(t, []byte{0x10, 0x90}, indexed.Data)
+
+	// The colors are restored by the colorspace.
+	restored, err := cs.ImageToRGB(*indexed)
+	require.NoError(t, err)
+	require.Equal(t, img.Data, restored.Data)
+
+	_, _, ok = img.ToIndexed(2)
+	require.False(t, ok)
+
+	// Bitonal images.
+	_, ok = img.ToBitonal()
+	require.False(t, ok)
+	gray := &Image{
+		Width:            9,
+		Height:           1,
+		BitsPerComponent: 8,
+		ColorComponents:  1,
+		Data:             []byte{0, 255, 255, 0, 0, 0, 0, 0, 255},
+	}
+	bitonal, ok := gray.ToBitonal()
+	require.True(t, ok)
+	require.Equal(t, int64(1), bitonal.BitsPerComponent)
+	require.Equal(t, []byte{0x60, 0x80}, bitonal.Data)
+}
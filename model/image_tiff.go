@@ -0,0 +1,408 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"golang.org/x/image/tiff/lzw"
+
+	"github.com/unidoc/unipdf/v3/internal/ccittfax"
+)
+
+// TIFF tags read by the TIFF decoder.
+const (
+	tiffTagImageWidth      = 256
+	tiffTagImageLength     = 257
+	tiffTagBitsPerSample   = 258
+	tiffTagCompression     = 259
+	tiffTagPhotometric     = 262
+	tiffTagFillOrder       = 266
+	tiffTagStripOffsets    = 273
+	tiffTagSamplesPerPixel = 277
+	tiffTagRowsPerStrip    = 278
+	tiffTagStripByteCounts = 279
+	tiffTagPlanarConfig    = 284
+	tiffTagT4Options       = 292
+	tiffTagPredictor       = 317
+	tiffTagTileOffsets     = 324
+)
+
+// TIFF compression schemes.
+const (
+	tiffCompressionNone      = 1
+	tiffCompressionCCITTRLE  = 2
+	tiffCompressionCCITTFax3 = 3
+	tiffCompressionCCITTFax4 = 4
+	tiffCompressionLZW       = 5
+	tiffCompressionDeflate   = 8
+	tiffCompressionPackBits  = 32773
+	tiffCompressionDeflate2  = 32946
+)
+
+// TIFF photometric interpretations.
+const (
+	tiffPhotometricWhiteIsZero = 0
+	tiffPhotometricBlackIsZero = 1
+	tiffPhotometricCMYK        = 5
+)
+
+// maxTIFFImageSize is the maximum size of the decoded data of the TIFF images,
+// in bytes, which prevents invalid or malicious images from exhausting memory.
+const maxTIFFImageSize = 1 << 28
+
+// isTIFF returns true if `data` starts with a TIFF header.
+func isTIFF(data []byte) bool {
+	return len(data) >= 4 && (string(data[:4]) == "II\x2A\x00" || string(data[:4]) == "MM\x00\x2A")
+}
+
+// tiffDecoder decodes the first image of TIFF files.
+type tiffDecoder struct {
+	data  []byte
+	order binary.ByteOrder
+	tags  map[uint16][]uint32
+}
+
+// decodeTIFF decodes the TIFF images which are not supported by the Go TIFF decoder: bilevel
+// images compressed with CCITT schemes and CMYK images. Returns false if the image is not one of
+// them and should be decoded with the Go decoder.
+func decodeTIFF(data []byte) (*Image, bool, error) {
+	d := &tiffDecoder{data: data, tags: map[uint16][]uint32{}}
+	if err := d.readIFD(); err != nil {
+		return nil, false, err
+	}
+
+	compression := d.value(tiffTagCompression, tiffCompressionNone)
+	photometric := d.value(tiffTagPhotometric, tiffPhotometricWhiteIsZero)
+	if d.tags[tiffTagTileOffsets] != nil {
+		return nil, false, nil
+	}
+	switch {
+	case compression == tiffCompressionCCITTRLE || compression == tiffCompressionCCITTFax3 ||
+		compression == tiffCompressionCCITTFax4:
+		img, err := d.decodeCCITT(compression, photometric)
+		return img, true, err
+	case photometric == tiffPhotometricCMYK:
+		img, err := d.decodeCMYK(compression)
+		return img, true, err
+	}
+	return nil, false, nil
+}
+
+// readIFD reads the tags of the first image file directory.
+func (d *tiffDecoder) readIFD() error {
+	if !isTIFF(d.data) || len(d.data) < 8 {
+		return errors.New("invalid TIFF header")
+	}
+	d.order = binary.LittleEndian
+	if d.data[0] == 'M' {
+		d.order = binary.BigEndian
+	}
+
+	offset := int64(d.order.Uint32(d.data[4:]))
+	if offset+2 > int64(len(d.data)) {
+		return errors.New("invalid TIFF directory offset")
+	}
+	numEntries := int64(d.order.Uint16(d.data[offset:]))
+	if offset+2+12*numEntries > int64(len(d.data)) {
+		return errors.New("invalid TIFF directory")
+	}
+	for i := int64(0); i < numEntries; i++ {
+		entry := d.data[offset+2+12*i:]
+		tag := d.order.Uint16(entry)
+		typ := d.order.Uint16(entry[2:])
+		count := int64(d.order.Uint32(entry[4:]))
+
+		var size int64
+		switch typ {
+		case 1: // BYTE
+			size = 1
+		case 3: // SHORT
+			size = 2
+		case 4: // LONG
+			size = 4
+		default:
+			// Other types are not used by the tags read.
+			continue
+		}
+		raw := entry[8:12]
+		if size*count > 4 {
+			valOffset := int64(d.order.Uint32(raw))
+			if count > int64(len(d.data)) || valOffset+size*count > int64(len(d.data)) {
+				return fmt.Errorf("invalid TIFF tag %d", tag)
+			}
+			raw = d.data[valOffset : valOffset+size*count]
+		}
+
+		values := make([]uint32, count)
+		for j := range values {
+			switch size {
+			case 1:
+				values[j] = uint32(raw[j])
+			case 2:
+				values[j] = uint32(d.order.Uint16(raw[2*j:]))
+			case 4:
+				values[j] = d.order.Uint32(raw[4*j:])
+			}
+		}
+		d.tags[tag] = values
+	}
+	return nil
+}
+
+// value returns the first value of the tag `tag`, or `def` if it is not set.
+func (d *tiffDecoder) value(tag uint16, def uint32) uint32 {
+	if values := d.tags[tag]; len(values) > 0 {
+		return values[0]
+	}
+	return def
+}
+
+// dimensions returns the width and the height of the image.
+func (d *tiffDecoder) dimensions() (int, int, error) {
+	width := int(d.value(tiffTagImageWidth, 0))
+	height := int(d.value(tiffTagImageLength, 0))
+	if width <= 0 || height <= 0 || width > 1<<16 || height > 1<<16 {
+		return 0, 0, fmt.Errorf("invalid TIFF image dimensions %dx%d", width, height)
+	}
+	return width, height, nil
+}
+
+// checkSize returns an error if the decoded data of the image, made of
+// `height` rows of `rowSize` bytes, exceeds maxTIFFImageSize.
+func (d *tiffDecoder) checkSize(rowSize, height int) error {
+	if int64(rowSize)*int64(height) > maxTIFFImageSize {
+		return fmt.Errorf("TIFF image too large: %d rows of %d bytes", height, rowSize)
+	}
+	return nil
+}
+
+// strips returns the data of the strips of the image.
+func (d *tiffDecoder) strips() ([][]byte, error) {
+	offsets := d.tags[tiffTagStripOffsets]
+	counts := d.tags[tiffTagStripByteCounts]
+	if len(offsets) == 0 || len(offsets) != len(counts) {
+		return nil, errors.New("invalid TIFF strips")
+	}
+	strips := make([][]byte, len(offsets))
+	for i, offset := range offsets {
+		end := int64(offset) + int64(counts[i])
+		if end > int64(len(d.data)) {
+			return nil, errors.New("TIFF strip out of range")
+		}
+		strips[i] = d.data[offset:end]
+	}
+	return strips, nil
+}
+
+// decodeCCITT decodes bilevel images compressed with the CCITT scheme `compression`. The
+// returned image has 1 bit per component, with 1 for white pixels.
+func (d *tiffDecoder) decodeCCITT(compression, photometric uint32) (*Image, error) {
+	width, height, err := d.dimensions()
+	if err != nil {
+		return nil, err
+	}
+	strips, err := d.strips()
+	if err != nil {
+		return nil, err
+	}
+	rowsPerStrip := int(d.value(tiffTagRowsPerStrip, uint32(height)))
+	if rowsPerStrip <= 0 || rowsPerStrip > height {
+		rowsPerStrip = height
+	}
+
+	encoder := &ccittfax.Encoder{Columns: width}
+	switch compression {
+	case tiffCompressionCCITTRLE:
+		encoder.EncodedByteAlign = true
+	case tiffCompressionCCITTFax3:
+		options := d.value(tiffTagT4Options, 0)
+		if options&1 != 0 {
+			encoder.K = 1
+		}
+		encoder.EndOfLine = true
+		encoder.EncodedByteAlign = options&4 != 0
+	case tiffCompressionCCITTFax4:
+		encoder.K = -1
+	}
+
+	stride := (width + 7) / 8
+	if err := d.checkSize(stride, height); err != nil {
+		return nil, err
+	}
+	decoded := make([]byte, 0, stride*height)
+	row := 0
+	for _, strip := range strips {
+		if row >= height {
+			break
+		}
+		if d.value(tiffTagFillOrder, 1) == 2 {
+			strip = reverseBits(strip)
+		}
+		encoder.Rows = rowsPerStrip
+		if height-row < rowsPerStrip {
+			encoder.Rows = height - row
+		}
+		pixels, err := encoder.Decode(strip)
+		if err != nil {
+			return nil, err
+		}
+		for _, pixelsRow := range pixels {
+			if row >= height {
+				break
+			}
+			packed := make([]byte, stride)
+			for x := 0; x < width && x < len(pixelsRow); x++ {
+				// Pixels are 1 for white runs, 0 for black runs.
+				white := pixelsRow[x] == 1
+				if photometric == tiffPhotometricBlackIsZero {
+					white = !white
+				}
+				if white {
+					packed[x/8] |= 0x80 >> uint(x%8)
+				}
+			}
+			decoded = append(decoded, packed...)
+			row++
+		}
+	}
+	if row < height {
+		return nil, fmt.Errorf("TIFF image is missing rows: %d < %d", row, height)
+	}
+
+	return &Image{
+		Width:            int64(width),
+		Height:           int64(height),
+		BitsPerComponent: 1,
+		ColorComponents:  1,
+		Data:             decoded,
+	}, nil
+}
+
+// decodeCMYK decodes 8 bit CMYK images compressed with the scheme `compression`.
+func (d *tiffDecoder) decodeCMYK(compression uint32) (*Image, error) {
+	width, height, err := d.dimensions()
+	if err != nil {
+		return nil, err
+	}
+	samples := int(d.value(tiffTagSamplesPerPixel, 4))
+	if samples < 4 || d.value(tiffTagBitsPerSample, 8) != 8 {
+		return nil, errors.New("unsupported TIFF CMYK sample format")
+	}
+	if d.value(tiffTagPlanarConfig, 1) != 1 {
+		return nil, errors.New("unsupported TIFF planar configuration")
+	}
+	strips, err := d.strips()
+	if err != nil {
+		return nil, err
+	}
+
+	// Decompress the strips, which contain whole rows. The decompressed data
+	// is limited to the size of the image.
+	rowSize := width * samples
+	if err := d.checkSize(rowSize, height); err != nil {
+		return nil, err
+	}
+	size := int64(rowSize * height)
+	var raw []byte
+	for _, strip := range strips {
+		if int64(len(raw)) >= size {
+			break
+		}
+		limit := size - int64(len(raw))
+		var data []byte
+		switch compression {
+		case tiffCompressionNone:
+			data = strip
+		case tiffCompressionLZW:
+			r := lzw.NewReader(bytes.NewReader(strip), lzw.MSB, 8)
+			data, err = ioutil.ReadAll(io.LimitReader(r, limit))
+			r.Close()
+		case tiffCompressionDeflate, tiffCompressionDeflate2:
+			var r io.ReadCloser
+			if r, err = zlib.NewReader(bytes.NewReader(strip)); err == nil {
+				data, err = ioutil.ReadAll(io.LimitReader(r, limit))
+				r.Close()
+			}
+		case tiffCompressionPackBits:
+			data, err = unpackBits(strip)
+		default:
+			return nil, fmt.Errorf("unsupported TIFF compression %d", compression)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if d.value(tiffTagPredictor, 1) == 2 {
+			for y := 0; y+rowSize <= len(data); y += rowSize {
+				for x := samples; x < rowSize; x++ {
+					data[y+x] += data[y+x-samples]
+				}
+			}
+		}
+		raw = append(raw, data...)
+	}
+	if len(raw) < rowSize*height {
+		return nil, errors.New("TIFF image data too short")
+	}
+
+	// Drop the extra samples, e.g. alpha.
+	decoded := make([]byte, 0, 4*width*height)
+	for i := 0; i < width*height; i++ {
+		decoded = append(decoded, raw[i*samples:i*samples+4]...)
+	}
+	return &Image{
+		Width:            int64(width),
+		Height:           int64(height),
+		BitsPerComponent: 8,
+		ColorComponents:  4,
+		Data:             decoded,
+	}, nil
+}
+
+// unpackBits decodes the PackBits compressed data `data`.
+func unpackBits(data []byte) ([]byte, error) {
+	var out []byte
+	for i := 0; i < len(data); {
+		n := int(int8(data[i]))
+		i++
+		switch {
+		case n >= 0:
+			if i+n+1 > len(data) {
+				return nil, errors.New("invalid PackBits data")
+			}
+			out = append(out, data[i:i+n+1]...)
+			i += n + 1
+		case n != -128:
+			if i >= len(data) {
+				return nil, errors.New("invalid PackBits data")
+			}
+			for j := 0; j < 1-n; j++ {
+				out = append(out, data[i])
+			}
+			i++
+		}
+	}
+	return out, nil
+}
+
+// reverseBits returns a copy of `data` with the order of the bits of each byte reversed.
+func reverseBits(data []byte) []byte {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		var r byte
+		for j := uint(0); j < 8; j++ {
+			r |= ((b >> j) & 1) << (7 - j)
+		}
+		out[i] = r
+	}
+	return out
+}
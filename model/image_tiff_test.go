@@ -0,0 +1,169 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/bmp"
+
+	"github.com/unidoc/unipdf/v3/internal/ccittfax"
+)
+
+// makeTIFF returns a little-endian TIFF file with the image file directory entries `tags`
+// (written as LONG values) and the strips `strips`.
+func makeTIFF(tags map[uint16][]uint32, strips [][]byte) []byte {
+	var stripData bytes.Buffer
+	var offsets, counts []uint32
+	for _, strip := range strips {
+		offsets = append(offsets, uint32(8+stripData.Len()))
+		counts = append(counts, uint32(len(strip)))
+		stripData.Write(strip)
+	}
+	tags[tiffTagStripOffsets] = offsets
+	tags[tiffTagStripByteCounts] = counts
+
+	var keys []int
+	for tag := range tags {
+		keys = append(keys, int(tag))
+	}
+	sort.Ints(keys)
+
+	ifdOffset := 8 + stripData.Len()
+	valuesOffset := ifdOffset + 2 + 12*len(keys) + 4
+	var ifd, values bytes.Buffer
+	binary.Write(&ifd, binary.LittleEndian, uint16(len(keys)))
+	for _, key := range keys {
+		vals := tags[uint16(key)]
+		binary.Write(&ifd, binary.LittleEndian, uint16(key))
+		binary.Write(&ifd, binary.LittleEndian, uint16(4))
+		binary.Write(&ifd, binary.LittleEndian, uint32(len(vals)))
+		if len(vals) == 1 {
+			binary.Write(&ifd, binary.LittleEndian, vals[0])
+			continue
+		}
+		binary.Write(&ifd, binary.LittleEndian, uint32(valuesOffset+values.Len()))
+		binary.Write(&values, binary.LittleEndian, vals)
+	}
+	binary.Write(&ifd, binary.LittleEndian, uint32(0))
+
+	var out bytes.Buffer
+	out.WriteString("II\x2A\x00")
+	binary.Write(&out, binary.LittleEndian, uint32(ifdOffset))
+	out.Write(stripData.Bytes())
+	out.Write(ifd.Bytes())
+	out.Write(values.Bytes())
+	return out.Bytes()
+}
+
+func TestReadCMYKTIFF(t *testing.T) {
+	// 2x3 image in 2 strips of 2 and 1 rows.
+	pixels := []byte{
+		0, 10, 20, 30, 40, 50, 60, 70,
+		80, 90, 100, 110, 120, 130, 140, 150,
+		160, 170, 180, 190, 200, 210, 220, 230,
+	}
+	tags := map[uint16][]uint32{
+		tiffTagImageWidth:      {2},
+		tiffTagImageLength:     {3},
+		tiffTagBitsPerSample:   {8, 8, 8, 8},
+		tiffTagCompression:     {tiffCompressionNone},
+		tiffTagPhotometric:     {tiffPhotometricCMYK},
+		tiffTagSamplesPerPixel: {4},
+		tiffTagRowsPerStrip:    {2},
+	}
+	data := makeTIFF(tags, [][]byte{pixels[:16], pixels[16:]})
+
+	img, err := ImageHandling.Read(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), img.Width)
+	require.Equal(t, int64(3), img.Height)
+	require.Equal(t, 4, img.ColorComponents)
+	require.Equal(t, int64(8), img.BitsPerComponent)
+	require.Equal(t, pixels, img.Data)
+
+	xobj, err := NewXObjectImageFromImage(img, nil, nil)
+	require.NoError(t, err)
+	_, ok := xobj.ColorSpace.(*PdfColorspaceDeviceCMYK)
+	require.True(t, ok)
+
+	// PackBits compression: a literal run of 16 bytes and a repeated byte.
+	tags[tiffTagCompression] = []uint32{tiffCompressionPackBits}
+	tags[tiffTagRowsPerStrip] = []uint32{3}
+	packed := append([]byte{15}, pixels[:16]...)
+	packed = append(packed, 0xF9, 7) // 8 times 7.
+	data = makeTIFF(tags, [][]byte{packed})
+	img, err = ImageHandling.Read(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, append(append([]byte{}, pixels[:16]...), 7, 7, 7, 7, 7, 7, 7, 7), img.Data)
+
+	// The size of the images is checked before decoding them.
+	tags[tiffTagImageWidth] = []uint32{1 << 16}
+	tags[tiffTagImageLength] = []uint32{1 << 16}
+	data = makeTIFF(tags, [][]byte{packed})
+	_, err = ImageHandling.Read(bytes.NewReader(data))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "too large")
+}
+
+func TestReadCCITTTIFF(t *testing.T) {
+	// 16x4 bilevel image with a black diagonal line, in 2 strips.
+	const width, height = 16, 4
+	rows := make([][]byte, height)
+	for y := range rows {
+		rows[y] = make([]byte, width)
+		for x := range rows[y] {
+			if x != 2*y && x != 2*y+1 {
+				rows[y][x] = 1 // White.
+			}
+		}
+	}
+	var strips [][]byte
+	for i := 0; i < height; i += 2 {
+		encoder := &ccittfax.Encoder{K: -1, Columns: width, Rows: 2}
+		strips = append(strips, encoder.Encode(rows[i:i+2]))
+	}
+	data := makeTIFF(map[uint16][]uint32{
+		tiffTagImageWidth:    {width},
+		tiffTagImageLength:   {height},
+		tiffTagBitsPerSample: {1},
+		tiffTagCompression:   {tiffCompressionCCITTFax4},
+		tiffTagPhotometric:   {tiffPhotometricWhiteIsZero},
+		tiffTagRowsPerStrip:  {2},
+	}, strips)
+
+	img, err := ImageHandling.Read(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, int64(width), img.Width)
+	require.Equal(t, int64(height), img.Height)
+	require.Equal(t, int64(1), img.BitsPerComponent)
+	require.Equal(t, 1, img.ColorComponents)
+	require.Equal(t, []byte{
+		0x3F, 0xFF,
+		0xCF, 0xFF,
+		0xF3, 0xFF,
+		0xFC, 0xFF,
+	}, img.Data)
+}
+
+func TestReadBMP(t *testing.T) {
+	goimg := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	goimg.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	goimg.Set(1, 0, color.NRGBA{B: 255, A: 255})
+	var buf bytes.Buffer
+	require.NoError(t, bmp.Encode(&buf, goimg))
+
+	img, err := ImageHandling.Read(&buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), img.Width)
+	require.Equal(t, []byte{255, 0, 0, 0, 0, 255}, img.Data)
+}
@@ -0,0 +1,276 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// PdfInfoTrapped represents the trapping state of a document, as specified by
+// the Trapped entry of the document information dictionary.
+type PdfInfoTrapped string
+
+// Trapping states.
+const (
+	TrappedUnset   PdfInfoTrapped = ""
+	TrappedTrue    PdfInfoTrapped = "True"
+	TrappedFalse   PdfInfoTrapped = "False"
+	TrappedUnknown PdfInfoTrapped = "Unknown"
+)
+
+// PdfInfo represents the document information dictionary (section 14.3.3
+// p. 550 PDF32000_2008). Empty text fields and zero dates are not written.
+type PdfInfo struct {
+	Title        string
+	Author       string
+	Subject      string
+	Keywords     string
+	Creator      string
+	Producer     string
+	CreationDate time.Time
+	ModDate      time.Time
+	Trapped      PdfInfoTrapped
+
+	// custom contains the entries of the information dictionary which are
+	// not standard entries.
+	custom *core.PdfObjectDictionary
+}
+
+// standardInfoKeys contains the keys of the standard entries of the document
+// information dictionary.
+var standardInfoKeys = map[core.PdfObjectName]struct{}{
+	"Title": {}, "Author": {}, "Subject": {}, "Keywords": {}, "Creator": {},
+	"Producer": {}, "CreationDate": {}, "ModDate": {}, "Trapped": {},
+}
+
+// NewPdfInfo returns a new empty document information dictionary.
+func NewPdfInfo() *PdfInfo {
+	return &PdfInfo{custom: core.MakeDict()}
+}
+
+// NewPdfInfoFromObject loads a document information dictionary from the
+// specified PDF object. Invalid dates are ignored.
+func NewPdfInfoFromObject(obj core.PdfObject) (*PdfInfo, error) {
+	dict, ok := core.GetDict(obj)
+	if !ok {
+		return nil, errors.New("document information must be a dictionary")
+	}
+
+	info := NewPdfInfo()
+	for _, key := range dict.Keys() {
+		val := core.TraceToDirectObject(dict.Get(key))
+		if _, ok := standardInfoKeys[key]; !ok {
+			info.custom.Set(key, val)
+			continue
+		}
+
+		switch key {
+		case "CreationDate", "ModDate":
+			str, ok := core.GetString(val)
+			if !ok {
+				common.Log.Debug("Invalid document information %s: %v", key, val)
+				continue
+			}
+			date, err := NewPdfDate(str.Str())
+			if err != nil {
+				common.Log.Debug("Invalid document information %s: %v", key, err)
+				continue
+			}
+			if key == "CreationDate" {
+				info.CreationDate = date.ToGoTime()
+			} else {
+				info.ModDate = date.ToGoTime()
+			}
+		case "Trapped":
+			if name, ok := core.GetNameVal(val); ok {
+				info.Trapped = PdfInfoTrapped(name)
+			} else if b, ok := core.GetBoolVal(val); ok {
+				// Some producers write the trapping state as a boolean.
+				info.Trapped = TrappedFalse
+				if b {
+					info.Trapped = TrappedTrue
+				}
+			}
+		default:
+			str, ok := core.GetString(val)
+			if !ok {
+				common.Log.Debug("Invalid document information %s: %v", key, val)
+				continue
+			}
+			*info.textField(key) = str.Decoded()
+		}
+	}
+	return info, nil
+}
+
+// textField returns the text field corresponding to the standard text entry
+// `key` of the information dictionary.
+func (info *PdfInfo) textField(key core.PdfObjectName) *string {
+	switch key {
+	case "Title":
+		return &info.Title
+	case "Author":
+		return &info.Author
+	case "Subject":
+		return &info.Subject
+	case "Keywords":
+		return &info.Keywords
+	case "Creator":
+		return &info.Creator
+	}
+	return &info.Producer
+}
+
+// CustomInfo returns the value of the custom entry `key` of the information
+// dictionary, or an empty string if not set.
+func (info *PdfInfo) CustomInfo(key string) string {
+	if info.custom == nil {
+		return ""
+	}
+	str, ok := core.GetString(info.custom.Get(core.PdfObjectName(key)))
+	if !ok {
+		return ""
+	}
+	return str.Decoded()
+}
+
+// SetCustomInfo sets the value of the custom entry `key` of the information
+// dictionary. The entry is removed if `value` is empty. The keys of the
+// standard entries cannot be used.
+func (info *PdfInfo) SetCustomInfo(key, value string) error {
+	name := core.PdfObjectName(key)
+	if _, ok := standardInfoKeys[name]; ok {
+		return errors.New("cannot set standard entry as custom information")
+	}
+	if info.custom == nil {
+		info.custom = core.MakeDict()
+	}
+	if value == "" {
+		info.custom.Remove(name)
+		return nil
+	}
+	info.custom.Set(name, makeTextString(value))
+	return nil
+}
+
+// CustomKeys returns the keys of the custom entries of the information
+// dictionary.
+func (info *PdfInfo) CustomKeys() []string {
+	if info.custom == nil {
+		return nil
+	}
+	var keys []string
+	for _, key := range info.custom.Keys() {
+		keys = append(keys, string(key))
+	}
+	return keys
+}
+
+// ToPdfObject returns the PDF dictionary representation of the document
+// information.
+func (info *PdfInfo) ToPdfObject() core.PdfObject {
+	dict := core.MakeDict()
+	for _, field := range []struct {
+		key   core.PdfObjectName
+		value string
+	}{
+		{"Title", info.Title},
+		{"Author", info.Author},
+		{"Subject", info.Subject},
+		{"Keywords", info.Keywords},
+		{"Creator", info.Creator},
+		{"Producer", info.Producer},
+	} {
+		if field.value != "" {
+			dict.Set(field.key, makeTextString(field.value))
+		}
+	}
+
+	for _, field := range []struct {
+		key   core.PdfObjectName
+		value time.Time
+	}{
+		{"CreationDate", info.CreationDate},
+		{"ModDate", info.ModDate},
+	} {
+		if field.value.IsZero() {
+			continue
+		}
+		if date, err := NewPdfDateFromTime(field.value); err == nil {
+			dict.Set(field.key, date.ToPdfObject())
+		}
+	}
+
+	if info.Trapped != TrappedUnset {
+		dict.Set("Trapped", core.MakeName(string(info.Trapped)))
+	}
+
+	if info.custom != nil {
+		for _, key := range info.custom.Keys() {
+			dict.Set(key, info.custom.Get(key))
+		}
+	}
+	return dict
+}
+
+// makeTextString returns a text string object representing `s`, using the
+// UTF-16BE encoding if `s` contains non ASCII characters.
+func makeTextString(s string) *core.PdfObjectString {
+	for _, r := range s {
+		if r > 0x7f {
+			return core.MakeEncodedString(s, true)
+		}
+	}
+	return core.MakeString(s)
+}
+
+// GetPdfInfo returns the document information dictionary of the document.
+// An empty PdfInfo is returned if the document has no information
+// dictionary.
+func (r *PdfReader) GetPdfInfo() (*PdfInfo, error) {
+	trailer, err := r.GetTrailer()
+	if err != nil {
+		return nil, err
+	}
+
+	obj := core.ResolveReference(trailer.Get("Info"))
+	if obj == nil || core.IsNullObject(obj) {
+		return NewPdfInfo(), nil
+	}
+	return NewPdfInfoFromObject(obj)
+}
+
+// GetPdfInfo returns the document information dictionary of the output
+// document. By default, it contains the values set using the SetPdfTitle,
+// SetPdfAuthor, etc. package level functions.
+func (w *PdfWriter) GetPdfInfo() *PdfInfo {
+	info, err := NewPdfInfoFromObject(w.infoObj.PdfObject)
+	if err != nil {
+		common.Log.Debug("ERROR: invalid document information: %v", err)
+		return NewPdfInfo()
+	}
+	return info
+}
+
+// SetPdfInfo sets the document information dictionary of the output document,
+// replacing the existing entries.
+func (w *PdfWriter) SetPdfInfo(info *PdfInfo) {
+	if info == nil {
+		info = NewPdfInfo()
+	}
+	w.infoObj.PdfObject = info.ToPdfObject()
+}
+
+// SetPdfInfo sets the document information dictionary of the output
+// document. By default, the output document uses the information dictionary
+// generated by the PdfWriter.
+func (a *PdfAppender) SetPdfInfo(info *PdfInfo) {
+	a.info = info
+}
@@ -0,0 +1,93 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestPdfInfo(t *testing.T) {
+	created := time.Date(2019, 12, 31, 23, 59, 0, 0, time.FixedZone("", 2*3600))
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+
+	info := w.GetPdfInfo()
+	require.NotEmpty(t, info.Producer)
+	info.Title = "Déjà vu"
+	info.Author = "Alice"
+	info.Subject = "Testing"
+	info.Keywords = "info, test"
+	info.Creator = "Test"
+	info.CreationDate = created
+	info.Trapped = TrappedFalse
+	require.NoError(t, info.SetCustomInfo("Department", "R&D"))
+	require.Error(t, info.SetCustomInfo("Title", "Title"))
+	w.SetPdfInfo(info)
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	readInfo, err := r.GetPdfInfo()
+	require.NoError(t, err)
+	require.Equal(t, "Déjà vu", readInfo.Title)
+	require.Equal(t, "Alice", readInfo.Author)
+	require.Equal(t, "Testing", readInfo.Subject)
+	require.Equal(t, "info, test", readInfo.Keywords)
+	require.Equal(t, "Test", readInfo.Creator)
+	require.Equal(t, info.Producer, readInfo.Producer)
+	require.True(t, created.Equal(readInfo.CreationDate))
+	require.True(t, readInfo.ModDate.IsZero())
+	require.Equal(t, TrappedFalse, readInfo.Trapped)
+	require.Equal(t, []string{"Department"}, readInfo.CustomKeys())
+	require.Equal(t, "R&D", readInfo.CustomInfo("Department"))
+
+	// Update the information dictionary using an appender.
+	appender, err := NewPdfAppender(r)
+	require.NoError(t, err)
+	readInfo.Title = "Updated"
+	readInfo.ModDate = created.Add(time.Hour)
+	require.NoError(t, readInfo.SetCustomInfo("Department", ""))
+	appender.SetPdfInfo(readInfo)
+
+	buf.Reset()
+	require.NoError(t, appender.Write(&buf))
+
+	r, err = NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	readInfo, err = r.GetPdfInfo()
+	require.NoError(t, err)
+	require.Equal(t, "Updated", readInfo.Title)
+	require.Equal(t, "Alice", readInfo.Author)
+	require.True(t, created.Add(time.Hour).Equal(readInfo.ModDate))
+	require.Empty(t, readInfo.CustomKeys())
+}
+
+func TestPdfInfoInvalidEntries(t *testing.T) {
+	dict := core.MakeDict()
+	dict.Set("Title", core.MakeInteger(1))
+	dict.Set("CreationDate", core.MakeString("invalid"))
+	dict.Set("Trapped", core.MakeBool(true))
+
+	info, err := NewPdfInfoFromObject(dict)
+	require.NoError(t, err)
+	require.Empty(t, info.Title)
+	require.True(t, info.CreationDate.IsZero())
+	require.Equal(t, TrappedTrue, info.Trapped)
+
+	_, err = NewPdfInfoFromObject(core.MakeInteger(1))
+	require.Error(t, err)
+}
@@ -0,0 +1,216 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fonts
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var errInvalidCFF = errors.New("invalid CFF table")
+
+// ParseCFF parses the "CFF " table of OpenType fonts with PostScript outlines. Only the charset of
+// CID-keyed fonts is parsed, which maps the glyphs of the font to CIDs.
+// See the Compact Font Format Specification, Adobe Technical Note #5176.
+func (t *ttfParser) ParseCFF() error {
+	if err := t.Seek("CFF "); err != nil {
+		return err
+	}
+	data := make([]byte, t.tableLengths["CFF "])
+	if _, err := io.ReadFull(t.f, data); err != nil {
+		return err
+	}
+	if len(data) < 4 {
+		return errInvalidCFF
+	}
+
+	// Header, Name INDEX, Top DICT INDEX.
+	pos := int(data[2])
+	_, pos, err := cffIndex(data, pos)
+	if err != nil {
+		return err
+	}
+	topDicts, _, err := cffIndex(data, pos)
+	if err != nil {
+		return err
+	}
+	if len(topDicts) == 0 {
+		return errInvalidCFF
+	}
+	dict, err := cffDict(topDicts[0])
+	if err != nil {
+		return err
+	}
+	if _, ok := dict[0x0c1e]; !ok {
+		// Not CID-keyed (no ROS operator): the glyphs are selected by GID.
+		return nil
+	}
+
+	var charset int
+	if ops := dict[15]; len(ops) > 0 {
+		charset = ops[0]
+	}
+	cids, err := cffCharset(data, charset, int(t.numGlyphs))
+	if err != nil {
+		return err
+	}
+	t.rec.GlyphCIDs = cids
+	return nil
+}
+
+// cffIndex returns the entries of the CFF INDEX at `pos` in `data` and the position following the
+// INDEX.
+func cffIndex(data []byte, pos int) ([][]byte, int, error) {
+	if pos+2 > len(data) {
+		return nil, 0, errInvalidCFF
+	}
+	count := int(binary.BigEndian.Uint16(data[pos:]))
+	if count == 0 {
+		return nil, pos + 2, nil
+	}
+	if pos+3 > len(data) {
+		return nil, 0, errInvalidCFF
+	}
+	offSize := int(data[pos+2])
+	if offSize < 1 || offSize > 4 {
+		return nil, 0, errInvalidCFF
+	}
+	offsetsPos := pos + 3
+	dataPos := offsetsPos + (count+1)*offSize - 1
+	if dataPos >= len(data) {
+		return nil, 0, errInvalidCFF
+	}
+
+	offset := func(i int) int {
+		var v int
+		for _, b := range data[offsetsPos+i*offSize : offsetsPos+(i+1)*offSize] {
+			v = v<<8 | int(b)
+		}
+		return dataPos + v
+	}
+	entries := make([][]byte, count)
+	for i := range entries {
+		start, end := offset(i), offset(i+1)
+		if start > end || end > len(data) {
+			return nil, 0, errInvalidCFF
+		}
+		entries[i] = data[start:end]
+	}
+	return entries, offset(count), nil
+}
+
+// cffDict returns the integer operands of the operators of the CFF DICT `data`, keyed by operator.
+// The two byte operators are keyed by 0x0c00 plus their second byte. Real operands are returned as 0.
+func cffDict(data []byte) (map[int][]int, error) {
+	dict := map[int][]int{}
+	var operands []int
+	for i := 0; i < len(data); {
+		b0 := int(data[i])
+		switch {
+		case b0 == 12:
+			if i+1 >= len(data) {
+				return nil, errInvalidCFF
+			}
+			dict[0x0c00|int(data[i+1])] = operands
+			operands = nil
+			i += 2
+		case b0 <= 21:
+			dict[b0] = operands
+			operands = nil
+			i++
+		case b0 == 28:
+			if i+2 >= len(data) {
+				return nil, errInvalidCFF
+			}
+			operands = append(operands, int(int16(binary.BigEndian.Uint16(data[i+1:]))))
+			i += 3
+		case b0 == 29:
+			if i+4 >= len(data) {
+				return nil, errInvalidCFF
+			}
+			operands = append(operands, int(int32(binary.BigEndian.Uint32(data[i+1:]))))
+			i += 5
+		case b0 == 30:
+			// Real number: nibbles up to the 0xf end nibble.
+			i++
+			for i < len(data) && data[i]&0x0f != 0x0f && data[i]&0xf0 != 0xf0 {
+				i++
+			}
+			i++
+			operands = append(operands, 0)
+		case b0 >= 32 && b0 <= 246:
+			operands = append(operands, b0-139)
+			i++
+		case b0 >= 247 && b0 <= 254:
+			if i+1 >= len(data) {
+				return nil, errInvalidCFF
+			}
+			b1 := int(data[i+1])
+			if b0 <= 250 {
+				operands = append(operands, (b0-247)*256+b1+108)
+			} else {
+				operands = append(operands, -(b0-251)*256-b1-108)
+			}
+			i += 2
+		default:
+			return nil, errInvalidCFF
+		}
+	}
+	return dict, nil
+}
+
+// cffCharset returns the CIDs of the `numGlyphs` glyphs of a CID-keyed CFF font described by the
+// charset at offset `offset` of the CFF data `data`. The predefined charsets (offsets 0 to 2) are
+// mapped to the identity.
+func cffCharset(data []byte, offset, numGlyphs int) ([]uint16, error) {
+	cids := make([]uint16, numGlyphs)
+	if offset <= 2 {
+		for gid := range cids {
+			cids[gid] = uint16(gid)
+		}
+		return cids, nil
+	}
+	if offset >= len(data) {
+		return nil, errInvalidCFF
+	}
+
+	format := data[offset]
+	pos := offset + 1
+	// GID 0 is .notdef, with CID 0.
+	for gid := 1; gid < numGlyphs; {
+		switch format {
+		case 0:
+			if pos+2 > len(data) {
+				return nil, errInvalidCFF
+			}
+			cids[gid] = binary.BigEndian.Uint16(data[pos:])
+			pos += 2
+			gid++
+		case 1, 2:
+			size := 3
+			if format == 2 {
+				size = 4
+			}
+			if pos+size > len(data) {
+				return nil, errInvalidCFF
+			}
+			first := int(binary.BigEndian.Uint16(data[pos:]))
+			nLeft := int(data[pos+2])
+			if format == 2 {
+				nLeft = int(binary.BigEndian.Uint16(data[pos+2:]))
+			}
+			pos += size
+			for i := 0; i <= nLeft && gid < numGlyphs; i++ {
+				cids[gid] = uint16(first + i)
+				gid++
+			}
+		default:
+			return nil, errInvalidCFF
+		}
+	}
+	return cids, nil
+}
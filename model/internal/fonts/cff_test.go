@@ -0,0 +1,47 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fonts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCFFDict(t *testing.T) {
+	// "100 400 charset", "0 0 1 ROS" (two byte operator) and "-1000 FontBBox".
+	data := []byte{239, 248, 36, 15, 139, 139, 140, 12, 30, 28, 0xfc, 0x18, 5}
+	dict, err := cffDict(data)
+	require.NoError(t, err)
+	require.Equal(t, []int{100, 400}, dict[15])
+	require.Equal(t, []int{0, 0, 1}, dict[0x0c1e])
+	require.Equal(t, []int{-1000}, dict[5])
+}
+
+func TestCFFCharset(t *testing.T) {
+	// Format 0.
+	cids, err := cffCharset([]byte{0, 0, 0, 0, 0, 10, 0, 20}, 3, 3)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{0, 10, 20}, cids)
+
+	// Format 1: CIDs 100-102, then 200.
+	cids, err = cffCharset([]byte{0, 0, 0, 1, 0, 100, 2, 0, 200, 0}, 3, 5)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{0, 100, 101, 102, 200}, cids)
+
+	// Format 2.
+	cids, err = cffCharset([]byte{0, 0, 0, 2, 0, 50, 0, 1}, 3, 3)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{0, 50, 51}, cids)
+
+	// Predefined charset.
+	cids, err = cffCharset(nil, 0, 3)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{0, 1, 2}, cids)
+
+	_, err = cffCharset([]byte{0, 0, 0, 0}, 3, 3)
+	require.Error(t, err)
+}
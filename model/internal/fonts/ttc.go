@@ -0,0 +1,129 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package fonts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidTtcIndex is returned when a font index is out of the range of the fonts of a TrueType
+// collection.
+var ErrInvalidTtcIndex = errors.New("font index out of range of the collection")
+
+// IsTtc returns true if `data` is a TrueType collection (.ttc) file.
+func IsTtc(data []byte) bool {
+	return len(data) >= 4 && string(data[:4]) == "ttcf"
+}
+
+// readCollectionOffsets reads the offsets of the table directories of the fonts of a TrueType
+// collection, whose "ttcf" tag has already been read.
+func (t *ttfParser) readCollectionOffsets() ([]uint32, error) {
+	t.Skip(4) // majorVersion, minorVersion
+	numFonts := t.ReadULong()
+	if numFonts == 0 || numFonts > 0xFFFF {
+		return nil, fmt.Errorf("invalid number of fonts in collection: %d", numFonts)
+	}
+	offsets := make([]uint32, numFonts)
+	if err := binary.Read(t.f, binary.BigEndian, offsets); err != nil {
+		return nil, err
+	}
+	return offsets, nil
+}
+
+// collectionOffsets returns the offsets of the table directories of the fonts of the TrueType
+// collection `r`.
+func collectionOffsets(r io.ReadSeeker) ([]uint32, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	t := &ttfParser{f: r}
+	tag, err := t.ReadStr(4)
+	if err != nil {
+		return nil, err
+	}
+	if tag != "ttcf" {
+		return nil, errors.New("not a TrueType collection")
+	}
+	return t.readCollectionOffsets()
+}
+
+// TtcNumFonts returns the number of fonts of the TrueType collection `r`.
+func TtcNumFonts(r io.ReadSeeker) (int, error) {
+	offsets, err := collectionOffsets(r)
+	if err != nil {
+		return 0, err
+	}
+	return len(offsets), nil
+}
+
+// TtcParse returns a TtfType describing the font at index `index` of the TrueType collection `r`.
+func TtcParse(r io.ReadSeeker, index int) (TtfType, error) {
+	offsets, err := collectionOffsets(r)
+	if err != nil {
+		return TtfType{}, err
+	}
+	if index < 0 || index >= len(offsets) {
+		return TtfType{}, ErrInvalidTtcIndex
+	}
+	t := &ttfParser{f: r}
+	return t.parseAt(int64(offsets[index]))
+}
+
+// TtcExtractFont returns the font file of the font at index `index` of the TrueType collection
+// `data`, containing the tables of the font only, which can be embedded in PDF files.
+func TtcExtractFont(data []byte, index int) ([]byte, error) {
+	offsets, err := collectionOffsets(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(offsets) {
+		return nil, ErrInvalidTtcIndex
+	}
+
+	// Offset table: sfntVersion, numTables, searchRange, entrySelector, rangeShift.
+	start := int(offsets[index])
+	if start+12 > len(data) {
+		return nil, errors.New("invalid font offset")
+	}
+	numTables := int(binary.BigEndian.Uint16(data[start+4:]))
+	dirEnd := start + 12 + 16*numTables
+	if dirEnd > len(data) {
+		return nil, errors.New("invalid table directory")
+	}
+
+	var out bytes.Buffer
+	out.Write(data[start : start+12])
+	tableOffset := 12 + 16*numTables
+	var tables [][]byte
+	for i := 0; i < numTables; i++ {
+		rec := data[start+12+16*i : start+12+16*(i+1)]
+		offset := int(binary.BigEndian.Uint32(rec[8:]))
+		length := int(binary.BigEndian.Uint32(rec[12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, fmt.Errorf("invalid table %q", rec[:4])
+		}
+
+		var newRec [16]byte
+		copy(newRec[:], rec[:8]) // tag, checkSum
+		binary.BigEndian.PutUint32(newRec[8:], uint32(tableOffset))
+		binary.BigEndian.PutUint32(newRec[12:], uint32(length))
+		out.Write(newRec[:])
+
+		// Tables are 4-byte aligned.
+		table := data[offset : offset+length]
+		tables = append(tables, table)
+		tableOffset += (length + 3) &^ 3
+	}
+	for _, table := range tables {
+		out.Write(table)
+		out.Write(make([]byte, (4-len(table)%4)%4))
+	}
+	return out.Bytes(), nil
+}
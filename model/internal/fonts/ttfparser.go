@@ -107,6 +107,19 @@ type TtfType struct {
 	Chars map[rune]GID
 	// GlyphNames is a list of glyphs from the "post" section of the TrueType file.
 	GlyphNames []GlyphName
+
+	// CFF specifies whether the font is an OpenType font with PostScript (CFF) outlines rather
+	// than TrueType outlines.
+	CFF bool
+
+	// GlyphCIDs contains the CIDs of the glyphs of CID-keyed CFF fonts, indexed by GID. It is nil
+	// for other fonts, whose glyphs are selected by GID.
+	GlyphCIDs []uint16
+
+	// FsType contains the embedding licensing rights flags of the "OS/2" table. HasOS2 specifies
+	// whether the font has an "OS/2" table.
+	FsType uint16
+	HasOS2 bool
 }
 
 // MakeToUnicode returns a ToUnicode CMap based on the encoding of `ttf`.
@@ -157,6 +170,7 @@ type ttfParser struct {
 	rec              TtfType
 	f                io.ReadSeeker
 	tables           map[string]uint32
+	tableLengths     map[string]uint32
 	numberOfHMetrics uint16
 	numGlyphs        uint16
 }
@@ -201,24 +215,51 @@ func TtfParse(r io.ReadSeeker) (TtfType, error) {
 }
 
 // Parse returns a TtfType describing the TrueType font file in io.Reader `t`.f.
+// The first font of TrueType collections is parsed.
 func (t *ttfParser) Parse() (TtfType, error) {
+	version, err := t.ReadStr(4)
+	if err != nil {
+		return TtfType{}, err
+	}
+	if version == "ttcf" {
+		offsets, err := t.readCollectionOffsets()
+		if err != nil {
+			return TtfType{}, err
+		}
+		return t.parseAt(int64(offsets[0]))
+	}
+	return t.parseFont(version)
+}
 
+// parseAt parses the font whose table directory is at `offset`, e.g. a font
+// of a TrueType collection.
+func (t *ttfParser) parseAt(offset int64) (TtfType, error) {
+	if _, err := t.f.Seek(offset, io.SeekStart); err != nil {
+		return TtfType{}, err
+	}
 	version, err := t.ReadStr(4)
 	if err != nil {
 		return TtfType{}, err
 	}
+	return t.parseFont(version)
+}
+
+// parseFont parses the font whose table directory starts with `version`,
+// which has already been read.
+func (t *ttfParser) parseFont(version string) (TtfType, error) {
+	var err error
 	if version == "OTTO" {
+		// OpenType font with PostScript (CFF) outlines.
 		// See https://docs.microsoft.com/en-us/typography/opentype/spec/otff
-		return TtfType{}, fmt.Errorf("fonts based on PostScript outlines are not supported (%v)",
-			core.ErrNotSupported)
-	}
-	if version != "\x00\x01\x00\x00" && version != "true" {
+		t.rec.CFF = true
+	} else if version != "\x00\x01\x00\x00" && version != "true" {
 		// This is not an error. In the font_test.go example axes.txt we see version "true".
 		common.Log.Debug("Unrecognized TrueType file format. version=%q", version)
 	}
 	numTables := int(t.ReadUShort())
 	t.Skip(3 * 2) // searchRange, entrySelector, rangeShift
 	t.tables = make(map[string]uint32)
+	t.tableLengths = make(map[string]uint32)
 	var tag string
 	for j := 0; j < numTables; j++ {
 		tag, err = t.ReadStr(4)
@@ -227,8 +268,8 @@ func (t *ttfParser) Parse() (TtfType, error) {
 		}
 		t.Skip(4) // checkSum
 		offset := t.ReadULong()
-		t.Skip(4) // length
 		t.tables[tag] = offset
+		t.tableLengths[tag] = t.ReadULong()
 	}
 
 	common.Log.Trace(describeTables(t.tables))
@@ -302,6 +343,11 @@ func (t *ttfParser) ParseComponents() error {
 			return err
 		}
 	}
+	if _, ok := t.tables["CFF "]; ok && t.rec.CFF {
+		if err := t.ParseCFF(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -650,7 +696,9 @@ func (t *ttfParser) ParseOS2() error {
 		return err
 	}
 	version := t.ReadUShort()
-	t.Skip(4 * 2) // xAvgCharWidth, usWeightClass, usWidthClass
+	t.Skip(3 * 2) // xAvgCharWidth, usWeightClass, usWidthClass
+	t.rec.FsType = t.ReadUShort()
+	t.rec.HasOS2 = true
 	t.Skip(11*2 + 10 + 4*4 + 4)
 	fsSelection := t.ReadUShort()
 	t.rec.Bold = (fsSelection & 32) != 0
@@ -0,0 +1,403 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// LinkType represents the type of the target of a link annotation.
+type LinkType int
+
+// Link annotation target types.
+const (
+	LinkTypeUnknown LinkType = iota
+	LinkTypeURI              // Link to an external URI (URI action).
+	LinkTypeGoTo             // Link to a destination in the current document (Dest entry or GoTo action).
+	LinkTypeGoToR            // Link to a destination in another document (GoToR action).
+	LinkTypeNamed            // Link executing a predefined viewer action (Named action).
+)
+
+// String returns a string representation of the link type.
+func (t LinkType) String() string {
+	switch t {
+	case LinkTypeURI:
+		return "URI"
+	case LinkTypeGoTo:
+		return "GoTo"
+	case LinkTypeGoToR:
+		return "GoToR"
+	case LinkTypeNamed:
+		return "Named"
+	}
+	return "Unknown"
+}
+
+// Named actions which should be supported by all conforming readers
+// (section 12.6.4.11 "Named Actions" p. 430 PDF32000_2008).
+const (
+	NamedActionNextPage  = "NextPage"
+	NamedActionPrevPage  = "PrevPage"
+	NamedActionFirstPage = "FirstPage"
+	NamedActionLastPage  = "LastPage"
+)
+
+// NewPageDestFit returns a destination displaying the specified page with
+// its contents magnified just enough to fit the entire page in the window.
+func NewPageDestFit(page *PdfPage) OutlineDest {
+	return OutlineDest{PageObj: page.GetPageAsIndirectObject(), Mode: "Fit"}
+}
+
+// NewPageDestFitH returns a destination displaying the specified page with
+// the vertical coordinate `top` positioned at the top edge of the window and
+// the contents magnified to fit the entire width of the page in the window.
+func NewPageDestFitH(page *PdfPage, top float64) OutlineDest {
+	return OutlineDest{PageObj: page.GetPageAsIndirectObject(), Mode: "FitH", Y: top}
+}
+
+// NewPageDestXYZ returns a destination displaying the specified page with the
+// coordinates (left, top) positioned at the upper left corner of the window
+// and the contents magnified by the factor `zoom`. A zoom value of 0 keeps
+// the current magnification of the viewer.
+func NewPageDestXYZ(page *PdfPage, left, top, zoom float64) OutlineDest {
+	return OutlineDest{PageObj: page.GetPageAsIndirectObject(), Mode: "XYZ", X: left, Y: top, Zoom: zoom}
+}
+
+// newLinkAnnotation returns a new link annotation, with no visible border,
+// covering the area specified by `rect`.
+func newLinkAnnotation(rect PdfRectangle) *PdfAnnotationLink {
+	link := NewPdfAnnotationLink()
+	link.Rect = rect.ToPdfObject()
+	link.F = core.MakeInteger(4) // Print.
+
+	bs := NewBorderStyle()
+	bs.SetBorderWidth(0)
+	link.BS = bs.ToPdfObject()
+	return link
+}
+
+// NewLinkAnnotationURI returns a new link annotation which opens the
+// specified URI when activated.
+func NewLinkAnnotationURI(rect PdfRectangle, uri string) *PdfAnnotationLink {
+	link := newLinkAnnotation(rect)
+
+	action := NewPdfActionURI()
+	action.URI = core.MakeString(uri)
+	link.SetAction(action.PdfAction)
+	return link
+}
+
+// NewLinkAnnotationGoTo returns a new link annotation which jumps to the
+// specified destination in the current document when activated. The page
+// object of the destination must be set (see NewPageDestFit, NewPageDestFitH
+// and NewPageDestXYZ).
+func NewLinkAnnotationGoTo(rect PdfRectangle, dest OutlineDest) (*PdfAnnotationLink, error) {
+	if dest.PageObj == nil {
+		return nil, errors.New("destination page object not set")
+	}
+
+	link := newLinkAnnotation(rect)
+	link.Dest = dest.ToPdfObject()
+	return link, nil
+}
+
+// NewLinkAnnotationGoToR returns a new link annotation which jumps to a
+// destination in another PDF document, specified by its file path, when
+// activated. The page of the destination is specified by its zero-based
+// index in the remote document (the Page field of `dest`). If `newWindow` is
+// true, the remote document is opened in a new window.
+func NewLinkAnnotationGoToR(rect PdfRectangle, file string, dest OutlineDest, newWindow bool) *PdfAnnotationLink {
+	link := newLinkAnnotation(rect)
+
+	// Page objects of other documents cannot be referenced.
+	dest.PageObj = nil
+
+	action := NewPdfActionGoToR()
+	action.F = NewPdfFilespec()
+	action.F.F = core.MakeString(file)
+	action.D = dest.ToPdfObject()
+	if newWindow {
+		action.NewWindow = core.MakeBool(true)
+	}
+	link.SetAction(action.PdfAction)
+	return link
+}
+
+// NewLinkAnnotationNamed returns a new link annotation which executes the
+// specified named action when activated (e.g. NamedActionNextPage).
+func NewLinkAnnotationNamed(rect PdfRectangle, name string) *PdfAnnotationLink {
+	link := newLinkAnnotation(rect)
+
+	action := NewPdfActionNamed()
+	action.N = core.MakeName(name)
+	link.SetAction(action.PdfAction)
+	return link
+}
+
+// Link represents a link annotation of a document, along with its resolved
+// target. Only the fields relevant to the type of the link are set.
+type Link struct {
+	// Annotation is the underlying link annotation.
+	Annotation *PdfAnnotationLink
+
+	// PageNum is the number of the page containing the link.
+	PageNum int
+
+	// Rect is the activation area of the link, in page coordinates.
+	Rect *PdfRectangle
+
+	Type LinkType
+
+	// URI is the target URI of URI links.
+	URI string
+
+	// Dest is the explicit destination of GoTo and GoToR links. For GoTo
+	// links, named destinations are resolved using the name dictionary of
+	// the document.
+	Dest *OutlineDest
+
+	// DestName is the name of the destination of links to named
+	// destinations.
+	DestName string
+
+	// File is the path of the target document of GoToR links.
+	File string
+
+	// Named is the name of the action of Named links.
+	Named string
+}
+
+// SetURI changes the target of the link to the specified URI.
+func (l *Link) SetURI(uri string) {
+	action := NewPdfActionURI()
+	action.URI = core.MakeString(uri)
+	l.setAction(action.PdfAction)
+
+	l.Type = LinkTypeURI
+	l.URI = uri
+}
+
+// SetDest changes the target of the link to the specified destination in the
+// current document.
+func (l *Link) SetDest(dest OutlineDest) error {
+	if dest.PageObj == nil {
+		return errors.New("destination page object not set")
+	}
+	l.setAction(nil)
+	l.Annotation.Dest = dest.ToPdfObject()
+
+	l.Type = LinkTypeGoTo
+	l.Dest = &dest
+	return nil
+}
+
+// SetNamedAction changes the target of the link to the specified named action.
+func (l *Link) SetNamedAction(name string) {
+	action := NewPdfActionNamed()
+	action.N = core.MakeName(name)
+	l.setAction(action.PdfAction)
+
+	l.Type = LinkTypeNamed
+	l.Named = name
+}
+
+// setAction replaces the action of the link, removing its destination and
+// its previous target, if any.
+func (l *Link) setAction(action *PdfAction) {
+	l.Annotation.Dest = nil
+	l.Annotation.A = nil
+	l.Annotation.SetAction(action)
+
+	l.URI, l.DestName, l.File, l.Named = "", "", "", ""
+	l.Dest = nil
+}
+
+// GetLinks returns the link annotations of all the pages of the document,
+// along with their resolved targets. The links can be rewritten using the
+// SetURI, SetDest and SetNamedAction methods. The changes are applied
+// when writing the pages of the document.
+func (r *PdfReader) GetLinks() ([]*Link, error) {
+	var links []*Link
+	for i, page := range r.PageList {
+		annotations, err := page.GetAnnotations()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, annot := range annotations {
+			linkAnnot, ok := annot.GetContext().(*PdfAnnotationLink)
+			if !ok {
+				continue
+			}
+
+			link, err := r.newLink(linkAnnot)
+			if err != nil {
+				return nil, err
+			}
+			link.PageNum = i + 1
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+// newLink returns a new link, resolving the target of the link annotation.
+func (r *PdfReader) newLink(annot *PdfAnnotationLink) (*Link, error) {
+	link := &Link{Annotation: annot}
+	if arr, ok := core.GetArray(annot.Rect); ok {
+		if rect, err := NewPdfRectangle(*arr); err == nil {
+			link.Rect = rect
+		}
+	}
+
+	// The destination of the link is used only if the link has no action.
+	if annot.Dest != nil && annot.A == nil {
+		link.Type = LinkTypeGoTo
+		link.Dest, link.DestName = r.resolveDest(annot.Dest)
+		return link, nil
+	}
+
+	action, err := annot.GetAction()
+	if err != nil || action == nil {
+		return link, err
+	}
+
+	switch t := action.GetContext().(type) {
+	case *PdfActionURI:
+		link.Type = LinkTypeURI
+		link.URI, _ = core.GetStringVal(t.URI)
+	case *PdfActionGoTo:
+		link.Type = LinkTypeGoTo
+		link.Dest, link.DestName = r.resolveDest(t.D)
+	case *PdfActionGoToR:
+		link.Type = LinkTypeGoToR
+		if t.F != nil {
+			link.File = t.F.FileName()
+		}
+		if arr, ok := core.GetArray(t.D); ok {
+			link.Dest, _ = newRemoteDest(arr)
+		} else {
+			link.DestName = destName(t.D)
+		}
+	case *PdfActionNamed:
+		link.Type = LinkTypeNamed
+		link.Named, _ = core.GetNameVal(t.N)
+	}
+	return link, nil
+}
+
+// resolveDest resolves the destination object `obj`, which can be either an
+// explicit destination (array) or a named destination (name or string).
+// Returns the destination, if found, and the destination name, for named
+// destinations.
+func (r *PdfReader) resolveDest(obj core.PdfObject) (*OutlineDest, string) {
+	name := destName(obj)
+	if name != "" {
+		obj = r.lookupNamedDest(name)
+		if obj == nil {
+			common.Log.Debug("Named destination %s not found", name)
+			return nil, name
+		}
+	}
+
+	// Named destinations can be dictionaries with the destination as D entry.
+	if dict, ok := core.GetDict(obj); ok {
+		obj = dict.Get("D")
+	}
+
+	dest, err := newOutlineDestFromPdfObject(obj, r)
+	if err != nil {
+		common.Log.Debug("ERROR: invalid destination: %v", err)
+		return nil, name
+	}
+	return dest, name
+}
+
+// lookupNamedDest returns the destination with the specified name, looking
+// in the Dests dictionary of the catalog (PDF 1.1) and in the Dests name tree
+// of the name dictionary.
+func (r *PdfReader) lookupNamedDest(name string) core.PdfObject {
+	if dests, ok := core.GetDict(r.catalog.Get("Dests")); ok {
+		if obj := dests.Get(core.PdfObjectName(name)); obj != nil {
+			return core.ResolveReference(obj)
+		}
+	}
+
+	names, ok := core.GetDict(r.catalog.Get("Names"))
+	if !ok {
+		return nil
+	}
+	return nameTreeLookup(names.Get("Dests"), name, 0)
+}
+
+// maxNameTreeDepth is the maximum depth of the name trees traversed when
+// looking up a value, which prevents infinite recursion in invalid files.
+const maxNameTreeDepth = 32
+
+// nameTreeLookup returns the value associated to `name` in the name tree with
+// the root node `node` (section 7.9.6 "Name Trees" p. 88 PDF32000_2008).
+func nameTreeLookup(node core.PdfObject, name string, depth int) core.PdfObject {
+	dict, ok := core.GetDict(node)
+	if !ok || depth > maxNameTreeDepth {
+		return nil
+	}
+
+	if names, ok := core.GetArray(dict.Get("Names")); ok {
+		for i := 0; i+1 < names.Len(); i += 2 {
+			if key, ok := core.GetStringVal(names.Get(i)); ok && key == name {
+				return core.ResolveReference(names.Get(i + 1))
+			}
+		}
+	}
+
+	kids, ok := core.GetArray(dict.Get("Kids"))
+	if !ok {
+		return nil
+	}
+	for _, kid := range kids.Elements() {
+		kidDict, ok := core.GetDict(kid)
+		if !ok {
+			continue
+		}
+		if limits, ok := core.GetArray(kidDict.Get("Limits")); ok && limits.Len() == 2 {
+			first, _ := core.GetStringVal(limits.Get(0))
+			last, _ := core.GetStringVal(limits.Get(1))
+			if name < first || name > last {
+				continue
+			}
+		}
+		if obj := nameTreeLookup(kidDict, name, depth+1); obj != nil {
+			return obj
+		}
+	}
+	return nil
+}
+
+// destName returns the name of the named destination `obj`, or an empty
+// string if `obj` is not a named destination.
+func destName(obj core.PdfObject) string {
+	switch t := core.TraceToDirectObject(obj).(type) {
+	case *core.PdfObjectName:
+		return t.String()
+	case *core.PdfObjectString:
+		return t.Str()
+	}
+	return ""
+}
+
+// newRemoteDest parses the explicit destination of a remote document, for
+// which the page is specified by its index.
+func newRemoteDest(arr *core.PdfObjectArray) (*OutlineDest, error) {
+	if _, ok := core.GetIntVal(arr.Get(0)); !ok {
+		return nil, errors.New("invalid remote destination page")
+	}
+
+	// The pages of the remote document are not available.
+	return newOutlineDestFromPdfObject(arr, &PdfReader{})
+}
@@ -0,0 +1,153 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// writeReadPages writes the pages to a new document and reads it back.
+func writeReadPages(t *testing.T, pages ...*PdfPage) *PdfReader {
+	w := NewPdfWriter()
+	for _, page := range pages {
+		require.NoError(t, w.AddPage(page))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	return r
+}
+
+func TestLinks(t *testing.T) {
+	page1 := NewPdfPage()
+	page1.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+	page2 := NewPdfPage()
+	page2.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+
+	rect := PdfRectangle{Llx: 10, Lly: 10, Urx: 100, Ury: 30}
+	page1.AddAnnotation(NewLinkAnnotationURI(rect, "https://example.com").PdfAnnotation)
+
+	gotoLink, err := NewLinkAnnotationGoTo(rect, NewPageDestXYZ(page2, 0, 792, 1.5))
+	require.NoError(t, err)
+	page1.AddAnnotation(gotoLink.PdfAnnotation)
+
+	fitLink, err := NewLinkAnnotationGoTo(rect, NewPageDestFitH(page1, 500))
+	require.NoError(t, err)
+	page2.AddAnnotation(fitLink.PdfAnnotation)
+
+	remoteDest := OutlineDest{Page: 3, Mode: "Fit"}
+	page2.AddAnnotation(NewLinkAnnotationGoToR(rect, "other.pdf", remoteDest, true).PdfAnnotation)
+	page2.AddAnnotation(NewLinkAnnotationNamed(rect, NamedActionNextPage).PdfAnnotation)
+
+	_, err = NewLinkAnnotationGoTo(rect, OutlineDest{Page: 1, Mode: "Fit"})
+	require.Error(t, err)
+
+	// Read links.
+	r := writeReadPages(t, page1, page2)
+	links, err := r.GetLinks()
+	require.NoError(t, err)
+	require.Len(t, links, 5)
+
+	require.Equal(t, LinkTypeURI, links[0].Type)
+	require.Equal(t, 1, links[0].PageNum)
+	require.Equal(t, "https://example.com", links[0].URI)
+	require.Equal(t, rect, *links[0].Rect)
+
+	require.Equal(t, LinkTypeGoTo, links[1].Type)
+	require.NotNil(t, links[1].Dest)
+	require.Equal(t, int64(1), links[1].Dest.Page)
+	require.Equal(t, "XYZ", links[1].Dest.Mode)
+	require.Equal(t, 792.0, links[1].Dest.Y)
+	require.Equal(t, 1.5, links[1].Dest.Zoom)
+
+	require.Equal(t, LinkTypeGoTo, links[2].Type)
+	require.Equal(t, 2, links[2].PageNum)
+	require.Equal(t, int64(0), links[2].Dest.Page)
+	require.Equal(t, "FitH", links[2].Dest.Mode)
+	require.Equal(t, 500.0, links[2].Dest.Y)
+
+	require.Equal(t, LinkTypeGoToR, links[3].Type)
+	require.Equal(t, "other.pdf", links[3].File)
+	require.NotNil(t, links[3].Dest)
+	require.Equal(t, int64(3), links[3].Dest.Page)
+	require.Equal(t, "Fit", links[3].Dest.Mode)
+
+	require.Equal(t, LinkTypeNamed, links[4].Type)
+	require.Equal(t, NamedActionNextPage, links[4].Named)
+
+	// Rewrite links.
+	readPage1, err := r.GetPage(1)
+	require.NoError(t, err)
+	readPage2, err := r.GetPage(2)
+	require.NoError(t, err)
+
+	links[0].SetNamedAction(NamedActionLastPage)
+	require.NoError(t, links[1].SetDest(NewPageDestFit(readPage1)))
+	links[4].SetURI("https://example.org")
+
+	r = writeReadPages(t, readPage1, readPage2)
+	links, err = r.GetLinks()
+	require.NoError(t, err)
+	require.Len(t, links, 5)
+
+	require.Equal(t, LinkTypeNamed, links[0].Type)
+	require.Equal(t, NamedActionLastPage, links[0].Named)
+	require.Equal(t, LinkTypeGoTo, links[1].Type)
+	require.Equal(t, int64(0), links[1].Dest.Page)
+	require.Equal(t, "Fit", links[1].Dest.Mode)
+	require.Equal(t, LinkTypeGoToR, links[3].Type)
+	require.Equal(t, LinkTypeURI, links[4].Type)
+	require.Equal(t, "https://example.org", links[4].URI)
+}
+
+func TestLinksNamedDest(t *testing.T) {
+	rawText := `
+1 0 obj
+<< /Type /Catalog /Pages 2 0 R /Names << /Dests 5 0 R >> >>
+endobj
+2 0 obj
+<< /Type /Pages /Kids [3 0 R] /Count 1 >>
+endobj
+3 0 obj
+<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>
+endobj
+5 0 obj
+<< /Kids [6 0 R] >>
+endobj
+6 0 obj
+<< /Limits [(a) (chapter)] /Names [(a) [3 0 R /Fit] (chapter) << /D [3 0 R /FitH 100] >>] >>
+endobj
+`
+	r := NewReaderForText(rawText)
+	require.NoError(t, r.ParseIndObjSeries())
+
+	catalog, err := r.parser.LookupByNumber(1)
+	require.NoError(t, err)
+	page, err := r.parser.LookupByNumber(3)
+	require.NoError(t, err)
+	r.catalog, _ = core.GetDict(catalog)
+	pageInd := page.(*core.PdfIndirectObject)
+	r.pageList = []*core.PdfIndirectObject{pageInd}
+	r.PageList = []*PdfPage{{primitive: pageInd}}
+
+	dest, name := r.resolveDest(core.MakeString("chapter"))
+	require.Equal(t, "chapter", name)
+	require.NotNil(t, dest)
+	require.Equal(t, "FitH", dest.Mode)
+	require.Equal(t, 100.0, dest.Y)
+
+	dest, name = r.resolveDest(core.MakeString("missing"))
+	require.Equal(t, "missing", name)
+	require.Nil(t, dest)
+}
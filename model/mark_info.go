@@ -0,0 +1,120 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// PdfMarkInfo represents the mark information dictionary of a document, which
+// specifies its use of the tagged PDF conventions (MarkInfo entry of the
+// catalog, section 14.7.1 p. 552 PDF32000_2008).
+type PdfMarkInfo struct {
+	// Marked specifies whether the document conforms to the tagged PDF
+	// conventions.
+	Marked bool
+
+	// UserProperties specifies whether the structure elements of the
+	// document contain user properties attributes.
+	UserProperties bool
+
+	// Suspects specifies whether the document contains tag suspects, i.e.
+	// content whose tagging may be incorrect.
+	Suspects bool
+}
+
+// newPdfMarkInfoFromPdfObject returns the mark information dictionary `obj`,
+// or nil if `obj` is not a dictionary.
+func newPdfMarkInfoFromPdfObject(obj core.PdfObject) *PdfMarkInfo {
+	dict, ok := core.GetDict(obj)
+	if !ok {
+		return nil
+	}
+	mi := &PdfMarkInfo{}
+	mi.Marked, _ = core.GetBoolVal(dict.Get("Marked"))
+	mi.UserProperties, _ = core.GetBoolVal(dict.Get("UserProperties"))
+	mi.Suspects, _ = core.GetBoolVal(dict.Get("Suspects"))
+	return mi
+}
+
+// ToPdfObject returns the PDF representation of the mark information
+// dictionary. Only the flags which are set are written.
+func (mi *PdfMarkInfo) ToPdfObject() core.PdfObject {
+	d := core.MakeDict()
+	d.Set("Marked", core.MakeBool(mi.Marked))
+	if mi.UserProperties {
+		d.Set("UserProperties", core.MakeBool(true))
+	}
+	if mi.Suspects {
+		d.Set("Suspects", core.MakeBool(true))
+	}
+	return d
+}
+
+// GetMarkInfo returns the mark information dictionary of the document, or nil
+// if not specified.
+func (r *PdfReader) GetMarkInfo() *PdfMarkInfo {
+	return newPdfMarkInfoFromPdfObject(r.catalog.Get("MarkInfo"))
+}
+
+// IsTagged returns true if the document declares that it conforms to the
+// tagged PDF conventions (Marked entry of the mark information dictionary).
+func (r *PdfReader) IsTagged() bool {
+	mi := r.GetMarkInfo()
+	return mi != nil && mi.Marked
+}
+
+// GetLanguage returns the natural language of the text of the document (Lang
+// entry of the catalog), as a language tag such as "en-US". Returns an empty
+// string if not specified.
+func (r *PdfReader) GetLanguage() string {
+	if lang, ok := core.GetString(r.catalog.Get("Lang")); ok {
+		return lang.Decoded()
+	}
+	return ""
+}
+
+// SetMarkInfo sets the mark information dictionary of the output document.
+// The dictionary is removed if `mi` is nil. Note that SetStructTreeRoot marks
+// the document as tagged.
+func (w *PdfWriter) SetMarkInfo(mi *PdfMarkInfo) {
+	if mi == nil {
+		w.catalog.Remove("MarkInfo")
+		return
+	}
+	w.catalog.Set("MarkInfo", mi.ToPdfObject())
+}
+
+// SetLanguage sets the natural language of the text of the output document,
+// as a language tag such as "en-US" (RFC 3066). The entry is removed if `lang`
+// is empty.
+func (w *PdfWriter) SetLanguage(lang string) {
+	if lang == "" {
+		w.catalog.Remove("Lang")
+		return
+	}
+	w.catalog.Set("Lang", makeTextString(lang))
+}
+
+// GetStructParents returns the key of the page in the parent tree of the
+// structure tree of the document (StructParents entry), which associates the
+// marked content sequences of the page with their structure elements.
+// Returns false if the page does not have a key.
+func (p *PdfPage) GetStructParents() (int, bool) {
+	return core.GetIntVal(p.StructParents)
+}
+
+// SetStructParents sets the key of the page in the parent tree of the
+// structure tree of the document. Negative keys remove the entry. The keys of
+// the pages with marked content are set by PdfWriter.SetStructTreeRoot, this
+// is only needed for documents whose parent tree is built separately.
+func (p *PdfPage) SetStructParents(key int) {
+	if key < 0 {
+		p.StructParents = nil
+		return
+	}
+	p.StructParents = core.MakeInteger(int64(key))
+}
@@ -0,0 +1,59 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkInfoAndLanguage(t *testing.T) {
+	w := NewPdfWriter()
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+	page.SetStructParents(3)
+	require.NoError(t, w.AddPage(page))
+
+	w.SetLanguage("fr-CA")
+	w.SetMarkInfo(&PdfMarkInfo{Suspects: true})
+	// The structure tree marks the document and keeps the other flags.
+	require.NoError(t, w.SetStructTreeRoot(NewStructTreeRoot()))
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	require.Equal(t, "fr-CA", r.GetLanguage())
+	require.Equal(t, &PdfMarkInfo{Marked: true, Suspects: true}, r.GetMarkInfo())
+	require.True(t, r.IsTagged())
+	key, ok := r.PageList[0].GetStructParents()
+	require.True(t, ok)
+	require.Equal(t, 3, key)
+
+	// Removal.
+	w = NewPdfWriter()
+	page = NewPdfPage()
+	page.SetStructParents(1)
+	page.SetStructParents(-1)
+	require.NoError(t, w.AddPage(page))
+	w.SetLanguage("en")
+	w.SetLanguage("")
+	w.SetMarkInfo(&PdfMarkInfo{Marked: true})
+	w.SetMarkInfo(nil)
+
+	buf.Reset()
+	require.NoError(t, w.Write(&buf))
+	r, err = NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Empty(t, r.GetLanguage())
+	require.Nil(t, r.GetMarkInfo())
+	require.False(t, r.IsTagged())
+	_, ok = r.PageList[0].GetStructParents()
+	require.False(t, ok)
+}
@@ -0,0 +1,591 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"math"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// NumberFormatStyle represents the way fractional values of a number format
+// are displayed.
+type NumberFormatStyle string
+
+// Number format styles.
+const (
+	NumberFormatDecimal  NumberFormatStyle = "D" // Decimal fractions (default).
+	NumberFormatFraction NumberFormatStyle = "F" // Fractions.
+	NumberFormatRound    NumberFormatStyle = "R" // Rounded to whole units.
+	NumberFormatTruncate NumberFormatStyle = "T" // Truncated to whole units.
+)
+
+// PdfNumberFormat represents a number format dictionary, which specifies a
+// unit of a measurement and the conversion from the previous unit of the
+// number format array containing it (Table 263 - p. 611 PDF32000_2008).
+type PdfNumberFormat struct {
+	// Unit is the label of the unit, e.g. "mi", "ft" or "in".
+	Unit string
+
+	// Factor is the conversion factor from the previous unit of the number
+	// format array, or from default user space units for the first number
+	// format of an array.
+	Factor float64
+
+	// Style is the way fractional values are displayed. Defaults to
+	// NumberFormatDecimal.
+	Style NumberFormatStyle
+
+	// Precision is the precision of decimal values (e.g. 100 for 2 decimal
+	// places) or the denominator of fractional values. Defaults to 100 for
+	// decimal values and 16 for fractional values.
+	Precision int
+}
+
+// newPdfNumberFormatFromPdfObject loads a number format from the specified
+// number format dictionary.
+func newPdfNumberFormatFromPdfObject(obj core.PdfObject) (*PdfNumberFormat, error) {
+	d, ok := core.GetDict(obj)
+	if !ok {
+		return nil, core.ErrTypeError
+	}
+
+	format := &PdfNumberFormat{}
+	if unit, ok := core.GetString(d.Get("U")); ok {
+		format.Unit = unit.Decoded()
+	}
+	factor, err := core.GetNumberAsFloat(core.TraceToDirectObject(d.Get("C")))
+	if err != nil {
+		return nil, errors.New("number format conversion factor must be a number")
+	}
+	format.Factor = factor
+	if style, ok := core.GetNameVal(d.Get("F")); ok {
+		format.Style = NumberFormatStyle(style)
+	}
+	if precision, ok := core.GetIntVal(d.Get("D")); ok {
+		format.Precision = precision
+	}
+	return format, nil
+}
+
+// ToPdfObject returns the number format dictionary.
+func (f *PdfNumberFormat) ToPdfObject() core.PdfObject {
+	d := core.MakeDict()
+	d.Set("Type", core.MakeName("NumberFormat"))
+	d.Set("U", makeTextString(f.Unit))
+	d.Set("C", core.MakeFloat(f.Factor))
+	if f.Style != "" {
+		d.Set("F", core.MakeName(string(f.Style)))
+	}
+	if f.Precision > 0 {
+		d.Set("D", core.MakeInteger(int64(f.Precision)))
+	}
+	return d
+}
+
+// loadNumberFormats loads the number format array `obj`.
+func loadNumberFormats(obj core.PdfObject) ([]*PdfNumberFormat, error) {
+	arr, ok := core.GetArray(obj)
+	if !ok {
+		return nil, nil
+	}
+
+	formats := make([]*PdfNumberFormat, 0, arr.Len())
+	for _, elem := range arr.Elements() {
+		format, err := newPdfNumberFormatFromPdfObject(elem)
+		if err != nil {
+			return nil, err
+		}
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+// numberFormatsToPdfObject returns the number format array of `formats`, or
+// nil if `formats` is empty.
+func numberFormatsToPdfObject(formats []*PdfNumberFormat) core.PdfObject {
+	if len(formats) == 0 {
+		return nil
+	}
+	arr := core.MakeArray()
+	for _, format := range formats {
+		arr.Append(format.ToPdfObject())
+	}
+	return arr
+}
+
+// firstFactor returns the conversion factor of the first number format of
+// `formats`, or `defaultFactor` if `formats` is empty.
+func firstFactor(formats []*PdfNumberFormat, defaultFactor float64) float64 {
+	if len(formats) == 0 {
+		return defaultFactor
+	}
+	return formats[0].Factor
+}
+
+// PdfMeasure represents a measure dictionary, which specifies a coordinate
+// system for measurements (section 12.9 "Measurement Properties" p. 609
+// PDF32000_2008). Implemented by PdfMeasureRectilinear and
+// PdfMeasureGeospatial.
+type PdfMeasure interface {
+	ToPdfObject() core.PdfObject
+}
+
+// NewPdfMeasureFromPdfObject loads a measure from the specified measure
+// dictionary.
+func NewPdfMeasureFromPdfObject(obj core.PdfObject) (PdfMeasure, error) {
+	d, ok := core.GetDict(obj)
+	if !ok {
+		return nil, core.ErrTypeError
+	}
+
+	subtype, _ := core.GetNameVal(d.Get("Subtype"))
+	switch subtype {
+	case "", "RL":
+		return newPdfMeasureRectilinearFromDict(d)
+	case "GEO":
+		return newPdfMeasureGeospatialFromDict(d)
+	}
+	return nil, core.ErrNotSupported
+}
+
+// PdfMeasureRectilinear represents a rectilinear measure dictionary, which
+// specifies the scale of a drawing and the units of its measurements
+// (Table 262 - p. 610 PDF32000_2008).
+type PdfMeasureRectilinear struct {
+	// Ratio is a text description of the scale, e.g. "1in = 0.1mi".
+	Ratio string
+
+	// X contains the number formats of the measurements along the x axis.
+	// The conversion factor of the first format converts default user space
+	// units to its unit.
+	X []*PdfNumberFormat
+
+	// Y contains the number formats of the measurements along the y axis,
+	// if the scales of the axes differ. The X formats are used otherwise.
+	Y []*PdfNumberFormat
+
+	// Distance, Area, Angle and Slope contain the number formats of the
+	// distance, area, angle and slope measurements.
+	Distance []*PdfNumberFormat
+	Area     []*PdfNumberFormat
+	Angle    []*PdfNumberFormat
+	Slope    []*PdfNumberFormat
+
+	// Origin is the origin of the measurement coordinate system, in default
+	// user space units. Defaults to the origin of the page.
+	Origin [2]float64
+
+	// CYX is the factor converting the units of the y axis to the units of
+	// the x axis, used if Y is set.
+	CYX float64
+}
+
+// NewPdfMeasureRectilinear returns a new rectilinear measure converting
+// default user space units to `unit`, with `factor` units per default user
+// space unit.
+func NewPdfMeasureRectilinear(ratio, unit string, factor float64) *PdfMeasureRectilinear {
+	return &PdfMeasureRectilinear{
+		Ratio:    ratio,
+		X:        []*PdfNumberFormat{{Unit: unit, Factor: factor}},
+		Distance: []*PdfNumberFormat{{Unit: unit, Factor: 1}},
+		Area:     []*PdfNumberFormat{{Unit: "sq " + unit, Factor: 1}},
+	}
+}
+
+// newPdfMeasureRectilinearFromDict loads a rectilinear measure from the
+// measure dictionary `d`.
+func newPdfMeasureRectilinearFromDict(d *core.PdfObjectDictionary) (*PdfMeasureRectilinear, error) {
+	m := &PdfMeasureRectilinear{}
+	if ratio, ok := core.GetString(d.Get("R")); ok {
+		m.Ratio = ratio.Decoded()
+	}
+
+	for _, field := range []struct {
+		key     core.PdfObjectName
+		formats *[]*PdfNumberFormat
+	}{
+		{"X", &m.X}, {"Y", &m.Y}, {"D", &m.Distance}, {"A", &m.Area}, {"T", &m.Angle}, {"S", &m.Slope},
+	} {
+		formats, err := loadNumberFormats(d.Get(field.key))
+		if err != nil {
+			return nil, err
+		}
+		*field.formats = formats
+	}
+	if len(m.X) == 0 {
+		return nil, errors.New("rectilinear measure X entry missing")
+	}
+
+	if arr, ok := core.GetArray(d.Get("O")); ok {
+		origin, err := arr.ToFloat64Array()
+		if err != nil || len(origin) != 2 {
+			common.Log.Debug("ERROR: invalid measure origin: %v", arr)
+		} else {
+			m.Origin = [2]float64{origin[0], origin[1]}
+		}
+	}
+	if cyx, err := core.GetNumberAsFloat(core.TraceToDirectObject(d.Get("CYX"))); err == nil {
+		m.CYX = cyx
+	}
+	return m, nil
+}
+
+// ToPdfObject returns the measure dictionary.
+func (m *PdfMeasureRectilinear) ToPdfObject() core.PdfObject {
+	d := core.MakeDict()
+	d.Set("Type", core.MakeName("Measure"))
+	d.Set("Subtype", core.MakeName("RL"))
+	d.Set("R", makeTextString(m.Ratio))
+	d.SetIfNotNil("X", numberFormatsToPdfObject(m.X))
+	d.SetIfNotNil("Y", numberFormatsToPdfObject(m.Y))
+	d.SetIfNotNil("D", numberFormatsToPdfObject(m.Distance))
+	d.SetIfNotNil("A", numberFormatsToPdfObject(m.Area))
+	d.SetIfNotNil("T", numberFormatsToPdfObject(m.Angle))
+	d.SetIfNotNil("S", numberFormatsToPdfObject(m.Slope))
+	if m.Origin != [2]float64{} {
+		d.Set("O", core.MakeArrayFromFloats(m.Origin[:]))
+	}
+	if m.CYX != 0 {
+		d.Set("CYX", core.MakeFloat(m.CYX))
+	}
+	return d
+}
+
+// scales returns the factors converting default user space units to the
+// units of the x axis, along the x and y axes.
+func (m *PdfMeasureRectilinear) scales() (float64, float64) {
+	sx := firstFactor(m.X, 1)
+	if len(m.Y) == 0 {
+		return sx, sx
+	}
+	cyx := m.CYX
+	if cyx == 0 {
+		cyx = 1
+	}
+	return sx, m.Y[0].Factor * cyx
+}
+
+// ToMeasureUnits converts the point (`x`,`y`) from default user space units
+// to the units of the first X number format, relative to the origin of the
+// measure.
+func (m *PdfMeasureRectilinear) ToMeasureUnits(x, y float64) (float64, float64) {
+	sx, sy := m.scales()
+	return (x - m.Origin[0]) * sx, (y - m.Origin[1]) * sy
+}
+
+// MeasureDistance returns the distance between the points (`x1`,`y1`) and
+// (`x2`,`y2`) in default user space units, in the units of the first
+// Distance number format.
+func (m *PdfMeasureRectilinear) MeasureDistance(x1, y1, x2, y2 float64) float64 {
+	sx, sy := m.scales()
+	return math.Hypot((x2-x1)*sx, (y2-y1)*sy) * firstFactor(m.Distance, 1)
+}
+
+// MeasureArea returns the area of the polygon with vertices `points` in
+// default user space units, in the units of the first Area number format.
+func (m *PdfMeasureRectilinear) MeasureArea(points [][2]float64) float64 {
+	sx, sy := m.scales()
+
+	var area float64
+	for i := range points {
+		p, q := points[i], points[(i+1)%len(points)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	return math.Abs(area) / 2 * sx * sy * firstFactor(m.Area, 1)
+}
+
+// PdfMeasureGeospatial represents a geospatial measure dictionary, which maps
+// the points of a region of a page to geographic coordinates (section 12.9
+// PDF32000_2008, Adobe Supplement to ISO 32000 BaseVersion 1.7 ExtensionLevel
+// 3).
+type PdfMeasureGeospatial struct {
+	// Bounds contains the pairs of coordinates of the region of the unit
+	// square of the viewport, image or form the measure applies to. Defaults
+	// to the whole unit square.
+	Bounds []float64
+
+	// GCS is the geographic or projected coordinate system dictionary.
+	GCS core.PdfObject
+
+	// DCS is the display coordinate system dictionary. Can be nil.
+	DCS core.PdfObject
+
+	// PDU contains the preferred linear, area and angular display units.
+	PDU []string
+
+	// GPTS contains the geographic coordinates of the reference points, as
+	// pairs of latitude and longitude in degrees.
+	GPTS []float64
+
+	// LPTS contains the coordinates of the reference points in the unit
+	// square of the viewport, image or form the measure applies to. Defaults
+	// to the corners of Bounds.
+	LPTS []float64
+}
+
+// newPdfMeasureGeospatialFromDict loads a geospatial measure from the measure
+// dictionary `d`.
+func newPdfMeasureGeospatialFromDict(d *core.PdfObjectDictionary) (*PdfMeasureGeospatial, error) {
+	m := &PdfMeasureGeospatial{
+		GCS: d.Get("GCS"),
+		DCS: d.Get("DCS"),
+	}
+
+	for _, field := range []struct {
+		key core.PdfObjectName
+		val *[]float64
+	}{
+		{"Bounds", &m.Bounds}, {"GPTS", &m.GPTS}, {"LPTS", &m.LPTS},
+	} {
+		arr, ok := core.GetArray(d.Get(field.key))
+		if !ok {
+			continue
+		}
+		vals, err := arr.ToFloat64Array()
+		if err != nil {
+			return nil, err
+		}
+		*field.val = vals
+	}
+	if len(m.GPTS) == 0 {
+		return nil, errors.New("geospatial measure GPTS entry missing")
+	}
+
+	if arr, ok := core.GetArray(d.Get("PDU")); ok {
+		for _, elem := range arr.Elements() {
+			if name, ok := core.GetNameVal(elem); ok {
+				m.PDU = append(m.PDU, name)
+			}
+		}
+	}
+	return m, nil
+}
+
+// ToPdfObject returns the measure dictionary.
+func (m *PdfMeasureGeospatial) ToPdfObject() core.PdfObject {
+	d := core.MakeDict()
+	d.Set("Type", core.MakeName("Measure"))
+	d.Set("Subtype", core.MakeName("GEO"))
+	if len(m.Bounds) > 0 {
+		d.Set("Bounds", core.MakeArrayFromFloats(m.Bounds))
+	}
+	d.SetIfNotNil("GCS", m.GCS)
+	d.SetIfNotNil("DCS", m.DCS)
+	if len(m.PDU) > 0 {
+		arr := core.MakeArray()
+		for _, unit := range m.PDU {
+			arr.Append(core.MakeName(unit))
+		}
+		d.Set("PDU", arr)
+	}
+	d.Set("GPTS", core.MakeArrayFromFloats(m.GPTS))
+	if len(m.LPTS) > 0 {
+		d.Set("LPTS", core.MakeArrayFromFloats(m.LPTS))
+	}
+	return d
+}
+
+// localPoints returns the local reference points of the measure.
+func (m *PdfMeasureGeospatial) localPoints() []float64 {
+	if len(m.LPTS) > 0 {
+		return m.LPTS
+	}
+	if len(m.Bounds) > 0 {
+		return m.Bounds
+	}
+	return []float64{0, 0, 0, 1, 1, 1, 1, 0}
+}
+
+// ToGeographic converts the point (`u`,`v`) of the unit square of the
+// viewport, image or form the measure applies to, to its latitude and
+// longitude. The conversion is the affine transform fitting the reference
+// points best, in the least squares sense.
+func (m *PdfMeasureGeospatial) ToGeographic(u, v float64) (lat, lon float64, err error) {
+	lpts := m.localPoints()
+	n := len(m.GPTS) / 2
+	if len(lpts)/2 < n {
+		n = len(lpts) / 2
+	}
+	if n < 3 {
+		return 0, 0, errors.New("at least 3 reference points required")
+	}
+
+	// Normal equations of the least squares fits of lat = a*u + b*v + c and
+	// lon = d*u + e*v + f.
+	var ata [3][3]float64
+	var atLat, atLon [3]float64
+	for i := 0; i < n; i++ {
+		row := [3]float64{lpts[2*i], lpts[2*i+1], 1}
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				ata[j][k] += row[j] * row[k]
+			}
+			atLat[j] += row[j] * m.GPTS[2*i]
+			atLon[j] += row[j] * m.GPTS[2*i+1]
+		}
+	}
+	latCoeffs, ok := solve3(ata, atLat)
+	if !ok {
+		return 0, 0, errors.New("reference points are collinear")
+	}
+	lonCoeffs, _ := solve3(ata, atLon)
+
+	lat = latCoeffs[0]*u + latCoeffs[1]*v + latCoeffs[2]
+	lon = lonCoeffs[0]*u + lonCoeffs[1]*v + lonCoeffs[2]
+	return lat, lon, nil
+}
+
+// solve3 solves the linear system `a` x = `b` with Cramer's rule, returning
+// false if `a` is singular.
+func solve3(a [3][3]float64, b [3]float64) ([3]float64, bool) {
+	det := func(m [3][3]float64) float64 {
+		return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+			m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+			m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	}
+
+	d := det(a)
+	if math.Abs(d) < 1e-12 {
+		return [3]float64{}, false
+	}
+	var x [3]float64
+	for i := 0; i < 3; i++ {
+		m := a
+		for j := 0; j < 3; j++ {
+			m[j][i] = b[j]
+		}
+		x[i] = det(m) / d
+	}
+	return x, true
+}
+
+// PdfViewport represents a viewport dictionary, which specifies a region of
+// a page with its own measurement coordinate system (section 12.9.2 p. 609
+// PDF32000_2008).
+type PdfViewport struct {
+	// BBox is the region of the page, in default user space units.
+	BBox PdfRectangle
+
+	// Name is the name of the viewport. Can be empty.
+	Name string
+
+	// Measure is the measure of the viewport. Can be nil.
+	Measure PdfMeasure
+}
+
+// newPdfViewportFromPdfObject loads a viewport from the specified viewport
+// dictionary.
+func newPdfViewportFromPdfObject(obj core.PdfObject) (*PdfViewport, error) {
+	d, ok := core.GetDict(obj)
+	if !ok {
+		return nil, core.ErrTypeError
+	}
+
+	arr, ok := core.GetArray(d.Get("BBox"))
+	if !ok {
+		return nil, errors.New("viewport BBox entry missing")
+	}
+	bbox, err := NewPdfRectangle(*arr)
+	if err != nil {
+		return nil, err
+	}
+
+	vp := &PdfViewport{BBox: *bbox.Normalized()}
+	if name, ok := core.GetString(d.Get("Name")); ok {
+		vp.Name = name.Decoded()
+	}
+	if obj := d.Get("Measure"); obj != nil {
+		measure, err := NewPdfMeasureFromPdfObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		vp.Measure = measure
+	}
+	return vp, nil
+}
+
+// ToPdfObject returns the viewport dictionary.
+func (vp *PdfViewport) ToPdfObject() core.PdfObject {
+	d := core.MakeDict()
+	d.Set("Type", core.MakeName("Viewport"))
+	d.Set("BBox", vp.BBox.ToPdfObject())
+	if vp.Name != "" {
+		d.Set("Name", makeTextString(vp.Name))
+	}
+	if vp.Measure != nil {
+		d.Set("Measure", vp.Measure.ToPdfObject())
+	}
+	return d
+}
+
+// ToGeographic converts the point (`x`,`y`) of the page, in default user
+// space units, to its latitude and longitude, using the geospatial measure of
+// the viewport.
+func (vp *PdfViewport) ToGeographic(x, y float64) (lat, lon float64, err error) {
+	geo, ok := vp.Measure.(*PdfMeasureGeospatial)
+	if !ok {
+		return 0, 0, errors.New("viewport does not have a geospatial measure")
+	}
+	if vp.BBox.Width() == 0 || vp.BBox.Height() == 0 {
+		return 0, 0, errors.New("empty viewport")
+	}
+	u := (x - vp.BBox.Llx) / vp.BBox.Width()
+	v := (y - vp.BBox.Lly) / vp.BBox.Height()
+	return geo.ToGeographic(u, v)
+}
+
+// GetViewports returns the viewports of the page (VP entry).
+func (p *PdfPage) GetViewports() ([]*PdfViewport, error) {
+	arr, ok := core.GetArray(p.VP)
+	if !ok {
+		return nil, nil
+	}
+
+	viewports := make([]*PdfViewport, 0, arr.Len())
+	for _, obj := range arr.Elements() {
+		vp, err := newPdfViewportFromPdfObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		viewports = append(viewports, vp)
+	}
+	return viewports, nil
+}
+
+// SetViewports sets the viewports of the page. The viewports are removed if
+// `viewports` is empty.
+func (p *PdfPage) SetViewports(viewports []*PdfViewport) {
+	if len(viewports) == 0 {
+		p.VP = nil
+		return
+	}
+	arr := core.MakeArray()
+	for _, vp := range viewports {
+		arr.Append(vp.ToPdfObject())
+	}
+	p.VP = arr
+}
+
+// GetViewportAt returns the viewport of the page containing the point
+// (`x`,`y`) in default user space units, or nil if the point is not in a
+// viewport. The last viewport containing the point is used if the viewports
+// overlap, as specified in section 12.9.2.
+func (p *PdfPage) GetViewportAt(x, y float64) (*PdfViewport, error) {
+	viewports, err := p.GetViewports()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(viewports) - 1; i >= 0; i-- {
+		bbox := viewports[i].BBox
+		if x >= bbox.Llx && x <= bbox.Urx && y >= bbox.Lly && y <= bbox.Ury {
+			return viewports[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,89 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestMeasureViewports(t *testing.T) {
+	// 1 inch (72 points) represents 10 ft.
+	scale := NewPdfMeasureRectilinear("1 in = 10 ft", "ft", 10.0/72)
+	scale.X = append(scale.X, &PdfNumberFormat{Unit: "in", Factor: 12, Precision: 8, Style: NumberFormatFraction})
+	scale.Origin = [2]float64{36, 36}
+
+	gcs := core.MakeDict()
+	gcs.Set("Type", core.MakeName("GEOGCS"))
+	gcs.Set("EPSG", core.MakeInteger(4326))
+	geo := &PdfMeasureGeospatial{
+		GCS:  gcs,
+		PDU:  []string{"KM", "SQKM", "DEG"},
+		GPTS: []float64{40, -75, 41, -75, 41, -74, 40, -74},
+		LPTS: []float64{0, 0, 0, 1, 1, 1, 1, 0},
+	}
+
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+	page.SetViewports([]*PdfViewport{
+		{BBox: PdfRectangle{Urx: 612, Ury: 792}, Name: "Plan", Measure: scale},
+		{BBox: PdfRectangle{Llx: 100, Lly: 100, Urx: 300, Ury: 300}, Name: "Map", Measure: geo},
+	})
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	readPage, err := r.GetPage(1)
+	require.NoError(t, err)
+	viewports, err := readPage.GetViewports()
+	require.NoError(t, err)
+	require.Len(t, viewports, 2)
+	require.Equal(t, "Plan", viewports[0].Name)
+	require.Equal(t, scale, viewports[0].Measure)
+	require.Equal(t, "Map", viewports[1].Name)
+	readGeo, ok := viewports[1].Measure.(*PdfMeasureGeospatial)
+	require.True(t, ok)
+	require.Equal(t, geo.PDU, readGeo.PDU)
+	readGCS, ok := core.GetDict(readGeo.GCS)
+	require.True(t, ok)
+	epsg, _ := core.GetIntVal(readGCS.Get("EPSG"))
+	require.Equal(t, 4326, epsg)
+
+	// Rectilinear measurements.
+	vp, err := readPage.GetViewportAt(400, 500)
+	require.NoError(t, err)
+	require.Equal(t, "Plan", vp.Name)
+	rl, ok := vp.Measure.(*PdfMeasureRectilinear)
+	require.True(t, ok)
+	x, y := rl.ToMeasureUnits(108, 180)
+	require.InDelta(t, 10, x, 1e-9)
+	require.InDelta(t, 20, y, 1e-9)
+	require.InDelta(t, 50, rl.MeasureDistance(0, 0, 216, 288), 1e-9)
+	require.InDelta(t, 200, rl.MeasureArea([][2]float64{{0, 0}, {72, 0}, {72, 144}, {0, 144}}), 1e-9)
+
+	// Geospatial coordinates.
+	vp, err = readPage.GetViewportAt(150, 250)
+	require.NoError(t, err)
+	require.Equal(t, "Map", vp.Name)
+	lat, lon, err := vp.ToGeographic(150, 250)
+	require.NoError(t, err)
+	require.InDelta(t, 40.75, lat, 1e-9)
+	require.InDelta(t, -74.75, lon, 1e-9)
+	_, _, err = viewports[0].ToGeographic(0, 0)
+	require.Error(t, err)
+
+	vp, err = readPage.GetViewportAt(700, 0)
+	require.NoError(t, err)
+	require.Nil(t, vp)
+}
@@ -6,6 +6,8 @@
 package optimize
 
 import (
+	"context"
+
 	"github.com/unidoc/unipdf/v3/core"
 	"github.com/unidoc/unipdf/v3/model"
 )
@@ -32,3 +34,24 @@ func (c *Chain) Optimize(objects []core.PdfObject) (optimizedObjects []core.PdfO
 	}
 	return optimizedObjects, nil
 }
+
+// OptimizeContext optimizes PDF objects as Optimize, checking `ctx` before each optimizer of the
+// chain. Optimizers implementing model.ContextOptimizer are cancelled too.
+// It implements interface model.ContextOptimizer.
+func (c *Chain) OptimizeContext(ctx context.Context, objects []core.PdfObject) (optimizedObjects []core.PdfObject, err error) {
+	optimizedObjects = objects
+	for _, optimizer := range c.optimizers {
+		if err := ctx.Err(); err != nil {
+			return optimizedObjects, err
+		}
+		if ctxOptimizer, ok := optimizer.(model.ContextOptimizer); ok {
+			optimizedObjects, err = ctxOptimizer.OptimizeContext(ctx, optimizedObjects)
+		} else {
+			optimizedObjects, err = optimizer.Optimize(optimizedObjects)
+		}
+		if err != nil {
+			return optimizedObjects, err
+		}
+	}
+	return optimizedObjects, nil
+}
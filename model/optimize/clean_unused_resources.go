@@ -0,0 +1,383 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package optimize
+
+import (
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// prunableResourceCategories are the resource categories whose entries are
+// removed when unused by the content streams.
+var prunableResourceCategories = []core.PdfObjectName{
+	"Font", "XObject", "ExtGState", "ColorSpace", "Pattern", "Shading", "Properties",
+}
+
+// CleanUnusedResources removes the entries of the resource dictionaries of
+// pages, forms and tiling patterns which are not referenced by their content
+// streams, e.g. the fonts and images left behind by editing.
+// Resource dictionaries shared with objects whose content is not analyzed,
+// such as annotation appearances or the interactive form default resources,
+// are kept unchanged, as are resources of content streams that cannot be
+// parsed. The objects no longer referenced can then be dropped by the writer,
+// see model.PdfWriter.SetRemoveUnusedObjects.
+// It implements interface model.Optimizer.
+type CleanUnusedResources struct {
+}
+
+// resourceUsage holds the names used by content streams, per resource
+// category.
+type resourceUsage map[core.PdfObjectName]map[core.PdfObjectName]struct{}
+
+func (u resourceUsage) add(category, name core.PdfObjectName) {
+	names, ok := u[category]
+	if !ok {
+		names = make(map[core.PdfObjectName]struct{})
+		u[category] = names
+	}
+	names[name] = struct{}{}
+}
+
+// dictionaryOwner is a dictionary referencing another dictionary by key.
+type dictionaryOwner struct {
+	dict *core.PdfObjectDictionary
+	key  core.PdfObjectName
+}
+
+// resourceCollector collects the resource names used by the content streams
+// of a document.
+type resourceCollector struct {
+	// usage holds the names used per resource dictionary.
+	usage map[*core.PdfObjectDictionary]resourceUsage
+	// keep holds the resource dictionaries which must not be pruned.
+	keep map[*core.PdfObjectDictionary]struct{}
+	// analyzed holds the objects whose resources have been analyzed: page
+	// tree nodes, forms and patterns.
+	analyzed map[*core.PdfObjectDictionary]struct{}
+	// processing holds the content streams being processed to avoid infinite
+	// recursion.
+	processing map[*core.PdfObjectStream]struct{}
+}
+
+// Optimize optimizes PDF objects to decrease PDF size.
+func (c *CleanUnusedResources) Optimize(objects []core.PdfObject) (optimizedObjects []core.PdfObject, err error) {
+	catalog := findCatalog(objects)
+	if catalog == nil {
+		return objects, nil
+	}
+
+	collector := &resourceCollector{
+		usage:      make(map[*core.PdfObjectDictionary]resourceUsage),
+		keep:       make(map[*core.PdfObjectDictionary]struct{}),
+		analyzed:   make(map[*core.PdfObjectDictionary]struct{}),
+		processing: make(map[*core.PdfObjectStream]struct{}),
+	}
+	walkPages(catalog.Get("Pages"), collector.analyzed, collector.collectPage)
+
+	owners := findDictionaryOwners(objects)
+	isPrunable := func(resources *core.PdfObjectDictionary) bool {
+		if _, ok := collector.keep[resources]; ok {
+			return false
+		}
+		if _, ok := collector.usage[resources]; !ok {
+			return false
+		}
+		for _, owner := range owners[resources] {
+			if _, ok := collector.analyzed[owner.dict]; !ok || owner.key != "Resources" {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, category := range prunableResourceCategories {
+		// The category dictionaries may be shared by several resource
+		// dictionaries, in which case the names used by all of them are
+		// kept.
+		used := make(map[*core.PdfObjectDictionary]map[core.PdfObjectName]struct{})
+		prunable := make(map[*core.PdfObjectDictionary]bool)
+		for resources := range collector.usage {
+			catDict, ok := core.GetDict(resources.Get(category))
+			if !ok {
+				continue
+			}
+			if _, ok := prunable[catDict]; !ok {
+				prunable[catDict] = true
+				for _, owner := range owners[catDict] {
+					if owner.key != category || !isPrunable(owner.dict) {
+						prunable[catDict] = false
+						break
+					}
+				}
+				used[catDict] = make(map[core.PdfObjectName]struct{})
+			}
+			for name := range collector.usage[resources][category] {
+				used[catDict][name] = struct{}{}
+			}
+		}
+
+		for catDict, ok := range prunable {
+			if !ok {
+				continue
+			}
+			// Copy the keys as they are modified by the removals.
+			names := append([]core.PdfObjectName{}, catDict.Keys()...)
+			for _, name := range names {
+				if _, ok := used[catDict][name]; !ok {
+					common.Log.Trace("Removing unused %s resource %s", category, name)
+					catDict.Remove(name)
+				}
+			}
+		}
+		// Remove the emptied categories.
+		for resources := range collector.usage {
+			catDict, ok := core.GetDict(resources.Get(category))
+			if ok && prunable[catDict] && len(catDict.Keys()) == 0 {
+				resources.Remove(category)
+			}
+		}
+	}
+	return objects, nil
+}
+
+// collectPage collects the resources used by the page `node`, with resources
+// `resources`.
+func (c *resourceCollector) collectPage(node, resources *core.PdfObjectDictionary) {
+	if resources == nil {
+		return
+	}
+
+	content, err := decodePageContents(node)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to decode page contents: %v", err)
+		c.keep[resources] = struct{}{}
+		return
+	}
+	c.collectContent(string(content), resources)
+}
+
+// collectContent collects the names used by `content` from `resources`.
+func (c *resourceCollector) collectContent(content string, resources *core.PdfObjectDictionary) {
+	usage, ok := c.usage[resources]
+	if !ok {
+		usage = make(resourceUsage)
+		c.usage[resources] = usage
+	}
+
+	ops, err := contentstream.NewContentStreamParser(content).Parse()
+	if err != nil {
+		common.Log.Debug("ERROR: unable to parse content stream: %v", err)
+		c.keep[resources] = struct{}{}
+		return
+	}
+
+	lastName := func(params []core.PdfObject) (core.PdfObjectName, bool) {
+		if len(params) == 0 {
+			return "", false
+		}
+		name, ok := core.GetName(params[len(params)-1])
+		if !ok {
+			return "", false
+		}
+		return *name, true
+	}
+
+	for _, op := range *ops {
+		switch op.Operand {
+		case "Tf":
+			if len(op.Params) != 2 {
+				continue
+			}
+			name, ok := core.GetName(op.Params[0])
+			if !ok {
+				continue
+			}
+			usage.add("Font", *name)
+			c.collectFont(resources, *name)
+		case "Do":
+			name, ok := lastName(op.Params)
+			if !ok {
+				continue
+			}
+			usage.add("XObject", name)
+			c.collectXObject(resources, name)
+		case "gs":
+			if name, ok := lastName(op.Params); ok {
+				usage.add("ExtGState", name)
+			}
+		case "cs", "CS":
+			if name, ok := lastName(op.Params); ok {
+				usage.add("ColorSpace", name)
+			}
+		case "scn", "SCN":
+			if name, ok := lastName(op.Params); ok {
+				usage.add("Pattern", name)
+				c.collectPattern(resources, name)
+			}
+		case "sh":
+			if name, ok := lastName(op.Params); ok {
+				usage.add("Shading", name)
+			}
+		case "BDC", "DP":
+			if name, ok := lastName(op.Params); ok && len(op.Params) == 2 {
+				usage.add("Properties", name)
+			}
+		case "BI":
+			if len(op.Params) != 1 {
+				continue
+			}
+			img, ok := op.Params[0].(*contentstream.ContentStreamInlineImage)
+			if !ok {
+				continue
+			}
+			if name, ok := core.GetName(img.ColorSpace); ok {
+				usage.add("ColorSpace", *name)
+			}
+		}
+	}
+}
+
+// collectXObject collects the resources used by the form named `name` in
+// `resources`.
+func (c *resourceCollector) collectXObject(resources *core.PdfObjectDictionary, name core.PdfObjectName) {
+	xobjects, ok := core.GetDict(resources.Get("XObject"))
+	if !ok {
+		return
+	}
+	stream, ok := core.GetStream(xobjects.Get(name))
+	if !ok {
+		return
+	}
+	if subtype, _ := core.GetNameVal(stream.Get("Subtype")); subtype != "Form" {
+		return
+	}
+	c.collectStream(stream, resources)
+}
+
+// collectPattern collects the resources used by the tiling pattern named
+// `name` in `resources`.
+func (c *resourceCollector) collectPattern(resources *core.PdfObjectDictionary, name core.PdfObjectName) {
+	patterns, ok := core.GetDict(resources.Get("Pattern"))
+	if !ok {
+		return
+	}
+	if stream, ok := core.GetStream(patterns.Get(name)); ok {
+		c.collectStream(stream, resources)
+	}
+}
+
+// collectFont marks `resources` as not prunable if the font named `name` is
+// a Type3 font without resources, as its glyph procedures then use the
+// resources of the content stream.
+func (c *resourceCollector) collectFont(resources *core.PdfObjectDictionary, name core.PdfObjectName) {
+	fonts, ok := core.GetDict(resources.Get("Font"))
+	if !ok {
+		return
+	}
+	font, ok := core.GetDict(fonts.Get(name))
+	if !ok {
+		return
+	}
+	if subtype, _ := core.GetNameVal(font.Get("Subtype")); subtype == "Type3" && font.Get("Resources") == nil {
+		c.keep[resources] = struct{}{}
+	}
+}
+
+// collectStream collects the resources used by the content of the form or
+// pattern `stream` drawn with resources `parentResources`.
+func (c *resourceCollector) collectStream(stream *core.PdfObjectStream, parentResources *core.PdfObjectDictionary) {
+	if _, ok := c.processing[stream]; ok {
+		return
+	}
+	resources := parentResources
+	res, hasResources := core.GetDict(stream.Get("Resources"))
+	if hasResources {
+		if _, ok := c.analyzed[stream.PdfObjectDictionary]; ok {
+			return
+		}
+		resources = res
+	}
+	c.analyzed[stream.PdfObjectDictionary] = struct{}{}
+
+	data, err := core.DecodeStream(stream)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to decode content stream: %v", err)
+		c.keep[resources] = struct{}{}
+		return
+	}
+	c.processing[stream] = struct{}{}
+	c.collectContent(string(data), resources)
+	delete(c.processing, stream)
+}
+
+// findDictionaryOwners returns the dictionaries referencing each dictionary
+// of `objects`, along with the referencing keys.
+func findDictionaryOwners(objects []core.PdfObject) map[*core.PdfObjectDictionary][]dictionaryOwner {
+	owners := make(map[*core.PdfObjectDictionary][]dictionaryOwner)
+	visited := make(map[core.PdfObject]struct{})
+
+	var walk func(obj core.PdfObject)
+	walk = func(obj core.PdfObject) {
+		if _, ok := visited[obj]; ok {
+			return
+		}
+		switch t := obj.(type) {
+		case *core.PdfIndirectObject:
+			visited[obj] = struct{}{}
+			walk(t.PdfObject)
+		case *core.PdfObjectStream:
+			visited[obj] = struct{}{}
+			walk(t.PdfObjectDictionary)
+		case *core.PdfObjectStreams:
+			visited[obj] = struct{}{}
+			for _, elem := range t.Elements() {
+				walk(elem)
+			}
+		case *core.PdfObjectArray:
+			visited[obj] = struct{}{}
+			for _, elem := range t.Elements() {
+				walk(elem)
+			}
+		case *core.PdfObjectDictionary:
+			visited[obj] = struct{}{}
+			for _, key := range t.Keys() {
+				val := t.Get(key)
+				if dict, ok := core.GetDict(val); ok {
+					owners[dict] = append(owners[dict], dictionaryOwner{dict: t, key: key})
+				}
+				walk(val)
+			}
+		}
+	}
+	for _, obj := range objects {
+		walk(obj)
+	}
+	return owners
+}
+
+// decodePageContents returns the decoded content streams of `page`.
+func decodePageContents(page *core.PdfObjectDictionary) ([]byte, error) {
+	contents := page.Get("Contents")
+	if arr, ok := core.GetArray(contents); ok {
+		var content []byte
+		for _, obj := range arr.Elements() {
+			if stream, ok := core.GetStream(obj); ok {
+				data, err := core.DecodeStream(stream)
+				if err != nil {
+					return nil, err
+				}
+				content = append(content, data...)
+				content = append(content, '\n')
+			}
+		}
+		return content, nil
+	}
+	if stream, ok := core.GetStream(contents); ok {
+		return core.DecodeStream(stream)
+	}
+	return nil, nil
+}
@@ -0,0 +1,173 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package optimize_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/optimize"
+)
+
+// writeUnusedResources writes a document whose pages share resources, only
+// some of which are used by the page contents.
+func writeUnusedResources(t *testing.T, optimizer model.Optimizer, removeUnused bool) []byte {
+	resources := model.NewPdfPageResources()
+	for _, name := range []core.PdfObjectName{"F1", "F2"} {
+		font, err := model.NewPdfFontFromTTFFile("../testdata/font/OpenSans-Regular.ttf")
+		require.NoError(t, err)
+		resources.SetFontByName(name, font.ToPdfObject())
+	}
+	for _, name := range []core.PdfObjectName{"Im1", "Im2", "Im3"} {
+		ximg, err := model.NewXObjectImageFromImage(makeTestImage(20, 20, 3, 8), nil, core.NewFlateEncoder())
+		require.NoError(t, err)
+		require.NoError(t, resources.SetXObjectImageByName(name, ximg))
+	}
+	gs := core.MakeDict()
+	gs.Set("ca", core.MakeFloat(0.5))
+	require.NoError(t, resources.AddExtGState("GS1", gs))
+
+	// Form with its own resources.
+	form := model.NewXObjectForm()
+	form.BBox = core.MakeArrayFromFloats([]float64{0, 0, 10, 10})
+	form.Resources = model.NewPdfPageResources()
+	for _, name := range []core.PdfObjectName{"Im1", "Im2"} {
+		ximg, err := model.NewXObjectImageFromImage(makeTestImage(30, 30, 1, 8), nil, core.NewFlateEncoder())
+		require.NoError(t, err)
+		require.NoError(t, form.Resources.SetXObjectImageByName(name, ximg))
+	}
+	require.NoError(t, form.SetContentStream([]byte("/Im2 Do"), nil))
+	require.NoError(t, resources.SetXObjectFormByName("Fm1", form))
+
+	w := model.NewPdfWriter()
+	if optimizer != nil {
+		w.SetOptimizer(optimizer)
+	}
+	w.SetRemoveUnusedObjects(removeUnused)
+	for _, content := range []string{"BT /F1 12 Tf (Text) Tj ET /Im1 Do", "/Fm1 Do"} {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+		page.Resources = resources
+		require.NoError(t, page.AddContentStreamByString(content))
+		require.NoError(t, w.AddPage(page))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	return buf.Bytes()
+}
+
+func TestCleanUnusedResources(t *testing.T) {
+	original := writeUnusedResources(t, nil, false)
+	optimizer := optimize.New(optimize.Options{CleanUnusedResources: true})
+
+	// The pruned resources are kept in the file unless the unused objects
+	// are removed.
+	pruned := writeUnusedResources(t, optimizer, false)
+	cleaned := writeUnusedResources(t, optimizer, true)
+	require.Less(t, len(cleaned), len(pruned))
+	require.Less(t, len(cleaned), len(original))
+
+	r, err := model.NewPdfReader(bytes.NewReader(cleaned))
+	require.NoError(t, err)
+	numObjects := len(r.GetObjectNums())
+	for i := 1; i <= 2; i++ {
+		page, err := r.GetPage(i)
+		require.NoError(t, err)
+
+		_, found := page.Resources.GetFontByName("F1")
+		require.True(t, found)
+		_, found = page.Resources.GetFontByName("F2")
+		require.False(t, found)
+		for name, used := range map[core.PdfObjectName]bool{"Im1": true, "Im2": false, "Im3": false, "Fm1": true} {
+			_, xtype := page.Resources.GetXObjectByName(name)
+			require.Equal(t, used, xtype != model.XObjectTypeUndefined, name)
+		}
+		require.Nil(t, page.Resources.ExtGState)
+
+		form, err := page.Resources.GetXObjectFormByName("Fm1")
+		require.NoError(t, err)
+		require.False(t, form.Resources.HasXObjectByName("Im1"))
+		require.True(t, form.Resources.HasXObjectByName("Im2"))
+	}
+
+	r, err = model.NewPdfReader(bytes.NewReader(pruned))
+	require.NoError(t, err)
+	require.Greater(t, len(r.GetObjectNums()), numObjects)
+}
+
+// makeDict returns a dictionary with `entries`.
+func makeDict(entries map[core.PdfObjectName]core.PdfObject) *core.PdfObjectDictionary {
+	dict := core.MakeDict()
+	for key, val := range entries {
+		dict.Set(key, val)
+	}
+	return dict
+}
+
+func TestCleanUnusedResourcesShared(t *testing.T) {
+	// The font dictionary of the page is shared with the AcroForm default
+	// resources and the resources of the second page are shared with an
+	// annotation appearance, so neither is pruned.
+	image, err := core.MakeStream([]byte{0}, nil)
+	require.NoError(t, err)
+	fonts := core.MakeIndirectObject(makeDict(map[core.PdfObjectName]core.PdfObject{
+		"F1": makeDict(map[core.PdfObjectName]core.PdfObject{"Subtype": core.MakeName("Type1")}),
+		"F2": makeDict(map[core.PdfObjectName]core.PdfObject{"Subtype": core.MakeName("Type1")}),
+	}))
+	xobjects := func() core.PdfObject {
+		return makeDict(map[core.PdfObjectName]core.PdfObject{"Im1": image, "Im2": image})
+	}
+	content, err := core.MakeStream([]byte("/Im1 Do"), nil)
+	require.NoError(t, err)
+	res1 := makeDict(map[core.PdfObjectName]core.PdfObject{"Font": fonts, "XObject": xobjects()})
+	res2 := core.MakeIndirectObject(makeDict(map[core.PdfObjectName]core.PdfObject{"XObject": xobjects()}))
+	appearance, err := core.MakeStream(nil, nil)
+	require.NoError(t, err)
+	appearance.Set("Subtype", core.MakeName("Form"))
+	appearance.Set("Resources", res2)
+
+	pages := core.MakeDict()
+	page1 := core.MakeIndirectObject(makeDict(map[core.PdfObjectName]core.PdfObject{
+		"Type": core.MakeName("Page"), "Parent": pages, "Resources": res1, "Contents": content,
+	}))
+	page2 := core.MakeIndirectObject(makeDict(map[core.PdfObjectName]core.PdfObject{
+		"Type": core.MakeName("Page"), "Parent": pages, "Resources": res2, "Contents": content,
+		"Annots": core.MakeArray(makeDict(map[core.PdfObjectName]core.PdfObject{
+			"Subtype": core.MakeName("Square"),
+			"AP":      makeDict(map[core.PdfObjectName]core.PdfObject{"N": appearance}),
+		})),
+	}))
+	pages.Set("Type", core.MakeName("Pages"))
+	pages.Set("Kids", core.MakeArray(page1, page2))
+	catalog := core.MakeIndirectObject(makeDict(map[core.PdfObjectName]core.PdfObject{
+		"Type":     core.MakeName("Catalog"),
+		"Pages":    core.MakeIndirectObject(pages),
+		"AcroForm": makeDict(map[core.PdfObjectName]core.PdfObject{"DR": makeDict(map[core.PdfObjectName]core.PdfObject{"Font": fonts})}),
+	}))
+	objects := []core.PdfObject{catalog, page1, page2, fonts, res2, content, appearance}
+
+	opt := optimize.CleanUnusedResources{}
+	_, err = opt.Optimize(objects)
+	require.NoError(t, err)
+
+	fontDict, ok := core.GetDict(fonts)
+	require.True(t, ok)
+	require.Len(t, fontDict.Keys(), 2)
+	xobjDict, ok := core.GetDict(res1.Get("XObject"))
+	require.True(t, ok)
+	require.Equal(t, []core.PdfObjectName{"Im1"}, xobjDict.Keys())
+
+	res2Dict, ok := core.GetDict(res2)
+	require.True(t, ok)
+	xobjDict, ok = core.GetDict(res2Dict.Get("XObject"))
+	require.True(t, ok)
+	require.Len(t, xobjDict.Keys(), 2)
+}
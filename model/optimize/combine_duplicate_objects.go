@@ -0,0 +1,109 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package optimize
+
+import (
+	"crypto/md5"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// CombineDuplicateObjects combines identical indirect objects and streams,
+// comparing both the stream dictionaries and data. Combining is repeated
+// until no duplicates remain, so that objects which become identical once the
+// objects they reference are combined are combined as well. This merges the
+// fonts and images duplicated on each page of merged documents, along with
+// their descriptors, font files and soft masks.
+// Pages, annotations, form fields, structure elements and outline items are
+// not combined as their identity is significant.
+// It implements interface model.Optimizer.
+type CombineDuplicateObjects struct {
+}
+
+// Optimize optimizes PDF objects to decrease PDF size.
+func (c *CombineDuplicateObjects) Optimize(objects []core.PdfObject) (optimizedObjects []core.PdfObject, err error) {
+	optimizedObjects = make([]core.PdfObject, len(objects))
+	copy(optimizedObjects, objects)
+
+	for pass := 1; ; pass++ {
+		// The hashes of the objects include the numbers of the objects they
+		// reference, which must be unique.
+		updateObjectNumbers(optimizedObjects)
+
+		replaceTable := make(map[core.PdfObject]core.PdfObject)
+		objectsByHash := make(map[string]core.PdfObject)
+		for _, obj := range optimizedObjects {
+			hash, ok := duplicateObjectHash(obj)
+			if !ok {
+				continue
+			}
+			if first, found := objectsByHash[hash]; found {
+				replaceTable[obj] = first
+				continue
+			}
+			objectsByHash[hash] = obj
+		}
+		if len(replaceTable) == 0 {
+			break
+		}
+		common.Log.Trace("Pass %d: combining %d duplicate objects", pass, len(replaceTable))
+
+		remaining := make([]core.PdfObject, 0, len(optimizedObjects)-len(replaceTable))
+		for _, obj := range optimizedObjects {
+			if _, found := replaceTable[obj]; found {
+				continue
+			}
+			remaining = append(remaining, obj)
+		}
+		optimizedObjects = remaining
+		replaceObjectsInPlace(optimizedObjects, replaceTable)
+	}
+	return optimizedObjects, nil
+}
+
+// duplicateObjectHash returns the hash identifying the content of `obj`.
+// Returns false if `obj` cannot be combined with identical objects.
+func duplicateObjectHash(obj core.PdfObject) (string, bool) {
+	hasher := md5.New()
+	switch t := obj.(type) {
+	case *core.PdfIndirectObject:
+		if dict, ok := t.PdfObject.(*core.PdfObjectDictionary); ok && hasIdentity(dict) {
+			return "", false
+		}
+		hasher.Write([]byte("obj:"))
+		hasher.Write([]byte(t.PdfObject.WriteString()))
+	case *core.PdfObjectStream:
+		if hasIdentity(t.PdfObjectDictionary) {
+			return "", false
+		}
+		hasher.Write([]byte("stream:"))
+		hasher.Write([]byte(t.PdfObjectDictionary.WriteString()))
+		hasher.Write([]byte{0})
+		hasher.Write(t.Stream)
+	default:
+		return "", false
+	}
+	return string(hasher.Sum(nil)), true
+}
+
+// hasIdentity returns true if the object with dictionary `dict` is
+// significant by itself rather than by its content, e.g. a page or an
+// annotation, even if identical to another object.
+func hasIdentity(dict *core.PdfObjectDictionary) bool {
+	typ, _ := core.GetNameVal(dict.Get("Type"))
+	switch typ {
+	case "Catalog", "Pages", "Page", "Annot", "StructTreeRoot", "StructElem", "Outlines", "Sig",
+		"ObjStm", "XRef":
+		return true
+	}
+
+	// Annotations without Type, form fields and outline items.
+	if dict.Get("Rect") != nil && dict.Get("Subtype") != nil {
+		return true
+	}
+	return dict.Get("FT") != nil || dict.Get("Parent") != nil
+}
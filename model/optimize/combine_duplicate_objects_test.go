@@ -0,0 +1,136 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package optimize_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/optimize"
+)
+
+// writeDuplicatedPages writes a document whose pages each contain their own
+// copy of the same font and image, as in merged documents.
+func writeDuplicatedPages(t *testing.T, numPages int, optimizer model.Optimizer) []byte {
+	w := model.NewPdfWriter()
+	if optimizer != nil {
+		w.SetOptimizer(optimizer)
+	}
+	for i := 0; i < numPages; i++ {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+		page.Resources = model.NewPdfPageResources()
+
+		font, err := model.NewPdfFontFromTTFFile("../testdata/font/OpenSans-Regular.ttf")
+		require.NoError(t, err)
+		page.Resources.SetFontByName("F1", font.ToPdfObject())
+
+		// Images with the same data and different dimensions.
+		data := []byte{0, 64, 128, 192, 255, 32}
+		for name, width := range map[core.PdfObjectName]int64{"Im1": 2, "Im2": 3} {
+			img := &model.Image{Width: width, Height: 6 / width, BitsPerComponent: 8, ColorComponents: 1, Data: data}
+			ximg, err := model.NewXObjectImageFromImage(img, nil, nil)
+			require.NoError(t, err)
+			require.NoError(t, page.Resources.SetXObjectImageByName(name, ximg))
+		}
+
+		square := model.NewPdfAnnotationSquare()
+		square.Rect = core.MakeArrayFromFloats([]float64{0, 0, 10, 10})
+		page.AddAnnotation(square.PdfAnnotation)
+
+		require.NoError(t, page.AddContentStreamByString("BT /F1 12 Tf (Text) Tj ET /Im1 Do /Im2 Do"))
+		require.NoError(t, w.AddPage(page))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	return buf.Bytes()
+}
+
+func TestCombineDuplicateObjects(t *testing.T) {
+	original := writeDuplicatedPages(t, 3, nil)
+	optimized := writeDuplicatedPages(t, 3, optimize.New(optimize.Options{CombineDuplicateObjects: true}))
+	require.Less(t, 2*len(optimized), len(original))
+
+	r, err := model.NewPdfReader(bytes.NewReader(optimized))
+	require.NoError(t, err)
+	page1, err := r.GetPage(1)
+	require.NoError(t, err)
+	for i := 2; i <= 3; i++ {
+		page, err := r.GetPage(i)
+		require.NoError(t, err)
+
+		// Fonts and images are shared by the pages.
+		font1, found := page1.Resources.GetFontByName("F1")
+		require.True(t, found)
+		font, found := page.Resources.GetFontByName("F1")
+		require.True(t, found)
+		require.Same(t, core.ResolveReference(font1), core.ResolveReference(font))
+		for _, name := range []core.PdfObjectName{"Im1", "Im2"} {
+			img1, _ := page1.Resources.GetXObjectByName(name)
+			img, _ := page.Resources.GetXObjectByName(name)
+			require.Same(t, img1, img)
+		}
+
+		// Annotations are kept distinct.
+		annots1, err := page1.GetAnnotations()
+		require.NoError(t, err)
+		annots, err := page.GetAnnotations()
+		require.NoError(t, err)
+		require.Len(t, annots, 1)
+		require.True(t, annots1[0].GetContainingPdfObject() != annots[0].GetContainingPdfObject())
+	}
+
+	// Images with identical data and different dimensions are not combined.
+	im1, _ := page1.Resources.GetXObjectByName("Im1")
+	im2, _ := page1.Resources.GetXObjectByName("Im2")
+	require.True(t, im1 != im2)
+	ximg, err := page1.Resources.GetXObjectImageByName("Im2")
+	require.NoError(t, err)
+	require.Equal(t, int64(3), *ximg.Width)
+
+	font, err := model.NewPdfFontFromTTFFile("../testdata/font/OpenSans-Regular.ttf")
+	require.NoError(t, err)
+	fontObj, _ := page1.Resources.GetFontByName("F1")
+	loaded, err := model.NewPdfFontFromPdfObject(fontObj)
+	require.NoError(t, err)
+	require.Equal(t, font.BaseFont(), loaded.BaseFont())
+}
+
+func TestCombineDuplicateStreamsParameters(t *testing.T) {
+	objects, err := parseIndirectObjects(`
+1 0 obj
+<< /Width 2 /Height 3 /Length 6 >>
+stream
+abcdef
+endstream
+endobj
+2 0 obj
+<< /Width 3 /Height 2 /Length 6 >>
+stream
+abcdef
+endstream
+endobj
+3 0 obj
+<< /Width 2 /Height 3 /Length 6 >>
+stream
+abcdef
+endstream
+endobj
+`)
+	require.NoError(t, err)
+	require.Len(t, objects, 3)
+
+	// Only the streams with identical dictionaries are combined.
+	opt := optimize.CombineDuplicateStreams{}
+	optObjects, err := opt.Optimize(objects)
+	require.NoError(t, err)
+	require.Len(t, optObjects, 2)
+}
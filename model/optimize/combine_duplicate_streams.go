@@ -11,19 +11,23 @@ import (
 	"github.com/unidoc/unipdf/v3/core"
 )
 
-// CombineDuplicateStreams combines duplicated streams by its data hash.
+// CombineDuplicateStreams combines duplicated streams by the hash of their dictionary and data.
 // It implements interface model.Optimizer.
 type CombineDuplicateStreams struct {
 }
 
 // Optimize optimizes PDF objects to decrease PDF size.
 func (dup *CombineDuplicateStreams) Optimize(objects []core.PdfObject) (optimizedObjects []core.PdfObject, err error) {
+	updateObjectNumbers(objects)
 	replaceTable := make(map[core.PdfObject]core.PdfObject)
 	toDelete := make(map[core.PdfObject]struct{})
 	streamsByHash := make(map[string][]*core.PdfObjectStream)
 	for _, obj := range objects {
 		if stream, isStreamObj := obj.(*core.PdfObjectStream); isStreamObj {
+			// Streams with identical data may differ by their parameters
+			// (e.g. images of different dimensions).
 			hasher := md5.New()
+			hasher.Write([]byte(stream.PdfObjectDictionary.WriteString()))
 			hasher.Write([]byte(stream.Stream))
 			hash := string(hasher.Sum(nil))
 			streamsByHash[hash] = append(streamsByHash[hash], stream)
@@ -0,0 +1,701 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package optimize
+
+import (
+	"bytes"
+	"crypto/md5"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unitype"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/cmap"
+)
+
+// CombineFonts unifies the embedded TrueType composite fonts that originate
+// from the same font program, as when merging documents each embedding its
+// own subset of a font, into a single font re-subset to the union of the
+// glyphs used by the document.
+// Fonts are only unified when their programs are subsets of the same font
+// keeping the original glyph indices, with identical glyphs and consistent
+// widths and unicode mappings. Fonts used by content which is not analyzed, e.g. the interactive
+// form default resources, are left unchanged.
+// It implements interface model.Optimizer.
+type CombineFonts struct {
+}
+
+// combinedFont is a composite font considered for combining.
+type combinedFont struct {
+	// container is the object referencing the font in the resources.
+	container  core.PdfObject
+	dict       *core.PdfObjectDictionary
+	descendant *core.PdfObjectDictionary
+	fontFile   *core.PdfObjectStream
+	program    []byte
+	widths     map[int]float64
+	dw         float64
+	// used holds the codes of the glyphs used by the content streams.
+	used map[int]struct{}
+}
+
+// width returns the width of the glyph `cid` of font `f`.
+func (f *combinedFont) width(cid int) float64 {
+	if w, ok := f.widths[cid]; ok {
+		return w
+	}
+	return f.dw
+}
+
+// toUnicode returns the mappings of the ToUnicode CMap of font `f`.
+func (f *combinedFont) toUnicode() (map[cmap.CharCode]string, bool) {
+	stream, ok := core.GetStream(f.dict.Get("ToUnicode"))
+	if !ok {
+		return nil, true
+	}
+	data, err := core.DecodeStream(stream)
+	if err != nil {
+		return nil, false
+	}
+	cm, err := cmap.LoadCmapFromDataCID(data)
+	if err != nil {
+		return nil, false
+	}
+	return cm.CodeToUnicode(), true
+}
+
+// fontUsageCollector collects the glyphs used with the fonts of a document.
+type fontUsageCollector struct {
+	used map[*core.PdfObjectDictionary]map[int]struct{}
+	// containers holds the objects referencing the fonts in the resources.
+	containers map[*core.PdfObjectDictionary]core.PdfObject
+	// resources holds the analyzed resource dictionaries.
+	resources map[*core.PdfObjectDictionary]struct{}
+	// analyzed holds the analyzed page tree nodes, forms, patterns and
+	// appearance streams.
+	analyzed map[*core.PdfObjectDictionary]struct{}
+	// processing holds the content streams being processed to avoid infinite
+	// recursion.
+	processing map[*core.PdfObjectStream]struct{}
+	failed     bool
+}
+
+// Optimize optimizes PDF objects to decrease PDF size.
+func (c *CombineFonts) Optimize(objects []core.PdfObject) (optimizedObjects []core.PdfObject, err error) {
+	catalog := findCatalog(objects)
+	if catalog == nil {
+		return objects, nil
+	}
+
+	collector := &fontUsageCollector{
+		used:       make(map[*core.PdfObjectDictionary]map[int]struct{}),
+		containers: make(map[*core.PdfObjectDictionary]core.PdfObject),
+		resources:  make(map[*core.PdfObjectDictionary]struct{}),
+		analyzed:   make(map[*core.PdfObjectDictionary]struct{}),
+		processing: make(map[*core.PdfObjectStream]struct{}),
+	}
+	walkPages(catalog.Get("Pages"), collector.analyzed, collector.collectPage)
+	if collector.failed {
+		common.Log.Debug("Unable to analyze the content streams - fonts not combined")
+		return objects, nil
+	}
+
+	// Group the fonts by name and encoding.
+	owners := findDictionaryOwners(objects)
+	groups := make(map[string][]*combinedFont)
+	var keys []string
+	for dict, used := range collector.used {
+		if !collector.isFullyAnalyzed(dict, owners) {
+			continue
+		}
+		font, key := newCombinedFont(dict, collector.containers[dict], used)
+		if font == nil {
+			continue
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], font)
+	}
+	sort.Strings(keys)
+
+	replaceTable := make(map[core.PdfObject]core.PdfObject)
+	removable := make(map[core.PdfObject]struct{})
+	for _, key := range keys {
+		fonts := groups[key]
+		if len(fonts) < 2 {
+			continue
+		}
+		// The font with the largest program is the most likely to contain
+		// the glyphs of the others.
+		sort.SliceStable(fonts, func(i, j int) bool {
+			return len(fonts[i].program) > len(fonts[j].program)
+		})
+		if combined := combineFonts(fonts[0], fonts[1:]); len(combined) > 0 {
+			for _, font := range combined {
+				replaceTable[font.container] = fonts[0].container
+				collectFontObjects(font.container, removable)
+			}
+		}
+	}
+	if len(replaceTable) == 0 {
+		return objects, nil
+	}
+	common.Log.Trace("Combined %d fonts", len(replaceTable))
+	remaining := make([]core.PdfObject, 0, len(objects))
+	for _, obj := range objects {
+		if _, found := replaceTable[obj]; !found {
+			remaining = append(remaining, obj)
+		}
+	}
+	objects = remaining
+	replaceObjectsInPlace(objects, replaceTable)
+
+	// Drop the objects of the combined fonts which are no longer referenced.
+	referenced := make(map[core.PdfObject]struct{})
+	for _, obj := range objects {
+		if _, ok := removable[obj]; !ok {
+			markReferenced(obj, referenced)
+		}
+	}
+	optimizedObjects = make([]core.PdfObject, 0, len(objects))
+	for _, obj := range objects {
+		if _, ok := removable[obj]; ok {
+			if _, ok := referenced[obj]; !ok {
+				continue
+			}
+		}
+		optimizedObjects = append(optimizedObjects, obj)
+	}
+	return optimizedObjects, nil
+}
+
+// combineFonts combines the fonts of `others` which are compatible with
+// `base` into `base`, re-subsetting its program to the glyphs used by all of
+// them. Returns the combined fonts.
+func combineFonts(base *combinedFont, others []*combinedFont) []*combinedFont {
+	toUnicode, ok := base.toUnicode()
+	if !ok {
+		return nil
+	}
+	program := base.program
+	var combined []*combinedFont
+	for _, font := range others {
+		fontToUnicode, ok := font.toUnicode()
+		if !ok {
+			continue
+		}
+
+		// The widths and unicode mappings must not conflict.
+		consistent := true
+		for cid := range font.used {
+			if font.width(cid) != base.width(cid) {
+				consistent = false
+				break
+			}
+		}
+		for code, s := range fontToUnicode {
+			if baseStr, ok := toUnicode[code]; ok && baseStr != s {
+				consistent = false
+				break
+			}
+		}
+		if !consistent {
+			continue
+		}
+		merged, err := mergeFontPrograms(program, font.program)
+		if err != nil {
+			common.Log.Debug("Fonts %s not combined: %v", font.dict.Get("BaseFont"), err)
+			continue
+		}
+		program = merged
+
+		for cid := range font.used {
+			base.used[cid] = struct{}{}
+			if w, ok := font.widths[cid]; ok {
+				base.widths[cid] = w
+			}
+		}
+		if len(fontToUnicode) > 0 && toUnicode == nil {
+			toUnicode = make(map[cmap.CharCode]string, len(fontToUnicode))
+		}
+		for code, s := range fontToUnicode {
+			toUnicode[code] = s
+		}
+		combined = append(combined, font)
+	}
+	if len(combined) == 0 {
+		return nil
+	}
+
+	base.descendant.Set("W", makeCIDWidths(base.widths))
+	if toUnicode != nil {
+		// The ToUnicode stream of a combined font is reused if needed, as
+		// the stream objects cannot be added.
+		existing, ok := core.GetStream(base.dict.Get("ToUnicode"))
+		for _, font := range combined {
+			if !ok {
+				existing, ok = core.GetStream(font.dict.Get("ToUnicode"))
+			}
+		}
+		stream, err := cmap.NewToUnicodeCMapFromStrings(toUnicode).Stream()
+		if err != nil {
+			common.Log.Debug("ERROR: unable to create ToUnicode CMap: %v", err)
+			return nil
+		}
+		*existing = *stream
+		base.dict.Set("ToUnicode", existing)
+	}
+	base.resubset(program)
+	return combined
+}
+
+// resubset sets the program of font `f` to `program` subset to the glyphs
+// used with the font.
+func (f *combinedFont) resubset(program []byte) {
+	// The .notdef glyph is always kept.
+	indices := []unitype.GlyphIndex{0}
+	for cid := range f.used {
+		indices = append(indices, unitype.GlyphIndex(cid))
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	data, err := subsetFontProgram(program, indices)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to subset font: %v", err)
+		data = program
+	}
+	stream, err := core.MakeStream(data, core.NewFlateEncoder())
+	if err != nil {
+		common.Log.Debug("ERROR: unable to encode font: %v", err)
+		return
+	}
+	*f.fontFile = *stream
+	f.fontFile.Set("Length1", core.MakeInteger(int64(len(data))))
+
+	// Subset fonts are identified by a tag prefixed to their names.
+	baseFont, _ := core.GetNameVal(f.dict.Get("BaseFont"))
+	if hasSubsetTag(baseFont) {
+		return
+	}
+	hash := md5.Sum(data)
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + hash[i]%26
+	}
+	name := core.MakeName(string(tag) + "+" + baseFont)
+	f.dict.Set("BaseFont", name)
+	f.descendant.Set("BaseFont", name)
+	if descriptor, ok := core.GetDict(f.descendant.Get("FontDescriptor")); ok {
+		descriptor.Set("FontName", name)
+	}
+}
+
+// newCombinedFont returns the font with dictionary `dict` if it is an
+// embedded TrueType composite font with identity encoding, along with the key
+// of the fonts it may be combined with.
+func newCombinedFont(dict *core.PdfObjectDictionary, container core.PdfObject,
+	used map[int]struct{}) (*combinedFont, string) {
+	if subtype, _ := core.GetNameVal(dict.Get("Subtype")); subtype != "Type0" {
+		return nil, ""
+	}
+	if encoding, _ := core.GetNameVal(dict.Get("Encoding")); encoding != "Identity-H" {
+		return nil, ""
+	}
+	descendants, ok := core.GetArray(dict.Get("DescendantFonts"))
+	if !ok || descendants.Len() != 1 {
+		return nil, ""
+	}
+	descendant, ok := core.GetDict(descendants.Get(0))
+	if !ok {
+		return nil, ""
+	}
+	if subtype, _ := core.GetNameVal(descendant.Get("Subtype")); subtype != "CIDFontType2" {
+		return nil, ""
+	}
+	if obj := descendant.Get("CIDToGIDMap"); obj != nil {
+		if name, _ := core.GetNameVal(obj); name != "Identity" {
+			return nil, ""
+		}
+	}
+	descriptor, ok := core.GetDict(descendant.Get("FontDescriptor"))
+	if !ok {
+		return nil, ""
+	}
+	fontFile, ok := core.GetStream(descriptor.Get("FontFile2"))
+	if !ok {
+		return nil, ""
+	}
+	program, err := core.DecodeStream(fontFile)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to decode font program: %v", err)
+		return nil, ""
+	}
+	widths, ok := parseCIDWidths(descendant.Get("W"))
+	if !ok {
+		return nil, ""
+	}
+	dw := 1000.0
+	if obj := descendant.Get("DW"); obj != nil {
+		if dw, err = core.GetNumberAsFloat(core.TraceToDirectObject(obj)); err != nil {
+			return nil, ""
+		}
+	}
+
+	baseFont, _ := core.GetNameVal(dict.Get("BaseFont"))
+	if hasSubsetTag(baseFont) {
+		baseFont = baseFont[7:]
+	}
+	font := &combinedFont{
+		container:  container,
+		dict:       dict,
+		descendant: descendant,
+		fontFile:   fontFile,
+		program:    program,
+		widths:     widths,
+		dw:         dw,
+		used:       used,
+	}
+	return font, baseFont
+}
+
+// hasSubsetTag returns true if font name `name` is prefixed with a subset
+// tag of 6 uppercase letters.
+func hasSubsetTag(name string) bool {
+	if len(name) < 7 || name[6] != '+' {
+		return false
+	}
+	return strings.Trim(name[:6], "ABCDEFGHIJKLMNOPQRSTUVWXYZ") == ""
+}
+
+// subsetFontProgram returns the TrueType program `data` subset to the glyphs
+// `indices`, without the tables `pruned`.
+func subsetFontProgram(data []byte, indices []unitype.GlyphIndex, pruned ...string) ([]byte, error) {
+	// The font is parsed for each subset as subsetting alters the glyphs of
+	// the original font.
+	fnt, err := unitype.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	fnt, err = fnt.SubsetKeepIndices(indices)
+	if err != nil {
+		return nil, err
+	}
+	if err := fnt.PruneTables(pruned...); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := fnt.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseCIDWidths returns the glyph widths of the CIDFont widths array `obj`.
+func parseCIDWidths(obj core.PdfObject) (map[int]float64, bool) {
+	widths := make(map[int]float64)
+	if obj == nil {
+		return widths, true
+	}
+	arr, ok := core.GetArray(obj)
+	if !ok {
+		return nil, false
+	}
+	elements := arr.Elements()
+	for i := 0; i < len(elements); {
+		first, ok := core.GetIntVal(elements[i])
+		if !ok || i+1 >= len(elements) {
+			return nil, false
+		}
+		if list, ok := core.GetArray(elements[i+1]); ok {
+			vals, err := list.ToFloat64Array()
+			if err != nil {
+				return nil, false
+			}
+			for j, w := range vals {
+				widths[first+j] = w
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(elements) {
+			return nil, false
+		}
+		last, ok := core.GetIntVal(elements[i+1])
+		if !ok {
+			return nil, false
+		}
+		w, err := core.GetNumberAsFloat(core.TraceToDirectObject(elements[i+2]))
+		if err != nil {
+			return nil, false
+		}
+		for cid := first; cid <= last; cid++ {
+			widths[cid] = w
+		}
+		i += 3
+	}
+	return widths, true
+}
+
+// makeCIDWidths returns the CIDFont widths array of the glyph `widths`.
+func makeCIDWidths(widths map[int]float64) *core.PdfObjectArray {
+	cids := make([]int, 0, len(widths))
+	for cid := range widths {
+		cids = append(cids, cid)
+	}
+	sort.Ints(cids)
+
+	arr := core.MakeArray()
+	for i := 0; i < len(cids); {
+		// Consecutive glyphs are grouped as c [w1 w2 ...].
+		j := i + 1
+		for j < len(cids) && cids[j] == cids[j-1]+1 {
+			j++
+		}
+		list := core.MakeArray()
+		for _, cid := range cids[i:j] {
+			list.Append(core.MakeFloat(widths[cid]))
+		}
+		arr.Append(core.MakeInteger(int64(cids[i])), list)
+		i = j
+	}
+	return arr
+}
+
+// collectFontObjects collects the indirect objects and streams of the font
+// `obj` in `objects`.
+func collectFontObjects(obj core.PdfObject, objects map[core.PdfObject]struct{}) {
+	switch t := obj.(type) {
+	case *core.PdfIndirectObject:
+		if _, ok := objects[obj]; ok {
+			return
+		}
+		objects[obj] = struct{}{}
+		collectFontObjects(t.PdfObject, objects)
+	case *core.PdfObjectStream:
+		objects[obj] = struct{}{}
+	case *core.PdfObjectArray:
+		for _, elem := range t.Elements() {
+			collectFontObjects(elem, objects)
+		}
+	case *core.PdfObjectDictionary:
+		for _, key := range t.Keys() {
+			collectFontObjects(t.Get(key), objects)
+		}
+	}
+}
+
+// markReferenced marks the objects referenced by `obj` in `referenced`.
+func markReferenced(obj core.PdfObject, referenced map[core.PdfObject]struct{}) {
+	var children []core.PdfObject
+	switch t := obj.(type) {
+	case *core.PdfIndirectObject:
+		children = []core.PdfObject{t.PdfObject}
+	case *core.PdfObjectStream:
+		children = []core.PdfObject{t.PdfObjectDictionary}
+	case *core.PdfObjectStreams:
+		children = t.Elements()
+	case *core.PdfObjectArray:
+		children = t.Elements()
+	case *core.PdfObjectDictionary:
+		for _, key := range t.Keys() {
+			children = append(children, t.Get(key))
+		}
+	}
+	for _, child := range children {
+		if _, ok := referenced[child]; ok {
+			continue
+		}
+		referenced[child] = struct{}{}
+		markReferenced(child, referenced)
+	}
+}
+
+// isFullyAnalyzed returns true if the font with dictionary `dict` is only
+// referenced from analyzed resources.
+func (c *fontUsageCollector) isFullyAnalyzed(dict *core.PdfObjectDictionary,
+	owners map[*core.PdfObjectDictionary][]dictionaryOwner) bool {
+	// The font is referenced by font resource dictionaries, themselves
+	// referenced by the resources of the analyzed content streams.
+	for _, fontsOwner := range owners[dict] {
+		for _, resourcesOwner := range owners[fontsOwner.dict] {
+			resources := resourcesOwner.dict
+			if _, ok := c.resources[resources]; !ok || resourcesOwner.key != "Font" {
+				return false
+			}
+			for _, owner := range owners[resources] {
+				if _, ok := c.analyzed[owner.dict]; !ok || owner.key != "Resources" {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// collectPage collects the glyphs used by the page `node`, with resources
+// `resources`.
+func (c *fontUsageCollector) collectPage(node, resources *core.PdfObjectDictionary) {
+	content, err := decodePageContents(node)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to decode page contents: %v", err)
+		c.failed = true
+		return
+	}
+	if resources != nil {
+		c.collectContent(string(content), resources, nil)
+	}
+
+	// Annotation appearances.
+	annots, _ := core.GetArray(node.Get("Annots"))
+	for _, annot := range annots.Elements() {
+		annotDict, ok := core.GetDict(annot)
+		if !ok {
+			continue
+		}
+		appearances, ok := core.GetDict(annotDict.Get("AP"))
+		if !ok {
+			continue
+		}
+		for _, key := range appearances.Keys() {
+			appearance := appearances.Get(key)
+			if stream, ok := core.GetStream(appearance); ok {
+				c.collectStream(stream, nil, nil)
+				continue
+			}
+			if states, ok := core.GetDict(appearance); ok {
+				for _, state := range states.Keys() {
+					if stream, ok := core.GetStream(states.Get(state)); ok {
+						c.collectStream(stream, nil, nil)
+					}
+				}
+			}
+		}
+	}
+}
+
+// collectStream collects the glyphs used by the content of the form, pattern
+// or appearance `stream` drawn with resources `parentResources` and font
+// `font`.
+func (c *fontUsageCollector) collectStream(stream *core.PdfObjectStream,
+	parentResources, font *core.PdfObjectDictionary) {
+	if _, ok := c.processing[stream]; ok {
+		return
+	}
+	c.analyzed[stream.PdfObjectDictionary] = struct{}{}
+	resources := parentResources
+	if res, ok := core.GetDict(stream.Get("Resources")); ok {
+		resources = res
+	}
+	if resources == nil {
+		return
+	}
+
+	data, err := core.DecodeStream(stream)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to decode content stream: %v", err)
+		c.failed = true
+		return
+	}
+	c.processing[stream] = struct{}{}
+	c.collectContent(string(data), resources, font)
+	delete(c.processing, stream)
+}
+
+// collectContent collects the glyphs used by `content` with resources
+// `resources` and initial font `font`.
+func (c *fontUsageCollector) collectContent(content string, resources, font *core.PdfObjectDictionary) {
+	ops, err := contentstream.NewContentStreamParser(content).Parse()
+	if err != nil {
+		common.Log.Debug("ERROR: unable to parse content stream: %v", err)
+		c.failed = true
+		return
+	}
+	c.resources[resources] = struct{}{}
+	fonts, _ := core.GetDict(resources.Get("Font"))
+
+	addGlyphs := func(obj core.PdfObject) {
+		str, ok := core.GetString(obj)
+		if !ok || font == nil {
+			return
+		}
+		used := c.used[font]
+		data := str.Bytes()
+		for i := 0; i+1 < len(data); i += 2 {
+			used[int(data[i])<<8|int(data[i+1])] = struct{}{}
+		}
+	}
+
+	var stack []*core.PdfObjectDictionary
+	for _, op := range *ops {
+		switch op.Operand {
+		case "q":
+			stack = append(stack, font)
+		case "Q":
+			if len(stack) > 0 {
+				font = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "Tf":
+			font = nil
+			if len(op.Params) != 2 || fonts == nil {
+				continue
+			}
+			name, ok := core.GetName(op.Params[0])
+			if !ok {
+				continue
+			}
+			obj := fonts.Get(*name)
+			if font, ok = core.GetDict(obj); !ok {
+				font = nil
+				continue
+			}
+			if _, ok := c.used[font]; !ok {
+				c.used[font] = make(map[int]struct{})
+				c.containers[font] = obj
+			}
+		case "Tj", "'", `"`:
+			if len(op.Params) > 0 {
+				addGlyphs(op.Params[len(op.Params)-1])
+			}
+		case "TJ":
+			if len(op.Params) != 1 {
+				continue
+			}
+			if arr, ok := core.GetArray(op.Params[0]); ok {
+				for _, elem := range arr.Elements() {
+					addGlyphs(elem)
+				}
+			}
+		case "Do", "scn", "SCN":
+			if len(op.Params) == 0 {
+				continue
+			}
+			name, ok := core.GetName(op.Params[len(op.Params)-1])
+			if !ok {
+				continue
+			}
+			category := core.PdfObjectName("XObject")
+			if op.Operand != "Do" {
+				category = "Pattern"
+			}
+			dict, ok := core.GetDict(resources.Get(category))
+			if !ok {
+				continue
+			}
+			stream, ok := core.GetStream(dict.Get(*name))
+			if !ok {
+				continue
+			}
+			if subtype, _ := core.GetNameVal(stream.Get("Subtype")); subtype == "Image" {
+				continue
+			}
+			c.collectStream(stream, resources, font)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package optimize_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/creator"
+	"github.com/unidoc/unipdf/v3/extractor"
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/optimize"
+)
+
+// writeSubsetDocument writes a document showing `text` with its own subset
+// of a composite font.
+func writeSubsetDocument(t *testing.T, text string) *model.PdfReader {
+	font, err := model.NewCompositePdfFontFromTTFFile("../testdata/font/OpenSans-Regular.ttf")
+	require.NoError(t, err)
+
+	c := creator.New()
+	c.EnableFontSubsetting(font)
+	p := c.NewStyledParagraph()
+	chunk := p.Append(text)
+	chunk.Style.Font = font
+	require.NoError(t, c.Draw(p))
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	return r
+}
+
+// writeMergedDocument writes the pages of `readers` to a single document.
+func writeMergedDocument(t *testing.T, readers []*model.PdfReader, optimizer model.Optimizer) []byte {
+	w := model.NewPdfWriter()
+	if optimizer != nil {
+		w.SetOptimizer(optimizer)
+	}
+	for _, r := range readers {
+		page, err := r.GetPage(1)
+		require.NoError(t, err)
+		require.NoError(t, w.AddPage(page))
+	}
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	return buf.Bytes()
+}
+
+func TestCombineFonts(t *testing.T) {
+	texts := []string{"Hello world", "Quick brown fox", "Hello fox"}
+	var readers []*model.PdfReader
+	for _, text := range texts {
+		readers = append(readers, writeSubsetDocument(t, text))
+	}
+	original := writeMergedDocument(t, readers, nil)
+	combined := writeMergedDocument(t, readers, optimize.New(optimize.Options{CombineFonts: true}))
+	require.Less(t, len(combined), len(original))
+
+	r, err := model.NewPdfReader(bytes.NewReader(combined))
+	require.NoError(t, err)
+	var fonts []core.PdfObject
+	for i, text := range texts {
+		page, err := r.GetPage(i + 1)
+		require.NoError(t, err)
+		fontDict, ok := core.GetDict(page.Resources.Font)
+		require.True(t, ok)
+		for _, name := range fontDict.Keys() {
+			font, ok := core.GetDict(fontDict.Get(name))
+			require.True(t, ok)
+			if subtype, _ := core.GetNameVal(font.Get("Subtype")); subtype == "Type0" {
+				fonts = append(fonts, font)
+			}
+		}
+		require.Len(t, fonts, i+1)
+
+		// The text shown with the combined font is unchanged.
+		ex, err := extractor.New(page)
+		require.NoError(t, err)
+		extracted, err := ex.ExtractText()
+		require.NoError(t, err)
+		require.Contains(t, extracted, text)
+	}
+	require.Same(t, fonts[0], fonts[1])
+	require.Same(t, fonts[0], fonts[2])
+
+	font, err := model.NewPdfFontFromPdfObject(fonts[0])
+	require.NoError(t, err)
+	require.Regexp(t, `^[A-Z]{6}\+OpenSans`, font.BaseFont())
+}
@@ -0,0 +1,331 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package optimize
+
+import (
+	"math"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// ImageRecompress optimizes images by re-encoding them: 16-bit images are
+// reduced to 8 bits per component, images placed at a resolution above
+// UpperPPI are downsampled and images are re-encoded with DCT when that
+// reduces their size. Soft masks follow the resolution of their images and
+// are Flate encoded.
+// The resolution of an image is determined from its largest placement on the
+// pages, including placements within form XObjects. Images which are not
+// placed on any page are not downsampled.
+// TODO: Add support for inline images.
+// It implements interface model.Optimizer.
+type ImageRecompress struct {
+	// Quality is the DCT (JPEG) quality (1-100). Images are not DCT encoded
+	// if Quality is 0.
+	Quality int
+
+	// UpperPPI is the maximum resolution of the images in pixels per inch.
+	// Images are not downsampled if UpperPPI is 0.
+	UpperPPI float64
+
+	// ReduceBitDepth enables reducing 16-bit images to 8 bits per component.
+	ReduceBitDepth bool
+}
+
+// Optimize optimizes PDF objects to decrease PDF size.
+func (i *ImageRecompress) Optimize(objects []core.PdfObject) (optimizedObjects []core.PdfObject, err error) {
+	if i.Quality <= 0 && i.UpperPPI <= 0 && !i.ReduceBitDepth {
+		return objects, nil
+	}
+
+	var ppis map[*core.PdfObjectStream]float64
+	if i.UpperPPI > 0 {
+		ppis = findImagePlacementPPIs(objects)
+	}
+
+	// Soft masks are processed with their images.
+	masks := make(map[*core.PdfObjectStream]struct{})
+	for _, obj := range objects {
+		stream, ok := core.GetStream(obj)
+		if !ok || !isImageStream(stream) {
+			continue
+		}
+		if mask, ok := core.GetStream(stream.Get("SMask")); ok {
+			masks[mask] = struct{}{}
+		}
+	}
+
+	processed := make(map[*core.PdfObjectStream]struct{})
+	for _, obj := range objects {
+		stream, ok := core.GetStream(obj)
+		if !ok || !isImageStream(stream) {
+			continue
+		}
+		if _, isMask := masks[stream]; isMask {
+			continue
+		}
+		if _, done := processed[stream]; done {
+			continue
+		}
+		processed[stream] = struct{}{}
+
+		scale := 1.0
+		if ppi := ppis[stream]; i.UpperPPI > 0 && ppi > i.UpperPPI {
+			scale = i.UpperPPI / ppi
+		}
+		if err := i.recompressImage(stream, scale, i.Quality); err != nil {
+			common.Log.Debug("Keeping original image: %v", err)
+			continue
+		}
+		if mask, ok := core.GetStream(stream.Get("SMask")); ok {
+			if err := i.recompressImage(mask, scale, 0); err != nil {
+				common.Log.Debug("Keeping original soft mask: %v", err)
+			}
+		}
+	}
+	return objects, nil
+}
+
+// recompressImage re-encodes the image `stream` in place, scaling its
+// dimensions by `scale` and encoding it with DCT at `quality` (if > 0).
+func (i *ImageRecompress) recompressImage(stream *core.PdfObjectStream, scale float64, quality int) error {
+	ximg, err := model.NewXObjectImageFromStream(stream)
+	if err != nil {
+		return err
+	}
+	if ximg.ColorSpace == nil || ximg.BitsPerComponent == nil {
+		// Stencil masks and JPX images are kept as is.
+		return nil
+	}
+	switch ximg.ColorSpace.(type) {
+	case *model.PdfColorspaceDeviceGray, *model.PdfColorspaceDeviceRGB,
+		*model.PdfColorspaceDeviceCMYK, *model.PdfColorspaceICCBased:
+	default:
+		// Averaging samples is not valid for indexed and special colorspaces.
+		return nil
+	}
+
+	img, err := ximg.ToImage()
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	if i.ReduceBitDepth && img.BitsPerComponent == 16 {
+		reduceImageBitDepth(img)
+		changed = true
+	}
+	if scale < 1 && img.BitsPerComponent == 8 {
+		width := int64(math.Max(1, math.Round(float64(img.Width)*scale)))
+		height := int64(math.Max(1, math.Round(float64(img.Height)*scale)))
+		if width < img.Width || height < img.Height {
+			downsampleImage(img, width, height)
+			changed = true
+		}
+	}
+
+	_, isDCT := ximg.Filter.(*core.DCTEncoder)
+	var encoder core.StreamEncoder
+	switch {
+	case quality > 0 && img.BitsPerComponent == 8 && (!isDCT || changed):
+		dct := core.NewDCTEncoder()
+		dct.Quality = quality
+		encoder = dct
+	case !changed:
+		return nil
+	case isDCT:
+		encoder = core.NewDCTEncoder()
+	default:
+		encoder = core.NewFlateEncoder()
+	}
+
+	origSize := len(stream.Stream)
+	ximg.Filter = encoder
+	if err := ximg.SetImage(img, ximg.ColorSpace); err != nil {
+		return err
+	}
+	if !changed && len(ximg.Stream) >= origSize {
+		// Re-encoding alone is worse - keeping the original.
+		return nil
+	}
+
+	// The stream dictionary is rebuilt with the new encoding parameters.
+	// Keep the entries unknown to the image model.
+	dict := stream.PdfObjectDictionary
+	ximg.ToPdfObject()
+	for _, key := range dict.Keys() {
+		switch key {
+		case "Filter", "DecodeParms", "Length", "DL":
+			continue
+		}
+		if stream.Get(key) == nil {
+			stream.Set(key, dict.Get(key))
+		}
+	}
+	return nil
+}
+
+// isImageStream returns true if `stream` is an image XObject.
+func isImageStream(stream *core.PdfObjectStream) bool {
+	subtype, _ := core.GetNameVal(stream.Get("Subtype"))
+	return subtype == "Image"
+}
+
+// reduceImageBitDepth converts the 16-bit image `img` to 8 bits per
+// component, keeping the most significant byte of each sample.
+func reduceImageBitDepth(img *model.Image) {
+	data := make([]byte, len(img.Data)/2)
+	for j := range data {
+		data[j] = img.Data[2*j]
+	}
+	img.Data = data
+	img.BitsPerComponent = 8
+}
+
+// downsampleImage scales the 8-bit image `img` down to `width`x`height`
+// pixels, averaging the samples covered by each target pixel.
+func downsampleImage(img *model.Image, width, height int64) {
+	numComponents := int64(img.ColorComponents)
+	data := make([]byte, width*height*numComponents)
+	sums := make([]int64, numComponents)
+	for y := int64(0); y < height; y++ {
+		y0, y1 := y*img.Height/height, (y+1)*img.Height/height
+		for x := int64(0); x < width; x++ {
+			x0, x1 := x*img.Width/width, (x+1)*img.Width/width
+			for c := range sums {
+				sums[c] = 0
+			}
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					offset := (sy*img.Width + sx) * numComponents
+					for c := int64(0); c < numComponents; c++ {
+						sums[c] += int64(img.Data[offset+c])
+					}
+				}
+			}
+			count := (y1 - y0) * (x1 - x0)
+			offset := (y*width + x) * numComponents
+			for c := int64(0); c < numComponents; c++ {
+				data[offset+c] = byte((sums[c] + count/2) / count)
+			}
+		}
+	}
+	img.Data = data
+	img.Width = width
+	img.Height = height
+}
+
+// findImagePlacementPPIs returns the maximum resolution in pixels per inch
+// at which each image is placed on the pages of the document in `objects`.
+func findImagePlacementPPIs(objects []core.PdfObject) map[*core.PdfObjectStream]float64 {
+	ppis := make(map[*core.PdfObjectStream]float64)
+	catalog := findCatalog(objects)
+	if catalog == nil {
+		return ppis
+	}
+
+	visited := make(map[*core.PdfObjectDictionary]struct{})
+	walkPages(catalog.Get("Pages"), visited, func(node, resources *core.PdfObjectDictionary) {
+		content, err := decodePageContents(node)
+		if err != nil {
+			common.Log.Debug("ERROR: unable to decode page contents: %v", err)
+			return
+		}
+		forms := make(map[*core.PdfObjectStream]struct{})
+		collectContentPPIs(string(content), resources, transform.IdentityMatrix(), forms, ppis)
+	})
+	return ppis
+}
+
+// collectContentPPIs collects the resolutions of the images placed by
+// `content` with resources `resources` and initial transformation matrix
+// `ctm`. `forms` holds the forms being processed to avoid infinite recursion.
+func collectContentPPIs(content string, resources *core.PdfObjectDictionary, ctm transform.Matrix,
+	forms map[*core.PdfObjectStream]struct{}, ppis map[*core.PdfObjectStream]float64) {
+	ops, err := contentstream.NewContentStreamParser(content).Parse()
+	if err != nil {
+		common.Log.Debug("ERROR: unable to parse content stream: %v", err)
+		return
+	}
+
+	var xobjects *core.PdfObjectDictionary
+	if resources != nil {
+		xobjects, _ = core.GetDict(resources.Get("XObject"))
+	}
+
+	var stack []transform.Matrix
+	for _, op := range *ops {
+		switch op.Operand {
+		case "q":
+			stack = append(stack, ctm)
+		case "Q":
+			if len(stack) > 0 {
+				ctm = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "cm":
+			vals, err := core.GetNumbersAsFloat(op.Params)
+			if err != nil || len(vals) != 6 {
+				continue
+			}
+			ctm.Concat(transform.NewMatrix(vals[0], vals[1], vals[2], vals[3], vals[4], vals[5]))
+		case "Do":
+			if len(op.Params) != 1 || xobjects == nil {
+				continue
+			}
+			name, ok := core.GetName(op.Params[0])
+			if !ok {
+				continue
+			}
+			stream, ok := core.GetStream(xobjects.Get(*name))
+			if !ok {
+				continue
+			}
+
+			subtype, _ := core.GetNameVal(stream.Get("Subtype"))
+			switch subtype {
+			case "Image":
+				// Images are drawn in the unit square mapped by the CTM.
+				width, _ := core.GetIntVal(stream.Get("Width"))
+				height, _ := core.GetIntVal(stream.Get("Height"))
+				wInch := math.Hypot(ctm[0], ctm[1]) / 72
+				hInch := math.Hypot(ctm[3], ctm[4]) / 72
+				if wInch == 0 || hInch == 0 {
+					continue
+				}
+				ppi := math.Max(float64(width)/wInch, float64(height)/hInch)
+				if ppi > ppis[stream] {
+					ppis[stream] = ppi
+				}
+			case "Form":
+				if _, ok := forms[stream]; ok {
+					continue
+				}
+				data, err := core.DecodeStream(stream)
+				if err != nil {
+					common.Log.Debug("ERROR: unable to decode form: %v", err)
+					continue
+				}
+				formCTM := ctm
+				if arr, ok := core.GetArray(stream.Get("Matrix")); ok {
+					if vals, err := arr.ToFloat64Array(); err == nil && len(vals) == 6 {
+						formCTM.Concat(transform.NewMatrix(vals[0], vals[1], vals[2], vals[3], vals[4], vals[5]))
+					}
+				}
+				formResources := resources
+				if res, ok := core.GetDict(stream.Get("Resources")); ok {
+					formResources = res
+				}
+				forms[stream] = struct{}{}
+				collectContentPPIs(string(data), formResources, formCTM, forms, ppis)
+				delete(forms, stream)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package optimize_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/optimize"
+)
+
+// makeTestImage returns a `width`x`height` gradient image with
+// `numComponents` components of `bpc` bits.
+func makeTestImage(width, height int64, numComponents, bpc int) *model.Image {
+	bytesPerSample := bpc / 8
+	data := make([]byte, 0, width*height*int64(numComponents*bytesPerSample))
+	for y := int64(0); y < height; y++ {
+		for x := int64(0); x < width; x++ {
+			for c := 0; c < numComponents; c++ {
+				data = append(data, byte(x+y))
+				if bytesPerSample == 2 {
+					data = append(data, 0xff)
+				}
+			}
+		}
+	}
+	return &model.Image{
+		Width:            width,
+		Height:           height,
+		BitsPerComponent: int64(bpc),
+		ColorComponents:  numComponents,
+		Data:             data,
+	}
+}
+
+func TestImageRecompress(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	page.Resources = model.NewPdfPageResources()
+
+	// 16-bit RGB image with a soft mask, placed at 200 PPI within a form.
+	ximg, err := model.NewXObjectImageFromImage(makeTestImage(200, 100, 3, 16), nil, core.NewFlateEncoder())
+	require.NoError(t, err)
+	smask, err := model.NewXObjectImageFromImage(makeTestImage(200, 100, 1, 8), nil, core.NewFlateEncoder())
+	require.NoError(t, err)
+	require.NoError(t, ximg.SetSoftMask(smask))
+
+	form := model.NewXObjectForm()
+	form.BBox = core.MakeArrayFromFloats([]float64{0, 0, 1, 1})
+	form.Matrix = core.MakeArrayFromFloats([]float64{2, 0, 0, 2, 0, 0})
+	form.Resources = model.NewPdfPageResources()
+	require.NoError(t, form.Resources.SetXObjectImageByName("Im1", ximg))
+	require.NoError(t, form.SetContentStream([]byte("/Im1 Do"), nil))
+	require.NoError(t, page.Resources.SetXObjectFormByName("Fm1", form))
+
+	// Image placed at 50 PPI.
+	low, err := model.NewXObjectImageFromImage(makeTestImage(50, 50, 1, 8), nil, core.NewFlateEncoder())
+	require.NoError(t, err)
+	require.NoError(t, page.Resources.SetXObjectImageByName("Im2", low))
+	require.NoError(t, page.AddContentStreamByString("q 36 0 0 18 100 100 cm /Fm1 Do Q q 72 0 0 72 0 0 cm /Im2 Do Q"))
+
+	w := model.NewPdfWriter()
+	w.SetOptimizer(optimize.New(optimize.Options{
+		RecompressImages: true,
+		ImageUpperPPI:    100,
+		ImageQuality:     90,
+	}))
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	page, err = r.GetPage(1)
+	require.NoError(t, err)
+
+	form, err = page.Resources.GetXObjectFormByName("Fm1")
+	require.NoError(t, err)
+	ximg, err = form.Resources.GetXObjectImageByName("Im1")
+	require.NoError(t, err)
+	require.Equal(t, int64(100), *ximg.Width)
+	require.Equal(t, int64(50), *ximg.Height)
+	require.Equal(t, int64(8), *ximg.BitsPerComponent)
+	require.IsType(t, &core.DCTEncoder{}, ximg.Filter)
+	img, err := ximg.ToImage()
+	require.NoError(t, err)
+	require.Len(t, img.Data, 100*50*3)
+
+	smask, err = ximg.GetSoftMask()
+	require.NoError(t, err)
+	require.NotNil(t, smask)
+	require.Equal(t, int64(100), *smask.Width)
+	require.Equal(t, int64(50), *smask.Height)
+	require.IsType(t, &core.FlateEncoder{}, smask.Filter)
+	mask, err := smask.ToImage()
+	require.NoError(t, err)
+	// Average of the samples 0, 1, 1 and 2 of the first 2x2 block.
+	require.Equal(t, byte(1), mask.Data[0])
+	require.Equal(t, byte(5), mask.Data[2])
+
+	// The low resolution image is not downsampled and re-encoding the
+	// smooth gradient with DCT does not reduce its size.
+	low, err = page.Resources.GetXObjectImageByName("Im2")
+	require.NoError(t, err)
+	require.Equal(t, int64(50), *low.Width)
+	require.IsType(t, &core.FlateEncoder{}, low.Filter)
+}
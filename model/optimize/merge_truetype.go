@@ -0,0 +1,237 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package optimize
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// sfntFont is a TrueType font program split in tables.
+type sfntFont struct {
+	version []byte
+	tables  map[string][]byte
+	// glyphs holds the outline data of each glyph, empty for the glyphs
+	// removed by subsetting.
+	glyphs [][]byte
+}
+
+// parseSfnt parses the tables and glyphs of the TrueType font program `data`.
+func parseSfnt(data []byte) (*sfntFont, error) {
+	if len(data) < 12 {
+		return nil, errors.New("font program too short")
+	}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+16*numTables {
+		return nil, errors.New("invalid table directory")
+	}
+	font := &sfntFont{version: data[:4], tables: make(map[string][]byte, numTables)}
+	for i := 0; i < numTables; i++ {
+		record := data[12+16*i:]
+		offset := int64(binary.BigEndian.Uint32(record[8:]))
+		length := int64(binary.BigEndian.Uint32(record[12:]))
+		if offset+length > int64(len(data)) {
+			return nil, fmt.Errorf("table %q out of range", record[:4])
+		}
+		font.tables[string(record[:4])] = data[offset : offset+length]
+	}
+
+	head, maxp := font.tables["head"], font.tables["maxp"]
+	loca, glyf := font.tables["loca"], font.tables["glyf"]
+	if len(head) < 54 || len(maxp) < 6 || loca == nil || glyf == nil {
+		return nil, errors.New("missing glyph tables")
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	isShort := binary.BigEndian.Uint16(head[50:]) == 0
+	offsets := make([]int, numGlyphs+1)
+	for i := range offsets {
+		if isShort {
+			if len(loca) < 2*i+2 {
+				return nil, errors.New("loca table too short")
+			}
+			offsets[i] = 2 * int(binary.BigEndian.Uint16(loca[2*i:]))
+		} else {
+			if len(loca) < 4*i+4 {
+				return nil, errors.New("loca table too short")
+			}
+			offsets[i] = int(binary.BigEndian.Uint32(loca[4*i:]))
+		}
+	}
+	font.glyphs = make([][]byte, numGlyphs)
+	for i := 0; i < numGlyphs; i++ {
+		if offsets[i] > offsets[i+1] || offsets[i+1] > len(glyf) {
+			return nil, errors.New("invalid glyph offsets")
+		}
+		font.glyphs[i] = glyf[offsets[i]:offsets[i+1]]
+	}
+	return font, nil
+}
+
+// mergeFontPrograms merges the glyphs of the TrueType font programs `a` and
+// `b`, subsets of the same font with the original glyph indices, for use by
+// composite fonts with identity glyph mapping.
+// Returns an error if the programs do not have the same tables and glyphs.
+func mergeFontPrograms(a, b []byte) ([]byte, error) {
+	fontA, err := parseSfnt(a)
+	if err != nil {
+		return nil, err
+	}
+	fontB, err := parseSfnt(b)
+	if err != nil {
+		return nil, err
+	}
+	// The tables depending on the number of glyphs are taken from the font
+	// with the most glyphs.
+	if len(fontB.glyphs) > len(fontA.glyphs) {
+		fontA, fontB = fontB, fontA
+	}
+
+	if len(fontA.tables) != len(fontB.tables) {
+		return nil, errors.New("different tables")
+	}
+	for tag, table := range fontA.tables {
+		other, ok := fontB.tables[tag]
+		if !ok {
+			return nil, fmt.Errorf("missing table %q", tag)
+		}
+		switch tag {
+		case "glyf", "loca", "maxp", "hmtx", "hhea":
+			continue
+		case "cmap", "post", "name":
+			// Not used with the identity glyph mapping of composite fonts.
+			continue
+		case "head":
+			// Ignore the checksum adjustment and the format of loca.
+			if len(table) != len(other) || !bytes.Equal(table[:8], other[:8]) ||
+				!bytes.Equal(table[12:50], other[12:50]) || !bytes.Equal(table[52:], other[52:]) {
+				return nil, errors.New("different head tables")
+			}
+			continue
+		}
+		if !bytes.Equal(table, other) {
+			return nil, fmt.Errorf("different %q tables", tag)
+		}
+	}
+
+	glyphs := make([][]byte, len(fontA.glyphs))
+	copy(glyphs, fontA.glyphs)
+	for i, glyph := range fontB.glyphs {
+		if len(glyph) == 0 {
+			continue
+		}
+		if len(glyphs[i]) == 0 {
+			glyphs[i] = glyph
+			continue
+		}
+		if !bytes.Equal(trimGlyphPadding(glyphs[i]), trimGlyphPadding(glyph)) {
+			return nil, fmt.Errorf("different glyph %d", i)
+		}
+	}
+	fontA.glyphs = glyphs
+	return fontA.write(), nil
+}
+
+// trimGlyphPadding returns the glyph data `glyph` without the padding
+// aligning the glyphs in the glyf table, of at most 3 bytes.
+func trimGlyphPadding(glyph []byte) []byte {
+	for i := 0; i < 3 && len(glyph) > 0 && glyph[len(glyph)-1] == 0; i++ {
+		glyph = glyph[:len(glyph)-1]
+	}
+	return glyph
+}
+
+// write returns the font program of `font`, with the glyph tables rebuilt
+// from the glyphs.
+func (font *sfntFont) write() []byte {
+	// Glyphs are aligned on 4 bytes with long loca offsets.
+	var glyf bytes.Buffer
+	loca := make([]byte, 4*(len(font.glyphs)+1))
+	for i, glyph := range font.glyphs {
+		binary.BigEndian.PutUint32(loca[4*i:], uint32(glyf.Len()))
+		glyf.Write(glyph)
+		for glyf.Len()%4 != 0 {
+			glyf.WriteByte(0)
+		}
+	}
+	binary.BigEndian.PutUint32(loca[4*len(font.glyphs):], uint32(glyf.Len()))
+
+	tables := make(map[string][]byte, len(font.tables))
+	for tag, table := range font.tables {
+		tables[tag] = table
+	}
+	tables["glyf"] = glyf.Bytes()
+	tables["loca"] = loca
+	head := append([]byte{}, tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0)
+	binary.BigEndian.PutUint16(head[50:], 1)
+	tables["head"] = head
+	maxp := append([]byte{}, tables["maxp"]...)
+	binary.BigEndian.PutUint16(maxp[4:], uint16(len(font.glyphs)))
+	tables["maxp"] = maxp
+
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	numTables := len(tags)
+	entrySelector := 0
+	for 1<<uint(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := 16 << uint(entrySelector)
+
+	var buf bytes.Buffer
+	buf.Write(font.version)
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint16(header[0:], uint16(numTables))
+	binary.BigEndian.PutUint16(header[2:], uint16(searchRange))
+	binary.BigEndian.PutUint16(header[4:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(header[6:], uint16(numTables*16-searchRange))
+	buf.Write(header)
+
+	offset := 12 + 16*numTables
+	records := make([]byte, 16*numTables)
+	for i, tag := range tags {
+		table := tables[tag]
+		record := records[16*i:]
+		copy(record, tag)
+		binary.BigEndian.PutUint32(record[4:], sfntChecksum(table))
+		binary.BigEndian.PutUint32(record[8:], uint32(offset))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(table)))
+		offset += (len(table) + 3) &^ 3
+	}
+	buf.Write(records)
+	headOffset := 0
+	for _, tag := range tags {
+		if tag == "head" {
+			headOffset = buf.Len()
+		}
+		buf.Write(tables[tag])
+		for buf.Len()%4 != 0 {
+			buf.WriteByte(0)
+		}
+	}
+
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[headOffset+8:], 0xB1B0AFBA-sfntChecksum(data))
+	return data
+}
+
+// sfntChecksum returns the checksum of the table `data`.
+func sfntChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
@@ -7,6 +7,7 @@ package optimize_test
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"testing"
@@ -210,3 +211,20 @@ endobj
 		t.Fatalf("len(optObjects) != 6 (%d)", len(optObjects))
 	}
 }
+
+// TestOptimizeContext tests that the optimization chain stops when its context is cancelled.
+func TestOptimizeContext(t *testing.T) {
+	chain := optimize.New(optimize.Options{CombineIdenticalIndirectObjects: true})
+	objects := []core.PdfObject{core.MakeIndirectObject(core.MakeInteger(1))}
+
+	optimized, err := chain.OptimizeContext(context.Background(), objects)
+	if err != nil || len(optimized) != 1 {
+		t.Fatalf("Error: %v (%d objects)", err, len(optimized))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := chain.OptimizeContext(ctx, objects); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -12,21 +12,37 @@ import (
 // New creates a optimizers chain from options.
 func New(options Options) *Chain {
 	chain := new(Chain)
+	if options.CombineFonts {
+		chain.Append(new(CombineFonts))
+	}
 	if options.CleanFonts || options.SubsetFonts {
 		chain.Append(&CleanFonts{Subset: options.SubsetFonts})
 	}
 	if options.CleanContentstream {
 		chain.Append(new(CleanContentstream))
 	}
-	if options.ImageUpperPPI > 0 {
-		imageOptimizer := new(ImagePPI)
-		imageOptimizer.ImageUpperPPI = options.ImageUpperPPI
-		chain.Append(imageOptimizer)
+	if options.CleanUnusedResources {
+		chain.Append(new(CleanUnusedResources))
 	}
-	if options.ImageQuality > 0 {
-		imageOptimizer := new(Image)
-		imageOptimizer.ImageQuality = options.ImageQuality
-		chain.Append(imageOptimizer)
+	if options.RecompressImages {
+		// Single pass reducing the bit depth, downsampling and re-encoding
+		// the images.
+		chain.Append(&ImageRecompress{
+			Quality:        options.ImageQuality,
+			UpperPPI:       options.ImageUpperPPI,
+			ReduceBitDepth: true,
+		})
+	} else {
+		if options.ImageUpperPPI > 0 {
+			imageOptimizer := new(ImagePPI)
+			imageOptimizer.ImageUpperPPI = options.ImageUpperPPI
+			chain.Append(imageOptimizer)
+		}
+		if options.ImageQuality > 0 {
+			imageOptimizer := new(Image)
+			imageOptimizer.ImageQuality = options.ImageQuality
+			chain.Append(imageOptimizer)
+		}
 	}
 	if options.CombineDuplicateDirectObjects {
 		chain.Append(new(CombineDuplicateDirectObjects))
@@ -37,6 +53,9 @@ func New(options Options) *Chain {
 	if options.CombineIdenticalIndirectObjects {
 		chain.Append(new(CombineIdenticalIndirectObjects))
 	}
+	if options.CombineDuplicateObjects {
+		chain.Append(new(CombineDuplicateObjects))
+	}
 	if options.UseObjectStreams {
 		chain.Append(new(ObjectStreams))
 	}
@@ -8,13 +8,17 @@ package optimize
 // Options describes PDF optimization parameters.
 type Options struct {
 	CombineDuplicateStreams         bool
+	CombineDuplicateObjects         bool
 	CombineDuplicateDirectObjects   bool
 	ImageUpperPPI                   float64
 	ImageQuality                    int
+	RecompressImages                bool
 	UseObjectStreams                bool
 	CombineIdenticalIndirectObjects bool
 	CompressStreams                 bool
 	CleanFonts                      bool
+	CombineFonts                    bool
 	SubsetFonts                     bool
 	CleanContentstream              bool
+	CleanUnusedResources            bool
 }
@@ -11,6 +11,53 @@ import (
 	"github.com/unidoc/unipdf/v3/core"
 )
 
+// findCatalog returns the catalog dictionary of the document in `objects`, or
+// nil if not found.
+func findCatalog(objects []core.PdfObject) *core.PdfObjectDictionary {
+	for _, obj := range objects {
+		dict, ok := core.GetDict(obj)
+		if !ok {
+			continue
+		}
+		if typ, _ := core.GetNameVal(dict.Get("Type")); typ == "Catalog" {
+			return dict
+		}
+	}
+	return nil
+}
+
+// walkPages calls `visit` for each page of the page tree node `obj`, with the
+// resources of the page, inherited from its ancestors if not set. The page
+// tree nodes are added to `visited` and the nodes already visited are skipped,
+// which prevents infinite recursion in invalid page trees.
+func walkPages(obj core.PdfObject, visited map[*core.PdfObjectDictionary]struct{},
+	visit func(page, resources *core.PdfObjectDictionary)) {
+	var walk func(obj core.PdfObject, parentResources *core.PdfObjectDictionary)
+	walk = func(obj core.PdfObject, parentResources *core.PdfObjectDictionary) {
+		node, ok := core.GetDict(obj)
+		if !ok {
+			return
+		}
+		if _, ok := visited[node]; ok {
+			return
+		}
+		visited[node] = struct{}{}
+
+		resources := parentResources
+		if res, ok := core.GetDict(node.Get("Resources")); ok {
+			resources = res
+		}
+		if kids, ok := core.GetArray(node.Get("Kids")); ok {
+			for _, kid := range kids.Elements() {
+				walk(kid, resources)
+			}
+			return
+		}
+		visit(node, resources)
+	}
+	walk(obj, nil)
+}
+
 type objectStructure struct {
 	catalogDict *core.PdfObjectDictionary
 	pagesDict   *core.PdfObjectDictionary
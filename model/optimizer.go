@@ -6,6 +6,8 @@
 package model
 
 import (
+	"context"
+
 	"github.com/unidoc/unipdf/v3/core"
 )
 
@@ -16,3 +18,12 @@ import (
 type Optimizer interface {
 	Optimize(objects []core.PdfObject) ([]core.PdfObject, error)
 }
+
+// ContextOptimizer is implemented by optimizers which can be cancelled.
+//
+// OptimizeContext optimizes `objects` as Optimize, stopping with the error of `ctx` when it is
+// cancelled or its deadline is exceeded.
+type ContextOptimizer interface {
+	Optimizer
+	OptimizeContext(ctx context.Context, objects []core.PdfObject) ([]core.PdfObject, error)
+}
@@ -158,7 +158,10 @@ func (od OutlineDest) ToPdfObject() core.PdfObject {
 }
 
 // Outline represents a PDF outline dictionary (Table 152 - p. 376).
-// Currently, the Outline object can only be used to construct PDF outlines.
+// The outline of an existing document can be loaded using the GetOutlines
+// method of the PdfReader. The outline can be modified and written using the
+// AddOutlineTree method of the PdfWriter or the SetOutlineTree method of the
+// creator.
 type Outline struct {
 	Entries []*OutlineItem `json:"entries,omitempty"`
 }
@@ -184,6 +187,14 @@ func (o *Outline) Insert(index uint, item *OutlineItem) {
 	o.Entries = append(o.Entries[:index], append([]*OutlineItem{item}, o.Entries[index:]...)...)
 }
 
+// Remove removes the top level outline item at the specified index.
+func (o *Outline) Remove(index uint) {
+	if index >= uint(len(o.Entries)) {
+		return
+	}
+	o.Entries = append(o.Entries[:index], o.Entries[index+1:]...)
+}
+
 // Items returns all children outline items.
 func (o *Outline) Items() []*OutlineItem {
 	return o.Entries
@@ -210,7 +221,9 @@ func (o *Outline) ToPdfOutline() *PdfOutline {
 		}
 
 		outlineItems = append(outlineItems, outlineItem)
-		lenDescendants += lenChildren
+		if !item.Closed {
+			lenDescendants += lenChildren
+		}
 		prev = outlineItem
 	}
 
@@ -238,11 +251,37 @@ func (o *Outline) ToPdfObject() core.PdfObject {
 	return o.ToPdfOutline().ToPdfObject()
 }
 
+// OutlineItemFlag represents the style flags of an outline item title
+// (Table 154 - p. 377).
+type OutlineItemFlag int
+
+const (
+	// OutlineItemFlagItalic displays the item title in italic.
+	OutlineItemFlagItalic OutlineItemFlag = 1 << iota
+
+	// OutlineItemFlagBold displays the item title in bold.
+	OutlineItemFlagBold
+)
+
 // OutlineItem represents a PDF outline item dictionary (Table 153 - pp. 376 - 377).
 type OutlineItem struct {
 	Title   string         `json:"title"`
 	Dest    OutlineDest    `json:"dest"`
 	Entries []*OutlineItem `json:"entries,omitempty"`
+
+	// Action is the action performed when the item is activated. If set,
+	// the action is used instead of the destination of the item.
+	Action *PdfAction `json:"-"`
+
+	// Color is the color of the item title. The default color is black.
+	Color *PdfColorDeviceRGB `json:"color,omitempty"`
+
+	// Flags specifies the style of the item title.
+	Flags OutlineItemFlag `json:"flags,omitempty"`
+
+	// Closed specifies whether the children of the item are hidden
+	// when the document is opened.
+	Closed bool `json:"closed,omitempty"`
 }
 
 // NewOutlineItem returns a new outline item instance.
@@ -269,18 +308,37 @@ func (oi *OutlineItem) Insert(index uint, item *OutlineItem) {
 	oi.Entries = append(oi.Entries[:index], append([]*OutlineItem{item}, oi.Entries[index:]...)...)
 }
 
+// Remove removes the child outline item at the specified index.
+func (oi *OutlineItem) Remove(index uint) {
+	if index >= uint(len(oi.Entries)) {
+		return
+	}
+	oi.Entries = append(oi.Entries[:index], oi.Entries[index+1:]...)
+}
+
 // Items returns all children outline items.
 func (oi *OutlineItem) Items() []*OutlineItem {
 	return oi.Entries
 }
 
 // ToPdfOutlineItem returns a low level PdfOutlineItem object,
-// based on the current instance.
+// based on the current instance. The returned count is the number of
+// descendants of the item which are visible when the item is open.
 func (oi *OutlineItem) ToPdfOutlineItem() (*PdfOutlineItem, int64) {
 	// Create outline item.
 	currItem := NewPdfOutlineItem()
 	currItem.Title = core.MakeEncodedString(oi.Title, true)
-	currItem.Dest = oi.Dest.ToPdfObject()
+	if oi.Action != nil && oi.Action.context != nil {
+		currItem.A = oi.Action.context.ToPdfObject()
+	} else {
+		currItem.Dest = oi.Dest.ToPdfObject()
+	}
+	if oi.Color != nil {
+		currItem.C = core.MakeArrayFromFloats(oi.Color[:])
+	}
+	if oi.Flags != 0 {
+		currItem.F = core.MakeInteger(int64(oi.Flags))
+	}
 
 	// Create outline items.
 	var outlineItems []*PdfOutlineItem
@@ -297,7 +355,9 @@ func (oi *OutlineItem) ToPdfOutlineItem() (*PdfOutlineItem, int64) {
 		}
 
 		outlineItems = append(outlineItems, outlineItem)
-		lenDescendants += lenChildren
+		if !item.Closed {
+			lenDescendants += lenChildren
+		}
 		prev = outlineItem
 	}
 
@@ -308,7 +368,13 @@ func (oi *OutlineItem) ToPdfOutlineItem() (*PdfOutlineItem, int64) {
 	if lenOutlineItems > 0 {
 		currItem.First = &outlineItems[0].PdfOutlineTreeNode
 		currItem.Last = &outlineItems[lenOutlineItems-1].PdfOutlineTreeNode
-		currItem.Count = &lenDescendants
+
+		// The count of closed items is negative.
+		count := lenDescendants
+		if oi.Closed {
+			count = -count
+		}
+		currItem.Count = &count
 	}
 
 	return currItem, lenDescendants
@@ -319,3 +385,15 @@ func (oi *OutlineItem) ToPdfObject() core.PdfObject {
 	outlineItem, _ := oi.ToPdfOutlineItem()
 	return outlineItem.ToPdfObject()
 }
+
+// SetOutline sets the outline (bookmarks) of the document in the new
+// revision, replacing the outline of the original document. The outline is
+// removed if `outline` is nil. The destinations of the outline items must
+// reference the pages of the document.
+func (a *PdfAppender) SetOutline(outline *Outline) {
+	if outline == nil {
+		a.setCatalogEntry("Outlines", nil)
+		return
+	}
+	a.setCatalogEntry("Outlines", outline.ToPdfObject())
+}
@@ -13,6 +13,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
 )
 
 func TestGetOutlines(t *testing.T) {
@@ -71,3 +73,92 @@ func TestGetOutlines(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, srcJson, dstJson)
 }
+
+func TestOutlineReadWrite(t *testing.T) {
+	page1 := NewPdfPage()
+	page1.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+	page2 := NewPdfPage()
+	page2.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+
+	uriAction := NewPdfActionURI()
+	uriAction.URI = core.MakeString("https://example.com")
+
+	// Build outline.
+	outline := NewOutline()
+	chapter := NewOutlineItem("Chapter 1", NewPageDestFit(page1))
+	chapter.Color = NewPdfColorDeviceRGB(1, 0, 0)
+	chapter.Flags = OutlineItemFlagBold | OutlineItemFlagItalic
+	chapter.Closed = true
+	chapter.Add(NewOutlineItem("Section 1.1", NewPageDestXYZ(page1, 0, 500, 0)))
+	chapter.Add(NewOutlineItem("Section 1.2", NewPageDestFitH(page2, 300)))
+	outline.Add(chapter)
+
+	website := NewOutlineItem("Website", OutlineDest{})
+	website.Action = uriAction.PdfAction
+	outline.Add(website)
+
+	pdfOutline := outline.ToPdfOutline()
+	require.Equal(t, int64(2), *pdfOutline.Count)
+	chapterItem := pdfOutline.First.GetContext().(*PdfOutlineItem)
+	require.Equal(t, int64(-2), *chapterItem.Count)
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page1))
+	require.NoError(t, w.AddPage(page2))
+	w.AddOutlineTree(outline.ToOutlineTree())
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	// Read outline.
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	readOutline, err := r.GetOutlines()
+	require.NoError(t, err)
+	require.Len(t, readOutline.Entries, 2)
+
+	readChapter := readOutline.Entries[0]
+	require.Equal(t, "Chapter 1", readChapter.Title)
+	require.Equal(t, &PdfColorDeviceRGB{1, 0, 0}, readChapter.Color)
+	require.Equal(t, OutlineItemFlagBold|OutlineItemFlagItalic, readChapter.Flags)
+	require.True(t, readChapter.Closed)
+	require.Equal(t, "Fit", readChapter.Dest.Mode)
+	require.Len(t, readChapter.Entries, 2)
+	require.Equal(t, "Section 1.2", readChapter.Entries[1].Title)
+	require.Equal(t, int64(1), readChapter.Entries[1].Dest.Page)
+	require.Equal(t, 300.0, readChapter.Entries[1].Dest.Y)
+	require.False(t, readChapter.Entries[1].Closed)
+
+	readWebsite := readOutline.Entries[1]
+	require.NotNil(t, readWebsite.Action)
+	readURI, ok := readWebsite.Action.GetContext().(*PdfActionURI)
+	require.True(t, ok)
+	require.Equal(t, "https://example.com", readURI.URI.(*core.PdfObjectString).Str())
+
+	// Modify outline and write it along with the read pages.
+	readChapter.Remove(0)
+	readChapter.Closed = false
+	readChapter.Entries[0].Title = "Section 1"
+	readOutline.Remove(1)
+
+	w = NewPdfWriter()
+	for i := 1; i <= 2; i++ {
+		page, err := r.GetPage(i)
+		require.NoError(t, err)
+		require.NoError(t, w.AddPage(page))
+	}
+	w.AddOutlineTree(readOutline.ToOutlineTree())
+
+	buf.Reset()
+	require.NoError(t, w.Write(&buf))
+
+	r, err = NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	readOutline, err = r.GetOutlines()
+	require.NoError(t, err)
+	require.Len(t, readOutline.Entries, 1)
+	require.False(t, readOutline.Entries[0].Closed)
+	require.Len(t, readOutline.Entries[0].Entries, 1)
+	require.Equal(t, "Section 1", readOutline.Entries[0].Entries[0].Title)
+	require.Equal(t, int64(1), readOutline.Entries[0].Entries[0].Dest.Page)
+}
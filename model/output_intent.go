@@ -0,0 +1,179 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// PdfOutputIntentType represents the subtype of an output intent, which
+// specifies the standard the intent refers to (Table 365 - p. 633).
+type PdfOutputIntentType string
+
+// Output intent subtypes.
+const (
+	PdfOutputIntentTypePDFA  PdfOutputIntentType = "GTS_PDFA1" // PDF/A.
+	PdfOutputIntentTypePDFX  PdfOutputIntentType = "GTS_PDFX"  // PDF/X.
+	PdfOutputIntentTypePDFE1 PdfOutputIntentType = "ISO_PDFE1" // PDF/E-1.
+)
+
+// PdfOutputIntent represents an output intent, describing the color
+// characteristics of the intended output device.
+// See section 14.11.5 "Output Intents" (p. 632 PDF32000_2008).
+type PdfOutputIntent struct {
+	Type PdfOutputIntentType // Output intent subtype (S, Required).
+
+	// OutputCondition is a human readable description of the output condition.
+	OutputCondition string
+
+	// OutputConditionIdentifier identifies the output condition, e.g. a
+	// characterization name from the ICC registry such as "FOGRA39" (Required).
+	OutputConditionIdentifier string
+
+	// RegistryName is the registry of the condition identifier, e.g.
+	// "http://www.color.org".
+	RegistryName string
+
+	// Info describes the output condition, required if no profile is set.
+	Info string
+
+	// DestOutputProfile is the ICC profile of the output device. Required for
+	// PDF/A and for PDF/X conditions not in the registry.
+	DestOutputProfile *ICCProfile
+
+	profileStream *core.PdfObjectStream
+}
+
+// NewPdfOutputIntent returns a new output intent of type `typ` for the output
+// condition `identifier`, described by `profile`.
+func NewPdfOutputIntent(typ PdfOutputIntentType, identifier string, profile *ICCProfile) *PdfOutputIntent {
+	intent := &PdfOutputIntent{
+		Type:                      typ,
+		OutputConditionIdentifier: identifier,
+		DestOutputProfile:         profile,
+	}
+	if profile != nil {
+		intent.Info = profile.Description
+	}
+	return intent
+}
+
+// newPdfOutputIntentFromDict loads an output intent from `dict`.
+func newPdfOutputIntentFromDict(dict *core.PdfObjectDictionary) (*PdfOutputIntent, error) {
+	typ, ok := core.GetNameVal(dict.Get("S"))
+	if !ok {
+		return nil, errors.New("output intent missing S")
+	}
+
+	intent := &PdfOutputIntent{Type: PdfOutputIntentType(typ)}
+	if str, ok := core.GetString(dict.Get("OutputCondition")); ok {
+		intent.OutputCondition = str.Decoded()
+	}
+	if str, ok := core.GetString(dict.Get("OutputConditionIdentifier")); ok {
+		intent.OutputConditionIdentifier = str.Decoded()
+	}
+	if str, ok := core.GetString(dict.Get("RegistryName")); ok {
+		intent.RegistryName = str.Decoded()
+	}
+	if str, ok := core.GetString(dict.Get("Info")); ok {
+		intent.Info = str.Decoded()
+	}
+
+	if stream, ok := core.GetStream(dict.Get("DestOutputProfile")); ok {
+		data, err := core.DecodeStream(stream)
+		if err != nil {
+			return nil, err
+		}
+		profile, err := NewICCProfile(data)
+		if err != nil {
+			return nil, err
+		}
+		if n, ok := core.GetIntVal(stream.Get("N")); ok && n != profile.NumComponents() {
+			common.Log.Debug("Output intent profile N mismatch (%d != %d)", n, profile.NumComponents())
+		}
+		intent.DestOutputProfile = profile
+		intent.profileStream = stream
+	}
+	return intent, nil
+}
+
+// ToPdfObject returns the output intent dictionary.
+func (oi *PdfOutputIntent) ToPdfObject() core.PdfObject {
+	dict := core.MakeDict()
+	dict.Set("Type", core.MakeName("OutputIntent"))
+	dict.Set("S", core.MakeName(string(oi.Type)))
+	if oi.OutputCondition != "" {
+		dict.Set("OutputCondition", core.MakeString(oi.OutputCondition))
+	}
+	dict.Set("OutputConditionIdentifier", core.MakeString(oi.OutputConditionIdentifier))
+	if oi.RegistryName != "" {
+		dict.Set("RegistryName", core.MakeString(oi.RegistryName))
+	}
+	if oi.Info != "" {
+		dict.Set("Info", core.MakeString(oi.Info))
+	}
+
+	if profile := oi.DestOutputProfile; profile != nil {
+		if oi.profileStream == nil {
+			stream, err := core.MakeStream(profile.Data, core.NewFlateEncoder())
+			if err != nil {
+				common.Log.Debug("ERROR: unable to encode output intent profile: %v", err)
+				return dict
+			}
+			stream.Set("N", core.MakeInteger(int64(profile.NumComponents())))
+			oi.profileStream = stream
+		}
+		dict.Set("DestOutputProfile", oi.profileStream)
+	}
+	return dict
+}
+
+// GetOutputIntents returns the output intents of the document.
+func (r *PdfReader) GetOutputIntents() ([]*PdfOutputIntent, error) {
+	arr, ok := core.GetArray(r.catalog.Get("OutputIntents"))
+	if !ok {
+		return nil, nil
+	}
+
+	var intents []*PdfOutputIntent
+	for _, obj := range arr.Elements() {
+		dict, ok := core.GetDict(obj)
+		if !ok {
+			common.Log.Debug("Output intent not a dictionary (%T)", obj)
+			continue
+		}
+		intent, err := newPdfOutputIntentFromDict(dict)
+		if err != nil {
+			return nil, err
+		}
+		intents = append(intents, intent)
+	}
+	return intents, nil
+}
+
+// AddOutputIntent adds an output intent to the output document.
+func (w *PdfWriter) AddOutputIntent(intent *PdfOutputIntent) error {
+	if intent == nil {
+		return errors.New("nil output intent")
+	}
+	if intent.OutputConditionIdentifier == "" {
+		return errors.New("output intent missing OutputConditionIdentifier")
+	}
+
+	arr, ok := core.GetArray(w.catalog.Get("OutputIntents"))
+	if !ok {
+		arr = core.MakeArray()
+		w.catalog.Set("OutputIntents", arr)
+	}
+	obj := intent.ToPdfObject()
+	arr.Append(obj)
+
+	common.Log.Trace("Adding catalog OutputIntent...")
+	return w.addObjects(obj)
+}
@@ -86,7 +86,7 @@ func (p *PdfPage) setContainer(container *core.PdfIndirectObject) {
 func (p *PdfPage) Duplicate() *PdfPage {
 	var dup PdfPage
 	dup = *p
-	dup.pageDict = core.MakeDict()
+	dup.pageDict = core.MakeDict().Merge(p.pageDict)
 	dup.primitive = core.MakeIndirectObject(dup.pageDict)
 
 	return &dup
@@ -437,9 +437,30 @@ func (p *PdfPage) getParentResources() (*PdfPageResources, error) {
 	return nil, nil
 }
 
+// pageDictKeys are the page dictionary entries which are modeled by PdfPage.
+var pageDictKeys = map[core.PdfObjectName]struct{}{
+	"Type": {}, "Parent": {}, "LastModified": {}, "Resources": {}, "CropBox": {}, "MediaBox": {},
+	"BleedBox": {}, "TrimBox": {}, "ArtBox": {}, "BoxColorInfo": {}, "Contents": {}, "Rotate": {},
+	"Group": {}, "Thumb": {}, "B": {}, "Dur": {}, "Trans": {}, "AA": {}, "Metadata": {},
+	"PieceInfo": {}, "StructParents": {}, "ID": {}, "PZ": {}, "SeparationInfo": {}, "Tabs": {},
+	"TemplateInstantiated": {}, "PresSteps": {}, "UserUnit": {}, "VP": {}, "Annots": {},
+}
+
 // GetPageDict converts the Page to a PDF object dictionary.
+// The entries of the page dictionary which are not modeled by PdfPage, e.g.
+// vendor specific entries or the AF entry of PDF 2.0, are kept unchanged.
 func (p *PdfPage) GetPageDict() *core.PdfObjectDictionary {
 	d := p.pageDict
+
+	// Keep the entries which are not modeled.
+	var extra []core.PdfObjectName
+	extraVals := map[core.PdfObjectName]core.PdfObject{}
+	for _, key := range d.Keys() {
+		if _, ok := pageDictKeys[key]; !ok {
+			extra = append(extra, key)
+			extraVals[key] = d.Get(key)
+		}
+	}
 	d.Clear()
 	d.Set("Type", core.MakeName("Page"))
 	d.Set("Parent", p.Parent)
@@ -508,6 +529,9 @@ func (p *PdfPage) GetPageDict() *core.PdfObjectDictionary {
 		d.SetIfNotNil("Annots", p.Annots)
 	}
 
+	for _, key := range extra {
+		d.Set(key, extraVals[key])
+	}
 	return d
 }
 
@@ -528,6 +552,44 @@ func (p *PdfPage) ToPdfObject() core.PdfObject {
 	return container
 }
 
+// SetThumbnail sets the thumbnail image of the page (Thumb), used by
+// viewers for navigation, to `img`. The image must have 1 (gray) or 3 (RGB)
+// color components and its alpha channel, if any, is ignored.
+// If `encoder` is nil, the image is flate encoded.
+func (p *PdfPage) SetThumbnail(img *Image, encoder core.StreamEncoder) error {
+	if img == nil {
+		return errors.New("thumbnail image cannot be nil")
+	}
+	if img.ColorComponents != 1 && img.ColorComponents != 3 {
+		return fmt.Errorf("unsupported thumbnail color components: %d", img.ColorComponents)
+	}
+	if encoder == nil {
+		encoder = core.NewFlateEncoder()
+	}
+
+	ximg, err := NewXObjectImageFromImage(img, nil, encoder)
+	if err != nil {
+		return err
+	}
+	// Thumbnails cannot have soft masks.
+	ximg.SMask = nil
+	p.Thumb = ximg.ToPdfObject()
+	return nil
+}
+
+// GetThumbnail returns the thumbnail image of the page, or nil if the page
+// has no thumbnail.
+func (p *PdfPage) GetThumbnail() (*XObjectImage, error) {
+	if p.Thumb == nil {
+		return nil, nil
+	}
+	stream, ok := core.GetStream(p.Thumb)
+	if !ok {
+		return nil, core.ErrTypeError
+	}
+	return NewXObjectImageFromStream(stream)
+}
+
 // AddImageResource adds an image to the XObject resources.
 func (p *PdfPage) AddImageResource(name core.PdfObjectName, ximg *XObjectImage) error {
 	var xresDict *core.PdfObjectDictionary
@@ -819,14 +881,14 @@ func (p *PdfPage) SetContentStreams(cStreams []string, encoder core.StreamEncode
 	return nil
 }
 
-func getContentStreamAsString(cstreamObj core.PdfObject) (string, error) {
+func (p *PdfPage) getContentStreamAsString(cstreamObj core.PdfObject) (string, error) {
 	cstreamObj = core.TraceToDirectObject(cstreamObj)
 
 	switch v := cstreamObj.(type) {
 	case *core.PdfObjectString:
 		return v.Str(), nil
 	case *core.PdfObjectStream:
-		buf, err := core.DecodeStream(v)
+		buf, err := p.decodeStream(v)
 		if err != nil {
 			return "", err
 		}
@@ -836,6 +898,15 @@ func getContentStreamAsString(cstreamObj core.PdfObject) (string, error) {
 	return "", fmt.Errorf("invalid content stream object holder (%T)", cstreamObj)
 }
 
+// decodeStream decodes `stream` with the caches of the reader of the page, if
+// any (see PdfReader.SetCacheOptions).
+func (p *PdfPage) decodeStream(stream *core.PdfObjectStream) ([]byte, error) {
+	if p.reader != nil && p.reader.parser != nil {
+		return p.reader.parser.DecodeStream(stream)
+	}
+	return core.DecodeStream(stream)
+}
+
 // GetContentStreams returns the content stream as an array of strings.
 func (p *PdfPage) GetContentStreams() ([]string, error) {
 	if p.Contents == nil {
@@ -852,7 +923,7 @@ func (p *PdfPage) GetContentStreams() ([]string, error) {
 
 	var cStreams []string
 	for _, cStreamObj := range cStreamObjs {
-		cStreamStr, err := getContentStreamAsString(cStreamObj)
+		cStreamStr, err := p.getContentStreamAsString(cStreamObj)
 		if err != nil {
 			return nil, err
 		}
@@ -871,6 +942,34 @@ func (p *PdfPage) GetAllContentStreams() (string, error) {
 	return strings.Join(cstreams, " "), nil
 }
 
+// ToXObjectForm returns a Form XObject containing the content of the page,
+// which can be drawn on other pages. The bounding box of the form is the
+// crop box of the page and the form uses the resources of the page. The
+// annotations and the rotation of the page are not taken into account.
+func (p *PdfPage) ToXObjectForm() (*XObjectForm, error) {
+	content, err := p.GetAllContentStreams()
+	if err != nil {
+		return nil, err
+	}
+	bbox, err := p.GetCropBox()
+	if err != nil {
+		return nil, err
+	}
+
+	xform := NewXObjectForm()
+	xform.FormType = core.MakeInteger(1)
+	xform.BBox = bbox.ToPdfObject()
+	xform.Resources = p.Resources
+	if xform.Resources == nil {
+		xform.Resources = NewPdfPageResources()
+	}
+	xform.Group = p.Group
+	if err := xform.SetContentStream([]byte(content), core.NewFlateEncoder()); err != nil {
+		return nil, err
+	}
+	return xform, nil
+}
+
 // PdfPageResourcesColorspaces contains the colorspace in the PdfPageResources.
 // Needs to have matching name and colorspace map entry. The Names define the order.
 type PdfPageResourcesColorspaces struct {
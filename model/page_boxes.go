@@ -0,0 +1,277 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// maxPageTreeDepth is the maximum depth of the page tree traversed when
+// looking for inherited page attributes.
+const maxPageTreeDepth = 64
+
+// Unit conversion factors.
+const (
+	// PointsPerInch is the number of points (default user space units) per
+	// inch.
+	PointsPerInch = 72.0
+
+	// PointsPerMillimeter is the number of points (default user space units)
+	// per millimeter.
+	PointsPerMillimeter = PointsPerInch / 25.4
+)
+
+// InchesToPoints converts a length in inches to points.
+func InchesToPoints(inches float64) float64 {
+	return inches * PointsPerInch
+}
+
+// PointsToInches converts a length in points to inches.
+func PointsToInches(points float64) float64 {
+	return points / PointsPerInch
+}
+
+// MillimetersToPoints converts a length in millimeters to points.
+func MillimetersToPoints(mm float64) float64 {
+	return mm * PointsPerMillimeter
+}
+
+// PointsToMillimeters converts a length in points to millimeters.
+func PointsToMillimeters(points float64) float64 {
+	return points / PointsPerMillimeter
+}
+
+// PageBox represents one of the page boundaries (section 14.11.2 p. 643
+// PDF32000_2008).
+type PageBox string
+
+// Page boundaries.
+const (
+	PageBoxMedia PageBox = "MediaBox"
+	PageBoxCrop  PageBox = "CropBox"
+	PageBoxBleed PageBox = "BleedBox"
+	PageBoxTrim  PageBox = "TrimBox"
+	PageBoxArt   PageBox = "ArtBox"
+)
+
+// Normalized returns a copy of the rectangle whose lower left corner is
+// located below and to the left of its upper right corner.
+func (rect *PdfRectangle) Normalized() *PdfRectangle {
+	return &PdfRectangle{
+		Llx: math.Min(rect.Llx, rect.Urx),
+		Lly: math.Min(rect.Lly, rect.Ury),
+		Urx: math.Max(rect.Llx, rect.Urx),
+		Ury: math.Max(rect.Lly, rect.Ury),
+	}
+}
+
+// Intersect returns the intersection of the rectangle with the rectangle
+// `other`. The last return value is false if the rectangles do not overlap.
+func (rect *PdfRectangle) Intersect(other *PdfRectangle) (*PdfRectangle, bool) {
+	a, b := rect.Normalized(), other.Normalized()
+	r := &PdfRectangle{
+		Llx: math.Max(a.Llx, b.Llx),
+		Lly: math.Max(a.Lly, b.Lly),
+		Urx: math.Min(a.Urx, b.Urx),
+		Ury: math.Min(a.Ury, b.Ury),
+	}
+	if r.Llx > r.Urx || r.Lly > r.Ury {
+		return nil, false
+	}
+	return r, true
+}
+
+// getInheritedAttribute looks up the value of the inheritable page attribute
+// `key` in the ancestors of the page. It returns nil if the attribute is not
+// set by any ancestor.
+func (p *PdfPage) getInheritedAttribute(key core.PdfObjectName) (core.PdfObject, error) {
+	node := p.Parent
+	for depth := 0; node != nil; depth++ {
+		if depth > maxPageTreeDepth {
+			return nil, errors.New("page tree too deep")
+		}
+		dict, ok := core.GetDict(node)
+		if !ok {
+			return nil, errors.New("invalid parent objects dictionary")
+		}
+		if obj := dict.Get(key); obj != nil {
+			return obj, nil
+		}
+		node = dict.Get("Parent")
+	}
+	return nil, nil
+}
+
+// GetCropBox returns the crop box of the page, either set by the page or
+// inherited from the page tree. The crop box defaults to the media box and
+// is reduced to its intersection with the media box.
+func (p *PdfPage) GetCropBox() (*PdfRectangle, error) {
+	mbox, err := p.GetMediaBox()
+	if err != nil {
+		return nil, err
+	}
+
+	cbox := p.CropBox
+	if cbox == nil {
+		obj, err := p.getInheritedAttribute("CropBox")
+		if err != nil {
+			return nil, err
+		}
+		if obj == nil {
+			return mbox.Normalized(), nil
+		}
+		arr, ok := core.GetArray(obj)
+		if !ok {
+			return nil, errors.New("invalid crop box")
+		}
+		if cbox, err = NewPdfRectangle(*arr); err != nil {
+			return nil, err
+		}
+	}
+
+	rect, ok := cbox.Intersect(mbox)
+	if !ok {
+		return nil, errors.New("crop box outside of media box")
+	}
+	return rect, nil
+}
+
+// GetBox returns the effective value of the page boundary `box`, taking into
+// account the inherited attributes and the default values: the crop box
+// defaults to the media box and the bleed, trim and art boxes default to the
+// crop box. The boxes are reduced to their intersection with the media box.
+func (p *PdfPage) GetBox(box PageBox) (*PdfRectangle, error) {
+	var rect *PdfRectangle
+	switch box {
+	case PageBoxMedia:
+		mbox, err := p.GetMediaBox()
+		if err != nil {
+			return nil, err
+		}
+		return mbox.Normalized(), nil
+	case PageBoxCrop:
+		return p.GetCropBox()
+	case PageBoxBleed:
+		rect = p.BleedBox
+	case PageBoxTrim:
+		rect = p.TrimBox
+	case PageBoxArt:
+		rect = p.ArtBox
+	default:
+		return nil, fmt.Errorf("invalid page box %q", box)
+	}
+
+	if rect == nil {
+		return p.GetCropBox()
+	}
+	mbox, err := p.GetMediaBox()
+	if err != nil {
+		return nil, err
+	}
+	clipped, ok := rect.Intersect(mbox)
+	if !ok {
+		return nil, fmt.Errorf("%s outside of media box", box)
+	}
+	return clipped, nil
+}
+
+// SetBox sets the page boundary `box` of the page. The boundary is removed
+// from the page if `rect` is nil, in which case the default value is used.
+// The media box cannot be removed.
+func (p *PdfPage) SetBox(box PageBox, rect *PdfRectangle) error {
+	if rect != nil {
+		rect = rect.Normalized()
+	}
+
+	switch box {
+	case PageBoxMedia:
+		if rect == nil {
+			return errors.New("media box cannot be removed")
+		}
+		p.MediaBox = rect
+	case PageBoxCrop:
+		p.CropBox = rect
+	case PageBoxBleed:
+		p.BleedBox = rect
+	case PageBoxTrim:
+		p.TrimBox = rect
+	case PageBoxArt:
+		p.ArtBox = rect
+	default:
+		return fmt.Errorf("invalid page box %q", box)
+	}
+	return nil
+}
+
+// GetRotate returns the rotation of the page in degrees, either set by the
+// page or inherited from the page tree. The returned value is normalized
+// to 0, 90, 180 or 270.
+func (p *PdfPage) GetRotate() (int64, error) {
+	var rotate int64
+	if p.Rotate != nil {
+		rotate = *p.Rotate
+	} else {
+		obj, err := p.getInheritedAttribute("Rotate")
+		if err != nil {
+			return 0, err
+		}
+		if obj != nil {
+			val, ok := core.GetIntVal(obj)
+			if !ok {
+				return 0, errors.New("invalid page rotation")
+			}
+			rotate = int64(val)
+		}
+	}
+
+	rotate %= 360
+	if rotate < 0 {
+		rotate += 360
+	}
+	return rotate, nil
+}
+
+// GetUserUnit returns the size of the default user space units of the page,
+// in multiples of 1/72 inch. The default value is 1.
+func (p *PdfPage) GetUserUnit() float64 {
+	if p.UserUnit == nil {
+		return 1
+	}
+	unit, err := core.GetNumberAsFloat(core.TraceToDirectObject(p.UserUnit))
+	if err != nil || unit <= 0 {
+		return 1
+	}
+	return unit
+}
+
+// ApplyCropBox crops the page permanently: the media box of the page is
+// replaced with its effective crop box and the crop box is removed. The
+// bleed, trim and art boxes are reduced to their intersection with the new
+// media box, or removed if they do not overlap it.
+func (p *PdfPage) ApplyCropBox() error {
+	cbox, err := p.GetCropBox()
+	if err != nil {
+		return err
+	}
+	p.MediaBox = cbox
+	p.CropBox = nil
+
+	for _, box := range []**PdfRectangle{&p.BleedBox, &p.TrimBox, &p.ArtBox} {
+		if *box == nil {
+			continue
+		}
+		rect, ok := (*box).Intersect(cbox)
+		if !ok {
+			rect = nil
+		}
+		*box = rect
+	}
+	return nil
+}
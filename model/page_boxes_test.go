@@ -0,0 +1,115 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestPageBoxesInheritance(t *testing.T) {
+	rawText := `
+1 0 obj
+<< /Type /Pages /Kids [2 0 R] /Count 1 /MediaBox [0 0 612 792] /CropBox [-10 10 600 780] /Rotate -90 >>
+endobj
+2 0 obj
+<< /Type /Page /Parent 1 0 R /TrimBox [20 20 700 700] >>
+endobj
+`
+	r := NewReaderForText(rawText)
+	require.NoError(t, r.ParseIndObjSeries())
+
+	obj, err := r.parser.LookupByNumber(2)
+	require.NoError(t, err)
+	dict, ok := core.GetDict(obj)
+	require.True(t, ok)
+	page, err := r.newPdfPageFromDict(dict)
+	require.NoError(t, err)
+
+	mbox, err := page.GetBox(PageBoxMedia)
+	require.NoError(t, err)
+	require.Equal(t, PdfRectangle{Urx: 612, Ury: 792}, *mbox)
+
+	// The inherited crop box is reduced to the media box.
+	cbox, err := page.GetBox(PageBoxCrop)
+	require.NoError(t, err)
+	require.Equal(t, PdfRectangle{Llx: 0, Lly: 10, Urx: 600, Ury: 780}, *cbox)
+
+	// The bleed and art boxes default to the crop box.
+	for _, box := range []PageBox{PageBoxBleed, PageBoxArt} {
+		rect, err := page.GetBox(box)
+		require.NoError(t, err)
+		require.Equal(t, *cbox, *rect)
+	}
+	tbox, err := page.GetBox(PageBoxTrim)
+	require.NoError(t, err)
+	require.Equal(t, PdfRectangle{Llx: 20, Lly: 20, Urx: 612, Ury: 700}, *tbox)
+
+	rotate, err := page.GetRotate()
+	require.NoError(t, err)
+	require.Equal(t, int64(270), rotate)
+	require.Equal(t, 1.0, page.GetUserUnit())
+
+	_, err = page.GetBox("Invalid")
+	require.Error(t, err)
+
+	// Apply the crop box permanently.
+	require.NoError(t, page.ApplyCropBox())
+	require.Nil(t, page.CropBox)
+	require.Equal(t, *cbox, *page.MediaBox)
+	require.Equal(t, PdfRectangle{Llx: 20, Lly: 20, Urx: 600, Ury: 700}, *page.TrimBox)
+}
+
+func TestPageBoxesReadWrite(t *testing.T) {
+	page := NewPdfPage()
+	require.NoError(t, page.SetBox(PageBoxMedia, &PdfRectangle{Urx: 612, Ury: 792}))
+	require.Error(t, page.SetBox(PageBoxMedia, nil))
+	require.Error(t, page.SetBox("Invalid", &PdfRectangle{}))
+
+	boxes := map[PageBox]PdfRectangle{
+		PageBoxCrop:  {Llx: 10, Lly: 10, Urx: 602, Ury: 782},
+		PageBoxBleed: {Llx: 20, Lly: 20, Urx: 592, Ury: 772},
+		PageBoxTrim:  {Llx: 30, Lly: 30, Urx: 582, Ury: 762},
+		PageBoxArt:   {Llx: 40, Lly: 40, Urx: 572, Ury: 752},
+	}
+	for box, rect := range boxes {
+		// Set denormalized rectangles.
+		rect := PdfRectangle{Llx: rect.Urx, Lly: rect.Ury, Urx: rect.Llx, Ury: rect.Lly}
+		require.NoError(t, page.SetBox(box, &rect))
+	}
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	readPage, err := r.GetPage(1)
+	require.NoError(t, err)
+	for box, rect := range boxes {
+		readRect, err := readPage.GetBox(box)
+		require.NoError(t, err)
+		require.Equal(t, rect, *readRect, box)
+	}
+
+	// Remove box.
+	require.NoError(t, readPage.SetBox(PageBoxArt, nil))
+	rect, err := readPage.GetBox(PageBoxArt)
+	require.NoError(t, err)
+	require.Equal(t, boxes[PageBoxCrop], *rect)
+}
+
+func TestUnitConversion(t *testing.T) {
+	require.InDelta(t, 595.28, MillimetersToPoints(210), 0.01)
+	require.InDelta(t, 297, PointsToMillimeters(MillimetersToPoints(297)), 1e-9)
+	require.Equal(t, 612.0, InchesToPoints(8.5))
+	require.Equal(t, 11.0, PointsToInches(792))
+}
@@ -0,0 +1,199 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// Clone returns a deep copy of the page. Unlike Duplicate, which shares the
+// objects of the page, the page dictionary, the content streams, the
+// resources and the annotations are copied, so that the page and its copy
+// can be modified independently. The inherited attributes of the page
+// (Resources, MediaBox, CropBox and Rotate) are set on the copy, which does
+// not have a parent. Objects shared by the original page are shared by the
+// copy as well. References to other pages, e.g. in link destinations, refer
+// to the original pages, and the widget annotations of the copy are not
+// attached to the fields of the form.
+func (p *PdfPage) Clone() (*PdfPage, error) {
+	dict := p.GetPageDict()
+	c := pageCopier{copies: map[core.PdfObject]core.PdfObject{}}
+
+	container := core.MakeIndirectObject(nil)
+	c.copies[p.primitive] = container
+	c.copies[dict] = container
+
+	// Register the annotations before copying them, so that the references
+	// between the annotations of the page (e.g. Popup and Parent) refer to
+	// their copies.
+	var annots []*core.PdfIndirectObject
+	if arr, ok := core.GetArray(dict.Get("Annots")); ok {
+		for _, obj := range arr.Elements() {
+			annot, ok := core.GetIndirect(core.ResolveReference(obj))
+			if !ok {
+				continue
+			}
+			if _, ok := c.copies[annot]; !ok {
+				c.copies[annot] = core.MakeIndirectObject(nil)
+				annots = append(annots, annot)
+			}
+		}
+	}
+	for _, annot := range annots {
+		c.copies[annot].(*core.PdfIndirectObject).PdfObject = c.copy(annot.PdfObject)
+	}
+
+	cloneDict := core.MakeDict()
+	for _, key := range dict.Keys() {
+		if key != "Parent" {
+			cloneDict.Set(key, c.copy(dict.Get(key)))
+		}
+	}
+	for _, field := range []core.PdfObjectName{"MediaBox", "CropBox", "Rotate"} {
+		if cloneDict.Get(field) == nil {
+			if obj := inheritedPageAttribute(dict, field); obj != nil {
+				cloneDict.Set(field, c.copy(obj))
+			}
+		}
+	}
+
+	clone, err := p.reader.newPdfPageFromDict(cloneDict)
+	if err != nil {
+		return nil, err
+	}
+	clone.setContainer(container)
+	return clone, nil
+}
+
+// CopyPageTo adds a deep copy of the page, as returned by Clone, to the
+// writer `w`. The page may come from another document than the pages of the
+// writer. Returns the added copy.
+func (p *PdfPage) CopyPageTo(w *PdfWriter) (*PdfPage, error) {
+	if w == nil {
+		return nil, errors.New("nil writer")
+	}
+	clone, err := p.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.AddPage(clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// inheritedPageAttribute returns the value of the inheritable attribute
+// `field` of the page tree nodes above the page dictionary `dict`, or nil if
+// none of them sets it.
+func inheritedPageAttribute(dict *core.PdfObjectDictionary, field core.PdfObjectName) core.PdfObject {
+	visited := map[*core.PdfObjectDictionary]struct{}{dict: {}}
+	parent, ok := core.GetDict(dict.Get("Parent"))
+	for ok {
+		if _, seen := visited[parent]; seen {
+			break
+		}
+		visited[parent] = struct{}{}
+		if obj := parent.Get(field); obj != nil {
+			return obj
+		}
+		parent, ok = core.GetDict(parent.Get("Parent"))
+	}
+	return nil
+}
+
+// pageCopier makes deep copies of the objects of a page.
+type pageCopier struct {
+	// copies maps the copied objects to their copies.
+	copies map[core.PdfObject]core.PdfObject
+}
+
+// copy returns a deep copy of `obj`. The page tree nodes which have not been
+// registered in the copies are not copied, and the Parent entries refer to
+// the copies of their values only if these have been registered, as they
+// would otherwise pull the whole page tree or field hierarchy into the copy.
+func (c *pageCopier) copy(obj core.PdfObject) core.PdfObject {
+	if obj == nil {
+		return nil
+	}
+	if copied, ok := c.copies[obj]; ok {
+		return copied
+	}
+
+	switch t := obj.(type) {
+	case *core.PdfObjectReference:
+		return c.copy(core.ResolveReference(t))
+	case *core.PdfIndirectObject:
+		if isPageTreeNode(t.PdfObject) {
+			return t
+		}
+		ind := core.MakeIndirectObject(nil)
+		c.copies[obj] = ind
+		ind.PdfObject = c.copy(t.PdfObject)
+		return ind
+	case *core.PdfObjectStream:
+		stream := &core.PdfObjectStream{Stream: append([]byte(nil), t.Stream...)}
+		c.copies[obj] = stream
+		stream.PdfObjectDictionary = c.copy(t.PdfObjectDictionary).(*core.PdfObjectDictionary)
+		return stream
+	case *core.PdfObjectDictionary:
+		if isPageTreeNode(t) {
+			return t
+		}
+		dict := core.MakeDict()
+		c.copies[obj] = dict
+		for _, key := range t.Keys() {
+			val := t.Get(key)
+			if key == "Parent" {
+				copied, ok := c.copies[core.ResolveReference(val)]
+				if !ok {
+					continue
+				}
+				val = copied
+			} else {
+				val = c.copy(val)
+			}
+			dict.Set(key, val)
+		}
+		return dict
+	case *core.PdfObjectArray:
+		arr := core.MakeArray()
+		c.copies[obj] = arr
+		for _, val := range t.Elements() {
+			arr.Append(c.copy(val))
+		}
+		return arr
+	case *core.PdfObjectString:
+		str := *t
+		return &str
+	case *core.PdfObjectName:
+		name := *t
+		return &name
+	case *core.PdfObjectInteger:
+		val := *t
+		return &val
+	case *core.PdfObjectFloat:
+		val := *t
+		return &val
+	case *core.PdfObjectBool:
+		val := *t
+		return &val
+	case *core.PdfObjectNull:
+		return core.MakeNull()
+	}
+	return obj
+}
+
+// isPageTreeNode returns true if `obj` is a page or a pages dictionary.
+func isPageTreeNode(obj core.PdfObject) bool {
+	dict, ok := core.GetDict(obj)
+	if !ok {
+		return false
+	}
+	name, _ := core.GetNameVal(dict.Get("Type"))
+	return name == "Page" || name == "Pages"
+}
@@ -0,0 +1,97 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestPageClone(t *testing.T) {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 200, Ury: 100}
+	img := &Image{Width: 2, Height: 1, BitsPerComponent: 8, ColorComponents: 1, Data: []byte{0, 255}}
+	ximg, err := NewXObjectImageFromImage(img, nil, core.NewRawEncoder())
+	require.NoError(t, err)
+	require.NoError(t, page.AddImageResource("Im1", ximg))
+	require.NoError(t, page.AddContentStreamByString("q 20 0 0 10 0 0 cm /Im1 Do Q"))
+
+	text := NewPdfAnnotationText()
+	text.Rect = core.MakeArrayFromFloats([]float64{10, 10, 30, 30})
+	popup := NewPdfAnnotationPopup()
+	popup.Rect = core.MakeArrayFromFloats([]float64{30, 30, 90, 60})
+	popup.Parent = text.ToPdfObject()
+	text.Popup = popup
+	page.AddAnnotation(text.PdfAnnotation)
+	page.AddAnnotation(popup.PdfAnnotation)
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	original := r.PageList[0]
+	stream, _ := original.Resources.GetXObjectByName("Im1")
+	hash := ImageXObjectHash(stream)
+
+	clone, err := original.Clone()
+	require.NoError(t, err)
+	require.Nil(t, clone.Parent)
+	require.Equal(t, original.MediaBox, clone.MediaBox)
+	require.False(t, clone.GetPageAsIndirectObject() == original.GetPageAsIndirectObject())
+
+	// Modifying the copy does not change the original page.
+	gray := &Image{Width: 1, Height: 1, BitsPerComponent: 8, ColorComponents: 1, Data: []byte{128}}
+	require.NoError(t, clone.ReplaceImageXObject("Im1", gray, nil, nil))
+	require.NoError(t, clone.SetContentStreams([]string{"q 10 0 0 10 0 0 cm /Im1 Do Q"}, nil))
+	_, found := original.FindImageXObjectByHash(hash)
+	require.True(t, found)
+	_, found = clone.FindImageXObjectByHash(hash)
+	require.False(t, found)
+	content, err := original.GetAllContentStreams()
+	require.NoError(t, err)
+	require.Contains(t, content, "20 0 0 10 0 0 cm")
+
+	// The annotations refer to each other and to the copy.
+	annots, err := clone.GetAnnotations()
+	require.NoError(t, err)
+	require.Len(t, annots, 2)
+	textDict, ok := core.GetDict(annots[0].GetContainingPdfObject())
+	require.True(t, ok)
+	popupDict, ok := core.GetDict(annots[1].GetContainingPdfObject())
+	require.True(t, ok)
+	require.True(t, textDict.Get("Popup") == annots[1].GetContainingPdfObject())
+	require.True(t, popupDict.Get("Parent") == annots[0].GetContainingPdfObject())
+	origAnnots, err := original.GetAnnotations()
+	require.NoError(t, err)
+	require.False(t, origAnnots[0].GetContainingPdfObject() == annots[0].GetContainingPdfObject())
+
+	// Copy the page twice to another document.
+	w = NewPdfWriter()
+	_, err = original.CopyPageTo(&w)
+	require.NoError(t, err)
+	_, err = clone.CopyPageTo(&w)
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, w.Write(&buf))
+	r, err = NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, r.PageList, 2)
+	_, found = r.PageList[0].FindImageXObjectByHash(hash)
+	require.True(t, found)
+	_, found = r.PageList[1].FindImageXObjectByHash(hash)
+	require.False(t, found)
+	for _, page := range r.PageList {
+		annots, err := page.GetAnnotations()
+		require.NoError(t, err)
+		require.Len(t, annots, 2)
+	}
+}
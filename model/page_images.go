@@ -0,0 +1,83 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// imageReplacementKeys are the entries of image XObject dictionaries which are kept when their
+// image is replaced, as they do not depend on the image data.
+var imageReplacementKeys = []core.PdfObjectName{"StructParent", "OC", "Interpolate", "Intent"}
+
+// ImageXObjectHash returns the hex-encoded SHA-256 hash of the encoded data of the image XObject
+// stream `stream`. It identifies the images of pages in FindImageXObjectByHash.
+func ImageXObjectHash(stream *core.PdfObjectStream) string {
+	sum := sha256.Sum256(stream.Stream)
+	return hex.EncodeToString(sum[:])
+}
+
+// FindImageXObjectByHash returns the name of the first image XObject of the page resources whose
+// encoded data has the hash `hash`, as returned by ImageXObjectHash. Returns false if the page
+// does not have such image.
+func (p *PdfPage) FindImageXObjectByHash(hash string) (core.PdfObjectName, bool) {
+	if p.Resources == nil {
+		return "", false
+	}
+	dict, ok := core.GetDict(p.Resources.XObject)
+	if !ok {
+		return "", false
+	}
+	for _, name := range dict.Keys() {
+		stream, xtype := p.Resources.GetXObjectByName(name)
+		if xtype == XObjectTypeImage && ImageXObjectHash(stream) == hash {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// ReplaceImageXObject replaces the data of the image XObject `name` of the page resources by the
+// image `img` in the colorspace `cs`, encoded with `encoder`. The colorspace is guessed from the
+// number of components of the image if `cs` is nil and the raw encoding is used if `encoder` is
+// nil. The image may have other dimensions or another colorspace: the Width, Height, ColorSpace,
+// BitsPerComponent, Filter, DecodeParms and masks of the XObject are set for the new image. The
+// stream object is updated in place, so that the pages and forms which refer to it show the new
+// image, drawn in the area of the old one.
+func (p *PdfPage) ReplaceImageXObject(name core.PdfObjectName, img *Image, cs PdfColorspace,
+	encoder core.StreamEncoder) error {
+	if p.Resources == nil {
+		return errors.New("page has no resources")
+	}
+	stream, xtype := p.Resources.GetXObjectByName(name)
+	if stream == nil {
+		return fmt.Errorf("XObject %s not found", name)
+	}
+	if xtype != XObjectTypeImage {
+		return fmt.Errorf("XObject %s is not an image", name)
+	}
+
+	ximg, err := NewXObjectImageFromImage(img, cs, encoder)
+	if err != nil {
+		return err
+	}
+	replacement := ximg.ToPdfObject().(*core.PdfObjectStream)
+	dict := replacement.PdfObjectDictionary
+	for _, key := range imageReplacementKeys {
+		if obj := stream.Get(key); obj != nil && dict.Get(key) == nil {
+			dict.Set(key, obj)
+		}
+	}
+
+	stream.PdfObjectDictionary = dict
+	stream.Stream = replacement.Stream
+	return nil
+}
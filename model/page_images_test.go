@@ -0,0 +1,58 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestPageReplaceImageXObject(t *testing.T) {
+	page := NewPdfPage()
+	img := &Image{Width: 2, Height: 2, BitsPerComponent: 8, ColorComponents: 1, Data: []byte{0, 1, 2, 3}}
+	ximg, err := NewXObjectImageFromImage(img, nil, core.NewRawEncoder())
+	require.NoError(t, err)
+	ximg.StructParent = core.MakeInteger(4)
+	require.NoError(t, page.Resources.SetXObjectImageByName("Im1", ximg))
+	stream, _ := page.Resources.GetXObjectByName("Im1")
+
+	hash := ImageXObjectHash(stream)
+	name, found := page.FindImageXObjectByHash(hash)
+	require.True(t, found)
+	require.Equal(t, core.PdfObjectName("Im1"), name)
+	_, found = page.FindImageXObjectByHash("00")
+	require.False(t, found)
+
+	// Replace by an RGB image with other dimensions, compressed with Flate.
+	logo := &Image{Width: 3, Height: 1, BitsPerComponent: 8, ColorComponents: 3,
+		Data: []byte{255, 0, 0, 0, 255, 0, 0, 0, 255}}
+	require.NoError(t, page.ReplaceImageXObject("Im1", logo, nil, core.NewFlateEncoder()))
+
+	replaced, xtype := page.Resources.GetXObjectByName("Im1")
+	require.Equal(t, XObjectTypeImage, xtype)
+	require.True(t, replaced == stream)
+	width, _ := core.GetIntVal(stream.Get("Width"))
+	height, _ := core.GetIntVal(stream.Get("Height"))
+	require.Equal(t, 3, width)
+	require.Equal(t, 1, height)
+	cs, _ := core.GetNameVal(stream.Get("ColorSpace"))
+	require.Equal(t, "DeviceRGB", cs)
+	filter, _ := core.GetNameVal(stream.Get("Filter"))
+	require.Equal(t, "FlateDecode", filter)
+	structParent, _ := core.GetIntVal(stream.Get("StructParent"))
+	require.Equal(t, 4, structParent)
+
+	decoded, err := core.DecodeStream(stream)
+	require.NoError(t, err)
+	require.Equal(t, logo.Data, decoded)
+	_, found = page.FindImageXObjectByHash(hash)
+	require.False(t, found)
+
+	require.Error(t, page.ReplaceImageXObject("Im2", logo, nil, nil))
+}
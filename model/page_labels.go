@@ -0,0 +1,268 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// PageLabelStyle represents the numbering style of a page label range
+// (Table 159 - p. 385).
+type PageLabelStyle string
+
+// Page label numbering styles.
+const (
+	PageLabelStyleNone         PageLabelStyle = ""  // Prefix only.
+	PageLabelStyleDecimal      PageLabelStyle = "D" // 1, 2, 3...
+	PageLabelStyleUpperRoman   PageLabelStyle = "R" // I, II, III...
+	PageLabelStyleLowerRoman   PageLabelStyle = "r" // i, ii, iii...
+	PageLabelStyleUpperLetters PageLabelStyle = "A" // A to Z, AA to ZZ...
+	PageLabelStyleLowerLetters PageLabelStyle = "a" // a to z, aa to zz...
+)
+
+// PageLabelRange represents a page label dictionary, which specifies the
+// labels of the pages starting at page index PageIndex up to the next range.
+type PageLabelRange struct {
+	// PageIndex is the index of the first page of the range (0-based).
+	PageIndex int
+
+	// Style is the numbering style of the labels.
+	Style PageLabelStyle
+
+	// Prefix is the prefix of the labels of the range.
+	Prefix string
+
+	// Start is the numeric value of the label of the first page of the range.
+	// Values smaller than 1 are treated as 1.
+	Start int
+}
+
+// Label returns the label of the page at offset `offset` from the start of
+// the range.
+func (plr *PageLabelRange) Label(offset int) string {
+	start := plr.Start
+	if start < 1 {
+		start = 1
+	}
+	num := start + offset
+
+	switch plr.Style {
+	case PageLabelStyleDecimal:
+		return plr.Prefix + strconv.Itoa(num)
+	case PageLabelStyleUpperRoman:
+		return plr.Prefix + strings.ToUpper(formatRoman(num))
+	case PageLabelStyleLowerRoman:
+		return plr.Prefix + formatRoman(num)
+	case PageLabelStyleUpperLetters:
+		return plr.Prefix + strings.ToUpper(formatLetters(num))
+	case PageLabelStyleLowerLetters:
+		return plr.Prefix + formatLetters(num)
+	}
+	return plr.Prefix
+}
+
+// PageLabels represents the page labels of a document, which are stored in
+// the PageLabels number tree of the catalog (section 12.4.2 p. 382
+// PDF32000_2008). The ranges are sorted by page index.
+type PageLabels struct {
+	Ranges []*PageLabelRange
+}
+
+// NewPageLabels returns a new empty page labels instance.
+func NewPageLabels() *PageLabels {
+	return &PageLabels{}
+}
+
+// AddRange adds a new page label range starting at page index `pageIndex`
+// (0-based). An existing range starting at the same page index is replaced.
+func (pl *PageLabels) AddRange(pageIndex int, style PageLabelStyle, prefix string, start int) {
+	plr := &PageLabelRange{PageIndex: pageIndex, Style: style, Prefix: prefix, Start: start}
+	for i, r := range pl.Ranges {
+		if r.PageIndex == pageIndex {
+			pl.Ranges[i] = plr
+			return
+		}
+	}
+
+	pl.Ranges = append(pl.Ranges, plr)
+	sort.SliceStable(pl.Ranges, func(i, j int) bool {
+		return pl.Ranges[i].PageIndex < pl.Ranges[j].PageIndex
+	})
+}
+
+// Label returns the label of the page at index `pageIndex` (0-based). Pages
+// located before the first range are labeled using their decimal page
+// numbers.
+func (pl *PageLabels) Label(pageIndex int) string {
+	var plr *PageLabelRange
+	for _, r := range pl.Ranges {
+		if r.PageIndex > pageIndex {
+			break
+		}
+		plr = r
+	}
+	if plr == nil {
+		return strconv.Itoa(pageIndex + 1)
+	}
+	return plr.Label(pageIndex - plr.PageIndex)
+}
+
+// Labels returns the labels of the first `numPages` pages.
+func (pl *PageLabels) Labels(numPages int) []string {
+	labels := make([]string, numPages)
+	for i := range labels {
+		labels[i] = pl.Label(i)
+	}
+	return labels
+}
+
+// NewPageLabelsFromPdfObject loads the page labels from the specified
+// PageLabels number tree.
+func NewPageLabelsFromPdfObject(obj core.PdfObject) (*PageLabels, error) {
+	if _, ok := core.GetDict(obj); !ok {
+		return nil, errors.New("page labels must be a number tree dictionary")
+	}
+
+	pl := NewPageLabels()
+	numberTreeEntries(obj, 0, func(key int, val core.PdfObject) {
+		dict, ok := core.GetDict(val)
+		if !ok {
+			common.Log.Debug("Invalid page label dictionary for page %d: %v", key, val)
+			return
+		}
+
+		var style PageLabelStyle
+		if s, ok := core.GetNameVal(dict.Get("S")); ok {
+			style = PageLabelStyle(s)
+		}
+		var prefix string
+		if p, ok := core.GetString(dict.Get("P")); ok {
+			prefix = p.Decoded()
+		}
+		start := 1
+		if st, ok := core.GetIntVal(dict.Get("St")); ok {
+			start = st
+		}
+		pl.AddRange(key, style, prefix, start)
+	})
+	return pl, nil
+}
+
+// ToPdfObject returns the PageLabels number tree representation of the page
+// labels.
+func (pl *PageLabels) ToPdfObject() core.PdfObject {
+	nums := core.MakeArray()
+	for _, r := range pl.Ranges {
+		dict := core.MakeDict()
+		dict.Set("Type", core.MakeName("PageLabel"))
+		if r.Style != PageLabelStyleNone {
+			dict.Set("S", core.MakeName(string(r.Style)))
+		}
+		if r.Prefix != "" {
+			dict.Set("P", makeTextString(r.Prefix))
+		}
+		if r.Start > 1 {
+			dict.Set("St", core.MakeInteger(int64(r.Start)))
+		}
+		nums.Append(core.MakeInteger(int64(r.PageIndex)), dict)
+	}
+
+	tree := core.MakeDict()
+	tree.Set("Nums", nums)
+	return tree
+}
+
+// numberTreeEntries calls `fn` for each entry of the number tree `node`, in
+// the order in which the entries are stored.
+func numberTreeEntries(node core.PdfObject, depth int, fn func(key int, obj core.PdfObject)) {
+	dict, ok := core.GetDict(node)
+	if !ok || depth > maxNameTreeDepth {
+		return
+	}
+
+	if nums, ok := core.GetArray(dict.Get("Nums")); ok {
+		for i := 0; i+1 < nums.Len(); i += 2 {
+			key, ok := core.GetIntVal(nums.Get(i))
+			if !ok {
+				continue
+			}
+			fn(key, core.ResolveReference(nums.Get(i+1)))
+		}
+	}
+
+	if kids, ok := core.GetArray(dict.Get("Kids")); ok {
+		for _, kid := range kids.Elements() {
+			numberTreeEntries(kid, depth+1, fn)
+		}
+	}
+}
+
+// formatRoman returns the lowercase roman numeral representation of `num`.
+func formatRoman(num int) string {
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	numerals := []string{"m", "cm", "d", "cd", "c", "xc", "l", "xl", "x", "ix", "v", "iv", "i"}
+
+	var sb strings.Builder
+	for i, val := range values {
+		for num >= val {
+			sb.WriteString(numerals[i])
+			num -= val
+		}
+	}
+	return sb.String()
+}
+
+// formatLetters returns the lowercase letter representation of `num`: a to z
+// for 1 to 26, aa to zz for 27 to 52, etc.
+func formatLetters(num int) string {
+	if num < 1 {
+		return ""
+	}
+	letter := string(rune('a' + (num-1)%26))
+	return strings.Repeat(letter, (num-1)/26+1)
+}
+
+// GetPdfPageLabels returns the page labels of the document, or nil if the
+// document does not define page labels.
+func (r *PdfReader) GetPdfPageLabels() (*PageLabels, error) {
+	obj, err := r.GetPageLabels()
+	if err != nil || obj == nil {
+		return nil, err
+	}
+	return NewPageLabelsFromPdfObject(obj)
+}
+
+// GetPageLabel returns the label of the page with the specified page number
+// (1-based). The decimal page number is returned if the document does not
+// define page labels.
+func (r *PdfReader) GetPageLabel(pageNumber int) (string, error) {
+	if pageNumber < 1 || pageNumber > len(r.PageList) {
+		return "", errors.New("page number out of range")
+	}
+
+	labels, err := r.GetPdfPageLabels()
+	if err != nil {
+		return "", err
+	}
+	if labels == nil {
+		return strconv.Itoa(pageNumber), nil
+	}
+	return labels.Label(pageNumber - 1), nil
+}
+
+// SetPdfPageLabels sets the page labels of the output document.
+func (w *PdfWriter) SetPdfPageLabels(labels *PageLabels) error {
+	if labels == nil {
+		return nil
+	}
+	return w.SetPageLabels(labels.ToPdfObject())
+}
@@ -0,0 +1,90 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestPageLabels(t *testing.T) {
+	labels := NewPageLabels()
+	labels.AddRange(4, PageLabelStyleDecimal, "", 1)
+	labels.AddRange(0, PageLabelStyleLowerRoman, "", 1)
+	labels.AddRange(7, PageLabelStyleDecimal, "A-", 8)
+	labels.AddRange(9, PageLabelStyleUpperLetters, "", 26)
+	labels.AddRange(12, PageLabelStyleNone, "Cover", 1)
+
+	expected := []string{
+		"i", "ii", "iii", "iv",
+		"1", "2", "3",
+		"A-8", "A-9",
+		"Z", "AA", "BB",
+		"Cover",
+	}
+	require.Equal(t, expected, labels.Labels(len(expected)))
+
+	// Replace range.
+	labels.AddRange(0, PageLabelStyleUpperRoman, "", 47)
+	require.Equal(t, "XLVII", labels.Label(0))
+	require.Equal(t, "MCMXCIX", (&PageLabelRange{Style: PageLabelStyleUpperRoman, Start: 1999}).Label(0))
+
+	// Pages before the first range use decimal numbers.
+	require.Equal(t, "2", (&PageLabels{Ranges: []*PageLabelRange{{PageIndex: 2, Prefix: "x"}}}).Label(1))
+
+	// Write and read.
+	w := NewPdfWriter()
+	for i := 0; i < len(expected); i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+		require.NoError(t, w.AddPage(page))
+	}
+	require.NoError(t, w.SetPdfPageLabels(labels))
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	readLabels, err := r.GetPdfPageLabels()
+	require.NoError(t, err)
+	require.Equal(t, labels.Labels(len(expected)), readLabels.Labels(len(expected)))
+
+	label, err := r.GetPageLabel(9)
+	require.NoError(t, err)
+	require.Equal(t, "A-9", label)
+	_, err = r.GetPageLabel(100)
+	require.Error(t, err)
+}
+
+func TestPageLabelsNumberTree(t *testing.T) {
+	rawText := `
+1 0 obj
+<< /Kids [2 0 R 3 0 R] >>
+endobj
+2 0 obj
+<< /Limits [0 2] /Nums [0 << /S /r >> 2 << /S /D /P (p) >>] >>
+endobj
+3 0 obj
+<< /Limits [5 5] /Nums [5 << /S /a /St 3 >>] >>
+endobj
+`
+	r := NewReaderForText(rawText)
+	require.NoError(t, r.ParseIndObjSeries())
+	obj, err := r.parser.LookupByNumber(1)
+	require.NoError(t, err)
+
+	labels, err := NewPageLabelsFromPdfObject(obj)
+	require.NoError(t, err)
+	require.Equal(t, []string{"i", "ii", "p1", "p2", "p3", "c", "d"}, labels.Labels(7))
+
+	_, err = NewPageLabelsFromPdfObject(core.MakeInteger(1))
+	require.Error(t, err)
+}
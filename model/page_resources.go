@@ -0,0 +1,230 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// maxResourceFormDepth is the maximum nesting depth of the Form XObjects whose resources are
+// listed by PdfPage.ListResources.
+const maxResourceFormDepth = 32
+
+// PdfResourceType represents the type of a page resource, i.e. the resource dictionary entry it
+// is declared in.
+type PdfResourceType int
+
+// Page resource types.
+const (
+	ResourceTypeFont PdfResourceType = iota
+	ResourceTypeXObject
+	ResourceTypeExtGState
+	ResourceTypeColorSpace
+	ResourceTypeShading
+	ResourceTypePattern
+)
+
+// String returns the name of the resource dictionary entry of the resource type, e.g. "Font".
+func (t PdfResourceType) String() string {
+	switch t {
+	case ResourceTypeFont:
+		return "Font"
+	case ResourceTypeXObject:
+		return "XObject"
+	case ResourceTypeExtGState:
+		return "ExtGState"
+	case ResourceTypeColorSpace:
+		return "ColorSpace"
+	case ResourceTypeShading:
+		return "Shading"
+	case ResourceTypePattern:
+		return "Pattern"
+	}
+	return "Unknown"
+}
+
+// shadingTypeNames are the names of the shading types, indexed by ShadingType.
+var shadingTypeNames = []string{"", "FunctionBased", "Axial", "Radial", "FreeFormGouraud",
+	"LatticeFormGouraud", "CoonsPatch", "TensorProductPatch"}
+
+// PdfResourceSummary describes a resource used by a page.
+type PdfResourceSummary struct {
+	// Type is the type of the resource.
+	Type PdfResourceType
+
+	// Name is the name of the resource in its resource dictionary.
+	Name core.PdfObjectName
+
+	// Subtype describes the kind of resource: the font subtype (e.g. "TrueType"), the XObject
+	// subtype ("Image" or "Form"), the colorspace family (e.g. "ICCBased"), the shading type
+	// (e.g. "Axial") or the pattern type ("Tiling" or "Shading"). Empty for graphics states.
+	Subtype string
+
+	// BaseFont is the PostScript name of font resources.
+	BaseFont string
+
+	// Width and Height are the dimensions in pixels of image XObjects.
+	Width  int64
+	Height int64
+
+	// ObjectNumber is the object number of indirect resources, 0 for direct resources.
+	ObjectNumber int64
+
+	// Size is the size of the encoded stream data of the resource in bytes, including the streams
+	// it refers to, such as embedded font files, soft masks and ICC profiles. The resources of
+	// Form XObjects and patterns are listed separately and are not included.
+	Size int64
+
+	// Inherited specifies whether the resource is inherited from the page tree, i.e. the page
+	// does not have its own resource dictionary.
+	Inherited bool
+
+	// Form is the name of the Form XObject whose resource dictionary the resource is declared in.
+	// Empty for the resources of the page.
+	Form core.PdfObjectName
+}
+
+// ListResources returns summaries of the fonts, XObjects, graphics states, colorspaces, shadings
+// and patterns of the page resources, either direct or inherited from the page tree. The
+// resources of the Form XObjects of the page are included, with the name of the form set.
+func (p *PdfPage) ListResources() ([]PdfResourceSummary, error) {
+	if p.Resources == nil {
+		return nil, nil
+	}
+	inherited := p.Parent != nil && p.pageDict != nil && p.pageDict.Get("Resources") == nil
+
+	l := &resourceLister{inherited: inherited, visited: map[*core.PdfObjectDictionary]bool{}}
+	l.list(p.Resources.ToPdfObject(), "", 0)
+	return l.summaries, nil
+}
+
+// resourceLister lists the resources of resource dictionaries.
+type resourceLister struct {
+	summaries []PdfResourceSummary
+	inherited bool
+	visited   map[*core.PdfObjectDictionary]bool // Resource dictionaries already listed.
+}
+
+// list appends the summaries of the resources of the resource dictionary `resObj`, declared by
+// the Form XObject `form` (empty for the page) nested at depth `depth`.
+func (l *resourceLister) list(resObj core.PdfObject, form core.PdfObjectName, depth int) {
+	resDict, ok := core.GetDict(resObj)
+	if !ok || l.visited[resDict] || depth > maxResourceFormDepth {
+		return
+	}
+	l.visited[resDict] = true
+
+	types := []PdfResourceType{ResourceTypeFont, ResourceTypeXObject, ResourceTypeExtGState,
+		ResourceTypeColorSpace, ResourceTypeShading, ResourceTypePattern}
+	for _, typ := range types {
+		dict, ok := core.GetDict(resDict.Get(core.PdfObjectName(typ.String())))
+		if !ok {
+			continue
+		}
+		for _, name := range dict.Keys() {
+			obj := dict.Get(name)
+			summary := PdfResourceSummary{
+				Type:      typ,
+				Name:      name,
+				Inherited: l.inherited,
+				Form:      form,
+				Size:      resourceStreamSize(obj, map[core.PdfObject]bool{}),
+			}
+			if ind, ok := obj.(*core.PdfIndirectObject); ok {
+				summary.ObjectNumber = ind.ObjectNumber
+			} else if stream, ok := obj.(*core.PdfObjectStream); ok {
+				summary.ObjectNumber = stream.ObjectNumber
+			}
+			summarizeResource(&summary, core.TraceToDirectObject(obj))
+			l.summaries = append(l.summaries, summary)
+
+			if stream, ok := core.GetStream(obj); ok && summary.Subtype == "Form" {
+				l.list(stream.Get("Resources"), name, depth+1)
+			}
+		}
+	}
+}
+
+// summarizeResource sets the type specific fields of `summary` from the resource object `obj`.
+func summarizeResource(summary *PdfResourceSummary, obj core.PdfObject) {
+	var dict *core.PdfObjectDictionary
+	switch t := obj.(type) {
+	case *core.PdfObjectDictionary:
+		dict = t
+	case *core.PdfObjectStream:
+		dict = t.PdfObjectDictionary
+	case *core.PdfObjectName:
+		// Colorspace family name, e.g. /DeviceRGB.
+		summary.Subtype = t.String()
+		return
+	case *core.PdfObjectArray:
+		// Colorspace array, e.g. [/ICCBased 5 0 R].
+		if name, ok := core.GetName(t.Get(0)); ok {
+			summary.Subtype = name.String()
+		}
+		return
+	default:
+		return
+	}
+
+	switch summary.Type {
+	case ResourceTypeFont, ResourceTypeXObject:
+		summary.Subtype, _ = core.GetNameVal(dict.Get("Subtype"))
+		summary.BaseFont, _ = core.GetNameVal(dict.Get("BaseFont"))
+		if summary.Subtype == "Image" {
+			width, _ := core.GetIntVal(dict.Get("Width"))
+			height, _ := core.GetIntVal(dict.Get("Height"))
+			summary.Width, summary.Height = int64(width), int64(height)
+		}
+	case ResourceTypeShading:
+		if st, ok := core.GetIntVal(dict.Get("ShadingType")); ok && st > 0 && st < len(shadingTypeNames) {
+			summary.Subtype = shadingTypeNames[st]
+		}
+	case ResourceTypePattern:
+		switch pt, _ := core.GetIntVal(dict.Get("PatternType")); pt {
+		case 1:
+			summary.Subtype = "Tiling"
+		case 2:
+			summary.Subtype = "Shading"
+		}
+	}
+}
+
+// resourceStreamSize returns the total size of the encoded data of the streams of `obj` and of
+// the objects it refers to, except through the Resources and Parent entries. `visited` contains
+// the objects already counted.
+func resourceStreamSize(obj core.PdfObject, visited map[core.PdfObject]bool) int64 {
+	if obj == nil || visited[obj] {
+		return 0
+	}
+	visited[obj] = true
+
+	var size int64
+	sizeOfDict := func(dict *core.PdfObjectDictionary) {
+		for _, key := range dict.Keys() {
+			if key == "Resources" || key == "Parent" {
+				continue
+			}
+			size += resourceStreamSize(dict.Get(key), visited)
+		}
+	}
+	switch t := obj.(type) {
+	case *core.PdfObjectReference:
+		size += resourceStreamSize(t.Resolve(), visited)
+	case *core.PdfIndirectObject:
+		size += resourceStreamSize(t.PdfObject, visited)
+	case *core.PdfObjectStream:
+		size += int64(len(t.Stream))
+		sizeOfDict(t.PdfObjectDictionary)
+	case *core.PdfObjectDictionary:
+		sizeOfDict(t)
+	case *core.PdfObjectArray:
+		for _, elem := range t.Elements() {
+			size += resourceStreamSize(elem, visited)
+		}
+	}
+	return size
+}
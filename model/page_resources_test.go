@@ -0,0 +1,61 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestPageListResources(t *testing.T) {
+	// Resources inherited from the page tree.
+	rawText := `<<
+  /Type /Page
+  /Parent << /Type /Pages /Resources <<
+    /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >>
+    /ColorSpace << /CS0 [/Indexed /DeviceRGB 1 <FF000000FF00>] >>
+  >> >>
+  /MediaBox [0 0 612 792]
+>>`
+	parser := core.NewParserFromString(rawText)
+	pageDict, err := parser.ParseDict()
+	require.NoError(t, err)
+	page, err := (&PdfReader{}).newPdfPageFromDict(pageDict)
+	require.NoError(t, err)
+
+	summaries, err := page.ListResources()
+	require.NoError(t, err)
+	require.Equal(t, []PdfResourceSummary{
+		{Type: ResourceTypeFont, Name: "F1", Subtype: "Type1", BaseFont: "Helvetica", Inherited: true},
+		{Type: ResourceTypeColorSpace, Name: "CS0", Subtype: "Indexed", Inherited: true},
+	}, summaries)
+
+	// Direct resources, including the resources of a form.
+	page = NewPdfPage()
+	img := &Image{Width: 2, Height: 2, BitsPerComponent: 8, ColorComponents: 1, Data: []byte{0, 1, 2, 3}}
+	ximg, err := NewXObjectImageFromImage(img, nil, core.NewRawEncoder())
+	require.NoError(t, err)
+	require.NoError(t, page.Resources.SetXObjectImageByName("Im1", ximg))
+
+	form := NewXObjectForm()
+	form.Resources = NewPdfPageResources()
+	require.NoError(t, form.Resources.AddExtGState("GS1", core.MakeDict()))
+	require.NoError(t, form.SetContentStream([]byte("/GS1 gs"), core.NewRawEncoder()))
+	require.NoError(t, page.Resources.SetXObjectFormByName("Fm1", form))
+
+	summaries, err = page.ListResources()
+	require.NoError(t, err)
+	require.Len(t, summaries, 3)
+	require.Equal(t, PdfResourceSummary{Type: ResourceTypeXObject, Name: "Im1", Subtype: "Image",
+		Width: 2, Height: 2, Size: 4}, summaries[0])
+	require.Equal(t, PdfResourceSummary{Type: ResourceTypeXObject, Name: "Fm1", Subtype: "Form",
+		Size: 7}, summaries[1])
+	require.Equal(t, PdfResourceSummary{Type: ResourceTypeExtGState, Name: "GS1", Form: "Fm1"}, summaries[2])
+	require.Equal(t, "ExtGState", summaries[2].Type.String())
+}
@@ -0,0 +1,205 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// TransitionStyle represents the style of a page transition (Table 162 -
+// p. 392 PDF32000_2008).
+type TransitionStyle string
+
+// Page transition styles.
+const (
+	TransitionStyleSplit    TransitionStyle = "Split"    // Two lines sweeping across the screen.
+	TransitionStyleBlinds   TransitionStyle = "Blinds"   // Multiple lines sweeping in the same direction.
+	TransitionStyleBox      TransitionStyle = "Box"      // A rectangular box sweeping inward or outward.
+	TransitionStyleWipe     TransitionStyle = "Wipe"     // A single line sweeping across the screen.
+	TransitionStyleDissolve TransitionStyle = "Dissolve" // The old page dissolving gradually.
+	TransitionStyleGlitter  TransitionStyle = "Glitter"  // Dissolve sweeping in one direction.
+	TransitionStyleReplace  TransitionStyle = "R"        // The new page replacing the old one (default).
+	TransitionStyleFly      TransitionStyle = "Fly"      // The page area flying in or out.
+	TransitionStylePush     TransitionStyle = "Push"     // The new page pushing the old one off the screen.
+	TransitionStyleCover    TransitionStyle = "Cover"    // The new page sliding on, covering the old one.
+	TransitionStyleUncover  TransitionStyle = "Uncover"  // The old page sliding off, uncovering the new one.
+	TransitionStyleFade     TransitionStyle = "Fade"     // The new page fading in.
+)
+
+// TransitionDimension represents the dimension in which a Split or Blinds
+// transition occurs.
+type TransitionDimension string
+
+// Page transition dimensions.
+const (
+	TransitionDimensionHorizontal TransitionDimension = "H"
+	TransitionDimensionVertical   TransitionDimension = "V"
+)
+
+// TransitionMotion represents the direction of the motion of a Split, Box or
+// Fly transition.
+type TransitionMotion string
+
+// Page transition motions.
+const (
+	TransitionMotionInward  TransitionMotion = "I"
+	TransitionMotionOutward TransitionMotion = "O"
+)
+
+// TransitionDirectionNone is the direction of Fly transitions which do not
+// move in a specific direction, used when Scale is not 1.
+const TransitionDirectionNone = -1
+
+// PdfTransition represents the transition dictionary of a page, which
+// specifies the transition effect shown when the page is displayed during a
+// presentation (section 12.4.4.2 "Transition Effects" p. 391 PDF32000_2008).
+type PdfTransition struct {
+	// Style is the transition style. Defaults to TransitionStyleReplace.
+	Style TransitionStyle
+
+	// Duration is the duration of the transition effect, in seconds. Zero
+	// values are not written, the default duration being 1 second.
+	Duration float64
+
+	// Dimension is the dimension of Split and Blinds transitions. Defaults to
+	// TransitionDimensionHorizontal.
+	Dimension TransitionDimension
+
+	// Motion is the direction of the motion of Split, Box and Fly
+	// transitions. Defaults to TransitionMotionInward.
+	Motion TransitionMotion
+
+	// Direction is the direction in which Wipe, Glitter, Fly, Cover, Uncover
+	// and Push transitions move, in degrees counterclockwise from a left to
+	// right direction: 0, 90, 180, 270 or 315 (Glitter only). Set to
+	// TransitionDirectionNone for Fly transitions without direction.
+	Direction int
+
+	// Scale is the starting or ending scale of Fly transitions. Zero values
+	// are not written, the default scale being 1.
+	Scale float64
+
+	// Opaque specifies whether the area flying in Fly transitions is
+	// rectangular and opaque.
+	Opaque bool
+}
+
+// NewPdfTransition returns a new page transition with the specified style.
+func NewPdfTransition(style TransitionStyle) *PdfTransition {
+	return &PdfTransition{Style: style}
+}
+
+// newPdfTransitionFromPdfObject loads a page transition from the specified
+// transition dictionary.
+func newPdfTransitionFromPdfObject(obj core.PdfObject) (*PdfTransition, error) {
+	d, ok := core.GetDict(obj)
+	if !ok {
+		return nil, core.ErrTypeError
+	}
+
+	trans := &PdfTransition{}
+	if style, ok := core.GetNameVal(d.Get("S")); ok {
+		trans.Style = TransitionStyle(style)
+	}
+	if duration, err := core.GetNumberAsFloat(core.TraceToDirectObject(d.Get("D"))); err == nil {
+		trans.Duration = duration
+	}
+	if dim, ok := core.GetNameVal(d.Get("Dm")); ok {
+		trans.Dimension = TransitionDimension(dim)
+	}
+	if motion, ok := core.GetNameVal(d.Get("M")); ok {
+		trans.Motion = TransitionMotion(motion)
+	}
+	switch di := core.TraceToDirectObject(d.Get("Di")).(type) {
+	case *core.PdfObjectName:
+		if *di == "None" {
+			trans.Direction = TransitionDirectionNone
+		}
+	case *core.PdfObjectInteger, *core.PdfObjectFloat:
+		if val, err := core.GetNumberAsFloat(di); err == nil {
+			trans.Direction = int(val)
+		}
+	}
+	if scale, err := core.GetNumberAsFloat(core.TraceToDirectObject(d.Get("SS"))); err == nil {
+		trans.Scale = scale
+	}
+	if opaque, ok := core.GetBoolVal(d.Get("B")); ok {
+		trans.Opaque = opaque
+	}
+	return trans, nil
+}
+
+// ToPdfObject returns the transition dictionary of the page transition.
+func (t *PdfTransition) ToPdfObject() core.PdfObject {
+	d := core.MakeDict()
+	d.Set("Type", core.MakeName("Trans"))
+	if t.Style != "" {
+		d.Set("S", core.MakeName(string(t.Style)))
+	}
+	if t.Duration > 0 {
+		d.Set("D", core.MakeFloat(t.Duration))
+	}
+	if t.Dimension != "" {
+		d.Set("Dm", core.MakeName(string(t.Dimension)))
+	}
+	if t.Motion != "" {
+		d.Set("M", core.MakeName(string(t.Motion)))
+	}
+	if t.Direction == TransitionDirectionNone {
+		d.Set("Di", core.MakeName("None"))
+	} else if t.Direction != 0 {
+		d.Set("Di", core.MakeInteger(int64(t.Direction)))
+	}
+	if t.Scale > 0 {
+		d.Set("SS", core.MakeFloat(t.Scale))
+	}
+	if t.Opaque {
+		d.Set("B", core.MakeBool(true))
+	}
+	return d
+}
+
+// GetTransition returns the transition effect shown when the page is
+// displayed during a presentation, or nil if not specified.
+func (p *PdfPage) GetTransition() (*PdfTransition, error) {
+	obj := core.ResolveReference(p.Trans)
+	if obj == nil || core.IsNullObject(obj) {
+		return nil, nil
+	}
+	return newPdfTransitionFromPdfObject(obj)
+}
+
+// SetTransition sets the transition effect shown when the page is displayed
+// during a presentation. The transition is removed if `trans` is nil.
+func (p *PdfPage) SetTransition(trans *PdfTransition) {
+	if trans == nil {
+		p.Trans = nil
+		return
+	}
+	p.Trans = trans.ToPdfObject()
+}
+
+// GetDisplayDuration returns the maximum duration in seconds the page is
+// displayed during a presentation before advancing to the next page, and
+// false if not specified.
+func (p *PdfPage) GetDisplayDuration() (float64, bool) {
+	dur, err := core.GetNumberAsFloat(core.TraceToDirectObject(p.Dur))
+	if err != nil {
+		return 0, false
+	}
+	return dur, true
+}
+
+// SetDisplayDuration sets the maximum duration in seconds the page is
+// displayed during a presentation before advancing to the next page. The
+// duration is removed if `seconds` is not positive.
+func (p *PdfPage) SetDisplayDuration(seconds float64) {
+	if seconds <= 0 {
+		p.Dur = nil
+		return
+	}
+	p.Dur = core.MakeFloat(seconds)
+}
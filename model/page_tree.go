@@ -0,0 +1,100 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// SetBalancedPageTree sets whether the pages are written in a balanced page
+// tree, whose nodes have at most `maxKids` kids, rather than in a single flat
+// node. Large flat page trees are slow to load in some viewers. The Count
+// entries of the nodes are computed from the written pages and the inherited
+// attributes of the pages (Resources, MediaBox, CropBox and Rotate) are set on
+// the pages when they are added to the writer, so that the page trees of
+// malformed documents are repaired as well. Values of `maxKids` lower than 2
+// disable balancing, the default. Has no effect in append mode.
+func (w *PdfWriter) SetBalancedPageTree(maxKids int) {
+	if maxKids < 2 {
+		maxKids = 0
+	}
+	w.pageTreeMaxKids = maxKids
+}
+
+// balancePageTree rebuilds the page tree of the written objects into a
+// balanced tree of intermediate Pages nodes. The root node is kept, so that
+// the catalog does not change.
+func (w *PdfWriter) balancePageTree() {
+	catalog, ok := core.GetDict(w.root)
+	if !ok {
+		return
+	}
+	root, ok := core.GetIndirect(catalog.Get("Pages"))
+	if !ok {
+		return
+	}
+	rootDict, ok := core.GetDict(root)
+	if !ok {
+		return
+	}
+	kids, ok := core.GetArray(rootDict.Get("Kids"))
+	if !ok {
+		return
+	}
+
+	nodes := kids.Elements()
+	counts := make([]int64, len(nodes))
+	for i := range counts {
+		counts[i] = 1
+	}
+
+	// Group the nodes of each level, from the pages up, until they fit in the
+	// root. The nodes are distributed evenly between the groups so that the
+	// tree is balanced.
+	maxKids := w.pageTreeMaxKids
+	for len(nodes) > maxKids {
+		numGroups := (len(nodes) + maxKids - 1) / maxKids
+		parents := make([]core.PdfObject, numGroups)
+		parentCounts := make([]int64, numGroups)
+		for g := 0; g < numGroups; g++ {
+			start, end := g*len(nodes)/numGroups, (g+1)*len(nodes)/numGroups
+			dict := core.MakeDict()
+			node := core.MakeIndirectObject(dict)
+			groupKids := core.MakeArray()
+			for i := start; i < end; i++ {
+				groupKids.Append(nodes[i])
+				setPageTreeParent(nodes[i], node)
+				parentCounts[g] += counts[i]
+			}
+			dict.Set("Type", core.MakeName("Pages"))
+			dict.Set("Parent", root)
+			dict.Set("Kids", groupKids)
+			dict.Set("Count", core.MakeInteger(parentCounts[g]))
+
+			parents[g] = node
+			w.objects = append(w.objects, node)
+			w.objectsMap[node] = struct{}{}
+		}
+		nodes, counts = parents, parentCounts
+	}
+
+	var total int64
+	for i, node := range nodes {
+		setPageTreeParent(node, root)
+		total += counts[i]
+	}
+	rootDict.Set("Kids", core.MakeArray(nodes...))
+	rootDict.Set("Count", core.MakeInteger(total))
+	common.Log.Trace("Balanced page tree of %d pages", total)
+}
+
+// setPageTreeParent sets the Parent entry of the page tree node `node`.
+func setPageTreeParent(node core.PdfObject, parent *core.PdfIndirectObject) {
+	if dict, ok := core.GetDict(node); ok {
+		dict.Set("Parent", parent)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestBalancedPageTree(t *testing.T) {
+	const numPages, maxKids = 23, 4
+	w := NewPdfWriter()
+	for i := 0; i < numPages; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Urx: float64(100 + i), Ury: 792}
+		require.NoError(t, w.AddPage(page))
+	}
+	w.SetBalancedPageTree(maxKids)
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	// The pages are kept in order.
+	require.Len(t, r.PageList, numPages)
+	for i, page := range r.PageList {
+		require.Equal(t, float64(100+i), page.MediaBox.Urx)
+	}
+
+	// The nodes have at most maxKids kids, their counts are correct and all
+	// pages are at the same depth.
+	var depths []int
+	var walk func(node *core.PdfIndirectObject, parent core.PdfObject, depth int) int64
+	walk = func(node *core.PdfIndirectObject, parent core.PdfObject, depth int) int64 {
+		dict, ok := core.GetDict(node)
+		require.True(t, ok)
+		if parent != nil {
+			require.Equal(t, parent, core.ResolveReference(dict.Get("Parent")))
+		}
+		if name, _ := core.GetNameVal(dict.Get("Type")); name == "Page" {
+			depths = append(depths, depth)
+			return 1
+		}
+		kids, ok := core.GetArray(dict.Get("Kids"))
+		require.True(t, ok)
+		require.True(t, kids.Len() <= maxKids)
+		var count int64
+		for _, kid := range kids.Elements() {
+			kidObj, ok := core.GetIndirect(core.ResolveReference(kid))
+			require.True(t, ok)
+			count += walk(kidObj, node, depth+1)
+		}
+		nodeCount, ok := core.GetIntVal(dict.Get("Count"))
+		require.True(t, ok)
+		require.Equal(t, count, int64(nodeCount))
+		return count
+	}
+	require.Equal(t, int64(numPages), walk(r.pagesContainer, nil, 0))
+	require.Len(t, depths, numPages)
+	for _, depth := range depths {
+		require.Equal(t, 3, depth)
+	}
+}
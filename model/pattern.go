@@ -157,6 +157,51 @@ type PdfShadingPattern struct {
 	ExtGState core.PdfObject
 }
 
+// NewPdfTilingPattern returns a new tiling pattern with pattern cell `bbox`,
+// repeated every `xStep` and `yStep` units. Colored patterns specify their
+// colors in the pattern content stream, while uncolored patterns are painted
+// with the color specified when the pattern is used.
+// The pattern content is set with SetContentStream.
+func NewPdfTilingPattern(bbox PdfRectangle, xStep, yStep float64, colored bool) *PdfTilingPattern {
+	paintType := int64(2)
+	if colored {
+		paintType = 1
+	}
+
+	pattern := &PdfTilingPattern{
+		PdfPattern: &PdfPattern{
+			PatternType: 1,
+			container:   &core.PdfObjectStream{PdfObjectDictionary: core.MakeDict()},
+		},
+		PaintType:  core.MakeInteger(paintType),
+		TilingType: core.MakeInteger(1),
+		BBox:       &bbox,
+		XStep:      core.MakeFloat(xStep),
+		YStep:      core.MakeFloat(yStep),
+		Resources:  NewPdfPageResources(),
+	}
+	pattern.context = pattern
+	return pattern
+}
+
+// NewPdfShadingPattern returns a new shading pattern painting `shading`.
+func NewPdfShadingPattern(shading *PdfShading) *PdfShadingPattern {
+	pattern := &PdfShadingPattern{
+		PdfPattern: &PdfPattern{
+			PatternType: 2,
+			container:   core.MakeIndirectObject(core.MakeDict()),
+		},
+		Shading: shading,
+	}
+	pattern.context = pattern
+	return pattern
+}
+
+// NewPdfPatternFromPdfObject loads a tiling or shading pattern from `obj`.
+func NewPdfPatternFromPdfObject(obj core.PdfObject) (*PdfPattern, error) {
+	return newPdfPatternFromPdfObject(obj)
+}
+
 // Load a pdf pattern from an indirect object. Used in parsing/loading PDFs.
 func newPdfPatternFromPdfObject(container core.PdfObject) (*PdfPattern, error) {
 	pattern := &PdfPattern{}
@@ -173,6 +218,10 @@ func newPdfPatternFromPdfObject(container core.PdfObject) (*PdfPattern, error) {
 	} else if streamObj, is := core.GetStream(container); is {
 		pattern.container = streamObj
 		dict = streamObj.PdfObjectDictionary
+	} else if d, is := core.GetDict(container); is {
+		// Shading patterns may be direct dictionaries.
+		pattern.container = d
+		dict = d
 	} else {
 		common.Log.Debug("Pattern not an indirect object or stream. %T", container)
 		return nil, core.ErrTypeError
@@ -299,11 +348,11 @@ func newPdfTilingPatternFromDictionary(dict *core.PdfObjectDictionary) (*PdfTili
 		common.Log.Debug("Resources missing")
 		return nil, ErrRequiredAttributeMissing
 	}
-	dict, ok = core.TraceToDirectObject(obj).(*core.PdfObjectDictionary)
+	resDict, ok := core.GetDict(obj)
 	if !ok {
 		return nil, fmt.Errorf("invalid resource dictionary (%T)", obj)
 	}
-	resources, err := NewPdfPageResourcesFromDict(dict)
+	resources, err := NewPdfPageResourcesFromDict(resDict)
 	if err != nil {
 		return nil, err
 	}
@@ -311,7 +360,7 @@ func newPdfTilingPatternFromDictionary(dict *core.PdfObjectDictionary) (*PdfTili
 
 	// Matrix (optional).
 	if obj := dict.Get("Matrix"); obj != nil {
-		arr, ok := obj.(*core.PdfObjectArray)
+		arr, ok := core.GetArray(obj)
 		if !ok {
 			common.Log.Debug("Matrix not an array (got %T)", obj)
 			return nil, core.ErrTypeError
@@ -342,7 +391,7 @@ func newPdfShadingPatternFromDictionary(dict *core.PdfObjectDictionary) (*PdfSha
 
 	// Matrix (optional).
 	if obj := dict.Get("Matrix"); obj != nil {
-		arr, ok := obj.(*core.PdfObjectArray)
+		arr, ok := core.GetArray(obj)
 		if !ok {
 			common.Log.Debug("Matrix not an array (got %T)", obj)
 			return nil, core.ErrTypeError
@@ -369,6 +418,8 @@ func (p *PdfPattern) getDict() *core.PdfObjectDictionary {
 		return dict
 	} else if streamObj, is := p.container.(*core.PdfObjectStream); is {
 		return streamObj.PdfObjectDictionary
+	} else if dict, is := p.container.(*core.PdfObjectDictionary); is {
+		return dict
 	} else {
 		common.Log.Debug("Trying to access pattern dictionary of invalid object type (%T)", p.container)
 		return nil
@@ -420,7 +471,12 @@ func (p *PdfShadingPattern) ToPdfObject() core.PdfObject {
 	d := p.getDict()
 
 	if p.Shading != nil {
-		d.Set("Shading", p.Shading.ToPdfObject())
+		// Write the type specific shading attributes when available.
+		if ctx := p.Shading.GetContext(); ctx != nil {
+			d.Set("Shading", ctx.ToPdfObject())
+		} else {
+			d.Set("Shading", p.Shading.ToPdfObject())
+		}
 	}
 	if p.Matrix != nil {
 		d.Set("Matrix", p.Matrix)
@@ -0,0 +1,14 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package pdfa implements a writer profile producing documents conforming to
+// PDF/A-1b (ISO 19005-1), PDF/A-2b (ISO 19005-2) or PDF/A-3b (ISO 19005-3).
+// The profile fixes the documents written where possible, e.g. by adding the
+// PDF/A output intent and identification metadata or by removing the
+// encryption, and reports the requirements it cannot fix, such as fonts which
+// are not embedded and for which no substitute is provided. Validate and
+// ValidateUA check existing documents against PDF/A and basic PDF/UA-1
+// (ISO 14289-1) requirements.
+package pdfa
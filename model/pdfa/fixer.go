@@ -0,0 +1,574 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfa
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/xmp"
+)
+
+// fixer checks and fixes the objects of a document for a Profile.
+type fixer struct {
+	opts Options
+	w    *model.PdfWriter
+
+	// validate is set when the violations are only reported, see Validate.
+	validate bool
+
+	// objectNumber is the number of the object being walked.
+	objectNumber int64
+
+	visited       map[core.PdfObject]struct{}
+	catalog       *core.PdfObjectDictionary
+	catalogNumber int64
+	added         []core.PdfObject // Objects to write in addition.
+	violations    []Violation
+	err           error
+
+	// substitutes contains the font dictionaries built from the
+	// FontSubstitutes, by font name.
+	substitutes map[string]*core.PdfObjectDictionary
+}
+
+// newFixer returns a new fixer for the document written by `w` with `opts`.
+func newFixer(opts Options, w *model.PdfWriter) *fixer {
+	return &fixer{
+		opts:        opts,
+		w:           w,
+		visited:     map[core.PdfObject]struct{}{},
+		substitutes: map[string]*core.PdfObjectDictionary{},
+	}
+}
+
+// report records a violation of `requirement`.
+func (f *fixer) report(requirement Requirement, format string, args ...interface{}) {
+	f.violations = append(f.violations, Violation{
+		Requirement:  requirement,
+		Description:  fmt.Sprintf(format, args...),
+		ObjectNumber: f.objectNumber,
+	})
+}
+
+// fixable fixes the violation of `requirement` with `fix`, or reports it when
+// validating.
+func (f *fixer) fixable(requirement Requirement, fix func(), format string, args ...interface{}) {
+	if f.validate {
+		f.report(requirement, format, args...)
+		return
+	}
+	fix()
+}
+
+// walk checks and fixes `obj` and the objects it contains. `key` is the key
+// of `obj` in its containing dictionary and `parentKey` the key of this
+// dictionary. The elements of arrays have the key of the array.
+func (f *fixer) walk(obj core.PdfObject, key, parentKey core.PdfObjectName) {
+	if f.err != nil {
+		return
+	}
+	switch t := obj.(type) {
+	case *core.PdfObjectReference:
+		f.walk(t.Resolve(), key, parentKey)
+	case *core.PdfIndirectObject:
+		if f.isVisited(t) {
+			return
+		}
+		defer f.setObjectNumber(t.ObjectNumber)()
+		f.walk(t.PdfObject, key, parentKey)
+	case *core.PdfObjectStream:
+		if f.isVisited(t) {
+			return
+		}
+		defer f.setObjectNumber(t.ObjectNumber)()
+		f.checkStream(t)
+		f.walkDict(t.PdfObjectDictionary, key, parentKey)
+	case *core.PdfObjectDictionary:
+		if f.isVisited(t) {
+			return
+		}
+		f.walkDict(t, key, parentKey)
+	case *core.PdfObjectArray:
+		for _, elem := range t.Elements() {
+			f.walk(elem, key, parentKey)
+		}
+	}
+}
+
+// walkDict checks and fixes `dict` and walks its entries, see walk.
+func (f *fixer) walkDict(dict *core.PdfObjectDictionary, key, parentKey core.PdfObjectName) {
+	f.checkDict(dict, key, parentKey)
+	for _, k := range dict.Keys() {
+		f.walk(dict.Get(k), k, key)
+	}
+}
+
+// setObjectNumber sets the number of the object being walked to `num` and
+// returns a function restoring the previous number.
+func (f *fixer) setObjectNumber(num int64) func() {
+	prev := f.objectNumber
+	f.objectNumber = num
+	return func() { f.objectNumber = prev }
+}
+
+// isVisited returns true if `obj` was already walked and marks it as walked.
+func (f *fixer) isVisited(obj core.PdfObject) bool {
+	if _, ok := f.visited[obj]; ok {
+		return true
+	}
+	f.visited[obj] = struct{}{}
+	return false
+}
+
+// checkDict checks and fixes the dictionary `dict`, see walk.
+func (f *fixer) checkDict(dict *core.PdfObjectDictionary, key, parentKey core.PdfObjectName) {
+	typ, _ := core.GetNameVal(dict.Get("Type"))
+	if typ == "Catalog" && f.catalog == nil {
+		f.catalog = dict
+		f.catalogNumber = f.objectNumber
+	}
+
+	if dict.Get("AA") != nil {
+		f.fixable(RequirementActions, func() { dict.Remove("AA") }, "additional actions not allowed")
+	}
+
+	switch {
+	case typ == "Font" || parentKey == "Font":
+		f.checkFont(dict)
+	case parentKey == "ExtGState":
+		f.checkExtGState(dict)
+	case key == "Annots" || typ == "Annot":
+		f.checkAnnotation(dict)
+	case key == "AcroForm":
+		if dict.Get("XFA") != nil {
+			f.fixable(RequirementForms, func() { dict.Remove("XFA") }, "XFA forms not allowed")
+		}
+		if needAppearances, ok := core.GetBoolVal(dict.Get("NeedAppearances")); ok && needAppearances {
+			f.report(RequirementForms, "form fields need appearances to be generated")
+		}
+	}
+	if typ == "Action" || key == "A" || key == "OpenAction" || key == "Next" {
+		f.checkAction(dict)
+	}
+	if f.opts.Level == LevelA3B && dict.Get("EF") != nil && (typ == "" || typ == "Filespec") {
+		f.checkEmbeddedFile(dict)
+	}
+
+	if f.opts.Level == LevelA1B {
+		if group, ok := core.GetDict(dict.Get("Group")); ok {
+			if s, _ := core.GetNameVal(group.Get("S")); s == "Transparency" {
+				f.transparency(func() { dict.Remove("Group") }, "transparency group")
+			}
+		}
+	}
+}
+
+// transparency reports the transparency described by `description` or
+// removes it with `remove` if the profile removes transparency.
+func (f *fixer) transparency(remove func(), description string) {
+	if f.opts.RemoveTransparency && !f.validate {
+		remove()
+		return
+	}
+	f.report(RequirementTransparency, "%s not allowed", description)
+}
+
+// checkStream checks and fixes the stream `stream`.
+func (f *fixer) checkStream(stream *core.PdfObjectStream) {
+	if stream.Get("F") != nil {
+		f.report(RequirementStreams, "external stream data not allowed")
+	}
+	if f.opts.Level == LevelA1B {
+		switch typ, _ := core.GetNameVal(stream.Get("Type")); typ {
+		case "ObjStm":
+			f.report(RequirementStreams, "object streams not allowed")
+		case "XRef":
+			f.report(RequirementStreams, "cross-reference streams not allowed")
+		}
+	}
+
+	var filters []string
+	switch t := core.TraceToDirectObject(stream.Get("Filter")).(type) {
+	case *core.PdfObjectName:
+		filters = append(filters, string(*t))
+	case *core.PdfObjectArray:
+		for _, elem := range t.Elements() {
+			if name, ok := core.GetNameVal(elem); ok {
+				filters = append(filters, name)
+			}
+		}
+	}
+	for _, filter := range filters {
+		switch filter {
+		case core.StreamEncodingFilterNameLZW:
+			if len(filters) == 1 && !f.validate {
+				f.recompressLZW(stream)
+			} else {
+				f.report(RequirementStreams, "LZW compression not allowed")
+			}
+		case core.StreamEncodingFilterNameJPX:
+			if f.opts.Level == LevelA1B {
+				f.report(RequirementStreams, "JPEG2000 compression not allowed")
+			}
+		}
+	}
+
+	if subtype, _ := core.GetNameVal(stream.Get("Subtype")); subtype == "Image" {
+		if interpolate, ok := core.GetBoolVal(stream.Get("Interpolate")); ok && interpolate {
+			f.fixable(RequirementImages, func() {
+				stream.Set("Interpolate", core.MakeBool(false))
+			}, "image interpolation not allowed")
+		}
+		if f.opts.Level == LevelA1B && stream.Get("SMask") != nil {
+			f.transparency(func() { stream.Remove("SMask") }, "image soft mask")
+		}
+	}
+}
+
+// recompressLZW replaces the LZW compression of `stream` by Flate.
+func (f *fixer) recompressLZW(stream *core.PdfObjectStream) {
+	decoded, err := core.DecodeStream(stream)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to decode LZW stream: %v", err)
+		f.report(RequirementStreams, "LZW compression not allowed")
+		return
+	}
+	encoded, err := core.NewFlateEncoder().EncodeBytes(decoded)
+	if err != nil {
+		f.err = err
+		return
+	}
+	stream.Stream = encoded
+	stream.Set("Filter", core.MakeName(core.StreamEncodingFilterNameFlate))
+	stream.Remove("DecodeParms")
+	stream.Set("Length", core.MakeInteger(int64(len(encoded))))
+}
+
+// checkFont checks the embedding of the font `dict`, embedding a substitute
+// font program if possible.
+func (f *fixer) checkFont(dict *core.PdfObjectDictionary) {
+	subtype, _ := core.GetNameVal(dict.Get("Subtype"))
+	switch subtype {
+	case "Type3":
+		return
+	case "Type0":
+		// The descendant font is checked.
+		return
+	}
+	if isFontEmbedded(dict) {
+		return
+	}
+
+	name, _ := core.GetNameVal(dict.Get("BaseFont"))
+	if !f.validate && (subtype == "Type1" || subtype == "MMType1" || subtype == "TrueType") {
+		encoding, hasEncoding := core.GetNameVal(dict.Get("Encoding"))
+		if dict.Get("Encoding") == nil || hasEncoding && encoding == "WinAnsiEncoding" {
+			substitute, err := f.substitute(name)
+			if err != nil {
+				f.err = err
+				return
+			}
+			if substitute != nil {
+				for _, key := range append([]core.PdfObjectName{}, dict.Keys()...) {
+					dict.Remove(key)
+				}
+				for _, key := range substitute.Keys() {
+					dict.Set(key, substitute.Get(key))
+				}
+				return
+			}
+		}
+	}
+	f.report(RequirementFonts, "font %s is not embedded", name)
+}
+
+// isFontEmbedded returns true if the program of the simple or CID font `dict`
+// is embedded.
+func isFontEmbedded(dict *core.PdfObjectDictionary) bool {
+	descriptor, ok := core.GetDict(dict.Get("FontDescriptor"))
+	if !ok {
+		return false
+	}
+	for _, key := range []core.PdfObjectName{"FontFile", "FontFile2", "FontFile3"} {
+		if descriptor.Get(key) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// substitute returns the font dictionary of the substitute of the font
+// `name`, or nil if there is none.
+func (f *fixer) substitute(name string) (*core.PdfObjectDictionary, error) {
+	if dict, ok := f.substitutes[name]; ok {
+		return dict, nil
+	}
+	data, ok := f.opts.FontSubstitutes[name]
+	if !ok {
+		return nil, nil
+	}
+	font, err := model.NewPdfFontFromTTF(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := core.GetDict(font.ToPdfObject())
+	if !ok {
+		return nil, fmt.Errorf("invalid substitute of font %s", name)
+	}
+	for _, key := range dict.Keys() {
+		f.addReferenced(dict.Get(key))
+	}
+	f.substitutes[name] = dict
+	return dict, nil
+}
+
+// addReferenced adds the indirect objects within `obj` to the objects written.
+func (f *fixer) addReferenced(obj core.PdfObject) {
+	switch t := obj.(type) {
+	case *core.PdfIndirectObject:
+		if f.isVisited(t) {
+			return
+		}
+		f.added = append(f.added, t)
+		f.addReferenced(t.PdfObject)
+	case *core.PdfObjectStream:
+		if f.isVisited(t) {
+			return
+		}
+		f.added = append(f.added, t)
+		f.addReferenced(t.PdfObjectDictionary)
+	case *core.PdfObjectDictionary:
+		for _, key := range t.Keys() {
+			f.addReferenced(t.Get(key))
+		}
+	case *core.PdfObjectArray:
+		for _, elem := range t.Elements() {
+			f.addReferenced(elem)
+		}
+	}
+}
+
+// checkExtGState checks the graphics state parameter dictionary `dict`.
+func (f *fixer) checkExtGState(dict *core.PdfObjectDictionary) {
+	if f.opts.Level != LevelA1B {
+		return
+	}
+	if smask := dict.Get("SMask"); smask != nil {
+		if name, ok := core.GetNameVal(smask); !ok || name != "None" {
+			f.transparency(func() { dict.Set("SMask", core.MakeName("None")) }, "soft mask")
+		}
+	}
+	for _, key := range []core.PdfObjectName{"CA", "ca"} {
+		if alpha, err := core.GetNumberAsFloat(dict.Get(key)); err == nil && alpha != 1 {
+			f.transparency(func() { dict.Set(key, core.MakeFloat(1)) }, "constant alpha")
+		}
+	}
+	if bm := dict.Get("BM"); bm != nil {
+		if arr, ok := core.GetArray(bm); ok && arr.Len() > 0 {
+			bm = arr.Get(0)
+		}
+		if name, _ := core.GetNameVal(bm); name != "Normal" && name != "Compatible" {
+			f.transparency(func() { dict.Set("BM", core.MakeName("Normal")) }, "blend mode")
+		}
+	}
+}
+
+// Annotation flags.
+const (
+	annotationFlagInvisible    = 1
+	annotationFlagHidden       = 2
+	annotationFlagPrint        = 4
+	annotationFlagNoView       = 32
+	annotationFlagToggleNoView = 256
+)
+
+// checkAnnotation checks and fixes the annotation `dict`.
+func (f *fixer) checkAnnotation(dict *core.PdfObjectDictionary) {
+	subtype, _ := core.GetNameVal(dict.Get("Subtype"))
+	switch subtype {
+	case "Sound", "Movie", "3D", "Screen":
+		f.report(RequirementAnnotations, "%s annotation not allowed", subtype)
+	case "FileAttachment":
+		if f.opts.Level == LevelA1B {
+			f.report(RequirementAnnotations, "%s annotation not allowed", subtype)
+		}
+	}
+
+	if subtype != "Popup" {
+		flags, _ := core.GetIntVal(dict.Get("F"))
+		fixed := flags | annotationFlagPrint
+		fixed &^= annotationFlagInvisible | annotationFlagHidden | annotationFlagNoView | annotationFlagToggleNoView
+		if fixed != flags || dict.Get("F") == nil {
+			f.fixable(RequirementAnnotations, func() {
+				dict.Set("F", core.MakeInteger(int64(fixed)))
+			}, "annotation flags %d hide the annotation or prevent its printing", flags)
+		}
+	}
+
+	if f.opts.Level == LevelA1B {
+		if alpha, err := core.GetNumberAsFloat(dict.Get("CA")); err == nil && alpha != 1 {
+			f.transparency(func() { dict.Remove("CA") }, "annotation constant alpha")
+		}
+	}
+}
+
+// forbiddenActions contains the types of actions not allowed by each level.
+var forbiddenActions = map[Level][]string{
+	LevelA1B: {"Launch", "Sound", "Movie", "ResetForm", "ImportData", "JavaScript"},
+	LevelA2B: {"Launch", "Sound", "Movie", "ResetForm", "ImportData", "JavaScript",
+		"Hide", "SetOCGState", "Rendition", "Trans", "GoTo3DView"},
+	LevelA3B: {"Launch", "Sound", "Movie", "ResetForm", "ImportData", "JavaScript",
+		"Hide", "SetOCGState", "Rendition", "Trans", "GoTo3DView"},
+}
+
+// checkAction checks the action `dict`.
+func (f *fixer) checkAction(dict *core.PdfObjectDictionary) {
+	s, ok := core.GetNameVal(dict.Get("S"))
+	if !ok {
+		return
+	}
+	for _, forbidden := range forbiddenActions[f.opts.Level] {
+		if s == forbidden {
+			f.report(RequirementActions, "%s action not allowed", s)
+			return
+		}
+	}
+	if s == "Named" {
+		switch n, _ := core.GetNameVal(dict.Get("N")); n {
+		case "NextPage", "PrevPage", "FirstPage", "LastPage":
+		default:
+			f.report(RequirementActions, "named action %s not allowed", n)
+		}
+	}
+}
+
+// checkEmbeddedFile checks the file specification `dict` of an embedded
+// file, for PDF/A-3.
+func (f *fixer) checkEmbeddedFile(dict *core.PdfObjectDictionary) {
+	if dict.Get("AFRelationship") == nil {
+		f.fixable(RequirementEmbeddedFiles, func() {
+			dict.Set("AFRelationship", core.MakeName(string(model.AFRelationshipUnspecified)))
+		}, "embedded file without relationship")
+	}
+
+	ef, ok := core.GetDict(dict.Get("EF"))
+	if !ok {
+		return
+	}
+	for _, key := range []core.PdfObjectName{"F", "UF"} {
+		stream, ok := core.GetStream(ef.Get(key))
+		if !ok {
+			continue
+		}
+		if stream.Get("Subtype") == nil {
+			f.fixable(RequirementEmbeddedFiles, func() {
+				stream.Set("Subtype", core.MakeName("application/octet-stream"))
+			}, "embedded file without MIME type")
+		}
+		params, _ := core.GetDict(stream.Get("Params"))
+		if params == nil || params.Get("ModDate") == nil {
+			f.report(RequirementEmbeddedFiles, "embedded file without modification date")
+		}
+		// F and UF generally refer to the same stream.
+		return
+	}
+}
+
+// checkCatalog checks and fixes the entries of the catalog.
+func (f *fixer) checkCatalog() {
+	catalog := f.catalog
+	level := f.opts.Level
+	defer f.setObjectNumber(f.catalogNumber)()
+
+	if names, ok := core.GetDict(catalog.Get("Names")); ok {
+		if names.Get("JavaScript") != nil {
+			f.fixable(RequirementActions, func() { names.Remove("JavaScript") }, "JavaScript not allowed")
+		}
+		if level == LevelA1B && names.Get("EmbeddedFiles") != nil {
+			f.report(RequirementEmbeddedFiles, "embedded files not allowed")
+		}
+	}
+	if level == LevelA1B && catalog.Get("OCProperties") != nil {
+		f.report(RequirementOptionalContent, "optional content not allowed")
+	}
+}
+
+// fixCatalog checks and fixes the catalog, adding the output intent and the
+// metadata of the document.
+func (f *fixer) fixCatalog() error {
+	catalog := f.catalog
+	level := f.opts.Level
+	f.checkCatalog()
+
+	if !hasOutputIntent(catalog) {
+		profile := f.opts.OutputIntentProfile
+		if profile == nil {
+			var err error
+			if profile, err = model.NewICCProfileSRGB(); err != nil {
+				return err
+			}
+		}
+		identifier := f.opts.OutputConditionIdentifier
+		if identifier == "" {
+			identifier = profile.Description
+		}
+		intent := model.NewPdfOutputIntent(model.PdfOutputIntentTypePDFA, identifier, profile)
+		obj := intent.ToPdfObject()
+		intents, ok := core.GetArray(catalog.Get("OutputIntents"))
+		if !ok {
+			intents = core.MakeArray()
+			catalog.Set("OutputIntents", intents)
+		}
+		intents.Append(obj)
+		f.addReferenced(obj)
+	}
+
+	doc := xmp.NewDocument()
+	if stream, ok := core.GetStream(catalog.Get("Metadata")); ok {
+		parsed, err := xmp.NewDocumentFromStream(stream)
+		if err == nil {
+			doc = parsed
+		} else {
+			common.Log.Debug("ERROR: invalid metadata replaced: %v", err)
+		}
+	}
+	if info, ok := core.GetDict(f.w.GetPdfInfo().ToPdfObject()); ok {
+		doc.SyncFromInfo(info)
+	}
+	doc.SetFormat("application/pdf")
+	doc.SetPDFAIdentification(level.Part(), level.Conformance())
+	stream, err := doc.ToStream()
+	if err != nil {
+		return err
+	}
+	catalog.Set("Metadata", stream)
+	f.added = append(f.added, stream)
+	return nil
+}
+
+// hasOutputIntent returns true if `catalog` has a PDF/A output intent.
+func hasOutputIntent(catalog *core.PdfObjectDictionary) bool {
+	intents, ok := core.GetArray(catalog.Get("OutputIntents"))
+	if !ok {
+		return false
+	}
+	for _, obj := range intents.Elements() {
+		intent, ok := core.GetDict(obj)
+		if !ok {
+			continue
+		}
+		s, _ := core.GetNameVal(intent.Get("S"))
+		if strings.EqualFold(s, string(model.PdfOutputIntentTypePDFA)) && intent.Get("DestOutputProfile") != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// Level is a PDF/A conformance level.
+type Level int
+
+// PDF/A conformance levels.
+const (
+	// LevelA1B is PDF/A-1b (ISO 19005-1), based on PDF 1.4.
+	LevelA1B Level = iota + 1
+
+	// LevelA2B is PDF/A-2b (ISO 19005-2), based on PDF 1.7.
+	LevelA2B
+
+	// LevelA3B is PDF/A-3b (ISO 19005-3), based on PDF 1.7. PDF/A-3 differs
+	// from PDF/A-2 by allowing any kind of embedded files, associated to the
+	// document or its parts.
+	LevelA3B
+)
+
+// isValid returns true if `l` is a supported level.
+func (l Level) isValid() bool {
+	return l >= LevelA1B && l <= LevelA3B
+}
+
+// Part returns the part of the PDF/A standard of the level.
+func (l Level) Part() int {
+	return int(l)
+}
+
+// Conformance returns the conformance level within the part of the standard.
+func (l Level) Conformance() string {
+	return "B"
+}
+
+// String returns the name of the level, e.g. "PDF/A-1b".
+func (l Level) String() string {
+	return fmt.Sprintf("PDF/A-%db", l.Part())
+}
+
+// version returns the PDF version of the documents of the level.
+func (l Level) version() (int, int) {
+	if l == LevelA1B {
+		return 1, 4
+	}
+	return 1, 7
+}
+
+// Requirement identifies a group of PDF/A requirements.
+type Requirement string
+
+// PDF/A requirements which cannot always be fixed.
+const (
+	RequirementFonts           Requirement = "fonts"
+	RequirementTransparency    Requirement = "transparency"
+	RequirementActions         Requirement = "actions"
+	RequirementAnnotations     Requirement = "annotations"
+	RequirementOptionalContent Requirement = "optional content"
+	RequirementEmbeddedFiles   Requirement = "embedded files"
+	RequirementForms           Requirement = "forms"
+	RequirementImages          Requirement = "images"
+	RequirementStreams         Requirement = "streams"
+	RequirementMetadata        Requirement = "metadata"
+	RequirementOutputIntent    Requirement = "output intent"
+	RequirementEncryption      Requirement = "encryption"
+	RequirementFileStructure   Requirement = "file structure"
+)
+
+// Violation is a PDF/A requirement a document does not meet.
+type Violation struct {
+	Requirement Requirement
+	Description string
+
+	// ObjectNumber is the number of the object violating the requirement, or
+	// 0 for the requirements on the whole document and when the objects are
+	// not numbered yet.
+	ObjectNumber int64
+}
+
+// String returns a description of the violation.
+func (v Violation) String() string {
+	if v.ObjectNumber != 0 {
+		return fmt.Sprintf("%s: %s (object %d)", v.Requirement, v.Description, v.ObjectNumber)
+	}
+	return fmt.Sprintf("%s: %s", v.Requirement, v.Description)
+}
+
+// Options configures a Profile.
+type Options struct {
+	// Level is the conformance level of the output documents.
+	Level Level
+
+	// OutputIntentProfile is the ICC profile of the output intent added to the
+	// documents without PDF/A output intent. Defaults to sRGB.
+	OutputIntentProfile *model.ICCProfile
+
+	// OutputConditionIdentifier identifies the condition of the output
+	// intent. Defaults to the description of the profile.
+	OutputConditionIdentifier string
+
+	// FontSubstitutes contains TrueType font programs embedded in place of
+	// the simple fonts which are not embedded, by font name (BaseFont), e.g.
+	// a metric compatible font for "Helvetica". The substitutes are used with
+	// the WinAnsiEncoding and are only used for the fonts with this encoding
+	// or without encoding.
+	FontSubstitutes map[string][]byte
+
+	// RemoveTransparency removes the transparency forbidden by PDF/A-1,
+	// changing the appearance of the documents, instead of reporting it.
+	RemoveTransparency bool
+}
+
+// Profile is a writer profile producing PDF/A documents, see Apply.
+type Profile struct {
+	opts       Options
+	w          *model.PdfWriter
+	next       model.Optimizer
+	violations []Violation
+}
+
+// NewProfile returns a new PDF/A profile configured by `opts`.
+func NewProfile(opts Options) *Profile {
+	return &Profile{opts: opts}
+}
+
+// Apply sets up `w` to write PDF/A documents: sets the PDF version, cancels the
+// encryption and makes sure the document identifiers are written. If `w` has
+// no identifiers, they are generated according to the document ID policy
+// DocumentIDRefreshSecond, and are thus deterministic in the deterministic
+// output mode of `w`. The other requirements are
+// checked and fixed when the document is written, by an optimizer running
+// after the optimizer of `w`, if any. Apply should be called just before
+// writing, after any call to the Encrypt, SetVersion and SetOptimizer methods
+// of `w`.
+func (p *Profile) Apply(w *model.PdfWriter) error {
+	if !p.opts.Level.isValid() {
+		return errors.New("unsupported PDF/A level")
+	}
+
+	w.RemoveEncryption()
+	w.SetVersion(p.opts.Level.version())
+	if _, _, ok := w.GetDocumentID(); !ok {
+		// The identifiers are generated by the other policies as well when
+		// none is set.
+		w.SetDocumentIDPolicy(model.DocumentIDRefreshSecond)
+	}
+
+	if optimizer := w.GetOptimizer(); optimizer != p {
+		p.next = optimizer
+	}
+	p.w = w
+	w.SetOptimizer(p)
+	return nil
+}
+
+// Violations returns the PDF/A requirements the last document written did
+// not meet and which could not be fixed.
+func (p *Profile) Violations() []Violation {
+	return p.violations
+}
+
+// Optimize implements the model.Optimizer interface, checking and fixing the
+// objects written.
+func (p *Profile) Optimize(objects []core.PdfObject) ([]core.PdfObject, error) {
+	return p.OptimizeContext(context.Background(), objects)
+}
+
+// OptimizeContext implements the model.ContextOptimizer interface. The
+// optimizer of the writer runs with `ctx` if it implements
+// model.ContextOptimizer.
+func (p *Profile) OptimizeContext(ctx context.Context, objects []core.PdfObject) ([]core.PdfObject, error) {
+	var err error
+	if next, ok := p.next.(model.ContextOptimizer); ok {
+		objects, err = next.OptimizeContext(ctx, objects)
+	} else if p.next != nil {
+		objects, err = p.next.Optimize(objects)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	p.violations = nil
+
+	if p.opts.Level == LevelA1B {
+		// Object streams are not supported by PDF 1.4.
+		objects = unpackObjectStreams(objects)
+	}
+
+	f := newFixer(p.opts, p.w)
+	for _, obj := range objects {
+		f.walk(obj, "", "")
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.catalog == nil {
+		return nil, errors.New("catalog not found")
+	}
+	if err := f.fixCatalog(); err != nil {
+		return nil, err
+	}
+	p.violations = f.violations
+	return append(objects, f.added...), nil
+}
+
+// unpackObjectStreams returns `objects` with the objects contained in object
+// streams in place of the object streams.
+func unpackObjectStreams(objects []core.PdfObject) []core.PdfObject {
+	var unpacked []core.PdfObject
+	for _, obj := range objects {
+		if objStm, ok := obj.(*core.PdfObjectStreams); ok {
+			unpacked = append(unpacked, objStm.Elements()...)
+			continue
+		}
+		unpacked = append(unpacked, obj)
+	}
+	return unpacked
+}
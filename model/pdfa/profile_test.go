@@ -0,0 +1,188 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfa_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/optimize"
+	"github.com/unidoc/unipdf/v3/model/pdfa"
+	"github.com/unidoc/unipdf/v3/model/xmp"
+)
+
+// writeDocument writes an encrypted document with a standard font,
+// transparency, a hidden annotation and LZW compressed content, using
+// `profile` if not nil.
+func writeDocument(t *testing.T, profile *pdfa.Profile) []byte {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	page.Resources.SetFontByName("F1", model.DefaultFont().ToPdfObject())
+	gs := core.MakeDict()
+	gs.Set("ca", core.MakeFloat(0.5))
+	require.NoError(t, page.Resources.AddExtGState("GS1", gs))
+
+	lzw := core.NewLZWEncoder()
+	lzw.EarlyChange = 0
+	content, err := core.MakeStream([]byte("/GS1 gs BT /F1 12 Tf 10 10 Td (Archived) Tj ET"), lzw)
+	require.NoError(t, err)
+	page.Contents = content
+
+	annotation := model.NewPdfAnnotationSquare()
+	annotation.Rect = core.MakeArrayFromFloats([]float64{10, 10, 100, 100})
+	annotation.F = core.MakeInteger(2)
+	page.AddAnnotation(annotation.PdfAnnotation)
+
+	w := model.NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	require.NoError(t, w.Encrypt([]byte("user"), []byte("owner"), nil))
+	w.SetOptimizer(optimize.New(optimize.Options{UseObjectStreams: true}))
+	if profile != nil {
+		require.NoError(t, profile.Apply(&w))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	return buf.Bytes()
+}
+
+func TestProfileViolations(t *testing.T) {
+	profile := pdfa.NewProfile(pdfa.Options{Level: pdfa.LevelA1B})
+	writeDocument(t, profile)
+
+	requirements := map[pdfa.Requirement]bool{}
+	for _, violation := range profile.Violations() {
+		requirements[violation.Requirement] = true
+	}
+	require.Equal(t, map[pdfa.Requirement]bool{
+		pdfa.RequirementFonts:        true,
+		pdfa.RequirementTransparency: true,
+	}, requirements)
+
+	// Transparency is allowed by PDF/A-2.
+	profile = pdfa.NewProfile(pdfa.Options{Level: pdfa.LevelA2B})
+	writeDocument(t, profile)
+	for _, violation := range profile.Violations() {
+		require.Equal(t, pdfa.RequirementFonts, violation.Requirement, violation.String())
+	}
+}
+
+func TestProfileFixes(t *testing.T) {
+	substitute, err := ioutil.ReadFile("../testdata/font/OpenSans-Regular.ttf")
+	require.NoError(t, err)
+	profile := pdfa.NewProfile(pdfa.Options{
+		Level:              pdfa.LevelA1B,
+		FontSubstitutes:    map[string][]byte{"Helvetica": substitute},
+		RemoveTransparency: true,
+	})
+	data := writeDocument(t, profile)
+	require.Empty(t, profile.Violations())
+
+	r, err := model.NewPdfReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	encrypted, err := r.IsEncrypted()
+	require.NoError(t, err)
+	require.False(t, encrypted)
+	require.Equal(t, "1.4", r.PdfVersion().String())
+	trailer, err := r.GetTrailer()
+	require.NoError(t, err)
+	ids, ok := core.GetArray(trailer.Get("ID"))
+	require.True(t, ok)
+	require.Equal(t, 2, ids.Len())
+
+	doc, err := xmp.ReadDocument(r)
+	require.NoError(t, err)
+	require.NotNil(t, doc)
+	part, conformance, ok := doc.PDFAIdentification()
+	require.True(t, ok)
+	require.Equal(t, 1, part)
+	require.Equal(t, "B", conformance)
+
+	intents, err := r.GetOutputIntents()
+	require.NoError(t, err)
+	require.Len(t, intents, 1)
+	require.Equal(t, model.PdfOutputIntentTypePDFA, intents[0].Type)
+	require.NotNil(t, intents[0].DestOutputProfile)
+
+	page, err := r.GetPage(1)
+	require.NoError(t, err)
+	fontObj, found := page.Resources.GetFontByName("F1")
+	require.True(t, found)
+	font, err := model.NewPdfFontFromPdfObject(fontObj)
+	require.NoError(t, err)
+	descriptor := font.FontDescriptor()
+	require.NotNil(t, descriptor)
+	require.NotNil(t, descriptor.FontFile2)
+
+	gs, ok := core.GetDict(page.Resources.ExtGState)
+	require.True(t, ok)
+	gs, ok = core.GetDict(gs.Get("GS1"))
+	require.True(t, ok)
+	alpha, err := core.GetNumberAsFloat(gs.Get("ca"))
+	require.NoError(t, err)
+	require.Equal(t, 1.0, alpha)
+
+	annotations, err := page.GetAnnotations()
+	require.NoError(t, err)
+	require.Len(t, annotations, 1)
+	flags, _ := core.GetIntVal(annotations[0].F)
+	require.Equal(t, 4, flags)
+
+	content, err := page.GetAllContentStreams()
+	require.NoError(t, err)
+	require.Contains(t, content, "(Archived) Tj")
+	require.NotContains(t, string(data), "/LZWDecode")
+	require.NotContains(t, string(data), "/ObjStm")
+}
+
+// contextOptimizer records the calls of its OptimizeContext method.
+type contextOptimizer struct {
+	calls int
+}
+
+func (o *contextOptimizer) Optimize(objects []core.PdfObject) ([]core.PdfObject, error) {
+	return objects, nil
+}
+
+func (o *contextOptimizer) OptimizeContext(ctx context.Context, objects []core.PdfObject) ([]core.PdfObject, error) {
+	o.calls++
+	return objects, ctx.Err()
+}
+
+func TestProfileWriterOptions(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	w := model.NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	id := []byte("0123456789abcdef")
+	w.SetDeterministic(&model.DeterministicOptions{ID: id})
+	optimizer := &contextOptimizer{}
+	w.SetOptimizer(optimizer)
+	require.NoError(t, pdfa.NewProfile(pdfa.Options{Level: pdfa.LevelA2B}).Apply(&w))
+
+	// The generated identifiers follow the deterministic output mode.
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	require.Equal(t, 1, optimizer.calls)
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	id0, id1, ok := r.GetDocumentID()
+	require.True(t, ok)
+	require.Equal(t, id, id0)
+	require.Equal(t, id, id1)
+
+	// The context is forwarded to the optimizer of the writer.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Equal(t, context.Canceled, w.WriteContext(ctx, &bytes.Buffer{}, nil))
+	require.Equal(t, 2, optimizer.calls)
+}
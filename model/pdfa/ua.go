@@ -0,0 +1,177 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfa
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/xmp"
+)
+
+// PDF/UA requirements.
+const (
+	RequirementTagging       Requirement = "tagging"
+	RequirementTitle         Requirement = "title"
+	RequirementLanguage      Requirement = "language"
+	RequirementAlternateText Requirement = "alternate text"
+)
+
+// uaValidator checks the requirements of PDF/UA-1.
+type uaValidator struct {
+	violations []Violation
+
+	// roleMap maps the structure types of the document to the standard
+	// structure types.
+	roleMap *core.PdfObjectDictionary
+}
+
+// report records the violation of `requirement` by the object `num`.
+func (v *uaValidator) report(requirement Requirement, num int64, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{
+		Requirement:  requirement,
+		Description:  fmt.Sprintf(format, args...),
+		ObjectNumber: num,
+	})
+}
+
+// ValidateUA checks the document loaded by `r` against basic requirements of
+// PDF/UA-1 (ISO 14289-1): the document must be tagged, identified as PDF/UA,
+// have a title displayed by the viewers and a language, embed its fonts and
+// provide an alternate text for its figures. The structure of the tags and the
+// content streams are not analyzed.
+func ValidateUA(r *model.PdfReader) (*Report, error) {
+	trailer, err := r.GetTrailer()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.GetNumPages(); err != nil {
+		return nil, err
+	}
+	catalogObj, ok := core.GetIndirect(core.ResolveReference(trailer.Get("Root")))
+	if !ok {
+		return nil, errors.New("catalog not found")
+	}
+	catalog, ok := core.GetDict(catalogObj)
+	if !ok {
+		return nil, errors.New("catalog not found")
+	}
+
+	v := &uaValidator{}
+	v.checkCatalog(catalog, catalogObj.ObjectNumber)
+
+	nums := append([]int{}, r.GetObjectNums()...)
+	sort.Ints(nums)
+	for _, num := range nums {
+		obj, err := r.GetIndirectObjectByNumber(num)
+		if err != nil {
+			common.Log.Debug("ERROR: unable to read object %d: %v", num, err)
+			continue
+		}
+		var dict *core.PdfObjectDictionary
+		switch t := obj.(type) {
+		case *core.PdfIndirectObject:
+			dict, _ = t.PdfObject.(*core.PdfObjectDictionary)
+		case *core.PdfObjectStream:
+			dict = t.PdfObjectDictionary
+		}
+		if dict != nil {
+			v.checkDict(dict, int64(num))
+		}
+	}
+
+	return &Report{Standard: "PDF/UA-1", Violations: v.violations}, nil
+}
+
+// checkCatalog checks the entries of the catalog `catalog`, object `num`.
+func (v *uaValidator) checkCatalog(catalog *core.PdfObjectDictionary, num int64) {
+	marked := false
+	if markInfo, ok := core.GetDict(catalog.Get("MarkInfo")); ok {
+		marked, _ = core.GetBoolVal(markInfo.Get("Marked"))
+	}
+	if !marked {
+		v.report(RequirementTagging, num, "document not marked as tagged")
+	}
+	if tree, ok := core.GetDict(catalog.Get("StructTreeRoot")); ok {
+		v.roleMap, _ = core.GetDict(tree.Get("RoleMap"))
+	} else {
+		v.report(RequirementTagging, num, "structure tree missing")
+	}
+
+	if lang, ok := core.GetString(catalog.Get("Lang")); !ok || lang.Str() == "" {
+		v.report(RequirementLanguage, num, "document language missing")
+	}
+
+	display := false
+	if prefs, ok := core.GetDict(catalog.Get("ViewerPreferences")); ok {
+		display, _ = core.GetBoolVal(prefs.Get("DisplayDocTitle"))
+	}
+	if !display {
+		v.report(RequirementTitle, num, "document title not displayed")
+	}
+
+	stream, ok := core.GetStream(catalog.Get("Metadata"))
+	if !ok {
+		v.report(RequirementMetadata, num, "metadata missing")
+		return
+	}
+	doc, err := xmp.NewDocumentFromStream(stream)
+	if err != nil {
+		v.report(RequirementMetadata, stream.ObjectNumber, "invalid metadata: %v", err)
+		return
+	}
+	if part, _ := doc.GetText(xmp.NamespacePDFUAID, "part"); part != "1" {
+		v.report(RequirementMetadata, stream.ObjectNumber, "PDF/UA identification missing")
+	}
+	if doc.Title() == "" {
+		v.report(RequirementTitle, stream.ObjectNumber, "document title missing")
+	}
+}
+
+// checkDict checks the dictionary of the object `num`.
+func (v *uaValidator) checkDict(dict *core.PdfObjectDictionary, num int64) {
+	typ, _ := core.GetNameVal(dict.Get("Type"))
+	switch typ {
+	case "Font":
+		switch subtype, _ := core.GetNameVal(dict.Get("Subtype")); subtype {
+		case "Type0", "Type3":
+			// The descendant fonts are checked and the glyphs of Type3 fonts
+			// are in the document.
+		default:
+			if !isFontEmbedded(dict) {
+				name, _ := core.GetNameVal(dict.Get("BaseFont"))
+				v.report(RequirementFonts, num, "font %s is not embedded", name)
+			}
+		}
+	case "StructElem":
+		if v.structureType(dict) != "Figure" {
+			return
+		}
+		if dict.Get("Alt") == nil && dict.Get("ActualText") == nil {
+			v.report(RequirementAlternateText, num, "figure without alternate text")
+		}
+	}
+}
+
+// structureType returns the standard structure type of the structure element
+// `dict`, following the role map.
+func (v *uaValidator) structureType(dict *core.PdfObjectDictionary) string {
+	s, _ := core.GetNameVal(dict.Get("S"))
+	seen := map[string]bool{}
+	for v.roleMap != nil && !seen[s] {
+		seen[s] = true
+		mapped, ok := core.GetNameVal(v.roleMap.Get(core.PdfObjectName(s)))
+		if !ok {
+			break
+		}
+		s = mapped
+	}
+	return s
+}
@@ -0,0 +1,126 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfa
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/xmp"
+)
+
+// Report is the result of the validation of a document against a standard.
+type Report struct {
+	// Standard is the name of the standard, e.g. "PDF/A-1b".
+	Standard string
+
+	// Violations contains the requirements of the standard the document does
+	// not meet.
+	Violations []Violation
+}
+
+// IsCompliant returns true if the document validated meets all the
+// requirements checked.
+func (r *Report) IsCompliant() bool {
+	return len(r.Violations) == 0
+}
+
+// Validate checks the document loaded by `r` against the requirements of the
+// PDF/A conformance level `level` and returns a report of the violations found,
+// with the numbers of the objects violating the requirements. The validation
+// covers the requirements checked and fixed by Profile, the output intent,
+// the identification metadata and the file structure; the content streams are
+// not analyzed. Encrypted documents are only checked further once decrypted.
+func Validate(r *model.PdfReader, level Level) (*Report, error) {
+	if !level.isValid() {
+		return nil, errors.New("unsupported PDF/A level")
+	}
+	trailer, err := r.GetTrailer()
+	if err != nil {
+		return nil, err
+	}
+
+	f := newFixer(Options{Level: level}, nil)
+	f.validate = true
+	report := &Report{Standard: level.String()}
+
+	if trailer.Get("Encrypt") != nil {
+		f.report(RequirementEncryption, "encryption not allowed")
+	}
+	if _, err := r.GetNumPages(); err != nil {
+		// The objects of encrypted documents cannot be read.
+		report.Violations = f.violations
+		return report, nil
+	}
+	if ids, ok := core.GetArray(trailer.Get("ID")); !ok || ids.Len() != 2 {
+		f.report(RequirementFileStructure, "document identifier missing")
+	}
+
+	// The objects are walked from the trailer first, for the context of the
+	// objects to be known.
+	f.walk(trailer, "", "")
+	nums := append([]int{}, r.GetObjectNums()...)
+	sort.Ints(nums)
+	for _, num := range nums {
+		obj, err := r.GetIndirectObjectByNumber(num)
+		if err != nil {
+			common.Log.Debug("ERROR: unable to read object %d: %v", num, err)
+			f.objectNumber = int64(num)
+			f.report(RequirementFileStructure, "object cannot be read")
+			f.objectNumber = 0
+			continue
+		}
+		f.walk(obj, "", "")
+	}
+	if f.catalog == nil {
+		return nil, errors.New("catalog not found")
+	}
+	f.checkCatalog()
+	f.checkIdentification()
+
+	report.Violations = f.violations
+	return report, nil
+}
+
+// checkIdentification reports the catalog of a validated document missing
+// the PDF/A output intent or the PDF/A identification metadata.
+func (f *fixer) checkIdentification() {
+	catalog := f.catalog
+	level := f.opts.Level
+	defer f.setObjectNumber(f.catalogNumber)()
+
+	if !hasOutputIntent(catalog) {
+		f.report(RequirementOutputIntent, "PDF/A output intent missing")
+	}
+
+	stream, ok := core.GetStream(catalog.Get("Metadata"))
+	if !ok {
+		f.report(RequirementMetadata, "metadata missing")
+		return
+	}
+	defer f.setObjectNumber(stream.ObjectNumber)()
+	if level == LevelA1B && stream.Get("Filter") != nil {
+		f.report(RequirementMetadata, "metadata stream compression not allowed")
+	}
+	doc, err := xmp.NewDocumentFromStream(stream)
+	if err != nil {
+		f.report(RequirementMetadata, "invalid metadata: %v", err)
+		return
+	}
+	part, conformance, ok := doc.PDFAIdentification()
+	if !ok {
+		f.report(RequirementMetadata, "PDF/A identification missing")
+		return
+	}
+	if part != level.Part() || !strings.EqualFold(conformance, level.Conformance()) {
+		f.report(RequirementMetadata, "PDF/A identification %d%s does not match %s",
+			part, conformance, level)
+	}
+}
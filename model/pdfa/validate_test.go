@@ -0,0 +1,217 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfa_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/pdfa"
+	"github.com/unidoc/unipdf/v3/model/xmp"
+)
+
+// requirements returns the requirements violated in `report`.
+func requirements(report *pdfa.Report) map[pdfa.Requirement]bool {
+	violated := map[pdfa.Requirement]bool{}
+	for _, violation := range report.Violations {
+		violated[violation.Requirement] = true
+	}
+	return violated
+}
+
+func TestValidate(t *testing.T) {
+	data := writeDocument(t, nil)
+	r, err := model.NewPdfReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	// Only the encryption is reported until the document is decrypted.
+	report, err := pdfa.Validate(r, pdfa.LevelA1B)
+	require.NoError(t, err)
+	require.Equal(t, "PDF/A-1b", report.Standard)
+	require.False(t, report.IsCompliant())
+	require.Equal(t, map[pdfa.Requirement]bool{pdfa.RequirementEncryption: true}, requirements(report))
+
+	ok, err := r.Decrypt([]byte("user"))
+	require.NoError(t, err)
+	require.True(t, ok)
+	report, err = pdfa.Validate(r, pdfa.LevelA1B)
+	require.NoError(t, err)
+	violated := requirements(report)
+	for _, requirement := range []pdfa.Requirement{
+		pdfa.RequirementEncryption,
+		pdfa.RequirementFonts,
+		pdfa.RequirementTransparency,
+		pdfa.RequirementAnnotations,
+		pdfa.RequirementStreams,
+		pdfa.RequirementOutputIntent,
+		pdfa.RequirementMetadata,
+	} {
+		require.True(t, violated[requirement], requirement)
+	}
+	for _, violation := range report.Violations {
+		if violation.Requirement == pdfa.RequirementFonts {
+			require.NotZero(t, violation.ObjectNumber)
+			require.Contains(t, violation.String(), "(object ")
+		}
+	}
+
+	// Transparency is allowed by PDF/A-2.
+	report, err = pdfa.Validate(r, pdfa.LevelA2B)
+	require.NoError(t, err)
+	violated = requirements(report)
+	require.False(t, violated[pdfa.RequirementTransparency])
+	require.True(t, violated[pdfa.RequirementFonts])
+}
+
+func TestValidateProfile(t *testing.T) {
+	substitute, err := ioutil.ReadFile("../testdata/font/OpenSans-Regular.ttf")
+	require.NoError(t, err)
+	profile := pdfa.NewProfile(pdfa.Options{
+		Level:              pdfa.LevelA1B,
+		FontSubstitutes:    map[string][]byte{"Helvetica": substitute},
+		RemoveTransparency: true,
+	})
+	data := writeDocument(t, profile)
+
+	r, err := model.NewPdfReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	report, err := pdfa.Validate(r, pdfa.LevelA1B)
+	require.NoError(t, err)
+	require.True(t, report.IsCompliant(), "%v", report.Violations)
+
+	// The identification does not match PDF/A-2.
+	report, err = pdfa.Validate(r, pdfa.LevelA2B)
+	require.NoError(t, err)
+	require.Equal(t, map[pdfa.Requirement]bool{pdfa.RequirementMetadata: true}, requirements(report))
+}
+
+// tagger is an optimizer tagging the document written, with a figure
+// without alternate text.
+type tagger struct{}
+
+func (tagger) Optimize(objects []core.PdfObject) ([]core.PdfObject, error) {
+	for _, obj := range objects {
+		catalog, ok := core.GetDict(obj)
+		if !ok {
+			continue
+		}
+		if typ, _ := core.GetNameVal(catalog.Get("Type")); typ != "Catalog" {
+			continue
+		}
+		markInfo := core.MakeDict()
+		markInfo.Set("Marked", core.MakeBool(true))
+		catalog.Set("MarkInfo", markInfo)
+		catalog.Set("Lang", core.MakeString("en"))
+		prefs := core.MakeDict()
+		prefs.Set("DisplayDocTitle", core.MakeBool(true))
+		catalog.Set("ViewerPreferences", prefs)
+
+		roleMap := core.MakeDict()
+		roleMap.Set("Photo", core.MakeName("Figure"))
+		tree := core.MakeDict()
+		tree.Set("Type", core.MakeName("StructTreeRoot"))
+		tree.Set("RoleMap", roleMap)
+		treeObj := core.MakeIndirectObject(tree)
+		elem := core.MakeDict()
+		elem.Set("Type", core.MakeName("StructElem"))
+		elem.Set("S", core.MakeName("Photo"))
+		elem.Set("P", treeObj)
+		elemObj := core.MakeIndirectObject(elem)
+		tree.Set("K", elemObj)
+		catalog.Set("StructTreeRoot", treeObj)
+		return append(objects, treeObj, elemObj), nil
+	}
+	return objects, nil
+}
+
+func TestValidateUA(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	w := model.NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	report, err := pdfa.ValidateUA(r)
+	require.NoError(t, err)
+	require.Equal(t, "PDF/UA-1", report.Standard)
+	violated := requirements(report)
+	for _, requirement := range []pdfa.Requirement{
+		pdfa.RequirementTagging,
+		pdfa.RequirementLanguage,
+		pdfa.RequirementTitle,
+		pdfa.RequirementMetadata,
+	} {
+		require.True(t, violated[requirement], requirement)
+	}
+
+	doc := xmp.NewDocument()
+	doc.SetTitle("Tagged")
+	doc.SetText(xmp.NamespacePDFUAID, "part", "1")
+	w = model.NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	require.NoError(t, xmp.WriteDocument(&w, doc))
+	w.SetOptimizer(tagger{})
+	buf.Reset()
+	require.NoError(t, w.Write(&buf))
+
+	r, err = model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	report, err = pdfa.ValidateUA(r)
+	require.NoError(t, err)
+	var figures int
+	for _, violation := range report.Violations {
+		switch violation.Requirement {
+		case pdfa.RequirementAlternateText:
+			require.NotZero(t, violation.ObjectNumber)
+			figures++
+		case pdfa.RequirementFonts:
+			// Fonts added by the writer.
+		default:
+			t.Fatalf("unexpected violation: %s", violation)
+		}
+	}
+	require.Equal(t, 1, figures)
+}
+
+func TestValidateEmbeddedFiles(t *testing.T) {
+	fs, err := model.NewPdfFilespecFromEmbeddedFile(&model.EmbeddedFile{Name: "data.csv", Content: []byte("a,b")})
+	require.NoError(t, err)
+	w := model.NewPdfWriter()
+	require.NoError(t, w.AddPage(model.NewPdfPage()))
+	require.NoError(t, w.SetEmbeddedFiles([]*model.EmbeddedFileEntry{{Key: "data.csv", Filespec: fs}}))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	report, err := pdfa.Validate(r, pdfa.LevelA3B)
+	require.NoError(t, err)
+	var descriptions []string
+	for _, violation := range report.Violations {
+		if violation.Requirement == pdfa.RequirementEmbeddedFiles {
+			require.NotZero(t, violation.ObjectNumber)
+			descriptions = append(descriptions, violation.Description)
+		}
+	}
+	require.Equal(t, []string{
+		"embedded file without relationship",
+		"embedded file without MIME type",
+		"embedded file without modification date",
+	}, descriptions)
+
+	// Embedded files are only checked by PDF/A-1 and PDF/A-3.
+	report, err = pdfa.Validate(r, pdfa.LevelA2B)
+	require.NoError(t, err)
+	require.False(t, requirements(report)[pdfa.RequirementEmbeddedFiles])
+}
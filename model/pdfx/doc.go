@@ -0,0 +1,12 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package pdfx prepares documents for print production according to the
+// PDF/X standards (ISO 15930): Setup adds the PDF/X output intent and
+// identification to the documents written, SetTrimBox sets the page
+// boundaries required by PDF/X and Preflight checks existing documents for
+// the most common problems, such as RGB content and missing page boxes,
+// before sending them to a printer.
+package pdfx
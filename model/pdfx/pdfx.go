@@ -0,0 +1,198 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfx
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// Version is a version of the PDF/X standard.
+type Version string
+
+// PDF/X versions.
+const (
+	// VersionX1a is PDF/X-1a:2003 (ISO 15930-4), allowing CMYK, gray and spot
+	// colors only.
+	VersionX1a Version = "PDF/X-1a:2003"
+
+	// VersionX3 is PDF/X-3:2003 (ISO 15930-6), allowing color managed RGB
+	// and Lab colors.
+	VersionX3 Version = "PDF/X-3:2003"
+
+	// VersionX4 is PDF/X-4 (ISO 15930-7), allowing color managed RGB and Lab
+	// colors, transparency and optional content.
+	VersionX4 Version = "PDF/X-4"
+)
+
+// isValid returns true if `v` is a supported version.
+func (v Version) isValid() bool {
+	switch v {
+	case VersionX1a, VersionX3, VersionX4:
+		return true
+	}
+	return false
+}
+
+// pdfVersion returns the PDF version of the documents of the version.
+func (v Version) pdfVersion() (int, int) {
+	if v == VersionX4 {
+		return 1, 6
+	}
+	return 1, 4
+}
+
+// infoVersion returns the GTS_PDFXVersion entry of the document information
+// dictionary identifying the version.
+func (v Version) infoVersion() string {
+	if v == VersionX1a {
+		return "PDF/X-1:2003"
+	}
+	return string(v)
+}
+
+// infoConformance returns the GTS_PDFXConformance entry of the document
+// information dictionary identifying the version, if required.
+func (v Version) infoConformance() string {
+	if v == VersionX1a {
+		return string(v)
+	}
+	return ""
+}
+
+// defaultRegistryName is the registry of the characterized printing
+// conditions.
+const defaultRegistryName = "http://www.color.org"
+
+// Options describes the PDF/X version and the printing condition of the
+// documents set up with Setup.
+type Options struct {
+	// Version is the PDF/X version of the documents.
+	Version Version
+
+	// OutputConditionIdentifier identifies the printing condition, e.g. a
+	// characterization name from the ICC registry such as "FOGRA39"
+	// (Required).
+	OutputConditionIdentifier string
+
+	// OutputCondition is a human readable description of the printing
+	// condition.
+	OutputCondition string
+
+	// RegistryName is the registry of the condition identifier. Defaults to
+	// "http://www.color.org".
+	RegistryName string
+
+	// OutputIntentProfile is the ICC profile of the printing condition,
+	// required for conditions not in the registry.
+	OutputIntentProfile *model.ICCProfile
+
+	// Trapped is the trapping state of the documents, which must be known.
+	// Defaults to model.TrappedFalse.
+	Trapped model.PdfInfoTrapped
+}
+
+// NewOutputIntent returns the PDF/X output intent of the printing condition
+// described by `opts`.
+func NewOutputIntent(opts Options) *model.PdfOutputIntent {
+	intent := model.NewPdfOutputIntent(model.PdfOutputIntentTypePDFX,
+		opts.OutputConditionIdentifier, opts.OutputIntentProfile)
+	intent.OutputCondition = opts.OutputCondition
+	intent.RegistryName = opts.RegistryName
+	if intent.RegistryName == "" {
+		intent.RegistryName = defaultRegistryName
+	}
+	return intent
+}
+
+// Setup sets up `w` to write PDF/X documents as described by `opts`: sets the
+// PDF version, adds the PDF/X output intent and sets the PDF/X identification
+// and the trapping state in the document information. The pages must meet
+// the other requirements, see SetTrimBox and Preflight.
+func Setup(w *model.PdfWriter, opts Options) error {
+	if !opts.Version.isValid() {
+		return errors.New("unsupported PDF/X version")
+	}
+	if opts.OutputConditionIdentifier == "" {
+		return errors.New("output condition identifier required")
+	}
+	switch opts.Trapped {
+	case model.TrappedUnset:
+		opts.Trapped = model.TrappedFalse
+	case model.TrappedTrue, model.TrappedFalse:
+	default:
+		return errors.New("trapping state must be True or False")
+	}
+
+	w.SetVersion(opts.Version.pdfVersion())
+	if err := w.AddOutputIntent(NewOutputIntent(opts)); err != nil {
+		return err
+	}
+
+	info := w.GetPdfInfo()
+	info.Trapped = opts.Trapped
+	if err := info.SetCustomInfo("GTS_PDFXVersion", opts.Version.infoVersion()); err != nil {
+		return err
+	}
+	if err := info.SetCustomInfo("GTS_PDFXConformance", opts.Version.infoConformance()); err != nil {
+		return err
+	}
+	w.SetPdfInfo(info)
+	return nil
+}
+
+// SetTrimBox sets the trim box of `page` to `trim`, or to the crop box of the
+// page if `trim` is nil, and the bleed box to the trim box extended by `bleed`
+// on each side, within the media box. The art box is removed, as PDF/X does
+// not allow pages with both a trim box and an art box.
+func SetTrimBox(page *model.PdfPage, trim *model.PdfRectangle, bleed float64) error {
+	mbox, err := page.GetBox(model.PageBoxMedia)
+	if err != nil {
+		return err
+	}
+	if trim == nil {
+		if trim, err = page.GetCropBox(); err != nil {
+			return err
+		}
+	}
+	trim = trim.Normalized()
+	if !contains(mbox, trim) {
+		return errors.New("trim box outside of media box")
+	}
+	if bleed < 0 {
+		return errors.New("negative bleed")
+	}
+
+	if err := page.SetBox(model.PageBoxTrim, trim); err != nil {
+		return err
+	}
+	if err := page.SetBox(model.PageBoxArt, nil); err != nil {
+		return err
+	}
+	if bleed == 0 {
+		return page.SetBox(model.PageBoxBleed, nil)
+	}
+	bbox, _ := mbox.Intersect(&model.PdfRectangle{
+		Llx: trim.Llx - bleed,
+		Lly: trim.Lly - bleed,
+		Urx: trim.Urx + bleed,
+		Ury: trim.Ury + bleed,
+	})
+	return page.SetBox(model.PageBoxBleed, bbox)
+}
+
+// boxTolerance is the tolerance of the comparisons of the page boxes, in
+// points.
+const boxTolerance = 0.01
+
+// contains returns true if the rectangle `outer` contains the rectangle
+// `inner`.
+func contains(outer, inner *model.PdfRectangle) bool {
+	outer, inner = outer.Normalized(), inner.Normalized()
+	return inner.Llx >= outer.Llx-boxTolerance && inner.Lly >= outer.Lly-boxTolerance &&
+		inner.Urx <= outer.Urx+boxTolerance && inner.Ury <= outer.Ury+boxTolerance
+}
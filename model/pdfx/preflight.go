@@ -0,0 +1,422 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfx
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// Check identifies a group of preflight checks.
+type Check string
+
+// Preflight checks.
+const (
+	CheckOutputIntent Check = "output intent"
+	CheckInfo         Check = "document information"
+	CheckEncryption   Check = "encryption"
+	CheckBoxes        Check = "page boxes"
+	CheckColor        Check = "color"
+)
+
+// Issue is a problem found by Preflight.
+type Issue struct {
+	Check       Check
+	Description string
+
+	// Page is the number of the page with the problem, starting from 1, or 0
+	// for the problems of the whole document.
+	Page int
+}
+
+// String returns a description of the issue.
+func (i Issue) String() string {
+	if i.Page != 0 {
+		return fmt.Sprintf("%s: page %d: %s", i.Check, i.Page, i.Description)
+	}
+	return fmt.Sprintf("%s: %s", i.Check, i.Description)
+}
+
+// Report is the result of Preflight.
+type Report struct {
+	// Version is the PDF/X version the document was checked against.
+	Version Version
+
+	// Issues contains the problems found.
+	Issues []Issue
+}
+
+// IsCompliant returns true if no problem was found.
+func (r *Report) IsCompliant() bool {
+	return len(r.Issues) == 0
+}
+
+// Preflight checks the document loaded by `r` for the problems preventing
+// its printing as a PDF/X document of version `version`: missing or invalid
+// output intent, identification and trapping state, encryption, pages
+// without trim box or with inconsistent page boxes, and RGB colors not
+// allowed by the version, in the color spaces, images, shadings, patterns
+// and content streams of the pages. Fonts and transparency are not checked.
+func Preflight(r *model.PdfReader, version Version) (*Report, error) {
+	if !version.isValid() {
+		return nil, errors.New("unsupported PDF/X version")
+	}
+	p := &preflighter{report: &Report{Version: version}}
+
+	encrypted, err := r.IsEncrypted()
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		p.issue(CheckEncryption, "encryption not allowed")
+	}
+	numPages, err := r.GetNumPages()
+	if err != nil {
+		// The objects of encrypted documents cannot be read.
+		return p.report, nil
+	}
+
+	if err := p.checkOutputIntent(r); err != nil {
+		return nil, err
+	}
+	if err := p.checkInfo(r); err != nil {
+		return nil, err
+	}
+	for i := 1; i <= numPages; i++ {
+		page, err := r.GetPage(i)
+		if err != nil {
+			return nil, err
+		}
+		p.page = i
+		p.checkBoxes(page)
+		p.checkColors(page)
+	}
+	return p.report, nil
+}
+
+// preflighter checks a document, see Preflight.
+type preflighter struct {
+	report *Report
+
+	// rgbIntent is set if the output intent has an RGB profile, making the
+	// device RGB colors allowed by PDF/X-3 and PDF/X-4.
+	rgbIntent bool
+
+	page    int // Number of the page checked.
+	visited map[core.PdfObject]struct{}
+}
+
+// issue reports a problem of the page checked.
+func (p *preflighter) issue(check Check, format string, args ...interface{}) {
+	p.report.Issues = append(p.report.Issues, Issue{
+		Check:       check,
+		Description: fmt.Sprintf(format, args...),
+		Page:        p.page,
+	})
+}
+
+// checkOutputIntent checks the PDF/X output intent.
+func (p *preflighter) checkOutputIntent(r *model.PdfReader) error {
+	intents, err := r.GetOutputIntents()
+	if err != nil {
+		return err
+	}
+	var intent *model.PdfOutputIntent
+	for _, oi := range intents {
+		if oi.Type == model.PdfOutputIntentTypePDFX {
+			intent = oi
+			break
+		}
+	}
+	if intent == nil {
+		p.issue(CheckOutputIntent, "PDF/X output intent missing")
+		return nil
+	}
+	if intent.OutputConditionIdentifier == "" {
+		p.issue(CheckOutputIntent, "output condition identifier missing")
+	}
+	profile := intent.DestOutputProfile
+	if profile == nil && intent.RegistryName == "" {
+		p.issue(CheckOutputIntent, "output intent without profile for a condition not in a registry")
+	}
+	if profile != nil && profile.ColorSpace == model.ICCColorSpaceRGB {
+		if p.report.Version == VersionX1a {
+			p.issue(CheckOutputIntent, "RGB output intent profile not allowed")
+		}
+		p.rgbIntent = true
+	}
+	return nil
+}
+
+// checkInfo checks the PDF/X identification and the trapping state.
+func (p *preflighter) checkInfo(r *model.PdfReader) error {
+	info, err := r.GetPdfInfo()
+	if err != nil {
+		return err
+	}
+	version := p.report.Version
+	if v := info.CustomInfo("GTS_PDFXVersion"); v == "" {
+		p.issue(CheckInfo, "PDF/X version missing")
+	} else if v != version.infoVersion() {
+		p.issue(CheckInfo, "PDF/X version %q does not match %s", v, version)
+	}
+	if conformance := version.infoConformance(); conformance != "" &&
+		info.CustomInfo("GTS_PDFXConformance") != conformance {
+		p.issue(CheckInfo, "PDF/X conformance %s missing", conformance)
+	}
+	if info.Trapped != model.TrappedTrue && info.Trapped != model.TrappedFalse {
+		p.issue(CheckInfo, "trapping state must be True or False")
+	}
+	return nil
+}
+
+// checkBoxes checks the page boxes of `page`.
+func (p *preflighter) checkBoxes(page *model.PdfPage) {
+	mbox, err := page.GetMediaBox()
+	if err != nil {
+		p.issue(CheckBoxes, "media box missing")
+		return
+	}
+	switch {
+	case page.TrimBox == nil && page.ArtBox == nil:
+		p.issue(CheckBoxes, "trim box missing")
+	case page.TrimBox != nil && page.ArtBox != nil:
+		p.issue(CheckBoxes, "both trim box and art box set")
+	}
+
+	boxes := []struct {
+		box  model.PageBox
+		rect *model.PdfRectangle
+	}{
+		{model.PageBoxCrop, page.CropBox},
+		{model.PageBoxBleed, page.BleedBox},
+		{model.PageBoxTrim, page.TrimBox},
+		{model.PageBoxArt, page.ArtBox},
+	}
+	for _, b := range boxes {
+		if b.rect != nil && !contains(mbox, b.rect) {
+			p.issue(CheckBoxes, "%s outside of media box", b.box)
+		}
+	}
+	if page.BleedBox != nil {
+		for _, rect := range []*model.PdfRectangle{page.TrimBox, page.ArtBox} {
+			if rect != nil && !contains(page.BleedBox, rect) {
+				p.issue(CheckBoxes, "trim box outside of bleed box")
+				break
+			}
+		}
+	}
+}
+
+// rgbKind classifies the RGB color spaces.
+type rgbKind int
+
+const (
+	rgbNone       rgbKind = iota
+	rgbDevice             // DeviceRGB.
+	rgbCalibrated         // ICC based RGB, CalRGB and Lab.
+)
+
+// classifyColorSpace returns the kind of RGB of the color space `obj`.
+func classifyColorSpace(obj core.PdfObject) rgbKind {
+	obj = core.TraceToDirectObject(obj)
+	if name, ok := core.GetNameVal(obj); ok {
+		switch name {
+		case "DeviceRGB", "RGB":
+			return rgbDevice
+		}
+		return rgbNone
+	}
+
+	arr, ok := core.GetArray(obj)
+	if !ok || arr.Len() == 0 {
+		return rgbNone
+	}
+	family, _ := core.GetNameVal(arr.Get(0))
+	switch family {
+	case "CalRGB", "Lab":
+		return rgbCalibrated
+	case "ICCBased":
+		if stream, ok := core.GetStream(arr.Get(1)); ok {
+			if n, ok := core.GetIntVal(stream.Get("N")); ok && n == 3 {
+				return rgbCalibrated
+			}
+		}
+	case "Indexed", "I", "Pattern":
+		if arr.Len() > 1 {
+			return classifyColorSpace(arr.Get(1))
+		}
+	case "DeviceRGB", "RGB":
+		return rgbDevice
+	}
+	return rgbNone
+}
+
+// checkColorSpace reports the color space `obj` of `what` if not allowed.
+func (p *preflighter) checkColorSpace(obj core.PdfObject, what string) {
+	switch classifyColorSpace(obj) {
+	case rgbDevice:
+		if p.report.Version == VersionX1a || !p.rgbIntent {
+			p.issue(CheckColor, "%s uses device RGB", what)
+		}
+	case rgbCalibrated:
+		if p.report.Version == VersionX1a {
+			p.issue(CheckColor, "%s uses calibrated RGB or Lab", what)
+		}
+	}
+}
+
+// checkColors checks the colors used by `page`.
+func (p *preflighter) checkColors(page *model.PdfPage) {
+	p.visited = map[core.PdfObject]struct{}{}
+	if page.Resources != nil {
+		if res, ok := core.GetDict(page.Resources.ToPdfObject()); ok {
+			p.checkResources(res)
+		}
+	}
+	content, err := page.GetAllContentStreams()
+	if err != nil {
+		common.Log.Debug("ERROR: unable to read page %d content: %v", p.page, err)
+		return
+	}
+	p.checkContent(content, "page content")
+}
+
+// isVisited returns true if `obj` was already checked for the page, marking
+// it visited otherwise.
+func (p *preflighter) isVisited(obj core.PdfObject) bool {
+	if _, ok := p.visited[obj]; ok {
+		return true
+	}
+	p.visited[obj] = struct{}{}
+	return false
+}
+
+// checkResources checks the color spaces, images, forms, shadings and
+// patterns of the resource dictionary `res`.
+func (p *preflighter) checkResources(res *core.PdfObjectDictionary) {
+	if p.isVisited(res) {
+		return
+	}
+	if spaces, ok := core.GetDict(res.Get("ColorSpace")); ok {
+		for _, name := range spaces.Keys() {
+			p.checkColorSpace(spaces.Get(name), fmt.Sprintf("color space %s", name))
+		}
+	}
+	if shadings, ok := core.GetDict(res.Get("Shading")); ok {
+		for _, name := range shadings.Keys() {
+			p.checkShading(shadings.Get(name), fmt.Sprintf("shading %s", name))
+		}
+	}
+	if patterns, ok := core.GetDict(res.Get("Pattern")); ok {
+		for _, name := range patterns.Keys() {
+			p.checkPattern(patterns.Get(name), fmt.Sprintf("pattern %s", name))
+		}
+	}
+	if xobjects, ok := core.GetDict(res.Get("XObject")); ok {
+		for _, name := range xobjects.Keys() {
+			p.checkXObject(xobjects.Get(name), fmt.Sprintf("XObject %s", name))
+		}
+	}
+}
+
+// checkShading checks the shading `obj` named `what`.
+func (p *preflighter) checkShading(obj core.PdfObject, what string) {
+	var dict *core.PdfObjectDictionary
+	switch t := core.TraceToDirectObject(obj).(type) {
+	case *core.PdfObjectDictionary:
+		dict = t
+	case *core.PdfObjectStream:
+		dict = t.PdfObjectDictionary
+	default:
+		return
+	}
+	if !p.isVisited(dict) {
+		p.checkColorSpace(dict.Get("ColorSpace"), what)
+	}
+}
+
+// checkPattern checks the pattern `obj` named `what`.
+func (p *preflighter) checkPattern(obj core.PdfObject, what string) {
+	switch t := core.TraceToDirectObject(obj).(type) {
+	case *core.PdfObjectDictionary:
+		if !p.isVisited(t) {
+			p.checkShading(t.Get("Shading"), what)
+		}
+	case *core.PdfObjectStream:
+		p.checkForm(t, what)
+	}
+}
+
+// checkXObject checks the image or form XObject `obj` named `what`.
+func (p *preflighter) checkXObject(obj core.PdfObject, what string) {
+	stream, ok := core.GetStream(obj)
+	if !ok {
+		return
+	}
+	switch subtype, _ := core.GetNameVal(stream.Get("Subtype")); subtype {
+	case "Image":
+		if !p.isVisited(stream) {
+			p.checkColorSpace(stream.Get("ColorSpace"), what)
+		}
+	case "Form":
+		if group, ok := core.GetDict(stream.Get("Group")); ok {
+			p.checkColorSpace(group.Get("CS"), what+" group")
+		}
+		p.checkForm(stream, what)
+	}
+}
+
+// checkForm checks the resources and the content of the form or tiling
+// pattern `stream` named `what`.
+func (p *preflighter) checkForm(stream *core.PdfObjectStream, what string) {
+	if p.isVisited(stream) {
+		return
+	}
+	if res, ok := core.GetDict(stream.Get("Resources")); ok {
+		p.checkResources(res)
+	}
+	content, err := core.DecodeStream(stream)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to decode %s: %v", what, err)
+		return
+	}
+	p.checkContent(string(content), what)
+}
+
+// checkContent checks the colors set by the operators of the content stream
+// `content` of `what`. Each problem is reported once.
+func (p *preflighter) checkContent(content, what string) {
+	ops, err := contentstream.NewContentStreamParser(content).Parse()
+	if err != nil {
+		common.Log.Debug("ERROR: unable to parse %s: %v", what, err)
+		return
+	}
+	var rgbOp, inlineImage bool
+	for _, op := range *ops {
+		switch op.Operand {
+		case "rg", "RG":
+			if !rgbOp {
+				rgbOp = true
+				p.checkColorSpace(core.MakeName("DeviceRGB"), what)
+			}
+		case "BI":
+			if len(op.Params) != 1 || inlineImage {
+				continue
+			}
+			if img, ok := op.Params[0].(*contentstream.ContentStreamInlineImage); ok &&
+				classifyColorSpace(img.ColorSpace) != rgbNone {
+				inlineImage = true
+				p.checkColorSpace(img.ColorSpace, "inline image of "+what)
+			}
+		}
+	}
+}
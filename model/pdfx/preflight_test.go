@@ -0,0 +1,145 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfx_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/pdfx"
+)
+
+// writeDocument writes a document with a page filled with device RGB and a
+// calibrated RGB color space, calling `setup` on the page and the writer.
+func writeDocument(t *testing.T, setup func(page *model.PdfPage, w *model.PdfWriter)) *model.PdfReader {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 630, Ury: 810}
+	profile, err := model.NewICCProfileSRGB()
+	require.NoError(t, err)
+	iccStream, err := core.MakeStream(profile.Data, nil)
+	require.NoError(t, err)
+	iccStream.Set("N", core.MakeInteger(3))
+	spaces := core.MakeDict()
+	spaces.Set("CS0", core.MakeArray(core.MakeName("ICCBased"), iccStream))
+	page.Resources.ColorSpace = spaces
+	require.NoError(t, page.AddContentStreamByString("0 1 0 rg 0 0 100 100 re f"))
+
+	w := model.NewPdfWriter()
+	if setup != nil {
+		setup(page, &w)
+	}
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	return r
+}
+
+// checks returns the number of issues of `report` by check.
+func checks(report *pdfx.Report) map[pdfx.Check]int {
+	counts := map[pdfx.Check]int{}
+	for _, issue := range report.Issues {
+		counts[issue.Check]++
+	}
+	return counts
+}
+
+func TestPreflight(t *testing.T) {
+	r := writeDocument(t, nil)
+	report, err := pdfx.Preflight(r, pdfx.VersionX3)
+	require.NoError(t, err)
+	require.False(t, report.IsCompliant())
+	counts := checks(report)
+	require.Equal(t, 1, counts[pdfx.CheckOutputIntent])
+	require.Equal(t, 2, counts[pdfx.CheckInfo])
+	require.Equal(t, 1, counts[pdfx.CheckBoxes])
+	require.Equal(t, 1, counts[pdfx.CheckColor])
+	for _, issue := range report.Issues {
+		switch issue.Check {
+		case pdfx.CheckBoxes, pdfx.CheckColor:
+			require.Equal(t, 1, issue.Page, issue.String())
+		default:
+			require.Equal(t, 0, issue.Page, issue.String())
+		}
+	}
+
+	// The calibrated RGB color space is not allowed by PDF/X-1a.
+	report, err = pdfx.Preflight(r, pdfx.VersionX1a)
+	require.NoError(t, err)
+	require.Equal(t, 2, checks(report)[pdfx.CheckColor])
+}
+
+func TestSetup(t *testing.T) {
+	profile, err := model.NewICCProfileSRGB()
+	require.NoError(t, err)
+	opts := pdfx.Options{
+		Version:                   pdfx.VersionX3,
+		OutputConditionIdentifier: "sRGB",
+		OutputIntentProfile:       profile,
+	}
+	r := writeDocument(t, func(page *model.PdfPage, w *model.PdfWriter) {
+		require.NoError(t, pdfx.SetTrimBox(page, &model.PdfRectangle{Llx: 9, Lly: 9, Urx: 621, Ury: 801}, 18))
+		require.NoError(t, pdfx.Setup(w, opts))
+	})
+
+	// Device RGB is allowed by PDF/X-3 with an RGB output intent.
+	report, err := pdfx.Preflight(r, pdfx.VersionX3)
+	require.NoError(t, err)
+	require.True(t, report.IsCompliant(), "%v", report.Issues)
+	require.Equal(t, "1.4", r.PdfVersion().String())
+
+	intents, err := r.GetOutputIntents()
+	require.NoError(t, err)
+	require.Len(t, intents, 1)
+	require.Equal(t, model.PdfOutputIntentTypePDFX, intents[0].Type)
+	require.Equal(t, "http://www.color.org", intents[0].RegistryName)
+	info, err := r.GetPdfInfo()
+	require.NoError(t, err)
+	require.Equal(t, "PDF/X-3:2003", info.CustomInfo("GTS_PDFXVersion"))
+	require.Equal(t, model.TrappedFalse, info.Trapped)
+
+	page, err := r.GetPage(1)
+	require.NoError(t, err)
+	require.Equal(t, model.PdfRectangle{Llx: 9, Lly: 9, Urx: 621, Ury: 801}, *page.TrimBox)
+	require.Equal(t, model.PdfRectangle{Urx: 630, Ury: 810}, *page.BleedBox)
+
+	report, err = pdfx.Preflight(r, pdfx.VersionX1a)
+	require.NoError(t, err)
+	counts := checks(report)
+	require.Equal(t, 1, counts[pdfx.CheckOutputIntent])
+	require.Equal(t, 2, counts[pdfx.CheckInfo])
+	require.Equal(t, 2, counts[pdfx.CheckColor])
+	require.Zero(t, counts[pdfx.CheckBoxes])
+
+	require.Error(t, pdfx.Setup(&model.PdfWriter{}, pdfx.Options{Version: pdfx.VersionX4}))
+}
+
+func TestPreflightBoxes(t *testing.T) {
+	r := writeDocument(t, func(page *model.PdfPage, w *model.PdfWriter) {
+		page.TrimBox = &model.PdfRectangle{Llx: 10, Lly: 10, Urx: 600, Ury: 800}
+		page.ArtBox = &model.PdfRectangle{Llx: 20, Lly: 20, Urx: 500, Ury: 500}
+		page.BleedBox = &model.PdfRectangle{Llx: 20, Lly: 20, Urx: 610, Ury: 820}
+	})
+	report, err := pdfx.Preflight(r, pdfx.VersionX4)
+	require.NoError(t, err)
+	var descriptions []string
+	for _, issue := range report.Issues {
+		if issue.Check == pdfx.CheckBoxes {
+			descriptions = append(descriptions, issue.Description)
+		}
+	}
+	require.Equal(t, []string{
+		"both trim box and art box set",
+		"BleedBox outside of media box",
+		"trim box outside of bleed box",
+	}, descriptions)
+}
@@ -0,0 +1,11 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+// ProgressFunc is the type of the callbacks reporting the progress of long
+// operations, such as NewPdfReaderContext or PdfWriter.WriteContext: `done`
+// of the `total` items of the operation have been processed.
+type ProgressFunc func(done, total int)
@@ -6,6 +6,7 @@
 package model
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -18,6 +19,8 @@ import (
 
 // PdfReader represents a PDF file reader. It is a frontend to the lower level parsing mechanism and provides
 // a higher level access to work with PDF structure and information, such as the page structure etc.
+// A PdfReader and the objects loaded from it are not safe for concurrent use. Pages can be processed in
+// parallel with ProcessPages, or with readers obtained with Clone.
 type PdfReader struct {
 	parser         *core.PdfParser
 	root           core.PdfObject
@@ -39,6 +42,18 @@ type PdfReader struct {
 	// For tracking traversal (cache).
 	traversed map[core.PdfObject]struct{}
 	rs        io.ReadSeeker
+
+	// Password the document was decrypted with, nil if not decrypted.
+	password []byte
+
+	// readerAtOpts are the window options of the document read from an io.ReaderAt with
+	// NewPdfReaderAtWithOpts, nil for other readers.
+	readerAtOpts *core.ReaderAtOptions
+
+	// Context and progress callback of the loading of the document structure,
+	// set with NewPdfReaderContext.
+	ctx      context.Context
+	progress ProgressFunc
 }
 
 // NewPdfReader returns a new PdfReader for an input io.ReadSeeker interface. Can be used to read PDF from
@@ -47,34 +62,18 @@ type PdfReader struct {
 // Alternatively a lazy-loading reader can be created with NewPdfReaderLazy which loads only references,
 // and references are loaded from disk into memory on an as-needed basis.
 func NewPdfReader(rs io.ReadSeeker) (*PdfReader, error) {
-	pdfReader := &PdfReader{
-		rs:           rs,
-		traversed:    map[core.PdfObject]struct{}{},
-		modelManager: newModelManager(),
-		isLazy:       false,
-	}
-
-	// Create the parser, loads the cross reference table and trailer.
-	parser, err := core.NewParser(rs)
-	if err != nil {
-		return nil, err
-	}
-	pdfReader.parser = parser
-
-	isEncrypted, err := pdfReader.IsEncrypted()
-	if err != nil {
-		return nil, err
-	}
-
-	// Load pdf doc structure if not encrypted.
-	if !isEncrypted {
-		err = pdfReader.loadStructure()
-		if err != nil {
-			return nil, err
-		}
-	}
+	return NewPdfReaderContext(context.Background(), rs, nil)
+}
 
-	return pdfReader, nil
+// NewPdfReaderContext returns a new PdfReader for `rs` as NewPdfReader, loading
+// the document structure under the control of `ctx`: loading stops with the
+// error of `ctx` when it is cancelled or its deadline is exceeded. If not nil,
+// `progress` is called with the number of objects loaded. The objects are
+// loaded with the context and progress only if `ctx` can be cancelled or
+// `progress` is set. For encrypted documents, the structure is loaded by
+// Decrypt, which also uses `ctx` and `progress`.
+func NewPdfReaderContext(ctx context.Context, rs io.ReadSeeker, progress ProgressFunc) (*PdfReader, error) {
+	return newPdfReader(ctx, rs, nil, progress)
 }
 
 // NewPdfReaderLazy creates a new PdfReader for `rs` in lazy-loading mode. The difference
@@ -83,15 +82,53 @@ func NewPdfReader(rs io.ReadSeeker) (*PdfReader, error) {
 // Note that it may make sense to use the lazy-load reader when processing only parts of files,
 // rather than loading entire file into memory. Example: splitting a few pages from a large PDF file.
 func NewPdfReaderLazy(rs io.ReadSeeker) (*PdfReader, error) {
+	return NewPdfReaderWithOpts(rs, &ReaderOpts{LazyLoad: true})
+}
+
+// ReaderOpts defines the options of the PdfReader instances created by NewPdfReaderWithOpts.
+type ReaderOpts struct {
+	// LazyLoad enables the lazy-loading mode, see NewPdfReaderLazy.
+	LazyLoad bool
+
+	// Limits are the limits enforced when parsing the document, e.g. to guard services against
+	// malicious files. See core.Limits.
+	Limits core.Limits
+
+	// ReaderAt are the options of the windowed reads of the documents read from an io.ReaderAt
+	// with NewPdfReaderAtWithOpts, see core.ReaderAtOptions.
+	ReaderAt core.ReaderAtOptions
+}
+
+// NewReaderOpts returns the default options of PdfReader instances: the document structure is
+// loaded into memory and no limits are enforced.
+func NewReaderOpts() *ReaderOpts {
+	return &ReaderOpts{}
+}
+
+// NewPdfReaderWithOpts creates a new PdfReader for `rs` with the options `opts`, the default
+// options if nil. Errors wrapping core.ErrLimitExceeded are returned when the document exceeds
+// the limits of the options, including later on when loading objects in lazy-loading mode.
+func NewPdfReaderWithOpts(rs io.ReadSeeker, opts *ReaderOpts) (*PdfReader, error) {
+	return newPdfReader(context.Background(), rs, opts, nil)
+}
+
+// newPdfReader creates a new PdfReader for `rs` with the options `opts`, loading the document
+// structure with the context `ctx` and the progress callback `progress`.
+func newPdfReader(ctx context.Context, rs io.ReadSeeker, opts *ReaderOpts, progress ProgressFunc) (*PdfReader, error) {
+	if opts == nil {
+		opts = NewReaderOpts()
+	}
 	pdfReader := &PdfReader{
 		rs:           rs,
 		traversed:    map[core.PdfObject]struct{}{},
 		modelManager: newModelManager(),
-		isLazy:       true,
+		isLazy:       opts.LazyLoad,
+		ctx:          ctx,
+		progress:     progress,
 	}
 
 	// Create the parser, loads the cross reference table and trailer.
-	parser, err := core.NewParser(rs)
+	parser, err := core.NewParserWithLimits(rs, opts.Limits)
 	if err != nil {
 		return nil, err
 	}
@@ -113,6 +150,44 @@ func NewPdfReaderLazy(rs io.ReadSeeker) (*PdfReader, error) {
 	return pdfReader, nil
 }
 
+// NewPdfReaderAt returns a new PdfReader for the document of `size` bytes read
+// from `ra`, e.g. an *os.File, which must remain readable while the reader is
+// used. The file is read by windows rather than loaded in memory, see
+// core.NewReaderAtSeeker. As NewPdfReader, loads the entire document
+// structure into memory.
+func NewPdfReaderAt(ra io.ReaderAt, size int64) (*PdfReader, error) {
+	return NewPdfReaderAtWithOpts(ra, size, nil)
+}
+
+// NewPdfReaderAtLazy returns a new PdfReader for the document of `size` bytes
+// read from `ra` in lazy-loading mode, see NewPdfReaderAt and
+// NewPdfReaderLazy. Combining both limits the memory used to the objects
+// actually accessed, e.g. when extracting a few pages from a large file.
+func NewPdfReaderAtLazy(ra io.ReaderAt, size int64) (*PdfReader, error) {
+	return NewPdfReaderAtWithOpts(ra, size, &ReaderOpts{LazyLoad: true})
+}
+
+// NewPdfReaderAtWithOpts returns a new PdfReader for the document of `size`
+// bytes read from `ra` with the options `opts`, the default options if nil.
+// The document is read by windows of the ReaderAt options, so that any
+// io.ReaderAt can be used, e.g. one issuing HTTP range requests to cloud
+// storage: in lazy-loading mode, reading the metadata or extracting a few
+// pages only reads the windows containing the cross-reference table and the
+// objects loaded, rather than downloading the whole file. Clones of the
+// reader read `ra` with the same options, see Clone.
+func NewPdfReaderAtWithOpts(ra io.ReaderAt, size int64, opts *ReaderOpts) (*PdfReader, error) {
+	if opts == nil {
+		opts = NewReaderOpts()
+	}
+	r, err := NewPdfReaderWithOpts(core.NewReaderAtSeekerWithOptions(ra, size, opts.ReaderAt), opts)
+	if err != nil {
+		return nil, err
+	}
+	readerAtOpts := opts.ReaderAt
+	r.readerAtOpts = &readerAtOpts
+	return r, nil
+}
+
 // PdfVersion returns version of the PDF file.
 func (r *PdfReader) PdfVersion() core.Version {
 	return r.parser.PdfVersion()
@@ -140,6 +215,7 @@ func (r *PdfReader) Decrypt(password []byte) (bool, error) {
 	if !success {
 		return false, nil
 	}
+	r.password = append([]byte{}, password...)
 
 	err = r.loadStructure()
 	if err != nil {
@@ -232,6 +308,12 @@ func (r *PdfReader) loadStructure() error {
 	r.pageCount = int(*pageCount)
 	r.pageList = []*core.PdfIndirectObject{}
 
+	if !r.isLazy {
+		if err := r.loadObjects(); err != nil {
+			return err
+		}
+	}
+
 	traversedPageNodes := map[core.PdfObject]struct{}{}
 	err = r.buildPageList(ppages, nil, traversedPageNodes)
 	if err != nil {
@@ -258,6 +340,34 @@ func (r *PdfReader) loadStructure() error {
 	return nil
 }
 
+// loadObjects loads all the objects of the document, checking the context of
+// the reader and reporting the progress after each object. Does nothing if the
+// reader has neither a context which can be cancelled nor a progress
+// callback, the objects being loaded when traversing the document structure.
+func (r *PdfReader) loadObjects() error {
+	if (r.ctx == nil || r.ctx.Done() == nil) && r.progress == nil {
+		return nil
+	}
+
+	objNums := r.parser.GetObjectNums()
+	for i, objNum := range objNums {
+		if r.ctx != nil {
+			if err := r.ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if _, err := r.parser.LookupByNumber(objNum); err != nil {
+			// Invalid objects are only errors if referenced, which is checked
+			// when traversing the structure.
+			common.Log.Debug("ERROR: Failed to load object %d: %v", objNum, err)
+		}
+		if r.progress != nil {
+			r.progress(i+1, len(objNums))
+		}
+	}
+	return nil
+}
+
 func (r *PdfReader) loadOutlines() (*PdfOutlineTreeNode, error) {
 	if r.parser.GetCrypter() != nil && !r.parser.IsAuthenticated() {
 		return nil, fmt.Errorf("file need to be decrypted first")
@@ -464,25 +574,7 @@ func (r *PdfReader) GetOutlines() (*Outline, error) {
 		// Check if node is an outline item.
 		var entry *OutlineItem
 		if item, ok := node.context.(*PdfOutlineItem); ok {
-			// Search for outline destination object.
-			destObj := item.Dest
-			if (destObj == nil || core.IsNullObject(destObj)) && item.A != nil {
-				if actionDict, ok := core.GetDict(item.A); ok {
-					destObj, _ = core.GetArray(actionDict.Get("D"))
-				}
-			}
-
-			// Parse outline destination object.
-			var dest OutlineDest
-			if destObj != nil && !core.IsNullObject(destObj) {
-				if d, err := newOutlineDestFromPdfObject(destObj, r); err == nil {
-					dest = *d
-				} else {
-					common.Log.Debug("WARN: could not parse outline dest (%v): %v", destObj, err)
-				}
-			}
-
-			entry = NewOutlineItem(item.Title.Decoded(), dest)
+			entry = r.newOutlineItem(item)
 			*entries = append(*entries, entry)
 
 			// Traverse next node.
@@ -507,6 +599,51 @@ func (r *PdfReader) GetOutlines() (*Outline, error) {
 	return outline, nil
 }
 
+// newOutlineItem returns a high-level outline item, without children, based
+// on the specified outline item dictionary. GoTo actions are converted to
+// destinations, while other actions are kept as the action of the item.
+func (r *PdfReader) newOutlineItem(item *PdfOutlineItem) *OutlineItem {
+	// Search for outline destination object.
+	destObj := item.Dest
+	var action *PdfAction
+	if (destObj == nil || core.IsNullObject(destObj)) && item.A != nil {
+		a, err := r.loadAction(item.A)
+		if err != nil {
+			common.Log.Debug("WARN: could not load outline action (%v): %v", item.A, err)
+		}
+		if gotoAction, ok := a.GetContext().(*PdfActionGoTo); ok && a.Next == nil {
+			destObj = gotoAction.D
+		} else if a != nil {
+			action = a
+		}
+	}
+
+	// Parse outline destination object.
+	var dest OutlineDest
+	if destObj != nil && !core.IsNullObject(destObj) {
+		if d, _ := r.resolveDest(destObj); d != nil {
+			dest = *d
+		} else {
+			common.Log.Debug("WARN: could not parse outline dest (%v)", destObj)
+		}
+	}
+
+	entry := NewOutlineItem(item.Title.Decoded(), dest)
+	entry.Action = action
+	if arr, ok := core.GetArray(item.C); ok {
+		if vals, err := arr.ToFloat64Array(); err == nil && len(vals) == 3 {
+			entry.Color = NewPdfColorDeviceRGB(vals[0], vals[1], vals[2])
+		}
+	}
+	if flags, ok := core.GetIntVal(item.F); ok {
+		entry.Flags = OutlineItemFlag(flags)
+	}
+	if item.Count != nil && *item.Count < 0 {
+		entry.Closed = true
+	}
+	return entry
+}
+
 // AcroFormRepairOptions contains options for rebuilding the AcroForm.
 type AcroFormRepairOptions struct {
 }
@@ -772,6 +909,20 @@ func (r *PdfReader) GetNumPages() (int, error) {
 	return len(r.pageList), nil
 }
 
+// SetCacheOptions sets the configuration of the caches used to read the
+// document. The decoded content streams of the pages are cached with the
+// data decoded by core.PdfParser.DecodeStream.
+func (r *PdfReader) SetCacheOptions(opts core.CacheOptions) {
+	r.parser.SetCacheOptions(opts)
+}
+
+// CacheStats returns the counters of the work done to read the document, e.g.
+// the number of objects parsed and of bytes decoded, to monitor the caches
+// configured with SetCacheOptions.
+func (r *PdfReader) CacheStats() core.CacheStats {
+	return r.parser.CacheStats()
+}
+
 // Resolves a reference, returning the object and indicates whether or not
 // it was cached.
 func (r *PdfReader) resolveReference(ref *core.PdfObjectReference) (core.PdfObject, bool, error) {
@@ -886,6 +1037,22 @@ func (r *PdfReader) GetPageLabels() (core.PdfObject, error) {
 	return obj, nil
 }
 
+// GetCatalogMetadata returns the Metadata stream of the PDF catalog, which
+// contains the XMP metadata of the document, or nil if not present.
+// See section 14.3.2 "Metadata Streams" (p. 556 PDF32000_2008).
+func (r *PdfReader) GetCatalogMetadata() (*core.PdfObjectStream, error) {
+	obj := core.ResolveReference(r.catalog.Get("Metadata"))
+	if obj == nil || core.IsNullObject(obj) {
+		return nil, nil
+	}
+
+	stream, ok := core.GetStream(obj)
+	if !ok {
+		return nil, fmt.Errorf("catalog Metadata not a stream (%T)", obj)
+	}
+	return stream, nil
+}
+
 // Inspect inspects the object types, subtypes and content in the PDF file returning a map of
 // object type to number of instances of each.
 func (r *PdfReader) Inspect() (map[string]int, error) {
@@ -0,0 +1,59 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/model/optimize"
+)
+
+func TestReaderCacheStats(t *testing.T) {
+	w := model.NewPdfWriter()
+	w.SetOptimizer(optimize.New(optimize.Options{UseObjectStreams: true}))
+	for i := 1; i <= 3; i++ {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+		require.NoError(t, page.AddContentStreamByString(fmt.Sprintf("BT (Page %d) Tj ET", i)))
+		require.NoError(t, w.AddPage(page))
+	}
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := model.NewPdfReaderLazy(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	r.SetCacheOptions(core.CacheOptions{MaxObjectStreams: 1, MaxDecodedStreamSize: 1 << 20})
+
+	page, err := r.GetPage(1)
+	require.NoError(t, err)
+	var decoded int64
+	for i := 0; i < 2; i++ {
+		content, err := page.GetAllContentStreams()
+		require.NoError(t, err)
+		require.Contains(t, content, "(Page 1) Tj")
+		if i == 0 {
+			decoded = r.CacheStats().StreamsDecoded
+		}
+	}
+
+	// The content streams are decoded once.
+	stats := r.CacheStats()
+	require.True(t, decoded > 0)
+	require.Equal(t, decoded, stats.StreamsDecoded)
+	require.Equal(t, decoded, stats.StreamCacheHits)
+	require.True(t, stats.ObjectsParsed > 0)
+	require.True(t, stats.ObjectStreamsDecoded > 0)
+	require.True(t, stats.ObjectStreamCacheHits > 0)
+	require.True(t, stats.CachedObjectStreams <= 1)
+	require.True(t, stats.CachedStreamSize > 0)
+	require.True(t, stats.BytesDecoded > stats.CachedStreamSize)
+}
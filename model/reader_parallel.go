@@ -0,0 +1,134 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// Clone returns a new reader of the document read by `r`, sharing no state
+// with `r` so that both readers can be used concurrently.
+// The document must be read from an io.ReaderAt such as *os.File or
+// *bytes.Reader, which is read from concurrently by the readers. Encrypted
+// documents are decrypted with the password `r` was decrypted with, and the
+// clone uses the cache options of `r` with its own caches.
+// NOTE: Clone must not be called concurrently with other uses of `r`.
+func (r *PdfReader) Clone() (*PdfReader, error) {
+	ra, ok := r.rs.(io.ReaderAt)
+	if !ok {
+		return nil, errors.New("reader input does not implement io.ReaderAt")
+	}
+	offset, err := r.rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	size, err := r.rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.rs.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	opts := &ReaderOpts{LazyLoad: r.isLazy, Limits: r.parser.Limits()}
+	var clone *PdfReader
+	if r.readerAtOpts != nil {
+		// Keep reading by windows, e.g. for documents read by range requests.
+		opts.ReaderAt = *r.readerAtOpts
+		clone, err = NewPdfReaderAtWithOpts(ra, size, opts)
+	} else {
+		clone, err = NewPdfReaderWithOpts(io.NewSectionReader(ra, 0, size), opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	clone.SetCacheOptions(r.parser.GetCacheOptions())
+	if r.password != nil {
+		success, err := clone.Decrypt(r.password)
+		if err != nil {
+			return nil, err
+		}
+		if !success {
+			return nil, errors.New("unable to decrypt clone")
+		}
+	}
+	return clone, nil
+}
+
+// ProcessPages calls `process` for each page of `r` concurrently from
+// `numWorkers` goroutines, or as many as there are CPUs if `numWorkers` is
+// not positive. Each goroutine loads the pages from its own clone of `r`
+// (see Clone), so that `process` can extract, render or modify the pages
+// without synchronization. `process` is called with the 1-based page number
+// and must synchronize its accesses to shared state.
+// Pages are not processed in order. Processing stops at the first error,
+// which is returned.
+func (r *PdfReader) ProcessPages(numWorkers int, process func(pageNum int, page *PdfPage) error) error {
+	numPages, err := r.GetNumPages()
+	if err != nil {
+		return err
+	}
+	if numWorkers <= 0 {
+		numWorkers = runtime.NumCPU()
+	}
+	if numWorkers > numPages {
+		numWorkers = numPages
+	}
+
+	// The clones are created before processing as they read from `r`.
+	readers := make([]*PdfReader, numWorkers)
+	for i := range readers {
+		if readers[i], err = r.Clone(); err != nil {
+			return err
+		}
+	}
+
+	pageNums := make(chan int)
+	done := make(chan struct{})
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			close(done)
+		})
+	}
+
+	var wg sync.WaitGroup
+	for _, reader := range readers {
+		wg.Add(1)
+		go func(reader *PdfReader) {
+			defer wg.Done()
+			for pageNum := range pageNums {
+				page, err := reader.GetPage(pageNum)
+				if err == nil {
+					err = process(pageNum, page)
+				}
+				if err != nil {
+					fail(xerrors.Errorf("page %d: %w", pageNum, err))
+					return
+				}
+			}
+		}(reader)
+	}
+
+feed:
+	for pageNum := 1; pageNum <= numPages; pageNum++ {
+		select {
+		case pageNums <- pageNum:
+		case <-done:
+			break feed
+		}
+	}
+	close(pageNums)
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,122 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// writeNumberedPages writes a document of `numPages` pages showing their
+// page numbers.
+func writeNumberedPages(t *testing.T, numPages int) []byte {
+	w := NewPdfWriter()
+	for i := 1; i <= numPages; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+		require.NoError(t, page.AddContentStreamByString(fmt.Sprintf("BT (Page %d) Tj ET", i)))
+		require.NoError(t, w.AddPage(page))
+	}
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	return buf.Bytes()
+}
+
+func TestProcessPages(t *testing.T) {
+	data := writeNumberedPages(t, 10)
+	for _, lazy := range []bool{false, true} {
+		var r *PdfReader
+		var err error
+		if lazy {
+			r, err = NewPdfReaderLazy(bytes.NewReader(data))
+		} else {
+			r, err = NewPdfReader(bytes.NewReader(data))
+		}
+		require.NoError(t, err)
+
+		var mu sync.Mutex
+		contents := make(map[int]string)
+		err = r.ProcessPages(4, func(pageNum int, page *PdfPage) error {
+			content, err := page.GetAllContentStreams()
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			contents[pageNum] = content
+			mu.Unlock()
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, contents, 10)
+		for i := 1; i <= 10; i++ {
+			require.Contains(t, contents[i], fmt.Sprintf("(Page %d) Tj", i))
+		}
+
+		// The reader remains usable.
+		page, err := r.GetPage(3)
+		require.NoError(t, err)
+		content, err := page.GetAllContentStreams()
+		require.NoError(t, err)
+		require.Contains(t, content, "(Page 3) Tj")
+	}
+}
+
+func TestProcessPagesError(t *testing.T) {
+	r, err := NewPdfReader(bytes.NewReader(writeNumberedPages(t, 20)))
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var processed int
+	err = r.ProcessPages(2, func(pageNum int, page *PdfPage) error {
+		if pageNum == 3 {
+			return errors.New("failed")
+		}
+		mu.Lock()
+		processed++
+		mu.Unlock()
+		return nil
+	})
+	require.EqualError(t, err, "page 3: failed")
+	require.Less(t, processed, 19)
+}
+
+func TestProcessPagesWrapsErrors(t *testing.T) {
+	r, err := NewPdfReader(bytes.NewReader(writeNumberedPages(t, 4)))
+	require.NoError(t, err)
+
+	err = r.ProcessPages(2, func(pageNum int, page *PdfPage) error {
+		if pageNum == 2 {
+			return &core.LimitError{Limit: "MaxObjects", Value: 10}
+		}
+		return nil
+	})
+	require.Error(t, err)
+	require.True(t, xerrors.Is(err, core.ErrLimitExceeded))
+	var limitErr *core.LimitError
+	require.True(t, xerrors.As(err, &limitErr))
+	require.Equal(t, "MaxObjects", limitErr.Limit)
+}
+
+// readSeeker hides the io.ReaderAt implementation of a reader.
+type readSeeker struct {
+	io.ReadSeeker
+}
+
+func TestCloneRequiresReaderAt(t *testing.T) {
+	r, err := NewPdfReader(readSeeker{bytes.NewReader(writeNumberedPages(t, 1))})
+	require.NoError(t, err)
+	_, err = r.Clone()
+	require.Error(t, err)
+}
@@ -7,10 +7,13 @@ package model
 
 import (
 	"bytes"
+	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
 
 	"github.com/unidoc/unipdf/v3/core"
 )
@@ -47,3 +50,125 @@ func TestReaderLazy(t *testing.T) {
 	err = writer.Write(&buf)
 	require.NoError(t, err)
 }
+
+func TestReaderAt(t *testing.T) {
+	f, err := os.Open(`./testdata/pages3.pdf`)
+	require.NoError(t, err)
+	defer f.Close()
+	info, err := f.Stat()
+	require.NoError(t, err)
+
+	expected, err := NewPdfReader(f)
+	require.NoError(t, err)
+	for _, lazy := range []bool{false, true} {
+		var reader *PdfReader
+		if lazy {
+			reader, err = NewPdfReaderAtLazy(f, info.Size())
+		} else {
+			reader, err = NewPdfReaderAt(f, info.Size())
+		}
+		require.NoError(t, err)
+
+		numPages, err := reader.GetNumPages()
+		require.NoError(t, err)
+		require.Equal(t, len(expected.PageList), numPages)
+		for i := 1; i <= numPages; i++ {
+			page, err := reader.GetPage(i)
+			require.NoError(t, err)
+			content, err := page.GetAllContentStreams()
+			require.NoError(t, err)
+			expectedPage, err := expected.GetPage(i)
+			require.NoError(t, err)
+			expectedContent, err := expectedPage.GetAllContentStreams()
+			require.NoError(t, err)
+			require.Equal(t, expectedContent, content)
+		}
+
+		// The reader can be cloned to process pages concurrently.
+		err = reader.ProcessPages(2, func(pageNum int, page *PdfPage) error {
+			_, err := page.GetAllContentStreams()
+			return err
+		})
+		require.NoError(t, err)
+	}
+}
+
+// rangeReaderAt records the bytes read from the underlying reader, as a reader
+// issuing range requests would download them.
+type rangeReaderAt struct {
+	data      []byte
+	bytesRead int
+	calls     int
+}
+
+func (r *rangeReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	r.calls++
+	n, err := bytes.NewReader(r.data).ReadAt(p, offset)
+	r.bytesRead += n
+	return n, err
+}
+
+func TestReaderAtWithOptsRanges(t *testing.T) {
+	// Document with large content streams.
+	w := NewPdfWriter()
+	content := bytes.Repeat([]byte("0 0 m 100 100 l S\n"), 1000)
+	for i := 0; i < 20; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+		require.NoError(t, page.SetContentStreams([]string{string(content)}, nil))
+		require.NoError(t, w.AddPage(page))
+	}
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	ra := &rangeReaderAt{data: buf.Bytes()}
+	opts := &ReaderOpts{LazyLoad: true, ReaderAt: core.ReaderAtOptions{WindowSize: 4096, MaxWindows: 8}}
+	r, err := NewPdfReaderAtWithOpts(ra, int64(buf.Len()), opts)
+	require.NoError(t, err)
+	numPages, err := r.GetNumPages()
+	require.NoError(t, err)
+	require.Equal(t, 20, numPages)
+	page, err := r.GetPage(10)
+	require.NoError(t, err)
+	pageContent, err := page.GetAllContentStreams()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(pageContent, string(content)))
+
+	// Only the parts of the file containing the objects loaded are read.
+	require.True(t, ra.bytesRead < buf.Len()/2, "read %d of %d bytes", ra.bytesRead, buf.Len())
+
+	// Clones also read by windows.
+	calls := ra.calls
+	clone, err := r.Clone()
+	require.NoError(t, err)
+	_, err = clone.GetNumPages()
+	require.NoError(t, err)
+	require.True(t, ra.calls-calls < 100, "%d reads", ra.calls-calls)
+}
+
+func TestReaderWithOptsLimits(t *testing.T) {
+	data, err := ioutil.ReadFile(`./testdata/minimal.pdf`)
+	require.NoError(t, err)
+
+	r, err := NewPdfReaderWithOpts(bytes.NewReader(data), nil)
+	require.NoError(t, err)
+	numPages, err := r.GetNumPages()
+	require.NoError(t, err)
+	require.Equal(t, 1, numPages)
+
+	_, err = NewPdfReaderWithOpts(bytes.NewReader(data), &ReaderOpts{Limits: core.Limits{MaxObjects: 2}})
+	require.True(t, xerrors.Is(err, core.ErrLimitExceeded))
+
+	// Limits are also enforced when objects are loaded later on.
+	opts := &ReaderOpts{LazyLoad: true, Limits: core.Limits{MaxDecodedStreamSize: 10}}
+	r, err = NewPdfReaderWithOpts(bytes.NewReader(data), opts)
+	require.NoError(t, err)
+	page, err := r.GetPage(1)
+	require.NoError(t, err)
+	_, err = page.GetAllContentStreams()
+	require.True(t, xerrors.Is(err, core.ErrLimitExceeded), "%v", err)
+
+	clone, err := r.Clone()
+	require.NoError(t, err)
+	require.Equal(t, opts.Limits, clone.parser.Limits())
+}
@@ -154,9 +154,9 @@ func (r *PdfPageResources) GetExtGState(keyName core.PdfObjectName) (core.PdfObj
 	return nil, false
 }
 
-// HasExtGState checks whether a font is defined by the specified keyName.
+// HasExtGState checks whether an ExtGState is defined by the specified keyName.
 func (r *PdfPageResources) HasExtGState(keyName core.PdfObjectName) bool {
-	_, has := r.GetFontByName(keyName)
+	_, has := r.GetExtGState(keyName)
 	return has
 }
 
@@ -0,0 +1,246 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/core/security"
+)
+
+// SignatureFieldSummary describes a signature field of a document.
+type SignatureFieldSummary struct {
+	// Name is the fully qualified name of the field.
+	Name string
+
+	// Signed specifies whether the field contains a signature.
+	Signed bool
+
+	// SubFilter is the format of the signature, e.g. "adbe.pkcs7.detached".
+	// Empty if the field is not signed.
+	SubFilter string
+}
+
+// SecuritySummary describes the security properties of a document: its
+// encryption, access permissions, signatures and usage rights.
+type SecuritySummary struct {
+	// Encrypted specifies whether the document is encrypted.
+	Encrypted bool
+
+	// Encryption describes the encryption of the document. Only set if the
+	// document is encrypted.
+	Encryption *core.CryptInfo
+
+	// Algorithm is a short description of the encryption algorithm, e.g.
+	// "RC4", "AES-128" or "AES-256". Empty if the document is not
+	// encrypted.
+	Algorithm string
+
+	// UserPassword specifies whether a user password is required for opening
+	// the document.
+	UserPassword bool
+
+	// OwnerPassword specifies whether the owner permissions are protected by
+	// an owner password, i.e. cannot be obtained with an empty password.
+	OwnerPassword bool
+
+	// Permissions contains the access permissions granted to users opening
+	// the document with the user password. All permissions are granted for
+	// unencrypted documents.
+	Permissions security.Permissions
+
+	// SignatureFields describes the signature fields of the document.
+	SignatureFields []*SignatureFieldSummary
+
+	// Certified specifies whether the document has a certification (DocMDP)
+	// signature.
+	Certified bool
+
+	// CertificationPermissions is the access permissions level of the
+	// certification signature: 1 (no changes), 2 (form filling and signing)
+	// or 3 (form filling, signing and annotating). Zero if not certified.
+	CertificationPermissions int
+
+	// ReaderEnabled specifies whether the document has a usage rights
+	// signature (UR or UR3 entry of the Perms dictionary), which enables
+	// additional features in Adobe Reader.
+	ReaderEnabled bool
+
+	// UsageRights contains the rights granted by the usage rights signature,
+	// as "Category/Right" strings, e.g. "Form/FillIn" or "Annots/Create".
+	UsageRights []string
+}
+
+// String returns a multi-line description of the security summary.
+func (s *SecuritySummary) String() string {
+	var b strings.Builder
+	if s.Encrypted {
+		fmt.Fprintf(&b, "Encryption: %s, %d bits (V=%d R=%d)\n", s.Algorithm, s.Encryption.KeyLength,
+			s.Encryption.V, s.Encryption.R)
+		fmt.Fprintf(&b, "User password: %t\nOwner password: %t\n", s.UserPassword, s.OwnerPassword)
+	} else {
+		b.WriteString("Encryption: none\n")
+	}
+	fmt.Fprintf(&b, "Permissions: %s\n", describePermissions(s.Permissions))
+	for _, field := range s.SignatureFields {
+		fmt.Fprintf(&b, "Signature field: %s (signed: %t)\n", field.Name, field.Signed)
+	}
+	if s.Certified {
+		fmt.Fprintf(&b, "Certified: level %d\n", s.CertificationPermissions)
+	}
+	if s.ReaderEnabled {
+		fmt.Fprintf(&b, "Reader enabled: %s\n", strings.Join(s.UsageRights, ", "))
+	}
+	return b.String()
+}
+
+// describePermissions returns the names of the permissions `perms`.
+func describePermissions(perms security.Permissions) string {
+	if perms == security.PermOwner {
+		return "all"
+	}
+	var names []string
+	for _, perm := range []struct {
+		perm security.Permissions
+		name string
+	}{
+		{security.PermPrinting, "print"},
+		{security.PermModify, "modify"},
+		{security.PermExtractGraphics, "extract"},
+		{security.PermAnnotate, "annotate"},
+		{security.PermFillForms, "fill forms"},
+		{security.PermDisabilityExtract, "accessibility"},
+		{security.PermRotateInsert, "assemble"},
+		{security.PermFullPrintQuality, "high quality print"},
+	} {
+		if perms.Allowed(perm.perm) {
+			names = append(names, perm.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// GetSecuritySummary returns the security summary of the document. The
+// document must have been decrypted if encrypted, except for the encryption
+// properties which are always available.
+func (r *PdfReader) GetSecuritySummary() (*SecuritySummary, error) {
+	summary := &SecuritySummary{Permissions: security.PermOwner}
+
+	if crypter := r.parser.GetCrypter(); crypter != nil {
+		info := crypter.Info()
+		summary.Encrypted = true
+		summary.Encryption = &info
+		summary.Algorithm = cryptAlgorithm(info)
+		summary.Permissions = info.Permissions
+
+		ok, perms, err := r.parser.CheckAccessRights(nil)
+		if err != nil {
+			return nil, err
+		}
+		summary.UserPassword = !ok
+		summary.OwnerPassword = !ok || perms != security.PermOwner
+	}
+	if r.catalog == nil {
+		// Not decrypted.
+		return summary, nil
+	}
+
+	for _, field := range r.AcroForm.AllFields() {
+		sigField, ok := field.GetContext().(*PdfFieldSignature)
+		if !ok {
+			continue
+		}
+		name, err := field.FullName()
+		if err != nil {
+			common.Log.Debug("ERROR: invalid signature field name: %v", err)
+			name = field.PartialName()
+		}
+		fieldSummary := &SignatureFieldSummary{Name: name, Signed: sigField.V != nil}
+		if sigField.V != nil && sigField.V.SubFilter != nil {
+			fieldSummary.SubFilter, _ = core.GetNameVal(sigField.V.SubFilter)
+		}
+		summary.SignatureFields = append(summary.SignatureFields, fieldSummary)
+	}
+
+	perms, ok := core.GetDict(r.catalog.Get("Perms"))
+	if !ok {
+		return summary, nil
+	}
+	if params, ok := signatureTransformParams(perms.Get("DocMDP")); ok {
+		summary.Certified = true
+		summary.CertificationPermissions = 2
+		if p, ok := core.GetIntVal(params.Get("P")); ok {
+			summary.CertificationPermissions = p
+		}
+	}
+	for _, key := range usageRightsKeys {
+		if perms.Get(key) == nil {
+			continue
+		}
+		summary.ReaderEnabled = true
+		if params, ok := signatureTransformParams(perms.Get(key)); ok {
+			summary.UsageRights = usageRights(params)
+		}
+		break
+	}
+	return summary, nil
+}
+
+// cryptAlgorithm returns a short description of the algorithm of the
+// encryption `info`.
+func cryptAlgorithm(info core.CryptInfo) string {
+	switch info.Method {
+	case "AESV2":
+		return "AES-128"
+	case "AESV3":
+		return "AES-256"
+	case "Identity":
+		return "None"
+	}
+	return "RC4"
+}
+
+// signatureTransformParams returns the transform parameters dictionary of
+// the first signature reference of the signature dictionary `obj`. An empty
+// dictionary is returned if the signature does not have transform
+// parameters.
+func signatureTransformParams(obj core.PdfObject) (*core.PdfObjectDictionary, bool) {
+	sig, ok := core.GetDict(obj)
+	if !ok {
+		return nil, false
+	}
+	if refs, ok := core.GetArray(sig.Get("Reference")); ok && refs.Len() > 0 {
+		if ref, ok := core.GetDict(refs.Get(0)); ok {
+			if params, ok := core.GetDict(ref.Get("TransformParams")); ok {
+				return params, true
+			}
+		}
+	}
+	return core.MakeDict(), true
+}
+
+// usageRights returns the rights of the UR transform parameters `params`.
+func usageRights(params *core.PdfObjectDictionary) []string {
+	var rights []string
+	for _, category := range []core.PdfObjectName{"Document", "Msg", "Annots", "Form", "Signature", "EF"} {
+		arr, ok := core.GetArray(params.Get(category))
+		if !ok {
+			continue
+		}
+		for _, obj := range arr.Elements() {
+			if name, ok := core.GetNameVal(obj); ok {
+				rights = append(rights, string(category)+"/"+name)
+			}
+		}
+	}
+	return rights
+}
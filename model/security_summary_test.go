@@ -0,0 +1,100 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/core/security"
+)
+
+// writeSecurityTestDoc returns a single page document, encrypted with the
+// specified passwords and options if `opts` is not nil.
+func writeSecurityTestDoc(t *testing.T, userPass, ownerPass string, opts *EncryptOptions, perms *core.PdfObjectDictionary) []byte {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	if perms != nil {
+		w.catalog.Set("Perms", perms)
+		require.NoError(t, w.addObjects(perms))
+	}
+	if opts != nil {
+		require.NoError(t, w.Encrypt([]byte(userPass), []byte(ownerPass), opts))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	return buf.Bytes()
+}
+
+func TestSecuritySummary(t *testing.T) {
+	// Unencrypted document with usage rights and certification signatures.
+	makeSig := func(method string, params *core.PdfObjectDictionary) *core.PdfObjectDictionary {
+		ref := core.MakeDict()
+		ref.Set("TransformMethod", core.MakeName(method))
+		ref.Set("TransformParams", params)
+		sig := core.MakeDict()
+		sig.Set("Reference", core.MakeArray(ref))
+		return sig
+	}
+	urParams := core.MakeDict()
+	urParams.Set("Form", core.MakeArray(core.MakeName("FillIn"), core.MakeName("Export")))
+	urParams.Set("Annots", core.MakeArray(core.MakeName("Create")))
+	mdpParams := core.MakeDict()
+	mdpParams.Set("P", core.MakeInteger(1))
+	perms := core.MakeDict()
+	perms.Set("UR3", makeSig("UR3", urParams))
+	perms.Set("DocMDP", makeSig("DocMDP", mdpParams))
+
+	r, err := NewPdfReader(bytes.NewReader(writeSecurityTestDoc(t, "", "", nil, perms)))
+	require.NoError(t, err)
+	summary, err := r.GetSecuritySummary()
+	require.NoError(t, err)
+	require.False(t, summary.Encrypted)
+	require.Equal(t, security.PermOwner, summary.Permissions)
+	require.True(t, summary.Certified)
+	require.Equal(t, 1, summary.CertificationPermissions)
+	require.True(t, summary.ReaderEnabled)
+	require.Equal(t, []string{"Annots/Create", "Form/FillIn", "Form/Export"}, summary.UsageRights)
+	require.Contains(t, summary.String(), "Encryption: none")
+
+	// Owner password protected document.
+	data := writeSecurityTestDoc(t, "", "owner", &EncryptOptions{
+		Permissions: security.PermPrinting | security.PermFillForms,
+		Algorithm:   AES_256bit,
+	}, nil)
+	r, err = NewPdfReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	summary, err = r.GetSecuritySummary()
+	require.NoError(t, err)
+	require.True(t, summary.Encrypted)
+	require.Equal(t, "AES-256", summary.Algorithm)
+	require.Equal(t, 256, summary.Encryption.KeyLength)
+	require.Equal(t, 6, summary.Encryption.R)
+	require.False(t, summary.UserPassword)
+	require.True(t, summary.OwnerPassword)
+	require.True(t, summary.Permissions.Allowed(security.PermPrinting))
+	require.False(t, summary.Permissions.Allowed(security.PermModify))
+	require.Contains(t, summary.String(), "Permissions: print, fill forms")
+
+	// User password protected document.
+	data = writeSecurityTestDoc(t, "user", "owner", &EncryptOptions{Algorithm: RC4_128bit}, nil)
+	r, err = NewPdfReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	summary, err = r.GetSecuritySummary()
+	require.NoError(t, err)
+	require.True(t, summary.Encrypted)
+	require.Equal(t, "RC4", summary.Algorithm)
+	require.Equal(t, 128, summary.Encryption.KeyLength)
+	require.True(t, summary.UserPassword)
+	require.True(t, summary.OwnerPassword)
+}
@@ -134,6 +134,163 @@ type PdfShadingType7 struct {
 	Function          []PdfFunction
 }
 
+// newPdfShading returns a new shading of type `shadingType` in colorspace
+// `cs`. Shadings of types 1-3 are contained in a dictionary, while mesh
+// shadings (types 4-7) are contained in a stream.
+func newPdfShading(shadingType int64, cs PdfColorspace) *PdfShading {
+	shading := &PdfShading{
+		ShadingType: core.MakeInteger(shadingType),
+		ColorSpace:  cs,
+	}
+	if shadingType >= 4 {
+		shading.container = &core.PdfObjectStream{PdfObjectDictionary: core.MakeDict()}
+	} else {
+		shading.container = core.MakeDict()
+	}
+	return shading
+}
+
+// NewPdfShadingType1 returns a new function-based shading in colorspace `cs`,
+// where the color of each point of the domain [0 1 0 1] is defined by
+// `function`.
+func NewPdfShadingType1(cs PdfColorspace, function PdfFunction) *PdfShadingType1 {
+	shading := &PdfShadingType1{
+		PdfShading: newPdfShading(1, cs),
+		Function:   []PdfFunction{function},
+	}
+	shading.context = shading
+	return shading
+}
+
+// NewPdfShadingType2 returns a new axial shading in colorspace `cs`, varying
+// along the axis from (x0,y0) to (x1,y1), with the color at each point of the
+// axis defined by `function` for the parametric variable t in [0 1].
+func NewPdfShadingType2(cs PdfColorspace, x0, y0, x1, y1 float64, function PdfFunction) *PdfShadingType2 {
+	shading := &PdfShadingType2{
+		PdfShading: newPdfShading(2, cs),
+		Coords:     core.MakeArrayFromFloats([]float64{x0, y0, x1, y1}),
+		Function:   []PdfFunction{function},
+	}
+	shading.context = shading
+	return shading
+}
+
+// NewPdfShadingType3 returns a new radial shading in colorspace `cs`,
+// varying between the circles centered at (x0,y0) with radius r0 and at
+// (x1,y1) with radius r1, with colors defined by `function` for the parametric
+// variable t in [0 1].
+func NewPdfShadingType3(cs PdfColorspace, x0, y0, r0, x1, y1, r1 float64, function PdfFunction) *PdfShadingType3 {
+	shading := &PdfShadingType3{
+		PdfShading: newPdfShading(3, cs),
+		Coords:     core.MakeArrayFromFloats([]float64{x0, y0, r0, x1, y1, r1}),
+		Function:   []PdfFunction{function},
+	}
+	shading.context = shading
+	return shading
+}
+
+// NewPdfShadingType4 returns a new free-form triangle mesh shading in
+// colorspace `cs`. The mesh data is set with SetMeshData and is decoded
+// according to the bits per coordinate, component and flag and the `decode`
+// array.
+func NewPdfShadingType4(cs PdfColorspace, bitsPerCoordinate, bitsPerComponent, bitsPerFlag int,
+	decode []float64) *PdfShadingType4 {
+	shading := &PdfShadingType4{
+		PdfShading:        newPdfShading(4, cs),
+		BitsPerCoordinate: core.MakeInteger(int64(bitsPerCoordinate)),
+		BitsPerComponent:  core.MakeInteger(int64(bitsPerComponent)),
+		BitsPerFlag:       core.MakeInteger(int64(bitsPerFlag)),
+		Decode:            core.MakeArrayFromFloats(decode),
+	}
+	shading.context = shading
+	return shading
+}
+
+// NewPdfShadingType5 returns a new lattice-form triangle mesh shading in
+// colorspace `cs`, with `verticesPerRow` vertices in each row of the lattice.
+// The mesh data is set with SetMeshData.
+func NewPdfShadingType5(cs PdfColorspace, bitsPerCoordinate, bitsPerComponent, verticesPerRow int,
+	decode []float64) *PdfShadingType5 {
+	shading := &PdfShadingType5{
+		PdfShading:        newPdfShading(5, cs),
+		BitsPerCoordinate: core.MakeInteger(int64(bitsPerCoordinate)),
+		BitsPerComponent:  core.MakeInteger(int64(bitsPerComponent)),
+		VerticesPerRow:    core.MakeInteger(int64(verticesPerRow)),
+		Decode:            core.MakeArrayFromFloats(decode),
+	}
+	shading.context = shading
+	return shading
+}
+
+// NewPdfShadingType6 returns a new Coons patch mesh shading in colorspace
+// `cs`. The mesh data is set with SetMeshData.
+func NewPdfShadingType6(cs PdfColorspace, bitsPerCoordinate, bitsPerComponent, bitsPerFlag int,
+	decode []float64) *PdfShadingType6 {
+	shading := &PdfShadingType6{
+		PdfShading:        newPdfShading(6, cs),
+		BitsPerCoordinate: core.MakeInteger(int64(bitsPerCoordinate)),
+		BitsPerComponent:  core.MakeInteger(int64(bitsPerComponent)),
+		BitsPerFlag:       core.MakeInteger(int64(bitsPerFlag)),
+		Decode:            core.MakeArrayFromFloats(decode),
+	}
+	shading.context = shading
+	return shading
+}
+
+// NewPdfShadingType7 returns a new tensor-product patch mesh shading in
+// colorspace `cs`. The mesh data is set with SetMeshData.
+func NewPdfShadingType7(cs PdfColorspace, bitsPerCoordinate, bitsPerComponent, bitsPerFlag int,
+	decode []float64) *PdfShadingType7 {
+	shading := &PdfShadingType7{
+		PdfShading:        newPdfShading(7, cs),
+		BitsPerCoordinate: core.MakeInteger(int64(bitsPerCoordinate)),
+		BitsPerComponent:  core.MakeInteger(int64(bitsPerComponent)),
+		BitsPerFlag:       core.MakeInteger(int64(bitsPerFlag)),
+		Decode:            core.MakeArrayFromFloats(decode),
+	}
+	shading.context = shading
+	return shading
+}
+
+// NewPdfShadingFromPdfObject loads a shading from a shading dictionary or,
+// for mesh shadings, a shading stream.
+func NewPdfShadingFromPdfObject(obj core.PdfObject) (*PdfShading, error) {
+	return newPdfShadingFromPdfObject(obj)
+}
+
+// GetMeshData returns the decoded mesh data of mesh shadings (types 4-7).
+func (s *PdfShading) GetMeshData() ([]byte, error) {
+	stream, ok := s.container.(*core.PdfObjectStream)
+	if !ok {
+		return nil, errors.New("shading has no mesh data")
+	}
+	return core.DecodeStream(stream)
+}
+
+// SetMeshData sets the mesh data of mesh shadings (types 4-7), encoded with
+// `encoder`. The data is not compressed if `encoder` is nil.
+func (s *PdfShading) SetMeshData(data []byte, encoder core.StreamEncoder) error {
+	stream, ok := s.container.(*core.PdfObjectStream)
+	if !ok {
+		return errors.New("shading has no mesh data")
+	}
+	if encoder == nil {
+		encoder = core.NewRawEncoder()
+	}
+	encoded, err := encoder.EncodeBytes(data)
+	if err != nil {
+		return err
+	}
+
+	dict := stream.PdfObjectDictionary
+	dict.Remove("Filter")
+	dict.Remove("DecodeParms")
+	dict.Merge(encoder.MakeStreamDict())
+	dict.Set("Length", core.MakeInteger(int64(len(encoded))))
+	stream.Stream = encoded
+	return nil
+}
+
 // Used for PDF parsing. Loads the PDF shading from a PDF object.
 // Can be either an indirect object (types 1-3) containing the dictionary, or
 // a stream object with the stream dictionary containing the shading dictionary (types 4-7).
@@ -566,15 +723,19 @@ func newPdfShadingType4FromDictionary(dict *core.PdfObjectDictionary) (*PdfShadi
 	}
 	shading.Decode = arr
 
-	// Function (required).
-	obj = dict.Get("Function")
-	if obj == nil {
-		common.Log.Debug("Required attribute missing:  Function")
-		return nil, ErrRequiredAttributeMissing
-	}
-	shading.Function = []PdfFunction{}
-	if array, is := obj.(*core.PdfObjectArray); is {
-		for _, obj := range array.Elements() {
+	// Function (optional).
+	if obj := dict.Get("Function"); obj != nil {
+		shading.Function = []PdfFunction{}
+		if array, is := obj.(*core.PdfObjectArray); is {
+			for _, obj := range array.Elements() {
+				function, err := newPdfFunctionFromPdfObject(obj)
+				if err != nil {
+					common.Log.Debug("Error parsing function: %v", err)
+					return nil, err
+				}
+				shading.Function = append(shading.Function, function)
+			}
+		} else {
 			function, err := newPdfFunctionFromPdfObject(obj)
 			if err != nil {
 				common.Log.Debug("Error parsing function: %v", err)
@@ -582,13 +743,6 @@ func newPdfShadingType4FromDictionary(dict *core.PdfObjectDictionary) (*PdfShadi
 			}
 			shading.Function = append(shading.Function, function)
 		}
-	} else {
-		function, err := newPdfFunctionFromPdfObject(obj)
-		if err != nil {
-			common.Log.Debug("Error parsing function: %v", err)
-			return nil, err
-		}
-		shading.Function = append(shading.Function, function)
 	}
 
 	return &shading, nil
@@ -653,7 +807,6 @@ func newPdfShadingType5FromDictionary(dict *core.PdfObjectDictionary) (*PdfShadi
 
 	// Function (optional).
 	if obj := dict.Get("Function"); obj != nil {
-		// Function (required).
 		shading.Function = []PdfFunction{}
 		if array, is := obj.(*core.PdfObjectArray); is {
 			for _, obj := range array.Elements() {
@@ -0,0 +1,85 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestShadingPatternRoundTrip(t *testing.T) {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+	page.Resources = NewPdfPageResources()
+
+	// Free-form triangle mesh.
+	mesh := NewPdfShadingType4(NewPdfColorspaceDeviceGray(), 8, 8, 8, []float64{0, 100, 0, 100, 0, 1})
+	meshData := []byte{0, 0, 0, 0, 0, 255, 0, 255, 0, 255, 0, 128}
+	require.NoError(t, mesh.SetMeshData(meshData, core.NewFlateEncoder()))
+	require.NoError(t, page.Resources.SetShadingByName("Sh1", mesh.ToPdfObject()))
+
+	// Tiling pattern with a matrix and resources.
+	tiling := NewPdfTilingPattern(PdfRectangle{Urx: 10, Ury: 10}, 12, 12, true)
+	tiling.Matrix = core.MakeArrayFromFloats([]float64{1, 0, 0, 1, 5, 5})
+	require.NoError(t, tiling.Resources.SetShadingByName("Sh1", mesh.ToPdfObject()))
+	require.NoError(t, tiling.SetContentStream([]byte("/Sh1 sh"), nil))
+	require.NoError(t, page.Resources.SetPatternByName("P1", tiling.ToPdfObject()))
+
+	// Shading pattern with an axial shading.
+	function := &PdfFunctionType2{Domain: []float64{0, 1}, C0: []float64{0}, C1: []float64{1}, N: 1}
+	axial := NewPdfShadingType2(NewPdfColorspaceDeviceGray(), 0, 0, 100, 0, function)
+	shadingPattern := NewPdfShadingPattern(axial.PdfShading)
+	require.NoError(t, page.Resources.SetPatternByName("P2", shadingPattern.ToPdfObject()))
+	require.NoError(t, page.AddContentStreamByString("/Sh1 sh /Pattern cs /P1 scn 0 0 10 10 re f"))
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	page, err = r.GetPage(1)
+	require.NoError(t, err)
+
+	sh, ok := page.Resources.GetShadingByName("Sh1")
+	require.True(t, ok)
+	require.IsType(t, &PdfShadingType4{}, sh.GetContext())
+	data, err := sh.GetMeshData()
+	require.NoError(t, err)
+	require.Equal(t, meshData, data)
+
+	pattern, ok := page.Resources.GetPatternByName("P1")
+	require.True(t, ok)
+	require.True(t, pattern.IsTiling())
+	loadedTiling := pattern.GetAsTilingPattern()
+	require.True(t, loadedTiling.IsColored())
+	matrix, err := loadedTiling.Matrix.ToFloat64Array()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 0, 0, 1, 5, 5}, matrix)
+	_, ok = loadedTiling.Resources.GetShadingByName("Sh1")
+	require.True(t, ok)
+	content, err := loadedTiling.GetContentStream()
+	require.NoError(t, err)
+	require.Equal(t, "/Sh1 sh", string(content))
+
+	pattern, ok = page.Resources.GetPatternByName("P2")
+	require.True(t, ok)
+	require.True(t, pattern.IsShading())
+	loadedAxial, ok := pattern.GetAsShadingPattern().Shading.GetContext().(*PdfShadingType2)
+	require.True(t, ok)
+	coords, err := loadedAxial.Coords.ToFloat64Array()
+	require.NoError(t, err)
+	require.Equal(t, []float64{0, 0, 100, 0}, coords)
+
+	// Dictionary shadings have no mesh data.
+	_, err = loadedAxial.GetMeshData()
+	require.Error(t, err)
+}
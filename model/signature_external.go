@@ -0,0 +1,223 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// ExternalSignature represents a document containing an unsigned signature
+// field, whose signature is produced out-of-band, e.g. by a signing device or
+// a remote signing service. The signature Contents of the document are a
+// placeholder of reserved size, filled with zeros, which is excluded from the
+// byte range covered by the signature.
+//
+// The two-step signing flow is:
+//  1. Prepare the document with PdfAppender.PrepareExternalSignature and send
+//     the signed data (SignedData) or its digest (Digest) to the signer. The
+//     document data can be stored meanwhile and loaded back with
+//     NewExternalSignature.
+//  2. Embed the signature returned by the signer with Embed.
+type ExternalSignature struct {
+	// Data contains the document, with the signature placeholder.
+	Data []byte
+
+	// ByteRange contains the offsets and lengths of the two byte ranges of
+	// the document covered by the signature, i.e. the data before and after
+	// the signature Contents.
+	ByteRange [4]int64
+}
+
+// externalByteRangeRegexp matches the byte range array of signature
+// dictionaries.
+var externalByteRangeRegexp = regexp.MustCompile(`/ByteRange\s*\[\s*(\d+)\s+(\d+)\s+(\d+)\s+(\d+)\s*\]`)
+
+// NewExternalSignature returns the external signature of the document `data`
+// prepared by PdfAppender.PrepareExternalSignature. The placeholder is
+// located by the last byte range of the document, which must cover the whole
+// document except for the signature Contents.
+func NewExternalSignature(data []byte) (*ExternalSignature, error) {
+	matches := externalByteRangeRegexp.FindAllSubmatch(data, -1)
+	if len(matches) == 0 {
+		return nil, errors.New("signature byte range not found")
+	}
+
+	s := &ExternalSignature{Data: data}
+	match := matches[len(matches)-1]
+	for i := range s.ByteRange {
+		val, err := strconv.ParseInt(string(match[i+1]), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		s.ByteRange[i] = val
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// PrepareExternalSignature adds the signature field `field` to the page
+// `pageNum` (see Sign) and writes the document, reserving `contentsSize`
+// bytes for the signature. The filter and subfilter of the signature are set
+// to Adobe.PPKLite and adbe.pkcs7.detached if not specified. The appender
+// cannot be written again afterwards. The signature of the returned document
+// is embedded with ExternalSignature.Embed, once produced.
+func (a *PdfAppender) PrepareExternalSignature(pageNum int, field *PdfFieldSignature, contentsSize int) (*ExternalSignature, error) {
+	if field == nil {
+		return nil, errors.New("signature field cannot be nil")
+	}
+	sig := field.V
+	if sig == nil {
+		return nil, errors.New("signature dictionary cannot be nil")
+	}
+	if contentsSize <= 0 {
+		return nil, errors.New("signature contents size must be positive")
+	}
+	sigDict, ok := sig.container.PdfObject.(*pdfSignDictionary)
+	if !ok {
+		return nil, errors.New("invalid signature dictionary")
+	}
+
+	var handler SignatureHandler = &externalSignatureHandler{contentsSize: contentsSize}
+	if err := handler.InitSignature(sig); err != nil {
+		return nil, err
+	}
+	sigDict.handler = &handler
+
+	if err := a.Sign(pageNum, field); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := a.Write(&buf); err != nil {
+		return nil, err
+	}
+
+	s := &ExternalSignature{Data: buf.Bytes()}
+	if sig.ByteRange == nil || sig.ByteRange.Len() != 4 {
+		return nil, errors.New("invalid signature byte range")
+	}
+	for i := range s.ByteRange {
+		val, err := core.GetNumberAsInt64(sig.ByteRange.Get(i))
+		if err != nil {
+			return nil, errors.New("invalid signature byte range")
+		}
+		s.ByteRange[i] = val
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SignedData returns the data of the document covered by the signature,
+// i.e. the document without the signature Contents.
+func (s *ExternalSignature) SignedData() []byte {
+	br := s.ByteRange
+	data := make([]byte, 0, br[1]+br[3])
+	data = append(data, s.Data[br[0]:br[0]+br[1]]...)
+	return append(data, s.Data[br[2]:br[2]+br[3]]...)
+}
+
+// Digest returns the digest of the data covered by the signature, computed
+// with the hash function `hash`, which must be linked into the binary.
+func (s *ExternalSignature) Digest(hash crypto.Hash) ([]byte, error) {
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash function %d not available", hash)
+	}
+	h := hash.New()
+	br := s.ByteRange
+	h.Write(s.Data[br[0] : br[0]+br[1]])
+	h.Write(s.Data[br[2] : br[2]+br[3]])
+	return h.Sum(nil), nil
+}
+
+// ContentsSize returns the maximum size in bytes of the signature which can
+// be embedded in the document.
+func (s *ExternalSignature) ContentsSize() int {
+	// The Contents are hex-encoded and delimited by angle brackets.
+	return int(s.ByteRange[2]-s.ByteRange[1]-2) / 2
+}
+
+// Embed writes the signature `signature` (e.g. a DER-encoded PKCS#7
+// detached signature) into the signature Contents of the document data. An
+// error is returned if the signature exceeds the reserved size.
+func (s *ExternalSignature) Embed(signature []byte) error {
+	if len(signature) > s.ContentsSize() {
+		return fmt.Errorf("signature size %d exceeds reserved size %d", len(signature), s.ContentsSize())
+	}
+
+	contents := s.Data[s.ByteRange[1]+1 : s.ByteRange[2]-1]
+	n := hex.Encode(contents, signature)
+	for i := n; i < len(contents); i++ {
+		contents[i] = '0'
+	}
+	return nil
+}
+
+// validate checks that the byte range of the signature covers the whole
+// document, except for hex-encoded signature Contents.
+func (s *ExternalSignature) validate() error {
+	br, size := s.ByteRange, int64(len(s.Data))
+	if br[0] != 0 || br[1] <= 0 || br[2] < br[1]+2 || br[3] < 0 || br[2]+br[3] != size {
+		return errors.New("signature byte range does not cover the document")
+	}
+	if s.Data[br[1]] != '<' || s.Data[br[2]-1] != '>' {
+		return errors.New("signature contents not found")
+	}
+	return nil
+}
+
+// externalSignatureHandler is the signature handler of signatures produced
+// out-of-band, which reserves the space of the signature Contents.
+type externalSignatureHandler struct {
+	contentsSize int
+}
+
+// IsApplicable returns false, as the handler cannot validate signatures.
+func (h *externalSignatureHandler) IsApplicable(sig *PdfSignature) bool {
+	return false
+}
+
+// Validate returns an error, as the handler cannot validate signatures.
+func (h *externalSignatureHandler) Validate(sig *PdfSignature, digest Hasher) (SignatureValidationResult, error) {
+	return SignatureValidationResult{}, errors.New("external signatures cannot be validated by the placeholder handler")
+}
+
+// InitSignature sets the handler, the default filter and subfilter and the
+// Contents placeholder of the signature.
+func (h *externalSignatureHandler) InitSignature(sig *PdfSignature) error {
+	sig.Handler = h
+	if sig.Filter == nil {
+		sig.Filter = core.MakeName("Adobe.PPKLite")
+	}
+	if sig.SubFilter == nil {
+		sig.SubFilter = core.MakeName("adbe.pkcs7.detached")
+	}
+	return h.Sign(sig, nil)
+}
+
+// NewDigest returns a digest discarding the signed data, which is hashed by
+// the external signer.
+func (h *externalSignatureHandler) NewDigest(sig *PdfSignature) (Hasher, error) {
+	return ioutil.Discard, nil
+}
+
+// Sign sets the Contents of the signature to the placeholder.
+func (h *externalSignatureHandler) Sign(sig *PdfSignature, digest Hasher) error {
+	sig.Contents = core.MakeHexString(string(make([]byte, h.contentsSize)))
+	return nil
+}
@@ -0,0 +1,588 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// maxStructTreeDepth is the maximum depth of the structure trees loaded by
+// the reader.
+const maxStructTreeDepth = 256
+
+// Standard structure types (section 14.8.4 p. 600 PDF32000_2008).
+const (
+	StructTypeDocument  = "Document"
+	StructTypePart      = "Part"
+	StructTypeArt       = "Art"
+	StructTypeSect      = "Sect"
+	StructTypeDiv       = "Div"
+	StructTypeTOC       = "TOC"
+	StructTypeTOCI      = "TOCI"
+	StructTypeP         = "P"
+	StructTypeH         = "H"
+	StructTypeH1        = "H1"
+	StructTypeH2        = "H2"
+	StructTypeH3        = "H3"
+	StructTypeH4        = "H4"
+	StructTypeH5        = "H5"
+	StructTypeH6        = "H6"
+	StructTypeL         = "L"
+	StructTypeLI        = "LI"
+	StructTypeLbl       = "Lbl"
+	StructTypeLBody     = "LBody"
+	StructTypeTable     = "Table"
+	StructTypeTR        = "TR"
+	StructTypeTH        = "TH"
+	StructTypeTD        = "TD"
+	StructTypeTHead     = "THead"
+	StructTypeTBody     = "TBody"
+	StructTypeTFoot     = "TFoot"
+	StructTypeSpan      = "Span"
+	StructTypeQuote     = "Quote"
+	StructTypeNote      = "Note"
+	StructTypeReference = "Reference"
+	StructTypeCode      = "Code"
+	StructTypeLink      = "Link"
+	StructTypeAnnot     = "Annot"
+	StructTypeFigure    = "Figure"
+	StructTypeFormula   = "Formula"
+	StructTypeForm      = "Form"
+	StructTypeCaption   = "Caption"
+)
+
+// StructTreeRoot represents the structure tree root of a tagged PDF document
+// (Table 322 - p. 565).
+type StructTreeRoot struct {
+	// Kids contains the top level structure elements.
+	Kids []*StructElement
+
+	// RoleMap maps the custom structure types to standard structure types.
+	RoleMap map[string]string
+
+	// ClassMap maps the attribute class names to attribute objects.
+	ClassMap core.PdfObject
+}
+
+// StructElement represents a structure element (Table 323 - p. 566).
+type StructElement struct {
+	// Type is the structure type of the element (S).
+	Type string
+
+	// Parent is the parent element, or nil for top level elements.
+	Parent *StructElement
+
+	// Page is the page on which the content of the element is drawn (Pg).
+	// The marked content children of the element without explicit page are
+	// located on this page.
+	Page *core.PdfIndirectObject
+
+	// ID is the element identifier.
+	ID string
+
+	// Title is the title of the element (T).
+	Title string
+
+	// Lang is the language of the element content.
+	Lang string
+
+	// Alt is the alternate description of the element.
+	Alt string
+
+	// ActualText is the replacement text of the element content.
+	ActualText string
+
+	// Expansion is the expanded form of an abbreviation (E).
+	Expansion string
+
+	// Attributes contains the attribute objects of the element (A).
+	Attributes core.PdfObject
+
+	// Classes contains the attribute classes of the element (C).
+	Classes core.PdfObject
+
+	// Kids contains the children of the element, in logical order.
+	Kids []*StructKid
+}
+
+// StructKid represents a child of a structure element, which is either a
+// structure element, a marked content sequence or a PDF object such as an
+// annotation.
+type StructKid struct {
+	// Element is the child structure element, for structure element kids.
+	Element *StructElement
+
+	// MCID is the marked content identifier, for marked content kids.
+	// It is -1 for the other kinds of kids.
+	MCID int
+
+	// Page is the page containing the marked content sequence or object.
+	// If nil, the page of the parent element is used.
+	Page *core.PdfIndirectObject
+
+	// Stream is the content stream containing the marked content sequence,
+	// if it is not the content stream of the page (e.g. a Form XObject).
+	Stream core.PdfObject
+
+	// Object is the referenced object, for object reference kids (OBJR).
+	Object core.PdfObject
+}
+
+// IsElement returns true if the kid is a structure element.
+func (k *StructKid) IsElement() bool {
+	return k.Element != nil
+}
+
+// IsMarkedContent returns true if the kid is a marked content sequence.
+func (k *StructKid) IsMarkedContent() bool {
+	return k.Element == nil && k.MCID >= 0
+}
+
+// IsObjectRef returns true if the kid is an object reference.
+func (k *StructKid) IsObjectRef() bool {
+	return k.Element == nil && k.MCID < 0 && k.Object != nil
+}
+
+// NewStructTreeRoot returns a new empty structure tree root.
+func NewStructTreeRoot() *StructTreeRoot {
+	return &StructTreeRoot{RoleMap: map[string]string{}}
+}
+
+// NewStructElement returns a new structure element of the specified type.
+func NewStructElement(structType string) *StructElement {
+	return &StructElement{Type: structType}
+}
+
+// Add appends a top level structure element to the tree.
+func (t *StructTreeRoot) Add(elem *StructElement) {
+	elem.Parent = nil
+	t.Kids = append(t.Kids, elem)
+}
+
+// ResolveType returns the standard structure type corresponding to the
+// structure type `structType`, following the role map of the tree.
+func (t *StructTreeRoot) ResolveType(structType string) string {
+	for i := 0; i < len(t.RoleMap); i++ {
+		mapped, ok := t.RoleMap[structType]
+		if !ok || mapped == structType {
+			break
+		}
+		structType = mapped
+	}
+	return structType
+}
+
+// Walk calls `fn` for each structure element of the tree, in depth first
+// logical order. The traversal of the children of an element is skipped if
+// `fn` returns false.
+func (t *StructTreeRoot) Walk(fn func(elem *StructElement, depth int) bool) {
+	var walk func(elem *StructElement, depth int)
+	walk = func(elem *StructElement, depth int) {
+		if !fn(elem, depth) {
+			return
+		}
+		for _, kid := range elem.Kids {
+			if kid.Element != nil {
+				walk(kid.Element, depth+1)
+			}
+		}
+	}
+	for _, elem := range t.Kids {
+		walk(elem, 0)
+	}
+}
+
+// FindByMCID returns the structure element containing the marked content
+// sequence with the identifier `mcid` of the page `page`, or nil if not found.
+func (t *StructTreeRoot) FindByMCID(page *core.PdfIndirectObject, mcid int) *StructElement {
+	var found *StructElement
+	t.Walk(func(elem *StructElement, depth int) bool {
+		if found != nil {
+			return false
+		}
+		for _, kid := range elem.Kids {
+			if kid.IsMarkedContent() && kid.MCID == mcid && elem.kidPage(kid) == page {
+				found = elem
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// Add appends the structure element `child` to the children of the element.
+func (e *StructElement) Add(child *StructElement) {
+	child.Parent = e
+	e.Kids = append(e.Kids, &StructKid{Element: child, MCID: -1})
+}
+
+// AddMarkedContent appends the marked content sequence with the identifier
+// `mcid` to the children of the element. The sequence is located in the
+// content stream of `page`, or of the page of the element if nil.
+func (e *StructElement) AddMarkedContent(page *core.PdfIndirectObject, mcid int) {
+	e.Kids = append(e.Kids, &StructKid{MCID: mcid, Page: page})
+}
+
+// AddObjectRef appends a reference to the object `obj` (e.g. an annotation
+// dictionary) to the children of the element. The object is located on
+// `page`, or on the page of the element if nil.
+func (e *StructElement) AddObjectRef(page *core.PdfIndirectObject, obj core.PdfObject) {
+	e.Kids = append(e.Kids, &StructKid{MCID: -1, Page: page, Object: obj})
+}
+
+// Elements returns the child structure elements of the element.
+func (e *StructElement) Elements() []*StructElement {
+	var elems []*StructElement
+	for _, kid := range e.Kids {
+		if kid.Element != nil {
+			elems = append(elems, kid.Element)
+		}
+	}
+	return elems
+}
+
+// kidPage returns the page of the kid `kid` of the element.
+func (e *StructElement) kidPage(kid *StructKid) *core.PdfIndirectObject {
+	if kid.Page != nil {
+		return kid.Page
+	}
+	for elem := e; elem != nil; elem = elem.Parent {
+		if elem.Page != nil {
+			return elem.Page
+		}
+	}
+	return nil
+}
+
+// GetStructTreeRoot returns the structure tree of the document, or nil if the
+// document is not a tagged PDF document.
+func (r *PdfReader) GetStructTreeRoot() (*StructTreeRoot, error) {
+	rootDict, ok := core.GetDict(r.catalog.Get("StructTreeRoot"))
+	if !ok {
+		return nil, nil
+	}
+
+	tree := NewStructTreeRoot()
+	if roleMap, ok := core.GetDict(rootDict.Get("RoleMap")); ok {
+		for _, key := range roleMap.Keys() {
+			if name, ok := core.GetNameVal(roleMap.Get(key)); ok {
+				tree.RoleMap[string(key)] = name
+			}
+		}
+	}
+	tree.ClassMap = rootDict.Get("ClassMap")
+
+	visited := map[*core.PdfObjectDictionary]struct{}{}
+	for _, kid := range structKids(rootDict.Get("K")) {
+		elemDict, ok := core.GetDict(kid)
+		if !ok {
+			continue
+		}
+		elem, err := loadStructElement(elemDict, nil, visited, 0)
+		if err != nil {
+			return nil, err
+		}
+		if elem != nil {
+			tree.Kids = append(tree.Kids, elem)
+		}
+	}
+	return tree, nil
+}
+
+// structKids returns the kids of a K entry, which is either a single kid or
+// an array of kids.
+func structKids(obj core.PdfObject) []core.PdfObject {
+	if obj == nil {
+		return nil
+	}
+	if arr, ok := core.GetArray(obj); ok {
+		return arr.Elements()
+	}
+	return []core.PdfObject{obj}
+}
+
+// loadStructElement loads the structure element `dict` and its descendants.
+func loadStructElement(dict *core.PdfObjectDictionary, parent *StructElement,
+	visited map[*core.PdfObjectDictionary]struct{}, depth int) (*StructElement, error) {
+	if depth > maxStructTreeDepth {
+		return nil, errors.New("structure tree too deep")
+	}
+	if _, ok := visited[dict]; ok {
+		common.Log.Debug("ERROR: structure element loop detected")
+		return nil, nil
+	}
+	visited[dict] = struct{}{}
+
+	structType, ok := core.GetNameVal(dict.Get("S"))
+	if !ok {
+		common.Log.Debug("ERROR: structure element without type. Skipping")
+		return nil, nil
+	}
+
+	elem := &StructElement{
+		Type:       structType,
+		Parent:     parent,
+		Attributes: dict.Get("A"),
+		Classes:    dict.Get("C"),
+	}
+	elem.Page, _ = core.GetIndirect(dict.Get("Pg"))
+	for _, field := range []struct {
+		key core.PdfObjectName
+		val *string
+	}{
+		{"ID", &elem.ID},
+		{"T", &elem.Title},
+		{"Lang", &elem.Lang},
+		{"Alt", &elem.Alt},
+		{"ActualText", &elem.ActualText},
+		{"E", &elem.Expansion},
+	} {
+		if str, ok := core.GetString(dict.Get(field.key)); ok {
+			*field.val = str.Decoded()
+		}
+	}
+
+	for _, kidObj := range structKids(dict.Get("K")) {
+		if mcid, ok := core.GetIntVal(kidObj); ok {
+			elem.Kids = append(elem.Kids, &StructKid{MCID: mcid})
+			continue
+		}
+
+		kidDict, ok := core.GetDict(kidObj)
+		if !ok {
+			common.Log.Debug("Invalid structure element kid: %v", kidObj)
+			continue
+		}
+
+		kidType, _ := core.GetNameVal(kidDict.Get("Type"))
+		switch kidType {
+		case "MCR":
+			mcid, ok := core.GetIntVal(kidDict.Get("MCID"))
+			if !ok {
+				continue
+			}
+			kid := &StructKid{MCID: mcid, Stream: kidDict.Get("Stm")}
+			kid.Page, _ = core.GetIndirect(kidDict.Get("Pg"))
+			elem.Kids = append(elem.Kids, kid)
+		case "OBJR":
+			kid := &StructKid{MCID: -1, Object: kidDict.Get("Obj")}
+			kid.Page, _ = core.GetIndirect(kidDict.Get("Pg"))
+			if kid.Object != nil {
+				elem.Kids = append(elem.Kids, kid)
+			}
+		default:
+			child, err := loadStructElement(kidDict, elem, visited, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if child != nil {
+				elem.Kids = append(elem.Kids, &StructKid{Element: child, MCID: -1})
+			}
+		}
+	}
+	return elem, nil
+}
+
+// structTreeWriter holds the state used for serializing a structure tree.
+type structTreeWriter struct {
+	// pageKeys maps the pages to their StructParents keys.
+	pageKeys map[*core.PdfIndirectObject]int
+
+	// pageMCIDs contains the structure elements of the marked content
+	// sequences of the pages, by parent tree key and MCID.
+	pageMCIDs map[int]map[int]*core.PdfIndirectObject
+
+	// objects contains the structure elements of the referenced objects,
+	// by parent tree key.
+	objects map[int]*core.PdfIndirectObject
+
+	nextKey int
+}
+
+// ToPdfObject returns the PDF representation of the structure tree, including
+// its parent tree. The StructParents entries of the pages containing marked
+// content and the StructParent entries of the referenced objects are set
+// accordingly.
+func (t *StructTreeRoot) ToPdfObject() core.PdfObject {
+	rootDict := core.MakeDict()
+	root := core.MakeIndirectObject(rootDict)
+	rootDict.Set("Type", core.MakeName("StructTreeRoot"))
+
+	sw := &structTreeWriter{
+		pageKeys:  map[*core.PdfIndirectObject]int{},
+		pageMCIDs: map[int]map[int]*core.PdfIndirectObject{},
+		objects:   map[int]*core.PdfIndirectObject{},
+	}
+
+	kids := core.MakeArray()
+	for _, elem := range t.Kids {
+		kids.Append(sw.writeElement(elem, root))
+	}
+	rootDict.Set("K", kids)
+
+	if len(t.RoleMap) > 0 {
+		roleMap := core.MakeDict()
+		for key, val := range t.RoleMap {
+			roleMap.Set(core.PdfObjectName(key), core.MakeName(val))
+		}
+		rootDict.Set("RoleMap", roleMap)
+	}
+	rootDict.SetIfNotNil("ClassMap", t.ClassMap)
+
+	// Build parent tree.
+	nums := core.MakeArray()
+	for key := 0; key < sw.nextKey; key++ {
+		if elem, ok := sw.objects[key]; ok {
+			nums.Append(core.MakeInteger(int64(key)), elem)
+			continue
+		}
+
+		mcids := sw.pageMCIDs[key]
+		maxMCID := -1
+		for mcid := range mcids {
+			if mcid > maxMCID {
+				maxMCID = mcid
+			}
+		}
+		parents := core.MakeArray()
+		for mcid := 0; mcid <= maxMCID; mcid++ {
+			if elem, ok := mcids[mcid]; ok {
+				parents.Append(elem)
+			} else {
+				parents.Append(core.MakeNull())
+			}
+		}
+		nums.Append(core.MakeInteger(int64(key)), parents)
+	}
+	parentTree := core.MakeDict()
+	parentTree.Set("Nums", nums)
+	rootDict.Set("ParentTree", parentTree)
+	rootDict.Set("ParentTreeNextKey", core.MakeInteger(int64(sw.nextKey)))
+
+	return root
+}
+
+// writeElement returns the PDF representation of the structure element
+// `elem`, whose parent is `parent`.
+func (sw *structTreeWriter) writeElement(elem *StructElement, parent core.PdfObject) *core.PdfIndirectObject {
+	dict := core.MakeDict()
+	container := core.MakeIndirectObject(dict)
+
+	dict.Set("Type", core.MakeName("StructElem"))
+	dict.Set("S", core.MakeName(elem.Type))
+	dict.Set("P", parent)
+	if elem.Page != nil {
+		dict.Set("Pg", elem.Page)
+	}
+	for _, field := range []struct {
+		key core.PdfObjectName
+		val string
+	}{
+		{"ID", elem.ID},
+		{"T", elem.Title},
+		{"Lang", elem.Lang},
+		{"Alt", elem.Alt},
+		{"ActualText", elem.ActualText},
+		{"E", elem.Expansion},
+	} {
+		if field.val != "" {
+			dict.Set(field.key, makeTextString(field.val))
+		}
+	}
+	dict.SetIfNotNil("A", elem.Attributes)
+	dict.SetIfNotNil("C", elem.Classes)
+
+	kids := core.MakeArray()
+	for _, kid := range elem.Kids {
+		page := elem.kidPage(kid)
+		switch {
+		case kid.Element != nil:
+			kids.Append(sw.writeElement(kid.Element, container))
+		case kid.MCID >= 0:
+			if page == nil {
+				common.Log.Debug("ERROR: marked content %d without page. Skipping", kid.MCID)
+				continue
+			}
+			if kid.Stream != nil || page != elem.Page {
+				mcr := core.MakeDict()
+				mcr.Set("Type", core.MakeName("MCR"))
+				mcr.Set("Pg", page)
+				mcr.Set("MCID", core.MakeInteger(int64(kid.MCID)))
+				mcr.SetIfNotNil("Stm", kid.Stream)
+				kids.Append(mcr)
+			} else {
+				kids.Append(core.MakeInteger(int64(kid.MCID)))
+			}
+			if kid.Stream == nil {
+				key := sw.pageKey(page)
+				sw.pageMCIDs[key][kid.MCID] = container
+			}
+		case kid.Object != nil:
+			objr := core.MakeDict()
+			objr.Set("Type", core.MakeName("OBJR"))
+			if page != nil {
+				objr.Set("Pg", page)
+			}
+			objr.Set("Obj", kid.Object)
+			kids.Append(objr)
+
+			if objDict, ok := core.GetDict(kid.Object); ok {
+				key := sw.nextKey
+				sw.nextKey++
+				sw.objects[key] = container
+				objDict.Set("StructParent", core.MakeInteger(int64(key)))
+			}
+		}
+	}
+	if kids.Len() > 0 {
+		dict.Set("K", kids)
+	}
+	return container
+}
+
+// pageKey returns the parent tree key of the page `page`, setting the
+// StructParents entry of the page if not already done.
+func (sw *structTreeWriter) pageKey(page *core.PdfIndirectObject) int {
+	if key, ok := sw.pageKeys[page]; ok {
+		return key
+	}
+
+	key := sw.nextKey
+	sw.nextKey++
+	sw.pageKeys[page] = key
+	sw.pageMCIDs[key] = map[int]*core.PdfIndirectObject{}
+	if pageDict, ok := core.GetDict(page); ok {
+		pageDict.Set("StructParents", core.MakeInteger(int64(key)))
+	}
+	return key
+}
+
+// SetStructTreeRoot sets the structure tree of the output document and marks
+// the document as a tagged PDF document. The method must be called after
+// adding the pages referenced by the structure tree to the writer.
+func (w *PdfWriter) SetStructTreeRoot(tree *StructTreeRoot) error {
+	if tree == nil {
+		return nil
+	}
+
+	obj := tree.ToPdfObject()
+	w.catalog.Set("StructTreeRoot", obj)
+
+	// Keep the other flags of the mark information dictionary, if set.
+	markInfo := newPdfMarkInfoFromPdfObject(w.catalog.Get("MarkInfo"))
+	if markInfo == nil {
+		markInfo = &PdfMarkInfo{}
+	}
+	markInfo.Marked = true
+	w.SetMarkInfo(markInfo)
+
+	common.Log.Trace("Setting catalog StructTreeRoot...")
+	return w.addObjects(obj)
+}
@@ -0,0 +1,183 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestStructTreeReadWrite(t *testing.T) {
+	w := NewPdfWriter()
+	var pages []*core.PdfIndirectObject
+	for i := 0; i < 2; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+		content := `/H1 <</MCID 0>> BDC BT /F1 12 Tf 72 720 Td (Title) Tj ET EMC
+/P <</MCID 1>> BDC BT /F1 12 Tf 72 700 Td (Text) Tj ET EMC`
+		require.NoError(t, page.AddContentStreamByString(content))
+		require.NoError(t, w.AddPage(page))
+		pages = append(pages, page.GetPageAsIndirectObject())
+	}
+
+	annot := core.MakeIndirectObject(core.MakeDict())
+
+	tree := NewStructTreeRoot()
+	tree.RoleMap["Heading"] = StructTypeH1
+	doc := NewStructElement(StructTypeDocument)
+	doc.Lang = "en-US"
+	tree.Add(doc)
+
+	for i, page := range pages {
+		h := NewStructElement("Heading")
+		h.Page = page
+		h.Title = "Heading"
+		h.AddMarkedContent(nil, 0)
+		doc.Add(h)
+
+		p := NewStructElement(StructTypeP)
+		p.Page = page
+		p.ActualText = "Text"
+		p.AddMarkedContent(nil, 1)
+		if i == 1 {
+			p.AddMarkedContent(pages[0], 2)
+			p.AddObjectRef(nil, annot)
+		}
+		doc.Add(p)
+	}
+	require.Equal(t, StructTypeH1, tree.ResolveType("Heading"))
+	require.Equal(t, doc.Kids[3].Element, tree.FindByMCID(pages[0], 2))
+	require.NoError(t, w.SetStructTreeRoot(tree))
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	catalog, ok := core.GetDict(r.parser.GetTrailer().Get("Root"))
+	require.True(t, ok)
+	markInfo, ok := core.GetDict(catalog.Get("MarkInfo"))
+	require.True(t, ok)
+	marked, _ := core.GetBoolVal(markInfo.Get("Marked"))
+	require.True(t, marked)
+
+	readTree, err := r.GetStructTreeRoot()
+	require.NoError(t, err)
+	require.NotNil(t, readTree)
+	require.Equal(t, StructTypeH1, readTree.ResolveType("Heading"))
+	require.Len(t, readTree.Kids, 1)
+
+	readDoc := readTree.Kids[0]
+	require.Equal(t, StructTypeDocument, readDoc.Type)
+	require.Equal(t, "en-US", readDoc.Lang)
+	elems := readDoc.Elements()
+	require.Len(t, elems, 4)
+	for _, elem := range elems {
+		require.Equal(t, readDoc, elem.Parent)
+	}
+	require.Equal(t, "Heading", elems[0].Type)
+	require.Equal(t, "Heading", elems[0].Title)
+	require.Equal(t, "Text", elems[1].ActualText)
+	require.Len(t, elems[3].Kids, 3)
+	require.True(t, elems[3].Kids[1].IsMarkedContent())
+	require.True(t, elems[3].Kids[2].IsObjectRef())
+
+	var types []string
+	readTree.Walk(func(elem *StructElement, depth int) bool {
+		types = append(types, elem.Type)
+		return true
+	})
+	require.Equal(t, []string{StructTypeDocument, "Heading", StructTypeP, "Heading", StructTypeP}, types)
+
+	// Marked content lookup.
+	page1 := r.PageList[0].GetPageAsIndirectObject()
+	page2 := r.PageList[1].GetPageAsIndirectObject()
+	require.Equal(t, elems[0], readTree.FindByMCID(page1, 0))
+	require.Equal(t, elems[1], readTree.FindByMCID(page1, 1))
+	require.Equal(t, elems[3], readTree.FindByMCID(page1, 2))
+	require.Equal(t, elems[2], readTree.FindByMCID(page2, 0))
+	require.Nil(t, readTree.FindByMCID(page2, 5))
+
+	// Parent tree.
+	rootDict, ok := core.GetDict(catalog.Get("StructTreeRoot"))
+	require.True(t, ok)
+	parents := map[int]core.PdfObject{}
+	numberTreeEntries(rootDict.Get("ParentTree"), 0, func(key int, obj core.PdfObject) {
+		parents[key] = obj
+	})
+	require.Len(t, parents, 3)
+
+	key, ok := core.GetIntVal(page1.PdfObject.(*core.PdfObjectDictionary).Get("StructParents"))
+	require.True(t, ok)
+	arr, ok := core.GetArray(parents[key])
+	require.True(t, ok)
+	require.Equal(t, 3, arr.Len())
+}
+
+func TestStructTreeParse(t *testing.T) {
+	rawText := `
+1 0 obj
+<< /Type /StructTreeRoot /K 2 0 R /RoleMap << /Custom /Custom2 /Custom2 /Custom >> >>
+endobj
+2 0 obj
+<< /Type /StructElem /S /Sect /P 1 0 R /Pg 5 0 R /K [3 0 R 0 << /Type /MCR /MCID 3 /Pg 6 0 R >>] >>
+endobj
+3 0 obj
+<< /Type /StructElem /S /Custom /P 2 0 R /K [2 0 R 3 0 R 1] /Alt (Alternate) >>
+endobj
+5 0 obj
+<< /Type /Page >>
+endobj
+6 0 obj
+<< /Type /Page >>
+endobj
+`
+	r := NewReaderForText(rawText)
+	require.NoError(t, r.ParseIndObjSeries())
+
+	root, err := r.parser.LookupByNumber(1)
+	require.NoError(t, err)
+	r.catalog = core.MakeDict()
+	r.catalog.Set("StructTreeRoot", root)
+
+	tree, err := r.GetStructTreeRoot()
+	require.NoError(t, err)
+	require.Len(t, tree.Kids, 1)
+
+	// Role map loops are not followed indefinitely.
+	require.NotEmpty(t, tree.ResolveType("Custom"))
+
+	sect := tree.Kids[0]
+	require.Equal(t, StructTypeSect, sect.Type)
+	require.Len(t, sect.Kids, 3)
+
+	// Loops in the structure tree are skipped.
+	custom := sect.Kids[0].Element
+	require.NotNil(t, custom)
+	require.Equal(t, "Alternate", custom.Alt)
+	require.Len(t, custom.Kids, 1)
+	require.True(t, custom.Kids[0].IsMarkedContent())
+
+	page5, err := r.parser.LookupByNumber(5)
+	require.NoError(t, err)
+	page6, err := r.parser.LookupByNumber(6)
+	require.NoError(t, err)
+	require.Equal(t, custom, tree.FindByMCID(page5.(*core.PdfIndirectObject), 1))
+	require.Equal(t, sect, tree.FindByMCID(page5.(*core.PdfIndirectObject), 0))
+	require.Equal(t, sect, tree.FindByMCID(page6.(*core.PdfIndirectObject), 3))
+	require.Nil(t, tree.FindByMCID(page6.(*core.PdfIndirectObject), 0))
+
+	// A missing structure tree is not an error.
+	r.catalog = core.MakeDict()
+	tree, err = r.GetStructTreeRoot()
+	require.NoError(t, err)
+	require.Nil(t, tree)
+}
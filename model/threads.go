@@ -0,0 +1,220 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// PdfThread represents an article thread, a sequence of logically connected
+// areas (beads) of the pages of a document, which viewers let the user follow
+// in order (section 12.4.3 "Articles" p. 386 PDF32000_2008).
+type PdfThread struct {
+	// Info is the information dictionary of the thread, containing its title,
+	// author, etc. Can be nil.
+	Info *PdfInfo
+
+	// Beads contains the beads of the thread, in reading order.
+	Beads []*PdfBead
+}
+
+// PdfBead represents an area of a page belonging to an article thread.
+type PdfBead struct {
+	// Page is the page containing the bead.
+	Page *PdfPage
+
+	// Rect is the location of the bead on the page, in default user space
+	// units.
+	Rect PdfRectangle
+}
+
+// NewPdfThread returns a new article thread with the specified title.
+func NewPdfThread(title string) *PdfThread {
+	info := NewPdfInfo()
+	info.Title = title
+	return &PdfThread{Info: info}
+}
+
+// AddBead appends a bead covering the area `rect` of `page` to the thread.
+func (t *PdfThread) AddBead(page *PdfPage, rect PdfRectangle) {
+	t.Beads = append(t.Beads, &PdfBead{Page: page, Rect: rect})
+}
+
+// GetThreads returns the article threads of the document (Threads entry of
+// the catalog), or nil if the document does not have article threads. The
+// beads whose pages are not part of the page tree are skipped.
+func (r *PdfReader) GetThreads() ([]*PdfThread, error) {
+	arr, ok := core.GetArray(r.catalog.Get("Threads"))
+	if !ok {
+		return nil, nil
+	}
+
+	pages := make(map[*core.PdfIndirectObject]*PdfPage, len(r.PageList))
+	for _, page := range r.PageList {
+		pages[page.primitive] = page
+	}
+
+	var threads []*PdfThread
+	for _, obj := range arr.Elements() {
+		threadDict, ok := core.GetDict(obj)
+		if !ok {
+			common.Log.Debug("ERROR: invalid thread object: %T", obj)
+			continue
+		}
+
+		thread := &PdfThread{}
+		if infoObj := threadDict.Get("I"); infoObj != nil {
+			info, err := NewPdfInfoFromObject(infoObj)
+			if err != nil {
+				return nil, err
+			}
+			thread.Info = info
+		}
+
+		// The beads form a circular list, starting at the first bead.
+		first := core.ResolveReference(threadDict.Get("F"))
+		visited := map[core.PdfObject]struct{}{}
+		for bead := first; bead != nil; {
+			if _, ok := visited[bead]; ok {
+				break
+			}
+			visited[bead] = struct{}{}
+
+			beadDict, ok := core.GetDict(bead)
+			if !ok {
+				common.Log.Debug("ERROR: invalid bead object: %T", bead)
+				break
+			}
+			if page, ok := referencedPage(beadDict.Get("P"), pages); ok {
+				if rect, err := beadRect(beadDict.Get("R")); err == nil {
+					thread.Beads = append(thread.Beads, &PdfBead{Page: page, Rect: *rect})
+				} else {
+					common.Log.Debug("ERROR: invalid bead rectangle: %v", err)
+				}
+			}
+			bead = core.ResolveReference(beadDict.Get("N"))
+		}
+		threads = append(threads, thread)
+	}
+	return threads, nil
+}
+
+// referencedPage returns the page of `pages` referenced by `obj`.
+func referencedPage(obj core.PdfObject, pages map[*core.PdfIndirectObject]*PdfPage) (*PdfPage, bool) {
+	ind, ok := core.GetIndirect(core.ResolveReference(obj))
+	if !ok {
+		return nil, false
+	}
+	page, ok := pages[ind]
+	return page, ok
+}
+
+// beadRect returns the normalized rectangle of the bead rectangle `obj`.
+func beadRect(obj core.PdfObject) (*PdfRectangle, error) {
+	arr, ok := core.GetArray(obj)
+	if !ok {
+		return nil, core.ErrTypeError
+	}
+	rect, err := NewPdfRectangle(*arr)
+	if err != nil {
+		return nil, err
+	}
+	return rect.Normalized(), nil
+}
+
+// SetThreads sets the article threads of the output document. The pages of
+// the beads must have been added to the writer. The beads of the pages of the
+// writer are replaced by the beads of the threads.
+func (w *PdfWriter) SetThreads(threads []*PdfThread) error {
+	// Make the bead arrays of the pages.
+	pageBeads := map[*PdfPage]*core.PdfObjectArray{}
+	var pageOrder []*PdfPage
+
+	arr := core.MakeArray()
+	for _, thread := range threads {
+		if thread == nil || len(thread.Beads) == 0 {
+			return errors.New("thread must have at least one bead")
+		}
+
+		threadDict := core.MakeDict()
+		threadDict.Set("Type", core.MakeName("Thread"))
+		if thread.Info != nil {
+			threadDict.Set("I", thread.Info.ToPdfObject())
+		}
+		threadObj := core.MakeIndirectObject(threadDict)
+
+		beadObjs := make([]*core.PdfIndirectObject, len(thread.Beads))
+		for i, bead := range thread.Beads {
+			if bead == nil || bead.Page == nil {
+				return errors.New("bead page cannot be nil")
+			}
+			if !w.hasObject(bead.Page.primitive) {
+				return errors.New("bead page not added to the writer")
+			}
+
+			beadDict := core.MakeDict()
+			beadDict.Set("Type", core.MakeName("Bead"))
+			if i == 0 {
+				beadDict.Set("T", threadObj)
+			}
+			beadDict.Set("P", bead.Page.primitive)
+			beadDict.Set("R", bead.Rect.ToPdfObject())
+			beadObjs[i] = core.MakeIndirectObject(beadDict)
+
+			beads, ok := pageBeads[bead.Page]
+			if !ok {
+				beads = core.MakeArray()
+				pageBeads[bead.Page] = beads
+				pageOrder = append(pageOrder, bead.Page)
+			}
+			beads.Append(beadObjs[i])
+		}
+
+		// Link the beads in a circular list.
+		for i, beadObj := range beadObjs {
+			beadDict := beadObj.PdfObject.(*core.PdfObjectDictionary)
+			beadDict.Set("N", beadObjs[(i+1)%len(beadObjs)])
+			beadDict.Set("V", beadObjs[(i+len(beadObjs)-1)%len(beadObjs)])
+		}
+		threadDict.Set("F", beadObjs[0])
+		arr.Append(threadObj)
+	}
+
+	w.removeBeads()
+	if len(threads) == 0 {
+		w.catalog.Remove("Threads")
+		return nil
+	}
+	for _, page := range pageOrder {
+		page.B = pageBeads[page]
+		if pageDict, ok := core.GetDict(page.primitive); ok {
+			pageDict.Set("B", page.B)
+		}
+	}
+
+	w.catalog.Set("Threads", arr)
+	return w.addObjects(arr)
+}
+
+// removeBeads removes the beads of the pages of the writer.
+func (w *PdfWriter) removeBeads() {
+	pagesDict, ok := core.GetDict(w.pages)
+	if !ok {
+		return
+	}
+	kids, ok := core.GetArray(pagesDict.Get("Kids"))
+	if !ok {
+		return
+	}
+	for _, kid := range kids.Elements() {
+		if pageDict, ok := core.GetDict(kid); ok {
+			pageDict.Remove("B")
+		}
+	}
+}
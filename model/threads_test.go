@@ -0,0 +1,105 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestThreadsAndTransitions(t *testing.T) {
+	var pages []*PdfPage
+	for i := 0; i < 3; i++ {
+		page := NewPdfPage()
+		page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+		pages = append(pages, page)
+	}
+
+	// Transitions of the slides.
+	fly := NewPdfTransition(TransitionStyleFly)
+	fly.Duration = 0.5
+	fly.Direction = TransitionDirectionNone
+	fly.Scale = 0.25
+	fly.Opaque = true
+	pages[0].SetTransition(fly)
+	pages[0].SetDisplayDuration(5)
+	split := NewPdfTransition(TransitionStyleSplit)
+	split.Dimension = TransitionDimensionVertical
+	split.Motion = TransitionMotionOutward
+	pages[1].SetTransition(split)
+
+	w := NewPdfWriter()
+	for _, page := range pages {
+		require.NoError(t, w.AddPage(page))
+	}
+
+	article := NewPdfThread("Article")
+	article.AddBead(pages[0], PdfRectangle{Llx: 50, Lly: 400, Urx: 300, Ury: 700})
+	article.AddBead(pages[0], PdfRectangle{Llx: 310, Lly: 400, Urx: 560, Ury: 700})
+	article.AddBead(pages[2], PdfRectangle{Llx: 50, Lly: 50, Urx: 560, Ury: 700})
+	sidebar := NewPdfThread("Sidebar")
+	sidebar.AddBead(pages[1], PdfRectangle{Llx: 400, Lly: 50, Urx: 560, Ury: 300})
+	require.NoError(t, w.SetThreads([]*PdfThread{article, sidebar}))
+	require.Error(t, w.SetThreads([]*PdfThread{NewPdfThread("Empty")}))
+	require.Error(t, w.SetThreads([]*PdfThread{{Beads: []*PdfBead{{Page: NewPdfPage()}}}}))
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	threads, err := r.GetThreads()
+	require.NoError(t, err)
+	require.Len(t, threads, 2)
+	require.Equal(t, "Article", threads[0].Info.Title)
+	require.Len(t, threads[0].Beads, 3)
+	require.Equal(t, r.PageList[0], threads[0].Beads[0].Page)
+	require.Equal(t, r.PageList[0], threads[0].Beads[1].Page)
+	require.Equal(t, r.PageList[2], threads[0].Beads[2].Page)
+	require.Equal(t, PdfRectangle{Llx: 310, Lly: 400, Urx: 560, Ury: 700}, threads[0].Beads[1].Rect)
+	require.Equal(t, "Sidebar", threads[1].Info.Title)
+	require.Len(t, threads[1].Beads, 1)
+	require.Equal(t, r.PageList[1], threads[1].Beads[0].Page)
+	beads, ok := core.GetArray(r.PageList[0].B)
+	require.True(t, ok)
+	require.Equal(t, 2, beads.Len())
+
+	trans, err := r.PageList[0].GetTransition()
+	require.NoError(t, err)
+	require.Equal(t, fly, trans)
+	dur, ok := r.PageList[0].GetDisplayDuration()
+	require.True(t, ok)
+	require.Equal(t, 5.0, dur)
+
+	trans, err = r.PageList[1].GetTransition()
+	require.NoError(t, err)
+	require.Equal(t, split, trans)
+	_, ok = r.PageList[1].GetDisplayDuration()
+	require.False(t, ok)
+
+	trans, err = r.PageList[2].GetTransition()
+	require.NoError(t, err)
+	require.Nil(t, trans)
+
+	// Copy the document with its threads.
+	w = NewPdfWriter()
+	for _, page := range r.PageList {
+		require.NoError(t, w.AddPage(page))
+	}
+	require.NoError(t, w.SetThreads(threads))
+	buf.Reset()
+	require.NoError(t, w.Write(&buf))
+	r, err = NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	threads, err = r.GetThreads()
+	require.NoError(t, err)
+	require.Len(t, threads, 2)
+	require.Len(t, threads[0].Beads, 3)
+}
@@ -0,0 +1,261 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// PdfTransparencyGroup represents the group attributes dictionary of a
+// transparency group XObject or page group (Table 147 - p. 339).
+// See section 11.6.6 "Transparency Group XObjects" (p. 338 PDF32000_2008).
+type PdfTransparencyGroup struct {
+	// CS is the group colour space, required for the groups of luminosity
+	// soft masks (Optional).
+	CS PdfColorspace
+
+	// I specifies whether the group is isolated.
+	I bool
+
+	// K specifies whether the group is a knockout group.
+	K bool
+}
+
+// NewPdfTransparencyGroup returns a new transparency group with colour space
+// `cs`, which can be nil.
+func NewPdfTransparencyGroup(cs PdfColorspace) *PdfTransparencyGroup {
+	return &PdfTransparencyGroup{CS: cs}
+}
+
+// NewPdfTransparencyGroupFromPdfObject loads a transparency group from the
+// group attributes dictionary `obj`.
+func NewPdfTransparencyGroupFromPdfObject(obj core.PdfObject) (*PdfTransparencyGroup, error) {
+	dict, ok := core.GetDict(obj)
+	if !ok {
+		return nil, fmt.Errorf("group not a dictionary (%T)", obj)
+	}
+	if s, _ := core.GetNameVal(dict.Get("S")); s != "Transparency" {
+		return nil, fmt.Errorf("unsupported group subtype %q", s)
+	}
+
+	group := &PdfTransparencyGroup{}
+	if obj := dict.Get("CS"); obj != nil {
+		cs, err := NewPdfColorspaceFromPdfObject(obj)
+		if err != nil {
+			common.Log.Debug("ERROR: invalid group colorspace: %v", err)
+			return nil, err
+		}
+		group.CS = cs
+	}
+	if val, ok := core.GetBool(dict.Get("I")); ok {
+		group.I = bool(*val)
+	}
+	if val, ok := core.GetBool(dict.Get("K")); ok {
+		group.K = bool(*val)
+	}
+	return group, nil
+}
+
+// ToPdfObject returns the group attributes dictionary.
+func (g *PdfTransparencyGroup) ToPdfObject() core.PdfObject {
+	dict := core.MakeDict()
+	dict.Set("Type", core.MakeName("Group"))
+	dict.Set("S", core.MakeName("Transparency"))
+	if g.CS != nil {
+		dict.Set("CS", g.CS.ToPdfObject())
+	}
+	if g.I {
+		dict.Set("I", core.MakeBool(true))
+	}
+	if g.K {
+		dict.Set("K", core.MakeBool(true))
+	}
+	return dict
+}
+
+// PdfSoftMaskType represents the subtype of a soft mask, specifying how the
+// mask values are derived from the transparency group G.
+type PdfSoftMaskType string
+
+// Soft mask subtypes.
+const (
+	// PdfSoftMaskAlpha derives the mask values from the group alpha.
+	PdfSoftMaskAlpha PdfSoftMaskType = "Alpha"
+
+	// PdfSoftMaskLuminosity derives the mask values from the luminosity of
+	// the group colors.
+	PdfSoftMaskLuminosity PdfSoftMaskType = "Luminosity"
+)
+
+// PdfSoftMask represents a soft mask dictionary, set with the SMask entry
+// of a graphics state parameter dictionary (Table 144 - p. 335).
+// See section 11.6.5.2 "Soft-Mask Dictionaries" (p. 334 PDF32000_2008).
+type PdfSoftMask struct {
+	S PdfSoftMaskType // Subtype (Required).
+
+	// G is the transparency group XObject defining the mask (Required).
+	G *XObjectForm
+
+	// BC is the backdrop colour of luminosity masks, in the group colour
+	// space. The default backdrop is black (Optional).
+	BC []float64
+
+	// TR is the transfer function mapping the computed values to the mask
+	// values, either a function or the name Identity (Optional).
+	TR core.PdfObject
+}
+
+// NewPdfSoftMask returns a new soft mask of type `typ` defined by the
+// transparency group `group`.
+func NewPdfSoftMask(typ PdfSoftMaskType, group *XObjectForm) *PdfSoftMask {
+	return &PdfSoftMask{
+		S: typ,
+		G: group,
+	}
+}
+
+// NewPdfSoftMaskFromPdfObject loads a soft mask from the SMask entry `obj` of
+// a graphics state parameter dictionary. Returns nil if the soft mask is the
+// name None.
+func NewPdfSoftMaskFromPdfObject(obj core.PdfObject) (*PdfSoftMask, error) {
+	if name, ok := core.GetNameVal(obj); ok {
+		if name == "None" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("invalid soft mask name %q", name)
+	}
+
+	dict, ok := core.GetDict(obj)
+	if !ok {
+		return nil, fmt.Errorf("soft mask not a dictionary (%T)", obj)
+	}
+
+	s, ok := core.GetNameVal(dict.Get("S"))
+	if !ok {
+		common.Log.Debug("Soft mask missing S")
+		return nil, ErrRequiredAttributeMissing
+	}
+	mask := &PdfSoftMask{S: PdfSoftMaskType(s)}
+	if mask.S != PdfSoftMaskAlpha && mask.S != PdfSoftMaskLuminosity {
+		return nil, fmt.Errorf("invalid soft mask subtype %q", s)
+	}
+
+	stream, ok := core.GetStream(dict.Get("G"))
+	if !ok {
+		common.Log.Debug("Soft mask missing G")
+		return nil, ErrRequiredAttributeMissing
+	}
+	group, err := NewXObjectFormFromStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	mask.G = group
+
+	if arr, ok := core.GetArray(dict.Get("BC")); ok {
+		bc, err := arr.ToFloat64Array()
+		if err != nil {
+			return nil, err
+		}
+		mask.BC = bc
+	}
+	mask.TR = dict.Get("TR")
+	return mask, nil
+}
+
+// ToPdfObject returns the soft mask dictionary.
+func (m *PdfSoftMask) ToPdfObject() core.PdfObject {
+	dict := core.MakeDict()
+	dict.Set("Type", core.MakeName("Mask"))
+	dict.Set("S", core.MakeName(string(m.S)))
+	if m.G != nil {
+		dict.Set("G", m.G.ToPdfObject())
+	}
+	if len(m.BC) > 0 {
+		dict.Set("BC", core.MakeArrayFromFloats(m.BC))
+	}
+	dict.SetIfNotNil("TR", m.TR)
+	return dict
+}
+
+// GetSoftMask returns the soft mask set by the graphics state parameter
+// dictionary `name`. Returns nil if the graphics state does not set a soft
+// mask or resets it.
+func (r *PdfPageResources) GetSoftMask(name core.PdfObjectName) (*PdfSoftMask, error) {
+	gs, err := r.GetExtGStateByName(name)
+	if err != nil {
+		return nil, err
+	}
+	return gs.SMask, nil
+}
+
+// GetTransparencyGroup returns the transparency group of the page. Returns
+// nil if the page is not a transparency group.
+func (p *PdfPage) GetTransparencyGroup() (*PdfTransparencyGroup, error) {
+	if p.Group == nil {
+		return nil, nil
+	}
+	return NewPdfTransparencyGroupFromPdfObject(p.Group)
+}
+
+// SetTransparencyGroup sets the page transparency group. The group is removed
+// if `group` is nil.
+func (p *PdfPage) SetTransparencyGroup(group *PdfTransparencyGroup) {
+	if group == nil {
+		p.Group = nil
+		return
+	}
+	p.Group = group.ToPdfObject()
+}
+
+// GetTransparencyGroup returns the transparency group of the form. Returns
+// nil if the form is not a transparency group XObject.
+func (xform *XObjectForm) GetTransparencyGroup() (*PdfTransparencyGroup, error) {
+	if xform.Group == nil {
+		return nil, nil
+	}
+	return NewPdfTransparencyGroupFromPdfObject(xform.Group)
+}
+
+// SetTransparencyGroup makes the form a transparency group XObject. The group
+// is removed if `group` is nil.
+func (xform *XObjectForm) SetTransparencyGroup(group *PdfTransparencyGroup) {
+	if group == nil {
+		xform.Group = nil
+		return
+	}
+	xform.Group = group.ToPdfObject()
+}
+
+// GetSoftMask returns the soft-mask image of the image, or nil if the image
+// has no soft mask.
+func (ximg *XObjectImage) GetSoftMask() (*XObjectImage, error) {
+	if ximg.SMask == nil {
+		return nil, nil
+	}
+	stream, ok := core.GetStream(ximg.SMask)
+	if !ok {
+		return nil, fmt.Errorf("SMask not a stream (%T)", ximg.SMask)
+	}
+	return NewXObjectImageFromStream(stream)
+}
+
+// SetSoftMask sets the soft-mask image of the image, which must be a
+// DeviceGray image. The soft mask is removed if `mask` is nil.
+func (ximg *XObjectImage) SetSoftMask(mask *XObjectImage) error {
+	if mask == nil {
+		ximg.SMask = nil
+		return nil
+	}
+	if _, ok := mask.ColorSpace.(*PdfColorspaceDeviceGray); !ok {
+		return errors.New("soft mask must be a DeviceGray image")
+	}
+	ximg.SMask = mask.ToPdfObject()
+	return nil
+}
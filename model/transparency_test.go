@@ -0,0 +1,107 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestTransparencyGroup(t *testing.T) {
+	group := NewPdfTransparencyGroup(NewPdfColorspaceDeviceRGB())
+	group.I = true
+	loaded, err := NewPdfTransparencyGroupFromPdfObject(group.ToPdfObject())
+	require.NoError(t, err)
+	require.IsType(t, &PdfColorspaceDeviceRGB{}, loaded.CS)
+	require.True(t, loaded.I)
+	require.False(t, loaded.K)
+
+	// Only transparency groups are supported.
+	dict := core.MakeDict()
+	dict.Set("S", core.MakeName("Other"))
+	_, err = NewPdfTransparencyGroupFromPdfObject(dict)
+	require.Error(t, err)
+}
+
+func TestSoftMaskRoundTrip(t *testing.T) {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+	page.Resources = NewPdfPageResources()
+	page.SetTransparencyGroup(&PdfTransparencyGroup{CS: NewPdfColorspaceDeviceRGB(), K: true})
+
+	// Graphics state with a luminosity soft mask.
+	form := NewXObjectForm()
+	form.BBox = core.MakeArrayFromFloats([]float64{0, 0, 100, 100})
+	form.SetTransparencyGroup(NewPdfTransparencyGroup(NewPdfColorspaceDeviceGray()))
+	require.NoError(t, form.SetContentStream([]byte("1 g 0 0 50 50 re f"), nil))
+	mask := NewPdfSoftMask(PdfSoftMaskLuminosity, form)
+	mask.BC = []float64{0}
+	gs := core.MakeDict()
+	gs.Set("SMask", mask.ToPdfObject())
+	require.NoError(t, page.Resources.AddExtGState("GS1", gs))
+	gs = core.MakeDict()
+	gs.Set("SMask", core.MakeName("None"))
+	require.NoError(t, page.Resources.AddExtGState("GS2", gs))
+
+	// Image with a soft mask.
+	img := &Image{Width: 2, Height: 1, BitsPerComponent: 8, ColorComponents: 3, Data: []byte{255, 0, 0, 0, 0, 255}}
+	ximg, err := NewXObjectImageFromImage(img, nil, nil)
+	require.NoError(t, err)
+	alpha := &Image{Width: 2, Height: 1, BitsPerComponent: 8, ColorComponents: 1, Data: []byte{255, 64}}
+	smask, err := NewXObjectImageFromImage(alpha, nil, core.NewFlateEncoder())
+	require.NoError(t, err)
+	require.Error(t, smask.SetSoftMask(ximg))
+	require.NoError(t, ximg.SetSoftMask(smask))
+	require.NoError(t, page.Resources.SetXObjectImageByName("Im1", ximg))
+	require.NoError(t, page.AddContentStreamByString("/GS1 gs /Im1 Do /GS2 gs"))
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	page, err = r.GetPage(1)
+	require.NoError(t, err)
+
+	group, err := page.GetTransparencyGroup()
+	require.NoError(t, err)
+	require.NotNil(t, group)
+	require.True(t, group.K)
+	require.IsType(t, &PdfColorspaceDeviceRGB{}, group.CS)
+
+	loadedMask, err := page.Resources.GetSoftMask("GS1")
+	require.NoError(t, err)
+	require.NotNil(t, loadedMask)
+	require.Equal(t, PdfSoftMaskLuminosity, loadedMask.S)
+	require.Equal(t, []float64{0}, loadedMask.BC)
+	content, err := loadedMask.G.GetContentStream()
+	require.NoError(t, err)
+	require.Equal(t, "1 g 0 0 50 50 re f", string(content))
+	maskGroup, err := loadedMask.G.GetTransparencyGroup()
+	require.NoError(t, err)
+	require.IsType(t, &PdfColorspaceDeviceGray{}, maskGroup.CS)
+
+	loadedMask, err = page.Resources.GetSoftMask("GS2")
+	require.NoError(t, err)
+	require.Nil(t, loadedMask)
+	_, err = page.Resources.GetSoftMask("GS3")
+	require.Error(t, err)
+
+	ximg, err = page.Resources.GetXObjectImageByName("Im1")
+	require.NoError(t, err)
+	smask, err = ximg.GetSoftMask()
+	require.NoError(t, err)
+	require.NotNil(t, smask)
+	alpha, err = smask.ToImage()
+	require.NoError(t, err)
+	require.Equal(t, []byte{255, 64}, alpha.Data)
+}
@@ -0,0 +1,131 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// usageRightsKeys are the entries of the permissions (Perms) dictionary of
+// the catalog containing usage rights signatures.
+var usageRightsKeys = []core.PdfObjectName{"UR3", "UR"}
+
+// UsageRightsRemoval reports the usage rights signatures removed from a
+// document by PdfAppender.RemoveUsageRights.
+type UsageRightsRemoval struct {
+	// Entries contains the keys of the removed entries of the permissions
+	// (Perms) dictionary, i.e. "UR3" and/or "UR". Empty if the document had
+	// no usage rights signature.
+	Entries []string
+
+	// Rights contains the rights which were granted by the removed
+	// signatures, as "Category/Right" strings, e.g. "Form/FillIn".
+	Rights []string
+
+	// PermsRemoved specifies whether the permissions dictionary was removed
+	// from the catalog, as it had no other entries.
+	PermsRemoved bool
+}
+
+// String returns a description of the removal.
+func (rep *UsageRightsRemoval) String() string {
+	if len(rep.Entries) == 0 {
+		return "No usage rights signature"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Removed usage rights signatures: %s", strings.Join(rep.Entries, ", "))
+	if len(rep.Rights) > 0 {
+		fmt.Fprintf(&b, " (rights: %s)", strings.Join(rep.Rights, ", "))
+	}
+	if rep.PermsRemoved {
+		b.WriteString(", removed empty permissions dictionary")
+	}
+	return b.String()
+}
+
+// HasUsageRights returns true if the document is Reader-enabled, i.e. has a
+// usage rights signature (UR or UR3 entry of the Perms dictionary of the
+// catalog). Usage rights signatures are invalidated when the document is
+// modified, which makes Adobe Reader disable the enabled features and warn
+// the user. See PdfAppender.RemoveUsageRights.
+func (r *PdfReader) HasUsageRights() bool {
+	if r.catalog == nil {
+		return false
+	}
+	perms, ok := core.GetDict(r.catalog.Get("Perms"))
+	if !ok {
+		return false
+	}
+	for _, key := range usageRightsKeys {
+		if perms.Get(key) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveUsageRights removes the usage rights signatures of the document in
+// the new revision, so that the document can be modified without Adobe
+// Reader reporting the usage rights as invalid. The other entries of the
+// permissions dictionary, such as the certification signature (DocMDP), are
+// kept. The returned report describes the removed signatures and the rights
+// they granted. The document is left unchanged if it has no usage rights
+// signature.
+func (a *PdfAppender) RemoveUsageRights() (*UsageRightsRemoval, error) {
+	report := &UsageRightsRemoval{}
+	if a.Reader.catalog == nil {
+		return report, nil
+	}
+	perms, ok := core.GetDict(a.Reader.catalog.Get("Perms"))
+	if !ok {
+		return report, nil
+	}
+
+	kept := core.MakeDict()
+	seen := map[string]struct{}{}
+	for _, key := range perms.Keys() {
+		if !isUsageRightsKey(key) {
+			kept.Set(key, perms.Get(key))
+			continue
+		}
+		report.Entries = append(report.Entries, string(key))
+		params, ok := signatureTransformParams(perms.Get(key))
+		if !ok {
+			continue
+		}
+		for _, right := range usageRights(params) {
+			if _, ok := seen[right]; !ok {
+				seen[right] = struct{}{}
+				report.Rights = append(report.Rights, right)
+			}
+		}
+	}
+	if len(report.Entries) == 0 {
+		return report, nil
+	}
+
+	if len(kept.Keys()) == 0 {
+		report.PermsRemoved = true
+		a.setCatalogEntry("Perms", nil)
+	} else {
+		a.setCatalogEntry("Perms", kept)
+	}
+	return report, nil
+}
+
+// isUsageRightsKey returns true if `key` is an entry of the permissions
+// dictionary containing a usage rights signature.
+func isUsageRightsKey(key core.PdfObjectName) bool {
+	for _, k := range usageRightsKeys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestRemoveUsageRights(t *testing.T) {
+	makeSig := func(method string, params *core.PdfObjectDictionary) *core.PdfObjectDictionary {
+		ref := core.MakeDict()
+		ref.Set("TransformMethod", core.MakeName(method))
+		ref.Set("TransformParams", params)
+		sig := core.MakeDict()
+		sig.Set("Reference", core.MakeArray(ref))
+		return sig
+	}
+	urParams := core.MakeDict()
+	urParams.Set("Form", core.MakeArray(core.MakeName("FillIn"), core.MakeName("Export")))
+	mdpParams := core.MakeDict()
+	mdpParams.Set("P", core.MakeInteger(2))
+
+	removeUsageRights := func(perms *core.PdfObjectDictionary) (*UsageRightsRemoval, *PdfReader) {
+		r, err := NewPdfReader(bytes.NewReader(writeSecurityTestDoc(t, "", "", nil, perms)))
+		require.NoError(t, err)
+		a, err := NewPdfAppender(r)
+		require.NoError(t, err)
+		report, err := a.RemoveUsageRights()
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, a.Write(&buf))
+		r, err = NewPdfReader(bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		return report, r
+	}
+
+	// Usage rights and certification signatures.
+	perms := core.MakeDict()
+	perms.Set("UR3", makeSig("UR3", urParams))
+	perms.Set("DocMDP", makeSig("DocMDP", mdpParams))
+	r, err := NewPdfReader(bytes.NewReader(writeSecurityTestDoc(t, "", "", nil, perms)))
+	require.NoError(t, err)
+	require.True(t, r.HasUsageRights())
+
+	report, r := removeUsageRights(perms)
+	require.Equal(t, []string{"UR3"}, report.Entries)
+	require.Equal(t, []string{"Form/FillIn", "Form/Export"}, report.Rights)
+	require.False(t, report.PermsRemoved)
+	require.Equal(t, "Removed usage rights signatures: UR3 (rights: Form/FillIn, Form/Export)", report.String())
+	require.False(t, r.HasUsageRights())
+	summary, err := r.GetSecuritySummary()
+	require.NoError(t, err)
+	require.True(t, summary.Certified)
+	require.Equal(t, 2, summary.CertificationPermissions)
+
+	// Usage rights signatures only.
+	perms = core.MakeDict()
+	perms.Set("UR", makeSig("UR", core.MakeDict()))
+	perms.Set("UR3", makeSig("UR3", urParams))
+	report, r = removeUsageRights(perms)
+	require.Equal(t, []string{"UR", "UR3"}, report.Entries)
+	require.Equal(t, []string{"Form/FillIn", "Form/Export"}, report.Rights)
+	require.True(t, report.PermsRemoved)
+	require.False(t, r.HasUsageRights())
+	require.Nil(t, r.catalog.Get("Perms"))
+
+	// No usage rights.
+	report, r = removeUsageRights(nil)
+	require.Empty(t, report.Entries)
+	require.Equal(t, "No usage rights signature", report.String())
+	require.False(t, r.HasUsageRights())
+}
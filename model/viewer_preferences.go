@@ -0,0 +1,331 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// PageLayout specifies the page layout used when the document is opened
+// (PageLayout entry of the catalog, Table 28 - p. 73 PDF32000_2008).
+type PageLayout string
+
+// Page layouts.
+const (
+	PageLayoutSinglePage     PageLayout = "SinglePage"     // One page at a time.
+	PageLayoutOneColumn      PageLayout = "OneColumn"      // Pages in one column.
+	PageLayoutTwoColumnLeft  PageLayout = "TwoColumnLeft"  // Pages in two columns, odd pages on the left.
+	PageLayoutTwoColumnRight PageLayout = "TwoColumnRight" // Pages in two columns, odd pages on the right.
+	PageLayoutTwoPageLeft    PageLayout = "TwoPageLeft"    // Two pages at a time, odd pages on the left.
+	PageLayoutTwoPageRight   PageLayout = "TwoPageRight"   // Two pages at a time, odd pages on the right.
+)
+
+// PageMode specifies how the document is displayed when opened (PageMode
+// entry of the catalog, Table 28 - p. 73 PDF32000_2008).
+type PageMode string
+
+// Page modes.
+const (
+	PageModeUseNone        PageMode = "UseNone"        // Neither outlines nor thumbnails visible.
+	PageModeUseOutlines    PageMode = "UseOutlines"    // Outline panel visible.
+	PageModeUseThumbs      PageMode = "UseThumbs"      // Thumbnails panel visible.
+	PageModeFullScreen     PageMode = "FullScreen"     // Full screen mode.
+	PageModeUseOC          PageMode = "UseOC"          // Optional content group panel visible.
+	PageModeUseAttachments PageMode = "UseAttachments" // Attachments panel visible.
+)
+
+// ReadingDirection represents the predominant reading order of the text of a
+// document.
+type ReadingDirection string
+
+// Reading directions.
+const (
+	ReadingDirectionL2R ReadingDirection = "L2R" // Left to right.
+	ReadingDirectionR2L ReadingDirection = "R2L" // Right to left, including vertical writing systems.
+)
+
+// PrintScaling specifies the page scaling option of the print dialog.
+type PrintScaling string
+
+// Print scaling options.
+const (
+	PrintScalingNone       PrintScaling = "None"       // No page scaling.
+	PrintScalingAppDefault PrintScaling = "AppDefault" // Default scaling of the viewer.
+)
+
+// Duplex specifies the paper handling option of the print dialog.
+type Duplex string
+
+// Duplex options.
+const (
+	DuplexNone          Duplex = ""                    // Not specified.
+	DuplexSimplex       Duplex = "Simplex"             // Print single-sided.
+	DuplexFlipShortEdge Duplex = "DuplexFlipShortEdge" // Duplex and flip on the short edge of the sheet.
+	DuplexFlipLongEdge  Duplex = "DuplexFlipLongEdge"  // Duplex and flip on the long edge of the sheet.
+)
+
+// ViewerPreferences represents the viewer preferences dictionary of a
+// document, which controls the way the document is presented on screen or in
+// print (section 12.2 "Viewer Preferences", Table 150 - p. 362
+// PDF32000_2008). The zero values of the fields are the default values and
+// are not written.
+type ViewerPreferences struct {
+	// HideToolbar specifies whether to hide the tool bars of the viewer.
+	HideToolbar bool
+
+	// HideMenubar specifies whether to hide the menu bar of the viewer.
+	HideMenubar bool
+
+	// HideWindowUI specifies whether to hide the user interface elements of
+	// the document window, leaving only the contents of the document.
+	HideWindowUI bool
+
+	// FitWindow specifies whether to resize the document window to fit the
+	// size of the first displayed page.
+	FitWindow bool
+
+	// CenterWindow specifies whether to center the document window on the
+	// screen.
+	CenterWindow bool
+
+	// DisplayDocTitle specifies whether the window title displays the title
+	// of the document information dictionary instead of the file name.
+	DisplayDocTitle bool
+
+	// NonFullScreenPageMode is the page mode used when exiting the full
+	// screen mode. Defaults to PageModeUseNone.
+	NonFullScreenPageMode PageMode
+
+	// Direction is the predominant reading order of the text. Defaults to
+	// ReadingDirectionL2R.
+	Direction ReadingDirection
+
+	// ViewArea, ViewClip, PrintArea and PrintClip are the page boundaries
+	// displayed, clipped on screen, printed and clipped when printing.
+	// Default to PageBoxCrop.
+	ViewArea  PageBox
+	ViewClip  PageBox
+	PrintArea PageBox
+	PrintClip PageBox
+
+	// PrintScaling is the page scaling option of the print dialog. Defaults
+	// to PrintScalingAppDefault.
+	PrintScaling PrintScaling
+
+	// Duplex is the paper handling option of the print dialog.
+	Duplex Duplex
+
+	// PickTrayByPDFSize specifies whether the page size is used to select
+	// the input paper tray. Nil if not specified, which lets the viewer
+	// decide.
+	PickTrayByPDFSize *bool
+
+	// PrintPageRange contains the 1-based page number ranges of the print
+	// dialog, as pairs of first and last page numbers.
+	PrintPageRange [][2]int
+
+	// NumCopies is the number of copies of the print dialog. Values smaller
+	// than 1 are not written.
+	NumCopies int
+}
+
+// NewViewerPreferences returns new viewer preferences with default values.
+func NewViewerPreferences() *ViewerPreferences {
+	return &ViewerPreferences{}
+}
+
+// newViewerPreferencesFromPdfObject loads the viewer preferences from the
+// specified viewer preferences dictionary.
+func newViewerPreferencesFromPdfObject(obj core.PdfObject) (*ViewerPreferences, error) {
+	d, ok := core.GetDict(obj)
+	if !ok {
+		return nil, core.ErrTypeError
+	}
+
+	prefs := &ViewerPreferences{}
+	getBool := func(key core.PdfObjectName) bool {
+		val, _ := core.GetBoolVal(d.Get(key))
+		return val
+	}
+	getName := func(key core.PdfObjectName) string {
+		val, _ := core.GetNameVal(d.Get(key))
+		return val
+	}
+
+	prefs.HideToolbar = getBool("HideToolbar")
+	prefs.HideMenubar = getBool("HideMenubar")
+	prefs.HideWindowUI = getBool("HideWindowUI")
+	prefs.FitWindow = getBool("FitWindow")
+	prefs.CenterWindow = getBool("CenterWindow")
+	prefs.DisplayDocTitle = getBool("DisplayDocTitle")
+	prefs.NonFullScreenPageMode = PageMode(getName("NonFullScreenPageMode"))
+	prefs.Direction = ReadingDirection(getName("Direction"))
+	prefs.ViewArea = PageBox(getName("ViewArea"))
+	prefs.ViewClip = PageBox(getName("ViewClip"))
+	prefs.PrintArea = PageBox(getName("PrintArea"))
+	prefs.PrintClip = PageBox(getName("PrintClip"))
+	prefs.PrintScaling = PrintScaling(getName("PrintScaling"))
+	prefs.Duplex = Duplex(getName("Duplex"))
+
+	if val, ok := core.GetBoolVal(d.Get("PickTrayByPDFSize")); ok {
+		prefs.PickTrayByPDFSize = &val
+	}
+	if arr, ok := core.GetArray(d.Get("PrintPageRange")); ok {
+		nums, err := arr.ToIntegerArray()
+		if err == nil {
+			for i := 0; i+1 < len(nums); i += 2 {
+				prefs.PrintPageRange = append(prefs.PrintPageRange, [2]int{nums[i], nums[i+1]})
+			}
+		}
+	}
+	if val, ok := core.GetIntVal(d.Get("NumCopies")); ok {
+		prefs.NumCopies = val
+	}
+
+	return prefs, nil
+}
+
+// ToPdfObject returns the viewer preferences dictionary.
+func (vp *ViewerPreferences) ToPdfObject() core.PdfObject {
+	d := core.MakeDict()
+	setBool := func(key core.PdfObjectName, val bool) {
+		if val {
+			d.Set(key, core.MakeBool(true))
+		}
+	}
+	setName := func(key core.PdfObjectName, val string) {
+		if val != "" {
+			d.Set(key, core.MakeName(val))
+		}
+	}
+
+	setBool("HideToolbar", vp.HideToolbar)
+	setBool("HideMenubar", vp.HideMenubar)
+	setBool("HideWindowUI", vp.HideWindowUI)
+	setBool("FitWindow", vp.FitWindow)
+	setBool("CenterWindow", vp.CenterWindow)
+	setBool("DisplayDocTitle", vp.DisplayDocTitle)
+	setName("NonFullScreenPageMode", string(vp.NonFullScreenPageMode))
+	setName("Direction", string(vp.Direction))
+	setName("ViewArea", string(vp.ViewArea))
+	setName("ViewClip", string(vp.ViewClip))
+	setName("PrintArea", string(vp.PrintArea))
+	setName("PrintClip", string(vp.PrintClip))
+	setName("PrintScaling", string(vp.PrintScaling))
+	setName("Duplex", string(vp.Duplex))
+
+	if vp.PickTrayByPDFSize != nil {
+		d.Set("PickTrayByPDFSize", core.MakeBool(*vp.PickTrayByPDFSize))
+	}
+	if len(vp.PrintPageRange) > 0 {
+		arr := core.MakeArray()
+		for _, r := range vp.PrintPageRange {
+			arr.Append(core.MakeInteger(int64(r[0])), core.MakeInteger(int64(r[1])))
+		}
+		d.Set("PrintPageRange", arr)
+	}
+	if vp.NumCopies > 0 {
+		d.Set("NumCopies", core.MakeInteger(int64(vp.NumCopies)))
+	}
+
+	return d
+}
+
+// GetViewerPreferences returns the viewer preferences of the document, or
+// nil if not specified.
+func (r *PdfReader) GetViewerPreferences() (*ViewerPreferences, error) {
+	obj := core.ResolveReference(r.catalog.Get("ViewerPreferences"))
+	if obj == nil || core.IsNullObject(obj) {
+		return nil, nil
+	}
+	if !r.isLazy {
+		if err := r.traverseObjectData(obj); err != nil {
+			return nil, err
+		}
+	}
+	return newViewerPreferencesFromPdfObject(obj)
+}
+
+// GetPageLayout returns the page layout used when the document is opened.
+// Returns PageLayoutSinglePage, the default value, if not specified.
+func (r *PdfReader) GetPageLayout() PageLayout {
+	if layout, ok := core.GetNameVal(r.catalog.Get("PageLayout")); ok {
+		return PageLayout(layout)
+	}
+	return PageLayoutSinglePage
+}
+
+// GetPageMode returns how the document is displayed when opened. Returns
+// PageModeUseNone, the default value, if not specified.
+func (r *PdfReader) GetPageMode() PageMode {
+	if mode, ok := core.GetNameVal(r.catalog.Get("PageMode")); ok {
+		return PageMode(mode)
+	}
+	return PageModeUseNone
+}
+
+// SetViewerPreferences sets the viewer preferences of the output document.
+// The viewer preferences are removed if `prefs` is nil.
+func (w *PdfWriter) SetViewerPreferences(prefs *ViewerPreferences) error {
+	if prefs == nil {
+		w.catalog.Remove("ViewerPreferences")
+		return nil
+	}
+
+	obj := prefs.ToPdfObject()
+	w.catalog.Set("ViewerPreferences", obj)
+	return w.addObjects(obj)
+}
+
+// SetPageLayout sets the page layout used when the output document is
+// opened. The entry is removed if `layout` is empty.
+func (w *PdfWriter) SetPageLayout(layout PageLayout) {
+	if layout == "" {
+		w.catalog.Remove("PageLayout")
+		return
+	}
+	w.catalog.Set("PageLayout", core.MakeName(string(layout)))
+}
+
+// SetPageMode sets how the output document is displayed when opened. The
+// entry is removed if `mode` is empty.
+func (w *PdfWriter) SetPageMode(mode PageMode) {
+	if mode == "" {
+		w.catalog.Remove("PageMode")
+		return
+	}
+	w.catalog.Set("PageMode", core.MakeName(string(mode)))
+}
+
+// SetViewerPreferences sets the viewer preferences of the document in the new
+// revision. The viewer preferences are removed if `prefs` is nil.
+func (a *PdfAppender) SetViewerPreferences(prefs *ViewerPreferences) {
+	if prefs == nil {
+		a.setCatalogEntry("ViewerPreferences", nil)
+		return
+	}
+	a.setCatalogEntry("ViewerPreferences", prefs.ToPdfObject())
+}
+
+// SetPageLayout sets the page layout used when the document is opened in the
+// new revision. The entry is removed if `layout` is empty.
+func (a *PdfAppender) SetPageLayout(layout PageLayout) {
+	if layout == "" {
+		a.setCatalogEntry("PageLayout", nil)
+		return
+	}
+	a.setCatalogEntry("PageLayout", core.MakeName(string(layout)))
+}
+
+// SetPageMode sets how the document is displayed when opened in the new
+// revision. The entry is removed if `mode` is empty.
+func (a *PdfAppender) SetPageMode(mode PageMode) {
+	if mode == "" {
+		a.setCatalogEntry("PageMode", nil)
+		return
+	}
+	a.setCatalogEntry("PageMode", core.MakeName(string(mode)))
+}
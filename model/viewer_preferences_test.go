@@ -0,0 +1,67 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewerPreferences(t *testing.T) {
+	page := NewPdfPage()
+	page.MediaBox = &PdfRectangle{Urx: 612, Ury: 792}
+
+	w := NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+
+	pickTray := false
+	prefs := NewViewerPreferences()
+	prefs.HideToolbar = true
+	prefs.FitWindow = true
+	prefs.DisplayDocTitle = true
+	prefs.NonFullScreenPageMode = PageModeUseOutlines
+	prefs.Direction = ReadingDirectionR2L
+	prefs.PrintArea = PageBoxMedia
+	prefs.PrintScaling = PrintScalingNone
+	prefs.Duplex = DuplexFlipLongEdge
+	prefs.PickTrayByPDFSize = &pickTray
+	prefs.PrintPageRange = [][2]int{{1, 1}, {3, 4}}
+	prefs.NumCopies = 2
+	require.NoError(t, w.SetViewerPreferences(prefs))
+	w.SetPageLayout(PageLayoutTwoColumnLeft)
+	w.SetPageMode(PageModeFullScreen)
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	readPrefs, err := r.GetViewerPreferences()
+	require.NoError(t, err)
+	require.Equal(t, prefs, readPrefs)
+	require.Equal(t, PageLayoutTwoColumnLeft, r.GetPageLayout())
+	require.Equal(t, PageModeFullScreen, r.GetPageMode())
+
+	// Update the preferences in an incremental update.
+	a, err := NewPdfAppender(r)
+	require.NoError(t, err)
+	a.SetViewerPreferences(nil)
+	a.SetPageLayout(PageLayoutSinglePage)
+	a.SetPageMode("")
+
+	var out bytes.Buffer
+	require.NoError(t, a.Write(&out))
+	r, err = NewPdfReader(bytes.NewReader(out.Bytes()))
+	require.NoError(t, err)
+
+	readPrefs, err = r.GetViewerPreferences()
+	require.NoError(t, err)
+	require.Nil(t, readPrefs)
+	require.Equal(t, PageLayoutSinglePage, r.GetPageLayout())
+	require.Equal(t, PageModeUseNone, r.GetPageMode())
+}
@@ -11,6 +11,7 @@ package model
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"flag"
@@ -93,7 +94,7 @@ func getPdfModifiedDate() time.Time {
 
 // SetPdfModifiedDate sets the ModDate attribute of the output PDF.
 func SetPdfModifiedDate(modifiedDate time.Time) {
-	pdfCreationDate = modifiedDate
+	pdfModifiedDate = modifiedDate
 }
 
 func getPdfProducer() string {
@@ -174,6 +175,10 @@ type PdfWriter struct {
 	acroForm *PdfAcroForm
 
 	optimizer              Optimizer
+	removeUnusedObjects    bool
+	pageTreeMaxKids        int
+	deterministic          *DeterministicOptions
+	idPolicy               DocumentIDPolicy
 	crossReferenceMap      map[int]crossReference
 	writeOffset            int64 // used by PdfAppender
 	ObjNumOffset           int
@@ -469,6 +474,20 @@ func (w *PdfWriter) SetPageLabels(pageLabels core.PdfObject) error {
 	return w.addObjects(pageLabels)
 }
 
+// SetCatalogMetadata sets the Metadata entry in the PDF catalog, which
+// contains the XMP metadata of the document.
+// See section 14.3.2 "Metadata Streams" (p. 556 PDF32000_2008).
+func (w *PdfWriter) SetCatalogMetadata(metadata *core.PdfObjectStream) error {
+	if metadata == nil {
+		w.catalog.Remove("Metadata")
+		return nil
+	}
+
+	common.Log.Trace("Setting catalog Metadata...")
+	w.catalog.Set("Metadata", metadata)
+	return w.addObjects(metadata)
+}
+
 // SetOptimizer sets the optimizer to optimize PDF before writing.
 func (w *PdfWriter) SetOptimizer(optimizer Optimizer) {
 	w.optimizer = optimizer
@@ -479,6 +498,80 @@ func (w *PdfWriter) GetOptimizer() Optimizer {
 	return w.optimizer
 }
 
+// SetRemoveUnusedObjects sets whether the objects which are not reachable from
+// the trailer are dropped before writing, e.g. the resources removed by the
+// optimizer or left behind by editing. Has no effect in append mode.
+func (w *PdfWriter) SetRemoveUnusedObjects(remove bool) {
+	w.removeUnusedObjects = remove
+}
+
+// removeUnreachableObjects drops the objects which are not reachable from the
+// document catalog, information dictionary or encryption dictionary.
+func (w *PdfWriter) removeUnreachableObjects() {
+	reachable := make(map[core.PdfObject]struct{}, len(w.objects))
+	var mark func(obj core.PdfObject)
+	mark = func(obj core.PdfObject) {
+		if obj == nil {
+			return
+		}
+		if _, ok := reachable[obj]; ok {
+			return
+		}
+		switch t := obj.(type) {
+		case *core.PdfObjectReference:
+			mark(t.Resolve())
+		case *core.PdfIndirectObject:
+			reachable[obj] = struct{}{}
+			mark(t.PdfObject)
+		case *core.PdfObjectStream:
+			reachable[obj] = struct{}{}
+			mark(t.PdfObjectDictionary)
+		case *core.PdfObjectArray:
+			reachable[obj] = struct{}{}
+			for _, elem := range t.Elements() {
+				mark(elem)
+			}
+		case *core.PdfObjectDictionary:
+			reachable[obj] = struct{}{}
+			for _, key := range t.Keys() {
+				mark(t.Get(key))
+			}
+		}
+	}
+	for _, root := range []*core.PdfIndirectObject{w.root, w.infoObj, w.encryptObj} {
+		if root != nil {
+			mark(root)
+		}
+	}
+
+	objects := make([]core.PdfObject, 0, len(w.objects))
+	objectsMap := make(map[core.PdfObject]struct{}, len(w.objects))
+	for _, obj := range w.objects {
+		if objStm, ok := obj.(*core.PdfObjectStreams); ok {
+			// Keep the reachable objects of the object streams.
+			var elements []core.PdfObject
+			for _, elem := range objStm.Elements() {
+				if _, ok := reachable[elem]; ok {
+					elements = append(elements, elem)
+				}
+			}
+			if len(elements) == 0 {
+				continue
+			}
+			if len(elements) < objStm.Len() {
+				obj = core.MakeObjectStreams(elements...)
+			}
+		} else if _, ok := reachable[obj]; !ok {
+			continue
+		}
+		objects = append(objects, obj)
+		objectsMap[obj] = struct{}{}
+	}
+	common.Log.Trace("Removed %d unreachable objects", len(w.objects)-len(objects))
+	w.objects = objects
+	w.objectsMap = objectsMap
+}
+
 func (w *PdfWriter) hasObject(obj core.PdfObject) bool {
 	_, found := w.objectsMap[obj]
 	return found
@@ -955,6 +1048,33 @@ func (w *PdfWriter) Encrypt(userPass, ownerPass []byte, options *EncryptOptions)
 	return nil
 }
 
+// RemoveEncryption cancels the encryption of the output file set by Encrypt.
+func (w *PdfWriter) RemoveEncryption() {
+	if w.crypter == nil {
+		return
+	}
+	if w.encryptObj != nil {
+		for i, obj := range w.objects {
+			if obj == w.encryptObj {
+				w.objects = append(w.objects[:i], w.objects[i+1:]...)
+				break
+			}
+		}
+		delete(w.objectsMap, w.encryptObj)
+	}
+	w.crypter = nil
+	w.encryptDict = nil
+	w.encryptObj = nil
+}
+
+// SetDocumentID sets the file identifiers written in the ID entry of the
+// trailer, which are otherwise only written for encrypted files: `id0` is
+// the permanent identifier of the document and `id1` identifies the revision.
+// Encrypt replaces the identifiers.
+func (w *PdfWriter) SetDocumentID(id0, id1 []byte) {
+	w.ids = core.MakeArray(core.MakeHexString(string(id0)), core.MakeHexString(string(id1)))
+}
+
 // Wrapper function to handle writing out string.
 func (w *PdfWriter) writeString(s string) {
 	if w.werr != nil {
@@ -977,6 +1097,14 @@ func (w *PdfWriter) writeBytes(bb []byte) {
 
 // Write writes out the PDF.
 func (w *PdfWriter) Write(writer io.Writer) error {
+	return w.WriteContext(context.Background(), writer, nil)
+}
+
+// WriteContext writes out the PDF as Write, stopping with the error of `ctx`
+// when it is cancelled or its deadline is exceeded. The optimizer is cancelled
+// too if it implements ContextOptimizer, e.g. optimize.Chain. If not nil,
+// `progress` is called with the number of objects written.
+func (w *PdfWriter) WriteContext(ctx context.Context, writer io.Writer, progress ProgressFunc) error {
 	common.Log.Trace("Write()")
 
 	lk := license.GetLicenseKey()
@@ -1032,10 +1160,17 @@ func (w *PdfWriter) Write(writer io.Writer) error {
 	// TODO: Copying wastes memory. Might be worth making user responsible for handling properly.
 	//       Is copy needed for optimization?
 	w.copyObjects()
+	if w.pageTreeMaxKids > 0 && !w.appendMode {
+		w.balancePageTree()
+	}
 
 	if w.optimizer != nil {
 		var err error
-		w.objects, err = w.optimizer.Optimize(w.objects)
+		if optimizer, ok := w.optimizer.(ContextOptimizer); ok {
+			w.objects, err = optimizer.OptimizeContext(ctx, w.objects)
+		} else {
+			w.objects, err = w.optimizer.Optimize(w.objects)
+		}
 		if err != nil {
 			return err
 		}
@@ -1045,6 +1180,13 @@ func (w *PdfWriter) Write(writer io.Writer) error {
 		}
 		w.objectsMap = objMap
 	}
+	if w.removeUnusedObjects && !w.appendMode {
+		w.removeUnreachableObjects()
+	}
+	w.applyDocumentIDPolicy()
+	if w.deterministic != nil && !w.appendMode {
+		w.applyDeterministic()
+	}
 
 	w.writePos = w.writeOffset
 	w.writer = bufio.NewWriter(writer)
@@ -1102,7 +1244,13 @@ func (w *PdfWriter) Write(writer io.Writer) error {
 	}
 
 	// Write out indirect/stream objects that are not in object streams.
-	for _, obj := range w.objects {
+	for i, obj := range w.objects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(i, len(w.objects))
+		}
 		if skip := objectsInObjectStreams[obj]; skip {
 			continue
 		}
@@ -1131,6 +1279,9 @@ func (w *PdfWriter) Write(writer io.Writer) error {
 		}
 		w.writeObject(int(objectNumber), obj)
 	}
+	if progress != nil {
+		progress(len(w.objects), len(w.objects))
+	}
 
 	xrefOffset := w.writePos
 	var maxIndex int
@@ -1204,6 +1355,8 @@ func (w *PdfWriter) Write(writer io.Writer) error {
 		// If encrypted!
 		if w.crypter != nil {
 			crossReferenceStream.Set("Encrypt", w.encryptObj)
+		}
+		if w.ids != nil {
 			crossReferenceStream.Set("ID", w.ids)
 			common.Log.Trace("Ids: %s", w.ids)
 		}
@@ -1257,6 +1410,8 @@ func (w *PdfWriter) Write(writer io.Writer) error {
 		// If encrypted!
 		if w.crypter != nil {
 			trailer.Set("Encrypt", w.encryptObj)
+		}
+		if w.ids != nil {
 			trailer.Set("ID", w.ids)
 			common.Log.Trace("Ids: %s", w.ids)
 		}
@@ -0,0 +1,150 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"sort"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// DeterministicOptions contains the options of the deterministic output mode
+// of the writer.
+type DeterministicOptions struct {
+	// ID is the file identifier written in both entries of the ID array of the
+	// trailer, unless the identifiers are set by SetDocumentID or Encrypt.
+	// 16 zero bytes are written if empty.
+	ID []byte
+
+	// CreationDate and ModDate are the creation and modification dates of the
+	// document information dictionary. The dates are removed if zero.
+	CreationDate time.Time
+	ModDate      time.Time
+}
+
+// SetDeterministic enables the deterministic output mode of the writer, with
+// the options `opts`, or disables it if `opts` is nil. The output of the
+// writer is then byte-identical for identical documents, e.g. for
+// reproducible builds or content-addressed storage: the objects are numbered
+// in the order in which they are reached from the catalog and the document
+// information dictionary, the dictionary entries are written with sorted keys
+// and the file identifiers and dates are set from `opts`. The output of the
+// optimizer must be deterministic too. Encrypted output is never deterministic,
+// as encryption uses random data. The mode is ignored in append mode.
+func (w *PdfWriter) SetDeterministic(opts *DeterministicOptions) {
+	w.deterministic = opts
+}
+
+// applyDeterministic sets the dates and the file identifiers of the document
+// according to the deterministic output options and puts the objects in
+// canonical order.
+func (w *PdfWriter) applyDeterministic() {
+	opts := w.deterministic
+	if info, ok := core.GetDict(w.infoObj); ok {
+		setDate := func(key core.PdfObjectName, t time.Time) {
+			info.Remove(key)
+			if t.IsZero() {
+				return
+			}
+			date, err := NewPdfDateFromTime(t)
+			if err != nil {
+				common.Log.Debug("ERROR: invalid %s: %v", key, err)
+				return
+			}
+			info.Set(key, date.ToPdfObject())
+		}
+		setDate("CreationDate", opts.CreationDate)
+		setDate("ModDate", opts.ModDate)
+	}
+
+	if w.ids == nil {
+		id := opts.ID
+		if len(id) == 0 {
+			id = make([]byte, 16)
+		}
+		w.ids = core.MakeArray(core.MakeHexString(string(id)), core.MakeHexString(string(id)))
+	}
+
+	// Sort the dictionary keys first, as they determine the order in which
+	// the objects are reached.
+	sorted := make(map[core.PdfObject]struct{}, len(w.objects))
+	var sortKeys func(obj core.PdfObject)
+	sortKeys = func(obj core.PdfObject) {
+		if _, ok := sorted[obj]; ok {
+			return
+		}
+		sorted[obj] = struct{}{}
+		switch t := obj.(type) {
+		case *core.PdfIndirectObject:
+			sortKeys(t.PdfObject)
+		case *core.PdfObjectStream:
+			sortKeys(t.PdfObjectDictionary)
+		case *core.PdfObjectArray:
+			for _, elem := range t.Elements() {
+				sortKeys(elem)
+			}
+		case *core.PdfObjectDictionary:
+			t.SortKeys()
+			for _, key := range t.Keys() {
+				sortKeys(t.Get(key))
+			}
+		}
+	}
+	for _, obj := range w.objects {
+		sortKeys(obj)
+	}
+
+	// Number the objects in depth-first order from the catalog and the
+	// document information dictionary. The unreachable objects, including
+	// the object streams, keep their order after the reachable ones.
+	rank := make(map[core.PdfObject]int, len(w.objects))
+	visited := make(map[core.PdfObject]struct{}, len(w.objects))
+	var visit func(obj core.PdfObject)
+	visit = func(obj core.PdfObject) {
+		if obj == nil {
+			return
+		}
+		if _, ok := visited[obj]; ok {
+			return
+		}
+		visited[obj] = struct{}{}
+		switch t := obj.(type) {
+		case *core.PdfObjectReference:
+			visit(t.Resolve())
+		case *core.PdfIndirectObject:
+			rank[obj] = len(rank)
+			visit(t.PdfObject)
+		case *core.PdfObjectStream:
+			rank[obj] = len(rank)
+			visit(t.PdfObjectDictionary)
+		case *core.PdfObjectArray:
+			for _, elem := range t.Elements() {
+				visit(elem)
+			}
+		case *core.PdfObjectDictionary:
+			for _, key := range t.Keys() {
+				visit(t.Get(key))
+			}
+		}
+	}
+	for _, root := range []*core.PdfIndirectObject{w.root, w.infoObj, w.encryptObj} {
+		if root != nil {
+			visit(root)
+		}
+	}
+
+	getRank := func(obj core.PdfObject) int {
+		if r, ok := rank[obj]; ok {
+			return r
+		}
+		return len(rank)
+	}
+	sort.SliceStable(w.objects, func(i, j int) bool {
+		return getRank(w.objects[i]) < getRank(w.objects[j])
+	})
+}
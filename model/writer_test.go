@@ -7,11 +7,16 @@ package model
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
 )
 
 // Tests loading annotations from file, writing back out and reloading.
@@ -127,3 +132,89 @@ func TestWriterErrorHandling(t *testing.T) {
 	err = w.Write(&out)
 	require.Error(t, err)
 }
+
+func TestWriteReadContext(t *testing.T) {
+	w := NewPdfWriter()
+	for i := 0; i < 3; i++ {
+		page := NewPdfPage()
+		require.NoError(t, page.AddContentStreamByString("0 0 10 10 re f"))
+		require.NoError(t, w.AddPage(page))
+	}
+
+	var done, total int
+	progress := func(d, n int) {
+		require.True(t, d >= done && d <= n)
+		done, total = d, n
+	}
+	var buf bytes.Buffer
+	require.NoError(t, w.WriteContext(context.Background(), &buf, progress))
+	require.NotZero(t, total)
+	require.Equal(t, total, done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := w.WriteContext(ctx, ioutil.Discard, nil)
+	require.Equal(t, context.Canceled, err)
+
+	done, total = 0, 0
+	r, err := NewPdfReaderContext(context.Background(), bytes.NewReader(buf.Bytes()), progress)
+	require.NoError(t, err)
+	require.NotZero(t, total)
+	require.Equal(t, total, done)
+	numPages, err := r.GetNumPages()
+	require.NoError(t, err)
+	require.Equal(t, 3, numPages)
+
+	_, err = NewPdfReaderContext(ctx, bytes.NewReader(buf.Bytes()), nil)
+	require.Equal(t, context.Canceled, err)
+}
+
+func TestWriterDeterministic(t *testing.T) {
+	write := func(reversed bool, id []byte) []byte {
+		w := NewPdfWriter()
+		for i := 0; i < 2; i++ {
+			page := NewPdfPage()
+			require.NoError(t, page.AddContentStreamByString("0 0 10 10 re f"))
+
+			// Build the resources with different key orders.
+			gs := core.MakeDict()
+			if reversed {
+				gs.Set("ca", core.MakeFloat(0.5))
+				gs.Set("CA", core.MakeFloat(0.5))
+			} else {
+				gs.Set("CA", core.MakeFloat(0.5))
+				gs.Set("ca", core.MakeFloat(0.5))
+			}
+			require.NoError(t, page.Resources.AddExtGState("GS0", core.MakeIndirectObject(gs)))
+			require.NoError(t, w.AddPage(page))
+		}
+		w.SetDeterministic(&DeterministicOptions{
+			ID:           id,
+			CreationDate: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		})
+
+		var buf bytes.Buffer
+		require.NoError(t, w.Write(&buf))
+		return buf.Bytes()
+	}
+
+	SetPdfCreationDate(time.Now())
+	SetPdfModifiedDate(time.Now())
+	defer SetPdfCreationDate(time.Time{})
+	defer SetPdfModifiedDate(time.Time{})
+
+	out := write(false, nil)
+	require.Equal(t, out, write(false, nil))
+	require.Equal(t, out, write(true, nil))
+	require.Contains(t, string(out), "<</CA 0.5/ca 0.5>>")
+	require.Contains(t, string(out), "/ID [<00000000000000000000000000000000> <00000000000000000000000000000000>]")
+	require.Contains(t, string(out), "/CreationDate (D:20200102030405+00'00')")
+	require.NotContains(t, string(out), "/ModDate")
+	require.Contains(t, string(write(false, []byte("abc"))), "/ID [<616263> <616263>]")
+
+	r, err := NewPdfReader(bytes.NewReader(out))
+	require.NoError(t, err)
+	numPages, err := r.GetNumPages()
+	require.NoError(t, err)
+	require.Equal(t, 2, numPages)
+}
@@ -0,0 +1,282 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+// XFAPacket represents a packet of an XFA (XML Forms Architecture) form,
+// such as the template or the datasets. See section 12.7.8 "XFA Forms"
+// (p. 456 PDF32000_2008).
+type XFAPacket struct {
+	// Name is the name of the packet (e.g. "template", "datasets"). The
+	// name is empty if the XFA resource is specified as a single stream
+	// containing the whole XDP document.
+	Name string
+
+	// Stream is the stream object containing the packet XML data.
+	Stream *core.PdfObjectStream
+}
+
+// Data returns the decoded XML data of the packet.
+func (p *XFAPacket) Data() ([]byte, error) {
+	if p.Stream == nil {
+		return nil, errors.New("missing XFA packet stream")
+	}
+	return core.DecodeStream(p.Stream)
+}
+
+// HasXFA returns true if the form contains an XFA resource. Documents which
+// contain both an XFA resource and AcroForm fields are hybrid forms.
+func (form *PdfAcroForm) HasXFA() bool {
+	if form == nil || form.XFA == nil {
+		return false
+	}
+	return !core.IsNullObject(core.ResolveReference(form.XFA))
+}
+
+// IsDynamicXFA returns true if the document contains a dynamic XFA form,
+// which has to be rendered from the XFA template by conforming viewers
+// (NeedsRendering catalog flag). The AcroForm fields of dynamic XFA forms, if
+// any, generally do not reflect the form contents.
+func (r *PdfReader) IsDynamicXFA() bool {
+	needsRendering, _ := core.GetBoolVal(r.catalog.Get("NeedsRendering"))
+	return needsRendering && r.AcroForm.HasXFA()
+}
+
+// XFAPackets returns the packets of the XFA resource of the form. The XFA
+// resource can be either a single stream, in which case a single unnamed
+// packet is returned, or an array of packet name and stream pairs.
+func (form *PdfAcroForm) XFAPackets() ([]*XFAPacket, error) {
+	if !form.HasXFA() {
+		return nil, nil
+	}
+
+	switch t := core.TraceToDirectObject(form.XFA).(type) {
+	case *core.PdfObjectStream:
+		return []*XFAPacket{{Stream: t}}, nil
+	case *core.PdfObjectArray:
+		if t.Len()%2 != 0 {
+			return nil, errors.New("invalid XFA array length")
+		}
+
+		var packets []*XFAPacket
+		for i := 0; i < t.Len(); i += 2 {
+			name, ok := core.GetStringVal(t.Get(i))
+			if !ok {
+				return nil, fmt.Errorf("invalid XFA packet name (%T)", t.Get(i))
+			}
+			stream, ok := core.GetStream(t.Get(i + 1))
+			if !ok {
+				return nil, fmt.Errorf("invalid XFA packet %s stream (%T)", name, t.Get(i+1))
+			}
+			packets = append(packets, &XFAPacket{Name: name, Stream: stream})
+		}
+		return packets, nil
+	}
+
+	return nil, fmt.Errorf("invalid XFA resource (%T)", form.XFA)
+}
+
+// GetXFAPacket returns the XFA packet with the specified name. If the XFA
+// resource is a single stream, the named packet cannot be retrieved directly
+// and core.ErrNotSupported is returned. Use GetXFADatasets for extracting the
+// datasets in both cases.
+func (form *PdfAcroForm) GetXFAPacket(name string) (*XFAPacket, error) {
+	packets, err := form.XFAPackets()
+	if err != nil {
+		return nil, err
+	}
+	for _, packet := range packets {
+		if packet.Name == "" {
+			return nil, core.ErrNotSupported
+		}
+		if packet.Name == name {
+			return packet, nil
+		}
+	}
+	return nil, fmt.Errorf("XFA packet %s not found", name)
+}
+
+// GetXFADatasets returns the XML data of the datasets packet of the XFA form,
+// which contains the form data.
+func (form *PdfAcroForm) GetXFADatasets() ([]byte, error) {
+	packets, err := form.XFAPackets()
+	if err != nil {
+		return nil, err
+	}
+	if len(packets) == 0 {
+		return nil, errors.New("form does not contain XFA data")
+	}
+
+	for _, packet := range packets {
+		if packet.Name == "datasets" {
+			return packet.Data()
+		}
+	}
+
+	// The datasets may be embedded in the complete XDP document.
+	if len(packets) == 1 && packets[0].Name == "" {
+		data, err := packets[0].Data()
+		if err != nil {
+			return nil, err
+		}
+		start, end, found := xfaFindElement(data, "datasets")
+		if !found {
+			return nil, errors.New("XFA datasets not found")
+		}
+		return data[start:end], nil
+	}
+
+	return nil, errors.New("XFA datasets not found")
+}
+
+// SetXFADatasets replaces the datasets packet of the XFA form with `data`.
+// A new stream is created for the packet, so that the change is detected
+// when writing the document or appending a new revision. If the XFA form
+// does not contain a datasets packet, a new one is inserted before the last
+// packet (usually the closing packet of the XDP document).
+func (form *PdfAcroForm) SetXFADatasets(data []byte) error {
+	packets, err := form.XFAPackets()
+	if err != nil {
+		return err
+	}
+	if len(packets) == 0 {
+		return errors.New("form does not contain XFA data")
+	}
+
+	// Single stream containing the whole XDP document.
+	if len(packets) == 1 && packets[0].Name == "" {
+		xdp, err := packets[0].Data()
+		if err != nil {
+			return err
+		}
+		start, end, found := xfaFindElement(xdp, "datasets")
+		if !found {
+			return errors.New("XFA datasets not found")
+		}
+
+		var buf bytes.Buffer
+		buf.Write(xdp[:start])
+		buf.Write(data)
+		buf.Write(xdp[end:])
+
+		stream, err := makeXFAStream(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		form.XFA = stream
+		return nil
+	}
+
+	stream, err := makeXFAStream(data)
+	if err != nil {
+		return err
+	}
+
+	arr := core.MakeArray()
+	replaced := false
+	for i, packet := range packets {
+		if packet.Name == "datasets" {
+			arr.Append(core.MakeString(packet.Name), stream)
+			replaced = true
+			continue
+		}
+		if !replaced && i == len(packets)-1 {
+			common.Log.Debug("XFA datasets packet not found. Inserting new packet.")
+			arr.Append(core.MakeString("datasets"), stream)
+			replaced = true
+		}
+		arr.Append(core.MakeString(packet.Name), packet.Stream)
+	}
+	form.XFA = arr
+	return nil
+}
+
+// RemoveXFA removes the XFA resource from the form. For hybrid forms, this
+// makes viewers use the AcroForm fields instead of the XFA form.
+// The NeedsRendering catalog flag, if set, should also be cleared for dynamic
+// XFA forms.
+func (form *PdfAcroForm) RemoveXFA() {
+	if form == nil {
+		return
+	}
+	form.XFA = nil
+	if dict, ok := core.GetDict(form.container); ok {
+		dict.Remove("XFA")
+	}
+}
+
+// makeXFAStream returns a new flate encoded stream containing `data`.
+func makeXFAStream(data []byte) (*core.PdfObjectStream, error) {
+	return core.MakeStream(data, core.NewFlateEncoder())
+}
+
+// xfaFindElement finds the element with the specified local name in the XML
+// `data`, regardless of its namespace prefix. It returns the start offset of
+// the opening tag and the end offset of the closing tag of the element.
+func xfaFindElement(data []byte, name string) (int, int, bool) {
+	var start int
+	for offset := 0; offset < len(data); {
+		idx := bytes.IndexByte(data[offset:], '<')
+		if idx < 0 {
+			return 0, 0, false
+		}
+		start = offset + idx
+
+		tagEnd := start + 1
+		for tagEnd < len(data) && !isXMLTagDelimiter(data[tagEnd]) {
+			tagEnd++
+		}
+		tag := data[start+1 : tagEnd]
+		if i := bytes.IndexByte(tag, ':'); i >= 0 {
+			tag = tag[i+1:]
+		}
+		if string(tag) != name {
+			offset = start + 1
+			continue
+		}
+
+		// Found opening tag. Check if it is self closing.
+		closeIdx := bytes.IndexByte(data[tagEnd:], '>')
+		if closeIdx < 0 {
+			return 0, 0, false
+		}
+		if data[tagEnd+closeIdx-1] == '/' {
+			return start, tagEnd + closeIdx + 1, true
+		}
+
+		// Find the closing tag, including the namespace prefix, if any.
+		closing := append([]byte("</"), data[start+1:tagEnd]...)
+		endIdx := bytes.Index(data[tagEnd:], closing)
+		if endIdx < 0 {
+			return 0, 0, false
+		}
+		end := tagEnd + endIdx
+		gt := bytes.IndexByte(data[end:], '>')
+		if gt < 0 {
+			return 0, 0, false
+		}
+		return start, end + gt + 1, true
+	}
+
+	return 0, 0, false
+}
+
+// isXMLTagDelimiter returns true if `b` terminates the name of an XML tag.
+func isXMLTagDelimiter(b byte) bool {
+	switch b {
+	case ' ', '\t', '\r', '\n', '>', '/':
+		return true
+	}
+	return false
+}
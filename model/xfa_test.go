@@ -0,0 +1,74 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+)
+
+func TestXFADatasets(t *testing.T) {
+	makeStream := func(data string) *core.PdfObjectStream {
+		stream, err := core.MakeStream([]byte(data), core.NewRawEncoder())
+		require.NoError(t, err)
+		return stream
+	}
+
+	datasets := `<xfa:datasets xmlns:xfa="http://www.xfa.org/schema/xfa-data/1.0/"><xfa:data><name>John</name></xfa:data></xfa:datasets>`
+	newDatasets := `<xfa:datasets xmlns:xfa="http://www.xfa.org/schema/xfa-data/1.0/"><xfa:data><name>Jane</name></xfa:data></xfa:datasets>`
+
+	// XFA packet array.
+	form := NewPdfAcroForm()
+	require.False(t, form.HasXFA())
+	form.XFA = core.MakeArray(
+		core.MakeString("preamble"), makeStream(`<xdp:xdp xmlns:xdp="http://ns.adobe.com/xdp/">`),
+		core.MakeString("template"), makeStream(`<template/>`),
+		core.MakeString("datasets"), makeStream(datasets),
+		core.MakeString("postamble"), makeStream(`</xdp:xdp>`),
+	)
+	require.True(t, form.HasXFA())
+
+	packets, err := form.XFAPackets()
+	require.NoError(t, err)
+	require.Len(t, packets, 4)
+
+	data, err := form.GetXFADatasets()
+	require.NoError(t, err)
+	require.Equal(t, datasets, string(data))
+
+	require.NoError(t, form.SetXFADatasets([]byte(newDatasets)))
+	data, err = form.GetXFADatasets()
+	require.NoError(t, err)
+	require.Equal(t, newDatasets, string(data))
+
+	packet, err := form.GetXFAPacket("template")
+	require.NoError(t, err)
+	data, err = packet.Data()
+	require.NoError(t, err)
+	require.Equal(t, `<template/>`, string(data))
+
+	// Single XDP stream.
+	xdp := `<xdp:xdp xmlns:xdp="http://ns.adobe.com/xdp/"><template/>` + datasets + `</xdp:xdp>`
+	form.XFA = makeStream(xdp)
+
+	data, err = form.GetXFADatasets()
+	require.NoError(t, err)
+	require.Equal(t, datasets, string(data))
+
+	require.NoError(t, form.SetXFADatasets([]byte(newDatasets)))
+	packets, err = form.XFAPackets()
+	require.NoError(t, err)
+	require.Len(t, packets, 1)
+	data, err = packets[0].Data()
+	require.NoError(t, err)
+	require.Equal(t, `<xdp:xdp xmlns:xdp="http://ns.adobe.com/xdp/"><template/>`+newDatasets+`</xdp:xdp>`, string(data))
+
+	form.RemoveXFA()
+	require.False(t, form.HasXFA())
+}
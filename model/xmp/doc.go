@@ -0,0 +1,12 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package xmp implements the parsing and serialization of XMP metadata
+// packets, as stored in the Metadata stream of PDF documents (section 14.3.2
+// p. 556 PDF32000_2008). The package provides typed accessors for the
+// properties of the Dublin Core, XMP basic, Adobe PDF and PDF/A
+// identification schemas, the synchronization of these properties with the
+// document information dictionary and the definition of custom properties.
+package xmp
@@ -0,0 +1,100 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package xmp
+
+// ExtensionProperty describes a property of an ExtensionSchema.
+type ExtensionProperty struct {
+	// Name is the local name of the property.
+	Name string
+
+	// ValueType is the type of the values of the property, e.g. "Text".
+	ValueType string
+
+	// Category is "external" for the properties set manually and "internal"
+	// for the properties computed by applications.
+	Category string
+
+	// Description describes the property.
+	Description string
+}
+
+// ExtensionSchema describes a custom schema used in the metadata of PDF/A
+// documents, which must describe the schemas not defined by the XMP
+// specification (pdfaExtension:schemas property).
+type ExtensionSchema struct {
+	// Schema is the description of the schema.
+	Schema string
+
+	// NamespaceURI is the namespace of the schema.
+	NamespaceURI string
+
+	// Prefix is the preferred prefix of the namespace.
+	Prefix string
+
+	// Properties describes the properties of the schema.
+	Properties []ExtensionProperty
+}
+
+// toValue returns the pdfaSchema structure value describing the schema.
+func (s ExtensionSchema) toValue() *Value {
+	props := &Value{Kind: ValueSeq}
+	for _, prop := range s.Properties {
+		props.Items = append(props.Items, NewStruct(
+			NewProperty(NamespacePDFAProperty, "name", NewText(prop.Name)),
+			NewProperty(NamespacePDFAProperty, "valueType", NewText(prop.ValueType)),
+			NewProperty(NamespacePDFAProperty, "category", NewText(prop.Category)),
+			NewProperty(NamespacePDFAProperty, "description", NewText(prop.Description)),
+		))
+	}
+	return NewStruct(
+		NewProperty(NamespacePDFASchema, "schema", NewText(s.Schema)),
+		NewProperty(NamespacePDFASchema, "namespaceURI", NewText(s.NamespaceURI)),
+		NewProperty(NamespacePDFASchema, "prefix", NewText(s.Prefix)),
+		NewProperty(NamespacePDFASchema, "property", props),
+	)
+}
+
+// SetExtensionSchema adds the description of the custom schema `schema` to
+// the PDF/A extension schemas of the document, replacing any description of
+// the same namespace. The prefix of the schema is registered for the
+// namespace.
+func (d *Document) SetExtensionSchema(schema ExtensionSchema) {
+	d.RegisterNamespace(schema.NamespaceURI, schema.Prefix)
+
+	schemas := d.Get(NamespacePDFAExtension, "schemas")
+	if schemas == nil || schemas.Kind != ValueBag {
+		schemas = &Value{Kind: ValueBag}
+	}
+	value := schema.toValue()
+	replaced := false
+	for i, item := range schemas.Items {
+		if uri := item.Field(NamespacePDFASchema, "namespaceURI"); uri != nil && uri.Text == schema.NamespaceURI {
+			schemas.Items[i] = value
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		schemas.Items = append(schemas.Items, value)
+	}
+	d.Set(NamespacePDFAExtension, "schemas", schemas)
+}
+
+// ExtensionSchemas returns the namespaces of the custom schemas described by
+// the PDF/A extension schemas of the document.
+func (d *Document) ExtensionSchemas() []string {
+	schemas := d.Get(NamespacePDFAExtension, "schemas")
+	if schemas == nil {
+		return nil
+	}
+	var namespaces []string
+	for _, item := range schemas.Items {
+		if uri := item.Field(NamespacePDFASchema, "namespaceURI"); uri != nil {
+			namespaces = append(namespaces, uri.Text)
+		}
+	}
+	return namespaces
+}
@@ -0,0 +1,223 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package xmp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+)
+
+// maxNodeDepth is the maximum nesting level of the XML elements of the parsed
+// packets, which limits the resources used for parsing malformed packets.
+const maxNodeDepth = 64
+
+// xmlNode represents an element of the XML tree of an XMP packet.
+type xmlNode struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*xmlNode
+	text     string
+}
+
+// attr returns the value of the attribute with the specified namespace and
+// local name.
+func (n *xmlNode) attr(space, local string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// is returns true if the node is the RDF element with the specified name.
+func (n *xmlNode) is(local string) bool {
+	return n.name.Space == NamespaceRDF && n.name.Local == local
+}
+
+// Parse parses the XMP packet `data`. The packet wrapper (xpacket processing
+// instructions) and the x:xmpmeta element are optional.
+func Parse(data []byte) (*Document, error) {
+	root, prefixes, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rdf := findRDF(root, 0)
+	if rdf == nil {
+		return nil, errors.New("rdf:RDF element not found")
+	}
+
+	doc := NewDocument()
+	for namespace, prefix := range prefixes {
+		if _, ok := defaultPrefixes[namespace]; !ok {
+			doc.prefixes[namespace] = prefix
+		}
+	}
+	for _, desc := range rdf.children {
+		if !desc.is("Description") {
+			common.Log.Debug("Skipping unexpected RDF element %s", desc.name.Local)
+			continue
+		}
+		for _, prop := range parseDescription(desc) {
+			doc.Set(prop.Namespace, prop.Name, prop.Value)
+		}
+	}
+	return doc, nil
+}
+
+// parseXML parses the XML tree of the data. The namespace prefixes declared
+// in the data are also returned, by namespace.
+func parseXML(data []byte) (*xmlNode, map[string]string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+	prefixes := map[string]string{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(stack) > maxNodeDepth {
+				return nil, nil, errors.New("XMP packet nesting too deep")
+			}
+			node := &xmlNode{name: t.Name}
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" {
+					prefixes[a.Value] = a.Name.Local
+					continue
+				}
+				if a.Name.Space == "" && a.Name.Local == "xmlns" {
+					continue
+				}
+				node.attrs = append(node.attrs, a)
+			}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.text += string(t)
+		}
+	}
+	return root, prefixes, nil
+}
+
+// findRDF returns the first rdf:RDF element of the tree `node`.
+func findRDF(node *xmlNode, depth int) *xmlNode {
+	if node.is("RDF") {
+		return node
+	}
+	if depth > maxNodeDepth {
+		return nil
+	}
+	for _, child := range node.children {
+		if rdf := findRDF(child, depth+1); rdf != nil {
+			return rdf
+		}
+	}
+	return nil
+}
+
+// isPropertyAttr returns true if the attribute `a` of a node represents a
+// property, using the RDF attribute shorthand.
+func isPropertyAttr(a xml.Attr) bool {
+	return a.Name.Space != NamespaceRDF && a.Name.Space != NamespaceXML && a.Name.Space != ""
+}
+
+// parseDescription returns the properties of the rdf:Description element
+// `node`, specified either as attributes or as child elements.
+func parseDescription(node *xmlNode) []*Property {
+	var props []*Property
+	for _, a := range node.attrs {
+		if isPropertyAttr(a) {
+			props = append(props, NewProperty(a.Name.Space, a.Name.Local, NewText(a.Value)))
+		}
+	}
+	for _, child := range node.children {
+		props = append(props, NewProperty(child.name.Space, child.name.Local, parseValue(child)))
+	}
+	return props
+}
+
+// parseValue returns the value of the property element `node`.
+func parseValue(node *xmlNode) *Value {
+	var v *Value
+	if resource, ok := node.attr(NamespaceRDF, "resource"); ok {
+		v = &Value{Kind: ValueURI, Text: resource}
+	} else if parseType, _ := node.attr(NamespaceRDF, "parseType"); parseType == "Resource" {
+		v = &Value{Kind: ValueStruct}
+		for _, child := range node.children {
+			v.Fields = append(v.Fields, NewProperty(child.name.Space, child.name.Local, parseValue(child)))
+		}
+	} else if len(node.children) == 1 && node.children[0].is("Description") {
+		v = &Value{Kind: ValueStruct, Fields: parseDescription(node.children[0])}
+	} else if len(node.children) == 1 && isArrayNode(node.children[0]) {
+		array := node.children[0]
+		v = &Value{Kind: arrayKind(array)}
+		for _, item := range array.children {
+			if item.is("li") {
+				v.Items = append(v.Items, parseValue(item))
+			}
+		}
+	} else if hasPropertyAttrs(node) {
+		v = &Value{Kind: ValueStruct, Fields: parseDescription(node)}
+	} else {
+		v = &Value{Kind: ValueText, Text: node.text}
+		if len(node.children) > 0 {
+			common.Log.Debug("Unsupported XMP property value %s:%s", node.name.Space, node.name.Local)
+			v.Text = strings.TrimSpace(node.text)
+		}
+	}
+
+	if lang, ok := node.attr(NamespaceXML, "lang"); ok {
+		v.Lang = lang
+	}
+	return v
+}
+
+// isArrayNode returns true if `node` is an RDF array element.
+func isArrayNode(node *xmlNode) bool {
+	return node.is("Seq") || node.is("Bag") || node.is("Alt")
+}
+
+// arrayKind returns the kind of the RDF array element `node`.
+func arrayKind(node *xmlNode) ValueKind {
+	switch node.name.Local {
+	case "Seq":
+		return ValueSeq
+	case "Bag":
+		return ValueBag
+	}
+	return ValueAlt
+}
+
+// hasPropertyAttrs returns true if the element `node` has attributes
+// representing properties.
+func hasPropertyAttrs(node *xmlNode) bool {
+	for _, a := range node.attrs {
+		if isPropertyAttr(a) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package xmp
+
+import (
+	"strings"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// NewDocumentFromStream parses the XMP packet contained in the metadata
+// stream `stream`.
+func NewDocumentFromStream(stream *core.PdfObjectStream) (*Document, error) {
+	data, err := core.DecodeStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// ReadDocument returns the XMP metadata of the document read by `r`, or nil
+// if the document has no metadata stream.
+func ReadDocument(r *model.PdfReader) (*Document, error) {
+	stream, err := r.GetCatalogMetadata()
+	if err != nil || stream == nil {
+		return nil, err
+	}
+	return NewDocumentFromStream(stream)
+}
+
+// ToStream returns a metadata stream containing the XMP packet of the
+// document, which can be set as the metadata of the output documents using
+// the SetCatalogMetadata method of the PdfWriter. The stream is not
+// compressed, so that the metadata can be read by applications which do not
+// support PDF filters.
+func (d *Document) ToStream() (*core.PdfObjectStream, error) {
+	stream, err := core.MakeStream(d.Bytes(), nil)
+	if err != nil {
+		return nil, err
+	}
+	stream.Set("Type", core.MakeName("Metadata"))
+	stream.Set("Subtype", core.MakeName("XML"))
+	return stream, nil
+}
+
+// WriteDocument sets the XMP metadata of the document written by `w`.
+func WriteDocument(w *model.PdfWriter, d *Document) error {
+	stream, err := d.ToStream()
+	if err != nil {
+		return err
+	}
+	return w.SetCatalogMetadata(stream)
+}
+
+// SyncFromInfo sets the XMP properties equivalent to the entries of the
+// document information dictionary `info` (Table 317 - p. 556), replacing the
+// existing values. The entries missing from `info` are not modified.
+func (d *Document) SyncFromInfo(info *core.PdfObjectDictionary) {
+	if info == nil {
+		return
+	}
+
+	if title, ok := infoString(info, "Title"); ok {
+		d.SetTitle(title)
+	}
+	if author, ok := infoString(info, "Author"); ok {
+		d.SetCreators(author)
+	}
+	if subject, ok := infoString(info, "Subject"); ok {
+		d.SetDescription(subject)
+	}
+	if keywords, ok := infoString(info, "Keywords"); ok {
+		d.SetKeywords(keywords)
+	}
+	if creator, ok := infoString(info, "Creator"); ok {
+		d.SetCreatorTool(creator)
+	}
+	if producer, ok := infoString(info, "Producer"); ok {
+		d.SetProducer(producer)
+	}
+	if date, ok := infoDate(info, "CreationDate"); ok {
+		d.SetCreateDate(date)
+	}
+	if date, ok := infoDate(info, "ModDate"); ok {
+		d.SetModifyDate(date)
+		d.SetMetadataDate(date)
+	}
+	if trapped, ok := core.GetNameVal(info.Get("Trapped")); ok {
+		d.SetTrapped(trapped)
+	}
+}
+
+// SyncToInfo sets the entries of the document information dictionary `info`
+// equivalent to the XMP properties of the document, replacing the existing
+// values. The entries without equivalent XMP property are not modified.
+func (d *Document) SyncToInfo(info *core.PdfObjectDictionary) {
+	if info == nil {
+		return
+	}
+
+	if _, ok := d.GetText(NamespaceDC, "title"); ok {
+		info.Set("Title", makeInfoString(d.Title()))
+	}
+	if creators := d.Creators(); len(creators) > 0 {
+		info.Set("Author", makeInfoString(strings.Join(creators, ", ")))
+	}
+	if _, ok := d.GetText(NamespaceDC, "description"); ok {
+		info.Set("Subject", makeInfoString(d.Description()))
+	}
+	if keywords, ok := d.GetText(NamespacePDF, "Keywords"); ok {
+		info.Set("Keywords", makeInfoString(keywords))
+	}
+	if tool, ok := d.GetText(NamespaceXMP, "CreatorTool"); ok {
+		info.Set("Creator", makeInfoString(tool))
+	}
+	if producer, ok := d.GetText(NamespacePDF, "Producer"); ok {
+		info.Set("Producer", makeInfoString(producer))
+	}
+	if date, ok := d.CreateDate(); ok {
+		setInfoDate(info, "CreationDate", date)
+	}
+	if date, ok := d.ModifyDate(); ok {
+		setInfoDate(info, "ModDate", date)
+	}
+	if trapped, ok := d.GetText(NamespacePDF, "Trapped"); ok {
+		info.Set("Trapped", core.MakeName(trapped))
+	}
+}
+
+// infoString returns the decoded value of the text string entry `key` of the
+// information dictionary.
+func infoString(info *core.PdfObjectDictionary, key core.PdfObjectName) (string, bool) {
+	str, ok := core.GetString(info.Get(key))
+	if !ok {
+		return "", false
+	}
+	return str.Decoded(), true
+}
+
+// infoDate returns the value of the date entry `key` of the information
+// dictionary.
+func infoDate(info *core.PdfObjectDictionary, key core.PdfObjectName) (time.Time, bool) {
+	str, ok := core.GetString(info.Get(key))
+	if !ok {
+		return time.Time{}, false
+	}
+	date, err := model.NewPdfDate(str.Str())
+	if err != nil {
+		common.Log.Debug("Invalid %s date %q: %v", key, str.Str(), err)
+		return time.Time{}, false
+	}
+	return date.ToGoTime(), true
+}
+
+// setInfoDate sets the date entry `key` of the information dictionary.
+func setInfoDate(info *core.PdfObjectDictionary, key core.PdfObjectName, t time.Time) {
+	date, err := model.NewPdfDateFromTime(t)
+	if err != nil {
+		common.Log.Debug("Invalid %s date %v: %v", key, t, err)
+		return
+	}
+	info.Set(key, date.ToPdfObject())
+}
+
+// makeInfoString returns a text string object for the information
+// dictionary, using UTF-16BE encoding for non ASCII text.
+func makeInfoString(s string) *core.PdfObjectString {
+	for _, r := range s {
+		if r > 0x7f {
+			return core.MakeEncodedString(s, true)
+		}
+	}
+	return core.MakeString(s)
+}
@@ -0,0 +1,253 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package xmp
+
+import (
+	"strconv"
+	"time"
+)
+
+// dateLayouts contains the layouts of the valid XMP date values, from the
+// most to the least precise.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006-01",
+	"2006",
+}
+
+// ParseDate parses an XMP date value (ISO 8601 subset). Dates without time
+// zone are interpreted as UTC.
+func ParseDate(s string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// FormatDate returns the XMP representation of the date `t`.
+func FormatDate(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// GetDate returns the value of the date property with the specified namespace
+// and name.
+func (d *Document) GetDate(namespace, name string) (time.Time, bool) {
+	text, ok := d.GetText(namespace, name)
+	if !ok {
+		return time.Time{}, false
+	}
+	return ParseDate(text)
+}
+
+// SetDate sets the value of the date property with the specified namespace
+// and name. The property is removed if `t` is the zero time.
+func (d *Document) SetDate(namespace, name string, t time.Time) {
+	if t.IsZero() {
+		d.Remove(namespace, name)
+		return
+	}
+	d.SetText(namespace, name, FormatDate(t))
+}
+
+// Title returns the default title of the document (dc:title).
+func (d *Document) Title() string {
+	text, _ := d.GetText(NamespaceDC, "title")
+	return text
+}
+
+// SetTitle sets the default title of the document (dc:title).
+func (d *Document) SetTitle(title string) {
+	d.Set(NamespaceDC, "title", NewLangAlt(title))
+}
+
+// Creators returns the authors of the document (dc:creator).
+func (d *Document) Creators() []string {
+	if v := d.Get(NamespaceDC, "creator"); v != nil {
+		return v.Strings()
+	}
+	return nil
+}
+
+// SetCreators sets the authors of the document (dc:creator).
+func (d *Document) SetCreators(creators ...string) {
+	if len(creators) == 0 {
+		d.Remove(NamespaceDC, "creator")
+		return
+	}
+	d.Set(NamespaceDC, "creator", NewSeq(creators...))
+}
+
+// Description returns the default description of the document
+// (dc:description).
+func (d *Document) Description() string {
+	text, _ := d.GetText(NamespaceDC, "description")
+	return text
+}
+
+// SetDescription sets the default description of the document
+// (dc:description).
+func (d *Document) SetDescription(description string) {
+	d.Set(NamespaceDC, "description", NewLangAlt(description))
+}
+
+// Subjects returns the keywords of the document (dc:subject).
+func (d *Document) Subjects() []string {
+	if v := d.Get(NamespaceDC, "subject"); v != nil {
+		return v.Strings()
+	}
+	return nil
+}
+
+// SetSubjects sets the keywords of the document (dc:subject).
+func (d *Document) SetSubjects(subjects ...string) {
+	if len(subjects) == 0 {
+		d.Remove(NamespaceDC, "subject")
+		return
+	}
+	d.Set(NamespaceDC, "subject", NewBag(subjects...))
+}
+
+// Format returns the MIME type of the document (dc:format).
+func (d *Document) Format() string {
+	text, _ := d.GetText(NamespaceDC, "format")
+	return text
+}
+
+// SetFormat sets the MIME type of the document (dc:format).
+func (d *Document) SetFormat(format string) {
+	d.SetText(NamespaceDC, "format", format)
+}
+
+// CreatorTool returns the name of the application which created the
+// document (xmp:CreatorTool).
+func (d *Document) CreatorTool() string {
+	text, _ := d.GetText(NamespaceXMP, "CreatorTool")
+	return text
+}
+
+// SetCreatorTool sets the name of the application which created the
+// document (xmp:CreatorTool).
+func (d *Document) SetCreatorTool(tool string) {
+	d.SetText(NamespaceXMP, "CreatorTool", tool)
+}
+
+// CreateDate returns the creation date of the document (xmp:CreateDate).
+func (d *Document) CreateDate() (time.Time, bool) {
+	return d.GetDate(NamespaceXMP, "CreateDate")
+}
+
+// SetCreateDate sets the creation date of the document (xmp:CreateDate).
+func (d *Document) SetCreateDate(t time.Time) {
+	d.SetDate(NamespaceXMP, "CreateDate", t)
+}
+
+// ModifyDate returns the modification date of the document (xmp:ModifyDate).
+func (d *Document) ModifyDate() (time.Time, bool) {
+	return d.GetDate(NamespaceXMP, "ModifyDate")
+}
+
+// SetModifyDate sets the modification date of the document (xmp:ModifyDate).
+func (d *Document) SetModifyDate(t time.Time) {
+	d.SetDate(NamespaceXMP, "ModifyDate", t)
+}
+
+// MetadataDate returns the modification date of the metadata
+// (xmp:MetadataDate).
+func (d *Document) MetadataDate() (time.Time, bool) {
+	return d.GetDate(NamespaceXMP, "MetadataDate")
+}
+
+// SetMetadataDate sets the modification date of the metadata
+// (xmp:MetadataDate).
+func (d *Document) SetMetadataDate(t time.Time) {
+	d.SetDate(NamespaceXMP, "MetadataDate", t)
+}
+
+// Producer returns the name of the application which produced the PDF
+// document (pdf:Producer).
+func (d *Document) Producer() string {
+	text, _ := d.GetText(NamespacePDF, "Producer")
+	return text
+}
+
+// SetProducer sets the name of the application which produced the PDF
+// document (pdf:Producer).
+func (d *Document) SetProducer(producer string) {
+	d.SetText(NamespacePDF, "Producer", producer)
+}
+
+// Keywords returns the keywords of the PDF document (pdf:Keywords).
+func (d *Document) Keywords() string {
+	text, _ := d.GetText(NamespacePDF, "Keywords")
+	return text
+}
+
+// SetKeywords sets the keywords of the PDF document (pdf:Keywords).
+func (d *Document) SetKeywords(keywords string) {
+	d.SetText(NamespacePDF, "Keywords", keywords)
+}
+
+// PDFVersion returns the PDF version of the document (pdf:PDFVersion).
+func (d *Document) PDFVersion() string {
+	text, _ := d.GetText(NamespacePDF, "PDFVersion")
+	return text
+}
+
+// SetPDFVersion sets the PDF version of the document (pdf:PDFVersion).
+func (d *Document) SetPDFVersion(version string) {
+	d.SetText(NamespacePDF, "PDFVersion", version)
+}
+
+// Trapped returns the trapping state of the document (pdf:Trapped), which
+// is either "True", "False" or "Unknown".
+func (d *Document) Trapped() string {
+	text, _ := d.GetText(NamespacePDF, "Trapped")
+	return text
+}
+
+// SetTrapped sets the trapping state of the document (pdf:Trapped).
+func (d *Document) SetTrapped(trapped string) {
+	d.SetText(NamespacePDF, "Trapped", trapped)
+}
+
+// PDFAIdentification returns the PDF/A part and conformance level of the
+// document (pdfaid:part and pdfaid:conformance). The last return value is
+// false if the document does not claim PDF/A conformance.
+func (d *Document) PDFAIdentification() (int, string, bool) {
+	partText, ok := d.GetText(NamespacePDFAID, "part")
+	if !ok {
+		return 0, "", false
+	}
+	part, err := strconv.Atoi(partText)
+	if err != nil {
+		return 0, "", false
+	}
+	conformance, _ := d.GetText(NamespacePDFAID, "conformance")
+	return part, conformance, true
+}
+
+// SetPDFAIdentification sets the PDF/A part and conformance level of the
+// document (e.g. 2 and "B" for PDF/A-2b). The identification is removed if
+// `part` is 0.
+func (d *Document) SetPDFAIdentification(part int, conformance string) {
+	if part == 0 {
+		d.Remove(NamespacePDFAID, "part")
+		d.Remove(NamespacePDFAID, "conformance")
+		return
+	}
+	d.SetText(NamespacePDFAID, "part", strconv.Itoa(part))
+	if conformance != "" {
+		d.SetText(NamespacePDFAID, "conformance", conformance)
+	} else {
+		d.Remove(NamespacePDFAID, "conformance")
+	}
+}
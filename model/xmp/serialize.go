@@ -0,0 +1,147 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package xmp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"sort"
+	"strings"
+)
+
+// Packet wrapper of the serialized XMP packets.
+const (
+	packetHeader = "<?xpacket begin=\"\xef\xbb\xbf\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n"
+	packetFooter = "<?xpacket end=\"w\"?>"
+
+	// paddingSize is the size of the whitespace padding added at the end of
+	// the serialized packets, allowing in-place modifications of the packet.
+	paddingSize = 2048
+)
+
+// Bytes returns the serialized XMP packet of the document, including the
+// packet wrapper and padding. The properties are grouped by namespace into
+// rdf:Description elements.
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(packetHeader)
+	buf.WriteString("<x:xmpmeta xmlns:x=\"" + namespaceMeta + "\">\n")
+	buf.WriteString(" <rdf:RDF xmlns:rdf=\"" + NamespaceRDF + "\">\n")
+
+	// Group properties by namespace, in order of first occurrence.
+	var namespaces []string
+	groups := map[string][]*Property{}
+	for _, prop := range d.properties {
+		if _, ok := groups[prop.Namespace]; !ok {
+			namespaces = append(namespaces, prop.Namespace)
+		}
+		groups[prop.Namespace] = append(groups[prop.Namespace], prop)
+	}
+
+	for _, namespace := range namespaces {
+		props := groups[namespace]
+
+		// Collect the namespaces used by the properties of the group.
+		used := map[string]struct{}{}
+		for _, prop := range props {
+			collectNamespaces(prop, used)
+		}
+		var decls []string
+		for ns := range used {
+			if ns == NamespaceRDF || ns == NamespaceXML {
+				continue
+			}
+			decls = append(decls, " xmlns:"+d.Prefix(ns)+"=\""+escape(ns)+"\"")
+		}
+		sort.Strings(decls)
+
+		buf.WriteString("  <rdf:Description rdf:about=\"\"" + strings.Join(decls, "") + ">\n")
+		for _, prop := range props {
+			d.writeElement(&buf, d.qualifiedName(prop), prop.Value, 3)
+		}
+		buf.WriteString("  </rdf:Description>\n")
+	}
+
+	buf.WriteString(" </rdf:RDF>\n")
+	buf.WriteString("</x:xmpmeta>\n")
+
+	padding := strings.Repeat(" ", 99) + "\n"
+	for i := 0; i < paddingSize/len(padding); i++ {
+		buf.WriteString(padding)
+	}
+	buf.WriteString(packetFooter)
+	return buf.Bytes()
+}
+
+// collectNamespaces adds the namespaces used by the property `prop` and its
+// fields to `used`.
+func collectNamespaces(prop *Property, used map[string]struct{}) {
+	used[prop.Namespace] = struct{}{}
+	var collect func(v *Value)
+	collect = func(v *Value) {
+		if v == nil {
+			return
+		}
+		for _, item := range v.Items {
+			collect(item)
+		}
+		for _, field := range v.Fields {
+			collectNamespaces(field, used)
+		}
+	}
+	collect(prop.Value)
+}
+
+// qualifiedName returns the name of the property with its namespace prefix.
+func (d *Document) qualifiedName(prop *Property) string {
+	return d.Prefix(prop.Namespace) + ":" + prop.Name
+}
+
+// writeElement writes the element `name` with the value `v` to `buf`.
+func (d *Document) writeElement(buf *bytes.Buffer, name string, v *Value, depth int) {
+	if v == nil {
+		return
+	}
+	indent := strings.Repeat(" ", depth)
+	buf.WriteString(indent + "<" + name)
+	if v.Lang != "" {
+		buf.WriteString(" xml:lang=\"" + escape(v.Lang) + "\"")
+	}
+
+	switch v.Kind {
+	case ValueURI:
+		buf.WriteString(" rdf:resource=\"" + escape(v.Text) + "\"/>\n")
+	case ValueSeq, ValueBag, ValueAlt:
+		arrayName := "rdf:Alt"
+		switch v.Kind {
+		case ValueSeq:
+			arrayName = "rdf:Seq"
+		case ValueBag:
+			arrayName = "rdf:Bag"
+		}
+
+		buf.WriteString(">\n" + indent + " <" + arrayName + ">\n")
+		for _, item := range v.Items {
+			d.writeElement(buf, "rdf:li", item, depth+2)
+		}
+		buf.WriteString(indent + " </" + arrayName + ">\n" + indent + "</" + name + ">\n")
+	case ValueStruct:
+		buf.WriteString(" rdf:parseType=\"Resource\">\n")
+		for _, field := range v.Fields {
+			d.writeElement(buf, d.qualifiedName(field), field.Value, depth+1)
+		}
+		buf.WriteString(indent + "</" + name + ">\n")
+	default:
+		buf.WriteString(">" + escape(v.Text) + "</" + name + ">\n")
+	}
+}
+
+// escape returns the XML escaped representation of `s`.
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,333 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package xmp
+
+import (
+	"fmt"
+)
+
+// Namespace URIs of the common XMP schemas.
+const (
+	NamespaceRDF       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	NamespaceXML       = "http://www.w3.org/XML/1998/namespace"
+	NamespaceDC        = "http://purl.org/dc/elements/1.1/"
+	NamespaceXMP       = "http://ns.adobe.com/xap/1.0/"
+	NamespaceXMPMM     = "http://ns.adobe.com/xap/1.0/mm/"
+	NamespaceXMPRights = "http://ns.adobe.com/xap/1.0/rights/"
+	NamespacePDF       = "http://ns.adobe.com/pdf/1.3/"
+	NamespacePDFAID    = "http://www.aiim.org/pdfa/ns/id/"
+	NamespacePDFUAID   = "http://www.aiim.org/pdfua/ns/id/"
+
+	NamespacePDFAExtension = "http://www.aiim.org/pdfa/ns/extension/"
+	NamespacePDFASchema    = "http://www.aiim.org/pdfa/ns/schema#"
+	NamespacePDFAProperty  = "http://www.aiim.org/pdfa/ns/property#"
+
+	namespaceMeta = "adobe:ns:meta/"
+)
+
+// defaultPrefixes contains the conventional prefixes of the common schemas.
+var defaultPrefixes = map[string]string{
+	NamespaceRDF:       "rdf",
+	NamespaceXML:       "xml",
+	NamespaceDC:        "dc",
+	NamespaceXMP:       "xmp",
+	NamespaceXMPMM:     "xmpMM",
+	NamespaceXMPRights: "xmpRights",
+	NamespacePDF:       "pdf",
+	NamespacePDFAID:    "pdfaid",
+	NamespacePDFUAID:   "pdfuaid",
+
+	NamespacePDFAExtension: "pdfaExtension",
+	NamespacePDFASchema:    "pdfaSchema",
+	NamespacePDFAProperty:  "pdfaProperty",
+}
+
+// ValueKind represents the kind of an XMP property value.
+type ValueKind int
+
+// XMP property value kinds.
+const (
+	// ValueText is a simple text value.
+	ValueText ValueKind = iota
+
+	// ValueURI is a URI value (rdf:resource).
+	ValueURI
+
+	// ValueSeq is an ordered array (rdf:Seq).
+	ValueSeq
+
+	// ValueBag is an unordered array (rdf:Bag).
+	ValueBag
+
+	// ValueAlt is an array of alternatives (rdf:Alt), generally language
+	// alternatives identified by their xml:lang qualifier.
+	ValueAlt
+
+	// ValueStruct is a structure containing fields.
+	ValueStruct
+)
+
+// Value represents the value of an XMP property.
+type Value struct {
+	// Kind is the kind of the value.
+	Kind ValueKind
+
+	// Text contains the text of text and URI values.
+	Text string
+
+	// Lang is the xml:lang qualifier of the value. Optional.
+	Lang string
+
+	// Items contains the items of array values.
+	Items []*Value
+
+	// Fields contains the fields of structure values.
+	Fields []*Property
+}
+
+// NewText returns a new simple text value.
+func NewText(text string) *Value {
+	return &Value{Kind: ValueText, Text: text}
+}
+
+// NewSeq returns a new ordered array of text values.
+func NewSeq(items ...string) *Value {
+	return newArray(ValueSeq, items)
+}
+
+// NewBag returns a new unordered array of text values.
+func NewBag(items ...string) *Value {
+	return newArray(ValueBag, items)
+}
+
+// NewLangAlt returns a new language alternative array containing the
+// specified text as default (x-default) value.
+func NewLangAlt(text string) *Value {
+	return &Value{
+		Kind:  ValueAlt,
+		Items: []*Value{{Kind: ValueText, Text: text, Lang: "x-default"}},
+	}
+}
+
+// NewStruct returns a new structure value with the specified fields.
+func NewStruct(fields ...*Property) *Value {
+	return &Value{Kind: ValueStruct, Fields: fields}
+}
+
+func newArray(kind ValueKind, items []string) *Value {
+	v := &Value{Kind: kind}
+	for _, item := range items {
+		v.Items = append(v.Items, NewText(item))
+	}
+	return v
+}
+
+// IsArray returns true if the value is an array.
+func (v *Value) IsArray() bool {
+	return v.Kind == ValueSeq || v.Kind == ValueBag || v.Kind == ValueAlt
+}
+
+// String returns the text of the value. For language alternatives, the text
+// of the default alternative is returned. For other arrays, the text of the
+// first item is returned.
+func (v *Value) String() string {
+	switch v.Kind {
+	case ValueText, ValueURI:
+		return v.Text
+	case ValueAlt:
+		return v.LangText("x-default")
+	case ValueSeq, ValueBag:
+		if len(v.Items) > 0 {
+			return v.Items[0].String()
+		}
+	}
+	return ""
+}
+
+// LangText returns the text of the language alternative `lang`. If there is
+// no alternative for the language, the default alternative (x-default) is
+// returned, or the first alternative if there is no default one.
+func (v *Value) LangText(lang string) string {
+	if !v.IsArray() {
+		return v.Text
+	}
+	if len(v.Items) == 0 {
+		return ""
+	}
+
+	var def *Value
+	for _, item := range v.Items {
+		if item.Lang == lang {
+			return item.Text
+		}
+		if item.Lang == "x-default" && def == nil {
+			def = item
+		}
+	}
+	if def == nil {
+		def = v.Items[0]
+	}
+	return def.Text
+}
+
+// Strings returns the text of the items of array values. For simple values,
+// the text of the value is returned as single item.
+func (v *Value) Strings() []string {
+	if !v.IsArray() {
+		if v.Kind == ValueStruct {
+			return nil
+		}
+		return []string{v.Text}
+	}
+
+	var items []string
+	for _, item := range v.Items {
+		if item.Kind == ValueText || item.Kind == ValueURI {
+			items = append(items, item.Text)
+		}
+	}
+	return items
+}
+
+// Field returns the value of the structure field with the specified
+// namespace and name, or nil if not found.
+func (v *Value) Field(namespace, name string) *Value {
+	for _, field := range v.Fields {
+		if field.Namespace == namespace && field.Name == name {
+			return field.Value
+		}
+	}
+	return nil
+}
+
+// Property represents an XMP property.
+type Property struct {
+	// Namespace is the URI of the schema namespace of the property.
+	Namespace string
+
+	// Name is the local name of the property.
+	Name string
+
+	// Value is the value of the property.
+	Value *Value
+}
+
+// NewProperty returns a new property with the specified namespace, name and
+// value.
+func NewProperty(namespace, name string, value *Value) *Property {
+	return &Property{Namespace: namespace, Name: name, Value: value}
+}
+
+// Document represents an XMP metadata packet.
+type Document struct {
+	properties []*Property
+	prefixes   map[string]string // Namespace prefixes, by namespace.
+}
+
+// NewDocument returns a new empty XMP document.
+func NewDocument() *Document {
+	return &Document{prefixes: map[string]string{}}
+}
+
+// RegisterNamespace sets the prefix used for serializing the properties of
+// the specified namespace. The conventional prefixes are used for the common
+// schemas, unless registered otherwise.
+func (d *Document) RegisterNamespace(namespace, prefix string) {
+	d.prefixes[namespace] = prefix
+}
+
+// Prefix returns the prefix used for serializing the properties of the
+// specified namespace. If no prefix was registered for the namespace, a
+// new prefix is generated.
+func (d *Document) Prefix(namespace string) string {
+	if prefix, ok := d.prefixes[namespace]; ok {
+		return prefix
+	}
+	if prefix, ok := defaultPrefixes[namespace]; ok {
+		return prefix
+	}
+
+	used := map[string]struct{}{}
+	for _, prefix := range defaultPrefixes {
+		used[prefix] = struct{}{}
+	}
+	for _, prefix := range d.prefixes {
+		used[prefix] = struct{}{}
+	}
+	for i := 1; ; i++ {
+		prefix := fmt.Sprintf("ns%d", i)
+		if _, ok := used[prefix]; !ok {
+			d.prefixes[namespace] = prefix
+			return prefix
+		}
+	}
+}
+
+// Properties returns the top level properties of the document.
+func (d *Document) Properties() []*Property {
+	return d.properties
+}
+
+// Get returns the value of the property with the specified namespace and
+// name, or nil if the property is not set.
+func (d *Document) Get(namespace, name string) *Value {
+	for _, prop := range d.properties {
+		if prop.Namespace == namespace && prop.Name == name {
+			return prop.Value
+		}
+	}
+	return nil
+}
+
+// Set sets the value of the property with the specified namespace and name.
+// The property is removed if `value` is nil.
+func (d *Document) Set(namespace, name string, value *Value) {
+	if value == nil {
+		d.Remove(namespace, name)
+		return
+	}
+	for _, prop := range d.properties {
+		if prop.Namespace == namespace && prop.Name == name {
+			prop.Value = value
+			return
+		}
+	}
+	d.properties = append(d.properties, NewProperty(namespace, name, value))
+}
+
+// Remove removes the property with the specified namespace and name.
+func (d *Document) Remove(namespace, name string) {
+	for i, prop := range d.properties {
+		if prop.Namespace == namespace && prop.Name == name {
+			d.properties = append(d.properties[:i], d.properties[i+1:]...)
+			return
+		}
+	}
+}
+
+// GetText returns the text of the property with the specified namespace and
+// name. For language alternatives, the text of the default alternative is
+// returned. The second return value is false if the property is not set.
+func (d *Document) GetText(namespace, name string) (string, bool) {
+	v := d.Get(namespace, name)
+	if v == nil {
+		return "", false
+	}
+	return v.String(), true
+}
+
+// SetText sets the property with the specified namespace and name to a simple
+// text value.
+func (d *Document) SetText(namespace, name, text string) {
+	d.Set(namespace, name, NewText(text))
+}
+
+// SetCustomProperty sets the value of a custom property of the namespace
+// `namespace`, serialized using the prefix `prefix`.
+func (d *Document) SetCustomProperty(namespace, prefix, name string, value *Value) {
+	d.RegisterNamespace(namespace, prefix)
+	d.Set(namespace, name, value)
+}
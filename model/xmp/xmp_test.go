@@ -0,0 +1,242 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package xmp
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+const testPacket = `<?xpacket begin="` + "\xef\xbb\xbf" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmp:CreatorTool="Writer" xmp:CreateDate="2020-03-04T10:20:30+02:00"/>
+  <rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/">
+   <dc:title>
+    <rdf:Alt>
+     <rdf:li xml:lang="x-default">Report &amp; Summary</rdf:li>
+     <rdf:li xml:lang="fr">Rapport</rdf:li>
+    </rdf:Alt>
+   </dc:title>
+   <dc:creator><rdf:Seq><rdf:li>Alice</rdf:li><rdf:li>Bob</rdf:li></rdf:Seq></dc:creator>
+   <dc:subject><rdf:Bag><rdf:li>pdf</rdf:li><rdf:li>xmp</rdf:li></rdf:Bag></dc:subject>
+  </rdf:Description>
+  <rdf:Description rdf:about="" xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/">
+   <pdfaid:part>2</pdfaid:part>
+   <pdfaid:conformance>B</pdfaid:conformance>
+  </rdf:Description>
+  <rdf:Description rdf:about="" xmlns:my="http://example.com/ns/"
+    xmlns:stRef="http://ns.adobe.com/xap/1.0/sType/ResourceRef#">
+   <my:link rdf:resource="http://example.com/"/>
+   <my:ref rdf:parseType="Resource">
+    <stRef:documentID>doc1</stRef:documentID>
+    <stRef:instanceID>inst1</stRef:instanceID>
+   </my:ref>
+   <my:short stRef:documentID="doc2"/>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`
+
+const nsMy = "http://example.com/ns/"
+const nsRef = "http://ns.adobe.com/xap/1.0/sType/ResourceRef#"
+
+func checkTestDocument(t *testing.T, doc *Document) {
+	require.Equal(t, "Report & Summary", doc.Title())
+	require.Equal(t, "Rapport", doc.Get(NamespaceDC, "title").LangText("fr"))
+	require.Equal(t, "Report & Summary", doc.Get(NamespaceDC, "title").LangText("de"))
+	require.Equal(t, []string{"Alice", "Bob"}, doc.Creators())
+	require.Equal(t, []string{"pdf", "xmp"}, doc.Subjects())
+	require.Equal(t, ValueBag, doc.Get(NamespaceDC, "subject").Kind)
+	require.Equal(t, "Writer", doc.CreatorTool())
+
+	created, ok := doc.CreateDate()
+	require.True(t, ok)
+	require.True(t, created.Equal(time.Date(2020, 3, 4, 8, 20, 30, 0, time.UTC)))
+
+	part, conformance, ok := doc.PDFAIdentification()
+	require.True(t, ok)
+	require.Equal(t, 2, part)
+	require.Equal(t, "B", conformance)
+
+	link := doc.Get(nsMy, "link")
+	require.Equal(t, ValueURI, link.Kind)
+	require.Equal(t, "http://example.com/", link.Text)
+
+	ref := doc.Get(nsMy, "ref")
+	require.Equal(t, ValueStruct, ref.Kind)
+	require.Equal(t, "doc1", ref.Field(nsRef, "documentID").Text)
+	require.Equal(t, "inst1", ref.Field(nsRef, "instanceID").Text)
+
+	short := doc.Get(nsMy, "short")
+	require.Equal(t, ValueStruct, short.Kind)
+	require.Equal(t, "doc2", short.Field(nsRef, "documentID").Text)
+}
+
+func TestParseSerialize(t *testing.T) {
+	doc, err := Parse([]byte(testPacket))
+	require.NoError(t, err)
+	checkTestDocument(t, doc)
+	require.Equal(t, "my", doc.Prefix(nsMy))
+
+	// Round trip.
+	data := doc.Bytes()
+	require.True(t, bytes.HasPrefix(data, []byte("<?xpacket begin=")))
+	require.True(t, bytes.HasSuffix(data, []byte(`<?xpacket end="w"?>`)))
+	require.Contains(t, string(data), `xmlns:my="http://example.com/ns/"`)
+
+	doc, err = Parse(data)
+	require.NoError(t, err)
+	checkTestDocument(t, doc)
+
+	_, err = Parse([]byte("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\"/>"))
+	require.Error(t, err)
+}
+
+func TestCustomProperties(t *testing.T) {
+	doc := NewDocument()
+	doc.SetCustomProperty("http://example.com/invoice/", "inv", "Number", NewText("42"))
+	doc.SetCustomProperty("http://example.com/invoice/", "inv", "Items", NewSeq("a", "b"))
+	doc.Set("http://example.com/other/", "Flag", NewText("true"))
+	doc.SetPDFAIdentification(3, "U")
+
+	data := doc.Bytes()
+	require.Contains(t, string(data), "<inv:Number>42</inv:Number>")
+	require.Contains(t, string(data), `xmlns:ns1="http://example.com/other/"`)
+
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	number, ok := parsed.GetText("http://example.com/invoice/", "Number")
+	require.True(t, ok)
+	require.Equal(t, "42", number)
+	require.Equal(t, []string{"a", "b"}, parsed.Get("http://example.com/invoice/", "Items").Strings())
+	part, conformance, ok := parsed.PDFAIdentification()
+	require.True(t, ok)
+	require.Equal(t, 3, part)
+	require.Equal(t, "U", conformance)
+
+	parsed.Remove("http://example.com/invoice/", "Number")
+	require.Nil(t, parsed.Get("http://example.com/invoice/", "Number"))
+	parsed.SetPDFAIdentification(0, "")
+	_, _, ok = parsed.PDFAIdentification()
+	require.False(t, ok)
+}
+
+func TestExtensionSchema(t *testing.T) {
+	schema := ExtensionSchema{
+		Schema:       "Invoice schema",
+		NamespaceURI: "http://example.com/invoice/",
+		Prefix:       "inv",
+		Properties: []ExtensionProperty{
+			{Name: "Number", ValueType: "Text", Category: "external", Description: "Invoice number"},
+		},
+	}
+	doc := NewDocument()
+	doc.SetExtensionSchema(schema)
+	schema.Schema = "Invoice schema v2"
+	doc.SetExtensionSchema(schema)
+	doc.SetExtensionSchema(ExtensionSchema{Schema: "Other", NamespaceURI: "http://example.com/other/", Prefix: "oth"})
+	doc.SetCustomProperty("http://example.com/invoice/", "inv", "Number", NewText("42"))
+
+	data := doc.Bytes()
+	require.Contains(t, string(data), "<pdfaSchema:prefix>inv</pdfaSchema:prefix>")
+	require.Contains(t, string(data), "<pdfaProperty:name>Number</pdfaProperty:name>")
+
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://example.com/invoice/", "http://example.com/other/"}, parsed.ExtensionSchemas())
+	schemas := parsed.Get(NamespacePDFAExtension, "schemas")
+	require.NotNil(t, schemas)
+	require.Equal(t, "Invoice schema v2", schemas.Items[0].Field(NamespacePDFASchema, "schema").Text)
+	props := schemas.Items[0].Field(NamespacePDFASchema, "property")
+	require.Len(t, props.Items, 1)
+	require.Equal(t, "Number", props.Items[0].Field(NamespacePDFAProperty, "name").Text)
+}
+
+func TestInfoSync(t *testing.T) {
+	modDate, err := model.NewPdfDateFromTime(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC))
+	require.NoError(t, err)
+
+	info := core.MakeDict()
+	info.Set("Title", core.MakeEncodedString("Résumé", true))
+	info.Set("Author", core.MakeString("Alice"))
+	info.Set("Subject", core.MakeString("Subject"))
+	info.Set("Keywords", core.MakeString("a, b"))
+	info.Set("Creator", core.MakeString("Editor"))
+	info.Set("Producer", core.MakeString("unipdf"))
+	info.Set("ModDate", modDate.ToPdfObject())
+	info.Set("Trapped", core.MakeName("False"))
+
+	doc := NewDocument()
+	doc.SyncFromInfo(info)
+	require.Equal(t, "Résumé", doc.Title())
+	require.Equal(t, []string{"Alice"}, doc.Creators())
+	require.Equal(t, "Subject", doc.Description())
+	require.Equal(t, "a, b", doc.Keywords())
+	require.Equal(t, "Editor", doc.CreatorTool())
+	require.Equal(t, "unipdf", doc.Producer())
+	require.Equal(t, "False", doc.Trapped())
+	modified, ok := doc.ModifyDate()
+	require.True(t, ok)
+	require.True(t, modified.Equal(modDate.ToGoTime()))
+
+	// Sync back to a new dictionary.
+	doc.SetCreators("Alice", "Bob")
+	out := core.MakeDict()
+	doc.SyncToInfo(out)
+	title, ok := core.GetString(out.Get("Title"))
+	require.True(t, ok)
+	require.Equal(t, "Résumé", title.Decoded())
+	author, ok := core.GetStringVal(out.Get("Author"))
+	require.True(t, ok)
+	require.Equal(t, "Alice, Bob", author)
+	trapped, ok := core.GetNameVal(out.Get("Trapped"))
+	require.True(t, ok)
+	require.Equal(t, "False", trapped)
+	date, ok := core.GetString(out.Get("ModDate"))
+	require.True(t, ok)
+	pdfDate, err := model.NewPdfDate(date.Str())
+	require.NoError(t, err)
+	require.True(t, pdfDate.ToGoTime().Equal(modDate.ToGoTime()))
+}
+
+func TestReadWriteDocument(t *testing.T) {
+	doc := NewDocument()
+	doc.SetTitle("Metadata")
+	doc.SetPDFAIdentification(2, "B")
+
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	w := model.NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	require.NoError(t, WriteDocument(&w, doc))
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	stream, err := r.GetCatalogMetadata()
+	require.NoError(t, err)
+	require.NotNil(t, stream)
+	subtype, _ := core.GetNameVal(stream.Get("Subtype"))
+	require.Equal(t, "XML", subtype)
+	require.Nil(t, stream.Get("Filter"))
+
+	readDoc, err := ReadDocument(r)
+	require.NoError(t, err)
+	require.Equal(t, "Metadata", readDoc.Title())
+	part, _, ok := readDoc.PDFAIdentification()
+	require.True(t, ok)
+	require.Equal(t, 2, part)
+}
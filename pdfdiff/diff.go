@@ -0,0 +1,232 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfdiff
+
+import (
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// ChangeType represents the type of a difference between two documents.
+type ChangeType int
+
+// Change types.
+const (
+	// ChangeAdded is used for objects and pages only present in the new
+	// document.
+	ChangeAdded ChangeType = iota
+
+	// ChangeRemoved is used for objects and pages only present in the old
+	// document.
+	ChangeRemoved
+
+	// ChangeModified is used for objects and pages present in both
+	// documents with different values.
+	ChangeModified
+
+	// ChangeMoved is used for text runs present in both documents at
+	// different locations of the text.
+	ChangeMoved
+)
+
+// String returns a string representation of the change type.
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	case ChangeMoved:
+		return "moved"
+	}
+	return fmt.Sprintf("ChangeType(%d)", int(t))
+}
+
+// ObjectDiff represents a difference between the object graphs of two
+// documents.
+type ObjectDiff struct {
+	// Path is the path of the object from the object the comparison started
+	// from, made of dictionary keys and array indexes, e.g.
+	// "/Resources/Font/F1/BaseFont" or "/Annots[0]/Rect".
+	Path string
+
+	// Type is the type of the difference.
+	Type ChangeType
+
+	// Old is the object of the old document. Nil if the object was added.
+	Old core.PdfObject
+
+	// New is the object of the new document. Nil if the object was removed.
+	New core.PdfObject
+}
+
+// String returns a string describing the difference.
+func (d ObjectDiff) String() string {
+	switch d.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("added %s: %s", d.Path, describeObject(d.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("removed %s: %s", d.Path, describeObject(d.Old))
+	}
+	return fmt.Sprintf("modified %s: %s -> %s", d.Path, describeObject(d.Old), describeObject(d.New))
+}
+
+// PageDiff represents the differences between the pages with the same page
+// number of two documents.
+type PageDiff struct {
+	// PageNumber is the 1-based page number of the pages.
+	PageNumber int
+
+	// Type is ChangeAdded or ChangeRemoved if the page is only present in
+	// the new or old document, ChangeModified otherwise.
+	Type ChangeType
+
+	// Objects contains the differences between the page dictionaries and
+	// the objects they reference, the paths starting at the page
+	// dictionaries. The page content streams are compared separately.
+	Objects []ObjectDiff
+
+	// ContentChanged is set if the decoded page content streams differ.
+	ContentChanged bool
+
+	// DiffPixels is the number of differing pixels of the rendered pages.
+	// Only set by visual comparisons.
+	DiffPixels int
+
+	// DiffBoxes contains the bounding boxes of the differing areas of the
+	// rendered pages, in pixels from the top left corner of the rendered
+	// images. Only set by visual comparisons.
+	DiffBoxes []image.Rectangle
+}
+
+// Result contains the differences between two documents.
+type Result struct {
+	// Objects contains the document level differences, i.e. the differences
+	// between the catalogs and the document information dictionaries,
+	// with paths starting with "/Root" and "/Info". The page tree is compared
+	// page by page.
+	Objects []ObjectDiff
+
+	// Pages contains the differences between the pages of the documents.
+	// Only the differing pages are included.
+	Pages []PageDiff
+}
+
+// Equal returns true if no differences were found.
+func (r *Result) Equal() bool {
+	return len(r.Objects) == 0 && len(r.Pages) == 0
+}
+
+// Options contains the options used for comparing documents.
+type Options struct {
+	// IgnoreKeys contains dictionary keys whose values are not compared,
+	// e.g. "ModDate" or "ID".
+	IgnoreKeys []string
+
+	// Visual enables the comparison of the rendered pages.
+	Visual bool
+
+	// DPI is the resolution used for rendering the pages compared visually.
+	// Defaults to 72.
+	DPI float64
+
+	// Tolerance is the largest difference between the color components of
+	// two pixels considered equal, from 0 (exact comparison) to 255.
+	Tolerance uint8
+}
+
+// Compare compares the documents read by `oldReader` and `newReader`. The
+// structural differences are always reported. The pages are also compared
+// visually if enabled by the options.
+func Compare(oldReader, newReader *model.PdfReader, opts *Options) (*Result, error) {
+	if oldReader == nil || newReader == nil {
+		return nil, errors.New("readers cannot be nil")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	oldPages, err := readerPages(oldReader)
+	if err != nil {
+		return nil, err
+	}
+	newPages, err := readerPages(newReader)
+	if err != nil {
+		return nil, err
+	}
+	c := newComparer(oldPages, newPages, opts.IgnoreKeys)
+
+	// Compare document level objects.
+	oldTrailer, err := oldReader.GetTrailer()
+	if err != nil {
+		return nil, err
+	}
+	newTrailer, err := newReader.GetTrailer()
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{}
+	c.compareCatalogs(oldTrailer.Get("Root"), newTrailer.Get("Root"))
+	c.compare("/Info", oldTrailer.Get("Info"), newTrailer.Get("Info"))
+	res.Objects = c.flush()
+
+	// Compare pages.
+	var visual *visualComparer
+	if opts.Visual {
+		visual = newVisualComparer(opts.DPI, opts.Tolerance)
+	}
+	numPages := len(oldPages)
+	if len(newPages) > numPages {
+		numPages = len(newPages)
+	}
+	for i := 0; i < numPages; i++ {
+		pageDiff := PageDiff{PageNumber: i + 1, Type: ChangeModified}
+		switch {
+		case i >= len(newPages):
+			pageDiff.Type = ChangeRemoved
+		case i >= len(oldPages):
+			pageDiff.Type = ChangeAdded
+		default:
+			oldPage, newPage := oldPages[i], newPages[i]
+			if err := c.comparePages(&pageDiff, oldPage, newPage); err != nil {
+				return nil, err
+			}
+			if visual != nil {
+				if err := visual.comparePages(&pageDiff, oldPage, newPage); err != nil {
+					return nil, err
+				}
+			}
+			if len(pageDiff.Objects) == 0 && !pageDiff.ContentChanged && pageDiff.DiffPixels == 0 {
+				continue
+			}
+		}
+		res.Pages = append(res.Pages, pageDiff)
+	}
+
+	return res, nil
+}
+
+// readerPages returns the pages of the document read by `r`.
+func readerPages(r *model.PdfReader) ([]*model.PdfPage, error) {
+	numPages, err := r.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]*model.PdfPage, numPages)
+	for i := range pages {
+		if pages[i], err = r.GetPage(i + 1); err != nil {
+			return nil, err
+		}
+	}
+	return pages, nil
+}
@@ -0,0 +1,219 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfdiff
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// makeReader returns a reader for a document containing a 200x100 page per
+// content stream of `contents`.
+func makeReader(t *testing.T, contents ...string) *model.PdfReader {
+	w := model.NewPdfWriter()
+	for _, content := range contents {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Urx: 200, Ury: 100}
+		stream, err := core.MakeStream([]byte(content), core.NewFlateEncoder())
+		require.NoError(t, err)
+		page.Contents = stream
+		require.NoError(t, w.AddPage(page))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	return r
+}
+
+func TestCompareEqual(t *testing.T) {
+	content := "1 0 0 rg 0 0 50 50 re f"
+	res, err := Compare(makeReader(t, content), makeReader(t, content), &Options{
+		IgnoreKeys: []string{"CreationDate", "ModDate"},
+		Visual:     true,
+	})
+	require.NoError(t, err)
+	require.True(t, res.Equal(), "%v", res)
+}
+
+func TestCompareStructure(t *testing.T) {
+	oldReader := makeReader(t, "0 0 50 50 re f", "0 0 50 50 re f")
+	newReader := makeReader(t, "0 0 50 50 re f", "0 0 60 50 re f", "")
+
+	newPage, err := newReader.GetPage(1)
+	require.NoError(t, err)
+	newPage.MediaBox = &model.PdfRectangle{Urx: 300, Ury: 100}
+	newPage.GetPageDict()
+
+	res, err := Compare(oldReader, newReader, &Options{IgnoreKeys: []string{"CreationDate", "ModDate"}})
+	require.NoError(t, err)
+	require.Empty(t, res.Objects)
+	require.Len(t, res.Pages, 3)
+
+	// The media box of the first page was changed.
+	require.Equal(t, 1, res.Pages[0].PageNumber)
+	require.Equal(t, ChangeModified, res.Pages[0].Type)
+	require.False(t, res.Pages[0].ContentChanged)
+	require.Len(t, res.Pages[0].Objects, 1)
+	require.Equal(t, "/MediaBox[2]", res.Pages[0].Objects[0].Path)
+	require.Equal(t, "modified /MediaBox[2]: 200 -> 300", res.Pages[0].Objects[0].String())
+
+	// The content of the second page was changed.
+	require.Equal(t, 2, res.Pages[1].PageNumber)
+	require.True(t, res.Pages[1].ContentChanged)
+	require.Empty(t, res.Pages[1].Objects)
+
+	// The third page was added.
+	require.Equal(t, 3, res.Pages[2].PageNumber)
+	require.Equal(t, ChangeAdded, res.Pages[2].Type)
+}
+
+func TestCompareVisual(t *testing.T) {
+	oldReader := makeReader(t, "0 0 50 50 re f")
+	newReader := makeReader(t, "0 0 50 50 re f 100 0 20 10 re f")
+
+	res, err := Compare(oldReader, newReader, &Options{
+		IgnoreKeys: []string{"CreationDate", "ModDate"},
+		Visual:     true,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Pages, 1)
+	require.True(t, res.Pages[0].ContentChanged)
+	require.Equal(t, 200, res.Pages[0].DiffPixels)
+	require.Equal(t, []image.Rectangle{image.Rect(100, 90, 120, 100)}, res.Pages[0].DiffBoxes)
+}
+
+func TestCompareImages(t *testing.T) {
+	a := image.NewGray(image.Rect(0, 0, 100, 50))
+	b := image.NewGray(image.Rect(0, 0, 100, 50))
+	b.SetGray(10, 10, color.Gray{Y: 200})
+	b.SetGray(11, 12, color.Gray{Y: 200})
+	b.SetGray(90, 40, color.Gray{Y: 5})
+
+	n, boxes := CompareImages(a, b, 0)
+	require.Equal(t, 3, n)
+	require.Equal(t, []image.Rectangle{image.Rect(10, 10, 12, 13), image.Rect(90, 40, 91, 41)}, boxes)
+
+	// Differences up to the tolerance are ignored.
+	n, boxes = CompareImages(a, b, 10)
+	require.Equal(t, 2, n)
+	require.Len(t, boxes, 1)
+
+	// Images of different sizes.
+	n, boxes = CompareImages(a, image.NewGray(image.Rect(0, 0, 50, 60)), 0)
+	require.Equal(t, 6000, n)
+	require.Equal(t, []image.Rectangle{image.Rect(0, 0, 100, 60)}, boxes)
+}
+
+// makeTextPages returns a page per element of `pages`, each line of the
+// pages being drawn at a separate position.
+func makeTextPages(t *testing.T, pages ...[]string) []*model.PdfPage {
+	font, err := model.NewStandard14Font(model.HelveticaName)
+	require.NoError(t, err)
+
+	var result []*model.PdfPage
+	for _, lines := range pages {
+		var content bytes.Buffer
+		for i, line := range lines {
+			fmt.Fprintf(&content, "BT /F1 12 Tf 50 %d Td (%s) Tj ET\n", 700-20*i, line)
+		}
+		stream, err := core.MakeStream(content.Bytes(), nil)
+		require.NoError(t, err)
+
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+		page.Contents = stream
+		require.NoError(t, page.Resources.SetFontByName("F1", font.ToPdfObject()))
+		result = append(result, page)
+	}
+	return result
+}
+
+func TestCompareText(t *testing.T) {
+	oldPages := makeTextPages(t,
+		[]string{"The parties agree to the terms.", "Payment is due within thirty days."},
+		[]string{"This agreement is governed by the laws of France."})
+	newPages := makeTextPages(t,
+		[]string{"The parties agree to the new terms.", "This agreement is governed by the laws of Spain."},
+		[]string{"Payment is due within thirty days."})
+
+	diffs, err := comparePagesText(oldPages, newPages, nil)
+	require.NoError(t, err)
+
+	var strs []string
+	for _, d := range diffs {
+		strs = append(strs, d.Type.String()+" "+textOf(d))
+	}
+	require.Equal(t, []string{
+		"added new",
+		"moved Payment is due within thirty days.",
+		"removed France.",
+		"added Spain.",
+	}, strs)
+
+	// The moved run is located on the second page of the new document.
+	moved := diffs[1]
+	require.Equal(t, 1, moved.Old.PageNumber)
+	require.Equal(t, 2, moved.New.PageNumber)
+	require.InDelta(t, 50, moved.New.BBox.Llx, 1)
+	require.InDelta(t, 700, moved.New.BBox.Lly, 5)
+
+	// The runs shorter than MinMoveWords are reported as removed and added.
+	diffs, err = comparePagesText(oldPages, newPages, &TextOptions{MinMoveWords: 10})
+	require.NoError(t, err)
+	require.Len(t, diffs, 5)
+
+	// The texts of the same documents are equal.
+	w := model.NewPdfWriter()
+	for _, page := range oldPages {
+		require.NoError(t, w.AddPage(page))
+	}
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	diffs, err = CompareText(r, r, nil)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+}
+
+// textOf returns the text of the run of the difference `d`.
+func textOf(d TextDiff) string {
+	if d.New != nil {
+		return d.New.Text
+	}
+	return d.Old.Text
+}
+
+func TestDiffWords(t *testing.T) {
+	a := strings.Fields("a b c a b b a")
+	b := strings.Fields("c b a b a c")
+
+	var ops []string
+	for _, op := range diffWords(a, b) {
+		switch op.kind {
+		case opEqual:
+			require.Equal(t, a[op.i], b[op.j])
+			ops = append(ops, "="+a[op.i])
+		case opDelete:
+			ops = append(ops, "-"+a[op.i])
+		case opInsert:
+			ops = append(ops, "+"+b[op.j])
+		}
+	}
+	// The edit distance is 5.
+	require.Equal(t, []string{"-a", "-b", "=c", "+b", "=a", "=b", "-b", "=a", "+c"}, ops)
+}
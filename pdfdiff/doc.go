@@ -0,0 +1,15 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package pdfdiff compares PDF documents. The structural comparison walks the
+// object graphs of the documents from their catalogs and reports the added,
+// removed and modified objects, per page and at the document level. The
+// visual comparison renders the pages of both documents and reports the
+// number of differing pixels and the bounding boxes of the differing areas of
+// each page, which is useful for the regression testing of document
+// generation pipelines. The text comparison aligns the words extracted from
+// the documents and reports the runs of text inserted, removed or moved, with
+// their locations.
+package pdfdiff
@@ -0,0 +1,307 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfdiff
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// inheritableKeys contains the page attributes inheritable from the page
+// tree nodes.
+var inheritableKeys = []core.PdfObjectName{"Resources", "MediaBox", "CropBox", "Rotate"}
+
+// streamKeys contains the stream dictionary keys describing the encoding of
+// the stream data, which are not compared as the decoded data is.
+var streamKeys = map[core.PdfObjectName]bool{
+	"Length":      true,
+	"Filter":      true,
+	"DecodeParms": true,
+}
+
+// objectPair is a pair of objects of the old and new documents.
+type objectPair struct {
+	old, new core.PdfObject
+}
+
+// comparer compares the object graphs of two documents.
+type comparer struct {
+	// oldPages and newPages map the page dictionaries of the documents to
+	// their page numbers. The pages referenced by the compared objects are
+	// compared by page number, not by value.
+	oldPages map[*core.PdfObjectDictionary]int
+	newPages map[*core.PdfObjectDictionary]int
+
+	ignore  map[core.PdfObjectName]bool
+	visited map[objectPair]bool
+	diffs   []ObjectDiff
+}
+
+// newComparer returns a new comparer of the documents containing the pages
+// `oldPages` and `newPages`, not comparing the values of the keys `ignore`.
+func newComparer(oldPages, newPages []*model.PdfPage, ignore []string) *comparer {
+	pageNumbers := func(pages []*model.PdfPage) map[*core.PdfObjectDictionary]int {
+		m := make(map[*core.PdfObjectDictionary]int, len(pages))
+		for i, page := range pages {
+			if dict, ok := core.GetDict(page.GetContainingPdfObject()); ok {
+				m[dict] = i + 1
+			}
+		}
+		return m
+	}
+
+	c := &comparer{
+		oldPages: pageNumbers(oldPages),
+		newPages: pageNumbers(newPages),
+		ignore:   make(map[core.PdfObjectName]bool, len(ignore)),
+		visited:  map[objectPair]bool{},
+	}
+	for _, key := range ignore {
+		c.ignore[core.PdfObjectName(key)] = true
+	}
+	return c
+}
+
+// flush returns the differences found since the last call and resets the
+// differences of the comparer.
+func (c *comparer) flush() []ObjectDiff {
+	diffs := c.diffs
+	c.diffs = nil
+	return diffs
+}
+
+// add records a difference of type `t` at path `path`.
+func (c *comparer) add(path string, t ChangeType, oldObj, newObj core.PdfObject) {
+	c.diffs = append(c.diffs, ObjectDiff{Path: path, Type: t, Old: oldObj, New: newObj})
+}
+
+// compareCatalogs compares the catalogs `oldRoot` and `newRoot`, except their
+// page trees.
+func (c *comparer) compareCatalogs(oldRoot, newRoot core.PdfObject) {
+	oldDict, ok := core.GetDict(oldRoot)
+	if !ok {
+		c.compare("/Root", oldRoot, newRoot)
+		return
+	}
+	newDict, ok := core.GetDict(newRoot)
+	if !ok {
+		c.compare("/Root", oldRoot, newRoot)
+		return
+	}
+
+	c.visited[objectPair{core.ResolveReference(oldRoot), core.ResolveReference(newRoot)}] = true
+	c.compareDicts("/Root", oldDict, newDict, map[core.PdfObjectName]bool{"Pages": true})
+}
+
+// comparePages compares the page dictionaries and the content streams of
+// `oldPage` and `newPage` and records the differences in `diff`.
+func (c *comparer) comparePages(diff *PageDiff, oldPage, newPage *model.PdfPage) error {
+	oldDict, _ := core.GetDict(oldPage.GetContainingPdfObject())
+	newDict, _ := core.GetDict(newPage.GetContainingPdfObject())
+	if oldDict == nil || newDict == nil {
+		return fmt.Errorf("page %d: invalid page dictionary", diff.PageNumber)
+	}
+
+	ignore := map[core.PdfObjectName]bool{"Parent": true, "Contents": true}
+	c.visited[objectPair{oldDict, newDict}] = true
+	c.compareDicts("", effectivePageDict(oldDict), effectivePageDict(newDict), ignore)
+	diff.Objects = c.flush()
+
+	oldContents, err := oldPage.GetAllContentStreams()
+	if err != nil {
+		return err
+	}
+	newContents, err := newPage.GetAllContentStreams()
+	if err != nil {
+		return err
+	}
+	diff.ContentChanged = oldContents != newContents
+	return nil
+}
+
+// effectivePageDict returns a copy of the page dictionary `dict` containing
+// the inheritable attributes of the page inherited from the page tree.
+func effectivePageDict(dict *core.PdfObjectDictionary) *core.PdfObjectDictionary {
+	d := core.MakeDict()
+	d.Merge(dict)
+	for _, key := range inheritableKeys {
+		if d.Get(key) != nil {
+			continue
+		}
+
+		visited := map[*core.PdfObjectDictionary]bool{dict: true}
+		node, ok := core.GetDict(dict.Get("Parent"))
+		for ok && !visited[node] {
+			if val := node.Get(key); val != nil {
+				d.Set(key, val)
+				break
+			}
+			visited[node] = true
+			node, ok = core.GetDict(node.Get("Parent"))
+		}
+	}
+	return d
+}
+
+// compare compares the objects `oldObj` and `newObj` located at path `path`.
+func (c *comparer) compare(path string, oldObj, newObj core.PdfObject) {
+	if oldObj == nil && newObj == nil {
+		return
+	}
+	if oldObj == nil {
+		c.add(path, ChangeAdded, nil, newObj)
+		return
+	}
+	if newObj == nil {
+		c.add(path, ChangeRemoved, oldObj, nil)
+		return
+	}
+
+	oldObj = core.ResolveReference(oldObj)
+	newObj = core.ResolveReference(newObj)
+	switch oldObj.(type) {
+	case *core.PdfIndirectObject, *core.PdfObjectStream:
+		pair := objectPair{oldObj, newObj}
+		if c.visited[pair] {
+			return
+		}
+		c.visited[pair] = true
+	}
+
+	oldDirect := core.TraceToDirectObject(oldObj)
+	newDirect := core.TraceToDirectObject(newObj)
+	switch o := oldDirect.(type) {
+	case *core.PdfObjectDictionary:
+		n, ok := newDirect.(*core.PdfObjectDictionary)
+		if !ok {
+			break
+		}
+
+		// Referenced pages are compared by page number.
+		oldPage, isOldPage := c.oldPages[o]
+		newPage, isNewPage := c.newPages[n]
+		if isOldPage || isNewPage {
+			if oldPage != newPage {
+				c.add(path, ChangeModified, oldDirect, newDirect)
+			}
+			return
+		}
+		c.compareDicts(path, o, n, nil)
+		return
+	case *core.PdfObjectArray:
+		n, ok := newDirect.(*core.PdfObjectArray)
+		if !ok {
+			break
+		}
+		c.compareArrays(path, o, n)
+		return
+	case *core.PdfObjectStream:
+		n, ok := newDirect.(*core.PdfObjectStream)
+		if !ok {
+			break
+		}
+		c.compareStreams(path, o, n)
+		return
+	default:
+		if equalPrimitives(oldDirect, newDirect) {
+			return
+		}
+	}
+
+	c.add(path, ChangeModified, oldDirect, newDirect)
+}
+
+// compareDicts compares the entries of the dictionaries `oldDict` and
+// `newDict`, except the entries whose keys are ignored by the comparer or are
+// in `ignore`.
+func (c *comparer) compareDicts(path string, oldDict, newDict *core.PdfObjectDictionary,
+	ignore map[core.PdfObjectName]bool) {
+	skip := func(key core.PdfObjectName) bool {
+		return c.ignore[key] || ignore[key]
+	}
+
+	for _, key := range oldDict.Keys() {
+		if skip(key) {
+			continue
+		}
+		c.compare(path+"/"+string(key), oldDict.Get(key), newDict.Get(key))
+	}
+	for _, key := range newDict.Keys() {
+		if skip(key) || oldDict.Get(key) != nil {
+			continue
+		}
+		c.add(path+"/"+string(key), ChangeAdded, nil, newDict.Get(key))
+	}
+}
+
+// compareArrays compares the elements of the arrays `oldArr` and `newArr`.
+func (c *comparer) compareArrays(path string, oldArr, newArr *core.PdfObjectArray) {
+	n := oldArr.Len()
+	if newArr.Len() > n {
+		n = newArr.Len()
+	}
+	for i := 0; i < n; i++ {
+		c.compare(fmt.Sprintf("%s[%d]", path, i), oldArr.Get(i), newArr.Get(i))
+	}
+}
+
+// compareStreams compares the dictionaries and the decoded data of the
+// streams `oldStream` and `newStream`.
+func (c *comparer) compareStreams(path string, oldStream, newStream *core.PdfObjectStream) {
+	c.compareDicts(path, oldStream.PdfObjectDictionary, newStream.PdfObjectDictionary, streamKeys)
+
+	oldData, oldErr := core.DecodeStream(oldStream)
+	newData, newErr := core.DecodeStream(newStream)
+	if oldErr != nil || newErr != nil {
+		// Compare the encoded data of the streams which cannot be decoded.
+		common.Log.Debug("ERROR: unable to decode streams at %s: %v %v", path, oldErr, newErr)
+		oldData, newData = oldStream.Stream, newStream.Stream
+	}
+	if !bytes.Equal(oldData, newData) {
+		c.add(path, ChangeModified, oldStream, newStream)
+	}
+}
+
+// equalPrimitives returns true if the direct objects `a` and `b`, which are
+// neither dictionaries, arrays nor streams, are equal. Integer and real
+// numbers with the same value are equal.
+func equalPrimitives(a, b core.PdfObject) bool {
+	if core.IsNullObject(a) || core.IsNullObject(b) {
+		return core.IsNullObject(a) && core.IsNullObject(b)
+	}
+
+	switch a.(type) {
+	case *core.PdfObjectInteger, *core.PdfObjectFloat:
+		va, errA := core.GetNumberAsFloat(a)
+		vb, errB := core.GetNumberAsFloat(b)
+		return errA == nil && errB == nil && va == vb
+	case *core.PdfObjectString:
+		sb, ok := b.(*core.PdfObjectString)
+		return ok && a.(*core.PdfObjectString).Str() == sb.Str()
+	}
+	return a.WriteString() == b.WriteString()
+}
+
+// describeObject returns a short description of `obj`: the value of the
+// primitive objects or the type of the other objects.
+func describeObject(obj core.PdfObject) string {
+	switch t := core.TraceToDirectObject(obj).(type) {
+	case nil:
+		return "null"
+	case *core.PdfObjectDictionary:
+		return fmt.Sprintf("dictionary (%d entries)", len(t.Keys()))
+	case *core.PdfObjectArray:
+		return fmt.Sprintf("array (%d elements)", t.Len())
+	case *core.PdfObjectStream:
+		return "stream"
+	default:
+		return t.WriteString()
+	}
+}
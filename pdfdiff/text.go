@@ -0,0 +1,496 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfdiff
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/extractor"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// maxEditDistance is the largest number of inserted and removed words
+// between the aligned words of the compared texts for which the shortest edit
+// script is searched. Larger differences are reported as the
+// removal of the old text and the insertion of the new text.
+const maxEditDistance = 4096
+
+// TextRun represents a run of consecutive words of the same text line.
+type TextRun struct {
+	// Text is the text of the run, the words being separated by spaces.
+	Text string
+
+	// PageNumber is the 1-based number of the page containing the run.
+	PageNumber int
+
+	// BBox is the bounding box of the run, in the default user space of the
+	// page.
+	BBox model.PdfRectangle
+}
+
+// TextDiff represents a difference between the texts of two documents.
+type TextDiff struct {
+	// Type is the type of the difference: ChangeAdded, ChangeRemoved or
+	// ChangeMoved.
+	Type ChangeType
+
+	// Old is the run of the old document. Nil if the run was added.
+	Old *TextRun
+
+	// New is the run of the new document. Nil if the run was removed.
+	New *TextRun
+}
+
+// String returns a string describing the difference.
+func (d TextDiff) String() string {
+	location := func(r *TextRun) string {
+		return fmt.Sprintf("page %d (%.2f, %.2f, %.2f, %.2f)", r.PageNumber,
+			r.BBox.Llx, r.BBox.Lly, r.BBox.Urx, r.BBox.Ury)
+	}
+
+	switch d.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("added %q at %s", d.New.Text, location(d.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("removed %q at %s", d.Old.Text, location(d.Old))
+	}
+	return fmt.Sprintf("moved %q from %s to %s", d.Old.Text, location(d.Old), location(d.New))
+}
+
+// TextOptions contains the options used for comparing the texts of documents.
+type TextOptions struct {
+	// MinMoveWords is the smallest number of words of the removed and added
+	// runs with the same text reported as moved runs. Defaults to 2, the runs
+	// of a single word being reported as removed and added.
+	MinMoveWords int
+}
+
+// CompareText compares the texts extracted from the documents read by
+// `oldReader` and `newReader`. The words of the documents are aligned in
+// reading order, across pages, and the differences are reported as the runs
+// of words removed from the old document, added to the new document or moved
+// to other locations of the text, in the order of the texts.
+func CompareText(oldReader, newReader *model.PdfReader, opts *TextOptions) ([]TextDiff, error) {
+	if oldReader == nil || newReader == nil {
+		return nil, errors.New("readers cannot be nil")
+	}
+
+	oldPages, err := readerPages(oldReader)
+	if err != nil {
+		return nil, err
+	}
+	newPages, err := readerPages(newReader)
+	if err != nil {
+		return nil, err
+	}
+	return comparePagesText(oldPages, newPages, opts)
+}
+
+// ComparePageText compares the texts extracted from the pages `oldPage` and
+// `newPage`. The page numbers of the runs of the differences are 1.
+func ComparePageText(oldPage, newPage *model.PdfPage, opts *TextOptions) ([]TextDiff, error) {
+	if oldPage == nil || newPage == nil {
+		return nil, errors.New("pages cannot be nil")
+	}
+	return comparePagesText([]*model.PdfPage{oldPage}, []*model.PdfPage{newPage}, opts)
+}
+
+// comparePagesText compares the texts of the pages `oldPages` and `newPages`.
+func comparePagesText(oldPages, newPages []*model.PdfPage, opts *TextOptions) ([]TextDiff, error) {
+	if opts == nil {
+		opts = &TextOptions{}
+	}
+	minMoveWords := opts.MinMoveWords
+	if minMoveWords <= 0 {
+		minMoveWords = 2
+	}
+
+	oldWords, err := extractWords(oldPages)
+	if err != nil {
+		return nil, err
+	}
+	newWords, err := extractWords(newPages)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the runs of removed and added words of the edit script.
+	type hunkRun struct {
+		run   *TextRun
+		words int
+	}
+	var removed, added []hunkRun
+	var diffs []TextDiff
+	var curRemoved, curAdded []textWord
+	flush := func(words []textWord, t ChangeType) {
+		for _, run := range splitRuns(words) {
+			hr := hunkRun{run: newTextRun(run), words: len(run)}
+			if t == ChangeRemoved {
+				removed = append(removed, hr)
+				diffs = append(diffs, TextDiff{Type: t, Old: hr.run})
+			} else {
+				added = append(added, hr)
+				diffs = append(diffs, TextDiff{Type: t, New: hr.run})
+			}
+		}
+	}
+	for _, op := range diffWords(wordTexts(oldWords), wordTexts(newWords)) {
+		switch op.kind {
+		case opDelete:
+			curRemoved = append(curRemoved, oldWords[op.i])
+		case opInsert:
+			curAdded = append(curAdded, newWords[op.j])
+		default:
+			flush(curRemoved, ChangeRemoved)
+			flush(curAdded, ChangeAdded)
+			curRemoved, curAdded = nil, nil
+		}
+	}
+	flush(curRemoved, ChangeRemoved)
+	flush(curAdded, ChangeAdded)
+
+	// Pair the removed and added runs with the same text as moved runs.
+	moved := map[*TextRun]*TextRun{}
+	matched := map[*TextRun]bool{}
+	for _, r := range removed {
+		if r.words < minMoveWords {
+			continue
+		}
+		for _, a := range added {
+			if !matched[a.run] && a.run.Text == r.run.Text {
+				moved[r.run] = a.run
+				matched[a.run] = true
+				break
+			}
+		}
+	}
+	if len(moved) == 0 {
+		return diffs, nil
+	}
+
+	result := make([]TextDiff, 0, len(diffs)-len(moved))
+	for _, d := range diffs {
+		if d.New != nil && matched[d.New] {
+			continue
+		}
+		if d.Old != nil && moved[d.Old] != nil {
+			d = TextDiff{Type: ChangeMoved, Old: d.Old, New: moved[d.Old]}
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// textWord represents a word extracted from a page.
+type textWord struct {
+	text string
+	page int
+	line int
+	bbox model.PdfRectangle
+}
+
+// extractWords returns the words of the text extracted from `pages`.
+func extractWords(pages []*model.PdfPage) ([]textWord, error) {
+	var words []textWord
+	line := 0
+	for i, page := range pages {
+		ex, err := extractor.New(page)
+		if err != nil {
+			return nil, err
+		}
+		pageText, _, _, err := ex.ExtractPageText()
+		if err != nil {
+			return nil, err
+		}
+
+		var cur *textWord
+		for _, mark := range pageText.Marks().Elements() {
+			if mark.Meta || strings.TrimSpace(mark.Text) == "" {
+				cur = nil
+				if strings.Contains(mark.Text, "\n") {
+					line++
+				}
+				continue
+			}
+			if cur == nil {
+				words = append(words, textWord{page: i + 1, line: line, bbox: mark.BBox})
+				cur = &words[len(words)-1]
+			}
+			cur.text += mark.Text
+			cur.bbox = unionRect(cur.bbox, mark.BBox)
+		}
+		line++
+	}
+	return words, nil
+}
+
+// wordTexts returns the texts of `words`.
+func wordTexts(words []textWord) []string {
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.text
+	}
+	return texts
+}
+
+// splitRuns splits the consecutive words `words` into runs of words of the
+// same line.
+func splitRuns(words []textWord) [][]textWord {
+	var runs [][]textWord
+	start := 0
+	for i := 1; i <= len(words); i++ {
+		if i == len(words) || words[i].line != words[start].line {
+			runs = append(runs, words[start:i])
+			start = i
+		}
+	}
+	return runs
+}
+
+// newTextRun returns the run of the words `words` of the same line.
+func newTextRun(words []textWord) *TextRun {
+	run := &TextRun{PageNumber: words[0].page, BBox: words[0].bbox}
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.text
+		run.BBox = unionRect(run.BBox, w.bbox)
+	}
+	run.Text = strings.Join(texts, " ")
+	return run
+}
+
+// unionRect returns the smallest rectangle containing `a` and `b`.
+func unionRect(a, b model.PdfRectangle) model.PdfRectangle {
+	return model.PdfRectangle{
+		Llx: math.Min(a.Llx, b.Llx),
+		Lly: math.Min(a.Lly, b.Lly),
+		Urx: math.Max(a.Urx, b.Urx),
+		Ury: math.Max(a.Ury, b.Ury),
+	}
+}
+
+// Edit script operation kinds.
+const (
+	opEqual = iota
+	opDelete
+	opInsert
+)
+
+// diffOp represents an operation of an edit script transforming a sequence
+// `a` into a sequence `b`.
+type diffOp struct {
+	// kind is the kind of the operation.
+	kind int
+
+	// i is the index of the element of `a` kept or deleted.
+	i int
+
+	// j is the index of the element of `b` kept or inserted.
+	j int
+}
+
+// diffWords returns an edit script transforming `a` into `b`. As in the
+// patience diff, the words occurring once in both `a` and `b` are aligned
+// first, using the longest increasing subsequence of their positions, which
+// keeps the moved sentences together. The words between the aligned words are
+// compared using Myers' algorithm.
+func diffWords(a, b []string) []diffOp {
+	var ops []diffOp
+	patienceDiff(a, b, 0, 0, &ops)
+	return ops
+}
+
+// patienceDiff appends the edit script transforming `a` into `b`, which start
+// at indexes `i0` and `j0` of the compared sequences, to `ops`.
+func patienceDiff(a, b []string, i0, j0 int, ops *[]diffOp) {
+	// Trim the common prefix and suffix.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		*ops = append(*ops, diffOp{kind: opEqual, i: i0 + prefix, j: j0 + prefix})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	i1, j1 := i0+prefix, j0+prefix
+	if anchors := uniqueAnchors(ma, mb); len(anchors) > 0 {
+		pi, pj := 0, 0
+		for _, anchor := range anchors {
+			patienceDiff(ma[pi:anchor.i], mb[pj:anchor.j], i1+pi, j1+pj, ops)
+			*ops = append(*ops, diffOp{kind: opEqual, i: i1 + anchor.i, j: j1 + anchor.j})
+			pi, pj = anchor.i+1, anchor.j+1
+		}
+		patienceDiff(ma[pi:], mb[pj:], i1+pi, j1+pj, ops)
+	} else {
+		for _, op := range myersDiff(ma, mb) {
+			op.i += i1
+			op.j += j1
+			*ops = append(*ops, op)
+		}
+	}
+
+	for k := 0; k < suffix; k++ {
+		*ops = append(*ops, diffOp{kind: opEqual, i: i0 + len(a) - suffix + k, j: j0 + len(b) - suffix + k})
+	}
+}
+
+// uniqueAnchors returns the longest sequence of pairs of indexes of the words
+// occurring once in both `a` and `b`, increasing in both `a` and `b`.
+func uniqueAnchors(a, b []string) []diffOp {
+	type occurrences struct {
+		countA, countB int
+		j              int
+	}
+	words := map[string]*occurrences{}
+	for _, w := range a {
+		o := words[w]
+		if o == nil {
+			o = &occurrences{}
+			words[w] = o
+		}
+		o.countA++
+	}
+	for j, w := range b {
+		if o := words[w]; o != nil {
+			o.countB++
+			o.j = j
+		}
+	}
+
+	var pairs []diffOp
+	for i, w := range a {
+		if o := words[w]; o.countA == 1 && o.countB == 1 {
+			pairs = append(pairs, diffOp{kind: opEqual, i: i, j: o.j})
+		}
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	// Find the longest increasing subsequence of the indexes in `b` using
+	// patience sorting. tails[k] is the index of the pair ending the
+	// smallest subsequence of length k+1.
+	var tails []int
+	prev := make([]int, len(pairs))
+	for p, pair := range pairs {
+		k := sort.Search(len(tails), func(k int) bool { return pairs[tails[k]].j >= pair.j })
+		prev[p] = -1
+		if k > 0 {
+			prev[p] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, p)
+		} else {
+			tails[k] = p
+		}
+	}
+
+	anchors := make([]diffOp, len(tails))
+	for k, p := len(tails)-1, tails[len(tails)-1]; k >= 0; k, p = k-1, prev[p] {
+		anchors[k] = pairs[p]
+	}
+	return anchors
+}
+
+// myersDiff returns the shortest edit script transforming `a` into `b`, or
+// the deletion of `a` followed by the insertion of `b` if the edit distance
+// exceeds maxEditDistance.
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	max := n + m
+	if max > maxEditDistance {
+		max = maxEditDistance
+	}
+
+	// trace[d] contains the furthest reaching x of the diagonals -d to d
+	// before the step d.
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	for i := range a {
+		ops = append(ops, diffOp{kind: opDelete, i: i})
+	}
+	for j := range b {
+		ops = append(ops, diffOp{kind: opInsert, i: n, j: j})
+	}
+	return ops
+}
+
+// backtrack returns the edit script ending at `n`,`m` found by myersDiff
+// from the furthest reaching paths `trace`.
+func backtrack(trace [][]int, n, m int) []diffOp {
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX, prevY := 0, 0
+		if d > 0 {
+			prevX = at(prevK)
+			prevY = prevX - prevK
+		}
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{kind: opEqual, i: x, j: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{kind: opInsert, i: prevX, j: prevY})
+			} else {
+				ops = append(ops, diffOp{kind: opDelete, i: prevX, j: prevY})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,145 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfdiff
+
+import (
+	"image"
+	"sort"
+
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/render"
+)
+
+// diffCellSize is the size, in pixels, of the cells used for grouping the
+// differing pixels into areas. The differing pixels of adjacent cells belong
+// to the same area.
+const diffCellSize = 8
+
+// visualComparer compares the rendered pages of two documents.
+type visualComparer struct {
+	device    *render.ImageDevice
+	tolerance uint8
+}
+
+// newVisualComparer returns a new visual comparer rendering the pages at
+// resolution `dpi` and considering equal the pixels whose color components
+// differ by at most `tolerance`.
+func newVisualComparer(dpi float64, tolerance uint8) *visualComparer {
+	device := render.NewImageDevice()
+	device.DPI = dpi
+	return &visualComparer{device: device, tolerance: tolerance}
+}
+
+// comparePages renders `oldPage` and `newPage` and records the differences
+// between the rendered images in `diff`.
+func (v *visualComparer) comparePages(diff *PageDiff, oldPage, newPage *model.PdfPage) error {
+	oldImg, err := v.device.Render(oldPage)
+	if err != nil {
+		return err
+	}
+	newImg, err := v.device.Render(newPage)
+	if err != nil {
+		return err
+	}
+
+	diff.DiffPixels, diff.DiffBoxes = CompareImages(oldImg, newImg, v.tolerance)
+	return nil
+}
+
+// CompareImages compares the images `a` and `b` pixel by pixel, considering
+// equal the pixels whose color components differ by at most `tolerance`, and
+// returns the number of differing pixels and the bounding boxes of the
+// differing areas, relative to the top left corners of the images and sorted
+// from top to bottom and left to right. The images of different sizes are
+// entirely different.
+func CompareImages(a, b image.Image, tolerance uint8) (int, []image.Rectangle) {
+	ab, bb := a.Bounds(), b.Bounds()
+	if ab.Dx() != bb.Dx() || ab.Dy() != bb.Dy() {
+		r := image.Rect(0, 0, ab.Dx(), ab.Dy()).Union(image.Rect(0, 0, bb.Dx(), bb.Dy()))
+		return r.Dx() * r.Dy(), []image.Rectangle{r}
+	}
+
+	// Find the bounding boxes of the differing pixels of each cell.
+	w, h := ab.Dx(), ab.Dy()
+	cols, rows := (w+diffCellSize-1)/diffCellSize, (h+diffCellSize-1)/diffCellSize
+	cells := make([]image.Rectangle, cols*rows)
+	numDiffs := 0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !differentColors(a, b, ab.Min.X+x, ab.Min.Y+y, bb.Min.X+x, bb.Min.Y+y, tolerance) {
+				continue
+			}
+			numDiffs++
+			i := (y/diffCellSize)*cols + x/diffCellSize
+			cells[i] = cells[i].Union(image.Rect(x, y, x+1, y+1))
+		}
+	}
+	if numDiffs == 0 {
+		return 0, nil
+	}
+
+	// Merge the boxes of the adjacent cells.
+	var boxes []image.Rectangle
+	visited := make([]bool, len(cells))
+	for i := range cells {
+		if visited[i] || cells[i].Empty() {
+			continue
+		}
+
+		box := image.Rectangle{}
+		stack := []int{i}
+		visited[i] = true
+		for len(stack) > 0 {
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			box = box.Union(cells[j])
+
+			col, row := j%cols, j/cols
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					c, r := col+dx, row+dy
+					if c < 0 || c >= cols || r < 0 || r >= rows {
+						continue
+					}
+					k := r*cols + c
+					if !visited[k] && !cells[k].Empty() {
+						visited[k] = true
+						stack = append(stack, k)
+					}
+				}
+			}
+		}
+		boxes = append(boxes, box)
+	}
+
+	sort.Slice(boxes, func(i, j int) bool {
+		if boxes[i].Min.Y != boxes[j].Min.Y {
+			return boxes[i].Min.Y < boxes[j].Min.Y
+		}
+		return boxes[i].Min.X < boxes[j].Min.X
+	})
+	return numDiffs, boxes
+}
+
+// differentColors returns true if a color component of the pixel `ax`,`ay`
+// of `a` differs from the one of the pixel `bx`,`by` of `b` by more than
+// `tolerance`.
+func differentColors(a, b image.Image, ax, ay, bx, by int, tolerance uint8) bool {
+	r1, g1, b1, a1 := a.At(ax, ay).RGBA()
+	r2, g2, b2, a2 := b.At(bx, by).RGBA()
+
+	limit := uint32(tolerance) * 0x101
+	for _, d := range [][2]uint32{{r1, r2}, {g1, g2}, {b1, b2}, {a1, a2}} {
+		v1, v2 := d[0], d[1]
+		if v1 > v2 {
+			v1, v2 = v2, v1
+		}
+		if v2-v1 > limit {
+			return true
+		}
+	}
+	return false
+}
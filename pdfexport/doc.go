@@ -0,0 +1,13 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package pdfexport converts PDF documents to HTML and EPUB. The pages are
+// segmented into headings, paragraphs, lists, tables and figures by the
+// layout analysis of the extractor package, and the styles of the text runs
+// (font, weight, size, color, rise) are kept. In the reflowable mode, the
+// blocks are written as semantic markup which adapts to the reading device.
+// In the fixed mode, the text runs and the images are positioned as on the
+// PDF pages, favoring fidelity over reflowability.
+package pdfexport
@@ -0,0 +1,233 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"html"
+	"io"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/extractor"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// epubContainer is the container file of EPUB documents, which points to the package document.
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+</rootfiles>
+</container>
+`
+
+// WriteEPUB writes the document `r` to `w` as an EPUB 3 document, with one content document per
+// page and a navigation document listing the headings, or the pages if no headings were found.
+// In the fixed mode, the EPUB document has a fixed (pre-paginated) layout. `opts` may be nil for
+// the default options. The export stops with the error of `ctx` when it is cancelled or its
+// deadline is exceeded.
+func WriteEPUB(ctx context.Context, w io.Writer, r *model.PdfReader, opts *Options) error {
+	doc, err := newExportDoc(ctx, r, opts)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	// The mimetype file must come first and be stored uncompressed.
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mw, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		"META-INF/container.xml": []byte(epubContainer),
+		"OEBPS/content.opf":      doc.epubPackage(epubIdentifier(r, doc.title), epubModified(r)),
+		"OEBPS/nav.xhtml":        doc.epubNav(),
+		"OEBPS/style.css":        []byte(styleSheet),
+	}
+	names := []string{"META-INF/container.xml", "OEBPS/content.opf", "OEBPS/nav.xhtml", "OEBPS/style.css"}
+	for _, p := range doc.pages {
+		name := "OEBPS/" + pageFileName(p)
+		files[name] = doc.epubPage(p)
+		names = append(names, name)
+	}
+	for _, img := range doc.images {
+		name := "OEBPS/images/" + img.name
+		files[name] = img.data
+		names = append(names, name)
+	}
+	for _, name := range names {
+		fw, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// pageFileName returns the name of the content document of the page `p`.
+func pageFileName(p *exportPage) string {
+	return fmt.Sprintf("page%d.xhtml", p.num)
+}
+
+// epubImageSrc returns the path of the image `img` relative to the content documents.
+func epubImageSrc(img *exportImage) string {
+	return "images/" + img.name
+}
+
+// epubPage returns the content document of the page `p`.
+func (doc *exportDoc) epubPage(p *exportPage) []byte {
+	var b bytes.Buffer
+	doc.writeXHTMLHead(&b, fmt.Sprintf("%s - %d", doc.title, p.num))
+	if doc.opts.Mode == ModeFixed {
+		fmt.Fprintf(&b, "<meta name=\"viewport\" content=\"width=%s, height=%s\"/>\n",
+			formatPt(p.mediaBox.Width()), formatPt(p.mediaBox.Height()))
+	}
+	fmt.Fprintf(&b, "</head>\n<body>\n")
+	doc.writePage(&b, p, epubImageSrc)
+	fmt.Fprintf(&b, "</body>\n</html>\n")
+	return b.Bytes()
+}
+
+// navItem is an item of the navigation document.
+type navItem struct {
+	title string
+	href  string
+	level int
+	items []*navItem
+}
+
+// epubNav returns the navigation document, listing the headings of the document, nested
+// according to their levels, or the pages if the document has no headings.
+func (doc *exportDoc) epubNav() []byte {
+	root := &navItem{}
+	parents := []*navItem{root}
+	for _, p := range doc.pages {
+		for _, block := range p.blocks {
+			if block.Type != extractor.BlockHeading {
+				continue
+			}
+			item := &navItem{
+				title: joinLines(block.Text),
+				href:  pageFileName(p) + "#" + p.headings[block],
+				level: doc.headingLevel(block),
+			}
+			for len(parents) > 1 && parents[len(parents)-1].level >= item.level {
+				parents = parents[:len(parents)-1]
+			}
+			parent := parents[len(parents)-1]
+			parent.items = append(parent.items, item)
+			parents = append(parents, item)
+		}
+	}
+	if len(root.items) == 0 {
+		for _, p := range doc.pages {
+			root.items = append(root.items, &navItem{title: fmt.Sprintf("Page %d", p.num), href: pageFileName(p)})
+		}
+	}
+
+	var b bytes.Buffer
+	doc.writeXHTMLHead(&b, doc.title)
+	fmt.Fprintf(&b, "</head>\n<body>\n<nav epub:type=\"toc\" id=\"toc\">\n<h1>%s</h1>\n", html.EscapeString(doc.title))
+	writeNavItems(&b, root.items)
+	fmt.Fprintf(&b, "</nav>\n</body>\n</html>\n")
+	return b.Bytes()
+}
+
+// writeNavItems writes the list of navigation items `items` to `w`.
+func writeNavItems(w io.Writer, items []*navItem) {
+	fmt.Fprintf(w, "<ol>\n")
+	for _, item := range items {
+		fmt.Fprintf(w, "<li><a href=\"%s\">%s</a>", item.href, html.EscapeString(item.title))
+		if len(item.items) > 0 {
+			fmt.Fprintf(w, "\n")
+			writeNavItems(w, item.items)
+		}
+		fmt.Fprintf(w, "</li>\n")
+	}
+	fmt.Fprintf(w, "</ol>\n")
+}
+
+// writeXHTMLHead writes the beginning of an XHTML content document with title `title` to `w`,
+// up to the end of the head element, excluded.
+func (doc *exportDoc) writeXHTMLHead(w io.Writer, title string) {
+	fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<!DOCTYPE html>\n")
+	fmt.Fprintf(w, "<html xmlns=\"http://www.w3.org/1999/xhtml\" xmlns:epub=\"http://www.idpf.org/2007/ops\" "+
+		"xml:lang=\"%s\" lang=\"%s\">\n", html.EscapeString(doc.lang), html.EscapeString(doc.lang))
+	fmt.Fprintf(w, "<head>\n<meta charset=\"utf-8\"/>\n<title>%s</title>\n", html.EscapeString(title))
+	fmt.Fprintf(w, "<link rel=\"stylesheet\" type=\"text/css\" href=\"style.css\"/>\n")
+}
+
+// epubPackage returns the package document, with the metadata, the manifest and the spine of the
+// EPUB document.
+func (doc *exportDoc) epubPackage(identifier string, modified time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(&b, "<package xmlns=\"http://www.idpf.org/2007/opf\" version=\"3.0\" unique-identifier=\"uid\">\n")
+	fmt.Fprintf(&b, "<metadata xmlns:dc=\"http://purl.org/dc/elements/1.1/\">\n")
+	fmt.Fprintf(&b, "<dc:identifier id=\"uid\">%s</dc:identifier>\n", identifier)
+	fmt.Fprintf(&b, "<dc:title>%s</dc:title>\n<dc:language>%s</dc:language>\n", html.EscapeString(doc.title), html.EscapeString(doc.lang))
+	fmt.Fprintf(&b, "<meta property=\"dcterms:modified\">%s</meta>\n", modified.UTC().Format("2006-01-02T15:04:05Z"))
+	if doc.opts.Mode == ModeFixed {
+		fmt.Fprintf(&b, "<meta property=\"rendition:layout\">pre-paginated</meta>\n")
+	}
+	fmt.Fprintf(&b, "</metadata>\n<manifest>\n")
+	fmt.Fprintf(&b, "<item id=\"nav\" href=\"nav.xhtml\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>\n")
+	fmt.Fprintf(&b, "<item id=\"css\" href=\"style.css\" media-type=\"text/css\"/>\n")
+	for _, p := range doc.pages {
+		fmt.Fprintf(&b, "<item id=\"page%d\" href=\"%s\" media-type=\"application/xhtml+xml\"/>\n",
+			p.num, pageFileName(p))
+	}
+	for i, img := range doc.images {
+		fmt.Fprintf(&b, "<item id=\"image%d\" href=\"%s\" media-type=\"image/png\"/>\n", i+1, epubImageSrc(img))
+	}
+	fmt.Fprintf(&b, "</manifest>\n<spine>\n")
+	for _, p := range doc.pages {
+		fmt.Fprintf(&b, "<itemref idref=\"page%d\"/>\n", p.num)
+	}
+	fmt.Fprintf(&b, "</spine>\n</package>\n")
+	return b.Bytes()
+}
+
+// epubIdentifier returns the unique identifier of the EPUB document: a name based UUID derived
+// from the identifier of the document `r`, or from its title `title` if it has none.
+func epubIdentifier(r *model.PdfReader, title string) string {
+	h := sha1.New()
+	if id0, id1, ok := r.GetDocumentID(); ok {
+		h.Write(id0)
+		h.Write(id1)
+	} else {
+		h.Write([]byte(title))
+	}
+	u := h.Sum(nil)[:16]
+	u[6] = u[6]&0x0f | 0x50 // Version 5.
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 variant.
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+// epubModified returns the modification date of the document `r`: the modification or creation
+// date of its information dictionary, or the current time.
+func epubModified(r *model.PdfReader) time.Time {
+	if info, err := r.GetPdfInfo(); err == nil {
+		if !info.ModDate.IsZero() {
+			return info.ModDate
+		}
+		if !info.CreationDate.IsZero() {
+			return info.CreationDate
+		}
+	}
+	return time.Now()
+}
@@ -0,0 +1,492 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfexport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/extractor"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// Mode is the export mode, which selects between reflowability and fidelity.
+type Mode int
+
+// Export modes.
+const (
+	// ModeReflow writes the blocks of the pages as semantic markup (headings, paragraphs, lists,
+	// tables and figures), which reflows to the size of the reading device. The headers and footers
+	// of the pages are skipped.
+	ModeReflow Mode = iota
+
+	// ModeFixed positions the text runs and the images as on the PDF pages, with their fonts, sizes
+	// and colors. EPUB documents are written with a fixed (pre-paginated) layout.
+	ModeFixed
+)
+
+// Options specifies the export options.
+type Options struct {
+	// Mode is the export mode. Default: ModeReflow.
+	Mode Mode
+
+	// Title is the title of the exported document. Default: the title of the document
+	// information dictionary, or "Untitled".
+	Title string
+
+	// Language is the language of the exported document, e.g. "en-US". Default: the language of
+	// the document catalog (Lang), or "en".
+	Language string
+
+	// Styles keeps the font families, sizes and colors of the text runs in the reflowable mode.
+	// Bold and italic text, superscripts and subscripts are always kept.
+	Styles bool
+
+	// HeadersFooters keeps the headers and footers of the pages in the reflowable mode.
+	HeadersFooters bool
+
+	// NoImages skips the images of the pages.
+	NoImages bool
+}
+
+// maxHeadingLevel is the level of the smallest headings (h6).
+const maxHeadingLevel = 6
+
+// exportDoc is a document prepared for export.
+type exportDoc struct {
+	opts  Options
+	title string
+	lang  string
+	pages []*exportPage
+	// levels maps the font sizes of the headings to their levels.
+	levels map[float64]int
+	images []*exportImage
+}
+
+// exportPage is a page prepared for export.
+type exportPage struct {
+	num      int
+	mediaBox model.PdfRectangle
+	blocks   []*extractor.LayoutBlock
+	images   map[*extractor.LayoutBlock]*exportImage
+	headings map[*extractor.LayoutBlock]string // Heading ids.
+}
+
+// exportImage is an image of the document, encoded as PNG.
+type exportImage struct {
+	name string
+	data []byte
+}
+
+// newExportDoc analyzes the layout of the pages of the document `r` and encodes its images.
+func newExportDoc(ctx context.Context, r *model.PdfReader, opts *Options) (*exportDoc, error) {
+	doc := &exportDoc{levels: map[float64]int{}}
+	if opts != nil {
+		doc.opts = *opts
+	}
+
+	doc.title = doc.opts.Title
+	if doc.title == "" {
+		info, err := r.GetPdfInfo()
+		if err != nil {
+			return nil, err
+		}
+		doc.title = info.Title
+	}
+	if doc.title == "" {
+		doc.title = "Untitled"
+	}
+	doc.lang = doc.opts.Language
+	if doc.lang == "" {
+		doc.lang = r.GetLanguage()
+	}
+	if doc.lang == "" {
+		doc.lang = "en"
+	}
+
+	numPages, err := r.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+	var headingSizes []float64
+	for i := 1; i <= numPages; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := r.GetPage(i)
+		if err != nil {
+			return nil, err
+		}
+		mediaBox, err := page.GetMediaBox()
+		if err != nil {
+			return nil, err
+		}
+		e, err := extractor.New(page)
+		if err != nil {
+			return nil, err
+		}
+		layout, err := e.AnalyzeLayout()
+		if err != nil {
+			return nil, err
+		}
+
+		p := &exportPage{
+			num:      i,
+			mediaBox: *mediaBox,
+			images:   map[*extractor.LayoutBlock]*exportImage{},
+			headings: map[*extractor.LayoutBlock]string{},
+		}
+		for _, block := range layout.Blocks {
+			switch block.Type {
+			case extractor.BlockFigure:
+				if doc.opts.NoImages {
+					continue
+				}
+				img, err := doc.addImage(block.Image)
+				if err != nil {
+					common.Log.Debug("ERROR: could not export image: %v", err)
+					continue
+				}
+				p.images[block] = img
+			case extractor.BlockHeading:
+				p.headings[block] = fmt.Sprintf("h%d-%d", i, len(p.headings)+1)
+				headingSizes = append(headingSizes, blockFontSize(block))
+			case extractor.BlockHeader, extractor.BlockFooter:
+				if doc.opts.Mode == ModeReflow && !doc.opts.HeadersFooters {
+					continue
+				}
+			}
+			p.blocks = append(p.blocks, block)
+		}
+		doc.pages = append(doc.pages, p)
+	}
+
+	// The heading levels are the ranks of the heading font sizes.
+	sort.Sort(sort.Reverse(sort.Float64Slice(headingSizes)))
+	for _, size := range headingSizes {
+		if _, ok := doc.levels[size]; !ok {
+			doc.levels[size] = minInt(len(doc.levels)+1, maxHeadingLevel)
+		}
+	}
+	return doc, nil
+}
+
+// addImage encodes the image `mark` as PNG and adds it to the images of the document.
+func (doc *exportDoc) addImage(mark *extractor.ImageMark) (*exportImage, error) {
+	goImg, err := mark.Image.ToGoImage()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, goImg); err != nil {
+		return nil, err
+	}
+	img := &exportImage{
+		name: fmt.Sprintf("image%d.png", len(doc.images)+1),
+		data: buf.Bytes(),
+	}
+	doc.images = append(doc.images, img)
+	return img, nil
+}
+
+// headingLevel returns the level of the heading block `block`.
+func (doc *exportDoc) headingLevel(block *extractor.LayoutBlock) int {
+	if level, ok := doc.levels[blockFontSize(block)]; ok {
+		return level
+	}
+	return 1
+}
+
+// writePage writes the body of the page `p` to `w`. The images are referenced by `imageSrc`.
+func (doc *exportDoc) writePage(w io.Writer, p *exportPage, imageSrc func(*exportImage) string) {
+	if doc.opts.Mode == ModeFixed {
+		doc.writeFixedPage(w, p, imageSrc)
+		return
+	}
+
+	fmt.Fprintf(w, "<section class=\"page\" id=\"page-%d\">\n", p.num)
+	for _, block := range p.blocks {
+		switch block.Type {
+		case extractor.BlockHeading:
+			level := doc.headingLevel(block)
+			fmt.Fprintf(w, "<h%d id=\"%s\">%s</h%d>\n", level, p.headings[block],
+				html.EscapeString(joinLines(block.Text)), level)
+		case extractor.BlockList:
+			tag := "ul"
+			if orderedListRegexp.MatchString(block.Text) {
+				tag = "ol"
+			}
+			fmt.Fprintf(w, "<%s>\n", tag)
+			for _, item := range block.Items {
+				fmt.Fprintf(w, "<li>%s</li>\n", html.EscapeString(item))
+			}
+			fmt.Fprintf(w, "</%s>\n", tag)
+		case extractor.BlockTable:
+			writeTable(w, block.Table)
+		case extractor.BlockFigure:
+			img := p.images[block]
+			fmt.Fprintf(w, "<figure><img src=\"%s\" alt=\"\" style=\"width:%spt\"/></figure>\n",
+				imageSrc(img), formatPt(block.BBox.Width()))
+		case extractor.BlockHeader, extractor.BlockFooter:
+			fmt.Fprintf(w, "<p class=\"%s\">%s</p>\n", block.Type, doc.runsHTML(block))
+		default:
+			fmt.Fprintf(w, "<p>%s</p>\n", doc.runsHTML(block))
+		}
+	}
+	fmt.Fprintf(w, "</section>\n")
+}
+
+// orderedListRegexp matches the enumerators of ordered lists.
+var orderedListRegexp = regexp.MustCompile(`^\s*\(?(\d{1,3}|[a-zA-Z]|[ivxlcdm]{1,6})[.)]\s`)
+
+// runsHTML returns the markup of the text runs of the text block `block`, for the reflowable
+// mode. The lines are joined by spaces.
+func (doc *exportDoc) runsHTML(block *extractor.LayoutBlock) string {
+	var b strings.Builder
+	for _, run := range block.Marks.Runs() {
+		var text strings.Builder
+		var style *extractor.TextMark
+		for _, tm := range run.Elements() {
+			if tm.Meta {
+				text.WriteString(" ")
+				continue
+			}
+			if style == nil {
+				tm := tm
+				style = &tm
+			}
+			text.WriteString(tm.Text)
+		}
+		if style == nil {
+			b.WriteString(" ")
+			continue
+		}
+		// The spaces at the ends of the run are kept out of the inline elements.
+		raw := collapseSpaces(text.String())
+		s := strings.TrimSpace(raw)
+		if s == "" {
+			b.WriteString(raw)
+			continue
+		}
+		lead, trail := raw[:strings.Index(raw, s)], raw[strings.Index(raw, s)+len(s):]
+		s = html.EscapeString(s)
+		if style.Rise > 0 {
+			s = "<sup>" + s + "</sup>"
+		} else if style.Rise < 0 {
+			s = "<sub>" + s + "</sub>"
+		}
+		if style.IsItalic() {
+			s = "<i>" + s + "</i>"
+		}
+		if style.IsBold() {
+			s = "<b>" + s + "</b>"
+		}
+		if doc.opts.Styles {
+			s = fmt.Sprintf("<span style=\"%s\">%s</span>", textStyle(*style, true), s)
+		}
+		b.WriteString(lead + s + trail)
+	}
+	return strings.TrimSpace(collapseSpaces(b.String()))
+}
+
+// writeFixedPage writes the page `p` to `w` with its text runs and images positioned as on the
+// PDF page.
+func (doc *exportDoc) writeFixedPage(w io.Writer, p *exportPage, imageSrc func(*exportImage) string) {
+	box := p.mediaBox
+	fmt.Fprintf(w, "<div class=\"page\" id=\"page-%d\" style=\"width:%spt;height:%spt\">\n",
+		p.num, formatPt(box.Width()), formatPt(box.Height()))
+	for _, block := range p.blocks {
+		if block.Type == extractor.BlockFigure {
+			fmt.Fprintf(w, "<img src=\"%s\" alt=\"\" style=\"left:%spt;top:%spt;width:%spt;height:%spt\"/>\n",
+				imageSrc(p.images[block]), formatPt(block.BBox.Llx-box.Llx), formatPt(box.Ury-block.BBox.Ury),
+				formatPt(block.BBox.Width()), formatPt(block.BBox.Height()))
+			continue
+		}
+		id := ""
+		if hid, ok := p.headings[block]; ok {
+			id = fmt.Sprintf(" id=\"%s\"", hid)
+		}
+		for _, span := range fixedSpans(block.Marks) {
+			fmt.Fprintf(w, "<span%s style=\"left:%spt;top:%spt;%s\">%s</span>\n", id,
+				formatPt(span.bbox.Llx-box.Llx), formatPt(box.Ury-span.bbox.Ury), textStyle(span.style, true),
+				html.EscapeString(span.text))
+			id = ""
+		}
+	}
+	fmt.Fprintf(w, "</div>\n")
+}
+
+// fixedSpan is a line of a text run, positioned on the page.
+type fixedSpan struct {
+	text  string
+	bbox  model.PdfRectangle
+	style extractor.TextMark
+}
+
+// fixedSpans returns the lines of the text runs of `marks`.
+func fixedSpans(marks *extractor.TextMarkArray) []fixedSpan {
+	var spans []fixedSpan
+	for _, run := range marks.Runs() {
+		var span *fixedSpan
+		var spanMarks extractor.TextMarkArray
+		var text strings.Builder
+		flush := func() {
+			if span != nil {
+				if bbox, ok := spanMarks.BBox(); ok {
+					span.bbox = bbox
+				}
+				span.text = strings.TrimRight(text.String(), " ")
+				spans = append(spans, *span)
+			}
+			span = nil
+			spanMarks = extractor.TextMarkArray{}
+			text.Reset()
+		}
+		for _, tm := range run.Elements() {
+			if tm.Meta {
+				if strings.ContainsAny(tm.Text, "\n\t") {
+					flush()
+				} else if span != nil {
+					text.WriteString(" ")
+				}
+				continue
+			}
+			if span != nil && math.Abs(tm.BBox.Lly-span.bbox.Lly) > tm.FontSize/2 {
+				flush()
+			}
+			if span == nil {
+				span = &fixedSpan{bbox: tm.BBox, style: tm}
+			}
+			spanMarks.Append(tm)
+			text.WriteString(tm.Text)
+		}
+		flush()
+	}
+	return spans
+}
+
+// writeTable writes the table `table` to `w`.
+func writeTable(w io.Writer, table *extractor.TextTable) {
+	fmt.Fprintf(w, "<table>\n")
+	for _, row := range table.Cells {
+		fmt.Fprintf(w, "<tr>")
+		for _, cell := range row {
+			fmt.Fprintf(w, "<td>%s</td>", html.EscapeString(joinLines(cell.Text)))
+		}
+		fmt.Fprintf(w, "</tr>\n")
+	}
+	fmt.Fprintf(w, "</table>\n")
+}
+
+// textStyle returns the CSS style of the text mark `tm`: font family, weight and style, color and
+// font size if `size` is true.
+func textStyle(tm extractor.TextMark, size bool) string {
+	var parts []string
+	if tm.Font != nil {
+		parts = append(parts, "font-family:"+fontFamily(tm.Font.BaseFont()))
+	}
+	if tm.IsBold() {
+		parts = append(parts, "font-weight:bold")
+	}
+	if tm.IsItalic() {
+		parts = append(parts, "font-style:italic")
+	}
+	if size && tm.FontSize > 0 {
+		parts = append(parts, "font-size:"+formatPt(tm.FontSize)+"pt")
+	}
+	if tm.FillColor != nil {
+		r, g, b, _ := tm.FillColor.RGBA()
+		if r|g|b != 0 {
+			c := color.RGBAModel.Convert(tm.FillColor).(color.RGBA)
+			parts = append(parts, fmt.Sprintf("color:#%02x%02x%02x", c.R, c.G, c.B))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// fontFamily returns the CSS font family of the font with base font name `name`, e.g.
+// "'Helvetica',sans-serif" for "ABCDEF+Helvetica-Bold".
+func fontFamily(name string) string {
+	if i := strings.IndexByte(name, '+'); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.IndexAny(name, "-,"); i > 0 {
+		name = name[:i]
+	}
+	lower := strings.ToLower(name)
+	generic := "sans-serif"
+	switch {
+	case strings.Contains(lower, "courier") || strings.Contains(lower, "mono"):
+		generic = "monospace"
+	case strings.Contains(lower, "times") || strings.Contains(lower, "serif") && !strings.Contains(lower, "sans"):
+		generic = "serif"
+	}
+	name = strings.NewReplacer("'", "", "\"", "", ";", "").Replace(name)
+	if name == "" {
+		return generic
+	}
+	return "'" + name + "'," + generic
+}
+
+// blockFontSize returns the rendered font size of most characters of the text block `block`,
+// rounded to half points.
+func blockFontSize(block *extractor.LayoutBlock) float64 {
+	sizes := map[float64]int{}
+	for _, tm := range block.Marks.Elements() {
+		if !tm.Meta {
+			sizes[math.Round(tm.FontSize*2)/2] += len(tm.Text)
+		}
+	}
+	var size float64
+	n := 0
+	for s, c := range sizes {
+		if c > n || c == n && s > size {
+			size, n = s, c
+		}
+	}
+	return size
+}
+
+// joinLines joins the lines of `text` with spaces.
+func joinLines(text string) string {
+	return strings.TrimSpace(collapseSpaces(strings.Replace(text, "\n", " ", -1)))
+}
+
+// spacesRegexp matches sequences of white space.
+var spacesRegexp = regexp.MustCompile(`\s+`)
+
+// collapseSpaces replaces the sequences of white space of `s` with single spaces.
+func collapseSpaces(s string) string {
+	return spacesRegexp.ReplaceAllString(s, " ")
+}
+
+// formatPt formats the length `v` in points with at most 2 decimals.
+func formatPt(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// minInt returns the minimum of `a` and `b`.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
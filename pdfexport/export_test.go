@@ -0,0 +1,134 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// makeExportReader returns a reader of a document with a page with a heading, a paragraph with
+// bold text, a list and an image.
+func makeExportReader(t *testing.T) *model.PdfReader {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	for name, stdName := range map[string]model.StdFontName{
+		"F1": model.HelveticaName,
+		"F2": model.HelveticaBoldName,
+	} {
+		font, err := model.NewStandard14Font(stdName)
+		require.NoError(t, err)
+		require.NoError(t, page.Resources.SetFontByName(core.PdfObjectName(name), font.ToPdfObject()))
+	}
+	contents := `BT /F2 20 Tf 72 700 Td (Introduction & scope) Tj ET
+BT /F1 10 Tf 72 660 Td 12 TL
+(Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod) Tj T*
+(tempor incididunt ut labore et dolore magna aliqua. ) Tj /F2 10 Tf (Ut enim) Tj /F1 10 Tf ( ad minim veniam,) Tj T*
+(quis nostrud exercitation ullamco laboris nisi ut aliquip ex ea commodo.) Tj ET
+BT /F1 10 Tf 72 580 Td 12 TL
+(1. First item of the list) Tj T*
+(2. Second item of the list) Tj ET
+q 100 0 0 50 72 420 cm BI /W 1 /H 1 /CS /G /BPC 8 ID A EI Q`
+	require.NoError(t, page.SetContentStreams([]string{contents}, core.NewRawEncoder()))
+
+	w := model.NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	return r
+}
+
+func TestWriteHTML(t *testing.T) {
+	r := makeExportReader(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteHTML(context.Background(), &buf, r, &Options{Title: "Test <doc>"}))
+	out := buf.String()
+	require.Contains(t, out, "<title>Test &lt;doc&gt;</title>")
+	require.Contains(t, out, `<h1 id="h1-1">Introduction &amp; scope</h1>`)
+	require.Contains(t, out, "<p>Lorem ipsum dolor sit amet")
+	require.Contains(t, out, "aliqua. <b>Ut enim</b> ad minim")
+	require.Contains(t, out, "<ol>\n<li>First item of the list</li>\n<li>Second item of the list</li>\n</ol>")
+	require.Contains(t, out, `<img src="data:image/png;base64,`)
+	require.True(t, strings.Index(out, "<h1") < strings.Index(out, "<p>"))
+
+	buf.Reset()
+	require.NoError(t, WriteHTML(context.Background(), &buf, r, &Options{Mode: ModeFixed, NoImages: true}))
+	out = buf.String()
+	require.Contains(t, out, `<div class="page" id="page-1" style="width:612pt;height:792pt">`)
+	require.Contains(t, out, `<span id="h1-1" style="left:72pt;top:`)
+	require.Contains(t, out, "font-family:'Helvetica',sans-serif;font-weight:bold;font-size:20pt")
+	require.NotContains(t, out, "<img")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Equal(t, context.Canceled, WriteHTML(ctx, &buf, r, nil))
+}
+
+func TestWriteEPUB(t *testing.T) {
+	r := makeExportReader(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteEPUB(context.Background(), &buf, r, &Options{Title: "Test"}))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var names []string
+	files := map[string]string{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	require.Equal(t, []string{"mimetype", "META-INF/container.xml", "OEBPS/content.opf", "OEBPS/nav.xhtml",
+		"OEBPS/style.css", "OEBPS/page1.xhtml", "OEBPS/images/image1.png"}, names)
+	require.Equal(t, zip.Store, zr.File[0].Method)
+	require.Equal(t, "application/epub+zip", files["mimetype"])
+
+	opf := files["OEBPS/content.opf"]
+	require.Contains(t, opf, "<dc:title>Test</dc:title>")
+	require.Contains(t, opf, "<dc:identifier id=\"uid\">urn:uuid:")
+	require.Contains(t, opf, `<itemref idref="page1"/>`)
+	require.Contains(t, opf, `href="images/image1.png" media-type="image/png"`)
+	require.NotContains(t, opf, "pre-paginated")
+	require.Contains(t, files["OEBPS/nav.xhtml"], `<li><a href="page1.xhtml#h1-1">Introduction &amp; scope</a></li>`)
+	require.Contains(t, files["OEBPS/page1.xhtml"], `<img src="images/image1.png"`)
+	require.Contains(t, files["OEBPS/page1.xhtml"], `<h1 id="h1-1">`)
+
+	buf.Reset()
+	require.NoError(t, WriteEPUB(context.Background(), &buf, r, &Options{Title: "Test", Mode: ModeFixed}))
+	zr, err = zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	rc, err := zr.File[2].Open()
+	require.NoError(t, err)
+	opf2, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	require.Contains(t, string(opf2), "<meta property=\"rendition:layout\">pre-paginated</meta>")
+	require.Contains(t, string(opf2), opf[strings.Index(opf, "urn:uuid:"):strings.Index(opf, "</dc:identifier>")])
+}
+
+func TestFontFamily(t *testing.T) {
+	require.Equal(t, "'Helvetica',sans-serif", fontFamily("ABCDEF+Helvetica-Bold"))
+	require.Equal(t, "'Times',serif", fontFamily("Times-Roman"))
+	require.Equal(t, "'Courier',monospace", fontFamily("Courier"))
+	require.Equal(t, "'DejaVuSerif',serif", fontFamily("DejaVuSerif,Italic"))
+	require.Equal(t, "sans-serif", fontFamily(""))
+}
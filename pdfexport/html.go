@@ -0,0 +1,53 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfexport
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// styleSheet is the style sheet of the exported documents.
+const styleSheet = `body { margin: 1em; line-height: 1.4; }
+figure { margin: 1em 0; }
+figure img { max-width: 100%; height: auto; }
+table { border-collapse: collapse; margin: 1em 0; }
+td { border: 1px solid #999; padding: 0.2em 0.4em; vertical-align: top; }
+p.header, p.footer { font-size: smaller; color: #666; }
+div.page { position: relative; overflow: hidden; margin: 0 auto 1em auto; }
+div.page span { position: absolute; white-space: pre; line-height: 1; }
+div.page img { position: absolute; }
+`
+
+// WriteHTML writes the document `r` to `w` as a single HTML (XHTML compatible) page, with the
+// images embedded as data URIs. `opts` may be nil for the default options. The export stops with
+// the error of `ctx` when it is cancelled or its deadline is exceeded.
+func WriteHTML(ctx context.Context, w io.Writer, r *model.PdfReader, opts *Options) error {
+	doc, err := newExportDoc(ctx, r, opts)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<!DOCTYPE html>\n<html lang=\"%s\">\n<head>\n<meta charset=\"utf-8\"/>\n", html.EscapeString(doc.lang))
+	fmt.Fprintf(bw, "<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", html.EscapeString(doc.title), styleSheet)
+	for _, p := range doc.pages {
+		doc.writePage(bw, p, dataURI)
+	}
+	fmt.Fprintf(bw, "</body>\n</html>\n")
+	return bw.Flush()
+}
+
+// dataURI returns the data URI of the image `img`.
+func dataURI(img *exportImage) string {
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(img.data)
+}
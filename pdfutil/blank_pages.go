@@ -0,0 +1,458 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// BlankPageOptions contains the options used for detecting blank pages.
+type BlankPageOptions struct {
+	// MaxInkCoverage is the maximal fraction of the page area covered by the
+	// paths painted on blank pages. The area covered by a path is
+	// approximated by its bounding box. The paths painted in white are
+	// ignored. By default, the paths painted in other colors make the pages
+	// not blank.
+	MaxInkCoverage float64
+
+	// ImageNoiseThreshold is the maximal fraction of non-white pixels of the
+	// images of blank pages, which allows ignoring the noise of scanned
+	// blank pages. By default, the images make the pages not blank and are
+	// not decoded.
+	ImageNoiseThreshold float64
+
+	// WhiteLevel is the minimal gray level, in the [0, 1] range, of the
+	// colors and pixels considered white. Defaults to 0.9.
+	WhiteLevel float64
+}
+
+// BlankPageInfo contains the result of the blank page analysis of a page.
+type BlankPageInfo struct {
+	// Blank is true if the page is blank according to the options of the
+	// analysis.
+	Blank bool
+
+	// HasText is true if the page shows visible text, which is not made of
+	// white space only.
+	HasText bool
+
+	// NumImages is the number of images painted on the page.
+	NumImages int
+
+	// InkCoverage is the fraction of the page area covered by the paths
+	// which are not painted in white.
+	InkCoverage float64
+
+	// ImageNoise is the largest fraction of non-white pixels of the images
+	// of the page. It is only computed if the ImageNoiseThreshold option is
+	// set.
+	ImageNoise float64
+}
+
+// FindBlankPages returns the page numbers of the blank pages of the document
+// read by `r`, according to the options `opts`. See AnalyzeBlankPage.
+// The pages can be removed with the Delete method of Document.
+func FindBlankPages(r *model.PdfReader, opts *BlankPageOptions) ([]int, error) {
+	if r == nil {
+		return nil, errors.New("reader cannot be nil")
+	}
+	numPages, err := r.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+
+	var blank []int
+	for i := 1; i <= numPages; i++ {
+		page, err := r.GetPage(i)
+		if err != nil {
+			return nil, err
+		}
+		info, err := AnalyzeBlankPage(page, opts)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %v", i, err)
+		}
+		if info.Blank {
+			blank = append(blank, i)
+		}
+	}
+	return blank, nil
+}
+
+// IsBlankPage returns true if the page `page` is blank according to the
+// options `opts`. See AnalyzeBlankPage.
+func IsBlankPage(page *model.PdfPage, opts *BlankPageOptions) (bool, error) {
+	info, err := AnalyzeBlankPage(page, opts)
+	if err != nil {
+		return false, err
+	}
+	return info.Blank, nil
+}
+
+// AnalyzeBlankPage analyzes the content stream of the page `page` and of the
+// Form XObjects it paints. The page is blank if it does not show text, if
+// its paths cover at most the MaxInkCoverage fraction of its area and if it
+// does not paint images, or if the images only contain noise when the
+// ImageNoiseThreshold option is set. Invisible text and white space are
+// ignored. The annotations of the page are not analyzed.
+func AnalyzeBlankPage(page *model.PdfPage, opts *BlankPageOptions) (*BlankPageInfo, error) {
+	if page == nil {
+		return nil, errors.New("page cannot be nil")
+	}
+	if opts == nil {
+		opts = &BlankPageOptions{}
+	}
+	whiteLevel := opts.WhiteLevel
+	if whiteLevel <= 0 {
+		whiteLevel = 0.9
+	}
+
+	mbox, err := page.GetMediaBox()
+	if err != nil {
+		return nil, err
+	}
+	content, err := page.GetAllContentStreams()
+	if err != nil {
+		return nil, err
+	}
+	resources := page.Resources
+	if resources == nil {
+		resources = model.NewPdfPageResources()
+	}
+
+	a := &blankPageAnalyzer{
+		opts:       opts,
+		whiteLevel: whiteLevel,
+		mbox:       mbox,
+		info:       &BlankPageInfo{},
+	}
+	if err := a.analyzeContent(content, resources, nil); err != nil {
+		return nil, err
+	}
+
+	info := a.info
+	if area := mbox.Width() * mbox.Height(); area > 0 {
+		info.InkCoverage = math.Min(a.inkArea/area, 1)
+	}
+	info.Blank = !info.HasText && info.InkCoverage <= opts.MaxInkCoverage &&
+		(info.NumImages == 0 || (opts.ImageNoiseThreshold > 0 && info.ImageNoise <= opts.ImageNoiseThreshold))
+	return info, nil
+}
+
+// blankPageAnalyzer collects the ink painted by the content streams of a page.
+type blankPageAnalyzer struct {
+	opts       *BlankPageOptions
+	whiteLevel float64
+	mbox       *model.PdfRectangle
+	info       *BlankPageInfo
+
+	// inkArea is the area of the page covered by paths, in default user
+	// space units.
+	inkArea float64
+
+	// forms walks the Form XObjects painted by the content streams.
+	forms formWalker
+}
+
+// blankTextState is the part of the graphics state which is not tracked by
+// the content stream processor.
+type blankTextState struct {
+	font       *model.PdfFont
+	renderMode int
+}
+
+// analyzeContent analyzes the content stream `content` using the resources
+// `resources`, starting with the graphics state `gs` or the default graphics
+// state if nil.
+func (a *blankPageAnalyzer) analyzeContent(content string, resources *model.PdfPageResources,
+	gs *contentstream.GraphicsState) error {
+	ops, err := contentstream.NewContentStreamParser(content).Parse()
+	if err != nil {
+		return err
+	}
+
+	var state blankTextState
+	var stack []blankTextState
+	var path []transform.Point
+
+	proc := contentstream.NewContentStreamProcessor(*ops)
+	if gs != nil {
+		proc.SetInitialGraphicsState(*gs)
+	}
+	proc.AddHandler(contentstream.HandlerConditionEnumAllOperands, "",
+		func(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState,
+			resources *model.PdfPageResources) error {
+			if a.info.HasText {
+				// The page is not blank.
+				return nil
+			}
+			vals, _ := core.GetNumbersAsFloat(op.Params)
+			switch op.Operand {
+			case "q":
+				stack = append(stack, state)
+			case "Q":
+				if len(stack) > 0 {
+					state = stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+				}
+			case "Tr":
+				if len(vals) == 1 {
+					state.renderMode = int(vals[0])
+				}
+			case "Tf":
+				state.font = nil
+				if len(op.Params) == 2 {
+					if name, ok := core.GetName(op.Params[0]); ok {
+						if obj, ok := resources.GetFontByName(*name); ok {
+							font, err := model.NewPdfFontFromPdfObject(obj)
+							if err != nil {
+								common.Log.Debug("ERROR: invalid font %s: %v", *name, err)
+							}
+							state.font = font
+						}
+					}
+				}
+			case "Tj", "'", "\"", "TJ":
+				if state.renderMode != 3 && a.showsText(op.Params, state.font) {
+					a.info.HasText = true
+				}
+			case "m", "l":
+				if len(vals) == 2 {
+					path = append(path, transform.NewPoint(vals[0], vals[1]))
+				}
+			case "c", "v", "y":
+				for i := 0; i+1 < len(vals); i += 2 {
+					path = append(path, transform.NewPoint(vals[i], vals[i+1]))
+				}
+			case "re":
+				if len(vals) == 4 {
+					x, y, w, h := vals[0], vals[1], vals[2], vals[3]
+					path = append(path, transform.NewPoint(x, y), transform.NewPoint(x+w, y+h))
+				}
+			case "f", "F", "f*":
+				a.paintPath(path, gs, true, false, 0)
+				path = nil
+			case "S", "s":
+				a.paintPath(path, gs, false, true, gs.LineWidth)
+				path = nil
+			case "B", "B*", "b", "b*":
+				a.paintPath(path, gs, true, true, gs.LineWidth)
+				path = nil
+			case "n":
+				path = nil
+			case "sh":
+				// Shadings do not have bounds: the content is considered to
+				// cover the page.
+				a.inkArea = math.Inf(1)
+			case "BI":
+				if len(op.Params) != 1 {
+					return nil
+				}
+				if iimg, ok := op.Params[0].(*contentstream.ContentStreamInlineImage); ok {
+					a.addInlineImage(iimg, resources)
+				}
+			case "Do":
+				if len(op.Params) != 1 {
+					return nil
+				}
+				name, ok := core.GetName(op.Params[0])
+				if !ok {
+					return nil
+				}
+				return a.paintXObject(*name, resources, gs)
+			}
+			return nil
+		})
+	return proc.Process(resources)
+}
+
+// showsText returns true if the text showing operator parameters `params`
+// contain characters which are not white space, when decoded with the font
+// `font`. The text is considered visible if the font is not known.
+func (a *blankPageAnalyzer) showsText(params []core.PdfObject, font *model.PdfFont) bool {
+	var strs []*core.PdfObjectString
+	for _, param := range params {
+		if str, ok := core.GetString(param); ok {
+			strs = append(strs, str)
+		} else if arr, ok := core.GetArray(param); ok {
+			for _, elem := range arr.Elements() {
+				if str, ok := core.GetString(elem); ok {
+					strs = append(strs, str)
+				}
+			}
+		}
+	}
+	for _, str := range strs {
+		data := str.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		if font == nil {
+			return true
+		}
+		text, _, _ := font.CharcodeBytesToUnicode(data)
+		if strings.TrimSpace(text) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// paintPath adds the area covered by the path `path` painted with the
+// graphics state `gs`, filled if `fill` is true and stroked with a line
+// width of `lineWidth` if `stroke` is true.
+func (a *blankPageAnalyzer) paintPath(path []transform.Point, gs contentstream.GraphicsState,
+	fill, stroke bool, lineWidth float64) {
+	if len(path) == 0 {
+		return
+	}
+	fill = fill && !a.isWhite(gs.ColorspaceNonStroking, gs.ColorNonStroking)
+	stroke = stroke && !a.isWhite(gs.ColorspaceStroking, gs.ColorStroking)
+	if !fill && !stroke {
+		return
+	}
+
+	ctm := ctmMatrix(gs.CTM)
+	llx, lly := math.Inf(1), math.Inf(1)
+	urx, ury := math.Inf(-1), math.Inf(-1)
+	for _, p := range path {
+		x, y := ctm.transform(p.X, p.Y)
+		llx, lly = math.Min(llx, x), math.Min(lly, y)
+		urx, ury = math.Max(urx, x), math.Max(ury, y)
+	}
+	if stroke {
+		// Half of the line width on each side of the path.
+		margin := lineWidth * math.Sqrt(math.Abs(gs.CTM[0]*gs.CTM[4]-gs.CTM[1]*gs.CTM[3])) / 2
+		llx, lly, urx, ury = llx-margin, lly-margin, urx+margin, ury+margin
+	}
+
+	// Clip the bounding box to the page.
+	llx, lly = math.Max(llx, a.mbox.Llx), math.Max(lly, a.mbox.Lly)
+	urx, ury = math.Min(urx, a.mbox.Urx), math.Min(ury, a.mbox.Ury)
+	if urx > llx && ury > lly {
+		a.inkArea += (urx - llx) * (ury - lly)
+	}
+}
+
+// isWhite returns true if the color `col` of the colorspace `cs` is white.
+func (a *blankPageAnalyzer) isWhite(cs model.PdfColorspace, col model.PdfColor) bool {
+	if cs == nil || col == nil {
+		return false
+	}
+	rgbColor, err := cs.ColorToRGB(col)
+	if err != nil {
+		return false
+	}
+	rgb, ok := rgbColor.(*model.PdfColorDeviceRGB)
+	if !ok {
+		return false
+	}
+	return math.Min(rgb.R(), math.Min(rgb.G(), rgb.B())) >= a.whiteLevel
+}
+
+// paintXObject analyzes the XObject `name` of the resources `resources`,
+// painted with the graphics state `gs`.
+func (a *blankPageAnalyzer) paintXObject(name core.PdfObjectName, resources *model.PdfPageResources,
+	gs contentstream.GraphicsState) error {
+	stream, xtype := resources.GetXObjectByName(name)
+	switch xtype {
+	case model.XObjectTypeImage:
+		a.info.NumImages++
+		if a.opts.ImageNoiseThreshold <= 0 {
+			return nil
+		}
+		ximg, err := model.NewXObjectImageFromStream(stream)
+		if err != nil {
+			common.Log.Debug("ERROR: invalid image %s: %v", name, err)
+			a.info.ImageNoise = 1
+			return nil
+		}
+		img, err := ximg.ToImage()
+		if err == nil && ximg.ColorSpace != nil {
+			var rgb model.Image
+			if rgb, err = ximg.ColorSpace.ImageToRGB(*img); err == nil {
+				img = &rgb
+			}
+		}
+		a.addImage(img, err)
+	case model.XObjectTypeForm:
+		return a.forms.walk(stream, resources,
+			func(content []byte, formResources *model.PdfPageResources, matrix transform.Matrix) error {
+				gs.CTM = gs.CTM.Mult(matrix)
+				return a.analyzeContent(string(content), formResources, &gs)
+			})
+	}
+	return nil
+}
+
+// addInlineImage adds the noise of the inline image `iimg`.
+func (a *blankPageAnalyzer) addInlineImage(iimg *contentstream.ContentStreamInlineImage,
+	resources *model.PdfPageResources) {
+	a.info.NumImages++
+	if a.opts.ImageNoiseThreshold <= 0 {
+		return
+	}
+	img, err := iimg.ToImage(resources)
+	if err == nil {
+		if isMask, _ := iimg.IsMask(); !isMask {
+			var cs model.PdfColorspace
+			if cs, err = iimg.GetColorSpace(resources); err == nil {
+				var rgb model.Image
+				if rgb, err = cs.ImageToRGB(*img); err == nil {
+					img = &rgb
+				}
+			}
+		}
+	}
+	a.addImage(img, err)
+}
+
+// addImage adds the noise of the image `img`, which could not be decoded if
+// `err` is not nil.
+func (a *blankPageAnalyzer) addImage(img *model.Image, err error) {
+	var noise float64
+	if err == nil {
+		noise, err = a.imageNoise(img)
+	}
+	if err != nil {
+		common.Log.Debug("ERROR: could not decode image: %v", err)
+		noise = 1
+	}
+	a.info.ImageNoise = math.Max(a.info.ImageNoise, noise)
+}
+
+// imageNoise returns the fraction of the pixels of the image `img` which are
+// not white.
+func (a *blankPageAnalyzer) imageNoise(img *model.Image) (float64, error) {
+	goimg, err := img.ToGoImage()
+	if err != nil {
+		return 0, err
+	}
+	bounds := goimg.Bounds()
+	numPixels := bounds.Dx() * bounds.Dy()
+	if numPixels == 0 {
+		return 0, nil
+	}
+	minGray := uint8(math.Ceil(a.whiteLevel * 255))
+	var dark int
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if color.GrayModel.Convert(goimg.At(x, y)).(color.Gray).Y < minGray {
+				dark++
+			}
+		}
+	}
+	return float64(dark) / float64(numPixels), nil
+}
@@ -0,0 +1,83 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestAnalyzeBlankPage(t *testing.T) {
+	font, err := model.NewStandard14Font(model.HelveticaName)
+	require.NoError(t, err)
+
+	// newPage returns a page with the content `content`.
+	newPage := func(content string) *model.PdfPage {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Urx: 100, Ury: 100}
+		require.NoError(t, page.Resources.SetFontByName("F1", font.ToPdfObject()))
+		require.NoError(t, page.AddContentStreamByString(content))
+		return page
+	}
+	analyze := func(page *model.PdfPage, opts *BlankPageOptions) *BlankPageInfo {
+		info, err := AnalyzeBlankPage(page, opts)
+		require.NoError(t, err)
+		return info
+	}
+
+	// White paths, white space and invisible text.
+	info := analyze(newPage("1 g 0 0 100 100 re f BT /F1 12 Tf (   ) Tj 3 Tr [(Hidden)] TJ ET"), nil)
+	require.True(t, info.Blank)
+	require.False(t, info.HasText)
+	require.Zero(t, info.InkCoverage)
+
+	info = analyze(newPage("BT /F1 12 Tf 10 10 Td (Text) Tj ET"), nil)
+	require.False(t, info.Blank)
+	require.True(t, info.HasText)
+
+	// A rule covering 2% of the page, drawn in a scaled coordinate system.
+	page := newPage("q 10 0 0 10 0 0 cm 0 G 0.2 w 0 5 m 10 5 l S Q")
+	info = analyze(page, nil)
+	require.False(t, info.Blank)
+	require.InDelta(t, 0.02, info.InkCoverage, 1e-9)
+	info = analyze(page, &BlankPageOptions{MaxInkCoverage: 0.05})
+	require.True(t, info.Blank)
+
+	// Text in a Form XObject.
+	page = newPage("q 0.5 0 0 0.5 0 0 cm /Fm1 Do Q")
+	form := model.NewXObjectForm()
+	form.Resources = page.Resources
+	require.NoError(t, form.SetContentStream([]byte("BT /F1 12 Tf (Form) Tj ET"), nil))
+	require.NoError(t, page.Resources.SetXObjectFormByName("Fm1", form))
+	require.False(t, analyze(page, nil).Blank)
+
+	// Scanned page with a few dark pixels.
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = 250
+	}
+	data[42], data[57] = 0, 30
+	img := &model.Image{Width: 10, Height: 10, BitsPerComponent: 8, ColorComponents: 1, Data: data}
+	ximg, err := model.NewXObjectImageFromImage(img, nil, core.NewRawEncoder())
+	require.NoError(t, err)
+	page = newPage("q 100 0 0 100 0 0 cm /Im1 Do Q")
+	require.NoError(t, page.Resources.SetXObjectImageByName("Im1", ximg))
+	info = analyze(page, nil)
+	require.False(t, info.Blank)
+	require.Equal(t, 1, info.NumImages)
+	require.Zero(t, info.ImageNoise)
+
+	info = analyze(page, &BlankPageOptions{ImageNoiseThreshold: 0.05})
+	require.True(t, info.Blank)
+	require.InDelta(t, 0.02, info.ImageNoise, 1e-9)
+	blank, err := IsBlankPage(page, &BlankPageOptions{ImageNoiseThreshold: 0.01})
+	require.NoError(t, err)
+	require.False(t, blank)
+}
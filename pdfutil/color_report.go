@@ -0,0 +1,534 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// maxColorAuditDepth is the maximum nesting level of the Form XObjects and
+// tiling patterns which are audited, which prevents infinite recursion in
+// invalid files.
+const maxColorAuditDepth = 16
+
+// ColorModel represents the kind of colors of a color space.
+type ColorModel int
+
+// Color models.
+const (
+	ColorModelOther ColorModel = iota // Patterns and unknown color spaces.
+	ColorModelGray
+	ColorModelRGB
+	ColorModelCMYK
+	ColorModelLab
+	ColorModelSpot // Separation and DeviceN color spaces.
+)
+
+// String returns the name of the color model, e.g. "CMYK".
+func (m ColorModel) String() string {
+	switch m {
+	case ColorModelGray:
+		return "Gray"
+	case ColorModelRGB:
+		return "RGB"
+	case ColorModelCMYK:
+		return "CMYK"
+	case ColorModelLab:
+		return "Lab"
+	case ColorModelSpot:
+		return "Spot"
+	}
+	return "Other"
+}
+
+// ICCProfileInfo describes an ICC profile embedded in a document, either in
+// ICCBased color spaces or in output intents.
+type ICCProfileInfo struct {
+	// Description is the description of the profile, e.g. "Coated FOGRA39".
+	Description string
+
+	// ColorSpace is the data color space signature of the profile, e.g.
+	// model.ICCColorSpaceCMYK. Empty if the profile could not be parsed.
+	ColorSpace string
+
+	// DeviceClass is the device class signature of the profile, e.g.
+	// model.ICCClassOutput. Empty if the profile could not be parsed.
+	DeviceClass string
+
+	// Version is the profile version, e.g. "2.1.0".
+	Version string
+
+	// OutputIntent is the output condition identifier of the output intent
+	// embedding the profile. Empty for the profiles only used by color
+	// spaces.
+	OutputIntent string
+
+	// Data contains the raw profile data.
+	Data []byte
+
+	// Profile is the parsed profile, nil if the profile is invalid.
+	Profile *model.ICCProfile
+}
+
+// ColorspaceUsage describes the use of a color space by an object of a page.
+type ColorspaceUsage struct {
+	// Page is the number of the page, starting from 1.
+	Page int
+
+	// Object describes the object using the color space: "Content" for the
+	// color operators of content streams, "Inline image", "Image /Im1",
+	// "Shading /Sh1", "Pattern /P1" or "Group" (transparency group color
+	// space). Objects of Form XObjects and tiling patterns are prefixed by
+	// their path, e.g. "Form /Fm1 > Image /Im2".
+	Object string
+
+	// ObjectNumber is the object number of the indirect images, shadings and
+	// patterns, 0 otherwise.
+	ObjectNumber int64
+
+	// Family is the color space family, e.g. "DeviceRGB" or "ICCBased".
+	Family string
+
+	// Model is the color model of the color space. The model of Indexed
+	// color spaces is the model of their base color space.
+	Model ColorModel
+
+	// Profile is the ICC profile of ICCBased color spaces, and of the base or
+	// alternate color spaces of Indexed, Separation and DeviceN color spaces.
+	// Nil if the color space does not use an ICC profile.
+	Profile *ICCProfileInfo
+}
+
+// ColorReport describes the color spaces and the ICC profiles used by a
+// document.
+type ColorReport struct {
+	// Usages lists the color spaces used by the objects of each page. Each
+	// color space is listed once per object.
+	Usages []ColorspaceUsage
+
+	// Profiles lists the ICC profiles embedded in the document, once per
+	// distinct profile data.
+	Profiles []*ICCProfileInfo
+
+	// MixedRGBCMYK specifies whether the document uses both RGB and CMYK
+	// colors.
+	MixedRGBCMYK bool
+
+	// MixedPages lists the numbers of the pages using both RGB and CMYK
+	// colors.
+	MixedPages []int
+}
+
+// String returns a multi-line description of the color report.
+func (r *ColorReport) String() string {
+	var b strings.Builder
+	for _, u := range r.Usages {
+		fmt.Fprintf(&b, "Page %d: %s: %s (%s)", u.Page, u.Object, u.Family, u.Model)
+		if u.Profile != nil && u.Profile.Description != "" {
+			fmt.Fprintf(&b, " %q", u.Profile.Description)
+		}
+		b.WriteString("\n")
+	}
+	for _, p := range r.Profiles {
+		fmt.Fprintf(&b, "ICC profile: %q %s %s %d bytes", p.Description, strings.TrimSpace(p.ColorSpace),
+			p.DeviceClass, len(p.Data))
+		if p.OutputIntent != "" {
+			fmt.Fprintf(&b, " (output intent %s)", p.OutputIntent)
+		}
+		b.WriteString("\n")
+	}
+	if r.MixedRGBCMYK {
+		b.WriteString("Mixed RGB and CMYK colors")
+		if len(r.MixedPages) > 0 {
+			pages := make([]string, len(r.MixedPages))
+			for i, page := range r.MixedPages {
+				pages[i] = fmt.Sprint(page)
+			}
+			fmt.Fprintf(&b, " on pages %s", strings.Join(pages, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// AuditColors returns the report of the color spaces used by the pages of the
+// document read by `r` and of its embedded ICC profiles. The content streams,
+// images, shadings, patterns, Form XObjects and transparency groups of the
+// pages are audited, as well as the output intents of the document.
+func AuditColors(r *model.PdfReader) (*ColorReport, error) {
+	if r == nil {
+		return nil, errors.New("reader cannot be nil")
+	}
+	a := newColorAuditor()
+
+	intents, err := r.GetOutputIntents()
+	if err != nil {
+		return nil, err
+	}
+	for _, intent := range intents {
+		if intent.DestOutputProfile == nil {
+			continue
+		}
+		info := a.profileInfo(intent.DestOutputProfile.Data)
+		if info.OutputIntent == "" {
+			info.OutputIntent = intent.OutputConditionIdentifier
+		}
+	}
+
+	numPages, err := r.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i <= numPages; i++ {
+		page, err := r.GetPage(i)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.auditPage(page, i); err != nil {
+			return nil, fmt.Errorf("page %d: %v", i, err)
+		}
+	}
+	return a.finish(), nil
+}
+
+// AuditPageColors returns the report of the color spaces used by the page
+// `page`, numbered 1. See AuditColors.
+func AuditPageColors(page *model.PdfPage) (*ColorReport, error) {
+	if page == nil {
+		return nil, errors.New("page cannot be nil")
+	}
+	a := newColorAuditor()
+	if err := a.auditPage(page, 1); err != nil {
+		return nil, err
+	}
+	return a.finish(), nil
+}
+
+// colorAuditor collects the color spaces used by pages.
+type colorAuditor struct {
+	report   *ColorReport
+	page     int
+	profiles map[[sha256.Size]byte]*ICCProfileInfo
+
+	// usages contains the keys of the usages of the current page, so that
+	// each color space is listed once per object.
+	usages map[string]struct{}
+
+	// forms walks the Form XObjects and tiling patterns of the current page.
+	forms formWalker
+}
+
+// newColorAuditor returns a new color auditor.
+func newColorAuditor() *colorAuditor {
+	return &colorAuditor{
+		report:   &ColorReport{},
+		profiles: map[[sha256.Size]byte]*ICCProfileInfo{},
+	}
+}
+
+// finish returns the report, with the mixed color usage set.
+func (a *colorAuditor) finish() *ColorReport {
+	rgbPages := map[int]bool{}
+	cmykPages := map[int]bool{}
+	for _, u := range a.report.Usages {
+		switch u.Model {
+		case ColorModelRGB:
+			rgbPages[u.Page] = true
+		case ColorModelCMYK:
+			cmykPages[u.Page] = true
+		}
+	}
+	var lastPage int
+	for _, u := range a.report.Usages {
+		if u.Page != lastPage && rgbPages[u.Page] && cmykPages[u.Page] {
+			a.report.MixedPages = append(a.report.MixedPages, u.Page)
+		}
+		lastPage = u.Page
+	}
+	a.report.MixedRGBCMYK = len(rgbPages) > 0 && len(cmykPages) > 0
+	return a.report
+}
+
+// profileInfo returns the description of the ICC profile `data`, added to the
+// report profiles the first time it is used.
+func (a *colorAuditor) profileInfo(data []byte) *ICCProfileInfo {
+	key := sha256.Sum256(data)
+	if info, ok := a.profiles[key]; ok {
+		return info
+	}
+	info := &ICCProfileInfo{Data: data}
+	if profile, err := model.NewICCProfile(data); err == nil {
+		info.Profile = profile
+		info.Description = profile.Description
+		info.ColorSpace = profile.ColorSpace
+		info.DeviceClass = profile.DeviceClass
+		info.Version = profile.Version
+	} else {
+		common.Log.Debug("ERROR: invalid ICC profile: %v", err)
+	}
+	a.profiles[key] = info
+	a.report.Profiles = append(a.report.Profiles, info)
+	return info
+}
+
+// add adds the usage of the color space `cs` by the object `object`, of
+// object number `objNum`.
+func (a *colorAuditor) add(object string, objNum int64, cs model.PdfColorspace) {
+	if cs == nil {
+		return
+	}
+	u := ColorspaceUsage{
+		Page:         a.page,
+		Object:       object,
+		ObjectNumber: objNum,
+		Family:       cs.String(),
+		Model:        colorModel(cs),
+	}
+	if data := iccData(cs); len(data) > 0 {
+		u.Profile = a.profileInfo(data)
+	}
+
+	key := fmt.Sprintf("%s|%d|%s|%p", object, objNum, u.Family, u.Profile)
+	if _, ok := a.usages[key]; ok {
+		return
+	}
+	a.usages[key] = struct{}{}
+	a.report.Usages = append(a.report.Usages, u)
+}
+
+// addObject adds the usage of the color space object `obj`, e.g. the
+// ColorSpace entry of an image.
+func (a *colorAuditor) addObject(object string, objNum int64, obj core.PdfObject) {
+	if obj == nil {
+		return
+	}
+	cs, err := model.NewPdfColorspaceFromPdfObject(obj)
+	if err != nil {
+		common.Log.Debug("ERROR: invalid color space of %s: %v", object, err)
+		return
+	}
+	a.add(object, objNum, cs)
+}
+
+// auditPage adds the color spaces used by the page `page`, numbered `pageNum`.
+func (a *colorAuditor) auditPage(page *model.PdfPage, pageNum int) error {
+	a.page = pageNum
+	a.usages = map[string]struct{}{}
+	a.forms = formWalker{}
+
+	content, err := page.GetAllContentStreams()
+	if err != nil {
+		return err
+	}
+	resources := page.Resources
+	if resources == nil {
+		resources = model.NewPdfPageResources()
+	}
+	if err := a.auditContent(content, resources, ""); err != nil {
+		return err
+	}
+	a.auditResources(resources, "", 0)
+	a.auditGroup(page.Group, "")
+	return nil
+}
+
+// auditContent adds the color spaces used by the color operators and the
+// inline images of the content stream `content`, of the object with the
+// path `prefix`.
+func (a *colorAuditor) auditContent(content string, resources *model.PdfPageResources, prefix string) error {
+	ops, err := contentstream.NewContentStreamParser(content).Parse()
+	if err != nil {
+		return err
+	}
+	for _, op := range *ops {
+		object := prefix + "Content"
+		switch op.Operand {
+		case "g", "G":
+			a.add(object, 0, model.NewPdfColorspaceDeviceGray())
+		case "rg", "RG":
+			a.add(object, 0, model.NewPdfColorspaceDeviceRGB())
+		case "k", "K":
+			a.add(object, 0, model.NewPdfColorspaceDeviceCMYK())
+		case "cs", "CS":
+			if len(op.Params) != 1 {
+				continue
+			}
+			name, ok := core.GetName(op.Params[0])
+			if !ok {
+				continue
+			}
+			if cs, ok := resources.GetColorspaceByName(*name); ok {
+				a.add(object, 0, cs)
+			} else {
+				a.addObject(object, 0, name)
+			}
+		case "BI":
+			if len(op.Params) != 1 {
+				continue
+			}
+			iimg, ok := op.Params[0].(*contentstream.ContentStreamInlineImage)
+			if !ok {
+				continue
+			}
+			if isMask, ok := core.GetBoolVal(iimg.ImageMask); ok && isMask {
+				continue
+			}
+			cs, err := iimg.GetColorSpace(resources)
+			if err != nil {
+				common.Log.Debug("ERROR: invalid inline image color space: %v", err)
+				continue
+			}
+			a.add(prefix+"Inline image", 0, cs)
+		}
+	}
+	return nil
+}
+
+// auditResources adds the color spaces used by the images, shadings,
+// patterns and Form XObjects of the resources `resources`, of the object with
+// the path `prefix` nested at depth `depth`.
+func (a *colorAuditor) auditResources(resources *model.PdfPageResources, prefix string, depth int) {
+	if xobjects, ok := core.GetDict(resources.XObject); ok {
+		for _, name := range xobjects.Keys() {
+			stream, ok := core.GetStream(xobjects.Get(name))
+			if !ok {
+				continue
+			}
+			switch subtype, _ := core.GetNameVal(stream.Get("Subtype")); subtype {
+			case "Image":
+				a.addObject(fmt.Sprintf("%sImage /%s", prefix, name), stream.ObjectNumber, stream.Get("ColorSpace"))
+			case "Form":
+				a.auditForm(stream, fmt.Sprintf("%sForm /%s > ", prefix, name), resources, depth)
+			}
+		}
+	}
+
+	if shadings, ok := core.GetDict(resources.Shading); ok {
+		for _, name := range shadings.Keys() {
+			object := fmt.Sprintf("%sShading /%s", prefix, name)
+			a.auditShading(shadings.Get(name), object)
+		}
+	}
+
+	if patterns, ok := core.GetDict(resources.Pattern); ok {
+		for _, name := range patterns.Keys() {
+			obj := patterns.Get(name)
+			object := fmt.Sprintf("%sPattern /%s", prefix, name)
+			if stream, ok := core.GetStream(obj); ok {
+				// Tiling pattern.
+				a.auditForm(stream, object+" > ", resources, depth)
+			} else if dict, ok := core.GetDict(obj); ok {
+				// Shading pattern.
+				a.auditShading(dict.Get("Shading"), object)
+			}
+		}
+	}
+}
+
+// auditShading adds the color space of the shading `obj`, used by the object
+// `object`.
+func (a *colorAuditor) auditShading(obj core.PdfObject, object string) {
+	var dict *core.PdfObjectDictionary
+	var objNum int64
+	switch t := core.ResolveReference(obj).(type) {
+	case *core.PdfObjectStream:
+		dict, objNum = t.PdfObjectDictionary, t.ObjectNumber
+	case *core.PdfIndirectObject:
+		dict, _ = core.GetDict(t.PdfObject)
+		objNum = t.ObjectNumber
+	case *core.PdfObjectDictionary:
+		dict = t
+	}
+	if dict != nil {
+		a.addObject(object, objNum, dict.Get("ColorSpace"))
+	}
+}
+
+// auditForm adds the color spaces used by the Form XObject or tiling pattern
+// `stream`, of path `prefix`, drawn with the resources `parent`.
+func (a *colorAuditor) auditForm(stream *core.PdfObjectStream, prefix string,
+	parent *model.PdfPageResources, depth int) {
+	if depth >= maxColorAuditDepth {
+		return
+	}
+	err := a.forms.walk(stream, parent,
+		func(content []byte, resources *model.PdfPageResources, _ transform.Matrix) error {
+			if err := a.auditContent(string(content), resources, prefix); err != nil {
+				common.Log.Debug("ERROR: invalid content of %s: %v", strings.TrimSuffix(prefix, " > "), err)
+			}
+			// Forms without resources use the resources of the parent,
+			// which are audited with the parent.
+			if resources != parent {
+				a.auditResources(resources, prefix, depth+1)
+			}
+			a.auditGroup(stream.Get("Group"), prefix)
+			return nil
+		})
+	if err != nil {
+		common.Log.Debug("ERROR: invalid %s: %v", strings.TrimSuffix(prefix, " > "), err)
+	}
+}
+
+// auditGroup adds the color space of the transparency group `obj`, of the
+// object with the path `prefix`.
+func (a *colorAuditor) auditGroup(obj core.PdfObject, prefix string) {
+	if group, ok := core.GetDict(obj); ok {
+		a.addObject(prefix+"Group", 0, group.Get("CS"))
+	}
+}
+
+// colorModel returns the color model of the color space `cs`.
+func colorModel(cs model.PdfColorspace) ColorModel {
+	switch t := cs.(type) {
+	case *model.PdfColorspaceDeviceGray, *model.PdfColorspaceCalGray:
+		return ColorModelGray
+	case *model.PdfColorspaceDeviceRGB, *model.PdfColorspaceCalRGB:
+		return ColorModelRGB
+	case *model.PdfColorspaceDeviceCMYK:
+		return ColorModelCMYK
+	case *model.PdfColorspaceLab:
+		return ColorModelLab
+	case *model.PdfColorspaceICCBased:
+		switch t.N {
+		case 1:
+			return ColorModelGray
+		case 3:
+			return ColorModelRGB
+		case 4:
+			return ColorModelCMYK
+		}
+	case *model.PdfColorspaceSpecialIndexed:
+		return colorModel(t.Base)
+	case *model.PdfColorspaceSpecialSeparation, *model.PdfColorspaceDeviceN:
+		return ColorModelSpot
+	}
+	return ColorModelOther
+}
+
+// iccData returns the ICC profile data of the color space `cs`, or of its
+// base or alternate color space. Returns nil if it does not use a profile.
+func iccData(cs model.PdfColorspace) []byte {
+	switch t := cs.(type) {
+	case *model.PdfColorspaceICCBased:
+		return t.Data
+	case *model.PdfColorspaceSpecialIndexed:
+		return iccData(t.Base)
+	case *model.PdfColorspaceSpecialSeparation:
+		return iccData(t.AlternateSpace)
+	case *model.PdfColorspaceDeviceN:
+		return iccData(t.AlternateSpace)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestAuditPageColors(t *testing.T) {
+	page := model.NewPdfPage()
+	page.Resources = model.NewPdfPageResources()
+
+	// ICC based RGB color space.
+	profile, err := model.NewICCProfileSRGB()
+	require.NoError(t, err)
+	iccCS, err := model.NewPdfColorspaceICCBasedFromProfile(profile)
+	require.NoError(t, err)
+	require.NoError(t, page.Resources.SetColorspaceByName("CS0", iccCS))
+
+	// CMYK image.
+	img := &model.Image{Width: 1, Height: 1, BitsPerComponent: 8, ColorComponents: 4, Data: []byte{0, 0, 0, 255}}
+	ximg, err := model.NewXObjectImageFromImage(img, nil, core.NewRawEncoder())
+	require.NoError(t, err)
+	require.NoError(t, page.Resources.SetXObjectImageByName("Im1", ximg))
+
+	// Form XObject in gray.
+	form := model.NewXObjectForm()
+	require.NoError(t, form.SetContentStream([]byte("0.5 g 0 0 5 5 re f"), nil))
+	require.NoError(t, page.Resources.SetXObjectFormByName("Fm1", form))
+
+	content := "1 0 0 rg 0 0 10 10 re f 0 0 1 rg /CS0 cs 1 0 0 sc /Im1 Do /Fm1 Do"
+	require.NoError(t, page.AddContentStreamByString(content))
+
+	report, err := AuditPageColors(page)
+	require.NoError(t, err)
+	require.Len(t, report.Usages, 4)
+	require.Equal(t, ColorspaceUsage{Page: 1, Object: "Content", Family: "DeviceRGB", Model: ColorModelRGB},
+		report.Usages[0])
+	require.Equal(t, "ICCBased", report.Usages[1].Family)
+	require.Equal(t, ColorModelRGB, report.Usages[1].Model)
+	require.NotNil(t, report.Usages[1].Profile)
+	require.Equal(t, "Image /Im1", report.Usages[2].Object)
+	require.Equal(t, ColorModelCMYK, report.Usages[2].Model)
+	require.Equal(t, ColorspaceUsage{Page: 1, Object: "Form /Fm1 > Content", Family: "DeviceGray",
+		Model: ColorModelGray}, report.Usages[3])
+
+	require.Len(t, report.Profiles, 1)
+	require.Equal(t, "sRGB IEC61966-2.1", report.Profiles[0].Description)
+	require.Equal(t, model.ICCColorSpaceRGB, report.Profiles[0].ColorSpace)
+	require.True(t, report.MixedRGBCMYK)
+	require.Equal(t, []int{1}, report.MixedPages)
+	require.Contains(t, report.String(), "Mixed RGB and CMYK colors on pages 1")
+}
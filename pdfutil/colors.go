@@ -0,0 +1,818 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// ColorTarget represents the target color space of color conversions.
+type ColorTarget int
+
+// Color conversion targets.
+const (
+	ColorTargetGray ColorTarget = iota
+	ColorTargetRGB
+	ColorTargetCMYK
+)
+
+// ColorConversionOptions contains the options used for converting the colors
+// of documents.
+type ColorConversionOptions struct {
+	// Target is the target color space.
+	Target ColorTarget
+
+	// ConvertRGB converts RGB color components, in the [0, 1] range, to the
+	// components of the target color space. It allows using ICC profile based
+	// conversions. By default, gray levels are computed from the luminance of
+	// the colors and CMYK colors are computed using the maximal black
+	// component. The colors are first converted to RGB: ICC based colors are
+	// converted using their alternate color space and spot colors using the
+	// tint transforms of their alternate color space.
+	ConvertRGB func(r, g, b float64) []float64
+
+	// SkipImages leaves the images unchanged.
+	SkipImages bool
+
+	// SkipAnnotations leaves the annotation colors and appearances unchanged.
+	SkipAnnotations bool
+}
+
+// ConvertColors converts the colors of all the pages of the document read by
+// `r` to the target color space specified by `opts`, in place. The content
+// streams, images, Form XObjects, patterns, shadings and annotations of the
+// pages are converted, as well as the default appearances of the interactive
+// form fields. The objects shared by several pages are converted once.
+// The converted document can be written using a Document or a PdfWriter.
+func ConvertColors(r *model.PdfReader, opts *ColorConversionOptions) error {
+	if r == nil {
+		return errors.New("reader cannot be nil")
+	}
+	conv, err := newColorConverter(opts)
+	if err != nil {
+		return err
+	}
+
+	numPages, err := r.GetNumPages()
+	if err != nil {
+		return err
+	}
+	for i := 1; i <= numPages; i++ {
+		page, err := r.GetPage(i)
+		if err != nil {
+			return err
+		}
+		if err := conv.convertPage(page); err != nil {
+			return fmt.Errorf("page %d: %v", i, err)
+		}
+	}
+
+	if r.AcroForm != nil && !conv.opts.SkipAnnotations {
+		conv.convertAcroForm(r.AcroForm)
+	}
+	return nil
+}
+
+// ConvertPageColors converts the colors of the page `page` to the target
+// color space specified by `opts`, in place. See ConvertColors.
+func ConvertPageColors(page *model.PdfPage, opts *ColorConversionOptions) error {
+	if page == nil {
+		return errors.New("page cannot be nil")
+	}
+	conv, err := newColorConverter(opts)
+	if err != nil {
+		return err
+	}
+	return conv.convertPage(page)
+}
+
+// colorConverter converts colors to a target color space.
+type colorConverter struct {
+	opts   *ColorConversionOptions
+	target model.PdfColorspace
+
+	// converted contains the converted objects, so that shared objects are
+	// converted once and reference cycles are not followed.
+	converted map[core.PdfObject]struct{}
+}
+
+// newColorConverter returns a color converter using the options `opts`.
+func newColorConverter(opts *ColorConversionOptions) (*colorConverter, error) {
+	if opts == nil {
+		opts = &ColorConversionOptions{}
+	}
+	conv := &colorConverter{
+		opts:      opts,
+		converted: map[core.PdfObject]struct{}{},
+	}
+	switch opts.Target {
+	case ColorTargetGray:
+		conv.target = model.NewPdfColorspaceDeviceGray()
+	case ColorTargetRGB:
+		conv.target = model.NewPdfColorspaceDeviceRGB()
+	case ColorTargetCMYK:
+		conv.target = model.NewPdfColorspaceDeviceCMYK()
+	default:
+		return nil, fmt.Errorf("invalid color target %d", opts.Target)
+	}
+	return conv, nil
+}
+
+// visit returns true if the object `obj` was not converted yet, and marks it
+// as converted.
+func (c *colorConverter) visit(obj core.PdfObject) bool {
+	obj = core.ResolveReference(obj)
+	if _, ok := c.converted[obj]; ok {
+		return false
+	}
+	c.converted[obj] = struct{}{}
+	return true
+}
+
+// fromRGB returns the components in the target color space of the RGB color
+// `r`, `g`, `b`.
+func (c *colorConverter) fromRGB(r, g, b float64) []float64 {
+	if c.opts.ConvertRGB != nil {
+		return c.opts.ConvertRGB(r, g, b)
+	}
+
+	switch c.opts.Target {
+	case ColorTargetGray:
+		return []float64{model.NewPdfColorDeviceRGB(r, g, b).ToGray().Val()}
+	case ColorTargetCMYK:
+		k := 1 - math.Max(r, math.Max(g, b))
+		if k >= 1 {
+			return []float64{0, 0, 0, 1}
+		}
+		return []float64{(1 - r - k) / (1 - k), (1 - g - k) / (1 - k), (1 - b - k) / (1 - k), k}
+	}
+	return []float64{r, g, b}
+}
+
+// convertColor returns the components in the target color space of the color
+// `color` of the color space `cs`.
+func (c *colorConverter) convertColor(cs model.PdfColorspace, color model.PdfColor) ([]float64, error) {
+	if cs == nil || color == nil {
+		return nil, errors.New("undefined color")
+	}
+	rgbColor, err := cs.ColorToRGB(color)
+	if err != nil {
+		return nil, err
+	}
+	rgb, ok := rgbColor.(*model.PdfColorDeviceRGB)
+	if !ok {
+		return nil, errors.New("type check error")
+	}
+	return c.fromRGB(rgb.R(), rgb.G(), rgb.B()), nil
+}
+
+// convertComponents converts the color components `vals` of a device color
+// space, determined by the number of components.
+func (c *colorConverter) convertComponents(vals []float64) ([]float64, error) {
+	var cs model.PdfColorspace
+	switch len(vals) {
+	case 1:
+		cs = model.NewPdfColorspaceDeviceGray()
+	case 3:
+		cs = model.NewPdfColorspaceDeviceRGB()
+	case 4:
+		cs = model.NewPdfColorspaceDeviceCMYK()
+	default:
+		return nil, fmt.Errorf("invalid number of color components: %d", len(vals))
+	}
+	color, err := cs.ColorFromFloats(vals)
+	if err != nil {
+		return nil, err
+	}
+	return c.convertColor(cs, color)
+}
+
+// convertColorArray returns the conversion of the array of device color
+// components `obj`. Empty arrays, specifying transparent colors, and invalid
+// objects are returned unchanged.
+func (c *colorConverter) convertColorArray(obj core.PdfObject) core.PdfObject {
+	arr, ok := core.GetArray(obj)
+	if !ok || arr.Len() == 0 {
+		return obj
+	}
+	vals, err := arr.ToFloat64Array()
+	if err != nil {
+		return obj
+	}
+	converted, err := c.convertComponents(vals)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to convert color array: %v", err)
+		return obj
+	}
+	return core.MakeArrayFromFloats(converted)
+}
+
+// colorOperation returns the operation setting the stroking or non-stroking
+// color with the components `vals` in the target color space.
+func (c *colorConverter) colorOperation(stroking bool, vals []float64) *contentstream.ContentStreamOperation {
+	operands := map[ColorTarget][2]string{
+		ColorTargetGray: {"g", "G"},
+		ColorTargetRGB:  {"rg", "RG"},
+		ColorTargetCMYK: {"k", "K"},
+	}[c.opts.Target]
+	operand := operands[0]
+	if stroking {
+		operand = operands[1]
+	}
+
+	params := make([]core.PdfObject, len(vals))
+	for i, val := range vals {
+		params[i] = core.MakeFloat(val)
+	}
+	return &contentstream.ContentStreamOperation{Operand: operand, Params: params}
+}
+
+// convertPage converts the colors of the page `page`.
+func (c *colorConverter) convertPage(page *model.PdfPage) error {
+	content, err := page.GetAllContentStreams()
+	if err != nil {
+		return err
+	}
+	converted, err := c.convertContent(content, page.Resources)
+	if err != nil {
+		return err
+	}
+	err = page.SetContentStreams([]string{converted}, core.NewFlateEncoder())
+	if err != nil {
+		return err
+	}
+	if err := c.convertResources(page.Resources); err != nil {
+		return err
+	}
+	c.convertGroup(page.Group)
+
+	if c.opts.SkipAnnotations {
+		return nil
+	}
+	annots, err := page.GetAnnotations()
+	if err != nil {
+		return err
+	}
+	for _, annot := range annots {
+		if err := c.convertAnnotation(annot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertContent returns the content stream `content`, using the resources
+// `resources`, with its color operations and inline images converted.
+func (c *colorConverter) convertContent(content string, resources *model.PdfPageResources) (string, error) {
+	ops, err := contentstream.NewContentStreamParser(content).Parse()
+	if err != nil {
+		return "", err
+	}
+	if resources == nil {
+		resources = model.NewPdfPageResources()
+	}
+
+	proc := contentstream.NewContentStreamProcessor(*ops)
+	proc.AddRewriteHandler(contentstream.HandlerConditionEnumAllOperands, "",
+		func(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState,
+			resources *model.PdfPageResources) ([]*contentstream.ContentStreamOperation, error) {
+			var stroking bool
+			switch op.Operand {
+			case "CS", "SC", "SCN", "G", "RG", "K":
+				stroking = true
+			case "cs", "sc", "scn", "g", "rg", "k":
+			case "BI":
+				return c.convertInlineImage(op, resources), nil
+			default:
+				return []*contentstream.ContentStreamOperation{op}, nil
+			}
+
+			// Replace the color operations by operations setting the
+			// converted color in the target color space, using the color
+			// tracked by the processor. Patterns are left unchanged.
+			cs, color := gs.ColorspaceNonStroking, gs.ColorNonStroking
+			if stroking {
+				cs, color = gs.ColorspaceStroking, gs.ColorStroking
+			}
+			if _, ok := cs.(*model.PdfColorspaceSpecialPattern); ok {
+				return []*contentstream.ContentStreamOperation{op}, nil
+			}
+			vals, err := c.convertColor(cs, color)
+			if err != nil {
+				return nil, err
+			}
+			return []*contentstream.ContentStreamOperation{c.colorOperation(stroking, vals)}, nil
+		})
+	converted, err := proc.Rewrite(resources)
+	if err != nil {
+		return "", err
+	}
+	return string(converted.Bytes()), nil
+}
+
+// convertInlineImage returns the operations drawing the converted inline
+// image drawn by `op`. The operation is returned unchanged if the image
+// cannot be converted.
+func (c *colorConverter) convertInlineImage(op *contentstream.ContentStreamOperation,
+	resources *model.PdfPageResources) []*contentstream.ContentStreamOperation {
+	keep := []*contentstream.ContentStreamOperation{op}
+	if c.opts.SkipImages || len(op.Params) != 1 {
+		return keep
+	}
+	iimg, ok := op.Params[0].(*contentstream.ContentStreamInlineImage)
+	if !ok {
+		return keep
+	}
+	if isMask, ok := core.GetBoolVal(iimg.ImageMask); ok && isMask {
+		return keep
+	}
+
+	cs, err := iimg.GetColorSpace(resources)
+	if err != nil || cs == nil || c.isTargetColorspace(cs) {
+		return keep
+	}
+	img, err := iimg.ToImage(resources)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to decode inline image: %v", err)
+		return keep
+	}
+	converted, err := c.convertImage(img, cs)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to convert inline image: %v", err)
+		return keep
+	}
+	newImg, err := contentstream.NewInlineImageFromImage(*converted, core.NewFlateEncoder())
+	if err != nil {
+		common.Log.Debug("ERROR: unable to create inline image: %v", err)
+		return keep
+	}
+	newImg.Interpolate = iimg.Interpolate
+	return []*contentstream.ContentStreamOperation{{Operand: "BI", Params: []core.PdfObject{newImg}}}
+}
+
+// convertImage returns the image `img` of the color space `cs` converted to
+// the target color space, with 8 bits per component.
+func (c *colorConverter) convertImage(img *model.Image, cs model.PdfColorspace) (*model.Image, error) {
+	rgbImg, err := cs.ImageToRGB(*img)
+	if err != nil {
+		return nil, err
+	}
+	samples := rgbImg.GetSamples()
+	maxVal := math.Pow(2, float64(rgbImg.BitsPerComponent)) - 1
+	numComponents := c.target.GetNumComponents()
+	numPixels := int(rgbImg.Width * rgbImg.Height)
+	if len(samples) < 3*numPixels {
+		return nil, errors.New("invalid image data")
+	}
+
+	data := make([]byte, 0, numComponents*numPixels)
+	for i := 0; i < numPixels; i++ {
+		r := float64(samples[3*i]) / maxVal
+		g := float64(samples[3*i+1]) / maxVal
+		b := float64(samples[3*i+2]) / maxVal
+		for _, val := range c.fromRGB(r, g, b) {
+			data = append(data, byte(math.Round(math.Min(math.Max(val, 0), 1)*255)))
+		}
+	}
+	return &model.Image{
+		Width:            rgbImg.Width,
+		Height:           rgbImg.Height,
+		BitsPerComponent: 8,
+		ColorComponents:  numComponents,
+		Data:             data,
+	}, nil
+}
+
+// isTargetColorspace returns true if `cs` is the target color space.
+func (c *colorConverter) isTargetColorspace(cs model.PdfColorspace) bool {
+	switch cs.(type) {
+	case *model.PdfColorspaceDeviceGray:
+		return c.opts.Target == ColorTargetGray
+	case *model.PdfColorspaceDeviceRGB:
+		return c.opts.Target == ColorTargetRGB
+	case *model.PdfColorspaceDeviceCMYK:
+		return c.opts.Target == ColorTargetCMYK
+	}
+	return false
+}
+
+// convertResources converts the XObjects, patterns and shadings of the
+// resources `resources`.
+func (c *colorConverter) convertResources(resources *model.PdfPageResources) error {
+	if resources == nil {
+		return nil
+	}
+
+	if dict, ok := core.GetDict(resources.XObject); ok {
+		for _, name := range dict.Keys() {
+			stream, xtype := resources.GetXObjectByName(name)
+			if stream == nil || !c.visit(stream) {
+				continue
+			}
+			switch xtype {
+			case model.XObjectTypeImage:
+				if err := c.convertXObjectImage(stream); err != nil {
+					return err
+				}
+			case model.XObjectTypeForm:
+				if err := c.convertXObjectForm(stream); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if dict, ok := core.GetDict(resources.Pattern); ok {
+		for _, name := range dict.Keys() {
+			pattern, ok := resources.GetPatternByName(name)
+			if !ok || !c.visit(pattern.GetContainingPdfObject()) {
+				continue
+			}
+			if err := c.convertPattern(pattern); err != nil {
+				return err
+			}
+		}
+	}
+
+	if dict, ok := core.GetDict(resources.Shading); ok {
+		for _, name := range dict.Keys() {
+			shading, ok := resources.GetShadingByName(name)
+			if !ok || !c.visit(shading.GetContainingPdfObject()) {
+				continue
+			}
+			if err := c.convertShading(shading); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// convertXObjectImage converts the image XObject `stream` in place. Stencil
+// masks and images which cannot be decoded are left unchanged.
+func (c *colorConverter) convertXObjectImage(stream *core.PdfObjectStream) error {
+	if c.opts.SkipImages {
+		return nil
+	}
+	ximg, err := model.NewXObjectImageFromStream(stream)
+	if err != nil {
+		return err
+	}
+	if ximg.ColorSpace == nil || c.isTargetColorspace(ximg.ColorSpace) {
+		return nil
+	}
+	if isMask, ok := core.GetBoolVal(ximg.ImageMask); ok && isMask {
+		return nil
+	}
+
+	img, err := ximg.ToImage()
+	if err != nil {
+		common.Log.Debug("ERROR: unable to decode image: %v", err)
+		return nil
+	}
+	converted, err := c.convertImage(img, ximg.ColorSpace)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to convert image: %v", err)
+		return nil
+	}
+
+	ximg.Filter = core.NewFlateEncoder()
+	ximg.Decode = nil
+	if err := ximg.SetImage(converted, c.target); err != nil {
+		return err
+	}
+	ximg.ToPdfObject()
+	return nil
+}
+
+// convertXObjectForm converts the Form XObject `stream` in place.
+func (c *colorConverter) convertXObjectForm(stream *core.PdfObjectStream) error {
+	xform, err := model.NewXObjectFormFromStream(stream)
+	if err != nil {
+		return err
+	}
+	content, err := xform.GetContentStream()
+	if err != nil {
+		return err
+	}
+	converted, err := c.convertContent(string(content), xform.Resources)
+	if err != nil {
+		return err
+	}
+	if err := c.convertResources(xform.Resources); err != nil {
+		return err
+	}
+	c.convertGroup(xform.Group)
+
+	if err := xform.SetContentStream([]byte(converted), core.NewFlateEncoder()); err != nil {
+		return err
+	}
+	xform.ToPdfObject()
+	return nil
+}
+
+// convertGroup sets the color space of the transparency group `obj` to the
+// target color space.
+func (c *colorConverter) convertGroup(obj core.PdfObject) {
+	dict, ok := core.GetDict(obj)
+	if !ok || dict.Get("CS") == nil {
+		return
+	}
+	dict.Set("CS", c.target.ToPdfObject())
+}
+
+// convertPattern converts the pattern `pattern` in place.
+func (c *colorConverter) convertPattern(pattern *model.PdfPattern) error {
+	if pattern.IsShading() {
+		shading := pattern.GetAsShadingPattern().Shading
+		if shading == nil || !c.visit(shading.GetContainingPdfObject()) {
+			return nil
+		}
+		return c.convertShading(shading)
+	}
+	if stream, ok := core.GetStream(pattern.GetContainingPdfObject()); ok {
+		// Tiling patterns are converted as forms.
+		tiling := pattern.GetAsTilingPattern()
+		content, err := tiling.GetContentStream()
+		if err != nil {
+			return err
+		}
+		converted, err := c.convertContent(string(content), tiling.Resources)
+		if err != nil {
+			return err
+		}
+		if err := c.convertResources(tiling.Resources); err != nil {
+			return err
+		}
+		encoded, err := core.MakeStream([]byte(converted), core.NewFlateEncoder())
+		if err != nil {
+			return err
+		}
+		for _, key := range []core.PdfObjectName{"Filter", "DecodeParms", "Length"} {
+			stream.Remove(key)
+			stream.SetIfNotNil(key, encoded.Get(key))
+		}
+		stream.Stream = encoded.Stream
+	}
+	return nil
+}
+
+// convertShading converts the shading `shading` in place. The shading
+// functions are replaced by sampled functions computing the converted colors.
+// The mesh shadings specifying the colors of their vertices are left
+// unchanged.
+func (c *colorConverter) convertShading(shading *model.PdfShading) error {
+	container := core.ResolveReference(shading.GetContainingPdfObject())
+	var dict *core.PdfObjectDictionary
+	if stream, ok := container.(*core.PdfObjectStream); ok {
+		dict = stream.PdfObjectDictionary
+	} else if d, ok := container.(*core.PdfObjectDictionary); ok {
+		dict = d
+	}
+	if dict == nil || shading.ColorSpace == nil || c.isTargetColorspace(shading.ColorSpace) {
+		return nil
+	}
+
+	var funcs []model.PdfFunction
+	var domain []float64
+	switch ctx := shading.GetContext().(type) {
+	case *model.PdfShadingType1:
+		funcs = ctx.Function
+		domain = []float64{0, 1, 0, 1}
+		if ctx.Domain != nil {
+			domain, _ = ctx.Domain.ToFloat64Array()
+		}
+	case *model.PdfShadingType2:
+		funcs = ctx.Function
+		domain = []float64{0, 1}
+		if ctx.Domain != nil {
+			domain, _ = ctx.Domain.ToFloat64Array()
+		}
+	case *model.PdfShadingType3:
+		funcs = ctx.Function
+		domain = []float64{0, 1}
+		if ctx.Domain != nil {
+			domain, _ = ctx.Domain.ToFloat64Array()
+		}
+	case *model.PdfShadingType4:
+		funcs, domain = ctx.Function, meshFunctionDomain(ctx.Decode)
+	case *model.PdfShadingType5:
+		funcs, domain = ctx.Function, meshFunctionDomain(ctx.Decode)
+	case *model.PdfShadingType6:
+		funcs, domain = ctx.Function, meshFunctionDomain(ctx.Decode)
+	case *model.PdfShadingType7:
+		funcs, domain = ctx.Function, meshFunctionDomain(ctx.Decode)
+	}
+	if len(funcs) == 0 || (len(domain) != 2 && len(domain) != 4) {
+		common.Log.Debug("Shading colors cannot be converted. Skipping.")
+		return nil
+	}
+
+	function, err := c.sampleShadingFunctions(funcs, shading.ColorSpace, domain)
+	if err != nil {
+		return err
+	}
+	if shading.Background != nil {
+		vals, err := shading.Background.ToFloat64Array()
+		if err == nil {
+			if color, err := shading.ColorSpace.ColorFromFloats(vals); err == nil {
+				if converted, err := c.convertColor(shading.ColorSpace, color); err == nil {
+					dict.Set("Background", core.MakeArrayFromFloats(converted))
+				}
+			}
+		}
+	}
+	dict.Set("Function", function)
+	dict.Set("ColorSpace", c.target.ToPdfObject())
+	return nil
+}
+
+// meshFunctionDomain returns the domain of the parametric variable of a mesh
+// shading, specified by the last pair of its Decode array.
+func meshFunctionDomain(decode *core.PdfObjectArray) []float64 {
+	if decode == nil {
+		return nil
+	}
+	vals, err := decode.ToFloat64Array()
+	if err != nil || len(vals) < 2 {
+		return nil
+	}
+	return vals[len(vals)-2:]
+}
+
+// sampleShadingFunctions returns a sampled function (type 0) computing the
+// colors of the shading functions `funcs`, producing colors of the color
+// space `cs`, converted to the target color space.
+func (c *colorConverter) sampleShadingFunctions(funcs []model.PdfFunction, cs model.PdfColorspace,
+	domain []float64) (core.PdfObject, error) {
+	numInputs := len(domain) / 2
+	size := []int{256}
+	if numInputs == 2 {
+		size = []int{32, 32}
+	}
+	numOutputs := c.target.GetNumComponents()
+
+	eval := func(x []float64) ([]float64, error) {
+		var vals []float64
+		for _, f := range funcs {
+			out, err := f.Evaluate(x)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, out...)
+		}
+		color, err := cs.ColorFromFloats(vals)
+		if err != nil {
+			return nil, err
+		}
+		return c.convertColor(cs, color)
+	}
+
+	sample := func(dim, i int) float64 {
+		d0, d1 := domain[2*dim], domain[2*dim+1]
+		return d0 + (d1-d0)*float64(i)/float64(size[dim]-1)
+	}
+	numSamples := size[0]
+	if numInputs == 2 {
+		numSamples *= size[1]
+	}
+	data := make([]byte, 0, numSamples*numOutputs)
+	for i := 0; i < numSamples; i++ {
+		// The first input varies the fastest.
+		x := []float64{sample(0, i%size[0])}
+		if numInputs == 2 {
+			x = append(x, sample(1, i/size[0]))
+		}
+		vals, err := eval(x)
+		if err != nil {
+			return nil, err
+		}
+		for _, val := range vals {
+			data = append(data, byte(math.Round(math.Min(math.Max(val, 0), 1)*255)))
+		}
+	}
+
+	stream, err := core.MakeStream(data, core.NewFlateEncoder())
+	if err != nil {
+		return nil, err
+	}
+	ranges := make([]float64, 0, 2*numOutputs)
+	for i := 0; i < numOutputs; i++ {
+		ranges = append(ranges, 0, 1)
+	}
+	sizes := make([]int64, len(size))
+	for i, s := range size {
+		sizes[i] = int64(s)
+	}
+	stream.Set("FunctionType", core.MakeInteger(0))
+	stream.Set("Domain", core.MakeArrayFromFloats(domain))
+	stream.Set("Range", core.MakeArrayFromFloats(ranges))
+	stream.Set("Size", core.MakeArrayFromIntegers64(sizes))
+	stream.Set("BitsPerSample", core.MakeInteger(8))
+	return stream, nil
+}
+
+// convertAnnotation converts the colors and the appearance streams of the
+// annotation `annot` in place.
+func (c *colorConverter) convertAnnotation(annot *model.PdfAnnotation) error {
+	annot.C = c.convertColorArray(annot.C)
+
+	switch ctx := annot.GetContext().(type) {
+	case *model.PdfAnnotationFreeText:
+		ctx.DA = c.convertDA(ctx.DA)
+	case *model.PdfAnnotationLine:
+		ctx.IC = c.convertColorArray(ctx.IC)
+	case *model.PdfAnnotationSquare:
+		ctx.IC = c.convertColorArray(ctx.IC)
+	case *model.PdfAnnotationCircle:
+		ctx.IC = c.convertColorArray(ctx.IC)
+	case *model.PdfAnnotationPolygon:
+		ctx.IC = c.convertColorArray(ctx.IC)
+	case *model.PdfAnnotationPolyLine:
+		ctx.IC = c.convertColorArray(ctx.IC)
+	case *model.PdfAnnotationRedact:
+		ctx.IC = c.convertColorArray(ctx.IC)
+		ctx.DA = c.convertDA(ctx.DA)
+	case *model.PdfAnnotationWidget:
+		if mk, ok := core.GetDict(ctx.MK); ok && c.visit(mk) {
+			for _, key := range []core.PdfObjectName{"BC", "BG"} {
+				if obj := mk.Get(key); obj != nil {
+					mk.Set(key, c.convertColorArray(obj))
+				}
+			}
+		}
+	}
+
+	// Appearance streams.
+	ap, ok := core.GetDict(annot.AP)
+	if !ok {
+		return nil
+	}
+	for _, key := range []core.PdfObjectName{"N", "R", "D"} {
+		var streams []*core.PdfObjectStream
+		if stream, ok := core.GetStream(ap.Get(key)); ok {
+			streams = append(streams, stream)
+		} else if states, ok := core.GetDict(ap.Get(key)); ok {
+			for _, state := range states.Keys() {
+				if stream, ok := core.GetStream(states.Get(state)); ok {
+					streams = append(streams, stream)
+				}
+			}
+		}
+		for _, stream := range streams {
+			if !c.visit(stream) {
+				continue
+			}
+			if err := c.convertXObjectForm(stream); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// convertDA returns the default appearance string `obj` with its color
+// operations converted. The object is returned unchanged if it cannot be
+// converted.
+func (c *colorConverter) convertDA(obj core.PdfObject) core.PdfObject {
+	str, ok := core.GetString(obj)
+	if !ok {
+		return obj
+	}
+	converted, err := c.convertContent(str.Str(), nil)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to convert default appearance %q: %v", str.Str(), err)
+		return obj
+	}
+	return core.MakeString(strings.Join(strings.Fields(converted), " "))
+}
+
+// convertAcroForm converts the default appearance strings of the interactive
+// form `form` and of its fields.
+func (c *colorConverter) convertAcroForm(form *model.PdfAcroForm) {
+	if form.DA != nil {
+		if str, ok := core.GetString(c.convertDA(form.DA)); ok {
+			form.DA = str
+		}
+	}
+	for _, field := range form.AllFields() {
+		text, ok := field.GetContext().(*model.PdfFieldText)
+		if !ok || text.DA == nil {
+			continue
+		}
+		if str, ok := core.GetString(c.convertDA(text.DA)); ok {
+			text.DA = str
+		}
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestConvertPageColors(t *testing.T) {
+	page := model.NewPdfPage()
+	page.Resources = model.NewPdfPageResources()
+
+	// RGB image.
+	img := &model.Image{
+		Width:            2,
+		Height:           1,
+		BitsPerComponent: 8,
+		ColorComponents:  3,
+		Data:             []byte{255, 0, 0, 0, 0, 255},
+	}
+	ximg, err := model.NewXObjectImageFromImage(img, nil, core.NewFlateEncoder())
+	require.NoError(t, err)
+	require.NoError(t, page.Resources.SetXObjectImageByName("Im1", ximg))
+
+	// Form XObject.
+	form := model.NewXObjectForm()
+	require.NoError(t, form.SetContentStream([]byte("0 1 0 rg 0 0 5 5 re f"), nil))
+	require.NoError(t, page.Resources.SetXObjectFormByName("Fm1", form))
+
+	// Axial shading.
+	function := core.MakeDict()
+	function.Set("FunctionType", core.MakeInteger(2))
+	function.Set("Domain", core.MakeArrayFromFloats([]float64{0, 1}))
+	function.Set("C0", core.MakeArrayFromFloats([]float64{1, 0, 0}))
+	function.Set("C1", core.MakeArrayFromFloats([]float64{0, 0, 1}))
+	function.Set("N", core.MakeInteger(1))
+	shading := core.MakeDict()
+	shading.Set("ShadingType", core.MakeInteger(2))
+	shading.Set("ColorSpace", core.MakeName("DeviceRGB"))
+	shading.Set("Coords", core.MakeArrayFromFloats([]float64{0, 0, 100, 0}))
+	shading.Set("Function", function)
+	require.NoError(t, page.Resources.SetShadingByName("Sh1", shading))
+
+	content := "1 0 0 rg 0 0 10 10 re f 0 0 1 RG 0 0 m 10 10 l S /Im1 Do /Fm1 Do /Sh1 sh"
+	require.NoError(t, page.AddContentStreamByString(content))
+
+	square := model.NewPdfAnnotationSquare()
+	square.Rect = core.MakeArrayFromFloats([]float64{0, 0, 10, 10})
+	square.C = core.MakeArrayFromFloats([]float64{1, 1, 1})
+	square.IC = core.MakeArrayFromFloats([]float64{0, 0, 1})
+	page.AddAnnotation(square.PdfAnnotation)
+
+	require.Error(t, ConvertPageColors(page, &ColorConversionOptions{Target: 10}))
+	require.NoError(t, ConvertPageColors(page, &ColorConversionOptions{Target: ColorTargetGray}))
+
+	// Content stream.
+	converted, err := page.GetAllContentStreams()
+	require.NoError(t, err)
+	require.Equal(t, "0.3 g\n0 0 10 10 re\nf\n0.11 G\n0 0 m\n10 10 l\nS\n/Im1 Do\n/Fm1 Do\n/Sh1 sh\n", converted)
+
+	// Image.
+	ximg, err = page.Resources.GetXObjectImageByName("Im1")
+	require.NoError(t, err)
+	require.IsType(t, &model.PdfColorspaceDeviceGray{}, ximg.ColorSpace)
+	img, err = ximg.ToImage()
+	require.NoError(t, err)
+	require.Equal(t, []byte{77, 28}, img.Data)
+
+	// Form.
+	form, err = page.Resources.GetXObjectFormByName("Fm1")
+	require.NoError(t, err)
+	formContent, err := form.GetContentStream()
+	require.NoError(t, err)
+	require.Equal(t, "0.59 g\n0 0 5 5 re\nf\n", string(formContent))
+
+	// Shading.
+	sh, ok := page.Resources.GetShadingByName("Sh1")
+	require.True(t, ok)
+	require.IsType(t, &model.PdfColorspaceDeviceGray{}, sh.ColorSpace)
+	axial := sh.GetContext().(*model.PdfShadingType2)
+	require.Len(t, axial.Function, 1)
+	for x, expected := range map[float64]float64{0: 0.3, 0.5: 0.205, 1: 0.11} {
+		vals, err := axial.Function[0].Evaluate([]float64{x})
+		require.NoError(t, err)
+		require.InDelta(t, expected, vals[0], 0.01)
+	}
+
+	// Annotation.
+	requireColorArray(t, []float64{1}, square.C)
+	requireColorArray(t, []float64{0.11}, square.IC)
+}
+
+// requireColorArray checks that `obj` is an array containing `expected`.
+func requireColorArray(t *testing.T, expected []float64, obj core.PdfObject) {
+	arr, ok := core.GetArray(obj)
+	require.True(t, ok)
+	vals, err := arr.ToFloat64Array()
+	require.NoError(t, err)
+	require.InDeltaSlice(t, expected, vals, 1e-9)
+}
+
+func TestConvertColorsCMYK(t *testing.T) {
+	r := makeReader(t, 2, 100, "field")
+	for i := 1; i <= 2; i++ {
+		page, err := r.GetPage(i)
+		require.NoError(t, err)
+		require.NoError(t, page.AddContentStreamByString("1 0 0 rg 0 0 10 10 re f 0.5 g /Pattern cs /P1 scn"))
+	}
+
+	r.AcroForm.DA = core.MakeString("/Helv 0 Tf 0 g")
+	require.NoError(t, ConvertColors(r, &ColorConversionOptions{Target: ColorTargetCMYK}))
+	require.Equal(t, "/Helv 0 Tf 0 0 0 1 k", r.AcroForm.DA.Str())
+
+	d, err := NewDocumentFromReader(r)
+	require.NoError(t, err)
+	out := writeAndRead(t, d)
+	page, err := out.GetPage(2)
+	require.NoError(t, err)
+	content, err := page.GetAllContentStreams()
+	require.NoError(t, err)
+
+	// Patterns are not converted.
+	require.Contains(t, content, "0 1 1 0 k\n0 0 10 10 re\nf\n0 0 0 0.5 k\n/Pattern cs\n/P1 scn\n")
+
+	// Custom conversion.
+	r = makeReader(t, 1, 100, "")
+	page, err = r.GetPage(1)
+	require.NoError(t, err)
+	require.NoError(t, page.AddContentStreamByString("1 0 0 RG"))
+	opts := &ColorConversionOptions{
+		Target: ColorTargetCMYK,
+		ConvertRGB: func(r, g, b float64) []float64 {
+			return []float64{0, r, g, b}
+		},
+	}
+	require.NoError(t, ConvertColors(r, opts))
+	content, err = page.GetAllContentStreams()
+	require.NoError(t, err)
+	require.Contains(t, content, "\n0 1 0 0 K\n")
+}
@@ -0,0 +1,21 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package pdfutil provides high level document assembly operations: splitting
+// documents by page ranges or by the top level items of their outlines,
+// merging documents, reordering, deleting and rotating pages. The pages are copied from the source readers when the
+// assembled documents are written, so that the source documents are not
+// modified and the same page can be used in several output documents.
+// The interactive forms of the source documents are merged, the fields with
+// conflicting names being renamed. The package also provides functions for
+// scaling and transforming the content of pages and for stamping pages with
+// the pages of other documents or with text such as headers, footers, page
+// numbers and Bates numbers, for converting the colors of documents to a
+// target color space, for auditing the color spaces and ICC profiles used by
+// documents, for detecting blank pages, e.g. in scanned documents, and
+// scanned pages with the resolution of their images, and for computing
+// canonical hashes of pages and embedded files, which allow detecting
+// identical pages across documents.
+package pdfutil
@@ -0,0 +1,481 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// pageEntry represents a page of an assembled document.
+type pageEntry struct {
+	// page is the source page.
+	page *model.PdfPage
+
+	// form is the interactive form of the source document, if any.
+	form *model.PdfAcroForm
+
+	// rotate is the rotation of the output page. The rotation of the source
+	// page is used if nil.
+	rotate *int64
+
+	// stamps contains the stamps drawn on the output page.
+	stamps []pageStamp
+}
+
+// pageStamp represents content drawn on the pages of a document when the
+// document is written.
+type pageStamp interface {
+	// apply draws the stamp on the page `page`, whose page number in the
+	// output document containing `numPages` pages is `pageNumber`.
+	apply(page *model.PdfPage, pageNumber, numPages int) error
+}
+
+// rotation returns the rotation of the output page.
+func (e *pageEntry) rotation() (int64, error) {
+	if e.rotate != nil {
+		return *e.rotate, nil
+	}
+	return e.page.GetRotate()
+}
+
+// Document represents a document assembled from the pages of other documents.
+// The page numbers used by the methods of the document are 1-based.
+type Document struct {
+	entries []*pageEntry
+
+	// outline is the outline written with the document, if any.
+	outline *model.Outline
+}
+
+// NewDocument returns a new empty document.
+func NewDocument() *Document {
+	return &Document{}
+}
+
+// NewDocumentFromReader returns a new document containing the pages of the
+// document read by `r`.
+func NewDocumentFromReader(r *model.PdfReader) (*Document, error) {
+	d := NewDocument()
+	if err := d.AppendReader(r); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Merge returns a new document containing the pages of the documents read by
+// `readers`, in order.
+func Merge(readers ...*model.PdfReader) (*Document, error) {
+	d := NewDocument()
+	for _, r := range readers {
+		if err := d.AppendReader(r); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// Split returns a new document for each of the specified page ranges of the
+// document read by `r`.
+func Split(r *model.PdfReader, ranges ...PageRange) ([]*Document, error) {
+	d, err := NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return d.Split(ranges...)
+}
+
+// AppendReader appends the pages of the document read by `r` which are
+// included in the specified page ranges. All the pages are appended if no
+// range is specified.
+func (d *Document) AppendReader(r *model.PdfReader, ranges ...PageRange) error {
+	if r == nil {
+		return errors.New("reader cannot be nil")
+	}
+	numPages, err := r.GetNumPages()
+	if err != nil {
+		return err
+	}
+	if len(ranges) == 0 {
+		ranges = []PageRange{{First: 1}}
+		if numPages == 0 {
+			return nil
+		}
+	}
+
+	var entries []*pageEntry
+	for _, pr := range ranges {
+		pages, err := pr.Pages(numPages)
+		if err != nil {
+			return err
+		}
+		for _, pageNum := range pages {
+			page, err := r.GetPage(pageNum)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, &pageEntry{page: page, form: r.AcroForm})
+		}
+	}
+	d.entries = append(d.entries, entries...)
+	return nil
+}
+
+// AppendPage appends the page `page` to the document.
+func (d *Document) AppendPage(page *model.PdfPage) {
+	d.entries = append(d.entries, &pageEntry{page: page})
+}
+
+// AppendDocument appends the pages of the document `other` to the document.
+func (d *Document) AppendDocument(other *Document) {
+	for _, e := range other.entries {
+		dup := *e
+		d.entries = append(d.entries, &dup)
+	}
+}
+
+// NumPages returns the number of pages of the document.
+func (d *Document) NumPages() int {
+	return len(d.entries)
+}
+
+// Page returns the source page of the page with the specified page number.
+func (d *Document) Page(pageNumber int) (*model.PdfPage, error) {
+	if err := d.checkPageNumber(pageNumber); err != nil {
+		return nil, err
+	}
+	return d.entries[pageNumber-1].page, nil
+}
+
+// Extract returns a new document containing the pages of the document which
+// are included in the specified page ranges.
+func (d *Document) Extract(ranges ...PageRange) (*Document, error) {
+	sub := NewDocument()
+	for _, pr := range ranges {
+		pages, err := pr.Pages(len(d.entries))
+		if err != nil {
+			return nil, err
+		}
+		for _, pageNum := range pages {
+			dup := *d.entries[pageNum-1]
+			sub.entries = append(sub.entries, &dup)
+		}
+	}
+	return sub, nil
+}
+
+// Split returns a new document for each of the specified page ranges.
+func (d *Document) Split(ranges ...PageRange) ([]*Document, error) {
+	docs := make([]*Document, 0, len(ranges))
+	for _, pr := range ranges {
+		sub, err := d.Extract(pr)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, sub)
+	}
+	return docs, nil
+}
+
+// SplitEvery splits the document into documents of `n` pages. The last
+// document contains the remaining pages.
+func (d *Document) SplitEvery(n int) ([]*Document, error) {
+	if n < 1 {
+		return nil, errors.New("invalid number of pages per document")
+	}
+
+	var ranges []PageRange
+	for first := 1; first <= len(d.entries); first += n {
+		last := first + n - 1
+		if last > len(d.entries) {
+			last = len(d.entries)
+		}
+		ranges = append(ranges, PageRange{First: first, Last: last})
+	}
+	return d.Split(ranges...)
+}
+
+// Delete removes the pages with the specified page numbers.
+func (d *Document) Delete(pageNumbers ...int) error {
+	deleted := make(map[int]struct{}, len(pageNumbers))
+	for _, pageNum := range pageNumbers {
+		if err := d.checkPageNumber(pageNum); err != nil {
+			return err
+		}
+		deleted[pageNum] = struct{}{}
+	}
+
+	entries := make([]*pageEntry, 0, len(d.entries))
+	for i, e := range d.entries {
+		if _, ok := deleted[i+1]; !ok {
+			entries = append(entries, e)
+		}
+	}
+	d.entries = entries
+	return nil
+}
+
+// Reorder arranges the pages of the document in the order specified by the
+// page numbers `order`. The pages which are not listed are removed and the
+// pages listed several times are repeated.
+func (d *Document) Reorder(order ...int) error {
+	entries := make([]*pageEntry, 0, len(order))
+	for _, pageNum := range order {
+		if err := d.checkPageNumber(pageNum); err != nil {
+			return err
+		}
+		dup := *d.entries[pageNum-1]
+		entries = append(entries, &dup)
+	}
+	d.entries = entries
+	return nil
+}
+
+// Move moves the page with the page number `pageNumber` so that it becomes
+// the page with the page number `to`.
+func (d *Document) Move(pageNumber, to int) error {
+	if err := d.checkPageNumber(pageNumber); err != nil {
+		return err
+	}
+	if err := d.checkPageNumber(to); err != nil {
+		return err
+	}
+
+	e := d.entries[pageNumber-1]
+	entries := append(d.entries[:pageNumber-1:pageNumber-1], d.entries[pageNumber:]...)
+	entries = append(entries[:to-1], append([]*pageEntry{e}, entries[to-1:]...)...)
+	d.entries = entries
+	return nil
+}
+
+// Rotate rotates the pages with the specified page numbers clockwise by
+// `angle` degrees, which must be a multiple of 90. All the pages are rotated
+// if no page number is specified.
+func (d *Document) Rotate(angle int, pageNumbers ...int) error {
+	return d.rotate(angle, true, pageNumbers)
+}
+
+// SetRotation sets the rotation of the pages with the specified page numbers
+// to `angle` degrees, which must be a multiple of 90. The rotation of all the
+// pages is set if no page number is specified.
+func (d *Document) SetRotation(angle int, pageNumbers ...int) error {
+	return d.rotate(angle, false, pageNumbers)
+}
+
+// rotate sets or increments the rotation of the specified pages.
+func (d *Document) rotate(angle int, relative bool, pageNumbers []int) error {
+	if angle%90 != 0 {
+		return fmt.Errorf("invalid rotation angle %d: must be a multiple of 90", angle)
+	}
+	if len(pageNumbers) == 0 {
+		for i := range d.entries {
+			pageNumbers = append(pageNumbers, i+1)
+		}
+	}
+	for _, pageNum := range pageNumbers {
+		if err := d.checkPageNumber(pageNum); err != nil {
+			return err
+		}
+	}
+
+	for _, pageNum := range pageNumbers {
+		e := d.entries[pageNum-1]
+		rotate := int64(angle)
+		if relative {
+			current, err := e.rotation()
+			if err != nil {
+				return err
+			}
+			rotate += current
+		}
+		rotate %= 360
+		if rotate < 0 {
+			rotate += 360
+		}
+		e.rotate = &rotate
+	}
+	return nil
+}
+
+// selectPages returns the page numbers of the pages of the document which are
+// included in the page ranges `ranges` and have the parity `parity`. All the
+// pages are included if no range is specified.
+func (d *Document) selectPages(ranges []PageRange, parity PageParity) ([]int, error) {
+	if len(ranges) == 0 {
+		ranges = []PageRange{{First: 1}}
+		if len(d.entries) == 0 {
+			return nil, nil
+		}
+	}
+
+	var pages []int
+	for _, pr := range ranges {
+		rangePages, err := pr.Pages(len(d.entries))
+		if err != nil {
+			return nil, err
+		}
+		for _, pageNum := range rangePages {
+			if (parity == OddPages && pageNum%2 == 0) || (parity == EvenPages && pageNum%2 == 1) {
+				continue
+			}
+			pages = append(pages, pageNum)
+		}
+	}
+	return pages, nil
+}
+
+// addStamp adds the stamp `stamp` to the pages with the specified page
+// numbers.
+func (d *Document) addStamp(stamp pageStamp, pageNumbers []int) {
+	for _, pageNum := range pageNumbers {
+		e := d.entries[pageNum-1]
+		e.stamps = append(e.stamps[:len(e.stamps):len(e.stamps)], stamp)
+	}
+}
+
+// SetOutline sets the outline written with the document. The pages of the
+// destinations of the outline items are the 0-based indices of the pages of
+// the document: the items whose page is out of range have no destination.
+// The outline replaces the outline set on the writer by WritePdf.
+func (d *Document) SetOutline(outline *model.Outline) {
+	d.outline = outline
+}
+
+// checkPageNumber returns an error if `pageNumber` is not a valid page
+// number of the document.
+func (d *Document) checkPageNumber(pageNumber int) error {
+	if pageNumber < 1 || pageNumber > len(d.entries) {
+		return fmt.Errorf("page number %d out of range (1-%d)", pageNumber, len(d.entries))
+	}
+	return nil
+}
+
+// Write writes the document to `w`.
+func (d *Document) Write(w io.Writer) error {
+	pw := model.NewPdfWriter()
+	return d.WritePdf(&pw, w)
+}
+
+// WriteToFile writes the document to the file at `outputPath`.
+func (d *Document) WriteToFile(outputPath string) error {
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return d.Write(f)
+}
+
+// WritePdf adds the pages and the merged interactive form of the document to
+// the writer `pw` and writes the output document to `w`. It allows
+// configuring the writer (e.g. the document information or the outlines)
+// before writing the document. The pages are copied, so that the source
+// documents are not modified.
+func (d *Document) WritePdf(pw *model.PdfWriter, w io.Writer) error {
+	if pw == nil {
+		return errors.New("writer cannot be nil")
+	}
+
+	pages := make([]*model.PdfPage, 0, len(d.entries))
+	for i, e := range d.entries {
+		page := e.page.Duplicate()
+		pages = append(pages, page)
+		if e.rotate != nil {
+			rotate := *e.rotate
+			page.Rotate = &rotate
+		}
+		if len(e.stamps) > 0 {
+			// Copy the resources of the page, so that the resources of the
+			// source page are not modified.
+			if page.Resources != nil {
+				page.Resources = copyResources(page.Resources)
+				page.Resources.Font = copyDict(page.Resources.Font)
+				page.Resources.XObject = copyDict(page.Resources.XObject)
+			}
+			for _, stamp := range e.stamps {
+				if err := stamp.apply(page, i+1, len(d.entries)); err != nil {
+					return err
+				}
+			}
+		}
+		if err := pw.AddPage(page); err != nil {
+			return err
+		}
+	}
+
+	if d.outline != nil {
+		outline := model.NewOutline()
+		for _, item := range d.outline.Entries {
+			outline.Add(resolveOutlineItem(item, pages))
+		}
+		pw.AddOutlineTree(outline.ToOutlineTree())
+	}
+
+	form, restore, err := d.mergeForms()
+	if err != nil {
+		return err
+	}
+	defer restore()
+	if form != nil {
+		if err := pw.SetForms(form); err != nil {
+			return err
+		}
+	}
+
+	return pw.Write(w)
+}
+
+// resolveOutlineItem returns a copy of the outline item `item` and of its
+// children whose destinations refer to the pages `pages`, indexed by the
+// page numbers of the destinations.
+func resolveOutlineItem(item *model.OutlineItem, pages []*model.PdfPage) *model.OutlineItem {
+	dup := *item
+	if page := item.Dest.Page; page >= 0 && page < int64(len(pages)) {
+		dup.Dest.PageObj = pages[page].GetPageAsIndirectObject()
+	} else {
+		dup.Dest = model.OutlineDest{Page: -1}
+	}
+	dup.Entries = make([]*model.OutlineItem, 0, len(item.Entries))
+	for _, child := range item.Entries {
+		dup.Entries = append(dup.Entries, resolveOutlineItem(child, pages))
+	}
+	return &dup
+}
+
+// copyResources returns a shallow copy of the resources `res`, so that the
+// resources can be modified without modifying the source resources.
+func copyResources(res *model.PdfPageResources) *model.PdfPageResources {
+	dup := model.NewPdfPageResources()
+	dup.ExtGState = res.ExtGState
+	dup.ColorSpace = res.ColorSpace
+	dup.Pattern = res.Pattern
+	dup.Shading = res.Shading
+	dup.XObject = res.XObject
+	dup.Font = res.Font
+	dup.ProcSet = res.ProcSet
+	dup.Properties = res.Properties
+	return dup
+}
+
+// copyDict returns a shallow copy of `obj` if it is a dictionary, or `obj`
+// otherwise.
+func copyDict(obj core.PdfObject) core.PdfObject {
+	dict, ok := core.GetDict(obj)
+	if !ok {
+		return obj
+	}
+	dup := core.MakeDict()
+	for _, key := range dict.Keys() {
+		dup.Set(key, dict.Get(key))
+	}
+	return dup
+}
@@ -0,0 +1,173 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/annotator"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// makeReader returns a reader for a document containing `numPages` pages.
+// The width of the pages is `baseWidth` plus the page index, which allows
+// identifying the pages of the output documents. A text field named
+// `fieldName` is added to each page if `fieldName` is not empty.
+func makeReader(t *testing.T, numPages int, baseWidth float64, fieldName string) *model.PdfReader {
+	w := model.NewPdfWriter()
+	form := model.NewPdfAcroForm()
+	for i := 0; i < numPages; i++ {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Urx: baseWidth + float64(i), Ury: 500}
+		if fieldName != "" {
+			name := fieldName
+			if i > 0 {
+				name += string(rune('a' + i))
+			}
+			field, err := annotator.NewTextField(page, name, []float64{10, 10, 100, 30}, annotator.TextFieldOptions{})
+			require.NoError(t, err)
+			*form.Fields = append(*form.Fields, field.PdfField)
+			for _, widget := range field.Annotations {
+				page.AddAnnotation(widget.PdfAnnotation)
+			}
+		}
+		require.NoError(t, w.AddPage(page))
+	}
+	if fieldName != "" {
+		require.NoError(t, w.SetForms(form))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	return r
+}
+
+// writeAndRead writes the document `d` and returns a reader for the output.
+func writeAndRead(t *testing.T, d *Document) *model.PdfReader {
+	var buf bytes.Buffer
+	require.NoError(t, d.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	return r
+}
+
+// pageWidths returns the widths of the pages of the document read by `r`.
+func pageWidths(t *testing.T, r *model.PdfReader) []float64 {
+	numPages, err := r.GetNumPages()
+	require.NoError(t, err)
+
+	var widths []float64
+	for i := 1; i <= numPages; i++ {
+		page, err := r.GetPage(i)
+		require.NoError(t, err)
+		mbox, err := page.GetMediaBox()
+		require.NoError(t, err)
+		widths = append(widths, mbox.Width())
+	}
+	return widths
+}
+
+func TestParsePageRanges(t *testing.T) {
+	ranges, err := ParsePageRanges("1-3, 5,8-")
+	require.NoError(t, err)
+	require.Equal(t, []PageRange{{1, 3}, {5, 5}, {8, 0}}, ranges)
+
+	pages, err := ranges[2].Pages(10)
+	require.NoError(t, err)
+	require.Equal(t, []int{8, 9, 10}, pages)
+	_, err = ranges[2].Pages(5)
+	require.Error(t, err)
+
+	for _, s := range []string{"a", "0-2", "3-1", "1-x"} {
+		_, err := ParsePageRanges(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestSplitMerge(t *testing.T) {
+	r1 := makeReader(t, 5, 100, "")
+	r2 := makeReader(t, 2, 200, "")
+
+	docs, err := Split(r1, PageRange{1, 2}, PageRange{3, 0})
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	require.Equal(t, []float64{100, 101}, pageWidths(t, writeAndRead(t, docs[0])))
+	require.Equal(t, []float64{102, 103, 104}, pageWidths(t, writeAndRead(t, docs[1])))
+
+	// The same source pages can be used by several documents.
+	merged, err := Merge(r2, r1, r2)
+	require.NoError(t, err)
+	require.Equal(t, 9, merged.NumPages())
+	require.Equal(t, []float64{200, 201, 100, 101, 102, 103, 104, 200, 201}, pageWidths(t, writeAndRead(t, merged)))
+
+	parts, err := merged.SplitEvery(4)
+	require.NoError(t, err)
+	require.Len(t, parts, 3)
+	require.Equal(t, 1, parts[2].NumPages())
+
+	// The source documents are not modified.
+	numPages, err := r1.GetNumPages()
+	require.NoError(t, err)
+	require.Equal(t, 5, numPages)
+	require.Equal(t, []float64{100, 101, 102, 103, 104}, pageWidths(t, r1))
+}
+
+func TestReorderDeleteRotate(t *testing.T) {
+	d, err := NewDocumentFromReader(makeReader(t, 5, 100, ""))
+	require.NoError(t, err)
+
+	require.NoError(t, d.Delete(2, 4))
+	require.Error(t, d.Delete(4))
+	require.NoError(t, d.Reorder(3, 1, 2, 1))
+	require.Error(t, d.Reorder(5))
+	require.NoError(t, d.Move(1, 4))
+
+	require.NoError(t, d.Rotate(90))
+	require.NoError(t, d.Rotate(-180, 1))
+	require.NoError(t, d.SetRotation(450, 2))
+	require.Error(t, d.Rotate(45))
+	require.Error(t, d.Rotate(90, 10))
+
+	r := writeAndRead(t, d)
+	require.Equal(t, []float64{100, 102, 100, 104}, pageWidths(t, r))
+
+	var rotations []int64
+	for i := 1; i <= 4; i++ {
+		page, err := r.GetPage(i)
+		require.NoError(t, err)
+		require.NotNil(t, page.Rotate)
+		rotations = append(rotations, *page.Rotate)
+	}
+	require.Equal(t, []int64{270, 90, 90, 90}, rotations)
+}
+
+func TestMergeForms(t *testing.T) {
+	r1 := makeReader(t, 2, 100, "name")
+	r2 := makeReader(t, 3, 200, "name")
+
+	d := NewDocument()
+	require.NoError(t, d.AppendReader(r1))
+	require.NoError(t, d.AppendReader(r2, PageRange{1, 2}))
+
+	r := writeAndRead(t, d)
+	require.NotNil(t, r.AcroForm)
+
+	var names []string
+	for _, field := range r.AcroForm.AllFields() {
+		name, err := field.FullName()
+		require.NoError(t, err)
+		names = append(names, name)
+	}
+	require.Equal(t, []string{"name", "nameb", "name_2", "nameb_2"}, names)
+
+	// The source field names are restored.
+	require.Equal(t, "name", (*r2.AcroForm.Fields)[0].PartialName())
+}
@@ -0,0 +1,187 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"fmt"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// mergeForms returns the interactive form of the document, which contains the
+// fields of the source forms having widget annotations on the pages of the
+// document. The top level fields whose names conflict with the names of the
+// fields of the previous forms are renamed by appending a numeric suffix to
+// their names. The returned function restores the names of the renamed
+// fields and must be called once the document is written.
+func (d *Document) mergeForms() (*model.PdfAcroForm, func(), error) {
+	restore := func() {}
+
+	// Collect the source forms and the widget annotations of the pages.
+	var forms []*model.PdfAcroForm
+	formSet := map[*model.PdfAcroForm]struct{}{}
+	widgets := map[core.PdfObject]struct{}{}
+	for _, e := range d.entries {
+		if e.form == nil {
+			continue
+		}
+		if _, ok := formSet[e.form]; !ok {
+			formSet[e.form] = struct{}{}
+			forms = append(forms, e.form)
+		}
+
+		annots, err := e.page.GetAnnotations()
+		if err != nil {
+			return nil, restore, err
+		}
+		for _, annot := range annots {
+			widgets[annot.GetContainingPdfObject()] = struct{}{}
+		}
+	}
+	if len(forms) == 0 {
+		return nil, restore, nil
+	}
+
+	merged := model.NewPdfAcroForm()
+	names := map[string]struct{}{}
+	renamed := map[*model.PdfField]*core.PdfObjectString{}
+	restore = func() {
+		for field, name := range renamed {
+			field.T = name
+		}
+	}
+
+	var fonts *core.PdfObjectDictionary
+	var sigFlags int64
+	included := map[core.PdfObject]struct{}{}
+	for _, form := range forms {
+		if form.Fields == nil {
+			continue
+		}
+
+		var fields []*model.PdfField
+		formNames := map[string]struct{}{}
+		for _, field := range *form.Fields {
+			if hasWidget(field, widgets) {
+				fields = append(fields, field)
+				formNames[field.PartialName()] = struct{}{}
+			}
+		}
+
+		for _, field := range fields {
+			name := field.PartialName()
+			if _, ok := names[name]; ok {
+				newName := uniqueFieldName(name, names, formNames)
+				common.Log.Debug("Renaming conflicting field %q to %q", name, newName)
+				renamed[field] = field.T
+				field.T = core.MakeString(newName)
+				formNames[newName] = struct{}{}
+			}
+
+			*merged.Fields = append(*merged.Fields, field)
+			for _, f := range allFields(field) {
+				included[f.GetContainingPdfObject()] = struct{}{}
+			}
+		}
+		for name := range formNames {
+			names[name] = struct{}{}
+		}
+
+		if form.NeedAppearances != nil && bool(*form.NeedAppearances) {
+			merged.NeedAppearances = core.MakeBool(true)
+		}
+		if form.SigFlags != nil {
+			sigFlags |= int64(*form.SigFlags)
+		}
+		if merged.DA == nil {
+			merged.DA = form.DA
+		}
+		if merged.Q == nil {
+			merged.Q = form.Q
+		}
+		if form.DR != nil {
+			if merged.DR == nil {
+				merged.DR = copyResources(form.DR)
+			}
+			if srcFonts, ok := core.GetDict(form.DR.Font); ok {
+				if fonts == nil {
+					fonts = core.MakeDict()
+				}
+				for _, key := range srcFonts.Keys() {
+					if fonts.Get(key) == nil {
+						fonts.Set(key, srcFonts.Get(key))
+					}
+				}
+			}
+		}
+	}
+
+	if sigFlags != 0 {
+		merged.SigFlags = core.MakeInteger(sigFlags)
+	}
+	if fonts != nil {
+		merged.DR.Font = fonts
+	}
+
+	// Keep the calculation order of the included fields.
+	for _, form := range forms {
+		if form.CO == nil {
+			continue
+		}
+		for _, obj := range form.CO.Elements() {
+			if _, ok := included[core.ResolveReference(obj)]; !ok {
+				continue
+			}
+			if merged.CO == nil {
+				merged.CO = core.MakeArray()
+			}
+			merged.CO.Append(obj)
+		}
+	}
+	return merged, restore, nil
+}
+
+// hasWidget returns true if the field `field` or one of its descendants has a
+// widget annotation contained in `widgets`.
+func hasWidget(field *model.PdfField, widgets map[core.PdfObject]struct{}) bool {
+	for _, f := range allFields(field) {
+		for _, widget := range f.Annotations {
+			if _, ok := widgets[widget.GetContainingPdfObject()]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allFields returns the field `field` and its descendants.
+func allFields(field *model.PdfField) []*model.PdfField {
+	fields := []*model.PdfField{field}
+	for _, kid := range field.Kids {
+		fields = append(fields, allFields(kid)...)
+	}
+	return fields
+}
+
+// uniqueFieldName returns a field name based on `name` which is not
+// contained in any of the name sets `nameSets`.
+func uniqueFieldName(name string, nameSets ...map[string]struct{}) string {
+	for i := 2; ; i++ {
+		newName := fmt.Sprintf("%s_%d", name, i)
+		unique := true
+		for _, names := range nameSets {
+			if _, ok := names[newName]; ok {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			return newName
+		}
+	}
+}
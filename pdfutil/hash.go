@@ -0,0 +1,400 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"sort"
+	"strconv"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// HashOptions specifies the options of the page and embedded file hashing
+// functions.
+type HashOptions struct {
+	// Annotations specifies whether the annotations of the pages are hashed.
+	// By default only the content of the pages is hashed.
+	Annotations bool
+
+	// NewHash returns the hash function used. Defaults to SHA-256.
+	NewHash func() hash.Hash
+}
+
+// newHash returns a new hash of the options `opts`.
+func (opts *HashOptions) newHash() hash.Hash {
+	if opts == nil || opts.NewHash == nil {
+		return sha256.New()
+	}
+	return opts.NewHash()
+}
+
+// canonicalIgnoredKeys contains the keys of the dictionary entries which are
+// not part of the canonical form of the objects, as they do not affect the
+// appearance of the pages: the stream filters are ignored as the decoded
+// stream data is hashed, and the metadata and structure entries are ignored.
+var canonicalIgnoredKeys = map[core.PdfObjectName]struct{}{
+	"Length":        {},
+	"Filter":        {},
+	"DecodeParms":   {},
+	"Parent":        {},
+	"NM":            {},
+	"M":             {},
+	"StructParent":  {},
+	"StructParents": {},
+	"LastModified":  {},
+	"PieceInfo":     {},
+	"Metadata":      {},
+}
+
+// resourceOperands maps the content stream operators using named resources to
+// the resource category of their name operands.
+var resourceOperands = map[string]core.PdfObjectName{
+	"Tf":  "Font",
+	"Do":  "XObject",
+	"gs":  "ExtGState",
+	"cs":  "ColorSpace",
+	"CS":  "ColorSpace",
+	"scn": "Pattern",
+	"SCN": "Pattern",
+	"sh":  "Shading",
+	"BDC": "Properties",
+	"DP":  "Properties",
+}
+
+// canonicalWriter writes the canonical form of PDF objects, which does not
+// depend on the object numbers, the stream filters and the order of the
+// dictionary entries.
+type canonicalWriter struct {
+	opts *HashOptions
+
+	// digests contains the digests of the canonical forms of the indirect
+	// objects and streams already written, so that shared objects are
+	// processed once.
+	digests map[core.PdfObject]string
+
+	// visiting contains the objects being written, for detecting cycles.
+	visiting map[core.PdfObject]bool
+}
+
+// newCanonicalWriter returns a new canonical writer using the hash function
+// of the options `opts`.
+func newCanonicalWriter(opts *HashOptions) *canonicalWriter {
+	return &canonicalWriter{
+		opts:     opts,
+		digests:  map[core.PdfObject]string{},
+		visiting: map[core.PdfObject]bool{},
+	}
+}
+
+// digest returns the hex encoded digest of the canonical form of `obj`.
+func (cw *canonicalWriter) digest(obj core.PdfObject) string {
+	var buf bytes.Buffer
+	cw.write(&buf, obj)
+	h := cw.opts.newHash()
+	h.Write(buf.Bytes())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// write writes the canonical form of `obj` to `buf`. Indirect objects and
+// streams are replaced by the digests of their canonical forms. References
+// to pages, e.g. the P entries of annotations, are replaced by a marker.
+func (cw *canonicalWriter) write(buf *bytes.Buffer, obj core.PdfObject) {
+	switch t := obj.(type) {
+	case *core.PdfIndirectObject, *core.PdfObjectStream:
+		if isPageDict(obj) {
+			buf.WriteString("@page")
+			return
+		}
+		if d, ok := cw.digests[obj]; ok {
+			buf.WriteString("{" + d + "}")
+			return
+		}
+		if cw.visiting[obj] {
+			buf.WriteString("@cycle")
+			return
+		}
+		cw.visiting[obj] = true
+		var sub bytes.Buffer
+		if stream, ok := t.(*core.PdfObjectStream); ok {
+			cw.writeStream(&sub, stream)
+		} else {
+			cw.write(&sub, t.(*core.PdfIndirectObject).PdfObject)
+		}
+		delete(cw.visiting, obj)
+
+		h := cw.opts.newHash()
+		h.Write(sub.Bytes())
+		d := hex.EncodeToString(h.Sum(nil))
+		cw.digests[obj] = d
+		buf.WriteString("{" + d + "}")
+	case *core.PdfObjectReference:
+		cw.write(buf, t.Resolve())
+	case *core.PdfObjectDictionary:
+		if isPageDict(t) {
+			buf.WriteString("@page")
+			return
+		}
+		cw.writeDict(buf, t)
+	case *core.PdfObjectArray:
+		buf.WriteString("[")
+		for i, elem := range t.Elements() {
+			if i > 0 {
+				buf.WriteString(" ")
+			}
+			cw.write(buf, elem)
+		}
+		buf.WriteString("]")
+	case *core.PdfObjectInteger:
+		buf.WriteString(formatNumber(float64(*t)))
+	case *core.PdfObjectFloat:
+		buf.WriteString(formatNumber(float64(*t)))
+	case *core.PdfObjectString:
+		buf.WriteString("<" + hex.EncodeToString(t.Bytes()) + ">")
+	case *core.PdfObjectName:
+		buf.WriteString("/" + string(*t))
+	case *core.PdfObjectBool:
+		buf.WriteString(strconv.FormatBool(bool(*t)))
+	default:
+		buf.WriteString("null")
+	}
+}
+
+// writeDict writes the canonical form of the dictionary `d`, with sorted keys.
+func (cw *canonicalWriter) writeDict(buf *bytes.Buffer, d *core.PdfObjectDictionary) {
+	var keys []string
+	for _, key := range d.Keys() {
+		if _, ignored := canonicalIgnoredKeys[key]; ignored {
+			continue
+		}
+		if core.IsNullObject(d.Get(key)) {
+			continue
+		}
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+
+	buf.WriteString("<<")
+	for _, key := range keys {
+		buf.WriteString("/" + key + " ")
+		cw.write(buf, d.Get(core.PdfObjectName(key)))
+	}
+	buf.WriteString(">>")
+}
+
+// writeStream writes the canonical form of `stream`: its dictionary and its
+// decoded data. The encoded data is used if the stream cannot be decoded.
+func (cw *canonicalWriter) writeStream(buf *bytes.Buffer, stream *core.PdfObjectStream) {
+	data, err := core.DecodeStream(stream)
+	if err != nil {
+		common.Log.Debug("ERROR: unable to decode stream: %v", err)
+		data = stream.Stream
+		cw.writeDict(buf, stream.PdfObjectDictionary)
+		buf.WriteString("/Filter ")
+		cw.write(buf, stream.Get("Filter"))
+	} else {
+		cw.writeDict(buf, stream.PdfObjectDictionary)
+	}
+	fmt.Fprintf(buf, "stream %d\n", len(data))
+	buf.Write(data)
+}
+
+// isPageDict returns true if `obj` is a page dictionary.
+func isPageDict(obj core.PdfObject) bool {
+	d, ok := core.GetDict(obj)
+	if !ok {
+		return false
+	}
+	name, _ := core.GetNameVal(d.Get("Type"))
+	return name == "Page"
+}
+
+// formatNumber returns the canonical representation of the number `val`, so
+// that integer and real numbers with the same value are written the same.
+func formatNumber(val float64) string {
+	return strconv.FormatFloat(val, 'f', -1, 64)
+}
+
+// writeContent writes the canonical form of the content stream operations
+// `ops`, using the resources `resources`. The names of the resources used by
+// the operations are replaced by the digests of the resources, so that the
+// content does not depend on how the resources are named.
+func (cw *canonicalWriter) writeContent(buf *bytes.Buffer, ops *contentstream.ContentStreamOperations,
+	resources *core.PdfObjectDictionary) {
+	resourceName := func(category core.PdfObjectName, obj core.PdfObject) core.PdfObject {
+		name, ok := obj.(*core.PdfObjectName)
+		if !ok || resources == nil {
+			return obj
+		}
+		catDict, ok := core.GetDict(resources.Get(category))
+		if !ok {
+			return obj
+		}
+		res := catDict.Get(*name)
+		if res == nil {
+			return obj
+		}
+		return core.MakeName("R" + cw.digest(res))
+	}
+
+	for _, op := range *ops {
+		params := op.Params
+		if category, ok := resourceOperands[op.Operand]; ok && len(params) > 0 {
+			params = append([]core.PdfObject{}, params...)
+			switch op.Operand {
+			case "scn", "SCN":
+				params[len(params)-1] = resourceName(category, params[len(params)-1])
+			case "BDC", "DP":
+				if len(params) > 1 {
+					params[1] = resourceName(category, params[1])
+				}
+			default:
+				params[0] = resourceName(category, params[0])
+			}
+		}
+
+		for _, param := range params {
+			if img, ok := param.(*contentstream.ContentStreamInlineImage); ok {
+				buf.WriteString(img.WriteString())
+			} else {
+				cw.write(buf, param)
+			}
+			buf.WriteString(" ")
+		}
+		buf.WriteString(op.Operand + "\n")
+	}
+}
+
+// PageHash returns the canonical hash of the page `page`, which is the same
+// for pages which look the same even if they are stored differently, so that
+// identical pages can be detected across documents. The hash covers:
+//   - the content of the page, normalized by parsing and rewriting the content
+//     streams, with the resources used by the content identified by the hash
+//     of their content instead of their names,
+//   - the effective media box, crop box and rotation of the page,
+//   - the annotations of the page, if specified by `opts`.
+//
+// The object numbers, the stream filters, the order of the dictionary
+// entries and the resources not used by the content do not affect the hash.
+// The hash function is SHA-256 unless specified otherwise by `opts`, which
+// can be nil.
+func PageHash(page *model.PdfPage, opts *HashOptions) ([]byte, error) {
+	cw := newCanonicalWriter(opts)
+	var buf bytes.Buffer
+
+	mbox, err := page.GetMediaBox()
+	if err != nil {
+		return nil, err
+	}
+	cbox, err := page.GetCropBox()
+	if err != nil {
+		return nil, err
+	}
+	rotate, err := page.GetRotate()
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString("MediaBox ")
+	cw.write(&buf, mbox.ToPdfObject())
+	buf.WriteString("\nCropBox ")
+	cw.write(&buf, cbox.ToPdfObject())
+	fmt.Fprintf(&buf, "\nRotate %d\n", (rotate%360+360)%360)
+
+	content, err := page.GetAllContentStreams()
+	if err != nil {
+		return nil, err
+	}
+	var resources *core.PdfObjectDictionary
+	if page.Resources != nil {
+		resources, _ = core.GetDict(page.Resources.ToPdfObject())
+	}
+	buf.WriteString("Contents\n")
+	ops, err := contentstream.NewContentStreamParser(content).Parse()
+	if err != nil {
+		common.Log.Debug("ERROR: unable to parse page content: %v", err)
+		buf.WriteString(content)
+	} else {
+		cw.writeContent(&buf, ops, resources)
+	}
+
+	if opts != nil && opts.Annotations {
+		annotations, err := page.GetAnnotations()
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("Annots\n")
+		for _, annot := range annotations {
+			cw.write(&buf, annot.GetContainingPdfObject())
+			buf.WriteString("\n")
+		}
+	}
+
+	h := cw.opts.newHash()
+	h.Write(buf.Bytes())
+	return h.Sum(nil), nil
+}
+
+// PageHashes returns the canonical hashes of the pages of the document read
+// by `r`, see PageHash.
+func PageHashes(r *model.PdfReader, opts *HashOptions) ([][]byte, error) {
+	numPages, err := r.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([][]byte, numPages)
+	for i := range hashes {
+		page, err := r.GetPage(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		if hashes[i], err = PageHash(page, opts); err != nil {
+			return nil, fmt.Errorf("page %d: %v", i+1, err)
+		}
+	}
+	return hashes, nil
+}
+
+// EmbeddedFileHash returns the hash of the content of the embedded file
+// `file`. The hash function is SHA-256 unless specified otherwise by `opts`,
+// which can be nil.
+func EmbeddedFileHash(file *model.EmbeddedFile, opts *HashOptions) []byte {
+	h := opts.newHash()
+	h.Write(file.Content)
+	return h.Sum(nil)
+}
+
+// EmbeddedFileHashes returns the hashes of the contents of the files embedded
+// in the document read by `r`, keyed by the keys of the embedded files name
+// tree, see EmbeddedFileHash. The file specifications referring to external
+// files, without embedded file streams, are skipped.
+func EmbeddedFileHashes(r *model.PdfReader, opts *HashOptions) (map[string][]byte, error) {
+	entries, err := r.GetEmbeddedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		file, err := entry.Filespec.GetEmbeddedFile()
+		if err != nil {
+			return nil, fmt.Errorf("embedded file %q: %v", entry.Key, err)
+		}
+		if file == nil {
+			common.Log.Debug("Skipping file %q: no embedded file stream", entry.Key)
+			continue
+		}
+		hashes[entry.Key] = EmbeddedFileHash(file, opts)
+	}
+	return hashes, nil
+}
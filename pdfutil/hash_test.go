@@ -0,0 +1,119 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"bytes"
+	"crypto/md5"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// makeHashReader returns a reader for a document whose pages draw the text of
+// `contents` with the Helvetica font named `fontName`. An unused font named
+// Unused is also added to the resources of the pages if `unused` is true. The
+// content streams are flate encoded if `flate` is true.
+func makeHashReader(t *testing.T, fontName string, unused, flate bool, contents ...string) *model.PdfReader {
+	font, err := model.NewStandard14Font(model.HelveticaName)
+	require.NoError(t, err)
+	unusedFont, err := model.NewStandard14Font(model.CourierName)
+	require.NoError(t, err)
+
+	var encoder core.StreamEncoder
+	if flate {
+		encoder = core.NewFlateEncoder()
+	}
+
+	w := model.NewPdfWriter()
+	for _, text := range contents {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+		require.NoError(t, page.Resources.SetFontByName(core.PdfObjectName(fontName), font.ToPdfObject()))
+		if unused {
+			require.NoError(t, page.Resources.SetFontByName("Unused", unusedFont.ToPdfObject()))
+		}
+		content := "BT /" + fontName + " 12 Tf 100 700 Td (" + text + ") Tj ET"
+		require.NoError(t, page.SetContentStreams([]string{content}, encoder))
+		require.NoError(t, w.AddPage(page))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	return r
+}
+
+func TestPageHash(t *testing.T) {
+	r1 := makeHashReader(t, "F1", false, false, "Hello", "World")
+	r2 := makeHashReader(t, "F7", true, true, "Cover", "World", "Hello")
+
+	hashes1, err := PageHashes(r1, nil)
+	require.NoError(t, err)
+	hashes2, err := PageHashes(r2, nil)
+	require.NoError(t, err)
+	require.Len(t, hashes1, 2)
+	require.Len(t, hashes2, 3)
+
+	// Identical pages have the same hash, regardless of the object numbers,
+	// the resource names, the unused resources and the stream filters.
+	require.Equal(t, hashes1[0], hashes2[2])
+	require.Equal(t, hashes1[1], hashes2[1])
+	require.NotEqual(t, hashes1[0], hashes1[1])
+	require.NotEqual(t, hashes2[0], hashes2[1])
+
+	// Rotated pages have a different hash.
+	page, err := r1.GetPage(1)
+	require.NoError(t, err)
+	rotate := int64(90)
+	page.Rotate = &rotate
+	hash, err := PageHash(page, nil)
+	require.NoError(t, err)
+	require.NotEqual(t, hashes1[0], hash)
+
+	// Custom hash function.
+	hash, err = PageHash(page, &HashOptions{NewHash: md5.New})
+	require.NoError(t, err)
+	require.Len(t, hash, md5.Size)
+}
+
+func TestEmbeddedFileHashes(t *testing.T) {
+	w := model.NewPdfWriter()
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	require.NoError(t, w.AddPage(page))
+	files := []*model.EmbeddedFile{
+		{Name: "a.txt", Content: []byte("same")},
+		{Name: "b.txt", Content: []byte("same")},
+		{Name: "c.txt", Content: []byte("other")},
+	}
+	var entries []*model.EmbeddedFileEntry
+	for _, file := range files {
+		fs, err := model.NewPdfFilespecFromEmbeddedFile(file)
+		require.NoError(t, err)
+		entries = append(entries, &model.EmbeddedFileEntry{Key: file.Name, Filespec: fs})
+	}
+	// File specification of an external file, which is skipped.
+	external := model.NewPdfFilespec()
+	external.F = core.MakeString("external.txt")
+	entries = append(entries, &model.EmbeddedFileEntry{Key: "external.txt", Filespec: external})
+	require.NoError(t, w.SetEmbeddedFiles(entries))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	hashes, err := EmbeddedFileHashes(r, nil)
+	require.NoError(t, err)
+	require.Len(t, hashes, 3)
+	require.Equal(t, EmbeddedFileHash(files[0], nil), hashes["a.txt"])
+	require.Equal(t, hashes["a.txt"], hashes["b.txt"])
+	require.NotEqual(t, hashes["a.txt"], hashes["c.txt"])
+}
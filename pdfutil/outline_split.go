@@ -0,0 +1,134 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// OutlinePart represents a part of a document split by SplitByOutline.
+type OutlinePart struct {
+	// Title is the title of the top level outline item starting the part.
+	// It is empty for the pages preceding the first item.
+	Title string
+
+	// Pages is the range of the pages of the source document in the part.
+	Pages PageRange
+
+	// Document contains the pages of the part. Its outline contains the
+	// outline item starting the part and its children.
+	Document *Document
+}
+
+// SplitByOutline splits the document read by `r` into parts starting at the
+// pages of the top level items of its outline, e.g. a part for each chapter.
+// The page of an item without destination is the page of its first child
+// having one. The items whose page does not follow the page of the previous
+// item are ignored and the pages preceding the first item, if any, form a
+// part without title. As for the other documents, only the objects used by
+// the pages of the parts and the form fields of these pages are written.
+func SplitByOutline(r *model.PdfReader) ([]*OutlinePart, error) {
+	if r == nil {
+		return nil, errors.New("reader cannot be nil")
+	}
+	outline, err := r.GetOutlines()
+	if err != nil {
+		return nil, err
+	}
+	d, err := NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	pageIndices := make(map[*core.PdfIndirectObject]int, len(r.PageList))
+	for i, page := range r.PageList {
+		pageIndices[page.GetPageAsIndirectObject()] = i
+	}
+
+	// Find the first page of each part.
+	var titles []string
+	var items []*model.OutlineItem
+	var starts []int
+	for _, item := range outline.Entries {
+		start, ok := outlineItemPage(item, pageIndices)
+		if !ok {
+			continue
+		}
+		if len(starts) > 0 && start <= starts[len(starts)-1] {
+			common.Log.Debug("Ignoring outline item %q on page %d", item.Title, start+1)
+			continue
+		}
+		if len(starts) == 0 && start > 0 {
+			titles = append(titles, "")
+			items = append(items, nil)
+			starts = append(starts, 0)
+		}
+		titles = append(titles, item.Title)
+		items = append(items, item)
+		starts = append(starts, start)
+	}
+	if len(starts) == 0 {
+		return nil, errors.New("outline has no page destinations")
+	}
+
+	parts := make([]*OutlinePart, 0, len(starts))
+	for i, start := range starts {
+		pr := PageRange{First: start + 1, Last: len(r.PageList)}
+		if i+1 < len(starts) {
+			pr.Last = starts[i+1]
+		}
+		sub, err := d.Extract(pr)
+		if err != nil {
+			return nil, err
+		}
+		if items[i] != nil {
+			partOutline := model.NewOutline()
+			partOutline.Add(shiftOutlineItem(items[i], pageIndices, start, pr.Last-start))
+			sub.SetOutline(partOutline)
+		}
+		parts = append(parts, &OutlinePart{Title: titles[i], Pages: pr, Document: sub})
+	}
+	return parts, nil
+}
+
+// outlineItemPage returns the 0-based index of the destination page of the
+// outline item `item`, or of its first descendant having one.
+func outlineItemPage(item *model.OutlineItem, pageIndices map[*core.PdfIndirectObject]int) (int, bool) {
+	if item.Action == nil && item.Dest.PageObj != nil {
+		if index, ok := pageIndices[item.Dest.PageObj]; ok {
+			return index, true
+		}
+	}
+	for _, child := range item.Entries {
+		if index, ok := outlineItemPage(child, pageIndices); ok {
+			return index, true
+		}
+	}
+	return 0, false
+}
+
+// shiftOutlineItem returns a copy of the outline item `item` and of its
+// children, whose destinations are the indices of their pages in the part of
+// `numPages` pages starting at the page index `start`. The items whose page
+// is not in the part have no destination.
+func shiftOutlineItem(item *model.OutlineItem, pageIndices map[*core.PdfIndirectObject]int,
+	start, numPages int) *model.OutlineItem {
+	dup := *item
+	dup.Dest.PageObj = nil
+	dup.Dest.Page = -1
+	if index, ok := pageIndices[item.Dest.PageObj]; ok && index >= start && index < start+numPages {
+		dup.Dest.Page = int64(index - start)
+	}
+	dup.Entries = make([]*model.OutlineItem, 0, len(item.Entries))
+	for _, child := range item.Entries {
+		dup.Entries = append(dup.Entries, shiftOutlineItem(child, pageIndices, start, numPages))
+	}
+	return &dup
+}
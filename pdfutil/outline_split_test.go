@@ -0,0 +1,87 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestSplitByOutline(t *testing.T) {
+	w := model.NewPdfWriter()
+	var pages []*model.PdfPage
+	for i := 0; i < 6; i++ {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Urx: 100 + float64(i), Ury: 500}
+		require.NoError(t, w.AddPage(page))
+		pages = append(pages, page)
+	}
+	dest := func(i int) model.OutlineDest {
+		return model.OutlineDest{PageObj: pages[i].GetPageAsIndirectObject(), Mode: "Fit"}
+	}
+	chapter1 := model.NewOutlineItem("Chapter 1", dest(1))
+	chapter1.Add(model.NewOutlineItem("Section 1.1", dest(2)))
+	// The page of an item without destination is the page of its children.
+	chapter2 := model.NewOutlineItem("Chapter 2", model.OutlineDest{Page: -1})
+	chapter2.Add(model.NewOutlineItem("Section 2.1", dest(3)))
+	chapter2.Add(model.NewOutlineItem("Cover", dest(0)))
+	outline := model.NewOutline()
+	outline.Add(chapter1)
+	outline.Add(chapter2)
+	// Ignored, as it precedes the previous item.
+	outline.Add(model.NewOutlineItem("Back", dest(2)))
+	w.AddOutlineTree(outline.ToOutlineTree())
+
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	parts, err := SplitByOutline(r)
+	require.NoError(t, err)
+	require.Len(t, parts, 3)
+	require.Equal(t, "", parts[0].Title)
+	require.Equal(t, PageRange{First: 1, Last: 1}, parts[0].Pages)
+	require.Equal(t, "Chapter 1", parts[1].Title)
+	require.Equal(t, PageRange{First: 2, Last: 3}, parts[1].Pages)
+	require.Equal(t, "Chapter 2", parts[2].Title)
+	require.Equal(t, PageRange{First: 4, Last: 6}, parts[2].Pages)
+
+	out := writeAndRead(t, parts[0].Document)
+	require.Equal(t, []float64{100}, pageWidths(t, out))
+	_, err = out.GetOutlines()
+	require.Error(t, err)
+
+	out = writeAndRead(t, parts[1].Document)
+	require.Equal(t, []float64{101, 102}, pageWidths(t, out))
+	partOutline, err := out.GetOutlines()
+	require.NoError(t, err)
+	require.Len(t, partOutline.Entries, 1)
+	require.Equal(t, "Chapter 1", partOutline.Entries[0].Title)
+	require.Equal(t, int64(0), partOutline.Entries[0].Dest.Page)
+	require.Len(t, partOutline.Entries[0].Entries, 1)
+	require.Equal(t, int64(1), partOutline.Entries[0].Entries[0].Dest.Page)
+
+	out = writeAndRead(t, parts[2].Document)
+	require.Equal(t, []float64{103, 104, 105}, pageWidths(t, out))
+	partOutline, err = out.GetOutlines()
+	require.NoError(t, err)
+	sections := partOutline.Entries[0].Entries
+	require.Len(t, sections, 2)
+	require.Equal(t, "Section 2.1", sections[0].Title)
+	require.Equal(t, int64(0), sections[0].Dest.Page)
+	require.NotNil(t, sections[0].Dest.PageObj)
+	// The destination page of the cover is not in the part.
+	require.Equal(t, "Cover", sections[1].Title)
+	require.Nil(t, sections[1].Dest.PageObj)
+
+	_, err = SplitByOutline(makeReader(t, 2, 100, ""))
+	require.Error(t, err)
+}
@@ -0,0 +1,248 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"errors"
+	"math"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// PageParity specifies the pages selected by their page number parity.
+type PageParity int
+
+// Page parities.
+const (
+	AllPages PageParity = iota
+	OddPages
+	EvenPages
+)
+
+// OverlayOptions contains the options used for stamping pages.
+type OverlayOptions struct {
+	// Underlay draws the stamp under the page content. By default, the stamp
+	// is drawn over the page content.
+	Underlay bool
+
+	// Pages contains the ranges of the stamped pages. All the pages are
+	// stamped if empty. Only used when stamping documents.
+	Pages []PageRange
+
+	// Parity restricts the stamped pages to the odd or even pages. Only used
+	// when stamping documents.
+	Parity PageParity
+
+	// Fit scales the stamp uniformly so that it fits the stamped pages and
+	// centers it. By default, the stamp is not scaled and its lower left
+	// corner is placed at the lower left corner of the stamped pages.
+	Fit bool
+}
+
+// overlay represents a stamp applied to a page.
+type overlay struct {
+	// form contains the content of the stamp page.
+	form *model.XObjectForm
+
+	// box and rotate are the crop box and the rotation of the stamp page.
+	box    *model.PdfRectangle
+	rotate int64
+
+	underlay bool
+	fit      bool
+}
+
+// newOverlay returns the overlay drawing the page `stamp`.
+func newOverlay(stamp *model.PdfPage, opts *OverlayOptions) (*overlay, error) {
+	if stamp == nil {
+		return nil, errors.New("stamp page cannot be nil")
+	}
+	if opts == nil {
+		opts = &OverlayOptions{}
+	}
+
+	form, err := stamp.ToXObjectForm()
+	if err != nil {
+		return nil, err
+	}
+	box, err := stamp.GetCropBox()
+	if err != nil {
+		return nil, err
+	}
+	rotate, err := stamp.GetRotate()
+	if err != nil {
+		return nil, err
+	}
+	return &overlay{
+		form:     form,
+		box:      box,
+		rotate:   rotate,
+		underlay: opts.Underlay,
+		fit:      opts.Fit,
+	}, nil
+}
+
+// OverlayPage draws the content of the page `stamp` over or under the content
+// of the page `page`, as specified by `opts`. The stamp page is converted to a
+// Form XObject, so that its resources do not conflict with the resources of
+// the stamped page. The stamp is drawn upright in the orientation in which
+// the pages are displayed, taking their rotation into account. The page is
+// modified in place.
+func OverlayPage(page, stamp *model.PdfPage, opts *OverlayOptions) error {
+	if page == nil {
+		return errors.New("page cannot be nil")
+	}
+	ov, err := newOverlay(stamp, opts)
+	if err != nil {
+		return err
+	}
+	return ov.apply(page, 1, 1)
+}
+
+// Stamp returns a new document containing the pages of the document read by
+// `target`, with the page number `stampPage` of the document read by `stamp`
+// drawn over or under the pages selected by `opts`.
+func Stamp(target, stamp *model.PdfReader, stampPage int, opts *OverlayOptions) (*Document, error) {
+	if stamp == nil {
+		return nil, errors.New("stamp reader cannot be nil")
+	}
+	page, err := stamp.GetPage(stampPage)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := NewDocumentFromReader(target)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Overlay(page, opts); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Overlay draws the content of the page `stamp` over or under the content of
+// the pages of the document selected by `opts`. The stamp is applied to the
+// copies of the pages made when the document is written, so that the source
+// documents are not modified. See OverlayPage for details.
+func (d *Document) Overlay(stamp *model.PdfPage, opts *OverlayOptions) error {
+	if opts == nil {
+		opts = &OverlayOptions{}
+	}
+	ov, err := newOverlay(stamp, opts)
+	if err != nil {
+		return err
+	}
+
+	pages, err := d.selectPages(opts.Pages, opts.Parity)
+	if err != nil {
+		return err
+	}
+	d.addStamp(ov, pages)
+	return nil
+}
+
+// apply draws the overlay on the page `page`.
+func (ov *overlay) apply(page *model.PdfPage, pageNumber, numPages int) error {
+	tbox, err := page.GetCropBox()
+	if err != nil {
+		return err
+	}
+	trotate, err := page.GetRotate()
+	if err != nil {
+		return err
+	}
+
+	// Compute the transformation from the stamp space to the page space,
+	// through the display space of the pages.
+	sm, sw, sh := displayMatrix(ov.box, ov.rotate)
+	tm, tw, th := displayMatrix(tbox, trotate)
+	if sw == 0 || sh == 0 || tw == 0 || th == 0 {
+		return errors.New("empty page box")
+	}
+	place := pageMatrix{1, 0, 0, 1, 0, 0}
+	if ov.fit {
+		s := math.Min(tw/sw, th/sh)
+		place = pageMatrix{s, 0, 0, s, (tw - sw*s) / 2, (th - sh*s) / 2}
+	}
+	m := sm.mult(place).mult(tm.inverse())
+
+	if page.Resources == nil {
+		page.Resources = model.NewPdfPageResources()
+	}
+	name := page.Resources.GenerateXObjectName()
+	if err := page.Resources.SetXObjectFormByName(name, ov.form); err != nil {
+		return err
+	}
+
+	draw := contentstream.NewContentCreator().
+		Add_q().
+		Add_cm(m[0], m[1], m[2], m[3], m[4], m[5]).
+		Add_Do(name).
+		Add_Q()
+	return addPageContent(page, draw.Bytes(), ov.underlay)
+}
+
+// addPageContent adds the content stream `content` to the page, after the
+// page content or before it if `underlay` is true. The page content is
+// wrapped in a q/Q pair, so that the added content is drawn using the
+// default graphics state.
+func addPageContent(page *model.PdfPage, content []byte, underlay bool) error {
+	stream, err := core.MakeStream(content, core.NewFlateEncoder())
+	if err != nil {
+		return err
+	}
+
+	var existing []core.PdfObject
+	if arr, ok := core.GetArray(page.Contents); ok {
+		existing = arr.Elements()
+	} else if page.Contents != nil {
+		existing = []core.PdfObject{page.Contents}
+	}
+
+	contents := core.MakeArray()
+	if underlay {
+		contents.Append(stream)
+		contents.Append(existing...)
+	} else {
+		// Isolate the graphics state of the page content.
+		if len(existing) > 0 {
+			prefix, err := core.MakeStream(contentstream.NewContentCreator().Add_q().Bytes(), nil)
+			if err != nil {
+				return err
+			}
+			suffix, err := core.MakeStream(contentstream.NewContentCreator().Add_Q().Bytes(), nil)
+			if err != nil {
+				return err
+			}
+			contents.Append(prefix)
+			contents.Append(existing...)
+			contents.Append(suffix)
+		}
+		contents.Append(stream)
+	}
+	page.Contents = contents
+	return nil
+}
+
+// displayMatrix returns the matrix transforming the default user space of a
+// page having the crop box `box` and the rotation `rotate` into its display
+// space, whose origin is the lower left corner of the displayed page. The
+// size of the displayed page is also returned.
+func displayMatrix(box *model.PdfRectangle, rotate int64) (pageMatrix, float64, float64) {
+	w, h := box.Width(), box.Height()
+	switch rotate {
+	case 90:
+		return pageMatrix{0, -1, 1, 0, -box.Lly, w + box.Llx}, h, w
+	case 180:
+		return pageMatrix{-1, 0, 0, -1, box.Llx + w, box.Lly + h}, w, h
+	case 270:
+		return pageMatrix{0, 1, -1, 0, box.Lly + h, -box.Llx}, h, w
+	}
+	return pageMatrix{1, 0, 0, 1, -box.Llx, -box.Lly}, w, h
+}
@@ -0,0 +1,110 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// pageOperations returns the content stream operations of the page.
+func pageOperations(t *testing.T, page *model.PdfPage) *contentstream.ContentStreamOperations {
+	content, err := page.GetAllContentStreams()
+	require.NoError(t, err)
+	ops, err := contentstream.NewContentStreamParser(content).Parse()
+	require.NoError(t, err)
+	return ops
+}
+
+func TestOverlayPage(t *testing.T) {
+	stamp := model.NewPdfPage()
+	stamp.MediaBox = &model.PdfRectangle{Urx: 792, Ury: 612}
+	require.NoError(t, stamp.AddContentStreamByString("0 0 1 rg 0 0 100 100 re f"))
+
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	rotate := int64(90)
+	page.Rotate = &rotate
+	require.NoError(t, page.AddContentStreamByString("1 0 0 1 10 10 cm"))
+
+	// Overlay.
+	require.NoError(t, OverlayPage(page, stamp, nil))
+	ops := *pageOperations(t, page)
+	require.Len(t, ops, 7)
+	require.Equal(t, "q", ops[0].Operand)
+	require.Equal(t, "Q", ops[2].Operand)
+	require.Equal(t, "cm", ops[4].Operand)
+	require.Equal(t, "Do", ops[5].Operand)
+
+	// The stamp is drawn upright on the rotated page.
+	var err error
+	vals := make([]float64, 6)
+	for i, param := range ops[4].Params {
+		vals[i], err = core.GetNumberAsFloat(param)
+		require.NoError(t, err)
+	}
+	require.InDeltaSlice(t, []float64{0, 1, -1, 0, 612, 0}, vals, 1e-9)
+
+	xform, err := page.Resources.GetXObjectFormByName("XObj1")
+	require.NoError(t, err)
+	require.NotNil(t, xform)
+	formContent, err := xform.GetContentStream()
+	require.NoError(t, err)
+	require.Contains(t, string(formContent), "re f")
+
+	// Underlay with fit.
+	page = model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 396, Ury: 612}
+	require.NoError(t, page.AddContentStreamByString("0 0 m 10 10 l S"))
+	require.NoError(t, OverlayPage(page, stamp, &OverlayOptions{Underlay: true, Fit: true}))
+
+	ops = *pageOperations(t, page)
+	require.Equal(t, "q", ops[0].Operand)
+	require.Equal(t, "Do", ops[2].Operand)
+	require.Equal(t, "S", ops[len(ops)-1].Operand)
+	for i, param := range ops[1].Params {
+		vals[i], err = core.GetNumberAsFloat(param)
+		require.NoError(t, err)
+	}
+	require.InDeltaSlice(t, []float64{0.5, 0, 0, 0.5, 0, 153}, vals, 1e-9)
+}
+
+func TestStamp(t *testing.T) {
+	target := makeReader(t, 4, 100, "")
+	stamp := makeReader(t, 2, 300, "")
+
+	_, err := Stamp(target, stamp, 3, nil)
+	require.Error(t, err)
+
+	d, err := Stamp(target, stamp, 2, &OverlayOptions{Pages: []PageRange{{2, 0}}, Parity: OddPages})
+	require.NoError(t, err)
+	r := writeAndRead(t, d)
+
+	var stamped []bool
+	for i := 1; i <= 4; i++ {
+		page, err := r.GetPage(i)
+		require.NoError(t, err)
+		content, err := page.GetAllContentStreams()
+		require.NoError(t, err)
+		stamped = append(stamped, strings.Contains(content, "/XObj1 Do"))
+	}
+	require.Equal(t, []bool{false, false, true, false}, stamped)
+
+	// The source pages are not modified.
+	srcPage, err := target.GetPage(3)
+	require.NoError(t, err)
+	require.False(t, srcPage.Resources.HasXObjectByName("XObj1"))
+
+	var buf bytes.Buffer
+	require.NoError(t, d.Write(&buf))
+}
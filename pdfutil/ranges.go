@@ -0,0 +1,69 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PageRange represents an inclusive range of page numbers (1-based).
+// A Last value of 0 denotes the last page of the document.
+type PageRange struct {
+	First int
+	Last  int
+}
+
+// Pages returns the page numbers of the range, for a document containing
+// `numPages` pages.
+func (pr PageRange) Pages(numPages int) ([]int, error) {
+	last := pr.Last
+	if last == 0 {
+		last = numPages
+	}
+	if pr.First < 1 || last > numPages || pr.First > last {
+		return nil, fmt.Errorf("invalid page range %d-%d for %d pages", pr.First, pr.Last, numPages)
+	}
+
+	pages := make([]int, 0, last-pr.First+1)
+	for i := pr.First; i <= last; i++ {
+		pages = append(pages, i)
+	}
+	return pages, nil
+}
+
+// ParsePageRanges parses a comma separated list of page ranges such as
+// "1-3,5,8-". Open ranges extend to the last page of the document.
+func ParsePageRanges(s string) ([]PageRange, error) {
+	var ranges []PageRange
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid page range %q", part)
+		}
+		pr := PageRange{First: first, Last: first}
+		if len(bounds) == 2 {
+			pr.Last = 0
+			if last := strings.TrimSpace(bounds[1]); last != "" {
+				if pr.Last, err = strconv.Atoi(last); err != nil {
+					return nil, fmt.Errorf("invalid page range %q", part)
+				}
+			}
+		}
+		if pr.First < 1 || pr.Last < 0 || (pr.Last != 0 && pr.Last < pr.First) {
+			return nil, fmt.Errorf("invalid page range %q", part)
+		}
+		ranges = append(ranges, pr)
+	}
+	return ranges, nil
+}
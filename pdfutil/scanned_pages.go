@@ -0,0 +1,329 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// ScannedPageOptions contains the options used for detecting scanned pages.
+type ScannedPageOptions struct {
+	// MinCoverage is the minimal fraction of the page area covered by the
+	// image of scanned pages. Defaults to 0.9.
+	MinCoverage float64
+}
+
+// PageImageInfo describes an image painted on a page.
+type PageImageInfo struct {
+	// Name is the name of the image XObject, or is empty for inline images.
+	Name core.PdfObjectName
+
+	// Width and Height are the dimensions of the image, in pixels.
+	Width  int
+	Height int
+
+	// DPIX and DPIY are the effective resolutions of the image at the size it
+	// is painted, in pixels per inch along the image width and height.
+	DPIX float64
+	DPIY float64
+
+	// Coverage is the fraction of the page area covered by the image.
+	Coverage float64
+
+	// ColorSpace is the name of the color space family of the image, or
+	// ImageMask for stencil masks.
+	ColorSpace string
+
+	// Model is the color model of the color space of the image.
+	Model ColorModel
+
+	// BitsPerComponent is the number of bits per color component.
+	BitsPerComponent int
+
+	// Filters contains the names of the filters of the image data.
+	Filters []string
+}
+
+// ScannedPageInfo contains the result of the scanned page analysis of a page.
+type ScannedPageInfo struct {
+	// Scanned is true if the page is essentially a single image covering the
+	// page, without visible text.
+	Scanned bool
+
+	// Image is the image covering the largest fraction of the page, or nil if
+	// the page does not paint images.
+	Image *PageImageInfo
+
+	// NumImages is the number of images painted on the page.
+	NumImages int
+
+	// HasVisibleText is true if the page shows visible text.
+	HasVisibleText bool
+
+	// HasInvisibleText is true if the page shows invisible text, such as the
+	// text layers added by OCR software over scanned images.
+	HasInvisibleText bool
+}
+
+// DetectScannedPages returns the scanned page analysis of the pages of the
+// document read by `r`, according to the options `opts`. See
+// DetectScannedPage.
+func DetectScannedPages(r *model.PdfReader, opts *ScannedPageOptions) ([]*ScannedPageInfo, error) {
+	if r == nil {
+		return nil, errors.New("reader cannot be nil")
+	}
+	numPages, err := r.GetNumPages()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*ScannedPageInfo, 0, numPages)
+	for i := 1; i <= numPages; i++ {
+		page, err := r.GetPage(i)
+		if err != nil {
+			return nil, err
+		}
+		info, err := DetectScannedPage(page, opts)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %v", i, err)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// DetectScannedPage analyzes the images and the text painted by the content
+// stream of the page `page` and by the Form XObjects it paints. The page is
+// considered scanned if its largest image covers at least the MinCoverage
+// fraction of its area and if it does not show visible text. The returned
+// description of the largest image, e.g. its effective resolution and its
+// color space, allows deciding how to recompress it, and the invisible text
+// flag whether the page needs OCR.
+func DetectScannedPage(page *model.PdfPage, opts *ScannedPageOptions) (*ScannedPageInfo, error) {
+	if page == nil {
+		return nil, errors.New("page cannot be nil")
+	}
+	minCoverage := 0.9
+	if opts != nil && opts.MinCoverage > 0 {
+		minCoverage = opts.MinCoverage
+	}
+
+	mbox, err := page.GetMediaBox()
+	if err != nil {
+		return nil, err
+	}
+	content, err := page.GetAllContentStreams()
+	if err != nil {
+		return nil, err
+	}
+	resources := page.Resources
+	if resources == nil {
+		resources = model.NewPdfPageResources()
+	}
+
+	d := &scannedPageDetector{
+		mbox: mbox,
+		info: &ScannedPageInfo{},
+	}
+	if err := d.analyzeContent(content, resources, nil); err != nil {
+		return nil, err
+	}
+
+	info := d.info
+	info.Scanned = info.Image != nil && info.Image.Coverage >= minCoverage && !info.HasVisibleText
+	return info, nil
+}
+
+// scannedPageDetector collects the images and the text painted by the
+// content streams of a page.
+type scannedPageDetector struct {
+	mbox *model.PdfRectangle
+	info *ScannedPageInfo
+
+	// forms walks the Form XObjects painted by the content streams.
+	forms formWalker
+}
+
+// analyzeContent analyzes the content stream `content` using the resources
+// `resources`, starting with the graphics state `gs` or the default graphics
+// state if nil.
+func (d *scannedPageDetector) analyzeContent(content string, resources *model.PdfPageResources,
+	gs *contentstream.GraphicsState) error {
+	ops, err := contentstream.NewContentStreamParser(content).Parse()
+	if err != nil {
+		return err
+	}
+
+	// The text rendering mode is not tracked by the content stream
+	// processor.
+	renderMode := 0
+	var stack []int
+
+	proc := contentstream.NewContentStreamProcessor(*ops)
+	if gs != nil {
+		proc.SetInitialGraphicsState(*gs)
+	}
+	proc.AddHandler(contentstream.HandlerConditionEnumAllOperands, "",
+		func(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState,
+			resources *model.PdfPageResources) error {
+			switch op.Operand {
+			case "q":
+				stack = append(stack, renderMode)
+			case "Q":
+				if len(stack) > 0 {
+					renderMode = stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+				}
+			case "Tr":
+				if len(op.Params) == 1 {
+					if mode, ok := core.GetIntVal(op.Params[0]); ok {
+						renderMode = mode
+					}
+				}
+			case "Tj", "'", "\"", "TJ":
+				if renderMode == 3 {
+					d.info.HasInvisibleText = true
+				} else {
+					d.info.HasVisibleText = true
+				}
+			case "BI":
+				if len(op.Params) != 1 {
+					return nil
+				}
+				iimg, ok := op.Params[0].(*contentstream.ContentStreamInlineImage)
+				if !ok {
+					return nil
+				}
+				img := &PageImageInfo{}
+				img.Width, _ = core.GetIntVal(iimg.Width)
+				img.Height, _ = core.GetIntVal(iimg.Height)
+				if isMask, _ := iimg.IsMask(); isMask {
+					img.ColorSpace = "ImageMask"
+					img.BitsPerComponent = 1
+				} else {
+					img.BitsPerComponent, _ = core.GetIntVal(iimg.BitsPerComponent)
+					cs, err := iimg.GetColorSpace(resources)
+					if err != nil {
+						common.Log.Debug("ERROR: invalid inline image color space: %v", err)
+					}
+					d.setColorspace(img, cs)
+				}
+				img.Filters = filterNames(iimg.Filter)
+				d.addImage(img, gs.CTM)
+			case "Do":
+				if len(op.Params) != 1 {
+					return nil
+				}
+				name, ok := core.GetName(op.Params[0])
+				if !ok {
+					return nil
+				}
+				return d.paintXObject(*name, resources, gs)
+			}
+			return nil
+		})
+	return proc.Process(resources)
+}
+
+// paintXObject analyzes the XObject `name` of the resources `resources`,
+// painted with the graphics state `gs`.
+func (d *scannedPageDetector) paintXObject(name core.PdfObjectName, resources *model.PdfPageResources,
+	gs contentstream.GraphicsState) error {
+	stream, xtype := resources.GetXObjectByName(name)
+	switch xtype {
+	case model.XObjectTypeImage:
+		img := &PageImageInfo{Name: name}
+		img.Width, _ = core.GetIntVal(stream.Get("Width"))
+		img.Height, _ = core.GetIntVal(stream.Get("Height"))
+		if isMask, _ := core.GetBoolVal(stream.Get("ImageMask")); isMask {
+			img.ColorSpace = "ImageMask"
+			img.BitsPerComponent = 1
+		} else {
+			img.BitsPerComponent, _ = core.GetIntVal(stream.Get("BitsPerComponent"))
+			var cs model.PdfColorspace
+			if obj := stream.Get("ColorSpace"); obj != nil {
+				var err error
+				if cs, err = model.NewPdfColorspaceFromPdfObject(obj); err != nil {
+					common.Log.Debug("ERROR: invalid color space of image %s: %v", name, err)
+				}
+			}
+			d.setColorspace(img, cs)
+		}
+		img.Filters = filterNames(stream.Get("Filter"))
+		d.addImage(img, gs.CTM)
+	case model.XObjectTypeForm:
+		return d.forms.walk(stream, resources,
+			func(content []byte, formResources *model.PdfPageResources, matrix transform.Matrix) error {
+				gs.CTM = gs.CTM.Mult(matrix)
+				return d.analyzeContent(string(content), formResources, &gs)
+			})
+	}
+	return nil
+}
+
+// setColorspace sets the color space of the image `img` to `cs`.
+func (d *scannedPageDetector) setColorspace(img *PageImageInfo, cs model.PdfColorspace) {
+	if cs == nil {
+		return
+	}
+	img.ColorSpace = cs.String()
+	img.Model = colorModel(cs)
+}
+
+// addImage adds the image `img`, painted in the unit square transformed by
+// `ctm`, and keeps it if it covers the largest area of the page.
+func (d *scannedPageDetector) addImage(img *PageImageInfo, ctm transform.Matrix) {
+	d.info.NumImages++
+
+	// The image width and height are painted along the transformed axes.
+	width := math.Hypot(ctm[0], ctm[1])
+	height := math.Hypot(ctm[3], ctm[4])
+	if width > 0 {
+		img.DPIX = float64(img.Width) * 72 / width
+	}
+	if height > 0 {
+		img.DPIY = float64(img.Height) * 72 / height
+	}
+
+	// Bounding box of the image, clipped to the page.
+	bbox := ctmMatrix(ctm).transformRect(&model.PdfRectangle{Urx: 1, Ury: 1})
+	llx, lly := math.Max(bbox.Llx, d.mbox.Llx), math.Max(bbox.Lly, d.mbox.Lly)
+	urx, ury := math.Min(bbox.Urx, d.mbox.Urx), math.Min(bbox.Ury, d.mbox.Ury)
+	if area := d.mbox.Width() * d.mbox.Height(); urx > llx && ury > lly && area > 0 {
+		img.Coverage = math.Min((urx-llx)*(ury-lly)/area, 1)
+	}
+
+	if d.info.Image == nil || img.Coverage > d.info.Image.Coverage {
+		d.info.Image = img
+	}
+}
+
+// filterNames returns the names of the filters `obj`, which is a filter
+// name or an array of filter names.
+func filterNames(obj core.PdfObject) []string {
+	if name, ok := core.GetNameVal(obj); ok {
+		return []string{name}
+	}
+	arr, ok := core.GetArray(obj)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, elem := range arr.Elements() {
+		if name, ok := core.GetNameVal(elem); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
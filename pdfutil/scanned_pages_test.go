@@ -0,0 +1,71 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestDetectScannedPage(t *testing.T) {
+	// 1 bit image of 85x110 pixels, i.e. 10 DPI on a letter page.
+	img := &model.Image{Width: 85, Height: 110, BitsPerComponent: 1, ColorComponents: 1,
+		Data: make([]byte, 11*110)}
+	ximg, err := model.NewXObjectImageFromImage(img, nil, core.NewFlateEncoder())
+	require.NoError(t, err)
+
+	newPage := func(content string) *model.PdfPage {
+		page := model.NewPdfPage()
+		page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+		require.NoError(t, page.Resources.SetXObjectImageByName("Im1", ximg))
+		require.NoError(t, page.AddContentStreamByString(content))
+		return page
+	}
+
+	// Scanned page with an OCR text layer.
+	page := newPage("q 612 0 0 792 0 0 cm /Im1 Do Q BT 3 Tr (OCR) Tj ET")
+	info, err := DetectScannedPage(page, nil)
+	require.NoError(t, err)
+	require.True(t, info.Scanned)
+	require.True(t, info.HasInvisibleText)
+	require.False(t, info.HasVisibleText)
+	require.Equal(t, 1, info.NumImages)
+	require.Equal(t, &PageImageInfo{
+		Name:             "Im1",
+		Width:            85,
+		Height:           110,
+		DPIX:             10,
+		DPIY:             10,
+		Coverage:         1,
+		ColorSpace:       "DeviceGray",
+		Model:            ColorModelGray,
+		BitsPerComponent: 1,
+		Filters:          []string{"FlateDecode"},
+	}, info.Image)
+
+	// Image covering half of the page, rotated by 90 degrees.
+	page = newPage("q 0 396 -612 0 612 0 cm /Im1 Do Q")
+	info, err = DetectScannedPage(page, nil)
+	require.NoError(t, err)
+	require.False(t, info.Scanned)
+	require.InDelta(t, 0.5, info.Image.Coverage, 1e-9)
+	require.InDelta(t, 85*72/396.0, info.Image.DPIX, 1e-9)
+	require.InDelta(t, 110*72/612.0, info.Image.DPIY, 1e-9)
+	info, err = DetectScannedPage(page, &ScannedPageOptions{MinCoverage: 0.5})
+	require.NoError(t, err)
+	require.True(t, info.Scanned)
+
+	// Visible text and no images.
+	info, err = DetectScannedPage(newPage("BT (Text) Tj ET"), nil)
+	require.NoError(t, err)
+	require.False(t, info.Scanned)
+	require.True(t, info.HasVisibleText)
+	require.Nil(t, info.Image)
+}
@@ -0,0 +1,289 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// TextPosition represents the position of a text stamp on the page.
+type TextPosition int
+
+// Text stamp positions.
+const (
+	BottomCenter TextPosition = iota
+	BottomLeft
+	BottomRight
+	TopCenter
+	TopLeft
+	TopRight
+)
+
+// TextStamp represents a line of text, such as a header, a footer or a Bates
+// number, stamped on pages. The text can contain the following placeholders:
+//
+//	{page}  the page number
+//	{pages} the number of pages of the document
+//	{date}  the stamp date
+//	{bates} the Bates number of the page
+type TextStamp struct {
+	// Text is the stamped text.
+	Text string
+
+	// Position is the position of the text on the pages. The text is
+	// positioned relative to the crop box of the pages, in the orientation in
+	// which the pages are displayed.
+	Position TextPosition
+
+	// MarginX and MarginY are the distances between the text and the edges of
+	// the pages. The default margins are 36 points (1/2 inch).
+	MarginX float64
+	MarginY float64
+
+	// Font is the font of the text. Helvetica is used if nil.
+	Font *model.PdfFont
+
+	// FontSize is the font size. The default font size is 10.
+	FontSize float64
+
+	// Color is the color of the text. Black is used if nil.
+	Color *model.PdfColorDeviceRGB
+
+	// Date is the value of the {date} placeholder, formatted using
+	// DateFormat ("2006-01-02" by default). The current date is used if zero.
+	Date       time.Time
+	DateFormat string
+
+	// FirstPageNumber is the value of the {page} placeholder for the first
+	// page of the document. The default value is 1.
+	FirstPageNumber int
+
+	// BatesStart is the Bates number of the first page of the document. The
+	// Bates number of the page with the page number n is BatesStart + n - 1.
+	// The default value is 1.
+	BatesStart int
+
+	// BatesDigits is the minimum number of digits of the Bates numbers, which
+	// are padded with zeros. The default value is 6.
+	BatesDigits int
+
+	// BatesPrefix and BatesSuffix are added before and after the Bates
+	// numbers.
+	BatesPrefix string
+	BatesSuffix string
+
+	// Underlay draws the text under the page content. By default, the text is
+	// drawn over the page content.
+	Underlay bool
+
+	// Pages contains the ranges of the stamped pages. All the pages are
+	// stamped if empty. Only used when stamping documents.
+	Pages []PageRange
+
+	// Parity restricts the stamped pages to the odd or even pages. Only used
+	// when stamping documents.
+	Parity PageParity
+}
+
+// NewPageNumberStamp returns a text stamp drawing the page numbers, in the
+// "Page 1 of 10" format.
+func NewPageNumberStamp(position TextPosition) *TextStamp {
+	return &TextStamp{Text: "Page {page} of {pages}", Position: position}
+}
+
+// NewBatesStamp returns a text stamp drawing Bates numbers, starting with
+// the Bates number `start`.
+func NewBatesStamp(prefix string, start int, position TextPosition) *TextStamp {
+	return &TextStamp{
+		Text:        "{bates}",
+		Position:    position,
+		BatesPrefix: prefix,
+		BatesStart:  start,
+	}
+}
+
+// textStamp represents a text stamp applied to pages.
+type textStamp struct {
+	TextStamp
+
+	// fontObj is the PDF representation of the font, shared by the stamped
+	// pages.
+	fontObj core.PdfObject
+}
+
+// newTextStamp returns a text stamp using the options of `ts` with the
+// default values applied.
+func newTextStamp(ts *TextStamp) (*textStamp, error) {
+	if ts == nil {
+		return nil, errors.New("text stamp cannot be nil")
+	}
+
+	stamp := &textStamp{TextStamp: *ts}
+	if stamp.Font == nil {
+		font, err := model.NewStandard14Font(model.HelveticaName)
+		if err != nil {
+			return nil, err
+		}
+		stamp.Font = font
+	}
+	if stamp.FontSize <= 0 {
+		stamp.FontSize = 10
+	}
+	if stamp.MarginX == 0 {
+		stamp.MarginX = 36
+	}
+	if stamp.MarginY == 0 {
+		stamp.MarginY = 36
+	}
+	if stamp.Color == nil {
+		stamp.Color = model.NewPdfColorDeviceRGB(0, 0, 0)
+	}
+	if stamp.Date.IsZero() {
+		stamp.Date = time.Now()
+	}
+	if stamp.DateFormat == "" {
+		stamp.DateFormat = "2006-01-02"
+	}
+	if stamp.FirstPageNumber == 0 {
+		stamp.FirstPageNumber = 1
+	}
+	if stamp.BatesStart == 0 {
+		stamp.BatesStart = 1
+	}
+	if stamp.BatesDigits == 0 {
+		stamp.BatesDigits = 6
+	}
+	stamp.fontObj = stamp.Font.ToPdfObject()
+	return stamp, nil
+}
+
+// StampTextPage draws the text stamp `ts` on the page `page`, whose page
+// number is `pageNumber` in a document containing `numPages` pages. The page
+// is modified in place.
+func StampTextPage(page *model.PdfPage, ts *TextStamp, pageNumber, numPages int) error {
+	if page == nil {
+		return errors.New("page cannot be nil")
+	}
+	stamp, err := newTextStamp(ts)
+	if err != nil {
+		return err
+	}
+	return stamp.apply(page, pageNumber, numPages)
+}
+
+// StampText draws the text stamp `ts` on the pages of the document selected
+// by the Pages and Parity fields of the stamp. The placeholders are replaced
+// using the page numbers of the output document. The stamp is applied to
+// the copies of the pages made when the document is written, so that the
+// source documents are not modified.
+func (d *Document) StampText(ts *TextStamp) error {
+	stamp, err := newTextStamp(ts)
+	if err != nil {
+		return err
+	}
+	pages, err := d.selectPages(stamp.Pages, stamp.Parity)
+	if err != nil {
+		return err
+	}
+	d.addStamp(stamp, pages)
+	return nil
+}
+
+// text returns the stamped text for the page with the page number
+// `pageNumber` in a document containing `numPages` pages.
+func (ts *textStamp) text(pageNumber, numPages int) string {
+	bates := fmt.Sprintf("%s%0*d%s", ts.BatesPrefix, ts.BatesDigits, ts.BatesStart+pageNumber-1, ts.BatesSuffix)
+	replacer := strings.NewReplacer(
+		"{page}", strconv.Itoa(ts.FirstPageNumber+pageNumber-1),
+		"{pages}", strconv.Itoa(ts.FirstPageNumber+numPages-1),
+		"{date}", ts.Date.Format(ts.DateFormat),
+		"{bates}", bates,
+	)
+	return replacer.Replace(ts.Text)
+}
+
+// apply draws the text stamp on the page `page`.
+func (ts *textStamp) apply(page *model.PdfPage, pageNumber, numPages int) error {
+	text := ts.text(pageNumber, numPages)
+	encoded, numMisses := ts.Font.StringToCharcodeBytes(text)
+	if numMisses > 0 {
+		common.Log.Debug("Text stamp %q contains %d characters not supported by the font", text, numMisses)
+	}
+	var width float64
+	for _, r := range text {
+		if metrics, ok := ts.Font.GetRuneMetrics(r); ok {
+			width += metrics.Wx
+		}
+	}
+	width *= ts.FontSize / 1000
+
+	box, err := page.GetCropBox()
+	if err != nil {
+		return err
+	}
+	rotate, err := page.GetRotate()
+	if err != nil {
+		return err
+	}
+	dm, w, h := displayMatrix(box, rotate)
+
+	// Compute the position of the text in the display space.
+	var x, y float64
+	switch ts.Position {
+	case BottomLeft, TopLeft:
+		x = ts.MarginX
+	case BottomRight, TopRight:
+		x = w - ts.MarginX - width
+	default:
+		x = (w - width) / 2
+	}
+	switch ts.Position {
+	case TopCenter, TopLeft, TopRight:
+		y = h - ts.MarginY - ts.FontSize
+	default:
+		y = ts.MarginY
+	}
+	m := dm.inverse()
+
+	if page.Resources == nil {
+		page.Resources = model.NewPdfPageResources()
+	}
+	fontName := generateFontName(page.Resources)
+	if err := page.Resources.SetFontByName(fontName, ts.fontObj); err != nil {
+		return err
+	}
+
+	cc := contentstream.NewContentCreator().
+		Add_q().
+		Add_cm(m[0], m[1], m[2], m[3], m[4], m[5]).
+		Add_BT().
+		Add_Tf(fontName, ts.FontSize).
+		Add_rg(ts.Color.R(), ts.Color.G(), ts.Color.B()).
+		Add_Td(x, y).
+		Add_Tj(*core.MakeStringFromBytes(encoded)).
+		Add_ET().
+		Add_Q()
+	return addPageContent(page, cc.Bytes(), ts.Underlay)
+}
+
+// generateFontName returns a font name which is not used by the resources.
+func generateFontName(res *model.PdfPageResources) core.PdfObjectName {
+	for i := 1; ; i++ {
+		name := core.PdfObjectName(fmt.Sprintf("StampF%d", i))
+		if !res.HasFontByName(name) {
+			return name
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestTextStampText(t *testing.T) {
+	stamp, err := newTextStamp(&TextStamp{
+		Text:            "{page}/{pages} {date} {bates}",
+		Date:            time.Date(2020, 3, 14, 0, 0, 0, 0, time.UTC),
+		DateFormat:      "02.01.2006",
+		FirstPageNumber: 5,
+		BatesPrefix:     "ABC",
+		BatesSuffix:     "-X",
+		BatesStart:      98,
+		BatesDigits:     4,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "5/14 14.03.2020 ABC0098-X", stamp.text(1, 10))
+	require.Equal(t, "7/14 14.03.2020 ABC0100-X", stamp.text(3, 10))
+
+	stamp, err = newTextStamp(NewBatesStamp("DOC", 0, BottomRight))
+	require.NoError(t, err)
+	require.Equal(t, "DOC000002", stamp.text(2, 2))
+
+	_, err = newTextStamp(nil)
+	require.Error(t, err)
+}
+
+func TestStampTextPage(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	rotate := int64(90)
+	page.Rotate = &rotate
+	require.NoError(t, page.AddContentStreamByString("0 0 m 10 10 l S"))
+
+	require.NoError(t, StampTextPage(page, &TextStamp{Text: "Header", Position: TopLeft}, 1, 1))
+	ops := *pageOperations(t, page)
+	require.Equal(t, "q", ops[0].Operand)
+	require.Equal(t, "Q", ops[4].Operand)
+
+	var opNames []string
+	for _, op := range ops[5:] {
+		opNames = append(opNames, op.Operand)
+	}
+	require.Equal(t, []string{"q", "cm", "BT", "Tf", "rg", "Td", "Tj", "ET", "Q"}, opNames)
+
+	// The text is positioned at the top left corner of the displayed page.
+	vals := make([]float64, 2)
+	for i, param := range ops[10].Params {
+		val, err := core.GetNumberAsFloat(param)
+		require.NoError(t, err)
+		vals[i] = val
+	}
+	require.InDeltaSlice(t, []float64{36, 612 - 36 - 10}, vals, 1e-9)
+
+	str, ok := core.GetString(ops[11].Params[0])
+	require.True(t, ok)
+	require.Equal(t, "Header", str.Str())
+	require.True(t, page.Resources.HasFontByName("StampF1"))
+
+	// A second stamp uses another font name.
+	require.NoError(t, StampTextPage(page, &TextStamp{Text: "Footer", Underlay: true}, 1, 1))
+	require.True(t, page.Resources.HasFontByName("StampF2"))
+	ops = *pageOperations(t, page)
+	require.Equal(t, "BT", ops[2].Operand)
+}
+
+func TestDocumentStampText(t *testing.T) {
+	r := makeReader(t, 4, 100, "")
+	d, err := NewDocumentFromReader(r)
+	require.NoError(t, err)
+
+	require.NoError(t, d.StampText(NewPageNumberStamp(BottomCenter)))
+	require.NoError(t, d.StampText(&TextStamp{
+		Text:        "{bates}",
+		Position:    TopRight,
+		BatesPrefix: "B",
+		BatesStart:  10,
+		BatesDigits: 3,
+		Parity:      EvenPages,
+	}))
+	require.Error(t, d.StampText(&TextStamp{Pages: []PageRange{{5, 6}}}))
+
+	out := writeAndRead(t, d)
+	for i := 1; i <= 4; i++ {
+		page, err := out.GetPage(i)
+		require.NoError(t, err)
+		content, err := page.GetAllContentStreams()
+		require.NoError(t, err)
+
+		require.Contains(t, content, fmt.Sprintf("(Page %d of 4) Tj", i))
+		bates := fmt.Sprintf("(B%03d) Tj", i+9)
+		require.Equal(t, i%2 == 0, strings.Contains(content, bates), bates)
+	}
+
+	// The source pages are not modified.
+	srcPage, err := r.GetPage(1)
+	require.NoError(t, err)
+	require.False(t, srcPage.Resources.HasFontByName("StampF1"))
+}
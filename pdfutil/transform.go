@@ -0,0 +1,272 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"errors"
+	"math"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// ScaleOptions contains the options used for scaling pages.
+type ScaleOptions struct {
+	// Stretch disables the preservation of the aspect ratio of the page
+	// content. By default, the content is scaled uniformly so that it fits
+	// the new page size.
+	Stretch bool
+
+	// NoCentering disables the centering of the scaled content on the new
+	// page. By default, the content is centered when its aspect ratio does
+	// not match the aspect ratio of the new page.
+	NoCentering bool
+}
+
+// ScalePage scales the content of the page so that its media box fits the
+// page size `width` x `height` (e.g. for converting A4 pages to Letter
+// pages). The media box of the page is replaced with [0 0 width height] and
+// the other page boxes and the annotations are transformed accordingly. The
+// page size is specified in the default user space of the page, i.e. before
+// applying the rotation of the page. The page is modified in place.
+func ScalePage(page *model.PdfPage, width, height float64, opts *ScaleOptions) error {
+	if page == nil {
+		return errors.New("page cannot be nil")
+	}
+	if width <= 0 || height <= 0 {
+		return errors.New("invalid page size")
+	}
+	if opts == nil {
+		opts = &ScaleOptions{}
+	}
+
+	mbox, err := page.GetBox(model.PageBoxMedia)
+	if err != nil {
+		return err
+	}
+	if mbox.Width() == 0 || mbox.Height() == 0 {
+		return errors.New("empty media box")
+	}
+
+	sx, sy := width/mbox.Width(), height/mbox.Height()
+	if !opts.Stretch {
+		sx = math.Min(sx, sy)
+		sy = sx
+	}
+	tx, ty := -mbox.Llx*sx, -mbox.Lly*sy
+	if !opts.NoCentering {
+		tx += (width - mbox.Width()*sx) / 2
+		ty += (height - mbox.Height()*sy) / 2
+	}
+
+	if err := TransformPage(page, sx, 0, 0, sy, tx, ty); err != nil {
+		return err
+	}
+	page.MediaBox = &model.PdfRectangle{Urx: width, Ury: height}
+	return nil
+}
+
+// TransformPage transforms the content of the page using the transformation
+// matrix [a b c d e f], as specified by the cm operator. The page content
+// streams are wrapped in a transformation and the page boxes and the
+// annotation coordinates are transformed accordingly. The boxes and the
+// annotation rectangles are replaced with the bounding boxes of their
+// transformed corners. The page is modified in place.
+func TransformPage(page *model.PdfPage, a, b, c, d, e, f float64) error {
+	if page == nil {
+		return errors.New("page cannot be nil")
+	}
+	m := pageMatrix{a, b, c, d, e, f}
+	if m[0]*m[3]-m[1]*m[2] == 0 {
+		return errors.New("transformation matrix not invertible")
+	}
+
+	// Transform page boxes. The crop box is set explicitly, as it can be
+	// inherited from the page tree.
+	mbox, err := page.GetBox(model.PageBoxMedia)
+	if err != nil {
+		return err
+	}
+	cbox, err := page.GetBox(model.PageBoxCrop)
+	if err != nil {
+		return err
+	}
+	if page.CropBox != nil || *cbox != *mbox {
+		page.CropBox = m.transformRect(cbox)
+	}
+	page.MediaBox = m.transformRect(mbox)
+	for _, box := range []**model.PdfRectangle{&page.BleedBox, &page.TrimBox, &page.ArtBox} {
+		if *box != nil {
+			*box = m.transformRect(*box)
+		}
+	}
+
+	// Wrap page content.
+	if page.Contents != nil {
+		prefix, err := core.MakeStream(contentstream.NewContentCreator().
+			Add_q().
+			Add_cm(a, b, c, d, e, f).
+			Bytes(), core.NewFlateEncoder())
+		if err != nil {
+			return err
+		}
+		suffix, err := core.MakeStream(contentstream.NewContentCreator().
+			Add_Q().
+			Bytes(), core.NewFlateEncoder())
+		if err != nil {
+			return err
+		}
+
+		contents := core.MakeArray(prefix)
+		if arr, ok := core.GetArray(page.Contents); ok {
+			contents.Append(arr.Elements()...)
+		} else {
+			contents.Append(page.Contents)
+		}
+		contents.Append(suffix)
+		page.Contents = contents
+	}
+
+	// Transform annotations.
+	annots, err := page.GetAnnotations()
+	if err != nil {
+		return err
+	}
+	for _, annot := range annots {
+		if err := m.transformAnnotation(annot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pageMatrix represents a transformation matrix [a b c d e f].
+type pageMatrix [6]float64
+
+// ctmMatrix returns the matrix of the current transformation matrix `ctm`
+// of a graphics state.
+func ctmMatrix(ctm transform.Matrix) pageMatrix {
+	return pageMatrix{ctm[0], ctm[1], ctm[3], ctm[4], ctm[6], ctm[7]}
+}
+
+// transform returns the transformed coordinates of the point (x, y).
+func (m pageMatrix) transform(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// mult returns the matrix which applies the transformation of `m` followed
+// by the transformation of `n`.
+func (m pageMatrix) mult(n pageMatrix) pageMatrix {
+	return pageMatrix{
+		n[0]*m[0] + n[2]*m[1],
+		n[1]*m[0] + n[3]*m[1],
+		n[0]*m[2] + n[2]*m[3],
+		n[1]*m[2] + n[3]*m[3],
+		n[0]*m[4] + n[2]*m[5] + n[4],
+		n[1]*m[4] + n[3]*m[5] + n[5],
+	}
+}
+
+// inverse returns the inverse of the matrix, which must be invertible.
+func (m pageMatrix) inverse() pageMatrix {
+	det := m[0]*m[3] - m[1]*m[2]
+	return pageMatrix{
+		m[3] / det,
+		-m[1] / det,
+		-m[2] / det,
+		m[0] / det,
+		(m[2]*m[5] - m[3]*m[4]) / det,
+		(m[1]*m[4] - m[0]*m[5]) / det,
+	}
+}
+
+// transformRect returns the bounding box of the transformed corners of the
+// rectangle `rect`.
+func (m pageMatrix) transformRect(rect *model.PdfRectangle) *model.PdfRectangle {
+	res := &model.PdfRectangle{
+		Llx: math.Inf(1), Lly: math.Inf(1),
+		Urx: math.Inf(-1), Ury: math.Inf(-1),
+	}
+	for _, p := range [][2]float64{
+		{rect.Llx, rect.Lly}, {rect.Urx, rect.Lly},
+		{rect.Urx, rect.Ury}, {rect.Llx, rect.Ury},
+	} {
+		x, y := m.transform(p[0], p[1])
+		res.Llx, res.Urx = math.Min(res.Llx, x), math.Max(res.Urx, x)
+		res.Lly, res.Ury = math.Min(res.Lly, y), math.Max(res.Ury, y)
+	}
+	return res
+}
+
+// transformPoints returns a copy of the coordinate array `obj`, containing
+// x and y coordinates pairs, with the points transformed. Invalid arrays are
+// returned unmodified.
+func (m pageMatrix) transformPoints(obj core.PdfObject) core.PdfObject {
+	arr, ok := core.GetArray(obj)
+	if !ok {
+		return obj
+	}
+	vals, err := arr.ToFloat64Array()
+	if err != nil || len(vals)%2 != 0 {
+		common.Log.Debug("Invalid coordinates array: %v", obj)
+		return obj
+	}
+	for i := 0; i < len(vals); i += 2 {
+		vals[i], vals[i+1] = m.transform(vals[i], vals[i+1])
+	}
+	return core.MakeArrayFromFloats(vals)
+}
+
+// transformAnnotation transforms the rectangle and the coordinates of the
+// annotation `annot`.
+func (m pageMatrix) transformAnnotation(annot *model.PdfAnnotation) error {
+	if annot.Rect != nil {
+		arr, ok := core.GetArray(annot.Rect)
+		if !ok {
+			return errors.New("invalid annotation rectangle")
+		}
+		rect, err := model.NewPdfRectangle(*arr)
+		if err != nil {
+			return err
+		}
+		annot.Rect = m.transformRect(rect).ToPdfObject()
+	}
+
+	switch t := annot.GetContext().(type) {
+	case *model.PdfAnnotationLink:
+		t.QuadPoints = m.transformPoints(t.QuadPoints)
+	case *model.PdfAnnotationHighlight:
+		t.QuadPoints = m.transformPoints(t.QuadPoints)
+	case *model.PdfAnnotationUnderline:
+		t.QuadPoints = m.transformPoints(t.QuadPoints)
+	case *model.PdfAnnotationSquiggly:
+		t.QuadPoints = m.transformPoints(t.QuadPoints)
+	case *model.PdfAnnotationStrikeOut:
+		t.QuadPoints = m.transformPoints(t.QuadPoints)
+	case *model.PdfAnnotationRedact:
+		t.QuadPoints = m.transformPoints(t.QuadPoints)
+	case *model.PdfAnnotationLine:
+		t.L = m.transformPoints(t.L)
+	case *model.PdfAnnotationFreeText:
+		t.CL = m.transformPoints(t.CL)
+	case *model.PdfAnnotationPolygon:
+		t.Vertices = m.transformPoints(t.Vertices)
+	case *model.PdfAnnotationPolyLine:
+		t.Vertices = m.transformPoints(t.Vertices)
+	case *model.PdfAnnotationInk:
+		if paths, ok := core.GetArray(t.InkList); ok {
+			inkList := core.MakeArray()
+			for _, path := range paths.Elements() {
+				inkList.Append(m.transformPoints(path))
+			}
+			t.InkList = inkList
+		}
+	}
+	return nil
+}
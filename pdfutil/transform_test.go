@@ -0,0 +1,102 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestScalePage(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 595, Ury: 842}
+	page.TrimBox = &model.PdfRectangle{Llx: 10, Lly: 10, Urx: 585, Ury: 832}
+	require.NoError(t, page.AddContentStreamByString("0 0 m 595 842 l S"))
+
+	link := model.NewPdfAnnotationLink()
+	link.Rect = core.MakeArrayFromFloats([]float64{100, 100, 200, 200})
+	link.QuadPoints = core.MakeArrayFromFloats([]float64{100, 100, 200, 100, 200, 200, 100, 200})
+	page.AddAnnotation(link.PdfAnnotation)
+
+	ink := model.NewPdfAnnotationInk()
+	ink.Rect = core.MakeArrayFromFloats([]float64{0, 0, 595, 842})
+	ink.InkList = core.MakeArray(core.MakeArrayFromFloats([]float64{0, 0, 595, 842}))
+	page.AddAnnotation(ink.PdfAnnotation)
+
+	require.Error(t, ScalePage(page, 0, 792, nil))
+	require.NoError(t, ScalePage(page, 612, 792, nil))
+
+	scale := 792.0 / 842.0
+	tx := (612 - 595*scale) / 2
+	require.Equal(t, model.PdfRectangle{Urx: 612, Ury: 792}, *page.MediaBox)
+	require.Nil(t, page.CropBox)
+	require.InDelta(t, 10*scale+tx, page.TrimBox.Llx, 1e-6)
+	require.InDelta(t, 832*scale, page.TrimBox.Ury, 1e-6)
+
+	// Write and read back.
+	w := model.NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	readPage, err := r.GetPage(1)
+	require.NoError(t, err)
+
+	content, err := readPage.GetAllContentStreams()
+	require.NoError(t, err)
+	fields := strings.Fields(content)
+	require.Equal(t, []string{"q", "cm"}, []string{fields[0], fields[7]})
+	require.Equal(t, "Q", fields[len(fields)-1])
+
+	annots, err := readPage.GetAnnotations()
+	require.NoError(t, err)
+	require.Len(t, annots, 2)
+
+	rectArr, ok := core.GetArray(annots[0].Rect)
+	require.True(t, ok)
+	rect, err := rectArr.ToFloat64Array()
+	require.NoError(t, err)
+	expected := []float64{100*scale + tx, 100 * scale, 200*scale + tx, 200 * scale}
+	require.InDeltaSlice(t, expected, rect, 1e-4)
+
+	readLink, ok := annots[0].GetContext().(*model.PdfAnnotationLink)
+	require.True(t, ok)
+	quadArr, ok := core.GetArray(readLink.QuadPoints)
+	require.True(t, ok)
+	quad, err := quadArr.ToFloat64Array()
+	require.NoError(t, err)
+	require.InDelta(t, expected[0], quad[0], 1e-4)
+	require.InDelta(t, expected[3], quad[5], 1e-4)
+
+	readInk, ok := annots[1].GetContext().(*model.PdfAnnotationInk)
+	require.True(t, ok)
+	paths, ok := core.GetArray(readInk.InkList)
+	require.True(t, ok)
+	path, err := paths.Get(0).(*core.PdfObjectArray).ToFloat64Array()
+	require.NoError(t, err)
+	require.InDeltaSlice(t, []float64{tx, 0, 612 - tx, 792}, path, 1e-4)
+}
+
+func TestScalePageStretch(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Llx: 100, Lly: 100, Urx: 300, Ury: 200}
+	page.CropBox = &model.PdfRectangle{Llx: 150, Lly: 100, Urx: 250, Ury: 200}
+
+	require.NoError(t, ScalePage(page, 400, 400, &ScaleOptions{Stretch: true}))
+	require.Equal(t, model.PdfRectangle{Urx: 400, Ury: 400}, *page.MediaBox)
+	require.Equal(t, model.PdfRectangle{Llx: 100, Lly: 0, Urx: 300, Ury: 400}, *page.CropBox)
+	require.Nil(t, page.Contents)
+
+	require.Error(t, TransformPage(page, 0, 0, 0, 0, 0, 0))
+}
@@ -0,0 +1,54 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// formWalker walks the Form XObjects and tiling patterns drawn by content
+// streams. The zero value is ready to use.
+type formWalker struct {
+	// visited contains the streams being walked, so that reference cycles
+	// are not followed.
+	visited map[*core.PdfObjectStream]struct{}
+}
+
+// walk calls `fn` with the decoded content of the Form XObject or tiling
+// pattern `stream`, drawn with the resources `parent`, with its resources and
+// its matrix. Forms without resources use the resources of the parent. `fn`
+// is not called if `stream` is already being walked, i.e. if it draws itself.
+func (w *formWalker) walk(stream *core.PdfObjectStream, parent *model.PdfPageResources,
+	fn func(content []byte, resources *model.PdfPageResources, matrix transform.Matrix) error) error {
+	if _, ok := w.visited[stream]; ok {
+		return nil
+	}
+	if w.visited == nil {
+		w.visited = map[*core.PdfObjectStream]struct{}{}
+	}
+	w.visited[stream] = struct{}{}
+	defer delete(w.visited, stream)
+
+	content, err := core.DecodeStream(stream)
+	if err != nil {
+		return err
+	}
+	resources := parent
+	if dict, ok := core.GetDict(stream.Get("Resources")); ok {
+		if resources, err = model.NewPdfPageResourcesFromDict(dict); err != nil {
+			return err
+		}
+	}
+	matrix := transform.IdentityMatrix()
+	if arr, ok := core.GetArray(stream.Get("Matrix")); ok {
+		if vals, err := arr.ToFloat64Array(); err == nil && len(vals) == 6 {
+			matrix = transform.NewMatrix(vals[0], vals[1], vals[2], vals[3], vals[4], vals[5])
+		}
+	}
+	return fn(content, resources, matrix)
+}
@@ -0,0 +1,47 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package pdfutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestFormWalker(t *testing.T) {
+	stream, err := core.MakeStream([]byte("/Fm1 Do"), core.NewRawEncoder())
+	require.NoError(t, err)
+	stream.Set("Matrix", core.MakeArrayFromFloats([]float64{2, 0, 0, 2, 10, 20}))
+	parent := model.NewPdfPageResources()
+
+	// The form draws itself: the nested walk of the same stream is skipped.
+	var w formWalker
+	calls := 0
+	var walk func(content []byte, resources *model.PdfPageResources, matrix transform.Matrix) error
+	walk = func(content []byte, resources *model.PdfPageResources, matrix transform.Matrix) error {
+		calls++
+		require.Equal(t, "/Fm1 Do", string(content))
+		require.True(t, resources == parent)
+		require.Equal(t, transform.NewMatrix(2, 0, 0, 2, 10, 20), matrix)
+		return w.walk(stream, resources, walk)
+	}
+	require.NoError(t, w.walk(stream, parent, walk))
+	require.Equal(t, 1, calls)
+
+	// The resources of the form are used if set.
+	stream.Set("Resources", core.MakeDict())
+	require.NoError(t, w.walk(stream, parent,
+		func(content []byte, resources *model.PdfPageResources, matrix transform.Matrix) error {
+			calls++
+			require.False(t, resources == parent)
+			return nil
+		}))
+	require.Equal(t, 2, calls)
+}
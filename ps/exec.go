@@ -50,6 +50,7 @@ func (exec *PSExecutor) Execute(objects []PSObject) ([]PSObject, error) {
 	for _, obj := range objects {
 		err := exec.Stack.Push(obj)
 		if err != nil {
+			exec.Stack.Empty()
 			return nil, err
 		}
 	}
@@ -57,6 +58,8 @@ func (exec *PSExecutor) Execute(objects []PSObject) ([]PSObject, error) {
 	err := exec.program.Exec(exec.Stack)
 	if err != nil {
 		common.Log.Debug("Exec failed: %v", err)
+		// Reset the stack for the next execution.
+		exec.Stack.Empty()
 		return nil, err
 	}
 
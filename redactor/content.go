@@ -0,0 +1,893 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package redactor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// maxFormDepth is the maximum nesting level of the Form XObjects which are
+// redacted, which prevents infinite recursion in invalid files.
+const maxFormDepth = 10
+
+// Approximate ascent and descent of the glyphs, used for computing the
+// bounding boxes of the glyphs in text space units.
+const (
+	glyphAscent  = 0.8
+	glyphDescent = -0.2
+)
+
+// minGlyphOverlap is the minimum fraction of the area of a glyph which has to
+// be covered by a redacted area for the glyph to be redacted.
+const minGlyphOverlap = 0.25
+
+// redactor removes the content located in the redacted areas from content
+// streams.
+type redactor struct {
+	areas []Area
+
+	// fonts caches the fonts loaded from font dictionaries.
+	fonts map[core.PdfObject]*model.PdfFont
+
+	// forms caches the redacted copies of Form XObjects, by original stream
+	// and transformation, so that forms drawn multiple times with the same
+	// CTM are redacted only once.
+	forms map[formKey]*model.XObjectForm
+
+	// xobjects tracks the XObjects drawn by the redacted content streams, by
+	// resources, so that the redacted originals can be removed.
+	xobjects map[*model.PdfPageResources]*xobjectRefs
+
+	// removeOps specifies whether the operations intersecting the areas are
+	// removed as a whole, including path painting operations, rather than
+	// redacted. See RemovePageContent.
+	removeOps bool
+}
+
+// formKey identifies a redacted Form XObject.
+type formKey struct {
+	stream *core.PdfObjectStream
+	ctm    transform.Matrix
+}
+
+// xobjectRefs tracks the XObject names of a resources dictionary which are
+// drawn by the redacted content streams.
+type xobjectRefs struct {
+	// replaced contains the XObjects which were removed from or replaced in
+	// the content streams, and used contains the XObjects drawn by the
+	// redacted content streams.
+	replaced map[core.PdfObjectName]bool
+	used     map[core.PdfObjectName]bool
+
+	// keep specifies whether the XObjects have to be kept, because the
+	// resources are also used by forms which are not redacted.
+	keep bool
+}
+
+// textState represents the text state parameters (section 9.3 p. 243 PDF32000_2008).
+type textState struct {
+	font        *model.PdfFont
+	fontSize    float64
+	charSpacing float64
+	wordSpacing float64
+	hScale      float64
+	leading     float64
+	rise        float64
+}
+
+// markedContent represents an open marked content sequence.
+type markedContent struct {
+	op       *contentstream.ContentStreamOperation
+	redacted bool
+}
+
+// contentRedactor holds the state used while redacting a content stream.
+type contentRedactor struct {
+	*redactor
+	resources *model.PdfPageResources
+	xobjects  *xobjectRefs
+	baseCTM   transform.Matrix
+	depth     int
+
+	ts      textState
+	tsStack []textState
+	tm      transform.Matrix // Text matrix.
+	tlm     transform.Matrix // Text line matrix.
+
+	marked []*markedContent
+	out    contentstream.ContentStreamOperations
+
+	// Path construction and clipping operations of the current path, and
+	// the points of the path in the page default user space, when removing
+	// operations.
+	path       []*contentstream.ContentStreamOperation
+	pathPoints []float64
+}
+
+// newRedactor returns a new redactor for the specified areas.
+func newRedactor(areas []Area) *redactor {
+	return &redactor{
+		areas: areas,
+		fonts: map[core.PdfObject]*model.PdfFont{},
+		forms: map[formKey]*model.XObjectForm{},
+
+		xobjects: map[*model.PdfPageResources]*xobjectRefs{},
+	}
+}
+
+// redactPage redacts the content streams of `page`. The XObjects of the page
+// resources which are not drawn anymore by the redacted content are removed
+// from the resources, so that the redacted content cannot be recovered from
+// them.
+func (rd *redactor) redactPage(page *model.PdfPage) (*contentstream.ContentStreamOperations, error) {
+	contents, err := page.GetAllContentStreams()
+	if err != nil {
+		return nil, err
+	}
+	if page.Resources == nil {
+		page.Resources = model.NewPdfPageResources()
+	}
+	copyXObjects(page.Resources)
+
+	ops, err := rd.redactContent(contents, page.Resources, identityMatrix(), 0)
+	if err != nil {
+		return nil, err
+	}
+	rd.removeReplacedXObjects()
+	return ops, nil
+}
+
+// removeReplacedXObjects removes the XObjects which were removed from or
+// replaced in the redacted content streams, and which are not drawn anymore,
+// from their resources.
+func (rd *redactor) removeReplacedXObjects() {
+	for resources, refs := range rd.xobjects {
+		if refs.keep {
+			continue
+		}
+		dict, ok := core.GetDict(resources.XObject)
+		if !ok {
+			continue
+		}
+		for name := range refs.replaced {
+			if !refs.used[name] {
+				common.Log.Debug("Removing redacted XObject %s from resources", name)
+				dict.Remove(name)
+			}
+		}
+	}
+}
+
+// copyXObjects replaces the XObject dictionary of `resources` with a copy, so
+// that adding the redacted XObjects or removing the original ones does not
+// affect the other pages or forms sharing the dictionary.
+func copyXObjects(resources *model.PdfPageResources) {
+	if dict, ok := core.GetDict(resources.XObject); ok {
+		resources.XObject = core.MakeDict().Merge(dict)
+	}
+}
+
+// identityMatrix returns the identity transformation matrix.
+func identityMatrix() transform.Matrix {
+	return transform.IdentityMatrix()
+}
+
+// redactContent redacts the content stream `contents`, using `resources`.
+// The `ctm` matrix maps the user space of the content stream to the default
+// user space of the page. The returned operations are balanced with respect
+// to the q/Q operators.
+func (rd *redactor) redactContent(contents string, resources *model.PdfPageResources,
+	ctm transform.Matrix, depth int) (*contentstream.ContentStreamOperations, error) {
+	ops, err := contentstream.NewContentStreamParser(contents).Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	refs, ok := rd.xobjects[resources]
+	if !ok {
+		refs = &xobjectRefs{
+			replaced: map[core.PdfObjectName]bool{},
+			used:     map[core.PdfObjectName]bool{},
+		}
+		rd.xobjects[resources] = refs
+	}
+
+	cr := &contentRedactor{
+		redactor:  rd,
+		resources: resources,
+		xobjects:  refs,
+		baseCTM:   ctm,
+		depth:     depth,
+		ts:        textState{hScale: 1},
+		tm:        transform.IdentityMatrix(),
+		tlm:       transform.IdentityMatrix(),
+	}
+
+	proc := contentstream.NewContentStreamProcessor(*ops)
+	proc.AddHandler(contentstream.HandlerConditionEnumAllOperands, "",
+		func(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState,
+			resources *model.PdfPageResources) error {
+			return cr.processOp(op, gs)
+		})
+	if err := proc.Process(resources); err != nil {
+		return nil, err
+	}
+
+	// Emit the path left unpainted, then close the marked content sequences
+	// and graphics states left open.
+	cr.flushPath()
+	cr.closeMarkedContent(len(cr.marked))
+	for range cr.tsStack {
+		cr.out = append(cr.out, &contentstream.ContentStreamOperation{Operand: "Q"})
+	}
+	return &cr.out, nil
+}
+
+// processOp processes the operation `op`, appending the redacted operations
+// to the output.
+func (cr *contentRedactor) processOp(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState) error {
+	// Matrix mapping the current user space to the page default user space.
+	ctm := cr.baseCTM.Mult(gs.CTM)
+	if cr.removeOps && cr.processPathOp(op, ctm) {
+		return nil
+	}
+
+	switch op.Operand {
+	case "q":
+		cr.tsStack = append(cr.tsStack, cr.ts)
+	case "Q":
+		if len(cr.tsStack) == 0 {
+			// Unbalanced operator, skipped by the processor as well.
+			return nil
+		}
+		cr.ts = cr.tsStack[len(cr.tsStack)-1]
+		cr.tsStack = cr.tsStack[:len(cr.tsStack)-1]
+	case "BT":
+		cr.tm = transform.IdentityMatrix()
+		cr.tlm = transform.IdentityMatrix()
+	case "Tf":
+		cr.setFont(op)
+	case "Tc":
+		cr.ts.charSpacing = floatParam(op, 0)
+	case "Tw":
+		cr.ts.wordSpacing = floatParam(op, 0)
+	case "Tz":
+		cr.ts.hScale = floatParam(op, 0) / 100
+	case "TL":
+		cr.ts.leading = floatParam(op, 0)
+	case "Ts":
+		cr.ts.rise = floatParam(op, 0)
+	case "Td":
+		cr.moveLine(floatParam(op, 0), floatParam(op, 1))
+	case "TD":
+		cr.ts.leading = -floatParam(op, 1)
+		cr.moveLine(floatParam(op, 0), floatParam(op, 1))
+	case "Tm":
+		if vals, err := core.GetNumbersAsFloat(op.Params); err == nil && len(vals) == 6 {
+			cr.tlm = transform.NewMatrix(vals[0], vals[1], vals[2], vals[3], vals[4], vals[5])
+			cr.tm = cr.tlm
+		}
+	case "T*":
+		cr.moveLine(0, -cr.ts.leading)
+	case "Tj":
+		if len(op.Params) == 1 {
+			cr.showText(op, core.MakeArray(op.Params[0]), ctm, nil)
+			return nil
+		}
+	case "TJ":
+		if arr, ok := core.GetArray(firstParam(op)); ok {
+			cr.showText(op, arr, ctm, nil)
+			return nil
+		}
+	case "'":
+		if len(op.Params) == 1 {
+			cr.moveLine(0, -cr.ts.leading)
+			lineOp := &contentstream.ContentStreamOperation{Operand: "T*"}
+			cr.showText(op, core.MakeArray(op.Params[0]), ctm, []*contentstream.ContentStreamOperation{lineOp})
+			return nil
+		}
+	case "\"":
+		if len(op.Params) == 3 {
+			cr.ts.wordSpacing = floatParam(op, 0)
+			cr.ts.charSpacing = floatParam(op, 1)
+			cr.moveLine(0, -cr.ts.leading)
+			prefix := []*contentstream.ContentStreamOperation{
+				{Operand: "Tw", Params: []core.PdfObject{op.Params[0]}},
+				{Operand: "Tc", Params: []core.PdfObject{op.Params[1]}},
+				{Operand: "T*"},
+			}
+			cr.showText(op, core.MakeArray(op.Params[2]), ctm, prefix)
+			return nil
+		}
+	case "BMC", "BDC":
+		cr.marked = append(cr.marked, &markedContent{op: op})
+	case "EMC":
+		if len(cr.marked) > 0 {
+			cr.closeMarkedContent(1)
+		}
+	case "Do":
+		return cr.processXObject(op, ctm)
+	case "BI":
+		if cr.intersectsUnitSquare(ctm) {
+			// Inline images overlapping the redacted areas are removed.
+			common.Log.Debug("Redacting inline image")
+			return nil
+		}
+	}
+
+	cr.out = append(cr.out, op)
+	return nil
+}
+
+// moveLine moves to the start of the next line, offset from the start of the
+// current line by (tx, ty).
+func (cr *contentRedactor) moveLine(tx, ty float64) {
+	cr.tlm.Concat(transform.TranslationMatrix(tx, ty))
+	cr.tm = cr.tlm
+}
+
+// setFont sets the current font from the parameters of the Tf operator.
+func (cr *contentRedactor) setFont(op *contentstream.ContentStreamOperation) {
+	cr.ts.font = nil
+	cr.ts.fontSize = floatParam(op, 1)
+
+	name, ok := core.GetName(firstParam(op))
+	if !ok || cr.resources == nil {
+		return
+	}
+	fontObj, ok := cr.resources.GetFontByName(*name)
+	if !ok {
+		common.Log.Debug("Font %s not found in resources", name)
+		return
+	}
+
+	if font, ok := cr.fonts[fontObj]; ok {
+		cr.ts.font = font
+		return
+	}
+	font, err := model.NewPdfFontFromPdfObject(fontObj)
+	if err != nil {
+		common.Log.Debug("ERROR: could not load font %s: %v", name, err)
+		return
+	}
+	cr.fonts[fontObj] = font
+	cr.ts.font = font
+}
+
+// closeMarkedContent closes the last `n` marked content sequences. The
+// replacement text properties of the sequences containing redacted content
+// are removed, so that the redacted text cannot be recovered from them.
+func (cr *contentRedactor) closeMarkedContent(n int) {
+	for i := 0; i < n && len(cr.marked) > 0; i++ {
+		mc := cr.marked[len(cr.marked)-1]
+		cr.marked = cr.marked[:len(cr.marked)-1]
+		if !mc.redacted || len(mc.op.Params) < 2 {
+			continue
+		}
+
+		props, ok := core.GetDict(mc.op.Params[1])
+		if !ok {
+			continue
+		}
+		stripped := core.MakeDict()
+		for _, key := range props.Keys() {
+			switch key {
+			case "ActualText", "Alt", "E":
+				continue
+			}
+			stripped.Set(key, props.Get(key))
+		}
+		mc.op.Params[1] = stripped
+	}
+}
+
+// showText processes the text showing operation `op`, which shows the strings
+// and applies the positioning adjustments contained in `items`. If none of the
+// glyphs are redacted, the operation is kept unchanged. Otherwise, the
+// operation is replaced by a TJ operation showing the remaining glyphs, in
+// which the redacted glyphs are replaced by positioning adjustments, so that
+// the remaining glyphs are not moved. The `prefix` operations are emitted
+// before the TJ operation in the latter case.
+func (cr *contentRedactor) showText(op *contentstream.ContentStreamOperation, items *core.PdfObjectArray,
+	ctm transform.Matrix, prefix []*contentstream.ContentStreamOperation) {
+	ts := cr.ts
+	th := ts.hScale
+	scale := ts.fontSize * th
+
+	result := core.MakeArray()
+	var pending float64 // Pending adjustment, in thousandths of text space units.
+	var kept []byte
+	redacted := false
+
+	flushKept := func() {
+		if len(kept) > 0 {
+			result.Append(core.MakeStringFromBytes(kept))
+			kept = nil
+		}
+	}
+	addAdjustment := func(val float64) {
+		flushKept()
+		pending += val
+	}
+	flushAdjustment := func() {
+		if pending != 0 {
+			result.Append(core.MakeFloat(pending))
+			pending = 0
+		}
+	}
+
+	for _, item := range items.Elements() {
+		if val, err := core.GetNumberAsFloat(item); err == nil {
+			cr.tm.Concat(transform.TranslationMatrix(-val/1000*scale, 0))
+			addAdjustment(val)
+			continue
+		}
+
+		str, ok := core.GetString(item)
+		if !ok {
+			continue
+		}
+		glyphs := cr.splitGlyphs(str.Bytes())
+		for _, g := range glyphs {
+			advance := (g.width*ts.fontSize + ts.charSpacing) * th
+			if g.isSpace {
+				advance += ts.wordSpacing * th
+			}
+
+			isRedacted := cr.isGlyphRedacted(g.width*ts.fontSize*th, ctm)
+			cr.tm.Concat(transform.TranslationMatrix(advance, 0))
+
+			if !isRedacted {
+				flushAdjustment()
+				kept = append(kept, g.data...)
+				continue
+			}
+
+			redacted = true
+			if scale != 0 {
+				addAdjustment(-advance * 1000 / scale)
+			}
+		}
+	}
+
+	if !redacted {
+		cr.out = append(cr.out, op)
+		return
+	}
+	if cr.removeOps {
+		// Only keep the text state changes of the operation.
+		for _, mc := range cr.marked {
+			mc.redacted = true
+		}
+		cr.out = append(cr.out, prefix...)
+		return
+	}
+
+	flushKept()
+	flushAdjustment()
+	for _, mc := range cr.marked {
+		mc.redacted = true
+	}
+
+	cr.out = append(cr.out, prefix...)
+	cr.out = append(cr.out, &contentstream.ContentStreamOperation{
+		Operand: "TJ",
+		Params:  []core.PdfObject{result},
+	})
+}
+
+// glyph represents the encoded data of a glyph shown by a text showing
+// operator.
+type glyph struct {
+	data    []byte
+	width   float64 // Glyph width, in text space units (before scaling by font size).
+	isSpace bool    // Single byte code 32, affected by word spacing.
+}
+
+// splitGlyphs splits the string data `data` into glyphs using the current
+// font. If the code lengths cannot be determined, the whole string is
+// returned as a single glyph, which is redacted as a unit.
+func (cr *contentRedactor) splitGlyphs(data []byte) []glyph {
+	font := cr.ts.font
+	if font == nil {
+		// Unknown font. Estimate the glyph widths.
+		glyphs := make([]glyph, len(data))
+		for i, b := range data {
+			glyphs[i] = glyph{data: []byte{b}, width: 0.5, isSpace: b == ' '}
+		}
+		return glyphs
+	}
+
+	codes := font.BytesToCharcodes(data)
+	if len(codes) == 0 {
+		return nil
+	}
+
+	codeLen := len(data) / len(codes)
+	if codeLen*len(codes) != len(data) {
+		width := 0.0
+		for _, code := range codes {
+			if metrics, ok := font.GetCharMetrics(code); ok {
+				width += metrics.Wx / 1000
+			}
+		}
+		return []glyph{{data: data, width: width}}
+	}
+
+	glyphs := make([]glyph, len(codes))
+	for i, code := range codes {
+		g := glyph{data: data[i*codeLen : (i+1)*codeLen]}
+		if metrics, ok := font.GetCharMetrics(code); ok {
+			g.width = metrics.Wx / 1000
+		}
+		g.isSpace = codeLen == 1 && code == 32
+		glyphs[i] = g
+	}
+	return glyphs
+}
+
+// isGlyphRedacted returns true if the glyph with the specified width (in
+// unscaled text space units), positioned at the origin of the current text
+// matrix, is covered by any of the redacted areas.
+func (cr *contentRedactor) isGlyphRedacted(width float64, ctm transform.Matrix) bool {
+	ts := cr.ts
+	m := ctm.Mult(cr.tm)
+
+	bottom := ts.rise + glyphDescent*ts.fontSize
+	top := ts.rise + glyphAscent*ts.fontSize
+	bbox := transformedBBox(m, 0, bottom, width, top)
+	glyphArea := (bbox.Urx - bbox.Llx) * (bbox.Ury - bbox.Lly)
+
+	for _, area := range cr.areas {
+		if glyphArea <= 0 {
+			// Zero width glyph. Check if its origin is inside the area.
+			x, y := transformPoint(m, 0, ts.rise)
+			if x >= area.Rect.Llx && x <= area.Rect.Urx && y >= area.Rect.Lly && y <= area.Rect.Ury {
+				return true
+			}
+			continue
+		}
+		if intersectionArea(bbox, area.Rect) >= minGlyphOverlap*glyphArea {
+			return true
+		}
+	}
+	return false
+}
+
+// intersectsUnitSquare returns true if the unit square, transformed by `ctm`
+// (e.g. the area of an image), intersects any of the redacted areas.
+func (cr *contentRedactor) intersectsUnitSquare(ctm transform.Matrix) bool {
+	bbox := transformedBBox(ctm, 0, 0, 1, 1)
+	for _, area := range cr.areas {
+		if intersectionArea(bbox, area.Rect) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// coversUnitSquare returns true if the unit square, transformed by `ctm`, is
+// entirely covered by one of the redacted areas.
+func (cr *contentRedactor) coversUnitSquare(ctm transform.Matrix) bool {
+	bbox := transformedBBox(ctm, 0, 0, 1, 1)
+	for _, area := range cr.areas {
+		r := area.Rect
+		if bbox.Llx >= r.Llx && bbox.Urx <= r.Urx && bbox.Lly >= r.Lly && bbox.Ury <= r.Ury {
+			return true
+		}
+	}
+	return false
+}
+
+// processXObject processes the Do operation `op`. Images overlapping the
+// redacted areas are removed or have the redacted pixels blanked. Forms
+// overlapping the redacted areas are replaced by redacted copies.
+func (cr *contentRedactor) processXObject(op *contentstream.ContentStreamOperation, ctm transform.Matrix) error {
+	name, ok := core.GetName(firstParam(op))
+	if !ok || cr.resources == nil {
+		cr.out = append(cr.out, op)
+		return nil
+	}
+
+	stream, xtype := cr.resources.GetXObjectByName(*name)
+	switch xtype {
+	case model.XObjectTypeImage:
+		if !cr.intersectsUnitSquare(ctm) {
+			break
+		}
+		cr.xobjects.replaced[*name] = true
+		if cr.removeOps || cr.coversUnitSquare(ctm) {
+			common.Log.Debug("Redacting image %s", name)
+			return nil
+		}
+
+		newName, err := cr.redactImage(*name, ctm)
+		if err != nil {
+			// Remove the image if the pixels cannot be blanked.
+			common.Log.Debug("Removing image %s: could not redact pixels: %v", name, err)
+			return nil
+		}
+		cr.drawXObject(*newName)
+		return nil
+	case model.XObjectTypeForm:
+		newName, err := cr.redactForm(*name, ctm)
+		if err != nil {
+			return err
+		}
+		if newName != nil {
+			cr.xobjects.replaced[*name] = true
+			cr.drawXObject(*newName)
+			return nil
+		}
+		if stream.Get("Resources") == nil {
+			// The form is kept and draws the XObjects of the resources.
+			cr.xobjects.keep = true
+		}
+	}
+
+	cr.xobjects.used[*name] = true
+	cr.out = append(cr.out, op)
+	return nil
+}
+
+// drawXObject emits a Do operation drawing the XObject `name`.
+func (cr *contentRedactor) drawXObject(name core.PdfObjectName) {
+	cr.xobjects.used[name] = true
+	cr.out = append(cr.out, &contentstream.ContentStreamOperation{
+		Operand: "Do",
+		Params:  []core.PdfObject{core.MakeName(string(name))},
+	})
+}
+
+// redactImage creates a copy of the image XObject `name` in which the pixels
+// located in the redacted areas are blanked. The image is drawn in the unit
+// square transformed by `ctm`. Returns the resource name of the new image.
+func (cr *contentRedactor) redactImage(name core.PdfObjectName, ctm transform.Matrix) (*core.PdfObjectName, error) {
+	ximg, err := cr.resources.GetXObjectImageByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if ximg == nil {
+		return nil, fmt.Errorf("image %s not found", name)
+	}
+	if isMask, _ := core.GetBoolVal(ximg.ImageMask); isMask {
+		return nil, core.ErrNotSupported
+	}
+
+	img, err := ximg.ToImage()
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := int(img.Width), int(img.Height)
+	comps := int(img.ColorComponents)
+	samples := img.GetSamples()
+	if width <= 0 || height <= 0 || len(samples) < width*height*comps {
+		return nil, fmt.Errorf("invalid image data")
+	}
+
+	for row := 0; row < height; row++ {
+		for col := 0; col < width; col++ {
+			// Image space maps the unit square with the first row at the top.
+			u := (float64(col) + 0.5) / float64(width)
+			v := 1 - (float64(row)+0.5)/float64(height)
+			x, y := transformPoint(ctm, u, v)
+			if !cr.containsPoint(x, y) {
+				continue
+			}
+			idx := (row*width + col) * comps
+			for c := 0; c < comps; c++ {
+				samples[idx+c] = 0
+			}
+		}
+	}
+	img.SetSamples(samples)
+
+	redacted, err := model.UpdateXObjectImageFromImage(ximg, img, ximg.ColorSpace, core.NewFlateEncoder())
+	if err != nil {
+		return nil, err
+	}
+	redacted.Decode = ximg.Decode
+	redacted.Intent = ximg.Intent
+	redacted.Interpolate = ximg.Interpolate
+
+	newName := cr.uniqueXObjectName(name)
+	if err := cr.resources.SetXObjectImageByName(newName, redacted); err != nil {
+		return nil, err
+	}
+	return &newName, nil
+}
+
+// redactForm redacts the content of the Form XObject `name`, drawn with the
+// transformation `ctm`. If the form contains redacted content, a redacted
+// copy of the form is added to the resources and its name is returned.
+// Returns nil if the form does not overlap the redacted areas.
+func (cr *contentRedactor) redactForm(name core.PdfObjectName, ctm transform.Matrix) (*core.PdfObjectName, error) {
+	if cr.depth >= maxFormDepth {
+		common.Log.Debug("Form XObject nesting too deep. Skipping %s", name)
+		return nil, nil
+	}
+
+	xform, err := cr.resources.GetXObjectFormByName(name)
+	if err != nil || xform == nil {
+		common.Log.Debug("ERROR: could not load form %s: %v", name, err)
+		return nil, nil
+	}
+
+	formCTM := ctm
+	if arr, ok := core.GetArray(xform.Matrix); ok {
+		if vals, err := arr.ToFloat64Array(); err == nil && len(vals) == 6 {
+			formCTM = ctm.Mult(transform.NewMatrix(vals[0], vals[1], vals[2], vals[3], vals[4], vals[5]))
+		}
+	}
+
+	// Check if the form bounding box overlaps the redacted areas.
+	if arr, ok := core.GetArray(xform.BBox); ok {
+		if bbox, err := model.NewPdfRectangle(*arr); err == nil {
+			box := transformedBBox(formCTM, bbox.Llx, bbox.Lly, bbox.Urx, bbox.Ury)
+			overlaps := false
+			for _, area := range cr.areas {
+				if intersectionArea(box, area.Rect) > 0 {
+					overlaps = true
+					break
+				}
+			}
+			if !overlaps {
+				return nil, nil
+			}
+		}
+	}
+
+	stream, ok := xform.GetContainingPdfObject().(*core.PdfObjectStream)
+	if !ok {
+		return nil, nil
+	}
+	key := formKey{stream: stream, ctm: formCTM}
+	redacted, ok := cr.forms[key]
+	if !ok {
+		content, err := xform.GetContentStream()
+		if err != nil {
+			return nil, err
+		}
+		resources := xform.Resources
+		if resources == nil {
+			// Forms without resources use the resources of the parent.
+			resources = cr.resources
+		} else {
+			copyXObjects(resources)
+		}
+
+		ops, err := cr.redactContent(string(content), resources, formCTM, cr.depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		redacted = model.NewXObjectForm()
+		redacted.FormType = xform.FormType
+		redacted.BBox = xform.BBox
+		redacted.Matrix = xform.Matrix
+		redacted.Resources = xform.Resources
+		redacted.Group = xform.Group
+		redacted.OC = xform.OC
+		if err := redacted.SetContentStream(ops.Bytes(), core.NewFlateEncoder()); err != nil {
+			return nil, err
+		}
+		cr.forms[key] = redacted
+	}
+
+	newName := cr.uniqueXObjectName(name)
+	if err := cr.resources.SetXObjectFormByName(newName, redacted); err != nil {
+		return nil, err
+	}
+	return &newName, nil
+}
+
+// uniqueXObjectName returns a name based on `name` which is not used by any
+// of the XObjects of the resources.
+func (cr *contentRedactor) uniqueXObjectName(name core.PdfObjectName) core.PdfObjectName {
+	for i := 1; ; i++ {
+		candidate := core.PdfObjectName(fmt.Sprintf("%sR%d", name, i))
+		if !cr.resources.HasXObjectByName(candidate) {
+			return candidate
+		}
+	}
+}
+
+// containsPoint returns true if the point (x, y) is located in any of the
+// redacted areas.
+func (rd *redactor) containsPoint(x, y float64) bool {
+	for _, area := range rd.areas {
+		r := area.Rect
+		if x >= r.Llx && x <= r.Urx && y >= r.Lly && y <= r.Ury {
+			return true
+		}
+	}
+	return false
+}
+
+// transformPoint transforms the point (x, y) by the matrix `m`.
+func transformPoint(m transform.Matrix, x, y float64) (float64, float64) {
+	return x*m[0] + y*m[3] + m[6], x*m[1] + y*m[4] + m[7]
+}
+
+// transformedBBox returns the bounding box of the rectangle with the corners
+// (llx, lly) and (urx, ury), transformed by `m`.
+func transformedBBox(m transform.Matrix, llx, lly, urx, ury float64) model.PdfRectangle {
+	var points []float64
+	for _, p := range [][2]float64{{llx, lly}, {urx, lly}, {urx, ury}, {llx, ury}} {
+		x, y := transformPoint(m, p[0], p[1])
+		points = append(points, x, y)
+	}
+	return pointsRect(points)
+}
+
+// pointsRect returns the bounding box of the specified points (x, y pairs).
+func pointsRect(points []float64) model.PdfRectangle {
+	rect := model.PdfRectangle{
+		Llx: math.Inf(1), Lly: math.Inf(1),
+		Urx: math.Inf(-1), Ury: math.Inf(-1),
+	}
+	for i := 0; i+1 < len(points); i += 2 {
+		rect.Llx = math.Min(rect.Llx, points[i])
+		rect.Lly = math.Min(rect.Lly, points[i+1])
+		rect.Urx = math.Max(rect.Urx, points[i])
+		rect.Ury = math.Max(rect.Ury, points[i+1])
+	}
+	return rect
+}
+
+// intersectionArea returns the area of the intersection of rectangles `a`
+// and `b`.
+func intersectionArea(a, b model.PdfRectangle) float64 {
+	a, b = normalize(a), normalize(b)
+	w := math.Min(a.Urx, b.Urx) - math.Max(a.Llx, b.Llx)
+	h := math.Min(a.Ury, b.Ury) - math.Max(a.Lly, b.Lly)
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	return w * h
+}
+
+// normalize returns the rectangle `r` with the lower left corner coordinates
+// smaller than the upper right corner coordinates.
+func normalize(r model.PdfRectangle) model.PdfRectangle {
+	if r.Llx > r.Urx {
+		r.Llx, r.Urx = r.Urx, r.Llx
+	}
+	if r.Lly > r.Ury {
+		r.Lly, r.Ury = r.Ury, r.Lly
+	}
+	return r
+}
+
+// firstParam returns the first parameter of the operation `op`, or nil if the
+// operation has no parameters.
+func firstParam(op *contentstream.ContentStreamOperation) core.PdfObject {
+	if len(op.Params) == 0 {
+		return nil
+	}
+	return op.Params[0]
+}
+
+// floatParam returns the numeric parameter at index `i` of the operation
+// `op`, or 0 if the parameter is missing or not a number.
+func floatParam(op *contentstream.ContentStreamOperation, i int) float64 {
+	if i >= len(op.Params) {
+		return 0
+	}
+	val, err := core.GetNumberAsFloat(op.Params[i])
+	if err != nil {
+		return 0
+	}
+	return val
+}
@@ -0,0 +1,14 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+// Package redactor implements the redaction of PDF page contents. Unlike
+// drawing boxes over sensitive content, redaction removes the content located
+// in the redacted areas from the page content streams: text showing
+// operations are rewritten without the redacted glyphs, images are removed or
+// have the redacted pixels blanked and Form XObjects are redacted recursively.
+// The redacted areas can be specified explicitly or using the Redact
+// annotations of the pages. RemovePageContent provides a lighter cleanup,
+// removing the whole drawing operations which intersect page areas.
+package redactor
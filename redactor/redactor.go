@@ -0,0 +1,293 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package redactor
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// Area represents a page area to be redacted.
+type Area struct {
+	// Rect is the redacted area, in the default user space of the page.
+	Rect model.PdfRectangle
+
+	// FillColor is the color of the box drawn over the area once the content
+	// has been removed. No box is drawn if nil.
+	FillColor model.PdfColor
+}
+
+// Options contains the options used for redacting page contents.
+type Options struct {
+	// KeepAnnotations disables the removal of the annotations overlapping
+	// the redacted areas. By default, all the overlapping annotations except
+	// widget annotations are removed.
+	KeepAnnotations bool
+}
+
+// RedactPage removes the content located in the specified areas of the page
+// and draws the area fill boxes on top of the page content. The areas are
+// filled with black. Use RedactPageAreas for filling the areas with other
+// colors or disabling the fill.
+func RedactPage(page *model.PdfPage, rects []model.PdfRectangle, opts *Options) error {
+	areas := make([]Area, len(rects))
+	for i, rect := range rects {
+		areas[i] = Area{Rect: rect, FillColor: model.NewPdfColorDeviceGray(0)}
+	}
+	return RedactPageAreas(page, areas, opts)
+}
+
+// RedactPageAreas removes the content located in the specified areas of the
+// page and draws the fill boxes of the areas on top of the page content.
+func RedactPageAreas(page *model.PdfPage, areas []Area, opts *Options) error {
+	if page == nil {
+		return errors.New("page cannot be nil")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	if len(areas) == 0 {
+		return nil
+	}
+
+	rd := newRedactor(areas)
+	ops, err := rd.redactPage(page)
+	if err != nil {
+		return err
+	}
+
+	// Wrap the redacted content in a q/Q pair so that the fill boxes are
+	// drawn in the default user space.
+	redacted := append([]byte("q\n"), ops.Bytes()...)
+	redacted = append(redacted, []byte("\nQ\n")...)
+	redacted = append(redacted, drawAreas(areas)...)
+
+	if err := page.SetContentStreams([]string{string(redacted)}, core.NewFlateEncoder()); err != nil {
+		return err
+	}
+
+	if !opts.KeepAnnotations {
+		if err := removeOverlappingAnnotations(page, areas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyRedactAnnotations applies the Redact annotations of the page: the
+// content located in the areas marked for redaction (QuadPoints or Rect) is
+// removed, the areas are filled using the interior color of the annotations,
+// if any, and the Redact annotations are removed from the page. Returns the
+// number of applied redaction annotations.
+func ApplyRedactAnnotations(page *model.PdfPage, opts *Options) (int, error) {
+	annotations, err := page.GetAnnotations()
+	if err != nil {
+		return 0, err
+	}
+
+	var areas []Area
+	var kept []*model.PdfAnnotation
+	removed := map[*model.PdfAnnotation]struct{}{}
+	for _, annot := range annotations {
+		redact, ok := annot.GetContext().(*model.PdfAnnotationRedact)
+		if !ok {
+			continue
+		}
+		areas = append(areas, redactAnnotationAreas(redact)...)
+		removed[annot] = struct{}{}
+		if redact.PdfAnnotationMarkup != nil && redact.Popup != nil {
+			removed[redact.Popup.PdfAnnotation] = struct{}{}
+		}
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+
+	count := 0
+	for _, annot := range annotations {
+		if _, ok := removed[annot]; ok {
+			if _, isRedact := annot.GetContext().(*model.PdfAnnotationRedact); isRedact {
+				count++
+			}
+			continue
+		}
+		kept = append(kept, annot)
+	}
+	page.SetAnnotations(kept)
+
+	if err := RedactPageAreas(page, areas, opts); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ApplyAllRedactAnnotations applies the Redact annotations of all the pages
+// of the document. The redacted pages can then be written using a PdfWriter.
+// Returns the total number of applied redaction annotations.
+func ApplyAllRedactAnnotations(r *model.PdfReader, opts *Options) (int, error) {
+	total := 0
+	for i, page := range r.PageList {
+		count, err := ApplyRedactAnnotations(page, opts)
+		if err != nil {
+			common.Log.Debug("ERROR: could not apply redactions on page %d: %v", i+1, err)
+			return total, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// redactAnnotationAreas returns the areas marked for redaction by the Redact
+// annotation. The QuadPoints entry, if present, takes precedence over the
+// annotation rectangle.
+func redactAnnotationAreas(redact *model.PdfAnnotationRedact) []Area {
+	var fillColor model.PdfColor
+	if ic, ok := core.GetArray(redact.IC); ok {
+		if vals, err := ic.ToFloat64Array(); err == nil {
+			switch len(vals) {
+			case 1:
+				fillColor = model.NewPdfColorDeviceGray(vals[0])
+			case 3:
+				fillColor = model.NewPdfColorDeviceRGB(vals[0], vals[1], vals[2])
+			case 4:
+				fillColor = model.NewPdfColorDeviceCMYK(vals[0], vals[1], vals[2], vals[3])
+			}
+		}
+	}
+
+	var areas []Area
+	if quads, ok := core.GetArray(redact.QuadPoints); ok {
+		vals, err := quads.ToFloat64Array()
+		if err == nil && len(vals) >= 8 {
+			for i := 0; i+7 < len(vals); i += 8 {
+				areas = append(areas, Area{Rect: pointsRect(vals[i : i+8]), FillColor: fillColor})
+			}
+			return areas
+		}
+	}
+
+	if arr, ok := core.GetArray(redact.Rect); ok {
+		if rect, err := model.NewPdfRectangle(*arr); err == nil {
+			areas = append(areas, Area{Rect: *rect, FillColor: fillColor})
+		}
+	}
+	return areas
+}
+
+// removeOverlappingAnnotations removes the annotations of the page which
+// overlap any of the redacted areas, except the widget annotations, which are
+// referenced by the interactive form fields.
+func removeOverlappingAnnotations(page *model.PdfPage, areas []Area) error {
+	annotations, err := page.GetAnnotations()
+	if err != nil {
+		return err
+	}
+
+	removed := map[*model.PdfAnnotation]struct{}{}
+	for _, annot := range annotations {
+		if _, isWidget := annot.GetContext().(*model.PdfAnnotationWidget); isWidget {
+			continue
+		}
+		arr, ok := core.GetArray(annot.Rect)
+		if !ok {
+			continue
+		}
+		rect, err := model.NewPdfRectangle(*arr)
+		if err != nil {
+			continue
+		}
+		for _, area := range areas {
+			if intersectionArea(*rect, area.Rect) > 0 {
+				removed[annot] = struct{}{}
+				break
+			}
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	// Remove the popups of the removed annotations.
+	for _, annot := range annotations {
+		if _, ok := removed[annot]; !ok {
+			continue
+		}
+		if markup := annotationMarkup(annot); markup != nil && markup.Popup != nil {
+			removed[markup.Popup.PdfAnnotation] = struct{}{}
+		}
+	}
+
+	var kept []*model.PdfAnnotation
+	for _, annot := range annotations {
+		if _, ok := removed[annot]; !ok {
+			kept = append(kept, annot)
+		}
+	}
+	page.SetAnnotations(kept)
+	return nil
+}
+
+// annotationMarkup returns the markup dictionary of the annotation `annot`,
+// or nil if `annot` is not a markup annotation.
+func annotationMarkup(annot *model.PdfAnnotation) *model.PdfAnnotationMarkup {
+	switch t := annot.GetContext().(type) {
+	case *model.PdfAnnotationText:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationFreeText:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationLine:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationSquare:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationCircle:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationPolygon:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationPolyLine:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationHighlight:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationUnderline:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationSquiggly:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationStrikeOut:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationCaret:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationStamp:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationInk:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationFileAttachment:
+		return t.PdfAnnotationMarkup
+	case *model.PdfAnnotationRedact:
+		return t.PdfAnnotationMarkup
+	}
+	return nil
+}
+
+// drawAreas returns the content stream operations drawing the fill boxes of
+// the areas.
+func drawAreas(areas []Area) []byte {
+	cc := contentstream.NewContentCreator()
+	for _, area := range areas {
+		if area.FillColor == nil {
+			continue
+		}
+		r := area.Rect
+		cc.Add_q().
+			SetNonStrokingColor(area.FillColor).
+			Add_re(r.Llx, r.Lly, r.Urx-r.Llx, r.Ury-r.Lly).
+			Add_f().
+			Add_Q()
+	}
+	return cc.Bytes()
+}
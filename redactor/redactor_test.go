@@ -0,0 +1,222 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package redactor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/extractor"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// newTestPage returns a page showing "Secret Public" at (100, 700) using
+// Helvetica 12 and the specified additional content.
+func newTestPage(t *testing.T, extra string) *model.PdfPage {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+
+	font, err := model.NewStandard14Font(model.HelveticaName)
+	require.NoError(t, err)
+	page.Resources = model.NewPdfPageResources()
+	require.NoError(t, page.Resources.SetFontByName("F1", font.ToPdfObject()))
+
+	content := "BT /F1 12 Tf 100 700 Td (Secret Public) Tj ET\n" + extra
+	require.NoError(t, page.SetContentStreams([]string{content}, core.NewRawEncoder()))
+	return page
+}
+
+// writeReadPage writes the page to a new document and returns the first page
+// of the document read back.
+func writeReadPage(t *testing.T, page *model.PdfPage) *model.PdfPage {
+	w := model.NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	readPage, err := r.GetPage(1)
+	require.NoError(t, err)
+	return readPage
+}
+
+// extractText returns the text of the page.
+func extractText(t *testing.T, page *model.PdfPage) string {
+	ex, err := extractor.New(page)
+	require.NoError(t, err)
+	text, err := ex.ExtractText()
+	require.NoError(t, err)
+	return text
+}
+
+func TestRedactPageText(t *testing.T) {
+	page := newTestPage(t, "")
+
+	// "Secret" is about 38 points wide in Helvetica 12.
+	rect := model.PdfRectangle{Llx: 98, Lly: 695, Urx: 138, Ury: 712}
+	require.NoError(t, RedactPage(page, []model.PdfRectangle{rect}, nil))
+
+	readPage := writeReadPage(t, page)
+	text := extractText(t, readPage)
+	require.NotContains(t, text, "Secret")
+	require.Contains(t, text, "Public")
+
+	contents, err := readPage.GetAllContentStreams()
+	require.NoError(t, err)
+	require.NotContains(t, contents, "Secret")
+	require.Contains(t, contents, "re")
+}
+
+func TestRedactPageMarkedContent(t *testing.T) {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 612, Ury: 792}
+	font, err := model.NewStandard14Font(model.HelveticaName)
+	require.NoError(t, err)
+	page.Resources = model.NewPdfPageResources()
+	require.NoError(t, page.Resources.SetFontByName("F1", font.ToPdfObject()))
+
+	content := "/Span << /ActualText (Secret) /Lang (en) >> BDC BT /F1 12 Tf 100 700 Td (Secret) Tj ET EMC\n" +
+		"/Span << /ActualText (Public) >> BDC BT /F1 12 Tf 100 600 Td (Public) Tj ET EMC"
+	require.NoError(t, page.SetContentStreams([]string{content}, core.NewRawEncoder()))
+
+	rect := model.PdfRectangle{Llx: 90, Lly: 690, Urx: 200, Ury: 715}
+	require.NoError(t, RedactPageAreas(page, []Area{{Rect: rect}}, nil))
+
+	contents, err := page.GetAllContentStreams()
+	require.NoError(t, err)
+	require.NotContains(t, contents, "Secret")
+	require.Contains(t, contents, "/Lang")
+	require.Contains(t, contents, "(Public)")
+}
+
+func TestRedactPageImage(t *testing.T) {
+	// 4x4 white gray image drawn in (200, 500) - (300, 600).
+	img := &model.Image{
+		Width:            4,
+		Height:           4,
+		BitsPerComponent: 8,
+		ColorComponents:  1,
+		Data:             bytes.Repeat([]byte{0xff}, 16),
+	}
+	ximg, err := model.NewXObjectImageFromImage(img, nil, core.NewFlateEncoder())
+	require.NoError(t, err)
+
+	page := newTestPage(t, "q 100 0 0 100 200 500 cm /Im1 Do Q\nq 100 0 0 100 400 500 cm /Im1 Do Q")
+	require.NoError(t, page.Resources.SetXObjectImageByName("Im1", ximg))
+
+	// Blank the left half of the first image and cover the second image.
+	areas := []model.PdfRectangle{
+		{Llx: 190, Lly: 490, Urx: 250, Ury: 610},
+		{Llx: 390, Lly: 490, Urx: 510, Ury: 610},
+	}
+	require.NoError(t, RedactPage(page, areas, &Options{}))
+
+	contents, err := page.GetAllContentStreams()
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(contents, "Do"))
+	require.NotContains(t, contents, "/Im1 Do")
+	require.Contains(t, contents, "Secret")
+
+	redacted, err := page.Resources.GetXObjectImageByName("Im1R1")
+	require.NoError(t, err)
+	require.NotNil(t, redacted)
+	redactedImg, err := redacted.ToImage()
+	require.NoError(t, err)
+	for row := 0; row < 4; row++ {
+		require.Equal(t, []byte{0, 0, 0xff, 0xff}, redactedImg.Data[row*4:row*4+4])
+	}
+
+	// The original image is removed from the written file.
+	w := model.NewPdfWriter()
+	require.NoError(t, w.AddPage(page))
+	var buf bytes.Buffer
+	require.NoError(t, w.Write(&buf))
+	require.NotContains(t, buf.String(), "/Im1 ")
+	require.Equal(t, 1, strings.Count(buf.String(), "/Subtype /Image"))
+
+	r, err := model.NewPdfReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	readPage, err := r.GetPage(1)
+	require.NoError(t, err)
+	require.False(t, readPage.Resources.HasXObjectByName("Im1"))
+	require.True(t, readPage.Resources.HasXObjectByName("Im1R1"))
+}
+
+func TestApplyRedactAnnotations(t *testing.T) {
+	page := newTestPage(t, "")
+
+	redact := model.NewPdfAnnotationRedact()
+	redact.Rect = core.MakeArrayFromFloats([]float64{98, 695, 138, 712})
+	redact.IC = core.MakeArrayFromFloats([]float64{1, 0, 0})
+	page.AddAnnotation(redact.PdfAnnotation)
+
+	square := model.NewPdfAnnotationSquare()
+	square.Rect = core.MakeArrayFromFloats([]float64{100, 690, 120, 710})
+	page.AddAnnotation(square.PdfAnnotation)
+
+	text := model.NewPdfAnnotationText()
+	text.Rect = core.MakeArrayFromFloats([]float64{300, 300, 320, 320})
+	page.AddAnnotation(text.PdfAnnotation)
+
+	readPage := writeReadPage(t, page)
+	count, err := ApplyRedactAnnotations(readPage, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	annotations, err := readPage.GetAnnotations()
+	require.NoError(t, err)
+	require.Len(t, annotations, 1)
+	_, ok := annotations[0].GetContext().(*model.PdfAnnotationText)
+	require.True(t, ok)
+
+	readPage = writeReadPage(t, readPage)
+	extracted := extractText(t, readPage)
+	require.NotContains(t, extracted, "Secret")
+	require.Contains(t, extracted, "Public")
+
+	contents, err := readPage.GetAllContentStreams()
+	require.NoError(t, err)
+	require.Contains(t, contents, "1 0 0 rg")
+}
+
+func TestRemovePageContent(t *testing.T) {
+	img := &model.Image{Width: 1, Height: 1, BitsPerComponent: 8, ColorComponents: 1, Data: []byte{0}}
+	ximg, err := model.NewXObjectImageFromImage(img, nil, core.NewRawEncoder())
+	require.NoError(t, err)
+
+	// Header rule, logo and clipped box above a body text and rectangle.
+	page := newTestPage(t, strings.Join([]string{
+		"0 0 612 792 re W n",
+		"1 w 100 690 m 500 690 l S",
+		"q 50 0 0 20 100 760 cm /Im1 Do Q",
+		"q 90 740 20 20 re W f Q",
+		"BT /F1 12 Tf 100 300 Td (Body) Tj ET",
+		"100 100 50 50 re f",
+	}, "\n"))
+	require.NoError(t, page.Resources.SetXObjectImageByName("Im1", ximg))
+
+	header := model.PdfRectangle{Llx: 0, Lly: 680, Urx: 612, Ury: 792}
+	require.NoError(t, RemovePageContent(page, []model.PdfRectangle{header}))
+
+	readPage := writeReadPage(t, page)
+	text := extractText(t, readPage)
+	require.NotContains(t, text, "Secret")
+	require.NotContains(t, text, "Public")
+	require.Contains(t, text, "Body")
+
+	contents, err := readPage.GetAllContentStreams()
+	require.NoError(t, err)
+	require.NotContains(t, contents, "/Im1 Do")
+	require.NotContains(t, contents, "500 690 l")
+	require.Contains(t, contents, "0 0 612 792 re\nW\nn")
+	require.Contains(t, contents, "90 740 20 20 re\nW\nn")
+	require.Contains(t, contents, "100 100 50 50 re\nf")
+}
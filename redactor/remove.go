@@ -0,0 +1,124 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package redactor
+
+import (
+	"errors"
+
+	"github.com/unidoc/unipdf/v3/contentstream"
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// RemovePageContent removes the drawing operations intersecting the specified
+// areas of the page, in the default user space: the text showing operations
+// showing glyphs in the areas, and the images and paths intersecting them.
+// Form XObjects are processed recursively. Unlike RedactPage, operations are
+// removed as a whole (e.g. a text line crossing an area is removed entirely
+// and the pixels of images are not blanked), no boxes are drawn and the
+// annotations are kept. It is meant for cleaning page headers and footers,
+// not for removing sensitive content.
+func RemovePageContent(page *model.PdfPage, rects []model.PdfRectangle) error {
+	if page == nil {
+		return errors.New("page cannot be nil")
+	}
+	if len(rects) == 0 {
+		return nil
+	}
+
+	areas := make([]Area, len(rects))
+	for i, rect := range rects {
+		areas[i] = Area{Rect: rect}
+	}
+	rd := newRedactor(areas)
+	rd.removeOps = true
+	ops, err := rd.redactPage(page)
+	if err != nil {
+		return err
+	}
+	return page.SetContentStreams([]string{string(ops.Bytes())}, core.NewFlateEncoder())
+}
+
+// processPathOp processes the path construction, clipping and painting
+// operation `op`, drawn with the transformation `ctm`. The operations of a
+// path are held until the path is painted, and removed if the path
+// intersects the areas. Returns false if `op` is not part of a path.
+func (cr *contentRedactor) processPathOp(op *contentstream.ContentStreamOperation, ctm transform.Matrix) bool {
+	switch op.Operand {
+	case "m", "l", "c", "v", "y":
+		vals, _ := core.GetNumbersAsFloat(op.Params)
+		for i := 0; i+1 < len(vals); i += 2 {
+			x, y := transformPoint(ctm, vals[i], vals[i+1])
+			cr.pathPoints = append(cr.pathPoints, x, y)
+		}
+	case "re":
+		if vals, err := core.GetNumbersAsFloat(op.Params); err == nil && len(vals) == 4 {
+			bbox := transformedBBox(ctm, vals[0], vals[1], vals[0]+vals[2], vals[1]+vals[3])
+			cr.pathPoints = append(cr.pathPoints, bbox.Llx, bbox.Lly, bbox.Urx, bbox.Ury)
+		}
+	case "h":
+	case "W", "W*":
+		if len(cr.path) == 0 {
+			return false
+		}
+	case "S", "s", "f", "F", "f*", "B", "B*", "b", "b*", "n":
+		if len(cr.path) == 0 {
+			return false
+		}
+		cr.paintPath(op)
+		return true
+	default:
+		// Operations are not allowed within paths. Emit the invalid path as is.
+		cr.flushPath()
+		return false
+	}
+	cr.path = append(cr.path, op)
+	return true
+}
+
+// paintPath emits the current path painted by the operation `op`, unless it
+// intersects the areas. The clipping operations of removed paths are kept, with
+// the path ending without painting.
+func (cr *contentRedactor) paintPath(op *contentstream.ContentStreamOperation) {
+	path, points := cr.path, cr.pathPoints
+	cr.path, cr.pathPoints = nil, nil
+
+	if op.Operand == "n" || !cr.overlapsAnyArea(pointsRect(points)) {
+		cr.out = append(cr.out, path...)
+		cr.out = append(cr.out, op)
+		return
+	}
+
+	for _, pathOp := range path {
+		if pathOp.Operand == "W" || pathOp.Operand == "W*" {
+			cr.out = append(cr.out, path...)
+			cr.out = append(cr.out, &contentstream.ContentStreamOperation{Operand: "n"})
+			return
+		}
+	}
+}
+
+// flushPath emits the operations of the current path, if any.
+func (cr *contentRedactor) flushPath() {
+	cr.out = append(cr.out, cr.path...)
+	cr.path, cr.pathPoints = nil, nil
+}
+
+// overlapsAnyArea returns true if the rectangle `r` intersects or touches any
+// of the areas. Unlike intersectionArea, it detects the overlap of rectangles
+// with a zero width or height, such as the bounding boxes of horizontal
+// lines.
+func (cr *contentRedactor) overlapsAnyArea(r model.PdfRectangle) bool {
+	r = normalize(r)
+	for _, area := range cr.areas {
+		a := normalize(area.Rect)
+		if r.Llx <= a.Urx && r.Urx >= a.Llx && r.Lly <= a.Ury && r.Ury >= a.Lly {
+			return true
+		}
+	}
+	return false
+}
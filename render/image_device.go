@@ -6,12 +6,15 @@
 package render
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"image"
 	"image/draw"
 	"image/jpeg"
 	"image/png"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,6 +26,18 @@ import (
 // ImageDevice is used to render PDF pages to image targets.
 type ImageDevice struct {
 	renderer
+
+	// DPI is the resolution of the rendered images, in dots per inch.
+	// Defaults to 72, rendering one pixel per default user space unit.
+	DPI float64
+
+	// OutputWidth is the width of the rendered images in pixels. If set, it
+	// overrides DPI, which is useful for rendering thumbnails.
+	OutputWidth int
+
+	// JPEGQuality is the quality of the JPEG images saved, from 1 to 100.
+	// Defaults to 100.
+	JPEGQuality int
 }
 
 // NewImageDevice returns a new image device.
@@ -30,28 +45,62 @@ func NewImageDevice() *ImageDevice {
 	return &ImageDevice{}
 }
 
+// scale returns the number of pixels per default user space unit of the
+// images rendered from pages of width `width`.
+func (d *ImageDevice) scale(width float64) float64 {
+	if d.OutputWidth > 0 && width > 0 {
+		return float64(d.OutputWidth) / width
+	}
+	if d.DPI > 0 {
+		return d.DPI / model.PointsPerInch
+	}
+	return 1
+}
+
 // Render converts the specified PDF page into an image and returns the result.
+// The image contains the visible region of the page, i.e. its crop box,
+// rotated as specified by the page.
 func (d *ImageDevice) Render(page *model.PdfPage) (image.Image, error) {
+	return d.RenderContext(context.Background(), page)
+}
+
+// RenderContext converts the specified PDF page into an image as Render,
+// stopping with the error of `ctx` when it is cancelled or its deadline is
+// exceeded.
+func (d *ImageDevice) RenderContext(ctx context.Context, page *model.PdfPage) (image.Image, error) {
 	// Get page dimensions.
 	mbox, err := page.GetMediaBox()
 	if err != nil {
 		return nil, err
 	}
+	cbox, err := page.GetCropBox()
+	if err != nil {
+		return nil, err
+	}
+	rotate, err := page.GetRotate()
+	if err != nil {
+		return nil, err
+	}
 
 	// Render page.
 	width, height := mbox.Llx+mbox.Width(), mbox.Lly+mbox.Height()
+	visibleWidth := cbox.Width()
+	if rotate == 90 || rotate == 270 {
+		visibleWidth = cbox.Height()
+	}
+	scale := d.scale(visibleWidth)
 
-	ctx := imagerender.NewContext(int(width), int(height))
-	if err := d.renderPage(ctx, page); err != nil {
+	renderCtx := imagerender.NewContext(int(math.Round(width*scale)), int(math.Round(height*scale)))
+	if err := d.renderPage(ctx, renderCtx, page, scale); err != nil {
 		return nil, err
 	}
 
-	// Apply crop box, if one exists.
-	img := ctx.Image()
-	if box := page.CropBox; box != nil {
+	// Apply crop box.
+	img := renderCtx.Image()
+	if cbox.Llx != 0 || cbox.Lly != 0 || cbox.Urx != width || cbox.Ury != height {
 		// Calculate crop bounds and crop start position.
-		cropBounds := image.Rect(0, 0, int(box.Width()), int(box.Height()))
-		cropStart := image.Pt(int(box.Llx), int(height-box.Ury))
+		cropBounds := image.Rect(0, 0, int(math.Round(cbox.Width()*scale)), int(math.Round(cbox.Height()*scale)))
+		cropStart := image.Pt(int(math.Round(cbox.Llx*scale)), int(math.Round((height-cbox.Ury)*scale)))
 
 		// Crop image.
 		cropImg := image.NewRGBA(cropBounds)
@@ -59,48 +108,81 @@ func (d *ImageDevice) Render(page *model.PdfPage) (image.Image, error) {
 		img = cropImg
 	}
 
-	return img, nil
+	return rotateImage(img, rotate), nil
+}
+
+// rotateImage returns `img` rotated clockwise by `angle` degrees, a multiple
+// of 90.
+func rotateImage(img image.Image, angle int64) image.Image {
+	if angle == 0 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	var rotated *image.RGBA
+	if angle == 180 {
+		rotated = image.NewRGBA(image.Rect(0, 0, w, h))
+	} else {
+		rotated = image.NewRGBA(image.Rect(0, 0, h, w))
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.At(b.Min.X+x, b.Min.Y+y)
+			switch angle {
+			case 90:
+				rotated.Set(h-1-y, x, c)
+			case 180:
+				rotated.Set(w-1-x, h-1-y, c)
+			case 270:
+				rotated.Set(y, w-1-x, c)
+			}
+		}
+	}
+	return rotated
 }
 
 // RenderToPath converts the specified PDF page into an image and saves the
 // result at the specified location.
 func (d *ImageDevice) RenderToPath(page *model.PdfPage, outputPath string) error {
-	image, err := d.Render(page)
-	if err != nil {
-		return err
-	}
-
 	extension := strings.ToLower(filepath.Ext(outputPath))
 	if extension == "" {
 		return errors.New("could not recognize output file type")
 	}
-
-	switch extension {
-	case ".png":
-		return savePNG(outputPath, image)
-	case ".jpg", ".jpeg":
-		return saveJPG(outputPath, image, 100)
+	if extension != ".png" && extension != ".jpg" && extension != ".jpeg" {
+		return fmt.Errorf("unrecognized output file type: %s", extension)
 	}
 
-	return fmt.Errorf("unrecognized output file type: %s", extension)
-}
-
-func savePNG(path string, image image.Image) error {
-	file, err := os.Create(path)
+	file, err := os.Create(outputPath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	return png.Encode(file, image)
+	return d.RenderToWriter(page, file, extension[1:])
 }
 
-func saveJPG(path string, image image.Image, quality int) error {
-	file, err := os.Create(path)
+// RenderToWriter converts the specified PDF page into an image and writes
+// the result to `w`, encoded in the image format `format`, either "png" or
+// "jpeg" ("jpg").
+func (d *ImageDevice) RenderToWriter(page *model.PdfPage, w io.Writer, format string) error {
+	switch strings.ToLower(format) {
+	case "png", "jpg", "jpeg":
+	default:
+		return fmt.Errorf("unsupported image format: %s", format)
+	}
+
+	img, err := d.Render(page)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	return jpeg.Encode(file, image, &jpeg.Options{Quality: quality})
+	if strings.ToLower(format) == "png" {
+		return png.Encode(w, img)
+	}
+	quality := d.JPEGQuality
+	if quality <= 0 || quality > 100 {
+		quality = 100
+	}
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
 }
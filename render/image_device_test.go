@@ -0,0 +1,119 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package render
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/core"
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+// newTestPage returns a 200x100 page with a red square at the bottom left
+// corner.
+func newTestPage(t *testing.T) *model.PdfPage {
+	page := model.NewPdfPage()
+	page.MediaBox = &model.PdfRectangle{Urx: 200, Ury: 100}
+	content, err := core.MakeStream([]byte("1 0 0 rg 0 0 50 50 re f"), nil)
+	require.NoError(t, err)
+	page.Contents = content
+	return page
+}
+
+// requireColor checks the RGB color of the pixel (x, y) of `img`.
+func requireColor(t *testing.T, img image.Image, x, y int, r, g, b uint8) {
+	cr, cg, cb, _ := img.At(x, y).RGBA()
+	require.Equal(t, []uint8{r, g, b}, []uint8{uint8(cr >> 8), uint8(cg >> 8), uint8(cb >> 8)},
+		"pixel (%d, %d)", x, y)
+}
+
+func TestImageDeviceRender(t *testing.T) {
+	page := newTestPage(t)
+
+	device := NewImageDevice()
+	img, err := device.Render(page)
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 200, 100), img.Bounds())
+	requireColor(t, img, 10, 90, 255, 0, 0)
+	requireColor(t, img, 10, 10, 255, 255, 255)
+	requireColor(t, img, 60, 90, 255, 255, 255)
+
+	device.DPI = 144
+	img, err = device.Render(page)
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 400, 200), img.Bounds())
+	requireColor(t, img, 90, 190, 255, 0, 0)
+	requireColor(t, img, 110, 190, 255, 255, 255)
+
+	// The output width overrides the resolution.
+	device.OutputWidth = 50
+	img, err = device.Render(page)
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 50, 25), img.Bounds())
+}
+
+func TestImageDeviceRenderCropRotate(t *testing.T) {
+	page := newTestPage(t)
+	page.CropBox = &model.PdfRectangle{Urx: 100, Ury: 100}
+	rotate := int64(90)
+	page.Rotate = &rotate
+
+	device := NewImageDevice()
+	device.DPI = 144
+	img, err := device.Render(page)
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 200, 200), img.Bounds())
+	// The bottom left corner of the page is at the top left corner of the
+	// image rotated clockwise.
+	requireColor(t, img, 10, 10, 255, 0, 0)
+	requireColor(t, img, 10, 190, 255, 255, 255)
+
+	rotate = 270
+	img, err = device.Render(page)
+	require.NoError(t, err)
+	requireColor(t, img, 190, 190, 255, 0, 0)
+	requireColor(t, img, 10, 10, 255, 255, 255)
+}
+
+func TestImageDeviceRenderToWriter(t *testing.T) {
+	page := newTestPage(t)
+
+	device := NewImageDevice()
+	device.OutputWidth = 100
+	var buf bytes.Buffer
+	require.NoError(t, device.RenderToWriter(page, &buf, "png"))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 100, 50), img.Bounds())
+
+	buf.Reset()
+	require.NoError(t, device.RenderToWriter(page, &buf, "jpeg"))
+	require.NotZero(t, buf.Len())
+
+	require.Error(t, device.RenderToWriter(page, &buf, "bmp"))
+}
+
+func TestImageDeviceRenderContext(t *testing.T) {
+	page := newTestPage(t)
+
+	device := NewImageDevice()
+	_, err := device.RenderContext(context.Background(), page)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = device.RenderContext(ctx, page)
+	require.Equal(t, context.Canceled, err)
+	err = NewSVGDevice().RenderToWriterContext(ctx, page, ioutil.Discard)
+	require.Equal(t, context.Canceled, err)
+}
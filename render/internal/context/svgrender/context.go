@@ -0,0 +1,746 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package svgrender
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/unidoc/unipdf/v3/common"
+	"github.com/unidoc/unipdf/v3/internal/transform"
+	"github.com/unidoc/unipdf/v3/render/internal/context"
+)
+
+// document holds the SVG elements drawn by a context and the contexts saved
+// on its stack.
+type document struct {
+	defs     bytes.Buffer
+	body     bytes.Buffer
+	numClips int
+}
+
+// Context represents an SVG rendering context. The paths drawn are written
+// in the coordinates of the rendering area, one unit per pixel.
+type Context struct {
+	// TextAsPaths draws the glyphs of the text as paths instead of text
+	// elements, for the text to look the same without the fonts installed.
+	TextAsPaths bool
+
+	width       int
+	height      int
+	doc         *document
+	fillColor   color.NRGBA
+	strokeColor color.NRGBA
+	path        strings.Builder
+	start       transform.Point
+	current     transform.Point
+	hasCurrent  bool
+	clip        string
+	dashes      []float64
+	dashOffset  float64
+	lineWidth   float64
+	lineCap     context.LineCap
+	lineJoin    context.LineJoin
+	fillRule    context.FillRule
+	matrix      transform.Matrix
+	textState   *context.TextState
+	stack       []*Context
+}
+
+// NewContext creates a new SVG rendering context with the specified width
+// and height.
+func NewContext(width, height int) *Context {
+	return &Context{
+		width:       width,
+		height:      height,
+		doc:         &document{},
+		fillColor:   color.NRGBA{255, 255, 255, 255},
+		strokeColor: color.NRGBA{0, 0, 0, 255},
+		lineWidth:   1,
+		fillRule:    context.FillRuleWinding,
+		matrix:      transform.IdentityMatrix(),
+		textState:   context.NewTextState(),
+	}
+}
+
+// Width returns the width of the rendering area.
+func (dc *Context) Width() int {
+	return dc.width
+}
+
+// Height returns the height of the rendering area.
+func (dc *Context) Height() int {
+	return dc.height
+}
+
+// Write writes the SVG document of the region of the rendering area with
+// top left corner `x`,`y` and size `width`,`height` to `w`, rotated clockwise
+// by `rotate` degrees, a multiple of 90.
+func (dc *Context) Write(w io.Writer, x, y, width, height float64, rotate int64) error {
+	outWidth, outHeight := width, height
+	if rotate == 90 || rotate == 270 {
+		outWidth, outHeight = height, width
+	}
+
+	// Map the region to the viewport.
+	var m [6]float64
+	switch rotate {
+	case 90:
+		m = [6]float64{0, 1, -1, 0, height + y, -x}
+	case 180:
+		m = [6]float64{-1, 0, 0, -1, width + x, height + y}
+	case 270:
+		m = [6]float64{0, -1, 1, 0, -y, width + x}
+	default:
+		m = [6]float64{1, 0, 0, 1, -x, -y}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" `+
+		`version="1.1" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		formatFloat(outWidth), formatFloat(outHeight), formatFloat(outWidth), formatFloat(outHeight))
+	if dc.doc.defs.Len() > 0 {
+		buf.WriteString("<defs>\n")
+		buf.Write(dc.doc.defs.Bytes())
+		buf.WriteString("</defs>\n")
+	}
+	fmt.Fprintf(&buf, `<g transform="matrix(%s)">`+"\n", formatFloats(m[:]...))
+	buf.Write(dc.doc.body.Bytes())
+	buf.WriteString("</g>\n</svg>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// SetDash sets the current dash pattern to use. Call with zero arguments to
+// disable dashes. The values specify the lengths of each dash, with
+// alternating on and off lengths.
+func (dc *Context) SetDash(dashes ...float64) {
+	dc.dashes = dashes
+}
+
+// SetDashOffset sets the initial offset into the dash pattern to use when
+// stroking dashed paths.
+func (dc *Context) SetDashOffset(offset float64) {
+	dc.dashOffset = offset
+}
+
+// LineWidth returns the line width of the context.
+func (dc *Context) LineWidth() float64 {
+	return dc.lineWidth
+}
+
+// SetLineWidth sets the line width of the context.
+func (dc *Context) SetLineWidth(lineWidth float64) {
+	dc.lineWidth = lineWidth
+}
+
+// SetLineCap sets the line cap style.
+func (dc *Context) SetLineCap(lineCap context.LineCap) {
+	dc.lineCap = lineCap
+}
+
+// SetLineJoin sets the line join style.
+func (dc *Context) SetLineJoin(lineJoin context.LineJoin) {
+	dc.lineJoin = lineJoin
+}
+
+// SetFillRule sets the fill rule.
+func (dc *Context) SetFillRule(fillRule context.FillRule) {
+	dc.fillRule = fillRule
+}
+
+//
+// Color setters
+//
+
+// newColor returns the color with the components `r`, `g`, `b`, `a`, in
+// range 0-1.
+func newColor(r, g, b, a float64) color.NRGBA {
+	return color.NRGBA{
+		uint8(r * 255),
+		uint8(g * 255),
+		uint8(b * 255),
+		uint8(a * 255),
+	}
+}
+
+// patternColor returns the color of the pattern `pattern`. The SVG context
+// only draws solid colors, the color of the first pixel of the other
+// patterns is used.
+func patternColor(pattern context.Pattern) color.NRGBA {
+	return color.NRGBAModel.Convert(pattern.ColorAt(0, 0)).(color.NRGBA)
+}
+
+// SetFillStyle sets current fill style.
+func (dc *Context) SetFillStyle(pattern context.Pattern) {
+	dc.fillColor = patternColor(pattern)
+}
+
+// SetStrokeStyle sets current stroke style.
+func (dc *Context) SetStrokeStyle(pattern context.Pattern) {
+	dc.strokeColor = patternColor(pattern)
+}
+
+// SetStrokeRGBA sets the current color for stroking operations.
+// r, g, b, a values must be in range 0-1.
+func (dc *Context) SetStrokeRGBA(r, g, b, a float64) {
+	dc.strokeColor = newColor(r, g, b, a)
+}
+
+// SetFillRGBA sets the current color for fill operations.
+// r, g, b, a values must be in range 0-1.
+func (dc *Context) SetFillRGBA(r, g, b, a float64) {
+	dc.fillColor = newColor(r, g, b, a)
+}
+
+// SetRGBA sets the current color. r, g, b, a values should be between 0 and 1,
+// inclusive.
+func (dc *Context) SetRGBA(r, g, b, a float64) {
+	dc.fillColor = newColor(r, g, b, a)
+	dc.strokeColor = dc.fillColor
+}
+
+//
+// Path manipulation
+//
+
+// addPoint adds the command `cmd` with the points `points`, in user space,
+// to the current path and returns the last point in device space.
+func (dc *Context) addPoint(cmd byte, points ...float64) transform.Point {
+	var p transform.Point
+	if dc.path.Len() > 0 {
+		dc.path.WriteByte(' ')
+	}
+	dc.path.WriteByte(cmd)
+	for i := 0; i+1 < len(points); i += 2 {
+		x, y := dc.matrix.Transform(points[i], points[i+1])
+		p = transform.NewPoint(x, y)
+		dc.path.WriteByte(' ')
+		dc.path.WriteString(formatFloats(x, y))
+	}
+	return p
+}
+
+// MoveTo starts a new subpath within the current path starting at the
+// specified point.
+func (dc *Context) MoveTo(x, y float64) {
+	p := dc.addPoint('M', x, y)
+	dc.start = p
+	dc.current = p
+	dc.hasCurrent = true
+}
+
+// LineTo adds a line segment to the current path starting at the current
+// point. If there is no current point, it is equivalent to MoveTo(x, y).
+func (dc *Context) LineTo(x, y float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x, y)
+		return
+	}
+	dc.current = dc.addPoint('L', x, y)
+}
+
+// QuadraticTo adds a quadratic bezier curve to the current path starting at
+// the current point. If there is no current point, it first performs
+// MoveTo(x1, y1).
+func (dc *Context) QuadraticTo(x1, y1, x2, y2 float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x1, y1)
+	}
+	dc.current = dc.addPoint('Q', x1, y1, x2, y2)
+}
+
+// CubicTo adds a cubic bezier curve to the current path starting at the
+// current point. If there is no current point, it first performs
+// MoveTo(x1, y1).
+func (dc *Context) CubicTo(x1, y1, x2, y2, x3, y3 float64) {
+	if !dc.hasCurrent {
+		dc.MoveTo(x1, y1)
+	}
+	dc.current = dc.addPoint('C', x1, y1, x2, y2, x3, y3)
+}
+
+// ClosePath adds a line segment from the current point to the beginning
+// of the current subpath. If there is no current point, this is a no-op.
+func (dc *Context) ClosePath() {
+	if dc.hasCurrent {
+		dc.path.WriteString(" Z")
+		dc.current = dc.start
+	}
+}
+
+// ClearPath clears the current path. There is no current point after this
+// operation.
+func (dc *Context) ClearPath() {
+	dc.path.Reset()
+	dc.hasCurrent = false
+}
+
+// NewSubPath starts a new subpath within the current path. There is no current
+// point after this operation.
+func (dc *Context) NewSubPath() {
+	dc.hasCurrent = false
+}
+
+//
+// Path drawing
+//
+
+// writeElement writes the element `name` with the attributes `attrs`, pairs
+// of names and values, clipped by the current clipping region.
+func (dc *Context) writeElement(name string, attrs ...string) {
+	body := &dc.doc.body
+	body.WriteByte('<')
+	body.WriteString(name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		writeAttr(body, attrs[i], attrs[i+1])
+	}
+	if dc.clip != "" {
+		writeAttr(body, "clip-path", "url(#"+dc.clip+")")
+	}
+	body.WriteString("/>\n")
+}
+
+// fillRuleName returns the name of the current fill rule.
+func (dc *Context) fillRuleName() string {
+	if dc.fillRule == context.FillRuleEvenOdd {
+		return "evenodd"
+	}
+	return "nonzero"
+}
+
+// StrokePreserve strokes the current path with the current color, line width,
+// line cap, line join and dash settings. The path is preserved after this
+// operation.
+func (dc *Context) StrokePreserve() {
+	if dc.path.Len() == 0 {
+		return
+	}
+
+	lineCap := "round"
+	switch dc.lineCap {
+	case context.LineCapButt:
+		lineCap = "butt"
+	case context.LineCapSquare:
+		lineCap = "square"
+	}
+	lineJoin := "round"
+	if dc.lineJoin == context.LineJoinBevel {
+		lineJoin = "bevel"
+	}
+
+	attrs := []string{
+		"d", dc.path.String(),
+		"fill", "none",
+		"stroke", formatColor(dc.strokeColor),
+		"stroke-width", formatFloat(dc.lineWidth),
+		"stroke-linecap", lineCap,
+		"stroke-linejoin", lineJoin,
+	}
+	if dc.strokeColor.A != 255 {
+		attrs = append(attrs, "stroke-opacity", formatFloat(float64(dc.strokeColor.A)/255))
+	}
+	if len(dc.dashes) > 0 {
+		attrs = append(attrs, "stroke-dasharray", formatFloats(dc.dashes...),
+			"stroke-dashoffset", formatFloat(dc.dashOffset))
+	}
+	dc.writeElement("path", attrs...)
+}
+
+// Stroke strokes the current path with the current color, line width,
+// line cap, line join and dash settings. The path is cleared after this
+// operation.
+func (dc *Context) Stroke() {
+	dc.StrokePreserve()
+	dc.ClearPath()
+}
+
+// FillPreserve fills the current path with the current color. Open subpaths
+// are implicity closed. The path is preserved after this operation.
+func (dc *Context) FillPreserve() {
+	if dc.path.Len() == 0 {
+		return
+	}
+
+	attrs := []string{
+		"d", dc.path.String(),
+		"fill", formatColor(dc.fillColor),
+		"fill-rule", dc.fillRuleName(),
+	}
+	if dc.fillColor.A != 255 {
+		attrs = append(attrs, "fill-opacity", formatFloat(float64(dc.fillColor.A)/255))
+	}
+	dc.writeElement("path", attrs...)
+}
+
+// Fill fills the current path with the current color. Open subpaths
+// are implicity closed. The path is cleared after this operation.
+func (dc *Context) Fill() {
+	dc.FillPreserve()
+	dc.ClearPath()
+}
+
+// ClipPreserve updates the clipping region by intersecting the current
+// clipping region with the current path as it would be filled by dc.Fill().
+// The path is preserved after this operation.
+func (dc *Context) ClipPreserve() {
+	doc := dc.doc
+	doc.numClips++
+	id := fmt.Sprintf("clip%d", doc.numClips)
+
+	defs := &doc.defs
+	defs.WriteString("<clipPath")
+	writeAttr(defs, "id", id)
+	if dc.clip != "" {
+		// The clipping paths are intersected by clipping the new path with
+		// the current clipping path.
+		writeAttr(defs, "clip-path", "url(#"+dc.clip+")")
+	}
+	defs.WriteString("><path")
+	writeAttr(defs, "d", dc.path.String())
+	writeAttr(defs, "clip-rule", dc.fillRuleName())
+	defs.WriteString("/></clipPath>\n")
+
+	dc.clip = id
+}
+
+// Clip updates the clipping region by intersecting the current
+// clipping region with the current path as it would be filled by dc.Fill().
+// The path is cleared after this operation.
+func (dc *Context) Clip() {
+	dc.ClipPreserve()
+	dc.ClearPath()
+}
+
+// ResetClip clears the clipping region.
+func (dc *Context) ResetClip() {
+	dc.clip = ""
+}
+
+//
+// Drawing operations
+//
+
+// DrawRectangle draws a rectangle of size w,h at position x,y.
+func (dc *Context) DrawRectangle(x, y, w, h float64) {
+	dc.NewSubPath()
+	dc.MoveTo(x, y)
+	dc.LineTo(x+w, y)
+	dc.LineTo(x+w, y+h)
+	dc.LineTo(x, y+h)
+	dc.ClosePath()
+}
+
+// DrawImage draws the specified image at the specified point.
+func (dc *Context) DrawImage(im image.Image, x, y int) {
+	dc.DrawImageAnchored(im, x, y, 0, 0)
+}
+
+// DrawImageAnchored draws the specified image at the specified anchor point.
+// The anchor point is x - w * ax, y - h * ay, where w, h is the size of the
+// image. Use ax=0.5, ay=0.5 to center the image at the specified point.
+// The image is embedded in the SVG document as a PNG data URI.
+func (dc *Context) DrawImageAnchored(im image.Image, x, y int, ax, ay float64) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, im); err != nil {
+		common.Log.Debug("ERROR: unable to encode image: %v", err)
+		return
+	}
+
+	s := im.Bounds().Size()
+	x -= int(ax * float64(s.X))
+	y -= int(ay * float64(s.Y))
+	m := dc.matrix.Clone()
+	m.Translate(float64(x), float64(y))
+
+	dc.writeElement("image",
+		"width", strconv.Itoa(s.X),
+		"height", strconv.Itoa(s.Y),
+		"preserveAspectRatio", "none",
+		"transform", "matrix("+formatMatrix(m)+")",
+		"xlink:href", "data:image/png;base64,"+base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+//
+// Text operations
+//
+
+// TextState returns the current text state.
+func (dc *Context) TextState() *context.TextState {
+	return dc.textState
+}
+
+// DrawString draws the specified text at the specified point, as a text
+// element or as paths outlining the glyphs if TextAsPaths is set.
+func (dc *Context) DrawString(s string, x, y float64) {
+	tf := dc.textState.Tf
+	if tf == nil {
+		return
+	}
+	if dc.TextAsPaths && tf.TrueType() != nil {
+		dc.drawGlyphs(tf, s, x, y)
+		return
+	}
+
+	attrs := []string{
+		"x", formatFloat(x),
+		"y", formatFloat(y),
+		"transform", "matrix(" + formatMatrix(dc.matrix) + ")",
+		"font-family", tf.FamilyName(),
+		"font-size", formatFloat(tf.Size),
+		"fill", formatColor(dc.fillColor),
+		"xml:space", "preserve",
+	}
+	if dc.fillColor.A != 255 {
+		attrs = append(attrs, "fill-opacity", formatFloat(float64(dc.fillColor.A)/255))
+	}
+
+	body := &dc.doc.body
+	body.WriteString("<text")
+	for i := 0; i+1 < len(attrs); i += 2 {
+		writeAttr(body, attrs[i], attrs[i+1])
+	}
+	if dc.clip != "" {
+		writeAttr(body, "clip-path", "url(#"+dc.clip+")")
+	}
+	body.WriteByte('>')
+	xml.EscapeText(body, []byte(s))
+	body.WriteString("</text>\n")
+}
+
+// drawGlyphs fills the outlines of the glyphs of `s` drawn with the text font
+// `tf` at the specified point.
+func (dc *Context) drawGlyphs(tf *context.TextFont, s string, x, y float64) {
+	ttf := tf.TrueType()
+	scale := fixed.Int26_6(tf.Size * 64)
+
+	// The outlines are drawn in the user space of the text, as the glyphs
+	// of the image context.
+	path, hasCurrent := dc.path.String(), dc.hasCurrent
+	dc.ClearPath()
+
+	var glyph truetype.GlyphBuf
+	prev := rune(-1)
+	for _, r := range s {
+		index := ttf.Index(r)
+		if prev >= 0 {
+			x += float64(ttf.Kern(scale, ttf.Index(prev), index)) / 64
+		}
+		if err := glyph.Load(ttf, scale, index, font.HintingNone); err != nil {
+			common.Log.Debug("ERROR: unable to load glyph %q: %v", r, err)
+			continue
+		}
+
+		start := 0
+		for _, end := range glyph.Ends {
+			dc.drawContour(glyph.Points[start:end], x, y)
+			start = end
+		}
+		x += float64(glyph.AdvanceWidth) / 64
+		prev = r
+	}
+
+	prevRule := dc.fillRule
+	dc.fillRule = context.FillRuleWinding
+	dc.Fill()
+	dc.fillRule = prevRule
+
+	dc.path.WriteString(path)
+	dc.hasCurrent = hasCurrent
+}
+
+// drawContour adds the contour of a glyph, made of the quadratic B-spline
+// points `points`, with origin `x`,`y`, to the current path.
+func (dc *Context) drawContour(points []truetype.Point, x, y float64) {
+	if len(points) == 0 {
+		return
+	}
+
+	type point struct{ x, y float64 }
+	pt := func(p truetype.Point) point {
+		return point{x + float64(p.X)/64, y - float64(p.Y)/64}
+	}
+	mid := func(p, q point) point {
+		return point{(p.x + q.x) / 2, (p.y + q.y) / 2}
+	}
+	onCurve := func(p truetype.Point) bool {
+		return p.Flags&0x01 != 0
+	}
+
+	// Start on a point on the curve, or between two control points.
+	var start point
+	first, last := points[0], points[len(points)-1]
+	rest := points
+	switch {
+	case onCurve(first):
+		start, rest = pt(first), points[1:]
+	case onCurve(last):
+		start, rest = pt(last), points[:len(points)-1]
+	default:
+		start = mid(pt(first), pt(last))
+	}
+
+	dc.NewSubPath()
+	dc.MoveTo(start.x, start.y)
+	q0, on0 := start, true
+	for _, p := range rest {
+		q, on := pt(p), onCurve(p)
+		if on {
+			if on0 {
+				dc.LineTo(q.x, q.y)
+			} else {
+				dc.QuadraticTo(q0.x, q0.y, q.x, q.y)
+			}
+		} else if !on0 {
+			m := mid(q0, q)
+			dc.QuadraticTo(q0.x, q0.y, m.x, m.y)
+		}
+		q0, on0 = q, on
+	}
+	if on0 {
+		dc.LineTo(start.x, start.y)
+	} else {
+		dc.QuadraticTo(q0.x, q0.y, start.x, start.y)
+	}
+	dc.ClosePath()
+}
+
+// MeasureString returns the rendered width and height of the specified text
+// given the current font face.
+func (dc *Context) MeasureString(s string) (w, h float64) {
+	d := &font.Drawer{
+		Face: dc.textState.Tf.Face,
+	}
+	a := d.MeasureString(s)
+	return float64(a >> 6), dc.textState.Tf.Size
+}
+
+//
+// Transformation matrix operations
+//
+
+// Matrix returns the current transformation matrix.
+func (dc *Context) Matrix() transform.Matrix {
+	return dc.matrix
+}
+
+// SetMatrix modifies the transformation matrix.
+func (dc *Context) SetMatrix(m transform.Matrix) {
+	dc.matrix = m
+}
+
+// Translate updates the current matrix with a translation.
+func (dc *Context) Translate(x, y float64) {
+	dc.matrix.Translate(x, y)
+}
+
+// Scale updates the current matrix with a scaling factor.
+// Scaling occurs about the origin.
+func (dc *Context) Scale(x, y float64) {
+	dc.matrix.Scale(x, y)
+}
+
+// Rotate updates the current matrix with a anticlockwise rotation.
+// Rotation occurs about the origin. Angle is specified in radians.
+func (dc *Context) Rotate(angle float64) {
+	dc.matrix.Rotate(angle)
+}
+
+//
+// Stack operations
+//
+
+// Push saves the current state of the context for later retrieval. These
+// can be nested.
+func (dc *Context) Push() {
+	x := *dc
+	x.path = strings.Builder{}
+	dc.stack = append(dc.stack, &x)
+}
+
+// Pop restores the last saved context state from the stack.
+func (dc *Context) Pop() {
+	s := dc.stack
+	if len(s) == 0 {
+		return
+	}
+	x, s := s[len(s)-1], s[:len(s)-1]
+
+	// The current path and text state are not part of the saved state.
+	path := dc.path.String()
+	start, current, hasCurrent := dc.start, dc.current, dc.hasCurrent
+	textState := dc.textState
+
+	dc.TextAsPaths = x.TextAsPaths
+	dc.fillColor, dc.strokeColor = x.fillColor, x.strokeColor
+	dc.clip = x.clip
+	dc.dashes, dc.dashOffset = x.dashes, x.dashOffset
+	dc.lineWidth, dc.lineCap, dc.lineJoin = x.lineWidth, x.lineCap, x.lineJoin
+	dc.fillRule = x.fillRule
+	dc.matrix = x.matrix
+	dc.stack = s
+
+	dc.path.Reset()
+	dc.path.WriteString(path)
+	dc.start, dc.current, dc.hasCurrent = start, current, hasCurrent
+	dc.textState = textState
+}
+
+//
+// Formatting
+//
+
+// writeAttr writes the attribute `name` with the value `value` to `buf`.
+func writeAttr(buf *bytes.Buffer, name, value string) {
+	buf.WriteByte(' ')
+	buf.WriteString(name)
+	buf.WriteString(`="`)
+	xml.EscapeText(buf, []byte(value))
+	buf.WriteByte('"')
+}
+
+// formatFloat formats `v` with up to 3 decimals.
+func formatFloat(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 3, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// formatFloats formats `vals` separated by spaces.
+func formatFloats(vals ...float64) string {
+	strs := make([]string, len(vals))
+	for i, v := range vals {
+		strs[i] = formatFloat(v)
+	}
+	return strings.Join(strs, " ")
+}
+
+// formatMatrix formats `m` as the parameters of an SVG matrix transform.
+func formatMatrix(m transform.Matrix) string {
+	return formatFloats(m[0], m[1], m[3], m[4], m[6], m[7])
+}
+
+// formatColor formats the RGB components of `c` as an SVG color.
+func formatColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
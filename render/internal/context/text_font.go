@@ -137,3 +137,24 @@ func (tf *TextFont) GetRuneMetrics(r rune) (float64, float64, bool) {
 	metrics, ok := tf.origFont.GetRuneMetrics(r)
 	return metrics.Wx, metrics.Wy, ok && metrics.Wx != 0
 }
+
+// TrueType returns the TrueType font program drawing the glyphs of the text
+// font.
+func (tf *TextFont) TrueType() *truetype.Font {
+	return tf.ttf
+}
+
+// FamilyName returns the name of the PDF font the text font draws, without
+// the subset prefix, if any.
+func (tf *TextFont) FamilyName() string {
+	font := tf.Font
+	if tf.origFont != nil {
+		font = tf.origFont
+	}
+
+	name := font.BaseFont()
+	if len(name) > 7 && name[6] == '+' {
+		name = name[7:]
+	}
+	return name
+}
@@ -6,6 +6,7 @@
 package render
 
 import (
+	gocontext "context"
 	"errors"
 
 	"github.com/adrg/sysfont"
@@ -27,7 +28,10 @@ var (
 type renderer struct {
 }
 
-func (r renderer) renderPage(ctx context.Context, page *model.PdfPage) error {
+// renderPage renders `page` to `ctx`, scaling the default user space units
+// of the page by `scale` device pixels. Rendering stops with the error of
+// `goctx` when it is cancelled.
+func (r renderer) renderPage(goctx gocontext.Context, ctx context.Context, page *model.PdfPage, scale float64) error {
 	contents, err := page.GetAllContentStreams()
 	if err != nil {
 		return err
@@ -35,23 +39,23 @@ func (r renderer) renderPage(ctx context.Context, page *model.PdfPage) error {
 
 	// Change coordinate system.
 	ctx.Translate(0, float64(ctx.Height()))
-	ctx.Scale(1, -1)
+	ctx.Scale(scale, -scale)
 
 	// Create white background.
 	ctx.Push()
 	ctx.SetRGBA(1, 1, 1, 1)
-	ctx.DrawRectangle(0, 0, float64(ctx.Width()), float64(ctx.Height()))
+	ctx.DrawRectangle(0, 0, float64(ctx.Width())/scale, float64(ctx.Height())/scale)
 	ctx.Fill()
 	ctx.Pop()
 
 	// Set defaults.
-	ctx.SetLineWidth(1.0)
+	ctx.SetLineWidth(scale)
 	ctx.SetRGBA(0, 0, 0, 1)
 
-	return r.renderContentStream(ctx, contents, page.Resources)
+	return r.renderContentStream(goctx, ctx, contents, page.Resources)
 }
 
-func (r renderer) renderContentStream(ctx context.Context, contents string, resources *model.PdfPageResources) error {
+func (r renderer) renderContentStream(goctx gocontext.Context, ctx context.Context, contents string, resources *model.PdfPageResources) error {
 	operations, err := contentstream.NewContentStreamParser(contents).Parse()
 	if err != nil {
 		return err
@@ -66,6 +70,9 @@ func (r renderer) renderContentStream(ctx context.Context, contents string, reso
 	processor := contentstream.NewContentStreamProcessor(*operations)
 	processor.AddHandler(contentstream.HandlerConditionEnumAllOperands, "",
 		func(op *contentstream.ContentStreamOperation, gs contentstream.GraphicsState, resources *model.PdfPageResources) error {
+			if err := goctx.Err(); err != nil {
+				return err
+			}
 			common.Log.Debug("Processing %s", op.Operand)
 			switch op.Operand {
 			//
@@ -107,8 +114,11 @@ func (r renderer) renderContentStream(ctx context.Context, contents string, reso
 					return err
 				}
 
+				// The line widths are in device space, the current matrix of
+				// the context includes the scaling of the device.
 				// TODO: Take angle into account for line widths (8.4.3.2 Line Width).
-				s := (gs.CTM.ScalingFactorX() + gs.CTM.ScalingFactorY()) / 2.0
+				m := ctx.Matrix()
+				s := (m.ScalingFactorX() + m.ScalingFactorY()) / 2.0
 				ctx.SetLineWidth(s * fw[0])
 			// Set line cap style.
 			case "J":
@@ -729,7 +739,7 @@ func (r renderer) renderContentStream(ctx context.Context, contents string, reso
 					}
 
 					// Process the content stream in the Form object.
-					err = r.renderContentStream(ctx, string(formContent), formResources)
+					err = r.renderContentStream(goctx, ctx, string(formContent), formResources)
 					if err != nil {
 						return err
 					}
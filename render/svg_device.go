@@ -0,0 +1,94 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package render
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"os"
+
+	"github.com/unidoc/unipdf/v3/model"
+	"github.com/unidoc/unipdf/v3/render/internal/context/svgrender"
+)
+
+// SVGDevice is used to convert PDF pages to SVG documents, one unit of the
+// SVG documents per default user space unit of the pages. The paths are
+// converted to SVG paths and the images are embedded as PNG data URIs.
+type SVGDevice struct {
+	renderer
+
+	// TextAsPaths converts the text to paths outlining the glyphs, for the
+	// documents to look the same without the fonts installed. By default, the
+	// text is converted to text elements, which can be selected and searched.
+	TextAsPaths bool
+}
+
+// NewSVGDevice returns a new SVG device.
+func NewSVGDevice() *SVGDevice {
+	return &SVGDevice{}
+}
+
+// Render converts the specified PDF page into an SVG document and returns
+// the result. The document contains the visible region of the page, i.e. its
+// crop box, rotated as specified by the page.
+func (d *SVGDevice) Render(page *model.PdfPage) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := d.RenderToWriter(page, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderToWriter converts the specified PDF page into an SVG document and
+// writes the result to `w`.
+func (d *SVGDevice) RenderToWriter(page *model.PdfPage, w io.Writer) error {
+	return d.RenderToWriterContext(context.Background(), page, w)
+}
+
+// RenderToWriterContext converts the specified PDF page into an SVG document
+// and writes the result to `w` as RenderToWriter, stopping with the error of
+// `ctx` when it is cancelled or its deadline is exceeded.
+func (d *SVGDevice) RenderToWriterContext(ctx context.Context, page *model.PdfPage, w io.Writer) error {
+	// Get page dimensions.
+	mbox, err := page.GetMediaBox()
+	if err != nil {
+		return err
+	}
+	cbox, err := page.GetCropBox()
+	if err != nil {
+		return err
+	}
+	rotate, err := page.GetRotate()
+	if err != nil {
+		return err
+	}
+
+	// Render page.
+	width, height := mbox.Llx+mbox.Width(), mbox.Lly+mbox.Height()
+	renderCtx := svgrender.NewContext(int(math.Ceil(width)), int(math.Ceil(height)))
+	renderCtx.TextAsPaths = d.TextAsPaths
+	if err := d.renderPage(ctx, renderCtx, page, 1); err != nil {
+		return err
+	}
+
+	// Write the crop box region.
+	top := float64(renderCtx.Height()) - cbox.Ury
+	return renderCtx.Write(w, cbox.Llx, top, cbox.Width(), cbox.Height(), rotate)
+}
+
+// RenderToPath converts the specified PDF page into an SVG document and saves
+// the result at the specified location.
+func (d *SVGDevice) RenderToPath(page *model.PdfPage, outputPath string) error {
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return d.RenderToWriter(page, file)
+}
@@ -0,0 +1,61 @@
+/*
+ * This file is subject to the terms and conditions defined in
+ * file 'LICENSE.md', which is part of this source code package.
+ */
+
+package render
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/unidoc/unipdf/v3/model"
+)
+
+func TestSVGDeviceRender(t *testing.T) {
+	page := newTestPage(t)
+
+	device := NewSVGDevice()
+	data, err := device.Render(page)
+	require.NoError(t, err)
+
+	var doc struct {
+		Width   string `xml:"width,attr"`
+		Height  string `xml:"height,attr"`
+		ViewBox string `xml:"viewBox,attr"`
+		Group   struct {
+			Transform string `xml:"transform,attr"`
+			Paths     []struct {
+				D    string `xml:"d,attr"`
+				Fill string `xml:"fill,attr"`
+			} `xml:"path"`
+		} `xml:"g"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	require.Equal(t, "200", doc.Width)
+	require.Equal(t, "100", doc.Height)
+	require.Equal(t, "0 0 200 100", doc.ViewBox)
+	require.Equal(t, "matrix(1 0 0 1 0 0)", doc.Group.Transform)
+
+	// The white background and the red square.
+	require.Len(t, doc.Group.Paths, 2)
+	require.Equal(t, "#ffffff", doc.Group.Paths[0].Fill)
+	require.Equal(t, "#ff0000", doc.Group.Paths[1].Fill)
+	require.Equal(t, "M 0 100 L 50 100 L 50 50 L 0 50 Z", doc.Group.Paths[1].D)
+}
+
+func TestSVGDeviceRenderCropRotate(t *testing.T) {
+	page := newTestPage(t)
+	page.CropBox = &model.PdfRectangle{Urx: 100, Ury: 50}
+	rotate := int64(90)
+	page.Rotate = &rotate
+
+	data, err := NewSVGDevice().Render(page)
+	require.NoError(t, err)
+	svg := string(data)
+	require.True(t, strings.Contains(svg, `width="50" height="100"`), svg)
+	require.True(t, strings.Contains(svg, `<g transform="matrix(0 1 -1 0 100 0)">`), svg)
+}